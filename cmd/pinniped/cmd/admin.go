@@ -0,0 +1,20 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+//nolint:gochecknoglobals
+var adminCmd = &cobra.Command{
+	Use:          "admin",
+	Short:        "Administers one of [sessions]",
+	SilenceUsage: true, // Do not print usage message when commands fail.
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(adminCmd)
+}
@@ -0,0 +1,239 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	sessionv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/session/v1alpha1"
+	"go.pinniped.dev/internal/groupsuffix"
+	supervisorscheme "go.pinniped.dev/internal/supervisor/scheme"
+)
+
+//nolint:gochecknoinits
+func init() {
+	adminCmd.AddCommand(newAdminSessionsCommand(getRealSupervisorClientset))
+}
+
+type adminSessionsFlags struct {
+	outputFormat string // e.g., yaml, json, text
+
+	username             string
+	clientID             string
+	identityProviderName string
+
+	namespace string
+
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+
+	apiGroupSuffix string
+}
+
+func newAdminSessionsCommand(getClientset getSupervisorClientsetFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "sessions",
+		Short:        "Lists or revokes the downstream sessions of the Supervisor",
+		SilenceUsage: true, // do not print usage message when commands fail
+	}
+	cmd.AddCommand(newAdminSessionsListCommand(getClientset))
+	cmd.AddCommand(newAdminSessionsRevokeCommand(getClientset))
+	return cmd
+}
+
+func newAdminSessionsListCommand(getClientset getSupervisorClientsetFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:         cobra.NoArgs, // do not accept positional arguments for this command
+		Use:          "list",
+		Short:        "List the downstream sessions of the Supervisor, optionally filtered by user, client, or identity provider",
+		SilenceUsage: true, // do not print usage message when commands fail
+	}
+	flags := &adminSessionsFlags{}
+	addAdminSessionsFlags(cmd, flags)
+
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		return runAdminSessionsList(cmd.OutOrStdout(), getClientset, flags)
+	}
+
+	return cmd
+}
+
+func newAdminSessionsRevokeCommand(getClientset getSupervisorClientsetFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:         cobra.NoArgs, // do not accept positional arguments for this command
+		Use:          "revoke",
+		Short:        "Revoke the downstream sessions of the Supervisor which belong to a user, client, or identity provider",
+		SilenceUsage: true, // do not print usage message when commands fail
+	}
+	flags := &adminSessionsFlags{}
+	addAdminSessionsFlags(cmd, flags)
+
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		return runAdminSessionsRevoke(cmd.OutOrStdout(), getClientset, flags)
+	}
+
+	return cmd
+}
+
+func addAdminSessionsFlags(cmd *cobra.Command, flags *adminSessionsFlags) {
+	f := cmd.Flags()
+	f.StringVar(&flags.username, "username", "", "Only select the sessions of this downstream username")
+	f.StringVar(&flags.clientID, "client-id", "", "Only select the sessions of this OIDC client")
+	f.StringVar(&flags.identityProviderName, "identity-provider", "", "Only select the sessions which were started using the upstream identity provider with this name")
+	f.StringVarP(&flags.outputFormat, "output", "o", "text", "Output format (e.g., 'yaml', 'json', 'text')")
+	f.StringVarP(&flags.namespace, "namespace", "n", "pinniped-supervisor", "Namespace in which the Supervisor was installed")
+	f.StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
+	f.StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	f.StringVar(&flags.apiGroupSuffix, "api-group-suffix", groupsuffix.PinnipedDefaultSuffix, "Supervisor API group suffix")
+}
+
+func (f *adminSessionsFlags) selector() sessionv1alpha1.SessionSelector {
+	return sessionv1alpha1.SessionSelector{
+		Username:             f.username,
+		ClientID:             f.clientID,
+		IdentityProviderName: f.identityProviderName,
+	}
+}
+
+func runAdminSessionsList(output io.Writer, getClientset getSupervisorClientsetFunc, flags *adminSessionsFlags) error {
+	if err := validateAdminSessionsOutputFormat(flags.outputFormat); err != nil {
+		return err
+	}
+
+	clientset, err := getClientset(newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride), flags.apiGroupSuffix)
+	if err != nil {
+		return fmt.Errorf("could not configure Kubernetes client: %w", err)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancelFunc()
+	listRequest, err := clientset.SessionV1alpha1().SessionListRequests(flags.namespace).Create(ctx,
+		&sessionv1alpha1.SessionListRequest{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "pinniped-cli-"},
+			Spec:       sessionv1alpha1.SessionListRequestSpec{Selector: flags.selector()},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("could not complete SessionListRequest%s: %w", adminSessionsHint(err), err)
+	}
+
+	if flags.outputFormat == "text" {
+		return writeSessionsTable(output, listRequest.Status.Sessions, "No sessions found.")
+	}
+	return serializeSessionObject(output, flags, listRequest, "SessionListRequest")
+}
+
+func runAdminSessionsRevoke(output io.Writer, getClientset getSupervisorClientsetFunc, flags *adminSessionsFlags) error {
+	if err := validateAdminSessionsOutputFormat(flags.outputFormat); err != nil {
+		return err
+	}
+
+	// Never revoke the sessions of every user by accident.
+	if flags.selector() == (sessionv1alpha1.SessionSelector{}) {
+		return errors.New("at least one of --username, --client-id, or --identity-provider is required")
+	}
+
+	clientset, err := getClientset(newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride), flags.apiGroupSuffix)
+	if err != nil {
+		return fmt.Errorf("could not configure Kubernetes client: %w", err)
+	}
+
+	// Revoking a session may involve revoking its upstream tokens, so allow more time than a list.
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFunc()
+	revocationRequest, err := clientset.SessionV1alpha1().SessionRevocationRequests(flags.namespace).Create(ctx,
+		&sessionv1alpha1.SessionRevocationRequest{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "pinniped-cli-"},
+			Spec:       sessionv1alpha1.SessionRevocationRequestSpec{Selector: flags.selector()},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("could not complete SessionRevocationRequest%s: %w", adminSessionsHint(err), err)
+	}
+
+	if flags.outputFormat == "text" {
+		return writeSessionsTable(output, revocationRequest.Status.RevokedSessions, "No sessions were revoked.")
+	}
+	return serializeSessionObject(output, flags, revocationRequest, "SessionRevocationRequest")
+}
+
+func validateAdminSessionsOutputFormat(outputFormat string) error {
+	switch outputFormat {
+	case "text", "json", "yaml":
+		return nil
+	default:
+		return fmt.Errorf("unknown output format: %q", outputFormat)
+	}
+}
+
+func adminSessionsHint(err error) string {
+	if apierrors.IsNotFound(err) {
+		return " (is the Pinniped Supervisor session API running and healthy?)"
+	}
+	return ""
+}
+
+func writeSessionsTable(output io.Writer, sessions []sessionv1alpha1.DownstreamSession, noSessionsMessage string) error {
+	if len(sessions) == 0 {
+		_, err := fmt.Fprintln(output, noSessionsMessage)
+		return err
+	}
+
+	w := tabwriter.NewWriter(output, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tCLIENT\tIDENTITY PROVIDER\tSTARTED\tEXPIRES")
+	for _, session := range sessions {
+		expires := "<none>"
+		if session.ExpiresAt != nil {
+			expires = session.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s (%s)\t%s\t%s\n",
+			session.ID,
+			session.Username,
+			session.ClientID,
+			session.IdentityProviderName,
+			session.IdentityProviderType,
+			session.StartedAt.UTC().Format(time.RFC3339),
+			expires,
+		)
+	}
+	return w.Flush()
+}
+
+func serializeSessionObject(output io.Writer, flags *adminSessionsFlags, obj runtime.Object, kind string) error {
+	contentType := runtime.ContentTypeJSON
+	if flags.outputFormat == "yaml" {
+		contentType = runtime.ContentTypeYAML
+	}
+
+	scheme, _, _, sessionGV := supervisorscheme.New(flags.apiGroupSuffix)
+	codecs := serializer.NewCodecFactory(scheme)
+	respInfo, ok := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), contentType)
+	if !ok {
+		return fmt.Errorf("unknown content type: %q", contentType)
+	}
+
+	serializer := respInfo.PrettySerializer
+	if serializer == nil {
+		serializer = respInfo.Serializer
+	}
+
+	// Ensure that these fields are set so that the JSON/YAML output tells the full story.
+	obj.GetObjectKind().SetGroupVersionKind(sessionGV.WithKind(kind))
+
+	return serializer.Encode(obj, output)
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+
+	sessionv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/session/v1alpha1"
+	supervisorclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	fakesupervisorclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/here"
+)
+
+func TestAdminSessions(t *testing.T) {
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := metav1.NewTime(startedAt.Add(8 * time.Hour))
+	sessions := []sessionv1alpha1.DownstreamSession{
+		{
+			ID:                   "request-1",
+			Username:             "pinny",
+			ClientID:             "pinniped-cli",
+			IdentityProviderName: "some-ldap-idp",
+			IdentityProviderType: "ldap",
+			Scopes:               []string{"openid"},
+			StartedAt:            metav1.NewTime(startedAt),
+			ExpiresAt:            &expiresAt,
+		},
+		{
+			ID:                   "request-2",
+			Username:             "pinny",
+			ClientID:             "some-client",
+			IdentityProviderName: "other-oidc-idp",
+			IdentityProviderType: "oidc",
+			StartedAt:            metav1.NewTime(startedAt.Add(time.Hour)),
+		},
+	}
+
+	tests := []struct {
+		name                string
+		args                []string
+		sessionsOverride    []sessionv1alpha1.DownstreamSession
+		gettingClientsetErr error
+		callingAPIErr       error
+		wantSelector        *sessionv1alpha1.SessionSelector
+		wantNamespace       string
+		wantError           bool
+		wantStdout          string
+		wantStderr          string
+	}{
+		{
+			name: "list help flag",
+			args: []string{"list", "--help"},
+			wantStdout: here.Doc(`
+				List the downstream sessions of the Supervisor, optionally filtered by user, client, or identity provider
+
+				Usage:
+				  sessions list [flags]
+
+				Flags:
+				      --api-group-suffix string     Supervisor API group suffix (default "pinniped.dev")
+				      --client-id string            Only select the sessions of this OIDC client
+				  -h, --help                        help for list
+				      --identity-provider string    Only select the sessions which were started using the upstream identity provider with this name
+				      --kubeconfig string           Path to kubeconfig file
+				      --kubeconfig-context string   Kubeconfig context name (default: current active context)
+				  -n, --namespace string            Namespace in which the Supervisor was installed (default "pinniped-supervisor")
+				  -o, --output string               Output format (e.g., 'yaml', 'json', 'text') (default "text")
+				      --username string             Only select the sessions of this downstream username
+			`),
+		},
+		{
+			name:          "list as text",
+			args:          []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml", "--username", "pinny"},
+			wantSelector:  &sessionv1alpha1.SessionSelector{Username: "pinny"},
+			wantNamespace: "pinniped-supervisor",
+			wantStdout: here.Doc(`
+				ID          USERNAME   CLIENT         IDENTITY PROVIDER       STARTED                EXPIRES
+				request-1   pinny      pinniped-cli   some-ldap-idp (ldap)    2026-01-02T03:04:05Z   2026-01-02T11:04:05Z
+				request-2   pinny      some-client    other-oidc-idp (oidc)   2026-01-02T04:04:05Z   <none>
+			`),
+		},
+		{
+			name:             "list without any sessions",
+			args:             []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml", "-n", "some-namespace"},
+			sessionsOverride: []sessionv1alpha1.DownstreamSession{},
+			wantSelector:     &sessionv1alpha1.SessionSelector{},
+			wantNamespace:    "some-namespace",
+			wantStdout:       "No sessions found.\n",
+		},
+		{
+			name:             "list as json",
+			args:             []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml", "--client-id", "pinniped-cli", "-o", "json"},
+			sessionsOverride: sessions[:1],
+			wantSelector:     &sessionv1alpha1.SessionSelector{ClientID: "pinniped-cli"},
+			wantNamespace:    "pinniped-supervisor",
+			wantStdout: here.Doc(`
+				{
+				  "kind": "SessionListRequest",
+				  "apiVersion": "session.supervisor.pinniped.dev/v1alpha1",
+				  "metadata": {
+				    "creationTimestamp": null
+				  },
+				  "spec": {
+				    "selector": {
+				      "clientID": "pinniped-cli"
+				    }
+				  },
+				  "status": {
+				    "sessions": [
+				      {
+				        "id": "request-1",
+				        "username": "pinny",
+				        "clientID": "pinniped-cli",
+				        "identityProviderName": "some-ldap-idp",
+				        "identityProviderType": "ldap",
+				        "scopes": [
+				          "openid"
+				        ],
+				        "startedAt": "2026-01-02T03:04:05Z",
+				        "expiresAt": "2026-01-02T11:04:05Z"
+				      }
+				    ]
+				  }
+				}`),
+		},
+		{
+			name:             "list as yaml with a different API group suffix",
+			args:             []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml", "-o", "yaml", "--api-group-suffix", "tuna.io"},
+			sessionsOverride: []sessionv1alpha1.DownstreamSession{},
+			wantSelector:     &sessionv1alpha1.SessionSelector{},
+			wantNamespace:    "pinniped-supervisor",
+			wantStdout: here.Doc(`
+				apiVersion: session.supervisor.tuna.io/v1alpha1
+				kind: SessionListRequest
+				metadata:
+				  creationTimestamp: null
+				spec:
+				  selector: {}
+				status: {}
+			`),
+		},
+		{
+			name:       "list with an invalid output format",
+			args:       []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml", "-o", "csv"},
+			wantError:  true,
+			wantStderr: "Error: unknown output format: \"csv\"\n",
+		},
+		{
+			name:          "list fails because the session API is not installed",
+			args:          []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml"},
+			callingAPIErr: errors.NewNotFound(sessionv1alpha1.SchemeGroupVersion.WithResource("sessionlistrequests").GroupResource(), "whatever"),
+			wantSelector:  &sessionv1alpha1.SessionSelector{},
+			wantNamespace: "pinniped-supervisor",
+			wantError:     true,
+			wantStderr:    "Error: could not complete SessionListRequest (is the Pinniped Supervisor session API running and healthy?): sessionlistrequests.session.supervisor.pinniped.dev \"whatever\" not found\n",
+		},
+		{
+			name:                "getting clientset fails",
+			args:                []string{"list", "--kubeconfig", "testdata/kubeconfig.yaml"},
+			gettingClientsetErr: constable.Error("some get clientset error"),
+			wantError:           true,
+			wantStderr:          "Error: could not configure Kubernetes client: some get clientset error\n",
+		},
+		{
+			name:          "revoke as text",
+			args:          []string{"revoke", "--kubeconfig", "testdata/kubeconfig.yaml", "--username", "pinny", "--identity-provider", "some-ldap-idp"},
+			wantSelector:  &sessionv1alpha1.SessionSelector{Username: "pinny", IdentityProviderName: "some-ldap-idp"},
+			wantNamespace: "pinniped-supervisor",
+			wantStdout: here.Doc(`
+				ID          USERNAME   CLIENT         IDENTITY PROVIDER       STARTED                EXPIRES
+				request-1   pinny      pinniped-cli   some-ldap-idp (ldap)    2026-01-02T03:04:05Z   2026-01-02T11:04:05Z
+				request-2   pinny      some-client    other-oidc-idp (oidc)   2026-01-02T04:04:05Z   <none>
+			`),
+		},
+		{
+			name:             "revoke without any sessions",
+			args:             []string{"revoke", "--kubeconfig", "testdata/kubeconfig.yaml", "--client-id", "some-client"},
+			sessionsOverride: []sessionv1alpha1.DownstreamSession{},
+			wantSelector:     &sessionv1alpha1.SessionSelector{ClientID: "some-client"},
+			wantNamespace:    "pinniped-supervisor",
+			wantStdout:       "No sessions were revoked.\n",
+		},
+		{
+			name:       "revoke without a selector",
+			args:       []string{"revoke", "--kubeconfig", "testdata/kubeconfig.yaml"},
+			wantError:  true,
+			wantStderr: "Error: at least one of --username, --client-id, or --identity-provider is required\n",
+		},
+		{
+			name:          "revoke fails",
+			args:          []string{"revoke", "--kubeconfig", "testdata/kubeconfig.yaml", "--username", "pinny"},
+			callingAPIErr: constable.Error("some API error"),
+			wantSelector:  &sessionv1alpha1.SessionSelector{Username: "pinny"},
+			wantNamespace: "pinniped-supervisor",
+			wantError:     true,
+			wantStderr:    "Error: could not complete SessionRevocationRequest: some API error\n",
+		},
+		{
+			name:       "extra args",
+			args:       []string{"list", "extra-arg"},
+			wantError:  true,
+			wantStderr: "Error: unknown command \"extra-arg\" for \"sessions list\"\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var gotSelector *sessionv1alpha1.SessionSelector
+			var gotNamespace string
+			getClientset := func(clientConfig clientcmd.ClientConfig, apiGroupSuffix string) (supervisorclientset.Interface, error) {
+				if test.gettingClientsetErr != nil {
+					return nil, test.gettingClientsetErr
+				}
+				returnedSessions := sessions
+				if test.sessionsOverride != nil {
+					returnedSessions = test.sessionsOverride
+				}
+				clientset := fakesupervisorclientset.NewSimpleClientset()
+				clientset.PrependReactor("create", "sessionlistrequests", func(action kubetesting.Action) (bool, runtime.Object, error) {
+					req := action.(kubetesting.CreateAction).GetObject().(*sessionv1alpha1.SessionListRequest)
+					gotSelector, gotNamespace = &req.Spec.Selector, action.GetNamespace()
+					if test.callingAPIErr != nil {
+						return true, nil, test.callingAPIErr
+					}
+					return true, &sessionv1alpha1.SessionListRequest{
+						Spec:   req.Spec,
+						Status: sessionv1alpha1.SessionListRequestStatus{Sessions: returnedSessions},
+					}, nil
+				})
+				clientset.PrependReactor("create", "sessionrevocationrequests", func(action kubetesting.Action) (bool, runtime.Object, error) {
+					req := action.(kubetesting.CreateAction).GetObject().(*sessionv1alpha1.SessionRevocationRequest)
+					gotSelector, gotNamespace = &req.Spec.Selector, action.GetNamespace()
+					if test.callingAPIErr != nil {
+						return true, nil, test.callingAPIErr
+					}
+					return true, &sessionv1alpha1.SessionRevocationRequest{
+						Spec:   req.Spec,
+						Status: sessionv1alpha1.SessionRevocationRequestStatus{RevokedSessions: returnedSessions},
+					}, nil
+				})
+				return clientset, nil
+			}
+			cmd := newAdminSessionsCommand(getClientset)
+
+			stdout, stderr := bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetArgs(test.args)
+
+			err := cmd.Execute()
+			if test.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.wantStdout, stdout.String())
+			require.Equal(t, test.wantStderr, stderr.String())
+			require.Equal(t, test.wantSelector, gotSelector)
+			require.Equal(t, test.wantNamespace, gotNamespace)
+		})
+	}
+}
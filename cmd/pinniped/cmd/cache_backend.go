@@ -0,0 +1,74 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/execcredcache"
+	"go.pinniped.dev/internal/keychain"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// The supported values of the --cache-backend flag.
+	cacheBackendFile     = "file"
+	cacheBackendKeychain = "keychain"
+
+	// The user may override the cache backend selected by `--cache-backend` using an env var. This allows the user to
+	// keep their sessions and credentials in the OS keychain without editing the login command in their kubeconfig.
+	cacheBackendEnvVarName = "PINNIPED_CACHE_BACKEND"
+)
+
+// credentialCache is implemented by the cluster credential caches of the execcredcache package.
+type credentialCache interface {
+	Get(key interface{}) *clientauthv1beta1.ExecCredential
+	Put(key interface{}, cred *clientauthv1beta1.ExecCredential)
+}
+
+// cacheKeychain returns the keychain in which the sessions and credentials should be cached, or nil when they should
+// be cached in files. When the keychain was requested but is not available on this machine, it transparently falls
+// back to the files.
+func cacheKeychain(
+	requestedBackend string,
+	lookupEnv func(string) (string, bool),
+	openKeychain func() (keychain.Keychain, error),
+	pLogger plog.Logger,
+) (keychain.Keychain, error) {
+	backendSource := "--cache-backend"
+	if backendOverride, hasBackendOverride := lookupEnv(cacheBackendEnvVarName); hasBackendOverride {
+		requestedBackend = backendOverride
+		backendSource = cacheBackendEnvVarName
+	}
+
+	switch requestedBackend {
+	case cacheBackendFile, "":
+		return nil, nil
+	case cacheBackendKeychain:
+		kc, err := openKeychain()
+		if err != nil {
+			pLogger.DebugErr("OS keychain is not available, using cache files instead", err)
+			return nil, nil
+		}
+		return kc, nil
+	default:
+		return nil, fmt.Errorf(
+			"%s value not recognized: %s (supported values: %s)",
+			backendSource, requestedBackend,
+			strings.Join([]string{cacheBackendFile, cacheBackendKeychain}, ", "))
+	}
+}
+
+// newCredentialCache returns a cluster credential cache backed by the file at the given path, or by the keychain when
+// it is not nil. In the latter case the file is still used whenever the keychain cannot be used.
+func newCredentialCache(path string, kc keychain.Keychain) credentialCache {
+	fileCache := execcredcache.New(path)
+	if kc == nil {
+		return fileCache
+	}
+	return execcredcache.NewKeychain(kc, fileCache)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -19,6 +19,8 @@ import (
 	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // Adds handlers for various dynamic auth plugins in client-go
 	"k8s.io/client-go/tools/clientcmd"
@@ -65,6 +67,8 @@ type getKubeconfigOIDCParams struct {
 	debugSessionCache bool
 	caBundle          caBundleFlag
 	requestAudience   string
+	grantType         string
+	showQRCode        bool
 	upstreamIDPName   string
 	upstreamIDPType   string
 	upstreamIDPFlow   string
@@ -88,6 +92,11 @@ type getKubeconfigParams struct {
 	skipValidate              bool
 	timeout                   time.Duration
 	outputPath                string
+	merge                     bool
+	contextName               string
+	userName                  string
+	clusterName               string
+	clustersPath              string
 	staticToken               string
 	staticTokenEnvName        string
 	oidc                      getKubeconfigOIDCParams
@@ -96,6 +105,7 @@ type getKubeconfigParams struct {
 	credentialCachePath       string
 	credentialCachePathSet    bool
 	installHint               string
+	execAPIVersion            string
 }
 
 type discoveryResponseScopesSupported struct {
@@ -141,6 +151,8 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 	f.Var(&flags.oidc.caBundle, "oidc-ca-bundle", "Path to TLS certificate authority bundle (PEM format, optional, can be repeated)")
 	f.BoolVar(&flags.oidc.debugSessionCache, "oidc-debug-session-cache", false, "Print debug logs related to the OpenID Connect session cache")
 	f.StringVar(&flags.oidc.requestAudience, "oidc-request-audience", "", "Request a token with an alternate audience using RFC8693 token exchange")
+	f.StringVar(&flags.oidc.grantType, "oidc-grant-type", "", fmt.Sprintf("The OAuth2 grant type to use during OpenID Connect login (e.g. '%s', '%s')", grantTypeAuthorizationCode, grantTypeDevice))
+	f.BoolVar(&flags.oidc.showQRCode, "oidc-show-qr-code", false, "During OpenID Connect login, also print the login link as a QR code (device grant type only)")
 	f.StringVar(&flags.oidc.upstreamIDPName, "upstream-identity-provider-name", "", "The name of the upstream identity provider used during login with a Supervisor")
 	f.StringVar(&flags.oidc.upstreamIDPType, "upstream-identity-provider-type", "", fmt.Sprintf("The type of the upstream identity provider used during login with a Supervisor (e.g. '%s', '%s', '%s')", idpdiscoveryv1alpha1.IDPTypeOIDC, idpdiscoveryv1alpha1.IDPTypeLDAP, idpdiscoveryv1alpha1.IDPTypeActiveDirectory))
 	f.StringVar(&flags.oidc.upstreamIDPFlow, "upstream-identity-provider-flow", "", fmt.Sprintf("The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. '%s', '%s')", idpdiscoveryv1alpha1.IDPFlowCLIPassword, idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode))
@@ -149,8 +161,13 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 	f.BoolVar(&flags.skipValidate, "skip-validation", false, "Skip final validation of the kubeconfig (default: false)")
 	f.DurationVar(&flags.timeout, "timeout", 10*time.Minute, "Timeout for autodiscovery and validation")
 	f.StringVarP(&flags.outputPath, "output", "o", "", "Output file path (default: stdout)")
+	f.BoolVar(&flags.merge, "merge", false, "Merge the generated cluster, user, and context into the kubeconfig and switch to the context, instead of printing a new kubeconfig")
+	f.StringVar(&flags.contextName, "context-name", "", "Name of the generated context (default: the current context name with the --generated-name-suffix)")
+	f.StringVar(&flags.userName, "user-name", "", "Name of the generated user (default: the current user name with the --generated-name-suffix)")
+	f.StringVar(&flags.clustersPath, "clusters", "", "Path to a file which lists the clusters to generate a single kubeconfig for, or - to read it from stdin")
 	f.StringVar(&flags.generatedNameSuffix, "generated-name-suffix", "-pinniped", "Suffix to append to generated cluster, context, user kubeconfig entries")
 	f.StringVar(&flags.credentialCachePath, "credential-cache", "", "Path to cluster-specific credentials cache")
+	f.StringVar(&flags.execAPIVersion, "exec-api-version", "", "The client.authentication.k8s.io API version of the ExecCredential of the generated kubeconfig (e.g. 'v1beta1', 'v1') (default: autodetect from the Kubernetes version of the cluster)")
 	f.StringVar(&flags.installHint, "install-hint", "The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli for more details", "This text is shown to the user when the pinniped CLI is not installed.")
 	mustMarkHidden(cmd, "oidc-debug-session-cache")
 
@@ -161,6 +178,14 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 	mustMarkHidden(cmd, "concierge-namespace")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if flags.merge && flags.outputPath != "" {
+			return fmt.Errorf("only one of --merge and --output can be specified")
+		}
+		// When --kubeconfig was not specified, let the client-go loading rules follow the KUBECONFIG list of files,
+		// instead of treating the whole value of KUBECONFIG as a single path.
+		if !cmd.Flags().Changed("kubeconfig") {
+			flags.kubeconfigPath = ""
+		}
 		if flags.outputPath != "" {
 			out, err := os.Create(flags.outputPath)
 			if err != nil {
@@ -170,6 +195,18 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 			cmd.SetOut(out)
 		}
 		flags.credentialCachePathSet = cmd.Flags().Changed("credential-cache")
+		if flags.clustersPath != "" {
+			for _, name := range []string{"kubeconfig", "kubeconfig-context", "context-name", "user-name", "merge"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--%s cannot be used with --clusters", name)
+				}
+			}
+			clusters, err := readKubeconfigClusters(cmd.InOrStdin(), flags.clustersPath)
+			if err != nil {
+				return err
+			}
+			return runGetKubeconfigForClusters(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), deps, flags, clusters)
+		}
 		return runGetKubeconfig(cmd.Context(), cmd.OutOrStdout(), deps, flags)
 	}
 	return cmd
@@ -179,6 +216,23 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 	ctx, cancel := context.WithTimeout(ctx, flags.timeout)
 	defer cancel()
 
+	if err := prepareGetKubeconfig(ctx, flags); err != nil {
+		return err
+	}
+
+	clientConfig := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
+	kubeconfig, err := generateKubeconfig(ctx, deps, flags, clientConfig)
+	if err != nil {
+		return err
+	}
+
+	if flags.merge {
+		return mergeKubeconfig(out, clientConfig.ConfigAccess(), kubeconfig)
+	}
+	return writeConfigAsYAML(out, kubeconfig)
+}
+
+func prepareGetKubeconfig(ctx context.Context, flags getKubeconfigParams) error {
 	// the log statements in this file assume that Info logs are unconditionally printed so we set the global level to info
 	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, plog.LogSpec{Level: plog.LevelInfo, Format: plog.FormatCLI}); err != nil {
 		return err
@@ -189,19 +243,28 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 		return fmt.Errorf("invalid API group suffix: %w", err)
 	}
 
-	clientConfig := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
+	if flags.execAPIVersion != "" && flags.execAPIVersion != "v1beta1" && flags.execAPIVersion != "v1" {
+		return fmt.Errorf(`invalid --exec-api-version %q, supported values are "v1beta1" and "v1"`, flags.execAPIVersion)
+	}
+	return nil
+}
+
+// generateKubeconfig autodiscovers the Concierge and Supervisor settings of the cluster of the given client config,
+// and returns a new kubeconfig for that cluster which runs "pinniped login". The flags are passed by value, so the
+// autodiscovered settings do not leak into the kubeconfigs generated for other clusters.
+func generateKubeconfig(ctx context.Context, deps kubeconfigDeps, flags getKubeconfigParams, clientConfig clientcmd.ClientConfig) (clientcmdapi.Config, error) {
 	currentKubeConfig, err := clientConfig.RawConfig()
 	if err != nil {
-		return fmt.Errorf("could not load --kubeconfig: %w", err)
+		return clientcmdapi.Config{}, fmt.Errorf("could not load --kubeconfig: %w", err)
 	}
 	currentKubeconfigNames, err := getCurrentContext(currentKubeConfig, flags)
 	if err != nil {
-		return fmt.Errorf("could not load --kubeconfig/--kubeconfig-context: %w", err)
+		return clientcmdapi.Config{}, fmt.Errorf("could not load --kubeconfig/--kubeconfig-context: %w", err)
 	}
 	cluster := currentKubeConfig.Clusters[currentKubeconfigNames.ClusterName]
 	clientset, err := deps.getClientset(clientConfig, flags.concierge.apiGroupSuffix)
 	if err != nil {
-		return fmt.Errorf("could not configure Kubernetes client: %w", err)
+		return clientcmdapi.Config{}, fmt.Errorf("could not configure Kubernetes client: %w", err)
 	}
 
 	// Generate the new context/cluster/user names by appending the --generated-name-suffix to the original values.
@@ -210,11 +273,20 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 		UserName:    currentKubeconfigNames.UserName + flags.generatedNameSuffix,
 		ClusterName: currentKubeconfigNames.ClusterName + flags.generatedNameSuffix,
 	}
+	if flags.contextName != "" {
+		newKubeconfigNames.ContextName = flags.contextName
+	}
+	if flags.userName != "" {
+		newKubeconfigNames.UserName = flags.userName
+	}
+	if flags.clusterName != "" {
+		newKubeconfigNames.ClusterName = flags.clusterName
+	}
 
 	if !flags.concierge.disabled {
 		credentialIssuer, err := waitForCredentialIssuer(ctx, clientset, flags, deps)
 		if err != nil {
-			return err
+			return clientcmdapi.Config{}, err
 		}
 
 		authenticator, err := lookupAuthenticator(
@@ -224,13 +296,13 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 			deps.log,
 		)
 		if err != nil {
-			return err
+			return clientcmdapi.Config{}, err
 		}
 		if err := discoverConciergeParams(credentialIssuer, &flags, cluster, deps.log); err != nil {
-			return err
+			return clientcmdapi.Config{}, err
 		}
 		if err := discoverAuthenticatorParams(authenticator, &flags, deps.log); err != nil {
-			return err
+			return clientcmdapi.Config{}, err
 		}
 
 		// Point kubectl at the concierge endpoint.
@@ -241,21 +313,24 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 	if len(flags.oidc.issuer) > 0 {
 		err = pinnipedSupervisorDiscovery(ctx, &flags, deps.log)
 		if err != nil {
-			return err
+			return clientcmdapi.Config{}, err
 		}
 	}
 
+	if flags.execAPIVersion == "" {
+		flags.execAPIVersion = detectExecAPIVersion(clientset, deps.log)
+	}
+
 	execConfig, err := newExecConfig(deps, flags)
 	if err != nil {
-		return err
+		return clientcmdapi.Config{}, err
 	}
 
 	kubeconfig := newExecKubeconfig(cluster, execConfig, newKubeconfigNames)
 	if err := validateKubeconfig(ctx, flags, kubeconfig, deps.log); err != nil {
-		return err
+		return clientcmdapi.Config{}, err
 	}
-
-	return writeConfigAsYAML(out, kubeconfig)
+	return kubeconfig, nil
 }
 
 func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdapi.ExecConfig, error) {
@@ -265,6 +340,9 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 		Env:                []clientcmdapi.ExecEnvVar{},
 		ProvideClusterInfo: true,
 	}
+	if flags.execAPIVersion == "v1" {
+		execConfig.APIVersion = clientauthenticationv1.SchemeGroupVersion.String()
+	}
 
 	execConfig.InstallHint = flags.installHint
 	var err error
@@ -302,6 +380,10 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 		if flags.staticTokenEnvName != "" {
 			execConfig.Args = append(execConfig.Args, "--token-env="+flags.staticTokenEnvName)
 		}
+		// The v1 API requires the interactive mode. The static login never reads from stdin.
+		if flags.execAPIVersion == "v1" {
+			execConfig.InteractiveMode = clientcmdapi.NeverExecInteractiveMode
+		}
 		return execConfig, nil
 	}
 
@@ -339,6 +421,12 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 		}
 		execConfig.Args = append(execConfig.Args, "--request-audience="+flags.oidc.requestAudience)
 	}
+	if flags.oidc.grantType != "" {
+		execConfig.Args = append(execConfig.Args, "--grant-type="+flags.oidc.grantType)
+	}
+	if flags.oidc.showQRCode {
+		execConfig.Args = append(execConfig.Args, "--show-qr-code")
+	}
 	if flags.oidc.upstreamIDPName != "" {
 		execConfig.Args = append(execConfig.Args, "--upstream-identity-provider-name="+flags.oidc.upstreamIDPName)
 	}
@@ -348,10 +436,31 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 	if flags.oidc.upstreamIDPFlow != "" {
 		execConfig.Args = append(execConfig.Args, "--upstream-identity-provider-flow="+flags.oidc.upstreamIDPFlow)
 	}
+	// The v1 API requires the interactive mode. The OIDC login may prompt for a username and password, or for an
+	// authorization code to be pasted, so it uses stdin when it is available.
+	if flags.execAPIVersion == "v1" {
+		execConfig.InteractiveMode = clientcmdapi.IfAvailableExecInteractiveMode
+	}
 
 	return execConfig, nil
 }
 
+// detectExecAPIVersion returns the ExecCredential API version for the generated kubeconfig. kubectl supports the v1 API
+// since Kubernetes 1.22, and kubectl is supported within one minor version of the cluster, so v1 is only used for
+// clusters with Kubernetes 1.23 or later. When the version of the cluster is unknown, v1beta1 is used.
+func detectExecAPIVersion(clientset conciergeclientset.Interface, log plog.MinLogger) string {
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return "v1beta1"
+	}
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil || !serverVersion.AtLeast(version.MustParseGeneric("1.23")) {
+		return "v1beta1"
+	}
+	log.Info("discovered Kubernetes version which supports the v1 ExecCredential API", "version", info.GitVersion)
+	return "v1"
+}
+
 type kubeconfigNames struct{ ContextName, UserName, ClusterName string }
 
 func getCurrentContext(currentKubeConfig clientcmdapi.Config, flags getKubeconfigParams) (*kubeconfigNames, error) {
@@ -603,8 +712,12 @@ func lookupAuthenticator(clientset conciergeclientset.Interface, authType, authN
 			return clientset.AuthenticationV1alpha1().WebhookAuthenticators().Get(ctx, authName, metav1.GetOptions{})
 		case "jwt":
 			return clientset.AuthenticationV1alpha1().JWTAuthenticators().Get(ctx, authName, metav1.GetOptions{})
+		case "x509":
+			return clientset.AuthenticationV1alpha1().X509ClientCertificateAuthenticators().Get(ctx, authName, metav1.GetOptions{})
+		case "serviceaccounttoken":
+			return clientset.AuthenticationV1alpha1().ServiceAccountTokenAuthenticators().Get(ctx, authName, metav1.GetOptions{})
 		default:
-			return nil, fmt.Errorf(`invalid authenticator type %q, supported values are "webhook" and "jwt"`, authType)
+			return nil, fmt.Errorf(`invalid authenticator type %q, supported values are "webhook", "jwt", "x509", and "serviceaccounttoken"`, authType)
 		}
 	}
 
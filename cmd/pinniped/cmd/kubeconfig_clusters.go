@@ -0,0 +1,205 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// kubeconfigClusters is the format of the file which is passed to "pinniped get kubeconfig --clusters".
+type kubeconfigClusters struct {
+	Clusters []kubeconfigCluster `json:"clusters"`
+}
+
+type kubeconfigCluster struct {
+	// Kubeconfig is the path to the admin kubeconfig of the cluster. When it is empty, the kubeconfig is found
+	// using the same rules as kubectl.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// Context is the name of the context of the cluster in the kubeconfig. When it is empty, the current context is used.
+	Context string `json:"context,omitempty"`
+
+	// Name is the name of the generated cluster, user, and context. When it is empty, the names of the kubeconfig
+	// context, user, and cluster are used with the --generated-name-suffix.
+	Name string `json:"name,omitempty"`
+}
+
+// description returns a short description of the cluster for log and error messages.
+func (c kubeconfigCluster) description() string {
+	description := c.Kubeconfig
+	if description == "" {
+		description = "default kubeconfig"
+	}
+	if c.Context != "" {
+		description += " (context " + c.Context + ")"
+	}
+	return description
+}
+
+func readKubeconfigClusters(stdin io.Reader, path string) ([]kubeconfigCluster, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read --clusters: %w", err)
+	}
+
+	var clusters kubeconfigClusters
+	if err := yaml.UnmarshalStrict(data, &clusters); err != nil {
+		return nil, fmt.Errorf("could not parse --clusters: %w", err)
+	}
+	if len(clusters.Clusters) == 0 {
+		return nil, fmt.Errorf("could not parse --clusters: no clusters are listed")
+	}
+	return clusters.Clusters, nil
+}
+
+type kubeconfigClusterResult struct {
+	kubeconfig clientcmdapi.Config
+	log        *bufferedLogger
+	err        error
+}
+
+// runGetKubeconfigForClusters generates a kubeconfig for each of the clusters concurrently, and prints a single kubeconfig
+// which contains all of them. When the kubeconfig cannot be generated for some of the clusters, their errors are printed
+// and the kubeconfig for the other clusters is still printed, but an error is returned so that the command fails.
+func runGetKubeconfigForClusters(ctx context.Context, out io.Writer, errOut io.Writer, deps kubeconfigDeps, flags getKubeconfigParams, clusters []kubeconfigCluster) error {
+	ctx, cancel := context.WithTimeout(ctx, flags.timeout)
+	defer cancel()
+
+	if err := prepareGetKubeconfig(ctx, flags); err != nil {
+		return err
+	}
+
+	results := make([]kubeconfigClusterResult, len(clusters))
+	var wg sync.WaitGroup
+	for i := range clusters {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = generateKubeconfigForCluster(ctx, deps, flags, clusters[i])
+		}()
+	}
+	wg.Wait()
+
+	merged := clientcmdapi.NewConfig()
+	failed := 0
+	for i, result := range results {
+		description := clusters[i].description()
+
+		// Print the logs of each cluster together, in the order of the list of clusters.
+		result.log.replay(deps.log, "cluster", description)
+
+		err := result.err
+		if err == nil {
+			err = addGeneratedKubeconfig(merged, result.kubeconfig)
+		}
+		if err != nil {
+			failed++
+			if _, writeErr := fmt.Fprintf(errOut, "Error: could not generate kubeconfig for cluster %s: %v\n", description, err); writeErr != nil {
+				return fmt.Errorf("could not write output: %w", writeErr)
+			}
+			continue
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = result.kubeconfig.CurrentContext
+		}
+	}
+
+	if failed == len(clusters) {
+		return fmt.Errorf("could not generate kubeconfig for any of the %d clusters", len(clusters))
+	}
+	if err := writeConfigAsYAML(out, *merged); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("could not generate kubeconfig for %d of the %d clusters", failed, len(clusters))
+	}
+	return nil
+}
+
+func generateKubeconfigForCluster(ctx context.Context, deps kubeconfigDeps, flags getKubeconfigParams, cluster kubeconfigCluster) kubeconfigClusterResult {
+	log := &bufferedLogger{}
+	deps.log = log
+
+	flags.kubeconfigPath = cluster.Kubeconfig
+	flags.kubeconfigContextOverride = cluster.Context
+	flags.contextName = cluster.Name
+	flags.userName = cluster.Name
+	flags.clusterName = cluster.Name
+
+	kubeconfig, err := generateKubeconfig(ctx, deps, flags, newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride))
+	return kubeconfigClusterResult{kubeconfig: kubeconfig, log: log, err: err}
+}
+
+// addGeneratedKubeconfig adds the cluster, user, and context of a generated kubeconfig to the merged kubeconfig, unless
+// the kubeconfig of another cluster already used one of their names.
+func addGeneratedKubeconfig(merged *clientcmdapi.Config, generated clientcmdapi.Config) error {
+	for name := range generated.Clusters {
+		if _, exists := merged.Clusters[name]; exists {
+			return generatedNameCollisionError("cluster", name)
+		}
+	}
+	for name := range generated.AuthInfos {
+		if _, exists := merged.AuthInfos[name]; exists {
+			return generatedNameCollisionError("user", name)
+		}
+	}
+	for name := range generated.Contexts {
+		if _, exists := merged.Contexts[name]; exists {
+			return generatedNameCollisionError("context", name)
+		}
+	}
+
+	for name, cluster := range generated.Clusters {
+		merged.Clusters[name] = cluster
+	}
+	for name, user := range generated.AuthInfos {
+		merged.AuthInfos[name] = user
+	}
+	for name, kubeContext := range generated.Contexts {
+		merged.Contexts[name] = kubeContext
+	}
+	return nil
+}
+
+func generatedNameCollisionError(kind, name string) error {
+	return fmt.Errorf("a %s named %q was already generated for another cluster (set the name of the cluster in --clusters to choose another name)", kind, name)
+}
+
+// bufferedLogger records log messages, so that the messages about each cluster can be printed together after the
+// kubeconfigs of all the clusters were generated concurrently. Each bufferedLogger is only used by one goroutine.
+type bufferedLogger struct {
+	entries []bufferedLogEntry
+}
+
+type bufferedLogEntry struct {
+	msg           string
+	keysAndValues []interface{}
+}
+
+func (l *bufferedLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.entries = append(l.entries, bufferedLogEntry{msg: msg, keysAndValues: keysAndValues})
+}
+
+// replay logs the recorded messages to the given logger, with an additional key and value.
+func (l *bufferedLogger) replay(log plog.MinLogger, key string, value interface{}) {
+	for _, entry := range l.entries {
+		log.Info(entry.msg, append([]interface{}{key, value}, entry.keysAndValues...)...)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// mergeKubeconfig merges the cluster, user, and context of the generated kubeconfig into the existing kubeconfig and
+// makes the generated context the current context. Like kubectl, it writes new entries to the first file of the
+// KUBECONFIG list (or to the --kubeconfig file), and updates existing entries in the file which defines them.
+//
+// An existing entry with the same name is only replaced when it was generated by Pinniped before, so that running the
+// same command again updates the entries without clobbering unrelated entries which happen to have the same name.
+func mergeKubeconfig(out io.Writer, configAccess clientcmd.ConfigAccess, generated clientcmdapi.Config) error {
+	existing, err := configAccess.GetStartingConfig()
+	if err != nil {
+		return fmt.Errorf("could not load kubeconfig to merge into: %w", err)
+	}
+
+	// Normalize the generated kubeconfig in the same way as the loaded kubeconfig, so that their entries can be compared.
+	generatedYAML, err := clientcmd.Write(generated)
+	if err != nil {
+		return err
+	}
+	normalized, err := clientcmd.Load(generatedYAML)
+	if err != nil {
+		return err
+	}
+
+	contextName := normalized.CurrentContext
+	context := normalized.Contexts[contextName]
+	clusterName, userName := context.Cluster, context.AuthInfo
+	cluster, user := normalized.Clusters[clusterName], normalized.AuthInfos[userName]
+
+	// Check for collisions with unrelated entries before changing anything.
+	existingCluster, clusterExists := existing.Clusters[clusterName]
+	existingUser, userExists := existing.AuthInfos[userName]
+	existingContext, contextExists := existing.Contexts[contextName]
+	if userExists && !isPinnipedUser(existingUser) {
+		return mergeCollisionError("user", userName)
+	}
+	if contextExists && !isPinnipedUser(existing.AuthInfos[existingContext.AuthInfo]) {
+		return mergeCollisionError("context", contextName)
+	}
+	if clusterExists && !isPinnipedCluster(existing, clusterName) {
+		return mergeCollisionError("cluster", clusterName)
+	}
+
+	// Existing entries are updated in the file which defines them, and new entries are added to the default file.
+	defaultFilename := configAccess.GetDefaultFilename()
+	var messages []string
+	merge := func(kind, name string, exists bool, existingEntry, newEntry interface{}, location string) bool {
+		if !exists {
+			messages = append(messages, fmt.Sprintf("Added %s %q to %s", kind, name, defaultFilename))
+			return true
+		}
+		if apiequality.Semantic.DeepEqual(existingEntry, newEntry) {
+			messages = append(messages, fmt.Sprintf("The %s %q in %s is already up to date", kind, name, location))
+			return false
+		}
+		messages = append(messages, fmt.Sprintf("Updated %s %q in %s", kind, name, location))
+		return true
+	}
+	if clusterExists {
+		cluster.LocationOfOrigin = existingCluster.LocationOfOrigin
+	}
+	if userExists {
+		user.LocationOfOrigin = existingUser.LocationOfOrigin
+	}
+	if contextExists {
+		context.LocationOfOrigin = existingContext.LocationOfOrigin
+	}
+	clusterChanged := merge("cluster", clusterName, clusterExists, existingCluster, cluster, cluster.LocationOfOrigin)
+	userChanged := merge("user", userName, userExists, existingUser, user, user.LocationOfOrigin)
+	contextChanged := merge("context", contextName, contextExists, existingContext, context, context.LocationOfOrigin)
+	currentContextChanged := existing.CurrentContext != contextName
+	if currentContextChanged {
+		messages = append(messages, fmt.Sprintf("Switched to context %q", contextName))
+	}
+
+	if clusterChanged || userChanged || contextChanged || currentContextChanged {
+		existing.Clusters[clusterName] = cluster
+		existing.AuthInfos[userName] = user
+		existing.Contexts[contextName] = context
+		existing.CurrentContext = contextName
+		if err := clientcmd.ModifyConfig(configAccess, *existing, false); err != nil {
+			return fmt.Errorf("could not write kubeconfig: %w", err)
+		}
+	}
+
+	for _, message := range messages {
+		if _, err := fmt.Fprintln(out, message); err != nil {
+			return fmt.Errorf("could not write output: %w", err)
+		}
+	}
+	return nil
+}
+
+func mergeCollisionError(kind, name string) error {
+	return fmt.Errorf("could not merge into kubeconfig: a %s named %q already exists and was not generated by Pinniped "+
+		"(use --context-name, --user-name, or --generated-name-suffix to choose other names)", kind, name)
+}
+
+// isPinnipedUser returns whether the user runs one of the "pinniped login" commands to get its credentials.
+func isPinnipedUser(user *clientcmdapi.AuthInfo) bool {
+	if user == nil || user.Exec == nil || len(user.Exec.Args) < 2 || user.Exec.Args[0] != "login" {
+		return false
+	}
+	return user.Exec.Args[1] == "oidc" || user.Exec.Args[1] == "static"
+}
+
+// isPinnipedCluster returns whether the cluster is only used by contexts with Pinniped users, which means that it was
+// generated by Pinniped. A cluster which is not used by any context cannot be attributed, so it is not considered to
+// be generated by Pinniped.
+func isPinnipedCluster(config *clientcmdapi.Config, clusterName string) bool {
+	used := false
+	for _, context := range config.Contexts {
+		if context.Cluster != clusterName {
+			continue
+		}
+		if !isPinnipedUser(config.AuthInfos[context.AuthInfo]) {
+			return false
+		}
+		used = true
+	}
+	return used
+}
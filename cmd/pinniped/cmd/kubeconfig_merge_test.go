@@ -0,0 +1,183 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestMergeKubeconfig(t *testing.T) {
+	// The existing kubeconfig is split across two files, like KUBECONFIG=first.yaml:second.yaml.
+	firstKubeconfig := here.Doc(`
+		apiVersion: v1
+		kind: Config
+		clusters:
+		- name: other-cluster
+		  cluster:
+			server: https://other-cluster.example.com
+		contexts:
+		- name: other-context
+		  context:
+			cluster: other-cluster
+			user: other-user
+		current-context: other-context
+		users:
+		- name: other-user
+		  user:
+			token: other-token
+	`)
+	secondKubeconfig := here.Doc(`
+		apiVersion: v1
+		kind: Config
+		clusters:
+		- name: kind-cluster-pinniped
+		  cluster:
+			server: https://old-concierge.example.com
+		contexts:
+		- name: kind-context-pinniped
+		  context:
+			cluster: kind-cluster-pinniped
+			user: kind-user-pinniped
+		users:
+		- name: kind-user-pinniped
+		  user:
+			exec:
+			  apiVersion: client.authentication.k8s.io/v1beta1
+			  command: pinniped
+			  args:
+			  - login
+			  - static
+			  - --token=old-token
+	`)
+
+	generated := func(names kubeconfigNames) clientcmdapi.Config {
+		return newExecKubeconfig(
+			&clientcmdapi.Cluster{Server: "https://concierge.example.com"},
+			&clientcmdapi.ExecConfig{
+				APIVersion:         "client.authentication.k8s.io/v1beta1",
+				Command:            "pinniped",
+				Args:               []string{"login", "static", "--token=test-token"},
+				Env:                []clientcmdapi.ExecEnvVar{},
+				ProvideClusterInfo: true,
+			},
+			&names,
+		)
+	}
+
+	tests := []struct {
+		name             string
+		names            kubeconfigNames
+		wantErr          string
+		wantStdout       string
+		wantFirstChanged bool
+	}{
+		{
+			name:  "adds new entries to the first file",
+			names: kubeconfigNames{ContextName: "new-context", UserName: "new-user", ClusterName: "new-cluster"},
+			wantStdout: here.Doc(`
+				Added cluster "new-cluster" to FIRST
+				Added user "new-user" to FIRST
+				Added context "new-context" to FIRST
+				Switched to context "new-context"
+			`),
+			wantFirstChanged: true,
+		},
+		{
+			name:  "updates entries which were generated by Pinniped in the file which defines them",
+			names: kubeconfigNames{ContextName: "kind-context-pinniped", UserName: "kind-user-pinniped", ClusterName: "kind-cluster-pinniped"},
+			wantStdout: here.Doc(`
+				Updated cluster "kind-cluster-pinniped" in SECOND
+				Updated user "kind-user-pinniped" in SECOND
+				The context "kind-context-pinniped" in SECOND is already up to date
+				Switched to context "kind-context-pinniped"
+			`),
+			wantFirstChanged: true, // the current context is always written to the first file
+		},
+		{
+			name:    "user collision",
+			names:   kubeconfigNames{ContextName: "new-context", UserName: "other-user", ClusterName: "new-cluster"},
+			wantErr: `could not merge into kubeconfig: a user named "other-user" already exists and was not generated by Pinniped (use --context-name, --user-name, or --generated-name-suffix to choose other names)`,
+		},
+		{
+			name:    "context collision",
+			names:   kubeconfigNames{ContextName: "other-context", UserName: "new-user", ClusterName: "new-cluster"},
+			wantErr: `could not merge into kubeconfig: a context named "other-context" already exists and was not generated by Pinniped (use --context-name, --user-name, or --generated-name-suffix to choose other names)`,
+		},
+		{
+			name:    "cluster collision",
+			names:   kubeconfigNames{ContextName: "new-context", UserName: "new-user", ClusterName: "other-cluster"},
+			wantErr: `could not merge into kubeconfig: a cluster named "other-cluster" already exists and was not generated by Pinniped (use --context-name, --user-name, or --generated-name-suffix to choose other names)`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpdir := testutil.TempDir(t)
+			firstPath := filepath.Join(tmpdir, "first.yaml")
+			secondPath := filepath.Join(tmpdir, "second.yaml")
+			require.NoError(t, os.WriteFile(firstPath, []byte(firstKubeconfig), 0600))
+			require.NoError(t, os.WriteFile(secondPath, []byte(secondKubeconfig), 0600))
+			configAccess := &clientcmd.ClientConfigLoadingRules{Precedence: []string{firstPath, secondPath}}
+
+			var stdout bytes.Buffer
+			err := mergeKubeconfig(&stdout, configAccess, generated(tt.names))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Empty(t, stdout.String())
+				requireFileContents(t, firstPath, firstKubeconfig)
+				requireFileContents(t, secondPath, secondKubeconfig)
+				return
+			}
+			require.NoError(t, err)
+			wantStdout := bytes.ReplaceAll([]byte(tt.wantStdout), []byte("FIRST"), []byte(firstPath))
+			wantStdout = bytes.ReplaceAll(wantStdout, []byte("SECOND"), []byte(secondPath))
+			require.Equal(t, string(wantStdout), stdout.String())
+			if !tt.wantFirstChanged {
+				requireFileContents(t, firstPath, firstKubeconfig)
+			}
+
+			// The merged kubeconfig has the generated entries, and the other entries are unchanged.
+			merged, err := configAccess.Load()
+			require.NoError(t, err)
+			require.Equal(t, tt.names.ContextName, merged.CurrentContext)
+			require.Equal(t, tt.names.ClusterName, merged.Contexts[tt.names.ContextName].Cluster)
+			require.Equal(t, tt.names.UserName, merged.Contexts[tt.names.ContextName].AuthInfo)
+			require.Equal(t, "https://concierge.example.com", merged.Clusters[tt.names.ClusterName].Server)
+			require.Equal(t, []string{"login", "static", "--token=test-token"}, merged.AuthInfos[tt.names.UserName].Exec.Args)
+			require.Equal(t, "other-token", merged.AuthInfos["other-user"].Token)
+			require.Equal(t, "https://other-cluster.example.com", merged.Clusters["other-cluster"].Server)
+
+			// Merging the same entries again does not change anything.
+			firstContents, err := os.ReadFile(firstPath)
+			require.NoError(t, err)
+			secondContents, err := os.ReadFile(secondPath)
+			require.NoError(t, err)
+			stdout.Reset()
+			require.NoError(t, mergeKubeconfig(&stdout, configAccess, generated(tt.names)))
+			require.NotContains(t, stdout.String(), "Added")
+			require.NotContains(t, stdout.String(), "Updated")
+			require.NotContains(t, stdout.String(), "Switched")
+			requireFileContents(t, firstPath, string(firstContents))
+			requireFileContents(t, secondPath, string(secondContents))
+		})
+	}
+}
+
+func requireFileContents(t *testing.T, path string, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+}
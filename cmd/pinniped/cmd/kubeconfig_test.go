@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -16,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	kubetesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
 
@@ -40,6 +42,29 @@ func TestGetKubeconfig(t *testing.T) {
 	require.NoError(t, err)
 	testConciergeCABundlePath := filepath.Join(tmpdir, "testconciergeca.pem")
 	require.NoError(t, os.WriteFile(testConciergeCABundlePath, testConciergeCA.Bundle(), 0600))
+	testKubeconfig, err := os.ReadFile("./testdata/kubeconfig.yaml")
+	require.NoError(t, err)
+	mergedKubeconfigPath := filepath.Join(tmpdir, "merged-kubeconfig.yaml")
+	require.NoError(t, os.WriteFile(mergedKubeconfigPath, testKubeconfig, 0600))
+	clustersPath := filepath.Join(tmpdir, "clusters.yaml")
+	require.NoError(t, os.WriteFile(clustersPath, []byte(here.Doc(`
+		clusters:
+		- kubeconfig: ./testdata/kubeconfig.yaml
+		- kubeconfig: ./testdata/kubeconfig.yaml
+		  context: some-other-context
+		  name: other
+	`)), 0600))
+	clustersWithErrorsPath := filepath.Join(tmpdir, "clusters-with-errors.yaml")
+	require.NoError(t, os.WriteFile(clustersWithErrorsPath, []byte(here.Doc(`
+		clusters:
+		- kubeconfig: ./testdata/kubeconfig.yaml
+		  context: invalid-context-no-such-cluster
+		- kubeconfig: ./testdata/kubeconfig.yaml
+		- kubeconfig: ./testdata/kubeconfig.yaml
+		  context: kind-context
+	`)), 0600))
+	invalidClustersPath := filepath.Join(tmpdir, "invalid-clusters.yaml")
+	require.NoError(t, os.WriteFile(invalidClustersPath, []byte("clusters:\n- kubeconfig: a\n  contxt: b\n"), 0600))
 
 	credentialIssuer := func() runtime.Object {
 		return &configv1alpha1.CredentialIssuer{
@@ -111,6 +136,7 @@ func TestGetKubeconfig(t *testing.T) {
 		wantStderr              func(string, string) testutil.RequireErrorStringFunc
 		wantOptionsCount        int
 		wantAPIGroupSuffix      string
+		kubernetesVersion       string
 	}{
 		{
 			name: "help flag passed",
@@ -123,6 +149,7 @@ func TestGetKubeconfig(t *testing.T) {
 				  kubeconfig [flags]
 
 				Flags:
+				      --clusters string                          Path to a file which lists the clusters to generate a single kubeconfig for, or - to read it from stdin
 				      --concierge-api-group-suffix string        Concierge API group suffix (default "pinniped.dev")
 				      --concierge-authenticator-name string      Concierge authenticator name (default: autodiscover)
 				      --concierge-authenticator-type string      Concierge authenticator type (e.g., 'webhook', 'jwt') (default: autodiscover)
@@ -131,20 +158,25 @@ func TestGetKubeconfig(t *testing.T) {
 				      --concierge-endpoint string                API base for the Concierge endpoint
 				      --concierge-mode mode                      Concierge mode of operation (default TokenCredentialRequestAPI)
 				      --concierge-skip-wait                      Skip waiting for any pending Concierge strategies to become ready (default: false)
+				      --context-name string                      Name of the generated context (default: the current context name with the --generated-name-suffix)
 				      --credential-cache string                  Path to cluster-specific credentials cache
+				      --exec-api-version string                  The client.authentication.k8s.io API version of the ExecCredential of the generated kubeconfig (e.g. 'v1beta1', 'v1') (default: autodetect from the Kubernetes version of the cluster)
 				      --generated-name-suffix string             Suffix to append to generated cluster, context, user kubeconfig entries (default "-pinniped")
 				  -h, --help                                     help for kubeconfig
 				      --install-hint string                      This text is shown to the user when the pinniped CLI is not installed. (default "The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli for more details")
 				      --kubeconfig string                        Path to kubeconfig file
 				      --kubeconfig-context string                Kubeconfig context name (default: current active context)
+				      --merge                                    Merge the generated cluster, user, and context into the kubeconfig and switch to the context, instead of printing a new kubeconfig
 				      --no-concierge                             Generate a configuration which does not use the Concierge, but sends the credential to the cluster directly
 				      --oidc-ca-bundle path                      Path to TLS certificate authority bundle (PEM format, optional, can be repeated)
 				      --oidc-client-id string                    OpenID Connect client ID (default: autodiscover) (default "pinniped-cli")
+				      --oidc-grant-type string                   The OAuth2 grant type to use during OpenID Connect login (e.g. 'authorization_code', 'device')
 				      --oidc-issuer string                       OpenID Connect issuer URL (default: autodiscover)
 				      --oidc-listen-port uint16                  TCP port for localhost listener (authorization code flow only)
 				      --oidc-request-audience string             Request a token with an alternate audience using RFC8693 token exchange
 				      --oidc-scopes strings                      OpenID Connect scopes to request during login (default [offline_access,openid,pinniped:request-audience,username,groups])
 				      --oidc-session-cache string                Path to OpenID Connect session cache file
+				      --oidc-show-qr-code                        During OpenID Connect login, also print the login link as a QR code (device grant type only)
 				      --oidc-skip-browser                        During OpenID Connect login, skip opening the browser (just print the URL)
 				  -o, --output string                            Output file path (default: stdout)
 				      --skip-validation                          Skip final validation of the kubeconfig (default: false)
@@ -154,6 +186,7 @@ func TestGetKubeconfig(t *testing.T) {
 				      --upstream-identity-provider-flow string   The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. 'cli_password', 'browser_authcode')
 				      --upstream-identity-provider-name string   The name of the upstream identity provider used during login with a Supervisor
 				      --upstream-identity-provider-type string   The type of the upstream identity provider used during login with a Supervisor (e.g. 'oidc', 'ldap', 'activedirectory')
+				      --user-name string                         Name of the generated user (default: the current user name with the --generated-name-suffix)
 			`)
 			},
 		},
@@ -345,7 +378,7 @@ func TestGetKubeconfig(t *testing.T) {
 			},
 			wantError: true,
 			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
-				return testutil.WantExactErrorString(`Error: invalid authenticator type "invalid", supported values are "webhook" and "jwt"` + "\n")
+				return testutil.WantExactErrorString(`Error: invalid authenticator type "invalid", supported values are "webhook", "jwt", "x509", and "serviceaccounttoken"` + "\n")
 			},
 		},
 		{
@@ -784,6 +817,18 @@ func TestGetKubeconfig(t *testing.T) {
 				return testutil.WantExactErrorString(`Error: invalid API group suffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')` + "\n")
 			},
 		},
+		{
+			name: "invalid exec API version",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--exec-api-version", "v2",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: invalid --exec-api-version "v2", supported values are "v1beta1" and "v1"` + "\n")
+			},
+		},
 		{
 			name: "when OIDC discovery document 400s",
 			args: func(issuerCABundle string, issuerURL string) []string {
@@ -1382,6 +1427,268 @@ func TestGetKubeconfig(t *testing.T) {
 					`"availableFlows"=["cli_password","flow2"] "idpName"="some-ldap-idp" "idpType"="ldap" "selectedFlow"="cli_password"`}
 			},
 		},
+		{
+			name: "merge and output flags",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--static-token", "test-token",
+					"--merge",
+					"--output", filepath.Join(tmpdir, "output.yaml"),
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: only one of --merge and --output can be specified` + "\n")
+			},
+		},
+		{
+			name: "valid static token merged into the kubeconfig",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", mergedKubeconfigPath,
+					"--static-token", "test-token",
+					"--skip-validation",
+					"--merge",
+					"--context-name", "test-context",
+					"--user-name", "test-user",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "name"="test-authenticator"`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Docf(`
+					Added cluster "kind-cluster-pinniped" to %[1]s
+					Added user "test-user" to %[1]s
+					Added context "test-context" to %[1]s
+					Switched to context "test-context"
+				`, mergedKubeconfigPath)
+			},
+		},
+		{
+			name: "clusters and kubeconfig flags",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--clusters", clustersPath,
+					"--static-token", "test-token",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: --kubeconfig cannot be used with --clusters` + "\n")
+			},
+		},
+		{
+			name: "clusters file does not exist",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--clusters", "./does/not/exist",
+					"--static-token", "test-token",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: could not read --clusters: open ./does/not/exist: no such file or directory` + "\n")
+			},
+		},
+		{
+			name: "clusters file is invalid",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--clusters", invalidClustersPath,
+					"--static-token", "test-token",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: could not parse --clusters: error unmarshaling JSON: while decoding JSON: json: unknown field "contxt"` + "\n")
+			},
+		},
+		{
+			name: "valid static token for a list of clusters",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--clusters", clustersPath,
+					"--static-token", "test-token",
+					"--skip-validation",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "cluster"="./testdata/kubeconfig.yaml" "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"  "cluster"="./testdata/kubeconfig.yaml"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "cluster"="./testdata/kubeconfig.yaml" "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "cluster"="./testdata/kubeconfig.yaml" "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "cluster"="./testdata/kubeconfig.yaml" "name"="test-authenticator"`,
+					`"level"=0 "msg"="discovered CredentialIssuer"  "cluster"="./testdata/kubeconfig.yaml (context some-other-context)" "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"  "cluster"="./testdata/kubeconfig.yaml (context some-other-context)"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "cluster"="./testdata/kubeconfig.yaml (context some-other-context)" "endpoint"="https://some-other-fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "cluster"="./testdata/kubeconfig.yaml (context some-other-context)" "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "cluster"="./testdata/kubeconfig.yaml (context some-other-context)" "name"="test-authenticator"`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Doc(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					- cluster:
+						certificate-authority-data: c29tZS1vdGhlci1mYWtlLWNlcnRpZmljYXRlLWF1dGhvcml0eS1kYXRhLXZhbHVl
+						server: https://some-other-fake-server-url-value
+					  name: other
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					- context:
+						cluster: other
+						user: other
+					  name: other
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - static
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=webhook
+						  - --concierge-endpoint=https://fake-server-url-value
+						  - --concierge-ca-bundle-data=ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						  - --token=test-token
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+						    for more details
+						  provideClusterInfo: true
+					- name: other
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - static
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=webhook
+						  - --concierge-endpoint=https://some-other-fake-server-url-value
+						  - --concierge-ca-bundle-data=c29tZS1vdGhlci1mYWtlLWNlcnRpZmljYXRlLWF1dGhvcml0eS1kYXRhLXZhbHVl
+						  - --token=test-token
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+						    for more details
+						  provideClusterInfo: true
+				`)
+			},
+		},
+		{
+			name: "valid static token for a list of clusters with errors for some clusters",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--clusters", clustersWithErrorsPath,
+					"--static-token", "test-token",
+					"--skip-validation",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "cluster"="./testdata/kubeconfig.yaml" "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"  "cluster"="./testdata/kubeconfig.yaml"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "cluster"="./testdata/kubeconfig.yaml" "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "cluster"="./testdata/kubeconfig.yaml" "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "cluster"="./testdata/kubeconfig.yaml" "name"="test-authenticator"`,
+					`"level"=0 "msg"="discovered CredentialIssuer"  "cluster"="./testdata/kubeconfig.yaml (context kind-context)" "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"  "cluster"="./testdata/kubeconfig.yaml (context kind-context)"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "cluster"="./testdata/kubeconfig.yaml (context kind-context)" "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "cluster"="./testdata/kubeconfig.yaml (context kind-context)" "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "cluster"="./testdata/kubeconfig.yaml (context kind-context)" "name"="test-authenticator"`,
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(here.Doc(`
+					Error: could not generate kubeconfig for cluster ./testdata/kubeconfig.yaml (context invalid-context-no-such-cluster): could not load --kubeconfig/--kubeconfig-context: no such cluster "invalid-cluster"
+					Error: could not generate kubeconfig for cluster ./testdata/kubeconfig.yaml (context kind-context): a cluster named "kind-cluster-pinniped" was already generated for another cluster (set the name of the cluster in --clusters to choose another name)
+					Error: could not generate kubeconfig for 2 of the 3 clusters
+				`))
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Doc(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - static
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=webhook
+						  - --concierge-endpoint=https://fake-server-url-value
+						  - --concierge-ca-bundle-data=ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						  - --token=test-token
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+						    for more details
+						  provideClusterInfo: true
+				`)
+			},
+		},
 		{
 			name: "valid static token",
 			args: func(issuerCABundle string, issuerURL string) []string {
@@ -1510,6 +1817,195 @@ func TestGetKubeconfig(t *testing.T) {
 				`)
 			},
 		},
+		{
+			name: "valid static token with the v1 ExecCredential API autodetected from the Kubernetes version",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--static-token", "test-token",
+					"--skip-validation",
+				}
+			},
+			kubernetesVersion: "v1.27.2",
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "name"="test-authenticator"`,
+					`"level"=0 "msg"="discovered Kubernetes version which supports the v1 ExecCredential API"  "version"="v1.27.2"`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Doc(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1
+						  args:
+						  - login
+						  - static
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=webhook
+						  - --concierge-endpoint=https://fake-server-url-value
+						  - --concierge-ca-bundle-data=ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						  - --token=test-token
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+						    for more details
+						  interactiveMode: Never
+						  provideClusterInfo: true
+				`)
+			},
+		},
+		{
+			name: "valid static token with the v1beta1 ExecCredential API autodetected from an old Kubernetes version",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--static-token", "test-token",
+					"--skip-validation",
+				}
+			},
+			kubernetesVersion: "v1.22.17",
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "name"="test-authenticator"`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Doc(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - static
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=webhook
+						  - --concierge-endpoint=https://fake-server-url-value
+						  - --concierge-ca-bundle-data=ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						  - --token=test-token
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+						    for more details
+						  provideClusterInfo: true
+				`)
+			},
+		},
+		{
+			name: "valid OIDC login with the v1 ExecCredential API",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				f := testutil.WriteStringToTempFile(t, "testca-*.pem", issuerCABundle)
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--skip-validation",
+					"--no-concierge",
+					"--oidc-issuer", issuerURL,
+					"--oidc-ca-bundle", f.Name(),
+					"--upstream-identity-provider-name", "some-ldap-idp",
+					"--upstream-identity-provider-type", "ldap",
+					"--upstream-identity-provider-flow", "cli_password",
+					"--exec-api-version", "v1",
+				}
+			},
+			oidcDiscoveryResponse: happyOIDCDiscoveryResponse,
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Docf(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1
+						  args:
+						  - login
+						  - oidc
+						  - --issuer=%s
+						  - --client-id=pinniped-cli
+						  - --scopes=offline_access,openid,pinniped:request-audience,username,groups
+						  - --ca-bundle-data=%s
+						  - --upstream-identity-provider-name=some-ldap-idp
+						  - --upstream-identity-provider-type=ldap
+						  - --upstream-identity-provider-flow=cli_password
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+						    for more details
+						  interactiveMode: IfAvailable
+						  provideClusterInfo: true
+					`,
+					issuerURL,
+					base64.StdEncoding.EncodeToString([]byte(issuerCABundle)))
+			},
+		},
 		{
 			name: "autodetect JWT authenticator",
 			args: func(issuerCABundle string, issuerURL string) []string {
@@ -1604,6 +2100,8 @@ func TestGetKubeconfig(t *testing.T) {
 					"--oidc-session-cache", "/path/to/cache/dir/sessions.yaml",
 					"--oidc-debug-session-cache",
 					"--oidc-request-audience", "test-audience",
+					"--oidc-grant-type", "device",
+					"--oidc-show-qr-code",
 					"--skip-validation",
 					"--generated-name-suffix", "-sso",
 					"--credential-cache", "/path/to/cache/dir/credentials.yaml",
@@ -1658,6 +2156,8 @@ func TestGetKubeconfig(t *testing.T) {
 						  - --session-cache=/path/to/cache/dir/sessions.yaml
 						  - --debug-session-cache
 						  - --request-audience=test-audience
+						  - --grant-type=device
+						  - --show-qr-code
 						  command: '.../path/to/pinniped'
 						  env: []
 						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
@@ -3249,6 +3749,9 @@ func TestGetKubeconfig(t *testing.T) {
 					if tt.conciergeObjects != nil {
 						fake = fakeconciergeclientset.NewSimpleClientset(tt.conciergeObjects(issuerCABundle, issuerEndpoint)...)
 					}
+					if tt.kubernetesVersion != "" {
+						fake.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: tt.kubernetesVersion}
+					}
 					if len(tt.conciergeReactions) > 0 {
 						fake.ReactionChain = append(tt.conciergeReactions, fake.ReactionChain...)
 					}
@@ -0,0 +1,448 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/strings/slices"
+
+	conciergev1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	identityv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/identity/v1alpha1"
+	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/plog"
+)
+
+//nolint:gochecknoglobals
+var kubeconfigCmd = &cobra.Command{
+	Use:          "kubeconfig",
+	Short:        "Validates a Pinniped-based kubeconfig",
+	SilenceUsage: true, // Do not print usage message when commands fail.
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(kubeconfigCmd)
+	kubeconfigCmd.AddCommand(kubeconfigValidateCommand(kubeconfigValidateRealDeps()))
+}
+
+type kubeconfigValidateDeps struct {
+	getClientset     getConciergeClientsetFunc
+	getKubeClientset getKubeClientsetFunc
+	log              plog.MinLogger
+}
+
+func kubeconfigValidateRealDeps() kubeconfigValidateDeps {
+	return kubeconfigValidateDeps{
+		getClientset:     getRealConciergeClientset,
+		getKubeClientset: getRealKubeClientset,
+		log:              plog.New(),
+	}
+}
+
+type kubeconfigValidateFlags struct {
+	contextName               string
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+	login                     bool
+	timeout                   time.Duration
+}
+
+func kubeconfigValidateCommand(deps kubeconfigValidateDeps) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "validate PATH",
+		Short: "Check that a generated kubeconfig works and print a diagnostic report",
+		Long: here.Doc(
+			`Check that a generated kubeconfig works and print a diagnostic report
+
+			Performs the steps of the login flow of a kubeconfig which was generated by "pinniped get kubeconfig":
+			it looks up the CredentialIssuer and the authenticator of the Concierge using the admin kubeconfig,
+			and fetches the OpenID Connect discovery document of the issuer and the identity providers of the
+			Supervisor. With --login, it also logs in using the kubeconfig and makes a SelfSubjectReview to
+			check the resulting identity.`,
+		),
+		SilenceUsage: true, // do not print usage message when commands fail
+	}
+	flags := &kubeconfigValidateFlags{}
+
+	f := cmd.Flags()
+	f.StringVar(&flags.contextName, "context", "", "Context of the generated kubeconfig to validate (default: its current context)")
+	f.StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to the admin kubeconfig file which is used to look up the Concierge configuration")
+	f.StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Admin kubeconfig context name (default: current active context)")
+	f.BoolVar(&flags.login, "login", false, "Also log in using the generated kubeconfig and check the resulting identity")
+	f.DurationVar(&flags.timeout, "timeout", 10*time.Minute, "Timeout for all the checks")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		// When --kubeconfig was not specified, let the client-go loading rules follow the KUBECONFIG list of files,
+		// instead of treating the whole value of KUBECONFIG as a single path.
+		if !cmd.Flags().Changed("kubeconfig") {
+			flags.kubeconfigPath = ""
+		}
+		return runKubeconfigValidate(cmd.Context(), cmd.OutOrStdout(), deps, flags, args[0])
+	}
+	return cmd
+}
+
+// generatedKubeconfig is a context of a generated kubeconfig, with the settings of its "pinniped login" command.
+type generatedKubeconfig struct {
+	config      *clientcmdapi.Config
+	contextName string
+	clusterName string
+	server      string
+
+	loginCommand    string // "oidc" or "static"
+	issuer          string
+	clientID        string
+	requestAudience string
+	caBundlePaths   []string
+	caBundleData    []string
+	upstreamIDPName string
+	upstreamIDPType string
+	upstreamIDPFlow string
+
+	conciergeEnabled           bool
+	conciergeAuthenticatorType string
+	conciergeAuthenticatorName string
+	conciergeEndpoint          string
+	conciergeAPIGroupSuffix    string
+}
+
+// skippedCheckError is returned by a check which does not apply to the kubeconfig.
+type skippedCheckError string
+
+func (e skippedCheckError) Error() string { return string(e) }
+
+type kubeconfigCheckResult struct {
+	name    string
+	result  string
+	details string
+}
+
+type kubeconfigValidateReport struct {
+	results []kubeconfigCheckResult
+	failed  int
+}
+
+func (r *kubeconfigValidateReport) add(name string, details string, err error) {
+	var skipped skippedCheckError
+	switch {
+	case err == nil:
+		r.results = append(r.results, kubeconfigCheckResult{name: name, result: "ok", details: details})
+	case errors.As(err, &skipped):
+		r.results = append(r.results, kubeconfigCheckResult{name: name, result: "skipped", details: skipped.Error()})
+	default:
+		r.failed++
+		r.results = append(r.results, kubeconfigCheckResult{name: name, result: "failed", details: err.Error()})
+	}
+}
+
+func (r *kubeconfigValidateReport) write(output io.Writer) error {
+	w := tabwriter.NewWriter(output, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tDETAILS")
+	for _, result := range r.results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.name, result.result, result.details)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("could not write output: %w", err)
+	}
+	if r.failed > 0 {
+		return fmt.Errorf("%d of the %d checks failed", r.failed, len(r.results))
+	}
+	return nil
+}
+
+func runKubeconfigValidate(ctx context.Context, output io.Writer, deps kubeconfigValidateDeps, flags *kubeconfigValidateFlags, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, flags.timeout)
+	defer cancel()
+
+	report := &kubeconfigValidateReport{}
+
+	generated, err := loadGeneratedKubeconfig(path, flags.contextName)
+	if err != nil {
+		report.add("kubeconfig", "", err)
+		skipped := skippedCheckError("the kubeconfig is invalid")
+		for _, name := range []string{"concierge", "authenticator", "issuer", "login"} {
+			report.add(name, "", skipped)
+		}
+		return report.write(output)
+	}
+	report.add("kubeconfig", fmt.Sprintf(`context %q runs "pinniped login %s" for the cluster %q at %s`,
+		generated.contextName, generated.loginCommand, generated.clusterName, generated.server), nil)
+
+	if generated.conciergeEnabled {
+		adminClientConfig := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
+		clientset, err := deps.getClientset(adminClientConfig, generated.conciergeAPIGroupSuffix)
+		if err != nil {
+			err = fmt.Errorf("could not configure Kubernetes client for the admin kubeconfig: %w", err)
+			report.add("concierge", "", err)
+			report.add("authenticator", "", err)
+		} else {
+			adminServer := ""
+			if restConfig, err := adminClientConfig.ClientConfig(); err == nil {
+				adminServer = restConfig.Host
+			}
+			details, err := checkConcierge(ctx, clientset, adminServer, generated)
+			report.add("concierge", details, err)
+			details, err = checkAuthenticator(clientset, generated, deps.log)
+			report.add("authenticator", details, err)
+		}
+	} else {
+		skipped := skippedCheckError("the kubeconfig does not use the Concierge")
+		report.add("concierge", "", skipped)
+		report.add("authenticator", "", skipped)
+	}
+
+	details, err := checkIssuer(ctx, generated)
+	report.add("issuer", details, err)
+
+	if flags.login {
+		details, err = checkLogin(ctx, deps, generated)
+	} else {
+		details, err = "", skippedCheckError("use --login to log in using the kubeconfig and check the resulting identity")
+	}
+	report.add("login", details, err)
+
+	return report.write(output)
+}
+
+func loadGeneratedKubeconfig(path string, contextName string) (*generatedKubeconfig, error) {
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the kubeconfig: %w", err)
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("the kubeconfig has no current context, so --context must be specified")
+	}
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("no such context %q", contextName)
+	}
+	cluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("no such cluster %q", kubeContext.Cluster)
+	}
+	user, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("no such user %q", kubeContext.AuthInfo)
+	}
+	if !isPinnipedUser(user) {
+		return nil, fmt.Errorf(`the user %q does not run "pinniped login oidc" or "pinniped login static"`, kubeContext.AuthInfo)
+	}
+
+	generated := &generatedKubeconfig{
+		config:       config,
+		contextName:  contextName,
+		clusterName:  kubeContext.Cluster,
+		server:       cluster.Server,
+		loginCommand: user.Exec.Args[1],
+	}
+
+	// Parse the arguments with the flags of the login command itself, so that they are interpreted in the same way.
+	var loginCmd *cobra.Command
+	if generated.loginCommand == "oidc" {
+		loginCmd = oidcLoginCommand(oidcLoginCommandDeps{})
+	} else {
+		loginCmd = staticLoginCommand(staticLoginDeps{})
+	}
+	f := loginCmd.Flags()
+	if err := f.Parse(user.Exec.Args[2:]); err != nil {
+		return nil, fmt.Errorf(`the user %q runs "pinniped login %s" with invalid arguments: %w`, kubeContext.AuthInfo, generated.loginCommand, err)
+	}
+	flagValue := func(name string) string { return f.Lookup(name).Value.String() }
+
+	generated.conciergeEnabled = flagValue("enable-concierge") == "true"
+	generated.conciergeAuthenticatorType = flagValue("concierge-authenticator-type")
+	generated.conciergeAuthenticatorName = flagValue("concierge-authenticator-name")
+	generated.conciergeEndpoint = flagValue("concierge-endpoint")
+	generated.conciergeAPIGroupSuffix = flagValue("concierge-api-group-suffix")
+	if generated.loginCommand == "oidc" {
+		generated.issuer = flagValue("issuer")
+		generated.clientID = flagValue("client-id")
+		generated.requestAudience = flagValue("request-audience")
+		generated.caBundlePaths, _ = f.GetStringSlice("ca-bundle")
+		generated.caBundleData, _ = f.GetStringSlice("ca-bundle-data")
+		generated.upstreamIDPName = flagValue("upstream-identity-provider-name")
+		generated.upstreamIDPType = flagValue("upstream-identity-provider-type")
+		generated.upstreamIDPFlow = flagValue("upstream-identity-provider-flow")
+		if generated.issuer == "" {
+			return nil, fmt.Errorf(`the user %q runs "pinniped login oidc" without --issuer`, kubeContext.AuthInfo)
+		}
+	}
+
+	if generated.conciergeEnabled && generated.server != generated.conciergeEndpoint {
+		return nil, fmt.Errorf("the cluster %q has the server %s, but the Concierge endpoint is %s",
+			generated.clusterName, generated.server, generated.conciergeEndpoint)
+	}
+	return generated, nil
+}
+
+func checkConcierge(ctx context.Context, clientset conciergeclientset.Interface, adminServer string, generated *generatedKubeconfig) (string, error) {
+	credentialIssuers, err := clientset.ConfigV1alpha1().CredentialIssuers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not list CredentialIssuers: %w", err)
+	}
+	if len(credentialIssuers.Items) == 0 {
+		return "", fmt.Errorf("no CredentialIssuers were found")
+	}
+
+	for i := range credentialIssuers.Items {
+		credentialIssuer := &credentialIssuers.Items[i]
+		for _, mode := range []conciergeModeFlag{modeTokenCredentialRequestAPI, modeImpersonationProxy} {
+			frontend, err := getConciergeFrontend(credentialIssuer, mode)
+			if err != nil {
+				continue
+			}
+			var endpoints []string
+			switch mode {
+			case modeTokenCredentialRequestAPI:
+				// The kubeconfig uses the server of the admin kubeconfig, which may differ from the advertised one.
+				endpoints = []string{adminServer, frontend.TokenCredentialRequestAPIInfo.Server}
+			case modeImpersonationProxy:
+				endpoints = []string{frontend.ImpersonationProxyInfo.Endpoint}
+			}
+			if slices.Contains(endpoints, generated.conciergeEndpoint) {
+				return fmt.Sprintf("CredentialIssuer %q serves the Concierge endpoint %s in %s mode",
+					credentialIssuer.Name, generated.conciergeEndpoint, mode.String()), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no successful strategy of a CredentialIssuer serves the Concierge endpoint %s", generated.conciergeEndpoint)
+}
+
+func checkAuthenticator(clientset conciergeclientset.Interface, generated *generatedKubeconfig, log plog.MinLogger) (string, error) {
+	authenticator, err := lookupAuthenticator(clientset, generated.conciergeAuthenticatorType, generated.conciergeAuthenticatorName, log)
+	if err != nil {
+		return "", fmt.Errorf("could not find the authenticator: %w", err)
+	}
+
+	switch auth := authenticator.(type) {
+	case *conciergev1alpha1.JWTAuthenticator:
+		if generated.loginCommand == "oidc" {
+			if !slices.Contains(append([]string{auth.Spec.Issuer}, auth.Spec.IssuerAliases...), generated.issuer) {
+				return "", fmt.Errorf("JWTAuthenticator %q does not accept tokens from the issuer %s", auth.Name, generated.issuer)
+			}
+			// Without an RFC8693 token exchange, the audience of the ID token is the client ID.
+			audience := generated.requestAudience
+			if audience == "" {
+				audience = generated.clientID
+			}
+			if !slices.Contains(append([]string{auth.Spec.Audience}, auth.Spec.AdditionalAudiences...), audience) {
+				return "", fmt.Errorf("JWTAuthenticator %q does not accept tokens with the audience %q", auth.Name, audience)
+			}
+		}
+		return fmt.Sprintf("JWTAuthenticator %q accepts tokens from the issuer %s with the audience %q",
+			auth.Name, auth.Spec.Issuer, auth.Spec.Audience), nil
+	case *conciergev1alpha1.WebhookAuthenticator:
+		return fmt.Sprintf("WebhookAuthenticator %q sends tokens to %s", auth.Name, auth.Spec.Endpoint), nil
+	default:
+		return fmt.Sprintf("found the %s authenticator %q", generated.conciergeAuthenticatorType, authenticator.GetName()), nil
+	}
+}
+
+func checkIssuer(ctx context.Context, generated *generatedKubeconfig) (string, error) {
+	if generated.loginCommand != "oidc" {
+		return "", skippedCheckError("the kubeconfig uses a static token")
+	}
+
+	var httpClient *http.Client
+	var err error
+	if len(generated.caBundlePaths) > 0 || len(generated.caBundleData) > 0 {
+		httpClient, err = makeClient(generated.caBundlePaths, generated.caBundleData, nil)
+	} else {
+		httpClient, err = newDiscoveryHTTPClient(nil)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	discoveredProvider, err := discoverOIDCProvider(ctx, generated.issuer, httpClient)
+	if err != nil {
+		return "", err
+	}
+	pinnipedIDPsEndpoint, err := discoverIDPsDiscoveryEndpointURL(discoveredProvider)
+	if err != nil {
+		return "", err
+	}
+	if pinnipedIDPsEndpoint == "" {
+		return fmt.Sprintf("%s is an OpenID Connect provider", generated.issuer), nil
+	}
+	if generated.upstreamIDPName == "" {
+		return fmt.Sprintf("%s is a Pinniped Supervisor, and the kubeconfig uses its default identity provider", generated.issuer), nil
+	}
+
+	discoveredUpstreamIDPs, err := discoverAllAvailableSupervisorUpstreamIDPs(ctx, pinnipedIDPsEndpoint, httpClient)
+	if err != nil {
+		return "", err
+	}
+	_, _, discoveredIDPFlows, err := selectUpstreamIDPNameAndType(discoveredUpstreamIDPs, generated.upstreamIDPName, generated.upstreamIDPType)
+	if err != nil {
+		return "", err
+	}
+	if generated.upstreamIDPFlow != "" && len(discoveredIDPFlows) > 0 {
+		flows := make([]string, 0, len(discoveredIDPFlows))
+		for _, flow := range discoveredIDPFlows {
+			flows = append(flows, flow.String())
+		}
+		if !slices.Contains(flows, generated.upstreamIDPFlow) {
+			return "", fmt.Errorf("the %s identity provider %q does not support the %s flow (supported flows: %s)",
+				generated.upstreamIDPType, generated.upstreamIDPName, generated.upstreamIDPFlow, strings.Join(flows, ", "))
+		}
+	}
+	return fmt.Sprintf("%s is a Pinniped Supervisor with the %s identity provider %q",
+		generated.issuer, generated.upstreamIDPType, generated.upstreamIDPName), nil
+}
+
+func checkLogin(ctx context.Context, deps kubeconfigValidateDeps, generated *generatedKubeconfig) (string, error) {
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(*generated.config, generated.contextName, &clientcmd.ConfigOverrides{}, nil)
+
+	kubeClient, err := deps.getKubeClientset(clientConfig)
+	if err != nil {
+		return "", fmt.Errorf("could not configure Kubernetes client: %w", err)
+	}
+	review, err := kubeClient.AuthenticationV1beta1().SelfSubjectReviews().Create(ctx, &authenticationv1beta1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		return describeLoggedInUser(review.Status.UserInfo.Username, review.Status.UserInfo.Groups), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("could not complete SelfSubjectReview: %w", err)
+	}
+
+	// Clusters before Kubernetes 1.27 do not serve SelfSubjectReviews, but the Concierge serves WhoAmIRequests.
+	clientset, err := deps.getClientset(clientConfig, generated.conciergeAPIGroupSuffix)
+	if err != nil {
+		return "", fmt.Errorf("could not configure Kubernetes client: %w", err)
+	}
+	whoAmI, err := clientset.IdentityV1alpha1().WhoAmIRequests().Create(ctx, &identityv1alpha1.WhoAmIRequest{}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not complete SelfSubjectReview or WhoAmIRequest: %w", err)
+	}
+	return describeLoggedInUser(whoAmI.Status.KubernetesUserInfo.User.Username, whoAmI.Status.KubernetesUserInfo.User.Groups) +
+		" (using a WhoAmIRequest, because the cluster does not serve SelfSubjectReviews)", nil
+}
+
+func describeLoggedInUser(username string, groups []string) string {
+	if len(groups) == 0 {
+		return fmt.Sprintf("logged in as the user %q without groups", username)
+	}
+	return fmt.Sprintf("logged in as the user %q with the groups %s", username, strings.Join(groups, ", "))
+}
@@ -0,0 +1,496 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+
+	conciergev1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	identityv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/identity/v1alpha1"
+	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	fakeconciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/testlogger"
+)
+
+func TestKubeconfigValidate(t *testing.T) {
+	// generatedKubeconfig returns a kubeconfig like the ones generated by "pinniped get kubeconfig".
+	generatedKubeconfig := func(server string, args ...string) string {
+		var b strings.Builder
+		b.WriteString(here.Docf(`
+			apiVersion: v1
+			kind: Config
+			clusters:
+			- name: kind-cluster-pinniped
+			  cluster:
+			    server: %s
+			contexts:
+			- name: kind-context-pinniped
+			  context:
+			    cluster: kind-cluster-pinniped
+			    user: kind-user-pinniped
+			current-context: kind-context-pinniped
+			users:
+			- name: kind-user-pinniped
+			  user:
+			    exec:
+			      apiVersion: client.authentication.k8s.io/v1beta1
+			      command: pinniped
+			      args:
+		`, server))
+		for _, arg := range args {
+			b.WriteString("      - " + arg + "\n")
+		}
+		return b.String()
+	}
+	staticKubeconfig := func(args ...string) string {
+		return generatedKubeconfig("https://fake-server-url-value", append([]string{
+			"login",
+			"static",
+			"--enable-concierge",
+			"--concierge-api-group-suffix=pinniped.dev",
+			"--concierge-authenticator-name=test-authenticator",
+			"--concierge-authenticator-type=webhook",
+			"--concierge-endpoint=https://fake-server-url-value",
+			"--token=test-token",
+		}, args...)...)
+	}
+	oidcKubeconfig := func(issuerCABundle string, issuerURL string, args ...string) string {
+		return generatedKubeconfig("https://fake-server-url-value", append([]string{
+			"login",
+			"oidc",
+			"--enable-concierge",
+			"--concierge-api-group-suffix=pinniped.dev",
+			"--concierge-authenticator-name=test-authenticator",
+			"--concierge-authenticator-type=jwt",
+			"--concierge-endpoint=https://fake-server-url-value",
+			"--issuer=" + issuerURL,
+			"--client-id=pinniped-cli",
+			"--ca-bundle-data=" + base64.StdEncoding.EncodeToString([]byte(issuerCABundle)),
+		}, args...)...)
+	}
+
+	credentialIssuer := &configv1alpha1.CredentialIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-credential-issuer"},
+		Status: configv1alpha1.CredentialIssuerStatus{
+			Strategies: []configv1alpha1.CredentialIssuerStrategy{{
+				Type:   configv1alpha1.KubeClusterSigningCertificateStrategyType,
+				Status: configv1alpha1.SuccessStrategyStatus,
+				Reason: configv1alpha1.FetchedKeyStrategyReason,
+				Frontend: &configv1alpha1.CredentialIssuerFrontend{
+					Type: configv1alpha1.TokenCredentialRequestAPIFrontendType,
+					TokenCredentialRequestAPIInfo: &configv1alpha1.TokenCredentialRequestAPIInfo{
+						Server: "https://concierge-endpoint.example.com",
+					},
+				},
+			}},
+		},
+	}
+	webhookAuthenticator := &conciergev1alpha1.WebhookAuthenticator{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"},
+		Spec:       conciergev1alpha1.WebhookAuthenticatorSpec{Endpoint: "https://example.com/webhook"},
+	}
+	jwtAuthenticator := func(issuerURL string) runtime.Object {
+		return &conciergev1alpha1.JWTAuthenticator{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"},
+			Spec:       conciergev1alpha1.JWTAuthenticatorSpec{Issuer: issuerURL, Audience: "test-audience"},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		kubeconfig            func(issuerCABundle string, issuerURL string) string
+		args                  []string
+		conciergeObjects      func(issuerURL string) []runtime.Object
+		getClientsetErr       error
+		idpsDiscoveryResponse string
+		selfSubjectReviewErr  error
+		wantStdout            func(issuerURL string) string
+		wantStderr            string
+	}{
+		{
+			name: "help flag passed",
+			args: []string{"--help"},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				Check that a generated kubeconfig works and print a diagnostic report
+
+				Performs the steps of the login flow of a kubeconfig which was generated by "pinniped get kubeconfig":
+				it looks up the CredentialIssuer and the authenticator of the Concierge using the admin kubeconfig,
+				and fetches the OpenID Connect discovery document of the issuer and the identity providers of the
+				Supervisor. With --login, it also logs in using the kubeconfig and makes a SelfSubjectReview to
+				check the resulting identity.
+
+				Usage:
+				  validate PATH [flags]
+
+				Flags:
+				      --context string              Context of the generated kubeconfig to validate (default: its current context)
+				  -h, --help                        help for validate
+				      --kubeconfig string           Path to the admin kubeconfig file which is used to look up the Concierge configuration
+				      --kubeconfig-context string   Admin kubeconfig context name (default: current active context)
+				      --login                       Also log in using the generated kubeconfig and check the resulting identity
+				      --timeout duration            Timeout for all the checks (default 10m0s)
+				`)
+			},
+		},
+		{
+			name:       "missing path",
+			args:       []string{},
+			wantStderr: "Error: accepts 1 arg(s), received 0\n",
+		},
+		{
+			name: "kubeconfig does not exist",
+			args: []string{"./does/not/exist.yaml"},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      failed    could not load the kubeconfig: open ./does/not/exist.yaml: no such file or directory
+				concierge       skipped   the kubeconfig is invalid
+				authenticator   skipped   the kubeconfig is invalid
+				issuer          skipped   the kubeconfig is invalid
+				login           skipped   the kubeconfig is invalid
+				`)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+		{
+			name: "kubeconfig was not generated by Pinniped",
+			args: []string{"./testdata/kubeconfig.yaml"},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      failed    the user "kind-user" does not run "pinniped login oidc" or "pinniped login static"
+				concierge       skipped   the kubeconfig is invalid
+				authenticator   skipped   the kubeconfig is invalid
+				issuer          skipped   the kubeconfig is invalid
+				login           skipped   the kubeconfig is invalid
+				`)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+		{
+			name: "kubeconfig has invalid login arguments",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig("--not-a-flag")
+			},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      failed    the user "kind-user-pinniped" runs "pinniped login static" with invalid arguments: unknown flag: --not-a-flag
+				concierge       skipped   the kubeconfig is invalid
+				authenticator   skipped   the kubeconfig is invalid
+				issuer          skipped   the kubeconfig is invalid
+				login           skipped   the kubeconfig is invalid
+				`)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+		{
+			name: "kubeconfig server does not match the Concierge endpoint",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig("--concierge-endpoint=https://other-endpoint.example.com")
+			},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      failed    the cluster "kind-cluster-pinniped" has the server https://fake-server-url-value, but the Concierge endpoint is https://other-endpoint.example.com
+				concierge       skipped   the kubeconfig is invalid
+				authenticator   skipped   the kubeconfig is invalid
+				issuer          skipped   the kubeconfig is invalid
+				login           skipped   the kubeconfig is invalid
+				`)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+		{
+			name: "static token",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig()
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml"},
+			conciergeObjects: func(issuerURL string) []runtime.Object {
+				return []runtime.Object{credentialIssuer, webhookAuthenticator}
+			},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login static" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       ok        CredentialIssuer "test-credential-issuer" serves the Concierge endpoint https://fake-server-url-value in TokenCredentialRequestAPI mode
+				authenticator   ok        WebhookAuthenticator "test-authenticator" sends tokens to https://example.com/webhook
+				issuer          skipped   the kubeconfig uses a static token
+				login           skipped   use --login to log in using the kubeconfig and check the resulting identity
+				`)
+			},
+		},
+		{
+			name: "error getting the admin clientset",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig()
+			},
+			args:            []string{"--kubeconfig", "./testdata/kubeconfig.yaml"},
+			getClientsetErr: constable.Error("some kube error"),
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login static" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       failed    could not configure Kubernetes client for the admin kubeconfig: some kube error
+				authenticator   failed    could not configure Kubernetes client for the admin kubeconfig: some kube error
+				issuer          skipped   the kubeconfig uses a static token
+				login           skipped   use --login to log in using the kubeconfig and check the resulting identity
+				`)
+			},
+			wantStderr: "Error: 2 of the 5 checks failed\n",
+		},
+		{
+			name: "Concierge is not installed",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig()
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml"},
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login static" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       failed    no CredentialIssuers were found
+				authenticator   failed    could not find the authenticator: webhookauthenticators.authentication.concierge.pinniped.dev "test-authenticator" not found
+				issuer          skipped   the kubeconfig uses a static token
+				login           skipped   use --login to log in using the kubeconfig and check the resulting identity
+				`)
+			},
+			wantStderr: "Error: 2 of the 5 checks failed\n",
+		},
+		{
+			name: "OIDC login with a Supervisor and a test login",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return oidcKubeconfig(issuerCABundle, issuerURL,
+					"--request-audience=test-audience",
+					"--upstream-identity-provider-name=some-ldap-idp",
+					"--upstream-identity-provider-type=ldap",
+					"--upstream-identity-provider-flow=cli_password",
+				)
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml", "--login"},
+			conciergeObjects: func(issuerURL string) []runtime.Object {
+				return []runtime.Object{credentialIssuer, jwtAuthenticator(issuerURL)}
+			},
+			idpsDiscoveryResponse: `{"pinniped_identity_providers": [{"name": "some-ldap-idp", "type": "ldap", "flows": ["cli_password"]}]}`,
+			wantStdout: func(issuerURL string) string {
+				return here.Docf(`
+				CHECK           RESULT   DETAILS
+				kubeconfig      ok       context "kind-context-pinniped" runs "pinniped login oidc" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       ok       CredentialIssuer "test-credential-issuer" serves the Concierge endpoint https://fake-server-url-value in TokenCredentialRequestAPI mode
+				authenticator   ok       JWTAuthenticator "test-authenticator" accepts tokens from the issuer %[1]s with the audience "test-audience"
+				issuer          ok       %[1]s is a Pinniped Supervisor with the ldap identity provider "some-ldap-idp"
+				login           ok       logged in as the user "test-user" with the groups group-a, group-b
+				`, issuerURL)
+			},
+		},
+		{
+			name: "OIDC login with the wrong audience",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return oidcKubeconfig(issuerCABundle, issuerURL)
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml"},
+			conciergeObjects: func(issuerURL string) []runtime.Object {
+				return []runtime.Object{credentialIssuer, jwtAuthenticator(issuerURL)}
+			},
+			wantStdout: func(issuerURL string) string {
+				return here.Docf(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login oidc" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       ok        CredentialIssuer "test-credential-issuer" serves the Concierge endpoint https://fake-server-url-value in TokenCredentialRequestAPI mode
+				authenticator   failed    JWTAuthenticator "test-authenticator" does not accept tokens with the audience "pinniped-cli"
+				issuer          ok        %[1]s is a Pinniped Supervisor, and the kubeconfig uses its default identity provider
+				login           skipped   use --login to log in using the kubeconfig and check the resulting identity
+				`, issuerURL)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+		{
+			name: "OIDC login with a flow which is not supported by the identity provider",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return oidcKubeconfig(issuerCABundle, issuerURL,
+					"--request-audience=test-audience",
+					"--upstream-identity-provider-name=some-ldap-idp",
+					"--upstream-identity-provider-type=ldap",
+					"--upstream-identity-provider-flow=browser_authcode",
+				)
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml"},
+			conciergeObjects: func(issuerURL string) []runtime.Object {
+				return []runtime.Object{credentialIssuer, jwtAuthenticator(issuerURL)}
+			},
+			idpsDiscoveryResponse: `{"pinniped_identity_providers": [{"name": "some-ldap-idp", "type": "ldap", "flows": ["cli_password"]}]}`,
+			wantStdout: func(issuerURL string) string {
+				return here.Docf(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login oidc" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       ok        CredentialIssuer "test-credential-issuer" serves the Concierge endpoint https://fake-server-url-value in TokenCredentialRequestAPI mode
+				authenticator   ok        JWTAuthenticator "test-authenticator" accepts tokens from the issuer %[1]s with the audience "test-audience"
+				issuer          failed    the ldap identity provider "some-ldap-idp" does not support the browser_authcode flow (supported flows: cli_password)
+				login           skipped   use --login to log in using the kubeconfig and check the resulting identity
+				`, issuerURL)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+		{
+			name: "test login on a cluster which does not serve SelfSubjectReviews",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig()
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml", "--login"},
+			conciergeObjects: func(issuerURL string) []runtime.Object {
+				return []runtime.Object{credentialIssuer, webhookAuthenticator}
+			},
+			selfSubjectReviewErr: apierrors.NewNotFound(schema.GroupResource{Group: "authentication.k8s.io", Resource: "selfsubjectreviews"}, ""),
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login static" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       ok        CredentialIssuer "test-credential-issuer" serves the Concierge endpoint https://fake-server-url-value in TokenCredentialRequestAPI mode
+				authenticator   ok        WebhookAuthenticator "test-authenticator" sends tokens to https://example.com/webhook
+				issuer          skipped   the kubeconfig uses a static token
+				login           ok        logged in as the user "test-user" without groups (using a WhoAmIRequest, because the cluster does not serve SelfSubjectReviews)
+				`)
+			},
+		},
+		{
+			name: "test login fails",
+			kubeconfig: func(issuerCABundle string, issuerURL string) string {
+				return staticKubeconfig()
+			},
+			args: []string{"--kubeconfig", "./testdata/kubeconfig.yaml", "--login"},
+			conciergeObjects: func(issuerURL string) []runtime.Object {
+				return []runtime.Object{credentialIssuer, webhookAuthenticator}
+			},
+			selfSubjectReviewErr: constable.Error("some login error"),
+			wantStdout: func(issuerURL string) string {
+				return here.Doc(`
+				CHECK           RESULT    DETAILS
+				kubeconfig      ok        context "kind-context-pinniped" runs "pinniped login static" for the cluster "kind-cluster-pinniped" at https://fake-server-url-value
+				concierge       ok        CredentialIssuer "test-credential-issuer" serves the Concierge endpoint https://fake-server-url-value in TokenCredentialRequestAPI mode
+				authenticator   ok        WebhookAuthenticator "test-authenticator" sends tokens to https://example.com/webhook
+				issuer          skipped   the kubeconfig uses a static token
+				login           failed    could not complete SelfSubjectReview: some login error
+				`)
+			},
+			wantStderr: "Error: 1 of the 5 checks failed\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var issuerEndpointPtr *string
+			issuerCABundle, issuerEndpoint := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-type", "application/json")
+				switch r.URL.Path {
+				case "/.well-known/openid-configuration":
+					_, err := fmt.Fprintf(w, `{"issuer": %q, "discovery.supervisor.pinniped.dev/v1alpha1": {"pinniped_identity_providers_endpoint": "%s/v1alpha1/pinniped_identity_providers"}}`,
+						*issuerEndpointPtr, *issuerEndpointPtr)
+					require.NoError(t, err)
+				case "/v1alpha1/pinniped_identity_providers":
+					_, err := w.Write([]byte(tt.idpsDiscoveryResponse))
+					require.NoError(t, err)
+				default:
+					t.Fatalf("tried to call issuer at a path that wasn't one of the expected discovery endpoints.")
+				}
+			})
+			issuerEndpointPtr = &issuerEndpoint
+
+			args := tt.args
+			if tt.kubeconfig != nil {
+				path := filepath.Join(testutil.TempDir(t), "kubeconfig.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(tt.kubeconfig(issuerCABundle, issuerEndpoint)), 0600))
+				args = append([]string{path}, args...)
+			}
+
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // old test with lots of log statements
+			cmd := kubeconfigValidateCommand(kubeconfigValidateDeps{
+				getClientset: func(clientConfig clientcmd.ClientConfig, apiGroupSuffix string) (conciergeclientset.Interface, error) {
+					require.Equal(t, "pinniped.dev", apiGroupSuffix)
+					if tt.getClientsetErr != nil {
+						return nil, tt.getClientsetErr
+					}
+					var objects []runtime.Object
+					if tt.conciergeObjects != nil {
+						objects = tt.conciergeObjects(issuerEndpoint)
+					}
+					clientset := fakeconciergeclientset.NewSimpleClientset(objects...)
+					clientset.PrependReactor("create", "whoamirequests", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+						return true, &identityv1alpha1.WhoAmIRequest{
+							Status: identityv1alpha1.WhoAmIRequestStatus{
+								KubernetesUserInfo: identityv1alpha1.KubernetesUserInfo{
+									User: identityv1alpha1.UserInfo{Username: "test-user"},
+								},
+							},
+						}, nil
+					})
+					return clientset, nil
+				},
+				getKubeClientset: func(clientConfig clientcmd.ClientConfig) (kubernetes.Interface, error) {
+					rawConfig, err := clientConfig.RawConfig()
+					require.NoError(t, err)
+					require.Equal(t, "kind-context-pinniped", rawConfig.CurrentContext)
+					clientset := kubefake.NewSimpleClientset()
+					clientset.PrependReactor("create", "selfsubjectreviews", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+						if tt.selfSubjectReviewErr != nil {
+							return true, nil, tt.selfSubjectReviewErr
+						}
+						return true, &authenticationv1beta1.SelfSubjectReview{
+							Status: authenticationv1beta1.SelfSubjectReviewStatus{
+								UserInfo: authenticationv1.UserInfo{Username: "test-user", Groups: []string{"group-a", "group-b"}},
+							},
+						}, nil
+					})
+					return clientset, nil
+				},
+				log: testLog.Logger,
+			})
+			require.NotNil(t, cmd)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(args)
+
+			err := cmd.Execute()
+			if tt.wantStderr != "" {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			testLog.Expect(nil)
+
+			wantStdout := ""
+			if tt.wantStdout != nil {
+				wantStdout = tt.wantStdout(issuerEndpoint)
+			}
+			require.Equal(t, wantStdout, stdout.String(), "unexpected stdout")
+			require.Equal(t, tt.wantStderr, stderr.String(), "unexpected stderr")
+		})
+	}
+}
@@ -1,14 +1,31 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
 	"github.com/spf13/cobra"
+	"golang.org/x/net/http/httpproxy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
-	"k8s.io/client-go/tools/auth/exec"
 
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/net/phttp"
+)
+
+const (
+	// The user may use these environment variables to override the --proxy-url and --no-proxy flags of the login
+	// commands, without editing their kubeconfig file.
+	proxyURLEnvVarName = "PINNIPED_PROXY_URL"
+	noProxyEnvVarName  = "PINNIPED_NO_PROXY"
 )
 
 //nolint:gochecknoglobals
@@ -35,14 +52,141 @@ func init() {
 	rootCmd.AddCommand(loginCmd)
 }
 
-func loadClusterInfo() *clientauthv1beta1.Cluster {
-	obj, _, err := exec.LoadExecCredentialFromEnv()
-	if err != nil {
-		return nil
+// execInfo is the ExecCredential which client-go passes to a credential plugin in the KUBERNETES_EXEC_INFO
+// environment variable.
+type execInfo struct {
+	// apiVersion is the ExecCredential API version which was requested by client-go, or empty when the login
+	// command was not run by client-go.
+	apiVersion string
+
+	// cluster is the cluster info, which is only passed when the kubeconfig sets provideClusterInfo.
+	cluster *clientauthv1beta1.Cluster
+}
+
+func loadExecInfo(lookupEnv func(string) (string, bool)) execInfo {
+	env, _ := lookupEnv("KUBERNETES_EXEC_INFO")
+	if env == "" {
+		return execInfo{}
+	}
+	// Decode only the TypeMeta first. This does not use exec.LoadExecCredential, which returns an error when the
+	// spec.cluster field is missing, as it is when the kubeconfig does not set provideClusterInfo.
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal([]byte(env), &typeMeta); err != nil {
+		return execInfo{}
 	}
-	cred, ok := obj.(*clientauthv1beta1.ExecCredential)
-	if !ok {
-		return nil
+	switch typeMeta.APIVersion {
+	case clientauthv1beta1.SchemeGroupVersion.String():
+		var cred clientauthv1beta1.ExecCredential
+		if err := json.Unmarshal([]byte(env), &cred); err != nil {
+			return execInfo{}
+		}
+		return execInfo{apiVersion: typeMeta.APIVersion, cluster: cred.Spec.Cluster}
+	case clientauthv1.SchemeGroupVersion.String():
+		var cred clientauthv1.ExecCredential
+		if err := json.Unmarshal([]byte(env), &cred); err != nil {
+			return execInfo{}
+		}
+		// The cluster info is kept in the v1beta1 format, which has the same fields, so that it is hashed in the
+		// same way into the keys of the credential cache.
+		var cluster *clientauthv1beta1.Cluster
+		if c := cred.Spec.Cluster; c != nil {
+			cluster = &clientauthv1beta1.Cluster{
+				Server:                   c.Server,
+				TLSServerName:            c.TLSServerName,
+				InsecureSkipTLSVerify:    c.InsecureSkipTLSVerify,
+				CertificateAuthorityData: c.CertificateAuthorityData,
+				ProxyURL:                 c.ProxyURL,
+				DisableCompression:       c.DisableCompression,
+				Config:                   c.Config,
+			}
+		}
+		return execInfo{apiVersion: typeMeta.APIVersion, cluster: cluster}
+	default:
+		return execInfo{}
+	}
+}
+
+// execCredentialAPIVersion returns the ExecCredential API version which is printed by a login command: the version
+// of the --exec-api-version flag, or else the version which was requested by client-go, or else v1beta1.
+func execCredentialAPIVersion(flag string, info execInfo) (string, error) {
+	switch flag {
+	case "":
+		if info.apiVersion != "" {
+			return info.apiVersion, nil
+		}
+		return clientauthv1beta1.SchemeGroupVersion.String(), nil
+	case "v1beta1", "v1":
+		return clientauthv1.SchemeGroupVersion.Group + "/" + flag, nil
+	default:
+		return "", fmt.Errorf(`invalid --exec-api-version %q, supported values are "v1beta1" and "v1"`, flag)
+	}
+}
+
+// writeExecCredential prints the credential in the given ExecCredential API version. The credentials are always
+// cached in the v1beta1 format, which has the same status fields as v1.
+func writeExecCredential(out io.Writer, cred *clientauthv1beta1.ExecCredential, apiVersion string) error {
+	if apiVersion != clientauthv1.SchemeGroupVersion.String() {
+		return json.NewEncoder(out).Encode(cred)
+	}
+	v1Cred := &clientauthv1.ExecCredential{TypeMeta: cred.TypeMeta}
+	v1Cred.APIVersion = apiVersion
+	if cred.Status != nil {
+		v1Cred.Status = &clientauthv1.ExecCredentialStatus{
+			ExpirationTimestamp:   cred.Status.ExpirationTimestamp,
+			Token:                 cred.Status.Token,
+			ClientCertificateData: cred.Status.ClientCertificateData,
+			ClientKeyData:         cred.Status.ClientKeyData,
+		}
+	}
+	return json.NewEncoder(out).Encode(v1Cred)
+}
+
+// loginProxy returns the proxy function for the HTTP requests of the login commands to the issuer and to the
+// Concierge, or nil to use the proxy configured by the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables,
+// like kubectl does. The PINNIPED_PROXY_URL and PINNIPED_NO_PROXY environment variables override the flags. With a
+// proxy URL, the standard environment variables are ignored. Without one, the hosts of noProxy are excluded from
+// the proxy of the standard environment variables, in addition to the hosts of NO_PROXY.
+func loginProxy(proxyURL string, noProxy []string, lookupEnv func(string) (string, bool)) (func(*http.Request) (*url.URL, error), error) {
+	proxyURLSource := "--proxy-url"
+	if value, ok := lookupEnv(proxyURLEnvVarName); ok {
+		proxyURL = value
+		proxyURLSource = proxyURLEnvVarName
+	}
+	if value, ok := lookupEnv(noProxyEnvVarName); ok {
+		noProxy = nil
+		if value != "" {
+			noProxy = strings.Split(value, ",")
+		}
+	}
+
+	if proxyURL != "" {
+		proxy, err := phttp.ProxyFunc(proxyURL, noProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", proxyURLSource, err)
+		}
+		return proxy, nil
+	}
+
+	if len(noProxy) == 0 {
+		return nil, nil
+	}
+	proxyForURL := (&httpproxy.Config{
+		HTTPProxy:  firstEnv(lookupEnv, "HTTP_PROXY", "http_proxy"),
+		HTTPSProxy: firstEnv(lookupEnv, "HTTPS_PROXY", "https_proxy"),
+		NoProxy:    strings.Join(append([]string{firstEnv(lookupEnv, "NO_PROXY", "no_proxy")}, noProxy...), ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}, nil
+}
+
+// firstEnv returns the value of the first of the environment variables which is set, like the standard library does
+// for the upper and lower case names of the proxy environment variables.
+func firstEnv(lookupEnv func(string) (string, bool), names ...string) string {
+	for _, name := range names {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			return value
+		}
 	}
-	return cred.Spec.Cluster
+	return ""
 }
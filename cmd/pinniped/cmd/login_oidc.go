@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -7,9 +7,9 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,14 +21,15 @@ import (
 
 	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
-	"go.pinniped.dev/internal/execcredcache"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/keychain"
 	"go.pinniped.dev/internal/net/phttp"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/pkg/conciergeclient"
 	"go.pinniped.dev/pkg/oidcclient"
 	"go.pinniped.dev/pkg/oidcclient/filesession"
+	"go.pinniped.dev/pkg/oidcclient/keychainsession"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
 )
 
@@ -39,6 +40,10 @@ const (
 	// which specifies "cli_password" when using an IDE plugin where there is no interactive CLI available. This allows
 	// the user to use one kubeconfig file for both flows.
 	upstreamIdentityProviderFlowEnvVarName = "PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW"
+
+	// The supported values of the --grant-type flag.
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeDevice            = "device"
 )
 
 //nolint:gochecknoinits
@@ -50,12 +55,14 @@ type oidcLoginCommandDeps struct {
 	lookupEnv     func(string) (string, bool)
 	login         func(string, string, ...oidcclient.Option) (*oidctypes.Token, error)
 	exchangeToken func(context.Context, *conciergeclient.Client, string) (*clientauthv1beta1.ExecCredential, error)
+	openKeychain  func() (keychain.Keychain, error)
 }
 
 func oidcLoginCommandRealDeps() oidcLoginCommandDeps {
 	return oidcLoginCommandDeps{
-		lookupEnv: os.LookupEnv,
-		login:     oidcclient.Login,
+		lookupEnv:    os.LookupEnv,
+		login:        oidcclient.Login,
+		openKeychain: keychain.New,
 		exchangeToken: func(ctx context.Context, client *conciergeclient.Client, token string) (*clientauthv1beta1.ExecCredential, error) {
 			return client.ExchangeToken(ctx, token)
 		},
@@ -69,6 +76,9 @@ type oidcLoginFlags struct {
 	scopes                       []string
 	skipBrowser                  bool
 	skipListen                   bool
+	browserCommand               string
+	browserCommandArgs           []string
+	remoteBrowser                bool
 	sessionCachePath             string
 	caBundlePaths                []string
 	caBundleData                 []string
@@ -84,6 +94,16 @@ type oidcLoginFlags struct {
 	upstreamIdentityProviderName string
 	upstreamIdentityProviderType string
 	upstreamIdentityProviderFlow string
+	grantType                    string
+	showQRCode                   bool
+	cacheBackend                 string
+	usernameFile                 string
+	passwordFile                 string
+	credentialsCommand           string
+	credentialsCommandArgs       []string
+	execAPIVersion               string
+	proxyURL                     string
+	noProxy                      []string
 }
 
 func oidcLoginCommand(deps oidcLoginCommandDeps) *cobra.Command {
@@ -114,6 +134,9 @@ func oidcLoginCommand(deps oidcLoginCommandDeps) *cobra.Command {
 	cmd.Flags().StringSliceVar(&flags.scopes, "scopes", []string{oidcapi.ScopeOfflineAccess, oidcapi.ScopeOpenID, oidcapi.ScopeRequestAudience, oidcapi.ScopeUsername, oidcapi.ScopeGroups}, "OIDC scopes to request during login")
 	cmd.Flags().BoolVar(&flags.skipBrowser, "skip-browser", false, "Skip opening the browser (just print the URL)")
 	cmd.Flags().BoolVar(&flags.skipListen, "skip-listen", false, "Skip starting a localhost callback listener (manual copy/paste flow only)")
+	cmd.Flags().StringVar(&flags.browserCommand, "browser-command", "", "Command which opens the login link in a web browser, with the link as its last argument (browser_authcode flow only)")
+	cmd.Flags().StringArrayVar(&flags.browserCommandArgs, "browser-command-arg", nil, "Argument of the browser command (can be repeated)")
+	cmd.Flags().BoolVar(&flags.remoteBrowser, "remote-browser", false, "Log in using a web browser on another machine, by pasting the authorization code instead of using a localhost callback listener (browser_authcode flow only)")
 	cmd.Flags().StringVar(&flags.sessionCachePath, "session-cache", filepath.Join(mustGetConfigDir(), "sessions.yaml"), "Path to session cache file")
 	cmd.Flags().StringSliceVar(&flags.caBundlePaths, "ca-bundle", nil, "Path to TLS certificate authority bundle (PEM format, optional, can be repeated)")
 	cmd.Flags().StringSliceVar(&flags.caBundleData, "ca-bundle-data", nil, "Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)")
@@ -130,6 +153,16 @@ func oidcLoginCommand(deps oidcLoginCommandDeps) *cobra.Command {
 	cmd.Flags().StringVar(&flags.upstreamIdentityProviderName, "upstream-identity-provider-name", "", "The name of the upstream identity provider used during login with a Supervisor")
 	cmd.Flags().StringVar(&flags.upstreamIdentityProviderType, "upstream-identity-provider-type", idpdiscoveryv1alpha1.IDPTypeOIDC.String(), fmt.Sprintf("The type of the upstream identity provider used during login with a Supervisor (e.g. '%s', '%s', '%s')", idpdiscoveryv1alpha1.IDPTypeOIDC, idpdiscoveryv1alpha1.IDPTypeLDAP, idpdiscoveryv1alpha1.IDPTypeActiveDirectory))
 	cmd.Flags().StringVar(&flags.upstreamIdentityProviderFlow, "upstream-identity-provider-flow", "", fmt.Sprintf("The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. '%s', '%s')", idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode, idpdiscoveryv1alpha1.IDPFlowCLIPassword))
+	cmd.Flags().StringVar(&flags.grantType, "grant-type", grantTypeAuthorizationCode, fmt.Sprintf("The OAuth2 grant type to use during login (e.g. '%s', '%s')", grantTypeAuthorizationCode, grantTypeDevice))
+	cmd.Flags().BoolVar(&flags.showQRCode, "show-qr-code", false, "Also print the login link as a QR code (device grant type only)")
+	cmd.Flags().StringVar(&flags.cacheBackend, "cache-backend", cacheBackendFile, fmt.Sprintf("Where to cache sessions and cluster credentials (e.g. '%s', '%s')", cacheBackendFile, cacheBackendKeychain))
+	cmd.Flags().StringVar(&flags.usernameFile, "username-file", "", "Path to a file containing the username (cli_password flow only)")
+	cmd.Flags().StringVar(&flags.passwordFile, "password-file", "", "Path to a file containing the password (cli_password flow only)")
+	cmd.Flags().StringVar(&flags.credentialsCommand, "credentials-command", "", "Command which prints the username and password as a JSON object (cli_password flow only)")
+	cmd.Flags().StringArrayVar(&flags.credentialsCommandArgs, "credentials-command-arg", nil, "Argument of the credentials command (can be repeated)")
+	cmd.Flags().StringVar(&flags.execAPIVersion, "exec-api-version", "", "The client.authentication.k8s.io API version of the printed ExecCredential (e.g. 'v1beta1', 'v1') (default: the version requested by client-go)")
+	cmd.Flags().StringVar(&flags.proxyURL, "proxy-url", "", "URL of the HTTP, HTTPS, or SOCKS5 proxy for requests to the issuer and the Concierge (default: the proxy of the HTTPS_PROXY and HTTP_PROXY environment variables)")
+	cmd.Flags().StringSliceVar(&flags.noProxy, "no-proxy", nil, "Hosts, domains, and CIDRs which are reached without the proxy (can be repeated)")
 
 	// --skip-listen is mainly needed for testing. We'll leave it hidden until we have a non-testing use case.
 	mustMarkHidden(cmd, "skip-listen")
@@ -149,17 +182,40 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		plog.WarningErr("Received error while setting log level", err)
 	}
 
+	info := loadExecInfo(deps.lookupEnv)
+	apiVersion, err := execCredentialAPIVersion(flags.execAPIVersion, info)
+	if err != nil {
+		return err
+	}
+
+	proxy, err := loginProxy(flags.proxyURL, flags.noProxy, deps.lookupEnv)
+	if err != nil {
+		return err
+	}
+
+	// Decide whether the sessions and credentials are cached in the OS keychain instead of in files.
+	kc, err := cacheKeychain(flags.cacheBackend, deps.lookupEnv, deps.openKeychain, pLogger)
+	if err != nil {
+		return err
+	}
+
 	// Initialize the session cache.
 	var sessionOptions []filesession.Option
+	var keychainSessionOptions []keychainsession.Option
 
 	// If the hidden --debug-session-cache option is passed, log all the errors from the session cache.
 	if flags.debugSessionCache {
 		logger := plog.WithName("session")
-		sessionOptions = append(sessionOptions, filesession.WithErrorReporter(func(err error) {
+		reportErr := func(err error) {
 			logger.Error("error during session cache operation", err)
-		}))
+		}
+		sessionOptions = append(sessionOptions, filesession.WithErrorReporter(reportErr))
+		keychainSessionOptions = append(keychainSessionOptions, keychainsession.WithErrorReporter(reportErr))
+	}
+	var sessionCache oidcclient.SessionCache = filesession.New(flags.sessionCachePath, sessionOptions...)
+	if kc != nil {
+		sessionCache = keychainsession.New(kc, sessionCache, keychainSessionOptions...)
 	}
-	sessionCache := filesession.New(flags.sessionCachePath, sessionOptions...)
 
 	// Initialize the login handler.
 	opts := []oidcclient.Option{
@@ -192,6 +248,29 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 	}
 	opts = append(opts, flowOpts...)
 
+	// The username and password can be provided non-interactively, for example by a CI system. The environment
+	// variables PINNIPED_USERNAME and PINNIPED_PASSWORD are always checked first by the login handler.
+	if flags.usernameFile != "" || flags.passwordFile != "" {
+		opts = append(opts, oidcclient.WithCLICredentialFiles(flags.usernameFile, flags.passwordFile))
+	}
+	if flags.credentialsCommand != "" {
+		opts = append(opts, oidcclient.WithCLICredentialsCommand(flags.credentialsCommand, flags.credentialsCommandArgs...))
+	}
+
+	switch flags.grantType {
+	case grantTypeAuthorizationCode:
+	case grantTypeDevice:
+		// With the device authorization grant, the user always logs in using a web browser, possibly on another
+		// device, so it takes precedence over the flow chosen above.
+		opts = append(opts, oidcclient.WithDeviceAuthorizationGrant())
+		if flags.showQRCode {
+			opts = append(opts, oidcclient.WithDeviceQRCode())
+		}
+	default:
+		return fmt.Errorf("--grant-type value not recognized: %s (supported values: %s)",
+			flags.grantType, strings.Join([]string{grantTypeAuthorizationCode, grantTypeDevice}, ", "))
+	}
+
 	var concierge *conciergeclient.Client
 	if flags.conciergeEnabled {
 		var err error
@@ -200,12 +279,18 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 			conciergeclient.WithBase64CABundle(flags.conciergeCABundle),
 			conciergeclient.WithAuthenticator(flags.conciergeAuthenticatorType, flags.conciergeAuthenticatorName),
 			conciergeclient.WithAPIGroupSuffix(flags.conciergeAPIGroupSuffix),
+			conciergeclient.WithProxy(proxy),
 		)
 		if err != nil {
 			return fmt.Errorf("invalid Concierge parameters: %w", err)
 		}
 	}
 
+	// --browser-command opens the browser using a specific command, e.g. to choose a browser or a browser profile.
+	if flags.browserCommand != "" {
+		opts = append(opts, oidcclient.WithBrowserCommand(flags.browserCommand, flags.browserCommandArgs...))
+	}
+
 	// --skip-browser skips opening the browser.
 	if flags.skipBrowser {
 		opts = append(opts, oidcclient.WithSkipBrowserOpen())
@@ -216,8 +301,14 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		opts = append(opts, oidcclient.WithSkipListen())
 	}
 
-	if len(flags.caBundlePaths) > 0 || len(flags.caBundleData) > 0 {
-		client, err := makeClient(flags.caBundlePaths, flags.caBundleData)
+	// --remote-browser neither opens the browser nor starts the localhost callback listener, and always
+	// prompts for the pasted authorization code.
+	if flags.remoteBrowser {
+		opts = append(opts, oidcclient.WithRemoteBrowser())
+	}
+
+	if len(flags.caBundlePaths) > 0 || len(flags.caBundleData) > 0 || proxy != nil {
+		client, err := makeClient(flags.caBundlePaths, flags.caBundleData, proxy)
 		if err != nil {
 			return err
 		}
@@ -229,14 +320,14 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		ClusterInfo *clientauthv1beta1.Cluster `json:"cluster"`
 	}{
 		Args:        os.Args[1:],
-		ClusterInfo: loadClusterInfo(),
+		ClusterInfo: info.cluster,
 	}
-	var credCache *execcredcache.Cache
+	var credCache credentialCache
 	if flags.credentialCachePath != "" {
-		credCache = execcredcache.New(flags.credentialCachePath)
+		credCache = newCredentialCache(flags.credentialCachePath, kc)
 		if cred := credCache.Get(cacheKey); cred != nil {
 			pLogger.Debug("using cached cluster credential.")
-			return json.NewEncoder(cmd.OutOrStdout()).Encode(cred)
+			return writeExecCredential(cmd.OutOrStdout(), cred, apiVersion)
 		}
 	}
 
@@ -268,7 +359,7 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		pLogger.Debug("caching cluster credential for future use.")
 		credCache.Put(cacheKey, cred)
 	}
-	return json.NewEncoder(cmd.OutOrStdout()).Encode(cred)
+	return writeExecCredential(cmd.OutOrStdout(), cred, apiVersion)
 }
 
 func flowOptions(
@@ -311,6 +402,17 @@ func flowOptions(
 				flowSource, requestedIDPType, requestedFlow,
 				strings.Join([]string{idpdiscoveryv1alpha1.IDPFlowCLIPassword.String(), idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode.String()}, ", "))
 		}
+	case idpdiscoveryv1alpha1.IDPTypeKerberos:
+		switch requestedFlow {
+		case idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode, "":
+			return nil, nil // browser authcode flow is the default Option, so don't need to return an Option here
+		default:
+			// Kerberos logins require the browser to authenticate the user using SPNEGO.
+			return nil, fmt.Errorf(
+				"%s value not recognized for identity provider type %q: %s (supported values: %s)",
+				flowSource, requestedIDPType, requestedFlow,
+				idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode.String())
+		}
 	default:
 		// Surprisingly cobra does not support this kind of flag validation. See https://github.com/spf13/pflag/issues/236
 		return nil, fmt.Errorf(
@@ -320,13 +422,19 @@ func flowOptions(
 				idpdiscoveryv1alpha1.IDPTypeOIDC.String(),
 				idpdiscoveryv1alpha1.IDPTypeLDAP.String(),
 				idpdiscoveryv1alpha1.IDPTypeActiveDirectory.String(),
+				idpdiscoveryv1alpha1.IDPTypeKerberos.String(),
 			}, ", "),
 		)
 	}
 }
 
-func makeClient(caBundlePaths []string, caBundleData []string) (*http.Client, error) {
-	pool := x509.NewCertPool()
+// makeClient returns an HTTP client which trusts the given CA bundles, or the system roots when there are none, and
+// which uses the given proxy function, or the proxy of the environment variables when it is nil.
+func makeClient(caBundlePaths []string, caBundleData []string, proxy func(*http.Request) (*url.URL, error)) (*http.Client, error) {
+	var pool *x509.CertPool
+	if len(caBundlePaths) > 0 || len(caBundleData) > 0 {
+		pool = x509.NewCertPool()
+	}
 	for _, p := range caBundlePaths {
 		pem, err := os.ReadFile(p)
 		if err != nil {
@@ -341,7 +449,7 @@ func makeClient(caBundlePaths []string, caBundleData []string) (*http.Client, er
 		}
 		pool.AppendCertsFromPEM(pem)
 	}
-	return phttp.Default(pool), nil
+	return phttp.DefaultWithProxy(pool, proxy), nil
 }
 
 func tokenCredential(token *oidctypes.Token) *clientauthv1beta1.ExecCredential {
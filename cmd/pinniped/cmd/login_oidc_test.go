@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -22,8 +22,10 @@ import (
 
 	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/keychain"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/fakekeychain"
 	"go.pinniped.dev/pkg/conciergeclient"
 	"go.pinniped.dev/pkg/oidcclient"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
@@ -49,12 +51,14 @@ func TestLoginOIDCCommand(t *testing.T) {
 		args             []string
 		loginErr         error
 		conciergeErr     error
+		openKeychainErr  error
 		env              map[string]string
 		wantError        bool
 		wantStdout       string
 		wantStderr       string
 		wantOptionsCount int
 		wantLogs         []string
+		wantKeychainLen  int
 	}{
 		{
 			name: "help flag passed",
@@ -74,8 +78,11 @@ func TestLoginOIDCCommand(t *testing.T) {
 				  oidc --issuer ISSUER [flags]
 
 				Flags:
+				      --browser-command string                   Command which opens the login link in a web browser, with the link as its last argument (browser_authcode flow only)
+				      --browser-command-arg stringArray          Argument of the browser command (can be repeated)
 				      --ca-bundle strings                        Path to TLS certificate authority bundle (PEM format, optional, can be repeated)
 				      --ca-bundle-data strings                   Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)
+				      --cache-backend string                     Where to cache sessions and cluster credentials (e.g. 'file', 'keychain') (default "file")
 				      --client-id string                         OpenID Connect client ID (default "pinniped-cli")
 				      --concierge-api-group-suffix string        Concierge API group suffix (default "pinniped.dev")
 				      --concierge-authenticator-name string      Concierge authenticator name
@@ -83,17 +90,27 @@ func TestLoginOIDCCommand(t *testing.T) {
 				      --concierge-ca-bundle-data string          CA bundle to use when connecting to the Concierge
 				      --concierge-endpoint string                API base for the Concierge endpoint
 				      --credential-cache string                  Path to cluster-specific credentials cache ("" disables the cache) (default "` + cfgDir + `/credentials.yaml")
+				      --credentials-command string               Command which prints the username and password as a JSON object (cli_password flow only)
+				      --credentials-command-arg stringArray      Argument of the credentials command (can be repeated)
 				      --enable-concierge                         Use the Concierge to login
+				      --exec-api-version string                  The client.authentication.k8s.io API version of the printed ExecCredential (e.g. 'v1beta1', 'v1') (default: the version requested by client-go)
+				      --grant-type string                        The OAuth2 grant type to use during login (e.g. 'authorization_code', 'device') (default "authorization_code")
 				  -h, --help                                     help for oidc
 				      --issuer string                            OpenID Connect issuer URL
 				      --listen-port uint16                       TCP port for localhost listener (authorization code flow only)
+				      --no-proxy strings                         Hosts, domains, and CIDRs which are reached without the proxy (can be repeated)
+				      --password-file string                     Path to a file containing the password (cli_password flow only)
+				      --proxy-url string                         URL of the HTTP, HTTPS, or SOCKS5 proxy for requests to the issuer and the Concierge (default: the proxy of the HTTPS_PROXY and HTTP_PROXY environment variables)
+				      --remote-browser                           Log in using a web browser on another machine, by pasting the authorization code instead of using a localhost callback listener (browser_authcode flow only)
 				      --request-audience string                  Request a token with an alternate audience using RFC8693 token exchange
 				      --scopes strings                           OIDC scopes to request during login (default [offline_access,openid,pinniped:request-audience,username,groups])
 				      --session-cache string                     Path to session cache file (default "` + cfgDir + `/sessions.yaml")
+				      --show-qr-code                             Also print the login link as a QR code (device grant type only)
 				      --skip-browser                             Skip opening the browser (just print the URL)
 					  --upstream-identity-provider-flow string   The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. 'browser_authcode', 'cli_password')
 					  --upstream-identity-provider-name string   The name of the upstream identity provider used during login with a Supervisor
 					  --upstream-identity-provider-type string   The type of the upstream identity provider used during login with a Supervisor (e.g. 'oidc', 'ldap', 'activedirectory') (default "oidc")
+				      --username-file string                     Path to a file containing the username (cli_password flow only)
 			`),
 		},
 		{
@@ -163,7 +180,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 			},
 			wantError: true,
 			wantStderr: here.Doc(`
-				Error: --upstream-identity-provider-type value not recognized: invalid (supported values: oidc, ldap, activedirectory)
+				Error: --upstream-identity-provider-type value not recognized: invalid (supported values: oidc, ldap, activedirectory, kerberos)
 			`),
 		},
 		{
@@ -175,7 +192,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 			env:       map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "browser_authcode"},
 			wantError: true,
 			wantStderr: here.Doc(`
-				Error: --upstream-identity-provider-type value not recognized: invalid (supported values: oidc, ldap, activedirectory)
+				Error: --upstream-identity-provider-type value not recognized: invalid (supported values: oidc, ldap, activedirectory, kerberos)
 			`),
 		},
 		{
@@ -448,6 +465,143 @@ func TestLoginOIDCCommand(t *testing.T) {
 				Error: PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW value not recognized for identity provider type "activedirectory": foo (supported values: cli_password, browser_authcode)
 			`),
 		},
+		{
+			name: "device grant type is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--grant-type", "device",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "device grant type with QR code is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--grant-type", "device",
+				"--show-qr-code",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "ldap upstream type with device grant type is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--upstream-identity-provider-type", "ldap",
+				"--grant-type", "device",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "ldap upstream type with non-interactive credentials is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--upstream-identity-provider-type", "ldap",
+				"--username-file", "/path/to/username",
+				"--password-file", "/path/to/password",
+				"--credentials-command", "/path/to/command",
+				"--credentials-command-arg", "some arg",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 7,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "browser command and remote browser are allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--browser-command", "/path/to/browser",
+				"--browser-command-arg", "--profile-directory=Work",
+				"--remote-browser",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "proxy URL",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--proxy-url", "http://proxy.example.com:3128",
+				"--no-proxy", ".internal.example.com,10.0.0.0/8",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "no proxy for the proxy of the environment variables",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_NO_PROXY": ".internal.example.com"},
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "invalid proxy URL",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--proxy-url", "ftp://proxy.example.com",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --proxy-url: unsupported scheme "ftp"
+			`),
+		},
+		{
+			name: "unsupported grant type is an error",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--grant-type", "password",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --grant-type value not recognized: password (supported values: authorization_code, device)
+			`),
+		},
+		{
+			name: "kerberos upstream type with default flow is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--upstream-identity-provider-type", "kerberos",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 4,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "kerberos upstream type with CLI flow is an error",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--upstream-identity-provider-type", "kerberos",
+				"--upstream-identity-provider-flow", "cli_password",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --upstream-identity-provider-flow value not recognized for identity provider type "kerberos": cli_password (supported values: browser_authcode)
+			`),
+		},
 		{
 			name: "login error",
 			args: []string{
@@ -480,6 +634,62 @@ func TestLoginOIDCCommand(t *testing.T) {
 				Error: could not complete Concierge credential exchange: some concierge error
 			`),
 		},
+		{
+			name: "invalid cache backend",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--cache-backend", "invalid",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --cache-backend value not recognized: invalid (supported values: file, keychain)
+			`),
+		},
+		{
+			name: "invalid cache backend from env var override",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--cache-backend", "keychain",
+			},
+			env:       map[string]string{"PINNIPED_CACHE_BACKEND": "invalid"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: PINNIPED_CACHE_BACKEND value not recognized: invalid (supported values: file, keychain)
+			`),
+		},
+		{
+			name: "keychain cache backend falls back to files when the keychain is not available",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--cache-backend", "keychain",
+				"--credential-cache", testutil.TempDir(t) + "/credentials.yaml", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			openKeychainErr:  fmt.Errorf("some keychain error"),
+			env:              map[string]string{"PINNIPED_DEBUG": "true"},
+			wantOptionsCount: 4,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+			wantLogs: []string{
+				nowStr + `  pinniped-login  cmd/cache_backend.go:54  OS keychain is not available, using cache files instead  {"error": "some keychain error"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:334  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:354  No concierge configured, skipping token credential exchange`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:359  caching cluster credential for future use.`,
+			},
+		},
+		{
+			name: "success with keychain cache backend from env var override",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", testutil.TempDir(t) + "/credentials.yaml", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_CACHE_BACKEND": "keychain"},
+			wantOptionsCount: 4,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+			wantKeychainLen:  2, // the credential and the index
+		},
 		{
 			name: "success with minimal options",
 			args: []string{
@@ -491,8 +701,8 @@ func TestLoginOIDCCommand(t *testing.T) {
 			wantOptionsCount: 4,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 			wantLogs: []string{
-				nowStr + `  pinniped-login  cmd/login_oidc.go:243  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:263  No concierge configured, skipping token credential exchange`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:334  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:354  No concierge configured, skipping token credential exchange`,
 			},
 		},
 		{
@@ -521,12 +731,37 @@ func TestLoginOIDCCommand(t *testing.T) {
 			wantOptionsCount: 11,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"token":"exchanged-token"}}` + "\n",
 			wantLogs: []string{
-				nowStr + `  pinniped-login  cmd/login_oidc.go:243  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:253  Exchanging token for cluster credential  {"endpoint": "https://127.0.0.1:1234/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:261  Successfully exchanged token for cluster credential.`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:268  caching cluster credential for future use.`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:334  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:344  Exchanging token for cluster credential  {"endpoint": "https://127.0.0.1:1234/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:352  Successfully exchanged token for cluster credential.`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:359  caching cluster credential for future use.`,
 			},
 		},
+		{
+			name: "success with the v1 ExecCredential API requested by client-go",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env: map[string]string{
+				"KUBERNETES_EXEC_INFO": `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":true}}`,
+			},
+			wantOptionsCount: 4,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "invalid exec API version",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--exec-api-version", "v2",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --exec-api-version "v2", supported values are "v1beta1" and "v1"
+			`),
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -537,6 +772,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 
 			var (
 				gotOptions []oidcclient.Option
+				kc         = fakekeychain.New()
 			)
 			cmd := oidcLoginCommand(oidcLoginCommandDeps{
 				lookupEnv: func(s string) (string, bool) {
@@ -572,6 +808,12 @@ func TestLoginOIDCCommand(t *testing.T) {
 						},
 					}, nil
 				},
+				openKeychain: func() (keychain.Keychain, error) {
+					if tt.openKeychainErr != nil {
+						return nil, tt.openKeychainErr
+					}
+					return kc, nil
+				},
 			})
 			require.NotNil(t, cmd)
 
@@ -590,6 +832,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 			require.Len(t, gotOptions, tt.wantOptionsCount)
 
 			require.Equal(t, tt.wantLogs, logLines(buf.String()))
+			require.Equal(t, tt.wantKeychainLen, kc.Len())
 		})
 	}
 }
@@ -1,11 +1,10 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,9 +13,9 @@ import (
 	"github.com/spf13/cobra"
 	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 
-	"go.pinniped.dev/internal/execcredcache"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/keychain"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/pkg/conciergeclient"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
@@ -30,11 +29,13 @@ func init() {
 type staticLoginDeps struct {
 	lookupEnv     func(string) (string, bool)
 	exchangeToken func(context.Context, *conciergeclient.Client, string) (*clientauthv1beta1.ExecCredential, error)
+	openKeychain  func() (keychain.Keychain, error)
 }
 
 func staticLoginRealDeps() staticLoginDeps {
 	return staticLoginDeps{
-		lookupEnv: os.LookupEnv,
+		lookupEnv:    os.LookupEnv,
+		openKeychain: keychain.New,
 		exchangeToken: func(ctx context.Context, client *conciergeclient.Client, token string) (*clientauthv1beta1.ExecCredential, error) {
 			return client.ExchangeToken(ctx, token)
 		},
@@ -51,6 +52,10 @@ type staticLoginParams struct {
 	conciergeCABundle          string
 	conciergeAPIGroupSuffix    string
 	credentialCachePath        string
+	cacheBackend               string
+	execAPIVersion             string
+	proxyURL                   string
+	noProxy                    []string
 }
 
 func staticLoginCommand(deps staticLoginDeps) *cobra.Command {
@@ -85,6 +90,10 @@ func staticLoginCommand(deps staticLoginDeps) *cobra.Command {
 	cmd.Flags().StringVar(&flags.conciergeCABundle, "concierge-ca-bundle-data", "", "CA bundle to use when connecting to the Concierge")
 	cmd.Flags().StringVar(&flags.conciergeAPIGroupSuffix, "concierge-api-group-suffix", groupsuffix.PinnipedDefaultSuffix, "Concierge API group suffix")
 	cmd.Flags().StringVar(&flags.credentialCachePath, "credential-cache", filepath.Join(mustGetConfigDir(), "credentials.yaml"), "Path to cluster-specific credentials cache (\"\" disables the cache)")
+	cmd.Flags().StringVar(&flags.cacheBackend, "cache-backend", cacheBackendFile, fmt.Sprintf("Where to cache cluster credentials (e.g. '%s', '%s')", cacheBackendFile, cacheBackendKeychain))
+	cmd.Flags().StringVar(&flags.execAPIVersion, "exec-api-version", "", "The client.authentication.k8s.io API version of the printed ExecCredential (e.g. 'v1beta1', 'v1') (default: the version requested by client-go)")
+	cmd.Flags().StringVar(&flags.proxyURL, "proxy-url", "", "URL of the HTTP, HTTPS, or SOCKS5 proxy for requests to the Concierge (default: the proxy of the HTTPS_PROXY and HTTP_PROXY environment variables)")
+	cmd.Flags().StringSliceVar(&flags.noProxy, "no-proxy", nil, "Hosts, domains, and CIDRs which are reached without the proxy (can be repeated)")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error { return runStaticLogin(cmd, deps, flags) }
 
@@ -105,6 +114,23 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 		return fmt.Errorf("one of --token or --token-env must be set")
 	}
 
+	info := loadExecInfo(deps.lookupEnv)
+	apiVersion, err := execCredentialAPIVersion(flags.execAPIVersion, info)
+	if err != nil {
+		return err
+	}
+
+	proxy, err := loginProxy(flags.proxyURL, flags.noProxy, deps.lookupEnv)
+	if err != nil {
+		return err
+	}
+
+	// Decide whether the credentials are cached in the OS keychain instead of in a file.
+	kc, err := cacheKeychain(flags.cacheBackend, deps.lookupEnv, deps.openKeychain, pLogger)
+	if err != nil {
+		return err
+	}
+
 	var concierge *conciergeclient.Client
 	if flags.conciergeEnabled {
 		var err error
@@ -113,6 +139,7 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 			conciergeclient.WithBase64CABundle(flags.conciergeCABundle),
 			conciergeclient.WithAuthenticator(flags.conciergeAuthenticatorType, flags.conciergeAuthenticatorName),
 			conciergeclient.WithAPIGroupSuffix(flags.conciergeAPIGroupSuffix),
+			conciergeclient.WithProxy(proxy),
 		)
 		if err != nil {
 			return fmt.Errorf("invalid Concierge parameters: %w", err)
@@ -143,14 +170,14 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 	}{
 		Args:        os.Args[1:],
 		Token:       token,
-		ClusterInfo: loadClusterInfo(),
+		ClusterInfo: info.cluster,
 	}
-	var credCache *execcredcache.Cache
+	var credCache credentialCache
 	if flags.credentialCachePath != "" {
-		credCache = execcredcache.New(flags.credentialCachePath)
+		credCache = newCredentialCache(flags.credentialCachePath, kc)
 		if cred := credCache.Get(cacheKey); cred != nil {
 			pLogger.Debug("using cached cluster credential.")
-			return json.NewEncoder(out).Encode(cred)
+			return writeExecCredential(out, cred, apiVersion)
 		}
 	}
 
@@ -174,5 +201,5 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 		credCache.Put(cacheKey, cred)
 	}
 
-	return json.NewEncoder(out).Encode(cred)
+	return writeExecCredential(out, cred, apiVersion)
 }
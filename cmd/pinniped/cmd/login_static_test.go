@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -20,8 +20,10 @@ import (
 
 	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/keychain"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/fakekeychain"
 	"go.pinniped.dev/pkg/conciergeclient"
 )
 
@@ -44,11 +46,13 @@ func TestLoginStaticCommand(t *testing.T) {
 		env              map[string]string
 		loginErr         error
 		conciergeErr     error
+		openKeychainErr  error
 		wantError        bool
 		wantStdout       string
 		wantStderr       string
 		wantOptionsCount int
 		wantLogs         []string
+		wantKeychainLen  int
 	}{
 		{
 			name: "help flag passed",
@@ -68,6 +72,7 @@ func TestLoginStaticCommand(t *testing.T) {
 				  static [--token TOKEN] [--token-env TOKEN_NAME] [flags]
 
 				Flags:
+				      --cache-backend string                  Where to cache cluster credentials (e.g. 'file', 'keychain') (default "file")
 				      --concierge-api-group-suffix string     Concierge API group suffix (default "pinniped.dev")
 				      --concierge-authenticator-name string   Concierge authenticator name
 				      --concierge-authenticator-type string   Concierge authenticator type (e.g., 'webhook', 'jwt')
@@ -75,7 +80,10 @@ func TestLoginStaticCommand(t *testing.T) {
 				      --concierge-endpoint string             API base for the Concierge endpoint
 				      --credential-cache string               Path to cluster-specific credentials cache ("" disables the cache) (default "` + cfgDir + `/credentials.yaml")
 				      --enable-concierge                      Use the Concierge to login
+				      --exec-api-version string               The client.authentication.k8s.io API version of the printed ExecCredential (e.g. 'v1beta1', 'v1') (default: the version requested by client-go)
 				  -h, --help                                  help for static
+				      --no-proxy strings                      Hosts, domains, and CIDRs which are reached without the proxy (can be repeated)
+				      --proxy-url string                      URL of the HTTP, HTTPS, or SOCKS5 proxy for requests to the Concierge (default: the proxy of the HTTPS_PROXY and HTTP_PROXY environment variables)
 				      --token string                          Static token to present during login
 				      --token-env string                      Environment variable containing a static token
 			`),
@@ -148,7 +156,7 @@ func TestLoginStaticCommand(t *testing.T) {
 				Error: could not complete Concierge credential exchange: some concierge error
 			`),
 			wantLogs: []string{
-				nowStr + `  pinniped-login  cmd/login_static.go:159  exchanging static token for cluster credential  {"endpoint": "https://127.0.0.1/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
+				nowStr + `  pinniped-login  cmd/login_static.go:186  exchanging static token for cluster credential  {"endpoint": "https://127.0.0.1/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
 			},
 		},
 		{
@@ -166,6 +174,50 @@ func TestLoginStaticCommand(t *testing.T) {
 				Error: invalid Concierge parameters: invalid API group suffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')
 			`),
 		},
+		{
+			name: "invalid cache backend",
+			args: []string{
+				"--token", "test-token",
+				"--cache-backend", "invalid",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --cache-backend value not recognized: invalid (supported values: file, keychain)
+			`),
+		},
+		{
+			name: "concierge success with keychain cache backend",
+			args: []string{
+				"--token", "test-token",
+				"--enable-concierge",
+				"--concierge-endpoint", "https://127.0.0.1/",
+				"--concierge-authenticator-type", "webhook",
+				"--concierge-authenticator-name", "test-authenticator",
+				"--cache-backend", "keychain",
+				"--credential-cache", testutil.TempDir(t) + "/credentials.yaml",
+			},
+			wantStdout:      `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"token":"exchanged-token"}}` + "\n",
+			wantKeychainLen: 2, // the credential and the index
+		},
+		{
+			name: "concierge success when the keychain is not available",
+			args: []string{
+				"--token", "test-token",
+				"--enable-concierge",
+				"--concierge-endpoint", "https://127.0.0.1/",
+				"--concierge-authenticator-type", "webhook",
+				"--concierge-authenticator-name", "test-authenticator",
+				"--credential-cache", testutil.TempDir(t) + "/credentials.yaml",
+			},
+			openKeychainErr: fmt.Errorf("some keychain error"),
+			env:             map[string]string{"PINNIPED_DEBUG": "true", "PINNIPED_CACHE_BACKEND": "keychain"},
+			wantStdout:      `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"token":"exchanged-token"}}` + "\n",
+			wantLogs: []string{
+				nowStr + `  pinniped-login  cmd/cache_backend.go:54  OS keychain is not available, using cache files instead  {"error": "some keychain error"}`,
+				nowStr + `  pinniped-login  cmd/login_static.go:186  exchanging static token for cluster credential  {"endpoint": "https://127.0.0.1/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
+				nowStr + `  pinniped-login  cmd/login_static.go:195  exchanged static token for cluster credential`,
+			},
+		},
 		{
 			name: "static token success",
 			args: []string{
@@ -174,6 +226,61 @@ func TestLoginStaticCommand(t *testing.T) {
 			env:        map[string]string{"PINNIPED_DEBUG": "true"},
 			wantStdout: `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"token":"test-token"}}` + "\n",
 		},
+		{
+			name: "static token success with the v1 ExecCredential API requested by client-go",
+			args: []string{
+				"--token", "test-token",
+			},
+			env: map[string]string{
+				"KUBERNETES_EXEC_INFO": `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"cluster":{"server":"https://127.0.0.1/"},"interactive":false}}`,
+			},
+			wantStdout: `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":false},"status":{"token":"test-token"}}` + "\n",
+		},
+		{
+			name: "static token success with the v1 ExecCredential API selected by flag",
+			args: []string{
+				"--token", "test-token",
+				"--exec-api-version", "v1",
+			},
+			env: map[string]string{
+				"KUBERNETES_EXEC_INFO": `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false}}`,
+			},
+			wantStdout: `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":false},"status":{"token":"test-token"}}` + "\n",
+		},
+		{
+			name: "invalid exec API version",
+			args: []string{
+				"--token", "test-token",
+				"--exec-api-version", "v2",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --exec-api-version "v2", supported values are "v1beta1" and "v1"
+			`),
+		},
+		{
+			name: "invalid proxy URL",
+			args: []string{
+				"--token", "test-token",
+				"--proxy-url", "ftp://proxy.example.com",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --proxy-url: unsupported scheme "ftp"
+			`),
+		},
+		{
+			name: "invalid proxy URL from env var",
+			args: []string{
+				"--token", "test-token",
+				"--proxy-url", "http://proxy.example.com:3128",
+			},
+			env:       map[string]string{"PINNIPED_PROXY_URL": "http://"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid PINNIPED_PROXY_URL: missing host
+			`),
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -182,6 +289,7 @@ func TestLoginStaticCommand(t *testing.T) {
 			fakeClock := clocktesting.NewFakeClock(now)
 			ctx := plog.TestZapOverrides(context.Background(), t, &buf, nil, zap.WithClock(plog.ZapClock(fakeClock)))
 
+			kc := fakekeychain.New()
 			cmd := staticLoginCommand(staticLoginDeps{
 				lookupEnv: func(s string) (string, bool) {
 					v, ok := tt.env[s]
@@ -202,6 +310,12 @@ func TestLoginStaticCommand(t *testing.T) {
 						},
 					}, nil
 				},
+				openKeychain: func() (keychain.Keychain, error) {
+					if tt.openKeychainErr != nil {
+						return nil, tt.openKeychainErr
+					}
+					return kc, nil
+				},
 			})
 			require.NotNil(t, cmd)
 
@@ -219,6 +333,7 @@ func TestLoginStaticCommand(t *testing.T) {
 			require.Equal(t, tt.wantStderr, stderr.String(), "unexpected stderr")
 
 			require.Equal(t, tt.wantLogs, logLines(buf.String()))
+			require.Equal(t, tt.wantKeychainLen, kc.Len())
 		})
 	}
 }
@@ -0,0 +1,112 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginProxy(t *testing.T) {
+	tests := []struct {
+		name        string
+		proxyURL    string
+		noProxy     []string
+		env         map[string]string
+		wantNil     bool
+		wantProxies map[string]string // request URL to proxy URL, or to "" when the request is not proxied
+		wantErr     string
+	}{
+		{
+			name:    "no flags or env vars",
+			env:     map[string]string{"HTTPS_PROXY": "http://env-proxy.example.com:3128"},
+			wantNil: true,
+		},
+		{
+			name:     "proxy URL flag",
+			proxyURL: "http://proxy.example.com:3128",
+			noProxy:  []string{".internal.example.com"},
+			env:      map[string]string{"HTTPS_PROXY": "http://env-proxy.example.com:3128", "NO_PROXY": "issuer.example.com"},
+			wantProxies: map[string]string{
+				"https://issuer.example.com/token":          "http://proxy.example.com:3128",
+				"https://issuer.internal.example.com/token": "",
+			},
+		},
+		{
+			name:     "env vars override the flags",
+			proxyURL: "http://proxy.example.com:3128",
+			noProxy:  []string{".internal.example.com"},
+			env:      map[string]string{"PINNIPED_PROXY_URL": "socks5://other-proxy.example.com:1080", "PINNIPED_NO_PROXY": "issuer.example.com"},
+			wantProxies: map[string]string{
+				"https://issuer.example.com/token":          "",
+				"https://issuer.internal.example.com/token": "socks5://other-proxy.example.com:1080",
+			},
+		},
+		{
+			name:     "empty no proxy env var clears the flag",
+			proxyURL: "http://proxy.example.com:3128",
+			noProxy:  []string{".internal.example.com"},
+			env:      map[string]string{"PINNIPED_NO_PROXY": ""},
+			wantProxies: map[string]string{
+				"https://issuer.internal.example.com/token": "http://proxy.example.com:3128",
+			},
+		},
+		{
+			name:    "no proxy flag without proxy URL excludes hosts from the proxy of the env vars",
+			noProxy: []string{".internal.example.com"},
+			env:     map[string]string{"https_proxy": "http://env-proxy.example.com:3128", "NO_PROXY": "10.0.0.0/8"},
+			wantProxies: map[string]string{
+				"https://issuer.example.com/token":          "http://env-proxy.example.com:3128",
+				"https://issuer.internal.example.com/token": "",
+				"https://10.1.2.3/token":                    "",
+			},
+		},
+		{
+			name:     "invalid proxy URL flag",
+			proxyURL: "ftp://proxy.example.com",
+			wantErr:  `invalid --proxy-url: unsupported scheme "ftp"`,
+		},
+		{
+			name:    "invalid proxy URL env var",
+			env:     map[string]string{"PINNIPED_PROXY_URL": "http://"},
+			wantErr: "invalid PINNIPED_PROXY_URL: missing host",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lookupEnv := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+			proxy, err := loginProxy(tt.proxyURL, tt.noProxy, lookupEnv)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				require.Nil(t, proxy)
+				return
+			}
+			require.NotNil(t, proxy)
+			for requestURL, wantProxy := range tt.wantProxies {
+				req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+				require.NoError(t, err)
+				got, err := proxy(req)
+				require.NoError(t, err)
+				if wantProxy == "" {
+					require.Nil(t, got, requestURL)
+					continue
+				}
+				require.NotNil(t, got, requestURL)
+				require.Equal(t, wantProxy, got.String(), requestURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.pinniped.dev/internal/execcredcache"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/keychain"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/filesession"
+	"go.pinniped.dev/pkg/oidcclient/keychainsession"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(logoutCommand(logoutCommandRealDeps()))
+}
+
+type logoutCommandDeps struct {
+	lookupEnv    func(string) (string, bool)
+	openKeychain func() (keychain.Keychain, error)
+	revoke       func(ctx context.Context, httpClient *http.Client, issuer, clientID, refreshToken string) error
+}
+
+func logoutCommandRealDeps() logoutCommandDeps {
+	return logoutCommandDeps{
+		lookupEnv:    os.LookupEnv,
+		openKeychain: keychain.New,
+		revoke:       oidcclient.RevokeRefreshToken,
+	}
+}
+
+type logoutFlags struct {
+	issuer              string
+	clientID            string
+	all                 bool
+	sessionCachePath    string
+	credentialCachePath string
+	cacheBackend        string
+	caBundlePaths       []string
+	caBundleData        []string
+	skipRevocation      bool
+}
+
+// sessionRefreshToken is the refresh token of a deleted session, which still has to be revoked at its issuer.
+type sessionRefreshToken struct {
+	issuer   string
+	clientID string
+	token    string
+}
+
+func logoutCommand(deps logoutCommandDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "logout [--issuer ISSUER | --all]",
+			Short: "Log out of OpenID Connect sessions",
+			Long: here.Doc(
+				`Log out of OpenID Connect sessions
+
+					Deletes the sessions of "pinniped login oidc" with the issuer (or with all issuers)
+					from the session cache, and revokes them at their issuer, so that the next login
+					asks for credentials again. This can be used to switch to another identity.
+
+					All the cluster credentials are also deleted from the credential cache, since they
+					are not associated with an issuer. They will be renewed by the next login.`,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		flags logoutFlags
+	)
+	cmd.Flags().StringVar(&flags.issuer, "issuer", "", "Log out of the sessions with this OpenID Connect issuer URL")
+	cmd.Flags().StringVar(&flags.clientID, "client-id", "", "Only log out of the sessions of this OpenID Connect client ID")
+	cmd.Flags().BoolVar(&flags.all, "all", false, "Log out of the sessions with all issuers")
+	cmd.Flags().StringVar(&flags.sessionCachePath, "session-cache", filepath.Join(mustGetConfigDir(), "sessions.yaml"), "Path to session cache file")
+	cmd.Flags().StringVar(&flags.credentialCachePath, "credential-cache", filepath.Join(mustGetConfigDir(), "credentials.yaml"), "Path to cluster-specific credentials cache (\"\" disables the cache)")
+	cmd.Flags().StringVar(&flags.cacheBackend, "cache-backend", cacheBackendFile, fmt.Sprintf("Where sessions and cluster credentials are cached (e.g. '%s', '%s')", cacheBackendFile, cacheBackendKeychain))
+	cmd.Flags().StringSliceVar(&flags.caBundlePaths, "ca-bundle", nil, "Path to TLS certificate authority bundle (PEM format, optional, can be repeated)")
+	cmd.Flags().StringSliceVar(&flags.caBundleData, "ca-bundle-data", nil, "Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)")
+	cmd.Flags().BoolVar(&flags.skipRevocation, "skip-revocation", false, "Only delete the sessions from the cache, without revoking them at their issuer")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error { return runLogout(cmd, deps, flags) }
+
+	return cmd
+}
+
+func runLogout(cmd *cobra.Command, deps logoutCommandDeps, flags logoutFlags) error {
+	pLogger, err := SetLogLevel(cmd.Context(), deps.lookupEnv)
+	if err != nil {
+		plog.WarningErr("Received error while setting log level", err)
+	}
+
+	switch {
+	case flags.issuer == "" && !flags.all:
+		return fmt.Errorf("one of --issuer or --all must be set")
+	case flags.issuer != "" && flags.all:
+		return fmt.Errorf("only one of --issuer or --all may be set")
+	}
+
+	var httpClient *http.Client
+	if !flags.skipRevocation {
+		httpClient, err = makeClient(flags.caBundlePaths, flags.caBundleData, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	kc, err := cacheKeychain(flags.cacheBackend, deps.lookupEnv, deps.openKeychain, pLogger)
+	if err != nil {
+		return err
+	}
+
+	// Failing to clean up the caches does not fail the logout, but the user should know about it.
+	warn := func(err error) {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+	}
+
+	// Delete the matching sessions, remembering their refresh tokens so that they can be revoked afterwards.
+	var (
+		loggedOut     int
+		refreshTokens []sessionRefreshToken
+	)
+	shouldDelete := func(key oidcclient.SessionCacheKey, token *oidctypes.Token) bool {
+		if (!flags.all && key.Issuer != flags.issuer) || (flags.clientID != "" && key.ClientID != flags.clientID) {
+			return false
+		}
+		loggedOut++
+		if token.RefreshToken != nil && token.RefreshToken.Token != "" {
+			refreshTokens = append(refreshTokens, sessionRefreshToken{issuer: key.Issuer, clientID: key.ClientID, token: token.RefreshToken.Token})
+		}
+		return true
+	}
+	sessionCache := filesession.New(flags.sessionCachePath, filesession.WithErrorReporter(warn))
+	sessionCache.DeleteSessions(shouldDelete)
+	if kc != nil {
+		keychainsession.New(kc, sessionCache, keychainsession.WithErrorReporter(warn)).DeleteSessions(shouldDelete)
+	}
+
+	if flags.credentialCachePath != "" {
+		credCache := execcredcache.New(flags.credentialCachePath)
+		credCache.Clear()
+		if kc != nil {
+			execcredcache.NewKeychain(kc, credCache).Clear()
+		}
+	}
+
+	// Revoking the sessions is best-effort, since the issuer may be unreachable or may not support it.
+	if !flags.skipRevocation && len(refreshTokens) > 0 {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+		defer cancel()
+
+		for _, refreshToken := range refreshTokens {
+			pLogger.Debug("Revoking session", "issuer", refreshToken.issuer, "client id", refreshToken.clientID)
+			if err := deps.revoke(ctx, httpClient, refreshToken.issuer, refreshToken.clientID, refreshToken.token); err != nil {
+				warn(fmt.Errorf("could not revoke the session with %q, so it remains valid there until it expires: %w", refreshToken.issuer, err))
+			}
+		}
+	}
+
+	if loggedOut == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No sessions found.")
+		return nil
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Logged out of %d session(s).\n", loggedOut)
+	return nil
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/execcredcache"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/keychain"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/fakekeychain"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/filesession"
+	"go.pinniped.dev/pkg/oidcclient/keychainsession"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+func TestLogoutCommand(t *testing.T) {
+	cfgDir := mustGetConfigDir()
+
+	sessionKey := func(issuer, clientID string) oidcclient.SessionCacheKey {
+		return oidcclient.SessionCacheKey{
+			Issuer:      issuer,
+			ClientID:    clientID,
+			Scopes:      []string{"openid"},
+			RedirectURI: "http://localhost:0/callback",
+		}
+	}
+	sessionToken := func(refreshToken string) *oidctypes.Token {
+		return &oidctypes.Token{
+			RefreshToken: &oidctypes.RefreshToken{Token: refreshToken},
+		}
+	}
+	// These are the sessions in the session cache at the start of each test.
+	sessions := map[string]oidcclient.SessionCacheKey{
+		"test-refresh-token-1": sessionKey("https://issuer-1.example.com", "pinniped-cli"),
+		"test-refresh-token-2": sessionKey("https://issuer-1.example.com", "other-client"),
+		"test-refresh-token-3": sessionKey("https://issuer-2.example.com", "pinniped-cli"),
+	}
+
+	type revocation struct{ issuer, clientID, refreshToken string }
+
+	tests := []struct {
+		name                 string
+		args                 []string
+		env                  map[string]string
+		openKeychainErr      error
+		sessionsInKeychain   bool
+		revokeErr            error
+		wantError            bool
+		wantStdout           string
+		wantStderr           string
+		wantRevocations      []revocation
+		wantRemainingTokens  []string
+		wantCredentialsKept  bool
+		wantKeychainLen      int
+		wantNoSessionsInFile bool
+	}{
+		{
+			name: "help flag passed",
+			args: []string{"--help"},
+			wantStdout: here.Doc(`
+				Log out of OpenID Connect sessions
+
+				Deletes the sessions of "pinniped login oidc" with the issuer (or with all issuers)
+				from the session cache, and revokes them at their issuer, so that the next login
+				asks for credentials again. This can be used to switch to another identity.
+
+				All the cluster credentials are also deleted from the credential cache, since they
+				are not associated with an issuer. They will be renewed by the next login.
+
+				Usage:
+				  logout [--issuer ISSUER | --all] [flags]
+
+				Flags:
+				      --all                       Log out of the sessions with all issuers
+				      --ca-bundle strings         Path to TLS certificate authority bundle (PEM format, optional, can be repeated)
+				      --ca-bundle-data strings    Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)
+				      --cache-backend string      Where sessions and cluster credentials are cached (e.g. 'file', 'keychain') (default "file")
+				      --client-id string          Only log out of the sessions of this OpenID Connect client ID
+				      --credential-cache string   Path to cluster-specific credentials cache ("" disables the cache) (default "` + cfgDir + `/credentials.yaml")
+				  -h, --help                      help for logout
+				      --issuer string             Log out of the sessions with this OpenID Connect issuer URL
+				      --session-cache string      Path to session cache file (default "` + cfgDir + `/sessions.yaml")
+				      --skip-revocation           Only delete the sessions from the cache, without revoking them at their issuer
+			`),
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2", "test-refresh-token-3"},
+			wantCredentialsKept: true,
+		},
+		{
+			name:      "missing required flags",
+			args:      []string{},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: one of --issuer or --all must be set
+			`),
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2", "test-refresh-token-3"},
+			wantCredentialsKept: true,
+		},
+		{
+			name:      "conflicting flags",
+			args:      []string{"--issuer", "https://issuer-1.example.com", "--all"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: only one of --issuer or --all may be set
+			`),
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2", "test-refresh-token-3"},
+			wantCredentialsKept: true,
+		},
+		{
+			name:      "invalid CA bundle",
+			args:      []string{"--issuer", "https://issuer-1.example.com", "--ca-bundle-data", "invalid-base64"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: could not read --ca-bundle-data: illegal base64 data at input byte 7
+			`),
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2", "test-refresh-token-3"},
+			wantCredentialsKept: true,
+		},
+		{
+			name:      "invalid cache backend",
+			args:      []string{"--issuer", "https://issuer-1.example.com", "--cache-backend", "invalid"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --cache-backend value not recognized: invalid (supported values: file, keychain)
+			`),
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2", "test-refresh-token-3"},
+			wantCredentialsKept: true,
+		},
+		{
+			name:                "no matching sessions",
+			args:                []string{"--issuer", "https://issuer-3.example.com"},
+			wantStdout:          "No sessions found.\n",
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2", "test-refresh-token-3"},
+		},
+		{
+			name:       "log out of the sessions with an issuer",
+			args:       []string{"--issuer", "https://issuer-1.example.com"},
+			wantStdout: "Logged out of 2 session(s).\n",
+			wantRevocations: []revocation{
+				{issuer: "https://issuer-1.example.com", clientID: "other-client", refreshToken: "test-refresh-token-2"},
+				{issuer: "https://issuer-1.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-1"},
+			},
+			wantRemainingTokens: []string{"test-refresh-token-3"},
+		},
+		{
+			name:       "log out of the sessions of a client with an issuer",
+			args:       []string{"--issuer", "https://issuer-1.example.com", "--client-id", "pinniped-cli"},
+			wantStdout: "Logged out of 1 session(s).\n",
+			wantRevocations: []revocation{
+				{issuer: "https://issuer-1.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-1"},
+			},
+			wantRemainingTokens: []string{"test-refresh-token-2", "test-refresh-token-3"},
+		},
+		{
+			name:       "log out of all sessions",
+			args:       []string{"--all"},
+			wantStdout: "Logged out of 3 session(s).\n",
+			wantRevocations: []revocation{
+				{issuer: "https://issuer-1.example.com", clientID: "other-client", refreshToken: "test-refresh-token-2"},
+				{issuer: "https://issuer-1.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-1"},
+				{issuer: "https://issuer-2.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-3"},
+			},
+		},
+		{
+			name:       "log out without revoking the sessions",
+			args:       []string{"--all", "--skip-revocation"},
+			wantStdout: "Logged out of 3 session(s).\n",
+		},
+		{
+			name:       "revocation fails",
+			args:       []string{"--issuer", "https://issuer-2.example.com"},
+			revokeErr:  fmt.Errorf("some revocation error"),
+			wantStdout: "Logged out of 1 session(s).\n",
+			wantStderr: here.Doc(`
+				Warning: could not revoke the session with "https://issuer-2.example.com", so it remains valid there until it expires: some revocation error
+			`),
+			wantRevocations: []revocation{
+				{issuer: "https://issuer-2.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-3"},
+			},
+			wantRemainingTokens: []string{"test-refresh-token-1", "test-refresh-token-2"},
+		},
+		{
+			name:               "log out of the sessions in the keychain",
+			args:               []string{"--issuer", "https://issuer-2.example.com", "--cache-backend", "keychain"},
+			sessionsInKeychain: true,
+			wantStdout:         "Logged out of 1 session(s).\n",
+			wantRevocations: []revocation{
+				{issuer: "https://issuer-2.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-3"},
+			},
+			wantRemainingTokens:  []string{"test-refresh-token-1", "test-refresh-token-2"},
+			wantKeychainLen:      3, // the two remaining sessions and the index
+			wantNoSessionsInFile: true,
+		},
+		{
+			name:            "keychain is not available",
+			args:            []string{"--all", "--cache-backend", "keychain"},
+			openKeychainErr: fmt.Errorf("some keychain error"),
+			wantStdout:      "Logged out of 3 session(s).\n",
+			wantRevocations: []revocation{
+				{issuer: "https://issuer-1.example.com", clientID: "other-client", refreshToken: "test-refresh-token-2"},
+				{issuer: "https://issuer-1.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-1"},
+				{issuer: "https://issuer-2.example.com", clientID: "pinniped-cli", refreshToken: "test-refresh-token-3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tmpdir := testutil.TempDir(t)
+			sessionCachePath := filepath.Join(tmpdir, "sessions.yaml")
+			credentialCachePath := filepath.Join(tmpdir, "credentials.yaml")
+
+			kc := fakekeychain.New()
+			var sessionCache oidcclient.SessionCache = filesession.New(sessionCachePath)
+			if tt.sessionsInKeychain {
+				sessionCache = keychainsession.New(kc, sessionCache)
+			}
+			for refreshToken, key := range sessions {
+				sessionCache.PutToken(key, sessionToken(refreshToken))
+			}
+
+			credentialCache := execcredcache.New(credentialCachePath)
+			credentialKey := "test-credential-key"
+			credentialCache.Put(credentialKey, &clientauthv1beta1.ExecCredential{
+				Status: &clientauthv1beta1.ExecCredentialStatus{
+					Token:               "test-token",
+					ExpirationTimestamp: &metav1.Time{Time: time.Now().Add(1 * time.Hour)},
+				},
+			})
+
+			var revocations []revocation
+			cmd := logoutCommand(logoutCommandDeps{
+				lookupEnv: func(s string) (string, bool) {
+					v, ok := tt.env[s]
+					return v, ok
+				},
+				openKeychain: func() (keychain.Keychain, error) {
+					if tt.openKeychainErr != nil {
+						return nil, tt.openKeychainErr
+					}
+					return kc, nil
+				},
+				revoke: func(ctx context.Context, httpClient *http.Client, issuer, clientID, refreshToken string) error {
+					require.NotNil(t, httpClient)
+					revocations = append(revocations, revocation{issuer: issuer, clientID: clientID, refreshToken: refreshToken})
+					return tt.revokeErr
+				},
+			})
+			require.NotNil(t, cmd)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(append(tt.args, "--session-cache", sessionCachePath, "--credential-cache", credentialCachePath))
+			err := cmd.ExecuteContext(context.Background())
+			if tt.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantStdout, stdout.String(), "unexpected stdout")
+			require.Equal(t, tt.wantStderr, stderr.String(), "unexpected stderr")
+
+			require.ElementsMatch(t, tt.wantRevocations, revocations)
+
+			var remainingTokens []string
+			for _, key := range sessions {
+				if token := sessionCache.GetToken(key); token != nil {
+					remainingTokens = append(remainingTokens, token.RefreshToken.Token)
+				}
+			}
+			require.ElementsMatch(t, tt.wantRemainingTokens, remainingTokens)
+			if tt.wantNoSessionsInFile {
+				require.NoFileExists(t, sessionCachePath)
+			}
+
+			if tt.wantCredentialsKept {
+				require.NotNil(t, credentialCache.Get(credentialKey))
+			} else {
+				require.Nil(t, credentialCache.Get(credentialKey))
+			}
+			require.Equal(t, tt.wantKeychainLen, kc.Len())
+		})
+	}
+}
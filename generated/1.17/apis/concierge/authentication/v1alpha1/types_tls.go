@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -8,4 +8,39 @@ type TLSSpec struct {
 	// X.509 Certificate Authority (base64-encoded PEM bundle). If omitted, a default set of system roots will be trusted.
 	// +optional
 	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+
+	// CertificateAuthorityDataSource is a reference to a Secret or ConfigMap which holds the X.509 Certificate
+	// Authority as a PEM bundle. Changes to the referenced Secret or ConfigMap are loaded automatically, which makes
+	// it easier to rotate the CA bundle than when using CertificateAuthorityData. Only one of CertificateAuthorityData
+	// and CertificateAuthorityDataSource may be specified.
+	// +optional
+	CertificateAuthorityDataSource *CertificateAuthorityDataSourceSpec `json:"certificateAuthorityDataSource,omitempty"`
+}
+
+// CertificateAuthorityDataSourceKind is the kind of object which holds a CA bundle.
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+type CertificateAuthorityDataSourceKind string
+
+const (
+	// CertificateAuthorityDataSourceKindSecret reads the CA bundle from a Secret.
+	CertificateAuthorityDataSourceKindSecret = CertificateAuthorityDataSourceKind("Secret")
+
+	// CertificateAuthorityDataSourceKindConfigMap reads the CA bundle from a ConfigMap.
+	CertificateAuthorityDataSourceKindConfigMap = CertificateAuthorityDataSourceKind("ConfigMap")
+)
+
+// CertificateAuthorityDataSourceSpec is a reference to a key of a Secret or ConfigMap which holds a CA bundle.
+type CertificateAuthorityDataSourceSpec struct {
+	// Kind is the kind of object which holds the CA bundle, either "Secret" or "ConfigMap".
+	// Secrets must be of type "Opaque" or "kubernetes.io/tls".
+	Kind CertificateAuthorityDataSourceKind `json:"kind"`
+
+	// Name is the name of the Secret or ConfigMap, which must be in the namespace in which the Concierge is installed.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key in the Secret or ConfigMap whose value is the CA bundle as PEM, e.g. "ca.crt".
+	// The value is not base64-encoded, other than the encoding which Kubernetes always uses for Secret data.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
 }
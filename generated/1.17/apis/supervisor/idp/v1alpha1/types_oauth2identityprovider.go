@@ -0,0 +1,158 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type OAuth2IdentityProviderPhase string
+
+const (
+	// OAuth2PhasePending is the default phase for newly-created OAuth2IdentityProvider resources.
+	OAuth2PhasePending OAuth2IdentityProviderPhase = "Pending"
+
+	// OAuth2PhaseReady is the phase for an OAuth2IdentityProvider resource in a healthy state.
+	OAuth2PhaseReady OAuth2IdentityProviderPhase = "Ready"
+
+	// OAuth2PhaseError is the phase for an OAuth2IdentityProvider in an unhealthy state.
+	OAuth2PhaseError OAuth2IdentityProviderPhase = "Error"
+)
+
+// OAuth2IdentityProviderStatus is the status of an OAuth2 identity provider.
+type OAuth2IdentityProviderStatus struct {
+	// Phase summarizes the overall status of the OAuth2IdentityProvider.
+	// +kubebuilder:default=Pending
+	// +kubebuilder:validation:Enum=Pending;Ready;Error
+	Phase OAuth2IdentityProviderPhase `json:"phase,omitempty"`
+
+	// Represents the observations of an identity provider's current state.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// OAuth2AuthorizationConfig provides information about how to form the OAuth2 authorization
+// request parameters.
+type OAuth2AuthorizationConfig struct {
+	// scopes are the scopes that will be requested from your OAuth2 provider in the authorization request during an
+	// OAuth2 Authorization Code Flow. No scopes are requested when this is not set. Include any scopes which are
+	// required to read the user's information from the userinfo endpoint, and any scopes which are required to
+	// receive a refresh token from the token endpoint. When your OAuth2 provider does not return refresh tokens,
+	// the Supervisor instead uses the access token to call the userinfo endpoint during refreshes of the user's
+	// session, until the access token expires.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+
+	// additionalAuthorizeParameters are extra query parameters that should be included in the authorize request to
+	// your OAuth2 provider in the authorization request during an OAuth2 Authorization Code Flow. By default, no extra
+	// parameters are sent. The standard parameters that will be sent are "response_type", "scope", "client_id",
+	// "state", "nonce", "code_challenge", "code_challenge_method", and "redirect_uri". These parameters cannot be
+	// included in this setting.
+	// +optional
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
+	AdditionalAuthorizeParameters []Parameter `json:"additionalAuthorizeParameters,omitempty"`
+}
+
+// OAuth2Claims provides CEL expressions which compute identities from the userinfo endpoint response.
+// Each expression is evaluated against a variable named "claims", which holds the JSON object returned by the
+// userinfo endpoint. For example, `string(claims.id)` or `claims.login`.
+// See https://github.com/google/cel-spec for the expression language.
+type OAuth2Claims struct {
+	// UID is an expression which must evaluate to a non-empty string which uniquely and permanently identifies the
+	// user in your OAuth2 provider, such as a numeric user ID. It is combined with the userinfo URL to compute the
+	// subject of the downstream tokens, and it must not change when the user's session is refreshed.
+	// +kubebuilder:validation:MinLength=1
+	UID string `json:"uid"`
+
+	// Username is an expression which must evaluate to a non-empty string, which will be used as the username.
+	// +kubebuilder:validation:MinLength=1
+	Username string `json:"username"`
+
+	// Groups is an expression which must evaluate to a list of strings or a single string, which will be used
+	// as the group memberships. Empty group names are ignored. By default, the identities will not include any
+	// group memberships when this setting is not configured.
+	// +optional
+	Groups string `json:"groups,omitempty"`
+}
+
+// OAuth2Client contains information about an OAuth2 client (e.g., client ID and client secret).
+type OAuth2Client struct {
+	// SecretName contains the name of a namespace-local Secret object that provides the clientID and
+	// clientSecret for an OAuth2 client. The Secret is expected to be of type "secrets.pinniped.dev/oidc-client"
+	// with keys "clientID" and "clientSecret".
+	SecretName string `json:"secretName"`
+}
+
+// OAuth2IdentityProviderSpec is the spec for configuring an OAuth2 identity provider.
+type OAuth2IdentityProviderSpec struct {
+	// AuthorizationURL is the URL of the authorization endpoint of this OAuth2 identity provider.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^https://`
+	AuthorizationURL string `json:"authorizationURL"`
+
+	// TokenURL is the URL of the token endpoint of this OAuth2 identity provider.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^https://`
+	TokenURL string `json:"tokenURL"`
+
+	// UserInfoURL is the URL of an endpoint of this OAuth2 identity provider which returns a JSON object describing
+	// the user when it is called with the user's access token as a bearer token. It is called after each login and
+	// refresh, and its response is used to compute the user's identity using the Claims expressions.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^https://`
+	UserInfoURL string `json:"userInfoURL"`
+
+	// TLS configuration for requests to the endpoints of this OAuth2 identity provider.
+	// +optional
+	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// AuthorizationConfig holds information about how to form the OAuth2 authorization request
+	// parameters to be used with this OAuth2 identity provider.
+	// +optional
+	AuthorizationConfig OAuth2AuthorizationConfig `json:"authorizationConfig,omitempty"`
+
+	// Claims provides the expressions which will be used to compute an identity from the userinfo endpoint
+	// response of this OAuth2 identity provider.
+	Claims OAuth2Claims `json:"claims"`
+
+	// OAuth2Client contains OAuth2 client information to be used with this OAuth2 identity provider.
+	Client OAuth2Client `json:"client"`
+}
+
+// OAuth2IdentityProvider describes the configuration of an upstream OAuth2 identity provider which does not
+// implement OpenID Connect, so it does not offer OIDC discovery or ID tokens. Its names share the same namespace as
+// the names of OIDCIdentityProviders, so an OAuth2IdentityProvider must not have the same name as an
+// OIDCIdentityProvider.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped;pinniped-idp;pinniped-idps
+// +kubebuilder:printcolumn:name="Authorization URL",type=string,JSONPath=`.spec.authorizationURL`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type OAuth2IdentityProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec for configuring the identity provider.
+	Spec OAuth2IdentityProviderSpec `json:"spec"`
+
+	// Status of the identity provider.
+	Status OAuth2IdentityProviderStatus `json:"status,omitempty"`
+}
+
+// OAuth2IdentityProviderList lists OAuth2IdentityProvider objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type OAuth2IdentityProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []OAuth2IdentityProvider `json:"items"`
+}
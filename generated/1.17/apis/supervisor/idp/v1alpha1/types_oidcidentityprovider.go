@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -111,8 +111,29 @@ type OIDCAuthorizationConfig struct {
 	// allowPasswordGrant defaults to false.
 	// +optional
 	AllowPasswordGrant bool `json:"allowPasswordGrant,omitempty"`
+
+	// pushedAuthorizationRequests determines whether the Supervisor uses Pushed Authorization Requests
+	// (see https://datatracker.ietf.org/doc/html/rfc9126) in the browser-based OIDC Authorization Code Flow. When they
+	// are used, the Supervisor sends the authorization request parameters directly to the OIDC provider's
+	// pushed_authorization_request_endpoint, and then redirects the user's browser to the authorization endpoint with
+	// only the "client_id" and the "request_uri" returned by the OIDC provider. "Auto" uses them when the OIDC
+	// provider's discovery document advertises a pushed_authorization_request_endpoint. "Required" always uses them,
+	// and the OIDCIdentityProvider is not valid when the OIDC provider does not advertise the endpoint. "Disabled"
+	// never uses them. pushedAuthorizationRequests defaults to "Auto".
+	// +optional
+	PushedAuthorizationRequests OIDCPushedAuthorizationRequestsMode `json:"pushedAuthorizationRequests,omitempty"`
 }
 
+// OIDCPushedAuthorizationRequestsMode determines whether Pushed Authorization Requests are used.
+// +kubebuilder:validation:Enum=Auto;Required;Disabled
+type OIDCPushedAuthorizationRequestsMode string
+
+const (
+	OIDCPushedAuthorizationRequestsAuto     = OIDCPushedAuthorizationRequestsMode("Auto")
+	OIDCPushedAuthorizationRequestsRequired = OIDCPushedAuthorizationRequestsMode("Required")
+	OIDCPushedAuthorizationRequestsDisabled = OIDCPushedAuthorizationRequestsMode("Disabled")
+)
+
 // Parameter is a key/value pair which represents a parameter in an HTTP request.
 type Parameter struct {
 	// The name of the parameter. Required.
@@ -149,16 +170,148 @@ type OIDCClaims struct {
 	// are not available, the "additionalClaims" claim will be excluded from the ID tokens generated by the Supervisor.
 	// +optional
 	AdditionalClaimMappings map[string]string `json:"additionalClaimMappings,omitempty"`
+
+	// Expressions allows the username, groups, and additional claims to be computed from the upstream claims using
+	// CEL expressions, instead of being copied from a single upstream claim. When an expression is configured, it
+	// takes precedence over the corresponding Username, Groups, or AdditionalClaimMappings setting.
+	// +optional
+	Expressions *OIDCClaimExpressions `json:"expressions,omitempty"`
+
+	// MicrosoftGraphGroups, when set, resolves the group memberships of users of Azure AD (Microsoft Entra ID) using
+	// Microsoft Graph when the upstream tokens contain a groups overage claim instead of the "groups" claim.
+	// +optional
+	MicrosoftGraphGroups *OIDCMicrosoftGraphGroups `json:"microsoftGraphGroups,omitempty"`
 }
 
+// OIDCMicrosoftGraphGroups configures how group memberships are resolved using Microsoft Graph.
+// Azure AD replaces the "groups" claim with a groups overage claim when a user is a member of too many groups to fit
+// into a token. When this happens during login or refresh, the Supervisor gets a token for Microsoft Graph from the
+// token endpoint using the client credentials grant with the client credentials from the OIDCIdentityProvider's
+// Secret, fetches the IDs of all groups of which the user is a transitive member, and uses them as the "groups"
+// claim. These are the same group IDs which Azure AD puts into the "groups" claim by default. The client must be
+// granted the "User.Read.All" and "GroupMember.Read.All" application permissions for Microsoft Graph in Azure AD.
+// Note that the TLS configuration of the OIDCIdentityProvider is also used for requests to Microsoft Graph.
+type OIDCMicrosoftGraphGroups struct {
+	// URL is the base URL of Microsoft Graph, which may be changed to use a national cloud deployment of Microsoft
+	// Graph. Optional. When not specified, "https://graph.microsoft.com" is used.
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// OIDCClaimExpressions provides CEL expressions which compute identities from upstream claims.
+// Each expression is evaluated against a variable named "claims", which is a map of the claims from the
+// ID token merged with the claims from the userinfo endpoint response. For example,
+// `claims.email.split("@")[0]` or `claims.groups.filter(g, g.startsWith("k8s-"))`.
+// See https://github.com/google/cel-spec for the expression language.
+type OIDCClaimExpressions struct {
+	// Username is an expression which must evaluate to a non-empty string, which will be used as the username.
+	// When this expression is used, the "email_verified" claim is not checked.
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Groups is an expression which must evaluate to a list of strings or a single string, which will be used
+	// as the group memberships. Empty group names are ignored.
+	// +optional
+	Groups string `json:"groups,omitempty"`
+
+	// AdditionalClaims is a map of new claim names as the keys, and expressions as the values. Each expression may
+	// evaluate to any JSON value. The resulting claims are nested under the top-level "additionalClaims" claim in ID
+	// tokens generated by the Supervisor, in the same way as AdditionalClaimMappings. Claims whose expressions
+	// cannot be evaluated for a user are excluded.
+	// +optional
+	AdditionalClaims map[string]string `json:"additionalClaims,omitempty"`
+}
+
+// OIDCClientAuthenticationMethod is a method of client authentication at the token endpoint, as defined by
+// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication.
+// +kubebuilder:validation:Enum=client_secret_basic;client_secret_post;client_secret_jwt;private_key_jwt
+type OIDCClientAuthenticationMethod string
+
+const (
+	OIDCClientAuthenticationMethodClientSecretBasic = OIDCClientAuthenticationMethod("client_secret_basic")
+	OIDCClientAuthenticationMethodClientSecretPost  = OIDCClientAuthenticationMethod("client_secret_post")
+	OIDCClientAuthenticationMethodClientSecretJWT   = OIDCClientAuthenticationMethod("client_secret_jwt")
+	OIDCClientAuthenticationMethodPrivateKeyJWT     = OIDCClientAuthenticationMethod("private_key_jwt")
+)
+
 // OIDCClient contains information about an OIDC client (e.g., client ID and client
 // secret).
 type OIDCClient struct {
 	// SecretName contains the name of a namespace-local Secret object that provides the clientID and
 	// clientSecret for an OIDC client. If only the SecretName is specified in an OIDCClient
 	// struct, then it is expected that the Secret is of type "secrets.pinniped.dev/oidc-client" with keys
-	// "clientID" and "clientSecret".
+	// "clientID" and "clientSecret". When the AuthenticationMethod is "private_key_jwt", the Secret must
+	// instead have the keys "clientID" and "privateKey", where "privateKey" is a PEM-encoded RSA or ECDSA
+	// private key, and may optionally have the key "keyID", which is used as the "kid" header of the
+	// client assertions.
 	SecretName string `json:"secretName"`
+
+	// AuthenticationMethod determines how the Supervisor authenticates to the token endpoint and the revocation
+	// endpoint of the OIDC identity provider. "client_secret_basic" sends the client secret using HTTP basic auth,
+	// and "client_secret_post" sends it in the request body. "client_secret_jwt" sends a client assertion JWT
+	// signed with the client secret using HS256, and "private_key_jwt" sends a client assertion JWT signed with
+	// the private key from the Secret using RS256 or ES256. The audience of the client assertions is the URL of
+	// the token endpoint.
+	// Optional. When not specified, "client_secret_basic" is tried first, falling back to "client_secret_post"
+	// when the OIDC identity provider rejects it.
+	// +optional
+	AuthenticationMethod OIDCClientAuthenticationMethod `json:"authenticationMethod,omitempty"`
+}
+
+// OIDCIDTokenDecryption configures the decryption of ID tokens which are encrypted by the OIDC identity provider.
+type OIDCIDTokenDecryption struct {
+	// SecretName contains the name of a namespace-local Secret object of type
+	// "secrets.pinniped.dev/oidc-id-token-decryption-key" with the key "jwk", whose value is a JSON Web Key
+	// (RFC 7517) containing the private key with which ID tokens are decrypted, e.g. an RSA key for the "RSA-OAEP"
+	// key management algorithms or an EC key for the "ECDH-ES" key management algorithms. The corresponding public
+	// key must be registered with the OIDC identity provider as the client's encryption key. When the JSON Web Key
+	// has a "kid", it must match the "kid" header of the encrypted ID tokens.
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// OIDCProxy configures the HTTP proxy through which the Supervisor makes its requests to the OIDC identity provider.
+type OIDCProxy struct {
+	// URL is the URL of the proxy, e.g. "http://proxy.example.com:3128". The "http", "https", and "socks5" schemes
+	// are supported.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^(https?|socks5)://`
+	URL string `json:"url"`
+
+	// NoProxy is a list of hosts which are contacted directly rather than through the proxy. Each entry is a host
+	// name, a domain name with a leading ".", an IP address, or a CIDR range, optionally followed by a port, using
+	// the same syntax as each entry of the NO_PROXY environment variable.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// OIDCRetryPolicy configures the retries of requests to the token and revocation endpoints of the OIDC identity
+// provider which fail with a network error or with a retryable HTTP status code during refreshes and revocations.
+type OIDCRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of each request, including the first attempt.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	MaxAttempts int32 `json:"maxAttempts"`
+
+	// InitialBackoffMilliseconds is the number of milliseconds to wait before the first retry. Each further retry
+	// waits twice as long as the previous one, up to maxBackoffMilliseconds. Optional. When not specified, defaults
+	// to 250 milliseconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InitialBackoffMilliseconds int64 `json:"initialBackoffMilliseconds,omitempty"`
+
+	// MaxBackoffMilliseconds is the maximum number of milliseconds to wait before each retry. Optional. When not
+	// specified, defaults to 5000 milliseconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBackoffMilliseconds int64 `json:"maxBackoffMilliseconds,omitempty"`
+
+	// RetryableStatusCodes are the HTTP status codes of the responses after which a request is retried. Each must
+	// be between 400 and 599. Requests which fail without a response, e.g. because the connection could not be
+	// established, are always retried. Optional. When not specified, defaults to 429, 500, 502, 503, and 504.
+	// +optional
+	RetryableStatusCodes []int32 `json:"retryableStatusCodes,omitempty"`
 }
 
 // OIDCIdentityProviderSpec is the spec for configuring an OIDC identity provider.
@@ -186,6 +339,24 @@ type OIDCIdentityProviderSpec struct {
 	// OIDCClient contains OIDC client information to be used used with this OIDC identity
 	// provider.
 	Client OIDCClient `json:"client"`
+
+	// IDTokenDecryption configures the decryption of ID tokens, for OIDC identity providers which are configured
+	// to encrypt the ID tokens that they issue to this client using JWE. The decrypted ID tokens must be signed JWTs,
+	// which are validated as usual. ID tokens which are not encrypted are still accepted.
+	// +optional
+	IDTokenDecryption *OIDCIDTokenDecryption `json:"idTokenDecryption,omitempty"`
+
+	// Proxy configures the HTTP proxy for all requests to this OIDC identity provider, i.e. the discovery, JWKS,
+	// token, revocation, and userinfo requests. When specified, it is used instead of the proxy configured by the
+	// HTTPS_PROXY and NO_PROXY environment variables of the Supervisor pods.
+	// +optional
+	Proxy *OIDCProxy `json:"proxy,omitempty"`
+
+	// RetryPolicy configures the retries of failed requests to the token and revocation endpoints of this OIDC
+	// identity provider during refreshes and revocations, so that transient errors of the OIDC identity provider
+	// do not fail the refreshes of downstream sessions. Optional. When not specified, failed requests are not retried.
+	// +optional
+	RetryPolicy *OIDCRetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 // OIDCIdentityProvider describes the configuration of an upstream OpenID Connect identity provider.
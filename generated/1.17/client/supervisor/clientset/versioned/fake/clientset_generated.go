@@ -13,6 +13,10 @@ import (
 	fakeconfigv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/config/v1alpha1/fake"
 	idpv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/idp/v1alpha1"
 	fakeidpv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/idp/v1alpha1/fake"
+	logincheckv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/logincheck/v1alpha1"
+	fakelogincheckv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/logincheck/v1alpha1/fake"
+	sessionv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/session/v1alpha1"
+	fakesessionv1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/session/v1alpha1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -81,3 +85,13 @@ func (c *Clientset) ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface {
 func (c *Clientset) IDPV1alpha1() idpv1alpha1.IDPV1alpha1Interface {
 	return &fakeidpv1alpha1.FakeIDPV1alpha1{Fake: &c.Fake}
 }
+
+// LogincheckV1alpha1 retrieves the LogincheckV1alpha1Client
+func (c *Clientset) LogincheckV1alpha1() logincheckv1alpha1.LogincheckV1alpha1Interface {
+	return &fakelogincheckv1alpha1.FakeLogincheckV1alpha1{Fake: &c.Fake}
+}
+
+// SessionV1alpha1 retrieves the SessionV1alpha1Client
+func (c *Clientset) SessionV1alpha1() sessionv1alpha1.SessionV1alpha1Interface {
+	return &fakesessionv1alpha1.FakeSessionV1alpha1{Fake: &c.Fake}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -23,15 +23,32 @@ type JWTAuthenticatorSpec struct {
 	// +kubebuilder:validation:Pattern=`^https://`
 	Issuer string `json:"issuer"`
 
+	// IssuerAliases are other issuer URLs which are also accepted in the "iss" JWT claim, e.g. the
+	// previous issuer URL of an OIDC provider which has been migrated. When the discovery of the
+	// Issuer fails, then each alias is tried in order for discovering the public signing keys.
+	// +optional
+	IssuerAliases []string `json:"issuerAliases,omitempty"`
+
 	// Audience is the required value of the "aud" JWT claim.
 	// +kubebuilder:validation:MinLength=1
 	Audience string `json:"audience"`
 
+	// AdditionalAudiences are other values of the "aud" JWT claim which are also accepted. A JWT is
+	// accepted when its "aud" claim contains either the Audience or any of the AdditionalAudiences.
+	// +optional
+	AdditionalAudiences []string `json:"additionalAudiences,omitempty"`
+
 	// Claims allows customization of the claims that will be mapped to user identity
 	// for Kubernetes access.
 	// +optional
 	Claims JWTTokenClaims `json:"claims"`
 
+	// ClaimMappings allows the username and groups to be computed from the claims of the JWT using CEL
+	// expressions, e.g. to add a prefix to the username or to read the groups from a nested claim. When an
+	// expression is configured, it takes precedence over the corresponding setting in Claims.
+	// +optional
+	ClaimMappings *JWTClaimMappings `json:"claimMappings,omitempty"`
+
 	// TLS configuration for communicating with the OIDC provider.
 	// +optional
 	TLS *TLSSpec `json:"tls,omitempty"`
@@ -51,6 +68,21 @@ type JWTTokenClaims struct {
 	Username string `json:"username"`
 }
 
+// JWTClaimMappings provides CEL expressions which compute the user's identity from the claims of a JWT.
+// Each expression is evaluated against a variable named "claims", which is a map of the claims of the
+// JWT. For example, `"corp:" + claims.email` or `claims.realm_access.roles.filter(r, r.startsWith("k8s-"))`.
+// See https://github.com/google/cel-spec for the expression language.
+type JWTClaimMappings struct {
+	// Username is an expression which must evaluate to a non-empty string, which will be used as the username.
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Groups is an expression which must evaluate to a list of strings or a single string, which will be used
+	// as the group memberships. Empty group names are ignored.
+	// +optional
+	Groups string `json:"groups,omitempty"`
+}
+
 // JWTAuthenticator describes the configuration of a JWT authenticator.
 //
 // Upon receiving a signed JWT, a JWTAuthenticator will performs some validation on it (e.g., valid
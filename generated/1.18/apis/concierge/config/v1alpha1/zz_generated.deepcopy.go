@@ -12,6 +12,90 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSigningRequestsSpec) DeepCopyInto(out *CertificateSigningRequestsSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSigningRequestsSpec.
+func (in *CertificateSigningRequestsSpec) DeepCopy() *CertificateSigningRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSigningRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificatesSpec) DeepCopyInto(out *ClientCertificatesSpec) {
+	*out = *in
+	if in.TTLSeconds != nil {
+		in, out := &in.TTLSeconds, &out.TTLSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RefreshMarginSeconds != nil {
+		in, out := &in.RefreshMarginSeconds, &out.RefreshMarginSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificatesSpec.
+func (in *ClientCertificatesSpec) DeepCopy() *ClientCertificatesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificatesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialIssuancePolicySpec) DeepCopyInto(out *CredentialIssuancePolicySpec) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(CredentialIssuancePolicyWebhookSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialIssuancePolicySpec.
+func (in *CredentialIssuancePolicySpec) DeepCopy() *CredentialIssuancePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialIssuancePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialIssuancePolicyWebhookSpec) DeepCopyInto(out *CredentialIssuancePolicyWebhookSpec) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialIssuancePolicyWebhookSpec.
+func (in *CredentialIssuancePolicyWebhookSpec) DeepCopy() *CredentialIssuancePolicyWebhookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialIssuancePolicyWebhookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CredentialIssuer) DeepCopyInto(out *CredentialIssuer) {
 	*out = *in
@@ -123,6 +207,21 @@ func (in *CredentialIssuerSpec) DeepCopyInto(out *CredentialIssuerSpec) {
 		*out = new(ImpersonationProxySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ClientCertificates != nil {
+		in, out := &in.ClientCertificates, &out.ClientCertificates
+		*out = new(ClientCertificatesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateSigningRequests != nil {
+		in, out := &in.CertificateSigningRequests, &out.CertificateSigningRequests
+		*out = new(CertificateSigningRequestsSpec)
+		**out = **in
+	}
+	if in.CredentialIssuancePolicy != nil {
+		in, out := &in.CredentialIssuancePolicy, &out.CredentialIssuancePolicy
+		*out = new(CredentialIssuancePolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -186,6 +285,32 @@ func (in *CredentialIssuerStrategy) DeepCopy() *CredentialIssuerStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpersonationProxyCertificateRotationSpec) DeepCopyInto(out *ImpersonationProxyCertificateRotationSpec) {
+	*out = *in
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OverlapSeconds != nil {
+		in, out := &in.OverlapSeconds, &out.OverlapSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpersonationProxyCertificateRotationSpec.
+func (in *ImpersonationProxyCertificateRotationSpec) DeepCopy() *ImpersonationProxyCertificateRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonationProxyCertificateRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImpersonationProxyInfo) DeepCopyInto(out *ImpersonationProxyInfo) {
 	*out = *in
@@ -229,6 +354,21 @@ func (in *ImpersonationProxyServiceSpec) DeepCopy() *ImpersonationProxyServiceSp
 func (in *ImpersonationProxySpec) DeepCopyInto(out *ImpersonationProxySpec) {
 	*out = *in
 	in.Service.DeepCopyInto(&out.Service)
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(ProxyProtocolSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ImpersonationProxyTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateRotation != nil {
+		in, out := &in.CertificateRotation, &out.CertificateRotation
+		*out = new(ImpersonationProxyCertificateRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -242,6 +382,48 @@ func (in *ImpersonationProxySpec) DeepCopy() *ImpersonationProxySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpersonationProxyTLSSpec) DeepCopyInto(out *ImpersonationProxyTLSSpec) {
+	*out = *in
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpersonationProxyTLSSpec.
+func (in *ImpersonationProxyTLSSpec) DeepCopy() *ImpersonationProxyTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonationProxyTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyProtocolSpec) DeepCopyInto(out *ProxyProtocolSpec) {
+	*out = *in
+	if in.TrustedCIDRs != nil {
+		in, out := &in.TrustedCIDRs, &out.TrustedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyProtocolSpec.
+func (in *ProxyProtocolSpec) DeepCopy() *ProxyProtocolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyProtocolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TokenCredentialRequestAPIInfo) DeepCopyInto(out *TokenCredentialRequestAPIInfo) {
 	*out = *in
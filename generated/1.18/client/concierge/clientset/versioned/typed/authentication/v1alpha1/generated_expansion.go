@@ -7,4 +7,8 @@ package v1alpha1
 
 type JWTAuthenticatorExpansion interface{}
 
+type ServiceAccountTokenAuthenticatorExpansion interface{}
+
 type WebhookAuthenticatorExpansion interface{}
+
+type X509ClientCertificateAuthenticatorExpansion interface{}
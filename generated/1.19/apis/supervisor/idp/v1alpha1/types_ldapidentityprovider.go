@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -20,6 +20,51 @@ const (
 	LDAPPhaseError LDAPIdentityProviderPhase = "Error"
 )
 
+// LDAPSearchScope is the scope of an LDAP search.
+// +kubebuilder:validation:Enum=WholeSubtree;SingleLevel;BaseObject
+type LDAPSearchScope string
+
+const (
+	// LDAPSearchScopeWholeSubtree searches the base entry and all of its descendants.
+	LDAPSearchScopeWholeSubtree LDAPSearchScope = "WholeSubtree"
+
+	// LDAPSearchScopeSingleLevel searches only the immediate children of the base entry.
+	LDAPSearchScopeSingleLevel LDAPSearchScope = "SingleLevel"
+
+	// LDAPSearchScopeBaseObject searches only the base entry itself.
+	LDAPSearchScopeBaseObject LDAPSearchScope = "BaseObject"
+)
+
+// LDAPDerefAliases determines how aliases are dereferenced during an LDAP search.
+// +kubebuilder:validation:Enum=Never;InSearching;FindingBaseObject;Always
+type LDAPDerefAliases string
+
+const (
+	// LDAPDerefAliasesNever does not dereference aliases.
+	LDAPDerefAliasesNever LDAPDerefAliases = "Never"
+
+	// LDAPDerefAliasesInSearching dereferences aliases which are found while searching below the base entry.
+	LDAPDerefAliasesInSearching LDAPDerefAliases = "InSearching"
+
+	// LDAPDerefAliasesFindingBaseObject dereferences aliases only when locating the base entry.
+	LDAPDerefAliasesFindingBaseObject LDAPDerefAliases = "FindingBaseObject"
+
+	// LDAPDerefAliasesAlways dereferences aliases both when locating the base entry and while searching.
+	LDAPDerefAliasesAlways LDAPDerefAliases = "Always"
+)
+
+// ReferralCredentialPolicy determines which credentials are used when following referrals.
+// +kubebuilder:validation:Enum=BindAccount;Anonymous
+type ReferralCredentialPolicy string
+
+const (
+	// ReferralCredentialPolicyBindAccount binds to referred servers using the configured bind account.
+	ReferralCredentialPolicyBindAccount ReferralCredentialPolicy = "BindAccount"
+
+	// ReferralCredentialPolicyAnonymous searches referred servers without binding.
+	ReferralCredentialPolicyAnonymous ReferralCredentialPolicy = "Anonymous"
+)
+
 // Status of an LDAP identity provider.
 type LDAPIdentityProviderStatus struct {
 	// Phase summarizes the overall status of the LDAPIdentityProvider.
@@ -40,11 +85,45 @@ type LDAPIdentityProviderBind struct {
 	// password for an LDAP bind user. This account will be used to perform LDAP searches. The Secret should be
 	// of type "kubernetes.io/basic-auth" which includes "username" and "password" keys. The username value
 	// should be the full dn (distinguished name) of your bind account, e.g. "cn=bind-account,ou=users,dc=example,dc=com".
-	// The password must be non-empty.
+	// The password must be non-empty. The Secret may optionally also include "username2" and "password2" keys,
+	// which provide secondary bind credentials that will be used whenever a bind using the primary credentials fails.
+	// This allows the bind credentials to be rotated without interrupting logins.
+	// Required unless UserDNTemplate is specified.
 	// +kubebuilder:validation:MinLength=1
-	SecretName string `json:"secretName"`
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// UserDNTemplate configures logins to bind directly as the end user, instead of first searching for the user's
+	// entry using the bind user. The placeholder "{username}" is replaced by the username which the end user typed
+	// when they tried to log in, e.g. "uid={username},ou=users,dc=example,dc=com". After binding as the end user,
+	// the user's entry is read and their groups are searched as the end user, so the end user must be allowed to
+	// read their own entry and to perform the group search. The userSearch base and filter are ignored.
+	// When SecretName is not specified, sessions cannot be checked against the LDAP server when they are refreshed,
+	// so the groups from the initial login are kept for the life of the session and additional claims are
+	// removed at the first refresh. Optional.
+	// +optional
+	UserDNTemplate string `json:"userDNTemplate,omitempty"`
+
+	// ClientCertificateSecretName contains the name of a namespace-local Secret object that provides a client
+	// certificate and private key, for LDAP servers which require a client certificate in addition to the bind
+	// username and password. The client certificate will be presented to the LDAP server during the TLS handshake
+	// of every connection. The Secret should be of type "kubernetes.io/tls" which includes "tls.crt" and "tls.key"
+	// keys. Optional.
+	// +optional
+	ClientCertificateSecretName string `json:"clientCertificateSecretName,omitempty"`
 }
 
+// LDAPBinaryAttributeDecoder determines how the value of a binary attribute is converted into a string.
+// +kubebuilder:validation:Enum=UUID;UUIDLittleEndian;Base64;Hex
+type LDAPBinaryAttributeDecoder string
+
+const (
+	LDAPBinaryAttributeDecoderUUID             = LDAPBinaryAttributeDecoder("UUID")
+	LDAPBinaryAttributeDecoderUUIDLittleEndian = LDAPBinaryAttributeDecoder("UUIDLittleEndian")
+	LDAPBinaryAttributeDecoderBase64           = LDAPBinaryAttributeDecoder("Base64")
+	LDAPBinaryAttributeDecoderHex              = LDAPBinaryAttributeDecoder("Hex")
+)
+
 type LDAPIdentityProviderUserSearchAttributes struct {
 	// Username specifies the name of the attribute in the LDAP entry whose value shall become the username
 	// of the user after a successful authentication. This would typically be the same attribute name used in
@@ -62,6 +141,15 @@ type LDAPIdentityProviderUserSearchAttributes struct {
 	// server in the user's entry. Distinguished names can be used by specifying lower-case "dn".
 	// +kubebuilder:validation:MinLength=1
 	UID string `json:"uid,omitempty"`
+
+	// UIDBinaryDecoder declares that the UID attribute has a binary value, and determines how it is converted into
+	// the user's UID. "UUID" formats a 16 byte value as a UUID, "UUIDLittleEndian" formats a 16 byte value as a UUID
+	// whose first three fields are stored in little-endian byte order (like the objectGUID attribute of Active
+	// Directory), "Base64" encodes the value using standard base64, and "Hex" encodes the value using lowercase
+	// hexadecimal. It is not used when the UID is "dn". Changing this setting changes the UIDs, and therefore the
+	// identities, of all users. Optional. When not specified, the UID attribute's value is used without decoding.
+	// +optional
+	UIDBinaryDecoder LDAPBinaryAttributeDecoder `json:"uidBinaryDecoder,omitempty"`
 }
 
 type LDAPIdentityProviderGroupSearchAttributes struct {
@@ -72,6 +160,13 @@ type LDAPIdentityProviderGroupSearchAttributes struct {
 	// Optional. When not specified, the default will act as if the GroupName were specified as "dn" (distinguished name).
 	// +optional
 	GroupName string `json:"groupName,omitempty"`
+
+	// GroupNameBinaryDecoder declares that the GroupName attribute has a binary value, and determines how it is
+	// converted into the group name. The decoders are the same as for the UIDBinaryDecoder of the user search.
+	// It is not used when the GroupName is "dn". Optional. When not specified, the GroupName attribute's value is
+	// used without decoding.
+	// +optional
+	GroupNameBinaryDecoder LDAPBinaryAttributeDecoder `json:"groupNameBinaryDecoder,omitempty"`
 }
 
 type LDAPIdentityProviderUserSearch struct {
@@ -95,6 +190,19 @@ type LDAPIdentityProviderUserSearch struct {
 	// the result of the user search.
 	// +optional
 	Attributes LDAPIdentityProviderUserSearchAttributes `json:"attributes,omitempty"`
+
+	// Scope is the scope of the user search. "WholeSubtree" searches the Base entry and all of its descendants,
+	// "SingleLevel" searches only the immediate children of the Base entry, and "BaseObject" searches only the
+	// Base entry itself. Optional. When not specified, defaults to "WholeSubtree".
+	// +optional
+	Scope LDAPSearchScope `json:"scope,omitempty"`
+
+	// DerefAliases determines how alias entries are dereferenced by the LDAP server during the user search.
+	// "Never" does not dereference aliases, "InSearching" dereferences aliases which are found while searching
+	// below the Base entry, "FindingBaseObject" dereferences the Base entry only, and "Always" does both.
+	// Optional. When not specified, defaults to "Never".
+	// +optional
+	DerefAliases LDAPDerefAliases `json:"derefAliases,omitempty"`
 }
 
 type LDAPIdentityProviderGroupSearch struct {
@@ -115,6 +223,17 @@ type LDAPIdentityProviderGroupSearch struct {
 	// +optional
 	Filter string `json:"filter,omitempty"`
 
+	// UserAttributeForFilter specifies which attribute's value from the user entry found as a result of
+	// the user search will be used to replace the "{}" placeholder(s) in the group search Filter.
+	// For example, specifying "uid" as the UserAttributeForFilter while specifying
+	// "&(objectClass=posixGroup)(memberUid={})" as the Filter would search for groups by replacing
+	// the "{}" placeholder in the Filter with the value of the user's "uid" attribute.
+	// Optional. When not specified, the default will act as if "dn" were specified. For example, leaving
+	// UserAttributeForFilter unspecified while specifying "&(objectClass=groupOfNames)(member={})" as the Filter
+	// would search for groups by replacing the "{}" placeholder(s) with the dn (distinguished name) of the user.
+	// +optional
+	UserAttributeForFilter string `json:"userAttributeForFilter,omitempty"`
+
 	// Attributes specifies how the group's information should be read from each LDAP entry which was found as
 	// the result of the group search.
 	// +optional
@@ -144,6 +263,199 @@ type LDAPIdentityProviderGroupSearch struct {
 	// release notes before upgrading to ensure that the meaning of this field has
 	// not changed.
 	SkipGroupRefresh bool `json:"skipGroupRefresh,omitempty"`
+
+	// GroupRefreshIntervalSeconds is the minimum number of seconds between searches for the user's group memberships
+	// during refreshes of the user's session. The groups found at login, or by the most recent group search, are kept
+	// until the interval has elapsed. This reduces the load of group searches on the LDAP server while still keeping
+	// group memberships reasonably current, as a less drastic alternative to skipGroupRefresh.
+	// Optional. When not specified, the groups are searched again on every refresh.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	GroupRefreshIntervalSeconds int64 `json:"groupRefreshIntervalSeconds,omitempty"`
+
+	// PageSize is the maximum number of group entries to request from the LDAP server in each page of results
+	// when searching for a user's group memberships. The group search uses the paged results control (RFC 2696)
+	// and all pages are combined into the user's list of groups. Smaller pages may be needed when the LDAP server
+	// enforces a size limit which is lower than the number of groups that a user may belong to.
+	// Optional. When not specified, defaults to 250.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PageSize int32 `json:"pageSize,omitempty"`
+
+	// Scope is the scope of the group search. "WholeSubtree" searches the Base entry and all of its descendants,
+	// "SingleLevel" searches only the immediate children of the Base entry, and "BaseObject" searches only the
+	// Base entry itself. Optional. When not specified, defaults to "WholeSubtree".
+	// +optional
+	Scope LDAPSearchScope `json:"scope,omitempty"`
+
+	// DerefAliases determines how alias entries are dereferenced by the LDAP server during the group search.
+	// "Never" does not dereference aliases, "InSearching" dereferences aliases which are found while searching
+	// below the Base entry, "FindingBaseObject" dereferences the Base entry only, and "Always" does both.
+	// Optional. When not specified, defaults to "Never".
+	// +optional
+	DerefAliases LDAPDerefAliases `json:"derefAliases,omitempty"`
+
+	// GroupNameTransformations is a list of transformations which are applied, in order, to each group name found by
+	// the group search before the groups are used in the user's session. Transformations allow the group names
+	// to stay stable, e.g. for use in Kubernetes RBAC bindings, even when they are formatted differently by the LDAP
+	// server. Group names which become empty after the transformations are removed, as are duplicates.
+	// Optional. When not specified, group names are used as they were found.
+	// +optional
+	GroupNameTransformations []GroupNameTransformation `json:"groupNameTransformations,omitempty"`
+}
+
+// GroupNameTransformationType is the type of a transformation which is applied to group names.
+// +kubebuilder:validation:Enum=Prefix;StripDNComponents;Lowercase;RegexReplace
+type GroupNameTransformationType string
+
+const (
+	// GroupNameTransformationTypePrefix adds the Prefix to the start of each group name.
+	GroupNameTransformationTypePrefix GroupNameTransformationType = "Prefix"
+
+	// GroupNameTransformationTypeStripDNComponents replaces each group name which is a dn (distinguished name)
+	// with the value of its first relative dn, e.g. "cn=admins,ou=groups,dc=example,dc=com" becomes "admins".
+	// Group names which are not a dn are left unchanged.
+	GroupNameTransformationTypeStripDNComponents GroupNameTransformationType = "StripDNComponents"
+
+	// GroupNameTransformationTypeLowercase converts each group name to lowercase.
+	GroupNameTransformationTypeLowercase GroupNameTransformationType = "Lowercase"
+
+	// GroupNameTransformationTypeRegexReplace replaces all matches of the Regex in each group name with the Replacement.
+	GroupNameTransformationTypeRegexReplace GroupNameTransformationType = "RegexReplace"
+)
+
+// GroupNameTransformation describes a transformation which is applied to each group name found by the group search.
+type GroupNameTransformation struct {
+	// Type is the type of the transformation.
+	// +kubebuilder:validation:Required
+	Type GroupNameTransformationType `json:"type"`
+
+	// Prefix is added to the start of each group name. Required when Type is "Prefix", and not allowed otherwise.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Regex is a regular expression using RE2 syntax (https://github.com/google/re2/wiki/Syntax). Required when
+	// Type is "RegexReplace", and not allowed otherwise.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Replacement replaces each match of the Regex. It may refer to capture groups of the Regex, e.g. "${1}".
+	// Only allowed when Type is "RegexReplace". When not specified, matches are removed from the group name.
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// LDAPIdentityProviderReferrals provides configuration for following referrals which are returned by the LDAP server.
+type LDAPIdentityProviderReferrals struct {
+	// Follow enables following the referrals (including search result references) which are returned by the
+	// LDAP server during user and group searches. When false, referrals are ignored. Optional.
+	// +optional
+	Follow bool `json:"follow,omitempty"`
+
+	// MaxHops is the maximum length of a chain of referrals which will be followed. For example, a value of 1 will
+	// follow referrals returned by the configured LDAP server, but will not follow any further referrals returned
+	// by the referred servers. Optional. When not specified, defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	MaxHops int32 `json:"maxHops,omitempty"`
+
+	// CredentialPolicy determines which credentials are used to perform searches on referred servers.
+	// "BindAccount" binds using the same bind account that is configured by spec.bind.secretName, which requires
+	// the referred servers to accept those credentials. "Anonymous" does not bind before searching the referred
+	// servers. Regardless of this setting, an end user's password is always verified by binding to the server on
+	// which their user entry was found. Optional. When not specified, defaults to "BindAccount".
+	// +optional
+	CredentialPolicy ReferralCredentialPolicy `json:"credentialPolicy,omitempty"`
+}
+
+// LDAPIdentityProviderAttributeMappings provides configuration for mapping attributes of the user's LDAP entry
+// into the identities of users who log in using this identity provider.
+type LDAPIdentityProviderAttributeMappings struct {
+	// AdditionalClaims allows for the values of additional attributes of the user's LDAP entry to be mapped into the
+	// "additionalClaims" claim of the ID tokens generated by the Supervisor. This should be specified as a map of
+	// new claim names as the keys, and LDAP attribute names as the values, for example: mail, employeeID, or department.
+	// The attribute name "dn" may be used to map the user's distinguished name. These new claim names will be nested
+	// under the top-level "additionalClaims" claim in ID tokens generated by the Supervisor when this
+	// LDAPIdentityProvider was used for user authentication. These claims will be made available to all clients.
+	// Attributes with a single value become string claims, and attributes with multiple values become string array
+	// claims. The attribute values are fetched when the user logs in, and fetched again each time that their session
+	// is refreshed. Attributes which are not present on the user's entry are excluded from the "additionalClaims" claim.
+	// When this map is empty, the "additionalClaims" claim will be excluded from the ID tokens generated by the Supervisor.
+	// +optional
+	AdditionalClaims map[string]string `json:"additionalClaims,omitempty"`
+}
+
+// LDAPAttributeAssertionOperator is the operator of an LDAPIdentityProviderAttributeAssertion.
+// +kubebuilder:validation:Enum=Equals;NotEquals;Absent;DaysSinceEpochInFuture;GeneralizedTimeInFuture
+type LDAPAttributeAssertionOperator string
+
+const (
+	LDAPAttributeAssertionOperatorEquals                  = LDAPAttributeAssertionOperator("Equals")
+	LDAPAttributeAssertionOperatorNotEquals               = LDAPAttributeAssertionOperator("NotEquals")
+	LDAPAttributeAssertionOperatorAbsent                  = LDAPAttributeAssertionOperator("Absent")
+	LDAPAttributeAssertionOperatorDaysSinceEpochInFuture  = LDAPAttributeAssertionOperator("DaysSinceEpochInFuture")
+	LDAPAttributeAssertionOperatorGeneralizedTimeInFuture = LDAPAttributeAssertionOperator("GeneralizedTimeInFuture")
+)
+
+// LDAPIdentityProviderAttributeAssertion is an assertion about an attribute of the user's LDAP entry which must hold
+// for the user's session to be refreshed.
+type LDAPIdentityProviderAttributeAssertion struct {
+	// Attribute is the name of the attribute of the user's LDAP entry, for example: nsAccountLock or shadowExpire.
+	// +kubebuilder:validation:MinLength=1
+	Attribute string `json:"attribute"`
+
+	// Operator determines how the attribute is checked. "Equals" requires one of the attribute's values to equal the
+	// Value, and "NotEquals" requires none of the attribute's values to equal the Value. Values are compared
+	// case-insensitively. "Absent" requires the attribute to have no values. "DaysSinceEpochInFuture" requires the
+	// attribute's value, a number of days since January 1, 1970 like shadowExpire, to be after the current day.
+	// "GeneralizedTimeInFuture" requires the attribute's value, an LDAP GeneralizedTime, to be after the current time.
+	// The last two operators also pass when the attribute has no value, or when a number of days is not positive,
+	// since these mean that the account never expires.
+	Operator LDAPAttributeAssertionOperator `json:"operator"`
+
+	// Value is the value which is compared by the "Equals" and "NotEquals" operators, for example: true.
+	// It is not used by the other operators.
+	// +optional
+	Value string `json:"value,omitempty"`
+}
+
+// LDAPIdentityProviderRefreshChecks provides configuration for the checks which are performed on the user's LDAP entry
+// when their session is refreshed. When any check fails, the refresh is rejected and the user must log in again.
+type LDAPIdentityProviderRefreshChecks struct {
+	// AttributeAssertions is a list of assertions about attributes of the user's LDAP entry, which can be used to
+	// reject the refreshes of users whose accounts have been locked or have expired, for example: nsAccountLock
+	// NotEquals true, or shadowExpire DaysSinceEpochInFuture. All of the assertions must hold. Optional.
+	// +optional
+	AttributeAssertions []LDAPIdentityProviderAttributeAssertion `json:"attributeAssertions,omitempty"`
+}
+
+// AuthenticationRateLimit is a limit on the rate of authentication attempts. Attempts beyond the limit are
+// rejected without contacting the server.
+type AuthenticationRateLimit struct {
+	// AttemptsPerMinute is the sustained number of authentication attempts which are allowed per minute.
+	// +kubebuilder:validation:Minimum=1
+	AttemptsPerMinute int32 `json:"attemptsPerMinute"`
+
+	// Burst is the number of authentication attempts which are allowed in quick succession before the
+	// AttemptsPerMinute limit applies. Optional. When not specified, defaults to the value of AttemptsPerMinute.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// LDAPIdentityProviderAuthenticationRateLimits provides configuration for limiting the rate of authentication attempts
+// using this identity provider, to protect the accounts of the LDAP users from being locked out by brute force attacks.
+type LDAPIdentityProviderAuthenticationRateLimits struct {
+	// PerUsername limits the rate of authentication attempts for each username. Optional. When not specified,
+	// the rate of authentication attempts for each username is not limited.
+	// +optional
+	PerUsername *AuthenticationRateLimit `json:"perUsername,omitempty"`
+
+	// PerClientIP limits the rate of authentication attempts from each client IP address. Optional. When not specified,
+	// the rate of authentication attempts from each client IP address is not limited.
+	// +optional
+	PerClientIP *AuthenticationRateLimit `json:"perClientIP,omitempty"`
 }
 
 // Spec for configuring an LDAP identity provider.
@@ -152,9 +464,22 @@ type LDAPIdentityProviderSpec struct {
 	// +kubebuilder:validation:MinLength=1
 	Host string `json:"host"`
 
+	// Hosts is an optional list of additional hostnames of replicas of this LDAP identity provider, for example
+	// ldap-replica.example.com:636. When the Host cannot be reached, each of these will be tried in the order listed.
+	// Hosts which recently could not be reached are tried only after the other hosts.
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+
 	// TLS contains the connection settings for how to establish the connection to the Host.
 	TLS *TLSSpec `json:"tls,omitempty"`
 
+	// ProxyURL is the URL of a proxy through which all connections to the LDAP server should be made, for example
+	// http://proxy.example.com:3128 for an HTTP proxy which supports the CONNECT method, or socks5://proxy.example.com:1080
+	// for a SOCKS5 proxy. The schemes http, socks5, and socks5h are supported. Credentials for the proxy may be included
+	// in the URL. Optional. When not specified, connections are made directly to the LDAP server.
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+
 	// Bind contains the configuration for how to provide access credentials during an initial bind to the LDAP server
 	// to be allowed to perform searches and binds to validate a user's credentials during a user's authentication attempt.
 	Bind LDAPIdentityProviderBind `json:"bind,omitempty"`
@@ -164,6 +489,45 @@ type LDAPIdentityProviderSpec struct {
 
 	// GroupSearch contains the configuration for searching for a user's group membership in the LDAP provider.
 	GroupSearch LDAPIdentityProviderGroupSearch `json:"groupSearch,omitempty"`
+
+	// DialTimeoutSeconds is the maximum number of seconds to wait while establishing a network connection to the
+	// LDAP server. Optional. When not specified, defaults to 60 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DialTimeoutSeconds int64 `json:"dialTimeoutSeconds,omitempty"`
+
+	// SearchTimeoutSeconds is the time limit, in seconds, which will be requested of the LDAP server for each
+	// search operation performed during logins and refreshes. Optional. When not specified, defaults to 90 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SearchTimeoutSeconds int64 `json:"searchTimeoutSeconds,omitempty"`
+
+	// ConnectionRevalidationIntervalSeconds is how often, in seconds, the connection to the LDAP server is validated
+	// again, so that the LDAPConnectionValid condition and the phase reflect the current availability of the server.
+	// Optional. When not specified, the connection is only validated again when this spec or the bind Secret changes.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ConnectionRevalidationIntervalSeconds int64 `json:"connectionRevalidationIntervalSeconds,omitempty"`
+
+	// Referrals contains the configuration for following referrals which are returned by the LDAP server during
+	// searches, e.g. when the directory is split into multiple partitions which are served by different servers.
+	// +optional
+	Referrals LDAPIdentityProviderReferrals `json:"referrals,omitempty"`
+
+	// AttributeMappings contains the configuration for mapping attributes of the user's LDAP entry into the
+	// identities of users who log in using this identity provider.
+	// +optional
+	AttributeMappings LDAPIdentityProviderAttributeMappings `json:"attributeMappings,omitempty"`
+
+	// RefreshChecks contains the configuration for the checks which are performed on the user's LDAP entry when
+	// their session is refreshed.
+	// +optional
+	RefreshChecks LDAPIdentityProviderRefreshChecks `json:"refreshChecks,omitempty"`
+
+	// AuthenticationRateLimits contains the configuration for limiting the rate of authentication attempts using
+	// this identity provider. Optional. When not specified, the rate of authentication attempts is not limited.
+	// +optional
+	AuthenticationRateLimits LDAPIdentityProviderAuthenticationRateLimits `json:"authenticationRateLimits,omitempty"`
 }
 
 // LDAPIdentityProvider describes the configuration of an upstream Lightweight Directory Access
@@ -0,0 +1,75 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LDAPLoginCheckRequest can be used to check whether a user can log in using an LDAPIdentityProvider or an
+// ActiveDirectoryIdentityProvider, without starting a session for the user. The user search, the bind as the user,
+// and the group search are performed just like during a login.
+// +genclient
+// +genclient:onlyVerbs=create
+// +kubebuilder:subresource:status
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type LDAPLoginCheckRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LDAPLoginCheckRequestSpec `json:"spec"`
+
+	// +optional
+	Status LDAPLoginCheckRequestStatus `json:"status"`
+}
+
+// Spec of the LDAPLoginCheckRequest.
+type LDAPLoginCheckRequestSpec struct {
+	// The identity provider using which to check the login.
+	IdentityProvider LDAPLoginCheckIdentityProvider `json:"identityProvider"`
+
+	// The username of the user whose login should be checked.
+	Username string `json:"username"`
+
+	// The password of the user whose login should be checked. This is never returned.
+	Password string `json:"password,omitempty"`
+}
+
+// LDAPLoginCheckIdentityProvider refers to an identity provider in the same namespace.
+type LDAPLoginCheckIdentityProvider struct {
+	// Kind of the identity provider, either LDAPIdentityProvider or ActiveDirectoryIdentityProvider.
+	Kind string `json:"kind"`
+
+	// Name of the identity provider.
+	Name string `json:"name"`
+}
+
+// Status of the LDAPLoginCheckRequest.
+type LDAPLoginCheckRequestStatus struct {
+	// Whether the user was found and their password was accepted.
+	Authenticated bool `json:"authenticated"`
+
+	// A human-readable description of the result of the check.
+	Message string `json:"message,omitempty"`
+
+	// The username of the user as it would appear in the ID tokens issued by the Supervisor, when authenticated.
+	Username string `json:"username,omitempty"`
+
+	// The distinguished name of the user's entry, when authenticated.
+	DN string `json:"dn,omitempty"`
+
+	// The groups of the user as they would appear in the ID tokens issued by the Supervisor, when authenticated.
+	// +listType=atomic
+	Groups []string `json:"groups,omitempty"`
+}
+
+// LDAPLoginCheckRequestList is a list of LDAPLoginCheckRequest objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type LDAPLoginCheckRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of LDAPLoginCheckRequest.
+	Items []LDAPLoginCheckRequest `json:"items"`
+}
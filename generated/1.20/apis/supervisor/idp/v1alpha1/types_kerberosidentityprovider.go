@@ -0,0 +1,146 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type KerberosIdentityProviderPhase string
+
+const (
+	// KerberosPhasePending is the default phase for newly-created KerberosIdentityProvider resources.
+	KerberosPhasePending KerberosIdentityProviderPhase = "Pending"
+
+	// KerberosPhaseReady is the phase for a KerberosIdentityProvider resource in a healthy state.
+	KerberosPhaseReady KerberosIdentityProviderPhase = "Ready"
+
+	// KerberosPhaseError is the phase for a KerberosIdentityProvider in an unhealthy state.
+	KerberosPhaseError KerberosIdentityProviderPhase = "Error"
+)
+
+// KerberosUsernameFormat determines how the authenticated Kerberos principal is mapped to a username.
+type KerberosUsernameFormat string
+
+const (
+	// KerberosUsernamePrincipal specifies that the full principal name including the realm should be used as the
+	// username, e.g. "alice@EXAMPLE.COM".
+	KerberosUsernamePrincipal KerberosUsernameFormat = "principal"
+
+	// KerberosUsernameShortName specifies that the principal name without the realm should be used as the
+	// username, e.g. "alice".
+	KerberosUsernameShortName KerberosUsernameFormat = "shortName"
+)
+
+// KerberosIdentityProviderStatus is the status of a Kerberos identity provider.
+type KerberosIdentityProviderStatus struct {
+	// Phase summarizes the overall status of the KerberosIdentityProvider.
+	// +kubebuilder:default=Pending
+	// +kubebuilder:validation:Enum=Pending;Ready;Error
+	Phase KerberosIdentityProviderPhase `json:"phase,omitempty"`
+
+	// Represents the observations of an identity provider's current state.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// KerberosKeytabSpec identifies the keytab of the Supervisor's Kerberos service principal.
+type KerberosKeytabSpec struct {
+	// SecretName contains the name of a namespace-local Secret object that provides the keytab of the
+	// service principal. The Secret is expected to be of type "secrets.pinniped.dev/kerberos-keytab" with
+	// the key "keytab".
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// KerberosClaims allows customization of the username.
+type KerberosClaims struct {
+	// Username configures how the authenticated Kerberos principal shall determine the username in Kubernetes.
+	// Can be either "principal" or "shortName". Defaults to "principal".
+	// The "principal" option includes the realm, e.g. "alice@EXAMPLE.COM". The "shortName" option omits the
+	// realm, e.g. "alice", which is only unique when users may log in from a single realm.
+	// +kubebuilder:default=principal
+	// +kubebuilder:validation:Enum=principal;shortName
+	// +optional
+	Username KerberosUsernameFormat `json:"username,omitempty"`
+}
+
+// KerberosGroupSearch refers to an LDAPIdentityProvider or ActiveDirectoryIdentityProvider whose user and
+// group searches are used to find the groups of the users who log in using Kerberos.
+type KerberosGroupSearch struct {
+	// IdentityProviderKind is the kind of the identity provider. Can be either "LDAPIdentityProvider" or
+	// "ActiveDirectoryIdentityProvider". Defaults to "LDAPIdentityProvider".
+	// +kubebuilder:default=LDAPIdentityProvider
+	// +kubebuilder:validation:Enum=LDAPIdentityProvider;ActiveDirectoryIdentityProvider
+	// +optional
+	IdentityProviderKind string `json:"identityProviderKind,omitempty"`
+
+	// IdentityProviderName is the name of the identity provider, which must be in the same namespace.
+	// The user's username is used as the username for its user search, so its user search should be configured
+	// to find users by the chosen username format.
+	// +kubebuilder:validation:MinLength=1
+	IdentityProviderName string `json:"identityProviderName"`
+}
+
+// KerberosIdentityProviderSpec is the spec for configuring a Kerberos identity provider.
+type KerberosIdentityProviderSpec struct {
+	// ServicePrincipalName is the name of the Supervisor's Kerberos service principal without the realm,
+	// e.g. "HTTP/supervisor.example.com". Browsers request service tickets for the host name of the
+	// FederationDomain issuer, so the host part should match it.
+	// +kubebuilder:validation:MinLength=1
+	ServicePrincipalName string `json:"servicePrincipalName"`
+
+	// Keytab identifies the Secret which contains the keys of the service principal.
+	Keytab KerberosKeytabSpec `json:"keytab"`
+
+	// AllowedRealms lists the Kerberos realms from which users may log in, e.g. "EXAMPLE.COM".
+	// Users whose principals are from any other realm are rejected, even when the realm is trusted by the KDC.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	AllowedRealms []string `json:"allowedRealms"`
+
+	// Claims allows customization of the username.
+	// +kubebuilder:default={}
+	// +optional
+	Claims KerberosClaims `json:"claims,omitempty"`
+
+	// GroupSearch optionally configures the search of the user's groups after the user has been authenticated.
+	// When not specified, users will not have any groups.
+	// +optional
+	GroupSearch *KerberosGroupSearch `json:"groupSearch,omitempty"`
+}
+
+// KerberosIdentityProvider describes the configuration of an upstream Kerberos realm. Users on domain-joined
+// workstations log in without typing their passwords, since their browsers authenticate them to the Supervisor
+// using SPNEGO on its Kerberos login endpoint. Their usernames are computed from their Kerberos principals, and
+// their groups can optionally be searched using an LDAPIdentityProvider or ActiveDirectoryIdentityProvider.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped;pinniped-idp;pinniped-idps
+// +kubebuilder:printcolumn:name="Service Principal",type=string,JSONPath=`.spec.servicePrincipalName`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type KerberosIdentityProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec for configuring the identity provider.
+	Spec KerberosIdentityProviderSpec `json:"spec"`
+
+	// Status of the identity provider.
+	Status KerberosIdentityProviderStatus `json:"status,omitempty"`
+}
+
+// KerberosIdentityProviderList lists KerberosIdentityProvider objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type KerberosIdentityProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []KerberosIdentityProvider `json:"items"`
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SessionListRequest can be used to list the active downstream sessions of the Supervisor, optionally only those of a
+// particular user, client, or identity provider. The tokens of the sessions are never returned.
+// +genclient
+// +genclient:onlyVerbs=create
+// +kubebuilder:subresource:status
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionListRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SessionListRequestSpec `json:"spec"`
+
+	// +optional
+	Status SessionListRequestStatus `json:"status"`
+}
+
+// Spec of the SessionListRequest.
+type SessionListRequestSpec struct {
+	// Selects which sessions to list. All active sessions are listed when no fields of the selector are specified.
+	// +optional
+	Selector SessionSelector `json:"selector"`
+}
+
+// Status of the SessionListRequest.
+type SessionListRequestStatus struct {
+	// The active sessions which were selected.
+	// +listType=atomic
+	Sessions []DownstreamSession `json:"sessions,omitempty"`
+}
+
+// SessionListRequestList is a list of SessionListRequest objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionListRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of SessionListRequest.
+	Items []SessionListRequest `json:"items"`
+}
@@ -14,7 +14,11 @@ import (
 type IDPV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ActiveDirectoryIdentityProvidersGetter
+	GitHubIdentityProvidersGetter
+	GitLabIdentityProvidersGetter
+	KerberosIdentityProvidersGetter
 	LDAPIdentityProvidersGetter
+	OAuth2IdentityProvidersGetter
 	OIDCIdentityProvidersGetter
 }
 
@@ -27,10 +31,26 @@ func (c *IDPV1alpha1Client) ActiveDirectoryIdentityProviders(namespace string) A
 	return newActiveDirectoryIdentityProviders(c, namespace)
 }
 
+func (c *IDPV1alpha1Client) GitHubIdentityProviders(namespace string) GitHubIdentityProviderInterface {
+	return newGitHubIdentityProviders(c, namespace)
+}
+
+func (c *IDPV1alpha1Client) GitLabIdentityProviders(namespace string) GitLabIdentityProviderInterface {
+	return newGitLabIdentityProviders(c, namespace)
+}
+
+func (c *IDPV1alpha1Client) KerberosIdentityProviders(namespace string) KerberosIdentityProviderInterface {
+	return newKerberosIdentityProviders(c, namespace)
+}
+
 func (c *IDPV1alpha1Client) LDAPIdentityProviders(namespace string) LDAPIdentityProviderInterface {
 	return newLDAPIdentityProviders(c, namespace)
 }
 
+func (c *IDPV1alpha1Client) OAuth2IdentityProviders(namespace string) OAuth2IdentityProviderInterface {
+	return newOAuth2IdentityProviders(c, namespace)
+}
+
 func (c *IDPV1alpha1Client) OIDCIdentityProviders(namespace string) OIDCIdentityProviderInterface {
 	return newOIDCIdentityProviders(c, namespace)
 }
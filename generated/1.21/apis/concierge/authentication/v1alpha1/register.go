@@ -34,6 +34,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&WebhookAuthenticatorList{},
 		&JWTAuthenticator{},
 		&JWTAuthenticatorList{},
+		&X509ClientCertificateAuthenticator{},
+		&X509ClientCertificateAuthenticatorList{},
+		&ServiceAccountTokenAuthenticator{},
+		&ServiceAccountTokenAuthenticatorList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
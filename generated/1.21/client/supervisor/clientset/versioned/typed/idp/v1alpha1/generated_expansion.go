@@ -7,6 +7,14 @@ package v1alpha1
 
 type ActiveDirectoryIdentityProviderExpansion interface{}
 
+type GitHubIdentityProviderExpansion interface{}
+
+type GitLabIdentityProviderExpansion interface{}
+
+type KerberosIdentityProviderExpansion interface{}
+
 type LDAPIdentityProviderExpansion interface{}
 
+type OAuth2IdentityProviderExpansion interface{}
+
 type OIDCIdentityProviderExpansion interface{}
@@ -0,0 +1,64 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// Status of a service account token authenticator.
+type ServiceAccountTokenAuthenticatorStatus struct {
+	// Represents the observations of the authenticator's current state.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// Spec for configuring a service account token authenticator. The token of a TokenCredentialRequest which uses
+// this authenticator must be a service account token of this cluster which is bound to the audience of the
+// authenticator, e.g. one which is projected into a Pod by a serviceAccountToken volume. The token is validated
+// by the Kubernetes API using a TokenReview, and the cluster credential is issued for the identity of the service
+// account.
+type ServiceAccountTokenAuthenticatorSpec struct {
+	// Audience is the audience to which the service account tokens must be bound. It must not be an audience
+	// which is accepted by the Kubernetes API server itself, so that the tokens which are meant for the API server
+	// cannot be exchanged for cluster credentials, and so that legacy service account tokens, which are not bound
+	// to any audience, are rejected.
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+
+	// AllowedNamespaces restricts which service account tokens are accepted to those of the service accounts in
+	// these namespaces. When it is empty, the service accounts of all namespaces are accepted.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// ServiceAccountTokenAuthenticator describes the configuration of a service account token authenticator, which
+// lets workloads exchange their audience-bound service account tokens for cluster credentials.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped;pinniped-authenticator;pinniped-authenticators,scope=Cluster
+// +kubebuilder:printcolumn:name="Audience",type=string,JSONPath=`.spec.audience`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type ServiceAccountTokenAuthenticator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec for configuring the authenticator.
+	Spec ServiceAccountTokenAuthenticatorSpec `json:"spec"`
+
+	// Status of the authenticator.
+	Status ServiceAccountTokenAuthenticatorStatus `json:"status,omitempty"`
+}
+
+// List of ServiceAccountTokenAuthenticator objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ServiceAccountTokenAuthenticatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ServiceAccountTokenAuthenticator `json:"items"`
+}
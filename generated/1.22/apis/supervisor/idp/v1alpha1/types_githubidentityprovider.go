@@ -0,0 +1,198 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type GitHubIdentityProviderPhase string
+
+const (
+	// GitHubPhasePending is the default phase for newly-created GitHubIdentityProvider resources.
+	GitHubPhasePending GitHubIdentityProviderPhase = "Pending"
+
+	// GitHubPhaseReady is the phase for a GitHubIdentityProvider resource in a healthy state.
+	GitHubPhaseReady GitHubIdentityProviderPhase = "Ready"
+
+	// GitHubPhaseError is the phase for a GitHubIdentityProvider in an unhealthy state.
+	GitHubPhaseError GitHubIdentityProviderPhase = "Error"
+)
+
+// GitHubAllowedAuthOrganizationsPolicy determines which GitHub users may log in.
+type GitHubAllowedAuthOrganizationsPolicy string
+
+const (
+	// GitHubAllowedAuthOrganizationsPolicyAllGitHubUsers means any GitHub user is allowed to log in using this
+	// identity provider, regardless of their organization membership or lack thereof.
+	GitHubAllowedAuthOrganizationsPolicyAllGitHubUsers GitHubAllowedAuthOrganizationsPolicy = "AllGitHubUsers"
+
+	// GitHubAllowedAuthOrganizationsPolicyOnlyUsersFromAllowedOrganizations means only those users with membership in
+	// the listed GitHub organizations are allowed to log in.
+	GitHubAllowedAuthOrganizationsPolicyOnlyUsersFromAllowedOrganizations GitHubAllowedAuthOrganizationsPolicy = "OnlyUsersFromAllowedOrganizations"
+)
+
+// GitHubUsernameAttribute determines which property of the GitHub user record is used as the username.
+type GitHubUsernameAttribute string
+
+const (
+	// GitHubUsernameLogin specifies that the user's GitHub login name should be used as the username.
+	// Note that GitHub users can change their own login names.
+	GitHubUsernameLogin GitHubUsernameAttribute = "login"
+
+	// GitHubUsernameID specifies that the user's immutable numeric GitHub ID should be used as the username.
+	GitHubUsernameID GitHubUsernameAttribute = "id"
+
+	// GitHubUsernameLoginAndID specifies that the username should be the user's login name and numeric ID,
+	// separated by a colon, e.g. "octocat:1234567".
+	GitHubUsernameLoginAndID GitHubUsernameAttribute = "login:id"
+)
+
+// GitHubGroupNameAttribute determines which property of the GitHub team record is used as the group name.
+type GitHubGroupNameAttribute string
+
+const (
+	// GitHubUseTeamNameForGroupName specifies that the GitHub team's name should be used as the group name.
+	// Note that team names can be changed by the organization's owners.
+	GitHubUseTeamNameForGroupName GitHubGroupNameAttribute = "name"
+
+	// GitHubUseTeamSlugForGroupName specifies that the GitHub team's slug should be used as the group name.
+	GitHubUseTeamSlugForGroupName GitHubGroupNameAttribute = "slug"
+)
+
+// GitHubIdentityProviderStatus is the status of a GitHub identity provider.
+type GitHubIdentityProviderStatus struct {
+	// Phase summarizes the overall status of the GitHubIdentityProvider.
+	// +kubebuilder:default=Pending
+	// +kubebuilder:validation:Enum=Pending;Ready;Error
+	Phase GitHubIdentityProviderPhase `json:"phase,omitempty"`
+
+	// Represents the observations of an identity provider's current state.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// GitHubAPIConfig allows configuration for GitHub Enterprise Server.
+type GitHubAPIConfig struct {
+	// Host is required only for GitHub Enterprise Server.
+	// Defaults to using GitHub's public API ("github.com").
+	// Do not specify a protocol or path, since the API is always accessed using https at "/api/v3", except for
+	// "github.com" whose API is at "https://api.github.com".
+	// +kubebuilder:default="github.com"
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// TLS configuration for GitHub Enterprise Server.
+	// +optional
+	TLS *TLSSpec `json:"tls,omitempty"`
+}
+
+// GitHubClaims allows customization of the username and groups claims.
+type GitHubClaims struct {
+	// Username configures which property of the GitHub user record shall determine the username in Kubernetes.
+	// Can be either "id", "login", or "login:id". Defaults to "login:id".
+	// The "login" and "login:id" options are easier to read, but GitHub users can change their own login names,
+	// which would change their username in Kubernetes. The "id" option is immutable, but is not human-readable.
+	// +kubebuilder:default="login:id"
+	// +kubebuilder:validation:Enum={"id","login","login:id"}
+	// +optional
+	Username GitHubUsernameAttribute `json:"username,omitempty"`
+
+	// Groups configures which property of the GitHub team record shall determine the group names in Kubernetes.
+	// Can be either "name" or "slug". Defaults to "slug".
+	// Each group name is prefixed by the login name of the team's organization and a slash,
+	// e.g. "my-org/my-team-slug".
+	// +kubebuilder:default=slug
+	// +kubebuilder:validation:Enum=name;slug
+	// +optional
+	Groups GitHubGroupNameAttribute `json:"groups,omitempty"`
+}
+
+// GitHubClientSpec contains information about the GitHub client that this identity provider will use
+// for web-based login flows.
+type GitHubClientSpec struct {
+	// SecretName contains the name of a namespace-local Secret object that provides the clientID and
+	// clientSecret for a GitHub App or GitHub OAuth2 client. The Secret is expected to be of type
+	// "secrets.pinniped.dev/github-client" with keys "clientID" and "clientSecret".
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// GitHubOrganizationsSpec configures which GitHub organizations' members may log in.
+type GitHubOrganizationsSpec struct {
+	// Policy configures which GitHub users may log in. It can be either "OnlyUsersFromAllowedOrganizations"
+	// or "AllGitHubUsers". Defaults to "OnlyUsersFromAllowedOrganizations".
+	// When "OnlyUsersFromAllowedOrganizations", only members of the organizations listed in Allowed may log in,
+	// and only teams from the listed organizations will be used as group memberships.
+	// When "AllGitHubUsers", any GitHub user may log in, and the teams of all organizations will be used as
+	// group memberships. Allowed must be empty when the policy is "AllGitHubUsers".
+	// +kubebuilder:default=OnlyUsersFromAllowedOrganizations
+	// +kubebuilder:validation:Enum=OnlyUsersFromAllowedOrganizations;AllGitHubUsers
+	// +optional
+	Policy GitHubAllowedAuthOrganizationsPolicy `json:"policy,omitempty"`
+
+	// Allowed is a list of the login names of GitHub organizations. The names are matched case-insensitively.
+	// +listType=set
+	// +optional
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// GitHubAllowAuthenticationSpec configures which users may log in using this identity provider.
+type GitHubAllowAuthenticationSpec struct {
+	// Organizations configures which GitHub organizations' members may log in.
+	Organizations GitHubOrganizationsSpec `json:"organizations"`
+}
+
+// GitHubIdentityProviderSpec is the spec for configuring a GitHub identity provider.
+type GitHubIdentityProviderSpec struct {
+	// GitHubAPI allows configuration for GitHub Enterprise Server.
+	// +kubebuilder:default={}
+	// +optional
+	GitHubAPI GitHubAPIConfig `json:"githubAPI,omitempty"`
+
+	// Claims allows customization of the username and groups claims.
+	// +kubebuilder:default={}
+	// +optional
+	Claims GitHubClaims `json:"claims,omitempty"`
+
+	// AllowAuthentication configures which users may log in using this identity provider.
+	AllowAuthentication GitHubAllowAuthenticationSpec `json:"allowAuthentication"`
+
+	// Client identifies the secret with credentials for a GitHub App or GitHub OAuth2 App (a GitHub client).
+	Client GitHubClientSpec `json:"client"`
+}
+
+// GitHubIdentityProvider describes the configuration of an upstream GitHub identity provider, using either
+// github.com or GitHub Enterprise Server. Users log in using the GitHub OAuth2 web flow. Their usernames are
+// computed from their GitHub user records, and their groups are computed from their GitHub team memberships.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped;pinniped-idp;pinniped-idps
+// +kubebuilder:printcolumn:name="Host",type=string,JSONPath=`.spec.githubAPI.host`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type GitHubIdentityProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec for configuring the identity provider.
+	Spec GitHubIdentityProviderSpec `json:"spec"`
+
+	// Status of the identity provider.
+	Status GitHubIdentityProviderStatus `json:"status,omitempty"`
+}
+
+// GitHubIdentityProviderList lists GitHubIdentityProvider objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type GitHubIdentityProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []GitHubIdentityProvider `json:"items"`
+}
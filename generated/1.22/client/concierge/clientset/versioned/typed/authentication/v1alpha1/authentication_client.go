@@ -14,7 +14,9 @@ import (
 type AuthenticationV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	JWTAuthenticatorsGetter
+	ServiceAccountTokenAuthenticatorsGetter
 	WebhookAuthenticatorsGetter
+	X509ClientCertificateAuthenticatorsGetter
 }
 
 // AuthenticationV1alpha1Client is used to interact with features provided by the authentication.concierge.pinniped.dev group.
@@ -26,10 +28,18 @@ func (c *AuthenticationV1alpha1Client) JWTAuthenticators() JWTAuthenticatorInter
 	return newJWTAuthenticators(c)
 }
 
+func (c *AuthenticationV1alpha1Client) ServiceAccountTokenAuthenticators() ServiceAccountTokenAuthenticatorInterface {
+	return newServiceAccountTokenAuthenticators(c)
+}
+
 func (c *AuthenticationV1alpha1Client) WebhookAuthenticators() WebhookAuthenticatorInterface {
 	return newWebhookAuthenticators(c)
 }
 
+func (c *AuthenticationV1alpha1Client) X509ClientCertificateAuthenticators() X509ClientCertificateAuthenticatorInterface {
+	return newX509ClientCertificateAuthenticators(c)
+}
+
 // NewForConfig creates a new AuthenticationV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*AuthenticationV1alpha1Client, error) {
 	config := *c
@@ -9,6 +9,14 @@ package v1alpha1
 // JWTAuthenticatorLister.
 type JWTAuthenticatorListerExpansion interface{}
 
+// ServiceAccountTokenAuthenticatorListerExpansion allows custom methods to be added to
+// ServiceAccountTokenAuthenticatorLister.
+type ServiceAccountTokenAuthenticatorListerExpansion interface{}
+
 // WebhookAuthenticatorListerExpansion allows custom methods to be added to
 // WebhookAuthenticatorLister.
 type WebhookAuthenticatorListerExpansion interface{}
+
+// X509ClientCertificateAuthenticatorListerExpansion allows custom methods to be added to
+// X509ClientCertificateAuthenticatorLister.
+type X509ClientCertificateAuthenticatorListerExpansion interface{}
@@ -13,6 +13,30 @@ type ActiveDirectoryIdentityProviderListerExpansion interface{}
 // ActiveDirectoryIdentityProviderNamespaceLister.
 type ActiveDirectoryIdentityProviderNamespaceListerExpansion interface{}
 
+// GitHubIdentityProviderListerExpansion allows custom methods to be added to
+// GitHubIdentityProviderLister.
+type GitHubIdentityProviderListerExpansion interface{}
+
+// GitHubIdentityProviderNamespaceListerExpansion allows custom methods to be added to
+// GitHubIdentityProviderNamespaceLister.
+type GitHubIdentityProviderNamespaceListerExpansion interface{}
+
+// GitLabIdentityProviderListerExpansion allows custom methods to be added to
+// GitLabIdentityProviderLister.
+type GitLabIdentityProviderListerExpansion interface{}
+
+// GitLabIdentityProviderNamespaceListerExpansion allows custom methods to be added to
+// GitLabIdentityProviderNamespaceLister.
+type GitLabIdentityProviderNamespaceListerExpansion interface{}
+
+// KerberosIdentityProviderListerExpansion allows custom methods to be added to
+// KerberosIdentityProviderLister.
+type KerberosIdentityProviderListerExpansion interface{}
+
+// KerberosIdentityProviderNamespaceListerExpansion allows custom methods to be added to
+// KerberosIdentityProviderNamespaceLister.
+type KerberosIdentityProviderNamespaceListerExpansion interface{}
+
 // LDAPIdentityProviderListerExpansion allows custom methods to be added to
 // LDAPIdentityProviderLister.
 type LDAPIdentityProviderListerExpansion interface{}
@@ -21,6 +45,14 @@ type LDAPIdentityProviderListerExpansion interface{}
 // LDAPIdentityProviderNamespaceLister.
 type LDAPIdentityProviderNamespaceListerExpansion interface{}
 
+// OAuth2IdentityProviderListerExpansion allows custom methods to be added to
+// OAuth2IdentityProviderLister.
+type OAuth2IdentityProviderListerExpansion interface{}
+
+// OAuth2IdentityProviderNamespaceListerExpansion allows custom methods to be added to
+// OAuth2IdentityProviderNamespaceLister.
+type OAuth2IdentityProviderNamespaceListerExpansion interface{}
+
 // OIDCIdentityProviderListerExpansion allows custom methods to be added to
 // OIDCIdentityProviderLister.
 type OIDCIdentityProviderListerExpansion interface{}
@@ -0,0 +1,70 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logincheck
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LDAPLoginCheckRequest can be used to check whether a user can log in using an LDAPIdentityProvider or an
+// ActiveDirectoryIdentityProvider, without starting a session for the user.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type LDAPLoginCheckRequest struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec LDAPLoginCheckRequestSpec
+
+	// +optional
+	Status LDAPLoginCheckRequestStatus
+}
+
+// Spec of the LDAPLoginCheckRequest.
+type LDAPLoginCheckRequestSpec struct {
+	// The identity provider using which to check the login.
+	IdentityProvider LDAPLoginCheckIdentityProvider
+
+	// The username of the user whose login should be checked.
+	Username string
+
+	// The password of the user whose login should be checked. This is never returned.
+	Password string
+}
+
+// LDAPLoginCheckIdentityProvider refers to an identity provider in the same namespace.
+type LDAPLoginCheckIdentityProvider struct {
+	// Kind of the identity provider, either LDAPIdentityProvider or ActiveDirectoryIdentityProvider.
+	Kind string
+
+	// Name of the identity provider.
+	Name string
+}
+
+// Status of the LDAPLoginCheckRequest.
+type LDAPLoginCheckRequestStatus struct {
+	// Whether the user was found and their password was accepted.
+	Authenticated bool
+
+	// A human-readable description of the result of the check.
+	Message string
+
+	// The username of the user as it would appear in the ID tokens issued by the Supervisor, when authenticated.
+	Username string
+
+	// The distinguished name of the user's entry, when authenticated.
+	DN string
+
+	// The groups of the user as they would appear in the ID tokens issued by the Supervisor, when authenticated.
+	Groups []string
+}
+
+// LDAPLoginCheckRequestList is a list of LDAPLoginCheckRequest objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type LDAPLoginCheckRequestList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	// Items is a list of LDAPLoginCheckRequest.
+	Items []LDAPLoginCheckRequest
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
@@ -32,6 +32,10 @@ const (
 	// IDTokenClaimAuthorizedParty is name of the authorized party claim defined by the OIDC spec.
 	IDTokenClaimAuthorizedParty = "azp"
 
+	// IDTokenClaimSessionID is name of the session ID claim defined by the OIDC Front-Channel and Back-Channel Logout
+	// specs. Its value identifies the downstream session, and it can be used with the end session endpoint.
+	IDTokenClaimSessionID = "sid"
+
 	// IDTokenClaimUsername is the name of a custom claim in the downstream ID token whose value will contain the user's
 	// username which was mapped from the upstream identity provider.
 	IDTokenClaimUsername = "username"
@@ -44,6 +48,14 @@ const (
 	// token, if any claims are present.
 	IDTokenClaimAdditionalClaims = "additionalClaims"
 
+	// ClaimIdentityType is the name of a custom claim in downstream JWT access tokens, and of a custom member of
+	// token introspection responses, whose value describes what kind of identity the token represents.
+	ClaimIdentityType = "identity_type"
+
+	// IdentityTypeClient is the value of the ClaimIdentityType claim for tokens which were issued to an OIDCClient for
+	// itself by a client credentials grant, i.e. tokens which do not represent a user.
+	IdentityTypeClient = "client"
+
 	// GrantTypeAuthorizationCode is the name of the grant type for authorization code flows defined by the OIDC spec.
 	GrantTypeAuthorizationCode = "authorization_code"
 
@@ -53,6 +65,12 @@ const (
 	// GrantTypeTokenExchange is the name of a custom grant type for RFC8693 token exchanges.
 	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange" //nolint:gosec // this is not a credential
 
+	// GrantTypeDeviceCode is the name of the grant type for RFC8628 device authorization grants.
+	GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code" //nolint:gosec // this is not a credential
+
+	// GrantTypeClientCredentials is the name of the grant type for client credentials grants defined by RFC6749.
+	GrantTypeClientCredentials = "client_credentials" //nolint:gosec // this is not a credential
+
 	// ScopeOpenID is name of the openid scope defined by the OIDC spec.
 	ScopeOpenID = "openid"
 
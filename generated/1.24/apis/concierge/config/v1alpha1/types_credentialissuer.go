@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -8,7 +8,7 @@ import (
 )
 
 // StrategyType enumerates a type of "strategy" used to implement credential access on a cluster.
-// +kubebuilder:validation:Enum=KubeClusterSigningCertificate;ImpersonationProxy
+// +kubebuilder:validation:Enum=KubeClusterSigningCertificate;ImpersonationProxy;CertificateSigningRequestAPI
 type StrategyType string
 
 // FrontendType enumerates a type of "frontend" used to provide access to users of a cluster.
@@ -26,6 +26,7 @@ type StrategyReason string
 const (
 	KubeClusterSigningCertificateStrategyType = StrategyType("KubeClusterSigningCertificate")
 	ImpersonationProxyStrategyType            = StrategyType("ImpersonationProxy")
+	CertificateSigningRequestAPIStrategyType  = StrategyType("CertificateSigningRequestAPI")
 
 	TokenCredentialRequestAPIFrontendType = FrontendType("TokenCredentialRequestAPI")
 	ImpersonationProxyFrontendType        = FrontendType("ImpersonationProxy")
@@ -46,6 +47,110 @@ const (
 type CredentialIssuerSpec struct {
 	// ImpersonationProxy describes the intended configuration of the Concierge impersonation proxy.
 	ImpersonationProxy *ImpersonationProxySpec `json:"impersonationProxy"`
+
+	// ClientCertificates configures the lifetimes of the client certificates which are issued by the
+	// TokenCredentialRequest API.
+	//
+	// +optional
+	ClientCertificates *ClientCertificatesSpec `json:"clientCertificates,omitempty"`
+
+	// CertificateSigningRequests configures the Concierge to issue the client certificates of the
+	// TokenCredentialRequest API by creating CertificateSigningRequests in the certificates.k8s.io API of the cluster.
+	// This is useful on clusters where the kube-cert-agent cannot reach the signing keypair of the cluster,
+	// such as many managed clusters. When it is not set, the Concierge does not use the certificates.k8s.io API.
+	//
+	// +optional
+	CertificateSigningRequests *CertificateSigningRequestsSpec `json:"certificateSigningRequests,omitempty"`
+
+	// CredentialIssuancePolicy configures an additional check which the TokenCredentialRequest API performs after a
+	// token has been authenticated and before a client certificate is issued, so that credentials can be denied
+	// based on the username, groups, or IP address of the client. The decisions are logged by the Concierge.
+	// When it is not set, a credential is issued to every authenticated user.
+	//
+	// +optional
+	CredentialIssuancePolicy *CredentialIssuancePolicySpec `json:"credentialIssuancePolicy,omitempty"`
+}
+
+// CertificateSigningRequestsSpec describes how the Concierge should use the certificates.k8s.io API to issue
+// client certificates.
+type CertificateSigningRequestsSpec struct {
+	// SignerName is the signerName of the CertificateSigningRequests which are created by the Concierge.
+	// The signer must issue client certificates which are trusted by the Kubernetes API server.
+	// The Concierge approves the CertificateSigningRequests which it creates, so it must be allowed to approve
+	// requests for this signer. Note that the certificates.k8s.io API does not allow certificates which are valid
+	// for less than 10 minutes to be requested, and that some signers ignore the requested lifetime.
+	//
+	// +kubebuilder:default:="kubernetes.io/kube-apiserver-client"
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	SignerName string `json:"signerName,omitempty"`
+}
+
+// ClientCertificatesSpec describes the lifetimes of the client certificates which are issued by the Concierge.
+type ClientCertificatesSpec struct {
+	// TTLSeconds is how long each client certificate is valid. Shorter lifetimes limit how long a certificate can be
+	// used after the token which it was exchanged for has been revoked. The default is 300 seconds (5 minutes).
+	//
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	TTLSeconds *int32 `json:"ttlSeconds,omitempty"`
+
+	// RefreshMarginSeconds is how much earlier than the end of its lifetime each client certificate is reported to
+	// expire in the expirationTimestamp of the TokenCredentialRequest, so that clients get a new certificate before
+	// the old one is rejected, e.g. because of clock skew. The default is 0 seconds.
+	// It must be less than half of ttlSeconds, otherwise the defaults are used.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=600
+	// +optional
+	RefreshMarginSeconds *int32 `json:"refreshMarginSeconds,omitempty"`
+}
+
+// CredentialIssuancePolicySpec describes the checks which decide whether the TokenCredentialRequest API may issue a
+// client certificate to an authenticated user. When both an expression and a webhook are configured, both must
+// allow the credential.
+type CredentialIssuancePolicySpec struct {
+	// Expression is a CEL expression which must evaluate to true for a credential to be issued. It may use the
+	// variables username (a string), groups (a list of strings), sourceIP (a string, which is empty when the IP
+	// address of the client is not known), and authenticator (a map with the keys apiGroup, kind, and name).
+	// For example, !("contractors" in groups) || sourceIP.startsWith("10.") denies credentials to contractors
+	// outside of the 10.0.0.0/8 network. See https://github.com/google/cel-spec for the expression language.
+	// When the expression is invalid, every credential is denied.
+	//
+	// +optional
+	Expression string `json:"expression,omitempty"`
+
+	// Webhook configures an HTTPS endpoint which is sent a SubjectAccessReview, in the same format which Kubernetes
+	// sends to authorization webhooks, for each credential which is about to be issued. The review describes the
+	// creation of a tokencredentialrequests resource in the login.concierge.pinniped.dev API group by the user, and
+	// its extra info holds the IP address of the client and the authenticator. The credential is only issued when
+	// the webhook allows it, so it is denied when the webhook cannot be reached.
+	//
+	// +optional
+	Webhook *CredentialIssuancePolicyWebhookSpec `json:"webhook,omitempty"`
+}
+
+// CredentialIssuancePolicyWebhookSpec describes how to reach the webhook of a CredentialIssuancePolicy.
+type CredentialIssuancePolicyWebhookSpec struct {
+	// Endpoint is the HTTPS URL of the webhook.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^https://`
+	Endpoint string `json:"endpoint"`
+
+	// CertificateAuthorityData is the base64-encoded PEM CA bundle which is used to verify the serving certificate
+	// of the webhook. When it is not set, the CAs of the operating system are trusted.
+	//
+	// +optional
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+
+	// TimeoutSeconds is how long to wait for the webhook to respond. The default is 10 seconds.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // ImpersonationProxyMode enumerates the configuration modes for the impersonation proxy.
@@ -100,6 +205,30 @@ type ImpersonationProxySpec struct {
 	//
 	// +optional
 	ExternalEndpoint string `json:"externalEndpoint,omitempty"`
+
+	// ProxyProtocol configures the impersonation proxy to accept connections which begin with a PROXY protocol
+	// (version 1 or 2) header, as sent by many L4 load balancers, so that the IP addresses of the original clients
+	// are known, e.g. for audit logs. When it is set, every connection must begin with such a header, so the load
+	// balancer in front of the impersonation proxy must be configured to send it. When it is not set, the PROXY
+	// protocol is not accepted.
+	//
+	// +optional
+	ProxyProtocol *ProxyProtocolSpec `json:"proxyProtocol,omitempty"`
+
+	// TLS configures the TLS versions and cipher suites which are accepted by the impersonation proxy.
+	// When it is not set, the defaults of the Concierge are used. Changing it restarts the impersonation proxy.
+	//
+	// +optional
+	TLS *ImpersonationProxyTLSSpec `json:"tls,omitempty"`
+
+	// CertificateRotation configures the Concierge to periodically replace the CA which issues the serving
+	// certificate of the impersonation proxy. Each new CA is published in the status of the CredentialIssuer for a
+	// while before the serving certificate is issued by it, so that clients can learn to trust it in advance.
+	// Connections which were already established are not closed when the serving certificate changes.
+	// When it is not set, the CA is not rotated.
+	//
+	// +optional
+	CertificateRotation *ImpersonationProxyCertificateRotationSpec `json:"certificateRotation,omitempty"`
 }
 
 // ImpersonationProxyServiceSpec describes how the Concierge should provision a Service to expose the impersonation proxy.
@@ -124,6 +253,81 @@ type ImpersonationProxyServiceSpec struct {
 	//
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerClass specifies the spec.loadBalancerClass of the provisioned Service when its type is
+	// LoadBalancer, to select a load balancer implementation other than the default one of the cluster.
+	// Kubernetes does not allow the class of a Service to be changed, so the Service is deleted and provisioned
+	// again when this field is changed, which may change its IP address or hostname.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+
+	// NodePort specifies the port on each node through which the provisioned Service is exposed when its type is
+	// LoadBalancer. When it is not set, Kubernetes chooses a port.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// ExternalTrafficPolicy specifies the spec.externalTrafficPolicy of the provisioned Service when its type is
+	// LoadBalancer. Local preserves the IP addresses of the clients and avoids a second hop between nodes, but the
+	// load balancer must then only send traffic to the nodes which run the Concierge. When it is not set, Cluster
+	// is used.
+	//
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy string `json:"externalTrafficPolicy,omitempty"`
+}
+
+// ProxyProtocolSpec describes which peers may send PROXY protocol headers to the impersonation proxy.
+type ProxyProtocolSpec struct {
+	// TrustedCIDRs are the networks, in CIDR notation (e.g. "10.0.0.0/8"), of the load balancers which are allowed
+	// to send PROXY protocol headers. Connections from any other peer are closed. When it is empty, every peer is
+	// allowed, so the impersonation proxy should only be reachable through the load balancer.
+	//
+	// +optional
+	TrustedCIDRs []string `json:"trustedCIDRs,omitempty"`
+}
+
+// ImpersonationProxyTLSSpec describes the TLS versions and cipher suites which are accepted by the impersonation proxy.
+type ImpersonationProxyTLSSpec struct {
+	// MinVersion is the minimum TLS version which is accepted, either "1.2" or "1.3". The default is "1.2".
+	//
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// CipherSuites are the names of the TLS 1.2 cipher suites which are accepted, as named by the Go crypto/tls
+	// package, e.g. "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384". Only secure cipher suites can be used. They cannot be
+	// configured when minVersion is "1.3", because the cipher suites of TLS 1.3 are not configurable. When it is
+	// empty, the default cipher suites of the Concierge are used.
+	//
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+// ImpersonationProxyCertificateRotationSpec describes how often the CA of the impersonation proxy is replaced.
+type ImpersonationProxyCertificateRotationSpec struct {
+	// IntervalSeconds is how often a new CA is created. Each CA issues the serving certificate for this long before
+	// it is replaced by the next one. The default is 7776000 seconds (90 days).
+	//
+	// +kubebuilder:validation:Minimum=86400
+	// +kubebuilder:validation:Maximum=315360000
+	// +optional
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+
+	// OverlapSeconds is how long each new CA is published in the status of the CredentialIssuer, next to the
+	// current CA, before the serving certificate is issued by it. Clients should fetch the CA bundle again within
+	// this time, e.g. by generating a new kubeconfig. The default is 604800 seconds (7 days).
+	// It must be less than intervalSeconds.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=31536000
+	// +optional
+	OverlapSeconds *int32 `json:"overlapSeconds,omitempty"`
 }
 
 // CredentialIssuerStatus describes the status of the Concierge.
@@ -206,6 +410,7 @@ type ImpersonationProxyInfo struct {
 	Endpoint string `json:"endpoint"`
 
 	// CertificateAuthorityData is the base64-encoded PEM CA bundle of the impersonation proxy.
+	// While the CA of the impersonation proxy is being rotated, it contains both the current and the next CA.
 	// +kubebuilder:validation:MinLength=1
 	CertificateAuthorityData string `json:"certificateAuthorityData"`
 }
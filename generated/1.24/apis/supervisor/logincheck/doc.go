@@ -0,0 +1,8 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// +k8s:deepcopy-gen=package
+// +groupName=logincheck.supervisor.pinniped.dev
+
+// Package logincheck is the internal version of the Pinniped login check API.
+package logincheck
@@ -0,0 +1,44 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SessionListRequest can be used to list the active downstream sessions of the Supervisor, optionally only those of a
+// particular user, client, or identity provider. The tokens of the sessions are never returned.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionListRequest struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec SessionListRequestSpec
+
+	// +optional
+	Status SessionListRequestStatus
+}
+
+// Spec of the SessionListRequest.
+type SessionListRequestSpec struct {
+	// Selects which sessions to list. All active sessions are listed when no fields of the selector are specified.
+	// +optional
+	Selector SessionSelector
+}
+
+// Status of the SessionListRequest.
+type SessionListRequestStatus struct {
+	// The active sessions which were selected.
+	Sessions []DownstreamSession
+}
+
+// SessionListRequestList is a list of SessionListRequest objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionListRequestList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	// Items is a list of SessionListRequest.
+	Items []SessionListRequest
+}
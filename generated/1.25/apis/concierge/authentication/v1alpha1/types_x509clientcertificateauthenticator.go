@@ -0,0 +1,116 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// Status of an X.509 client certificate authenticator.
+type X509ClientCertificateAuthenticatorStatus struct {
+	// Represents the observations of the authenticator's current state.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// Spec for configuring an X.509 client certificate authenticator. The token of a TokenCredentialRequest which uses
+// this authenticator must be a JWT which is signed by the private key of the client certificate, e.g. by a smart card.
+// The client certificate and any intermediate certificates are included in the x5c header of the JWT. The JWT must
+// have an aud claim which equals the audience of the authenticator, and iat and exp claims which are at most five
+// minutes apart.
+type X509ClientCertificateAuthenticatorSpec struct {
+	// Audience is the required value of the aud claim of the JWTs. It should be unique for each cluster, so that
+	// the JWTs which are meant for one cluster cannot be used to log in to another cluster.
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+
+	// ClientCertificateAuthority is the X.509 Certificate Authority which issues the client certificates.
+	// Unlike for the other authenticators, the system roots are never trusted, so a CA bundle must be specified.
+	ClientCertificateAuthority TLSSpec `json:"clientCertificateAuthority"`
+
+	// AllowedOrganizationalUnits restricts which client certificates are accepted to those whose subject has at
+	// least one of these organizational units (OU). When it is empty, the organizational units are not checked.
+	// +optional
+	AllowedOrganizationalUnits []string `json:"allowedOrganizationalUnits,omitempty"`
+
+	// Claims describes which parts of the client certificates are used as the usernames and groups.
+	// +optional
+	Claims X509ClientCertificateClaims `json:"claims"`
+}
+
+// X509UsernameSource is a part of a client certificate which can be used as the username.
+// +kubebuilder:validation:Enum=CommonName;EmailAddress;UserPrincipalName
+type X509UsernameSource string
+
+const (
+	// X509UsernameSourceCommonName uses the common name (CN) of the subject.
+	X509UsernameSourceCommonName = X509UsernameSource("CommonName")
+
+	// X509UsernameSourceEmailAddress uses the first email address of the subject alternative names.
+	X509UsernameSourceEmailAddress = X509UsernameSource("EmailAddress")
+
+	// X509UsernameSourceUserPrincipalName uses the first Microsoft user principal name (UPN) of the subject
+	// alternative names, which is commonly found in the certificates of smart cards.
+	X509UsernameSourceUserPrincipalName = X509UsernameSource("UserPrincipalName")
+)
+
+// X509GroupsSource is a part of a client certificate which can be used as the groups.
+// +kubebuilder:validation:Enum=Organization;OrganizationalUnit;None
+type X509GroupsSource string
+
+const (
+	// X509GroupsSourceOrganization uses the organizations (O) of the subject.
+	X509GroupsSourceOrganization = X509GroupsSource("Organization")
+
+	// X509GroupsSourceOrganizationalUnit uses the organizational units (OU) of the subject.
+	X509GroupsSourceOrganizationalUnit = X509GroupsSource("OrganizationalUnit")
+
+	// X509GroupsSourceNone does not give the users any groups.
+	X509GroupsSourceNone = X509GroupsSource("None")
+)
+
+// X509ClientCertificateClaims describes which parts of the client certificates are used as the usernames and groups.
+type X509ClientCertificateClaims struct {
+	// Username is the part of the client certificate which is used as the username. The client certificates which
+	// do not have a value for it are rejected.
+	// +kubebuilder:default:="CommonName"
+	// +optional
+	Username X509UsernameSource `json:"username,omitempty"`
+
+	// Groups is the part of the client certificate which is used as the groups.
+	// +kubebuilder:default:="Organization"
+	// +optional
+	Groups X509GroupsSource `json:"groups,omitempty"`
+}
+
+// X509ClientCertificateAuthenticator describes the configuration of an X.509 client certificate authenticator,
+// which lets users exchange proof of the possession of a client certificate, e.g. one which is stored on a smart card,
+// for cluster credentials.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped;pinniped-authenticator;pinniped-authenticators,scope=Cluster
+// +kubebuilder:printcolumn:name="Audience",type=string,JSONPath=`.spec.audience`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type X509ClientCertificateAuthenticator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec for configuring the authenticator.
+	Spec X509ClientCertificateAuthenticatorSpec `json:"spec"`
+
+	// Status of the authenticator.
+	Status X509ClientCertificateAuthenticatorStatus `json:"status,omitempty"`
+}
+
+// List of X509ClientCertificateAuthenticator objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type X509ClientCertificateAuthenticatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []X509ClientCertificateAuthenticator `json:"items"`
+}
@@ -1,11 +1,70 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
 
+// LDAPConnectionProtocol describes how to establish the TLS connection to an LDAP server.
+// +kubebuilder:validation:Enum=ldaps;startTLS;auto
+type LDAPConnectionProtocol string
+
+const (
+	// LDAPConnectionProtocolLDAPS connects using TLS from the start, usually on port 636.
+	LDAPConnectionProtocolLDAPS = LDAPConnectionProtocol("ldaps")
+
+	// LDAPConnectionProtocolStartTLS connects without TLS and then upgrades the connection using the StartTLS
+	// extended operation, usually on port 389.
+	LDAPConnectionProtocolStartTLS = LDAPConnectionProtocol("startTLS")
+
+	// LDAPConnectionProtocolAuto tries ldaps first and falls back to startTLS when ldaps fails.
+	LDAPConnectionProtocolAuto = LDAPConnectionProtocol("auto")
+)
+
 // Configuration for TLS parameters related to identity provider integration.
 type TLSSpec struct {
 	// X.509 Certificate Authority (base64-encoded PEM bundle). If omitted, a default set of system roots will be trusted.
 	// +optional
 	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+
+	// CertificateAuthorityDataSource is a reference to a Secret or ConfigMap which holds the X.509 Certificate
+	// Authority as a PEM bundle. Changes to the referenced Secret or ConfigMap are loaded automatically, which makes
+	// it easier to rotate the CA bundle than when using CertificateAuthorityData. Only one of CertificateAuthorityData
+	// and CertificateAuthorityDataSource may be specified.
+	// +optional
+	CertificateAuthorityDataSource *CertificateAuthorityDataSourceSpec `json:"certificateAuthorityDataSource,omitempty"`
+
+	// ConnectionProtocol determines how to connect to an LDAP or Active Directory server, and is ignored by
+	// other types of identity providers. Use "ldaps" to always connect using TLS, "startTLS" to always
+	// connect using StartTLS, or "auto" to try ldaps first and fall back to startTLS when ldaps fails.
+	// When set to "ldaps" or "startTLS", the other protocol is never attempted.
+	// Defaults to "auto".
+	// +optional
+	ConnectionProtocol LDAPConnectionProtocol `json:"connectionProtocol,omitempty"`
+}
+
+// CertificateAuthorityDataSourceKind is the kind of object which holds a CA bundle.
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+type CertificateAuthorityDataSourceKind string
+
+const (
+	// CertificateAuthorityDataSourceKindSecret reads the CA bundle from a Secret.
+	CertificateAuthorityDataSourceKindSecret = CertificateAuthorityDataSourceKind("Secret")
+
+	// CertificateAuthorityDataSourceKindConfigMap reads the CA bundle from a ConfigMap.
+	CertificateAuthorityDataSourceKindConfigMap = CertificateAuthorityDataSourceKind("ConfigMap")
+)
+
+// CertificateAuthorityDataSourceSpec is a reference to a key of a Secret or ConfigMap which holds a CA bundle.
+type CertificateAuthorityDataSourceSpec struct {
+	// Kind is the kind of object which holds the CA bundle, either "Secret" or "ConfigMap".
+	// Secrets must be of type "Opaque" or "kubernetes.io/tls".
+	Kind CertificateAuthorityDataSourceKind `json:"kind"`
+
+	// Name is the name of the Secret or ConfigMap, which must be in the same namespace as the identity provider.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key in the Secret or ConfigMap whose value is the CA bundle as PEM, e.g. "ca.crt".
+	// The value is not base64-encoded, other than the encoding which Kubernetes always uses for Secret data.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
 }
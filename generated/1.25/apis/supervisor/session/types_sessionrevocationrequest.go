@@ -0,0 +1,45 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SessionRevocationRequest can be used to revoke the active downstream sessions of a particular user, client, or
+// identity provider, e.g. to quickly terminate every session of a compromised user. The access and refresh tokens
+// of the revoked sessions can no longer be used, and the tokens which they hold for upstream OIDC identity providers
+// are revoked too.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionRevocationRequest struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec SessionRevocationRequestSpec
+
+	// +optional
+	Status SessionRevocationRequestStatus
+}
+
+// Spec of the SessionRevocationRequest.
+type SessionRevocationRequestSpec struct {
+	// Selects which sessions to revoke. At least one field of the selector must be specified.
+	Selector SessionSelector
+}
+
+// Status of the SessionRevocationRequest.
+type SessionRevocationRequestStatus struct {
+	// The sessions which were revoked.
+	RevokedSessions []DownstreamSession
+}
+
+// SessionRevocationRequestList is a list of SessionRevocationRequest objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionRevocationRequestList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	// Items is a list of SessionRevocationRequest.
+	Items []SessionRevocationRequest
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SessionRevocationRequest can be used to revoke the active downstream sessions of a particular user, client, or
+// identity provider, e.g. to quickly terminate every session of a compromised user. The access and refresh tokens
+// of the revoked sessions can no longer be used, and the tokens which they hold for upstream OIDC identity providers
+// are revoked too.
+// +genclient
+// +genclient:onlyVerbs=create
+// +kubebuilder:subresource:status
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionRevocationRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SessionRevocationRequestSpec `json:"spec"`
+
+	// +optional
+	Status SessionRevocationRequestStatus `json:"status"`
+}
+
+// Spec of the SessionRevocationRequest.
+type SessionRevocationRequestSpec struct {
+	// Selects which sessions to revoke. At least one field of the selector must be specified.
+	Selector SessionSelector `json:"selector"`
+}
+
+// Status of the SessionRevocationRequest.
+type SessionRevocationRequestStatus struct {
+	// The sessions which were revoked.
+	// +listType=atomic
+	RevokedSessions []DownstreamSession `json:"revokedSessions,omitempty"`
+}
+
+// SessionRevocationRequestList is a list of SessionRevocationRequest objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SessionRevocationRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of SessionRevocationRequest.
+	Items []SessionRevocationRequest `json:"items"`
+}
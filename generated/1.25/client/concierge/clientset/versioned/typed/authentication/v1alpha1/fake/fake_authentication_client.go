@@ -19,10 +19,18 @@ func (c *FakeAuthenticationV1alpha1) JWTAuthenticators() v1alpha1.JWTAuthenticat
 	return &FakeJWTAuthenticators{c}
 }
 
+func (c *FakeAuthenticationV1alpha1) ServiceAccountTokenAuthenticators() v1alpha1.ServiceAccountTokenAuthenticatorInterface {
+	return &FakeServiceAccountTokenAuthenticators{c}
+}
+
 func (c *FakeAuthenticationV1alpha1) WebhookAuthenticators() v1alpha1.WebhookAuthenticatorInterface {
 	return &FakeWebhookAuthenticators{c}
 }
 
+func (c *FakeAuthenticationV1alpha1) X509ClientCertificateAuthenticators() v1alpha1.X509ClientCertificateAuthenticatorInterface {
+	return &FakeX509ClientCertificateAuthenticators{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeAuthenticationV1alpha1) RESTClient() rest.Interface {
@@ -13,8 +13,12 @@ import (
 type Interface interface {
 	// JWTAuthenticators returns a JWTAuthenticatorInformer.
 	JWTAuthenticators() JWTAuthenticatorInformer
+	// ServiceAccountTokenAuthenticators returns a ServiceAccountTokenAuthenticatorInformer.
+	ServiceAccountTokenAuthenticators() ServiceAccountTokenAuthenticatorInformer
 	// WebhookAuthenticators returns a WebhookAuthenticatorInformer.
 	WebhookAuthenticators() WebhookAuthenticatorInformer
+	// X509ClientCertificateAuthenticators returns a X509ClientCertificateAuthenticatorInformer.
+	X509ClientCertificateAuthenticators() X509ClientCertificateAuthenticatorInformer
 }
 
 type version struct {
@@ -33,7 +37,17 @@ func (v *version) JWTAuthenticators() JWTAuthenticatorInformer {
 	return &jWTAuthenticatorInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// ServiceAccountTokenAuthenticators returns a ServiceAccountTokenAuthenticatorInformer.
+func (v *version) ServiceAccountTokenAuthenticators() ServiceAccountTokenAuthenticatorInformer {
+	return &serviceAccountTokenAuthenticatorInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // WebhookAuthenticators returns a WebhookAuthenticatorInformer.
 func (v *version) WebhookAuthenticators() WebhookAuthenticatorInformer {
 	return &webhookAuthenticatorInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// X509ClientCertificateAuthenticators returns a X509ClientCertificateAuthenticatorInformer.
+func (v *version) X509ClientCertificateAuthenticators() X509ClientCertificateAuthenticatorInformer {
+	return &x509ClientCertificateAuthenticatorInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
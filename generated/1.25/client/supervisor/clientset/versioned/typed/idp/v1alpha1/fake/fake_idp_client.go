@@ -19,10 +19,26 @@ func (c *FakeIDPV1alpha1) ActiveDirectoryIdentityProviders(namespace string) v1a
 	return &FakeActiveDirectoryIdentityProviders{c, namespace}
 }
 
+func (c *FakeIDPV1alpha1) GitHubIdentityProviders(namespace string) v1alpha1.GitHubIdentityProviderInterface {
+	return &FakeGitHubIdentityProviders{c, namespace}
+}
+
+func (c *FakeIDPV1alpha1) GitLabIdentityProviders(namespace string) v1alpha1.GitLabIdentityProviderInterface {
+	return &FakeGitLabIdentityProviders{c, namespace}
+}
+
+func (c *FakeIDPV1alpha1) KerberosIdentityProviders(namespace string) v1alpha1.KerberosIdentityProviderInterface {
+	return &FakeKerberosIdentityProviders{c, namespace}
+}
+
 func (c *FakeIDPV1alpha1) LDAPIdentityProviders(namespace string) v1alpha1.LDAPIdentityProviderInterface {
 	return &FakeLDAPIdentityProviders{c, namespace}
 }
 
+func (c *FakeIDPV1alpha1) OAuth2IdentityProviders(namespace string) v1alpha1.OAuth2IdentityProviderInterface {
+	return &FakeOAuth2IdentityProviders{c, namespace}
+}
+
 func (c *FakeIDPV1alpha1) OIDCIdentityProviders(namespace string) v1alpha1.OIDCIdentityProviderInterface {
 	return &FakeOIDCIdentityProviders{c, namespace}
 }
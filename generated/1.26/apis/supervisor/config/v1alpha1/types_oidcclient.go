@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -21,18 +21,37 @@ const (
 // +kubebuilder:validation:Pattern=`^https://.+|^http://(127\.0\.0\.1|\[::1\])(:\d+)?/`
 type RedirectURI string
 
-// +kubebuilder:validation:Enum="authorization_code";"refresh_token";"urn:ietf:params:oauth:grant-type:token-exchange"
+// +kubebuilder:validation:Enum="authorization_code";"refresh_token";"urn:ietf:params:oauth:grant-type:token-exchange";"urn:ietf:params:oauth:grant-type:device_code";"client_credentials"
 type GrantType string
 
 // +kubebuilder:validation:Enum="openid";"offline_access";"username";"groups";"pinniped:request-audience"
 type Scope string
 
+// AccessTokenFormat is the format of the access tokens which are issued to an OIDCClient.
+// +kubebuilder:validation:Enum=Opaque;JWT
+type AccessTokenFormat string
+
+const (
+	// AccessTokenFormatOpaque is the format of opaque access tokens, which can only be validated by introspecting them.
+	AccessTokenFormatOpaque AccessTokenFormat = "Opaque"
+
+	// AccessTokenFormatJWT is the format of signed JWT access tokens, which can also be validated locally.
+	AccessTokenFormatJWT AccessTokenFormat = "JWT"
+)
+
+// TokenExchangeAudience is an audience which may be requested during an RFC8693 token exchange, e.g. the audience
+// of the JWTAuthenticator of a workload cluster.
+// +kubebuilder:validation:MinLength=1
+type TokenExchangeAudience string
+
 // OIDCClientSpec is a struct that describes an OIDCClient.
 type OIDCClientSpec struct {
 	// allowedRedirectURIs is a list of the allowed redirect_uri param values that should be accepted during OIDC flows with this
 	// client. Any other uris will be rejected.
 	// Must be a URI with the https scheme, unless the hostname is 127.0.0.1 or ::1 which may use the http scheme.
 	// Port numbers are not required for 127.0.0.1 or ::1 and are ignored when checking for a matching redirect_uri.
+	// The hostname of an https URI may start with a "*." wildcard, e.g. https://*.preview.example.com/callback, which
+	// matches exactly one DNS label in its place. The wildcard must be followed by at least two more labels.
 	// +listType=set
 	// +kubebuilder:validation:MinItems=1
 	AllowedRedirectURIs []RedirectURI `json:"allowedRedirectURIs"`
@@ -48,6 +67,12 @@ type OIDCClientSpec struct {
 	// - urn:ietf:params:oauth:grant-type:token-exchange: allows the client to perform RFC8693 token exchange,
 	//   which is a step in the process to be able to get a cluster credential for the user.
 	//   This grant must be listed if allowedScopes lists pinniped:request-audience.
+	// - urn:ietf:params:oauth:grant-type:device_code: allows the client to perform RFC8628 device authorization grants,
+	//   i.e. allows the client to log in users on hosts without a web browser, by asking the users to complete
+	//   their login in a web browser on another device.
+	// - client_credentials: allows the client to perform client credentials grants, i.e. allows the client to get
+	//   access tokens for itself instead of for a user. This grant is meant for trusted services which need to call
+	//   other services. See clientCredentials.
 	// +listType=set
 	// +kubebuilder:validation:MinItems=1
 	AllowedGrantTypes []GrantType `json:"allowedGrantTypes"`
@@ -71,6 +96,126 @@ type OIDCClientSpec struct {
 	// +listType=set
 	// +kubebuilder:validation:MinItems=1
 	AllowedScopes []Scope `json:"allowedScopes"`
+
+	// allowedTokenExchangeAudiences is a list of the audiences which this client may request during an RFC8693 token
+	// exchange. Any other audiences will be rejected. When empty, the allowedTokenExchangeAudiences of the
+	// FederationDomain are used instead, and when those are also empty, any audience may be requested, other than the
+	// reserved audiences which may never be requested.
+	// This list is only used when allowedGrantTypes lists urn:ietf:params:oauth:grant-type:token-exchange.
+	// +optional
+	// +listType=set
+	AllowedTokenExchangeAudiences []TokenExchangeAudience `json:"allowedTokenExchangeAudiences,omitempty"`
+
+	// allowedPostLogoutRedirectURIs is a list of the allowed post_logout_redirect_uri param values that should be
+	// accepted by the end session endpoint of the FederationDomain when this client asks the Supervisor to log out a user.
+	// Any other uris will be rejected. When empty, the end session endpoint will still log out the user, but it will
+	// not redirect the user's browser back to this client afterwards.
+	// Must be a URI with the https scheme, unless the hostname is 127.0.0.1 or ::1 which may use the http scheme.
+	// Unlike allowedRedirectURIs, the post_logout_redirect_uri param must exactly match one of these values.
+	// +optional
+	// +listType=set
+	AllowedPostLogoutRedirectURIs []RedirectURI `json:"allowedPostLogoutRedirectURIs,omitempty"`
+
+	// backchannelLogoutURI is the URI to which the Supervisor sends logout tokens, as defined by the OIDC
+	// Back-Channel Logout specification, when a downstream session of this client ends before its tokens expire
+	// or when its session storage is garbage collected. For example, this happens when an upstream refresh fails.
+	// The logout token is signed by the FederationDomain and it contains the sid claim of the ID tokens of the session.
+	// When empty, the Supervisor does not notify this client when its downstream sessions end.
+	// Must be a URI with the https scheme, unless the hostname is 127.0.0.1 or ::1 which may use the http scheme.
+	// +optional
+	BackchannelLogoutURI RedirectURI `json:"backchannelLogoutURI,omitempty"`
+
+	// tokenLifetimes are the lifetimes of the tokens which are issued to this client. Each lifetime which is not
+	// configured here is taken from the tokenLifetimes of the FederationDomain, or from the Supervisor's defaults
+	// when the FederationDomain does not configure it either.
+	// +optional
+	TokenLifetimes TokenLifetimes `json:"tokenLifetimes,omitempty"`
+
+	// groupsFilter limits which of the groups of a user are included in the ID tokens which are issued to this
+	// client, including the ID tokens which this client receives from RFC8693 token exchanges. This keeps the ID tokens
+	// small when users belong to many groups which this client does not need to know about. When no names or prefixes
+	// are configured, the ID tokens include all of the groups of the user.
+	// +optional
+	GroupsFilter GroupsFilter `json:"groupsFilter,omitempty"`
+
+	// accessTokenFormat is the format of the access tokens which are issued to this client.
+	// Opaque access tokens can only be validated by the token introspection endpoint of the FederationDomain.
+	// JWT access tokens, as defined by RFC9068, are signed by the FederationDomain's active signing key, so
+	// resource servers can also validate them locally using the keys which are published at the JWKS endpoint of the
+	// FederationDomain. Their aud claim is the client ID of this client, and they also contain the client_id, scope,
+	// and the username and groups claims when the username and groups scopes were granted. Because a JWT access token
+	// remains valid for local validation even after it is revoked, its lifetime is at most 300 seconds, even when
+	// the configured access token lifetime is longer.
+	// +optional
+	// +kubebuilder:default=Opaque
+	AccessTokenFormat AccessTokenFormat `json:"accessTokenFormat,omitempty"`
+
+	// requireConsent asks users whether they allow this client to receive the scopes which it requested, on a consent page
+	// which the Supervisor shows after the users have logged in, before this client receives an authorization code. The
+	// decisions of the users are remembered, so each user is only asked again when this client requests scopes which the
+	// user did not allow before, or when the remembered decision has expired. When false, which is the default, users are
+	// never asked, which is suitable for clients which are trusted by the administrator.
+	// +optional
+	RequireConsent bool `json:"requireConsent,omitempty"`
+
+	// clientCredentials configures the access tokens which this client may get for itself using the
+	// client_credentials grant. It is only used when allowedGrantTypes lists client_credentials.
+	// +optional
+	ClientCredentials ClientCredentials `json:"clientCredentials,omitempty"`
+}
+
+// ClientCredentials configures the client_credentials grant of an OIDCClient. The access tokens which are issued by
+// this grant identify the client itself rather than a user, so they never contain the username or groups claims and
+// they are marked with the identity_type claim (or introspection response member) set to "client".
+type ClientCredentials struct {
+	// allowedScopes is a list of the scope param values which this client may request during client_credentials
+	// grants. These are the scopes which are understood by the resource servers which the client calls. Requested
+	// scopes which are not listed here are rejected. The scopes which are about users, i.e. openid, offline_access,
+	// username, groups, and pinniped:request-audience, may not be listed.
+	// +optional
+	// +listType=set
+	AllowedScopes []string `json:"allowedScopes,omitempty"`
+
+	// allowedAudiences is a list of the audience param values which this client may request during
+	// client_credentials grants. Requested audiences which are not listed here are rejected. Audiences containing
+	// ".pinniped.dev" and the pinniped-cli audience are reserved and may not be listed. When no audience is requested,
+	// the audience of the access token is the client ID of this client.
+	// +optional
+	// +listType=set
+	AllowedAudiences []string `json:"allowedAudiences,omitempty"`
+}
+
+// GroupsFilter selects groups by their exact names or by the prefixes of their names. A group is selected when it
+// matches any of the names or any of the prefixes.
+type GroupsFilter struct {
+	// names are the exact names of the groups which are selected.
+	// +optional
+	// +listType=set
+	Names []string `json:"names,omitempty"`
+
+	// prefixes select the groups whose names start with any of these prefixes, e.g. "team-" selects "team-a".
+	// +optional
+	// +listType=set
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// TokenLifetimes describes the lifetimes of the tokens which are issued by the Supervisor.
+type TokenLifetimes struct {
+	// idTokenSeconds is the lifetime of ID tokens, in seconds. The Supervisor's default is 120 seconds.
+	// Must be between 120 and 3600.
+	// +optional
+	IDTokenSeconds *int32 `json:"idTokenSeconds,omitempty"`
+
+	// accessTokenSeconds is the lifetime of access tokens, in seconds. The Supervisor's default is 120 seconds.
+	// Must be between 120 and 3600.
+	// +optional
+	AccessTokenSeconds *int32 `json:"accessTokenSeconds,omitempty"`
+
+	// refreshTokenSeconds is the lifetime of refresh tokens, in seconds. Each refresh issues a new refresh token
+	// with a new lifetime. The Supervisor's default is 32400 seconds (9 hours).
+	// Must be between 600 and 32400, and must not be shorter than accessTokenSeconds when both are configured.
+	// +optional
+	RefreshTokenSeconds *int32 `json:"refreshTokenSeconds,omitempty"`
 }
 
 // OIDCClientStatus is a struct that describes the actual state of an OIDCClient.
@@ -0,0 +1,54 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SessionSelector selects downstream sessions. A session is selected when it matches all the specified fields.
+type SessionSelector struct {
+	// The username of the user to whom the sessions belong, as it appears in the ID tokens issued by the Supervisor.
+	// +optional
+	Username string
+
+	// The client ID of the client to which the sessions belong.
+	// +optional
+	ClientID string
+
+	// The name of the identity provider using which the sessions were started.
+	// +optional
+	IdentityProviderName string
+}
+
+// DownstreamSession describes a downstream session of the Supervisor. It never includes any tokens of the session.
+type DownstreamSession struct {
+	// The ID of the session, which is the ID of the authorization request which started the session.
+	ID string
+
+	// The username of the user as it appears in the ID tokens issued by the Supervisor.
+	Username string
+
+	// The username of the user in the identity provider, when known.
+	UpstreamUsername string
+
+	// The client ID of the client to which the session belongs.
+	ClientID string
+
+	// The name of the identity provider using which the session was started.
+	IdentityProviderName string
+
+	// The type of the identity provider using which the session was started, e.g. oidc or ldap.
+	IdentityProviderType string
+
+	// The scopes which were granted to the client.
+	Scopes []string
+
+	// When the session was started.
+	StartedAt metav1.Time
+
+	// When the session will end, when the lifetime of sessions is limited by the FederationDomain.
+	// +optional
+	ExpiresAt *metav1.Time
+}
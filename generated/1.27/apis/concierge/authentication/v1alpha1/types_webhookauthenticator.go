@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -25,6 +25,82 @@ type WebhookAuthenticatorSpec struct {
 	// TLS configuration.
 	// +optional
 	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// Cache configures an in-memory cache of the results of the webhook, keyed by a hash of the token, so that
+	// repeated requests with the same token do not each call the webhook. When not configured, every token is
+	// sent to the webhook. Note that a cached result is used until it expires, so e.g. revoking a token at the
+	// webhook only takes effect after its cached result expired.
+	// +optional
+	Cache *WebhookAuthenticatorCache `json:"cache,omitempty"`
+
+	// TimeoutSeconds is how long each call of the webhook may take before it is abandoned. When not configured,
+	// 30 seconds is used.
+	// Must be between 1 and 60.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Retry configures how often a failed call of the webhook is retried before the token is rejected. When not
+	// configured, the webhook is called up to 5 times, waiting 500 milliseconds before the first retry and 1.5
+	// times longer before each following retry.
+	// +optional
+	Retry *WebhookAuthenticatorRetry `json:"retry,omitempty"`
+
+	// CircuitBreaker stops calling the webhook for a while after it failed repeatedly, so that tokens are rejected
+	// immediately instead of waiting for the webhook while it is down. The state of the circuit breaker is reported
+	// by the WebhookAvailable condition. When not configured, the webhook is always called.
+	// +optional
+	CircuitBreaker *WebhookAuthenticatorCircuitBreaker `json:"circuitBreaker,omitempty"`
+}
+
+// WebhookAuthenticatorCache configures how the results of a webhook are cached.
+type WebhookAuthenticatorCache struct {
+	// ttlSeconds is how long a successful authentication is cached. When not configured, 120 seconds is used.
+	// Must be between 1 and 3600.
+	// +optional
+	TTLSeconds *int32 `json:"ttlSeconds,omitempty"`
+
+	// denialTTLSeconds is how long a denied authentication is cached, so that repeated requests with an invalid
+	// token do not each call the webhook. When not configured, 30 seconds is used. Setting it to 0 disables the
+	// caching of denials. Errors which happen while calling the webhook are never cached.
+	// Must be between 0 and 3600.
+	// +optional
+	DenialTTLSeconds *int32 `json:"denialTTLSeconds,omitempty"`
+
+	// maxSize is the maximum number of results which are cached. When the cache is full, the least recently used
+	// result is removed. When not configured, 1000 is used.
+	// Must be between 1 and 100000.
+	// +optional
+	MaxSize *int32 `json:"maxSize,omitempty"`
+}
+
+// WebhookAuthenticatorRetry configures how the calls of a webhook are retried.
+type WebhookAuthenticatorRetry struct {
+	// attempts is the maximum number of times that the webhook is called for each token, including the first
+	// call. Setting it to 1 disables retries. When not configured, 5 is used.
+	// Must be between 1 and 10.
+	// +optional
+	Attempts *int32 `json:"attempts,omitempty"`
+
+	// initialBackoffMilliseconds is how long to wait before the first retry. Each following retry waits 1.5 times
+	// longer than the previous one. When not configured, 500 milliseconds is used.
+	// Must be between 1 and 10000.
+	// +optional
+	InitialBackoffMilliseconds *int32 `json:"initialBackoffMilliseconds,omitempty"`
+}
+
+// WebhookAuthenticatorCircuitBreaker configures when a webhook is considered to be down.
+type WebhookAuthenticatorCircuitBreaker struct {
+	// failureThreshold is how many consecutive calls of the webhook must fail, after their retries, before the
+	// webhook is no longer called. When not configured, 5 is used.
+	// Must be between 1 and 100.
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// openSeconds is how long the webhook is not called after it failed repeatedly. Afterwards, the webhook is
+	// called for a single token to check whether it has recovered. When not configured, 30 seconds is used.
+	// Must be between 1 and 3600.
+	// +optional
+	OpenSeconds *int32 `json:"openSeconds,omitempty"`
 }
 
 // WebhookAuthenticator describes the configuration of a webhook authenticator.
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -31,6 +31,18 @@ type OIDCClientSecretRequestSpec struct {
 	// Revoke the old client secrets associated with the OIDCClient referenced by the metadata.name field.
 	// +optional
 	RevokeOldSecrets bool `json:"revokeOldSecrets"`
+
+	// When generating a new client secret, keep the old client secrets valid for this many seconds, after which they
+	// expire, so the clients can be updated to use the new client secret without downtime. When zero, the old client
+	// secrets remain valid until they are revoked. Cannot be used together with revokeOldSecrets.
+	// +optional
+	OldSecretsLifetimeSeconds int64 `json:"oldSecretsLifetimeSeconds,omitempty"`
+
+	// Revoke the individual client secrets which have these hashes, as listed in the status of a previous
+	// OIDCClientSecretRequest.
+	// +optional
+	// +listType=atomic
+	RevokeSecretHashes []string `json:"revokeSecretHashes,omitempty"`
 }
 
 // Status of the OIDCClientSecretRequest.
@@ -40,6 +52,27 @@ type OIDCClientSecretRequestStatus struct {
 
 	// The total number of client secrets associated with the OIDCClient referenced by the metadata.name field.
 	TotalClientSecrets int `json:"totalClientSecrets"`
+
+	// The client secrets associated with the OIDCClient referenced by the metadata.name field which have not expired
+	// or been revoked, most recent first.
+	// +optional
+	// +listType=atomic
+	ClientSecrets []ClientSecretStatus `json:"clientSecrets,omitempty"`
+}
+
+// ClientSecretStatus describes a client secret of an OIDCClient. It never includes the unencrypted client secret.
+type ClientSecretStatus struct {
+	// The bcrypt hash of the client secret, which identifies the client secret when revoking it.
+	Hash string `json:"hash"`
+
+	// When the client secret was generated. Not known for client secrets which were generated by older versions of
+	// the Supervisor.
+	// +optional
+	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+
+	// When the client secret expires. Not set when the client secret remains valid until it is revoked.
+	// +optional
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
 }
 
 // OIDCClientSecretRequestList is a list of OIDCClientSecretRequest objects.
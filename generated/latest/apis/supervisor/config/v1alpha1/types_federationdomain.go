@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -42,6 +42,75 @@ type FederationDomainTLSSpec struct {
 	SecretName string `json:"secretName,omitempty"`
 }
 
+// FederationDomainTransformsExpression defines a transform or policy expression.
+type FederationDomainTransformsExpression struct {
+	// Type determines the type of the expression. It must be one of the supported types.
+	// The "username/v1" type must evaluate to a non-empty string, which becomes the user's username.
+	// The "groups/v1" type must evaluate to a list of strings, which become the user's group memberships.
+	// The "policy/v1" type must evaluate to a boolean. When it is false, the user's authentication is rejected.
+	// +kubebuilder:validation:Enum=policy/v1;username/v1;groups/v1
+	Type string `json:"type"`
+
+	// Expression is a CEL expression that will be evaluated based on the Type.
+	// The expression may use the variables "username", which is a string, and "groups", which is a list of strings.
+	// These hold the user's identity after any previous expressions in the list have been evaluated.
+	// See https://github.com/google/cel-spec for more information about the expression language.
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+
+	// Message is only used when Type is policy/v1. It defines an error message to be used when the policy rejects
+	// an authentication attempt. When empty, a default message will be used.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FederationDomainTransforms defines identity transformations and policies for a FederationDomain.
+type FederationDomainTransforms struct {
+	// Expressions are an optional list of transforms and policies to be evaluated in order against the identity of
+	// each user who logs in using any upstream identity provider, before any tokens are issued to them. Each expression
+	// sees the username and groups which resulted from the previous expressions. When a policy rejects an
+	// authentication attempt, the remaining expressions are not evaluated. The same expressions are evaluated again
+	// during each refresh of the user's session.
+	// +optional
+	Expressions []FederationDomainTransformsExpression `json:"expressions,omitempty"`
+}
+
+// FederationDomainIdentityProvider describes how an identity provider is made available in this FederationDomain.
+type FederationDomainIdentityProvider struct {
+	// DisplayName is the name of this identity provider as it will appear to clients. This name ends up in the
+	// kubeconfig of end users, so changing the name of an identity provider that is in use by end users will be a
+	// disruptive change for those users. The display names must be unique within a FederationDomain.
+	// +kubebuilder:validation:MinLength=1
+	DisplayName string `json:"displayName"`
+
+	// ObjectRef is a reference to a Pinniped identity provider resource in the same namespace. A valid reference is
+	// required. When the reference cannot be resolved, the FederationDomain will not be served.
+	// The APIGroup must be the API group of the Supervisor's identity provider resources (idp.supervisor.pinniped.dev
+	// by default) and the Kind must be one of OIDCIdentityProvider, OAuth2IdentityProvider, GitHubIdentityProvider,
+	// GitLabIdentityProvider, LDAPIdentityProvider, ActiveDirectoryIdentityProvider, or KerberosIdentityProvider.
+	ObjectRef corev1.TypedLocalObjectReference `json:"objectRef"`
+}
+
+// FederationDomainClaimMapping copies a value from the identity of a user into a claim of their ID tokens.
+type FederationDomainClaimMapping struct {
+	// Claim is the name of the top-level claim in the ID tokens. It must not be a claim which is set by the
+	// Supervisor, such as iss, sub, aud, exp, iat, nbf, jti, rat, auth_time, nonce, acr, amr, azp, at_hash, c_hash,
+	// sid, username, groups, or additionalClaims. The claims must be unique within a FederationDomain.
+	// +kubebuilder:validation:MinLength=1
+	Claim string `json:"claim"`
+
+	// Source is the value which is copied into the claim. It must be one of the following:
+	// "username" or "groups", which are the username or groups of the user after the identity transformations,
+	// and which are only copied when the ID token contains the username or groups claim;
+	// "upstreamSubject" or "upstreamIssuer", which are the sub or iss claims of an upstream OIDC identity provider;
+	// "upstreamProviderName" or "upstreamProviderType", which are the name and type of the identity provider which
+	// the user used to log in; or "additionalClaims.<name>", which is one of the additional claims which were mapped
+	// from the upstream ID token or from the LDAP attributes of the user by the identity provider.
+	// When the source has no value for a user, the claim is left out of their ID tokens.
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+}
+
 // FederationDomainSpec is a struct that describes an OIDC Provider.
 type FederationDomainSpec struct {
 	// Issuer is the OIDC Provider's issuer, per the OIDC Discovery Metadata document, as well as the
@@ -58,6 +127,123 @@ type FederationDomainSpec struct {
 	// TLS configures how this FederationDomain is served over Transport Layer Security (TLS).
 	// +optional
 	TLS *FederationDomainTLSSpec `json:"tls,omitempty"`
+
+	// IdentityProviders is the optional list of identity providers which are available for use by this
+	// FederationDomain. Each is referenced by name and given a display name, which is the name by which clients
+	// such as the Pinniped CLI refer to it.
+	//
+	// When this list is empty, every identity provider in the Supervisor's namespace is available for use by this
+	// FederationDomain, using the name of its resource as its display name.
+	// +optional
+	IdentityProviders []FederationDomainIdentityProvider `json:"identityProviders,omitempty"`
+
+	// Transforms configures identity transformations and policies which are applied to the identities of the users
+	// who log in using this FederationDomain.
+	// +optional
+	Transforms FederationDomainTransforms `json:"transforms,omitempty"`
+
+	// TokenLifetimes are the default lifetimes of the tokens which are issued by this FederationDomain. They apply to
+	// every client, including the Pinniped CLI, unless an OIDCClient configures its own tokenLifetimes. Each lifetime
+	// which is not configured here uses the Supervisor's default.
+	// +optional
+	TokenLifetimes TokenLifetimes `json:"tokenLifetimes,omitempty"`
+
+	// SessionLifetimes limits how long the sessions of the users who log in using this FederationDomain may be
+	// refreshed. When a session has ended, its refresh token is rejected and the user must log in again.
+	// +optional
+	SessionLifetimes SessionLifetimes `json:"sessionLifetimes,omitempty"`
+
+	// AllowedTokenExchangeAudiences is the default list of the audiences which may be requested during an RFC8693
+	// token exchange. It applies to every client, including the Pinniped CLI, unless an OIDCClient configures its
+	// own allowedTokenExchangeAudiences. When empty, any audience may be requested, other than the reserved audiences
+	// which may never be requested.
+	// +optional
+	// +listType=set
+	AllowedTokenExchangeAudiences []TokenExchangeAudience `json:"allowedTokenExchangeAudiences,omitempty"`
+
+	// ClaimMappings copy values from the identities of the users who log in using this FederationDomain into
+	// top-level claims of their ID tokens, including the ID tokens which are issued during RFC8693 token exchanges.
+	// The values are copied again each time that new ID tokens are issued, e.g. during each refresh.
+	// +optional
+	ClaimMappings []FederationDomainClaimMapping `json:"claimMappings,omitempty"`
+
+	// IDTokenSigningAlgorithm is the JWS algorithm which this FederationDomain uses to sign ID tokens and which it
+	// advertises in its OIDC discovery document. When it is changed, a new signing key of the matching type is
+	// generated. When not configured, ES256 is used.
+	// +kubebuilder:validation:Enum=RS256;ES256;EdDSA
+	// +optional
+	IDTokenSigningAlgorithm string `json:"idTokenSigningAlgorithm,omitempty"`
+
+	// SigningKeyRotation configures how often the signing key of this FederationDomain is replaced by a new key,
+	// and how long the replaced key remains in the JWKS of this FederationDomain so that the tokens which it signed
+	// can still be verified. When not configured, the signing key is never rotated.
+	// +optional
+	SigningKeyRotation SigningKeyRotation `json:"signingKeyRotation,omitempty"`
+
+	// CORS allows web applications which are served from other origins, e.g. single-page applications which use
+	// PKCE, to call the discovery, JWKS, authorization, and token endpoints of this FederationDomain from the
+	// browser. Cross-origin requests never include cookies or other credentials. When not configured, no
+	// cross-origin requests are allowed.
+	// +optional
+	CORS CORSPolicy `json:"cors,omitempty"`
+}
+
+// CORSMethod is an HTTP method which may be used in cross-origin requests.
+// +kubebuilder:validation:Enum=GET;POST
+type CORSMethod string
+
+// CORSPolicy describes which cross-origin requests are allowed by a FederationDomain.
+type CORSPolicy struct {
+	// allowedOrigins are the origins of the web applications which may make cross-origin requests, e.g.
+	// https://app.example.com. Each origin must have an http or https scheme, must not have a path, and must be
+	// lowercase. Origins are compared exactly, so wildcards are not supported.
+	// +optional
+	// +listType=set
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+
+	// allowedMethods are the HTTP methods which may be used in cross-origin requests. GET allows the discovery,
+	// JWKS, and authorization endpoints to be called, and POST allows the token endpoint to be called. When not
+	// configured, both are allowed.
+	// +optional
+	// +listType=set
+	AllowedMethods []CORSMethod `json:"allowedMethods,omitempty"`
+
+	// maxAgeSeconds is how long browsers may cache the responses to their preflight requests. When not configured,
+	// browsers use their own default, which is usually 5 seconds.
+	// Must be between 0 and 86400.
+	// +optional
+	MaxAgeSeconds *int32 `json:"maxAgeSeconds,omitempty"`
+}
+
+// SessionLifetimes describes how long a downstream session may last.
+type SessionLifetimes struct {
+	// idleTimeoutSeconds ends a session when it was not refreshed for this many seconds. When not configured,
+	// the session only ends when its refresh token expires.
+	// Must be between 300 and 32400.
+	// +optional
+	IdleTimeoutSeconds *int32 `json:"idleTimeoutSeconds,omitempty"`
+
+	// maxLifetimeSeconds ends a session this many seconds after the user logged in, regardless of how often it
+	// was refreshed. When not configured, the session may be refreshed indefinitely.
+	// Must be between 600 and 2592000 (30 days), and must not be shorter than idleTimeoutSeconds when both are configured.
+	// +optional
+	MaxLifetimeSeconds *int32 `json:"maxLifetimeSeconds,omitempty"`
+}
+
+// SigningKeyRotation describes how often the signing keys of a FederationDomain are rotated.
+type SigningKeyRotation struct {
+	// intervalSeconds is how long each signing key is used to sign tokens before it is replaced by a new key.
+	// When not configured, the signing keys are never rotated.
+	// Must be between 7200 (2 hours) and 31536000 (365 days).
+	// +optional
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+
+	// overlapSeconds is how long each replaced signing key remains in the JWKS after it was replaced, so that the
+	// tokens which it signed remain verifiable until they expire. The default is 3600 seconds (1 hour), which is the
+	// longest allowed lifetime of ID tokens. It is only used when intervalSeconds is configured.
+	// Must be between 600 and 86400, and must be less than intervalSeconds.
+	// +optional
+	OverlapSeconds *int32 `json:"overlapSeconds,omitempty"`
 }
 
 // FederationDomainSecrets holds information about this OIDC Provider's secrets.
@@ -104,6 +290,14 @@ type FederationDomainStatus struct {
 	// Secrets contains information about this OIDC Provider's secrets.
 	// +optional
 	Secrets FederationDomainSecrets `json:"secrets,omitempty"`
+
+	// Conditions represent the observations of a FederationDomain's current state.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // FederationDomain describes the configuration of an OIDC provider.
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -40,7 +40,9 @@ type ActiveDirectoryIdentityProviderBind struct {
 	// password for an Active Directory bind user. This account will be used to perform LDAP searches. The Secret should be
 	// of type "kubernetes.io/basic-auth" which includes "username" and "password" keys. The username value
 	// should be the full dn (distinguished name) of your bind account, e.g. "cn=bind-account,ou=users,dc=example,dc=com".
-	// The password must be non-empty.
+	// The password must be non-empty. The Secret may optionally also include "username2" and "password2" keys,
+	// which provide secondary bind credentials that will be used whenever a bind using the primary credentials fails.
+	// This allows the bind credentials to be rotated without interrupting logins.
 	// +kubebuilder:validation:MinLength=1
 	SecretName string `json:"secretName"`
 }
@@ -81,6 +83,18 @@ type ActiveDirectoryIdentityProviderUserSearch struct {
 	// +optional
 	Base string `json:"base,omitempty"`
 
+	// UseGlobalCatalog causes the search for users to be performed on the Global Catalog of the Active Directory forest,
+	// so that users from any domain in the forest can log in. The Global Catalog is contacted on port 3269 when
+	// connecting using TLS, or on port 3268 when connecting using StartTLS, of the same hosts which are used for
+	// all other requests. Binds and group searches are still performed on the usual port.
+	// When Base is not specified, it will be based on the result of a query for the rootDomainNamingContext,
+	// which searches the whole forest for users when the forest contains a single tree of domains.
+	// Note that the Global Catalog only contains a partial set of attributes for each user, so the attributes
+	// used for the username, UID, and refresh checks must be included in the partial attribute set.
+	// Optional. When not specified, defaults to false.
+	// +optional
+	UseGlobalCatalog bool `json:"useGlobalCatalog,omitempty"`
+
 	// Filter is the search filter which should be applied when searching for users. The pattern "{}" must occur
 	// in the filter at least once and will be dynamically replaced by the username for which the search is being run.
 	// E.g. "mail={}" or "&(objectClass=person)(uid={})". For more information about LDAP filters, see
@@ -99,6 +113,19 @@ type ActiveDirectoryIdentityProviderUserSearch struct {
 	// the result of the user search.
 	// +optional
 	Attributes ActiveDirectoryIdentityProviderUserSearchAttributes `json:"attributes,omitempty"`
+
+	// Scope is the scope of the user search. "WholeSubtree" searches the Base entry and all of its descendants,
+	// "SingleLevel" searches only the immediate children of the Base entry, and "BaseObject" searches only the
+	// Base entry itself. Optional. When not specified, defaults to "WholeSubtree".
+	// +optional
+	Scope LDAPSearchScope `json:"scope,omitempty"`
+
+	// DerefAliases determines how alias entries are dereferenced by the Active Directory server during the user search.
+	// "Never" does not dereference aliases, "InSearching" dereferences aliases which are found while searching
+	// below the Base entry, "FindingBaseObject" dereferences the Base entry only, and "Always" does both.
+	// Optional. When not specified, defaults to "Never".
+	// +optional
+	DerefAliases LDAPDerefAliases `json:"derefAliases,omitempty"`
 }
 
 type ActiveDirectoryIdentityProviderGroupSearch struct {
@@ -127,6 +154,17 @@ type ActiveDirectoryIdentityProviderGroupSearch struct {
 	// +optional
 	Filter string `json:"filter,omitempty"`
 
+	// UserAttributeForFilter specifies which attribute's value from the user entry found as a result of
+	// the user search will be used to replace the "{}" placeholder(s) in the group search Filter.
+	// For example, specifying "uid" as the UserAttributeForFilter while specifying
+	// "&(objectClass=posixGroup)(memberUid={})" as the Filter would search for groups by replacing
+	// the "{}" placeholder in the Filter with the value of the user's "uid" attribute.
+	// Optional. When not specified, the default will act as if "dn" were specified. For example, leaving
+	// UserAttributeForFilter unspecified while specifying "&(objectClass=group)(member={})" as the Filter
+	// would search for groups by replacing the "{}" placeholder(s) with the dn (distinguished name) of the user.
+	// +optional
+	UserAttributeForFilter string `json:"userAttributeForFilter,omitempty"`
+
 	// Attributes specifies how the group's information should be read from each ActiveDirectory entry which was found as
 	// the result of the group search.
 	// +optional
@@ -156,17 +194,177 @@ type ActiveDirectoryIdentityProviderGroupSearch struct {
 	// release notes before upgrading to ensure that the meaning of this field has
 	// not changed.
 	SkipGroupRefresh bool `json:"skipGroupRefresh,omitempty"`
+
+	// GroupRefreshIntervalSeconds is the minimum number of seconds between searches for the user's group memberships
+	// during refreshes of the user's session. The groups found at login, or by the most recent group search, are kept
+	// until the interval has elapsed. This reduces the load of group searches on the Active Directory server while still keeping
+	// group memberships reasonably current, as a less drastic alternative to skipGroupRefresh.
+	// Optional. When not specified, the groups are searched again on every refresh.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	GroupRefreshIntervalSeconds int64 `json:"groupRefreshIntervalSeconds,omitempty"`
+
+	// PageSize is the maximum number of group entries to request from the Active Directory server in each page of results
+	// when searching for a user's group memberships. The group search uses the paged results control (RFC 2696)
+	// and all pages are combined into the user's list of groups. Smaller pages may be needed when the Active Directory server
+	// enforces a size limit which is lower than the number of groups that a user may belong to.
+	// Optional. When not specified, defaults to 250.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PageSize int32 `json:"pageSize,omitempty"`
+
+	// Scope is the scope of the group search. "WholeSubtree" searches the Base entry and all of its descendants,
+	// "SingleLevel" searches only the immediate children of the Base entry, and "BaseObject" searches only the
+	// Base entry itself. Optional. When not specified, defaults to "WholeSubtree".
+	// +optional
+	Scope LDAPSearchScope `json:"scope,omitempty"`
+
+	// DerefAliases determines how alias entries are dereferenced by the Active Directory server during the group search.
+	// "Never" does not dereference aliases, "InSearching" dereferences aliases which are found while searching
+	// below the Base entry, "FindingBaseObject" dereferences the Base entry only, and "Always" does both.
+	// Optional. When not specified, defaults to "Never".
+	// +optional
+	DerefAliases LDAPDerefAliases `json:"derefAliases,omitempty"`
+
+	// GroupNameTransformations is a list of transformations which are applied, in order, to each group name found by
+	// the group search before the groups are used in the user's session. Transformations allow the group names
+	// to stay stable, e.g. for use in Kubernetes RBAC bindings, even when they are formatted differently by the Active Directory
+	// server. Group names which become empty after the transformations are removed, as are duplicates.
+	// Optional. When not specified, group names are used as they were found.
+	// +optional
+	GroupNameTransformations []GroupNameTransformation `json:"groupNameTransformations,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderReferrals provides configuration for following referrals which are returned by the Active Directory server.
+type ActiveDirectoryIdentityProviderReferrals struct {
+	// Follow enables following the referrals (including search result references) which are returned by the
+	// Active Directory server during user and group searches. When false, referrals are ignored. Optional.
+	// +optional
+	Follow bool `json:"follow,omitempty"`
+
+	// MaxHops is the maximum length of a chain of referrals which will be followed. For example, a value of 1 will
+	// follow referrals returned by the configured Active Directory server, but will not follow any further referrals returned
+	// by the referred servers. Optional. When not specified, defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	MaxHops int32 `json:"maxHops,omitempty"`
+
+	// CredentialPolicy determines which credentials are used to perform searches on referred servers.
+	// "BindAccount" binds using the same bind account that is configured by spec.bind.secretName, which requires
+	// the referred servers to accept those credentials. "Anonymous" does not bind before searching the referred
+	// servers. Regardless of this setting, an end user's password is always verified by binding to the server on
+	// which their user entry was found. Optional. When not specified, defaults to "BindAccount".
+	// +optional
+	CredentialPolicy ReferralCredentialPolicy `json:"credentialPolicy,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderRefreshChecks provides configuration for the checks which are performed on the user's
+// Active Directory entry when their session is refreshed. When any check fails, the refresh is rejected and the user
+// must log in again.
+type ActiveDirectoryIdentityProviderRefreshChecks struct {
+	// SkipPasswordChangedCheck disables the check which rejects the refresh when the user's password has changed since
+	// they logged in, as indicated by the pwdLastSet attribute. Optional. When not specified, the check is performed.
+	// +optional
+	SkipPasswordChangedCheck bool `json:"skipPasswordChangedCheck,omitempty"`
+
+	// SkipAccountDisabledCheck disables the check which rejects the refresh when the user's account has been disabled,
+	// as indicated by the userAccountControl attribute. Optional. When not specified, the check is performed.
+	// +optional
+	SkipAccountDisabledCheck bool `json:"skipAccountDisabledCheck,omitempty"`
+
+	// SkipAccountLockedCheck disables the check which rejects the refresh when the user's account has been locked,
+	// as indicated by the msDS-User-Account-Control-Computed attribute. Optional. When not specified, the check is performed.
+	// +optional
+	SkipAccountLockedCheck bool `json:"skipAccountLockedCheck,omitempty"`
+
+	// UnchangedAttributes is a list of the names of additional attributes of the user's entry whose values must not
+	// change after the user logs in, for example: employeeID. Each of these attributes must have exactly one value on
+	// the user's entry, or else their logins and refreshes will fail. Sessions which started before an attribute was
+	// added to this list cannot be refreshed. Optional.
+	// +listType=set
+	// +optional
+	UnchangedAttributes []string `json:"unchangedAttributes,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderDomainControllerDiscovery provides configuration for discovering the domain controllers
+// of an Active Directory domain using DNS SRV records.
+type ActiveDirectoryIdentityProviderDomainControllerDiscovery struct {
+	// Domain is the DNS name of the Active Directory domain, for example: activedirectory.example.com.
+	// The domain controllers are discovered by looking up the SRV records for _ldap._tcp.<domain>. They are tried in
+	// order of the priority of their SRV records (lowest first), and then by weight (highest first). The ports from the
+	// SRV records are not used. Instead, the default port of the connection protocol is used, i.e. 636 for ldaps or 389
+	// for StartTLS.
+	// +kubebuilder:validation:MinLength=1
+	Domain string `json:"domain"`
+
+	// RefreshIntervalSeconds is how often the SRV records are looked up again to discover any changes to the domain
+	// controllers. Optional. When not specified, defaults to 300 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RefreshIntervalSeconds int64 `json:"refreshIntervalSeconds,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderAttributeMappings provides configuration for mapping attributes of the user's Active Directory entry
+// into the identities of users who log in using this identity provider.
+type ActiveDirectoryIdentityProviderAttributeMappings struct {
+	// AdditionalClaims allows for the values of additional attributes of the user's Active Directory entry to be mapped into the
+	// "additionalClaims" claim of the ID tokens generated by the Supervisor. This should be specified as a map of
+	// new claim names as the keys, and Active Directory attribute names as the values, for example: mail, employeeID, or department.
+	// The attribute name "dn" may be used to map the user's distinguished name. These new claim names will be nested
+	// under the top-level "additionalClaims" claim in ID tokens generated by the Supervisor when this
+	// ActiveDirectoryIdentityProvider was used for user authentication. These claims will be made available to all clients.
+	// Attributes with a single value become string claims, and attributes with multiple values become string array
+	// claims. The attribute values are fetched when the user logs in, and fetched again each time that their session
+	// is refreshed. Attributes which are not present on the user's entry are excluded from the "additionalClaims" claim.
+	// When this map is empty, the "additionalClaims" claim will be excluded from the ID tokens generated by the Supervisor.
+	// +optional
+	AdditionalClaims map[string]string `json:"additionalClaims,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderAuthenticationRateLimits provides configuration for limiting the rate of authentication attempts
+// using this identity provider, to protect the accounts of the Active Directory users from being locked out by brute force attacks.
+type ActiveDirectoryIdentityProviderAuthenticationRateLimits struct {
+	// PerUsername limits the rate of authentication attempts for each username. Optional. When not specified,
+	// the rate of authentication attempts for each username is not limited.
+	// +optional
+	PerUsername *AuthenticationRateLimit `json:"perUsername,omitempty"`
+
+	// PerClientIP limits the rate of authentication attempts from each client IP address. Optional. When not specified,
+	// the rate of authentication attempts from each client IP address is not limited.
+	// +optional
+	PerClientIP *AuthenticationRateLimit `json:"perClientIP,omitempty"`
 }
 
 // Spec for configuring an ActiveDirectory identity provider.
 type ActiveDirectoryIdentityProviderSpec struct {
 	// Host is the hostname of this Active Directory identity provider, i.e., where to connect. For example: ldap.example.com:636.
+	// Required unless DomainControllerDiscovery is configured, in which case Host must not be configured.
 	// +kubebuilder:validation:MinLength=1
-	Host string `json:"host"`
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Hosts is an optional list of additional hostnames of replicas of this Active Directory identity provider, for example
+	// ldap-replica.example.com:636. When the Host cannot be reached, each of these will be tried in the order listed.
+	// Hosts which recently could not be reached are tried only after the other hosts.
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+
+	// DomainControllerDiscovery configures discovering the domain controllers of the Active Directory domain using DNS,
+	// instead of connecting to a statically configured Host. Optional.
+	// +optional
+	DomainControllerDiscovery *ActiveDirectoryIdentityProviderDomainControllerDiscovery `json:"domainControllerDiscovery,omitempty"`
 
 	// TLS contains the connection settings for how to establish the connection to the Host.
 	TLS *TLSSpec `json:"tls,omitempty"`
 
+	// ProxyURL is the URL of a proxy through which all connections to the Active Directory server should be made, for example
+	// http://proxy.example.com:3128 for an HTTP proxy which supports the CONNECT method, or socks5://proxy.example.com:1080
+	// for a SOCKS5 proxy. The schemes http, socks5, and socks5h are supported. Credentials for the proxy may be included
+	// in the URL. Optional. When not specified, connections are made directly to the Active Directory server.
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+
 	// Bind contains the configuration for how to provide access credentials during an initial bind to the ActiveDirectory server
 	// to be allowed to perform searches and binds to validate a user's credentials during a user's authentication attempt.
 	Bind ActiveDirectoryIdentityProviderBind `json:"bind,omitempty"`
@@ -176,6 +374,45 @@ type ActiveDirectoryIdentityProviderSpec struct {
 
 	// GroupSearch contains the configuration for searching for a user's group membership in ActiveDirectory.
 	GroupSearch ActiveDirectoryIdentityProviderGroupSearch `json:"groupSearch,omitempty"`
+
+	// DialTimeoutSeconds is the maximum number of seconds to wait while establishing a network connection to the
+	// Active Directory server. Optional. When not specified, defaults to 60 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DialTimeoutSeconds int64 `json:"dialTimeoutSeconds,omitempty"`
+
+	// SearchTimeoutSeconds is the time limit, in seconds, which will be requested of the Active Directory server for each
+	// search operation performed during logins and refreshes. Optional. When not specified, defaults to 90 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SearchTimeoutSeconds int64 `json:"searchTimeoutSeconds,omitempty"`
+
+	// ConnectionRevalidationIntervalSeconds is how often, in seconds, the connection to the Active Directory server is validated
+	// again, so that the LDAPConnectionValid condition and the phase reflect the current availability of the server.
+	// Optional. When not specified, the connection is only validated again when this spec or the bind Secret changes.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ConnectionRevalidationIntervalSeconds int64 `json:"connectionRevalidationIntervalSeconds,omitempty"`
+
+	// Referrals contains the configuration for following referrals which are returned by the Active Directory server
+	// during searches, e.g. when users or groups are located in other domains of the forest.
+	// +optional
+	Referrals ActiveDirectoryIdentityProviderReferrals `json:"referrals,omitempty"`
+
+	// RefreshChecks contains the configuration for the checks which are performed on the user's Active Directory entry
+	// each time that their session is refreshed.
+	// +optional
+	RefreshChecks ActiveDirectoryIdentityProviderRefreshChecks `json:"refreshChecks,omitempty"`
+
+	// AttributeMappings contains the configuration for mapping attributes of the user's Active Directory entry into the
+	// identities of users who log in using this identity provider.
+	// +optional
+	AttributeMappings ActiveDirectoryIdentityProviderAttributeMappings `json:"attributeMappings,omitempty"`
+
+	// AuthenticationRateLimits contains the configuration for limiting the rate of authentication attempts using
+	// this identity provider. Optional. When not specified, the rate of authentication attempts is not limited.
+	// +optional
+	AuthenticationRateLimits ActiveDirectoryIdentityProviderAuthenticationRateLimits `json:"authenticationRateLimits,omitempty"`
 }
 
 // ActiveDirectoryIdentityProvider describes the configuration of an upstream Microsoft Active Directory identity provider.
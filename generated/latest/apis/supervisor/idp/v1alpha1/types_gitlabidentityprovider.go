@@ -0,0 +1,129 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type GitLabIdentityProviderPhase string
+
+const (
+	// GitLabPhasePending is the default phase for newly-created GitLabIdentityProvider resources.
+	GitLabPhasePending GitLabIdentityProviderPhase = "Pending"
+
+	// GitLabPhaseReady is the phase for a GitLabIdentityProvider resource in a healthy state.
+	GitLabPhaseReady GitLabIdentityProviderPhase = "Ready"
+
+	// GitLabPhaseError is the phase for a GitLabIdentityProvider in an unhealthy state.
+	GitLabPhaseError GitLabIdentityProviderPhase = "Error"
+)
+
+// GitLabUsernameAttribute determines which property of the GitLab user record is used as the username.
+type GitLabUsernameAttribute string
+
+const (
+	// GitLabUsernameUsername specifies that the user's GitLab username should be used as the username.
+	// Note that GitLab users can change their own usernames.
+	GitLabUsernameUsername GitLabUsernameAttribute = "username"
+
+	// GitLabUsernameID specifies that the user's immutable numeric GitLab ID should be used as the username.
+	GitLabUsernameID GitLabUsernameAttribute = "id"
+
+	// GitLabUsernameEmail specifies that the user's primary email address should be used as the username.
+	GitLabUsernameEmail GitLabUsernameAttribute = "email"
+)
+
+// GitLabIdentityProviderStatus is the status of a GitLab identity provider.
+type GitLabIdentityProviderStatus struct {
+	// Phase summarizes the overall status of the GitLabIdentityProvider.
+	// +kubebuilder:default=Pending
+	// +kubebuilder:validation:Enum=Pending;Ready;Error
+	Phase GitLabIdentityProviderPhase `json:"phase,omitempty"`
+
+	// Represents the observations of an identity provider's current state.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// GitLabClaims allows customization of the username claim.
+type GitLabClaims struct {
+	// Username configures which property of the GitLab user record shall determine the username in Kubernetes.
+	// Can be either "username", "id", or "email". Defaults to "username".
+	// The "username" option is easy to read, but GitLab users can change their own usernames, which would change
+	// their username in Kubernetes. The "id" option is immutable, but is not human-readable. The "email" option
+	// uses the user's primary email address.
+	// +kubebuilder:default=username
+	// +kubebuilder:validation:Enum=username;id;email
+	// +optional
+	Username GitLabUsernameAttribute `json:"username,omitempty"`
+}
+
+// GitLabClientSpec contains information about the GitLab client that this identity provider will use
+// for web-based login flows.
+type GitLabClientSpec struct {
+	// SecretName contains the name of a namespace-local Secret object that provides the clientID and
+	// clientSecret for a GitLab OAuth2 application. The Secret is expected to be of type
+	// "secrets.pinniped.dev/gitlab-client" with keys "clientID" and "clientSecret".
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// GitLabIdentityProviderSpec is the spec for configuring a GitLab identity provider.
+type GitLabIdentityProviderSpec struct {
+	// Host is the host of the GitLab instance, with an optional port. Defaults to "gitlab.com".
+	// Set it to the host of a self-managed GitLab instance to use that instance instead.
+	// Do not specify a protocol or path, since GitLab is always accessed using https, and its API
+	// is always at "/api/v4".
+	// +kubebuilder:default="gitlab.com"
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// TLS configuration for a self-managed GitLab instance.
+	// +optional
+	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// Claims allows customization of the username claim.
+	// +kubebuilder:default={}
+	// +optional
+	Claims GitLabClaims `json:"claims,omitempty"`
+
+	// Client identifies the secret with credentials for a GitLab OAuth2 application.
+	Client GitLabClientSpec `json:"client"`
+}
+
+// GitLabIdentityProvider describes the configuration of an upstream GitLab identity provider, using either
+// gitlab.com or a self-managed GitLab instance. Users log in using the GitLab OAuth2 web flow. Their usernames
+// are computed from their GitLab user records, and their groups are the full paths of the GitLab groups and
+// subgroups of which they are members, e.g. "my-group/my-subgroup".
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped;pinniped-idp;pinniped-idps
+// +kubebuilder:printcolumn:name="Host",type=string,JSONPath=`.spec.host`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type GitLabIdentityProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec for configuring the identity provider.
+	Spec GitLabIdentityProviderSpec `json:"spec"`
+
+	// Status of the identity provider.
+	Status GitLabIdentityProviderStatus `json:"status,omitempty"`
+}
+
+// GitLabIdentityProviderList lists GitLabIdentityProvider objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type GitLabIdentityProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []GitLabIdentityProvider `json:"items"`
+}
@@ -13,8 +13,16 @@ import (
 type Interface interface {
 	// ActiveDirectoryIdentityProviders returns a ActiveDirectoryIdentityProviderInformer.
 	ActiveDirectoryIdentityProviders() ActiveDirectoryIdentityProviderInformer
+	// GitHubIdentityProviders returns a GitHubIdentityProviderInformer.
+	GitHubIdentityProviders() GitHubIdentityProviderInformer
+	// GitLabIdentityProviders returns a GitLabIdentityProviderInformer.
+	GitLabIdentityProviders() GitLabIdentityProviderInformer
+	// KerberosIdentityProviders returns a KerberosIdentityProviderInformer.
+	KerberosIdentityProviders() KerberosIdentityProviderInformer
 	// LDAPIdentityProviders returns a LDAPIdentityProviderInformer.
 	LDAPIdentityProviders() LDAPIdentityProviderInformer
+	// OAuth2IdentityProviders returns a OAuth2IdentityProviderInformer.
+	OAuth2IdentityProviders() OAuth2IdentityProviderInformer
 	// OIDCIdentityProviders returns a OIDCIdentityProviderInformer.
 	OIDCIdentityProviders() OIDCIdentityProviderInformer
 }
@@ -35,11 +43,31 @@ func (v *version) ActiveDirectoryIdentityProviders() ActiveDirectoryIdentityProv
 	return &activeDirectoryIdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// GitHubIdentityProviders returns a GitHubIdentityProviderInformer.
+func (v *version) GitHubIdentityProviders() GitHubIdentityProviderInformer {
+	return &gitHubIdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// GitLabIdentityProviders returns a GitLabIdentityProviderInformer.
+func (v *version) GitLabIdentityProviders() GitLabIdentityProviderInformer {
+	return &gitLabIdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// KerberosIdentityProviders returns a KerberosIdentityProviderInformer.
+func (v *version) KerberosIdentityProviders() KerberosIdentityProviderInformer {
+	return &kerberosIdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // LDAPIdentityProviders returns a LDAPIdentityProviderInformer.
 func (v *version) LDAPIdentityProviders() LDAPIdentityProviderInformer {
 	return &lDAPIdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// OAuth2IdentityProviders returns a OAuth2IdentityProviderInformer.
+func (v *version) OAuth2IdentityProviders() OAuth2IdentityProviderInformer {
+	return &oAuth2IdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // OIDCIdentityProviders returns a OIDCIdentityProviderInformer.
 func (v *version) OIDCIdentityProviders() OIDCIdentityProviderInformer {
 	return &oIDCIdentityProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -0,0 +1,150 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package acmecert obtains TLS serving certificates from an ACME certificate authority, e.g. Let's Encrypt, using
+// either the HTTP-01 or the DNS-01 challenge to prove control over the hostnames.
+package acmecert
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// ChallengeSolver makes the ACME server's challenges of one type pass for a hostname.
+type ChallengeSolver interface {
+	// Type returns the type of the challenges which are solved, e.g. "http-01".
+	Type() string
+
+	// Present makes the challenge of the hostname pass, and returns once the ACME server may validate it.
+	Present(ctx context.Context, hostname string, token string, keyAuthorization string) error
+
+	// CleanUp removes what Present added, after the ACME server validated the challenge or gave up.
+	CleanUp(ctx context.Context, hostname string, token string, keyAuthorization string) error
+}
+
+// Issuer obtains certificates from an ACME server.
+type Issuer struct {
+	directoryURL string
+	email        string
+	solver       ChallengeSolver
+	httpClient   *http.Client
+}
+
+// NewIssuer returns an Issuer which obtains certificates from the ACME server with the directory URL, solving its
+// challenges with the solver. The email is the contact address of the ACME account, and may be empty.
+func NewIssuer(directoryURL string, email string, solver ChallengeSolver, httpClient *http.Client) *Issuer {
+	return &Issuer{
+		directoryURL: directoryURL,
+		email:        email,
+		solver:       solver,
+		httpClient:   httpClient,
+	}
+}
+
+// Issue obtains a certificate for the hostnames and the public key of the certKey, using the ACME account of the
+// accountKey. The account is registered when it does not exist yet, which accepts the ACME server's terms of service.
+// It returns the PEM-encoded certificate chain, starting with the certificate for the hostnames.
+func (i *Issuer) Issue(ctx context.Context, accountKey crypto.Signer, hostnames []string, certKey crypto.Signer) ([]byte, error) {
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: i.directoryURL,
+		HTTPClient:   i.httpClient,
+		UserAgent:    "pinniped-supervisor",
+	}
+
+	account := &acme.Account{}
+	if i.email != "" {
+		account.Contact = []string{"mailto:" + i.email}
+	}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("could not register ACME account: %w", err)
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(hostnames...))
+	if err != nil {
+		return nil, fmt.Errorf("could not create ACME order: %w", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := i.authorize(ctx, client, authzURL); err != nil {
+			return nil, err
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("ACME order did not become ready: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: hostnames[0]},
+		DNSNames: hostnames,
+	}, certKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not create certificate request: %w", err)
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not finalize ACME order: %w", err)
+	}
+
+	var chainPEM []byte
+	for _, der := range chain {
+		chainPEM = append(chainPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return chainPEM, nil
+}
+
+func (i *Issuer) authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("could not get ACME authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		// The hostname is still authorized by a previous order of the account.
+		return nil
+	}
+
+	hostname := authz.Identifier.Value
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == i.solver.Type() {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("ACME server does not offer a %s challenge for %s", i.solver.Type(), hostname)
+	}
+
+	// The key authorization is the same for every type of challenge. Solvers derive their responses from it.
+	keyAuthorization, err := client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return fmt.Errorf("could not compute key authorization: %w", err)
+	}
+	if err := i.solver.Present(ctx, hostname, challenge.Token, keyAuthorization); err != nil {
+		return fmt.Errorf("could not present %s challenge for %s: %w", challenge.Type, hostname, err)
+	}
+	defer func() {
+		if err := i.solver.CleanUp(ctx, hostname, challenge.Token, keyAuthorization); err != nil {
+			plog.WarningErr("could not clean up ACME challenge", err, "type", challenge.Type, "hostname", hostname)
+		}
+	}()
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("could not accept %s challenge for %s: %w", challenge.Type, hostname, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("ACME server could not validate %s challenge for %s: %w", challenge.Type, hostname, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package acmecert
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DNS01ChallengeType is the type of the challenges which are solved by publishing a DNS TXT record.
+	DNS01ChallengeType = "dns-01"
+
+	dns01WebhookRequestTimeout = 30 * time.Second
+)
+
+// DNS01WebhookSolver solves DNS-01 challenges by asking a webhook to publish and remove the TXT records, because the
+// Supervisor cannot update the many kinds of DNS providers itself.
+type DNS01WebhookSolver struct {
+	url              string
+	httpClient       *http.Client
+	propagationDelay time.Duration
+}
+
+var _ ChallengeSolver = (*DNS01WebhookSolver)(nil)
+
+// DNS01WebhookRequest is posted as JSON to the webhook for each TXT record which it should publish or remove.
+type DNS01WebhookRequest struct {
+	// Action is either "present", to publish the record, or "cleanup", to remove it.
+	Action string `json:"action"`
+
+	// FQDN is the fully qualified name of the record, e.g. "_acme-challenge.example.com.".
+	FQDN string `json:"fqdn"`
+
+	// Value is the value of the TXT record.
+	Value string `json:"value"`
+}
+
+// NewDNS01WebhookSolver returns a DNS01WebhookSolver which posts to the webhook URL. After a record was published,
+// it waits for the propagationDelay before the ACME server may look the record up.
+func NewDNS01WebhookSolver(url string, httpClient *http.Client, propagationDelay time.Duration) *DNS01WebhookSolver {
+	return &DNS01WebhookSolver{
+		url:              url,
+		httpClient:       httpClient,
+		propagationDelay: propagationDelay,
+	}
+}
+
+func (s *DNS01WebhookSolver) Type() string {
+	return DNS01ChallengeType
+}
+
+func (s *DNS01WebhookSolver) Present(ctx context.Context, hostname string, _ string, keyAuthorization string) error {
+	if err := s.post(ctx, "present", hostname, keyAuthorization); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(s.propagationDelay):
+		return nil
+	}
+}
+
+func (s *DNS01WebhookSolver) CleanUp(ctx context.Context, hostname string, _ string, keyAuthorization string) error {
+	return s.post(ctx, "cleanup", hostname, keyAuthorization)
+}
+
+func (s *DNS01WebhookSolver) post(ctx context.Context, action string, hostname string, keyAuthorization string) error {
+	// The value of the TXT record is the same as golang.org/x/crypto/acme's Client.DNS01ChallengeRecord.
+	digest := sha256.Sum256([]byte(keyAuthorization))
+	body, err := json.Marshal(DNS01WebhookRequest{
+		Action: action,
+		FQDN:   "_acme-challenge." + hostname + ".",
+		Value:  base64.RawURLEncoding.EncodeToString(digest[:]),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dns01WebhookRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %q from DNS-01 webhook", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package acmecert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+
+	"go.pinniped.dev/internal/testutil/tlsserver"
+)
+
+func TestDNS01WebhookSolver(t *testing.T) {
+	var requests []DNS01WebhookRequest
+	status := http.StatusOK
+	server := tlsserver.TLSTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var request DNS01WebhookRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+		w.WriteHeader(status)
+	}), nil)
+
+	solver := NewDNS01WebhookSolver(server.URL, server.Client(), time.Millisecond)
+	require.Equal(t, "dns-01", solver.Type())
+
+	// The record is the one which the ACME server expects for the key authorization.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keyAuthorization, err := (&acme.Client{Key: key}).HTTP01ChallengeResponse("some-token")
+	require.NoError(t, err)
+	wantValue, err := (&acme.Client{Key: key}).DNS01ChallengeRecord("some-token")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, solver.Present(ctx, "issuer.example.com", "some-token", keyAuthorization))
+	require.NoError(t, solver.CleanUp(ctx, "issuer.example.com", "some-token", keyAuthorization))
+	require.Equal(t, []DNS01WebhookRequest{
+		{Action: "present", FQDN: "_acme-challenge.issuer.example.com.", Value: wantValue},
+		{Action: "cleanup", FQDN: "_acme-challenge.issuer.example.com.", Value: wantValue},
+	}, requests)
+
+	status = http.StatusInternalServerError
+	err = solver.Present(ctx, "issuer.example.com", "some-token", keyAuthorization)
+	require.EqualError(t, err, `unexpected response status "500 Internal Server Error" from DNS-01 webhook`)
+}
+
+func TestDNS01WebhookSolverWaitsForPropagation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	solver := NewDNS01WebhookSolver(server.URL, server.Client(), time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, solver.Present(ctx, "issuer.example.com", "some-token", "some-token.thumbprint"), context.DeadlineExceeded)
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package acmecert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// HTTP01ChallengeType is the type of the challenges which are solved by serving a file over HTTP on port 80.
+	HTTP01ChallengeType = "http-01"
+
+	// HTTP01ChallengePathPrefix is the path under which the ACME server requests the key authorizations.
+	HTTP01ChallengePathPrefix = "/.well-known/acme-challenge/"
+
+	// HTTP01ChallengesSecretType is the type of the Secret which holds the pending key authorizations, by token.
+	HTTP01ChallengesSecretType corev1.SecretType = "secrets.pinniped.dev/acme-http01-challenges"
+
+	// How long to wait for a new key authorization to appear in the informer's cache.
+	http01CacheTimeout = 30 * time.Second
+)
+
+// ACME tokens are base64url-encoded, which are also valid keys of a Secret's data.
+var http01TokenRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`) //nolint:gochecknoglobals
+
+// HTTP01Solver solves HTTP-01 challenges by storing their key authorizations in a Secret, from which they are served
+// by the HTTP01Handler of every Supervisor pod, so that it does not matter which pod the ACME server reaches.
+type HTTP01Solver struct {
+	secrets      corev1client.SecretInterface
+	secretLister corev1listers.SecretNamespaceLister
+	secretName   string
+	labels       map[string]string
+}
+
+var _ ChallengeSolver = (*HTTP01Solver)(nil)
+
+// NewHTTP01Solver returns an HTTP01Solver which stores the key authorizations in the named Secret, which is created
+// with the labels when it does not exist.
+func NewHTTP01Solver(
+	secrets corev1client.SecretInterface,
+	secretLister corev1listers.SecretNamespaceLister,
+	secretName string,
+	labels map[string]string,
+) *HTTP01Solver {
+	return &HTTP01Solver{
+		secrets:      secrets,
+		secretLister: secretLister,
+		secretName:   secretName,
+		labels:       labels,
+	}
+}
+
+func (s *HTTP01Solver) Type() string {
+	return HTTP01ChallengeType
+}
+
+func (s *HTTP01Solver) Present(ctx context.Context, _ string, token string, keyAuthorization string) error {
+	err := s.updateSecret(ctx, func(data map[string][]byte) {
+		data[token] = []byte(keyAuthorization)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The ACME server may validate the challenge as soon as it is accepted, so wait until the key authorization is
+	// served by this pod. The informers of the other pods receive the Secret at about the same time.
+	return wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, http01CacheTimeout, true, func(_ context.Context) (bool, error) {
+		secret, err := s.secretLister.Get(s.secretName)
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return string(secret.Data[token]) == keyAuthorization, nil
+	})
+}
+
+func (s *HTTP01Solver) CleanUp(ctx context.Context, _ string, token string, _ string) error {
+	return s.updateSecret(ctx, func(data map[string][]byte) {
+		delete(data, token)
+	})
+}
+
+func (s *HTTP01Solver) updateSecret(ctx context.Context, update func(data map[string][]byte)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.secrets.Get(ctx, s.secretName, metav1.GetOptions{})
+		notFound := k8serrors.IsNotFound(err)
+		if err != nil && !notFound {
+			return fmt.Errorf("cannot get secret: %w", err)
+		}
+
+		if notFound {
+			data := map[string][]byte{}
+			update(data)
+			_, err := s.secrets.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: s.secretName, Labels: s.labels},
+				Type:       HTTP01ChallengesSecretType,
+				Data:       data,
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("cannot create secret: %w", err)
+			}
+			return nil
+		}
+
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		update(secret.Data)
+		_, err = s.secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// NewHTTP01Handler returns a handler which serves the key authorizations of the pending HTTP-01 challenges from the
+// named Secret. It serves nothing else, so it may be exposed on port 80 without exposing the Supervisor's endpoints
+// over plain HTTP.
+func NewHTTP01Handler(secretLister corev1listers.SecretNamespaceLister, secretName string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := strings.TrimPrefix(r.URL.Path, HTTP01ChallengePathPrefix)
+		if token == r.URL.Path || !http01TokenRegexp.MatchString(token) {
+			http.NotFound(w, r)
+			return
+		}
+		secret, err := secretLister.Get(secretName)
+		if err != nil || secret.Type != HTTP01ChallengesSecretType {
+			http.NotFound(w, r)
+			return
+		}
+		keyAuthorization, ok := secret.Data[token]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write(keyAuthorization)
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package acmecert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace  = "some-namespace"
+	testSecretName = "some-challenges-secret"
+)
+
+func TestHTTP01Solver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := kubernetesfake.NewSimpleClientset()
+	kubeInformers := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(testNamespace))
+	secretLister := kubeInformers.Core().V1().Secrets().Lister().Secrets(testNamespace)
+	kubeInformers.Start(ctx.Done())
+	kubeInformers.WaitForCacheSync(ctx.Done())
+
+	secrets := kubeClient.CoreV1().Secrets(testNamespace)
+	solver := NewHTTP01Solver(secrets, secretLister, testSecretName, map[string]string{"app": "pinniped-supervisor"})
+	require.Equal(t, "http-01", solver.Type())
+
+	// The first challenge creates the Secret, and the second one is added to it.
+	require.NoError(t, solver.Present(ctx, "issuer1.example.com", "token-1", "token-1.thumbprint"))
+	require.NoError(t, solver.Present(ctx, "issuer2.example.com", "token_2", "token_2.thumbprint"))
+
+	secret, err := secrets.Get(ctx, testSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, HTTP01ChallengesSecretType, secret.Type)
+	require.Equal(t, map[string]string{"app": "pinniped-supervisor"}, secret.Labels)
+	require.Equal(t, map[string][]byte{
+		"token-1": []byte("token-1.thumbprint"),
+		"token_2": []byte("token_2.thumbprint"),
+	}, secret.Data)
+
+	handler := NewHTTP01Handler(secretLister, testSecretName)
+	requireResponse(t, handler, http.MethodGet, "/.well-known/acme-challenge/token-1", http.StatusOK, "token-1.thumbprint")
+
+	require.NoError(t, solver.CleanUp(ctx, "issuer1.example.com", "token-1", "token-1.thumbprint"))
+	secret, err = secrets.Get(ctx, testSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"token_2": []byte("token_2.thumbprint")}, secret.Data)
+}
+
+func TestHTTP01Handler(t *testing.T) {
+	kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(), 0)
+	secretInformer := kubeInformers.Core().V1().Secrets()
+	require.NoError(t, secretInformer.Informer().GetIndexer().Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: testNamespace},
+		Type:       HTTP01ChallengesSecretType,
+		Data:       map[string][]byte{"some-token": []byte("some-token.thumbprint")},
+	}))
+	require.NoError(t, secretInformer.Informer().GetIndexer().Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "wrong-type", Namespace: testNamespace},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"some-token": []byte("some-token.thumbprint")},
+	}))
+	secretLister := secretInformer.Lister().Secrets(testNamespace)
+
+	tests := []struct {
+		name       string
+		secretName string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "pending challenge",
+			secretName: testSecretName,
+			method:     http.MethodGet,
+			path:       "/.well-known/acme-challenge/some-token",
+			wantStatus: http.StatusOK,
+			wantBody:   "some-token.thumbprint",
+		},
+		{
+			name:       "unknown token",
+			secretName: testSecretName,
+			method:     http.MethodGet,
+			path:       "/.well-known/acme-challenge/other-token",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+		{
+			name:       "invalid token",
+			secretName: testSecretName,
+			method:     http.MethodGet,
+			path:       "/.well-known/acme-challenge/some-token/../other",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+		{
+			name:       "other path",
+			secretName: testSecretName,
+			method:     http.MethodGet,
+			path:       "/healthz",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+		{
+			name:       "secret does not exist",
+			secretName: "does-not-exist",
+			method:     http.MethodGet,
+			path:       "/.well-known/acme-challenge/some-token",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+		{
+			name:       "secret has the wrong type",
+			secretName: "wrong-type",
+			method:     http.MethodGet,
+			path:       "/.well-known/acme-challenge/some-token",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+		{
+			name:       "wrong method",
+			secretName: testSecretName,
+			method:     http.MethodPost,
+			path:       "/.well-known/acme-challenge/some-token",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "Method Not Allowed\n",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			requireResponse(t, NewHTTP01Handler(secretLister, tt.secretName), tt.method, tt.path, tt.wantStatus, tt.wantBody)
+		})
+	}
+}
+
+func requireResponse(t *testing.T, handler http.Handler, method string, path string, wantStatus int, wantBody string) {
+	t.Helper()
+
+	rsp := httptest.NewRecorder()
+	handler.ServeHTTP(rsp, httptest.NewRequest(method, path, nil))
+	require.Equal(t, wantStatus, rsp.Code)
+	require.Equal(t, wantBody, rsp.Body.String())
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package auditlog writes the Supervisor's security audit log, which records the logins, token refreshes, token
+// exchanges, client credentials grants, revocations, and garbage collection of downstream sessions as JSON records.
+// The records are written to the sinks which were configured by SetSinks, and are dropped when there are none.
+package auditlog
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ory/fosite"
+
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+// Event is the kind of action which is recorded by an audit record.
+type Event string
+
+const (
+	EventLoginSucceeded          Event = "LoginSucceeded"
+	EventLoginFailed             Event = "LoginFailed"
+	EventTokenRefreshed          Event = "TokenRefreshed"
+	EventTokenExchanged          Event = "TokenExchanged"
+	EventClientTokenIssued       Event = "ClientTokenIssued"
+	EventTokenRevoked            Event = "TokenRevoked"
+	EventSessionRevoked          Event = "SessionRevoked"
+	EventSessionGarbageCollected Event = "SessionGarbageCollected"
+)
+
+// Record is one entry of the audit log. It never holds any tokens or credentials.
+type Record struct {
+	Time                 time.Time `json:"time"`
+	Event                Event     `json:"event"`
+	Username             string    `json:"username,omitempty"`
+	UpstreamUsername     string    `json:"upstreamUsername,omitempty"`
+	IdentityProviderName string    `json:"identityProviderName,omitempty"`
+	IdentityProviderType string    `json:"identityProviderType,omitempty"`
+	ClientID             string    `json:"clientID,omitempty"`
+	SessionID            string    `json:"sessionID,omitempty"`
+	Audience             string    `json:"audience,omitempty"`
+	SourceIP             string    `json:"sourceIP,omitempty"`
+	Reason               string    `json:"reason,omitempty"`
+}
+
+// Sink is a destination of audit records.
+type Sink interface {
+	// Write writes one audit record, which is encoded as a single line of JSON including the trailing newline.
+	Write(record []byte) error
+}
+
+//nolint:gochecknoglobals
+var (
+	sinksMutex sync.RWMutex
+	sinks      []Sink
+)
+
+// SetSinks replaces the destinations of the audit records. Without any sinks, audit records are dropped.
+func SetSinks(newSinks ...Sink) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	sinks = newSinks
+}
+
+// NewRecord returns a record of the event. When r is not nil, the source IP of the record is the address of the
+// client of r. When requester is not nil, the record describes the client and session of requester, including the
+// user of the session when it was already authenticated.
+func NewRecord(event Event, r *http.Request, requester fosite.Requester) *Record {
+	record := &Record{Event: event}
+	if r != nil {
+		record.SourceIP = SourceIP(r)
+	}
+	if requester == nil {
+		return record
+	}
+	record.SessionID = requester.GetID()
+	if client := requester.GetClient(); client != nil {
+		record.ClientID = client.GetID()
+	}
+	if session, ok := requester.GetSession().(*psession.PinnipedSession); ok && session.Custom != nil {
+		record.Username = session.Custom.Username
+		record.UpstreamUsername = session.Custom.UpstreamUsername
+		record.IdentityProviderName = session.Custom.ProviderName
+		record.IdentityProviderType = string(session.Custom.ProviderType)
+	}
+	return record
+}
+
+// SourceIP returns the IP address of the client of the request, without its port.
+func SourceIP(r *http.Request) string {
+	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return sourceIP
+}
+
+// Log writes the record to every sink, stamped with the current time when it does not have a time yet.
+// Failures to write are logged, but otherwise ignored, so that the audit log never breaks a login.
+func Log(record *Record) {
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		plog.Error("could not encode audit record", err, "event", record.Event)
+		return
+	}
+	encoded = append(encoded, '\n')
+
+	for _, sink := range sinks {
+		if err := sink.Write(encoded); err != nil {
+			plog.Error("could not write audit record", err, "event", record.Event)
+		}
+	}
+}
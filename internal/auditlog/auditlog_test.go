@@ -0,0 +1,123 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package auditlog
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/psession"
+)
+
+type failingSink struct{}
+
+func (failingSink) Write(_ []byte) error {
+	return errors.New("some write error")
+}
+
+func TestNewRecord(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/some/path", nil)
+	r.RemoteAddr = "10.1.2.3:4567"
+
+	requester := &fosite.Request{
+		ID:     "some-request-id",
+		Client: &fosite.DefaultClient{ID: "some-client"},
+		Session: &psession.PinnipedSession{
+			Custom: &psession.CustomSessionData{
+				Username:         "some-username",
+				UpstreamUsername: "some-upstream-username",
+				ProviderName:     "some-ldap-idp",
+				ProviderType:     psession.ProviderTypeLDAP,
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		r         *http.Request
+		requester fosite.Requester
+		want      *Record
+	}{
+		{
+			name:      "with request and requester",
+			r:         r,
+			requester: requester,
+			want: &Record{
+				Event:                EventLoginSucceeded,
+				Username:             "some-username",
+				UpstreamUsername:     "some-upstream-username",
+				IdentityProviderName: "some-ldap-idp",
+				IdentityProviderType: "ldap",
+				ClientID:             "some-client",
+				SessionID:            "some-request-id",
+				SourceIP:             "10.1.2.3",
+			},
+		},
+		{
+			name:      "requester without a session",
+			requester: &fosite.Request{ID: "some-request-id", Client: &fosite.DefaultClient{ID: "some-client"}},
+			want: &Record{
+				Event:     EventLoginSucceeded,
+				ClientID:  "some-client",
+				SessionID: "some-request-id",
+			},
+		},
+		{
+			name: "neither request nor requester",
+			want: &Record{Event: EventLoginSucceeded},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewRecord(EventLoginSucceeded, tt.r, tt.requester))
+		})
+	}
+}
+
+func TestSourceIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/some/path", nil)
+
+	r.RemoteAddr = "[fd00::1]:443"
+	require.Equal(t, "fd00::1", SourceIP(r))
+
+	r.RemoteAddr = "some-address-without-port"
+	require.Equal(t, "some-address-without-port", SourceIP(r))
+}
+
+func TestLog(t *testing.T) {
+	t.Cleanup(func() { SetSinks() })
+
+	// Without sinks, records are dropped.
+	Log(&Record{Event: EventLoginFailed})
+
+	var first, second bytes.Buffer
+	SetSinks(NewWriterSink(&first), failingSink{}, NewWriterSink(&second))
+
+	Log(&Record{
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Event:     EventLoginFailed,
+		ClientID:  "pinniped-cli",
+		SourceIP:  "10.1.2.3",
+		Reason:    "username/password not accepted by upstream",
+		SessionID: "some-request-id",
+	})
+
+	want := `{"time":"2026-01-02T03:04:05Z","event":"LoginFailed","clientID":"pinniped-cli","sessionID":"some-request-id",` +
+		`"sourceIP":"10.1.2.3","reason":"username/password not accepted by upstream"}` + "\n"
+	require.Equal(t, want, first.String())
+	require.Equal(t, want, second.String())
+
+	// Records without a time are stamped with the current time.
+	record := &Record{Event: EventSessionGarbageCollected}
+	Log(record)
+	require.WithinDuration(t, time.Now(), record.Time, time.Minute)
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	webhookQueueLength    = 1000
+	webhookRequestTimeout = 10 * time.Second
+)
+
+type writerSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewWriterSink returns a Sink which writes the audit records to the writer, e.g. os.Stdout.
+func NewWriterSink(writer io.Writer) Sink {
+	return &writerSink{writer: writer}
+}
+
+func (s *writerSink) Write(record []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err := s.writer.Write(record)
+	return err
+}
+
+type fileSink struct {
+	mutex        sync.Mutex
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	file         *os.File
+	size         int64
+}
+
+// NewFileSink returns a Sink which appends the audit records to the file at the path. Before the file would grow
+// beyond maxSizeBytes, it is renamed to path.1, after renaming the older path.N files to path.N+1, and a new file
+// is started. Only maxBackups of the renamed files are kept.
+func NewFileSink(path string, maxSizeBytes int64, maxBackups int) (Sink, error) {
+	s := &fileSink{path: path, maxSizeBytes: maxSizeBytes, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) Write(record []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.size > 0 && s.size+int64(len(record)) > s.maxSizeBytes {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("could not rotate audit log file: %w", err)
+		}
+	}
+
+	n, err := s.file.Write(record)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not open audit log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("could not stat audit log file: %w", err)
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return s.open()
+	}
+
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(s.backupPath(i), s.backupPath(i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return s.open()
+}
+
+func (s *fileSink) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+type webhookSink struct {
+	url        string
+	httpClient *http.Client
+	queue      chan []byte
+}
+
+// NewWebhookSink returns a Sink which posts each audit record as JSON to the URL. The records are posted in the
+// background, one at a time, until the context is cancelled, so that a slow webhook does not slow down logins.
+// When too many records are waiting to be posted, new records are dropped.
+func NewWebhookSink(ctx context.Context, url string, httpClient *http.Client) Sink {
+	s := &webhookSink{
+		url:        url,
+		httpClient: httpClient,
+		queue:      make(chan []byte, webhookQueueLength),
+	}
+	go s.run(ctx)
+	return s
+}
+
+func (s *webhookSink) Write(record []byte) error {
+	select {
+	case s.queue <- record:
+		return nil
+	default:
+		return errors.New("audit webhook queue is full, dropping audit record")
+	}
+}
+
+func (s *webhookSink) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-s.queue:
+			if err := s.post(ctx, record); err != nil {
+				plog.WarningErr("could not post audit record to webhook", err, "url", s.url)
+			}
+		}
+	}
+}
+
+func (s *webhookSink) post(ctx context.Context, record []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(record))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package auditlog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		writes     []string
+		wantFiles  map[string]string
+	}{
+		{
+			name:       "no rotation needed",
+			maxBackups: 2,
+			writes:     []string{"aaaa\n", "bbbb\n"},
+			wantFiles:  map[string]string{"audit.log": "aaaa\nbbbb\n"},
+		},
+		{
+			name:       "rotation keeps the backups",
+			maxBackups: 2,
+			writes:     []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"},
+			wantFiles: map[string]string{
+				"audit.log":   "gggg\n",
+				"audit.log.1": "eeee\nffff\n",
+				"audit.log.2": "cccc\ndddd\n",
+			},
+		},
+		{
+			name:       "rotation without backups",
+			maxBackups: 0,
+			writes:     []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			wantFiles:  map[string]string{"audit.log": "cccc\n"},
+		},
+		{
+			name:       "a record larger than the maximum size is still written",
+			maxBackups: 1,
+			writes:     []string{"aaaaaaaaaaaaaaaaaaaa\n", "bbbb\n"},
+			wantFiles: map[string]string{
+				"audit.log":   "bbbb\n",
+				"audit.log.1": "aaaaaaaaaaaaaaaaaaaa\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "audit.log")
+
+			sink, err := NewFileSink(path, 10, tt.maxBackups)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = sink.(*fileSink).file.Close() })
+
+			for _, write := range tt.writes {
+				require.NoError(t, sink.Write([]byte(write)))
+			}
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			gotFiles := map[string]string{}
+			for _, entry := range entries {
+				contents, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				require.NoError(t, err)
+				gotFiles[entry.Name()] = string(contents)
+			}
+			require.Equal(t, tt.wantFiles, gotFiles)
+		})
+	}
+}
+
+func TestFileSinkAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte("aaaa\n"), 0o600))
+
+	sink, err := NewFileSink(path, 10, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sink.(*fileSink).file.Close() })
+
+	require.NoError(t, sink.Write([]byte("bbbb\n")))
+	require.NoError(t, sink.Write([]byte("cccc\n")))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "cccc\n", string(contents))
+	contents, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	require.Equal(t, "aaaa\nbbbb\n", string(contents))
+}
+
+func TestFileSinkCannotOpenFile(t *testing.T) {
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "missing-dir", "audit.log"), 10, 1)
+	require.ErrorContains(t, err, "could not open audit log file")
+	require.Nil(t, sink)
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if string(body) == "fail\n" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		received <- string(body)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	sink := NewWebhookSink(ctx, server.URL, server.Client())
+
+	require.NoError(t, sink.Write([]byte("fail\n")))
+	require.NoError(t, sink.Write([]byte(`{"event":"LoginSucceeded"}`+"\n")))
+
+	select {
+	case body := <-received:
+		// The failed record did not stop the sink from posting the next record.
+		require.Equal(t, `{"event":"LoginSucceeded"}`+"\n", body)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for the webhook to receive the record")
+	}
+}
+
+func TestWebhookSinkQueueFull(t *testing.T) {
+	// The sink is not running, so nothing takes the records out of the queue.
+	sink := &webhookSink{url: "https://example.com", httpClient: http.DefaultClient, queue: make(chan []byte, 1)}
+
+	require.NoError(t, sink.Write([]byte("first\n")))
+	require.EqualError(t, sink.Write([]byte("second\n")), "audit webhook queue is full, dropping audit record")
+}
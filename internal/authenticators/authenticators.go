@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package authenticators contains authenticator interfaces.
@@ -6,10 +6,46 @@ package authenticators
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"k8s.io/apiserver/pkg/authentication/user"
+
+	"go.pinniped.dev/internal/constable"
 )
 
+// ErrThrottled is returned by a UserAuthenticator which did not attempt to authenticate the user because
+// there were too many recent authentication attempts for the same username or from the same client.
+const ErrThrottled = constable.Error("too many recent authentication attempts, please try again later")
+
+// ThrottledError is an error which wraps ErrThrottled and says why the attempt was throttled and how long the
+// client should wait before trying again.
+type ThrottledError struct {
+	// Reason describes which limit was exceeded.
+	Reason string
+
+	// RetryAfter is how long the client should wait before trying again. Zero means unknown.
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return ErrThrottled.Error() + ": " + e.Reason
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return ErrThrottled
+}
+
+// RetryAfter returns how long the client should wait before trying again when the error wraps a ThrottledError,
+// or zero otherwise.
+func RetryAfter(err error) time.Duration {
+	var throttledErr *ThrottledError
+	if errors.As(err, &throttledErr) {
+		return throttledErr.RetryAfter
+	}
+	return 0
+}
+
 // UserAuthenticator is an interface is similar to the k8s token authenticator, but works with username/passwords instead
 // of a single token string.
 //
@@ -26,6 +62,10 @@ import (
 // - nil response
 // - false
 // - an error
+// 4. For an authentication which was not attempted because of too many recent attempts:
+// - nil response
+// - false
+// - an error which wraps ErrThrottled
 // Other combinations of return values must be avoided.
 //
 // See k8s.io/apiserver/pkg/authentication/authenticator/interfaces.go for the token authenticator
@@ -38,4 +78,20 @@ type Response struct {
 	User                   user.Info
 	DN                     string
 	ExtraRefreshAttributes map[string]string
+	AdditionalClaims       map[string]interface{}
+}
+
+type clientIPContextKey struct{}
+
+// ContextWithClientIP returns a copy of the context which carries the IP address of the client on whose behalf
+// the user is being authenticated, for use by a UserAuthenticator.
+func ContextWithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, clientIP)
+}
+
+// ClientIPFromContext returns the IP address of the client which was added to the context by ContextWithClientIP,
+// or an empty string when there is none.
+func ClientIPFromContext(ctx context.Context) string {
+	clientIP, _ := ctx.Value(clientIPContextKey{}).(string)
+	return clientIP
 }
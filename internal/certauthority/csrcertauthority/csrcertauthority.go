@@ -0,0 +1,178 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package csrcertauthority implements a ClientCertIssuer which asks a signer of the certificates.k8s.io API
+// of the cluster to issue the client certificates.
+package csrcertauthority
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/certificate/csr"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/issuer"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// ErrNotConfigured is returned while no signer is configured, so that the next ClientCertIssuer is tried.
+	ErrNotConfigured = constable.Error("the CertificateSigningRequest API is not configured")
+
+	// DefaultSignerName is the built-in signer of the Kubernetes API server client certificates.
+	DefaultSignerName = certificatesv1.KubeAPIServerClientSignerName
+
+	// ApprovalReason is the reason of the Approved condition which is added to each CertificateSigningRequest.
+	ApprovalReason = "PinnipedConciergeApproved"
+
+	// csrGenerateName prefixes the names of the CertificateSigningRequests which are created by the Concierge.
+	csrGenerateName = "pinniped-client-"
+
+	// minExpiration is the shortest lifetime which may be requested from the certificates.k8s.io API.
+	minExpiration = 10 * time.Minute
+
+	// issueTimeout limits how long a TokenCredentialRequest waits for the signer to issue a certificate.
+	issueTimeout = 30 * time.Second
+)
+
+// CA issues client certificates by creating and approving CertificateSigningRequests. The name of the signer
+// may be changed at any time, and no certificates are issued while it is empty. It is safe for concurrent use.
+type CA struct {
+	client kubernetes.Interface
+
+	mu         sync.RWMutex
+	signerName string
+}
+
+var _ issuer.ClientCertIssuer = &CA{}
+
+// New returns a CA which will issue certificates using the given client once SetSignerName has been called.
+func New(client kubernetes.Interface) *CA {
+	return &CA{client: client}
+}
+
+// SetSignerName changes the signerName of the CertificateSigningRequests. An empty name stops the CA from
+// issuing any more certificates.
+func (c *CA) SetSignerName(signerName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.signerName = signerName
+}
+
+// SignerName returns the current signerName of the CertificateSigningRequests, which is empty when the CA
+// is not configured.
+func (c *CA) SignerName() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.signerName
+}
+
+func (c *CA) Name() string {
+	return "certificate-signing-request-api"
+}
+
+// IssueClientCertPEM issues a new client certificate for the given identity and duration, returning it as a
+// pair of PEM-formatted byte slices for the certificate and private key, along with its NotAfter. The certificate
+// is valid for at least the minimum duration which is allowed by the certificates.k8s.io API, and the signer may
+// also choose a different duration, so the NotAfter is read from the issued certificate.
+func (c *CA) IssueClientCertPEM(username string, groups []string, ttl time.Duration) ([]byte, []byte, time.Time, error) {
+	signerName := c.SignerName()
+	if signerName == "" {
+		return nil, nil, time.Time{}, ErrNotConfigured
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("could not generate private key: %w", err)
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: username, Organization: groups},
+	}, privateKey)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("could not create certificate request: %w", err)
+	}
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("could not marshal private key: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER})
+
+	if ttl < minExpiration {
+		ttl = minExpiration
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), issueTimeout)
+	defer cancel()
+
+	csrs := c.client.CertificatesV1().CertificateSigningRequests()
+	created, err := csrs.Create(ctx, &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: csrGenerateName},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:           pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER}),
+			SignerName:        signerName,
+			ExpirationSeconds: csr.DurationToExpirationSeconds(ttl),
+			Usages:            []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageClientAuth},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("could not create CertificateSigningRequest: %w", err)
+	}
+	// The CertificateSigningRequest is not needed anymore once this function returns, so clean it up right away
+	// instead of waiting for the garbage collection of the cluster to delete it.
+	defer c.delete(created)
+
+	// Only the authenticated identity is included in the request, so it is safe to approve it.
+	created.Status.Conditions = append(created.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateApproved,
+		Status:         corev1.ConditionTrue,
+		Reason:         ApprovalReason,
+		Message:        "approved by the Pinniped Concierge for an authenticated TokenCredentialRequest",
+		LastUpdateTime: metav1.Now(),
+	})
+	if _, err := csrs.UpdateApproval(ctx, created.Name, created, metav1.UpdateOptions{}); err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("could not approve CertificateSigningRequest %s: %w", created.Name, err)
+	}
+
+	certPEM, err := csr.WaitForCertificate(ctx, c.client, created.Name, created.UID)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("certificate was not issued for CertificateSigningRequest %s: %w", created.Name, err)
+	}
+
+	// Make sure that the signer issued a certificate for our key.
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("invalid certificate was issued for CertificateSigningRequest %s: %w", created.Name, err)
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("invalid certificate was issued for CertificateSigningRequest %s: %w", created.Name, err)
+	}
+
+	return certPEM, keyPEM, leaf.NotAfter, nil
+}
+
+func (c *CA) delete(csrToDelete *certificatesv1.CertificateSigningRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), issueTimeout)
+	defer cancel()
+
+	err := c.client.CertificatesV1().CertificateSigningRequests().Delete(ctx, csrToDelete.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &csrToDelete.UID},
+	})
+	if err != nil {
+		plog.DebugErr("could not delete CertificateSigningRequest", err, "name", csrToDelete.Name)
+	}
+}
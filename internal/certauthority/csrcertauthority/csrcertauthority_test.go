@@ -0,0 +1,227 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package csrcertauthority
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"go.pinniped.dev/internal/certauthority"
+)
+
+// signCSR issues a client certificate for the public key and subject of the PEM-encoded certificate request,
+// like a signer of the certificates.k8s.io API would.
+func signCSR(t *testing.T, csrPEM []byte) []byte {
+	t.Helper()
+
+	block, _ := pem.Decode(csrPEM)
+	require.NotNil(t, block)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	require.NoError(t, err)
+	require.NoError(t, csr.CheckSignature())
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-signer"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, csr.PublicKey, caKey)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+}
+
+func TestIssueClientCertPEM(t *testing.T) {
+	otherCertPEM, _, err := func() ([]byte, []byte, error) {
+		ca, err := certauthority.New("other-ca", time.Hour)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ca.IssueClientCertPEM("other-user", nil, time.Hour)
+	}()
+	require.NoError(t, err)
+
+	// addCSRReactors simulates the API server and a signer, which calls sign for each approved request.
+	addCSRReactors := func(client *kubernetesfake.Clientset, sign func(csr *certificatesv1.CertificateSigningRequest)) {
+		client.PrependReactor("create", "certificatesigningrequests", func(action kubetesting.Action) (bool, runtime.Object, error) {
+			csr := action.(kubetesting.CreateAction).GetObject().(*certificatesv1.CertificateSigningRequest)
+			csr.Name = csr.GenerateName + "abc12"
+			csr.UID = types.UID("some-uid")
+			return false, nil, nil
+		})
+		client.PrependReactor("update", "certificatesigningrequests", func(action kubetesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "approval" {
+				return false, nil, nil
+			}
+			sign(action.(kubetesting.UpdateAction).GetObject().(*certificatesv1.CertificateSigningRequest))
+			return false, nil, nil
+		})
+	}
+
+	tests := []struct {
+		name           string
+		signerName     string
+		ttl            time.Duration
+		sign           func(t *testing.T, csr *certificatesv1.CertificateSigningRequest)
+		createErr      error
+		wantErr        string
+		wantExpiration int32
+	}{
+		{
+			name:       "not configured",
+			signerName: "",
+			wantErr:    "the CertificateSigningRequest API is not configured",
+		},
+		{
+			name:       "issues a certificate for a short ttl",
+			signerName: "example.com/signer",
+			ttl:        5 * time.Minute,
+			sign: func(t *testing.T, csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Certificate = signCSR(t, csr.Spec.Request)
+			},
+			wantExpiration: 600,
+		},
+		{
+			name:       "issues a certificate for a long ttl",
+			signerName: "example.com/signer",
+			ttl:        time.Hour,
+			sign: func(t *testing.T, csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Certificate = signCSR(t, csr.Spec.Request)
+			},
+			wantExpiration: 3600,
+		},
+		{
+			name:       "request cannot be created",
+			signerName: "example.com/signer",
+			ttl:        5 * time.Minute,
+			createErr:  errors.New("some create error"),
+			wantErr:    "could not create CertificateSigningRequest: some create error",
+		},
+		{
+			name:       "signer denies the request",
+			signerName: "example.com/signer",
+			ttl:        5 * time.Minute,
+			sign: func(t *testing.T, csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{{
+					Type:    certificatesv1.CertificateDenied,
+					Status:  corev1.ConditionTrue,
+					Reason:  "SomeReason",
+					Message: "some message",
+				}}
+			},
+			wantErr: "certificate was not issued for CertificateSigningRequest pinniped-client-abc12: " +
+				"cannot watch on the certificate signing request: certificate signing request is denied, reason: SomeReason, message: some message",
+		},
+		{
+			name:       "signer issues a certificate for another key",
+			signerName: "example.com/signer",
+			ttl:        5 * time.Minute,
+			sign: func(t *testing.T, csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Certificate = otherCertPEM
+			},
+			wantErr: "invalid certificate was issued for CertificateSigningRequest pinniped-client-abc12: tls: private key does not match public key",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := kubernetesfake.NewSimpleClientset()
+			addCSRReactors(client, func(csr *certificatesv1.CertificateSigningRequest) { tt.sign(t, csr) })
+			if tt.createErr != nil {
+				client.PrependReactor("create", "certificatesigningrequests", func(action kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.createErr
+				})
+			}
+
+			subject := New(client)
+			subject.SetSignerName(tt.signerName)
+			require.Equal(t, tt.signerName, subject.SignerName())
+
+			certPEM, keyPEM, notAfter, err := subject.IssueClientCertPEM("some-user", []string{"group-1", "group-2"}, tt.ttl)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, certPEM)
+				require.Nil(t, keyPEM)
+				require.Zero(t, notAfter)
+			} else {
+				require.NoError(t, err)
+
+				block, _ := pem.Decode(certPEM)
+				require.NotNil(t, block)
+				cert, err := x509.ParseCertificate(block.Bytes)
+				require.NoError(t, err)
+				require.Equal(t, "some-user", cert.Subject.CommonName)
+				require.Equal(t, []string{"group-1", "group-2"}, cert.Subject.Organization)
+				// The NotAfter is the one chosen by the signer, not the one which was requested.
+				require.Equal(t, cert.NotAfter, notAfter)
+
+				var created *certificatesv1.CertificateSigningRequest
+				for _, action := range client.Actions() {
+					if action.GetVerb() == "create" {
+						created = action.(kubetesting.CreateAction).GetObject().(*certificatesv1.CertificateSigningRequest)
+					}
+				}
+				require.NotNil(t, created)
+				require.Equal(t, tt.signerName, created.Spec.SignerName)
+				require.Equal(t, pointer.Int32(tt.wantExpiration), created.Spec.ExpirationSeconds)
+				require.Equal(t, []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageClientAuth}, created.Spec.Usages)
+			}
+
+			if tt.signerName != "" && tt.createErr == nil {
+				// The request must have been approved, and then deleted once it was not needed anymore.
+				var approved, deleted bool
+				for _, action := range client.Actions() {
+					switch {
+					case action.GetVerb() == "update" && action.GetSubresource() == "approval":
+						conditions := action.(kubetesting.UpdateAction).GetObject().(*certificatesv1.CertificateSigningRequest).Status.Conditions
+						require.Len(t, conditions, 1)
+						require.Equal(t, certificatesv1.CertificateApproved, conditions[0].Type)
+						require.Equal(t, corev1.ConditionTrue, conditions[0].Status)
+						require.Equal(t, ApprovalReason, conditions[0].Reason)
+						approved = true
+					case action.GetVerb() == "delete":
+						require.Equal(t, "pinniped-client-abc12", action.(kubetesting.DeleteAction).GetName())
+						deleted = true
+					}
+				}
+				require.True(t, approved)
+				require.True(t, deleted)
+			}
+		})
+	}
+}
@@ -32,16 +32,26 @@ func (c *ca) Name() string {
 }
 
 // IssueClientCertPEM issues a new client certificate for the given identity and duration, returning it as a
-// pair of PEM-formatted byte slices for the certificate and private key.
-func (c *ca) IssueClientCertPEM(username string, groups []string, ttl time.Duration) ([]byte, []byte, error) {
+// pair of PEM-formatted byte slices for the certificate and private key, along with its NotAfter.
+func (c *ca) IssueClientCertPEM(username string, groups []string, ttl time.Duration) ([]byte, []byte, time.Time, error) {
 	caCrtPEM, caKeyPEM := c.provider.CurrentCertKeyContent()
 	// in the future we could split dynamiccert.Private into two interfaces (Private and PrivateRead)
 	// and have this code take PrivateRead as input.  We would then add ourselves as a listener to
 	// the PrivateRead.  This would allow us to only reload the CA contents when they actually change.
 	ca, err := certauthority.Load(string(caCrtPEM), string(caKeyPEM))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, time.Time{}, err
 	}
 
-	return ca.IssueClientCertPEM(username, groups, ttl)
+	cert, err := ca.IssueClientCert(username, groups, ttl)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	certPEM, keyPEM, err := certauthority.ToPEM(cert)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	return certPEM, keyPEM, cert.Leaf.NotAfter, nil
 }
@@ -94,12 +94,13 @@ func TestCAIssuePEM(t *testing.T) {
 			// Can't run these steps in parallel, because each one depends on the previous steps being
 			// run.
 
-			crtPEM, keyPEM, err := issuePEM(provider, ca, step.caCrtPEM, step.caKeyPEM)
+			crtPEM, keyPEM, notAfter, err := issuePEM(provider, ca, step.caCrtPEM, step.caKeyPEM)
 
 			if step.wantError != "" {
 				require.EqualError(t, err, step.wantError)
 				require.Empty(t, crtPEM)
 				require.Empty(t, keyPEM)
+				require.Zero(t, notAfter)
 			} else {
 				require.NoError(t, err)
 				require.NotEmpty(t, crtPEM)
@@ -111,16 +112,17 @@ func TestCAIssuePEM(t *testing.T) {
 				crtAssertions.RequireOrganizations([]string{"some-group1", "some-group2"})
 				crtAssertions.RequireLifetime(time.Now(), time.Now().Add(time.Hour*24), time.Minute*10)
 				crtAssertions.RequireMatchesPrivateKey(string(keyPEM))
+				require.WithinDuration(t, time.Now().Add(time.Hour*24), notAfter, time.Minute)
 			}
 		})
 	}
 }
 
-func issuePEM(provider dynamiccert.Provider, ca issuer.ClientCertIssuer, caCrt, caKey []byte) ([]byte, []byte, error) {
+func issuePEM(provider dynamiccert.Provider, ca issuer.ClientCertIssuer, caCrt, caKey []byte) ([]byte, []byte, time.Time, error) {
 	// if setting fails, look at that error
 	if caCrt != nil || caKey != nil {
 		if err := provider.SetCertKeyContent(caCrt, caKey); err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, err
 		}
 	}
 
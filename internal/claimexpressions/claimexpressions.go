@@ -0,0 +1,236 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package claimexpressions computes identities from the claims of upstream OIDC tokens using CEL expressions.
+// See https://github.com/google/cel-spec for the expression language.
+package claimexpressions
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ClaimsVariableName is the name of the variable which holds the upstream claims in the expressions.
+	ClaimsVariableName = "claims"
+
+	// costLimit limits how much work an expression may do each time that it is evaluated, to avoid having
+	// an expression consume too many resources. It is the same limit which Kubernetes uses for its CEL expressions.
+	costLimit = 1000000
+)
+
+// Expressions are the compiled expressions which compute the username, groups, and additional claims.
+// Any of them may be unset, in which case the corresponding method returns that the expression is not configured.
+// A nil *Expressions is valid and has no expressions configured.
+type Expressions struct {
+	username         cel.Program
+	groups           cel.Program
+	additionalClaims map[string]cel.Program
+}
+
+// Compile compiles the given expressions. Empty expressions are left unset.
+func Compile(username string, groups string, additionalClaims map[string]string) (*Expressions, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Expressions{}
+	if e.username, err = compile(env, "username", username); err != nil {
+		return nil, err
+	}
+	if e.groups, err = compile(env, "groups", groups); err != nil {
+		return nil, err
+	}
+
+	// Compile in a stable order, so the same error is returned each time for the same config.
+	claimNames := make([]string, 0, len(additionalClaims))
+	for claimName := range additionalClaims {
+		claimNames = append(claimNames, claimName)
+	}
+	sort.Strings(claimNames)
+	for _, claimName := range claimNames {
+		program, err := compile(env, fmt.Sprintf("additionalClaims[%s]", claimName), additionalClaims[claimName])
+		if err != nil {
+			return nil, err
+		}
+		if program != nil {
+			if e.additionalClaims == nil {
+				e.additionalClaims = map[string]cel.Program{}
+			}
+			e.additionalClaims[claimName] = program
+		}
+	}
+
+	return e, nil
+}
+
+// StringExpression is a compiled expression which computes a single string, such as a user's unique ID.
+type StringExpression struct {
+	name    string
+	program cel.Program
+}
+
+// CompileString compiles an expression which must evaluate to a non-empty string. The name describes the
+// expression in error messages. The expression must not be empty.
+func CompileString(name string, expression string) (*StringExpression, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("%s expression must not be empty", name)
+	}
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	program, err := compile(env, name, expression)
+	if err != nil {
+		return nil, err
+	}
+	return &StringExpression{name: name, program: program}, nil
+}
+
+// Eval evaluates the expression, which must result in a non-empty string.
+func (s *StringExpression) Eval(claims map[string]interface{}) (string, error) {
+	return evalString(s.program, s.name, claims)
+}
+
+func newEnv() (*cel.Env, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(ClaimsVariableName, cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create CEL environment: %w", err)
+	}
+	return env, nil
+}
+
+func compile(env *cel.Env, name string, expression string) (cel.Program, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("could not compile %s expression: %w", name, issues.Err())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("could not create program for %s expression: %w", name, err)
+	}
+	return program, nil
+}
+
+// HasUsername returns true when the username expression is configured.
+func (e *Expressions) HasUsername() bool {
+	return e != nil && e.username != nil
+}
+
+// HasGroups returns true when the groups expression is configured.
+func (e *Expressions) HasGroups() bool {
+	return e != nil && e.groups != nil
+}
+
+// HasAdditionalClaims returns true when any of the additional claims expressions are configured.
+func (e *Expressions) HasAdditionalClaims() bool {
+	return e != nil && len(e.additionalClaims) > 0
+}
+
+// Username evaluates the username expression, which must result in a non-empty string.
+func (e *Expressions) Username(claims map[string]interface{}) (string, error) {
+	if !e.HasUsername() {
+		return "", errors.New("username expression is not configured")
+	}
+	return evalString(e.username, "username", claims)
+}
+
+// Groups evaluates the groups expression, which must result in a list of strings or a single string.
+// Empty group names are removed from the result.
+func (e *Expressions) Groups(claims map[string]interface{}) ([]string, error) {
+	if !e.HasGroups() {
+		return nil, errors.New("groups expression is not configured")
+	}
+	result, err := eval(e.groups, claims)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate groups expression: %w", err)
+	}
+	if group, ok := result.Value().(string); ok {
+		return []string{group}, nil
+	}
+	native, err := result.ConvertToNative(reflect.TypeOf([]string{}))
+	if err != nil {
+		return nil, fmt.Errorf("groups expression must evaluate to a list of strings, but evaluated to type %s", result.Type().TypeName())
+	}
+	var groups []string
+	for _, group := range native.([]string) {
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// AdditionalClaims evaluates each of the additional claims expressions, which may result in any JSON value.
+// It returns the successfully evaluated claims, and the errors of the others keyed by claim name.
+func (e *Expressions) AdditionalClaims(claims map[string]interface{}) (map[string]interface{}, map[string]error) {
+	if !e.HasAdditionalClaims() {
+		return nil, nil
+	}
+	values := make(map[string]interface{}, len(e.additionalClaims))
+	var errs map[string]error
+	for claimName, program := range e.additionalClaims {
+		value, err := evalJSON(program, claims)
+		if err != nil {
+			if errs == nil {
+				errs = map[string]error{}
+			}
+			errs[claimName] = err
+			continue
+		}
+		values[claimName] = value
+	}
+	return values, errs
+}
+
+func eval(program cel.Program, claims map[string]interface{}) (ref.Val, error) {
+	if claims == nil {
+		claims = map[string]interface{}{}
+	}
+	result, _, err := program.Eval(map[string]interface{}{ClaimsVariableName: claims})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func evalString(program cel.Program, name string, claims map[string]interface{}) (string, error) {
+	result, err := eval(program, claims)
+	if err != nil {
+		return "", fmt.Errorf("could not evaluate %s expression: %w", name, err)
+	}
+	value, ok := result.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("%s expression must evaluate to a string, but evaluated to type %s", name, result.Type().TypeName())
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s expression evaluated to an empty string", name)
+	}
+	return value, nil
+}
+
+func evalJSON(program cel.Program, claims map[string]interface{}) (interface{}, error) {
+	result, err := eval(program, claims)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate expression: %w", err)
+	}
+	native, err := result.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, fmt.Errorf("expression must evaluate to a JSON value, but evaluated to type %s", result.Type().TypeName())
+	}
+	return native.(*structpb.Value).AsInterface(), nil
+}
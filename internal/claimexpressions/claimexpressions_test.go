@@ -0,0 +1,258 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package claimexpressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name             string
+		username         string
+		groups           string
+		additionalClaims map[string]string
+		wantErr          string
+	}{
+		{
+			name: "no expressions",
+		},
+		{
+			name:             "valid expressions",
+			username:         `claims.given_name + "." + claims.family_name`,
+			groups:           `claims.groups.filter(g, g.startsWith("k8s-"))`,
+			additionalClaims: map[string]string{"department": `claims.department.lowerAscii()`},
+		},
+		{
+			name:     "invalid username expression",
+			username: `claims.given_name +`,
+			wantErr:  "could not compile username expression: ",
+		},
+		{
+			name:    "invalid groups expression",
+			groups:  `claims.groups.filter(`,
+			wantErr: "could not compile groups expression: ",
+		},
+		{
+			name:             "invalid additional claim expression",
+			additionalClaims: map[string]string{"a": `claims.a`, "b": `)`},
+			wantErr:          "could not compile additionalClaims[b] expression: ",
+		},
+		{
+			name:     "unknown variable",
+			username: `user.name`,
+			wantErr:  "could not compile username expression: ",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.username, tt.groups, tt.additionalClaims)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, e)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.username != "", e.HasUsername())
+			require.Equal(t, tt.groups != "", e.HasGroups())
+			require.Equal(t, len(tt.additionalClaims) > 0, e.HasAdditionalClaims())
+		})
+	}
+}
+
+func TestNilExpressions(t *testing.T) {
+	var e *Expressions
+	require.False(t, e.HasUsername())
+	require.False(t, e.HasGroups())
+	require.False(t, e.HasAdditionalClaims())
+
+	_, err := e.Username(nil)
+	require.EqualError(t, err, "username expression is not configured")
+	_, err = e.Groups(nil)
+	require.EqualError(t, err, "groups expression is not configured")
+	values, errs := e.AdditionalClaims(nil)
+	require.Nil(t, values)
+	require.Nil(t, errs)
+}
+
+func TestUsername(t *testing.T) {
+	claims := map[string]interface{}{
+		"given_name":  "Jane",
+		"family_name": "Doe",
+		"empty":       "",
+		"number":      float64(42),
+	}
+
+	tests := []struct {
+		name         string
+		expression   string
+		wantUsername string
+		wantErr      string
+	}{
+		{
+			name:         "concatenates claims",
+			expression:   `claims.given_name + "." + claims.family_name`,
+			wantUsername: "Jane.Doe",
+		},
+		{
+			name:         "uses string extension functions",
+			expression:   `claims.given_name.lowerAscii()`,
+			wantUsername: "jane",
+		},
+		{
+			name:         "falls back when a claim is missing",
+			expression:   `has(claims.email) ? claims.email : claims.given_name`,
+			wantUsername: "Jane",
+		},
+		{
+			name:       "missing claim",
+			expression: `claims.email`,
+			wantErr:    "could not evaluate username expression: no such key: email",
+		},
+		{
+			name:       "not a string",
+			expression: `claims.number`,
+			wantErr:    "username expression must evaluate to a string, but evaluated to type double",
+		},
+		{
+			name:       "empty string",
+			expression: `claims.empty`,
+			wantErr:    "username expression evaluated to an empty string",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.expression, "", nil)
+			require.NoError(t, err)
+
+			username, err := e.Username(claims)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantUsername, username)
+		})
+	}
+}
+
+func TestStringExpression(t *testing.T) {
+	_, err := CompileString("uid", "")
+	require.EqualError(t, err, "uid expression must not be empty")
+
+	_, err = CompileString("uid", `claims.id +`)
+	require.ErrorContains(t, err, "could not compile uid expression: ")
+
+	e, err := CompileString("uid", `string(claims.id)`)
+	require.NoError(t, err)
+
+	uid, err := e.Eval(map[string]interface{}{"id": float64(42)})
+	require.NoError(t, err)
+	require.Equal(t, "42", uid)
+
+	_, err = e.Eval(map[string]interface{}{})
+	require.EqualError(t, err, "could not evaluate uid expression: no such key: id")
+
+	e, err = CompileString("uid", `claims.id`)
+	require.NoError(t, err)
+	_, err = e.Eval(map[string]interface{}{"id": float64(42)})
+	require.EqualError(t, err, "uid expression must evaluate to a string, but evaluated to type double")
+}
+
+func TestGroups(t *testing.T) {
+	claims := map[string]interface{}{
+		"groups":  []interface{}{"k8s-admins", "k8s-devs", "other", ""},
+		"group":   "single-group",
+		"numbers": []interface{}{float64(1), float64(2)},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		wantGroups []string
+		wantErr    string
+	}{
+		{
+			name:       "filters a list",
+			expression: `claims.groups.filter(g, g.startsWith("k8s-"))`,
+			wantGroups: []string{"k8s-admins", "k8s-devs"},
+		},
+		{
+			name:       "transforms a list and removes empty group names",
+			expression: `claims.groups.map(g, g.upperAscii())`,
+			wantGroups: []string{"K8S-ADMINS", "K8S-DEVS", "OTHER"},
+		},
+		{
+			name:       "single string",
+			expression: `claims.group`,
+			wantGroups: []string{"single-group"},
+		},
+		{
+			name:       "combines claims",
+			expression: `claims.groups.filter(g, g == "other") + [claims.group]`,
+			wantGroups: []string{"other", "single-group"},
+		},
+		{
+			name:       "empty list",
+			expression: `[]`,
+			wantGroups: nil,
+		},
+		{
+			name:       "list of numbers",
+			expression: `claims.numbers`,
+			wantErr:    "groups expression must evaluate to a list of strings, but evaluated to type list",
+		},
+		{
+			name:       "missing claim",
+			expression: `claims.roles`,
+			wantErr:    "could not evaluate groups expression: no such key: roles",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile("", tt.expression, nil)
+			require.NoError(t, err)
+
+			groups, err := e.Groups(claims)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantGroups, groups)
+		})
+	}
+}
+
+func TestAdditionalClaims(t *testing.T) {
+	claims := map[string]interface{}{
+		"department": "Engineering",
+		"level":      float64(3),
+		"roles":      []interface{}{"a", "b"},
+	}
+
+	e, err := Compile("", "", map[string]string{
+		"department": `claims.department.lowerAscii()`,
+		"senior":     `claims.level >= 3.0`,
+		"roles":      `claims.roles`,
+		"summary":    `{"department": claims.department, "level": claims.level}`,
+		"missing":    `claims.missing`,
+	})
+	require.NoError(t, err)
+
+	values, errs := e.AdditionalClaims(claims)
+	require.Equal(t, map[string]interface{}{
+		"department": "engineering",
+		"senior":     true,
+		"roles":      []interface{}{"a", "b"},
+		"summary":    map[string]interface{}{"department": "Engineering", "level": float64(3)},
+	}, values)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs["missing"], "could not evaluate expression: no such key: missing")
+}
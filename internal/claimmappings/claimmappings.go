@@ -0,0 +1,149 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package claimmappings copies values from the downstream sessions of users into top-level claims of their
+// downstream ID tokens, as configured by the claimMappings of a FederationDomain.
+package claimmappings
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/psession"
+)
+
+const (
+	// SourceUsername is the downstream username of the user. It is only mapped when the ID token contains the username.
+	SourceUsername = "username"
+
+	// SourceGroups is the downstream group membership of the user. It is only mapped when the ID token contains
+	// the groups.
+	SourceGroups = "groups"
+
+	// SourceUpstreamSubject is the sub claim of the upstream OIDC identity provider.
+	SourceUpstreamSubject = "upstreamSubject"
+
+	// SourceUpstreamIssuer is the iss claim of the upstream OIDC identity provider.
+	SourceUpstreamIssuer = "upstreamIssuer"
+
+	// SourceUpstreamProviderName is the name of the identity provider resource which the user used to log in.
+	SourceUpstreamProviderName = "upstreamProviderName"
+
+	// SourceUpstreamProviderType is the type of the identity provider which the user used to log in.
+	SourceUpstreamProviderType = "upstreamProviderType"
+
+	// SourceAdditionalClaimPrefix is the prefix of the sources which name one of the additional claims of the
+	// session, which were mapped from the upstream ID token or from the LDAP attributes of the user.
+	SourceAdditionalClaimPrefix = oidcapi.IDTokenClaimAdditionalClaims + "."
+)
+
+// reservedClaims are the claims of downstream ID tokens which are set by the Supervisor, so they cannot be mapped.
+var reservedClaims = sets.New[string](
+	oidcapi.IDTokenClaimIssuer,
+	oidcapi.IDTokenClaimSubject,
+	oidcapi.IDTokenClaimAuthorizedParty,
+	oidcapi.IDTokenClaimSessionID,
+	oidcapi.IDTokenClaimUsername,
+	oidcapi.IDTokenClaimGroups,
+	oidcapi.IDTokenClaimAdditionalClaims,
+	"aud", "exp", "iat", "nbf", "jti", "rat", "auth_time", "nonce", "acr", "amr", "at_hash", "c_hash",
+)
+
+// Mapping copies the value of the Source into the Claim of downstream ID tokens.
+type Mapping struct {
+	Claim  string
+	Source string
+}
+
+// Mappings are applied in order. A nil Mappings is valid and maps nothing.
+type Mappings []Mapping
+
+// Validate returns a message for each problem of the mappings, or nothing when they are valid. The fieldPath is
+// used to refer to the mappings in the messages.
+func Validate(fieldPath string, mappings []Mapping) []string {
+	var messages []string
+	claims := sets.New[string]()
+	for i, mapping := range mappings {
+		switch {
+		case mapping.Claim == "":
+			messages = append(messages, fmt.Sprintf("%s[%d].claim must not be empty", fieldPath, i))
+		case reservedClaims.Has(mapping.Claim):
+			messages = append(messages, fmt.Sprintf("%s[%d].claim %q is reserved for use by the Supervisor", fieldPath, i, mapping.Claim))
+		case claims.Has(mapping.Claim):
+			messages = append(messages, fmt.Sprintf("%s[%d].claim %q is mapped more than once", fieldPath, i, mapping.Claim))
+		}
+		claims.Insert(mapping.Claim)
+
+		if !isValidSource(mapping.Source) {
+			messages = append(messages, fmt.Sprintf("%s[%d].source %q is not supported", fieldPath, i, mapping.Source))
+		}
+	}
+	return messages
+}
+
+func isValidSource(source string) bool {
+	switch source {
+	case SourceUsername, SourceGroups, SourceUpstreamSubject, SourceUpstreamIssuer,
+		SourceUpstreamProviderName, SourceUpstreamProviderType:
+		return true
+	default:
+		return strings.HasPrefix(source, SourceAdditionalClaimPrefix) && len(source) > len(SourceAdditionalClaimPrefix)
+	}
+}
+
+// Apply returns the mapped claims for the given session. Mappings whose sources have no value in the session are
+// skipped, so the result may be empty.
+func (m Mappings) Apply(session *psession.PinnipedSession) map[string]interface{} {
+	if len(m) == 0 || session == nil || session.Fosite == nil || session.Fosite.Claims == nil {
+		return nil
+	}
+
+	mapped := map[string]interface{}{}
+	for _, mapping := range m {
+		if value, ok := sourceValue(session, mapping.Source); ok {
+			mapped[mapping.Claim] = value
+		}
+	}
+	return mapped
+}
+
+func sourceValue(session *psession.PinnipedSession, source string) (interface{}, bool) {
+	extra := session.Fosite.Claims.Extra
+	custom := session.Custom
+	if custom == nil {
+		custom = &psession.CustomSessionData{}
+	}
+
+	switch source {
+	case SourceUsername:
+		value, ok := extra[oidcapi.IDTokenClaimUsername]
+		return value, ok
+	case SourceGroups:
+		value, ok := extra[oidcapi.IDTokenClaimGroups]
+		return value, ok
+	case SourceUpstreamSubject:
+		if custom.OIDC == nil || custom.OIDC.UpstreamSubject == "" {
+			return nil, false
+		}
+		return custom.OIDC.UpstreamSubject, true
+	case SourceUpstreamIssuer:
+		if custom.OIDC == nil || custom.OIDC.UpstreamIssuer == "" {
+			return nil, false
+		}
+		return custom.OIDC.UpstreamIssuer, true
+	case SourceUpstreamProviderName:
+		return custom.ProviderName, custom.ProviderName != ""
+	case SourceUpstreamProviderType:
+		return string(custom.ProviderType), custom.ProviderType != ""
+	}
+
+	additionalClaims, ok := extra[oidcapi.IDTokenClaimAdditionalClaims].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := additionalClaims[strings.TrimPrefix(source, SourceAdditionalClaimPrefix)]
+	return value, ok
+}
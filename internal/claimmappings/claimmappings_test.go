@@ -0,0 +1,137 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package claimmappings
+
+import (
+	"testing"
+
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/psession"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []Mapping
+		want     []string
+	}{
+		{
+			name: "no mappings",
+		},
+		{
+			name: "every supported source",
+			mappings: []Mapping{
+				{Claim: "a", Source: "username"},
+				{Claim: "b", Source: "groups"},
+				{Claim: "c", Source: "upstreamSubject"},
+				{Claim: "d", Source: "upstreamIssuer"},
+				{Claim: "e", Source: "upstreamProviderName"},
+				{Claim: "f", Source: "upstreamProviderType"},
+				{Claim: "g", Source: "additionalClaims.email"},
+			},
+		},
+		{
+			name: "invalid mappings",
+			mappings: []Mapping{
+				{Claim: "", Source: "username"},
+				{Claim: "aud", Source: "groups"},
+				{Claim: "additionalClaims", Source: "additionalClaims.email"},
+				{Claim: "email", Source: "additionalClaims."},
+				{Claim: "email", Source: "upstreamEmail"},
+			},
+			want: []string{
+				`spec.claimMappings[0].claim must not be empty`,
+				`spec.claimMappings[1].claim "aud" is reserved for use by the Supervisor`,
+				`spec.claimMappings[2].claim "additionalClaims" is reserved for use by the Supervisor`,
+				`spec.claimMappings[3].source "additionalClaims." is not supported`,
+				`spec.claimMappings[4].claim "email" is mapped more than once`,
+				`spec.claimMappings[4].source "upstreamEmail" is not supported`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Validate("spec.claimMappings", tt.mappings))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	mappings := Mappings{
+		{Claim: "preferred_username", Source: "username"},
+		{Claim: "roles", Source: "groups"},
+		{Claim: "upstream_sub", Source: "upstreamSubject"},
+		{Claim: "upstream_iss", Source: "upstreamIssuer"},
+		{Claim: "idp", Source: "upstreamProviderName"},
+		{Claim: "idp_type", Source: "upstreamProviderType"},
+		{Claim: "email", Source: "additionalClaims.email"},
+	}
+
+	tests := []struct {
+		name     string
+		mappings Mappings
+		session  *psession.PinnipedSession
+		want     map[string]interface{}
+	}{
+		{
+			name:     "nil mappings",
+			mappings: nil,
+			session:  &psession.PinnipedSession{Fosite: &openid.DefaultSession{Claims: &jwt.IDTokenClaims{}}},
+			want:     nil,
+		},
+		{
+			name:     "session with every source",
+			mappings: mappings,
+			session: &psession.PinnipedSession{
+				Fosite: &openid.DefaultSession{Claims: &jwt.IDTokenClaims{Extra: map[string]interface{}{
+					"username":         "some-username",
+					"groups":           []string{"group1", "group2"},
+					"additionalClaims": map[string]interface{}{"email": "user@example.com"},
+				}}},
+				Custom: &psession.CustomSessionData{
+					ProviderName: "some-oidc-idp",
+					ProviderType: psession.ProviderTypeOIDC,
+					OIDC: &psession.OIDCSessionData{
+						UpstreamSubject: "some-upstream-subject",
+						UpstreamIssuer:  "https://upstream.example.com",
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"preferred_username": "some-username",
+				"roles":              []string{"group1", "group2"},
+				"upstream_sub":       "some-upstream-subject",
+				"upstream_iss":       "https://upstream.example.com",
+				"idp":                "some-oidc-idp",
+				"idp_type":           "oidc",
+				"email":              "user@example.com",
+			},
+		},
+		{
+			name:     "session without values for the sources, e.g. when the username and groups scopes were not granted",
+			mappings: mappings,
+			session: &psession.PinnipedSession{
+				Fosite: &openid.DefaultSession{Claims: &jwt.IDTokenClaims{}},
+				Custom: &psession.CustomSessionData{
+					ProviderName: "some-ldap-idp",
+					ProviderType: psession.ProviderTypeLDAP,
+				},
+			},
+			want: map[string]interface{}{
+				"idp":      "some-ldap-idp",
+				"idp_type": "ldap",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.mappings.Apply(tt.session))
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package impersonator
@@ -55,6 +55,7 @@ import (
 	"go.pinniped.dev/internal/httputil/securityheader"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/proxyprotocol"
 	"go.pinniped.dev/internal/valuelesscontext"
 )
 
@@ -63,24 +64,32 @@ import (
 // That start function takes a stopCh which can be used to stop the server.
 // Once a server has been stopped, don't start it again using the start function.
 // Instead, call the factory function again to get a new start function.
+// When proxyProtocol is not nil, every connection must begin with a PROXY protocol header.
+// When tlsPolicy is not nil, it overrides the default TLS versions and cipher suites of the server.
 type FactoryFunc func(
 	port int,
 	dynamicCertProvider dynamiccert.Private,
 	impersonationProxySignerCA dynamiccert.Public,
+	proxyProtocol *proxyprotocol.Config,
+	tlsPolicy *ptls.Policy,
 ) (func(stopCh <-chan struct{}) error, error)
 
 func New(
 	port int,
 	dynamicCertProvider dynamiccert.Private,
 	impersonationProxySignerCA dynamiccert.Public,
+	proxyProtocol *proxyprotocol.Config,
+	tlsPolicy *ptls.Policy,
 ) (func(stopCh <-chan struct{}) error, error) {
-	return newInternal(port, dynamicCertProvider, impersonationProxySignerCA, kubeclient.Secure, nil, nil, nil)
+	return newInternal(port, dynamicCertProvider, impersonationProxySignerCA, proxyProtocol, tlsPolicy, kubeclient.Secure, nil, nil, nil)
 }
 
 func newInternal( //nolint:funlen // yeah, it's kind of long.
 	port int,
 	dynamicCertProvider dynamiccert.Private,
 	impersonationProxySignerCA dynamiccert.Public,
+	proxyProtocol *proxyprotocol.Config,
+	tlsPolicy *ptls.Policy,
 	restConfigFunc ptls.RestConfigFunc, // for unit testing, should always be kubeclient.Secure in production
 	clientOpts []kubeclient.Option, // for unit testing, should always be nil in production
 	recOpts func(*genericoptions.RecommendedOptions), // for unit testing, should always be nil in production
@@ -111,6 +120,7 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 		if err := ptls.DefaultRecommendedOptions(recommendedOptions, restConfigFunc); err != nil {
 			return nil, fmt.Errorf("failed to secure recommended options: %w", err)
 		}
+		tlsPolicy.ApplyToServingOptions(recommendedOptions.SecureServing)
 
 		// Wire up the impersonation proxy signer CA as another valid authenticator for client cert auth,
 		// along with the Kube API server's CA.
@@ -148,6 +158,17 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 			return nil, err
 		}
 
+		// Learn the addresses of the original clients from the load balancer in front of this server, so that
+		// they are used as the remote address of each request, e.g. in audit logs and forwarded headers.
+		if proxyProtocol != nil {
+			serverConfig.SecureServing.Listener = proxyprotocol.NewListener(serverConfig.SecureServing.Listener, *proxyProtocol)
+			listener = serverConfig.SecureServing.Listener
+		}
+
+		// Count the open client connections and the bytes which they transfer.
+		serverConfig.SecureServing.Listener = newInstrumentedListener(serverConfig.SecureServing.Listener)
+		listener = serverConfig.SecureServing.Listener
+
 		// Loopback authentication to this server does not really make sense since we just proxy everything to
 		// the Kube API server, thus we replace loopback connection config with one that does direct connections
 		// the Kube API server. Loopback config is mainly used by post start hooks, so this is mostly future proofing.
@@ -452,6 +473,9 @@ func tokenFrom(ctx context.Context) string {
 	return token
 }
 
+// clientIPAuditAnnotationKey is the audit annotation which records the address of the client.
+const clientIPAuditAnnotationKey = "impersonation-proxy.concierge.pinniped.dev/client-ip"
+
 // contextKey type is unexported to prevent collisions.
 type contextKey int
 
@@ -522,6 +546,12 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 				return
 			}
 
+			// record the address of the client, which is the original client behind the load balancer when the
+			// PROXY protocol is used, unlike the source IPs of the audit event which trust client provided headers
+			if clientIP, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				audit.AddAuditAnnotation(r.Context(), clientIPAuditAnnotationKey, clientIP)
+			}
+
 			// grab the request's bearer token if present.  this is optional and does not fail the request if missing.
 			token := tokenFrom(r.Context())
 
@@ -564,10 +594,12 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 				"isUpgradeRequest", isUpgradeRequest,
 			)
 
-			// do not allow the client to cause log confusion by spoofing this header
-			if len(r.Header.Values("X-Forwarded-For")) > 0 {
+			// do not allow the client to cause log confusion by spoofing these headers,
+			// the reverse proxy sets X-Forwarded-For to the address of the client
+			if len(r.Header.Values("X-Forwarded-For")) > 0 || len(r.Header.Values("X-Real-Ip")) > 0 {
 				r = utilnet.CloneRequest(r)
 				r.Header.Del("X-Forwarded-For")
+				r.Header.Del("X-Real-Ip")
 			}
 
 			// the http2 code seems to call Close concurrently which can lead to data races
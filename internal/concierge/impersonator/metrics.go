@@ -0,0 +1,101 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"net"
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "pinniped_concierge"
+	metricsSubsystem = "impersonation_proxy"
+)
+
+//nolint:gochecknoglobals // Metrics are registered once with the global registry which is served by the Concierge.
+var (
+	activeConnections = metrics.NewGauge(&metrics.GaugeOpts{
+		Namespace:      metricsNamespace,
+		Subsystem:      metricsSubsystem,
+		Name:           "active_connections",
+		Help:           "Number of open client connections to the impersonation proxy.",
+		StabilityLevel: metrics.ALPHA,
+	})
+
+	receivedBytes = metrics.NewCounter(&metrics.CounterOpts{
+		Namespace:      metricsNamespace,
+		Subsystem:      metricsSubsystem,
+		Name:           "received_bytes_total",
+		Help:           "Number of bytes received from the clients of the impersonation proxy, including TLS overhead.",
+		StabilityLevel: metrics.ALPHA,
+	})
+
+	sentBytes = metrics.NewCounter(&metrics.CounterOpts{
+		Namespace:      metricsNamespace,
+		Subsystem:      metricsSubsystem,
+		Name:           "sent_bytes_total",
+		Help:           "Number of bytes sent to the clients of the impersonation proxy, including TLS overhead.",
+		StabilityLevel: metrics.ALPHA,
+	})
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the impersonation proxy with the global registry, which is served on the
+// /metrics endpoint of the Concierge's aggregated API server and on its metrics listener. It is safe to call more
+// than once.
+func RegisterMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(activeConnections, receivedBytes, sentBytes)
+	})
+}
+
+// newInstrumentedListener wraps a listener so that the connections which it accepts are counted while they are
+// open, along with the bytes which they send and receive.
+func newInstrumentedListener(inner net.Listener) net.Listener {
+	return &instrumentedListener{Listener: inner}
+}
+
+type instrumentedListener struct {
+	net.Listener
+}
+
+// Accept implements net.Listener.
+func (l *instrumentedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	activeConnections.Inc()
+	return &instrumentedConn{Conn: c}, nil
+}
+
+type instrumentedConn struct {
+	net.Conn
+	closeOnce sync.Once
+}
+
+func (c *instrumentedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		receivedBytes.Add(float64(n))
+	}
+	return n, err
+}
+
+func (c *instrumentedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		sentBytes.Add(float64(n))
+	}
+	return n, err
+}
+
+func (c *instrumentedConn) Close() error {
+	c.closeOnce.Do(activeConnections.Dec)
+	return c.Conn.Close()
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestInstrumentedListener(t *testing.T) {
+	RegisterMetrics()
+	RegisterMetrics() // registering again is a no-op
+
+	gauge := func() float64 {
+		t.Helper()
+		value, err := testutil.GetGaugeMetricValue(activeConnections)
+		require.NoError(t, err)
+		return value
+	}
+	counter := func(m metrics.CounterMetric) float64 {
+		t.Helper()
+		value, err := testutil.GetCounterMetricValue(m)
+		require.NoError(t, err)
+		return value
+	}
+	startingConnections, startingReceived, startingSent := gauge(), counter(receivedBytes), counter(sentBytes)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l := newInstrumentedListener(inner)
+	t.Cleanup(func() { _ = l.Close() })
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	server, err := l.Accept()
+	require.NoError(t, err)
+	require.Equal(t, startingConnections+1, gauge())
+
+	_, err = client.Write([]byte("hello"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(server, make([]byte, 5))
+	require.NoError(t, err)
+
+	_, err = server.Write([]byte("hi"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(client, make([]byte, 2))
+	require.NoError(t, err)
+
+	require.Equal(t, startingReceived+5, counter(receivedBytes))
+	require.Equal(t, startingSent+2, counter(sentBytes))
+
+	require.NoError(t, server.Close())
+	require.Error(t, server.Close()) // closing again does not count the connection twice
+	require.Equal(t, startingConnections, gauge())
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package server is the command line entry point for pinniped-concierge.
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"time"
 
@@ -21,21 +22,31 @@ import (
 	genericoptions "k8s.io/apiserver/pkg/server/options"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
 
 	conciergeopenapi "go.pinniped.dev/generated/latest/client/concierge/openapi"
+	"go.pinniped.dev/internal/certauthority/csrcertauthority"
 	"go.pinniped.dev/internal/certauthority/dynamiccertauthority"
 	"go.pinniped.dev/internal/concierge/apiserver"
+	"go.pinniped.dev/internal/concierge/impersonator"
 	conciergescheme "go.pinniped.dev/internal/concierge/scheme"
 	"go.pinniped.dev/internal/config/concierge"
 	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/controller/kubecertagent"
 	"go.pinniped.dev/internal/controllerinit"
 	"go.pinniped.dev/internal/controllermanager"
+	"go.pinniped.dev/internal/credentialpolicy"
 	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/debugserver"
 	"go.pinniped.dev/internal/downward"
 	"go.pinniped.dev/internal/dynamiccert"
+	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/healthcheck"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/issuer"
 	"go.pinniped.dev/internal/kubeclient"
+	"go.pinniped.dev/internal/metricsserver"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/registry/credentialrequest"
 )
@@ -107,6 +118,28 @@ func (a *App) runServer(ctx context.Context) error {
 		return fmt.Errorf("could not load config: %w", err)
 	}
 
+	// Apply changes to the log level and format without a restart.
+	plog.WatchConfigFile(ctx, a.configPath, cfg.Log)
+
+	if cfg.Debug.Port != nil {
+		if err := debugserver.Start(ctx, *cfg.Debug.Port); err != nil {
+			return fmt.Errorf("could not start debug server: %w", err)
+		}
+	}
+
+	// Serve the metrics of the TokenCredentialRequest API, the authenticators, the kube cert agent, and the
+	// impersonation proxy on the /metrics endpoint of the aggregated API server, and also on the metrics
+	// listener when it is enabled.
+	credentialrequest.RegisterMetrics()
+	authncache.RegisterMetrics()
+	kubecertagent.RegisterMetrics()
+	impersonator.RegisterMetrics()
+	if cfg.Metrics.Port != nil {
+		if err := metricsserver.Start(ctx, *cfg.Metrics.Port); err != nil {
+			return fmt.Errorf("could not start metrics server: %w", err)
+		}
+	}
+
 	// Discover in which namespace we are installed.
 	podInfo, err := downward.Load(a.downwardAPIPath)
 	if err != nil {
@@ -131,13 +164,41 @@ func (a *App) runServer(ctx context.Context) error {
 	// cert issuer used to issue certs to Pinniped clients wishing to login.
 	impersonationProxySigningCertProvider := dynamiccert.NewCA("impersonation-proxy-signing-cert")
 
+	// This holds the lifetime of the certs issued to Pinniped clients wishing to login. It is
+	// updated by a controller whenever the CredentialIssuer changes.
+	clientCertLifetime := issuer.NewDynamicClientCertLifetime()
+
+	// This holds the policy which decides whether certs may be issued to Pinniped clients wishing
+	// to login. It is updated by a controller whenever the CredentialIssuer changes.
+	credentialPolicy := credentialpolicy.NewDynamicPolicy()
+
+	// This cert issuer will be used to issue certs to Pinniped clients wishing to login using the
+	// certificates.k8s.io API, when it is configured on the CredentialIssuer. It does not use the
+	// leader election client because certs are issued by every pod.
+	csrClient, err := kubeclient.New()
+	if err != nil {
+		return fmt.Errorf("could not create client for the certificates.k8s.io API: %w", err)
+	}
+	csrCertAuthority := csrcertauthority.New(csrClient.Kubernetes)
+
+	// Record Events regarding the authenticators of TokenCredentialRequests, so that the issuance of cluster
+	// credentials can be traced. It does not use the leader election client because requests are handled by
+	// every pod.
+	eventClient, err := kubeclient.New(kubeclient.WithMiddleware(groupsuffix.New(*cfg.APIGroupSuffix)))
+	if err != nil {
+		return fmt.Errorf("could not create client for Events: %w", err)
+	}
+	eventBroadcaster := events.NewEventBroadcasterAdapter(eventClient.Kubernetes)
+	eventBroadcaster.StartRecordingToSink(ctx.Done())
+	defer eventBroadcaster.Shutdown()
+
 	// Get the "real" name of the login concierge API group (i.e., the API group name with the
 	// injected suffix).
 	scheme, loginGV, identityGV := conciergescheme.New(*cfg.APIGroupSuffix)
 
 	// Prepare to start the controllers, but defer actually starting them until the
 	// post start hook of the aggregated API server.
-	buildControllers, err := controllermanager.PrepareControllers(
+	buildControllers, readyzChecks, err := controllermanager.PrepareControllers(
 		&controllermanager.Config{
 			ServerInstallationInfo:           podInfo,
 			APIGroupSuffix:                   *cfg.APIGroupSuffix,
@@ -151,6 +212,9 @@ func (a *App) runServer(ctx context.Context) error {
 			ServingCertDuration:              time.Duration(*cfg.APIConfig.ServingCertificateConfig.DurationSeconds) * time.Second,
 			ServingCertRenewBefore:           time.Duration(*cfg.APIConfig.ServingCertificateConfig.RenewBeforeSeconds) * time.Second,
 			AuthenticatorCache:               authenticators,
+			ClientCertLifetime:               clientCertLifetime,
+			CredentialPolicy:                 credentialPolicy,
+			CSRCertAuthority:                 csrCertAuthority,
 			// This port should be safe to cast because the config reader already validated it.
 			ImpersonationProxyServerPort: int(*cfg.ImpersonationProxyServerPort),
 		},
@@ -160,8 +224,12 @@ func (a *App) runServer(ctx context.Context) error {
 	}
 
 	certIssuer := issuer.ClientCertIssuers{
-		dynamiccertauthority.New(dynamicSigningCertProvider),            // attempt to use the real Kube CA if possible
-		dynamiccertauthority.New(impersonationProxySigningCertProvider), // fallback to our internal CA if we need to
+		// attempt to use the real Kube CA if possible
+		dynamiccertauthority.New(dynamicSigningCertProvider),
+		// then try the certificates.k8s.io API, which only issues certs when it is configured on the CredentialIssuer
+		csrCertAuthority,
+		// fallback to our internal CA if we need to
+		dynamiccertauthority.New(impersonationProxySigningCertProvider),
 	}
 
 	// Get the aggregated API server config.
@@ -169,6 +237,9 @@ func (a *App) runServer(ctx context.Context) error {
 		dynamicServingCertProvider,
 		authenticators,
 		certIssuer,
+		clientCertLifetime,
+		credentialPolicy,
+		eventBroadcaster.NewRecorder("pinniped-concierge"),
 		buildControllers,
 		*cfg.APIGroupSuffix,
 		*cfg.AggregatedAPIServerPort,
@@ -186,6 +257,12 @@ func (a *App) runServer(ctx context.Context) error {
 		return fmt.Errorf("could not create aggregated API server: %w", err)
 	}
 
+	// Make the /readyz endpoint also fail when the controllers or the serving certificate are not healthy.
+	readyzChecks = append(readyzChecks, healthcheck.ServingCertificate(dynamicServingCertProvider, clock.RealClock{}))
+	if err := server.GenericAPIServer.AddReadyzChecks(readyzChecks...); err != nil {
+		return fmt.Errorf("could not add readiness checks: %w", err)
+	}
+
 	// Run the server. Its post-start hook will start the controllers.
 	return server.GenericAPIServer.PrepareRun().Run(ctx.Done())
 }
@@ -195,6 +272,9 @@ func getAggregatedAPIServerConfig(
 	dynamicCertProvider dynamiccert.Private,
 	authenticator credentialrequest.TokenCredentialRequestAuthenticator,
 	issuer issuer.ClientCertIssuer,
+	clientCertLifetime *issuer.DynamicClientCertLifetime,
+	credentialPolicy *credentialpolicy.DynamicPolicy,
+	eventRecorder events.EventRecorder,
 	buildControllers controllerinit.RunnerBuilder,
 	apiGroupSuffix string,
 	aggregatedAPIServerPort int64,
@@ -240,12 +320,19 @@ func getAggregatedAPIServerConfig(
 	if err := recommendedOptions.ApplyTo(serverConfig); err != nil {
 		return nil, fmt.Errorf("failed to apply recommended options: %w", err)
 	}
+	// Remember the IP address of the client of each request, so that the credential issuance policy can use it.
+	serverConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		return genericapiserver.DefaultBuildHandlerChain(credentialpolicy.WithSourceIP(apiHandler), c)
+	}
 
 	apiServerConfig := &apiserver.Config{
 		GenericConfig: serverConfig,
 		ExtraConfig: apiserver.ExtraConfig{
 			Authenticator:                 authenticator,
 			Issuer:                        issuer,
+			ClientCertLifetime:            clientCertLifetime,
+			CredentialPolicy:              credentialPolicy,
+			EventRecorder:                 eventRecorder,
 			BuildControllersPostStartHook: buildControllers,
 			Scheme:                        scheme,
 			NegotiatedSerializer:          codecs,
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package concierge contains functionality to load/store Config's from/to
@@ -79,6 +79,14 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 		return nil, fmt.Errorf("validate names: %w", err)
 	}
 
+	if err := validateDebug(config.Debug); err != nil {
+		return nil, fmt.Errorf("validate debug: %w", err)
+	}
+
+	if err := validateMetrics(&config); err != nil {
+		return nil, fmt.Errorf("validate metrics: %w", err)
+	}
+
 	plog.MaybeSetDeprecatedLogLevel(config.LogLevel, &config.Log)
 	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, config.Log); err != nil {
 		return nil, fmt.Errorf("validate log level: %w", err)
@@ -183,6 +191,33 @@ func validateAPIGroupSuffix(apiGroupSuffix string) error {
 	return groupsuffix.Validate(apiGroupSuffix)
 }
 
+func validateDebug(spec DebugSpec) error {
+	if spec.Port == nil {
+		return nil
+	}
+	if err := validateServerPort(spec.Port); err != nil {
+		return fmt.Errorf("port %w", err)
+	}
+	return nil
+}
+
+func validateMetrics(config *Config) error {
+	port := config.Metrics.Port
+	if port == nil {
+		return nil
+	}
+	if err := validateServerPort(port); err != nil {
+		return fmt.Errorf("port %w", err)
+	}
+	// The metrics listener binds to every interface, so it cannot share a port with the other listeners.
+	for _, otherPort := range []*int64{config.AggregatedAPIServerPort, config.ImpersonationProxyServerPort, config.Debug.Port} {
+		if otherPort != nil && *otherPort == *port {
+			return fmt.Errorf("port %d is already used by another listener", *port)
+		}
+	}
+	return nil
+}
+
 func validateServerPort(port *int64) error {
 	// It cannot be below 1024 because the container is not running as root.
 	if *port < 1024 || *port > 65535 {
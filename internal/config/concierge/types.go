@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package concierge
@@ -18,6 +18,8 @@ type Config struct {
 	// Deprecated: use log.level instead
 	LogLevel *plog.LogLevel `json:"logLevel"`
 	Log      plog.LogSpec   `json:"log"`
+	Debug    DebugSpec      `json:"debug"`
+	Metrics  MetricsSpec    `json:"metrics"`
 }
 
 // DiscoveryInfoSpec contains configuration knobs specific to
@@ -80,3 +82,15 @@ type KubeCertAgentSpec struct {
 	// ImagePullSecrets on the kube-cert-agent pods.
 	ImagePullSecrets []string
 }
+
+// DebugSpec configures the pprof and expvar endpoints, which are only served on the loopback interface of the pod.
+type DebugSpec struct {
+	// Port is the port of the debug endpoints. When it is not set, the debug endpoints are disabled.
+	Port *int64 `json:"port"`
+}
+
+// MetricsSpec configures the Prometheus metrics endpoint, which is served on a dedicated listener.
+type MetricsSpec struct {
+	// Port is the port of the metrics endpoint. When it is not set, the metrics endpoint is disabled.
+	Port *int64 `json:"port"`
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package supervisor contains functionality to load/store Config's from/to
@@ -7,15 +7,21 @@ package supervisor
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
 
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/plog"
 )
@@ -25,11 +31,45 @@ const (
 	NetworkUnix     = "unix"
 	NetworkTCP      = "tcp"
 
+	ValidatedSettingsCachePersistenceNone      = ""
+	ValidatedSettingsCachePersistenceConfigMap = "ConfigMap"
+	ValidatedSettingsCachePersistenceSecret    = "Secret"
+
 	// Use 10250 because it happens to be the same port on which the Kubelet listens, so some cluster types
 	// are more permissive with servers that run on this port. For example, GKE private clusters do not
 	// allow traffic from the control plane to most ports, but do allow traffic to port 10250. This allows
 	// the Concierge to work without additional configuration on these types of clusters.
 	aggregatedAPIServerPortDefault = 10250
+
+	storageGarbageCollectionIntervalSecondsDefault = 30
+	storageGarbageCollectionBatchSizeDefault       = 100
+
+	tracingSamplingRatePerMillionDefault = 1000000
+
+	AuditSinkTypeStdout  = "stdout"
+	AuditSinkTypeFile    = "file"
+	AuditSinkTypeWebhook = "webhook"
+
+	auditFileMaxSizeMegabytesDefault = 100
+	auditFileMaxBackupsDefault       = 3
+
+	acmeRenewBeforeDaysDefault         = 30
+	acmeHTTP01AddressDefault           = ":8081"
+	acmeDNS01PropagationSecondsDefault = 60
+
+	hstsMaxAgeSecondsMax        = 2 * 365 * 24 * 60 * 60
+	hstsPreloadMaxAgeSecondsMin = 365 * 24 * 60 * 60
+)
+
+//nolint:gochecknoglobals
+var referrerPolicies = sets.NewString(
+	"no-referrer",
+	"no-referrer-when-downgrade",
+	"origin",
+	"origin-when-cross-origin",
+	"same-origin",
+	"strict-origin",
+	"strict-origin-when-cross-origin",
 )
 
 // FromPath loads an Config from a provided local file path, inserts any
@@ -66,6 +106,62 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 		return nil, fmt.Errorf("validate names: %w", err)
 	}
 
+	if err := validateValidatedSettingsCache(config.ValidatedSettingsCache, &config.NamesConfig); err != nil {
+		return nil, fmt.Errorf("validate validatedSettingsCache: %w", err)
+	}
+
+	maybeSetStorageGarbageCollectionDefaults(&config.StorageGarbageCollection)
+
+	if err := validateStorageGarbageCollection(config.StorageGarbageCollection); err != nil {
+		return nil, fmt.Errorf("validate storageGarbageCollection: %w", err)
+	}
+
+	maybeSetTracingDefaults(&config.Tracing)
+
+	if err := validateTracing(config.Tracing); err != nil {
+		return nil, fmt.Errorf("validate tracing: %w", err)
+	}
+
+	maybeSetAuditDefaults(&config.Audit)
+
+	if err := validateAudit(config.Audit); err != nil {
+		return nil, fmt.Errorf("validate audit: %w", err)
+	}
+
+	if err := validateEndpointRateLimits(config.EndpointRateLimits); err != nil {
+		return nil, fmt.Errorf("validate endpointRateLimits: %w", err)
+	}
+
+	if err := validateClientIP(config.ClientIP); err != nil {
+		return nil, fmt.Errorf("validate clientIP: %w", err)
+	}
+
+	if err := validateLoginPageBranding(config.LoginPageBranding); err != nil {
+		return nil, fmt.Errorf("validate loginPageBranding: %w", err)
+	}
+
+	if err := validateTranslations(config.Translations); err != nil {
+		return nil, fmt.Errorf("validate translations: %w", err)
+	}
+
+	maybeSetACMEDefaults(&config.ACME)
+
+	if err := validateACME(config.ACME, &config.NamesConfig); err != nil {
+		return nil, fmt.Errorf("validate acme: %w", err)
+	}
+
+	if err := validateTLS(config.TLS); err != nil {
+		return nil, fmt.Errorf("validate tls: %w", err)
+	}
+
+	if err := validateHTTPHardening(config.HTTPHardening); err != nil {
+		return nil, fmt.Errorf("validate httpHardening: %w", err)
+	}
+
+	if err := validateDebug(config.Debug); err != nil {
+		return nil, fmt.Errorf("validate debug: %w", err)
+	}
+
 	plog.MaybeSetDeprecatedLogLevel(config.LogLevel, &config.Log)
 	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, config.Log); err != nil {
 		return nil, fmt.Errorf("validate log level: %w", err)
@@ -90,16 +186,39 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 	if err := validateEndpoint(*config.Endpoints.HTTP); err != nil {
 		return nil, fmt.Errorf("validate http endpoint: %w", err)
 	}
-	if err := validateAdditionalHTTPEndpointRequirements(*config.Endpoints.HTTP, config.AllowExternalHTTP); err != nil {
+	if err := validateAdditionalHTTPEndpointRequirements(*config.Endpoints.HTTP, config.AllowExternalHTTP, config.HTTPHardening.RedirectHTTPToHTTPS); err != nil {
 		return nil, fmt.Errorf("validate http endpoint: %w", err)
 	}
 	if err := validateAtLeastOneEnabledEndpoint(*config.Endpoints.HTTPS, *config.Endpoints.HTTP); err != nil {
 		return nil, fmt.Errorf("validate endpoints: %w", err)
 	}
+	if config.HTTPHardening.RedirectHTTPToHTTPS && config.Endpoints.HTTP.Network == NetworkDisabled {
+		return nil, constable.Error("validate httpHardening: redirectHTTPToHTTPS requires the http endpoint to be enabled")
+	}
 
 	return &config, nil
 }
 
+// TLSFromPath loads only the TLS policy from a provided local file path, and verifies that it is valid. It allows the
+// policy to be reloaded without reloading the rest of the Config.
+func TLSFromPath(path string) (*TLSSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("decode yaml: %w", err)
+	}
+
+	if err := validateTLS(config.TLS); err != nil {
+		return nil, fmt.Errorf("validate tls: %w", err)
+	}
+
+	return &config.TLS, nil
+}
+
 func maybeSetEndpointDefault(endpoint **Endpoint, defaultEndpoint Endpoint) {
 	if *endpoint != nil {
 		return
@@ -134,6 +253,276 @@ func validateNames(names *NamesConfigSpec) error {
 	return nil
 }
 
+func validateValidatedSettingsCache(spec ValidatedSettingsCacheSpec, names *NamesConfigSpec) error {
+	switch p := spec.Persistence; p {
+	case ValidatedSettingsCachePersistenceNone:
+		return nil
+	case ValidatedSettingsCachePersistenceConfigMap, ValidatedSettingsCachePersistenceSecret:
+		if names.ValidatedSettingsCache == "" {
+			return fmt.Errorf("names.validatedSettingsCache must be set with %q persistence", p)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown persistence %q", p)
+	}
+}
+
+func maybeSetStorageGarbageCollectionDefaults(spec *StorageGarbageCollectionSpec) {
+	if spec.IntervalSeconds == nil {
+		spec.IntervalSeconds = pointer.Int64(storageGarbageCollectionIntervalSecondsDefault)
+	}
+	if spec.BatchSize == nil {
+		spec.BatchSize = pointer.Int64(storageGarbageCollectionBatchSizeDefault)
+	}
+}
+
+func validateStorageGarbageCollection(spec StorageGarbageCollectionSpec) error {
+	if *spec.IntervalSeconds < 10 || *spec.IntervalSeconds > 3600 {
+		return constable.Error("intervalSeconds must be within range 10 to 3600")
+	}
+	if *spec.BatchSize < 1 || *spec.BatchSize > 10000 {
+		return constable.Error("batchSize must be within range 1 to 10000")
+	}
+	return nil
+}
+
+func maybeSetTracingDefaults(spec *TracingSpec) {
+	// Leave the spec empty when tracing is disabled.
+	if spec.Endpoint == "" {
+		return
+	}
+	if spec.SamplingRatePerMillion == nil {
+		spec.SamplingRatePerMillion = pointer.Int32(tracingSamplingRatePerMillionDefault)
+	}
+}
+
+func validateTracing(spec TracingSpec) error {
+	if spec.Endpoint == "" {
+		if spec.Insecure || spec.SamplingRatePerMillion != nil {
+			return constable.Error("endpoint must be set when tracing is configured")
+		}
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(spec.Endpoint); err != nil {
+		return fmt.Errorf("endpoint must be a host:port: %w", err)
+	}
+	if *spec.SamplingRatePerMillion < 0 || *spec.SamplingRatePerMillion > 1000000 {
+		return constable.Error("samplingRatePerMillion must be within range 0 to 1000000")
+	}
+	return nil
+}
+
+func maybeSetAuditDefaults(spec *AuditSpec) {
+	for i := range spec.Sinks {
+		file := spec.Sinks[i].File
+		if file == nil {
+			continue
+		}
+		if file.MaxSizeMegabytes == nil {
+			file.MaxSizeMegabytes = pointer.Int64(auditFileMaxSizeMegabytesDefault)
+		}
+		if file.MaxBackups == nil {
+			file.MaxBackups = pointer.Int64(auditFileMaxBackupsDefault)
+		}
+	}
+}
+
+func validateAudit(spec AuditSpec) error {
+	for i, sink := range spec.Sinks {
+		if err := validateAuditSink(sink); err != nil {
+			return fmt.Errorf("sinks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateAuditSink(sink AuditSinkSpec) error {
+	switch sink.Type {
+	case AuditSinkTypeStdout:
+		if sink.File != nil || sink.Webhook != nil {
+			return constable.Error("file and webhook must not be set for a stdout sink")
+		}
+	case AuditSinkTypeFile:
+		if sink.File == nil || sink.Webhook != nil {
+			return constable.Error("only file must be set for a file sink")
+		}
+		if !filepath.IsAbs(sink.File.Path) {
+			return constable.Error("file.path must be an absolute path")
+		}
+		if *sink.File.MaxSizeMegabytes < 1 || *sink.File.MaxSizeMegabytes > 10000 {
+			return constable.Error("file.maxSizeMegabytes must be within range 1 to 10000")
+		}
+		if *sink.File.MaxBackups < 0 || *sink.File.MaxBackups > 100 {
+			return constable.Error("file.maxBackups must be within range 0 to 100")
+		}
+	case AuditSinkTypeWebhook:
+		if sink.Webhook == nil || sink.File != nil {
+			return constable.Error("only webhook must be set for a webhook sink")
+		}
+		webhookURL, err := url.Parse(sink.Webhook.URL)
+		if err != nil {
+			return fmt.Errorf("webhook.url is invalid: %w", err)
+		}
+		if webhookURL.Scheme != "https" || webhookURL.Host == "" {
+			return constable.Error("webhook.url must be an https URL")
+		}
+		if _, err := base64.StdEncoding.DecodeString(sink.Webhook.CertificateAuthorityData); err != nil {
+			return fmt.Errorf("webhook.certificateAuthorityData is not valid base64: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", sink.Type)
+	}
+	return nil
+}
+
+func validateEndpointRateLimits(spec EndpointRateLimitsSpec) error {
+	if err := validateRateLimit(spec.PerClientIP); err != nil {
+		return fmt.Errorf("perClientIP: %w", err)
+	}
+	if err := validateRateLimit(spec.PerUsername); err != nil {
+		return fmt.Errorf("perUsername: %w", err)
+	}
+	return nil
+}
+
+func validateRateLimit(spec *RateLimitSpec) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.AttemptsPerMinute < 1 || spec.AttemptsPerMinute > 100000 {
+		return constable.Error("attemptsPerMinute must be within range 1 to 100000")
+	}
+	if spec.Burst < 0 || spec.Burst > 100000 {
+		return constable.Error("burst must be within range 0 to 100000")
+	}
+	return nil
+}
+
+func validateClientIP(spec ClientIPSpec) error {
+	for _, cidr := range spec.TrustedProxyCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("trustedProxyCIDRs: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateLoginPageBranding(spec LoginPageBrandingSpec) error {
+	return validateConfigMapName(spec.ConfigMapName)
+}
+
+func validateTranslations(spec TranslationsSpec) error {
+	return validateConfigMapName(spec.ConfigMapName)
+}
+
+func maybeSetACMEDefaults(spec *ACMESpec) {
+	// Leave the spec empty when ACME is disabled.
+	if spec.DirectoryURL == "" {
+		return
+	}
+	if spec.RenewBeforeDays == nil {
+		spec.RenewBeforeDays = pointer.Int64(acmeRenewBeforeDaysDefault)
+	}
+	if spec.HTTP01 != nil && spec.HTTP01.Address == "" {
+		spec.HTTP01.Address = acmeHTTP01AddressDefault
+	}
+	if spec.DNS01 != nil && spec.DNS01.PropagationSeconds == nil {
+		spec.DNS01.PropagationSeconds = pointer.Int64(acmeDNS01PropagationSecondsDefault)
+	}
+}
+
+func validateACME(spec ACMESpec, names *NamesConfigSpec) error {
+	if spec.DirectoryURL == "" {
+		return nil
+	}
+	if err := validateHTTPSURL(spec.DirectoryURL); err != nil {
+		return fmt.Errorf("directoryURL %w", err)
+	}
+	if _, err := base64.StdEncoding.DecodeString(spec.CertificateAuthorityData); err != nil {
+		return fmt.Errorf("certificateAuthorityData is not valid base64: %w", err)
+	}
+	if !spec.AcceptTermsOfService {
+		return constable.Error("acceptTermsOfService must be true to register an account with the ACME server")
+	}
+	if names.ACMEAccount == "" {
+		return constable.Error("names.acmeAccount must be set")
+	}
+	if *spec.RenewBeforeDays < 1 || *spec.RenewBeforeDays > 60 {
+		return constable.Error("renewBeforeDays must be within range 1 to 60")
+	}
+	if (spec.HTTP01 == nil) == (spec.DNS01 == nil) {
+		return constable.Error("exactly one of http01 and dns01 must be set")
+	}
+	if spec.HTTP01 != nil {
+		if names.ACMEHTTP01Challenges == "" {
+			return constable.Error("names.acmeHTTP01Challenges must be set with http01")
+		}
+		if _, _, err := net.SplitHostPort(spec.HTTP01.Address); err != nil {
+			return fmt.Errorf("http01.address is invalid: %w", err)
+		}
+	}
+	if spec.DNS01 != nil {
+		if err := validateHTTPSURL(spec.DNS01.Webhook.URL); err != nil {
+			return fmt.Errorf("dns01.webhook.url %w", err)
+		}
+		if _, err := base64.StdEncoding.DecodeString(spec.DNS01.Webhook.CertificateAuthorityData); err != nil {
+			return fmt.Errorf("dns01.webhook.certificateAuthorityData is not valid base64: %w", err)
+		}
+		if *spec.DNS01.PropagationSeconds < 0 || *spec.DNS01.PropagationSeconds > 3600 {
+			return constable.Error("dns01.propagationSeconds must be within range 0 to 3600")
+		}
+	}
+	return nil
+}
+
+func validateTLS(spec TLSSpec) error {
+	_, err := spec.Policy()
+	return err
+}
+
+// Policy returns the ptls.Policy which applies the TLSSpec.
+func (s TLSSpec) Policy() (*ptls.Policy, error) {
+	return ptls.NewPolicy(s.MinVersion, s.CipherSuites, s.CurvePreferences)
+}
+
+func validateHTTPHardening(spec HTTPHardeningSpec) error {
+	if spec.HSTS.MaxAgeSeconds < 0 || spec.HSTS.MaxAgeSeconds > hstsMaxAgeSecondsMax {
+		return fmt.Errorf("hsts.maxAgeSeconds must be within range 0 to %d", hstsMaxAgeSecondsMax)
+	}
+	if (spec.HSTS.IncludeSubDomains || spec.HSTS.Preload) && spec.HSTS.MaxAgeSeconds == 0 {
+		return constable.Error("hsts.maxAgeSeconds must be set with hsts.includeSubDomains or hsts.preload")
+	}
+	// These are the requirements of the preload lists of the browsers.
+	if spec.HSTS.Preload && (!spec.HSTS.IncludeSubDomains || spec.HSTS.MaxAgeSeconds < hstsPreloadMaxAgeSecondsMin) {
+		return fmt.Errorf("hsts.preload requires hsts.includeSubDomains and an hsts.maxAgeSeconds of at least %d", hstsPreloadMaxAgeSecondsMin)
+	}
+	if spec.ReferrerPolicy != "" && !referrerPolicies.Has(spec.ReferrerPolicy) {
+		return fmt.Errorf("referrerPolicy %q is invalid: must be one of %s", spec.ReferrerPolicy, strings.Join(referrerPolicies.List(), ", "))
+	}
+	return nil
+}
+
+func validateHTTPSURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("is invalid: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return constable.Error("must be an https URL")
+	}
+	return nil
+}
+
+func validateConfigMapName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("configMapName must be a valid ConfigMap name: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 func validateEndpoint(endpoint Endpoint) error {
 	switch n := endpoint.Network; n {
 	case NetworkTCP, NetworkUnix:
@@ -151,8 +540,9 @@ func validateEndpoint(endpoint Endpoint) error {
 	}
 }
 
-func validateAdditionalHTTPEndpointRequirements(endpoint Endpoint, allowExternalHTTP stringOrBoolAsBool) error {
-	if endpoint.Network == NetworkTCP && !addrIsOnlyOnLoopback(endpoint.Address) {
+func validateAdditionalHTTPEndpointRequirements(endpoint Endpoint, allowExternalHTTP stringOrBoolAsBool, redirectToHTTPS bool) error {
+	// An HTTP endpoint which only redirects to HTTPS does not serve anything which needs to be protected.
+	if endpoint.Network == NetworkTCP && !addrIsOnlyOnLoopback(endpoint.Address) && !redirectToHTTPS {
 		if allowExternalHTTP {
 			// Log that the validation should have been triggered.
 			plog.Warning("Listening on non-loopback interfaces for the HTTP port is deprecated and will be removed " +
@@ -212,6 +602,16 @@ func addrIsOnlyOnLoopback(addr string) bool {
 	return ip.IsLoopback()
 }
 
+func validateDebug(spec DebugSpec) error {
+	if spec.Port == nil {
+		return nil
+	}
+	if err := validateServerPort(spec.Port); err != nil {
+		return fmt.Errorf("port %w", err)
+	}
+	return nil
+}
+
 func validateServerPort(port *int64) error {
 	// It cannot be below 1024 because the container is not running as root.
 	if *port < 1024 || *port > 65535 {
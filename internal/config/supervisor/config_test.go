@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisor
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -69,6 +70,10 @@ func TestFromPath(t *testing.T) {
 					Level: plog.LevelTrace,
 				},
 				AggregatedAPIServerPort: pointer.Int64(12345),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
 			},
 		},
 		{
@@ -119,6 +124,10 @@ func TestFromPath(t *testing.T) {
 					Format: plog.FormatText,
 				},
 				AggregatedAPIServerPort: pointer.Int64(12345),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
 			},
 		},
 		{
@@ -170,6 +179,10 @@ func TestFromPath(t *testing.T) {
 					Format: plog.FormatText,
 				},
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
 			},
 		},
 		{
@@ -208,6 +221,10 @@ func TestFromPath(t *testing.T) {
 				},
 				AllowExternalHTTP:       false,
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
 			},
 		},
 		{
@@ -339,6 +356,10 @@ func TestFromPath(t *testing.T) {
 				},
 				AllowExternalHTTP:       true,
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
 			},
 		},
 		{
@@ -371,6 +392,10 @@ func TestFromPath(t *testing.T) {
 				},
 				AllowExternalHTTP:       true,
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
 			},
 		},
 		{
@@ -418,60 +443,1128 @@ func TestFromPath(t *testing.T) {
 			wantError: "validate names: missing required names: defaultTLSCertificateSecret",
 		},
 		{
-			name: "apiGroupSuffix is prefixed with '.'",
+			name: "validatedSettingsCache persisted to a ConfigMap",
 			yaml: here.Doc(`
 				---
-				apiGroupSuffix: .starts.with.dot
 				names:
 				  defaultTLSCertificateSecret: my-secret-name
+				  validatedSettingsCache: my-cache-name
+				validatedSettingsCache:
+				  persistence: ConfigMap
 			`),
-			wantError: "validate apiGroupSuffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+					ValidatedSettingsCache:      "my-cache-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				ValidatedSettingsCache: ValidatedSettingsCacheSpec{
+					Persistence: "ConfigMap",
+				},
+			},
 		},
 		{
-			name: "AggregatedAPIServerPortDefault too small",
+			name: "validatedSettingsCache persisted without a name",
 			yaml: here.Doc(`
 				---
-				aggregatedAPIServerPort: 1023
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				validatedSettingsCache:
+				  persistence: Secret
 			`),
-			wantError: "validate aggregatedAPIServerPort: must be within range 1024 to 65535",
+			wantError: `validate validatedSettingsCache: names.validatedSettingsCache must be set with "Secret" persistence`,
 		},
 		{
-			name: "AggregatedAPIServerPortDefault too large",
+			name: "validatedSettingsCache with unknown persistence",
 			yaml: here.Doc(`
 				---
-				aggregatedAPIServerPort: 65536
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  validatedSettingsCache: my-cache-name
+				validatedSettingsCache:
+				  persistence: Database
 			`),
-			wantError: "validate aggregatedAPIServerPort: must be within range 1024 to 65535",
+			wantError: `validate validatedSettingsCache: unknown persistence "Database"`,
 		},
-	}
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			// this is a serial test because it sets the global logger
-
-			// Write yaml to temp file
-			f, err := os.CreateTemp("", "pinniped-test-config-yaml-*")
-			require.NoError(t, err)
-			defer func() {
-				err := os.Remove(f.Name())
-				require.NoError(t, err)
-			}()
-			_, err = f.WriteString(test.yaml)
-			require.NoError(t, err)
-			err = f.Close()
-			require.NoError(t, err)
-
-			// Test FromPath()
-			ctx, cancel := context.WithCancel(context.Background())
-			t.Cleanup(cancel)
-			config, err := FromPath(ctx, f.Name())
-
-			if test.wantError != "" {
-				require.EqualError(t, err, test.wantError)
-			} else {
-				require.NoError(t, err)
-				require.Equal(t, test.wantConfig, config)
-			}
+		{
+			name: "storageGarbageCollection with custom interval and batch size",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				storageGarbageCollection:
+				  intervalSeconds: 300
+				  batchSize: 20
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(300),
+					BatchSize:       pointer.Int64(20),
+				},
+			},
+		},
+		{
+			name: "tracing with defaults",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: otel-collector.observability.svc:4317
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				Tracing: TracingSpec{
+					Endpoint:               "otel-collector.observability.svc:4317",
+					SamplingRatePerMillion: pointer.Int32(1000000),
+				},
+			},
+		},
+		{
+			name: "tracing with insecure connection and custom sampling rate",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: localhost:4317
+				  insecure: true
+				  samplingRatePerMillion: 5000
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				Tracing: TracingSpec{
+					Endpoint:               "localhost:4317",
+					Insecure:               true,
+					SamplingRatePerMillion: pointer.Int32(5000),
+				},
+			},
+		},
+		{
+			name: "tracing without endpoint",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  samplingRatePerMillion: 5000
+			`),
+			wantError: "validate tracing: endpoint must be set when tracing is configured",
+		},
+		{
+			name: "tracing endpoint without port",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: otel-collector
+			`),
+			wantError: "validate tracing: endpoint must be a host:port: address otel-collector: missing port in address",
+		},
+		{
+			name: "tracing sampling rate too large",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: localhost:4317
+				  samplingRatePerMillion: 1000001
+			`),
+			wantError: "validate tracing: samplingRatePerMillion must be within range 0 to 1000000",
+		},
+		{
+			name: "audit sinks",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				audit:
+				  sinks:
+				  - type: stdout
+				  - type: file
+				    file:
+				      path: /var/log/pinniped/audit.log
+				  - type: file
+				    file:
+				      path: /var/log/pinniped/other-audit.log
+				      maxSizeMegabytes: 10
+				      maxBackups: 0
+				  - type: webhook
+				    webhook:
+				      url: https://audit.example.com/records
+				      certificateAuthorityData: c29tZS1jYS1idW5kbGU=
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				Audit: AuditSpec{
+					Sinks: []AuditSinkSpec{
+						{Type: "stdout"},
+						{Type: "file", File: &AuditFileSinkSpec{
+							Path:             "/var/log/pinniped/audit.log",
+							MaxSizeMegabytes: pointer.Int64(100),
+							MaxBackups:       pointer.Int64(3),
+						}},
+						{Type: "file", File: &AuditFileSinkSpec{
+							Path:             "/var/log/pinniped/other-audit.log",
+							MaxSizeMegabytes: pointer.Int64(10),
+							MaxBackups:       pointer.Int64(0),
+						}},
+						{Type: "webhook", Webhook: &AuditWebhookSinkSpec{
+							URL:                      "https://audit.example.com/records",
+							CertificateAuthorityData: "c29tZS1jYS1idW5kbGU=",
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "audit sink with unknown type",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				audit:
+				  sinks:
+				  - type: syslog
+			`),
+			wantError: `validate audit: sinks[0]: unknown type "syslog"`,
+		},
+		{
+			name: "audit file sink with relative path",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				audit:
+				  sinks:
+				  - type: stdout
+				  - type: file
+				    file:
+				      path: audit.log
+			`),
+			wantError: "validate audit: sinks[1]: file.path must be an absolute path",
+		},
+		{
+			name: "audit file sink without file",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				audit:
+				  sinks:
+				  - type: file
+			`),
+			wantError: "validate audit: sinks[0]: only file must be set for a file sink",
+		},
+		{
+			name: "audit webhook sink with http URL",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				audit:
+				  sinks:
+				  - type: webhook
+				    webhook:
+				      url: http://audit.example.com/records
+			`),
+			wantError: "validate audit: sinks[0]: webhook.url must be an https URL",
+		},
+		{
+			name: "endpoint rate limits",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpointRateLimits:
+				  perClientIP:
+				    attemptsPerMinute: 60
+				    burst: 120
+				  perUsername:
+				    attemptsPerMinute: 5
+				clientIP:
+				  trustedProxyCIDRs: [10.0.0.0/8, "fd00::/8"]
+				  header: X-Real-IP
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				EndpointRateLimits: EndpointRateLimitsSpec{
+					PerClientIP: &RateLimitSpec{AttemptsPerMinute: 60, Burst: 120},
+					PerUsername: &RateLimitSpec{AttemptsPerMinute: 5},
+				},
+				ClientIP: ClientIPSpec{
+					TrustedProxyCIDRs: []string{"10.0.0.0/8", "fd00::/8"},
+					Header:            "X-Real-IP",
+				},
+			},
+		},
+		{
+			name: "endpoint rate limit without attempts per minute",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpointRateLimits:
+				  perClientIP:
+				    burst: 10
+			`),
+			wantError: "validate endpointRateLimits: perClientIP: attemptsPerMinute must be within range 1 to 100000",
+		},
+		{
+			name: "endpoint rate limit with negative burst",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpointRateLimits:
+				  perUsername:
+				    attemptsPerMinute: 5
+				    burst: -1
+			`),
+			wantError: "validate endpointRateLimits: perUsername: burst must be within range 0 to 100000",
+		},
+		{
+			name: "invalid trusted proxy CIDR",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				clientIP:
+				  trustedProxyCIDRs: [10.0.0.1]
+			`),
+			wantError: "validate clientIP: trustedProxyCIDRs: invalid CIDR address: 10.0.0.1",
+		},
+		{
+			name: "login page branding",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				loginPageBranding:
+				  configMapName: my-branding
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				LoginPageBranding: LoginPageBrandingSpec{
+					ConfigMapName: "my-branding",
+				},
+			},
+		},
+		{
+			name: "login page branding with an invalid configmap name",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				loginPageBranding:
+				  configMapName: My_Branding
+			`),
+			wantError: "validate loginPageBranding: configMapName must be a valid ConfigMap name: " +
+				"a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', " +
+				"and must start and end with an alphanumeric character " +
+				"(e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
+		{
+			name: "translations",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				translations:
+				  configMapName: my-translations
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				Translations: TranslationsSpec{
+					ConfigMapName: "my-translations",
+				},
+			},
+		},
+		{
+			name: "translations with an invalid configmap name",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				translations:
+				  configMapName: My_Translations
+			`),
+			wantError: "validate translations: configMapName must be a valid ConfigMap name: " +
+				"a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', " +
+				"and must start and end with an alphanumeric character " +
+				"(e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
+		{
+			name: "acme with http01",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  email: admin@example.com
+				  acceptTermsOfService: true
+				  renewBeforeDays: 20
+				  http01:
+				    address: ":8089"
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+					ACMEAccount:                 "my-acme-account",
+					ACMEHTTP01Challenges:        "my-acme-challenges",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				ACME: ACMESpec{
+					DirectoryURL:         "https://acme.example.com/directory",
+					Email:                "admin@example.com",
+					AcceptTermsOfService: true,
+					RenewBeforeDays:      pointer.Int64(20),
+					HTTP01: &ACMEHTTP01Spec{
+						Address: ":8089",
+					},
+				},
+			},
+		},
+		{
+			name: "acme with dns01 and defaults",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  certificateAuthorityData: LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t
+				  acceptTermsOfService: true
+				  dns01:
+				    webhook:
+				      url: https://dns-webhook.example.com/records
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+					ACMEAccount:                 "my-acme-account",
+					ACMEHTTP01Challenges:        "my-acme-challenges",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				ACME: ACMESpec{
+					DirectoryURL:             "https://acme.example.com/directory",
+					CertificateAuthorityData: "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t",
+					AcceptTermsOfService:     true,
+					RenewBeforeDays:          pointer.Int64(30),
+					DNS01: &ACMEDNS01Spec{
+						Webhook: ACMEDNS01WebhookSpec{
+							URL: "https://dns-webhook.example.com/records",
+						},
+						PropagationSeconds: pointer.Int64(60),
+					},
+				},
+			},
+		},
+		{
+			name: "acme with a directoryURL which is not https",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: http://acme.example.com/directory
+				  acceptTermsOfService: true
+				  http01: {}
+			`),
+			wantError: "validate acme: directoryURL must be an https URL",
+		},
+		{
+			name: "acme without accepting the terms of service",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  http01: {}
+			`),
+			wantError: "validate acme: acceptTermsOfService must be true to register an account with the ACME server",
+		},
+		{
+			name: "acme without the account name",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  http01: {}
+			`),
+			wantError: "validate acme: names.acmeAccount must be set",
+		},
+		{
+			name: "acme with renewBeforeDays too large",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  renewBeforeDays: 61
+				  http01: {}
+			`),
+			wantError: "validate acme: renewBeforeDays must be within range 1 to 60",
+		},
+		{
+			name: "acme without challenges",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+			`),
+			wantError: "validate acme: exactly one of http01 and dns01 must be set",
+		},
+		{
+			name: "acme with both challenges",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  http01: {}
+				  dns01:
+				    webhook:
+				      url: https://dns-webhook.example.com/records
+			`),
+			wantError: "validate acme: exactly one of http01 and dns01 must be set",
+		},
+		{
+			name: "acme with http01 without the challenges name",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  http01: {}
+			`),
+			wantError: "validate acme: names.acmeHTTP01Challenges must be set with http01",
+		},
+		{
+			name: "acme with an invalid http01 address",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  http01:
+				    address: "8081"
+			`),
+			wantError: "validate acme: http01.address is invalid: address 8081: missing port in address",
+		},
+		{
+			name: "acme with a dns01 webhook which is not https",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  dns01:
+				    webhook:
+				      url: http://dns-webhook.example.com/records
+			`),
+			wantError: "validate acme: dns01.webhook.url must be an https URL",
+		},
+		{
+			name: "acme with dns01 propagationSeconds too large",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				  acmeAccount: my-acme-account
+				  acmeHTTP01Challenges: my-acme-challenges
+				acme:
+				  directoryURL: https://acme.example.com/directory
+				  acceptTermsOfService: true
+				  dns01:
+				    webhook:
+				      url: https://dns-webhook.example.com/records
+				    propagationSeconds: 3601
+			`),
+			wantError: "validate acme: dns01.propagationSeconds must be within range 0 to 3600",
+		},
+		{
+			name: "tls policy",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  minVersion: "1.2"
+				  cipherSuites:
+				  - TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+				  - TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+				  curvePreferences: [X25519, P256]
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				TLS: TLSSpec{
+					MinVersion:       "1.2",
+					CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+					CurvePreferences: []string{"X25519", "P256"},
+				},
+			},
+		},
+		{
+			name: "tls policy with an invalid minVersion",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  minVersion: "1.0"
+			`),
+			wantError: `validate tls: minVersion "1.0" is invalid: must be 1.2 or 1.3`,
+		},
+		{
+			name: "tls policy with cipher suites and tls 1.3",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  minVersion: "1.3"
+				  cipherSuites: [TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384]
+			`),
+			wantError: "validate tls: cipherSuites cannot be configured when minVersion is 1.3",
+		},
+		{
+			name: "tls policy with an insecure cipher suite",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  cipherSuites: [TLS_RSA_WITH_RC4_128_SHA]
+			`),
+			wantError: `validate tls: cipher suite "TLS_RSA_WITH_RC4_128_SHA" is unknown or insecure`,
+		},
+		{
+			name: "tls policy with an invalid curve",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  curvePreferences: [P224]
+			`),
+			wantError: `validate tls: curve "P224" is invalid: must be one of X25519, P256, P384, or P521`,
+		},
+		{
+			name: "http hardening with a redirecting http endpoint on all interfaces",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  http:
+				    network: tcp
+				    address: :8080
+				httpHardening:
+				  hsts:
+				    maxAgeSeconds: 31536000
+				    includeSubDomains: true
+				    preload: true
+				  referrerPolicy: strict-origin
+				  redirectHTTPToHTTPS: true
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "tcp",
+						Address: ":8080",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				HTTPHardening: HTTPHardeningSpec{
+					HSTS: HSTSSpec{
+						MaxAgeSeconds:     31536000,
+						IncludeSubDomains: true,
+						Preload:           true,
+					},
+					ReferrerPolicy:      "strict-origin",
+					RedirectHTTPToHTTPS: true,
+				},
+			},
+		},
+		{
+			name: "http hardening with hsts maxAgeSeconds too large",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				httpHardening:
+				  hsts:
+				    maxAgeSeconds: 100000000
+			`),
+			wantError: "validate httpHardening: hsts.maxAgeSeconds must be within range 0 to 63072000",
+		},
+		{
+			name: "http hardening with hsts includeSubDomains without maxAgeSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				httpHardening:
+				  hsts:
+				    includeSubDomains: true
+			`),
+			wantError: "validate httpHardening: hsts.maxAgeSeconds must be set with hsts.includeSubDomains or hsts.preload",
+		},
+		{
+			name: "http hardening with hsts preload and a short maxAgeSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				httpHardening:
+				  hsts:
+				    maxAgeSeconds: 3600
+				    includeSubDomains: true
+				    preload: true
+			`),
+			wantError: "validate httpHardening: hsts.preload requires hsts.includeSubDomains and an hsts.maxAgeSeconds of at least 31536000",
+		},
+		{
+			name: "http hardening with an invalid referrerPolicy",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				httpHardening:
+				  referrerPolicy: unsafe-url
+			`),
+			wantError: `validate httpHardening: referrerPolicy "unsafe-url" is invalid: must be one of no-referrer, ` +
+				`no-referrer-when-downgrade, origin, origin-when-cross-origin, same-origin, strict-origin, strict-origin-when-cross-origin`,
+		},
+		{
+			name: "http hardening redirect without an http endpoint",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				httpHardening:
+				  redirectHTTPToHTTPS: true
+			`),
+			wantError: "validate httpHardening: redirectHTTPToHTTPS requires the http endpoint to be enabled",
+		},
+		{
+			name: "debug endpoints",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				debug:
+				  port: 6060
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				StorageGarbageCollection: StorageGarbageCollectionSpec{
+					IntervalSeconds: pointer.Int64(30),
+					BatchSize:       pointer.Int64(100),
+				},
+				Debug: DebugSpec{
+					Port: pointer.Int64(6060),
+				},
+			},
+		},
+		{
+			name: "debug port too large",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				debug:
+				  port: 65536
+			`),
+			wantError: "validate debug: port must be within range 1024 to 65535",
+		},
+		{
+			name: "storageGarbageCollection interval too small",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				storageGarbageCollection:
+				  intervalSeconds: 9
+			`),
+			wantError: "validate storageGarbageCollection: intervalSeconds must be within range 10 to 3600",
+		},
+		{
+			name: "storageGarbageCollection batch size too small",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				storageGarbageCollection:
+				  batchSize: 0
+			`),
+			wantError: "validate storageGarbageCollection: batchSize must be within range 1 to 10000",
+		},
+		{
+			name: "apiGroupSuffix is prefixed with '.'",
+			yaml: here.Doc(`
+				---
+				apiGroupSuffix: .starts.with.dot
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+			`),
+			wantError: "validate apiGroupSuffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
+		{
+			name: "AggregatedAPIServerPortDefault too small",
+			yaml: here.Doc(`
+				---
+				aggregatedAPIServerPort: 1023
+			`),
+			wantError: "validate aggregatedAPIServerPort: must be within range 1024 to 65535",
+		},
+		{
+			name: "AggregatedAPIServerPortDefault too large",
+			yaml: here.Doc(`
+				---
+				aggregatedAPIServerPort: 65536
+			`),
+			wantError: "validate aggregatedAPIServerPort: must be within range 1024 to 65535",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			// this is a serial test because it sets the global logger
+
+			// Write yaml to temp file
+			f, err := os.CreateTemp("", "pinniped-test-config-yaml-*")
+			require.NoError(t, err)
+			defer func() {
+				err := os.Remove(f.Name())
+				require.NoError(t, err)
+			}()
+			_, err = f.WriteString(test.yaml)
+			require.NoError(t, err)
+			err = f.Close()
+			require.NoError(t, err)
+
+			// Test FromPath()
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			config, err := FromPath(ctx, f.Name())
+
+			if test.wantError != "" {
+				require.EqualError(t, err, test.wantError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.wantConfig, config)
+			}
+		})
+	}
+}
+
+func TestTLSFromPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		yaml      string
+		want      *TLSSpec
+		wantError string
+	}{
+		{
+			name: "only the tls policy is loaded",
+			yaml: here.Doc(`
+				---
+				names: {}
+				tls:
+				  minVersion: "1.3"
+				  curvePreferences: [P384]
+			`),
+			want: &TLSSpec{MinVersion: "1.3", CurvePreferences: []string{"P384"}},
+		},
+		{
+			name: "no tls policy",
+			yaml: here.Doc(`
+				---
+				apiGroupSuffix: pinniped.dev
+			`),
+			want: &TLSSpec{},
+		},
+		{
+			name: "invalid tls policy",
+			yaml: here.Doc(`
+				---
+				tls:
+				  curvePreferences: [P224]
+			`),
+			wantError: `validate tls: curve "P224" is invalid: must be one of X25519, P256, P384, or P521`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.yaml), 0600))
+
+			got, err := TLSFromPath(path)
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
 		})
 	}
 }
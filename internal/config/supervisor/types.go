@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisor
@@ -15,17 +15,216 @@ type Config struct {
 	Labels         map[string]string `json:"labels"`
 	NamesConfig    NamesConfigSpec   `json:"names"`
 	// Deprecated: use log.level instead
-	LogLevel                *plog.LogLevel     `json:"logLevel"`
-	Log                     plog.LogSpec       `json:"log"`
-	Endpoints               *Endpoints         `json:"endpoints"`
-	AllowExternalHTTP       stringOrBoolAsBool `json:"insecureAcceptExternalUnencryptedHttpRequests"`
-	AggregatedAPIServerPort *int64             `json:"aggregatedAPIServerPort"`
+	LogLevel                 *plog.LogLevel               `json:"logLevel"`
+	Log                      plog.LogSpec                 `json:"log"`
+	Endpoints                *Endpoints                   `json:"endpoints"`
+	AllowExternalHTTP        stringOrBoolAsBool           `json:"insecureAcceptExternalUnencryptedHttpRequests"`
+	AggregatedAPIServerPort  *int64                       `json:"aggregatedAPIServerPort"`
+	ValidatedSettingsCache   ValidatedSettingsCacheSpec   `json:"validatedSettingsCache"`
+	StorageGarbageCollection StorageGarbageCollectionSpec `json:"storageGarbageCollection"`
+	Tracing                  TracingSpec                  `json:"tracing"`
+	Audit                    AuditSpec                    `json:"audit"`
+	EndpointRateLimits       EndpointRateLimitsSpec       `json:"endpointRateLimits"`
+	ClientIP                 ClientIPSpec                 `json:"clientIP"`
+	LoginPageBranding        LoginPageBrandingSpec        `json:"loginPageBranding"`
+	Translations             TranslationsSpec             `json:"translations"`
+	ACME                     ACMESpec                     `json:"acme"`
+	TLS                      TLSSpec                      `json:"tls"`
+	HTTPHardening            HTTPHardeningSpec            `json:"httpHardening"`
+	Debug                    DebugSpec                    `json:"debug"`
 }
 
 // NamesConfigSpec configures the names of some Kubernetes resources for the Supervisor.
 type NamesConfigSpec struct {
 	DefaultTLSCertificateSecret string `json:"defaultTLSCertificateSecret"`
 	APIService                  string `json:"apiService"`
+	ValidatedSettingsCache      string `json:"validatedSettingsCache"`
+	ACMEAccount                 string `json:"acmeAccount"`
+	ACMEHTTP01Challenges        string `json:"acmeHTTP01Challenges"`
+}
+
+// ValidatedSettingsCacheSpec configures the cache of the settings of LDAP and Active Directory identity
+// providers which were already validated by connecting to the upstream servers.
+type ValidatedSettingsCacheSpec struct {
+	// Persistence is the kind of object in which to persist the cache, so that the Supervisor does not need to
+	// connect to each upstream server again to validate unchanged settings after it restarts. Either "ConfigMap"
+	// or "Secret". When empty, the cache is only kept in memory.
+	Persistence string `json:"persistence"`
+}
+
+// StorageGarbageCollectionSpec configures the garbage collector which deletes the Supervisor's expired storage
+// Secrets, e.g. the Secrets which hold downstream sessions.
+type StorageGarbageCollectionSpec struct {
+	// IntervalSeconds is the minimum time between two garbage collection sweeps, in seconds.
+	IntervalSeconds *int64 `json:"intervalSeconds"`
+
+	// BatchSize is the maximum number of Secrets which are deleted at once. When more Secrets have expired, the
+	// remaining Secrets are deleted in further batches, shortly after each other.
+	BatchSize *int64 `json:"batchSize"`
+}
+
+// TracingSpec configures the export of OpenTelemetry traces of the Supervisor's login and token flows.
+type TracingSpec struct {
+	// Endpoint is the host:port of an OTLP gRPC collector to which spans are exported. When empty, tracing is disabled.
+	Endpoint string `json:"endpoint"`
+
+	// Insecure disables TLS for the connection to the collector, e.g. for a collector sidecar.
+	Insecure bool `json:"insecure"`
+
+	// SamplingRatePerMillion is the number of requests per million which are traced, unless the caller already
+	// decided whether the request is traced. Defaults to tracing every request when the endpoint is set.
+	SamplingRatePerMillion *int32 `json:"samplingRatePerMillion"`
+}
+
+// AuditSpec configures where the Supervisor writes its security audit log, which records logins, token refreshes,
+// token exchanges, revocations, and the garbage collection of sessions.
+type AuditSpec struct {
+	// Sinks are the destinations of the audit records. When empty, no audit records are written.
+	Sinks []AuditSinkSpec `json:"sinks"`
+}
+
+// AuditSinkSpec configures one destination of the security audit log. Exactly one of the fields which match the
+// type may be set.
+type AuditSinkSpec struct {
+	// Type is the kind of sink, either "stdout", "file", or "webhook".
+	Type string `json:"type"`
+
+	// File configures a sink of type "file".
+	File *AuditFileSinkSpec `json:"file,omitempty"`
+
+	// Webhook configures a sink of type "webhook".
+	Webhook *AuditWebhookSinkSpec `json:"webhook,omitempty"`
+}
+
+// AuditFileSinkSpec configures an audit log file, which is rotated when it grows too large.
+type AuditFileSinkSpec struct {
+	// Path is the absolute path of the file, e.g. on a volume which is mounted into the Supervisor's pods.
+	Path string `json:"path"`
+
+	// MaxSizeMegabytes is the size at which the file is rotated.
+	MaxSizeMegabytes *int64 `json:"maxSizeMegabytes"`
+
+	// MaxBackups is the number of rotated files which are kept, named after the file with a numeric suffix.
+	MaxBackups *int64 `json:"maxBackups"`
+}
+
+// AuditWebhookSinkSpec configures an HTTPS endpoint to which each audit record is posted as JSON.
+type AuditWebhookSinkSpec struct {
+	// URL is the https URL of the webhook.
+	URL string `json:"url"`
+
+	// CertificateAuthorityData is the base64-encoded PEM bundle used to verify the webhook's serving certificate.
+	// When empty, the system's trusted CAs are used.
+	CertificateAuthorityData string `json:"certificateAuthorityData"`
+}
+
+// EndpointRateLimitsSpec configures the limits on the rate of requests to the endpoints which accept passwords,
+// i.e. the authorization endpoint when it is given a username and password, and the login form, and to the token
+// endpoint. Requests beyond the limits are rejected with a 429 Too Many Requests response.
+type EndpointRateLimitsSpec struct {
+	// PerClientIP limits the rate of requests to each of those endpoints from each client IP address.
+	// When not set, the rate of requests per client IP address is not limited.
+	PerClientIP *RateLimitSpec `json:"perClientIP"`
+
+	// PerUsername limits the rate of login attempts for each username. When not set, the rate of login attempts
+	// per username is not limited.
+	PerUsername *RateLimitSpec `json:"perUsername"`
+}
+
+// ClientIPSpec configures how the Supervisor learns the IP addresses of its clients, which are used by the rate limits
+// per client IP address and recorded in the audit log. By default, the IP address of a client is the address of the
+// peer of its connection. When the Supervisor is behind an ingress or a load balancer which does not preserve the
+// addresses of the clients, all of the clients would then share the address, and the rate limits, of the proxy.
+type ClientIPSpec struct {
+	// TrustedProxyCIDRs are the networks of the ingresses and load balancers in front of the Supervisor. The IP address
+	// of a client is taken from the Header of the requests which come from those networks. When empty, the Header
+	// is ignored.
+	TrustedProxyCIDRs []string `json:"trustedProxyCIDRs"`
+
+	// Header is the request header in which the trusted proxies pass on the IP addresses of the clients, e.g.
+	// "X-Real-IP". It may list several addresses, separated by commas, when the requests pass through several
+	// proxies. Defaults to "X-Forwarded-For".
+	Header string `json:"header"`
+}
+
+// RateLimitSpec is a limit on the rate of requests.
+type RateLimitSpec struct {
+	// AttemptsPerMinute is the sustained number of requests which are allowed per minute.
+	AttemptsPerMinute int64 `json:"attemptsPerMinute"`
+
+	// Burst is the number of requests which are allowed in quick succession. Defaults to AttemptsPerMinute.
+	Burst int64 `json:"burst"`
+}
+
+// LoginPageBrandingSpec configures the branding of the Supervisor's login form and identity provider chooser pages.
+type LoginPageBrandingSpec struct {
+	// ConfigMapName is the name of a ConfigMap in the Supervisor's namespace which holds the branding bundle,
+	// i.e. a logo, colors, footer text, and custom CSS. Changes to the ConfigMap are applied without a restart.
+	// When empty, the pages are not branded.
+	ConfigMapName string `json:"configMapName"`
+}
+
+// TranslationsSpec configures additional translations of the Supervisor's HTML pages.
+type TranslationsSpec struct {
+	// ConfigMapName is the name of a ConfigMap in the Supervisor's namespace which holds additional catalogs of
+	// translations, one per language. Changes to the ConfigMap are applied without a restart.
+	// When empty, only the built-in translations are used.
+	ConfigMapName string `json:"configMapName"`
+}
+
+// ACMESpec configures the provisioning of TLS certificates for the issuer hostnames of the FederationDomains from an
+// ACME certificate authority, e.g. Let's Encrypt. The certificates are stored in the Secrets named by the
+// FederationDomains' spec.tls.secretName, and renewed before they expire.
+type ACMESpec struct {
+	// DirectoryURL is the https URL of the ACME server's directory, e.g.
+	// https://acme-v02.api.letsencrypt.org/directory. When empty, certificates are not provisioned.
+	DirectoryURL string `json:"directoryURL"`
+
+	// CertificateAuthorityData is the base64-encoded PEM bundle used to verify the ACME server's serving certificate.
+	// When empty, the system's trusted CAs are used.
+	CertificateAuthorityData string `json:"certificateAuthorityData"`
+
+	// Email is the contact address of the ACME account, to which the ACME server may send notices, e.g. about
+	// certificates which are about to expire. Optional.
+	Email string `json:"email"`
+
+	// AcceptTermsOfService must be true, to agree to the ACME server's terms of service when the account is registered.
+	AcceptTermsOfService bool `json:"acceptTermsOfService"`
+
+	// RenewBeforeDays is how many days before they expire the certificates are renewed.
+	RenewBeforeDays *int64 `json:"renewBeforeDays"`
+
+	// HTTP01 configures the HTTP-01 challenges. Exactly one of HTTP01 and DNS01 must be set.
+	HTTP01 *ACMEHTTP01Spec `json:"http01,omitempty"`
+
+	// DNS01 configures the DNS-01 challenges. Exactly one of HTTP01 and DNS01 must be set.
+	DNS01 *ACMEDNS01Spec `json:"dns01,omitempty"`
+}
+
+// ACMEHTTP01Spec configures the listener which serves the HTTP-01 challenges. The ACME server requests them on port
+// 80 of each issuer hostname, which must be forwarded to this listener. It serves nothing but the challenges.
+type ACMEHTTP01Spec struct {
+	// Address is the TCP address of the listener, e.g. ":8081".
+	Address string `json:"address"`
+}
+
+// ACMEDNS01Spec configures the webhook which publishes the TXT records of the DNS-01 challenges.
+type ACMEDNS01Spec struct {
+	// Webhook is posted a JSON request for each TXT record to publish or remove.
+	Webhook ACMEDNS01WebhookSpec `json:"webhook"`
+
+	// PropagationSeconds is how long to wait after a TXT record was published before the ACME server looks it up.
+	PropagationSeconds *int64 `json:"propagationSeconds"`
+}
+
+// ACMEDNS01WebhookSpec configures an HTTPS endpoint which publishes and removes DNS TXT records.
+type ACMEDNS01WebhookSpec struct {
+	// URL is the https URL of the webhook.
+	URL string `json:"url"`
+
+	// CertificateAuthorityData is the base64-encoded PEM bundle used to verify the webhook's serving certificate.
+	// When empty, the system's trusted CAs are used.
+	CertificateAuthorityData string `json:"certificateAuthorityData"`
 }
 
 type Endpoints struct {
@@ -51,3 +250,52 @@ func (sb *stringOrBoolAsBool) UnmarshalJSON(b []byte) error {
 	}
 	return nil
 }
+
+// TLSSpec configures the TLS policy of the Supervisor's listeners and of its connections to the upstream identity
+// providers. It does not apply to the connections to the Kubernetes API server, which always use TLS 1.3. Changes to
+// the policy apply to new connections without a restart.
+type TLSSpec struct {
+	// MinVersion is the minimum TLS version, either "1.2" or "1.3". Defaults to "1.2".
+	MinVersion string `json:"minVersion"`
+
+	// CipherSuites are the names of the allowed TLS 1.2 cipher suites, e.g. "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384".
+	// TLS 1.3 cipher suites cannot be configured. When empty, a default set of secure cipher suites is used.
+	CipherSuites []string `json:"cipherSuites"`
+
+	// CurvePreferences are the names of the allowed elliptic curves in order of preference, any of "X25519", "P256",
+	// "P384", and "P521". When empty, the Go defaults are used.
+	CurvePreferences []string `json:"curvePreferences"`
+}
+
+// HTTPHardeningSpec configures the security headers of all of the Supervisor's responses, and whether its plaintext
+// HTTP endpoint redirects to HTTPS.
+type HTTPHardeningSpec struct {
+	// HSTS configures the Strict-Transport-Security header of the responses to HTTPS requests.
+	HSTS HSTSSpec `json:"hsts"`
+
+	// ReferrerPolicy is the Referrer-Policy header of every response. Defaults to "no-referrer".
+	ReferrerPolicy string `json:"referrerPolicy"`
+
+	// RedirectHTTPToHTTPS makes the HTTP endpoint redirect every request to the same URL over HTTPS, instead of serving
+	// it. Because it does not serve anything else then, the HTTP endpoint may listen on any interface.
+	RedirectHTTPToHTTPS bool `json:"redirectHTTPToHTTPS"`
+}
+
+// HSTSSpec configures the Strict-Transport-Security header.
+type HSTSSpec struct {
+	// MaxAgeSeconds is how long browsers should only use HTTPS to connect to the issuer hostnames. When zero, the
+	// header is not sent.
+	MaxAgeSeconds int64 `json:"maxAgeSeconds"`
+
+	// IncludeSubDomains extends the policy to all subdomains of the issuer hostnames.
+	IncludeSubDomains bool `json:"includeSubDomains"`
+
+	// Preload allows the issuer hostnames to be included in the HSTS preload lists of browsers.
+	Preload bool `json:"preload"`
+}
+
+// DebugSpec configures the pprof and expvar endpoints, which are only served on the loopback interface of the pod.
+type DebugSpec struct {
+	// Port is the port of the debug endpoints. When it is not set, the debug endpoints are disabled.
+	Port *int64 `json:"port"`
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consentstorage stores the decisions of users to allow OIDCClients to receive the scopes which they
+// requested, and the logins which are waiting for such a decision on the consent page.
+package consentstorage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/ory/fosite"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/psession"
+)
+
+const (
+	TypeLabelValue        = "consent"
+	PendingTypeLabelValue = "pending-consent"
+
+	ErrInvalidConsentVersion        = constable.Error("consent data has wrong version")
+	ErrInvalidConsentData           = constable.Error("consent data must be present")
+	ErrInvalidPendingConsentVersion = constable.Error("pending consent data has wrong version")
+	ErrInvalidPendingConsentData    = constable.Error("pending consent data must be present")
+
+	// Version 1 was the initial release of storage.
+	consentStorageVersion = "1"
+)
+
+// Consent is the stored decision of one user to allow one client to receive some scopes.
+type Consent struct {
+	// Subject is the downstream subject of the user, which is stable across logins with the same upstream identity.
+	Subject string `json:"subject"`
+
+	// ClientID is the ID of the client which the user allowed.
+	ClientID string `json:"clientID"`
+
+	// Scopes are all of the scopes which the user allowed the client to receive.
+	Scopes []string `json:"scopes"`
+
+	Version string `json:"version"`
+}
+
+// Covers returns true when the user has allowed the client to receive all of the given scopes.
+func (c *Consent) Covers(scopes []string) bool {
+	if c == nil {
+		return len(scopes) == 0
+	}
+	return fosite.Arguments(c.Scopes).Has(scopes...)
+}
+
+// PendingConsent is a login which has finished at the upstream IDP, and which waits for the user to decide on the
+// consent page whether the client may receive the scopes which it requested.
+type PendingConsent struct {
+	// ClientID is the ID of the client which made the authorization request.
+	ClientID string `json:"clientID"`
+
+	// Scopes are the scopes which the user is asked to allow.
+	Scopes []string `json:"scopes"`
+
+	// AuthParams are the URL-encoded params of the original authorization request, so that the authorization
+	// request can be recreated when the user allows the client.
+	AuthParams string `json:"authParams"`
+
+	// CSRFToken is the value of the CSRF cookie of the browser which logged in, so that the consent page can only be
+	// used from that browser.
+	CSRFToken string `json:"csrfToken"`
+
+	// Session is the downstream session of the user, which is used to issue the authorization code when the user
+	// allows the client.
+	Session *psession.PinnipedSession `json:"session"`
+
+	// Expiry is when the user can no longer decide, after which they must log in again.
+	Expiry time.Time `json:"expiry"`
+
+	Version string `json:"version"`
+}
+
+// Storage stores the consent decisions, keyed by their users and clients, and the pending consents, keyed by
+// their IDs.
+type Storage interface {
+	// GetConsent returns the decision of the user about the client, or nil when the user never decided.
+	GetConsent(ctx context.Context, subject string, clientID string) (*Consent, error)
+	// SetConsent replaces the decision of the user about the client, which restarts its lifetime.
+	SetConsent(ctx context.Context, consent *Consent) error
+
+	CreatePendingConsent(ctx context.Context, id string, pending *PendingConsent) error
+	GetPendingConsent(ctx context.Context, id string) (*PendingConsent, error)
+	DeletePendingConsent(ctx context.Context, id string) error
+}
+
+type consentStorage struct {
+	consents crud.Storage
+	pending  crud.Storage
+}
+
+var _ Storage = &consentStorage{}
+
+// New returns a Storage which remembers the consent decisions for the consentLifetime, and which garbage collects
+// the pending consents after the pendingLifetime.
+func New(secrets corev1client.SecretInterface, clock func() time.Time, consentLifetime, pendingLifetime time.Duration) Storage {
+	return &consentStorage{
+		consents: crud.New(TypeLabelValue, secrets, clock, consentLifetime),
+		pending:  crud.New(PendingTypeLabelValue, secrets, clock, pendingLifetime),
+	}
+}
+
+func consentSignature(subject string, clientID string) string {
+	// The subject is a URL which cannot contain a newline, so the input cannot be ambiguous.
+	sum := sha256.Sum256([]byte(subject + "\n" + clientID))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func pendingSignature(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (s *consentStorage) GetConsent(ctx context.Context, subject string, clientID string) (*Consent, error) {
+	signature := consentSignature(subject, clientID)
+	consent := &Consent{}
+	_, err := s.consents.Get(ctx, signature, consent)
+
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consent for %s: %w", signature, err)
+	}
+
+	if err := validateConsent(consent); err != nil {
+		return nil, fmt.Errorf("consent for %s: %w", signature, err)
+	}
+
+	return consent, nil
+}
+
+func (s *consentStorage) SetConsent(ctx context.Context, consent *Consent) error {
+	consent.Version = consentStorageVersion
+	signature := consentSignature(consent.Subject, consent.ClientID)
+	// Updates keep the lifetime of the original Secret, so replace the Secret instead.
+	if err := s.consents.Delete(ctx, signature); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	_, err := s.consents.Create(ctx, signature, consent, nil, nil)
+	return err
+}
+
+func (s *consentStorage) CreatePendingConsent(ctx context.Context, id string, pending *PendingConsent) error {
+	pending.Version = consentStorageVersion
+	_, err := s.pending.Create(ctx, pendingSignature(id), pending, nil, nil)
+	return err
+}
+
+func (s *consentStorage) GetPendingConsent(ctx context.Context, id string) (*PendingConsent, error) {
+	signature := pendingSignature(id)
+	pending := &PendingConsent{}
+	_, err := s.pending.Get(ctx, signature, pending)
+
+	if errors.IsNotFound(err) {
+		return nil, fosite.ErrNotFound.WithWrap(err).WithDebug(err.Error())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending consent for %s: %w", signature, err)
+	}
+
+	if err := validatePendingConsent(pending); err != nil {
+		return nil, fmt.Errorf("pending consent for %s: %w", signature, err)
+	}
+
+	return pending, nil
+}
+
+func (s *consentStorage) DeletePendingConsent(ctx context.Context, id string) error {
+	return s.pending.Delete(ctx, pendingSignature(id))
+}
+
+// ReadPendingConsentFromSecret reads the pending consent from a Secret, e.g. one which was found by the garbage
+// collector.
+func ReadPendingConsentFromSecret(secret *corev1.Secret) (*PendingConsent, error) {
+	pending := &PendingConsent{}
+	if err := crud.FromSecret(PendingTypeLabelValue, secret, pending); err != nil {
+		return nil, err
+	}
+	if err := validatePendingConsent(pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func validateConsent(consent *Consent) error {
+	if consent.Version != consentStorageVersion {
+		return fmt.Errorf("%w: got version %s instead of %s", ErrInvalidConsentVersion, consent.Version, consentStorageVersion)
+	}
+	if consent.Subject == "" || consent.ClientID == "" {
+		return ErrInvalidConsentData
+	}
+	return nil
+}
+
+func validatePendingConsent(pending *PendingConsent) error {
+	if pending.Version != consentStorageVersion {
+		return fmt.Errorf("%w: got version %s instead of %s", ErrInvalidPendingConsentVersion, pending.Version, consentStorageVersion)
+	}
+	if pending.ClientID == "" || pending.Session == nil || pending.Session.Fosite == nil || pending.Session.Custom == nil {
+		return ErrInvalidPendingConsentData
+	}
+	return nil
+}
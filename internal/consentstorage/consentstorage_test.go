@@ -0,0 +1,172 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package consentstorage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/psession"
+)
+
+const (
+	namespace = "test-ns"
+
+	subject  = "https://issuer.example.com?idpName=some-idp&sub=some-user"
+	clientID = "client.oauth.pinniped.dev-some-client"
+)
+
+var fakeNow = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	consentLifetime = 30 * 24 * time.Hour
+	pendingLifetime = 11 * time.Minute
+)
+
+func TestConsent(t *testing.T) {
+	ctx, _, secrets, storage := makeTestSubject()
+
+	got, err := storage.GetConsent(ctx, subject, clientID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	err = storage.SetConsent(ctx, &Consent{Subject: subject, ClientID: clientID, Scopes: []string{"openid"}})
+	require.NoError(t, err)
+
+	got, err = storage.GetConsent(ctx, subject, clientID)
+	require.NoError(t, err)
+	require.Equal(t, &Consent{Subject: subject, ClientID: clientID, Scopes: []string{"openid"}, Version: "1"}, got)
+
+	// Setting the consent again replaces it.
+	err = storage.SetConsent(ctx, &Consent{Subject: subject, ClientID: clientID, Scopes: []string{"openid", "groups"}})
+	require.NoError(t, err)
+
+	got, err = storage.GetConsent(ctx, subject, clientID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"openid", "groups"}, got.Scopes)
+
+	// The consents of other users and other clients are separate.
+	got, err = storage.GetConsent(ctx, subject, "client.oauth.pinniped.dev-other-client")
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	list, err := secrets.List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, map[string]string{"storage.pinniped.dev/type": "consent"}, list.Items[0].Labels)
+	require.Equal(t, map[string]string{"storage.pinniped.dev/garbage-collect-after": "2030-01-31T00:00:00Z"}, list.Items[0].Annotations)
+	require.Equal(t, corev1.SecretType("storage.pinniped.dev/consent"), list.Items[0].Type)
+}
+
+func TestConsentWrongVersion(t *testing.T) {
+	ctx, _, secrets, storage := makeTestSubject()
+
+	_, err := secrets.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pinniped-storage-consent-qlu2gvyzm67thcrcfal4r23uuszozmya5ofk6varynnkg726mh3q",
+			Labels: map[string]string{"storage.pinniped.dev/type": "consent"},
+		},
+		Data: map[string][]byte{
+			"pinniped-storage-data":    []byte(`{"subject":"some-subject","clientID":"some-client","version":"not-the-right-version"}`),
+			"pinniped-storage-version": []byte("1"),
+		},
+		Type: "storage.pinniped.dev/consent",
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = storage.GetConsent(ctx, subject, clientID)
+	require.True(t, errors.Is(err, ErrInvalidConsentVersion), "wrong error: %v", err)
+}
+
+func TestConsentCovers(t *testing.T) {
+	consent := &Consent{Scopes: []string{"openid", "username"}}
+	require.True(t, consent.Covers([]string{"openid"}))
+	require.True(t, consent.Covers([]string{"username", "openid"}))
+	require.True(t, consent.Covers(nil))
+	require.False(t, consent.Covers([]string{"openid", "groups"}))
+
+	var noConsent *Consent
+	require.True(t, noConsent.Covers(nil))
+	require.False(t, noConsent.Covers([]string{"openid"}))
+}
+
+func TestPendingConsent(t *testing.T) {
+	ctx, _, secrets, storage := makeTestSubject()
+
+	_, err := storage.GetPendingConsent(ctx, "some-id")
+	require.EqualError(t, err, "not_found")
+	require.True(t, errors.Is(err, fosite.ErrNotFound))
+
+	pending := &PendingConsent{
+		ClientID:   clientID,
+		Scopes:     []string{"openid", "groups"},
+		AuthParams: "client_id=some-client&scope=openid+groups",
+		CSRFToken:  "some-csrf-token",
+		Session: &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{Claims: &jwt.IDTokenClaims{Subject: subject}},
+			Custom: &psession.CustomSessionData{Username: "some-user", ProviderType: psession.ProviderTypeLDAP},
+		},
+		Expiry: fakeNow.Add(10 * time.Minute),
+	}
+	err = storage.CreatePendingConsent(ctx, "some-id", pending)
+	require.NoError(t, err)
+
+	got, err := storage.GetPendingConsent(ctx, "some-id")
+	require.NoError(t, err)
+	require.Equal(t, clientID, got.ClientID)
+	require.Equal(t, []string{"openid", "groups"}, got.Scopes)
+	require.Equal(t, "client_id=some-client&scope=openid+groups", got.AuthParams)
+	require.Equal(t, "some-csrf-token", got.CSRFToken)
+	require.Equal(t, subject, got.Session.Fosite.Claims.Subject)
+	require.Equal(t, "some-user", got.Session.Custom.Username)
+	require.Equal(t, fakeNow.Add(10*time.Minute), got.Expiry)
+	require.Equal(t, "1", got.Version)
+
+	list, err := secrets.List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, map[string]string{"storage.pinniped.dev/garbage-collect-after": "2030-01-01T00:11:00Z"}, list.Items[0].Annotations)
+
+	fromSecret, err := ReadPendingConsentFromSecret(&list.Items[0])
+	require.NoError(t, err)
+	require.Equal(t, got, fromSecret)
+
+	err = storage.DeletePendingConsent(ctx, "some-id")
+	require.NoError(t, err)
+
+	_, err = storage.GetPendingConsent(ctx, "some-id")
+	require.True(t, errors.Is(err, fosite.ErrNotFound))
+}
+
+func TestReadPendingConsentFromSecretWithoutSession(t *testing.T) {
+	_, err := ReadPendingConsentFromSecret(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"storage.pinniped.dev/type": "pending-consent"},
+		},
+		Data: map[string][]byte{
+			"pinniped-storage-data":    []byte(`{"clientID":"some-client","version":"1"}`),
+			"pinniped-storage-version": []byte("1"),
+		},
+		Type: "storage.pinniped.dev/pending-consent",
+	})
+	require.EqualError(t, err, "pending consent data must be present")
+}
+
+func makeTestSubject() (context.Context, *fake.Clientset, corev1client.SecretInterface, Storage) {
+	client := fake.NewSimpleClientset()
+	secrets := client.CoreV1().Secrets(namespace)
+	return context.Background(), client, secrets, New(secrets, clocktesting.NewFakeClock(fakeNow).Now, consentLifetime, pendingLifetime)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package authenticator contains helper code for dealing with *Authenticator CRDs.
@@ -7,11 +7,14 @@ package authenticator
 import (
 	"crypto/x509"
 	"encoding/base64"
+	"errors"
 	"fmt"
 
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/util/cert"
 
 	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	"go.pinniped.dev/internal/controller/tlsconfigutil"
 )
 
 // Closer is a type that can be closed idempotently.
@@ -24,9 +27,35 @@ type Closer interface {
 
 // CABundle returns a PEM-encoded CA bundle from the provided spec. If the provided spec is nil, a
 // nil CA bundle will be returned. If the provided spec contains a CA bundle that is not properly
-// encoded, an error will be returned.
-func CABundle(spec *auth1alpha1.TLSSpec) (*x509.CertPool, []byte, error) {
-	if spec == nil || len(spec.CertificateAuthorityData) == 0 {
+// encoded, an error will be returned. When the spec has a certificateAuthorityDataSource, the CA
+// bundle is read from the referenced Secret or ConfigMap in the provided namespace.
+func CABundle(
+	spec *auth1alpha1.TLSSpec,
+	namespace string,
+	secretLister corev1listers.SecretLister,
+	configMapLister corev1listers.ConfigMapLister,
+) (*x509.CertPool, []byte, error) {
+	if spec == nil {
+		return nil, nil, nil
+	}
+
+	if source := spec.CertificateAuthorityDataSource; source != nil {
+		if len(spec.CertificateAuthorityData) > 0 {
+			return nil, nil, errors.New("certificateAuthorityData and certificateAuthorityDataSource cannot both be specified")
+		}
+		rootCAs, pem, _, err := tlsconfigutil.ReadCABundle(tlsconfigutil.CABundleSource{
+			Kind:      string(source.Kind),
+			Name:      source.Name,
+			Namespace: namespace,
+			Key:       source.Key,
+		}, secretLister, configMapLister)
+		if err != nil {
+			return nil, nil, fmt.Errorf("certificateAuthorityDataSource is invalid: %w", err)
+		}
+		return rootCAs, pem, nil
+	}
+
+	if len(spec.CertificateAuthorityData) == 0 {
 		return nil, nil, nil
 	}
 
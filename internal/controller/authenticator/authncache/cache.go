@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package authncache implements a cache of active authenticators.
@@ -8,6 +8,8 @@ import (
 	"context"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
@@ -25,7 +27,14 @@ const ErrNoSuchAuthenticator = constable.Error("no such authenticator")
 // Cache implements the authenticator.Token interface by multiplexing across a dynamic set of authenticators
 // loaded from authenticator resources.
 type Cache struct {
-	cache sync.Map
+	cache       sync.Map
+	lastVersion atomic.Uint64
+}
+
+// entry is an authenticator in the cache, along with the version which was assigned when it was stored.
+type entry struct {
+	value   Value
+	version uint64
 }
 
 type Key struct {
@@ -49,12 +58,12 @@ func (c *Cache) Get(key Key) Value {
 	if res == nil {
 		return nil
 	}
-	return res.(Value)
+	return res.(*entry).value
 }
 
 // Store an authenticator into the cache.
 func (c *Cache) Store(key Key, value Value) {
-	c.cache.Store(key, value)
+	c.cache.Store(key, &entry{value: value, version: c.lastVersion.Add(1)})
 }
 
 // Delete an authenticator from the cache.
@@ -79,15 +88,19 @@ func (c *Cache) Keys() []Key {
 	return result
 }
 
-func (c *Cache) AuthenticateTokenCredentialRequest(ctx context.Context, req *loginapi.TokenCredentialRequest) (user.Info, error) {
-	// Map the incoming request to a cache key.
-	key := Key{
-		Name: req.Spec.Authenticator.Name,
-		Kind: req.Spec.Authenticator.Kind,
-	}
-	if req.Spec.Authenticator.APIGroup != nil {
-		key.APIGroup = *req.Spec.Authenticator.APIGroup
+// AuthenticatorVersion returns the version of the authenticator which is requested by the TokenCredentialRequest, or
+// zero when it is not configured. A different version is returned each time that the authenticator is stored again,
+// e.g. after its resource was changed or replaced.
+func (c *Cache) AuthenticatorVersion(req *loginapi.TokenCredentialRequest) uint64 {
+	res, _ := c.cache.Load(keyForRequest(req))
+	if res == nil {
+		return 0
 	}
+	return res.(*entry).version
+}
+
+func (c *Cache) AuthenticateTokenCredentialRequest(ctx context.Context, req *loginapi.TokenCredentialRequest) (user.Info, error) {
+	key := keyForRequest(req)
 
 	val := c.Get(key)
 	if val == nil {
@@ -105,7 +118,9 @@ func (c *Cache) AuthenticateTokenCredentialRequest(ctx context.Context, req *log
 	ctx = valuelesscontext.New(ctx)
 
 	// Call the selected authenticator.
+	start := time.Now()
 	resp, authenticated, err := val.AuthenticateToken(ctx, req.Spec.Token)
+	observeTokenValidation(key, start, authenticated, err)
 	if err != nil {
 		return nil, err
 	}
@@ -120,3 +135,15 @@ func (c *Cache) AuthenticateTokenCredentialRequest(ctx context.Context, req *log
 	}
 	return respUser, nil
 }
+
+// keyForRequest maps the incoming request to a cache key.
+func keyForRequest(req *loginapi.TokenCredentialRequest) Key {
+	key := Key{
+		Name: req.Spec.Authenticator.Name,
+		Kind: req.Spec.Authenticator.Kind,
+	}
+	if req.Spec.Authenticator.APIGroup != nil {
+		key.APIGroup = *req.Spec.Authenticator.APIGroup
+	}
+	return key
+}
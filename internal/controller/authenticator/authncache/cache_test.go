@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package authncache
@@ -43,10 +43,18 @@ func TestCache(t *testing.T) {
 	require.Equal(t, mockToken2, cache.Get(key2))
 	require.Equal(t, 2, len(cache.Keys()))
 
+	// Storing an authenticator again changes its version.
+	request1 := &loginapi.TokenCredentialRequest{Spec: loginapi.TokenCredentialRequestSpec{Authenticator: corev1.TypedLocalObjectReference{Name: "authenticator-one"}}}
+	version1 := cache.AuthenticatorVersion(request1)
+	require.NotZero(t, version1)
+	cache.Store(key1, mockToken1)
+	require.NotEqual(t, version1, cache.AuthenticatorVersion(request1))
+
 	for _, key := range cache.Keys() {
 		cache.Delete(key)
 	}
 	require.Zero(t, len(cache.Keys()))
+	require.Zero(t, cache.AuthenticatorVersion(request1))
 
 	// Fill the cache back up with a fixed set of keys, but inserted in shuffled order.
 	keysInExpectedOrder := []Key{
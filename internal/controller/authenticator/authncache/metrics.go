@@ -0,0 +1,62 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package authncache
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "pinniped_concierge"
+	metricsSubsystem = "authenticator"
+
+	metricsLabelKind   = "authenticator_kind"
+	metricsLabelName   = "authenticator_name"
+	metricsLabelResult = "result"
+
+	metricsResultAuthenticated   = "authenticated"
+	metricsResultUnauthenticated = "unauthenticated"
+	metricsResultError           = "error"
+)
+
+//nolint:gochecknoglobals // Metrics are registered once with the global registry which is served by the Concierge.
+var (
+	tokenValidationDuration = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "token_validation_duration_seconds",
+		Help:      "Latency of the validation of tokens by JWTAuthenticators and WebhookAuthenticators in seconds, partitioned by authenticator kind, authenticator name, and result.",
+		// From 1ms to about 32s, which covers the default timeout of the webhooks.
+		Buckets:        metrics.ExponentialBuckets(0.001, 2, 16),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{metricsLabelKind, metricsLabelName, metricsLabelResult})
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the authenticators with the global registry, which is served on the
+// /metrics endpoint of the Concierge's aggregated API server and on its metrics listener. It is safe to call more
+// than once.
+func RegisterMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(tokenValidationDuration)
+	})
+}
+
+// observeTokenValidation records one validation of a token by the authenticator with the given key which started
+// at the given time.
+func observeTokenValidation(key Key, start time.Time, authenticated bool, err error) {
+	result := metricsResultUnauthenticated
+	switch {
+	case err != nil:
+		result = metricsResultError
+	case authenticated:
+		result = metricsResultAuthenticated
+	}
+	tokenValidationDuration.WithLabelValues(key.Kind, key.Name, result).Observe(time.Since(start).Seconds())
+}
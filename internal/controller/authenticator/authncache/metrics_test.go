@@ -0,0 +1,67 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package authncache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/component-base/metrics/testutil"
+
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/mocks/mocktokenauthenticator"
+)
+
+func TestTokenValidationMetrics(t *testing.T) {
+	RegisterMetrics()
+	RegisterMetrics() // registering again is a no-op
+
+	key := Key{Kind: "JWTAuthenticator", Name: "some-instrumented-authenticator"}
+
+	requireCount := func(result string, want int) {
+		t.Helper()
+		observations, err := testutil.GetHistogramMetricCount(tokenValidationDuration.WithLabelValues(key.Kind, key.Name, result))
+		require.NoError(t, err)
+		require.Equal(t, uint64(want), observations)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	m := mocktokenauthenticator.NewMockToken(ctrl)
+	m.EXPECT().AuthenticateToken(gomock.Any(), "good-token").
+		Return(&authenticator.Response{User: &user.DefaultInfo{Name: "test-user"}}, true, nil).Times(2)
+	m.EXPECT().AuthenticateToken(gomock.Any(), "bad-token").Return(nil, false, nil)
+	m.EXPECT().AuthenticateToken(gomock.Any(), "broken-token").Return(nil, false, errors.New("some authenticator error"))
+
+	c := New()
+	c.Store(key, m)
+
+	for _, token := range []string{"good-token", "good-token", "bad-token", "broken-token"} {
+		_, _ = c.AuthenticateTokenCredentialRequest(context.Background(), &loginapi.TokenCredentialRequest{
+			Spec: loginapi.TokenCredentialRequestSpec{
+				Authenticator: corev1.TypedLocalObjectReference{Kind: key.Kind, Name: key.Name},
+				Token:         token,
+			},
+		})
+	}
+
+	// Authenticators which do not exist are not recorded.
+	_, err := c.AuthenticateTokenCredentialRequest(context.Background(), &loginapi.TokenCredentialRequest{
+		Spec: loginapi.TokenCredentialRequestSpec{
+			Authenticator: corev1.TypedLocalObjectReference{Kind: key.Kind, Name: "no-such-authenticator"},
+			Token:         "good-token",
+		},
+	})
+	require.ErrorIs(t, err, ErrNoSuchAuthenticator)
+
+	requireCount(metricsResultAuthenticated, 2)
+	requireCount(metricsResultUnauthenticated, 1)
+	requireCount(metricsResultError, 1)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package cachecleaner implements a controller for garbage collecting authenticators from an authenticator cache.
@@ -24,16 +24,20 @@ func New(
 	cache *authncache.Cache,
 	webhooks authinformers.WebhookAuthenticatorInformer,
 	jwtAuthenticators authinformers.JWTAuthenticatorInformer,
+	x509Authenticators authinformers.X509ClientCertificateAuthenticatorInformer,
+	serviceAccountTokenAuthenticators authinformers.ServiceAccountTokenAuthenticatorInformer,
 	log logr.Logger,
 ) controllerlib.Controller {
 	return controllerlib.New(
 		controllerlib.Config{
 			Name: "cachecleaner-controller",
 			Syncer: &controller{
-				cache:             cache,
-				webhooks:          webhooks,
-				jwtAuthenticators: jwtAuthenticators,
-				log:               log.WithName("cachecleaner-controller"),
+				cache:                             cache,
+				webhooks:                          webhooks,
+				jwtAuthenticators:                 jwtAuthenticators,
+				x509Authenticators:                x509Authenticators,
+				serviceAccountTokenAuthenticators: serviceAccountTokenAuthenticators,
+				log:                               log.WithName("cachecleaner-controller"),
 			},
 		},
 		controllerlib.WithInformer(
@@ -46,14 +50,26 @@ func New(
 			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
 			controllerlib.InformerOption{},
 		),
+		controllerlib.WithInformer(
+			x509Authenticators,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		controllerlib.WithInformer(
+			serviceAccountTokenAuthenticators,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
 	)
 }
 
 type controller struct {
-	cache             *authncache.Cache
-	webhooks          authinformers.WebhookAuthenticatorInformer
-	jwtAuthenticators authinformers.JWTAuthenticatorInformer
-	log               logr.Logger
+	cache                             *authncache.Cache
+	webhooks                          authinformers.WebhookAuthenticatorInformer
+	jwtAuthenticators                 authinformers.JWTAuthenticatorInformer
+	x509Authenticators                authinformers.X509ClientCertificateAuthenticatorInformer
+	serviceAccountTokenAuthenticators authinformers.ServiceAccountTokenAuthenticatorInformer
+	log                               logr.Logger
 }
 
 // Sync implements controllerlib.Syncer.
@@ -68,6 +84,16 @@ func (c *controller) Sync(_ controllerlib.Context) error {
 		return fmt.Errorf("failed to list JWTAuthenticators: %w", err)
 	}
 
+	x509Authenticators, err := c.x509Authenticators.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list X509ClientCertificateAuthenticators: %w", err)
+	}
+
+	serviceAccountTokenAuthenticators, err := c.serviceAccountTokenAuthenticators.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list ServiceAccountTokenAuthenticators: %w", err)
+	}
+
 	// Index the current authenticators by cache key.
 	authenticatorSet := map[authncache.Key]bool{}
 	for _, webhook := range webhooks {
@@ -86,10 +112,26 @@ func (c *controller) Sync(_ controllerlib.Context) error {
 		}
 		authenticatorSet[key] = true
 	}
+	for _, x509Authenticator := range x509Authenticators {
+		key := authncache.Key{
+			Name:     x509Authenticator.Name,
+			Kind:     "X509ClientCertificateAuthenticator",
+			APIGroup: auth1alpha1.SchemeGroupVersion.Group,
+		}
+		authenticatorSet[key] = true
+	}
+	for _, serviceAccountTokenAuthenticator := range serviceAccountTokenAuthenticators {
+		key := authncache.Key{
+			Name:     serviceAccountTokenAuthenticator.Name,
+			Kind:     "ServiceAccountTokenAuthenticator",
+			APIGroup: auth1alpha1.SchemeGroupVersion.Group,
+		}
+		authenticatorSet[key] = true
+	}
 
 	// Delete any entries from the cache which are no longer in the cluster.
 	for _, key := range c.cache.Keys() {
-		if key.APIGroup != auth1alpha1.SchemeGroupVersion.Group || (key.Kind != "WebhookAuthenticator" && key.Kind != "JWTAuthenticator") {
+		if key.APIGroup != auth1alpha1.SchemeGroupVersion.Group || !isKnownKind(key.Kind) {
 			continue
 		}
 		if _, exists := authenticatorSet[key]; !exists {
@@ -110,3 +152,12 @@ func (c *controller) Sync(_ controllerlib.Context) error {
 	}
 	return nil
 }
+
+func isKnownKind(kind string) bool {
+	switch kind {
+	case "WebhookAuthenticator", "JWTAuthenticator", "X509ClientCertificateAuthenticator", "ServiceAccountTokenAuthenticator":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cachecleaner
@@ -44,6 +44,26 @@ func TestController(t *testing.T) {
 		Kind:     "JWTAuthenticator",
 		Name:     "test-jwt-authenticator-name-two",
 	}
+	testX509AuthenticatorKey1 := authncache.Key{
+		APIGroup: "authentication.concierge.pinniped.dev",
+		Kind:     "X509ClientCertificateAuthenticator",
+		Name:     "test-x509-authenticator-name-one",
+	}
+	testX509AuthenticatorKey2 := authncache.Key{
+		APIGroup: "authentication.concierge.pinniped.dev",
+		Kind:     "X509ClientCertificateAuthenticator",
+		Name:     "test-x509-authenticator-name-two",
+	}
+	testServiceAccountTokenAuthenticatorKey1 := authncache.Key{
+		APIGroup: "authentication.concierge.pinniped.dev",
+		Kind:     "ServiceAccountTokenAuthenticator",
+		Name:     "test-service-account-token-authenticator-name-one",
+	}
+	testServiceAccountTokenAuthenticatorKey2 := authncache.Key{
+		APIGroup: "authentication.concierge.pinniped.dev",
+		Kind:     "ServiceAccountTokenAuthenticator",
+		Name:     "test-service-account-token-authenticator-name-two",
+	}
 	testKeyUnknownType := authncache.Key{
 		APIGroup: "authentication.concierge.pinniped.dev",
 		Kind:     "SomeOtherAuthenticator",
@@ -111,6 +131,10 @@ func TestController(t *testing.T) {
 				cache.Store(testWebhookKey2, nil)
 				cache.Store(testJWTAuthenticatorKey1, newClosableCacheValue(t, 0))
 				cache.Store(testJWTAuthenticatorKey2, newClosableCacheValue(t, 1))
+				cache.Store(testX509AuthenticatorKey1, nil)
+				cache.Store(testX509AuthenticatorKey2, nil)
+				cache.Store(testServiceAccountTokenAuthenticatorKey1, nil)
+				cache.Store(testServiceAccountTokenAuthenticatorKey2, nil)
 				cache.Store(testKeyUnknownType, nil)
 			},
 			objects: []runtime.Object{
@@ -124,12 +148,24 @@ func TestController(t *testing.T) {
 						Name: testJWTAuthenticatorKey1.Name,
 					},
 				},
+				&authv1alpha.X509ClientCertificateAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: testX509AuthenticatorKey1.Name,
+					},
+				},
+				&authv1alpha.ServiceAccountTokenAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: testServiceAccountTokenAuthenticatorKey1.Name,
+					},
+				},
 			},
 			wantLogs: []string{
 				`cachecleaner-controller "level"=0 "msg"="deleting authenticator from cache" "authenticator"={"name":"test-jwt-authenticator-name-two"} "kind"="JWTAuthenticator"`,
+				`cachecleaner-controller "level"=0 "msg"="deleting authenticator from cache" "authenticator"={"name":"test-service-account-token-authenticator-name-two"} "kind"="ServiceAccountTokenAuthenticator"`,
 				`cachecleaner-controller "level"=0 "msg"="deleting authenticator from cache" "authenticator"={"name":"test-webhook-name-two"} "kind"="WebhookAuthenticator"`,
+				`cachecleaner-controller "level"=0 "msg"="deleting authenticator from cache" "authenticator"={"name":"test-x509-authenticator-name-two"} "kind"="X509ClientCertificateAuthenticator"`,
 			},
-			wantCacheKeys: []authncache.Key{testWebhookKey1, testJWTAuthenticatorKey1, testKeyUnknownType},
+			wantCacheKeys: []authncache.Key{testWebhookKey1, testJWTAuthenticatorKey1, testX509AuthenticatorKey1, testServiceAccountTokenAuthenticatorKey1, testKeyUnknownType},
 		},
 	}
 	for _, tt := range tests {
@@ -147,7 +183,9 @@ func TestController(t *testing.T) {
 
 			webhooks := informers.Authentication().V1alpha1().WebhookAuthenticators()
 			jwtAuthenticators := informers.Authentication().V1alpha1().JWTAuthenticators()
-			controller := New(cache, webhooks, jwtAuthenticators, testLog.Logger)
+			x509Authenticators := informers.Authentication().V1alpha1().X509ClientCertificateAuthenticators()
+			serviceAccountTokenAuthenticators := informers.Authentication().V1alpha1().ServiceAccountTokenAuthenticators()
+			controller := New(cache, webhooks, jwtAuthenticators, x509Authenticators, serviceAccountTokenAuthenticators, testLog.Logger)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -0,0 +1,213 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwtcachefiller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// jwksMinOnDemandRefreshInterval limits how often the signing keys are fetched while validating tokens, which
+	// happens when a token is signed by a key which is not cached yet, e.g. because the issuer rotated its keys.
+	jwksMinOnDemandRefreshInterval = 30 * time.Second
+
+	// jwksMaxResponseBytes limits how much of the response of the JWKS endpoint is read.
+	jwksMaxResponseBytes = 1024 * 1024
+)
+
+// JWKSCache holds the signing keys of the issuer of each JWTAuthenticator, so that tokens can continue to be validated
+// while the issuer is unavailable. It is shared by the controller returned by New, which adds a key set whenever it
+// creates an authenticator, and the controller returned by NewJWKSRefresher, which refreshes the key sets periodically.
+type JWKSCache struct {
+	clock clock.PassiveClock
+
+	lock    sync.RWMutex
+	keySets map[string]*cachedKeySet
+}
+
+// NewJWKSCache returns an empty JWKSCache.
+func NewJWKSCache(c clock.PassiveClock) *JWKSCache {
+	return &JWKSCache{clock: c, keySets: map[string]*cachedKeySet{}}
+}
+
+// newKeySet creates a key set for the JWTAuthenticator with the given name, replacing any previous key set.
+func (c *JWKSCache) newKeySet(name string, jwksURL string, client *http.Client) *cachedKeySet {
+	keySet := &cachedKeySet{jwksURL: jwksURL, client: client, clock: c.clock}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.keySets[name] = keySet
+	return keySet
+}
+
+func (c *JWKSCache) get(name string) *cachedKeySet {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.keySets[name]
+}
+
+// retain forgets the key sets of the JWTAuthenticators whose names are not given, e.g. because they were deleted.
+func (c *JWKSCache) retain(names sets.Set[string]) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for name := range c.keySets {
+		if !names.Has(name) {
+			delete(c.keySets, name)
+		}
+	}
+}
+
+// cachedKeySet implements the KeySet interface of go-oidc by verifying signatures with the keys which were most
+// recently fetched from the JWKS endpoint of the issuer.
+type cachedKeySet struct {
+	jwksURL string
+	client  *http.Client
+	clock   clock.PassiveClock
+
+	// fetchLock makes sure that only one fetch happens at a time.
+	fetchLock sync.Mutex
+
+	lock        sync.RWMutex
+	keys        []jose.JSONWebKey
+	fetchedAt   time.Time // when the keys were last fetched successfully
+	attemptedAt time.Time // when the keys were last fetched, successfully or not
+	lastErr     error     // the error of the last fetch, or nil when it succeeded
+	nextRefresh time.Time // when the refresher should fetch the keys again
+}
+
+// VerifySignature implements coreosoidc.KeySet.
+func (k *cachedKeySet) VerifySignature(ctx context.Context, jwt string) ([]byte, error) {
+	jws, err := jose.ParseSigned(jwt)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: malformed jwt: %w", err)
+	}
+
+	keyID := ""
+	for _, signature := range jws.Signatures {
+		keyID = signature.Header.KeyID
+		break
+	}
+
+	if payload, ok := verifyWithKeys(jws, keyID, k.cachedKeys()); ok {
+		return payload, nil
+	}
+
+	// The issuer may have rotated its keys since they were cached, so fetch them again, but not too often.
+	if k.refreshOnDemand(ctx) {
+		if payload, ok := verifyWithKeys(jws, keyID, k.cachedKeys()); ok {
+			return payload, nil
+		}
+	}
+
+	return nil, errors.New("failed to verify id token signature")
+}
+
+func verifyWithKeys(jws *jose.JSONWebSignature, keyID string, keys []jose.JSONWebKey) ([]byte, bool) {
+	for i := range keys {
+		key := keys[i]
+		if keyID != "" && keyID != key.KeyID {
+			continue
+		}
+		if payload, err := jws.Verify(&key); err == nil {
+			return payload, true
+		}
+	}
+	return nil, false
+}
+
+func (k *cachedKeySet) cachedKeys() []jose.JSONWebKey {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.keys
+}
+
+// refreshOnDemand fetches the keys again unless they were fetched recently. It returns true when it fetched them.
+func (k *cachedKeySet) refreshOnDemand(ctx context.Context) bool {
+	k.lock.RLock()
+	attemptedAt := k.attemptedAt
+	k.lock.RUnlock()
+
+	if k.clock.Since(attemptedAt) < jwksMinOnDemandRefreshInterval {
+		return false
+	}
+	return k.refresh(ctx) == nil
+}
+
+// refresh fetches the keys from the JWKS endpoint. When it fails, the previously fetched keys are kept.
+func (k *cachedKeySet) refresh(ctx context.Context) error {
+	k.fetchLock.Lock()
+	defer k.fetchLock.Unlock()
+
+	keys, err := k.fetch(ctx)
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.attemptedAt = k.clock.Now()
+	k.lastErr = err
+	if err != nil {
+		return err
+	}
+	k.keys = keys
+	k.fetchedAt = k.attemptedAt
+	return nil
+}
+
+func (k *cachedKeySet) fetch(ctx context.Context) ([]jose.JSONWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch keys: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("could not read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch keys: %s: %s", resp.Status, body)
+	}
+
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal(body, &keySet); err != nil {
+		return nil, fmt.Errorf("could not decode keys: %w", err)
+	}
+	return keySet.Keys, nil
+}
+
+// state returns when the keys were last fetched successfully, and the error of the last fetch.
+func (k *cachedKeySet) state() (time.Time, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.fetchedAt, k.lastErr
+}
+
+// refreshDue returns true when the refresher should fetch the keys now. It also returns how long to wait until
+// the refresh is due, when it is not due yet.
+func (k *cachedKeySet) refreshDue() (bool, time.Duration) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	untilDue := k.nextRefresh.Sub(k.clock.Now())
+	return untilDue <= 0, untilDue
+}
+
+func (k *cachedKeySet) scheduleRefresh(after time.Duration) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.nextRefresh = k.clock.Now().Add(after)
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwtcachefiller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	authinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/authentication/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	jwksRefresherControllerName = "jwks-refresher-controller"
+
+	// jwksRefreshInterval is how often the signing keys of each JWTAuthenticator are fetched again.
+	jwksRefreshInterval = 5 * time.Minute
+
+	// jwksRetryInterval is how soon the signing keys are fetched again after fetching them failed, or how soon
+	// to check again for the key set of a JWTAuthenticator which has not been created yet.
+	jwksRetryInterval = 30 * time.Second
+
+	// jwksRefreshJitterFactor spreads out the refreshes of the JWTAuthenticators which were created at the same
+	// time, e.g. when the Concierge starts, by waiting up to this fraction of the interval longer.
+	jwksRefreshJitterFactor = 0.2
+
+	typeJWKSRefreshed = "JWKSRefreshed"
+
+	reasonJWKSStale       = "Stale"
+	reasonJWKSUnavailable = "Unavailable"
+)
+
+// jitter returns a random duration which is a little longer than the given duration.
+func jitter(d time.Duration) time.Duration {
+	return wait.Jitter(d, jwksRefreshJitterFactor)
+}
+
+type jwksRefresherController struct {
+	jwksCache         *JWKSCache
+	client            pinnipedclientset.Interface
+	jwtAuthenticators authinformers.JWTAuthenticatorInformer
+	jitter            func(time.Duration) time.Duration
+	log               plog.Logger
+}
+
+// NewJWKSRefresher instantiates a new controllerlib.Controller which will periodically fetch the signing keys of
+// the issuer of each JWTAuthenticator again. The keys are kept in the provided JWKSCache, which should be shared
+// with the controller returned by New, so that tokens are validated with the cached keys. Whether the cached keys
+// are up to date is reported by a condition on the JWTAuthenticator.
+func NewJWKSRefresher(
+	jwksCache *JWKSCache,
+	client pinnipedclientset.Interface,
+	jwtAuthenticators authinformers.JWTAuthenticatorInformer,
+	log plog.Logger,
+) controllerlib.Controller {
+	return newJWKSRefresher(jwksCache, client, jwtAuthenticators, jitter, log)
+}
+
+func newJWKSRefresher(
+	jwksCache *JWKSCache,
+	client pinnipedclientset.Interface,
+	jwtAuthenticators authinformers.JWTAuthenticatorInformer,
+	jitter func(time.Duration) time.Duration,
+	log plog.Logger,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: jwksRefresherControllerName,
+			Syncer: &jwksRefresherController{
+				jwksCache:         jwksCache,
+				client:            client,
+				jwtAuthenticators: jwtAuthenticators,
+				jitter:            jitter,
+				log:               log.WithName(jwksRefresherControllerName),
+			},
+		},
+		controllerlib.WithInformer(
+			jwtAuthenticators,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *jwksRefresherController) Sync(ctx controllerlib.Context) error {
+	jwtAuthenticators, err := c.jwtAuthenticators.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list JWTAuthenticators: %w", err)
+	}
+
+	var nextSync time.Duration
+	scheduleSync := func(after time.Duration) {
+		if nextSync == 0 || after < nextSync {
+			nextSync = after
+		}
+	}
+
+	names := sets.New[string]()
+	for _, obj := range jwtAuthenticators {
+		names.Insert(obj.Name)
+
+		keySet := c.jwksCache.get(obj.Name)
+		if keySet == nil {
+			// The other controller has not created the authenticator yet, or it failed to create it.
+			scheduleSync(jwksRetryInterval)
+			continue
+		}
+
+		if due, untilDue := keySet.refreshDue(); !due {
+			scheduleSync(untilDue)
+		} else {
+			interval := c.jitter(jwksRefreshInterval)
+			if err := keySet.refresh(ctx.Context); err != nil {
+				c.log.DebugErr("failed to refresh JWKS", err, "jwtAuthenticator", obj.Name, "jwksURL", keySet.jwksURL)
+				interval = c.jitter(jwksRetryInterval)
+			}
+			keySet.scheduleRefresh(interval)
+			scheduleSync(interval)
+		}
+
+		if condition := jwksRefreshedCondition(keySet); condition != nil {
+			c.updateStatus(ctx.Context, obj, condition)
+		}
+	}
+
+	// Forget the key sets of any JWTAuthenticators which were deleted.
+	c.jwksCache.retain(names)
+
+	// Sync again when the next refresh is due.
+	if nextSync > 0 {
+		ctx.Queue.AddAfter(ctx.Key, nextSync)
+	}
+	return nil
+}
+
+// jwksRefreshedCondition describes whether the cached keys are up to date. It returns nil when the keys have
+// never been fetched yet.
+func jwksRefreshedCondition(keySet *cachedKeySet) *auth1alpha1.Condition {
+	fetchedAt, err := keySet.state()
+	switch {
+	case err == nil && fetchedAt.IsZero():
+		return nil
+	case err == nil:
+		return &auth1alpha1.Condition{
+			Type:    typeJWKSRefreshed,
+			Status:  auth1alpha1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: "cached signing keys are up to date",
+		}
+	case fetchedAt.IsZero():
+		return &auth1alpha1.Condition{
+			Type:    typeJWKSRefreshed,
+			Status:  auth1alpha1.ConditionFalse,
+			Reason:  reasonJWKSUnavailable,
+			Message: fmt.Sprintf("could not fetch signing keys from %s: %s", keySet.jwksURL, err),
+		}
+	default:
+		return &auth1alpha1.Condition{
+			Type:   typeJWKSRefreshed,
+			Status: auth1alpha1.ConditionFalse,
+			Reason: reasonJWKSStale,
+			Message: fmt.Sprintf("could not refresh signing keys from %s, so the keys which were fetched at %s are used: %s",
+				keySet.jwksURL, fetchedAt.UTC().Format(time.RFC3339), err),
+		}
+	}
+}
+
+func (c *jwksRefresherController) updateStatus(ctx context.Context, original *auth1alpha1.JWTAuthenticator, condition *auth1alpha1.Condition) {
+	log := c.log.WithValues("jwtAuthenticator", original.Name)
+	updated := original.DeepCopy()
+
+	_ = conditionsutil.MergeAuthenticatorConditions([]*auth1alpha1.Condition{condition}, original.Generation, &updated.Status.Conditions, log)
+
+	if equality.Semantic.DeepEqual(original, updated) {
+		return
+	}
+
+	_, err := c.client.
+		AuthenticationV1alpha1().
+		JWTAuthenticators().
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error("failed to update status", err)
+	}
+}
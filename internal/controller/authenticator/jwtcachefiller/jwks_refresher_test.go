@@ -0,0 +1,287 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwtcachefiller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coretesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+)
+
+type recordingQueue struct {
+	addAfterDurations []time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *recordingQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.addAfterDurations = append(q.addAfterDurations, duration)
+}
+
+func TestJWKSRefresherControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const testName = "test-name"
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: signingKey},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "some-key-id"),
+	)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "some-subject"}).CompactSerialize()
+	require.NoError(t, err)
+
+	startTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		noKeySet        bool
+		prefetch        bool
+		failAfterFirst  bool
+		failAlways      bool
+		wantCondition   *auth1alpha1.Condition // the condition of the last status update
+		wantAddAfter    []time.Duration
+		wantVerifyToken bool
+	}{
+		{
+			name:     "keys are refreshed",
+			prefetch: true,
+			wantCondition: &auth1alpha1.Condition{
+				Type:    "JWKSRefreshed",
+				Status:  "True",
+				Reason:  "Success",
+				Message: "cached signing keys are up to date",
+			},
+			wantAddAfter:    []time.Duration{5 * time.Minute, 5 * time.Minute},
+			wantVerifyToken: true,
+		},
+		{
+			name:           "keys cannot be refreshed, so the cached keys are used",
+			prefetch:       true,
+			failAfterFirst: true,
+			wantCondition: &auth1alpha1.Condition{
+				Type:    "JWKSRefreshed",
+				Status:  "False",
+				Reason:  "Stale",
+				Message: "could not refresh signing keys from JWKS_URL, so the keys which were fetched at 2026-01-02T03:04:05Z are used: could not fetch keys: 503 Service Unavailable: some jwks error",
+			},
+			wantAddAfter:    []time.Duration{5 * time.Minute, 30 * time.Second},
+			wantVerifyToken: true,
+		},
+		{
+			name:       "keys were never fetched",
+			failAlways: true,
+			wantCondition: &auth1alpha1.Condition{
+				Type:    "JWKSRefreshed",
+				Status:  "False",
+				Reason:  "Unavailable",
+				Message: "could not fetch signing keys from JWKS_URL: could not fetch keys: 503 Service Unavailable: some jwks error",
+			},
+			wantAddAfter: []time.Duration{30 * time.Second, 30 * time.Second},
+		},
+		{
+			name:         "the authenticator has not been created yet",
+			noKeySet:     true,
+			wantAddAfter: []time.Duration{30 * time.Second, 30 * time.Second},
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var lock sync.Mutex
+			failing := tt.failAlways
+			mux := http.NewServeMux()
+			mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				if failing {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					_, _ = w.Write([]byte("some jwks error"))
+					return
+				}
+				w.Header().Set("content-type", "application/json")
+				_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+					{Key: signingKey.Public(), KeyID: "some-key-id", Algorithm: string(jose.ES256), Use: "sig"},
+				}})
+			})
+			caBundlePEM, testURL := testutil.TLSTestServer(t, mux.ServeHTTP)
+			jwksURL := testURL + "/jwks.json"
+			rootCAs := x509.NewCertPool()
+			require.True(t, rootCAs.AppendCertsFromPEM([]byte(caBundlePEM)))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeClock := clocktesting.NewFakeClock(startTime)
+			jwksCache := NewJWKSCache(fakeClock)
+			var keySet *cachedKeySet
+			if !tt.noKeySet {
+				keySet = jwksCache.newKeySet(testName, jwksURL, phttp.Default(rootCAs))
+				if tt.prefetch {
+					require.NoError(t, keySet.refresh(ctx))
+					keySet.scheduleRefresh(jwksRefreshInterval)
+				}
+			}
+			// This key set belongs to a JWTAuthenticator which was deleted.
+			jwksCache.newKeySet("some-deleted-name", jwksURL, phttp.Default(rootCAs))
+
+			fakeClient := pinnipedfake.NewSimpleClientset(&auth1alpha1.JWTAuthenticator{
+				ObjectMeta: metav1.ObjectMeta{Name: testName, Generation: 1234},
+			})
+			informers := pinnipedinformers.NewSharedInformerFactory(fakeClient, 0)
+
+			controller := newJWKSRefresher(
+				jwksCache,
+				fakeClient,
+				informers.Authentication().V1alpha1().JWTAuthenticators(),
+				func(d time.Duration) time.Duration { return d },
+				plog.TestLogger(t, io.Discard),
+			)
+
+			informers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			queue := &recordingQueue{}
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}, Queue: queue}
+
+			require.NoError(t, controllerlib.TestSync(t, controller, syncCtx))
+			require.Nil(t, jwksCache.get("some-deleted-name"))
+
+			if tt.failAfterFirst {
+				lock.Lock()
+				failing = true
+				lock.Unlock()
+			}
+
+			fakeClock.Step(jwksRefreshInterval)
+			require.NoError(t, controllerlib.TestSync(t, controller, syncCtx))
+			require.Equal(t, tt.wantAddAfter, queue.addAfterDurations)
+
+			// The second sync only updates the status when the condition changed, and whether it changed depends
+			// on whether the informer has seen the first update yet, so only look at the last update.
+			updates := jwtAuthenticatorStatusUpdates(fakeClient.Actions())
+			if tt.wantCondition == nil {
+				require.Empty(t, updates)
+			} else {
+				require.NotEmpty(t, updates)
+				conditions := updates[len(updates)-1].Status.Conditions
+				require.Len(t, conditions, 1)
+				wantCondition := *tt.wantCondition
+				wantCondition.Message = strings.ReplaceAll(wantCondition.Message, "JWKS_URL", jwksURL)
+				wantCondition.ObservedGeneration = 1234
+				wantCondition.LastTransitionTime = conditions[0].LastTransitionTime
+				require.Equal(t, wantCondition, conditions[0])
+			}
+
+			if tt.wantVerifyToken {
+				payload, err := keySet.VerifySignature(ctx, token)
+				require.NoError(t, err)
+				require.Contains(t, string(payload), `"sub":"some-subject"`)
+			}
+		})
+	}
+}
+
+func TestCachedKeySetVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rotatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sign := func(key *ecdsa.PrivateKey, keyID string) string {
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: key},
+			(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID),
+		)
+		require.NoError(t, err)
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "some-subject"}).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	var lock sync.Mutex
+	fetches := 0
+	servedKey, servedKeyID := oldKey, "old-key-id"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		fetches++
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: servedKey.Public(), KeyID: servedKeyID, Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+	})
+	caBundlePEM, testURL := testutil.TLSTestServer(t, mux.ServeHTTP)
+	rootCAs := x509.NewCertPool()
+	require.True(t, rootCAs.AppendCertsFromPEM([]byte(caBundlePEM)))
+
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	keySet := NewJWKSCache(fakeClock).newKeySet("some-name", testURL+"/jwks.json", phttp.Default(rootCAs))
+	require.NoError(t, keySet.refresh(ctx))
+
+	// Tokens signed by the cached key are verified without fetching the keys again.
+	_, err = keySet.VerifySignature(ctx, sign(oldKey, "old-key-id"))
+	require.NoError(t, err)
+	require.Equal(t, 1, fetches)
+
+	// The issuer rotates its key.
+	lock.Lock()
+	servedKey, servedKeyID = rotatedKey, "rotated-key-id"
+	lock.Unlock()
+
+	// Right after the keys were fetched, they are not fetched again on demand.
+	_, err = keySet.VerifySignature(ctx, sign(rotatedKey, "rotated-key-id"))
+	require.EqualError(t, err, "failed to verify id token signature")
+	require.Equal(t, 1, fetches)
+
+	// Later, a token signed by an unknown key causes the keys to be fetched again.
+	fakeClock.Step(jwksMinOnDemandRefreshInterval)
+	_, err = keySet.VerifySignature(ctx, sign(rotatedKey, "rotated-key-id"))
+	require.NoError(t, err)
+	require.Equal(t, 2, fetches)
+
+	_, err = keySet.VerifySignature(ctx, "not a jwt")
+	require.ErrorContains(t, err, "oidc: malformed jwt: ")
+}
+
+func jwtAuthenticatorStatusUpdates(actions []coretesting.Action) []*auth1alpha1.JWTAuthenticator {
+	var updates []*auth1alpha1.JWTAuthenticator
+	for _, action := range actions {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			updates = append(updates, action.(coretesting.UpdateAction).GetObject().(*auth1alpha1.JWTAuthenticator))
+		}
+	}
+	return updates
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package jwtcachefiller implements a controller for filling an authncache.Cache with each
@@ -6,7 +6,9 @@
 package jwtcachefiller
 
 import (
+	"bytes"
 	"context"
+	"crypto/x509"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -15,17 +17,29 @@ import (
 	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
 	"github.com/go-logr/logr"
 	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/token/union"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/plugin/pkg/authenticator/token/oidc"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/klog/v2"
 
 	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
 	authinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/authentication/v1alpha1"
+	"go.pinniped.dev/internal/claimexpressions"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	pinnipedauthenticator "go.pinniped.dev/internal/controller/authenticator"
 	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/controller/conditionsutil"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/net/phttp"
 )
@@ -37,6 +51,15 @@ const (
 	defaultGroupsClaim   = oidcapi.IDTokenClaimGroups
 )
 
+const (
+	typeIssuerDiscovered = "IssuerDiscovered"
+
+	reasonSuccess              = "Success"
+	reasonInvalidIssuer        = "InvalidIssuer"
+	reasonDiscoveryFailed      = "DiscoveryFailed"
+	reasonInvalidAuthenticator = "InvalidAuthenticator"
+)
+
 // defaultSupportedSigningAlgos returns the default signing algos that this JWTAuthenticator
 // supports (i.e., if none are supplied by the user).
 func defaultSupportedSigningAlgos() []string {
@@ -58,13 +81,74 @@ type tokenAuthenticatorCloser interface {
 
 type jwtAuthenticator struct {
 	tokenAuthenticatorCloser
-	spec *auth1alpha1.JWTAuthenticatorSpec
+	spec             *auth1alpha1.JWTAuthenticatorSpec
+	caBundle         []byte
+	discoveredIssuer string
+}
+
+// unionAuthenticator accepts a token when any of its authenticators accepts it, and closes all of them.
+type unionAuthenticator struct {
+	authenticator.Token
+	closers []pinnipedauthenticator.Closer
+}
+
+func (u *unionAuthenticator) Close() {
+	for _, closer := range u.closers {
+		closer.Close()
+	}
+}
+
+// claimMappingsAuthenticator computes the username and groups of each token which was accepted by its
+// authenticator using the CEL expressions of the claim mappings.
+type claimMappingsAuthenticator struct {
+	tokenAuthenticatorCloser
+	expressions *claimexpressions.Expressions
+}
+
+func (a *claimMappingsAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	rsp, ok, err := a.tokenAuthenticatorCloser.AuthenticateToken(ctx, token)
+	if err != nil || !ok {
+		return rsp, ok, err
+	}
+
+	// The token has already been verified by the authenticator above, so its claims can be trusted.
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not parse token: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := parsed.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return nil, false, fmt.Errorf("could not read token claims: %w", err)
+	}
+
+	info := &user.DefaultInfo{
+		Name:   rsp.User.GetName(),
+		UID:    rsp.User.GetUID(),
+		Groups: rsp.User.GetGroups(),
+		Extra:  rsp.User.GetExtra(),
+	}
+	if a.expressions.HasUsername() {
+		if info.Name, err = a.expressions.Username(claims); err != nil {
+			return nil, false, fmt.Errorf("claim mappings: %w", err)
+		}
+	}
+	if a.expressions.HasGroups() {
+		if info.Groups, err = a.expressions.Groups(claims); err != nil {
+			return nil, false, fmt.Errorf("claim mappings: %w", err)
+		}
+	}
+	return &authenticator.Response{Audiences: rsp.Audiences, User: info}, true, nil
 }
 
 // New instantiates a new controllerlib.Controller which will populate the provided authncache.Cache.
 func New(
 	cache *authncache.Cache,
+	jwksCache *JWKSCache,
+	client pinnipedclientset.Interface,
 	jwtAuthenticators authinformers.JWTAuthenticatorInformer,
+	namespace string,
+	secrets corev1informers.SecretInformer,
+	configMaps corev1informers.ConfigMapInformer,
 	log logr.Logger,
 ) controllerlib.Controller {
 	return controllerlib.New(
@@ -72,7 +156,12 @@ func New(
 			Name: "jwtcachefiller-controller",
 			Syncer: &controller{
 				cache:             cache,
+				jwksCache:         jwksCache,
+				client:            client,
 				jwtAuthenticators: jwtAuthenticators,
+				namespace:         namespace,
+				secrets:           secrets,
+				configMaps:        configMaps,
 				log:               log.WithName("jwtcachefiller-controller"),
 			},
 		},
@@ -81,17 +170,52 @@ func New(
 			pinnipedcontroller.MatchAnythingFilter(nil), // nil parent func is fine because each event is distinct
 			controllerlib.InformerOption{},
 		),
+		// Any JWTAuthenticator may read its CA bundle from a Secret or ConfigMap, so changes to those
+		// cause all JWTAuthenticators to be synced again.
+		controllerlib.WithInformer(
+			secrets,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		controllerlib.WithInformer(
+			configMaps,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
 	)
 }
 
 type controller struct {
 	cache             *authncache.Cache
+	jwksCache         *JWKSCache
+	client            pinnipedclientset.Interface
 	jwtAuthenticators authinformers.JWTAuthenticatorInformer
+	namespace         string
+	secrets           corev1informers.SecretInformer
+	configMaps        corev1informers.ConfigMapInformer
 	log               logr.Logger
 }
 
 // Sync implements controllerlib.Syncer.
 func (c *controller) Sync(ctx controllerlib.Context) error {
+	// Changes to Secrets and ConfigMaps are queued without the name of a JWTAuthenticator.
+	if ctx.Key.Name == "" {
+		jwtAuthenticators, err := c.jwtAuthenticators.Lister().List(labels.Everything())
+		if err != nil {
+			return fmt.Errorf("failed to list JWTAuthenticators: %w", err)
+		}
+		var errs []error
+		for _, obj := range jwtAuthenticators {
+			if err := c.syncJWTAuthenticator(ctx.Context, obj); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+
 	obj, err := c.jwtAuthenticators.Lister().Get(ctx.Key.Name)
 	if err != nil && errors.IsNotFound(err) {
 		c.log.Info("Sync() found that the JWTAuthenticator does not exist yet or was deleted")
@@ -101,10 +225,27 @@ func (c *controller) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("failed to get JWTAuthenticator %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
 	}
 
+	return c.syncJWTAuthenticator(ctx.Context, obj)
+}
+
+func (c *controller) syncJWTAuthenticator(ctx context.Context, obj *auth1alpha1.JWTAuthenticator) error {
 	cacheKey := authncache.Key{
 		APIGroup: auth1alpha1.GroupName,
 		Kind:     "JWTAuthenticator",
-		Name:     ctx.Key.Name,
+		Name:     obj.Name,
+	}
+
+	rootCAs, caBundle, err := pinnipedauthenticator.CABundle(obj.Spec.TLS, c.namespace, c.secrets.Lister(), c.configMaps.Lister())
+	if err != nil {
+		return fmt.Errorf("failed to build jwt authenticator: invalid TLS configuration: %w", err)
+	}
+
+	var expressions *claimexpressions.Expressions
+	if mappings := obj.Spec.ClaimMappings; mappings != nil {
+		expressions, err = claimexpressions.Compile(mappings.Username, mappings.Groups, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build jwt authenticator: invalid claim mappings: %w", err)
+		}
 	}
 
 	// If this authenticator already exists, then only recreate it if is different from the desired
@@ -115,8 +256,9 @@ func (c *controller) Sync(ctx controllerlib.Context) error {
 	if value := c.cache.Get(cacheKey); value != nil {
 		jwtAuthenticator := c.extractValueAsJWTAuthenticator(value)
 		if jwtAuthenticator != nil {
-			if reflect.DeepEqual(jwtAuthenticator.spec, &obj.Spec) {
+			if reflect.DeepEqual(jwtAuthenticator.spec, &obj.Spec) && bytes.Equal(jwtAuthenticator.caBundle, caBundle) {
 				c.log.WithValues("jwtAuthenticator", klog.KObj(obj), "issuer", obj.Spec.Issuer).Info("actual jwt authenticator and desired jwt authenticator are the same")
+				c.updateStatus(ctx, obj, issuerDiscoveredCondition(jwtAuthenticator.discoveredIssuer))
 				return nil
 			}
 			jwtAuthenticator.Close()
@@ -125,16 +267,37 @@ func (c *controller) Sync(ctx controllerlib.Context) error {
 
 	// Make a deep copy of the spec so we aren't storing pointers to something that the informer cache
 	// may mutate!
-	jwtAuthenticator, err := newJWTAuthenticator(obj.Spec.DeepCopy())
+	jwtAuthenticator, condition, err := c.newJWTAuthenticator(ctx, obj.Name, obj.Spec.DeepCopy(), rootCAs, caBundle, expressions)
 	if err != nil {
+		c.updateStatus(ctx, obj, condition)
 		return fmt.Errorf("failed to build jwt authenticator: %w", err)
 	}
 
 	c.cache.Store(cacheKey, jwtAuthenticator)
 	c.log.WithValues("jwtAuthenticator", klog.KObj(obj), "issuer", obj.Spec.Issuer).Info("added new jwt authenticator")
+	c.updateStatus(ctx, obj, condition)
 	return nil
 }
 
+func (c *controller) updateStatus(ctx context.Context, original *auth1alpha1.JWTAuthenticator, condition *auth1alpha1.Condition) {
+	log := c.log.WithValues("jwtAuthenticator", klog.KObj(original))
+	updated := original.DeepCopy()
+
+	_ = conditionsutil.MergeAuthenticatorConditions([]*auth1alpha1.Condition{condition}, original.Generation, &updated.Status.Conditions, log)
+
+	if equality.Semantic.DeepEqual(original, updated) {
+		return
+	}
+
+	_, err := c.client.
+		AuthenticationV1alpha1().
+		JWTAuthenticators().
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error(err, "failed to update status")
+	}
+}
+
 func (c *controller) extractValueAsJWTAuthenticator(value authncache.Value) *jwtAuthenticator {
 	jwtAuthenticator, ok := value.(*jwtAuthenticator)
 	if !ok {
@@ -148,29 +311,48 @@ func (c *controller) extractValueAsJWTAuthenticator(value authncache.Value) *jwt
 	return jwtAuthenticator
 }
 
-// newJWTAuthenticator creates a jwt authenticator from the provided spec.
-func newJWTAuthenticator(spec *auth1alpha1.JWTAuthenticatorSpec) (*jwtAuthenticator, error) {
-	rootCAs, _, err := pinnipedauthenticator.CABundle(spec.TLS)
-	if err != nil {
-		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
-	}
-
+// newJWTAuthenticator creates a jwt authenticator from the provided spec, CA bundle, and compiled claim mappings.
+// It also returns the condition which describes the discovery of the issuer, even when it returns an error.
+func (c *controller) newJWTAuthenticator(
+	syncCtx context.Context,
+	name string,
+	spec *auth1alpha1.JWTAuthenticatorSpec,
+	rootCAs *x509.CertPool,
+	caBundle []byte,
+	expressions *claimexpressions.Expressions,
+) (*jwtAuthenticator, *auth1alpha1.Condition, error) {
 	usernameClaim := spec.Claims.Username
 	if usernameClaim == "" {
 		usernameClaim = defaultUsernameClaim
 	}
+	if expressions.HasUsername() {
+		// The username is computed by the expression instead, but the Kube OIDC authenticator requires a
+		// username claim, so use the "iss" claim which it has already validated to be present.
+		usernameClaim = "iss"
+	}
 	groupsClaim := spec.Claims.Groups
 	if groupsClaim == "" {
 		groupsClaim = defaultGroupsClaim
 	}
-
-	// copied from Kube OIDC code
-	issuerURL, err := url.Parse(spec.Issuer)
-	if err != nil {
-		return nil, err
+	if expressions.HasGroups() {
+		groupsClaim = "" // the groups are computed by the expression instead
 	}
-	if issuerURL.Scheme != "https" {
-		return nil, fmt.Errorf("issuer (%q) has invalid scheme (%q), require 'https'", spec.Issuer, issuerURL.Scheme)
+
+	issuers := append([]string{spec.Issuer}, spec.IssuerAliases...)
+	for i, issuer := range issuers {
+		// copied from Kube OIDC code
+		issuerURL, err := url.Parse(issuer)
+		if err != nil {
+			return nil, errorCondition(reasonInvalidIssuer, err), err
+		}
+		if issuerURL.Scheme != "https" {
+			description := "issuer"
+			if i > 0 {
+				description = "issuer alias"
+			}
+			err := fmt.Errorf("%s (%q) has invalid scheme (%q), require 'https'", description, issuer, issuerURL.Scheme)
+			return nil, errorCondition(reasonInvalidIssuer, err), err
+		}
 	}
 
 	client := phttp.Default(rootCAs)
@@ -178,34 +360,106 @@ func newJWTAuthenticator(spec *auth1alpha1.JWTAuthenticatorSpec) (*jwtAuthentica
 
 	ctx := coreosoidc.ClientContext(context.Background(), client)
 
-	provider, err := coreosoidc.NewProvider(ctx, spec.Issuer)
-	if err != nil {
-		return nil, fmt.Errorf("could not initialize provider: %w", err)
+	// Try the discovery of the issuer first, and then of each alias, e.g. in case the OIDC provider has
+	// not been fully migrated to its new issuer URL yet.
+	var discoveredIssuer string
+	var provider *coreosoidc.Provider
+	var discoveryErrs []error
+	for _, issuer := range issuers {
+		var err error
+		provider, err = coreosoidc.NewProvider(ctx, issuer)
+		if err != nil {
+			discoveryErrs = append(discoveryErrs, err)
+			continue
+		}
+		discoveredIssuer = issuer
+		break
+	}
+	if discoveredIssuer == "" {
+		err := fmt.Errorf("could not initialize provider: %w", utilerrors.NewAggregate(discoveryErrs))
+		return nil, errorCondition(reasonDiscoveryFailed, err), err
 	}
 	providerJSON := &struct {
 		JWKSURL string `json:"jwks_uri"`
 	}{}
 	if err := provider.Claims(providerJSON); err != nil {
-		return nil, fmt.Errorf("could not get provider jwks_uri: %w", err) // should be impossible because coreosoidc.NewProvider validates this
+		err = fmt.Errorf("could not get provider jwks_uri: %w", err) // should be impossible because coreosoidc.NewProvider validates this
+		return nil, errorCondition(reasonDiscoveryFailed, err), err
 	}
 	if len(providerJSON.JWKSURL) == 0 {
-		return nil, fmt.Errorf("issuer %q does not have jwks_uri set", spec.Issuer)
-	}
-	oidcAuthenticator, err := oidc.New(oidc.Options{
-		IssuerURL:            spec.Issuer,
-		KeySet:               coreosoidc.NewRemoteKeySet(ctx, providerJSON.JWKSURL),
-		ClientID:             spec.Audience,
-		UsernameClaim:        usernameClaim,
-		GroupsClaim:          groupsClaim,
-		SupportedSigningAlgs: defaultSupportedSigningAlgos(),
-		Client:               client,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("could not initialize authenticator: %w", err)
+		err := fmt.Errorf("issuer %q does not have jwks_uri set", discoveredIssuer)
+		return nil, errorCondition(reasonDiscoveryFailed, err), err
+	}
+
+	// The keys are cached, so tokens can still be validated while the issuer is unavailable. They are fetched
+	// now, and then refreshed by the controller returned by NewJWKSRefresher. When fetching them fails now, the
+	// refresher will try again soon, so there is no need to fail here.
+	keySet := c.jwksCache.newKeySet(name, providerJSON.JWKSURL, client)
+	if err := keySet.refresh(syncCtx); err == nil {
+		keySet.scheduleRefresh(jitter(jwksRefreshInterval))
+	}
+
+	// The Kube OIDC authenticator only accepts a single issuer and a single audience, so there is one
+	// for each combination of them, which all share the keys from the discovered issuer.
+	audiences := append([]string{spec.Audience}, spec.AdditionalAudiences...)
+	var tokenAuthenticators []authenticator.Token
+	var closers []pinnipedauthenticator.Closer
+	for _, issuer := range issuers {
+		for _, audience := range audiences {
+			oidcAuthenticator, err := oidc.New(oidc.Options{
+				IssuerURL:            issuer,
+				KeySet:               keySet,
+				ClientID:             audience,
+				UsernameClaim:        usernameClaim,
+				GroupsClaim:          groupsClaim,
+				SupportedSigningAlgs: defaultSupportedSigningAlgos(),
+				Client:               client,
+			})
+			if err != nil {
+				for _, closer := range closers {
+					closer.Close()
+				}
+				err = fmt.Errorf("could not initialize authenticator: %w", err)
+				return nil, errorCondition(reasonInvalidAuthenticator, err), err
+			}
+			tokenAuthenticators = append(tokenAuthenticators, oidcAuthenticator)
+			closers = append(closers, oidcAuthenticator)
+		}
+	}
+
+	var tokenAuthenticator tokenAuthenticatorCloser = &unionAuthenticator{
+		Token:   union.New(tokenAuthenticators...),
+		closers: closers,
+	}
+	if expressions.HasUsername() || expressions.HasGroups() {
+		tokenAuthenticator = &claimMappingsAuthenticator{
+			tokenAuthenticatorCloser: tokenAuthenticator,
+			expressions:              expressions,
+		}
 	}
 
 	return &jwtAuthenticator{
-		tokenAuthenticatorCloser: oidcAuthenticator,
+		tokenAuthenticatorCloser: tokenAuthenticator,
 		spec:                     spec,
-	}, nil
+		caBundle:                 caBundle,
+		discoveredIssuer:         discoveredIssuer,
+	}, issuerDiscoveredCondition(discoveredIssuer), nil
+}
+
+func issuerDiscoveredCondition(issuer string) *auth1alpha1.Condition {
+	return &auth1alpha1.Condition{
+		Type:    typeIssuerDiscovered,
+		Status:  auth1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: fmt.Sprintf("discovered issuer %s", issuer),
+	}
+}
+
+func errorCondition(reason string, err error) *auth1alpha1.Condition {
+	return &auth1alpha1.Condition{
+		Type:    typeIssuerDiscovered,
+		Status:  auth1alpha1.ConditionFalse,
+		Reason:  reason,
+		Message: err.Error(),
+	}
 }
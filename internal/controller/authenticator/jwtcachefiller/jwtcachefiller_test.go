@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package jwtcachefiller
@@ -13,6 +13,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -23,21 +24,27 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/square/go-jose.v2"
 	"gopkg.in/square/go-jose.v2/jwt"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
 
 	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/claimexpressions"
 	"go.pinniped.dev/internal/controller/authenticator/authncache"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/mocks/mocktokenauthenticatorcloser"
 	"go.pinniped.dev/internal/testutil"
 	"go.pinniped.dev/internal/testutil/testlogger"
+	"go.pinniped.dev/internal/testutil/tlsassertions"
 	"go.pinniped.dev/internal/testutil/tlsserver"
 )
 
@@ -45,6 +52,7 @@ func TestController(t *testing.T) {
 	t.Parallel()
 
 	const (
+		testNamespace       = "some-namespace"
 		goodECSigningKeyID  = "some-ec-key-id"
 		goodRSASigningKeyID = "some-rsa-key-id"
 		goodAudience        = "some-audience"
@@ -146,6 +154,14 @@ func TestController(t *testing.T) {
 
 	goodIssuer := server.URL
 
+	wantIssuerDiscoveredLog := `jwtcachefiller-controller "level"=0 "msg"="updated condition" "jwtAuthenticator"={"name":"test-name"} "message"="discovered issuer ` + goodIssuer + `" "reason"="Success" "status"="True" "type"="IssuerDiscovered"`
+	wantIssuerDiscoveredConditions := []auth1alpha1.Condition{{
+		Type:    "IssuerDiscovered",
+		Status:  auth1alpha1.ConditionTrue,
+		Reason:  "Success",
+		Message: "discovered issuer " + goodIssuer,
+	}}
+
 	someJWTAuthenticatorSpec := &auth1alpha1.JWTAuthenticatorSpec{
 		Issuer:   goodIssuer,
 		Audience: goodAudience,
@@ -172,6 +188,18 @@ func TestController(t *testing.T) {
 		Audience: goodAudience,
 		TLS:      &auth1alpha1.TLSSpec{CertificateAuthorityData: "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSURVVENDQWptZ0F3SUJBZ0lWQUpzNStTbVRtaTJXeUI0bGJJRXBXaUs5a1RkUE1BMEdDU3FHU0liM0RRRUIKQ3dVQU1COHhDekFKQmdOVkJBWVRBbFZUTVJBd0RnWURWUVFLREFkUWFYWnZkR0ZzTUI0WERUSXdNRFV3TkRFMgpNamMxT0ZvWERUSTBNRFV3TlRFMk1qYzFPRm93SHpFTE1Ba0dBMVVFQmhNQ1ZWTXhFREFPQmdOVkJBb01CMUJwCmRtOTBZV3d3Z2dFaU1BMEdDU3FHU0liM0RRRUJBUVVBQTRJQkR3QXdnZ0VLQW9JQkFRRERZWmZvWGR4Z2NXTEMKZEJtbHB5a0tBaG9JMlBuUWtsVFNXMno1cGcwaXJjOGFRL1E3MXZzMTRZYStmdWtFTGlvOTRZYWw4R01DdVFrbApMZ3AvUEE5N1VYelhQNDBpK25iNXcwRGpwWWd2dU9KQXJXMno2MFRnWE5NSFh3VHk4ME1SZEhpUFVWZ0VZd0JpCmtkNThzdEFVS1Y1MnBQTU1reTJjNy9BcFhJNmRXR2xjalUvaFBsNmtpRzZ5dEw2REtGYjJQRWV3MmdJM3pHZ2IKOFVVbnA1V05DZDd2WjNVY0ZHNXlsZEd3aGc3cnZ4U1ZLWi9WOEhCMGJmbjlxamlrSVcxWFM4dzdpUUNlQmdQMApYZWhKZmVITlZJaTJtZlczNlVQbWpMdnVKaGpqNDIrdFBQWndvdDkzdWtlcEgvbWpHcFJEVm9wamJyWGlpTUYrCkYxdnlPNGMxQWdNQkFBR2pnWU13Z1lBd0hRWURWUjBPQkJZRUZNTWJpSXFhdVkwajRVWWphWDl0bDJzby9LQ1IKTUI4R0ExVWRJd1FZTUJhQUZNTWJpSXFhdVkwajRVWWphWDl0bDJzby9LQ1JNQjBHQTFVZEpRUVdNQlFHQ0NzRwpBUVVGQndNQ0JnZ3JCZ0VGQlFjREFUQVBCZ05WSFJNQkFmOEVCVEFEQVFIL01BNEdBMVVkRHdFQi93UUVBd0lCCkJqQU5CZ2txaGtpRzl3MEJBUXNGQUFPQ0FRRUFYbEh4M2tIMDZwY2NDTDlEVE5qTnBCYnlVSytGd2R6T2IwWFYKcmpNaGtxdHVmdEpUUnR5T3hKZ0ZKNXhUR3pCdEtKamcrVU1pczBOV0t0VDBNWThVMU45U2c5SDl0RFpHRHBjVQpxMlVRU0Y4dXRQMVR3dnJIUzIrdzB2MUoxdHgrTEFiU0lmWmJCV0xXQ21EODUzRlVoWlFZekkvYXpFM28vd0p1CmlPUklMdUpNUk5vNlBXY3VLZmRFVkhaS1RTWnk3a25FcHNidGtsN3EwRE91eUFWdG9HVnlkb3VUR0FOdFhXK2YKczNUSTJjKzErZXg3L2RZOEJGQTFzNWFUOG5vZnU3T1RTTzdiS1kzSkRBUHZOeFQzKzVZUXJwNGR1Nmh0YUFMbAppOHNaRkhidmxpd2EzdlhxL3p1Y2JEaHEzQzBhZnAzV2ZwRGxwSlpvLy9QUUFKaTZLQT09Ci0tLS0tRU5EIENFUlRJRklDQVRFLS0tLS0K"},
 	}
+	additionalAudiencesJWTAuthenticatorSpec := &auth1alpha1.JWTAuthenticatorSpec{
+		Issuer:              goodIssuer,
+		Audience:            "some-other-audience",
+		AdditionalAudiences: []string{goodAudience},
+		TLS:                 tlsSpecFromTLSConfig(server.TLS),
+	}
+	issuerAliasJWTAuthenticatorSpec := &auth1alpha1.JWTAuthenticatorSpec{
+		Issuer:        goodIssuer + "/migrated",
+		IssuerAliases: []string{goodIssuer},
+		Audience:      goodAudience,
+		TLS:           tlsSpecFromTLSConfig(server.TLS),
+	}
 	missingTLSJWTAuthenticatorSpec := &auth1alpha1.JWTAuthenticatorSpec{
 		Issuer:   goodIssuer,
 		Audience: goodAudience,
@@ -181,15 +209,34 @@ func TestController(t *testing.T) {
 		Audience: goodAudience,
 		TLS:      &auth1alpha1.TLSSpec{CertificateAuthorityData: "invalid base64-encoded data"},
 	}
+	configMapCAJWTAuthenticatorSpec := &auth1alpha1.JWTAuthenticatorSpec{
+		Issuer:   goodIssuer,
+		Audience: goodAudience,
+		TLS: &auth1alpha1.TLSSpec{
+			CertificateAuthorityDataSource: &auth1alpha1.CertificateAuthorityDataSourceSpec{
+				Kind: auth1alpha1.CertificateAuthorityDataSourceKindConfigMap,
+				Name: "some-ca-configmap",
+				Key:  "ca.crt",
+			},
+		},
+	}
+	caBundleFromSpec, err := base64.StdEncoding.DecodeString(someJWTAuthenticatorSpec.TLS.CertificateAuthorityData)
+	require.NoError(t, err)
+	caConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-ca-configmap", Namespace: testNamespace},
+		Data:       map[string]string{"ca.crt": string(caBundleFromSpec)},
+	}
 
 	tests := []struct {
 		name                             string
 		cache                            func(*testing.T, *authncache.Cache, bool)
 		syncKey                          controllerlib.Key
 		jwtAuthenticators                []runtime.Object
+		kubeObjects                      []runtime.Object
 		wantClose                        bool
 		wantErr                          testutil.RequireErrorStringFunc
 		wantLogs                         []string
+		wantConditions                   []auth1alpha1.Condition
 		wantCacheEntries                 int
 		wantUsernameClaim                string
 		wantGroupsClaim                  string
@@ -215,10 +262,150 @@ func TestController(t *testing.T) {
 			},
 			wantLogs: []string{
 				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
 			},
+			wantConditions:                   wantIssuerDiscoveredConditions,
 			wantCacheEntries:                 1,
 			runTestsOnResultingAuthenticator: true,
 		},
+		{
+			name:    "valid jwt authenticator with additional audiences",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: *additionalAudiencesJWTAuthenticatorSpec,
+				},
+			},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
+			},
+			wantConditions:                   wantIssuerDiscoveredConditions,
+			wantCacheEntries:                 1,
+			runTestsOnResultingAuthenticator: true,
+		},
+		{
+			name:    "valid jwt authenticator with an issuer alias when the discovery of the issuer fails",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: *issuerAliasJWTAuthenticatorSpec,
+				},
+			},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `/migrated" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
+			},
+			wantConditions:                   wantIssuerDiscoveredConditions,
+			wantCacheEntries:                 1,
+			runTestsOnResultingAuthenticator: true,
+		},
+		{
+			name:    "jwt authenticator when the discovery of the issuer and of every issuer alias fails",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.JWTAuthenticatorSpec{
+						Issuer:        goodIssuer + "/migrated",
+						IssuerAliases: []string{goodIssuer + "/also-migrated"},
+						Audience:      goodAudience,
+						TLS:           tlsSpecFromTLSConfig(server.TLS),
+					},
+				},
+			},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="updated condition" "jwtAuthenticator"={"name":"test-name"} "message"="could not initialize provider: 404 Not Found: 404 page not found\n" "reason"="DiscoveryFailed" "status"="False" "type"="IssuerDiscovered"`,
+			},
+			wantConditions: []auth1alpha1.Condition{{
+				Type:    "IssuerDiscovered",
+				Status:  auth1alpha1.ConditionFalse,
+				Reason:  "DiscoveryFailed",
+				Message: "could not initialize provider: 404 Not Found: 404 page not found\n",
+			}},
+			wantErr: testutil.WantExactErrorString("failed to build jwt authenticator: could not initialize provider: 404 Not Found: 404 page not found\n"),
+		},
+		{
+			name:    "jwt authenticator with an invalid issuer alias",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.JWTAuthenticatorSpec{
+						Issuer:        goodIssuer,
+						IssuerAliases: []string{"http://insecure.example.com"},
+						Audience:      goodAudience,
+						TLS:           tlsSpecFromTLSConfig(server.TLS),
+					},
+				},
+			},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="updated condition" "jwtAuthenticator"={"name":"test-name"} "message"="issuer alias (\"http://insecure.example.com\") has invalid scheme (\"http\"), require 'https'" "reason"="InvalidIssuer" "status"="False" "type"="IssuerDiscovered"`,
+			},
+			wantConditions: []auth1alpha1.Condition{{
+				Type:    "IssuerDiscovered",
+				Status:  auth1alpha1.ConditionFalse,
+				Reason:  "InvalidIssuer",
+				Message: `issuer alias ("http://insecure.example.com") has invalid scheme ("http"), require 'https'`,
+			}},
+			wantErr: testutil.WantExactErrorString(`failed to build jwt authenticator: issuer alias ("http://insecure.example.com") has invalid scheme ("http"), require 'https'`),
+		},
+		{
+			name:    "valid jwt authenticator with claim mappings",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.JWTAuthenticatorSpec{
+						Issuer:   goodIssuer,
+						Audience: goodAudience,
+						TLS:      tlsSpecFromTLSConfig(server.TLS),
+						ClaimMappings: &auth1alpha1.JWTClaimMappings{
+							Username: `"corp:" + claims.username`,
+							Groups:   `claims.realm.roles`,
+						},
+					},
+				},
+			},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
+			},
+			wantConditions:   wantIssuerDiscoveredConditions,
+			wantCacheEntries: 1,
+		},
+		{
+			name:    "jwt authenticator with invalid claim mappings",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.JWTAuthenticatorSpec{
+						Issuer:   goodIssuer,
+						Audience: goodAudience,
+						TLS:      tlsSpecFromTLSConfig(server.TLS),
+						ClaimMappings: &auth1alpha1.JWTClaimMappings{
+							Username: `claims.`,
+						},
+					},
+				},
+			},
+			wantErr: testutil.WantMatchingErrorString(`^failed to build jwt authenticator: invalid claim mappings: could not compile username expression: `),
+		},
 		{
 			name:    "valid jwt authenticator with custom username claim",
 			syncKey: controllerlib.Key{Name: "test-name"},
@@ -232,6 +419,7 @@ func TestController(t *testing.T) {
 			},
 			wantLogs: []string{
 				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
 			},
 			wantCacheEntries:                 1,
 			wantUsernameClaim:                someJWTAuthenticatorSpecWithUsernameClaim.Claims.Username,
@@ -250,6 +438,7 @@ func TestController(t *testing.T) {
 			},
 			wantLogs: []string{
 				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
 			},
 			wantCacheEntries:                 1,
 			wantGroupsClaim:                  someJWTAuthenticatorSpecWithGroupsClaim.Claims.Groups,
@@ -279,6 +468,7 @@ func TestController(t *testing.T) {
 			},
 			wantLogs: []string{
 				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
 			},
 			wantCacheEntries:                 1,
 			runTestsOnResultingAuthenticator: true,
@@ -307,6 +497,7 @@ func TestController(t *testing.T) {
 			},
 			wantLogs: []string{
 				`jwtcachefiller-controller "level"=0 "msg"="actual jwt authenticator and desired jwt authenticator are the same" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
 			},
 			wantCacheEntries:                 1,
 			runTestsOnResultingAuthenticator: false, // skip the tests because the authenticator left in the cache is the mock version that was added above
@@ -335,10 +526,72 @@ func TestController(t *testing.T) {
 			wantLogs: []string{
 				`jwtcachefiller-controller "level"=0 "msg"="wrong JWT authenticator type in cache" "actualType"="struct { authenticator.Token }"`,
 				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
 			},
 			wantCacheEntries:                 1,
 			runTestsOnResultingAuthenticator: true,
 		},
+		{
+			name:    "valid jwt authenticator with CA from a ConfigMap",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: *configMapCAJWTAuthenticatorSpec,
+				},
+			},
+			kubeObjects: []runtime.Object{caConfigMap},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
+			},
+			wantCacheEntries:                 1,
+			runTestsOnResultingAuthenticator: true,
+		},
+		{
+			name: "changing the CA bundle in a ConfigMap closes previous instance of every jwt authenticator which uses it",
+			cache: func(t *testing.T, cache *authncache.Cache, wantClose bool) {
+				cache.Store(
+					authncache.Key{
+						Name:     "test-name",
+						Kind:     "JWTAuthenticator",
+						APIGroup: auth1alpha1.SchemeGroupVersion.Group,
+					},
+					newCacheValue(t, *configMapCAJWTAuthenticatorSpec, wantClose),
+				)
+			},
+			wantClose: true,
+			syncKey:   controllerlib.Key{}, // changes to Secrets and ConfigMaps are queued without a name
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: *configMapCAJWTAuthenticatorSpec,
+				},
+			},
+			kubeObjects: []runtime.Object{caConfigMap},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="added new jwt authenticator" "issuer"="` + goodIssuer + `" "jwtAuthenticator"={"name":"test-name"}`,
+				wantIssuerDiscoveredLog,
+			},
+			wantCacheEntries: 1,
+		},
+		{
+			name:    "jwt authenticator CA ConfigMap does not exist",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			jwtAuthenticators: []runtime.Object{
+				&auth1alpha1.JWTAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: *configMapCAJWTAuthenticatorSpec,
+				},
+			},
+			wantErr: testutil.WantExactErrorString(`failed to build jwt authenticator: invalid TLS configuration: certificateAuthorityDataSource is invalid: failed to get configmap "some-ca-configmap": configmap "some-ca-configmap" not found`),
+		},
 		{
 			name:    "valid jwt authenticator without CA",
 			syncKey: controllerlib.Key{Name: "test-name"},
@@ -350,6 +603,9 @@ func TestController(t *testing.T) {
 					Spec: *missingTLSJWTAuthenticatorSpec,
 				},
 			},
+			wantLogs: []string{
+				`jwtcachefiller-controller "level"=0 "msg"="updated condition" "jwtAuthenticator"={"name":"test-name"} "message"="could not initialize provider: Get \"` + goodIssuer + `/.well-known/openid-configuration\": ` + tlsassertions.GetTLSErrorPrefix() + `x509: certificate signed by unknown authority" "reason"="DiscoveryFailed" "status"="False" "type"="IssuerDiscovered"`,
+			},
 			wantErr: testutil.WantX509UntrustedCertErrorString(`failed to build jwt authenticator: could not initialize provider: Get "`+goodIssuer+`/.well-known/openid-configuration": %s`, "Acme Co"),
 		},
 		{
@@ -381,12 +637,24 @@ func TestController(t *testing.T) {
 				tt.cache(t, cache, tt.wantClose)
 			}
 
-			controller := New(cache, informers.Authentication().V1alpha1().JWTAuthenticators(), testLog.Logger)
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(tt.kubeObjects...), 0)
+
+			controller := New(
+				cache,
+				NewJWKSCache(clock.RealClock{}),
+				fakeClient,
+				informers.Authentication().V1alpha1().JWTAuthenticators(),
+				testNamespace,
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				testLog.Logger,
+			)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
 			informers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
 			controllerlib.TestRunSynchronously(t, controller)
 
 			syncCtx := controllerlib.Context{Context: ctx, Key: tt.syncKey}
@@ -399,6 +667,15 @@ func TestController(t *testing.T) {
 			require.Equal(t, tt.wantLogs, testLog.Lines())
 			require.Equal(t, tt.wantCacheEntries, len(cache.Keys()))
 
+			if tt.wantConditions != nil {
+				actual, err := fakeClient.AuthenticationV1alpha1().JWTAuthenticators().Get(ctx, syncCtx.Key.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				for i := range actual.Status.Conditions {
+					actual.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+				}
+				require.Equal(t, tt.wantConditions, actual.Status.Conditions)
+			}
+
 			if !tt.runTestsOnResultingAuthenticator {
 				return // end of test unless we wanted to run tests on the resulting authenticator from the cache
 			}
@@ -503,6 +780,99 @@ func TestController(t *testing.T) {
 	}
 }
 
+func TestClaimMappingsAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: signingKey}, (&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(t, err)
+	token, err := jwt.Signed(sig).Claims(map[string]interface{}{
+		"username": "pinny",
+		"realm":    map[string]interface{}{"roles": []string{"k8s-admins", "other", "k8s-viewers"}},
+	}).CompactSerialize()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		username         string
+		groups           string
+		delegateResponse *authenticator.Response
+		delegateOK       bool
+		delegateErr      error
+		wantResponse     *authenticator.Response
+		wantOK           bool
+		wantErr          string
+	}{
+		{
+			name:     "computes the username and groups",
+			username: `"corp:" + claims.username`,
+			groups:   `claims.realm.roles.filter(r, r.startsWith("k8s-"))`,
+			delegateResponse: &authenticator.Response{
+				Audiences: authenticator.Audiences{"some-audience"},
+				User:      &user.DefaultInfo{Name: "https://some-issuer", Groups: []string{"some-group"}},
+			},
+			delegateOK: true,
+			wantResponse: &authenticator.Response{
+				Audiences: authenticator.Audiences{"some-audience"},
+				User:      &user.DefaultInfo{Name: "corp:pinny", Groups: []string{"k8s-admins", "k8s-viewers"}},
+			},
+			wantOK: true,
+		},
+		{
+			name:     "computes only the username",
+			username: `claims.username`,
+			delegateResponse: &authenticator.Response{
+				User: &user.DefaultInfo{Name: "https://some-issuer", Groups: []string{"some-group"}},
+			},
+			delegateOK: true,
+			wantResponse: &authenticator.Response{
+				User: &user.DefaultInfo{Name: "pinny", Groups: []string{"some-group"}},
+			},
+			wantOK: true,
+		},
+		{
+			name:        "the token is rejected",
+			username:    `claims.username`,
+			delegateErr: errors.New("some error"),
+			wantErr:     "some error",
+		},
+		{
+			name:   "the expression fails",
+			groups: `claims.missing`,
+			delegateResponse: &authenticator.Response{
+				User: &user.DefaultInfo{Name: "some-user"},
+			},
+			delegateOK: true,
+			wantErr:    "claim mappings: could not evaluate groups expression: no such key: missing",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			delegate := mocktokenauthenticatorcloser.NewMockTokenAuthenticatorCloser(ctrl)
+			delegate.EXPECT().AuthenticateToken(gomock.Any(), token).Return(tt.delegateResponse, tt.delegateOK, tt.delegateErr)
+
+			expressions, err := claimexpressions.Compile(tt.username, tt.groups, nil)
+			require.NoError(t, err)
+
+			rsp, ok, err := (&claimMappingsAuthenticator{tokenAuthenticatorCloser: delegate, expressions: expressions}).
+				AuthenticateToken(context.Background(), token)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantResponse, rsp)
+			require.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
 // isNotInitialized checks if the error is the internally-defined "oidc: authenticator not initialized" error from
 // the underlying OIDC authenticator or "verifier is not initialized" from verifying distributed claims,
 // both of which are initialized asynchronously.
@@ -772,8 +1142,17 @@ func newCacheValue(t *testing.T, spec auth1alpha1.JWTAuthenticatorSpec, wantClos
 	}
 	tokenAuthenticatorCloser.EXPECT().Close().Times(wantCloses)
 
+	var caBundle []byte
+	if spec.TLS != nil {
+		var err error
+		caBundle, err = base64.StdEncoding.DecodeString(spec.TLS.CertificateAuthorityData)
+		require.NoError(t, err)
+	}
+
 	return &jwtAuthenticator{
 		tokenAuthenticatorCloser: tokenAuthenticatorCloser,
 		spec:                     &spec,
+		caBundle:                 caBundle,
+		discoveredIssuer:         spec.Issuer,
 	}
 }
@@ -0,0 +1,150 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package satokencachefiller implements a controller for filling an authncache.Cache with each
+// added/updated ServiceAccountTokenAuthenticator.
+package satokencachefiller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	"k8s.io/klog/v2"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	authinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/authentication/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/controllerlib"
+)
+
+// New instantiates a new controllerlib.Controller which will populate the provided authncache.Cache.
+func New(
+	cache *authncache.Cache,
+	serviceAccountTokenAuthenticators authinformers.ServiceAccountTokenAuthenticatorInformer,
+	tokenReviews authenticationv1client.TokenReviewInterface,
+	log logr.Logger,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "satokencachefiller-controller",
+			Syncer: &controller{
+				cache:                             cache,
+				serviceAccountTokenAuthenticators: serviceAccountTokenAuthenticators,
+				tokenReviews:                      tokenReviews,
+				log:                               log.WithName("satokencachefiller-controller"),
+			},
+		},
+		controllerlib.WithInformer(
+			serviceAccountTokenAuthenticators,
+			pinnipedcontroller.MatchAnythingFilter(nil), // nil parent func is fine because each event is distinct
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+type controller struct {
+	cache                             *authncache.Cache
+	serviceAccountTokenAuthenticators authinformers.ServiceAccountTokenAuthenticatorInformer
+	tokenReviews                      authenticationv1client.TokenReviewInterface
+	log                               logr.Logger
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *controller) Sync(ctx controllerlib.Context) error {
+	obj, err := c.serviceAccountTokenAuthenticators.Lister().Get(ctx.Key.Name)
+	if err != nil && errors.IsNotFound(err) {
+		c.log.Info("Sync() found that the ServiceAccountTokenAuthenticator does not exist yet or was deleted")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ServiceAccountTokenAuthenticator %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
+	}
+
+	spec := obj.Spec.DeepCopy()
+	if spec.Audience == "" {
+		return fmt.Errorf("failed to build service account token authenticator: audience must not be empty")
+	}
+
+	c.cache.Store(authncache.Key{
+		APIGroup: auth1alpha1.GroupName,
+		Kind:     "ServiceAccountTokenAuthenticator",
+		Name:     obj.Name,
+	}, &serviceAccountTokenAuthenticator{
+		tokenReviews:      c.tokenReviews,
+		audience:          spec.Audience,
+		allowedNamespaces: sets.NewString(spec.AllowedNamespaces...),
+	})
+	c.log.WithValues("serviceAccountTokenAuthenticator", klog.KObj(obj), "audience", spec.Audience).Info("added new service account token authenticator")
+	return nil
+}
+
+// serviceAccountTokenAuthenticator authenticates service account tokens which are bound to an audience by
+// submitting them to the Kubernetes API in a TokenReview.
+type serviceAccountTokenAuthenticator struct {
+	tokenReviews      authenticationv1client.TokenReviewInterface
+	audience          string
+	allowedNamespaces sets.String
+}
+
+var _ authenticator.Token = &serviceAccountTokenAuthenticator{}
+
+func (a *serviceAccountTokenAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	tokenReview, err := a.tokenReviews.Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: []string{a.audience},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, false, fmt.Errorf("could not review token: %w", err)
+	}
+
+	status := tokenReview.Status
+	if !status.Authenticated {
+		return nil, false, nil
+	}
+
+	// The Kubernetes API server returns those of the requested audiences to which the token is bound. Do not trust
+	// a TokenReview which does not confirm the audience, e.g. one from an API server which ignored the audiences.
+	if !sets.NewString(status.Audiences...).Has(a.audience) {
+		return nil, false, nil
+	}
+
+	// The Kubernetes API server may also accept other kinds of tokens, but only service account tokens are
+	// exchanged by this authenticator.
+	namespace, _, err := serviceaccount.SplitUsername(status.User.Username)
+	if err != nil {
+		return nil, false, nil
+	}
+	if a.allowedNamespaces.Len() > 0 && !a.allowedNamespaces.Has(namespace) {
+		return nil, false, nil
+	}
+
+	var extra map[string][]string
+	if len(status.User.Extra) > 0 {
+		extra = make(map[string][]string, len(status.User.Extra))
+		for k, v := range status.User.Extra {
+			extra[k] = v
+		}
+	}
+
+	return &authenticator.Response{
+		Audiences: status.Audiences,
+		User: &user.DefaultInfo{
+			Name:   status.User.Username,
+			UID:    status.User.UID,
+			Groups: status.User.Groups,
+			Extra:  extra,
+		},
+	}, true, nil
+}
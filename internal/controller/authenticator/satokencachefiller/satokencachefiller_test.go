@@ -0,0 +1,238 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package satokencachefiller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/testutil/testlogger"
+)
+
+func TestController(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                              string
+		syncKey                           controllerlib.Key
+		serviceAccountTokenAuthenticators []runtime.Object
+		wantErr                           string
+		wantLogs                          []string
+		wantCacheEntries                  int
+	}{
+		{
+			name:    "not found",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			wantLogs: []string{
+				`satokencachefiller-controller "level"=0 "msg"="Sync() found that the ServiceAccountTokenAuthenticator does not exist yet or was deleted"`,
+			},
+		},
+		{
+			name:    "missing audience",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			serviceAccountTokenAuthenticators: []runtime.Object{
+				&auth1alpha1.ServiceAccountTokenAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+				},
+			},
+			wantErr: "failed to build service account token authenticator: audience must not be empty",
+		},
+		{
+			name:    "valid service account token authenticator",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			serviceAccountTokenAuthenticators: []runtime.Object{
+				&auth1alpha1.ServiceAccountTokenAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+					Spec:       auth1alpha1.ServiceAccountTokenAuthenticatorSpec{Audience: "some-audience"},
+				},
+			},
+			wantLogs: []string{
+				`satokencachefiller-controller "level"=0 "msg"="added new service account token authenticator" "audience"="some-audience" "serviceAccountTokenAuthenticator"={"name":"test-name"}`,
+			},
+			wantCacheEntries: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClient := pinnipedfake.NewSimpleClientset(tt.serviceAccountTokenAuthenticators...)
+			informers := pinnipedinformers.NewSharedInformerFactory(fakeClient, 0)
+			cache := authncache.New()
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // old test with lots of log statements
+
+			controller := New(
+				cache,
+				informers.Authentication().V1alpha1().ServiceAccountTokenAuthenticators(),
+				kubefake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
+				testLog.Logger,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			informers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: tt.syncKey}
+
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantLogs, testLog.Lines())
+			require.Equal(t, tt.wantCacheEntries, len(cache.Keys()))
+		})
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	t.Parallel()
+
+	serviceAccountUser := authenticationv1.UserInfo{
+		Username: "system:serviceaccount:some-namespace:some-service-account",
+		UID:      "some-uid",
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:some-namespace", "system:authenticated"},
+		Extra: map[string]authenticationv1.ExtraValue{
+			"authentication.kubernetes.io/pod-name": {"some-pod"},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		allowedNamespaces []string
+		status            authenticationv1.TokenReviewStatus
+		reviewErr         error
+		wantResponse      *authenticator.Response
+		wantErr           string
+	}{
+		{
+			name:   "authenticated service account token",
+			status: authenticationv1.TokenReviewStatus{Authenticated: true, Audiences: []string{"some-audience"}, User: serviceAccountUser},
+			wantResponse: &authenticator.Response{
+				Audiences: []string{"some-audience"},
+				User: &user.DefaultInfo{
+					Name:   "system:serviceaccount:some-namespace:some-service-account",
+					UID:    "some-uid",
+					Groups: []string{"system:serviceaccounts", "system:serviceaccounts:some-namespace", "system:authenticated"},
+					Extra:  map[string][]string{"authentication.kubernetes.io/pod-name": {"some-pod"}},
+				},
+			},
+		},
+		{
+			name:              "service account in an allowed namespace",
+			allowedNamespaces: []string{"other-namespace", "some-namespace"},
+			status:            authenticationv1.TokenReviewStatus{Authenticated: true, Audiences: []string{"some-audience"}, User: serviceAccountUser},
+			wantResponse: &authenticator.Response{
+				Audiences: []string{"some-audience"},
+				User: &user.DefaultInfo{
+					Name:   "system:serviceaccount:some-namespace:some-service-account",
+					UID:    "some-uid",
+					Groups: []string{"system:serviceaccounts", "system:serviceaccounts:some-namespace", "system:authenticated"},
+					Extra:  map[string][]string{"authentication.kubernetes.io/pod-name": {"some-pod"}},
+				},
+			},
+		},
+		{
+			name:              "service account in a namespace which is not allowed",
+			allowedNamespaces: []string{"other-namespace"},
+			status:            authenticationv1.TokenReviewStatus{Authenticated: true, Audiences: []string{"some-audience"}, User: serviceAccountUser},
+		},
+		{
+			name:   "unauthenticated token",
+			status: authenticationv1.TokenReviewStatus{Authenticated: false, Error: "some error"},
+		},
+		{
+			name:   "token which is not bound to the audience",
+			status: authenticationv1.TokenReviewStatus{Authenticated: true, User: serviceAccountUser},
+		},
+		{
+			name: "token of a user which is not a service account",
+			status: authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				Audiences:     []string{"some-audience"},
+				User:          authenticationv1.UserInfo{Username: "some-user"},
+			},
+		},
+		{
+			name:      "token review fails",
+			reviewErr: errors.New("some API error"),
+			wantErr:   "could not review token: some API error",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kubeClient := kubefake.NewSimpleClientset()
+			kubeClient.PrependReactor("create", "tokenreviews", func(action coretesting.Action) (bool, runtime.Object, error) {
+				tokenReview := action.(coretesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+				require.Equal(t, authenticationv1.TokenReviewSpec{
+					Token:     "some-token",
+					Audiences: []string{"some-audience"},
+				}, tokenReview.Spec)
+				if tt.reviewErr != nil {
+					return true, nil, tt.reviewErr
+				}
+				tokenReview = tokenReview.DeepCopy()
+				tokenReview.Status = tt.status
+				return true, tokenReview, nil
+			})
+
+			informers := pinnipedinformers.NewSharedInformerFactory(pinnipedfake.NewSimpleClientset(
+				&auth1alpha1.ServiceAccountTokenAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+					Spec: auth1alpha1.ServiceAccountTokenAuthenticatorSpec{
+						Audience:          "some-audience",
+						AllowedNamespaces: tt.allowedNamespaces,
+					},
+				},
+			), 0)
+			cache := authncache.New()
+			controller := New(
+				cache,
+				informers.Authentication().V1alpha1().ServiceAccountTokenAuthenticators(),
+				kubeClient.AuthenticationV1().TokenReviews(),
+				testlogger.NewLegacy(t).Logger, //nolint:staticcheck  // old test with lots of log statements
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			informers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+			require.NoError(t, controllerlib.TestSync(t, controller, controllerlib.Context{Context: ctx, Key: controllerlib.Key{Name: "test-name"}}))
+
+			value := cache.Get(authncache.Key{APIGroup: auth1alpha1.GroupName, Kind: "ServiceAccountTokenAuthenticator", Name: "test-name"})
+			require.NotNil(t, value)
+
+			resp, authenticated, err := value.AuthenticateToken(ctx, "some-token")
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantResponse != nil, authenticated)
+			require.Equal(t, tt.wantResponse, resp)
+		})
+	}
+}
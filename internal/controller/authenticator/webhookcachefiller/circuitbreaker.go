@@ -0,0 +1,192 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/utils/clock"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerOpenSeconds      = 30
+
+	maxCircuitBreakerFailureThreshold = 100
+	maxCircuitBreakerOpenSeconds      = 3600
+)
+
+type circuitBreakerState int
+
+const (
+	// circuitBreakerClosed means that the webhook is called.
+	circuitBreakerClosed circuitBreakerState = iota
+	// circuitBreakerOpen means that the webhook failed repeatedly, so it is not called.
+	circuitBreakerOpen
+	// circuitBreakerHalfOpen means that the webhook is called for a single token to check whether it has recovered.
+	circuitBreakerHalfOpen
+)
+
+var errCircuitBreakerOpen = errors.New("webhook is unavailable: circuit breaker is open")
+
+// CircuitBreakers holds the circuit breaker of each WebhookAuthenticator which configures one. It is shared by the
+// controller returned by New, which adds a circuit breaker whenever it creates an authenticator, and the controller
+// returned by NewCircuitBreakerStatus, which reports the state of each circuit breaker.
+type CircuitBreakers struct {
+	lock     sync.RWMutex
+	breakers map[string]*circuitBreaker
+}
+
+// NewCircuitBreakers returns an empty CircuitBreakers.
+func NewCircuitBreakers() *CircuitBreakers {
+	return &CircuitBreakers{breakers: map[string]*circuitBreaker{}}
+}
+
+// store sets the circuit breaker of the WebhookAuthenticator with the given name, replacing any previous one.
+func (b *CircuitBreakers) store(name string, breaker *circuitBreaker) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.breakers[name] = breaker
+}
+
+func (b *CircuitBreakers) get(name string) *circuitBreaker {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.breakers[name]
+}
+
+// forget removes the circuit breaker of the WebhookAuthenticator with the given name, e.g. because it no longer
+// configures one.
+func (b *CircuitBreakers) forget(name string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.breakers, name)
+}
+
+// retain forgets the circuit breakers of the WebhookAuthenticators whose names are not given, e.g. because they
+// were deleted.
+func (b *CircuitBreakers) retain(names sets.Set[string]) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for name := range b.breakers {
+		if !names.Has(name) {
+			delete(b.breakers, name)
+		}
+	}
+}
+
+// newCircuitBreaker wraps the delegate with a circuit breaker which is configured by the given spec.
+func newCircuitBreaker(
+	delegate authenticator.Token,
+	spec *auth1alpha1.WebhookAuthenticatorCircuitBreaker,
+	clock clock.PassiveClock,
+) (*circuitBreaker, error) {
+	failureThreshold := valueOrDefault(spec.FailureThreshold, defaultCircuitBreakerFailureThreshold)
+	if failureThreshold < 1 || failureThreshold > maxCircuitBreakerFailureThreshold {
+		return nil, fmt.Errorf("failureThreshold must be between 1 and %d", maxCircuitBreakerFailureThreshold)
+	}
+	openSeconds := valueOrDefault(spec.OpenSeconds, defaultCircuitBreakerOpenSeconds)
+	if openSeconds < 1 || openSeconds > maxCircuitBreakerOpenSeconds {
+		return nil, fmt.Errorf("openSeconds must be between 1 and %d", maxCircuitBreakerOpenSeconds)
+	}
+
+	return &circuitBreaker{
+		delegate:         delegate,
+		failureThreshold: int(failureThreshold),
+		openDuration:     time.Duration(openSeconds) * time.Second,
+		clock:            clock,
+	}, nil
+}
+
+// circuitBreaker stops calling another authenticator for a while after it failed repeatedly.
+type circuitBreaker struct {
+	delegate         authenticator.Token
+	failureThreshold int
+	openDuration     time.Duration
+	clock            clock.PassiveClock
+
+	lock                sync.Mutex
+	state               circuitBreakerState
+	consecutiveFailures int
+	openedAt            time.Time
+	lastErr             error
+	trialInFlight       bool
+}
+
+// AuthenticateToken implements authenticator.Token.
+func (b *circuitBreaker) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	if !b.allow() {
+		return nil, false, errCircuitBreakerOpen
+	}
+
+	response, authenticated, err := b.delegate.AuthenticateToken(ctx, token)
+
+	// When the client went away, the call tells nothing about whether the webhook is down.
+	b.record(err, ctx.Err() != nil)
+
+	return response, authenticated, err
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case circuitBreakerOpen:
+		if b.clock.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.state = circuitBreakerHalfOpen
+		b.trialInFlight = true
+		return true
+	case circuitBreakerHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(err error, canceled bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == circuitBreakerHalfOpen {
+		b.trialInFlight = false
+	}
+
+	switch {
+	case err == nil:
+		b.state = circuitBreakerClosed
+		b.consecutiveFailures = 0
+		b.lastErr = nil
+	case canceled:
+		return
+	default:
+		b.consecutiveFailures++
+		b.lastErr = err
+		if b.state == circuitBreakerHalfOpen || b.consecutiveFailures >= b.failureThreshold {
+			b.state = circuitBreakerOpen
+			b.openedAt = b.clock.Now()
+		}
+	}
+}
+
+// status returns the state of the circuit breaker, when it opened, and the error of the last failed call.
+func (b *circuitBreaker) status() (circuitBreakerState, time.Time, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.state, b.openedAt, b.lastErr
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	authinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/authentication/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	circuitBreakerStatusControllerName = "webhook-circuit-breaker-status-controller"
+
+	// circuitBreakerStatusInterval is how often the state of each circuit breaker is reported.
+	circuitBreakerStatusInterval = 10 * time.Second
+
+	typeWebhookAvailable = "WebhookAvailable"
+
+	reasonCircuitBreakerClosed   = "CircuitBreakerClosed"
+	reasonCircuitBreakerOpen     = "CircuitBreakerOpen"
+	reasonCircuitBreakerHalfOpen = "CircuitBreakerHalfOpen"
+)
+
+type circuitBreakerStatusController struct {
+	circuitBreakers *CircuitBreakers
+	client          pinnipedclientset.Interface
+	webhooks        authinformers.WebhookAuthenticatorInformer
+	log             plog.Logger
+}
+
+// NewCircuitBreakerStatus instantiates a new controllerlib.Controller which will periodically report the state of
+// the circuit breaker of each WebhookAuthenticator as a condition on the WebhookAuthenticator. The circuit breakers
+// are read from the provided CircuitBreakers, which should be shared with the controller returned by New.
+func NewCircuitBreakerStatus(
+	circuitBreakers *CircuitBreakers,
+	client pinnipedclientset.Interface,
+	webhooks authinformers.WebhookAuthenticatorInformer,
+	log plog.Logger,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: circuitBreakerStatusControllerName,
+			Syncer: &circuitBreakerStatusController{
+				circuitBreakers: circuitBreakers,
+				client:          client,
+				webhooks:        webhooks,
+				log:             log.WithName(circuitBreakerStatusControllerName),
+			},
+		},
+		controllerlib.WithInformer(
+			webhooks,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *circuitBreakerStatusController) Sync(ctx controllerlib.Context) error {
+	webhooks, err := c.webhooks.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list WebhookAuthenticators: %w", err)
+	}
+
+	names := sets.New[string]()
+	for _, obj := range webhooks {
+		names.Insert(obj.Name)
+		c.updateStatus(ctx.Context, obj, c.circuitBreakers.get(obj.Name))
+	}
+
+	// Forget the circuit breakers of any WebhookAuthenticators which were deleted.
+	c.circuitBreakers.retain(names)
+
+	ctx.Queue.AddAfter(ctx.Key, circuitBreakerStatusInterval)
+	return nil
+}
+
+// webhookAvailableCondition describes the state of the circuit breaker.
+func webhookAvailableCondition(breaker *circuitBreaker) *auth1alpha1.Condition {
+	state, openedAt, err := breaker.status()
+	switch state {
+	case circuitBreakerOpen:
+		return &auth1alpha1.Condition{
+			Type:   typeWebhookAvailable,
+			Status: auth1alpha1.ConditionFalse,
+			Reason: reasonCircuitBreakerOpen,
+			Message: fmt.Sprintf("the webhook failed %d consecutive times, so tokens are rejected without calling it until %s: %s",
+				breaker.failureThreshold, openedAt.Add(breaker.openDuration).UTC().Format(time.RFC3339), err),
+		}
+	case circuitBreakerHalfOpen:
+		return &auth1alpha1.Condition{
+			Type:    typeWebhookAvailable,
+			Status:  auth1alpha1.ConditionFalse,
+			Reason:  reasonCircuitBreakerHalfOpen,
+			Message: fmt.Sprintf("the webhook is called again to check whether it has recovered: %s", err),
+		}
+	default:
+		return &auth1alpha1.Condition{
+			Type:    typeWebhookAvailable,
+			Status:  auth1alpha1.ConditionTrue,
+			Reason:  reasonCircuitBreakerClosed,
+			Message: "the webhook is called",
+		}
+	}
+}
+
+func (c *circuitBreakerStatusController) updateStatus(ctx context.Context, original *auth1alpha1.WebhookAuthenticator, breaker *circuitBreaker) {
+	log := c.log.WithValues("webhook", original.Name)
+	updated := original.DeepCopy()
+
+	if breaker != nil {
+		_ = conditionsutil.MergeAuthenticatorConditions(
+			[]*auth1alpha1.Condition{webhookAvailableCondition(breaker)},
+			original.Generation,
+			&updated.Status.Conditions,
+			log,
+		)
+	} else {
+		// The WebhookAuthenticator does not configure a circuit breaker (anymore), or it could not be created.
+		conditions := updated.Status.Conditions[:0]
+		for _, condition := range updated.Status.Conditions {
+			if condition.Type != typeWebhookAvailable {
+				conditions = append(conditions, condition)
+			}
+		}
+		updated.Status.Conditions = conditions
+	}
+
+	if equality.Semantic.DeepEqual(original, updated) {
+		return
+	}
+
+	_, err := c.client.
+		AuthenticationV1alpha1().
+		WebhookAuthenticators().
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error("failed to update status", err)
+	}
+}
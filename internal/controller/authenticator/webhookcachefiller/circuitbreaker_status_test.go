@@ -0,0 +1,187 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+type recordingQueue struct {
+	addAfterDurations []time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *recordingQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.addAfterDurations = append(q.addAfterDurations, duration)
+}
+
+func TestCircuitBreakerStatusControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const testName = "test-name"
+
+	openedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	someTime := metav1.NewTime(openedAt.Add(-time.Hour))
+
+	tests := []struct {
+		name          string
+		breakerState  *circuitBreakerState
+		conditions    []auth1alpha1.Condition
+		wantCondition *auth1alpha1.Condition
+		wantNoUpdate  bool
+	}{
+		{
+			name:         "closed",
+			breakerState: statePtr(circuitBreakerClosed),
+			wantCondition: &auth1alpha1.Condition{
+				Type:    "WebhookAvailable",
+				Status:  "True",
+				Reason:  "CircuitBreakerClosed",
+				Message: "the webhook is called",
+			},
+		},
+		{
+			name:         "open",
+			breakerState: statePtr(circuitBreakerOpen),
+			wantCondition: &auth1alpha1.Condition{
+				Type:    "WebhookAvailable",
+				Status:  "False",
+				Reason:  "CircuitBreakerOpen",
+				Message: "the webhook failed 5 consecutive times, so tokens are rejected without calling it until 2026-01-02T03:04:35Z: some webhook error",
+			},
+		},
+		{
+			name:         "half open",
+			breakerState: statePtr(circuitBreakerHalfOpen),
+			wantCondition: &auth1alpha1.Condition{
+				Type:    "WebhookAvailable",
+				Status:  "False",
+				Reason:  "CircuitBreakerHalfOpen",
+				Message: "the webhook is called again to check whether it has recovered: some webhook error",
+			},
+		},
+		{
+			name:         "unchanged condition",
+			breakerState: statePtr(circuitBreakerClosed),
+			conditions: []auth1alpha1.Condition{{
+				Type:               "WebhookAvailable",
+				Status:             "True",
+				ObservedGeneration: 1234,
+				LastTransitionTime: someTime,
+				Reason:             "CircuitBreakerClosed",
+				Message:            "the webhook is called",
+			}},
+			wantNoUpdate: true,
+		},
+		{
+			name: "the circuit breaker was removed",
+			conditions: []auth1alpha1.Condition{{
+				Type:               "WebhookAvailable",
+				Status:             "False",
+				ObservedGeneration: 1234,
+				LastTransitionTime: someTime,
+				Reason:             "CircuitBreakerOpen",
+				Message:            "some message",
+			}},
+		},
+		{
+			name:         "no circuit breaker",
+			wantNoUpdate: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			circuitBreakers := NewCircuitBreakers()
+			if tt.breakerState != nil {
+				breaker, err := newCircuitBreaker(nil, &auth1alpha1.WebhookAuthenticatorCircuitBreaker{}, clocktesting.NewFakePassiveClock(openedAt))
+				require.NoError(t, err)
+				breaker.state = *tt.breakerState
+				breaker.openedAt = openedAt
+				if breaker.state != circuitBreakerClosed {
+					breaker.lastErr = errors.New("some webhook error")
+				}
+				circuitBreakers.store(testName, breaker)
+			}
+			// This circuit breaker belongs to a WebhookAuthenticator which was deleted.
+			circuitBreakers.store("some-deleted-name", &circuitBreaker{})
+
+			fakeClient := pinnipedfake.NewSimpleClientset([]runtime.Object{&auth1alpha1.WebhookAuthenticator{
+				ObjectMeta: metav1.ObjectMeta{Name: testName, Generation: 1234},
+				Status:     auth1alpha1.WebhookAuthenticatorStatus{Conditions: tt.conditions},
+			}}...)
+			informers := pinnipedinformers.NewSharedInformerFactory(fakeClient, 0)
+
+			controller := NewCircuitBreakerStatus(
+				circuitBreakers,
+				fakeClient,
+				informers.Authentication().V1alpha1().WebhookAuthenticators(),
+				plog.TestLogger(t, io.Discard),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			informers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			queue := &recordingQueue{}
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}, Queue: queue}
+
+			require.NoError(t, controllerlib.TestSync(t, controller, syncCtx))
+			require.Equal(t, []time.Duration{10 * time.Second}, queue.addAfterDurations)
+			require.Nil(t, circuitBreakers.get("some-deleted-name"))
+
+			updates := webhookStatusUpdates(fakeClient.Actions())
+			if tt.wantNoUpdate {
+				require.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			conditions := updates[0].Status.Conditions
+			if tt.wantCondition == nil {
+				require.Empty(t, conditions)
+				return
+			}
+			require.Len(t, conditions, 1)
+			wantCondition := *tt.wantCondition
+			wantCondition.ObservedGeneration = 1234
+			wantCondition.LastTransitionTime = conditions[0].LastTransitionTime
+			require.Equal(t, wantCondition, conditions[0])
+		})
+	}
+}
+
+func statePtr(state circuitBreakerState) *circuitBreakerState {
+	return &state
+}
+
+func webhookStatusUpdates(actions []coretesting.Action) []*auth1alpha1.WebhookAuthenticator {
+	var updates []*auth1alpha1.WebhookAuthenticator
+	for _, action := range actions {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			updates = append(updates, action.(coretesting.UpdateAction).GetObject().(*auth1alpha1.WebhookAuthenticator))
+		}
+	}
+	return updates
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+func TestNewCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    auth1alpha1.WebhookAuthenticatorCircuitBreaker
+		wantErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "valid",
+			spec: auth1alpha1.WebhookAuthenticatorCircuitBreaker{
+				FailureThreshold: pointer.Int32(100),
+				OpenSeconds:      pointer.Int32(1),
+			},
+		},
+		{
+			name:    "failure threshold too low",
+			spec:    auth1alpha1.WebhookAuthenticatorCircuitBreaker{FailureThreshold: pointer.Int32(0)},
+			wantErr: "failureThreshold must be between 1 and 100",
+		},
+		{
+			name:    "open seconds too long",
+			spec:    auth1alpha1.WebhookAuthenticatorCircuitBreaker{OpenSeconds: pointer.Int32(3601)},
+			wantErr: "openSeconds must be between 1 and 3600",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := newCircuitBreaker(nil, &tt.spec, clocktesting.NewFakePassiveClock(time.Now()))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, b)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, b)
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+
+	calls := 0
+	var webhookErr error
+	delegate := authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+		calls++
+		if webhookErr != nil {
+			return nil, false, webhookErr
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{Name: "some-user"}}, true, nil
+	})
+
+	b, err := newCircuitBreaker(delegate, &auth1alpha1.WebhookAuthenticatorCircuitBreaker{
+		FailureThreshold: pointer.Int32(2),
+		OpenSeconds:      pointer.Int32(30),
+	}, fakeClock)
+	require.NoError(t, err)
+
+	requireState := func(wantState circuitBreakerState, wantErr string) {
+		t.Helper()
+		state, _, err := b.status()
+		require.Equal(t, wantState, state)
+		if wantErr == "" {
+			require.NoError(t, err)
+		} else {
+			require.EqualError(t, err, wantErr)
+		}
+	}
+
+	// The webhook is called while it works.
+	_, authenticated, err := b.AuthenticateToken(ctx, "some-token")
+	require.NoError(t, err)
+	require.True(t, authenticated)
+	require.Equal(t, 1, calls)
+	requireState(circuitBreakerClosed, "")
+
+	// A single failure does not open the circuit breaker.
+	webhookErr = errors.New("some webhook error")
+	_, _, err = b.AuthenticateToken(ctx, "some-token")
+	require.EqualError(t, err, "some webhook error")
+	requireState(circuitBreakerClosed, "some webhook error")
+
+	// A failure of a canceled request does not count.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = b.AuthenticateToken(canceledCtx, "some-token")
+	require.EqualError(t, err, "some webhook error")
+	requireState(circuitBreakerClosed, "some webhook error")
+	require.Equal(t, 3, calls)
+
+	// Consecutive failures open the circuit breaker, after which the webhook is not called.
+	_, _, err = b.AuthenticateToken(ctx, "some-token")
+	require.EqualError(t, err, "some webhook error")
+	requireState(circuitBreakerOpen, "some webhook error")
+	_, _, err = b.AuthenticateToken(ctx, "some-token")
+	require.EqualError(t, err, "webhook is unavailable: circuit breaker is open")
+	require.Equal(t, 4, calls)
+
+	// After a while, a single call checks whether the webhook has recovered, and it opens again when it has not.
+	fakeClock.Step(30 * time.Second)
+	require.True(t, b.allow())
+	requireState(circuitBreakerHalfOpen, "some webhook error")
+	require.False(t, b.allow())
+	b.record(webhookErr, false)
+	requireState(circuitBreakerOpen, "some webhook error")
+	_, _, err = b.AuthenticateToken(ctx, "some-token")
+	require.EqualError(t, err, "webhook is unavailable: circuit breaker is open")
+
+	// The circuit breaker closes again once the webhook has recovered.
+	fakeClock.Step(30 * time.Second)
+	webhookErr = nil
+	_, authenticated, err = b.AuthenticateToken(ctx, "some-token")
+	require.NoError(t, err)
+	require.True(t, authenticated)
+	require.Equal(t, 5, calls)
+	requireState(circuitBreakerClosed, "")
+}
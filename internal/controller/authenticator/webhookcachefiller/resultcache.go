@@ -0,0 +1,119 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/lru"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+const (
+	defaultCacheTTLSeconds       = 120
+	defaultCacheDenialTTLSeconds = 30
+	defaultCacheMaxSize          = 1000
+
+	maxCacheTTLSeconds = 3600
+	maxCacheMaxSize    = 100000
+)
+
+// cachingAuthenticator caches the results of another authenticator, keyed by a hash of the token, so that repeated
+// requests with the same token only call the other authenticator once until the cached result expires.
+type cachingAuthenticator struct {
+	delegate  authenticator.Token
+	ttl       time.Duration
+	denialTTL time.Duration
+	clock     clock.PassiveClock
+	results   *lru.Cache
+}
+
+type cachedResult struct {
+	response      *authenticator.Response
+	authenticated bool
+	expiresAt     time.Time
+}
+
+// newCachingAuthenticator wraps the delegate with a cache which is configured by the given spec.
+func newCachingAuthenticator(
+	delegate authenticator.Token,
+	spec *auth1alpha1.WebhookAuthenticatorCache,
+	clock clock.PassiveClock,
+) (*cachingAuthenticator, error) {
+	ttlSeconds := valueOrDefault(spec.TTLSeconds, defaultCacheTTLSeconds)
+	if ttlSeconds < 1 || ttlSeconds > maxCacheTTLSeconds {
+		return nil, fmt.Errorf("ttlSeconds must be between 1 and %d", maxCacheTTLSeconds)
+	}
+	denialTTLSeconds := valueOrDefault(spec.DenialTTLSeconds, defaultCacheDenialTTLSeconds)
+	if denialTTLSeconds < 0 || denialTTLSeconds > maxCacheTTLSeconds {
+		return nil, fmt.Errorf("denialTTLSeconds must be between 0 and %d", maxCacheTTLSeconds)
+	}
+	maxSize := valueOrDefault(spec.MaxSize, defaultCacheMaxSize)
+	if maxSize < 1 || maxSize > maxCacheMaxSize {
+		return nil, fmt.Errorf("maxSize must be between 1 and %d", maxCacheMaxSize)
+	}
+
+	return &cachingAuthenticator{
+		delegate:  delegate,
+		ttl:       time.Duration(ttlSeconds) * time.Second,
+		denialTTL: time.Duration(denialTTLSeconds) * time.Second,
+		clock:     clock,
+		results:   lru.New(int(maxSize)),
+	}, nil
+}
+
+func valueOrDefault(value *int32, defaultValue int32) int32 {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
+// AuthenticateToken implements authenticator.Token.
+func (a *cachingAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	key := cacheKey(ctx, token)
+
+	if value, ok := a.results.Get(key); ok {
+		result := value.(*cachedResult)
+		if a.clock.Now().Before(result.expiresAt) {
+			return result.response, result.authenticated, nil
+		}
+		a.results.Remove(key)
+	}
+
+	response, authenticated, err := a.delegate.AuthenticateToken(ctx, token)
+	if err != nil {
+		// Errors are usually temporary, e.g. when the webhook is unavailable, so they are never cached.
+		return nil, false, err
+	}
+
+	ttl := a.ttl
+	if !authenticated {
+		ttl = a.denialTTL
+	}
+	if ttl > 0 {
+		a.results.Add(key, &cachedResult{
+			response:      response,
+			authenticated: authenticated,
+			expiresAt:     a.clock.Now().Add(ttl),
+		})
+	}
+
+	return response, authenticated, nil
+}
+
+// cacheKey hashes the token, so that the tokens themselves are never held in memory by the cache. The audiences
+// of the request are part of the key, since the result of the webhook may depend on them.
+func cacheKey(ctx context.Context, token string) string {
+	audiences, _ := authenticator.AudiencesFrom(ctx)
+	hash := sha256.Sum256([]byte(strings.Join(audiences, "\x00") + "\x01" + token))
+	return string(hash[:])
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+func TestNewCachingAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    auth1alpha1.WebhookAuthenticatorCache
+		wantErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "valid",
+			spec: auth1alpha1.WebhookAuthenticatorCache{
+				TTLSeconds:       pointer.Int32(3600),
+				DenialTTLSeconds: pointer.Int32(0),
+				MaxSize:          pointer.Int32(1),
+			},
+		},
+		{
+			name:    "ttl too short",
+			spec:    auth1alpha1.WebhookAuthenticatorCache{TTLSeconds: pointer.Int32(0)},
+			wantErr: "ttlSeconds must be between 1 and 3600",
+		},
+		{
+			name:    "denial ttl too long",
+			spec:    auth1alpha1.WebhookAuthenticatorCache{DenialTTLSeconds: pointer.Int32(3601)},
+			wantErr: "denialTTLSeconds must be between 0 and 3600",
+		},
+		{
+			name:    "max size too large",
+			spec:    auth1alpha1.WebhookAuthenticatorCache{MaxSize: pointer.Int32(100001)},
+			wantErr: "maxSize must be between 1 and 100000",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := newCachingAuthenticator(nil, &tt.spec, clocktesting.NewFakePassiveClock(time.Now()))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, a)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, a)
+		})
+	}
+}
+
+func TestCachingAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+
+	calls := map[string]int{}
+	delegate := authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+		calls[token]++
+		switch token {
+		case "good-token", "other-good-token":
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "some-user"}}, true, nil
+		case "bad-token":
+			return nil, false, nil
+		default:
+			return nil, false, errors.New("some webhook error")
+		}
+	})
+
+	a, err := newCachingAuthenticator(delegate, &auth1alpha1.WebhookAuthenticatorCache{
+		TTLSeconds:       pointer.Int32(60),
+		DenialTTLSeconds: pointer.Int32(10),
+		MaxSize:          pointer.Int32(2),
+	}, fakeClock)
+	require.NoError(t, err)
+
+	requireAuthenticated := func(token string) {
+		t.Helper()
+		resp, authenticated, err := a.AuthenticateToken(ctx, token)
+		require.NoError(t, err)
+		require.True(t, authenticated)
+		require.Equal(t, "some-user", resp.User.GetName())
+	}
+	requireDenied := func(token string) {
+		t.Helper()
+		resp, authenticated, err := a.AuthenticateToken(ctx, token)
+		require.NoError(t, err)
+		require.False(t, authenticated)
+		require.Nil(t, resp)
+	}
+
+	// Successful authentications are cached until the ttl expired.
+	requireAuthenticated("good-token")
+	requireAuthenticated("good-token")
+	require.Equal(t, 1, calls["good-token"])
+	fakeClock.Step(59 * time.Second)
+	requireAuthenticated("good-token")
+	require.Equal(t, 1, calls["good-token"])
+	fakeClock.Step(time.Second)
+	requireAuthenticated("good-token")
+	require.Equal(t, 2, calls["good-token"])
+
+	// Denials are cached until the denial ttl expired.
+	requireDenied("bad-token")
+	requireDenied("bad-token")
+	require.Equal(t, 1, calls["bad-token"])
+	fakeClock.Step(10 * time.Second)
+	requireDenied("bad-token")
+	require.Equal(t, 2, calls["bad-token"])
+
+	// Errors are never cached.
+	for i := 0; i < 2; i++ {
+		resp, authenticated, err := a.AuthenticateToken(ctx, "error-token")
+		require.EqualError(t, err, "some webhook error")
+		require.False(t, authenticated)
+		require.Nil(t, resp)
+	}
+	require.Equal(t, 2, calls["error-token"])
+
+	// The least recently used result is removed when the cache is full.
+	requireAuthenticated("good-token")
+	requireAuthenticated("other-good-token")
+	requireAuthenticated("good-token")
+	require.Equal(t, 2, calls["good-token"])
+	requireDenied("bad-token")
+	require.Equal(t, 3, calls["bad-token"])
+
+	// The audiences of the request are part of the cache key.
+	audienceCtx := authenticator.WithAudiences(ctx, authenticator.Audiences{"some-audience"})
+	_, _, err = a.AuthenticateToken(audienceCtx, "good-token")
+	require.NoError(t, err)
+	require.Equal(t, 3, calls["good-token"])
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package webhookcachefiller implements a controller for filling an authncache.Cache with each added/updated WebhookAuthenticator.
@@ -7,17 +7,24 @@ package webhookcachefiller
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 	k8sauthv1beta1 "k8s.io/api/authentication/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	webhookutil "k8s.io/apiserver/pkg/util/webhook"
 	"k8s.io/apiserver/plugin/pkg/authenticator/token/webhook"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
 	authinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/authentication/v1alpha1"
@@ -27,15 +34,38 @@ import (
 	"go.pinniped.dev/internal/controllerlib"
 )
 
+const (
+	// defaultTimeoutSeconds matches the timeout which webhookutil.LoadKubeconfig configures.
+	defaultTimeoutSeconds = 30
+	maxTimeoutSeconds     = 60
+
+	maxRetryAttempts                   = 10
+	maxRetryInitialBackoffMilliseconds = 10000
+)
+
 // New instantiates a new controllerlib.Controller which will populate the provided authncache.Cache.
-func New(cache *authncache.Cache, webhooks authinformers.WebhookAuthenticatorInformer, log logr.Logger) controllerlib.Controller {
+func New(
+	cache *authncache.Cache,
+	circuitBreakers *CircuitBreakers,
+	webhooks authinformers.WebhookAuthenticatorInformer,
+	namespace string,
+	secrets corev1informers.SecretInformer,
+	configMaps corev1informers.ConfigMapInformer,
+	clock clock.PassiveClock,
+	log logr.Logger,
+) controllerlib.Controller {
 	return controllerlib.New(
 		controllerlib.Config{
 			Name: "webhookcachefiller-controller",
 			Syncer: &controller{
-				cache:    cache,
-				webhooks: webhooks,
-				log:      log.WithName("webhookcachefiller-controller"),
+				cache:           cache,
+				circuitBreakers: circuitBreakers,
+				webhooks:        webhooks,
+				namespace:       namespace,
+				secrets:         secrets,
+				configMaps:      configMaps,
+				clock:           clock,
+				log:             log.WithName("webhookcachefiller-controller"),
 			},
 		},
 		controllerlib.WithInformer(
@@ -43,17 +73,52 @@ func New(cache *authncache.Cache, webhooks authinformers.WebhookAuthenticatorInf
 			pinnipedcontroller.MatchAnythingFilter(nil), // nil parent func is fine because each event is distinct
 			controllerlib.InformerOption{},
 		),
+		// Any WebhookAuthenticator may read its CA bundle from a Secret or ConfigMap, so changes to those
+		// cause all WebhookAuthenticators to be synced again.
+		controllerlib.WithInformer(
+			secrets,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		controllerlib.WithInformer(
+			configMaps,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
 	)
 }
 
 type controller struct {
-	cache    *authncache.Cache
-	webhooks authinformers.WebhookAuthenticatorInformer
-	log      logr.Logger
+	cache           *authncache.Cache
+	circuitBreakers *CircuitBreakers
+	webhooks        authinformers.WebhookAuthenticatorInformer
+	namespace       string
+	secrets         corev1informers.SecretInformer
+	configMaps      corev1informers.ConfigMapInformer
+	clock           clock.PassiveClock
+	log             logr.Logger
 }
 
 // Sync implements controllerlib.Syncer.
 func (c *controller) Sync(ctx controllerlib.Context) error {
+	// Changes to Secrets and ConfigMaps are queued without the name of a WebhookAuthenticator.
+	if ctx.Key.Name == "" {
+		webhooks, err := c.webhooks.Lister().List(labels.Everything())
+		if err != nil {
+			return fmt.Errorf("failed to list WebhookAuthenticators: %w", err)
+		}
+		var errs []error
+		for _, obj := range webhooks {
+			if err := c.syncWebhook(obj); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+
 	obj, err := c.webhooks.Lister().Get(ctx.Key.Name)
 	if err != nil && errors.IsNotFound(err) {
 		c.log.Info("Sync() found that the WebhookAuthenticator does not exist yet or was deleted")
@@ -63,16 +128,48 @@ func (c *controller) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("failed to get WebhookAuthenticator %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
 	}
 
-	webhookAuthenticator, err := newWebhookAuthenticator(&obj.Spec, os.CreateTemp, clientcmd.WriteToFile)
+	return c.syncWebhook(obj)
+}
+
+func (c *controller) syncWebhook(obj *auth1alpha1.WebhookAuthenticator) error {
+	_, caBundle, err := pinnipedauthenticator.CABundle(obj.Spec.TLS, c.namespace, c.secrets.Lister(), c.configMaps.Lister())
+	if err != nil {
+		return fmt.Errorf("failed to build webhook config: invalid TLS configuration: %w", err)
+	}
+
+	webhookAuthenticator, err := newWebhookAuthenticator(&obj.Spec, caBundle, os.CreateTemp, clientcmd.WriteToFile)
 	if err != nil {
 		return fmt.Errorf("failed to build webhook config: %w", err)
 	}
 
+	// The circuit breaker wraps the webhook, and the cache wraps the circuit breaker, so that cached results
+	// continue to be used while the circuit breaker is open.
+	var tokenAuthenticator authncache.Value = webhookAuthenticator
+	var breaker *circuitBreaker
+	if obj.Spec.CircuitBreaker != nil {
+		breaker, err = newCircuitBreaker(tokenAuthenticator, obj.Spec.CircuitBreaker, c.clock)
+		if err != nil {
+			return fmt.Errorf("failed to build webhook config: invalid circuit breaker: %w", err)
+		}
+		tokenAuthenticator = breaker
+	}
+	if obj.Spec.Cache != nil {
+		tokenAuthenticator, err = newCachingAuthenticator(tokenAuthenticator, obj.Spec.Cache, c.clock)
+		if err != nil {
+			return fmt.Errorf("failed to build webhook config: invalid cache: %w", err)
+		}
+	}
+
+	if breaker != nil {
+		c.circuitBreakers.store(obj.Name, breaker)
+	} else {
+		c.circuitBreakers.forget(obj.Name)
+	}
 	c.cache.Store(authncache.Key{
 		APIGroup: auth1alpha1.GroupName,
 		Kind:     "WebhookAuthenticator",
-		Name:     ctx.Key.Name,
-	}, webhookAuthenticator)
+		Name:     obj.Name,
+	}, tokenAuthenticator)
 	c.log.WithValues("webhook", klog.KObj(obj), "endpoint", obj.Spec.Endpoint).Info("added new webhook authenticator")
 	return nil
 }
@@ -81,6 +178,7 @@ func (c *controller) Sync(ctx controllerlib.Context) error {
 // used to validate TLS connections.
 func newWebhookAuthenticator(
 	spec *auth1alpha1.WebhookAuthenticatorSpec,
+	caBundle []byte,
 	tempfileFunc func(string, string) (*os.File, error),
 	marshalFunc func(clientcmdapi.Config, string) error,
 ) (*webhook.WebhookTokenAuthenticator, error) {
@@ -90,11 +188,7 @@ func newWebhookAuthenticator(
 	}
 	defer func() { _ = os.Remove(temp.Name()) }()
 
-	cluster := &clientcmdapi.Cluster{Server: spec.Endpoint}
-	_, cluster.CertificateAuthorityData, err = pinnipedauthenticator.CABundle(spec.TLS)
-	if err != nil {
-		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
-	}
+	cluster := &clientcmdapi.Cluster{Server: spec.Endpoint, CertificateAuthorityData: caBundle}
 
 	kubeconfig := clientcmdapi.NewConfig()
 	kubeconfig.Clusters["anonymous-cluster"] = cluster
@@ -118,12 +212,15 @@ func newWebhookAuthenticator(
 	// custom proxy stuff used by the API server.
 	var customDial net.DialFunc
 
+	timeout, retryBackoff, err := requestOptions(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO refactor this code to directly construct the rest.Config
 	//  ideally we would keep rest config generation contained to the kubeclient package
 	//  but this will require some form of a new WithTLSConfigFunc kubeclient.Option
 	//  ex:
-	//  _, caBundle, err := pinnipedauthenticator.CABundle(spec.TLS)
-	//  ...
 	//  restConfig := &rest.Config{
 	//    Host:            spec.Endpoint,
 	//    TLSClientConfig: rest.TLSClientConfig{CAData: caBundle},
@@ -138,8 +235,33 @@ func newWebhookAuthenticator(
 	if err != nil {
 		return nil, err
 	}
+	clientConfig.Timeout = timeout
 
 	// this uses a http client that does not honor our TLS config
 	// TODO fix when we pick up https://github.com/kubernetes/kubernetes/pull/106155
-	return webhook.New(clientConfig, version, implicitAuds, *webhook.DefaultRetryBackoff())
+	return webhook.New(clientConfig, version, implicitAuds, retryBackoff)
+}
+
+// requestOptions returns the timeout of each call of the webhook and how the calls are retried.
+func requestOptions(spec *auth1alpha1.WebhookAuthenticatorSpec) (time.Duration, wait.Backoff, error) {
+	timeoutSeconds := valueOrDefault(spec.TimeoutSeconds, defaultTimeoutSeconds)
+	if timeoutSeconds < 1 || timeoutSeconds > maxTimeoutSeconds {
+		return 0, wait.Backoff{}, fmt.Errorf("invalid timeoutSeconds: must be between 1 and %d", maxTimeoutSeconds)
+	}
+
+	retryBackoff := *webhook.DefaultRetryBackoff()
+	if spec.Retry != nil {
+		attempts := valueOrDefault(spec.Retry.Attempts, int32(retryBackoff.Steps))
+		if attempts < 1 || attempts > maxRetryAttempts {
+			return 0, wait.Backoff{}, fmt.Errorf("invalid retry: attempts must be between 1 and %d", maxRetryAttempts)
+		}
+		initialBackoffMilliseconds := valueOrDefault(spec.Retry.InitialBackoffMilliseconds, int32(retryBackoff.Duration.Milliseconds()))
+		if initialBackoffMilliseconds < 1 || initialBackoffMilliseconds > maxRetryInitialBackoffMilliseconds {
+			return 0, wait.Backoff{}, fmt.Errorf("invalid retry: initialBackoffMilliseconds must be between 1 and %d", maxRetryInitialBackoffMilliseconds)
+		}
+		retryBackoff.Steps = int(attempts)
+		retryBackoff.Duration = time.Duration(initialBackoffMilliseconds) * time.Millisecond
+	}
+
+	return time.Duration(timeoutSeconds) * time.Second, retryBackoff, nil
 }
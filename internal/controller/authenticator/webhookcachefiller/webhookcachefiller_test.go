@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webhookcachefiller
@@ -11,16 +11,25 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/plugin/pkg/authenticator/token/webhook"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/pointer"
 
 	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/controller/authenticator/authncache"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/testutil"
@@ -30,13 +39,21 @@ import (
 func TestController(t *testing.T) {
 	t.Parallel()
 
+	const testNamespace = "some-namespace"
+
+	ca, err := certauthority.New("Test CA", time.Hour)
+	require.NoError(t, err)
+	caBundle := ca.Bundle()
+
 	tests := []struct {
-		name             string
-		syncKey          controllerlib.Key
-		webhooks         []runtime.Object
-		wantErr          string
-		wantLogs         []string
-		wantCacheEntries int
+		name               string
+		syncKey            controllerlib.Key
+		webhooks           []runtime.Object
+		kubeObjects        []runtime.Object
+		wantErr            string
+		wantLogs           []string
+		wantCacheEntries   int
+		wantCircuitBreaker bool
 	}{
 		{
 			name:    "not found",
@@ -60,6 +77,92 @@ func TestController(t *testing.T) {
 			},
 			wantErr: `failed to build webhook config: parse "http://invalid url": invalid character " " in host name`,
 		},
+		{
+			name:    "invalid base64 CA bundle",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint: "https://example.com",
+						TLS:      &auth1alpha1.TLSSpec{CertificateAuthorityData: "invalid-base64"},
+					},
+				},
+			},
+			wantErr: "failed to build webhook config: invalid TLS configuration: illegal base64 data at input byte 7",
+		},
+		{
+			name:    "invalid pem data CA bundle",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint: "https://example.com",
+						TLS:      &auth1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte("bad data"))},
+					},
+				},
+			},
+			wantErr: "failed to build webhook config: invalid TLS configuration: certificateAuthorityData is not valid PEM: data does not contain any valid RSA or ECDSA certificates",
+		},
+		{
+			name:    "CA bundle Secret does not exist",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint: "https://example.com",
+						TLS: &auth1alpha1.TLSSpec{
+							CertificateAuthorityDataSource: &auth1alpha1.CertificateAuthorityDataSourceSpec{
+								Kind: auth1alpha1.CertificateAuthorityDataSourceKindSecret,
+								Name: "some-ca-secret",
+								Key:  "ca.crt",
+							},
+						},
+					},
+				},
+			},
+			wantErr: `failed to build webhook config: invalid TLS configuration: certificateAuthorityDataSource is invalid: failed to get secret "some-ca-secret": secret "some-ca-secret" not found`,
+		},
+		{
+			name:    "a change to a Secret or ConfigMap syncs all webhooks",
+			syncKey: controllerlib.Key{}, // changes to Secrets and ConfigMaps are queued without a name
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint: "https://example.com",
+						TLS: &auth1alpha1.TLSSpec{
+							CertificateAuthorityDataSource: &auth1alpha1.CertificateAuthorityDataSourceSpec{
+								Kind: auth1alpha1.CertificateAuthorityDataSourceKindSecret,
+								Name: "some-ca-secret",
+								Key:  "ca.crt",
+							},
+						},
+					},
+				},
+			},
+			kubeObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "some-ca-secret", Namespace: testNamespace},
+					Type:       corev1.SecretTypeOpaque,
+					Data:       map[string][]byte{"ca.crt": caBundle},
+				},
+			},
+			wantLogs: []string{
+				`webhookcachefiller-controller "level"=0 "msg"="added new webhook authenticator" "endpoint"="https://example.com" "webhook"={"name":"test-name"}`,
+			},
+			wantCacheEntries: 1,
+		},
 		{
 			name:    "valid webhook",
 			syncKey: controllerlib.Key{Name: "test-name"},
@@ -79,6 +182,95 @@ func TestController(t *testing.T) {
 			},
 			wantCacheEntries: 1,
 		},
+		{
+			name:    "valid webhook with cache",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint: "https://example.com",
+						Cache:    &auth1alpha1.WebhookAuthenticatorCache{TTLSeconds: pointer.Int32(60)},
+					},
+				},
+			},
+			wantLogs: []string{
+				`webhookcachefiller-controller "level"=0 "msg"="added new webhook authenticator" "endpoint"="https://example.com" "webhook"={"name":"test-name"}`,
+			},
+			wantCacheEntries: 1,
+		},
+		{
+			name:    "invalid cache",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint: "https://example.com",
+						Cache:    &auth1alpha1.WebhookAuthenticatorCache{MaxSize: pointer.Int32(0)},
+					},
+				},
+			},
+			wantErr: "failed to build webhook config: invalid cache: maxSize must be between 1 and 100000",
+		},
+		{
+			name:    "valid webhook with timeout, retry, and circuit breaker",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint:       "https://example.com",
+						TimeoutSeconds: pointer.Int32(5),
+						Retry:          &auth1alpha1.WebhookAuthenticatorRetry{Attempts: pointer.Int32(1)},
+						CircuitBreaker: &auth1alpha1.WebhookAuthenticatorCircuitBreaker{},
+					},
+				},
+			},
+			wantLogs: []string{
+				`webhookcachefiller-controller "level"=0 "msg"="added new webhook authenticator" "endpoint"="https://example.com" "webhook"={"name":"test-name"}`,
+			},
+			wantCacheEntries:   1,
+			wantCircuitBreaker: true,
+		},
+		{
+			name:    "invalid timeout",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint:       "https://example.com",
+						TimeoutSeconds: pointer.Int32(61),
+					},
+				},
+			},
+			wantErr: "failed to build webhook config: invalid timeoutSeconds: must be between 1 and 60",
+		},
+		{
+			name:    "invalid circuit breaker",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint:       "https://example.com",
+						CircuitBreaker: &auth1alpha1.WebhookAuthenticatorCircuitBreaker{OpenSeconds: pointer.Int32(0)},
+					},
+				},
+			},
+			wantErr: "failed to build webhook config: invalid circuit breaker: openSeconds must be between 1 and 3600",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -88,14 +280,27 @@ func TestController(t *testing.T) {
 			fakeClient := pinnipedfake.NewSimpleClientset(tt.webhooks...)
 			informers := pinnipedinformers.NewSharedInformerFactory(fakeClient, 0)
 			cache := authncache.New()
+			circuitBreakers := NewCircuitBreakers()
 			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // old test with lots of log statements
 
-			controller := New(cache, informers.Authentication().V1alpha1().WebhookAuthenticators(), testLog.Logger)
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(tt.kubeObjects...), 0)
+
+			controller := New(
+				cache,
+				circuitBreakers,
+				informers.Authentication().V1alpha1().WebhookAuthenticators(),
+				testNamespace,
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				clock.RealClock{},
+				testLog.Logger,
+			)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
 			informers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
 			controllerlib.TestRunSynchronously(t, controller)
 
 			syncCtx := controllerlib.Context{Context: ctx, Key: tt.syncKey}
@@ -107,6 +312,7 @@ func TestController(t *testing.T) {
 			}
 			require.Equal(t, tt.wantLogs, testLog.Lines())
 			require.Equal(t, tt.wantCacheEntries, len(cache.Keys()))
+			require.Equal(t, tt.wantCircuitBreaker, circuitBreakers.get("test-name") != nil)
 		})
 	}
 }
@@ -114,40 +320,22 @@ func TestController(t *testing.T) {
 func TestNewWebhookAuthenticator(t *testing.T) {
 	t.Run("temp file failure", func(t *testing.T) {
 		brokenTempFile := func(_ string, _ string) (*os.File, error) { return nil, fmt.Errorf("some temp file error") }
-		res, err := newWebhookAuthenticator(nil, brokenTempFile, clientcmd.WriteToFile)
+		res, err := newWebhookAuthenticator(nil, nil, brokenTempFile, clientcmd.WriteToFile)
 		require.Nil(t, res)
 		require.EqualError(t, err, "unable to create temporary file: some temp file error")
 	})
 
 	t.Run("marshal failure", func(t *testing.T) {
 		marshalError := func(_ clientcmdapi.Config, _ string) error { return fmt.Errorf("some marshal error") }
-		res, err := newWebhookAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{}, os.CreateTemp, marshalError)
+		res, err := newWebhookAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{}, nil, os.CreateTemp, marshalError)
 		require.Nil(t, res)
 		require.EqualError(t, err, "unable to marshal kubeconfig: some marshal error")
 	})
 
-	t.Run("invalid base64", func(t *testing.T) {
-		res, err := newWebhookAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{
-			Endpoint: "https://example.com",
-			TLS:      &auth1alpha1.TLSSpec{CertificateAuthorityData: "invalid-base64"},
-		}, os.CreateTemp, clientcmd.WriteToFile)
-		require.Nil(t, res)
-		require.EqualError(t, err, "invalid TLS configuration: illegal base64 data at input byte 7")
-	})
-
-	t.Run("invalid pem data", func(t *testing.T) {
-		res, err := newWebhookAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{
-			Endpoint: "https://example.com",
-			TLS:      &auth1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte("bad data"))},
-		}, os.CreateTemp, clientcmd.WriteToFile)
-		require.Nil(t, res)
-		require.EqualError(t, err, "invalid TLS configuration: certificateAuthorityData is not valid PEM: data does not contain any valid RSA or ECDSA certificates")
-	})
-
 	t.Run("valid config with no TLS spec", func(t *testing.T) {
 		res, err := newWebhookAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{
 			Endpoint: "https://example.com",
-		}, os.CreateTemp, clientcmd.WriteToFile)
+		}, nil, os.CreateTemp, clientcmd.WriteToFile)
 		require.NotNil(t, res)
 		require.NoError(t, err)
 	})
@@ -162,11 +350,8 @@ func TestNewWebhookAuthenticator(t *testing.T) {
 		})
 		spec := &auth1alpha1.WebhookAuthenticatorSpec{
 			Endpoint: url,
-			TLS: &auth1alpha1.TLSSpec{
-				CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte(caBundle)),
-			},
 		}
-		res, err := newWebhookAuthenticator(spec, os.CreateTemp, clientcmd.WriteToFile)
+		res, err := newWebhookAuthenticator(spec, []byte(caBundle), os.CreateTemp, clientcmd.WriteToFile)
 		require.NoError(t, err)
 		require.NotNil(t, res)
 
@@ -176,3 +361,63 @@ func TestNewWebhookAuthenticator(t *testing.T) {
 		require.False(t, authenticated)
 	})
 }
+
+func TestRequestOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		spec        auth1alpha1.WebhookAuthenticatorSpec
+		wantTimeout time.Duration
+		wantBackoff wait.Backoff
+		wantErr     string
+	}{
+		{
+			name:        "defaults",
+			wantTimeout: 30 * time.Second,
+			wantBackoff: *webhook.DefaultRetryBackoff(),
+		},
+		{
+			name: "configured",
+			spec: auth1alpha1.WebhookAuthenticatorSpec{
+				TimeoutSeconds: pointer.Int32(5),
+				Retry: &auth1alpha1.WebhookAuthenticatorRetry{
+					Attempts:                   pointer.Int32(2),
+					InitialBackoffMilliseconds: pointer.Int32(100),
+				},
+			},
+			wantTimeout: 5 * time.Second,
+			wantBackoff: wait.Backoff{Duration: 100 * time.Millisecond, Factor: 1.5, Jitter: 0.2, Steps: 2},
+		},
+		{
+			name:    "timeout too short",
+			spec:    auth1alpha1.WebhookAuthenticatorSpec{TimeoutSeconds: pointer.Int32(0)},
+			wantErr: "invalid timeoutSeconds: must be between 1 and 60",
+		},
+		{
+			name:    "too many attempts",
+			spec:    auth1alpha1.WebhookAuthenticatorSpec{Retry: &auth1alpha1.WebhookAuthenticatorRetry{Attempts: pointer.Int32(11)}},
+			wantErr: "invalid retry: attempts must be between 1 and 10",
+		},
+		{
+			name:    "backoff too long",
+			spec:    auth1alpha1.WebhookAuthenticatorSpec{Retry: &auth1alpha1.WebhookAuthenticatorRetry{InitialBackoffMilliseconds: pointer.Int32(10001)}},
+			wantErr: "invalid retry: initialBackoffMilliseconds must be between 1 and 10000",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			timeout, backoff, err := requestOptions(&tt.spec)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTimeout, timeout)
+			require.Equal(t, tt.wantBackoff, backoff)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package x509cachefiller implements a controller for filling an authncache.Cache with each
+// added/updated X509ClientCertificateAuthenticator.
+package x509cachefiller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	authinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/authentication/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	pinnipedauthenticator "go.pinniped.dev/internal/controller/authenticator"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/x509clienttoken"
+)
+
+// New instantiates a new controllerlib.Controller which will populate the provided authncache.Cache.
+func New(
+	cache *authncache.Cache,
+	x509Authenticators authinformers.X509ClientCertificateAuthenticatorInformer,
+	namespace string,
+	secrets corev1informers.SecretInformer,
+	configMaps corev1informers.ConfigMapInformer,
+	clock clock.PassiveClock,
+	log logr.Logger,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "x509cachefiller-controller",
+			Syncer: &controller{
+				cache:              cache,
+				x509Authenticators: x509Authenticators,
+				namespace:          namespace,
+				secrets:            secrets,
+				configMaps:         configMaps,
+				clock:              clock,
+				log:                log.WithName("x509cachefiller-controller"),
+			},
+		},
+		controllerlib.WithInformer(
+			x509Authenticators,
+			pinnipedcontroller.MatchAnythingFilter(nil), // nil parent func is fine because each event is distinct
+			controllerlib.InformerOption{},
+		),
+		// Any X509ClientCertificateAuthenticator may read its CA bundle from a Secret or ConfigMap, so changes to
+		// those cause all X509ClientCertificateAuthenticators to be synced again.
+		controllerlib.WithInformer(
+			secrets,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		controllerlib.WithInformer(
+			configMaps,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+type controller struct {
+	cache              *authncache.Cache
+	x509Authenticators authinformers.X509ClientCertificateAuthenticatorInformer
+	namespace          string
+	secrets            corev1informers.SecretInformer
+	configMaps         corev1informers.ConfigMapInformer
+	clock              clock.PassiveClock
+	log                logr.Logger
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *controller) Sync(ctx controllerlib.Context) error {
+	// Changes to Secrets and ConfigMaps are queued without the name of an X509ClientCertificateAuthenticator.
+	if ctx.Key.Name == "" {
+		x509Authenticators, err := c.x509Authenticators.Lister().List(labels.Everything())
+		if err != nil {
+			return fmt.Errorf("failed to list X509ClientCertificateAuthenticators: %w", err)
+		}
+		var errs []error
+		for _, obj := range x509Authenticators {
+			if err := c.syncX509Authenticator(obj); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+
+	obj, err := c.x509Authenticators.Lister().Get(ctx.Key.Name)
+	if err != nil && errors.IsNotFound(err) {
+		c.log.Info("Sync() found that the X509ClientCertificateAuthenticator does not exist yet or was deleted")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get X509ClientCertificateAuthenticator %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
+	}
+
+	return c.syncX509Authenticator(obj)
+}
+
+func (c *controller) syncX509Authenticator(obj *auth1alpha1.X509ClientCertificateAuthenticator) error {
+	spec := obj.Spec.DeepCopy()
+
+	rootCAs, _, err := pinnipedauthenticator.CABundle(&spec.ClientCertificateAuthority, c.namespace, c.secrets.Lister(), c.configMaps.Lister())
+	if err != nil {
+		return fmt.Errorf("failed to build x509 authenticator: invalid client certificate authority: %w", err)
+	}
+	if rootCAs == nil {
+		return fmt.Errorf("failed to build x509 authenticator: invalid client certificate authority: no CA bundle is specified")
+	}
+
+	c.cache.Store(authncache.Key{
+		APIGroup: auth1alpha1.GroupName,
+		Kind:     "X509ClientCertificateAuthenticator",
+		Name:     obj.Name,
+	}, &x509Authenticator{
+		verifier: &x509clienttoken.Verifier{
+			Roots:                      rootCAs,
+			Audience:                   spec.Audience,
+			AllowedOrganizationalUnits: spec.AllowedOrganizationalUnits,
+			UsernameSource:             spec.Claims.Username,
+			GroupsSource:               spec.Claims.Groups,
+		},
+		clock: c.clock,
+	})
+	c.log.WithValues("x509Authenticator", klog.KObj(obj), "audience", spec.Audience).Info("added new x509 authenticator")
+	return nil
+}
+
+// x509Authenticator authenticates the tokens which prove the possession of a client certificate.
+type x509Authenticator struct {
+	verifier *x509clienttoken.Verifier
+	clock    clock.PassiveClock
+}
+
+var _ authenticator.Token = &x509Authenticator{}
+
+func (a *x509Authenticator) AuthenticateToken(_ context.Context, token string) (*authenticator.Response, bool, error) {
+	username, groups, err := a.verifier.Verify(token, a.clock.Now())
+	if err != nil {
+		return nil, false, err
+	}
+	return &authenticator.Response{User: &user.DefaultInfo{Name: username, Groups: groups}}, true, nil
+}
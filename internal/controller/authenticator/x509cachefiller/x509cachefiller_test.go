@@ -0,0 +1,220 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package x509cachefiller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/testutil/testlogger"
+	"go.pinniped.dev/internal/x509clienttoken"
+)
+
+func TestController(t *testing.T) {
+	t.Parallel()
+
+	const testNamespace = "some-namespace"
+
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "some-user", Organization: []string{"some-group"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+	clientCert, err := x509.ParseCertificate(clientDER)
+	require.NoError(t, err)
+	token, err := x509clienttoken.New(clientKey, []*x509.Certificate{clientCert}, "some-audience", now, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		syncKey            controllerlib.Key
+		x509Authenticators []runtime.Object
+		kubeObjects        []runtime.Object
+		wantErr            string
+		wantLogs           []string
+		wantCacheEntries   int
+	}{
+		{
+			name:    "not found",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			wantLogs: []string{
+				`x509cachefiller-controller "level"=0 "msg"="Sync() found that the X509ClientCertificateAuthenticator does not exist yet or was deleted"`,
+			},
+		},
+		{
+			name:    "missing CA bundle",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			x509Authenticators: []runtime.Object{
+				&auth1alpha1.X509ClientCertificateAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+					Spec:       auth1alpha1.X509ClientCertificateAuthenticatorSpec{Audience: "some-audience"},
+				},
+			},
+			wantErr: "failed to build x509 authenticator: invalid client certificate authority: no CA bundle is specified",
+		},
+		{
+			name:    "invalid pem data CA bundle",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			x509Authenticators: []runtime.Object{
+				&auth1alpha1.X509ClientCertificateAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+					Spec: auth1alpha1.X509ClientCertificateAuthenticatorSpec{
+						Audience: "some-audience",
+						ClientCertificateAuthority: auth1alpha1.TLSSpec{
+							CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte("bad data")),
+						},
+					},
+				},
+			},
+			wantErr: "failed to build x509 authenticator: invalid client certificate authority: certificateAuthorityData is not valid PEM: data does not contain any valid RSA or ECDSA certificates",
+		},
+		{
+			name:    "a change to a Secret or ConfigMap syncs all x509 authenticators",
+			syncKey: controllerlib.Key{}, // changes to Secrets and ConfigMaps are queued without a name
+			x509Authenticators: []runtime.Object{
+				&auth1alpha1.X509ClientCertificateAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+					Spec: auth1alpha1.X509ClientCertificateAuthenticatorSpec{
+						Audience: "some-audience",
+						ClientCertificateAuthority: auth1alpha1.TLSSpec{
+							CertificateAuthorityDataSource: &auth1alpha1.CertificateAuthorityDataSourceSpec{
+								Kind: auth1alpha1.CertificateAuthorityDataSourceKindConfigMap,
+								Name: "some-ca-configmap",
+								Key:  "ca.crt",
+							},
+						},
+					},
+				},
+			},
+			kubeObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "some-ca-configmap", Namespace: testNamespace},
+					Data:       map[string]string{"ca.crt": string(caBundle)},
+				},
+			},
+			wantLogs: []string{
+				`x509cachefiller-controller "level"=0 "msg"="added new x509 authenticator" "audience"="some-audience" "x509Authenticator"={"name":"test-name"}`,
+			},
+			wantCacheEntries: 1,
+		},
+		{
+			name:    "valid x509 authenticator",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			x509Authenticators: []runtime.Object{
+				&auth1alpha1.X509ClientCertificateAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+					Spec: auth1alpha1.X509ClientCertificateAuthenticatorSpec{
+						Audience: "some-audience",
+						ClientCertificateAuthority: auth1alpha1.TLSSpec{
+							CertificateAuthorityData: base64.StdEncoding.EncodeToString(caBundle),
+						},
+					},
+				},
+			},
+			wantLogs: []string{
+				`x509cachefiller-controller "level"=0 "msg"="added new x509 authenticator" "audience"="some-audience" "x509Authenticator"={"name":"test-name"}`,
+			},
+			wantCacheEntries: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClient := pinnipedfake.NewSimpleClientset(tt.x509Authenticators...)
+			informers := pinnipedinformers.NewSharedInformerFactory(fakeClient, 0)
+			cache := authncache.New()
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // old test with lots of log statements
+
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(tt.kubeObjects...), 0)
+
+			controller := New(
+				cache,
+				informers.Authentication().V1alpha1().X509ClientCertificateAuthenticators(),
+				testNamespace,
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				clocktesting.NewFakeClock(now),
+				testLog.Logger,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			informers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: tt.syncKey}
+
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantLogs, testLog.Lines())
+			require.Equal(t, tt.wantCacheEntries, len(cache.Keys()))
+
+			for _, key := range cache.Keys() {
+				resp, authenticated, err := cache.Get(key).AuthenticateToken(ctx, token)
+				require.NoError(t, err)
+				require.True(t, authenticated)
+				require.Equal(t, &user.DefaultInfo{Name: "some-user", Groups: []string{"some-group"}}, resp.User)
+
+				_, authenticated, err = cache.Get(key).AuthenticateToken(ctx, "some-other-token")
+				require.EqualError(t, err, "could not parse token: square/go-jose: compact JWS format must have three parts")
+				require.False(t, authenticated)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clientcertconfig implements a controller which keeps the lifetime of the client certificates issued by the
+// Concierge in sync with the spec.clientCertificates of the CredentialIssuer.
+package clientcertconfig
+
+import (
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	conciergeconfiginformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/config/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/issuer"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	minTTLSeconds           = 60
+	maxTTLSeconds           = 60 * 60
+	maxRefreshMarginSeconds = 10 * 60
+)
+
+type clientCertConfigController struct {
+	credentialIssuerResourceName string
+	credIssuerInformer           conciergeconfiginformers.CredentialIssuerInformer
+	certLifetime                 *issuer.DynamicClientCertLifetime
+}
+
+// NewClientCertConfigController returns a controller which updates certLifetime whenever the spec.clientCertificates
+// of the CredentialIssuer changes. The default lifetime is used when the CredentialIssuer does not exist, does not
+// configure the lifetime, or configures an invalid lifetime.
+func NewClientCertConfigController(
+	credentialIssuerResourceName string,
+	credentialIssuerInformer conciergeconfiginformers.CredentialIssuerInformer,
+	certLifetime *issuer.DynamicClientCertLifetime,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "client-cert-config-controller",
+			Syncer: &clientCertConfigController{
+				credentialIssuerResourceName: credentialIssuerResourceName,
+				credIssuerInformer:           credentialIssuerInformer,
+				certLifetime:                 certLifetime,
+			},
+		},
+		withInformer(credentialIssuerInformer,
+			pinnipedcontroller.SimpleFilterWithSingletonQueue(func(obj metav1.Object) bool {
+				return obj.GetName() == credentialIssuerResourceName
+			}),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *clientCertConfigController) Sync(_ controllerlib.Context) error {
+	credIssuer, err := c.credIssuerInformer.Lister().Get(c.credentialIssuerResourceName)
+	if k8serrors.IsNotFound(err) {
+		c.certLifetime.Set(issuer.DefaultClientCertLifetime)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get CredentialIssuer: %w", err)
+	}
+
+	lifetime, err := clientCertLifetime(credIssuer.Spec.ClientCertificates)
+	if err != nil {
+		plog.Warning("ignoring invalid client certificate lifetime of the CredentialIssuer",
+			"credentialIssuer", c.credentialIssuerResourceName, "reason", err.Error())
+		lifetime = issuer.DefaultClientCertLifetime
+	}
+
+	if lifetime != c.certLifetime.Get() {
+		plog.Info("updated the client certificate lifetime",
+			"ttl", lifetime.TTL.String(), "refreshMargin", lifetime.RefreshMargin.String())
+	}
+	c.certLifetime.Set(lifetime)
+	return nil
+}
+
+// clientCertLifetime validates the spec.clientCertificates of the CredentialIssuer and converts it into a
+// ClientCertLifetime, using the defaults for the settings which are not configured.
+func clientCertLifetime(spec *v1alpha1.ClientCertificatesSpec) (issuer.ClientCertLifetime, error) {
+	lifetime := issuer.DefaultClientCertLifetime
+	if spec == nil {
+		return lifetime, nil
+	}
+
+	if s := spec.TTLSeconds; s != nil {
+		if *s < minTTLSeconds || *s > maxTTLSeconds {
+			return issuer.ClientCertLifetime{}, fmt.Errorf("spec.clientCertificates.ttlSeconds must be between %d and %d seconds, but was %d",
+				minTTLSeconds, maxTTLSeconds, *s)
+		}
+		lifetime.TTL = time.Duration(*s) * time.Second
+	}
+
+	if s := spec.RefreshMarginSeconds; s != nil {
+		if *s < 0 || *s > maxRefreshMarginSeconds {
+			return issuer.ClientCertLifetime{}, fmt.Errorf("spec.clientCertificates.refreshMarginSeconds must be between 0 and %d seconds, but was %d",
+				maxRefreshMarginSeconds, *s)
+		}
+		lifetime.RefreshMargin = time.Duration(*s) * time.Second
+	}
+
+	// The clients must always be able to use their certificates for at least half of their lifetimes.
+	if lifetime.RefreshMargin >= lifetime.TTL/2 {
+		return issuer.ClientCertLifetime{}, fmt.Errorf("spec.clientCertificates.refreshMarginSeconds must be less than half of the ttl of %s, but was %s",
+			lifetime.TTL, lifetime.RefreshMargin)
+	}
+
+	return lifetime, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clientcertconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/issuer"
+)
+
+func TestClientCertConfigController(t *testing.T) {
+	const credentialIssuerResourceName = "some-credential-issuer"
+
+	// A lifetime which is never the result of a sync, to show that each sync always sets the lifetime.
+	sentinel := issuer.ClientCertLifetime{TTL: 42 * time.Hour, RefreshMargin: 42 * time.Minute}
+
+	credentialIssuer := func(spec *v1alpha1.ClientCertificatesSpec) *v1alpha1.CredentialIssuer {
+		return &v1alpha1.CredentialIssuer{
+			ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+			Spec:       v1alpha1.CredentialIssuerSpec{ClientCertificates: spec},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		credentialIssuer *v1alpha1.CredentialIssuer
+		wantLifetime     issuer.ClientCertLifetime
+	}{
+		{
+			name:         "CredentialIssuer does not exist",
+			wantLifetime: issuer.DefaultClientCertLifetime,
+		},
+		{
+			name:             "CredentialIssuer does not configure the client certificates",
+			credentialIssuer: credentialIssuer(nil),
+			wantLifetime:     issuer.DefaultClientCertLifetime,
+		},
+		{
+			name:             "CredentialIssuer configures only the ttl",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{TTLSeconds: pointer.Int32(1800)}),
+			wantLifetime:     issuer.ClientCertLifetime{TTL: 30 * time.Minute},
+		},
+		{
+			name: "CredentialIssuer configures the ttl and the refresh margin",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{
+				TTLSeconds:           pointer.Int32(3600),
+				RefreshMarginSeconds: pointer.Int32(300),
+			}),
+			wantLifetime: issuer.ClientCertLifetime{TTL: time.Hour, RefreshMargin: 5 * time.Minute},
+		},
+		{
+			name: "CredentialIssuer configures only the refresh margin",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{
+				RefreshMarginSeconds: pointer.Int32(60),
+			}),
+			wantLifetime: issuer.ClientCertLifetime{TTL: 5 * time.Minute, RefreshMargin: time.Minute},
+		},
+		{
+			name:             "CredentialIssuer configures a ttl which is too long",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{TTLSeconds: pointer.Int32(3601)}),
+			wantLifetime:     issuer.DefaultClientCertLifetime,
+		},
+		{
+			name:             "CredentialIssuer configures a ttl which is too short",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{TTLSeconds: pointer.Int32(59)}),
+			wantLifetime:     issuer.DefaultClientCertLifetime,
+		},
+		{
+			name: "CredentialIssuer configures a refresh margin which is too long",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{
+				TTLSeconds:           pointer.Int32(3600),
+				RefreshMarginSeconds: pointer.Int32(601),
+			}),
+			wantLifetime: issuer.DefaultClientCertLifetime,
+		},
+		{
+			name: "CredentialIssuer configures a refresh margin which is not less than half of the ttl",
+			credentialIssuer: credentialIssuer(&v1alpha1.ClientCertificatesSpec{
+				TTLSeconds:           pointer.Int32(600),
+				RefreshMarginSeconds: pointer.Int32(300),
+			}),
+			wantLifetime: issuer.DefaultClientCertLifetime,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformerClient := pinnipedfake.NewSimpleClientset()
+			if tt.credentialIssuer != nil {
+				require.NoError(t, pinnipedInformerClient.Tracker().Add(tt.credentialIssuer))
+			}
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedInformerClient, 0)
+
+			certLifetime := issuer.NewDynamicClientCertLifetime()
+			certLifetime.Set(sentinel)
+
+			subject := NewClientCertConfigController(
+				credentialIssuerResourceName,
+				pinnipedInformers.Config().V1alpha1().CredentialIssuers(),
+				certLifetime,
+				controllerlib.WithInformer,
+			)
+
+			// Must start informers before calling TestRunSynchronously().
+			pinnipedInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{
+				Context: ctx,
+				Key:     controllerlib.Key{Name: credentialIssuerResourceName},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLifetime, certLifetime.Get())
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package credentialpolicyconfig implements a controller which keeps the policy which decides whether the Concierge
+// may issue client certificates in sync with the spec.credentialIssuancePolicy of the CredentialIssuer.
+package credentialpolicyconfig
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	conciergeconfiginformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/config/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/credentialpolicy"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/plog"
+)
+
+type credentialPolicyConfigController struct {
+	credentialIssuerResourceName string
+	credIssuerInformer           conciergeconfiginformers.CredentialIssuerInformer
+	policy                       *credentialpolicy.DynamicPolicy
+
+	// appliedSpec is the spec from which the current policy was built, once synced is true, so that the policy
+	// is only built again when the spec changes.
+	appliedSpec *v1alpha1.CredentialIssuancePolicySpec
+	synced      bool
+}
+
+// NewCredentialPolicyConfigController returns a controller which updates policy whenever the
+// spec.credentialIssuancePolicy of the CredentialIssuer changes. Every credential is allowed when the CredentialIssuer
+// does not exist or does not configure a policy, and every credential is denied when it configures an invalid policy.
+func NewCredentialPolicyConfigController(
+	credentialIssuerResourceName string,
+	credentialIssuerInformer conciergeconfiginformers.CredentialIssuerInformer,
+	policy *credentialpolicy.DynamicPolicy,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "credential-policy-config-controller",
+			Syncer: &credentialPolicyConfigController{
+				credentialIssuerResourceName: credentialIssuerResourceName,
+				credIssuerInformer:           credentialIssuerInformer,
+				policy:                       policy,
+			},
+		},
+		withInformer(credentialIssuerInformer,
+			pinnipedcontroller.SimpleFilterWithSingletonQueue(func(obj metav1.Object) bool {
+				return obj.GetName() == credentialIssuerResourceName
+			}),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *credentialPolicyConfigController) Sync(_ controllerlib.Context) error {
+	var spec *v1alpha1.CredentialIssuancePolicySpec
+	credIssuer, err := c.credIssuerInformer.Lister().Get(c.credentialIssuerResourceName)
+	switch {
+	case k8serrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("could not get CredentialIssuer: %w", err)
+	default:
+		spec = credIssuer.Spec.CredentialIssuancePolicy
+	}
+
+	if c.synced && equality.Semantic.DeepEqual(spec, c.appliedSpec) {
+		return nil
+	}
+
+	policy, err := policyFromSpec(spec)
+	if err != nil {
+		plog.Warning("denying every credential because of the invalid credential issuance policy of the CredentialIssuer",
+			"credentialIssuer", c.credentialIssuerResourceName, "reason", err.Error())
+		policy = credentialpolicy.DenyAll("invalid credential issuance policy")
+	} else {
+		plog.Info("updated the credential issuance policy", "configured", policy != nil)
+	}
+
+	c.policy.Set(policy)
+	c.appliedSpec = spec.DeepCopy()
+	c.synced = true
+	return nil
+}
+
+// policyFromSpec validates the spec.credentialIssuancePolicy of the CredentialIssuer and converts it into a Policy,
+// which is nil when every credential is allowed.
+func policyFromSpec(spec *v1alpha1.CredentialIssuancePolicySpec) (*credentialpolicy.Policy, error) {
+	if spec == nil || (spec.Expression == "" && spec.Webhook == nil) {
+		return nil, nil
+	}
+
+	config := credentialpolicy.Config{Expression: spec.Expression}
+
+	if webhook := spec.Webhook; webhook != nil {
+		if endpoint, err := url.Parse(webhook.Endpoint); err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+			return nil, fmt.Errorf("spec.credentialIssuancePolicy.webhook.endpoint must be an https URL, but was %q", webhook.Endpoint)
+		}
+		config.WebhookURL = webhook.Endpoint
+
+		var rootCAs *x509.CertPool
+		if webhook.CertificateAuthorityData != "" {
+			caBundle, err := base64.StdEncoding.DecodeString(webhook.CertificateAuthorityData)
+			if err != nil {
+				return nil, fmt.Errorf("spec.credentialIssuancePolicy.webhook.certificateAuthorityData is not base64-encoded: %w", err)
+			}
+			rootCAs = x509.NewCertPool()
+			if !rootCAs.AppendCertsFromPEM(caBundle) {
+				return nil, errors.New("spec.credentialIssuancePolicy.webhook.certificateAuthorityData does not contain any PEM certificates")
+			}
+		}
+		config.WebhookClient = phttp.Default(rootCAs)
+
+		if s := webhook.TimeoutSeconds; s != nil {
+			config.WebhookTimeout = time.Duration(*s) * time.Second
+		}
+	}
+
+	policy, err := credentialpolicy.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("spec.credentialIssuancePolicy is invalid: %w", err)
+	}
+	return policy, nil
+}
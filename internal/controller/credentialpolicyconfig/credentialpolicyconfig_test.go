@@ -0,0 +1,134 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialpolicyconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/credentialpolicy"
+)
+
+func TestCredentialPolicyConfigController(t *testing.T) {
+	const credentialIssuerResourceName = "some-credential-issuer"
+
+	// A policy which is never the result of a sync, to show that each sync sets the policy.
+	sentinel := credentialpolicy.DenyAll("sentinel")
+
+	credentialIssuer := func(spec *v1alpha1.CredentialIssuancePolicySpec) *v1alpha1.CredentialIssuer {
+		return &v1alpha1.CredentialIssuer{
+			ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+			Spec:       v1alpha1.CredentialIssuerSpec{CredentialIssuancePolicy: spec},
+		}
+	}
+
+	allowedUser := credentialpolicy.Request{Username: "allowed-user"}
+	otherUser := credentialpolicy.Request{Username: "other-user"}
+
+	tests := []struct {
+		name             string
+		credentialIssuer *v1alpha1.CredentialIssuer
+		wantNilPolicy    bool
+		wantAllowedUser  credentialpolicy.Decision
+		wantOtherUser    credentialpolicy.Decision
+	}{
+		{
+			name:          "CredentialIssuer does not exist",
+			wantNilPolicy: true,
+		},
+		{
+			name:             "CredentialIssuer does not configure a policy",
+			credentialIssuer: credentialIssuer(nil),
+			wantNilPolicy:    true,
+		},
+		{
+			name:             "CredentialIssuer configures an empty policy",
+			credentialIssuer: credentialIssuer(&v1alpha1.CredentialIssuancePolicySpec{}),
+			wantNilPolicy:    true,
+		},
+		{
+			name: "CredentialIssuer configures an expression",
+			credentialIssuer: credentialIssuer(&v1alpha1.CredentialIssuancePolicySpec{
+				Expression: `username == "allowed-user"`,
+			}),
+			wantAllowedUser: credentialpolicy.Decision{Allowed: true},
+			wantOtherUser:   credentialpolicy.Decision{Reason: "denied by expression"},
+		},
+		{
+			name: "CredentialIssuer configures an invalid expression",
+			credentialIssuer: credentialIssuer(&v1alpha1.CredentialIssuancePolicySpec{
+				Expression: `username`,
+			}),
+			wantAllowedUser: credentialpolicy.Decision{Reason: "invalid credential issuance policy"},
+			wantOtherUser:   credentialpolicy.Decision{Reason: "invalid credential issuance policy"},
+		},
+		{
+			name: "CredentialIssuer configures a webhook which is not https",
+			credentialIssuer: credentialIssuer(&v1alpha1.CredentialIssuancePolicySpec{
+				Webhook: &v1alpha1.CredentialIssuancePolicyWebhookSpec{Endpoint: "http://example.com"},
+			}),
+			wantAllowedUser: credentialpolicy.Decision{Reason: "invalid credential issuance policy"},
+			wantOtherUser:   credentialpolicy.Decision{Reason: "invalid credential issuance policy"},
+		},
+		{
+			name: "CredentialIssuer configures a webhook with an invalid CA bundle",
+			credentialIssuer: credentialIssuer(&v1alpha1.CredentialIssuancePolicySpec{
+				Webhook: &v1alpha1.CredentialIssuancePolicyWebhookSpec{
+					Endpoint:                 "https://example.com",
+					CertificateAuthorityData: "bm90IGEgY2VydGlmaWNhdGU=", // "not a certificate"
+				},
+			}),
+			wantAllowedUser: credentialpolicy.Decision{Reason: "invalid credential issuance policy"},
+			wantOtherUser:   credentialpolicy.Decision{Reason: "invalid credential issuance policy"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformerClient := pinnipedfake.NewSimpleClientset()
+			if tt.credentialIssuer != nil {
+				require.NoError(t, pinnipedInformerClient.Tracker().Add(tt.credentialIssuer))
+			}
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedInformerClient, 0)
+
+			policy := credentialpolicy.NewDynamicPolicy()
+			policy.Set(sentinel)
+
+			subject := NewCredentialPolicyConfigController(
+				credentialIssuerResourceName,
+				pinnipedInformers.Config().V1alpha1().CredentialIssuers(),
+				policy,
+				controllerlib.WithInformer,
+			)
+
+			// Must start informers before calling TestRunSynchronously().
+			pinnipedInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{
+				Context: ctx,
+				Key:     controllerlib.Key{Name: credentialIssuerResourceName},
+			})
+			require.NoError(t, err)
+
+			if tt.wantNilPolicy {
+				require.Nil(t, policy.Get())
+				return
+			}
+			require.NotSame(t, sentinel, policy.Get())
+			require.Equal(t, tt.wantAllowedUser, policy.Get().Evaluate(ctx, allowedUser))
+			require.Equal(t, tt.wantOtherUser, policy.Get().Evaluate(ctx, otherUser))
+		})
+	}
+}
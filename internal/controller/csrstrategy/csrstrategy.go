@@ -0,0 +1,158 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package csrstrategy implements a controller which configures the Concierge to issue client certificates using the
+// certificates.k8s.io API of the cluster, and which reports the status of that strategy on the CredentialIssuer.
+package csrstrategy
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/utils/clock"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	configv1alpha1informers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/config/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/issuerconfig"
+	"go.pinniped.dev/internal/controller/kubecertagent"
+	"go.pinniped.dev/internal/controllerlib"
+)
+
+// SignerNameSetter is the part of the certificates.k8s.io CA which is configured by this controller.
+type SignerNameSetter interface {
+	SetSignerName(signerName string)
+}
+
+type csrStrategyController struct {
+	credentialIssuerName string
+	defaultSignerName    string
+	discoveryURLOverride *string
+	client               pinnipedclientset.Interface
+	credentialIssuers    configv1alpha1informers.CredentialIssuerInformer
+	kubePublicConfigMaps corev1informers.ConfigMapInformer
+	ca                   SignerNameSetter
+	clock                clock.Clock
+}
+
+// NewCSRStrategyController returns a controller which sets the signerName of the ca from the
+// spec.certificateSigningRequests of the CredentialIssuer, and which reports the CertificateSigningRequestAPI
+// strategy in the status of the CredentialIssuer. The ca does not issue any certificates while the CredentialIssuer
+// does not configure spec.certificateSigningRequests. The defaultSignerName is used when the signerName is not set.
+func NewCSRStrategyController(
+	credentialIssuerName string,
+	defaultSignerName string,
+	discoveryURLOverride *string,
+	client pinnipedclientset.Interface,
+	credentialIssuers configv1alpha1informers.CredentialIssuerInformer,
+	kubePublicConfigMaps corev1informers.ConfigMapInformer,
+	ca SignerNameSetter,
+	clock clock.Clock,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "csr-strategy-controller",
+			Syncer: &csrStrategyController{
+				credentialIssuerName: credentialIssuerName,
+				defaultSignerName:    defaultSignerName,
+				discoveryURLOverride: discoveryURLOverride,
+				client:               client,
+				credentialIssuers:    credentialIssuers,
+				kubePublicConfigMaps: kubePublicConfigMaps,
+				ca:                   ca,
+				clock:                clock,
+			},
+		},
+		withInformer(
+			credentialIssuers,
+			pinnipedcontroller.SimpleFilterWithSingletonQueue(func(obj metav1.Object) bool {
+				return obj.GetName() == credentialIssuerName
+			}),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			kubePublicConfigMaps,
+			pinnipedcontroller.SimpleFilterWithSingletonQueue(func(obj metav1.Object) bool {
+				return obj.GetNamespace() == kubecertagent.ClusterInfoNamespace
+			}),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *csrStrategyController) Sync(ctx controllerlib.Context) error {
+	credIssuer, err := c.credentialIssuers.Lister().Get(c.credentialIssuerName)
+	if k8serrors.IsNotFound(err) {
+		c.ca.SetSignerName("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get CredentialIssuer to update: %w", err)
+	}
+
+	spec := credIssuer.Spec.CertificateSigningRequests
+	if spec == nil {
+		c.ca.SetSignerName("")
+		// Most clusters never use this strategy, so only report that it is disabled when it was reported before.
+		if !hasStrategy(credIssuer) {
+			return nil
+		}
+		return issuerconfig.Update(ctx.Context, c.client, credIssuer, configv1alpha1.CredentialIssuerStrategy{
+			Type:           configv1alpha1.CertificateSigningRequestAPIStrategyType,
+			Status:         configv1alpha1.ErrorStrategyStatus,
+			Reason:         configv1alpha1.DisabledStrategyReason,
+			Message:        "spec.certificateSigningRequests is not configured",
+			LastUpdateTime: metav1.NewTime(c.clock.Now()),
+		})
+	}
+
+	signerName := spec.SignerName
+	if signerName == "" {
+		signerName = c.defaultSignerName
+	}
+	c.ca.SetSignerName(signerName)
+
+	apiInfo, err := kubecertagent.LoadAPIInfo(c.kubePublicConfigMaps.Lister(), c.discoveryURLOverride)
+	if err != nil {
+		return c.failStrategyAndErr(ctx.Context, credIssuer, err, configv1alpha1.CouldNotGetClusterInfoStrategyReason)
+	}
+
+	return issuerconfig.Update(ctx.Context, c.client, credIssuer, configv1alpha1.CredentialIssuerStrategy{
+		Type:           configv1alpha1.CertificateSigningRequestAPIStrategyType,
+		Status:         configv1alpha1.SuccessStrategyStatus,
+		Reason:         configv1alpha1.ListeningStrategyReason,
+		Message:        fmt.Sprintf("client certificates are issued by the %q signer of the certificates.k8s.io API", signerName),
+		LastUpdateTime: metav1.NewTime(c.clock.Now()),
+		Frontend: &configv1alpha1.CredentialIssuerFrontend{
+			Type:                          configv1alpha1.TokenCredentialRequestAPIFrontendType,
+			TokenCredentialRequestAPIInfo: apiInfo,
+		},
+	})
+}
+
+func (c *csrStrategyController) failStrategyAndErr(ctx context.Context, credIssuer *configv1alpha1.CredentialIssuer, err error, reason configv1alpha1.StrategyReason) error {
+	updateErr := issuerconfig.Update(ctx, c.client, credIssuer, configv1alpha1.CredentialIssuerStrategy{
+		Type:           configv1alpha1.CertificateSigningRequestAPIStrategyType,
+		Status:         configv1alpha1.ErrorStrategyStatus,
+		Reason:         reason,
+		Message:        err.Error(),
+		LastUpdateTime: metav1.NewTime(c.clock.Now()),
+	})
+	return utilerrors.NewAggregate([]error{err, updateErr})
+}
+
+func hasStrategy(credIssuer *configv1alpha1.CredentialIssuer) bool {
+	for _, strategy := range credIssuer.Status.Strategies {
+		if strategy.Type == configv1alpha1.CertificateSigningRequestAPIStrategyType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package csrstrategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/here"
+)
+
+type fakeSignerNameSetter struct {
+	signerName string
+}
+
+func (f *fakeSignerNameSetter) SetSignerName(signerName string) {
+	f.signerName = signerName
+}
+
+func TestCSRStrategyController(t *testing.T) {
+	const (
+		credentialIssuerName = "some-credential-issuer"
+		defaultSignerName    = "example.com/default-signer"
+	)
+
+	now := time.Date(2099, time.August, 8, 13, 57, 36, 123456789, time.Local)
+
+	clusterInfoConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-public", Name: "cluster-info"},
+		Data: map[string]string{"kubeconfig": here.Docf(`
+			kind: Config
+			apiVersion: v1
+			clusters:
+			- name: ""
+			  cluster:
+				certificate-authority-data: dGVzdC1rdWJlcm5ldGVzLWNh # "test-kubernetes-ca"
+				server: https://test-kubernetes-endpoint.example.com
+			`),
+		},
+	}
+
+	credentialIssuer := func(spec *configv1alpha1.CertificateSigningRequestsSpec, strategies ...configv1alpha1.CredentialIssuerStrategy) *configv1alpha1.CredentialIssuer {
+		return &configv1alpha1.CredentialIssuer{
+			ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerName},
+			Spec:       configv1alpha1.CredentialIssuerSpec{CertificateSigningRequests: spec},
+			Status:     configv1alpha1.CredentialIssuerStatus{Strategies: strategies},
+		}
+	}
+
+	successStrategy := func(signerName, server string) configv1alpha1.CredentialIssuerStrategy {
+		return configv1alpha1.CredentialIssuerStrategy{
+			Type:           configv1alpha1.CertificateSigningRequestAPIStrategyType,
+			Status:         configv1alpha1.SuccessStrategyStatus,
+			Reason:         configv1alpha1.ListeningStrategyReason,
+			Message:        `client certificates are issued by the "` + signerName + `" signer of the certificates.k8s.io API`,
+			LastUpdateTime: metav1.NewTime(now),
+			Frontend: &configv1alpha1.CredentialIssuerFrontend{
+				Type: configv1alpha1.TokenCredentialRequestAPIFrontendType,
+				TokenCredentialRequestAPIInfo: &configv1alpha1.TokenCredentialRequestAPIInfo{
+					Server:                   server,
+					CertificateAuthorityData: "dGVzdC1rdWJlcm5ldGVzLWNh",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		discoveryURLOverride *string
+		pinnipedObjects      []runtime.Object
+		kubeObjects          []runtime.Object
+		wantErr              string
+		wantSignerName       string
+		wantStrategies       []configv1alpha1.CredentialIssuerStrategy
+	}{
+		{
+			name:           "CredentialIssuer does not exist",
+			kubeObjects:    []runtime.Object{clusterInfoConfigMap},
+			wantSignerName: "",
+		},
+		{
+			name:            "CredentialIssuer does not configure the strategy",
+			pinnipedObjects: []runtime.Object{credentialIssuer(nil)},
+			kubeObjects:     []runtime.Object{clusterInfoConfigMap},
+			wantSignerName:  "",
+			wantStrategies:  nil,
+		},
+		{
+			name: "CredentialIssuer does not configure the strategy anymore",
+			pinnipedObjects: []runtime.Object{
+				credentialIssuer(nil, successStrategy("example.com/signer", "https://test-kubernetes-endpoint.example.com")),
+			},
+			kubeObjects:    []runtime.Object{clusterInfoConfigMap},
+			wantSignerName: "",
+			wantStrategies: []configv1alpha1.CredentialIssuerStrategy{{
+				Type:           configv1alpha1.CertificateSigningRequestAPIStrategyType,
+				Status:         configv1alpha1.ErrorStrategyStatus,
+				Reason:         configv1alpha1.DisabledStrategyReason,
+				Message:        "spec.certificateSigningRequests is not configured",
+				LastUpdateTime: metav1.NewTime(now),
+			}},
+		},
+		{
+			name: "CredentialIssuer configures the strategy with a signer",
+			pinnipedObjects: []runtime.Object{
+				credentialIssuer(&configv1alpha1.CertificateSigningRequestsSpec{SignerName: "example.com/signer"}),
+			},
+			kubeObjects:    []runtime.Object{clusterInfoConfigMap},
+			wantSignerName: "example.com/signer",
+			wantStrategies: []configv1alpha1.CredentialIssuerStrategy{
+				successStrategy("example.com/signer", "https://test-kubernetes-endpoint.example.com"),
+			},
+		},
+		{
+			name:                 "CredentialIssuer configures the strategy without a signer and the discovery URL is overridden",
+			discoveryURLOverride: pointer.String("https://overridden-server.example.com"),
+			pinnipedObjects: []runtime.Object{
+				credentialIssuer(&configv1alpha1.CertificateSigningRequestsSpec{}),
+			},
+			kubeObjects:    []runtime.Object{clusterInfoConfigMap},
+			wantSignerName: defaultSignerName,
+			wantStrategies: []configv1alpha1.CredentialIssuerStrategy{
+				successStrategy(defaultSignerName, "https://overridden-server.example.com"),
+			},
+		},
+		{
+			name: "cluster-info ConfigMap is missing",
+			pinnipedObjects: []runtime.Object{
+				credentialIssuer(&configv1alpha1.CertificateSigningRequestsSpec{SignerName: "example.com/signer"}),
+			},
+			wantErr:        `failed to get kube-public/cluster-info configmap: configmap "cluster-info" not found`,
+			wantSignerName: "example.com/signer",
+			wantStrategies: []configv1alpha1.CredentialIssuerStrategy{{
+				Type:           configv1alpha1.CertificateSigningRequestAPIStrategyType,
+				Status:         configv1alpha1.ErrorStrategyStatus,
+				Reason:         configv1alpha1.CouldNotGetClusterInfoStrategyReason,
+				Message:        `failed to get kube-public/cluster-info configmap: configmap "cluster-info" not found`,
+				LastUpdateTime: metav1.NewTime(now),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedAPIClient := pinnipedfake.NewSimpleClientset(tt.pinnipedObjects...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedfake.NewSimpleClientset(tt.pinnipedObjects...), 0)
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(tt.kubeObjects...), 0)
+
+			ca := &fakeSignerNameSetter{signerName: "some-previous-signer"}
+
+			subject := NewCSRStrategyController(
+				credentialIssuerName,
+				defaultSignerName,
+				tt.discoveryURLOverride,
+				pinnipedAPIClient,
+				pinnipedInformers.Config().V1alpha1().CredentialIssuers(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				ca,
+				clocktesting.NewFakeClock(now),
+				controllerlib.WithInformer,
+			)
+
+			// Must start informers before calling TestRunSynchronously().
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{Context: ctx})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.wantSignerName, ca.signerName)
+
+			if len(tt.pinnipedObjects) > 0 {
+				actual, err := pinnipedAPIClient.ConfigV1alpha1().CredentialIssuers().Get(ctx, credentialIssuerName, metav1.GetOptions{})
+				require.NoError(t, err)
+				require.Equal(t, tt.wantStrategies, actual.Status.Strategies)
+			}
+		})
+	}
+}
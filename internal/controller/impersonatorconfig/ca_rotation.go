@@ -0,0 +1,262 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonatorconfig
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	conciergeconfiginformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions/config/v1alpha1"
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/constable"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// nextCACrtKey and nextCAKeyKey hold the next CA in the CA Secret while the CA is being rotated.
+	nextCACrtKey = "next-ca.crt"
+	nextCAKeyKey = "next-ca.key"
+
+	defaultCARotationIntervalSeconds = 90 * 24 * 60 * 60
+	defaultCARotationOverlapSeconds  = 7 * 24 * 60 * 60
+	minCARotationIntervalSeconds     = 24 * 60 * 60
+	maxCARotationIntervalSeconds     = 10 * 365 * 24 * 60 * 60
+	maxCARotationOverlapSeconds      = 365 * 24 * 60 * 60
+)
+
+// caRotationSchedule describes how often the CA of the impersonation proxy is replaced.
+type caRotationSchedule struct {
+	// interval is how long after the creation of the current CA the next CA is created.
+	interval time.Duration
+
+	// overlap is how long after its creation the next CA replaces the current CA.
+	overlap time.Duration
+}
+
+// newCARotationSchedule returns the schedule which is described by the spec, or nil when the CA should not be rotated.
+func newCARotationSchedule(spec *v1alpha1.ImpersonationProxyCertificateRotationSpec) (*caRotationSchedule, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	intervalSeconds := int32(defaultCARotationIntervalSeconds)
+	if spec.IntervalSeconds != nil {
+		intervalSeconds = *spec.IntervalSeconds
+	}
+	if intervalSeconds < minCARotationIntervalSeconds || intervalSeconds > maxCARotationIntervalSeconds {
+		return nil, fmt.Errorf("invalid CertificateRotation IntervalSeconds %d (expected between %d and %d)",
+			intervalSeconds, minCARotationIntervalSeconds, maxCARotationIntervalSeconds)
+	}
+
+	overlapSeconds := int32(defaultCARotationOverlapSeconds)
+	if spec.OverlapSeconds != nil {
+		overlapSeconds = *spec.OverlapSeconds
+	}
+	if overlapSeconds < 0 || overlapSeconds > maxCARotationOverlapSeconds {
+		return nil, fmt.Errorf("invalid CertificateRotation OverlapSeconds %d (expected between 0 and %d)",
+			overlapSeconds, maxCARotationOverlapSeconds)
+	}
+	if overlapSeconds >= intervalSeconds {
+		return nil, fmt.Errorf("invalid CertificateRotation OverlapSeconds %d (expected less than IntervalSeconds %d)",
+			overlapSeconds, intervalSeconds)
+	}
+
+	return &caRotationSchedule{
+		interval: time.Duration(intervalSeconds) * time.Second,
+		overlap:  time.Duration(overlapSeconds) * time.Second,
+	}, nil
+}
+
+type caRotationController struct {
+	namespace                    string
+	credentialIssuerResourceName string
+	caSecretName                 string
+	k8sClient                    kubernetes.Interface
+	credIssuerInformer           conciergeconfiginformers.CredentialIssuerInformer
+	secretsInformer              corev1informers.SecretInformer
+	clock                        clock.Clock
+	logger                       plog.Logger
+}
+
+// NewCARotationController returns a controllerlib.Controller which periodically replaces the CA of the impersonation
+// proxy, as configured by spec.impersonationProxy.certificateRotation of the CredentialIssuer. Each new CA is first
+// stored in the CA Secret next to the current CA, so that the impersonator config controller publishes both CAs in
+// the status of the CredentialIssuer. Once the overlap has passed, the new CA replaces the current CA, which causes
+// the impersonator config controller to issue a new serving certificate. The running impersonation proxy loads the
+// new serving certificate without closing the connections which were already established.
+func NewCARotationController(
+	namespace string,
+	credentialIssuerResourceName string,
+	caSecretName string,
+	k8sClient kubernetes.Interface,
+	credentialIssuerInformer conciergeconfiginformers.CredentialIssuerInformer,
+	secretsInformer corev1informers.SecretInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+	clock clock.Clock,
+	logger plog.Logger,
+) controllerlib.Controller {
+	const name = "impersonator-ca-rotation-controller"
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: name,
+			Syncer: &caRotationController{
+				namespace:                    namespace,
+				credentialIssuerResourceName: credentialIssuerResourceName,
+				caSecretName:                 caSecretName,
+				k8sClient:                    k8sClient,
+				credIssuerInformer:           credentialIssuerInformer,
+				secretsInformer:              secretsInformer,
+				clock:                        clock,
+				logger:                       logger.WithName(name),
+			},
+		},
+		withInformer(credentialIssuerInformer,
+			pinnipedcontroller.SimpleFilterWithSingletonQueue(func(obj metav1.Object) bool {
+				return obj.GetName() == credentialIssuerResourceName
+			}),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			secretsInformer,
+			pinnipedcontroller.SimpleFilterWithSingletonQueue(func(obj metav1.Object) bool {
+				return obj.GetNamespace() == namespace && obj.GetName() == caSecretName
+			}),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *caRotationController) Sync(syncCtx controllerlib.Context) error {
+	credIssuer, err := c.credIssuerInformer.Lister().Get(c.credentialIssuerResourceName)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	caSecret, err := c.secretsInformer.Lister().Secrets(c.namespace).Get(c.caSecretName)
+	if k8serrors.IsNotFound(err) {
+		// The CA is created by the impersonator config controller once the impersonation proxy is enabled.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var rotationSpec *v1alpha1.ImpersonationProxyCertificateRotationSpec
+	if credIssuer.Spec.ImpersonationProxy != nil {
+		rotationSpec = credIssuer.Spec.ImpersonationProxy.CertificateRotation
+	}
+	schedule, err := newCARotationSchedule(rotationSpec)
+	if err != nil {
+		// The impersonator config controller reports the invalid configuration in the status of the CredentialIssuer.
+		c.logger.WarningErr("not rotating the CA of the impersonation proxy because of invalid configuration", err)
+		return nil
+	}
+
+	_, hasNextCA := caSecret.Data[nextCACrtKey]
+	if schedule == nil {
+		if hasNextCA {
+			// The rotation was disabled while it was in progress, so keep the current CA.
+			return c.updateCASecret(syncCtx.Context, caSecret, "removing the next CA of the impersonation proxy", removeNextCA)
+		}
+		return nil
+	}
+
+	now := c.clock.Now()
+
+	if !hasNextCA {
+		current, err := parseCertificate(caSecret.Data[caCrtKey])
+		if err != nil {
+			return fmt.Errorf("could not parse the CA of the impersonation proxy: %w", err)
+		}
+
+		createNextAt := current.NotBefore.Add(schedule.interval)
+		if now.Before(createNextAt) {
+			syncCtx.Queue.AddAfter(syncCtx.Key, createNextAt.Sub(now))
+			return nil
+		}
+
+		nextCA, err := certauthority.New(caCommonName, approximatelyOneHundredYears)
+		if err != nil {
+			return fmt.Errorf("could not create impersonation CA: %w", err)
+		}
+		nextCAPrivateKeyPEM, err := nextCA.PrivateKeyToPEM()
+		if err != nil {
+			return err
+		}
+		return c.updateCASecret(syncCtx.Context, caSecret, "creating the next CA of the impersonation proxy", func(data map[string][]byte) {
+			data[nextCACrtKey] = nextCA.Bundle()
+			data[nextCAKeyKey] = nextCAPrivateKeyPEM
+		})
+	}
+
+	next, err := loadNextCA(caSecret)
+	if err != nil {
+		// Start the rotation over with a new CA, since this one can never be used.
+		c.logger.WarningErr("found an invalid next CA of the impersonation proxy", err, "secret", klog.KObj(caSecret))
+		return c.updateCASecret(syncCtx.Context, caSecret, "removing the next CA of the impersonation proxy", removeNextCA)
+	}
+
+	promoteNextAt := next.NotBefore.Add(schedule.overlap)
+	if now.Before(promoteNextAt) {
+		syncCtx.Queue.AddAfter(syncCtx.Key, promoteNextAt.Sub(now))
+		return nil
+	}
+
+	return c.updateCASecret(syncCtx.Context, caSecret, "replacing the CA of the impersonation proxy with the next CA", func(data map[string][]byte) {
+		data[caCrtKey] = data[nextCACrtKey]
+		data[caKeyKey] = data[nextCAKeyKey]
+		removeNextCA(data)
+	})
+}
+
+func (c *caRotationController) updateCASecret(ctx context.Context, caSecret *v1.Secret, msg string, mutate func(data map[string][]byte)) error {
+	updatedSecret := caSecret.DeepCopy()
+	mutate(updatedSecret.Data)
+
+	c.logger.Info(msg, "secret", klog.KObj(updatedSecret))
+	// The update fails when the Secret was changed since it was read, e.g. by another instance of the Concierge,
+	// in which case the Secret is synced again once the informer sees the change.
+	_, err := c.k8sClient.CoreV1().Secrets(c.namespace).Update(ctx, updatedSecret, metav1.UpdateOptions{})
+	return err
+}
+
+func removeNextCA(data map[string][]byte) {
+	delete(data, nextCACrtKey)
+	delete(data, nextCAKeyKey)
+}
+
+// loadNextCA returns the certificate of the next CA, after checking that it can be used with its private key.
+func loadNextCA(caSecret *v1.Secret) (*x509.Certificate, error) {
+	crtBytes := caSecret.Data[nextCACrtKey]
+	if _, err := certauthority.Load(string(crtBytes), string(caSecret.Data[nextCAKeyKey])); err != nil {
+		return nil, err
+	}
+	return parseCertificate(crtBytes)
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, constable.Error("certificate is not PEM-encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,308 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonatorconfig
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+func TestNewCARotationSchedule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *v1alpha1.ImpersonationProxyCertificateRotationSpec
+		want    *caRotationSchedule
+		wantErr string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "defaults",
+			spec: &v1alpha1.ImpersonationProxyCertificateRotationSpec{},
+			want: &caRotationSchedule{interval: 90 * 24 * time.Hour, overlap: 7 * 24 * time.Hour},
+		},
+		{
+			name: "configured",
+			spec: &v1alpha1.ImpersonationProxyCertificateRotationSpec{
+				IntervalSeconds: pointer.Int32(86400),
+				OverlapSeconds:  pointer.Int32(0),
+			},
+			want: &caRotationSchedule{interval: 24 * time.Hour},
+		},
+		{
+			name:    "interval too short",
+			spec:    &v1alpha1.ImpersonationProxyCertificateRotationSpec{IntervalSeconds: pointer.Int32(3600)},
+			wantErr: "invalid CertificateRotation IntervalSeconds 3600 (expected between 86400 and 315360000)",
+		},
+		{
+			name:    "negative overlap",
+			spec:    &v1alpha1.ImpersonationProxyCertificateRotationSpec{OverlapSeconds: pointer.Int32(-1)},
+			wantErr: "invalid CertificateRotation OverlapSeconds -1 (expected between 0 and 31536000)",
+		},
+		{
+			name:    "overlap longer than the default interval",
+			spec:    &v1alpha1.ImpersonationProxyCertificateRotationSpec{OverlapSeconds: pointer.Int32(90 * 24 * 60 * 60)},
+			wantErr: "invalid CertificateRotation OverlapSeconds 7776000 (expected less than IntervalSeconds 7776000)",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newCARotationSchedule(tt.spec)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type addAfterQueue struct {
+	addAfterDurations []time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *addAfterQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.addAfterDurations = append(q.addAfterDurations, duration)
+}
+
+func TestCARotationControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const (
+		namespace                    = "some-namespace"
+		credentialIssuerResourceName = "some-credential-issuer"
+		caSecretName                 = "some-ca-secret"
+	)
+
+	newCASecretData := func(t *testing.T) (map[string][]byte, time.Time) {
+		t.Helper()
+		ca, err := certauthority.New(caCommonName, time.Hour)
+		require.NoError(t, err)
+		keyPEM, err := ca.PrivateKeyToPEM()
+		require.NoError(t, err)
+		cert, err := parseCertificate(ca.Bundle())
+		require.NoError(t, err)
+		return map[string][]byte{caCrtKey: ca.Bundle(), caKeyKey: keyPEM}, cert.NotBefore
+	}
+
+	rotation := &v1alpha1.ImpersonationProxyCertificateRotationSpec{
+		IntervalSeconds: pointer.Int32(2 * 86400),
+		OverlapSeconds:  pointer.Int32(3600),
+	}
+
+	type caSecretState int
+	const (
+		noCASecret caSecretState = iota
+		currentCAOnly
+		currentAndNextCA
+		currentAndInvalidNextCA
+	)
+
+	tests := []struct {
+		name                  string
+		noCredentialIssuer    bool
+		rotation              *v1alpha1.ImpersonationProxyCertificateRotationSpec
+		caSecret              caSecretState
+		sinceCreation         time.Duration // since the creation of the next CA when there is one, otherwise of the current CA
+		wantAddAfterDurations []time.Duration
+		wantNextCACreated     bool
+		wantNextCARemoved     bool
+		wantNextCAPromoted    bool
+	}{
+		{
+			name:               "no CredentialIssuer",
+			noCredentialIssuer: true,
+			rotation:           rotation,
+			caSecret:           currentCAOnly,
+			sinceCreation:      3 * 24 * time.Hour,
+		},
+		{
+			name:     "no CA Secret",
+			rotation: rotation,
+			caSecret: noCASecret,
+		},
+		{
+			name:          "rotation is not configured",
+			caSecret:      currentCAOnly,
+			sinceCreation: 3 * 24 * time.Hour,
+		},
+		{
+			name:              "rotation was disabled while it was in progress",
+			caSecret:          currentAndNextCA,
+			wantNextCARemoved: true,
+		},
+		{
+			name:          "rotation is invalid",
+			rotation:      &v1alpha1.ImpersonationProxyCertificateRotationSpec{IntervalSeconds: pointer.Int32(1)},
+			caSecret:      currentCAOnly,
+			sinceCreation: 3 * 24 * time.Hour,
+		},
+		{
+			name:                  "before the interval",
+			rotation:              rotation,
+			caSecret:              currentCAOnly,
+			sinceCreation:         24 * time.Hour,
+			wantAddAfterDurations: []time.Duration{24 * time.Hour},
+		},
+		{
+			name:              "after the interval",
+			rotation:          rotation,
+			caSecret:          currentCAOnly,
+			sinceCreation:     2 * 24 * time.Hour,
+			wantNextCACreated: true,
+		},
+		{
+			name:                  "before the end of the overlap",
+			rotation:              rotation,
+			caSecret:              currentAndNextCA,
+			sinceCreation:         20 * time.Minute,
+			wantAddAfterDurations: []time.Duration{40 * time.Minute},
+		},
+		{
+			name:               "after the end of the overlap",
+			rotation:           rotation,
+			caSecret:           currentAndNextCA,
+			sinceCreation:      time.Hour,
+			wantNextCAPromoted: true,
+		},
+		{
+			name:              "invalid next CA",
+			rotation:          rotation,
+			caSecret:          currentAndInvalidNextCA,
+			wantNextCARemoved: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var pinnipedObjects []runtime.Object
+			if !tt.noCredentialIssuer {
+				pinnipedObjects = append(pinnipedObjects, &v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode:                v1alpha1.ImpersonationProxyModeEnabled,
+							CertificateRotation: tt.rotation,
+						},
+					},
+				})
+			}
+
+			var kubeObjects []runtime.Object
+			var originalData map[string][]byte
+			createdAt := time.Now()
+			if tt.caSecret != noCASecret {
+				originalData, createdAt = newCASecretData(t)
+				switch tt.caSecret {
+				case currentAndNextCA:
+					var nextData map[string][]byte
+					nextData, createdAt = newCASecretData(t)
+					originalData[nextCACrtKey] = nextData[caCrtKey]
+					originalData[nextCAKeyKey] = nextData[caKeyKey]
+				case currentAndInvalidNextCA:
+					originalData[nextCACrtKey] = []byte("not a certificate")
+					originalData[nextCAKeyKey] = []byte("not a key")
+				case noCASecret, currentCAOnly:
+				}
+				kubeObjects = append(kubeObjects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: caSecretName, Namespace: namespace},
+					Data:       originalData,
+				})
+			}
+
+			pinnipedClient := pinnipedfake.NewSimpleClientset(pinnipedObjects...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedClient, 0)
+			kubeClient := kubernetesfake.NewSimpleClientset(kubeObjects...)
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+
+			controller := NewCARotationController(
+				namespace,
+				credentialIssuerResourceName,
+				caSecretName,
+				kubeClient,
+				pinnipedInformers.Config().V1alpha1().CredentialIssuers(),
+				kubeInformers.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+				clocktesting.NewFakeClock(createdAt.Add(tt.sinceCreation)),
+				plog.TestLogger(t, io.Discard),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			queue := &addAfterQueue{}
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}, Queue: queue}
+
+			require.NoError(t, controllerlib.TestSync(t, controller, syncCtx))
+			require.Equal(t, tt.wantAddAfterDurations, queue.addAfterDurations)
+
+			var updates []*corev1.Secret
+			for _, action := range kubeClient.Actions() {
+				if action.GetVerb() == "update" {
+					updates = append(updates, action.(coretesting.UpdateAction).GetObject().(*corev1.Secret))
+				}
+			}
+			if !tt.wantNextCACreated && !tt.wantNextCARemoved && !tt.wantNextCAPromoted {
+				require.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			data := updates[0].Data
+
+			switch {
+			case tt.wantNextCACreated:
+				require.Len(t, data, 4)
+				require.Equal(t, originalData[caCrtKey], data[caCrtKey])
+				require.Equal(t, originalData[caKeyKey], data[caKeyKey])
+				_, err := certauthority.Load(string(data[nextCACrtKey]), string(data[nextCAKeyKey]))
+				require.NoError(t, err)
+			case tt.wantNextCARemoved:
+				require.Equal(t, map[string][]byte{
+					caCrtKey: originalData[caCrtKey],
+					caKeyKey: originalData[caKeyKey],
+				}, data)
+			case tt.wantNextCAPromoted:
+				require.Equal(t, map[string][]byte{
+					caCrtKey: originalData[nextCACrtKey],
+					caKeyKey: originalData[nextCAKeyKey],
+				}, data)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package impersonatorconfig
@@ -43,9 +43,11 @@ import (
 	"go.pinniped.dev/internal/controller/apicerts"
 	"go.pinniped.dev/internal/controller/issuerconfig"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/endpointaddr"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/proxyprotocol"
 )
 
 const (
@@ -83,11 +85,25 @@ type impersonatorConfigController struct {
 	hasControlPlaneNodes              *bool
 	serverStopCh                      chan struct{}
 	errorCh                           chan error
+	runningListenerSpec               listenerSpec
 	tlsServingCertDynamicCertProvider dynamiccert.Private
 	infoLog                           logr.Logger
 	debugLog                          logr.Logger
 }
 
+// listenerSpec is the configuration of the listener of the impersonation proxy, which cannot be changed without
+// restarting the impersonation proxy.
+type listenerSpec struct {
+	proxyProtocol *v1alpha1.ProxyProtocolSpec
+	tls           *v1alpha1.ImpersonationProxyTLSSpec
+}
+
+// equal compares the specs field by field, since equality.Semantic.DeepEqual panics on unexported fields.
+func (s listenerSpec) equal(other listenerSpec) bool {
+	return equality.Semantic.DeepEqual(s.proxyProtocol, other.proxyProtocol) &&
+		equality.Semantic.DeepEqual(s.tls, other.tls)
+}
+
 func NewImpersonatorConfigController(
 	namespace string,
 	credentialIssuerResourceName string,
@@ -251,7 +267,10 @@ func (c *impersonatorConfigController) doSync(syncCtx controllerlib.Context, cre
 	}
 
 	if c.shouldHaveImpersonator(impersonationSpec) {
-		if err = c.ensureImpersonatorIsStarted(syncCtx); err != nil {
+		if err = c.ensureImpersonatorIsStarted(syncCtx, listenerSpec{
+			proxyProtocol: impersonationSpec.ProxyProtocol,
+			tls:           impersonationSpec.TLS,
+		}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -286,8 +305,9 @@ func (c *impersonatorConfigController) doSync(syncCtx controllerlib.Context, cre
 	}
 
 	var impersonationCA *certauthority.CA
+	var caBundle []byte
 	if c.shouldHaveImpersonator(impersonationSpec) {
-		if impersonationCA, err = c.ensureCASecretIsCreated(ctx); err != nil {
+		if impersonationCA, caBundle, err = c.ensureCASecretIsCreated(ctx); err != nil {
 			return nil, err
 		}
 		if err = c.ensureTLSSecret(ctx, nameInfo, impersonationCA); err != nil {
@@ -300,7 +320,7 @@ func (c *impersonatorConfigController) doSync(syncCtx controllerlib.Context, cre
 		c.clearTLSSecret()
 	}
 
-	credentialIssuerStrategyResult := c.doSyncResult(nameInfo, impersonationSpec, impersonationCA)
+	credentialIssuerStrategyResult := c.doSyncResult(nameInfo, impersonationSpec, caBundle)
 
 	if c.shouldHaveImpersonator(impersonationSpec) {
 		if err = c.loadSignerCA(); err != nil {
@@ -380,7 +400,15 @@ func (c *impersonatorConfigController) tlsSecretExists() (bool, *v1.Secret, erro
 	return true, secret, nil
 }
 
-func (c *impersonatorConfigController) ensureImpersonatorIsStarted(syncCtx controllerlib.Context) error {
+func (c *impersonatorConfigController) ensureImpersonatorIsStarted(syncCtx controllerlib.Context, spec listenerSpec) error {
+	if c.serverStopCh != nil && !c.runningListenerSpec.equal(spec) {
+		// The listener of a running server cannot be reconfigured, so restart the server.
+		c.infoLog.Info("restarting impersonation proxy to change the configuration of its listener", "port", c.impersonationProxyPort)
+		if err := c.ensureImpersonatorIsStopped(true); err != nil {
+			return err
+		}
+	}
+
 	if c.serverStopCh != nil {
 		// The server was already started, but it could have died in the background, so make a non-blocking
 		// check to see if it has sent any errors on the errorCh.
@@ -404,15 +432,34 @@ func (c *impersonatorConfigController) ensureImpersonatorIsStarted(syncCtx contr
 		}
 	}
 
-	c.infoLog.Info("starting impersonation proxy", "port", c.impersonationProxyPort)
+	proxyProtocol, err := proxyProtocolConfig(spec.proxyProtocol)
+	if err != nil {
+		return err
+	}
+	tlsPolicy, err := tlsPolicyFromSpec(spec.tls)
+	if err != nil {
+		return err
+	}
+
+	c.infoLog.Info("starting impersonation proxy",
+		"port", c.impersonationProxyPort,
+		"proxyProtocol", proxyProtocol != nil,
+		"tlsPolicy", tlsPolicy != nil,
+	)
 	startImpersonatorFunc, err := c.impersonatorFunc(
 		c.impersonationProxyPort,
 		c.tlsServingCertDynamicCertProvider,
 		c.impersonationSigningCertProvider,
+		proxyProtocol,
+		tlsPolicy,
 	)
 	if err != nil {
 		return err
 	}
+	c.runningListenerSpec = listenerSpec{
+		proxyProtocol: spec.proxyProtocol.DeepCopy(),
+		tls:           spec.tls.DeepCopy(),
+	}
 
 	c.serverStopCh = make(chan struct{})
 	// use a buffered channel so that startImpersonatorFunc can send
@@ -449,12 +496,18 @@ func (c *impersonatorConfigController) ensureImpersonatorIsStopped(shouldCloseEr
 
 	c.serverStopCh = nil
 	c.errorCh = nil
+	c.runningListenerSpec = listenerSpec{}
 
 	return stopErr
 }
 
 func (c *impersonatorConfigController) ensureLoadBalancerIsStarted(ctx context.Context, config *v1alpha1.ImpersonationProxySpec) error {
 	appNameLabel := c.labels[appLabelKey]
+	var loadBalancerClass *string
+	if config.Service.LoadBalancerClass != "" {
+		class := config.Service.LoadBalancerClass
+		loadBalancerClass = &class
+	}
 	loadBalancer := v1.Service{
 		Spec: v1.ServiceSpec{
 			Type: v1.ServiceTypeLoadBalancer,
@@ -463,10 +516,13 @@ func (c *impersonatorConfigController) ensureLoadBalancerIsStarted(ctx context.C
 					TargetPort: intstr.FromInt(c.impersonationProxyPort),
 					Port:       defaultHTTPSPort,
 					Protocol:   v1.ProtocolTCP,
+					NodePort:   config.Service.NodePort,
 				},
 			},
-			LoadBalancerIP: config.Service.LoadBalancerIP,
-			Selector:       map[string]string{appLabelKey: appNameLabel},
+			LoadBalancerIP:        config.Service.LoadBalancerIP,
+			LoadBalancerClass:     loadBalancerClass,
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicy(config.Service.ExternalTrafficPolicy),
+			Selector:              map[string]string{appLabelKey: appNameLabel},
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        c.generatedLoadBalancerServiceName,
@@ -578,6 +634,19 @@ func (c *impersonatorConfigController) createOrUpdateService(ctx context.Context
 		return err
 	}
 
+	// The class of a load balancer cannot be changed, so delete the Service instead. It will be created again
+	// with the desired class during a future sync, after the informer has seen the deletion.
+	if !equality.Semantic.DeepEqual(existingService.Spec.LoadBalancerClass, desiredService.Spec.LoadBalancerClass) {
+		log.Info("deleting service for impersonation proxy to change its load balancer class")
+		err := c.k8sClient.CoreV1().Services(c.namespace).Delete(ctx, existingService.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{
+				UID:             &existingService.UID,
+				ResourceVersion: &existingService.ResourceVersion,
+			},
+		})
+		return utilerrors.FilterOut(err, k8serrors.IsNotFound)
+	}
+
 	// The Service already exists, so update only the specific fields that are meaningfully part of our desired state.
 	updatedService := existingService.DeepCopy()
 	updatedService.ObjectMeta.Labels = desiredService.ObjectMeta.Labels
@@ -585,6 +654,22 @@ func (c *impersonatorConfigController) createOrUpdateService(ctx context.Context
 	updatedService.Spec.Type = desiredService.Spec.Type
 	updatedService.Spec.Selector = desiredService.Spec.Selector
 
+	// Kubernetes chooses a node port when none was requested, so only overwrite the existing node port when one was.
+	for i := range updatedService.Spec.Ports {
+		if i < len(desiredService.Spec.Ports) && desiredService.Spec.Ports[i].NodePort != 0 {
+			updatedService.Spec.Ports[i].NodePort = desiredService.Spec.Ports[i].NodePort
+		}
+	}
+
+	// Kubernetes defaults the external traffic policy of a load balancer to Cluster, so only go back to Cluster
+	// when a policy was previously requested, to avoid updating the Service during every sync.
+	switch {
+	case desiredService.Spec.ExternalTrafficPolicy != "":
+		updatedService.Spec.ExternalTrafficPolicy = desiredService.Spec.ExternalTrafficPolicy
+	case updatedService.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyLocal:
+		updatedService.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyCluster
+	}
+
 	// Do not simply overwrite the existing annotations with the desired annotations. Instead, merge-overwrite.
 	// Another actor in the system, like a human user or a non-Pinniped controller, might have updated the
 	// existing Service's annotations. If they did, then we do not want to overwrite those keys expect for
@@ -777,25 +862,34 @@ func (c *impersonatorConfigController) ensureTLSSecretIsCreatedAndLoaded(ctx con
 	return nil
 }
 
-func (c *impersonatorConfigController) ensureCASecretIsCreated(ctx context.Context) (*certauthority.CA, error) {
+// ensureCASecretIsCreated returns the current CA and the CA bundle which clients should trust. While the CA is being
+// rotated, the bundle also contains the next CA, which will issue the serving certificate after the rotation.
+func (c *impersonatorConfigController) ensureCASecretIsCreated(ctx context.Context) (*certauthority.CA, []byte, error) {
 	caSecret, err := c.secretsInformer.Lister().Secrets(c.namespace).Get(c.caSecretName)
 	if err != nil && !k8serrors.IsNotFound(err) {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var impersonationCA *certauthority.CA
 	if k8serrors.IsNotFound(err) {
-		impersonationCA, err = c.createCASecret(ctx)
-	} else {
-		crtBytes := caSecret.Data[caCrtKey]
-		keyBytes := caSecret.Data[caKeyKey]
-		impersonationCA, err = certauthority.Load(string(crtBytes), string(keyBytes))
+		impersonationCA, err := c.createCASecret(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return impersonationCA, impersonationCA.Bundle(), nil
 	}
+
+	crtBytes := caSecret.Data[caCrtKey]
+	keyBytes := caSecret.Data[caKeyKey]
+	impersonationCA, err := certauthority.Load(string(crtBytes), string(keyBytes))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return impersonationCA, nil
+	caBundle := impersonationCA.Bundle()
+	if nextCrtBytes := caSecret.Data[nextCACrtKey]; len(nextCrtBytes) > 0 {
+		caBundle = append(caBundle, nextCrtBytes...)
+	}
+	return impersonationCA, caBundle, nil
 }
 
 func (c *impersonatorConfigController) createCASecret(ctx context.Context) (*certauthority.CA, error) {
@@ -1018,7 +1112,7 @@ func (c *impersonatorConfigController) clearSignerCA() {
 	c.impersonationSigningCertProvider.UnsetCertKeyContent()
 }
 
-func (c *impersonatorConfigController) doSyncResult(nameInfo *certNameInfo, config *v1alpha1.ImpersonationProxySpec, ca *certauthority.CA) *v1alpha1.CredentialIssuerStrategy {
+func (c *impersonatorConfigController) doSyncResult(nameInfo *certNameInfo, config *v1alpha1.ImpersonationProxySpec, caBundle []byte) *v1alpha1.CredentialIssuerStrategy {
 	switch {
 	case c.disabledExplicitly(config):
 		return &v1alpha1.CredentialIssuerStrategy{
@@ -1055,7 +1149,7 @@ func (c *impersonatorConfigController) doSyncResult(nameInfo *certNameInfo, conf
 				Type: v1alpha1.ImpersonationProxyFrontendType,
 				ImpersonationProxyInfo: &v1alpha1.ImpersonationProxyInfo{
 					Endpoint:                 "https://" + nameInfo.clientEndpoint,
-					CertificateAuthorityData: base64.StdEncoding.EncodeToString(ca.Bundle()),
+					CertificateAuthorityData: base64.StdEncoding.EncodeToString(caBundle),
 				},
 			},
 		}
@@ -1091,6 +1185,23 @@ func validateCredentialIssuerSpec(spec *v1alpha1.ImpersonationProxySpec) error {
 		return fmt.Errorf("invalid LoadBalancerIP %q", spec.Service.LoadBalancerIP)
 	}
 
+	// If specified, validate that the LoadBalancerClass is a label-style key, as required by Kubernetes.
+	if class := spec.Service.LoadBalancerClass; class != "" && len(validation.IsQualifiedName(class)) > 0 {
+		return fmt.Errorf("invalid LoadBalancerClass %q", spec.Service.LoadBalancerClass)
+	}
+
+	if port := spec.Service.NodePort; port != 0 && len(validation.IsValidPortNum(int(port))) > 0 {
+		return fmt.Errorf("invalid NodePort %d", spec.Service.NodePort)
+	}
+
+	switch v1.ServiceExternalTrafficPolicy(spec.Service.ExternalTrafficPolicy) {
+	case "":
+	case v1.ServiceExternalTrafficPolicyCluster:
+	case v1.ServiceExternalTrafficPolicyLocal:
+	default:
+		return fmt.Errorf("invalid ExternalTrafficPolicy %q (expected Cluster or Local)", spec.Service.ExternalTrafficPolicy)
+	}
+
 	// If service is type "None", a non-empty external endpoint must be specified.
 	if spec.ExternalEndpoint == "" && spec.Service.Type == v1alpha1.ImpersonationProxyServiceTypeNone {
 		return fmt.Errorf("externalEndpoint must be set when service.type is None")
@@ -1102,5 +1213,48 @@ func validateCredentialIssuerSpec(spec *v1alpha1.ImpersonationProxySpec) error {
 		}
 	}
 
+	if _, err := proxyProtocolConfig(spec.ProxyProtocol); err != nil {
+		return err
+	}
+
+	if _, err := tlsPolicyFromSpec(spec.TLS); err != nil {
+		return err
+	}
+
+	if _, err := newCARotationSchedule(spec.CertificateRotation); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// proxyProtocolConfig returns the configuration of the PROXY protocol listener of the impersonation proxy,
+// or nil when the PROXY protocol should not be accepted.
+func proxyProtocolConfig(spec *v1alpha1.ProxyProtocolSpec) (*proxyprotocol.Config, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	config := &proxyprotocol.Config{}
+	for _, cidr := range spec.TrustedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ProxyProtocol TrustedCIDRs %q", cidr)
+		}
+		config.TrustedNetworks = append(config.TrustedNetworks, network)
+	}
+	return config, nil
+}
+
+// tlsPolicyFromSpec returns the TLS policy of the impersonation proxy, or nil when the defaults should be used.
+func tlsPolicyFromSpec(spec *v1alpha1.ImpersonationProxyTLSSpec) (*ptls.Policy, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	policy, err := ptls.NewPolicy(spec.MinVersion, spec.CipherSuites, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS: %w", err)
+	}
+	return policy, nil
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package impersonatorconfig
@@ -34,6 +34,7 @@ import (
 	kubernetesfake "k8s.io/client-go/kubernetes/fake"
 	coretesting "k8s.io/client-go/testing"
 	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
 	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
@@ -41,9 +42,11 @@ import (
 	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/controller/apicerts"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/proxyprotocol"
 	"go.pinniped.dev/internal/testutil"
 )
 
@@ -283,6 +286,8 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 		var impersonatorFuncWasCalled int
 		var impersonatorFuncError error
 		var impersonatorFuncReturnedFuncError error
+		var impersonatorFuncProxyProtocol *proxyprotocol.Config
+		var impersonatorFuncTLSPolicy *ptls.Policy
 		var startedTLSListener net.Listener
 		var startedTLSListenerMutex sync.RWMutex
 		var testHTTPServer *http.Server
@@ -295,8 +300,12 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 			port int,
 			dynamicCertProvider dynamiccert.Private,
 			impersonationProxySignerCAProvider dynamiccert.Public,
+			proxyProtocol *proxyprotocol.Config,
+			tlsPolicy *ptls.Policy,
 		) (func(stopCh <-chan struct{}) error, error) {
 			impersonatorFuncWasCalled++
+			impersonatorFuncProxyProtocol = proxyProtocol
+			impersonatorFuncTLSPolicy = tlsPolicy
 			r.Equal(8444, port)
 			r.NotNil(dynamicCertProvider)
 			r.NotNil(impersonationProxySignerCAProvider)
@@ -514,6 +523,19 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 			requireTLSSecretProviderIsEmpty()
 		}
 
+		// When a running server is restarted, the background routine of the old server re-enqueues the sync key
+		// as it stops. Wait for that, then forget about it, so that the next restart can enqueue it again.
+		var requireRestartWasEnqueued = func() {
+			r.Eventually(func() bool {
+				queue.mutex.RLock() // this is to satisfy the race detector
+				defer queue.mutex.RUnlock()
+				return syncContext.Key == queue.key
+			}, 10*time.Second, 10*time.Millisecond)
+			queue.mutex.Lock()
+			defer queue.mutex.Unlock()
+			queue.key = controllerlib.Key{}
+		}
+
 		var requireTLSServerIsNoLongerRunning = func() {
 			r.Greater(impersonatorFuncWasCalled, 0)
 			var err error
@@ -1698,6 +1720,44 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 				})
 			})
 
+			when("a load balancer and a secret already exist and the CA is being rotated", func() {
+				var caCrt, nextCACrt []byte
+				it.Before(func() {
+					addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+						ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+						Spec: v1alpha1.CredentialIssuerSpec{
+							ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+								Mode:                v1alpha1.ImpersonationProxyModeEnabled,
+								CertificateRotation: &v1alpha1.ImpersonationProxyCertificateRotationSpec{},
+							},
+						},
+					}, pinnipedInformerClient, pinnipedAPIClient)
+					addNodeWithRoleToTracker("worker", kubeAPIClient)
+					ca := newCA()
+					caSecret := newActualCASecret(ca, caSecretName)
+					nextCASecretData := newCACertSecretData(newCA())
+					caSecret.Data["next-ca.crt"] = nextCASecretData["ca.crt"]
+					caSecret.Data["next-ca.key"] = nextCASecretData["ca.key"]
+					caCrt = caSecret.Data["ca.crt"]
+					nextCACrt = caSecret.Data["next-ca.crt"]
+					addSecretToTrackers(caSecret, kubeAPIClient, kubeInformerClient)
+					tlsSecret := newActualTLSSecret(ca, tlsSecretName, localhostIP)
+					addSecretToTrackers(tlsSecret, kubeAPIClient, kubeInformerClient)
+					addLoadBalancerServiceWithIngressToTracker(loadBalancerServiceName, []corev1.LoadBalancerIngress{{IP: localhostIP}}, kubeInformerClient)
+					addLoadBalancerServiceWithIngressToTracker(loadBalancerServiceName, []corev1.LoadBalancerIngress{{IP: localhostIP}}, kubeAPIClient)
+				})
+
+				it("keeps serving the existing tls certs and publishes both the current and the next CA", func() {
+					startInformersAndController()
+					r.NoError(runControllerSync())
+					r.Len(kubeAPIClient.Actions(), 1)
+					requireNodesListed(kubeAPIClient.Actions()[0])
+					requireTLSServerIsRunning(caCrt, testServerAddr(), nil)
+					requireCredentialIssuer(newSuccessStrategy(localhostIP, append(append([]byte{}, caCrt...), nextCACrt...)))
+					requireSigningCertProviderHasLoadedCerts(signingCACertPEM, signingCAKeyPEM)
+				})
+			})
+
 			when("credentialissuer has service type loadbalancer and custom annotations", func() {
 				it.Before(func() {
 					addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
@@ -2393,6 +2453,144 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 			})
 		})
 
+		when("the PROXY protocol configuration changes while the impersonator is running", func() {
+			it.Before(func() {
+				addSecretToTrackers(signingCASecret, kubeInformerClient)
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode: v1alpha1.ImpersonationProxyModeEnabled,
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+				addNodeWithRoleToTracker("worker", kubeAPIClient)
+			})
+
+			it("restarts the impersonator with the new configuration", func() {
+				startInformersAndController()
+
+				r.NoError(runControllerSync())
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(1, impersonatorFuncWasCalled)
+				r.Nil(impersonatorFuncProxyProtocol)
+				r.Len(kubeAPIClient.Actions(), 3)
+				requireNodesListed(kubeAPIClient.Actions()[0])
+				requireLoadBalancerWasCreated(kubeAPIClient.Actions()[1])
+				requireCASecretWasCreated(kubeAPIClient.Actions()[2])
+				requireCredentialIssuer(newPendingStrategyWaitingForLB())
+
+				// Simulate the informer cache's background update from its watch.
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[1], kubeInformers.Core().V1().Services())
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[2], kubeInformers.Core().V1().Secrets())
+
+				// Start accepting the PROXY protocol.
+				updateCredentialIssuerInInformerAndWait(credentialIssuerResourceName, v1alpha1.CredentialIssuerSpec{
+					ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+						Mode:          v1alpha1.ImpersonationProxyModeEnabled,
+						ProxyProtocol: &v1alpha1.ProxyProtocolSpec{TrustedCIDRs: []string{"10.0.0.0/8"}},
+					},
+				}, pinnipedInformers.Config().V1alpha1().CredentialIssuers())
+
+				r.NoError(runControllerSync())
+				requireRestartWasEnqueued()
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(2, impersonatorFuncWasCalled)
+				_, trustedNetwork, err := net.ParseCIDR("10.0.0.0/8")
+				r.NoError(err)
+				r.Equal(&proxyprotocol.Config{TrustedNetworks: []*net.IPNet{trustedNetwork}}, impersonatorFuncProxyProtocol)
+				r.Len(kubeAPIClient.Actions(), 3)
+				requireCredentialIssuer(newPendingStrategyWaitingForLB())
+
+				// The impersonator is not restarted when the configuration is unchanged.
+				r.NoError(runControllerSync())
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(2, impersonatorFuncWasCalled)
+
+				// Stop accepting the PROXY protocol.
+				updateCredentialIssuerInInformerAndWait(credentialIssuerResourceName, v1alpha1.CredentialIssuerSpec{
+					ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+						Mode: v1alpha1.ImpersonationProxyModeEnabled,
+					},
+				}, pinnipedInformers.Config().V1alpha1().CredentialIssuers())
+
+				r.NoError(runControllerSync())
+				requireRestartWasEnqueued()
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(3, impersonatorFuncWasCalled)
+				r.Nil(impersonatorFuncProxyProtocol)
+			})
+		})
+
+		when("the TLS configuration changes while the impersonator is running", func() {
+			it.Before(func() {
+				addSecretToTrackers(signingCASecret, kubeInformerClient)
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode: v1alpha1.ImpersonationProxyModeEnabled,
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+				addNodeWithRoleToTracker("worker", kubeAPIClient)
+			})
+
+			it("restarts the impersonator with the new configuration", func() {
+				startInformersAndController()
+
+				r.NoError(runControllerSync())
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(1, impersonatorFuncWasCalled)
+				r.Nil(impersonatorFuncTLSPolicy)
+				r.Len(kubeAPIClient.Actions(), 3)
+				requireNodesListed(kubeAPIClient.Actions()[0])
+				requireLoadBalancerWasCreated(kubeAPIClient.Actions()[1])
+				requireCASecretWasCreated(kubeAPIClient.Actions()[2])
+				requireCredentialIssuer(newPendingStrategyWaitingForLB())
+
+				// Simulate the informer cache's background update from its watch.
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[1], kubeInformers.Core().V1().Services())
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[2], kubeInformers.Core().V1().Secrets())
+
+				// Only accept TLS 1.3.
+				updateCredentialIssuerInInformerAndWait(credentialIssuerResourceName, v1alpha1.CredentialIssuerSpec{
+					ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+						Mode: v1alpha1.ImpersonationProxyModeEnabled,
+						TLS:  &v1alpha1.ImpersonationProxyTLSSpec{MinVersion: "1.3"},
+					},
+				}, pinnipedInformers.Config().V1alpha1().CredentialIssuers())
+
+				r.NoError(runControllerSync())
+				requireRestartWasEnqueued()
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(2, impersonatorFuncWasCalled)
+				wantPolicy, err := ptls.NewPolicy("1.3", nil, nil)
+				r.NoError(err)
+				r.Equal(wantPolicy, impersonatorFuncTLSPolicy)
+				r.Len(kubeAPIClient.Actions(), 3)
+				requireCredentialIssuer(newPendingStrategyWaitingForLB())
+
+				// The impersonator is not restarted when the configuration is unchanged.
+				r.NoError(runControllerSync())
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(2, impersonatorFuncWasCalled)
+
+				// Go back to the defaults.
+				updateCredentialIssuerInInformerAndWait(credentialIssuerResourceName, v1alpha1.CredentialIssuerSpec{
+					ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+						Mode: v1alpha1.ImpersonationProxyModeEnabled,
+					},
+				}, pinnipedInformers.Config().V1alpha1().CredentialIssuers())
+
+				r.NoError(runControllerSync())
+				requireRestartWasEnqueued()
+				requireTLSServerIsRunningWithoutCerts()
+				r.Equal(3, impersonatorFuncWasCalled)
+				r.Nil(impersonatorFuncTLSPolicy)
+			})
+		})
+
 		when("the endpoint and mode switch from specified with no service, to not specified, to specified again", func() {
 			it.Before(func() {
 				addSecretToTrackers(signingCASecret, kubeInformerClient)
@@ -2868,6 +3066,72 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 			})
 		})
 
+		when("requesting a load balancer via CredentialIssuer with a class, a node port, and an external traffic policy", func() {
+			var spec v1alpha1.CredentialIssuerSpec
+
+			it.Before(func() {
+				spec = v1alpha1.CredentialIssuerSpec{
+					ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+						Mode:             v1alpha1.ImpersonationProxyModeEnabled,
+						ExternalEndpoint: localhostIP,
+						Service: v1alpha1.ImpersonationProxyServiceSpec{
+							Type:                  v1alpha1.ImpersonationProxyServiceTypeLoadBalancer,
+							LoadBalancerClass:     "example.com/some-load-balancer",
+							NodePort:              30443,
+							ExternalTrafficPolicy: "Local",
+						},
+					},
+				}
+				addSecretToTrackers(signingCASecret, kubeInformerClient)
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec:       spec,
+				}, pinnipedInformerClient, pinnipedAPIClient)
+				addNodeWithRoleToTracker("worker", kubeAPIClient)
+			})
+
+			it("creates the load balancer with those settings, updates it when they change, and deletes it when the class changes", func() {
+				startInformersAndController()
+
+				r.NoError(runControllerSync())
+				r.Len(kubeAPIClient.Actions(), 4)
+				requireNodesListed(kubeAPIClient.Actions()[0])
+				lbService := requireLoadBalancerWasCreated(kubeAPIClient.Actions()[1])
+				require.Equal(t, pointer.String("example.com/some-load-balancer"), lbService.Spec.LoadBalancerClass)
+				require.Equal(t, int32(30443), lbService.Spec.Ports[0].NodePort)
+				require.Equal(t, corev1.ServiceExternalTrafficPolicyLocal, lbService.Spec.ExternalTrafficPolicy)
+				ca := requireCASecretWasCreated(kubeAPIClient.Actions()[2])
+				requireTLSSecretWasCreated(kubeAPIClient.Actions()[3], ca)
+				requireCredentialIssuer(newSuccessStrategy(localhostIP, ca))
+
+				// Simulate the informer cache's background update from its watch.
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[1], kubeInformers.Core().V1().Services())
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[2], kubeInformers.Core().V1().Secrets())
+				addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[3], kubeInformers.Core().V1().Secrets())
+
+				// Change the node port and stop requesting an external traffic policy.
+				spec.ImpersonationProxy.Service.NodePort = 30444
+				spec.ImpersonationProxy.Service.ExternalTrafficPolicy = ""
+				updateCredentialIssuerInInformerAndWait(credentialIssuerResourceName, spec, pinnipedInformers.Config().V1alpha1().CredentialIssuers())
+
+				r.NoError(runControllerSync())
+				r.Len(kubeAPIClient.Actions(), 5) // one more item to update the loadbalancer
+				lbService = requireLoadBalancerWasUpdated(kubeAPIClient.Actions()[4])
+				require.Equal(t, pointer.String("example.com/some-load-balancer"), lbService.Spec.LoadBalancerClass)
+				require.Equal(t, int32(30444), lbService.Spec.Ports[0].NodePort)
+				require.Equal(t, corev1.ServiceExternalTrafficPolicyCluster, lbService.Spec.ExternalTrafficPolicy)
+
+				// Change the class, which cannot be updated.
+				spec.ImpersonationProxy.Service.LoadBalancerClass = "example.com/other-load-balancer"
+				updateCredentialIssuerInInformerAndWait(credentialIssuerResourceName, spec, pinnipedInformers.Config().V1alpha1().CredentialIssuers())
+
+				r.NoError(runControllerSync())
+				r.Len(kubeAPIClient.Actions(), 6) // one more item to delete the loadbalancer
+				requireServiceWasDeleted(kubeAPIClient.Actions()[5], loadBalancerServiceName)
+				requireTLSServerIsRunning(ca, testServerAddr(), nil)
+			})
+		})
+
 		when("sync is called more than once", func() {
 			it.Before(func() {
 				addSecretToTrackers(signingCASecret, kubeInformerClient)
@@ -3258,6 +3522,56 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 			})
 		})
 
+		when("the CredentialIssuer has invalid LoadBalancerClass", func() {
+			it.Before(func() {
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode: v1alpha1.ImpersonationProxyModeEnabled,
+							Service: v1alpha1.ImpersonationProxyServiceSpec{
+								LoadBalancerClass: "not a valid class!",
+							},
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+			})
+
+			it("returns an error", func() {
+				startInformersAndController()
+				errString := `could not load CredentialIssuer spec.impersonationProxy: invalid LoadBalancerClass "not a valid class!"`
+				r.EqualError(runControllerSync(), errString)
+				requireCredentialIssuer(newErrorStrategy(errString))
+				requireSigningCertProviderIsEmpty()
+				requireTLSServerWasNeverStarted()
+			})
+		})
+
+		when("the CredentialIssuer has invalid ExternalTrafficPolicy", func() {
+			it.Before(func() {
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode: v1alpha1.ImpersonationProxyModeEnabled,
+							Service: v1alpha1.ImpersonationProxyServiceSpec{
+								ExternalTrafficPolicy: "not-valid",
+							},
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+			})
+
+			it("returns an error", func() {
+				startInformersAndController()
+				errString := `could not load CredentialIssuer spec.impersonationProxy: invalid ExternalTrafficPolicy "not-valid" (expected Cluster or Local)`
+				r.EqualError(runControllerSync(), errString)
+				requireCredentialIssuer(newErrorStrategy(errString))
+				requireSigningCertProviderIsEmpty()
+				requireTLSServerWasNeverStarted()
+			})
+		})
+
 		when("the CredentialIssuer has invalid ExternalEndpoint", func() {
 			it.Before(func() {
 				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
@@ -3281,6 +3595,81 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 			})
 		})
 
+		when("the CredentialIssuer has invalid ProxyProtocol TrustedCIDRs", func() {
+			it.Before(func() {
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode:          v1alpha1.ImpersonationProxyModeEnabled,
+							ProxyProtocol: &v1alpha1.ProxyProtocolSpec{TrustedCIDRs: []string{"10.0.0.0/8", "10.0.0.1"}},
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+			})
+
+			it("returns an error", func() {
+				startInformersAndController()
+				errString := `could not load CredentialIssuer spec.impersonationProxy: invalid ProxyProtocol TrustedCIDRs "10.0.0.1"`
+				r.EqualError(runControllerSync(), errString)
+				requireCredentialIssuer(newErrorStrategy(errString))
+				requireSigningCertProviderIsEmpty()
+				requireTLSServerWasNeverStarted()
+			})
+		})
+
+		when("the CredentialIssuer has an invalid TLS configuration", func() {
+			it.Before(func() {
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode: v1alpha1.ImpersonationProxyModeEnabled,
+							TLS: &v1alpha1.ImpersonationProxyTLSSpec{
+								MinVersion:   "1.3",
+								CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+							},
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+			})
+
+			it("returns an error", func() {
+				startInformersAndController()
+				errString := `could not load CredentialIssuer spec.impersonationProxy: invalid TLS: cipherSuites cannot be configured when minVersion is 1.3`
+				r.EqualError(runControllerSync(), errString)
+				requireCredentialIssuer(newErrorStrategy(errString))
+				requireSigningCertProviderIsEmpty()
+				requireTLSServerWasNeverStarted()
+			})
+		})
+
+		when("the CredentialIssuer has an invalid CertificateRotation", func() {
+			it.Before(func() {
+				addCredentialIssuerToTrackers(v1alpha1.CredentialIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: credentialIssuerResourceName},
+					Spec: v1alpha1.CredentialIssuerSpec{
+						ImpersonationProxy: &v1alpha1.ImpersonationProxySpec{
+							Mode: v1alpha1.ImpersonationProxyModeEnabled,
+							CertificateRotation: &v1alpha1.ImpersonationProxyCertificateRotationSpec{
+								IntervalSeconds: pointer.Int32(86400),
+								OverlapSeconds:  pointer.Int32(86400),
+							},
+						},
+					},
+				}, pinnipedInformerClient, pinnipedAPIClient)
+			})
+
+			it("returns an error", func() {
+				startInformersAndController()
+				errString := `could not load CredentialIssuer spec.impersonationProxy: invalid CertificateRotation OverlapSeconds 86400 (expected less than IntervalSeconds 86400)`
+				r.EqualError(runControllerSync(), errString)
+				requireCredentialIssuer(newErrorStrategy(errString))
+				requireSigningCertProviderIsEmpty()
+				requireTLSServerWasNeverStarted()
+			})
+		})
+
 		when("there is an error creating the load balancer", func() {
 			it.Before(func() {
 				addNodeWithRoleToTracker("worker", kubeAPIClient)
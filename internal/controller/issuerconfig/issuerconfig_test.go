@@ -1,4 +1,4 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package issuerconfig
@@ -225,6 +225,7 @@ func TestMergeStrategy(t *testing.T) {
 func TestStrategySorting(t *testing.T) {
 	expected := []v1alpha1.CredentialIssuerStrategy{
 		{Type: v1alpha1.KubeClusterSigningCertificateStrategyType},
+		{Type: v1alpha1.CertificateSigningRequestAPIStrategyType},
 		{Type: v1alpha1.ImpersonationProxyStrategyType},
 		{Type: "Type1"},
 		{Type: "Type2"},
@@ -1,4 +1,4 @@
-// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package kubecertagent provides controllers that ensure a pod (the kube-cert-agent), is
@@ -26,6 +26,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	appsv1informers "k8s.io/client-go/informers/apps/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
@@ -304,15 +305,8 @@ func (c *agentController) Sync(ctx controllerlib.Context) error {
 	}
 
 	// Load the Kubernetes API info from the kube-public/cluster-info ConfigMap.
-	configMap, err := c.kubePublicConfigMaps.Lister().ConfigMaps(ClusterInfoNamespace).Get(clusterInfoName)
+	apiInfo, err := LoadAPIInfo(c.kubePublicConfigMaps.Lister(), c.cfg.DiscoveryURLOverride)
 	if err != nil {
-		err := fmt.Errorf("failed to get %s/%s configmap: %w", ClusterInfoNamespace, clusterInfoName, err)
-		return c.failStrategyAndErr(ctx.Context, credIssuer, firstErr(depErr, err), configv1alpha1.CouldNotGetClusterInfoStrategyReason)
-	}
-
-	apiInfo, err := c.extractAPIInfo(configMap)
-	if err != nil {
-		err := fmt.Errorf("could not extract Kubernetes API endpoint info from %s/%s configmap: %w", ClusterInfoNamespace, clusterInfoName, err)
 		return c.failStrategyAndErr(ctx.Context, credIssuer, firstErr(depErr, err), configv1alpha1.CouldNotGetClusterInfoStrategyReason)
 	}
 
@@ -328,6 +322,7 @@ func (c *agentController) Sync(ctx controllerlib.Context) error {
 	}
 
 	// Set the CredentialIssuer strategy to successful.
+	observeHealthy()
 	return issuerconfig.Update(ctx.Context, c.client.PinnipedConcierge, credIssuer, configv1alpha1.CredentialIssuerStrategy{
 		Type:           configv1alpha1.KubeClusterSigningCertificateStrategyType,
 		Status:         configv1alpha1.SuccessStrategyStatus,
@@ -446,6 +441,7 @@ func (c *agentController) createOrUpdateDeployment(ctx controllerlib.Context, ne
 }
 
 func (c *agentController) failStrategyAndErr(ctx context.Context, credIssuer *configv1alpha1.CredentialIssuer, err error, reason configv1alpha1.StrategyReason) error {
+	observeUnhealthy(reason)
 	updateErr := issuerconfig.Update(ctx, c.client.PinnipedConcierge, credIssuer, configv1alpha1.CredentialIssuerStrategy{
 		Type:           configv1alpha1.KubeClusterSigningCertificateStrategyType,
 		Status:         configv1alpha1.ErrorStrategyStatus,
@@ -456,7 +452,22 @@ func (c *agentController) failStrategyAndErr(ctx context.Context, credIssuer *co
 	return utilerrors.NewAggregate([]error{err, updateErr})
 }
 
-func (c *agentController) extractAPIInfo(configMap *corev1.ConfigMap) (*configv1alpha1.TokenCredentialRequestAPIInfo, error) {
+// LoadAPIInfo loads the Kubernetes API info for the TokenCredentialRequest API frontend from the
+// kube-public/cluster-info ConfigMap. The server is replaced by discoveryURLOverride when it is set.
+func LoadAPIInfo(configMaps corev1listers.ConfigMapLister, discoveryURLOverride *string) (*configv1alpha1.TokenCredentialRequestAPIInfo, error) {
+	configMap, err := configMaps.ConfigMaps(ClusterInfoNamespace).Get(clusterInfoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s configmap: %w", ClusterInfoNamespace, clusterInfoName, err)
+	}
+
+	apiInfo, err := extractAPIInfo(configMap, discoveryURLOverride)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract Kubernetes API endpoint info from %s/%s configmap: %w", ClusterInfoNamespace, clusterInfoName, err)
+	}
+	return apiInfo, nil
+}
+
+func extractAPIInfo(configMap *corev1.ConfigMap, discoveryURLOverride *string) (*configv1alpha1.TokenCredentialRequestAPIInfo, error) {
 	kubeConfigYAML, kubeConfigPresent := configMap.Data[clusterInfoConfigMapKey]
 	if !kubeConfigPresent {
 		return nil, fmt.Errorf("missing %q key", clusterInfoConfigMapKey)
@@ -473,8 +484,8 @@ func (c *agentController) extractAPIInfo(configMap *corev1.ConfigMap) (*configv1
 			Server:                   v.Server,
 			CertificateAuthorityData: base64.StdEncoding.EncodeToString(v.CertificateAuthorityData),
 		}
-		if c.cfg.DiscoveryURLOverride != nil {
-			result.Server = *c.cfg.DiscoveryURLOverride
+		if discoveryURLOverride != nil {
+			result.Server = *discoveryURLOverride
 		}
 		return result, nil
 	}
@@ -0,0 +1,59 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubecertagent
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+)
+
+const (
+	metricsNamespace = "pinniped_concierge"
+	metricsSubsystem = "kube_cert_agent"
+
+	metricsLabelReason = "reason"
+)
+
+//nolint:gochecknoglobals // Metrics are registered once with the global registry which is served by the Concierge.
+var (
+	healthy = metrics.NewGauge(&metrics.GaugeOpts{
+		Namespace:      metricsNamespace,
+		Subsystem:      metricsSubsystem,
+		Name:           "healthy",
+		Help:           "Whether the signing key of the cluster was fetched by the kube cert agent during the last sync (1) or not (0).",
+		StabilityLevel: metrics.ALPHA,
+	})
+
+	syncFailures = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      metricsNamespace,
+		Subsystem:      metricsSubsystem,
+		Name:           "sync_failures_total",
+		Help:           "Number of syncs of the kube cert agent which could not fetch the signing key of the cluster, partitioned by reason.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{metricsLabelReason})
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the kube cert agent with the global registry, which is served on the
+// /metrics endpoint of the Concierge's aggregated API server and on its metrics listener. It is safe to call more
+// than once.
+func RegisterMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(healthy, syncFailures)
+	})
+}
+
+func observeHealthy() {
+	healthy.Set(1)
+}
+
+func observeUnhealthy(reason configv1alpha1.StrategyReason) {
+	healthy.Set(0)
+	syncFailures.WithLabelValues(string(reason)).Inc()
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubecertagent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/metrics/testutil"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+)
+
+func TestHealthMetrics(t *testing.T) {
+	RegisterMetrics()
+	RegisterMetrics() // registering again is a no-op
+
+	// Other tests also sync the controller, so only compare the number of failures to its starting value.
+	failures := func() float64 {
+		t.Helper()
+		total, err := testutil.GetCounterMetricValue(syncFailures.WithLabelValues(string(configv1alpha1.CouldNotGetClusterInfoStrategyReason)))
+		require.NoError(t, err)
+		return total
+	}
+	requireHealthy := func(want float64) {
+		t.Helper()
+		value, err := testutil.GetGaugeMetricValue(healthy)
+		require.NoError(t, err)
+		require.Equal(t, want, value)
+	}
+	startingFailures := failures()
+
+	observeUnhealthy(configv1alpha1.CouldNotGetClusterInfoStrategyReason)
+	requireHealthy(0)
+	require.Equal(t, startingFailures+1, failures())
+
+	observeHealthy()
+	requireHealthy(1)
+	require.Equal(t, startingFailures+1, failures())
+
+	observeUnhealthy(configv1alpha1.CouldNotGetClusterInfoStrategyReason)
+	requireHealthy(0)
+	require.Equal(t, startingFailures+2, failures())
+}
@@ -0,0 +1,394 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	configinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// acmeIssuedAnnotation marks the TLS Secrets which hold a certificate from the ACME server. Only those Secrets are
+	// renewed, so that TLS Secrets which were created by other means are never overwritten.
+	acmeIssuedAnnotation = "supervisor.pinniped.dev/acme-issued"
+
+	acmeAccountSecretType corev1.SecretType = "secrets.pinniped.dev/acme-account"
+	acmeAccountKeyKey                       = "privateKey"
+
+	// How long to wait for the ACME server to issue a certificate.
+	acmeIssueTimeout = 10 * time.Minute
+
+	// How long to wait before requesting the certificate of a Secret again after a failure, so that failures do not
+	// exceed the rate limits of the ACME server.
+	acmeRetryInterval = 15 * time.Minute
+
+	// How often the pods which are not the leader check whether they have become the leader.
+	acmeNotLeaderInterval = time.Minute
+
+	acmeCertificateIssuedReason = "ACMECertificateIssued"
+	acmeCertificateFailedReason = "ACMECertificateFailed"
+	acmeCertificateEventAction  = "IssueACMECertificate"
+)
+
+// ACMECertificateIssuer obtains certificates from an ACME server, see acmecert.Issuer.
+type ACMECertificateIssuer interface {
+	Issue(ctx context.Context, accountKey crypto.Signer, hostnames []string, certKey crypto.Signer) ([]byte, error)
+}
+
+type acmeCertificateController struct {
+	issuer                   ACMECertificateIssuer
+	isLeader                 func() bool
+	accountSecretName        string
+	renewBefore              time.Duration
+	secretLabels             map[string]string
+	clock                    clock.Clock
+	kubeClient               kubernetes.Interface
+	secretInformer           corev1informers.SecretInformer
+	federationDomainInformer configinformers.FederationDomainInformer
+	eventRecorder            events.EventRecorder
+
+	// The time before which the certificate of each Secret is not requested again, by the name of the Secret. It is
+	// also set after a certificate was obtained, because the informer's cache may not show the updated Secret yet.
+	retryAt map[string]time.Time
+}
+
+// NewACMECertificateController returns a controller which obtains TLS certificates for the issuer hostnames of the
+// FederationDomains from an ACME server, and stores them in the TLS Secrets named by the FederationDomains'
+// spec.tls.secretName. The certificates are renewed when they expire within renewBefore. FederationDomains which share
+// a Secret get one certificate for all of their hostnames. TLS Secrets which already exist and were not created by
+// this controller are left alone. Only the leader, as reported by isLeader, obtains certificates.
+func NewACMECertificateController(
+	issuer ACMECertificateIssuer,
+	isLeader func() bool,
+	accountSecretName string,
+	renewBefore time.Duration,
+	secretLabels map[string]string,
+	clock clock.Clock,
+	kubeClient kubernetes.Interface,
+	secretInformer corev1informers.SecretInformer,
+	federationDomainInformer configinformers.FederationDomainInformer,
+	eventRecorder events.EventRecorder,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "acme-certificate-controller",
+			Syncer: &acmeCertificateController{
+				issuer:                   issuer,
+				isLeader:                 isLeader,
+				accountSecretName:        accountSecretName,
+				renewBefore:              renewBefore,
+				secretLabels:             secretLabels,
+				clock:                    clock,
+				kubeClient:               kubeClient,
+				secretInformer:           secretInformer,
+				federationDomainInformer: federationDomainInformer,
+				eventRecorder:            eventRecorder,
+				retryAt:                  map[string]time.Time{},
+			},
+		},
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypeFilter(corev1.SecretTypeTLS, nil),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			federationDomainInformer,
+			pinnipedcontroller.MatchAnythingFilter(nil),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+func (c *acmeCertificateController) Sync(ctx controllerlib.Context) error {
+	if !c.isLeader() {
+		// Only the leader can store the certificates and the HTTP-01 challenges, so the other pods would place
+		// orders which count against the rate limits of the ACME server without ever storing a certificate.
+		ctx.Queue.AddAfter(ctx.Key, acmeNotLeaderInterval)
+		return nil
+	}
+
+	ns := ctx.Key.Namespace
+	federationDomains, err := c.federationDomainInformer.Lister().FederationDomains(ns).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list FederationDomains: %w", err)
+	}
+
+	// FederationDomains which share a Secret are served with the same certificate, so it must include all of
+	// their hostnames.
+	hostnamesBySecret := map[string]sets.String{}
+	federationDomainsBySecret := map[string][]*configv1alpha1.FederationDomain{}
+	for _, federationDomain := range federationDomains {
+		if federationDomain.Spec.TLS == nil || federationDomain.Spec.TLS.SecretName == "" {
+			continue
+		}
+		issuerURL, err := url.Parse(federationDomain.Spec.Issuer)
+		if err != nil {
+			continue
+		}
+		hostname := lowercaseHostWithoutPort(issuerURL)
+		if hostname == "" || net.ParseIP(hostname) != nil {
+			// ACME servers only issue certificates for DNS names.
+			continue
+		}
+		secretName := federationDomain.Spec.TLS.SecretName
+		if hostnamesBySecret[secretName] == nil {
+			hostnamesBySecret[secretName] = sets.NewString()
+		}
+		hostnamesBySecret[secretName].Insert(hostname)
+		federationDomainsBySecret[secretName] = append(federationDomainsBySecret[secretName], federationDomain)
+	}
+
+	secretNames := make([]string, 0, len(hostnamesBySecret))
+	for secretName := range hostnamesBySecret {
+		secretNames = append(secretNames, secretName)
+	}
+	sort.Strings(secretNames)
+
+	var next time.Time
+	for _, secretName := range secretNames {
+		syncAgainAt := c.syncSecret(ctx.Context, ns, secretName, hostnamesBySecret[secretName].List(), federationDomainsBySecret[secretName])
+		if !syncAgainAt.IsZero() && (next.IsZero() || syncAgainAt.Before(next)) {
+			next = syncAgainAt
+		}
+	}
+
+	if !next.IsZero() {
+		ctx.Queue.AddAfter(ctx.Key, next.Sub(c.clock.Now()))
+	}
+	return nil
+}
+
+// syncSecret ensures that the Secret holds a certificate for the hostnames which does not need to be renewed yet.
+// It returns the time at which the Secret should be synced again, or the zero time when it is not managed by this
+// controller. Failures are reported by Events on the FederationDomains, and retried after the retry interval.
+func (c *acmeCertificateController) syncSecret(
+	ctx context.Context,
+	namespace string,
+	secretName string,
+	hostnames []string,
+	federationDomains []*configv1alpha1.FederationDomain,
+) time.Time {
+	now := c.clock.Now()
+
+	secret, err := c.secretInformer.Lister().Secrets(namespace).Get(secretName)
+	notFound := k8serrors.IsNotFound(err)
+	switch {
+	case err != nil && !notFound:
+		plog.WarningErr("acmeCertificateController Sync could not get TLS secret", err, "namespace", namespace, "secretName", secretName)
+		return now.Add(acmeRetryInterval)
+	case notFound:
+		// Obtain the first certificate.
+	case secret.Annotations[acmeIssuedAnnotation] == "":
+		plog.Debug("acmeCertificateController Sync found a TLS secret which was not issued by ACME", "namespace", namespace, "secretName", secretName)
+		return time.Time{}
+	default:
+		if renewAt, ok := c.renewAt(secret, hostnames); ok && now.Before(renewAt) {
+			return renewAt
+		}
+	}
+
+	if retryAt := c.retryAt[secretName]; now.Before(retryAt) {
+		return retryAt
+	}
+
+	c.retryAt[secretName] = now.Add(acmeRetryInterval)
+	notAfter, err := c.issueCertificate(ctx, namespace, secretName, hostnames)
+	if err != nil {
+		plog.WarningErr("acmeCertificateController Sync could not obtain a certificate from the ACME server", err,
+			"namespace", namespace, "secretName", secretName, "hostnames", hostnames)
+		for _, federationDomain := range federationDomains {
+			c.eventRecorder.Eventf(federationDomain, nil, corev1.EventTypeWarning, acmeCertificateFailedReason, acmeCertificateEventAction,
+				"could not obtain a TLS certificate for %s from the ACME server: %s", strings.Join(hostnames, ", "), err.Error())
+		}
+		return c.retryAt[secretName]
+	}
+
+	plog.Info("acmeCertificateController Sync obtained a certificate from the ACME server",
+		"namespace", namespace, "secretName", secretName, "hostnames", hostnames, "notAfter", notAfter)
+	for _, federationDomain := range federationDomains {
+		c.eventRecorder.Eventf(federationDomain, nil, corev1.EventTypeNormal, acmeCertificateIssuedReason, acmeCertificateEventAction,
+			"obtained a TLS certificate for %s from the ACME server, valid until %s", strings.Join(hostnames, ", "), notAfter.UTC().Format(time.RFC3339))
+	}
+	return notAfter.Add(-c.renewBefore)
+}
+
+// renewAt returns the time at which the certificate of the Secret should be renewed, or false when it should be
+// renewed right away because it is invalid or does not include all of the hostnames.
+func (c *acmeCertificateController) renewAt(secret *corev1.Secret, hostnames []string) (time.Time, bool) {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	for _, hostname := range hostnames {
+		if cert.VerifyHostname(hostname) != nil {
+			return time.Time{}, false
+		}
+	}
+	return cert.NotAfter.Add(-c.renewBefore), true
+}
+
+// issueCertificate obtains a certificate for the hostnames with a new private key, and stores both in the Secret.
+// It returns the time at which the certificate expires.
+func (c *acmeCertificateController) issueCertificate(ctx context.Context, namespace string, secretName string, hostnames []string) (time.Time, error) {
+	accountKey, err := c.accountKey(ctx, namespace)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot generate key: %w", err)
+	}
+
+	issueCtx, cancel := context.WithTimeout(ctx, acmeIssueTimeout)
+	defer cancel()
+	chainPEM, err := c.issuer.Issue(issueCtx, accountKey, hostnames, certKey)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	block, _ := pem.Decode(chainPEM)
+	if block == nil {
+		return time.Time{}, errors.New("ACME server returned no certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ACME server returned an invalid certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(certKey)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot marshal key: %w", err)
+	}
+	data := map[string][]byte{
+		corev1.TLSCertKey:       chainPEM,
+		corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+	if err := c.createOrUpdateTLSSecret(ctx, namespace, secretName, data); err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+func (c *acmeCertificateController) createOrUpdateTLSSecret(ctx context.Context, namespace string, secretName string, data map[string][]byte) error {
+	secretClient := c.kubeClient.CoreV1().Secrets(namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		oldSecret, err := secretClient.Get(ctx, secretName, metav1.GetOptions{})
+		notFound := k8serrors.IsNotFound(err)
+		if err != nil && !notFound {
+			return fmt.Errorf("cannot get secret: %w", err)
+		}
+
+		if notFound {
+			_, err := secretClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        secretName,
+					Namespace:   namespace,
+					Labels:      c.secretLabels,
+					Annotations: map[string]string{acmeIssuedAnnotation: "true"},
+				},
+				Type: corev1.SecretTypeTLS,
+				Data: data,
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("cannot create secret: %w", err)
+			}
+			return nil
+		}
+
+		if oldSecret.Annotations[acmeIssuedAnnotation] == "" {
+			return fmt.Errorf("secret %s was created by someone else in the meantime", secretName)
+		}
+		oldSecret.Data = data
+		_, err = secretClient.Update(ctx, oldSecret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// accountKey returns the private key of the ACME account, which is generated when the account Secret does not
+// exist yet.
+func (c *acmeCertificateController) accountKey(ctx context.Context, namespace string) (crypto.Signer, error) {
+	secret, err := c.secretInformer.Lister().Secrets(namespace).Get(c.accountSecretName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return nil, fmt.Errorf("cannot get ACME account secret: %w", err)
+	}
+
+	if notFound {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate ACME account key: %w", err)
+		}
+		keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal ACME account key: %w", err)
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: c.accountSecretName, Namespace: namespace, Labels: c.secretLabels},
+			Type:       acmeAccountSecretType,
+			Data:       map[string][]byte{acmeAccountKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})},
+		}
+		_, err = c.kubeClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		switch {
+		case k8serrors.IsAlreadyExists(err):
+			// The informer's cache did not show the Secret yet.
+			secret, err = c.kubeClient.CoreV1().Secrets(namespace).Get(ctx, c.accountSecretName, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("cannot get ACME account secret: %w", err)
+			}
+		case err != nil:
+			return nil, fmt.Errorf("cannot create ACME account secret: %w", err)
+		default:
+			return key, nil
+		}
+	}
+
+	block, _ := pem.Decode(secret.Data[acmeAccountKeyKey])
+	if secret.Type != acmeAccountSecretType || block == nil {
+		return nil, fmt.Errorf("ACME account secret %s is invalid", c.accountSecretName)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ACME account secret %s is invalid: %w", c.accountSecretName, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("ACME account secret %s is invalid: unsupported key type %T", c.accountSecretName, key)
+	}
+	return signer, nil
+}
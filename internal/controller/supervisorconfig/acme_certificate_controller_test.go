@@ -0,0 +1,356 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+)
+
+func TestACMECertificateControllerSync(t *testing.T) {
+	const (
+		namespace         = "some-namespace"
+		tlsSecretName     = "some-tls-secret"
+		accountSecretName = "some-acme-account"
+		renewBefore       = 30 * 24 * time.Hour
+		certLifetime      = 90 * 24 * time.Hour
+	)
+
+	frozenNow := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	wantNotAfter := frozenNow.Add(certLifetime).Format(time.RFC3339)
+
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	accountKeyDER, err := x509.MarshalPKCS8PrivateKey(accountKey)
+	require.NoError(t, err)
+	accountSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: accountSecretName, Namespace: namespace},
+		Type:       acmeAccountSecretType,
+		Data:       map[string][]byte{acmeAccountKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: accountKeyDER})},
+	}
+
+	newFederationDomain := func(name string, issuer string, secretName string) *configv1alpha1.FederationDomain {
+		return &configv1alpha1.FederationDomain{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: configv1alpha1.FederationDomainSpec{
+				Issuer: issuer,
+				TLS:    &configv1alpha1.FederationDomainTLSSpec{SecretName: secretName},
+			},
+		}
+	}
+
+	newTLSSecret := func(hostnames []string, notAfter time.Time, annotations map[string]string) *corev1.Secret {
+		certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalPKCS8PrivateKey(certKey)
+		require.NoError(t, err)
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: tlsSecretName, Namespace: namespace, Annotations: annotations},
+			Type:       corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       newTestCertPEM(t, hostnames, notAfter, certKey),
+				corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+			},
+		}
+	}
+	acmeIssued := map[string]string{acmeIssuedAnnotation: "true"}
+
+	tests := []struct {
+		name              string
+		federationDomains []*configv1alpha1.FederationDomain
+		secrets           []*corev1.Secret
+		notLeader         bool
+		issueErr          error
+		syncs             int
+		wantIssued        [][]string
+		wantAccountKey    *ecdsa.PublicKey
+		wantSecretFor     []string
+		wantSecretUnmoved bool
+		wantRequeueAfter  time.Duration
+		wantEvents        []string
+	}{
+		{
+			name: "FederationDomains without TLS secrets or with IP addresses are skipped",
+			federationDomains: []*configv1alpha1.FederationDomain{
+				{ObjectMeta: metav1.ObjectMeta{Name: "no-tls", Namespace: namespace}, Spec: configv1alpha1.FederationDomainSpec{Issuer: "https://issuer.example.com"}},
+				newFederationDomain("ip", "https://10.0.0.1/issuer", tlsSecretName),
+			},
+			wantEvents: []string{},
+		},
+		{
+			name:              "the first certificate is obtained with a new account",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://Issuer.Example.com:8443/path", tlsSecretName)},
+			wantIssued:        [][]string{{"issuer.example.com"}},
+			wantSecretFor:     []string{"issuer.example.com"},
+			wantRequeueAfter:  certLifetime - renewBefore,
+			wantEvents: []string{
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer.example.com from the ACME server, valid until " + wantNotAfter,
+			},
+		},
+		{
+			name: "FederationDomains which share a secret get one certificate for all of their hostnames",
+			federationDomains: []*configv1alpha1.FederationDomain{
+				newFederationDomain("fd1", "https://issuer2.example.com/a", tlsSecretName),
+				newFederationDomain("fd2", "https://issuer1.example.com/b", tlsSecretName),
+				newFederationDomain("fd3", "https://issuer2.example.com/c", tlsSecretName),
+			},
+			secrets:          []*corev1.Secret{accountSecret},
+			wantIssued:       [][]string{{"issuer1.example.com", "issuer2.example.com"}},
+			wantAccountKey:   &accountKey.PublicKey,
+			wantSecretFor:    []string{"issuer1.example.com", "issuer2.example.com"},
+			wantRequeueAfter: certLifetime - renewBefore,
+			wantEvents: []string{
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer1.example.com, issuer2.example.com from the ACME server, valid until " + wantNotAfter,
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer1.example.com, issuer2.example.com from the ACME server, valid until " + wantNotAfter,
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer1.example.com, issuer2.example.com from the ACME server, valid until " + wantNotAfter,
+			},
+		},
+		{
+			name:              "a pod which is not the leader does not obtain certificates",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://issuer.example.com", tlsSecretName)},
+			notLeader:         true,
+			syncs:             2,
+			wantRequeueAfter:  acmeNotLeaderInterval,
+			wantEvents:        []string{},
+		},
+		{
+			name:              "a TLS secret which was not issued by ACME is left alone",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://issuer.example.com", tlsSecretName)},
+			secrets:           []*corev1.Secret{newTLSSecret([]string{"other.example.com"}, frozenNow.Add(time.Hour), nil)},
+			wantSecretUnmoved: true,
+			wantEvents:        []string{},
+		},
+		{
+			name:              "a certificate which does not need to be renewed yet",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://issuer.example.com", tlsSecretName)},
+			secrets:           []*corev1.Secret{newTLSSecret([]string{"issuer.example.com"}, frozenNow.Add(renewBefore+time.Hour), acmeIssued)},
+			wantSecretUnmoved: true,
+			wantRequeueAfter:  time.Hour,
+			wantEvents:        []string{},
+		},
+		{
+			name:              "a certificate which expires soon is renewed",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://issuer.example.com", tlsSecretName)},
+			secrets: []*corev1.Secret{
+				accountSecret,
+				newTLSSecret([]string{"issuer.example.com"}, frozenNow.Add(renewBefore-time.Hour), acmeIssued),
+			},
+			wantIssued:       [][]string{{"issuer.example.com"}},
+			wantAccountKey:   &accountKey.PublicKey,
+			wantSecretFor:    []string{"issuer.example.com"},
+			wantRequeueAfter: certLifetime - renewBefore,
+			wantEvents: []string{
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer.example.com from the ACME server, valid until " + wantNotAfter,
+			},
+		},
+		{
+			name: "a certificate which does not include every hostname is renewed",
+			federationDomains: []*configv1alpha1.FederationDomain{
+				newFederationDomain("fd1", "https://issuer1.example.com", tlsSecretName),
+				newFederationDomain("fd2", "https://issuer2.example.com", tlsSecretName),
+			},
+			secrets: []*corev1.Secret{
+				accountSecret,
+				newTLSSecret([]string{"issuer1.example.com"}, frozenNow.Add(certLifetime), acmeIssued),
+			},
+			wantIssued:       [][]string{{"issuer1.example.com", "issuer2.example.com"}},
+			wantAccountKey:   &accountKey.PublicKey,
+			wantSecretFor:    []string{"issuer1.example.com", "issuer2.example.com"},
+			wantRequeueAfter: certLifetime - renewBefore,
+			wantEvents: []string{
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer1.example.com, issuer2.example.com from the ACME server, valid until " + wantNotAfter,
+				"Normal ACMECertificateIssued obtained a TLS certificate for issuer1.example.com, issuer2.example.com from the ACME server, valid until " + wantNotAfter,
+			},
+		},
+		{
+			name:              "a failure is reported and not retried right away",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://issuer.example.com", tlsSecretName)},
+			secrets:           []*corev1.Secret{accountSecret},
+			issueErr:          errors.New("ACME server could not validate http-01 challenge for issuer.example.com: connection refused"),
+			syncs:             2,
+			wantIssued:        [][]string{{"issuer.example.com"}},
+			wantAccountKey:    &accountKey.PublicKey,
+			wantRequeueAfter:  acmeRetryInterval,
+			wantEvents: []string{
+				"Warning ACMECertificateFailed could not obtain a TLS certificate for issuer.example.com from the ACME server: " +
+					"ACME server could not validate http-01 challenge for issuer.example.com: connection refused",
+			},
+		},
+		{
+			name:              "an invalid account secret",
+			federationDomains: []*configv1alpha1.FederationDomain{newFederationDomain("fd1", "https://issuer.example.com", tlsSecretName)},
+			secrets: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: accountSecretName, Namespace: namespace},
+				Type:       acmeAccountSecretType,
+				Data:       map[string][]byte{acmeAccountKeyKey: []byte("not a key")},
+			}},
+			wantRequeueAfter: acmeRetryInterval,
+			wantEvents: []string{
+				"Warning ACMECertificateFailed could not obtain a TLS certificate for issuer.example.com from the ACME server: " +
+					"ACME account secret some-acme-account is invalid",
+			},
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeAPIClient := kubernetesfake.NewSimpleClientset()
+			kubeInformerClient := kubernetesfake.NewSimpleClientset()
+			for _, secret := range tt.secrets {
+				require.NoError(t, kubeAPIClient.Tracker().Add(secret))
+				require.NoError(t, kubeInformerClient.Tracker().Add(secret))
+			}
+			pinnipedInformerClient := pinnipedfake.NewSimpleClientset()
+			for _, federationDomain := range tt.federationDomains {
+				require.NoError(t, pinnipedInformerClient.Tracker().Add(federationDomain))
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedInformerClient, 0)
+
+			issuer := &fakeACMEIssuer{t: t, now: frozenNow, lifetime: certLifetime, err: tt.issueErr}
+			eventRecorder := events.NewFakeRecorder(100)
+
+			c := NewACMECertificateController(
+				issuer,
+				func() bool { return !tt.notLeader },
+				accountSecretName,
+				renewBefore,
+				map[string]string{"myLabelKey": "myLabelValue"},
+				clocktesting.NewFakeClock(frozenNow),
+				kubeAPIClient,
+				kubeInformers.Core().V1().Secrets(),
+				pinnipedInformers.Config().V1alpha1().FederationDomains(),
+				eventRecorder,
+				controllerlib.WithInformer,
+			)
+
+			// Must start informers before calling TestRunSynchronously().
+			kubeInformers.Start(ctx.Done())
+			pinnipedInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, c)
+
+			syncs := tt.syncs
+			if syncs == 0 {
+				syncs = 1
+			}
+			key := controllerlib.Key{Namespace: namespace, Name: "fd1"}
+			for i := 0; i < syncs; i++ {
+				queue := &testQueue{t: t}
+				require.NoError(t, controllerlib.TestSync(t, c, controllerlib.Context{Context: ctx, Key: key, Queue: queue}))
+				if tt.wantRequeueAfter != 0 {
+					require.True(t, queue.called)
+					require.Equal(t, key, queue.key)
+					require.Equal(t, tt.wantRequeueAfter, queue.duration)
+				} else {
+					require.False(t, queue.called)
+				}
+			}
+
+			require.Equal(t, tt.wantIssued, issuer.issued)
+			if tt.wantAccountKey != nil {
+				require.True(t, tt.wantAccountKey.Equal(issuer.accountKey.Public()))
+			}
+			if len(tt.wantIssued) > 0 && tt.wantAccountKey == nil {
+				// A new account key was stored for the next certificates.
+				createdAccountSecret, err := kubeAPIClient.CoreV1().Secrets(namespace).Get(ctx, accountSecretName, metav1.GetOptions{})
+				require.NoError(t, err)
+				require.Equal(t, acmeAccountSecretType, createdAccountSecret.Type)
+				require.Equal(t, map[string]string{"myLabelKey": "myLabelValue"}, createdAccountSecret.Labels)
+				block, _ := pem.Decode(createdAccountSecret.Data[acmeAccountKeyKey])
+				require.NotNil(t, block)
+				createdAccountKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				require.NoError(t, err)
+				require.True(t, issuer.accountKey.(*ecdsa.PrivateKey).Equal(createdAccountKey))
+			}
+
+			tlsSecret, err := kubeAPIClient.CoreV1().Secrets(namespace).Get(ctx, tlsSecretName, metav1.GetOptions{})
+			switch {
+			case tt.wantSecretFor != nil:
+				require.NoError(t, err)
+				require.Equal(t, corev1.SecretTypeTLS, tlsSecret.Type)
+				require.Equal(t, "true", tlsSecret.Annotations[acmeIssuedAnnotation])
+				keyPair, err := tls.X509KeyPair(tlsSecret.Data[corev1.TLSCertKey], tlsSecret.Data[corev1.TLSPrivateKeyKey])
+				require.NoError(t, err)
+				cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+				require.NoError(t, err)
+				require.Equal(t, tt.wantSecretFor, cert.DNSNames)
+			case tt.wantSecretUnmoved:
+				require.NoError(t, err)
+				require.Equal(t, tt.secrets[len(tt.secrets)-1].Data, tlsSecret.Data)
+			default:
+				require.Error(t, err)
+			}
+
+			recorded := []string{}
+			for len(eventRecorder.Events) > 0 {
+				recorded = append(recorded, <-eventRecorder.Events)
+			}
+			require.Equal(t, tt.wantEvents, recorded)
+		})
+	}
+}
+
+type fakeACMEIssuer struct {
+	t        *testing.T
+	now      time.Time
+	lifetime time.Duration
+	err      error
+
+	issued     [][]string
+	accountKey crypto.Signer
+}
+
+func (f *fakeACMEIssuer) Issue(_ context.Context, accountKey crypto.Signer, hostnames []string, certKey crypto.Signer) ([]byte, error) {
+	f.issued = append(f.issued, hostnames)
+	f.accountKey = accountKey
+	if f.err != nil {
+		return nil, f.err
+	}
+	return newTestCertPEM(f.t, hostnames, f.now.Add(f.lifetime), certKey), nil
+}
+
+// newTestCertPEM returns a self-signed certificate for the hostnames and the key.
+func newTestCertPEM(t *testing.T, hostnames []string, notAfter time.Time, key crypto.Signer) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hostnames[0]},
+		DNSNames:     hostnames,
+		NotBefore:    notAfter.Add(-100 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package activedirectoryupstreamwatcher implements a controller which watches ActiveDirectoryIdentityProviders.
@@ -7,16 +7,23 @@ package activedirectoryupstreamwatcher
 import (
 	"context"
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
@@ -88,6 +95,10 @@ func (g *activeDirectoryUpstreamGenericLDAPImpl) Name() string {
 	return g.activeDirectoryIdentityProvider.Name
 }
 
+func (g *activeDirectoryUpstreamGenericLDAPImpl) UID() types.UID {
+	return g.activeDirectoryIdentityProvider.UID
+}
+
 func (g *activeDirectoryUpstreamGenericLDAPImpl) Generation() int64 {
 	return g.activeDirectoryIdentityProvider.Generation
 }
@@ -112,6 +123,15 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) BindSecretName() string {
 	return s.activeDirectoryIdentityProvider.Spec.Bind.SecretName
 }
 
+func (s *activeDirectoryUpstreamGenericLDAPSpec) UserDNTemplate() string {
+	// Active Directory does not support binding directly as the end user.
+	return ""
+}
+
+func (s *activeDirectoryUpstreamGenericLDAPSpec) ClientCertificateSecretName() string {
+	return "" // client certificates are not currently supported for Active Directory
+}
+
 func (s *activeDirectoryUpstreamGenericLDAPSpec) UserSearch() upstreamwatchers.UpstreamGenericLDAPUserSearch {
 	return &activeDirectoryUpstreamGenericLDAPUserSearch{s.activeDirectoryIdentityProvider.Spec.UserSearch}
 }
@@ -120,6 +140,18 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) GroupSearch() upstreamwatchers.
 	return &activeDirectoryUpstreamGenericLDAPGroupSearch{s.activeDirectoryIdentityProvider.Spec.GroupSearch}
 }
 
+func (s *activeDirectoryUpstreamGenericLDAPSpec) ProxyURL() string {
+	return s.activeDirectoryIdentityProvider.Spec.ProxyURL
+}
+
+func (s *activeDirectoryUpstreamGenericLDAPSpec) DialTimeoutSeconds() int64 {
+	return s.activeDirectoryIdentityProvider.Spec.DialTimeoutSeconds
+}
+
+func (s *activeDirectoryUpstreamGenericLDAPSpec) SearchTimeoutSeconds() int64 {
+	return s.activeDirectoryIdentityProvider.Spec.SearchTimeoutSeconds
+}
+
 func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx context.Context, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
 	config.GroupSearch.Base = s.activeDirectoryIdentityProvider.Spec.GroupSearch.Base
 	config.UserSearch.Base = s.activeDirectoryIdentityProvider.Spec.UserSearch.Base
@@ -133,6 +165,31 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx cont
 		}
 	}
 	ldapProvider := upstreamldap.New(*config)
+
+	if config.UserSearch.UseGlobalCatalog && config.UserSearch.Base == "" {
+		// When searching the Global Catalog for users, query your AD server for the rootDomainNamingContext
+		// to get a DN which includes all the domains of the forest's root tree to use as the user search base.
+		// https://ldapwiki.com/wiki/RootDomainNamingContext
+		rootDomainNamingContext, err := ldapProvider.SearchForRootDomainNamingContext(ctx)
+		if err != nil {
+			return &v1alpha1.Condition{
+				Type:    upstreamwatchers.TypeSearchBaseFound,
+				Status:  v1alpha1.ConditionFalse,
+				Reason:  upstreamwatchers.ReasonErrorFetchingSearchBase,
+				Message: fmt.Sprintf(`Error finding search base: %s`, err.Error()),
+			}
+		}
+		config.UserSearch.Base = rootDomainNamingContext
+		if config.GroupSearch.Base != "" {
+			return &v1alpha1.Condition{
+				Type:    upstreamwatchers.TypeSearchBaseFound,
+				Status:  v1alpha1.ConditionTrue,
+				Reason:  upstreamwatchers.ReasonSuccess,
+				Message: "Successfully fetched rootDomainNamingContext to use as default user search base from RootDSE.",
+			}
+		}
+	}
+
 	// Query your AD server for the defaultNamingContext to get a DN to use as the search base
 	// when it isn't specified.
 	// https://ldapwiki.com/wiki/DefaultNamingContext
@@ -151,11 +208,16 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx cont
 	if config.GroupSearch.Base == "" {
 		config.GroupSearch.Base = defaultNamingContext
 	}
+	message := "Successfully fetched defaultNamingContext to use as default search base from RootDSE."
+	if config.UserSearch.UseGlobalCatalog && s.activeDirectoryIdentityProvider.Spec.UserSearch.Base == "" {
+		message = "Successfully fetched rootDomainNamingContext to use as default user search base " +
+			"and defaultNamingContext to use as default group search base from RootDSE."
+	}
 	return &v1alpha1.Condition{
 		Type:    upstreamwatchers.TypeSearchBaseFound,
 		Status:  v1alpha1.ConditionTrue,
 		Reason:  upstreamwatchers.ReasonSuccess,
-		Message: "Successfully fetched defaultNamingContext to use as default search base from RootDSE.",
+		Message: message,
 	}
 }
 
@@ -188,6 +250,14 @@ func (u *activeDirectoryUpstreamGenericLDAPUserSearch) UIDAttribute() string {
 	return u.userSearch.Attributes.UID
 }
 
+func (u *activeDirectoryUpstreamGenericLDAPUserSearch) Scope() v1alpha1.LDAPSearchScope {
+	return u.userSearch.Scope
+}
+
+func (u *activeDirectoryUpstreamGenericLDAPUserSearch) DerefAliases() v1alpha1.LDAPDerefAliases {
+	return u.userSearch.DerefAliases
+}
+
 type activeDirectoryUpstreamGenericLDAPGroupSearch struct {
 	groupSearch v1alpha1.ActiveDirectoryIdentityProviderGroupSearch
 }
@@ -210,6 +280,18 @@ func (g *activeDirectoryUpstreamGenericLDAPGroupSearch) GroupNameAttribute() str
 	return g.groupSearch.Attributes.GroupName
 }
 
+func (g *activeDirectoryUpstreamGenericLDAPGroupSearch) Scope() v1alpha1.LDAPSearchScope {
+	return g.groupSearch.Scope
+}
+
+func (g *activeDirectoryUpstreamGenericLDAPGroupSearch) DerefAliases() v1alpha1.LDAPDerefAliases {
+	return g.groupSearch.DerefAliases
+}
+
+func (g *activeDirectoryUpstreamGenericLDAPGroupSearch) GroupNameTransformations() []v1alpha1.GroupNameTransformation {
+	return g.groupSearch.GroupNameTransformations
+}
+
 type activeDirectoryUpstreamGenericLDAPStatus struct {
 	activeDirectoryIdentityProvider v1alpha1.ActiveDirectoryIdentityProvider
 }
@@ -230,6 +312,13 @@ type activeDirectoryWatcherController struct {
 	client                                  pinnipedclientset.Interface
 	activeDirectoryIdentityProviderInformer idpinformers.ActiveDirectoryIdentityProviderInformer
 	secretInformer                          corev1informers.SecretInformer
+	configMapInformer                       corev1informers.ConfigMapInformer
+	lookupSRV                               lookupSRVFunc
+	clock                                   clock.Clock
+	discoveredDomainControllers             map[string]discoveredDomainControllers
+	conditionEvents                         *upstreamwatchers.ConditionEventRecorder
+	authenticationRateLimiters              *upstreamwatchers.AuthenticationRateLimiters
+	connectionRevalidations                 *upstreamwatchers.ConnectionRevalidations
 }
 
 // New instantiates a new controllerlib.Controller which will populate the provided UpstreamActiveDirectoryIdentityProviderICache.
@@ -238,18 +327,28 @@ func New(
 	client pinnipedclientset.Interface,
 	activeDirectoryIdentityProviderInformer idpinformers.ActiveDirectoryIdentityProviderInformer,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.EventRecorder,
+	validatedSettingsCache upstreamwatchers.ValidatedSettingsCacheI,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
+	if validatedSettingsCache == nil {
+		// start with an empty in-memory cache
+		validatedSettingsCache = upstreamwatchers.NewValidatedSettingsCache()
+	}
 	return newInternal(
 		idpCache,
-		// start with an empty cache
-		upstreamwatchers.NewValidatedSettingsCache(),
+		validatedSettingsCache,
 		// nil means to use a real production dialer when creating objects to add to the cache
 		nil,
 		client,
 		activeDirectoryIdentityProviderInformer,
 		secretInformer,
+		configMapInformer,
+		eventRecorder,
 		withInformer,
+		net.DefaultResolver.LookupSRV,
+		clock.RealClock{},
 	)
 }
 
@@ -261,7 +360,11 @@ func newInternal(
 	client pinnipedclientset.Interface,
 	activeDirectoryIdentityProviderInformer idpinformers.ActiveDirectoryIdentityProviderInformer,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.EventRecorder,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
+	lookupSRV lookupSRVFunc,
+	clock clock.Clock,
 ) controllerlib.Controller {
 	c := activeDirectoryWatcherController{
 		cache:                                   idpCache,
@@ -270,6 +373,13 @@ func newInternal(
 		client:                                  client,
 		activeDirectoryIdentityProviderInformer: activeDirectoryIdentityProviderInformer,
 		secretInformer:                          secretInformer,
+		configMapInformer:                       configMapInformer,
+		lookupSRV:                               lookupSRV,
+		clock:                                   clock,
+		discoveredDomainControllers:             map[string]discoveredDomainControllers{},
+		conditionEvents:                         upstreamwatchers.NewConditionEventRecorder(eventRecorder),
+		authenticationRateLimiters:              upstreamwatchers.NewAuthenticationRateLimiters(),
+		connectionRevalidations:                 upstreamwatchers.NewConnectionRevalidations(clock),
 	}
 	return controllerlib.New(
 		controllerlib.Config{Name: activeDirectoryControllerName, Syncer: &c},
@@ -280,7 +390,15 @@ func newInternal(
 		),
 		withInformer(
 			secretInformer,
-			pinnipedcontroller.MatchAnySecretOfTypeFilter(upstreamwatchers.LDAPBindAccountSecretType, pinnipedcontroller.SingletonQueue()),
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{upstreamwatchers.LDAPBindAccountSecretType, upstreamwatchers.LDAPClientCertificateSecretType, corev1.SecretTypeOpaque},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
 			controllerlib.InformerOption{},
 		),
 	)
@@ -294,55 +412,98 @@ func (c *activeDirectoryWatcherController) Sync(ctx controllerlib.Context) error
 	}
 
 	requeue := false
+	var discoveryRefreshInterval, revalidationInterval time.Duration
+	upstreamNames := make(map[string]bool, len(actualUpstreams))
+	upstreamUIDs := sets.New[types.UID]()
 	validatedUpstreams := make([]provider.UpstreamLDAPIdentityProviderI, 0, len(actualUpstreams))
 	for _, upstream := range actualUpstreams {
-		valid, requestedRequeue := c.validateUpstream(ctx.Context, upstream)
+		upstreamNames[upstream.Name] = true
+		upstreamUIDs.Insert(upstream.UID)
+		revalidateConnection, nextRevalidation := c.connectionRevalidations.Due(upstream.UID, upstream.Spec.ConnectionRevalidationIntervalSeconds)
+		if nextRevalidation > 0 && (revalidationInterval == 0 || nextRevalidation < revalidationInterval) {
+			revalidationInterval = nextRevalidation
+		}
+		valid, requestedRequeue := c.validateUpstream(ctx.Context, upstream, revalidateConnection)
 		if valid != nil {
 			validatedUpstreams = append(validatedUpstreams, valid)
 		}
 		if requestedRequeue {
 			requeue = true
 		}
+		if discovery := upstream.Spec.DomainControllerDiscovery; discovery != nil {
+			interval := domainControllerRefreshInterval(discovery)
+			if discoveryRefreshInterval == 0 || interval < discoveryRefreshInterval {
+				discoveryRefreshInterval = interval
+			}
+		}
+	}
+
+	// Forget the authentication rate limiters, the connection revalidations, and the discovered domain controllers
+	// of any upstreams which were deleted.
+	c.authenticationRateLimiters.Retain(upstreamUIDs)
+	c.connectionRevalidations.Retain(upstreamUIDs)
+	for name := range c.discoveredDomainControllers {
+		if !upstreamNames[name] {
+			delete(c.discoveredDomainControllers, name)
+		}
 	}
 
 	c.cache.SetActiveDirectoryIdentityProviders(validatedUpstreams)
 
+	// Sync again later to look up the SRV records again, since the domain controllers may have changed.
+	if discoveryRefreshInterval > 0 {
+		ctx.Queue.AddAfter(ctx.Key, discoveryRefreshInterval)
+	}
+
+	// Sync again later to validate the connections again, since the domain controllers may have become unavailable.
+	if revalidationInterval > 0 {
+		ctx.Queue.AddAfter(ctx.Key, revalidationInterval)
+	}
+
 	if requeue {
 		return controllerlib.ErrSyntheticRequeue
 	}
 	return nil
 }
 
-func (c *activeDirectoryWatcherController) validateUpstream(ctx context.Context, upstream *v1alpha1.ActiveDirectoryIdentityProvider) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
+func (c *activeDirectoryWatcherController) validateUpstream(ctx context.Context, upstream *v1alpha1.ActiveDirectoryIdentityProvider, revalidateConnection bool) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
 	spec := upstream.Spec
 
 	adUpstreamImpl := &activeDirectoryUpstreamGenericLDAPImpl{activeDirectoryIdentityProvider: *upstream}
 
+	// The Host and FailoverHosts are set below, since they may be discovered.
 	config := &upstreamldap.ProviderConfig{
 		Name:        upstream.Name,
 		ResourceUID: upstream.UID,
-		Host:        spec.Host,
 		UserSearch: upstreamldap.UserSearchConfig{
 			Base:              spec.UserSearch.Base,
 			Filter:            adUpstreamImpl.Spec().UserSearch().Filter(),
 			UsernameAttribute: adUpstreamImpl.Spec().UserSearch().UsernameAttribute(),
 			UIDAttribute:      adUpstreamImpl.Spec().UserSearch().UIDAttribute(),
+			UseGlobalCatalog:  spec.UserSearch.UseGlobalCatalog,
 		},
 		GroupSearch: upstreamldap.GroupSearchConfig{
-			Base:               spec.GroupSearch.Base,
-			Filter:             adUpstreamImpl.Spec().GroupSearch().Filter(),
-			GroupNameAttribute: adUpstreamImpl.Spec().GroupSearch().GroupNameAttribute(),
-			SkipGroupRefresh:   spec.GroupSearch.SkipGroupRefresh,
+			Base:                   spec.GroupSearch.Base,
+			Filter:                 adUpstreamImpl.Spec().GroupSearch().Filter(),
+			UserAttributeForFilter: spec.GroupSearch.UserAttributeForFilter,
+			GroupNameAttribute:     adUpstreamImpl.Spec().GroupSearch().GroupNameAttribute(),
+			SkipGroupRefresh:       spec.GroupSearch.SkipGroupRefresh,
+			RefreshInterval:        time.Duration(spec.GroupSearch.GroupRefreshIntervalSeconds) * time.Second,
+			PageSize:               uint32(spec.GroupSearch.PageSize),
+		},
+		Referrals: upstreamldap.ReferralConfig{
+			Follow:    spec.Referrals.Follow,
+			MaxHops:   int(spec.Referrals.MaxHops),
+			Anonymous: spec.Referrals.CredentialPolicy == v1alpha1.ReferralCredentialPolicyAnonymous,
 		},
 		Dialer: c.ldapDialer,
 		UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){
 			"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID"),
 		},
-		RefreshAttributeChecks: map[string]func(*ldap.Entry, provider.RefreshAttributes) error{
-			pwdLastSetAttribute:                 upstreamldap.AttributeUnchangedSinceLogin(pwdLastSetAttribute),
-			userAccountControlAttribute:         validUserAccountControl,
-			userAccountControlComputedAttribute: validComputedUserAccountControl,
-		},
+		RefreshAttributeChecks:  refreshAttributeChecks(spec.RefreshChecks),
+		AdditionalClaimMappings: spec.AttributeMappings.AdditionalClaims,
+		AuthenticationRateLimiter: c.authenticationRateLimiters.ForUpstream(upstream.UID,
+			spec.AuthenticationRateLimits.PerUsername, spec.AuthenticationRateLimits.PerClientIP),
 	}
 
 	if spec.GroupSearch.Attributes.GroupName == "" {
@@ -351,7 +512,16 @@ func (c *activeDirectoryWatcherController) validateUpstream(ctx context.Context,
 		}
 	}
 
-	conditions := upstreamwatchers.ValidateGenericLDAP(ctx, adUpstreamImpl, c.secretInformer, c.validatedSettingsCache, config)
+	domainControllersDiscoveredCondition := c.discoverDomainControllers(ctx, upstream, config)
+
+	var conditions upstreamwatchers.GradatedConditions
+	// No point in validating the rest of the config when there is no host to which to connect.
+	if len(config.Host) > 0 {
+		conditions = upstreamwatchers.ValidateGenericLDAP(ctx, adUpstreamImpl, c.secretInformer, c.configMapInformer, c.validatedSettingsCache, config, revalidateConnection)
+	}
+	// When refreshing the discovered domain controllers fails, the previously discovered domain controllers
+	// can still be used, so that failure is not fatal.
+	conditions.Append(domainControllersDiscoveredCondition, len(config.Host) == 0)
 
 	c.updateStatus(ctx, upstream, conditions.Conditions())
 
@@ -362,6 +532,8 @@ func (c *activeDirectoryWatcherController) updateStatus(ctx context.Context, ups
 	log := plog.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
 	updated := upstream.DeepCopy()
 
+	c.conditionEvents.RecordTransitions(upstreamwatchers.ObjectReferenceForEvents("ActiveDirectoryIdentityProvider", upstream), upstream.Status.Conditions, conditions)
+
 	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
 
 	updated.Status.Phase = v1alpha1.ActiveDirectoryPhaseReady
@@ -382,6 +554,41 @@ func (c *activeDirectoryWatcherController) updateStatus(ctx context.Context, ups
 	}
 }
 
+// refreshAttributeChecks returns the checks which are enabled by the spec, keyed by the name of the attribute which they check.
+func refreshAttributeChecks(refreshChecks v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks) map[string]func(*ldap.Entry, provider.RefreshAttributes) error {
+	checks := map[string]func(*ldap.Entry, provider.RefreshAttributes) error{}
+	if !refreshChecks.SkipPasswordChangedCheck {
+		checks[pwdLastSetAttribute] = upstreamldap.AttributeUnchangedSinceLogin(pwdLastSetAttribute)
+	}
+	if !refreshChecks.SkipAccountDisabledCheck {
+		checks[userAccountControlAttribute] = validUserAccountControl
+	}
+	if !refreshChecks.SkipAccountLockedCheck {
+		checks[userAccountControlComputedAttribute] = validComputedUserAccountControl
+	}
+	for _, attribute := range refreshChecks.UnchangedAttributes {
+		unchangedCheck := upstreamldap.AttributeUnchangedSinceLogin(attribute)
+		if existingCheck, found := checks[attribute]; found {
+			// Perform both checks on an attribute which also has a built-in check.
+			checks[attribute] = allRefreshAttributeChecks(existingCheck, unchangedCheck)
+			continue
+		}
+		checks[attribute] = unchangedCheck
+	}
+	return checks
+}
+
+func allRefreshAttributeChecks(checks ...func(*ldap.Entry, provider.RefreshAttributes) error) func(*ldap.Entry, provider.RefreshAttributes) error {
+	return func(entry *ldap.Entry, storedAttributes provider.RefreshAttributes) error {
+		for _, check := range checks {
+			if err := check(entry, storedAttributes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func microsoftUUIDFromBinaryAttr(attributeName string) func(entry *ldap.Entry) (string, error) {
 	// validation has already been done so we can just get the attribute...
 	return func(entry *ldap.Entry) (string, error) {
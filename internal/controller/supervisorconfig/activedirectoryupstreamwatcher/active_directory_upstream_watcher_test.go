@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package activedirectoryupstreamwatcher
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"sort"
 	"testing"
@@ -21,6 +22,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
@@ -55,6 +58,16 @@ func TestActiveDirectoryUpstreamWatcherControllerFilterSecrets(t *testing.T) {
 			wantUpdate: true,
 			wantDelete: true,
 		},
+		{
+			name: "an opaque secret which could hold a CA bundle",
+			secret: &corev1.Secret{
+				Type:       corev1.SecretTypeOpaque,
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
 		{
 			name: "a secret of the wrong type",
 			secret: &corev1.Secret{
@@ -80,9 +93,10 @@ func TestActiveDirectoryUpstreamWatcherControllerFilterSecrets(t *testing.T) {
 			fakeKubeClient := fake.NewSimpleClientset()
 			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
 			secretInformer := kubeInformers.Core().V1().Secrets()
+			configMapInformer := kubeInformers.Core().V1().ConfigMaps()
 			withInformer := testutil.NewObservableWithInformerOption()
 
-			New(nil, nil, activeDirectoryIDPInformer, secretInformer, withInformer.WithInformer)
+			New(nil, nil, activeDirectoryIDPInformer, secretInformer, configMapInformer, nil, nil, withInformer.WithInformer)
 
 			unrelated := corev1.Secret{}
 			filter := withInformer.GetFilterForInformer(secretInformer)
@@ -125,9 +139,10 @@ func TestActiveDirectoryUpstreamWatcherControllerFilterActiveDirectoryIdentityPr
 			fakeKubeClient := fake.NewSimpleClientset()
 			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
 			secretInformer := kubeInformers.Core().V1().Secrets()
+			configMapInformer := kubeInformers.Core().V1().ConfigMaps()
 			withInformer := testutil.NewObservableWithInformerOption()
 
-			New(nil, nil, activeDirectoryIDPInformer, secretInformer, withInformer.WithInformer)
+			New(nil, nil, activeDirectoryIDPInformer, secretInformer, configMapInformer, nil, nil, withInformer.WithInformer)
 
 			unrelated := corev1.Secret{}
 			filter := withInformer.GetFilterForInformer(activeDirectoryIDPInformer)
@@ -144,6 +159,16 @@ type comparableDialer struct {
 	upstreamldap.LDAPDialerFunc
 }
 
+type recordingQueue struct {
+	addAfterDurations []time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *recordingQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.addAfterDurations = append(q.addAfterDurations, duration)
+}
+
 func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 	t.Parallel()
 	now := metav1.NewTime(time.Now().UTC())
@@ -244,6 +269,37 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
+	domainControllersDiscoveredFromSpecCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "DomainControllersDiscovered",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "UsingConfigurationFromSpec",
+			Message:            "domainControllerDiscovery is not configured, so using the configured host",
+			ObservedGeneration: gen,
+		}
+	}
+	activeBindCredentialsPrimaryCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "ActiveBindCredentials",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "UsingPrimaryBindCredentials",
+			Message: fmt.Sprintf(`binding as user "%s" using the primary bind credentials from the ["username" "password"] keys of Secret "%s"`,
+				testBindUsername, testSecretName),
+			ObservedGeneration: gen,
+		}
+	}
+	activeBindCredentialsUnknownCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "ActiveBindCredentials",
+			Status:             "Unknown",
+			LastTransitionTime: now,
+			Reason:             "LDAPConnectionError",
+			Message:            "could not determine which bind credentials are working because the LDAP connection could not be validated",
+			ObservedGeneration: gen,
+		}
+	}
 	activeDirectoryConnectionValidTrueCondition := func(gen int64, secretVersion string) v1alpha1.Condition {
 		return v1alpha1.Condition{
 			Type:               "LDAPConnectionValid",
@@ -279,6 +335,36 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
+	searchOptionsValidTrueCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "SearchOptionsValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "loaded search options",
+			ObservedGeneration: gen,
+		}
+	}
+	timeoutsValidTrueCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "TimeoutsValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "loaded timeouts",
+			ObservedGeneration: gen,
+		}
+	}
+	proxyURLValidTrueCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "ProxyURLValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "no proxy URL provided",
+			ObservedGeneration: gen,
+		}
+	}
 
 	searchBaseFoundInRootDSECondition := func(gen int64) v1alpha1.Condition {
 		return v1alpha1.Condition{
@@ -291,6 +377,17 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		}
 	}
 
+	searchBaseFoundInRootDSEForGlobalCatalogCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "SearchBaseFound",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "Successfully fetched rootDomainNamingContext to use as default user search base from RootDSE.",
+			ObservedGeneration: gen,
+		}
+	}
+
 	searchBaseFoundInConfigCondition := func(gen int64) v1alpha1.Condition {
 		return v1alpha1.Condition{
 			Type:               "SearchBaseFound",
@@ -315,10 +412,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 
 	allConditionsTrue := func(gen int64, secretVersion string) []v1alpha1.Condition {
 		return []v1alpha1.Condition{
+			activeBindCredentialsPrimaryCondition(gen),
 			bindSecretValidTrueCondition(gen),
+			domainControllersDiscoveredFromSpecCondition(gen),
 			activeDirectoryConnectionValidTrueCondition(gen, secretVersion),
+			proxyURLValidTrueCondition(gen),
 			searchBaseFoundInConfigCondition(gen),
+			searchOptionsValidTrueCondition(gen),
 			tlsConfigurationValidLoadedTrueCondition(gen),
+			timeoutsValidTrueCondition(gen),
 		}
 	}
 
@@ -345,7 +447,14 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		return request
 	}
 
+	expectedRootDomainNamingContextSearch := func() *ldap.SearchRequest {
+		request := expectedDefaultNamingContextSearch()
+		request.Attributes = []string{"rootDomainNamingContext"}
+		return request
+	}
+
 	exampleDefaultNamingContext := "dc=default,dc=naming,dc=context,dc=example,dc=com"
+	exampleRootDomainNamingContext := "dc=example,dc=com"
 
 	exampleDefaultNamingContextSearchResult := &ldap.SearchResult{
 		Entries: []*ldap.Entry{
@@ -358,6 +467,17 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		},
 	}
 
+	exampleRootDomainNamingContextSearchResult := &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			{
+				DN: "",
+				Attributes: []*ldap.EntryAttribute{
+					ldap.NewEntryAttribute("rootDomainNamingContext", []string{exampleRootDomainNamingContext}),
+				},
+			},
+		},
+	}
+
 	tests := []struct {
 		name                     string
 		initialValidatedSettings map[string]upstreamwatchers.ValidatedSettings
@@ -365,10 +485,13 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		inputSecrets             []runtime.Object
 		setupMocks               func(conn *mockldapconn.MockConn)
 		dialErrors               map[string]error
+		lookupSRV                lookupSRVFunc
 		wantErr                  string
+		wantRequeueAfter         []time.Duration
 		wantResultingCache       []*upstreamldap.ProviderConfig
 		wantResultingUpstreams   []v1alpha1.ActiveDirectoryIdentityProvider
 		wantValidatedSettings    map[string]upstreamwatchers.ValidatedSettings
+		wantEvents               []string
 	}{
 		{
 			name:               "no ActiveDirectoryIdentityProvider upstreams clears the cache",
@@ -400,6 +523,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
 				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
 			}},
+			wantEvents: []string{
+				"Normal Success condition BindSecretValid changed to True: " + bindSecretValidTrueCondition(1234).Message,
+				"Normal Success condition LDAPConnectionValid changed to True: " + activeDirectoryConnectionValidTrueCondition(1234, "4242").Message,
+				"Normal UsingConfigurationFromSpec condition SearchBaseFound changed to True: " + searchBaseFoundInConfigCondition(1234).Message,
+			},
 		},
 		{
 			name:               "missing secret",
@@ -420,7 +548,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`secret "%s" not found`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -448,7 +580,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" has wrong type "some-other-type" (should be "kubernetes.io/basic-auth")`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -475,7 +611,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" is missing required keys ["username" "password"]`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -494,6 +634,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -502,6 +645,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "certificateAuthorityData is invalid: illegal base64 data at input byte 4",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -520,6 +664,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -528,6 +675,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "certificateAuthorityData is invalid: no certificates found",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -576,9 +724,13 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "True",
@@ -587,6 +739,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "no TLS configuration provided",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -645,9 +798,13 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "True",
@@ -656,6 +813,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "no TLS configuration provided",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -717,7 +875,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "True",
@@ -728,8 +888,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								"ldap.example.com", testBindUsername, testSecretName, "4242"),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -797,7 +960,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -808,8 +973,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								"ldap.example.com:5678", testBindUsername, "ldap.example.com:5678"),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -901,7 +1069,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								Message:            fmt.Sprintf(`secret "%s" not found`, "non-existent-secret"),
 								ObservedGeneration: 42,
 							},
+							domainControllersDiscoveredFromSpecCondition(42),
+							proxyURLValidTrueCondition(42),
+							searchOptionsValidTrueCondition(42),
 							tlsConfigurationValidLoadedTrueCondition(42),
+							timeoutsValidTrueCondition(42),
 						},
 					},
 				},
@@ -942,7 +1114,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -953,8 +1127,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								testHost, testBindUsername, testBindUsername),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1009,7 +1186,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -1020,8 +1199,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								testHost, testBindUsername, testBindUsername),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1045,7 +1227,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -1056,8 +1240,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								testHost, testBindUsername, testBindUsername),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error binding as \"test-bind-username\" before querying for defaultNamingContext: some bind error"),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1103,6 +1290,48 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
 			}},
 		},
+		{
+			name: "when the LDAP server connection is configured to be validated again periodically, then use the validated settings and sync again at the configured interval",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Generation = 1234
+				upstream.Spec.ConnectionRevalidationIntervalSeconds = 600
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+					searchBaseFoundInConfigCondition(1234),
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
+			}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should not perform a test dial and bind until the interval has passed.
+			},
+			wantRequeueAfter:   []time.Duration{10 * time.Minute},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
+			}},
+		},
 		{
 			name: "when the validated cache contains LDAP server info but the search base is empty, reload everything",
 			// this is an invalid state that shouldn't happen now, but if it does we should consider the whole
@@ -1157,10 +1386,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1174,6 +1408,189 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInRootDSECondition(0))),
 			}},
 		},
+		{
+			name: "when useGlobalCatalog is enabled and the user search base is not specified, use the rootDomainNamingContext as the user search base",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.UserSearch.Base = ""
+				upstream.Spec.UserSearch.UseGlobalCatalog = true
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(2)
+				conn.EXPECT().Close().Times(2)
+				conn.EXPECT().Search(expectedRootDomainNamingContextSearch()).Return(exampleRootDomainNamingContextSearchResult, nil).Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              exampleRootDomainNamingContext,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+						UseGlobalCatalog:  true,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID")},
+					RefreshAttributeChecks: map[string]func(*ldap.Entry, provider.RefreshAttributes) error{
+						"pwdLastSet":                         upstreamldap.AttributeUnchangedSinceLogin("pwdLastSet"),
+						"userAccountControl":                 validUserAccountControl,
+						"msDS-User-Account-Control-Computed": validComputedUserAccountControl,
+					}},
+			},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchBaseFoundInRootDSEForGlobalCatalogCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            exampleRootDomainNamingContext,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInRootDSEForGlobalCatalogCondition(0))),
+			}},
+		},
+		{
+			name: "when useGlobalCatalog is enabled and neither search base is specified, use the rootDomainNamingContext for users and the defaultNamingContext for groups",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.UserSearch.Base = ""
+				upstream.Spec.UserSearch.UseGlobalCatalog = true
+				upstream.Spec.GroupSearch.Base = ""
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(3)
+				conn.EXPECT().Close().Times(3)
+				conn.EXPECT().Search(expectedRootDomainNamingContextSearch()).Return(exampleRootDomainNamingContextSearchResult, nil).Times(1)
+				conn.EXPECT().Search(expectedDefaultNamingContextSearch()).Return(exampleDefaultNamingContextSearchResult, nil).Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              exampleRootDomainNamingContext,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+						UseGlobalCatalog:  true,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               exampleDefaultNamingContext,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID")},
+					RefreshAttributeChecks: map[string]func(*ldap.Entry, provider.RefreshAttributes) error{
+						"pwdLastSet":                         upstreamldap.AttributeUnchangedSinceLogin("pwdLastSet"),
+						"userAccountControl":                 validUserAccountControl,
+						"msDS-User-Account-Control-Computed": validComputedUserAccountControl,
+					}},
+			},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						{
+							Type:               "SearchBaseFound",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: "Successfully fetched rootDomainNamingContext to use as default user search base " +
+								"and defaultNamingContext to use as default group search base from RootDSE.",
+							ObservedGeneration: 1234,
+						},
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            exampleRootDomainNamingContext,
+				GroupSearchBase:           exampleDefaultNamingContext,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition: condPtr(v1alpha1.Condition{
+					Type:   "SearchBaseFound",
+					Status: "True",
+					Reason: "Success",
+					Message: "Successfully fetched rootDomainNamingContext to use as default user search base " +
+						"and defaultNamingContext to use as default group search base from RootDSE.",
+				}),
+			}},
+		},
+		{
+			name: "when useGlobalCatalog is enabled and querying the rootDomainNamingContext fails, then the search base is not found",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.UserSearch.Base = ""
+				upstream.Spec.UserSearch.UseGlobalCatalog = true
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(2)
+				conn.EXPECT().Close().Times(2)
+				conn.EXPECT().Search(expectedRootDomainNamingContextSearch()).Return(nil, errors.New("some search error")).Times(1)
+			},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for rootDomainNamingContext: some search error"),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{},
+		},
 		{
 			name: "when the LDAP server connection was already validated using TLS, and the search base was found, load TLS and search base info into the cache",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
@@ -1229,10 +1646,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1554,10 +1976,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1617,10 +2044,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1680,10 +2112,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1716,14 +2153,24 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: some error"),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{},
+			wantEvents: []string{
+				"Normal Success condition BindSecretValid changed to True: " + bindSecretValidTrueCondition(1234).Message,
+				"Normal Success condition LDAPConnectionValid changed to True: " + activeDirectoryConnectionValidTrueCondition(1234, "4242").Message,
+				"Warning ErrorFetchingSearchBase condition SearchBaseFound changed to False: Error finding search base: error querying RootDSE for defaultNamingContext: some error",
+			},
 		},
 		{
 			name: "when query for defaultNamingContext returns empty string",
@@ -1752,10 +2199,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: empty search base DN found"),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1794,10 +2246,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: expected to find 1 entry but found 2"),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1823,10 +2280,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: expected to find 1 entry but found 0"),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1891,10 +2353,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInRootDSECondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1953,9 +2420,13 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
 						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "True",
@@ -1964,6 +2435,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "loaded TLS configuration",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -1977,8 +2449,464 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
 			}},
 		},
-	}
-
+		{
+			name: "a user attribute can be used in the group search filter",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.GroupSearch.UserAttributeForFilter = "sAMAccountName"
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:                   testGroupSearchBase,
+						Filter:                 testGroupSearchFilter,
+						UserAttributeForFilter: "sAMAccountName",
+						GroupNameAttribute:     testGroupNameAttrName,
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID")},
+					RefreshAttributeChecks: map[string]func(*ldap.Entry, provider.RefreshAttributes) error{
+						"pwdLastSet":                         upstreamldap.AttributeUnchangedSinceLogin("pwdLastSet"),
+						"userAccountControl":                 validUserAccountControl,
+						"msDS-User-Account-Control-Computed": validComputedUserAccountControl,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "loaded TLS configuration",
+							ObservedGeneration: 1234,
+						},
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
+			}},
+		},
+		{
+			name: "invalid search scope and alias dereferencing are reported",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.UserSearch.DerefAliases = "Sometimes"
+				upstream.Spec.GroupSearch.Scope = "Everything"
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						{
+							Type:               "SearchOptionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidSearchOptions",
+							Message:            `userSearch.derefAliases "Sometimes" is not valid; groupSearch.scope "Everything" is not valid`,
+							ObservedGeneration: 1234,
+						},
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid group name transformations are reported",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.GroupSearch.GroupNameTransformations = []v1alpha1.GroupNameTransformation{
+					{Type: v1alpha1.GroupNameTransformationTypeRegexReplace},
+					{Type: v1alpha1.GroupNameTransformationTypeStripDNComponents, Replacement: "x"},
+				}
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						{
+							Type:               "SearchOptionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidSearchOptions",
+							Message: `groupSearch.groupNameTransformations[0].regex is required when type is "RegexReplace"; ` +
+								`groupSearch.groupNameTransformations[1].regex and replacement are not allowed when type is "StripDNComponents"`,
+							ObservedGeneration: 1234,
+						},
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "negative timeouts are invalid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.SearchTimeoutSeconds = -1
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						domainControllersDiscoveredFromSpecCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						{
+							Type:               "TimeoutsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTimeouts",
+							Message:            "searchTimeoutSeconds must not be negative, but was -1",
+							ObservedGeneration: 1234,
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "refresh checks can be skipped and unchanged attribute checks can be added",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.RefreshChecks = v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks{
+					SkipPasswordChangedCheck: true,
+					SkipAccountLockedCheck:   true,
+					UnchangedAttributes:      []string{"employeeID", "userAccountControl"},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID")},
+					// The checks of the attributes are closures, so build the expected checks in the same way as the
+					// controller. Their behavior is tested by TestRefreshAttributeChecks.
+					RefreshAttributeChecks: refreshAttributeChecks(v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks{
+						SkipPasswordChangedCheck: true,
+						SkipAccountLockedCheck:   true,
+						UnchangedAttributes:      []string{"employeeID", "userAccountControl"},
+					}),
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
+			}},
+		},
+		{
+			name: "discovers the domain controllers using DNS SRV records and ranks them by priority and weight",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.Host = ""
+				upstream.Spec.DomainControllerDiscovery = &v1alpha1.ActiveDirectoryIdentityProviderDomainControllerDiscovery{
+					Domain: "activedirectory.example.com",
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+				if service != "ldap" || proto != "tcp" || name != "activedirectory.example.com" {
+					return "", nil, fmt.Errorf("unexpected SRV lookup: _%s._%s.%s", service, proto, name)
+				}
+				return "_ldap._tcp.activedirectory.example.com.", []*net.SRV{
+					{Target: "dc3.activedirectory.example.com.", Port: 389, Priority: 10, Weight: 100},
+					{Target: "dc2.activedirectory.example.com.", Port: 389, Priority: 0, Weight: 50},
+					{Target: "dc1.activedirectory.example.com.", Port: 389, Priority: 0, Weight: 100},
+				}, nil
+			},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               "dc1.activedirectory.example.com",
+					FailoverHosts:      []string{"dc2.activedirectory.example.com", "dc3.activedirectory.example.com"},
+					DiscoveredDomain:   "activedirectory.example.com",
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID")},
+					RefreshAttributeChecks: map[string]func(*ldap.Entry, provider.RefreshAttributes) error{
+						"pwdLastSet":                         upstreamldap.AttributeUnchangedSinceLogin("pwdLastSet"),
+						"userAccountControl":                 validUserAccountControl,
+						"msDS-User-Account-Control-Computed": validComputedUserAccountControl,
+					},
+				},
+			},
+			wantRequeueAfter: []time.Duration{5 * time.Minute},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "DomainControllersDiscovered",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: `discovered domain controllers for domain "activedirectory.example.com": ` +
+								`["dc1.activedirectory.example.com" "dc2.activedirectory.example.com" "dc3.activedirectory.example.com"]`,
+							ObservedGeneration: 1234,
+						},
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: fmt.Sprintf(
+								`successfully able to connect to "dc1.activedirectory.example.com" and bind as user "%s" [validated with Secret "%s" at version "4242"]`,
+								testBindUsername, testSecretName),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchBaseFoundInConfigCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition: &v1alpha1.Condition{
+					Type:   "LDAPConnectionValid",
+					Status: "True",
+					Reason: "Success",
+					Message: fmt.Sprintf(
+						`successfully able to connect to "dc1.activedirectory.example.com" and bind as user "%s" [validated with Secret "%s" at version "4242"]`,
+						testBindUsername, testSecretName),
+				},
+				SearchBaseFoundCondition: condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
+			}},
+		},
+		{
+			name: "failing to discover the domain controllers is an error and the SRV records are looked up again at the configured interval",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.Host = ""
+				upstream.Spec.DomainControllerDiscovery = &v1alpha1.ActiveDirectoryIdentityProviderDomainControllerDiscovery{
+					Domain:                 "activedirectory.example.com",
+					RefreshIntervalSeconds: 60,
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+				return "", nil, errors.New("some DNS error")
+			},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantRequeueAfter:   []time.Duration{time.Minute},
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "DomainControllersDiscovered",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "DomainControllerDiscoveryError",
+							Message:            `error discovering domain controllers for domain "activedirectory.example.com": some DNS error`,
+							ObservedGeneration: 1234,
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "SRV records without any domain controllers are an error",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.Host = ""
+				upstream.Spec.DomainControllerDiscovery = &v1alpha1.ActiveDirectoryIdentityProviderDomainControllerDiscovery{
+					Domain: "activedirectory.example.com",
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+				return "_ldap._tcp.activedirectory.example.com.", []*net.SRV{{Target: ".", Port: 0, Priority: 0, Weight: 0}}, nil
+			},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantRequeueAfter:   []time.Duration{5 * time.Minute},
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "DomainControllersDiscovered",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "DomainControllerDiscoveryError",
+							Message: `error discovering domain controllers for domain "activedirectory.example.com": ` +
+								`no domain controllers were found in the SRV records for _ldap._tcp.activedirectory.example.com`,
+							ObservedGeneration: 1234,
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "configuring both host and domainControllerDiscovery is invalid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.DomainControllerDiscovery = &v1alpha1.ActiveDirectoryIdentityProviderDomainControllerDiscovery{
+					Domain: "activedirectory.example.com",
+				}
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantRequeueAfter:   []time.Duration{5 * time.Minute},
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "DomainControllersDiscovered",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidDomainControllerDiscovery",
+							Message:            "host and hosts must not be configured when domainControllerDiscovery is configured",
+							ObservedGeneration: 1234,
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "configuring neither host nor domainControllerDiscovery is invalid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.Host = ""
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "DomainControllersDiscovered",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidDomainControllerDiscovery",
+							Message:            "host must be configured when domainControllerDiscovery is not configured",
+							ObservedGeneration: 1234,
+						},
+					},
+				},
+			}},
+		},
+	}
+
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -2001,6 +2929,13 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				tt.setupMocks(conn)
 			}
 
+			lookupSRV := tt.lookupSRV
+			if lookupSRV == nil {
+				lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+					return "", nil, errors.New("SRV records should not have been looked up")
+				}
+			}
+
 			dialer := &comparableDialer{upstreamldap.LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (upstreamldap.Conn, error) {
 				if tt.dialErrors != nil {
 					dialErr := tt.dialErrors[addr.Endpoint()]
@@ -2022,6 +2957,8 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				}
 			}
 
+			eventRecorder := events.NewFakeRecorder(100)
+
 			controller := newInternal(
 				cache,
 				validatedSettingsCache,
@@ -2029,7 +2966,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				fakePinnipedClient,
 				pinnipedInformers.IDP().V1alpha1().ActiveDirectoryIdentityProviders(),
 				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
 				controllerlib.WithInformer,
+				lookupSRV,
+				clocktesting.NewFakeClock(time.Now()),
 			)
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -2039,13 +2980,15 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			kubeInformers.Start(ctx.Done())
 			controllerlib.TestRunSynchronously(t, controller)
 
-			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}}
+			queue := &recordingQueue{}
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}, Queue: queue}
 
 			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
 				require.EqualError(t, err, tt.wantErr)
 			} else {
 				require.NoError(t, err)
 			}
+			require.Equal(t, tt.wantRequeueAfter, queue.addAfterDurations)
 
 			actualIDPList := cache.GetActiveDirectoryIdentityProviders()
 			require.Equal(t, len(tt.wantResultingCache), len(actualIDPList))
@@ -2110,10 +3053,27 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				tt.wantValidatedSettings = map[string]upstreamwatchers.ValidatedSettings{}
 			}
 			require.Equal(t, tt.wantValidatedSettings, validatedSettingsCache.ValidatedSettingsByName)
+
+			// Check the Events which were recorded for condition transitions, when the test cares about them.
+			if tt.wantEvents != nil {
+				require.Equal(t, tt.wantEvents, recordedEvents(eventRecorder))
+			}
 		})
 	}
 }
 
+func recordedEvents(eventRecorder *events.FakeRecorder) []string {
+	recorded := []string{}
+	for {
+		select {
+		case event := <-eventRecorder.Events:
+			recorded = append(recorded, event)
+		default:
+			return recorded
+		}
+	}
+}
+
 func normalizeActiveDirectoryUpstreams(upstreams []v1alpha1.ActiveDirectoryIdentityProvider, now metav1.Time) []v1alpha1.ActiveDirectoryIdentityProvider {
 	result := make([]v1alpha1.ActiveDirectoryIdentityProvider, 0, len(upstreams))
 	for _, u := range upstreams {
@@ -2405,3 +3365,94 @@ func TestValidComputedUserAccountControl(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshAttributeChecks(t *testing.T) {
+	encoded := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	entry := &ldap.Entry{
+		DN: "some-dn",
+		Attributes: []*ldap.EntryAttribute{
+			ldap.NewEntryAttribute("pwdLastSet", []string{"132801740800000000"}),
+			ldap.NewEntryAttribute("userAccountControl", []string{"512"}),
+			ldap.NewEntryAttribute("msDS-User-Account-Control-Computed", []string{"16"}),
+			ldap.NewEntryAttribute("employeeID", []string{"1234"}),
+		},
+	}
+
+	tests := []struct {
+		name             string
+		refreshChecks    v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks
+		storedAttributes map[string]string
+		wantErrs         map[string]string
+	}{
+		{
+			name: "all built-in checks are enabled by default",
+			storedAttributes: map[string]string{
+				"pwdLastSet": encoded("132801740800000000"),
+			},
+			wantErrs: map[string]string{
+				"pwdLastSet":                         "",
+				"userAccountControl":                 "",
+				"msDS-User-Account-Control-Computed": "user has been locked",
+			},
+		},
+		{
+			name: "built-in checks can be skipped",
+			refreshChecks: v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks{
+				SkipPasswordChangedCheck: true,
+				SkipAccountDisabledCheck: true,
+				SkipAccountLockedCheck:   true,
+			},
+			wantErrs: map[string]string{},
+		},
+		{
+			name: "unchanged attribute checks can be added",
+			refreshChecks: v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks{
+				SkipAccountLockedCheck: true,
+				UnchangedAttributes:    []string{"employeeID"},
+			},
+			storedAttributes: map[string]string{
+				"pwdLastSet": encoded("132801740800000000"),
+				"employeeID": encoded("5678"),
+			},
+			wantErrs: map[string]string{
+				"pwdLastSet":         "",
+				"userAccountControl": "",
+				"employeeID":         `value for attribute "employeeID" has changed since initial value at login`,
+			},
+		},
+		{
+			name: "an unchanged attribute check is performed in addition to a built-in check of the same attribute",
+			refreshChecks: v1alpha1.ActiveDirectoryIdentityProviderRefreshChecks{
+				SkipPasswordChangedCheck: true,
+				SkipAccountLockedCheck:   true,
+				UnchangedAttributes:      []string{"userAccountControl"},
+			},
+			storedAttributes: map[string]string{
+				"userAccountControl": encoded("514"),
+			},
+			wantErrs: map[string]string{
+				"userAccountControl": `value for attribute "userAccountControl" has changed since initial value at login`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			checks := refreshAttributeChecks(tt.refreshChecks)
+
+			require.Len(t, checks, len(tt.wantErrs))
+			for attribute, wantErr := range tt.wantErrs {
+				require.Contains(t, checks, attribute)
+				err := checks[attribute](entry, provider.RefreshAttributes{AdditionalAttributes: tt.storedAttributes})
+				if wantErr != "" {
+					require.EqualError(t, err, wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+		})
+	}
+}
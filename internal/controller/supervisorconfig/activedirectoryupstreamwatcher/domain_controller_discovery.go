@@ -0,0 +1,199 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package activedirectoryupstreamwatcher
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/upstreamldap"
+)
+
+const (
+	typeDomainControllersDiscovered        = "DomainControllersDiscovered"
+	reasonInvalidDomainControllerDiscovery = "InvalidDomainControllerDiscovery"
+	reasonDomainControllerDiscoveryError   = "DomainControllerDiscoveryError"
+	discoveryNotConfiguredMessage          = "domainControllerDiscovery is not configured, so using the configured host"
+
+	// The SRV records which are registered by every Active Directory domain controller are _ldap._tcp.<domain>.
+	// See https://learn.microsoft.com/en-us/troubleshoot/windows-server/networking/verify-srv-dns-records-have-been-created
+	domainControllerSRVService = "ldap"
+	domainControllerSRVProto   = "tcp"
+
+	defaultDomainControllerRefreshInterval = 5 * time.Minute
+	lookupDomainControllersTimeout         = 30 * time.Second
+)
+
+// lookupSRVFunc has the same signature as net.Resolver.LookupSRV, so DNS lookups can be faked in tests.
+type lookupSRVFunc func(ctx context.Context, service, proto, name string) (cname string, addrs []*net.SRV, err error)
+
+// discoveredDomainControllers are the results of the most recent successful lookup of a domain's SRV records.
+type discoveredDomainControllers struct {
+	domain       string
+	hosts        []string
+	discoveredAt time.Time
+}
+
+func domainControllerRefreshInterval(discovery *v1alpha1.ActiveDirectoryIdentityProviderDomainControllerDiscovery) time.Duration {
+	if discovery.RefreshIntervalSeconds <= 0 {
+		return defaultDomainControllerRefreshInterval
+	}
+	return time.Duration(discovery.RefreshIntervalSeconds) * time.Second
+}
+
+// rankDomainControllers returns the hostnames from the SRV records ordered by priority (lowest first), then by weight
+// (highest first), and then by name. Unlike the weighted random selection described by RFC 2782, this order is stable,
+// which avoids needlessly switching between domain controllers each time that the records are looked up again.
+func rankDomainControllers(records []*net.SRV) []string {
+	sorted := make([]*net.SRV, 0, len(records))
+	for _, record := range records {
+		// A target of "." means that the service is decidedly not available at this domain (RFC 2782).
+		if record == nil || record.Target == "." {
+			continue
+		}
+		sorted = append(sorted, record)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		if sorted[i].Weight != sorted[j].Weight {
+			return sorted[i].Weight > sorted[j].Weight
+		}
+		return sorted[i].Target < sorted[j].Target
+	})
+
+	hosts := make([]string, 0, len(sorted))
+	seen := map[string]bool{}
+	for _, record := range sorted {
+		// The ports from the SRV records are for plain LDAP, so leave the port unspecified to allow the default port
+		// of the connection protocol to be used instead.
+		host := strings.ToLower(strings.TrimSuffix(record.Target, "."))
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func (c *activeDirectoryWatcherController) lookupDomainControllers(ctx context.Context, domain string) ([]string, error) {
+	lookupCtx, cancelFunc := context.WithTimeout(ctx, lookupDomainControllersTimeout)
+	defer cancelFunc()
+
+	_, records, err := c.lookupSRV(lookupCtx, domainControllerSRVService, domainControllerSRVProto, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := rankDomainControllers(records)
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no domain controllers were found in the SRV records for _%s._%s.%s",
+			domainControllerSRVService, domainControllerSRVProto, domain)
+	}
+	return hosts, nil
+}
+
+// discoverDomainControllers sets the hosts of the config, either from the spec or by looking up the domain controllers
+// of the configured domain. The lookup is cached until the refresh interval has passed. When a refresh fails, the
+// previously discovered domain controllers continue to be used. The config's Host is left empty when there is no
+// host to which the provider could connect.
+func (c *activeDirectoryWatcherController) discoverDomainControllers(
+	ctx context.Context,
+	upstream *v1alpha1.ActiveDirectoryIdentityProvider,
+	config *upstreamldap.ProviderConfig,
+) *v1alpha1.Condition {
+	spec := upstream.Spec
+	discovery := spec.DomainControllerDiscovery
+
+	if discovery == nil {
+		delete(c.discoveredDomainControllers, upstream.Name)
+		if len(spec.Host) == 0 {
+			return &v1alpha1.Condition{
+				Type:    typeDomainControllersDiscovered,
+				Status:  v1alpha1.ConditionFalse,
+				Reason:  reasonInvalidDomainControllerDiscovery,
+				Message: "host must be configured when domainControllerDiscovery is not configured",
+			}
+		}
+		config.Host = spec.Host
+		config.FailoverHosts = spec.Hosts
+		return &v1alpha1.Condition{
+			Type:    typeDomainControllersDiscovered,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  upstreamwatchers.ReasonUsingConfigurationFromSpec,
+			Message: discoveryNotConfiguredMessage,
+		}
+	}
+
+	if len(spec.Host) > 0 || len(spec.Hosts) > 0 {
+		return &v1alpha1.Condition{
+			Type:    typeDomainControllersDiscovered,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidDomainControllerDiscovery,
+			Message: "host and hosts must not be configured when domainControllerDiscovery is configured",
+		}
+	}
+
+	previous, hasPrevious := c.discoveredDomainControllers[upstream.Name]
+	hasPrevious = hasPrevious && previous.domain == discovery.Domain
+	if hasPrevious && c.clock.Now().Before(previous.discoveredAt.Add(domainControllerRefreshInterval(discovery))) {
+		setDiscoveredHosts(config, previous)
+		return discoveredCondition(previous)
+	}
+
+	hosts, err := c.lookupDomainControllers(ctx, discovery.Domain)
+	if err != nil {
+		if hasPrevious {
+			plog.WarningErr("could not refresh domain controllers, so using the previously discovered domain controllers", err,
+				"namespace", upstream.Namespace, "name", upstream.Name, "domain", discovery.Domain)
+			setDiscoveredHosts(config, previous)
+			return &v1alpha1.Condition{
+				Type:   typeDomainControllersDiscovered,
+				Status: v1alpha1.ConditionFalse,
+				Reason: reasonDomainControllerDiscoveryError,
+				Message: fmt.Sprintf(`error refreshing domain controllers for domain %q, so using the previously discovered domain controllers %q: %s`,
+					discovery.Domain, previous.hosts, err.Error()),
+			}
+		}
+		return &v1alpha1.Condition{
+			Type:    typeDomainControllersDiscovered,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonDomainControllerDiscoveryError,
+			Message: fmt.Sprintf(`error discovering domain controllers for domain %q: %s`, discovery.Domain, err.Error()),
+		}
+	}
+
+	discovered := discoveredDomainControllers{domain: discovery.Domain, hosts: hosts, discoveredAt: c.clock.Now()}
+	c.discoveredDomainControllers[upstream.Name] = discovered
+	setDiscoveredHosts(config, discovered)
+	return discoveredCondition(discovered)
+}
+
+func setDiscoveredHosts(config *upstreamldap.ProviderConfig, discovered discoveredDomainControllers) {
+	config.Host = discovered.hosts[0]
+	config.FailoverHosts = nil
+	if len(discovered.hosts) > 1 {
+		config.FailoverHosts = discovered.hosts[1:]
+	}
+	config.DiscoveredDomain = discovered.domain
+}
+
+func discoveredCondition(discovered discoveredDomainControllers) *v1alpha1.Condition {
+	return &v1alpha1.Condition{
+		Type:    typeDomainControllersDiscovered,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: fmt.Sprintf("discovered domain controllers for domain %q: %q", discovered.domain, discovered.hosts),
+	}
+}
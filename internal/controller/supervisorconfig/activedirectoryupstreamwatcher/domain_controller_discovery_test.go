@@ -0,0 +1,141 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package activedirectoryupstreamwatcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/upstreamldap"
+)
+
+func TestRankDomainControllers(t *testing.T) {
+	require.Equal(t,
+		[]string{
+			"dc1.activedirectory.example.com",
+			"dc2.activedirectory.example.com",
+			"dc3.activedirectory.example.com",
+			"dc4.activedirectory.example.com",
+		},
+		rankDomainControllers([]*net.SRV{
+			{Target: "dc4.activedirectory.example.com.", Port: 389, Priority: 20, Weight: 100},
+			{Target: "DC3.activedirectory.example.com.", Port: 389, Priority: 10, Weight: 0},
+			{Target: "dc2.activedirectory.example.com.", Port: 389, Priority: 0, Weight: 100},
+			{Target: "dc1.activedirectory.example.com.", Port: 389, Priority: 0, Weight: 100},
+			{Target: "dc2.activedirectory.example.com.", Port: 3268, Priority: 10, Weight: 100},
+			{Target: ".", Port: 0, Priority: 0, Weight: 0},
+		}),
+	)
+
+	require.Empty(t, rankDomainControllers(nil))
+}
+
+func TestDiscoverDomainControllers(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fakeClock := clocktesting.NewFakeClock(now)
+
+	var lookups int
+	var lookupErr error
+	records := []*net.SRV{
+		{Target: "dc1.activedirectory.example.com.", Port: 389, Priority: 0, Weight: 100},
+	}
+	c := &activeDirectoryWatcherController{
+		clock: fakeClock,
+		lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			lookups++
+			return "", records, lookupErr
+		},
+		discoveredDomainControllers: map[string]discoveredDomainControllers{},
+	}
+
+	upstream := &v1alpha1.ActiveDirectoryIdentityProvider{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+		Spec: v1alpha1.ActiveDirectoryIdentityProviderSpec{
+			DomainControllerDiscovery: &v1alpha1.ActiveDirectoryIdentityProviderDomainControllerDiscovery{
+				Domain:                 "activedirectory.example.com",
+				RefreshIntervalSeconds: 60,
+			},
+		},
+	}
+
+	// The first discovery looks up the SRV records.
+	config := &upstreamldap.ProviderConfig{}
+	condition := c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, v1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, 1, lookups)
+	require.Equal(t, "dc1.activedirectory.example.com", config.Host)
+	require.Nil(t, config.FailoverHosts)
+	require.Equal(t, "activedirectory.example.com", config.DiscoveredDomain)
+
+	// Before the refresh interval has passed, the previously discovered domain controllers are used without a lookup.
+	records = append(records, &net.SRV{Target: "dc2.activedirectory.example.com.", Port: 389, Priority: 0, Weight: 50})
+	fakeClock.Step(59 * time.Second)
+	config = &upstreamldap.ProviderConfig{}
+	condition = c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, v1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, 1, lookups)
+	require.Equal(t, "dc1.activedirectory.example.com", config.Host)
+	require.Nil(t, config.FailoverHosts)
+
+	// After the refresh interval has passed, the SRV records are looked up again.
+	fakeClock.Step(time.Second)
+	config = &upstreamldap.ProviderConfig{}
+	condition = c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, v1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, 2, lookups)
+	require.Equal(t, "dc1.activedirectory.example.com", config.Host)
+	require.Equal(t, []string{"dc2.activedirectory.example.com"}, config.FailoverHosts)
+
+	// When a refresh fails, the previously discovered domain controllers continue to be used.
+	lookupErr = errors.New("some DNS error")
+	fakeClock.Step(time.Minute)
+	config = &upstreamldap.ProviderConfig{}
+	condition = c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, &v1alpha1.Condition{
+		Type:   "DomainControllersDiscovered",
+		Status: v1alpha1.ConditionFalse,
+		Reason: "DomainControllerDiscoveryError",
+		Message: `error refreshing domain controllers for domain "activedirectory.example.com", so using the previously discovered ` +
+			`domain controllers ["dc1.activedirectory.example.com" "dc2.activedirectory.example.com"]: some DNS error`,
+	}, condition)
+	require.Equal(t, 3, lookups)
+	require.Equal(t, "dc1.activedirectory.example.com", config.Host)
+	require.Equal(t, []string{"dc2.activedirectory.example.com"}, config.FailoverHosts)
+
+	// The failed refresh is retried on the next discovery.
+	config = &upstreamldap.ProviderConfig{}
+	c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, 4, lookups)
+
+	// When the domain is changed, the previously discovered domain controllers cannot be used.
+	upstream.Spec.DomainControllerDiscovery.Domain = "other.example.com"
+	config = &upstreamldap.ProviderConfig{}
+	condition = c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, &v1alpha1.Condition{
+		Type:    "DomainControllersDiscovered",
+		Status:  v1alpha1.ConditionFalse,
+		Reason:  "DomainControllerDiscoveryError",
+		Message: `error discovering domain controllers for domain "other.example.com": some DNS error`,
+	}, condition)
+	require.Equal(t, 5, lookups)
+	require.Empty(t, config.Host)
+
+	// When discovery is no longer configured, the previously discovered domain controllers are forgotten.
+	upstream.Spec.DomainControllerDiscovery = nil
+	upstream.Spec.Host = "ldap.example.com"
+	config = &upstreamldap.ProviderConfig{}
+	condition = c.discoverDomainControllers(context.Background(), upstream, config)
+	require.Equal(t, v1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, "ldap.example.com", config.Host)
+	require.Empty(t, config.DiscoveredDomain)
+	require.Empty(t, c.discoveredDomainControllers)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -6,25 +6,72 @@ package supervisorconfig
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
 	configinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/claimmappings"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/idtransform"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 )
 
+const (
+	typeIdentityProvidersFound              = "IdentityProvidersFound"
+	typeIdentityProvidersDisplayNamesUnique = "IdentityProvidersDisplayNamesUnique"
+	typeTransformsExpressionsValid          = "TransformsExpressionsValid"
+	typeTokenLifetimesValid                 = "TokenLifetimesValid"
+	typeSessionLifetimesValid               = "SessionLifetimesValid"
+	typeClaimMappingsValid                  = "ClaimMappingsValid"
+	typeSigningKeyRotationValid             = "SigningKeyRotationValid"
+	typeCORSValid                           = "CORSValid"
+
+	reasonSuccess                             = "Success"
+	reasonIdentityProvidersObjectRefsNotFound = "IdentityProvidersObjectRefsNotFound"
+	reasonDuplicateDisplayNames               = "DuplicateDisplayNames"
+	reasonInvalidTransformsExpressions        = "InvalidTransformsExpressions"
+	reasonInvalidTokenLifetimes               = "InvalidTokenLifetimes"
+	reasonInvalidSessionLifetimes             = "InvalidSessionLifetimes"
+	reasonInvalidClaimMappings                = "InvalidClaimMappings"
+	reasonInvalidSigningKeyRotation           = "InvalidSigningKeyRotation"
+	reasonInvalidCORS                         = "InvalidCORS"
+
+	minSessionIdleTimeoutSeconds = 5 * 60
+	maxSessionIdleTimeoutSeconds = 9 * 60 * 60
+	minSessionMaxLifetimeSeconds = 10 * 60
+	maxSessionMaxLifetimeSeconds = 30 * 24 * 60 * 60
+
+	minSigningKeyRotationIntervalSeconds = 2 * 60 * 60
+	maxSigningKeyRotationIntervalSeconds = 365 * 24 * 60 * 60
+	minSigningKeyRotationOverlapSeconds  = 10 * 60
+	maxSigningKeyRotationOverlapSeconds  = 24 * 60 * 60
+	defaultSigningKeyRotationOverlap     = time.Hour
+
+	maxCORSMaxAgeSeconds = 24 * 60 * 60
+)
+
 // ProvidersSetter can be notified of all known valid providers with its SetIssuer function.
 // If there are no longer any valid issuers, then it can be called with no arguments.
 // Implementations of this type should be thread-safe to support calls from multiple goroutines.
@@ -34,35 +81,62 @@ type ProvidersSetter interface {
 
 type federationDomainWatcherController struct {
 	providerSetter           ProvidersSetter
+	apiGroupSuffix           string
 	clock                    clock.Clock
 	client                   pinnipedclientset.Interface
 	federationDomainInformer configinformers.FederationDomainInformer
+	idpInformers             idpinformers.Interface
 }
 
 // NewFederationDomainWatcherController creates a controllerlib.Controller that watches
 // FederationDomain objects and notifies a callback object of the collection of provider configs.
+// It also watches the identity provider objects, so that it can resolve the spec.identityProviders of each
+// FederationDomain.
 func NewFederationDomainWatcherController(
 	providerSetter ProvidersSetter,
+	apiGroupSuffix string,
 	clock clock.Clock,
 	client pinnipedclientset.Interface,
 	federationDomainInformer configinformers.FederationDomainInformer,
+	idpInformers idpinformers.Interface,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
+	options := []controllerlib.Option{
+		withInformer(
+			federationDomainInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	}
+	for _, idpInformer := range []controllerlib.InformerGetter{
+		idpInformers.OIDCIdentityProviders(),
+		idpInformers.OAuth2IdentityProviders(),
+		idpInformers.GitHubIdentityProviders(),
+		idpInformers.GitLabIdentityProviders(),
+		idpInformers.LDAPIdentityProviders(),
+		idpInformers.ActiveDirectoryIdentityProviders(),
+		idpInformers.KerberosIdentityProviders(),
+	} {
+		options = append(options, withInformer(
+			idpInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		))
+	}
+
 	return controllerlib.New(
 		controllerlib.Config{
 			Name: "FederationDomainWatcherController",
 			Syncer: &federationDomainWatcherController{
 				providerSetter:           providerSetter,
+				apiGroupSuffix:           apiGroupSuffix,
 				clock:                    clock,
 				client:                   client,
 				federationDomainInformer: federationDomainInformer,
+				idpInformers:             idpInformers,
 			},
 		},
-		withInformer(
-			federationDomainInformer,
-			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
-			controllerlib.InformerOption{},
-		),
+		options...,
 	)
 }
 
@@ -111,6 +185,25 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 
 	federationDomainIssuers := make([]*provider.FederationDomainIssuer, 0)
 	for _, federationDomain := range federationDomains {
+		identityProviders, conditions, err := c.resolveIdentityProviders(federationDomain)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not resolve identity providers: %w", err))
+			continue
+		}
+		identityTransforms, transformsConditions := validateTransforms(federationDomain)
+		conditions = append(conditions, transformsConditions...)
+		tokenLifetimes, tokenLifetimesConditions := validateTokenLifetimes(federationDomain)
+		conditions = append(conditions, tokenLifetimesConditions...)
+		sessionLifetimes, sessionLifetimesConditions := validateSessionLifetimes(federationDomain)
+		conditions = append(conditions, sessionLifetimesConditions...)
+		tokenExchangeAudiences := tokenExchangeAudiencesToStrings(federationDomain.Spec.AllowedTokenExchangeAudiences)
+		claimMappings, claimMappingsConditions := validateClaimMappings(federationDomain)
+		conditions = append(conditions, claimMappingsConditions...)
+		_, signingKeyRotationConditions := validateSigningKeyRotation(federationDomain)
+		conditions = append(conditions, signingKeyRotationConditions...)
+		corsPolicy, corsConditions := validateCORS(federationDomain)
+		conditions = append(conditions, corsConditions...)
+
 		issuerURL, urlParseErr := url.Parse(federationDomain.Spec.Issuer)
 
 		// Skip url parse errors because they will be validated below.
@@ -122,6 +215,7 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 					federationDomain.Name,
 					configv1alpha1.DuplicateFederationDomainStatusCondition,
 					"Duplicate issuer: "+federationDomain.Spec.Issuer,
+					conditions,
 				); err != nil {
 					errs = append(errs, fmt.Errorf("could not update status: %w", err))
 				}
@@ -137,13 +231,14 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 				federationDomain.Name,
 				configv1alpha1.SameIssuerHostMustUseSameSecretFederationDomainStatusCondition,
 				"Issuers with the same DNS hostname (address not including port) must use the same secretName: "+issuerURLToHostnameKey(issuerURL),
+				conditions,
 			); err != nil {
 				errs = append(errs, fmt.Errorf("could not update status: %w", err))
 			}
 			continue
 		}
 
-		federationDomainIssuer, err := provider.NewFederationDomainIssuer(federationDomain.Spec.Issuer) // This validates the Issuer URL.
+		federationDomainIssuer, err := provider.NewFederationDomainIssuerWithSettings(federationDomain.Spec.Issuer, identityProviders, identityTransforms, tokenLifetimes, sessionLifetimes, tokenExchangeAudiences, claimMappings, string(idTokenSigningAlgorithm(federationDomain)), corsPolicy) // This validates the Issuer URL.
 		if err != nil {
 			if err := c.updateStatus(
 				ctx.Context,
@@ -151,6 +246,21 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 				federationDomain.Name,
 				configv1alpha1.InvalidFederationDomainStatusCondition,
 				"Invalid: "+err.Error(),
+				conditions,
+			); err != nil {
+				errs = append(errs, fmt.Errorf("could not update status: %w", err))
+			}
+			continue
+		}
+
+		if hadErrorCondition(conditions) {
+			if err := c.updateStatus(
+				ctx.Context,
+				federationDomain.Namespace,
+				federationDomain.Name,
+				configv1alpha1.InvalidFederationDomainStatusCondition,
+				"Invalid: see status.conditions for details",
+				conditions,
 			); err != nil {
 				errs = append(errs, fmt.Errorf("could not update status: %w", err))
 			}
@@ -163,6 +273,7 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 			federationDomain.Name,
 			configv1alpha1.SuccessFederationDomainStatusCondition,
 			"Provider successfully created",
+			conditions,
 		); err != nil {
 			errs = append(errs, fmt.Errorf("could not update status: %w", err))
 			continue
@@ -176,11 +287,404 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 	return errors.NewAggregate(errs)
 }
 
+// resolveIdentityProviders finds the resources referred to by the spec.identityProviders of the FederationDomain. It
+// returns the resolved identity providers, or nil when the FederationDomain does not list any, and the conditions which
+// describe them. There are no conditions when the FederationDomain does not list any identity providers.
+func (c *federationDomainWatcherController) resolveIdentityProviders(
+	federationDomain *configv1alpha1.FederationDomain,
+) ([]provider.FederationDomainIdentityProvider, []*configv1alpha1.Condition, error) {
+	if len(federationDomain.Spec.IdentityProviders) == 0 {
+		return nil, nil, nil
+	}
+
+	identityProviders := make([]provider.FederationDomainIdentityProvider, 0, len(federationDomain.Spec.IdentityProviders))
+	displayNames := sets.NewString()
+	duplicateDisplayNames := sets.NewString()
+	var notFound []string
+	for i, idp := range federationDomain.Spec.IdentityProviders {
+		if displayNames.Has(idp.DisplayName) {
+			duplicateDisplayNames.Insert(idp.DisplayName)
+		}
+		displayNames.Insert(idp.DisplayName)
+
+		uid, problem, err := c.findIdentityProviderUID(federationDomain.Namespace, idp.ObjectRef)
+		if err != nil {
+			return nil, nil, err
+		}
+		if problem != "" {
+			notFound = append(notFound, fmt.Sprintf(".spec.identityProviders[%d] with displayName %q: %s", i, idp.DisplayName, problem))
+			continue
+		}
+		identityProviders = append(identityProviders, provider.FederationDomainIdentityProvider{
+			DisplayName: idp.DisplayName,
+			UID:         uid,
+		})
+	}
+
+	var conditions []*configv1alpha1.Condition
+	if len(notFound) > 0 {
+		conditions = append(conditions, &configv1alpha1.Condition{
+			Type:    typeIdentityProvidersFound,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonIdentityProvidersObjectRefsNotFound,
+			Message: strings.Join(notFound, "\n"),
+		})
+	} else {
+		conditions = append(conditions, &configv1alpha1.Condition{
+			Type:    typeIdentityProvidersFound,
+			Status:  configv1alpha1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: "the resources specified by .spec.identityProviders[].objectRef were found",
+		})
+	}
+
+	if duplicateDisplayNames.Len() > 0 {
+		conditions = append(conditions, &configv1alpha1.Condition{
+			Type:   typeIdentityProvidersDisplayNamesUnique,
+			Status: configv1alpha1.ConditionFalse,
+			Reason: reasonDuplicateDisplayNames,
+			Message: fmt.Sprintf("the names specified by .spec.identityProviders[].displayName contain duplicates: %s",
+				strings.Join(duplicateDisplayNames.List(), ", ")),
+		})
+	} else {
+		conditions = append(conditions, &configv1alpha1.Condition{
+			Type:    typeIdentityProvidersDisplayNamesUnique,
+			Status:  configv1alpha1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: "the names specified by .spec.identityProviders[].displayName are unique",
+		})
+	}
+
+	return identityProviders, conditions, nil
+}
+
+// findIdentityProviderUID returns the UID of the identity provider resource referred to by objectRef. When the
+// resource cannot be found, it returns a description of the problem instead.
+func (c *federationDomainWatcherController) findIdentityProviderUID(
+	namespace string,
+	objectRef corev1.TypedLocalObjectReference,
+) (types.UID, string, error) {
+	wantAPIGroup, _ := groupsuffix.Replace(idpv1alpha1.SchemeGroupVersion.Group, c.apiGroupSuffix)
+	if objectRef.APIGroup == nil || *objectRef.APIGroup != wantAPIGroup {
+		return "", fmt.Sprintf("objectRef must have apiGroup %q", wantAPIGroup), nil
+	}
+
+	var idp metav1.Object
+	var err error
+	switch objectRef.Kind {
+	case "OIDCIdentityProvider":
+		idp, err = c.idpInformers.OIDCIdentityProviders().Lister().OIDCIdentityProviders(namespace).Get(objectRef.Name)
+	case "OAuth2IdentityProvider":
+		idp, err = c.idpInformers.OAuth2IdentityProviders().Lister().OAuth2IdentityProviders(namespace).Get(objectRef.Name)
+	case "GitHubIdentityProvider":
+		idp, err = c.idpInformers.GitHubIdentityProviders().Lister().GitHubIdentityProviders(namespace).Get(objectRef.Name)
+	case "GitLabIdentityProvider":
+		idp, err = c.idpInformers.GitLabIdentityProviders().Lister().GitLabIdentityProviders(namespace).Get(objectRef.Name)
+	case "LDAPIdentityProvider":
+		idp, err = c.idpInformers.LDAPIdentityProviders().Lister().LDAPIdentityProviders(namespace).Get(objectRef.Name)
+	case "ActiveDirectoryIdentityProvider":
+		idp, err = c.idpInformers.ActiveDirectoryIdentityProviders().Lister().ActiveDirectoryIdentityProviders(namespace).Get(objectRef.Name)
+	case "KerberosIdentityProvider":
+		idp, err = c.idpInformers.KerberosIdentityProviders().Lister().KerberosIdentityProviders(namespace).Get(objectRef.Name)
+	default:
+		return "", fmt.Sprintf("objectRef kind %q is not supported", objectRef.Kind), nil
+	}
+	if k8serrors.IsNotFound(err) {
+		return "", fmt.Sprintf("cannot find %s %q", objectRef.Kind, objectRef.Name), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return idp.GetUID(), "", nil
+}
+
+// validateTransforms compiles the spec.transforms of the FederationDomain. It returns the compiled transforms, or nil
+// when there are none, and the conditions which describe the transforms. There are no conditions when the
+// FederationDomain has no transforms.
+func validateTransforms(federationDomain *configv1alpha1.FederationDomain) (*idtransform.TransformationPipeline, []*configv1alpha1.Condition) {
+	if len(federationDomain.Spec.Transforms.Expressions) == 0 {
+		return nil, nil
+	}
+
+	expressions := make([]idtransform.Expression, 0, len(federationDomain.Spec.Transforms.Expressions))
+	for _, e := range federationDomain.Spec.Transforms.Expressions {
+		expressions = append(expressions, idtransform.Expression{
+			Type:       idtransform.ExpressionType(e.Type),
+			Expression: e.Expression,
+			Message:    e.Message,
+		})
+	}
+
+	identityTransforms, err := idtransform.NewTransformationPipeline(expressions)
+	if err != nil {
+		return nil, []*configv1alpha1.Condition{{
+			Type:    typeTransformsExpressionsValid,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonInvalidTransformsExpressions,
+			Message: "spec.transforms: " + err.Error(),
+		}}
+	}
+
+	return identityTransforms, []*configv1alpha1.Condition{{
+		Type:    typeTransformsExpressionsValid,
+		Status:  configv1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: fmt.Sprintf("the %d spec.transforms.expressions are valid", len(expressions)),
+	}}
+}
+
+// validateTokenLifetimes checks the bounds of the spec.tokenLifetimes of the FederationDomain. It returns the
+// configured lifetimes, where zero means that the Supervisor's default should be used, and the conditions which
+// describe the lifetimes. There are no conditions when the FederationDomain does not configure any lifetimes.
+func validateTokenLifetimes(federationDomain *configv1alpha1.FederationDomain) (provider.TokenLifetimes, []*configv1alpha1.Condition) {
+	lifetimes := federationDomain.Spec.TokenLifetimes
+	if lifetimes.IDTokenSeconds == nil && lifetimes.AccessTokenSeconds == nil && lifetimes.RefreshTokenSeconds == nil {
+		return provider.TokenLifetimes{}, nil
+	}
+
+	if m := oidcclientvalidator.ValidateTokenLifetimes("spec.tokenLifetimes", lifetimes); len(m) > 0 {
+		return provider.TokenLifetimes{}, []*configv1alpha1.Condition{{
+			Type:    typeTokenLifetimesValid,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonInvalidTokenLifetimes,
+			Message: strings.Join(m, "; "),
+		}}
+	}
+
+	return provider.TokenLifetimes{
+		IDToken:      secondsToDuration(lifetimes.IDTokenSeconds),
+		AccessToken:  secondsToDuration(lifetimes.AccessTokenSeconds),
+		RefreshToken: secondsToDuration(lifetimes.RefreshTokenSeconds),
+	}, []*configv1alpha1.Condition{{
+		Type:    typeTokenLifetimesValid,
+		Status:  configv1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: "spec.tokenLifetimes are valid",
+	}}
+}
+
+// validateSessionLifetimes checks the bounds of the spec.sessionLifetimes of the FederationDomain. It returns the
+// configured lifetimes, where zero means that there is no limit, and the conditions which describe the lifetimes.
+// There are no conditions when the FederationDomain does not configure any lifetimes.
+func validateSessionLifetimes(federationDomain *configv1alpha1.FederationDomain) (provider.SessionLifetimes, []*configv1alpha1.Condition) {
+	lifetimes := federationDomain.Spec.SessionLifetimes
+	if lifetimes.IdleTimeoutSeconds == nil && lifetimes.MaxLifetimeSeconds == nil {
+		return provider.SessionLifetimes{}, nil
+	}
+
+	var m []string
+	if s := lifetimes.IdleTimeoutSeconds; s != nil && (*s < minSessionIdleTimeoutSeconds || *s > maxSessionIdleTimeoutSeconds) {
+		m = append(m, fmt.Sprintf("spec.sessionLifetimes.idleTimeoutSeconds must be between %d and %d seconds, but was %d",
+			minSessionIdleTimeoutSeconds, maxSessionIdleTimeoutSeconds, *s))
+	}
+	if s := lifetimes.MaxLifetimeSeconds; s != nil && (*s < minSessionMaxLifetimeSeconds || *s > maxSessionMaxLifetimeSeconds) {
+		m = append(m, fmt.Sprintf("spec.sessionLifetimes.maxLifetimeSeconds must be between %d and %d seconds, but was %d",
+			minSessionMaxLifetimeSeconds, maxSessionMaxLifetimeSeconds, *s))
+	}
+	if lifetimes.IdleTimeoutSeconds != nil && lifetimes.MaxLifetimeSeconds != nil &&
+		*lifetimes.MaxLifetimeSeconds < *lifetimes.IdleTimeoutSeconds {
+		m = append(m, "spec.sessionLifetimes.maxLifetimeSeconds must not be less than spec.sessionLifetimes.idleTimeoutSeconds")
+	}
+
+	if len(m) > 0 {
+		return provider.SessionLifetimes{}, []*configv1alpha1.Condition{{
+			Type:    typeSessionLifetimesValid,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonInvalidSessionLifetimes,
+			Message: strings.Join(m, "; "),
+		}}
+	}
+
+	return provider.SessionLifetimes{
+		IdleTimeout: secondsToDuration(lifetimes.IdleTimeoutSeconds),
+		MaxLifetime: secondsToDuration(lifetimes.MaxLifetimeSeconds),
+	}, []*configv1alpha1.Condition{{
+		Type:    typeSessionLifetimesValid,
+		Status:  configv1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: "spec.sessionLifetimes are valid",
+	}}
+}
+
+// validateSigningKeyRotation checks the bounds of the spec.signingKeyRotation of the FederationDomain. It returns the
+// rotation policy, which never rotates the keys when the FederationDomain does not configure an interval or when
+// its configuration is invalid, and the conditions which describe the configuration. There are no conditions when
+// the FederationDomain does not configure any signing key rotation.
+func validateSigningKeyRotation(federationDomain *configv1alpha1.FederationDomain) (signingKeyRotation, []*configv1alpha1.Condition) {
+	rotation := federationDomain.Spec.SigningKeyRotation
+	if rotation.IntervalSeconds == nil && rotation.OverlapSeconds == nil {
+		return signingKeyRotation{}, nil
+	}
+
+	var m []string
+	if rotation.IntervalSeconds == nil {
+		m = append(m, "spec.signingKeyRotation.overlapSeconds must not be configured without spec.signingKeyRotation.intervalSeconds")
+	}
+	if s := rotation.IntervalSeconds; s != nil && (*s < minSigningKeyRotationIntervalSeconds || *s > maxSigningKeyRotationIntervalSeconds) {
+		m = append(m, fmt.Sprintf("spec.signingKeyRotation.intervalSeconds must be between %d and %d seconds, but was %d",
+			minSigningKeyRotationIntervalSeconds, maxSigningKeyRotationIntervalSeconds, *s))
+	}
+	if s := rotation.OverlapSeconds; s != nil && (*s < minSigningKeyRotationOverlapSeconds || *s > maxSigningKeyRotationOverlapSeconds) {
+		m = append(m, fmt.Sprintf("spec.signingKeyRotation.overlapSeconds must be between %d and %d seconds, but was %d",
+			minSigningKeyRotationOverlapSeconds, maxSigningKeyRotationOverlapSeconds, *s))
+	}
+
+	policy := signingKeyRotation{interval: secondsToDuration(rotation.IntervalSeconds), overlap: defaultSigningKeyRotationOverlap}
+	if rotation.OverlapSeconds != nil {
+		policy.overlap = secondsToDuration(rotation.OverlapSeconds)
+	}
+	if rotation.IntervalSeconds != nil && policy.overlap >= policy.interval {
+		m = append(m, "spec.signingKeyRotation.overlapSeconds must be less than spec.signingKeyRotation.intervalSeconds")
+	}
+
+	if len(m) > 0 {
+		return signingKeyRotation{}, []*configv1alpha1.Condition{{
+			Type:    typeSigningKeyRotationValid,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonInvalidSigningKeyRotation,
+			Message: strings.Join(m, "; "),
+		}}
+	}
+
+	return policy, []*configv1alpha1.Condition{{
+		Type:    typeSigningKeyRotationValid,
+		Status:  configv1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: "spec.signingKeyRotation is valid",
+	}}
+}
+
+// validateCORS checks the spec.cors of the FederationDomain. It returns the CORS policy, which does not allow any
+// cross-origin requests when the FederationDomain does not configure any CORS or when its configuration is invalid,
+// and the conditions which describe the configuration. There are no conditions when the FederationDomain does not
+// configure any CORS.
+func validateCORS(federationDomain *configv1alpha1.FederationDomain) (provider.CORSPolicy, []*configv1alpha1.Condition) {
+	cors := federationDomain.Spec.CORS
+	if len(cors.AllowedOrigins) == 0 && len(cors.AllowedMethods) == 0 && cors.MaxAgeSeconds == nil {
+		return provider.CORSPolicy{}, nil
+	}
+
+	var m []string
+	if len(cors.AllowedOrigins) == 0 {
+		m = append(m, "spec.cors.allowedOrigins must not be empty when spec.cors is configured")
+	}
+	for _, origin := range cors.AllowedOrigins {
+		if !isValidCORSOrigin(origin) {
+			m = append(m, fmt.Sprintf("spec.cors.allowedOrigins must only contain lowercase origins with an http or https scheme and without a path, but contained %q", origin))
+		}
+	}
+	for _, method := range cors.AllowedMethods {
+		if method != http.MethodGet && method != http.MethodPost {
+			m = append(m, fmt.Sprintf("spec.cors.allowedMethods must only contain GET or POST, but contained %q", method))
+		}
+	}
+	if s := cors.MaxAgeSeconds; s != nil && (*s < 0 || *s > maxCORSMaxAgeSeconds) {
+		m = append(m, fmt.Sprintf("spec.cors.maxAgeSeconds must be between 0 and %d seconds, but was %d", maxCORSMaxAgeSeconds, *s))
+	}
+
+	if len(m) > 0 {
+		return provider.CORSPolicy{}, []*configv1alpha1.Condition{{
+			Type:    typeCORSValid,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonInvalidCORS,
+			Message: strings.Join(m, "; "),
+		}}
+	}
+
+	policy := provider.CORSPolicy{
+		AllowedOrigins: cors.AllowedOrigins,
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		MaxAge:         secondsToDuration(cors.MaxAgeSeconds),
+	}
+	if len(cors.AllowedMethods) > 0 {
+		policy.AllowedMethods = make([]string, len(cors.AllowedMethods))
+		for i, method := range cors.AllowedMethods {
+			policy.AllowedMethods[i] = string(method)
+		}
+	}
+
+	return policy, []*configv1alpha1.Condition{{
+		Type:    typeCORSValid,
+		Status:  configv1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: "spec.cors is valid",
+	}}
+}
+
+// isValidCORSOrigin returns whether the origin is serialized the way that browsers send it in Origin headers, so that
+// it can be compared exactly to those headers.
+func isValidCORSOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Scheme+"://"+u.Host == origin &&
+		strings.ToLower(origin) == origin
+}
+
+// validateClaimMappings checks the spec.claimMappings of the FederationDomain. It returns the configured mappings and
+// the conditions which describe them. There are no conditions when the FederationDomain does not configure any
+// mappings.
+func validateClaimMappings(federationDomain *configv1alpha1.FederationDomain) (claimmappings.Mappings, []*configv1alpha1.Condition) {
+	if len(federationDomain.Spec.ClaimMappings) == 0 {
+		return nil, nil
+	}
+
+	mappings := make(claimmappings.Mappings, len(federationDomain.Spec.ClaimMappings))
+	for i, mapping := range federationDomain.Spec.ClaimMappings {
+		mappings[i] = claimmappings.Mapping{Claim: mapping.Claim, Source: mapping.Source}
+	}
+
+	if m := claimmappings.Validate("spec.claimMappings", mappings); len(m) > 0 {
+		return nil, []*configv1alpha1.Condition{{
+			Type:    typeClaimMappingsValid,
+			Status:  configv1alpha1.ConditionFalse,
+			Reason:  reasonInvalidClaimMappings,
+			Message: strings.Join(m, "; "),
+		}}
+	}
+
+	return mappings, []*configv1alpha1.Condition{{
+		Type:    typeClaimMappingsValid,
+		Status:  configv1alpha1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: fmt.Sprintf("the %d spec.claimMappings are valid", len(mappings)),
+	}}
+}
+
+func tokenExchangeAudiencesToStrings(audiences []configv1alpha1.TokenExchangeAudience) []string {
+	if len(audiences) == 0 {
+		return nil
+	}
+	s := make([]string, len(audiences))
+	for i, audience := range audiences {
+		s[i] = string(audience)
+	}
+	return s
+}
+
+func secondsToDuration(seconds *int32) time.Duration {
+	if seconds == nil {
+		return 0
+	}
+	return time.Duration(*seconds) * time.Second
+}
+
+func hadErrorCondition(conditions []*configv1alpha1.Condition) bool {
+	for _, condition := range conditions {
+		if condition.Status == configv1alpha1.ConditionFalse {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *federationDomainWatcherController) updateStatus(
 	ctx context.Context,
 	namespace, name string,
 	status configv1alpha1.FederationDomainStatusCondition,
 	message string,
+	conditions []*configv1alpha1.Condition,
 ) error {
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		federationDomain, err := c.client.ConfigV1alpha1().FederationDomains(namespace).Get(ctx, name, metav1.GetOptions{})
@@ -188,7 +692,10 @@ func (c *federationDomainWatcherController) updateStatus(
 			return fmt.Errorf("get failed: %w", err)
 		}
 
-		if federationDomain.Status.Status == status && federationDomain.Status.Message == message {
+		updatedConditions := c.mergeConditions(federationDomain.Status.Conditions, conditions, federationDomain.Generation)
+
+		if federationDomain.Status.Status == status && federationDomain.Status.Message == message &&
+			equality.Semantic.DeepEqual(federationDomain.Status.Conditions, updatedConditions) {
 			return nil
 		}
 
@@ -204,9 +711,35 @@ func (c *federationDomainWatcherController) updateStatus(
 		federationDomain.Status.Status = status
 		federationDomain.Status.Message = message
 		federationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(c.clock.Now()))
+		federationDomain.Status.Conditions = updatedConditions
 		_, err = c.client.ConfigV1alpha1().FederationDomains(namespace).UpdateStatus(ctx, federationDomain, metav1.UpdateOptions{})
 		return err
 	})
 }
 
+// mergeConditions returns the new conditions of a FederationDomain. The existing conditions are replaced by the new
+// conditions, except that the LastTransitionTime of a condition is preserved when its status has not changed.
+func (c *federationDomainWatcherController) mergeConditions(
+	existing []configv1alpha1.Condition,
+	conditions []*configv1alpha1.Condition,
+	observedGeneration int64,
+) []configv1alpha1.Condition {
+	if len(conditions) == 0 {
+		return nil
+	}
+	merged := make([]configv1alpha1.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		updated := *condition
+		updated.ObservedGeneration = observedGeneration
+		updated.LastTransitionTime = metav1.NewTime(c.clock.Now())
+		for _, old := range existing {
+			if old.Type == updated.Type && old.Status == updated.Status {
+				updated.LastTransitionTime = old.LastTransitionTime
+			}
+		}
+		merged = append(merged, updated)
+	}
+	return merged
+}
+
 func timePtr(t metav1.Time) *metav1.Time { return &t }
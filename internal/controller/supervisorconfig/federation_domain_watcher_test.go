@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -16,40 +16,65 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	coretesting "k8s.io/client-go/testing"
 	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/claimmappings"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/testutil"
 )
 
+// sameOIDCIdentityProviderInformer returns the same OIDCIdentityProviderInformer every time, unlike the
+// informer factory, so that the filter which the controller added for that informer can be looked up.
+type sameOIDCIdentityProviderInformer struct {
+	idpinformers.Interface
+	oidcIdentityProviderInformer idpinformers.OIDCIdentityProviderInformer
+}
+
+func (i *sameOIDCIdentityProviderInformer) OIDCIdentityProviders() idpinformers.OIDCIdentityProviderInformer {
+	return i.oidcIdentityProviderInformer
+}
+
 func TestInformerFilters(t *testing.T) {
 	spec.Run(t, "informer filters", func(t *testing.T, when spec.G, it spec.S) {
 		var r *require.Assertions
 		var observableWithInformerOption *testutil.ObservableWithInformerOption
 		var configMapInformerFilter controllerlib.Filter
+		var oidcIdentityProviderInformerFilter controllerlib.Filter
 
 		it.Before(func() {
 			r = require.New(t)
 			observableWithInformerOption = testutil.NewObservableWithInformerOption()
-			federationDomainInformer := pinnipedinformers.NewSharedInformerFactoryWithOptions(nil, 0).Config().V1alpha1().FederationDomains()
+			informerFactory := pinnipedinformers.NewSharedInformerFactoryWithOptions(nil, 0)
+			federationDomainInformer := informerFactory.Config().V1alpha1().FederationDomains()
+			oidcIdentityProviderInformer := informerFactory.IDP().V1alpha1().OIDCIdentityProviders()
 			_ = NewFederationDomainWatcherController(
 				nil,
+				"",
 				nil,
 				nil,
 				federationDomainInformer,
+				&sameOIDCIdentityProviderInformer{
+					Interface:                    informerFactory.IDP().V1alpha1(),
+					oidcIdentityProviderInformer: oidcIdentityProviderInformer,
+				},
 				observableWithInformerOption.WithInformer, // make it possible to observe the behavior of the Filters
 			)
 			configMapInformerFilter = observableWithInformerOption.GetFilterForInformer(federationDomainInformer)
+			oidcIdentityProviderInformerFilter = observableWithInformerOption.GetFilterForInformer(oidcIdentityProviderInformer)
 		})
 
 		when("watching FederationDomain objects", func() {
@@ -80,6 +105,25 @@ func TestInformerFilters(t *testing.T) {
 				})
 			})
 		})
+
+		when("watching OIDCIdentityProvider objects", func() {
+			var subject controllerlib.Filter
+			var target, otherName *idpv1alpha1.OIDCIdentityProvider
+
+			it.Before(func() {
+				subject = oidcIdentityProviderInformerFilter
+				target = &idpv1alpha1.OIDCIdentityProvider{ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"}}
+				otherName = &idpv1alpha1.OIDCIdentityProvider{ObjectMeta: metav1.ObjectMeta{Name: "other-name", Namespace: "some-namespace"}}
+			})
+
+			when("any OIDCIdentityProvider changes", func() {
+				it("returns true to trigger the sync method", func() {
+					r.True(subject.Add(target))
+					r.True(subject.Update(target, otherName))
+					r.True(subject.Delete(target))
+				})
+			})
+		})
 	}, spec.Parallel(), spec.Report(report.Terminal{}))
 }
 
@@ -116,9 +160,11 @@ func TestSync(t *testing.T) {
 			// Set this at the last second to allow for injection of server override.
 			subject = NewFederationDomainWatcherController(
 				providersSetter,
+				"pinniped.dev",
 				clocktesting.NewFakeClock(frozenNow),
 				pinnipedAPIClient,
 				federationDomainInformers.Config().V1alpha1().FederationDomains(),
+				federationDomainInformers.IDP().V1alpha1(),
 				controllerlib.WithInformer,
 			)
 
@@ -673,6 +719,680 @@ func TestSync(t *testing.T) {
 			})
 		})
 
+		when("there are FederationDomains with transforms in the informer", func() {
+			var (
+				validFederationDomain   *v1alpha1.FederationDomain
+				invalidFederationDomain *v1alpha1.FederationDomain
+			)
+
+			it.Before(func() {
+				validFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "valid-transforms", Namespace: namespace, Generation: 42},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://valid-transforms.com",
+						Transforms: v1alpha1.FederationDomainTransforms{
+							Expressions: []v1alpha1.FederationDomainTransformsExpression{
+								{Type: "username/v1", Expression: `"pre:" + username`},
+								{Type: "policy/v1", Expression: `!("contractors" in groups)`, Message: "no contractors"},
+							},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(validFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(validFederationDomain))
+
+				invalidFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "invalid-transforms", Namespace: namespace, Generation: 7},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://invalid-transforms.com",
+						Transforms: v1alpha1.FederationDomainTransforms{
+							Expressions: []v1alpha1.FederationDomainTransformsExpression{
+								{Type: "username/v1", Expression: `groups`},
+							},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(invalidFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(invalidFederationDomain))
+			})
+
+			it("calls the ProvidersSetter with only the valid provider and its transforms", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(validFederationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.True(providersSetter.FederationDomainsReceived[0].IdentityTransforms().HasTransforms())
+			})
+
+			it("updates the status and conditions of the FederationDomains", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				validFederationDomain.Status.Status = v1alpha1.SuccessFederationDomainStatusCondition
+				validFederationDomain.Status.Message = "Provider successfully created"
+				validFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				validFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "TransformsExpressionsValid",
+					Status:             v1alpha1.ConditionTrue,
+					ObservedGeneration: 42,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "Success",
+					Message:            "the 2 spec.transforms.expressions are valid",
+				}}
+
+				invalidFederationDomain.Status.Status = v1alpha1.InvalidFederationDomainStatusCondition
+				invalidFederationDomain.Status.Message = "Invalid: see status.conditions for details"
+				invalidFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				invalidFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "TransformsExpressionsValid",
+					Status:             v1alpha1.ConditionFalse,
+					ObservedGeneration: 7,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "InvalidTransformsExpressions",
+					Message: "spec.transforms: expressions[0]: username/v1 expression must evaluate to type string, " +
+						"but evaluates to type list(string)",
+				}}
+
+				expectedActions := []coretesting.Action{
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain,
+					),
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						validFederationDomain.Namespace,
+						validFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						validFederationDomain.Namespace,
+						validFederationDomain,
+					),
+				}
+				r.ElementsMatch(expectedActions, pinnipedAPIClient.Actions())
+			})
+
+			when("the conditions are already up to date", func() {
+				it.Before(func() {
+					earlier := metav1.NewTime(frozenNow.Add(-time.Hour))
+					validFederationDomain.Status.Status = v1alpha1.SuccessFederationDomainStatusCondition
+					validFederationDomain.Status.Message = "Provider successfully created"
+					validFederationDomain.Status.LastUpdateTime = &earlier
+					validFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+						Type:               "TransformsExpressionsValid",
+						Status:             v1alpha1.ConditionTrue,
+						ObservedGeneration: 42,
+						LastTransitionTime: earlier,
+						Reason:             "Success",
+						Message:            "the 2 spec.transforms.expressions are valid",
+					}}
+					r.NoError(pinnipedAPIClient.Tracker().Update(federationDomainGVR, validFederationDomain, validFederationDomain.Namespace))
+				})
+
+				it("does not update the FederationDomain", func() {
+					startInformersAndController()
+					err := controllerlib.TestSync(t, subject, *syncContext)
+					r.NoError(err)
+
+					for _, action := range pinnipedAPIClient.Actions() {
+						if update, ok := action.(coretesting.UpdateAction); ok {
+							r.NotEqual(validFederationDomain.Name, update.GetObject().(*v1alpha1.FederationDomain).Name)
+						}
+					}
+				})
+			})
+		})
+
+		when("there are FederationDomains with token lifetimes in the informer", func() {
+			var (
+				validFederationDomain   *v1alpha1.FederationDomain
+				invalidFederationDomain *v1alpha1.FederationDomain
+			)
+
+			it.Before(func() {
+				validFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "valid-lifetimes", Namespace: namespace, Generation: 42},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://valid-lifetimes.com",
+						TokenLifetimes: v1alpha1.TokenLifetimes{
+							IDTokenSeconds:      pointer.Int32(300),
+							RefreshTokenSeconds: pointer.Int32(3600),
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(validFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(validFederationDomain))
+
+				invalidFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "invalid-lifetimes", Namespace: namespace, Generation: 7},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://invalid-lifetimes.com",
+						TokenLifetimes: v1alpha1.TokenLifetimes{
+							IDTokenSeconds:      pointer.Int32(60),
+							AccessTokenSeconds:  pointer.Int32(1800),
+							RefreshTokenSeconds: pointer.Int32(900),
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(invalidFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(invalidFederationDomain))
+			})
+
+			it("calls the ProvidersSetter with only the valid provider and its token lifetimes", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(validFederationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.Equal(provider.TokenLifetimes{
+					IDToken:      5 * time.Minute,
+					RefreshToken: time.Hour,
+				}, providersSetter.FederationDomainsReceived[0].TokenLifetimes())
+			})
+
+			it("updates the status and conditions of the FederationDomains", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				validFederationDomain.Status.Status = v1alpha1.SuccessFederationDomainStatusCondition
+				validFederationDomain.Status.Message = "Provider successfully created"
+				validFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				validFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "TokenLifetimesValid",
+					Status:             v1alpha1.ConditionTrue,
+					ObservedGeneration: 42,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "Success",
+					Message:            "spec.tokenLifetimes are valid",
+				}}
+
+				invalidFederationDomain.Status.Status = v1alpha1.InvalidFederationDomainStatusCondition
+				invalidFederationDomain.Status.Message = "Invalid: see status.conditions for details"
+				invalidFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				invalidFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "TokenLifetimesValid",
+					Status:             v1alpha1.ConditionFalse,
+					ObservedGeneration: 7,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "InvalidTokenLifetimes",
+					Message: `"spec.tokenLifetimes.idTokenSeconds" must be between 120 and 3600 seconds, but was 60; ` +
+						`"spec.tokenLifetimes.refreshTokenSeconds" must not be less than "spec.tokenLifetimes.accessTokenSeconds"`,
+				}}
+
+				expectedActions := []coretesting.Action{
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain,
+					),
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						validFederationDomain.Namespace,
+						validFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						validFederationDomain.Namespace,
+						validFederationDomain,
+					),
+				}
+				r.ElementsMatch(expectedActions, pinnipedAPIClient.Actions())
+			})
+		})
+
+		when("there are FederationDomains with session lifetimes in the informer", func() {
+			var (
+				validFederationDomain   *v1alpha1.FederationDomain
+				invalidFederationDomain *v1alpha1.FederationDomain
+			)
+
+			it.Before(func() {
+				validFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "valid-sessions", Namespace: namespace, Generation: 42},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://valid-sessions.com",
+						SessionLifetimes: v1alpha1.SessionLifetimes{
+							IdleTimeoutSeconds: pointer.Int32(1800),
+							MaxLifetimeSeconds: pointer.Int32(43200),
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(validFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(validFederationDomain))
+
+				invalidFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "invalid-sessions", Namespace: namespace, Generation: 7},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://invalid-sessions.com",
+						SessionLifetimes: v1alpha1.SessionLifetimes{
+							IdleTimeoutSeconds: pointer.Int32(32401),
+							MaxLifetimeSeconds: pointer.Int32(900),
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(invalidFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(invalidFederationDomain))
+			})
+
+			it("calls the ProvidersSetter with only the valid provider and its session lifetimes", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(validFederationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.Equal(provider.SessionLifetimes{
+					IdleTimeout: 30 * time.Minute,
+					MaxLifetime: 12 * time.Hour,
+				}, providersSetter.FederationDomainsReceived[0].SessionLifetimes())
+			})
+
+			it("updates the status and conditions of the FederationDomains", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				validFederationDomain.Status.Status = v1alpha1.SuccessFederationDomainStatusCondition
+				validFederationDomain.Status.Message = "Provider successfully created"
+				validFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				validFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "SessionLifetimesValid",
+					Status:             v1alpha1.ConditionTrue,
+					ObservedGeneration: 42,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "Success",
+					Message:            "spec.sessionLifetimes are valid",
+				}}
+
+				invalidFederationDomain.Status.Status = v1alpha1.InvalidFederationDomainStatusCondition
+				invalidFederationDomain.Status.Message = "Invalid: see status.conditions for details"
+				invalidFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				invalidFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "SessionLifetimesValid",
+					Status:             v1alpha1.ConditionFalse,
+					ObservedGeneration: 7,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "InvalidSessionLifetimes",
+					Message: "spec.sessionLifetimes.idleTimeoutSeconds must be between 300 and 32400 seconds, but was 32401; " +
+						"spec.sessionLifetimes.maxLifetimeSeconds must not be less than spec.sessionLifetimes.idleTimeoutSeconds",
+				}}
+
+				expectedActions := []coretesting.Action{
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain,
+					),
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						validFederationDomain.Namespace,
+						validFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						validFederationDomain.Namespace,
+						validFederationDomain,
+					),
+				}
+				r.ElementsMatch(expectedActions, pinnipedAPIClient.Actions())
+			})
+		})
+
+		when("there are FederationDomains with claim mappings in the informer", func() {
+			var (
+				validFederationDomain   *v1alpha1.FederationDomain
+				invalidFederationDomain *v1alpha1.FederationDomain
+			)
+
+			it.Before(func() {
+				validFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "valid-mappings", Namespace: namespace, Generation: 42},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://valid-mappings.com",
+						ClaimMappings: []v1alpha1.FederationDomainClaimMapping{
+							{Claim: "email", Source: "additionalClaims.email"},
+							{Claim: "idp", Source: "upstreamProviderName"},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(validFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(validFederationDomain))
+
+				invalidFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "invalid-mappings", Namespace: namespace, Generation: 7},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://invalid-mappings.com",
+						ClaimMappings: []v1alpha1.FederationDomainClaimMapping{
+							{Claim: "sub", Source: "upstreamSubject"},
+							{Claim: "email", Source: "additionalClaims.email"},
+							{Claim: "email", Source: "not-a-source"},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(invalidFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(invalidFederationDomain))
+			})
+
+			it("calls the ProvidersSetter with only the valid provider and its claim mappings", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(validFederationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.Equal(claimmappings.Mappings{
+					{Claim: "email", Source: "additionalClaims.email"},
+					{Claim: "idp", Source: "upstreamProviderName"},
+				}, providersSetter.FederationDomainsReceived[0].ClaimMappings())
+			})
+
+			it("updates the status and conditions of the FederationDomains", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				validFederationDomain.Status.Status = v1alpha1.SuccessFederationDomainStatusCondition
+				validFederationDomain.Status.Message = "Provider successfully created"
+				validFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				validFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "ClaimMappingsValid",
+					Status:             v1alpha1.ConditionTrue,
+					ObservedGeneration: 42,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "Success",
+					Message:            "the 2 spec.claimMappings are valid",
+				}}
+
+				invalidFederationDomain.Status.Status = v1alpha1.InvalidFederationDomainStatusCondition
+				invalidFederationDomain.Status.Message = "Invalid: see status.conditions for details"
+				invalidFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				invalidFederationDomain.Status.Conditions = []v1alpha1.Condition{{
+					Type:               "ClaimMappingsValid",
+					Status:             v1alpha1.ConditionFalse,
+					ObservedGeneration: 7,
+					LastTransitionTime: metav1.NewTime(frozenNow),
+					Reason:             "InvalidClaimMappings",
+					Message: `spec.claimMappings[0].claim "sub" is reserved for use by the Supervisor; ` +
+						`spec.claimMappings[2].claim "email" is mapped more than once; ` +
+						`spec.claimMappings[2].source "not-a-source" is not supported`,
+				}}
+
+				expectedActions := []coretesting.Action{
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						invalidFederationDomain.Namespace,
+						invalidFederationDomain,
+					),
+					coretesting.NewGetAction(
+						federationDomainGVR,
+						validFederationDomain.Namespace,
+						validFederationDomain.Name,
+					),
+					coretesting.NewUpdateSubresourceAction(
+						federationDomainGVR,
+						"status",
+						validFederationDomain.Namespace,
+						validFederationDomain,
+					),
+				}
+				r.ElementsMatch(expectedActions, pinnipedAPIClient.Actions())
+			})
+		})
+
+		when("there is a FederationDomain with allowed token exchange audiences in the informer", func() {
+			var federationDomain *v1alpha1.FederationDomain
+
+			it.Before(func() {
+				federationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "audiences", Namespace: namespace, Generation: 42},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:                        "https://audiences.com",
+						AllowedTokenExchangeAudiences: []v1alpha1.TokenExchangeAudience{"cluster-1", "cluster-2"},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+			})
+
+			it("calls the ProvidersSetter with the provider and its allowed token exchange audiences", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(federationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.Equal([]string{"cluster-1", "cluster-2"}, providersSetter.FederationDomainsReceived[0].TokenExchangeAudiences())
+			})
+		})
+
+		when("there is a FederationDomain with an ID token signing algorithm in the informer", func() {
+			var federationDomain *v1alpha1.FederationDomain
+
+			it.Before(func() {
+				federationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "signing-algorithm", Namespace: namespace, Generation: 42},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:                  "https://signing-algorithm.com",
+						IDTokenSigningAlgorithm: "EdDSA",
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+			})
+
+			it("calls the ProvidersSetter with the provider and its ID token signing algorithm", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(federationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.Equal("EdDSA", providersSetter.FederationDomainsReceived[0].IDTokenSigningAlgorithm())
+			})
+		})
+
+		when("there are FederationDomains with identity providers in the informer", func() {
+			var (
+				validFederationDomain          *v1alpha1.FederationDomain
+				danglingRefsFederationDomain   *v1alpha1.FederationDomain
+				duplicateNamesFederationDomain *v1alpha1.FederationDomain
+			)
+
+			idpRef := func(kind, name string) corev1.TypedLocalObjectReference {
+				return corev1.TypedLocalObjectReference{APIGroup: pointer.String("idp.supervisor.pinniped.dev"), Kind: kind, Name: name}
+			}
+
+			it.Before(func() {
+				r.NoError(federationDomainInformerClient.Tracker().Add(&idpv1alpha1.OIDCIdentityProvider{
+					ObjectMeta: metav1.ObjectMeta{Name: "some-oidc-idp", Namespace: namespace, UID: "oidc-uid"},
+				}))
+				r.NoError(federationDomainInformerClient.Tracker().Add(&idpv1alpha1.LDAPIdentityProvider{
+					ObjectMeta: metav1.ObjectMeta{Name: "some-ldap-idp", Namespace: namespace, UID: "ldap-uid"},
+				}))
+
+				validFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "valid-idps", Namespace: namespace, Generation: 3},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://valid-idps.com",
+						IdentityProviders: []v1alpha1.FederationDomainIdentityProvider{
+							{DisplayName: "My OIDC", ObjectRef: idpRef("OIDCIdentityProvider", "some-oidc-idp")},
+							{DisplayName: "My LDAP", ObjectRef: idpRef("LDAPIdentityProvider", "some-ldap-idp")},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(validFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(validFederationDomain))
+
+				danglingRefsFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "dangling-refs", Namespace: namespace, Generation: 4},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://dangling-refs.com",
+						IdentityProviders: []v1alpha1.FederationDomainIdentityProvider{
+							{DisplayName: "My OIDC", ObjectRef: idpRef("OIDCIdentityProvider", "some-oidc-idp")},
+							{DisplayName: "Missing", ObjectRef: idpRef("LDAPIdentityProvider", "does-not-exist")},
+							{DisplayName: "Wrong kind", ObjectRef: idpRef("Secret", "some-oidc-idp")},
+							{DisplayName: "Wrong group", ObjectRef: corev1.TypedLocalObjectReference{
+								APIGroup: pointer.String("wrong.example.com"), Kind: "OIDCIdentityProvider", Name: "some-oidc-idp",
+							}},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(danglingRefsFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(danglingRefsFederationDomain))
+
+				duplicateNamesFederationDomain = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "duplicate-names", Namespace: namespace, Generation: 5},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://duplicate-names.com",
+						IdentityProviders: []v1alpha1.FederationDomainIdentityProvider{
+							{DisplayName: "Same", ObjectRef: idpRef("OIDCIdentityProvider", "some-oidc-idp")},
+							{DisplayName: "Same", ObjectRef: idpRef("LDAPIdentityProvider", "some-ldap-idp")},
+						},
+					},
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(duplicateNamesFederationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(duplicateNamesFederationDomain))
+			})
+
+			it("calls the ProvidersSetter with only the valid provider and its resolved identity providers", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Len(providersSetter.FederationDomainsReceived, 1)
+				r.Equal(validFederationDomain.Spec.Issuer, providersSetter.FederationDomainsReceived[0].Issuer())
+				r.Equal([]provider.FederationDomainIdentityProvider{
+					{DisplayName: "My OIDC", UID: "oidc-uid"},
+					{DisplayName: "My LDAP", UID: "ldap-uid"},
+				}, providersSetter.FederationDomainsReceived[0].IdentityProviders())
+			})
+
+			it("updates the status and conditions of the FederationDomains", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				validFederationDomain.Status.Status = v1alpha1.SuccessFederationDomainStatusCondition
+				validFederationDomain.Status.Message = "Provider successfully created"
+				validFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				validFederationDomain.Status.Conditions = []v1alpha1.Condition{
+					{
+						Type:               "IdentityProvidersFound",
+						Status:             v1alpha1.ConditionTrue,
+						ObservedGeneration: 3,
+						LastTransitionTime: metav1.NewTime(frozenNow),
+						Reason:             "Success",
+						Message:            "the resources specified by .spec.identityProviders[].objectRef were found",
+					},
+					{
+						Type:               "IdentityProvidersDisplayNamesUnique",
+						Status:             v1alpha1.ConditionTrue,
+						ObservedGeneration: 3,
+						LastTransitionTime: metav1.NewTime(frozenNow),
+						Reason:             "Success",
+						Message:            "the names specified by .spec.identityProviders[].displayName are unique",
+					},
+				}
+
+				danglingRefsFederationDomain.Status.Status = v1alpha1.InvalidFederationDomainStatusCondition
+				danglingRefsFederationDomain.Status.Message = "Invalid: see status.conditions for details"
+				danglingRefsFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				danglingRefsFederationDomain.Status.Conditions = []v1alpha1.Condition{
+					{
+						Type:               "IdentityProvidersFound",
+						Status:             v1alpha1.ConditionFalse,
+						ObservedGeneration: 4,
+						LastTransitionTime: metav1.NewTime(frozenNow),
+						Reason:             "IdentityProvidersObjectRefsNotFound",
+						Message: here.Doc(`
+							.spec.identityProviders[1] with displayName "Missing": cannot find LDAPIdentityProvider "does-not-exist"
+							.spec.identityProviders[2] with displayName "Wrong kind": objectRef kind "Secret" is not supported
+							.spec.identityProviders[3] with displayName "Wrong group": objectRef must have apiGroup "idp.supervisor.pinniped.dev"`,
+						),
+					},
+					{
+						Type:               "IdentityProvidersDisplayNamesUnique",
+						Status:             v1alpha1.ConditionTrue,
+						ObservedGeneration: 4,
+						LastTransitionTime: metav1.NewTime(frozenNow),
+						Reason:             "Success",
+						Message:            "the names specified by .spec.identityProviders[].displayName are unique",
+					},
+				}
+
+				duplicateNamesFederationDomain.Status.Status = v1alpha1.InvalidFederationDomainStatusCondition
+				duplicateNamesFederationDomain.Status.Message = "Invalid: see status.conditions for details"
+				duplicateNamesFederationDomain.Status.LastUpdateTime = timePtr(metav1.NewTime(frozenNow))
+				duplicateNamesFederationDomain.Status.Conditions = []v1alpha1.Condition{
+					{
+						Type:               "IdentityProvidersFound",
+						Status:             v1alpha1.ConditionTrue,
+						ObservedGeneration: 5,
+						LastTransitionTime: metav1.NewTime(frozenNow),
+						Reason:             "Success",
+						Message:            "the resources specified by .spec.identityProviders[].objectRef were found",
+					},
+					{
+						Type:               "IdentityProvidersDisplayNamesUnique",
+						Status:             v1alpha1.ConditionFalse,
+						ObservedGeneration: 5,
+						LastTransitionTime: metav1.NewTime(frozenNow),
+						Reason:             "DuplicateDisplayNames",
+						Message:            "the names specified by .spec.identityProviders[].displayName contain duplicates: Same",
+					},
+				}
+
+				expectedActions := []coretesting.Action{}
+				for _, fd := range []*v1alpha1.FederationDomain{validFederationDomain, danglingRefsFederationDomain, duplicateNamesFederationDomain} {
+					expectedActions = append(expectedActions,
+						coretesting.NewGetAction(federationDomainGVR, fd.Namespace, fd.Name),
+						coretesting.NewUpdateSubresourceAction(federationDomainGVR, "status", fd.Namespace, fd),
+					)
+				}
+				r.ElementsMatch(expectedActions, pinnipedAPIClient.Actions())
+			})
+		})
+
 		when("there are FederationDomains with duplicate issuer names in the informer", func() {
 			var (
 				federationDomainDuplicate1 *v1alpha1.FederationDomain
@@ -1026,3 +1746,168 @@ func TestSync(t *testing.T) {
 		})
 	}, spec.Parallel(), spec.Report(report.Terminal{}))
 }
+
+func TestValidateSigningKeyRotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		rotation       v1alpha1.SigningKeyRotation
+		wantRotation   signingKeyRotation
+		wantConditions []*v1alpha1.Condition
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:         "interval with the default overlap",
+			rotation:     v1alpha1.SigningKeyRotation{IntervalSeconds: pointer.Int32(24 * 60 * 60)},
+			wantRotation: signingKeyRotation{interval: 24 * time.Hour, overlap: time.Hour},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "SigningKeyRotationValid",
+				Status:  v1alpha1.ConditionTrue,
+				Reason:  "Success",
+				Message: "spec.signingKeyRotation is valid",
+			}},
+		},
+		{
+			name:         "interval and overlap",
+			rotation:     v1alpha1.SigningKeyRotation{IntervalSeconds: pointer.Int32(7200), OverlapSeconds: pointer.Int32(600)},
+			wantRotation: signingKeyRotation{interval: 2 * time.Hour, overlap: 10 * time.Minute},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "SigningKeyRotationValid",
+				Status:  v1alpha1.ConditionTrue,
+				Reason:  "Success",
+				Message: "spec.signingKeyRotation is valid",
+			}},
+		},
+		{
+			name:     "overlap without interval",
+			rotation: v1alpha1.SigningKeyRotation{OverlapSeconds: pointer.Int32(600)},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "SigningKeyRotationValid",
+				Status:  v1alpha1.ConditionFalse,
+				Reason:  "InvalidSigningKeyRotation",
+				Message: "spec.signingKeyRotation.overlapSeconds must not be configured without spec.signingKeyRotation.intervalSeconds",
+			}},
+		},
+		{
+			name:     "out of bounds",
+			rotation: v1alpha1.SigningKeyRotation{IntervalSeconds: pointer.Int32(3600), OverlapSeconds: pointer.Int32(86401)},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:   "SigningKeyRotationValid",
+				Status: v1alpha1.ConditionFalse,
+				Reason: "InvalidSigningKeyRotation",
+				Message: "spec.signingKeyRotation.intervalSeconds must be between 7200 and 31536000 seconds, but was 3600; " +
+					"spec.signingKeyRotation.overlapSeconds must be between 600 and 86400 seconds, but was 86401; " +
+					"spec.signingKeyRotation.overlapSeconds must be less than spec.signingKeyRotation.intervalSeconds",
+			}},
+		},
+		{
+			name:     "overlap is not less than interval",
+			rotation: v1alpha1.SigningKeyRotation{IntervalSeconds: pointer.Int32(7200), OverlapSeconds: pointer.Int32(7200)},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "SigningKeyRotationValid",
+				Status:  v1alpha1.ConditionFalse,
+				Reason:  "InvalidSigningKeyRotation",
+				Message: "spec.signingKeyRotation.overlapSeconds must be less than spec.signingKeyRotation.intervalSeconds",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			federationDomain := &v1alpha1.FederationDomain{Spec: v1alpha1.FederationDomainSpec{SigningKeyRotation: tt.rotation}}
+			rotation, conditions := validateSigningKeyRotation(federationDomain)
+			require.Equal(t, tt.wantRotation, rotation)
+			require.Equal(t, tt.wantConditions, conditions)
+		})
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name           string
+		cors           v1alpha1.CORSPolicy
+		wantPolicy     provider.CORSPolicy
+		wantConditions []*v1alpha1.Condition
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "origins with the default methods",
+			cors: v1alpha1.CORSPolicy{AllowedOrigins: []string{"https://app.example.com", "http://localhost:3000"}},
+			wantPolicy: provider.CORSPolicy{
+				AllowedOrigins: []string{"https://app.example.com", "http://localhost:3000"},
+				AllowedMethods: []string{"GET", "POST"},
+			},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "CORSValid",
+				Status:  v1alpha1.ConditionTrue,
+				Reason:  "Success",
+				Message: "spec.cors is valid",
+			}},
+		},
+		{
+			name: "origins, methods, and max age",
+			cors: v1alpha1.CORSPolicy{
+				AllowedOrigins: []string{"https://app.example.com"},
+				AllowedMethods: []v1alpha1.CORSMethod{"GET"},
+				MaxAgeSeconds:  pointer.Int32(600),
+			},
+			wantPolicy: provider.CORSPolicy{
+				AllowedOrigins: []string{"https://app.example.com"},
+				AllowedMethods: []string{"GET"},
+				MaxAge:         10 * time.Minute,
+			},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "CORSValid",
+				Status:  v1alpha1.ConditionTrue,
+				Reason:  "Success",
+				Message: "spec.cors is valid",
+			}},
+		},
+		{
+			name: "methods without origins",
+			cors: v1alpha1.CORSPolicy{AllowedMethods: []v1alpha1.CORSMethod{"GET"}},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:    "CORSValid",
+				Status:  v1alpha1.ConditionFalse,
+				Reason:  "InvalidCORS",
+				Message: "spec.cors.allowedOrigins must not be empty when spec.cors is configured",
+			}},
+		},
+		{
+			name: "invalid origins, methods, and max age",
+			cors: v1alpha1.CORSPolicy{
+				AllowedOrigins: []string{"*", "https://app.example.com/", "https://App.example.com", "ftp://app.example.com", "https://user@app.example.com"},
+				AllowedMethods: []v1alpha1.CORSMethod{"PUT"},
+				MaxAgeSeconds:  pointer.Int32(86401),
+			},
+			wantConditions: []*v1alpha1.Condition{{
+				Type:   "CORSValid",
+				Status: v1alpha1.ConditionFalse,
+				Reason: "InvalidCORS",
+				Message: `spec.cors.allowedOrigins must only contain lowercase origins with an http or https scheme and without a path, but contained "*"; ` +
+					`spec.cors.allowedOrigins must only contain lowercase origins with an http or https scheme and without a path, but contained "https://app.example.com/"; ` +
+					`spec.cors.allowedOrigins must only contain lowercase origins with an http or https scheme and without a path, but contained "https://App.example.com"; ` +
+					`spec.cors.allowedOrigins must only contain lowercase origins with an http or https scheme and without a path, but contained "ftp://app.example.com"; ` +
+					`spec.cors.allowedOrigins must only contain lowercase origins with an http or https scheme and without a path, but contained "https://user@app.example.com"; ` +
+					`spec.cors.allowedMethods must only contain GET or POST, but contained "PUT"; ` +
+					`spec.cors.maxAgeSeconds must be between 0 and 86400 seconds, but was 86401`,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			federationDomain := &v1alpha1.FederationDomain{Spec: v1alpha1.FederationDomainSpec{CORS: tt.cors}}
+			policy, conditions := validateCORS(federationDomain)
+			require.Equal(t, tt.wantPolicy, policy)
+			require.Equal(t, tt.wantConditions, conditions)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package githubupstreamwatcher implements a controller which watches GitHubIdentityProviders.
+package githubupstreamwatcher
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/constable"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/upstreamgithub"
+)
+
+const (
+	// Setup for the name of our controller in logs.
+	githubControllerName = "github-upstream-observer"
+
+	// Constants related to the client credentials Secret.
+	githubClientSecretType corev1.SecretType = "secrets.pinniped.dev/github-client"
+
+	clientIDDataKey     = "clientID"
+	clientSecretDataKey = "clientSecret"
+
+	// The host of the public GitHub service, whose API is served from a different host than its web UI.
+	defaultHost    = "github.com"
+	defaultAPIHost = "api.github.com"
+
+	// Constants related to conditions.
+	typeClientCredentialsValid   = "ClientCredentialsValid" //nolint:gosec // this is not a credential
+	typeHostValid                = "HostValid"
+	typeTLSConfigurationValid    = "TLSConfigurationValid"
+	typeOrganizationsPolicyValid = "OrganizationsPolicyValid"
+
+	reasonInvalidHost = "InvalidHost"
+	reasonInvalid     = "Invalid"
+
+	// Errors that are generated by our reconcile process.
+	errGitHubFailureStatus = constable.Error("GitHubIdentityProvider has a failing condition")
+)
+
+// The scopes needed to read the user's record, organization memberships, and team memberships.
+var githubScopes = []string{"read:user", "read:org"} //nolint:gochecknoglobals
+
+// UpstreamGitHubIdentityProviderICache is a thread safe cache that holds a list of validated upstream GitHub IDP configurations.
+type UpstreamGitHubIdentityProviderICache interface {
+	SetGitHubIdentityProviders([]provider.UpstreamOIDCIdentityProviderI)
+}
+
+type githubWatcherController struct {
+	cache                          UpstreamGitHubIdentityProviderICache
+	log                            logr.Logger
+	client                         pinnipedclientset.Interface
+	githubIdentityProviderInformer idpinformers.GitHubIdentityProviderInformer
+	secretInformer                 corev1informers.SecretInformer
+	configMapInformer              corev1informers.ConfigMapInformer
+}
+
+// New instantiates a new controllerlib.Controller which will populate the provided UpstreamGitHubIdentityProviderICache.
+func New(
+	idpCache UpstreamGitHubIdentityProviderICache,
+	client pinnipedclientset.Interface,
+	githubIdentityProviderInformer idpinformers.GitHubIdentityProviderInformer,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	log logr.Logger,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	c := githubWatcherController{
+		cache:                          idpCache,
+		log:                            log.WithName(githubControllerName),
+		client:                         client,
+		githubIdentityProviderInformer: githubIdentityProviderInformer,
+		secretInformer:                 secretInformer,
+		configMapInformer:              configMapInformer,
+	}
+	return controllerlib.New(
+		controllerlib.Config{Name: githubControllerName, Syncer: &c},
+		withInformer(
+			githubIdentityProviderInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{githubClientSecretType, corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *githubWatcherController) Sync(ctx controllerlib.Context) error {
+	actualUpstreams, err := c.githubIdentityProviderInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list GitHubIdentityProviders: %w", err)
+	}
+
+	requeue := false
+	validatedUpstreams := make([]provider.UpstreamOIDCIdentityProviderI, 0, len(actualUpstreams))
+	for _, upstream := range actualUpstreams {
+		valid := c.validateUpstream(ctx, upstream)
+		if valid == nil {
+			requeue = true
+		} else {
+			validatedUpstreams = append(validatedUpstreams, provider.UpstreamOIDCIdentityProviderI(valid))
+		}
+	}
+	c.cache.SetGitHubIdentityProviders(validatedUpstreams)
+	if requeue {
+		return controllerlib.ErrSyntheticRequeue
+	}
+	return nil
+}
+
+// validateUpstream validates the provided v1alpha1.GitHubIdentityProvider and returns the validated configuration as a
+// upstreamgithub.ProviderConfig. As a side effect, it also updates the status of the v1alpha1.GitHubIdentityProvider.
+func (c *githubWatcherController) validateUpstream(ctx controllerlib.Context, upstream *v1alpha1.GitHubIdentityProvider) *upstreamgithub.ProviderConfig {
+	result := upstreamgithub.ProviderConfig{
+		Name:               upstream.Name,
+		ResourceUID:        upstream.UID,
+		Config:             &oauth2.Config{Scopes: githubScopes},
+		UsernameAttribute:  string(upstream.Spec.Claims.Username),
+		GroupNameAttribute: string(upstream.Spec.Claims.Groups),
+	}
+
+	conditions := []*v1alpha1.Condition{
+		c.validateSecret(upstream, &result),
+		validateHost(upstream, &result),
+		c.validateTLS(upstream, &result),
+		validateOrganizationsPolicy(upstream, &result),
+	}
+
+	c.updateStatus(ctx.Context, upstream, conditions)
+
+	valid := true
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	for _, condition := range conditions {
+		if condition.Status == v1alpha1.ConditionFalse {
+			valid = false
+			log.WithValues(
+				"type", condition.Type,
+				"reason", condition.Reason,
+				"message", condition.Message,
+			).Error(errGitHubFailureStatus, "found failing condition")
+		}
+	}
+	if valid {
+		return &result
+	}
+	return nil
+}
+
+// validateSecret validates the .spec.client.secretName field and returns the appropriate ClientCredentialsValid condition.
+func (c *githubWatcherController) validateSecret(upstream *v1alpha1.GitHubIdentityProvider, result *upstreamgithub.ProviderConfig) *v1alpha1.Condition {
+	secretName := upstream.Spec.Client.SecretName
+
+	// Fetch the Secret from informer cache.
+	secret, err := c.secretInformer.Lister().Secrets(upstream.Namespace).Get(secretName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonNotFound,
+			Message: err.Error(),
+		}
+	}
+
+	// Validate the secret .type field.
+	if secret.Type != githubClientSecretType {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonWrongType,
+			Message: fmt.Sprintf("referenced Secret %q has wrong type %q (should be %q)", secretName, secret.Type, githubClientSecretType),
+		}
+	}
+
+	// Validate the secret .data field.
+	clientID := secret.Data[clientIDDataKey]
+	clientSecret := secret.Data[clientSecretDataKey]
+	if len(clientID) == 0 || len(clientSecret) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{clientIDDataKey, clientSecretDataKey}),
+		}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.ClientID = string(clientID)
+	result.Config.ClientSecret = string(clientSecret)
+	return &v1alpha1.Condition{
+		Type:    typeClientCredentialsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "loaded client credentials",
+	}
+}
+
+// validateHost validates the .spec.githubAPI.host field and returns the appropriate HostValid condition.
+// The web flow endpoints are always on the given host, while the API is at "/api/v3" on the given host,
+// except for github.com whose API is on its own host.
+func validateHost(upstream *v1alpha1.GitHubIdentityProvider, result *upstreamgithub.ProviderConfig) *v1alpha1.Condition {
+	host := upstream.Spec.GitHubAPI.Host
+	if host == "" {
+		host = defaultHost
+	}
+
+	parsedURL, err := url.Parse("https://" + host)
+	if err != nil || parsedURL.Host != host || parsedURL.Hostname() == "" || strings.HasSuffix(host, ":") {
+		return &v1alpha1.Condition{
+			Type:    typeHostValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidHost,
+			Message: fmt.Sprintf(`spec.githubAPI.host (%q) must be a hostname with an optional port, without a scheme or path`, host),
+		}
+	}
+
+	apiBaseURL := &url.URL{Scheme: "https", Host: host, Path: "/api/v3"}
+	if strings.EqualFold(host, defaultHost) {
+		apiBaseURL = &url.URL{Scheme: "https", Host: defaultAPIHost}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.Endpoint = oauth2.Endpoint{
+		AuthURL:   (&url.URL{Scheme: "https", Host: host, Path: "/login/oauth/authorize"}).String(),
+		TokenURL:  (&url.URL{Scheme: "https", Host: host, Path: "/login/oauth/access_token"}).String(),
+		AuthStyle: oauth2.AuthStyleInParams,
+	}
+	result.APIBaseURL = apiBaseURL
+	return &v1alpha1.Condition{
+		Type:    typeHostValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: fmt.Sprintf("spec.githubAPI.host (%q) is valid", host),
+	}
+}
+
+// validateTLS validates the .spec.githubAPI.tls field and returns the appropriate TLSConfigurationValid condition.
+func (c *githubWatcherController) validateTLS(upstream *v1alpha1.GitHubIdentityProvider, result *upstreamgithub.ProviderConfig) *v1alpha1.Condition {
+	rootCAs, _, _, err := upstreamwatchers.ReadCABundle(upstream.Spec.GitHubAPI.TLS, upstream.Namespace, c.secretInformer, c.configMapInformer)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeTLSConfigurationValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonInvalidTLSConfig,
+			Message: fmt.Sprintf("spec.githubAPI.tls.%s", err.Error()),
+		}
+	}
+
+	result.Client = defaultClientShortTimeout(rootCAs)
+	return &v1alpha1.Condition{
+		Type:    typeTLSConfigurationValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "spec.githubAPI.tls is valid",
+	}
+}
+
+// validateOrganizationsPolicy validates the .spec.allowAuthentication.organizations field and returns the
+// appropriate OrganizationsPolicyValid condition.
+func validateOrganizationsPolicy(upstream *v1alpha1.GitHubIdentityProvider, result *upstreamgithub.ProviderConfig) *v1alpha1.Condition {
+	organizations := upstream.Spec.AllowAuthentication.Organizations
+	policy := organizations.Policy
+	if policy == "" {
+		policy = v1alpha1.GitHubAllowedAuthOrganizationsPolicyOnlyUsersFromAllowedOrganizations
+	}
+
+	switch {
+	case policy == v1alpha1.GitHubAllowedAuthOrganizationsPolicyOnlyUsersFromAllowedOrganizations && len(organizations.Allowed) == 0:
+		return &v1alpha1.Condition{
+			Type:    typeOrganizationsPolicyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalid,
+			Message: fmt.Sprintf("spec.allowAuthentication.organizations.policy is %q but spec.allowAuthentication.organizations.allowed is empty", policy),
+		}
+	case policy == v1alpha1.GitHubAllowedAuthOrganizationsPolicyAllGitHubUsers && len(organizations.Allowed) > 0:
+		return &v1alpha1.Condition{
+			Type:    typeOrganizationsPolicyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalid,
+			Message: fmt.Sprintf("spec.allowAuthentication.organizations.policy is %q but spec.allowAuthentication.organizations.allowed is not empty", policy),
+		}
+	}
+
+	allowed := sets.New[string]()
+	for _, org := range organizations.Allowed {
+		allowed.Insert(strings.ToLower(org))
+	}
+	result.AllowedOrganizations = allowed
+	return &v1alpha1.Condition{
+		Type:    typeOrganizationsPolicyValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: fmt.Sprintf("spec.allowAuthentication.organizations.policy (%q) is valid", policy),
+	}
+}
+
+func (c *githubWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.GitHubIdentityProvider, conditions []*v1alpha1.Condition) {
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	updated := upstream.DeepCopy()
+
+	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+
+	updated.Status.Phase = v1alpha1.GitHubPhaseReady
+	if hadErrorCondition {
+		updated.Status.Phase = v1alpha1.GitHubPhaseError
+	}
+
+	if equality.Semantic.DeepEqual(upstream, updated) {
+		return
+	}
+
+	_, err := c.client.
+		IDPV1alpha1().
+		GitHubIdentityProviders(upstream.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error(err, "failed to update status")
+	}
+}
+
+func defaultClientShortTimeout(rootCAs *x509.CertPool) *http.Client {
+	c := phttp.Default(rootCAs)
+	c.Timeout = time.Minute
+	return c
+}
@@ -0,0 +1,525 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package githubupstreamwatcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/testlogger"
+	"go.pinniped.dev/internal/upstreamgithub"
+)
+
+func TestGitHubUpstreamWatcherControllerFilterSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		secret     metav1.Object
+		wantAdd    bool
+		wantUpdate bool
+		wantDelete bool
+	}{
+		{
+			name: "a secret of the right type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/github-client",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
+		{
+			name: "a secret of the wrong type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/not-the-github-client-type",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+		{
+			name: "resource of wrong data type",
+			secret: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset()
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset()
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			secretInformer := kubeInformers.Core().V1().Secrets()
+			withInformer := testutil.NewObservableWithInformerOption()
+
+			New(
+				provider.NewDynamicUpstreamIDPProvider(),
+				nil,
+				pinnipedInformers.IDP().V1alpha1().GitHubIdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // test with no log assertions
+				withInformer.WithInformer,
+			)
+
+			unrelated := corev1.Secret{}
+			filter := withInformer.GetFilterForInformer(secretInformer)
+			require.Equal(t, test.wantAdd, filter.Add(test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(&unrelated, test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(test.secret, &unrelated))
+			require.Equal(t, test.wantDelete, filter.Delete(test.secret))
+		})
+	}
+}
+
+func TestGitHubUpstreamWatcherControllerSync(t *testing.T) {
+	t.Parallel()
+	now := metav1.NewTime(time.Now().UTC())
+
+	var (
+		testNamespace       = "test-namespace"
+		testName            = "test-name"
+		testSecretName      = "test-client-secret"
+		testClientID        = "test-github-client-id"
+		testClientSecret    = "test-github-client-secret"
+		testValidSecretData = map[string][]byte{"clientID": []byte(testClientID), "clientSecret": []byte(testClientSecret)}
+		testUID             = types.UID("test-uid")
+		testValidSpec       = v1alpha1.GitHubIdentityProviderSpec{
+			GitHubAPI: v1alpha1.GitHubAPIConfig{Host: "github.example.com"},
+			Claims: v1alpha1.GitHubClaims{
+				Username: v1alpha1.GitHubUsernameLogin,
+				Groups:   v1alpha1.GitHubUseTeamNameForGroupName,
+			},
+			AllowAuthentication: v1alpha1.GitHubAllowAuthenticationSpec{
+				Organizations: v1alpha1.GitHubOrganizationsSpec{
+					Policy:  v1alpha1.GitHubAllowedAuthOrganizationsPolicyOnlyUsersFromAllowedOrganizations,
+					Allowed: []string{"My-Org", "other-org"},
+				},
+			},
+			Client: v1alpha1.GitHubClientSpec{SecretName: testSecretName},
+		}
+		testValidSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+			Type:       "secrets.pinniped.dev/github-client",
+			Data:       testValidSecretData,
+		}
+	)
+
+	happyClientCredentialsValidCondition := v1alpha1.Condition{
+		Type:               "ClientCredentialsValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "loaded client credentials",
+		LastTransitionTime: now,
+	}
+	happyHostValidCondition := v1alpha1.Condition{
+		Type:               "HostValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            `spec.githubAPI.host ("github.example.com") is valid`,
+		LastTransitionTime: now,
+	}
+	happyOrganizationsPolicyValidCondition := v1alpha1.Condition{
+		Type:               "OrganizationsPolicyValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            `spec.allowAuthentication.organizations.policy ("OnlyUsersFromAllowedOrganizations") is valid`,
+		LastTransitionTime: now,
+	}
+	happyTLSConfigurationValidCondition := v1alpha1.Condition{
+		Type:               "TLSConfigurationValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "spec.githubAPI.tls is valid",
+		LastTransitionTime: now,
+	}
+
+	specWith := func(editFunc func(spec *v1alpha1.GitHubIdentityProviderSpec)) v1alpha1.GitHubIdentityProviderSpec {
+		spec := *testValidSpec.DeepCopy()
+		editFunc(&spec)
+		return spec
+	}
+
+	tests := []struct {
+		name                   string
+		inputUpstreams         []runtime.Object
+		inputSecrets           []runtime.Object
+		wantErr                string
+		wantLogs               []string
+		wantResultingCache     []string
+		wantResultingUpstreams []v1alpha1.GitHubIdentityProvider
+	}{
+		{
+			name:               "no upstreams",
+			wantResultingCache: []string{},
+		},
+		{
+			name: "valid upstream",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitHubIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantLogs: []string{
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.host (\"github.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.allowAuthentication.organizations.policy (\"OnlyUsersFromAllowedOrganizations\") is valid" "reason"="Success" "status"="True" "type"="OrganizationsPolicyValid"`,
+			},
+			wantResultingCache: []string{testName},
+			wantResultingUpstreams: []v1alpha1.GitHubIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Status: v1alpha1.GitHubIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						happyHostValidCondition,
+						happyOrganizationsPolicyValidCondition,
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "secret has the wrong type",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitHubIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+				Type:       "secrets.pinniped.dev/oidc-client",
+				Data:       testValidSecretData,
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="referenced Secret \"test-client-secret\" has wrong type \"secrets.pinniped.dev/oidc-client\" (should be \"secrets.pinniped.dev/github-client\")" "reason"="SecretWrongType" "status"="False" "type"="ClientCredentialsValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.host (\"github.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.allowAuthentication.organizations.policy (\"OnlyUsersFromAllowedOrganizations\") is valid" "reason"="Success" "status"="True" "type"="OrganizationsPolicyValid"`,
+				`github-upstream-observer "msg"="found failing condition" "error"="GitHubIdentityProvider has a failing condition" "message"="referenced Secret \"test-client-secret\" has wrong type \"secrets.pinniped.dev/oidc-client\" (should be \"secrets.pinniped.dev/github-client\")" "name"="test-name" "namespace"="test-namespace" "reason"="SecretWrongType" "type"="ClientCredentialsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitHubIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitHubIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "ClientCredentialsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretWrongType",
+							Message:            `referenced Secret "test-client-secret" has wrong type "secrets.pinniped.dev/oidc-client" (should be "secrets.pinniped.dev/github-client")`,
+						},
+						happyHostValidCondition,
+						happyOrganizationsPolicyValidCondition,
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "host includes a scheme",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitHubIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.GitHubIdentityProviderSpec) {
+					spec.GitHubAPI.Host = "https://github.example.com"
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.host (\"https://github.example.com\") must be a hostname with an optional port, without a scheme or path" "reason"="InvalidHost" "status"="False" "type"="HostValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.allowAuthentication.organizations.policy (\"OnlyUsersFromAllowedOrganizations\") is valid" "reason"="Success" "status"="True" "type"="OrganizationsPolicyValid"`,
+				`github-upstream-observer "msg"="found failing condition" "error"="GitHubIdentityProvider has a failing condition" "message"="spec.githubAPI.host (\"https://github.example.com\") must be a hostname with an optional port, without a scheme or path" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidHost" "type"="HostValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitHubIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitHubIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						{
+							Type:               "HostValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidHost",
+							Message:            `spec.githubAPI.host ("https://github.example.com") must be a hostname with an optional port, without a scheme or path`,
+						},
+						happyOrganizationsPolicyValidCondition,
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid CA bundle",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitHubIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.GitHubIdentityProviderSpec) {
+					spec.GitHubAPI.TLS = &v1alpha1.TLSSpec{CertificateAuthorityData: "invalid-base64"}
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.host (\"github.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 7" "reason"="InvalidTLSConfig" "status"="False" "type"="TLSConfigurationValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.allowAuthentication.organizations.policy (\"OnlyUsersFromAllowedOrganizations\") is valid" "reason"="Success" "status"="True" "type"="OrganizationsPolicyValid"`,
+				`github-upstream-observer "msg"="found failing condition" "error"="GitHubIdentityProvider has a failing condition" "message"="spec.githubAPI.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 7" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidTLSConfig" "type"="TLSConfigurationValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitHubIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitHubIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						happyHostValidCondition,
+						happyOrganizationsPolicyValidCondition,
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTLSConfig",
+							Message:            "spec.githubAPI.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 7",
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "allowed organizations are required by the default policy",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitHubIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.GitHubIdentityProviderSpec) {
+					spec.AllowAuthentication.Organizations = v1alpha1.GitHubOrganizationsSpec{}
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.host (\"github.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.allowAuthentication.organizations.policy is \"OnlyUsersFromAllowedOrganizations\" but spec.allowAuthentication.organizations.allowed is empty" "reason"="Invalid" "status"="False" "type"="OrganizationsPolicyValid"`,
+				`github-upstream-observer "msg"="found failing condition" "error"="GitHubIdentityProvider has a failing condition" "message"="spec.allowAuthentication.organizations.policy is \"OnlyUsersFromAllowedOrganizations\" but spec.allowAuthentication.organizations.allowed is empty" "name"="test-name" "namespace"="test-namespace" "reason"="Invalid" "type"="OrganizationsPolicyValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitHubIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitHubIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						happyHostValidCondition,
+						{
+							Type:               "OrganizationsPolicyValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "Invalid",
+							Message:            `spec.allowAuthentication.organizations.policy is "OnlyUsersFromAllowedOrganizations" but spec.allowAuthentication.organizations.allowed is empty`,
+						},
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "allowed organizations are not allowed by the AllGitHubUsers policy",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitHubIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.GitHubIdentityProviderSpec) {
+					spec.AllowAuthentication.Organizations.Policy = v1alpha1.GitHubAllowedAuthOrganizationsPolicyAllGitHubUsers
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.host (\"github.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.githubAPI.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`github-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.allowAuthentication.organizations.policy is \"AllGitHubUsers\" but spec.allowAuthentication.organizations.allowed is not empty" "reason"="Invalid" "status"="False" "type"="OrganizationsPolicyValid"`,
+				`github-upstream-observer "msg"="found failing condition" "error"="GitHubIdentityProvider has a failing condition" "message"="spec.allowAuthentication.organizations.policy is \"AllGitHubUsers\" but spec.allowAuthentication.organizations.allowed is not empty" "name"="test-name" "namespace"="test-namespace" "reason"="Invalid" "type"="OrganizationsPolicyValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitHubIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitHubIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						happyHostValidCondition,
+						{
+							Type:               "OrganizationsPolicyValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "Invalid",
+							Message:            `spec.allowAuthentication.organizations.policy is "AllGitHubUsers" but spec.allowAuthentication.organizations.allowed is not empty`,
+						},
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset(tt.inputUpstreams...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset(tt.inputSecrets...)
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // uses the same log assertions as the OIDC watcher test
+			cache := provider.NewDynamicUpstreamIDPProvider()
+			cache.SetGitHubIdentityProviders([]provider.UpstreamOIDCIdentityProviderI{
+				&upstreamgithub.ProviderConfig{Name: "initial-entry"},
+			})
+
+			controller := New(
+				cache,
+				fakePinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().GitHubIdentityProviders(),
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				testLog.Logger,
+				controllerlib.WithInformer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}}
+
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, strings.Join(tt.wantLogs, "\n"), strings.Join(testLog.Lines(), "\n"))
+
+			actualIDPList := cache.GetOIDCIdentityProviders()
+			actualNames := make([]string, 0, len(actualIDPList))
+			for _, actualIDP := range actualIDPList {
+				actualNames = append(actualNames, actualIDP.GetName())
+			}
+			require.Equal(t, tt.wantResultingCache, actualNames)
+			for i := range actualIDPList {
+				actualIDP := actualIDPList[i].(*upstreamgithub.ProviderConfig)
+				require.Equal(t, testClientID, actualIDP.GetClientID())
+				require.Equal(t, testClientSecret, actualIDP.Config.ClientSecret)
+				require.Equal(t, "https://github.example.com/login/oauth/authorize", actualIDP.GetAuthorizationURL().String())
+				require.Equal(t, "https://github.example.com/login/oauth/access_token", actualIDP.Config.Endpoint.TokenURL)
+				require.Equal(t, "https://github.example.com/api/v3", actualIDP.APIBaseURL.String())
+				require.Equal(t, []string{"read:user", "read:org"}, actualIDP.GetScopes())
+				require.Equal(t, "login", actualIDP.UsernameAttribute)
+				require.Equal(t, "name", actualIDP.GroupNameAttribute)
+				require.Equal(t, sets.New("my-org", "other-org"), actualIDP.AllowedOrganizations)
+				require.Equal(t, testUID, actualIDP.GetResourceUID())
+				require.Equal(t, time.Minute, actualIDP.Client.Timeout)
+			}
+
+			actualUpstreams, err := fakePinnipedClient.IDPV1alpha1().GitHubIdentityProviders(testNamespace).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+
+			// Assert on the expected Status of the upstreams. Preprocess the upstreams a bit so that they're easier to assert against.
+			require.ElementsMatch(t, tt.wantResultingUpstreams, normalizeGitHubUpstreams(actualUpstreams.Items, now))
+
+			// Running the sync() a second time should be idempotent except for logs, and should return the same error.
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHost(t *testing.T) {
+	tests := []struct {
+		host           string
+		wantValid      bool
+		wantAuthURL    string
+		wantAPIBaseURL string
+	}{
+		{host: "", wantValid: true, wantAuthURL: "https://github.com/login/oauth/authorize", wantAPIBaseURL: "https://api.github.com"},
+		{host: "github.com", wantValid: true, wantAuthURL: "https://github.com/login/oauth/authorize", wantAPIBaseURL: "https://api.github.com"},
+		{host: "github.example.com:8443", wantValid: true, wantAuthURL: "https://github.example.com:8443/login/oauth/authorize", wantAPIBaseURL: "https://github.example.com:8443/api/v3"},
+		{host: "github.example.com/path"},
+		{host: "user@github.example.com"},
+		{host: "github.example.com:"},
+		{host: ":8443"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.host, func(t *testing.T) {
+			result := upstreamgithub.ProviderConfig{Config: &oauth2.Config{}}
+			condition := validateHost(&v1alpha1.GitHubIdentityProvider{
+				Spec: v1alpha1.GitHubIdentityProviderSpec{GitHubAPI: v1alpha1.GitHubAPIConfig{Host: tt.host}},
+			}, &result)
+			if !tt.wantValid {
+				require.Equal(t, v1alpha1.ConditionFalse, condition.Status)
+				require.Equal(t, "InvalidHost", condition.Reason)
+				return
+			}
+			require.Equal(t, v1alpha1.ConditionTrue, condition.Status)
+			require.Equal(t, tt.wantAuthURL, result.Config.Endpoint.AuthURL)
+			require.Equal(t, tt.wantAPIBaseURL, result.APIBaseURL.String())
+		})
+	}
+}
+
+func normalizeGitHubUpstreams(upstreams []v1alpha1.GitHubIdentityProvider, now metav1.Time) []v1alpha1.GitHubIdentityProvider {
+	result := make([]v1alpha1.GitHubIdentityProvider, 0, len(upstreams))
+	for _, u := range upstreams {
+		normalized := u.DeepCopy()
+
+		// We're only interested in comparing the status, so zero out the spec.
+		normalized.Spec = v1alpha1.GitHubIdentityProviderSpec{}
+
+		// Round down the LastTransitionTime values to `now` if they were just updated. This makes
+		// it much easier to encode assertions about the expected timestamps.
+		for i := range normalized.Status.Conditions {
+			if time.Since(normalized.Status.Conditions[i].LastTransitionTime.Time) < 5*time.Second {
+				normalized.Status.Conditions[i].LastTransitionTime = now
+			}
+		}
+		result = append(result, *normalized)
+	}
+
+	return result
+}
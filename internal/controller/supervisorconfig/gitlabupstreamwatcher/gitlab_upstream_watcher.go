@@ -0,0 +1,310 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gitlabupstreamwatcher implements a controller which watches GitLabIdentityProviders.
+package gitlabupstreamwatcher
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/constable"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/upstreamgitlab"
+)
+
+const (
+	// Setup for the name of our controller in logs.
+	gitlabControllerName = "gitlab-upstream-observer"
+
+	// Constants related to the client credentials Secret.
+	gitlabClientSecretType corev1.SecretType = "secrets.pinniped.dev/gitlab-client"
+
+	clientIDDataKey     = "clientID"
+	clientSecretDataKey = "clientSecret"
+
+	// The host of the public GitLab service.
+	defaultHost = "gitlab.com"
+
+	// Constants related to conditions.
+	typeClientCredentialsValid = "ClientCredentialsValid" //nolint:gosec // this is not a credential
+	typeHostValid              = "HostValid"
+	typeTLSConfigurationValid  = "TLSConfigurationValid"
+
+	reasonInvalidHost = "InvalidHost"
+
+	// Errors that are generated by our reconcile process.
+	errGitLabFailureStatus = constable.Error("GitLabIdentityProvider has a failing condition")
+)
+
+// The scope needed to read the user's record and group memberships from the GitLab API.
+var gitlabScopes = []string{"read_api"} //nolint:gochecknoglobals
+
+// UpstreamGitLabIdentityProviderICache is a thread safe cache that holds a list of validated upstream GitLab IDP configurations.
+type UpstreamGitLabIdentityProviderICache interface {
+	SetGitLabIdentityProviders([]provider.UpstreamOIDCIdentityProviderI)
+}
+
+type gitlabWatcherController struct {
+	cache                          UpstreamGitLabIdentityProviderICache
+	log                            logr.Logger
+	client                         pinnipedclientset.Interface
+	gitlabIdentityProviderInformer idpinformers.GitLabIdentityProviderInformer
+	secretInformer                 corev1informers.SecretInformer
+	configMapInformer              corev1informers.ConfigMapInformer
+}
+
+// New instantiates a new controllerlib.Controller which will populate the provided UpstreamGitLabIdentityProviderICache.
+func New(
+	idpCache UpstreamGitLabIdentityProviderICache,
+	client pinnipedclientset.Interface,
+	gitlabIdentityProviderInformer idpinformers.GitLabIdentityProviderInformer,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	log logr.Logger,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	c := gitlabWatcherController{
+		cache:                          idpCache,
+		log:                            log.WithName(gitlabControllerName),
+		client:                         client,
+		gitlabIdentityProviderInformer: gitlabIdentityProviderInformer,
+		secretInformer:                 secretInformer,
+		configMapInformer:              configMapInformer,
+	}
+	return controllerlib.New(
+		controllerlib.Config{Name: gitlabControllerName, Syncer: &c},
+		withInformer(
+			gitlabIdentityProviderInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{gitlabClientSecretType, corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *gitlabWatcherController) Sync(ctx controllerlib.Context) error {
+	actualUpstreams, err := c.gitlabIdentityProviderInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list GitLabIdentityProviders: %w", err)
+	}
+
+	requeue := false
+	validatedUpstreams := make([]provider.UpstreamOIDCIdentityProviderI, 0, len(actualUpstreams))
+	for _, upstream := range actualUpstreams {
+		valid := c.validateUpstream(ctx, upstream)
+		if valid == nil {
+			requeue = true
+		} else {
+			validatedUpstreams = append(validatedUpstreams, provider.UpstreamOIDCIdentityProviderI(valid))
+		}
+	}
+	c.cache.SetGitLabIdentityProviders(validatedUpstreams)
+	if requeue {
+		return controllerlib.ErrSyntheticRequeue
+	}
+	return nil
+}
+
+// validateUpstream validates the provided v1alpha1.GitLabIdentityProvider and returns the validated configuration as a
+// upstreamgitlab.ProviderConfig. As a side effect, it also updates the status of the v1alpha1.GitLabIdentityProvider.
+func (c *gitlabWatcherController) validateUpstream(ctx controllerlib.Context, upstream *v1alpha1.GitLabIdentityProvider) *upstreamgitlab.ProviderConfig {
+	result := upstreamgitlab.ProviderConfig{
+		Name:              upstream.Name,
+		ResourceUID:       upstream.UID,
+		Config:            &oauth2.Config{Scopes: gitlabScopes},
+		UsernameAttribute: string(upstream.Spec.Claims.Username),
+	}
+
+	conditions := []*v1alpha1.Condition{
+		c.validateSecret(upstream, &result),
+		validateHost(upstream, &result),
+		c.validateTLS(upstream, &result),
+	}
+
+	c.updateStatus(ctx.Context, upstream, conditions)
+
+	valid := true
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	for _, condition := range conditions {
+		if condition.Status == v1alpha1.ConditionFalse {
+			valid = false
+			log.WithValues(
+				"type", condition.Type,
+				"reason", condition.Reason,
+				"message", condition.Message,
+			).Error(errGitLabFailureStatus, "found failing condition")
+		}
+	}
+	if valid {
+		return &result
+	}
+	return nil
+}
+
+// validateSecret validates the .spec.client.secretName field and returns the appropriate ClientCredentialsValid condition.
+func (c *gitlabWatcherController) validateSecret(upstream *v1alpha1.GitLabIdentityProvider, result *upstreamgitlab.ProviderConfig) *v1alpha1.Condition {
+	secretName := upstream.Spec.Client.SecretName
+
+	// Fetch the Secret from informer cache.
+	secret, err := c.secretInformer.Lister().Secrets(upstream.Namespace).Get(secretName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonNotFound,
+			Message: err.Error(),
+		}
+	}
+
+	// Validate the secret .type field.
+	if secret.Type != gitlabClientSecretType {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonWrongType,
+			Message: fmt.Sprintf("referenced Secret %q has wrong type %q (should be %q)", secretName, secret.Type, gitlabClientSecretType),
+		}
+	}
+
+	// Validate the secret .data field.
+	clientID := secret.Data[clientIDDataKey]
+	clientSecret := secret.Data[clientSecretDataKey]
+	if len(clientID) == 0 || len(clientSecret) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{clientIDDataKey, clientSecretDataKey}),
+		}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.ClientID = string(clientID)
+	result.Config.ClientSecret = string(clientSecret)
+	return &v1alpha1.Condition{
+		Type:    typeClientCredentialsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "loaded client credentials",
+	}
+}
+
+// validateHost validates the .spec.host field and returns the appropriate HostValid condition.
+// The web flow endpoints are always on the given host, and the API is always at "/api/v4" on the given host.
+func validateHost(upstream *v1alpha1.GitLabIdentityProvider, result *upstreamgitlab.ProviderConfig) *v1alpha1.Condition {
+	host := upstream.Spec.Host
+	if host == "" {
+		host = defaultHost
+	}
+
+	parsedURL, err := url.Parse("https://" + host)
+	if err != nil || parsedURL.Host != host || parsedURL.Hostname() == "" || strings.HasSuffix(host, ":") {
+		return &v1alpha1.Condition{
+			Type:    typeHostValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidHost,
+			Message: fmt.Sprintf(`spec.host (%q) must be a hostname with an optional port, without a scheme or path`, host),
+		}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.Endpoint = oauth2.Endpoint{
+		AuthURL:   (&url.URL{Scheme: "https", Host: host, Path: "/oauth/authorize"}).String(),
+		TokenURL:  (&url.URL{Scheme: "https", Host: host, Path: "/oauth/token"}).String(),
+		AuthStyle: oauth2.AuthStyleInParams,
+	}
+	result.APIBaseURL = &url.URL{Scheme: "https", Host: host, Path: "/api/v4"}
+	result.RevocationURL = &url.URL{Scheme: "https", Host: host, Path: "/oauth/revoke"}
+	return &v1alpha1.Condition{
+		Type:    typeHostValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: fmt.Sprintf("spec.host (%q) is valid", host),
+	}
+}
+
+// validateTLS validates the .spec.tls field and returns the appropriate TLSConfigurationValid condition.
+func (c *gitlabWatcherController) validateTLS(upstream *v1alpha1.GitLabIdentityProvider, result *upstreamgitlab.ProviderConfig) *v1alpha1.Condition {
+	rootCAs, _, _, err := upstreamwatchers.ReadCABundle(upstream.Spec.TLS, upstream.Namespace, c.secretInformer, c.configMapInformer)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeTLSConfigurationValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonInvalidTLSConfig,
+			Message: fmt.Sprintf("spec.tls.%s", err.Error()),
+		}
+	}
+
+	result.Client = defaultClientShortTimeout(rootCAs)
+	return &v1alpha1.Condition{
+		Type:    typeTLSConfigurationValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "spec.tls is valid",
+	}
+}
+
+func (c *gitlabWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.GitLabIdentityProvider, conditions []*v1alpha1.Condition) {
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	updated := upstream.DeepCopy()
+
+	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+
+	updated.Status.Phase = v1alpha1.GitLabPhaseReady
+	if hadErrorCondition {
+		updated.Status.Phase = v1alpha1.GitLabPhaseError
+	}
+
+	if equality.Semantic.DeepEqual(upstream, updated) {
+		return
+	}
+
+	_, err := c.client.
+		IDPV1alpha1().
+		GitLabIdentityProviders(upstream.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error(err, "failed to update status")
+	}
+}
+
+func defaultClientShortTimeout(rootCAs *x509.CertPool) *http.Client {
+	c := phttp.Default(rootCAs)
+	c.Timeout = time.Minute
+	return c
+}
@@ -0,0 +1,425 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gitlabupstreamwatcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/testlogger"
+	"go.pinniped.dev/internal/upstreamgitlab"
+)
+
+func TestGitLabUpstreamWatcherControllerFilterSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		secret     metav1.Object
+		wantAdd    bool
+		wantUpdate bool
+		wantDelete bool
+	}{
+		{
+			name: "a secret of the right type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/gitlab-client",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
+		{
+			name: "a secret of the wrong type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/not-the-gitlab-client-type",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+		{
+			name: "resource of wrong data type",
+			secret: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset()
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset()
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			secretInformer := kubeInformers.Core().V1().Secrets()
+			withInformer := testutil.NewObservableWithInformerOption()
+
+			New(
+				provider.NewDynamicUpstreamIDPProvider(),
+				nil,
+				pinnipedInformers.IDP().V1alpha1().GitLabIdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // test with no log assertions
+				withInformer.WithInformer,
+			)
+
+			unrelated := corev1.Secret{}
+			filter := withInformer.GetFilterForInformer(secretInformer)
+			require.Equal(t, test.wantAdd, filter.Add(test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(&unrelated, test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(test.secret, &unrelated))
+			require.Equal(t, test.wantDelete, filter.Delete(test.secret))
+		})
+	}
+}
+
+func TestGitLabUpstreamWatcherControllerSync(t *testing.T) {
+	t.Parallel()
+	now := metav1.NewTime(time.Now().UTC())
+
+	var (
+		testNamespace       = "test-namespace"
+		testName            = "test-name"
+		testSecretName      = "test-client-secret"
+		testClientID        = "test-gitlab-client-id"
+		testClientSecret    = "test-gitlab-client-secret"
+		testValidSecretData = map[string][]byte{"clientID": []byte(testClientID), "clientSecret": []byte(testClientSecret)}
+		testUID             = types.UID("test-uid")
+		testValidSpec       = v1alpha1.GitLabIdentityProviderSpec{
+			Host:   "gitlab.example.com",
+			Claims: v1alpha1.GitLabClaims{Username: v1alpha1.GitLabUsernameID},
+			Client: v1alpha1.GitLabClientSpec{SecretName: testSecretName},
+		}
+		testValidSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+			Type:       "secrets.pinniped.dev/gitlab-client",
+			Data:       testValidSecretData,
+		}
+	)
+
+	happyClientCredentialsValidCondition := v1alpha1.Condition{
+		Type:               "ClientCredentialsValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "loaded client credentials",
+		LastTransitionTime: now,
+	}
+	happyHostValidCondition := v1alpha1.Condition{
+		Type:               "HostValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            `spec.host ("gitlab.example.com") is valid`,
+		LastTransitionTime: now,
+	}
+	happyTLSConfigurationValidCondition := v1alpha1.Condition{
+		Type:               "TLSConfigurationValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "spec.tls is valid",
+		LastTransitionTime: now,
+	}
+
+	specWith := func(editFunc func(spec *v1alpha1.GitLabIdentityProviderSpec)) v1alpha1.GitLabIdentityProviderSpec {
+		spec := *testValidSpec.DeepCopy()
+		editFunc(&spec)
+		return spec
+	}
+
+	tests := []struct {
+		name                   string
+		inputUpstreams         []runtime.Object
+		inputSecrets           []runtime.Object
+		wantErr                string
+		wantLogs               []string
+		wantResultingCache     []string
+		wantResultingUpstreams []v1alpha1.GitLabIdentityProvider
+	}{
+		{
+			name:               "no upstreams",
+			wantResultingCache: []string{},
+		},
+		{
+			name: "valid upstream",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitLabIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantLogs: []string{
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.host (\"gitlab.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+			},
+			wantResultingCache: []string{testName},
+			wantResultingUpstreams: []v1alpha1.GitLabIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Status: v1alpha1.GitLabIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						happyHostValidCondition,
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "secret has the wrong type",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitLabIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+				Type:       "secrets.pinniped.dev/oidc-client",
+				Data:       testValidSecretData,
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="referenced Secret \"test-client-secret\" has wrong type \"secrets.pinniped.dev/oidc-client\" (should be \"secrets.pinniped.dev/gitlab-client\")" "reason"="SecretWrongType" "status"="False" "type"="ClientCredentialsValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.host (\"gitlab.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`gitlab-upstream-observer "msg"="found failing condition" "error"="GitLabIdentityProvider has a failing condition" "message"="referenced Secret \"test-client-secret\" has wrong type \"secrets.pinniped.dev/oidc-client\" (should be \"secrets.pinniped.dev/gitlab-client\")" "name"="test-name" "namespace"="test-namespace" "reason"="SecretWrongType" "type"="ClientCredentialsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitLabIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitLabIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "ClientCredentialsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretWrongType",
+							Message:            `referenced Secret "test-client-secret" has wrong type "secrets.pinniped.dev/oidc-client" (should be "secrets.pinniped.dev/gitlab-client")`,
+						},
+						happyHostValidCondition,
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "host includes a scheme",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitLabIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.GitLabIdentityProviderSpec) {
+					spec.Host = "https://gitlab.example.com"
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.host (\"https://gitlab.example.com\") must be a hostname with an optional port, without a scheme or path" "reason"="InvalidHost" "status"="False" "type"="HostValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.tls is valid" "reason"="Success" "status"="True" "type"="TLSConfigurationValid"`,
+				`gitlab-upstream-observer "msg"="found failing condition" "error"="GitLabIdentityProvider has a failing condition" "message"="spec.host (\"https://gitlab.example.com\") must be a hostname with an optional port, without a scheme or path" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidHost" "type"="HostValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitLabIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitLabIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						{
+							Type:               "HostValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidHost",
+							Message:            `spec.host ("https://gitlab.example.com") must be a hostname with an optional port, without a scheme or path`,
+						},
+						happyTLSConfigurationValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid CA bundle",
+			inputUpstreams: []runtime.Object{&v1alpha1.GitLabIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.GitLabIdentityProviderSpec) {
+					spec.TLS = &v1alpha1.TLSSpec{CertificateAuthorityData: "invalid-base64"}
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.host (\"gitlab.example.com\") is valid" "reason"="Success" "status"="True" "type"="HostValid"`,
+				`gitlab-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 7" "reason"="InvalidTLSConfig" "status"="False" "type"="TLSConfigurationValid"`,
+				`gitlab-upstream-observer "msg"="found failing condition" "error"="GitLabIdentityProvider has a failing condition" "message"="spec.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 7" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidTLSConfig" "type"="TLSConfigurationValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.GitLabIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.GitLabIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyClientCredentialsValidCondition,
+						happyHostValidCondition,
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTLSConfig",
+							Message:            "spec.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 7",
+						},
+					},
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset(tt.inputUpstreams...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset(tt.inputSecrets...)
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // uses the same log assertions as the OIDC watcher test
+			cache := provider.NewDynamicUpstreamIDPProvider()
+			cache.SetGitLabIdentityProviders([]provider.UpstreamOIDCIdentityProviderI{
+				&upstreamgitlab.ProviderConfig{Name: "initial-entry"},
+			})
+
+			controller := New(
+				cache,
+				fakePinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().GitLabIdentityProviders(),
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				testLog.Logger,
+				controllerlib.WithInformer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}}
+
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, strings.Join(tt.wantLogs, "\n"), strings.Join(testLog.Lines(), "\n"))
+
+			actualIDPList := cache.GetOIDCIdentityProviders()
+			actualNames := make([]string, 0, len(actualIDPList))
+			for _, actualIDP := range actualIDPList {
+				actualNames = append(actualNames, actualIDP.GetName())
+			}
+			require.Equal(t, tt.wantResultingCache, actualNames)
+			for i := range actualIDPList {
+				actualIDP := actualIDPList[i].(*upstreamgitlab.ProviderConfig)
+				require.Equal(t, testClientID, actualIDP.GetClientID())
+				require.Equal(t, testClientSecret, actualIDP.Config.ClientSecret)
+				require.Equal(t, "https://gitlab.example.com/oauth/authorize", actualIDP.GetAuthorizationURL().String())
+				require.Equal(t, "https://gitlab.example.com/oauth/token", actualIDP.Config.Endpoint.TokenURL)
+				require.Equal(t, "https://gitlab.example.com/api/v4", actualIDP.APIBaseURL.String())
+				require.Equal(t, "https://gitlab.example.com/oauth/revoke", actualIDP.RevocationURL.String())
+				require.Equal(t, []string{"read_api"}, actualIDP.GetScopes())
+				require.Equal(t, "id", actualIDP.UsernameAttribute)
+				require.Equal(t, testUID, actualIDP.GetResourceUID())
+				require.Equal(t, time.Minute, actualIDP.Client.Timeout)
+			}
+
+			actualUpstreams, err := fakePinnipedClient.IDPV1alpha1().GitLabIdentityProviders(testNamespace).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+
+			// Assert on the expected Status of the upstreams. Preprocess the upstreams a bit so that they're easier to assert against.
+			require.ElementsMatch(t, tt.wantResultingUpstreams, normalizeGitLabUpstreams(actualUpstreams.Items, now))
+
+			// Running the sync() a second time should be idempotent except for logs, and should return the same error.
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHost(t *testing.T) {
+	tests := []struct {
+		host           string
+		wantValid      bool
+		wantAuthURL    string
+		wantAPIBaseURL string
+	}{
+		{host: "", wantValid: true, wantAuthURL: "https://gitlab.com/oauth/authorize", wantAPIBaseURL: "https://gitlab.com/api/v4"},
+		{host: "gitlab.com", wantValid: true, wantAuthURL: "https://gitlab.com/oauth/authorize", wantAPIBaseURL: "https://gitlab.com/api/v4"},
+		{host: "gitlab.example.com:8443", wantValid: true, wantAuthURL: "https://gitlab.example.com:8443/oauth/authorize", wantAPIBaseURL: "https://gitlab.example.com:8443/api/v4"},
+		{host: "gitlab.example.com/path"},
+		{host: "user@gitlab.example.com"},
+		{host: "gitlab.example.com:"},
+		{host: ":8443"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.host, func(t *testing.T) {
+			result := upstreamgitlab.ProviderConfig{Config: &oauth2.Config{}}
+			condition := validateHost(&v1alpha1.GitLabIdentityProvider{
+				Spec: v1alpha1.GitLabIdentityProviderSpec{Host: tt.host},
+			}, &result)
+			if !tt.wantValid {
+				require.Equal(t, v1alpha1.ConditionFalse, condition.Status)
+				require.Equal(t, "InvalidHost", condition.Reason)
+				return
+			}
+			require.Equal(t, v1alpha1.ConditionTrue, condition.Status)
+			require.Equal(t, tt.wantAuthURL, result.Config.Endpoint.AuthURL)
+			require.Equal(t, tt.wantAPIBaseURL, result.APIBaseURL.String())
+		})
+	}
+}
+
+func normalizeGitLabUpstreams(upstreams []v1alpha1.GitLabIdentityProvider, now metav1.Time) []v1alpha1.GitLabIdentityProvider {
+	result := make([]v1alpha1.GitLabIdentityProvider, 0, len(upstreams))
+	for _, u := range upstreams {
+		normalized := u.DeepCopy()
+
+		// We're only interested in comparing the status, so zero out the spec.
+		normalized.Spec = v1alpha1.GitLabIdentityProviderSpec{}
+
+		// Round down the LastTransitionTime values to `now` if they were just updated. This makes
+		// it much easier to encode assertions about the expected timestamps.
+		for i := range normalized.Status.Conditions {
+			if time.Since(normalized.Status.Conditions[i].LastTransitionTime.Time) < 5*time.Second {
+				normalized.Status.Conditions[i].LastTransitionTime = now
+			}
+		}
+		result = append(result, *normalized)
+	}
+
+	return result
+}
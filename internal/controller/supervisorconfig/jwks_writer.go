@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -6,11 +6,14 @@ package supervisorconfig
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"gopkg.in/square/go-jose.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -21,6 +24,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
@@ -28,6 +32,7 @@ import (
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controller/supervisorconfig/generator"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 )
 
@@ -41,6 +46,9 @@ const (
 	//
 	// Note! The value for this key will contain only public key material!
 	jwksKey = "jwks"
+	// activeJWKCreatedAtKey points to the time at which the active JWK was generated, in RFC3339 format. It is used
+	// to decide when the active JWK should be rotated.
+	activeJWKCreatedAtKey = "activeJWKCreatedAt"
 
 	jwksSecretTypeValue corev1.SecretType = "secrets.pinniped.dev/federation-domain-jwks"
 )
@@ -49,17 +57,54 @@ const (
 	federationDomainKind = "FederationDomain"
 )
 
-// generateKey is stubbed out for the purpose of testing. The default behavior is to generate an EC key.
-var generateKey = generateECKey //nolint:gochecknoglobals
+// activeJWKKeyID is the key ID of the first active JWK of a FederationDomain. The key IDs of the JWKs which replace it
+// during signing key rotations also include the time at which they were generated, so that they are unique.
+const activeJWKKeyID = "pinniped-supervisor-key"
 
-func generateECKey(r io.Reader) (interface{}, error) {
-	return ecdsa.GenerateKey(elliptic.P256(), r)
+// signingKeyRotation is the policy for rotating the signing keys of a FederationDomain. A zero interval means that
+// the signing keys are never rotated.
+type signingKeyRotation struct {
+	// interval is how long each key is used to sign tokens before it is replaced by a new key.
+	interval time.Duration
+
+	// overlap is how long each replaced key remains in the JWKS after it was replaced.
+	overlap time.Duration
+}
+
+// defaultIDTokenSigningAlgorithm is used when a FederationDomain does not configure its idTokenSigningAlgorithm.
+const defaultIDTokenSigningAlgorithm = jose.SignatureAlgorithm(provider.DefaultIDTokenSigningAlgorithm)
+
+// generateKey is stubbed out for the purpose of testing. The default behavior is to generate a key of the type
+// which is used by the signing algorithm.
+var generateKey = generateKeyForAlgorithm //nolint:gochecknoglobals
+
+func generateKeyForAlgorithm(r io.Reader, algorithm jose.SignatureAlgorithm) (interface{}, error) {
+	switch algorithm {
+	case jose.RS256:
+		return rsa.GenerateKey(r, 2048)
+	case jose.EdDSA:
+		_, key, err := ed25519.GenerateKey(r)
+		return key, err
+	case jose.ES256:
+		return ecdsa.GenerateKey(elliptic.P256(), r)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// idTokenSigningAlgorithm returns the algorithm which the FederationDomain uses to sign its ID tokens.
+func idTokenSigningAlgorithm(federationDomain *configv1alpha1.FederationDomain) jose.SignatureAlgorithm {
+	if federationDomain.Spec.IDTokenSigningAlgorithm == "" {
+		return defaultIDTokenSigningAlgorithm
+	}
+	return jose.SignatureAlgorithm(federationDomain.Spec.IDTokenSigningAlgorithm)
 }
 
 // jwkController holds the fields necessary for the JWKS controller to communicate with FederationDomains and
 // secrets, both via a cache and via the API.
 type jwksWriterController struct {
 	jwksSecretLabels         map[string]string
+	clock                    clock.Clock
 	pinnipedClient           pinnipedclientset.Interface
 	kubeClient               kubernetes.Interface
 	federationDomainInformer configinformers.FederationDomainInformer
@@ -67,9 +112,11 @@ type jwksWriterController struct {
 }
 
 // NewJWKSWriterController returns a controllerlib.Controller that ensures a FederationDomain has a corresponding
-// Secret that contains a valid active JWK and JWKS.
+// Secret that contains a valid active JWK and JWKS. It also rotates the active JWK according to the
+// spec.signingKeyRotation of the FederationDomain.
 func NewJWKSWriterController(
 	jwksSecretLabels map[string]string,
+	clock clock.Clock,
 	kubeClient kubernetes.Interface,
 	pinnipedClient pinnipedclientset.Interface,
 	secretInformer corev1informers.SecretInformer,
@@ -85,6 +132,7 @@ func NewJWKSWriterController(
 			Name: "JWKSController",
 			Syncer: &jwksWriterController{
 				jwksSecretLabels:         jwksSecretLabels,
+				clock:                    clock,
 				kubeClient:               kubeClient,
 				pinnipedClient:           pinnipedClient,
 				secretInformer:           secretInformer,
@@ -136,13 +184,8 @@ func (c *jwksWriterController) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("cannot determine secret status: %w", err)
 	}
 	if !secretNeedsUpdate {
-		// Secret is up to date - we are good to go.
-		plog.Debug(
-			"secret is up to date",
-			"federationdomain",
-			klog.KRef(ctx.Key.Namespace, ctx.Key.Name),
-		)
-		return nil
+		// Secret is valid, but its keys might be due for rotation.
+		return c.rotateKeys(ctx, federationDomain)
 	}
 
 	// If the FederationDomain does not have a secret associated with it, that secret does not exist, or the secret
@@ -152,7 +195,7 @@ func (c *jwksWriterController) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("cannot generate secret: %w", err)
 	}
 
-	if err := c.createOrUpdateSecret(ctx.Context, secret); err != nil {
+	if err := c.createOrUpdateSecret(ctx.Context, secret, idTokenSigningAlgorithm(federationDomain)); err != nil {
 		return fmt.Errorf("cannot create or update secret: %w", err)
 	}
 	plog.Debug("created/updated secret", "secret", klog.KObj(secret))
@@ -185,8 +228,8 @@ func (c *jwksWriterController) secretNeedsUpdate(federationDomain *configv1alpha
 		return true, nil
 	}
 
-	if !isValid(secret) {
-		// If this secret is invalid, we need to generate a new one.
+	if !isValid(secret, idTokenSigningAlgorithm(federationDomain)) {
+		// If this secret is invalid, or if its key is for a different signing algorithm, we need to generate a new one.
 		return true, nil
 	}
 
@@ -198,17 +241,51 @@ func (c *jwksWriterController) generateSecret(federationDomain *configv1alpha1.F
 	// this FederationDomain should sign and verify ID tokens (e.g., hardcoded token secret, gRPC
 	// connection to KMS, etc).
 	//
-	// For now, we just generate an new RSA keypair and put that in the secret.
+	// For now, we just generate a new keypair for the configured signing algorithm and put that in the secret.
+
+	data, err := generateSecretData(idTokenSigningAlgorithm(federationDomain), activeJWKKeyID, nil, c.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      federationDomain.Name + "-jwks",
+			Namespace: federationDomain.Namespace,
+			Labels:    c.jwksSecretLabels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(federationDomain, schema.GroupVersionKind{
+					Group:   configv1alpha1.SchemeGroupVersion.Group,
+					Version: configv1alpha1.SchemeGroupVersion.Version,
+					Kind:    federationDomainKind,
+				}),
+			},
+		},
+		Data: data,
+		Type: jwksSecretTypeValue,
+	}
 
-	key, err := generateKey(rand.Reader)
+	return &s, nil
+}
+
+// generateSecretData generates a new active JWK for the signing algorithm and returns the data of a secret which
+// contains it. The JWKS of the secret also contains the public keys of the previous JWKs, so that the tokens which
+// they signed can still be verified.
+func generateSecretData(
+	algorithm jose.SignatureAlgorithm,
+	keyID string,
+	previousJWKs []jose.JSONWebKey,
+	now time.Time,
+) (map[string][]byte, error) {
+	key, err := generateKey(rand.Reader, algorithm)
 	if err != nil {
 		return nil, fmt.Errorf("cannot generate key: %w", err)
 	}
 
 	jwk := jose.JSONWebKey{
 		Key:       key,
-		KeyID:     "pinniped-supervisor-key",
-		Algorithm: "ES256",
+		KeyID:     keyID,
+		Algorithm: string(algorithm),
 		Use:       "sig",
 	}
 	jwkData, err := json.Marshal(jwk)
@@ -217,39 +294,104 @@ func (c *jwksWriterController) generateSecret(federationDomain *configv1alpha1.F
 	}
 
 	jwks := jose.JSONWebKeySet{
-		Keys: []jose.JSONWebKey{jwk.Public()},
+		Keys: append([]jose.JSONWebKey{jwk.Public()}, previousJWKs...),
 	}
 	jwksData, err := json.Marshal(jwks)
 	if err != nil {
 		return nil, fmt.Errorf("cannot marshal jwks: %w", err)
 	}
 
-	s := corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      federationDomain.Name + "-jwks",
-			Namespace: federationDomain.Namespace,
-			Labels:    c.jwksSecretLabels,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(federationDomain, schema.GroupVersionKind{
-					Group:   configv1alpha1.SchemeGroupVersion.Group,
-					Version: configv1alpha1.SchemeGroupVersion.Version,
-					Kind:    federationDomainKind,
-				}),
-			},
-		},
-		Data: map[string][]byte{
-			activeJWKKey: jwkData,
-			jwksKey:      jwksData,
-		},
-		Type: jwksSecretTypeValue,
+	return map[string][]byte{
+		activeJWKKey:          jwkData,
+		jwksKey:               jwksData,
+		activeJWKCreatedAtKey: []byte(now.UTC().Format(time.RFC3339)),
+	}, nil
+}
+
+// rotateKeys replaces the active JWK of the valid secret of the FederationDomain once it is older than the rotation
+// interval, and it removes the replaced JWK from the JWKS once the overlap period has passed. It requeues the
+// FederationDomain for the next time that its secret will need to be updated.
+func (c *jwksWriterController) rotateKeys(ctx controllerlib.Context, federationDomain *configv1alpha1.FederationDomain) error {
+	rotation, _ := validateSigningKeyRotation(federationDomain)
+	if rotation.interval == 0 {
+		plog.Debug("secret is up to date", "federationdomain", klog.KObj(federationDomain))
+		return nil
 	}
 
-	return &s, nil
+	secret, err := c.secretInformer.Lister().Secrets(federationDomain.Namespace).Get(federationDomain.Status.Secrets.JWKS.Name)
+	if err != nil {
+		return fmt.Errorf("cannot get secret: %w", err)
+	}
+
+	var activeJWK jose.JSONWebKey
+	if err := json.Unmarshal(secret.Data[activeJWKKey], &activeJWK); err != nil {
+		return fmt.Errorf("cannot unmarshal active jwk: %w", err)
+	}
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(secret.Data[jwksKey], &jwks); err != nil {
+		return fmt.Errorf("cannot unmarshal jwks: %w", err)
+	}
+
+	now := c.clock.Now()
+	createdAt := activeJWKCreatedAt(secret)
+	rotateAt := createdAt.Add(rotation.interval)
+	removePreviousJWKsAt := createdAt.Add(rotation.overlap)
+	hasPreviousJWKs := len(jwks.Keys) > 1
+
+	var data map[string][]byte
+	var next time.Duration
+	switch {
+	case !now.Before(rotateAt):
+		keyID := fmt.Sprintf("%s-%d", activeJWKKeyID, now.Unix())
+		data, err = generateSecretData(idTokenSigningAlgorithm(federationDomain), keyID, []jose.JSONWebKey{activeJWK.Public()}, now)
+		if err != nil {
+			return fmt.Errorf("cannot rotate secret: %w", err)
+		}
+		next = rotation.overlap
+	case hasPreviousJWKs && !now.Before(removePreviousJWKsAt):
+		jwksData, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{activeJWK.Public()}})
+		if err != nil {
+			return fmt.Errorf("cannot marshal jwks: %w", err)
+		}
+		data = map[string][]byte{}
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+		data[jwksKey] = jwksData
+		next = rotateAt.Sub(now)
+	case hasPreviousJWKs:
+		next = removePreviousJWKsAt.Sub(now)
+	default:
+		next = rotateAt.Sub(now)
+	}
+
+	if data != nil {
+		updatedSecret := secret.DeepCopy()
+		updatedSecret.Data = data
+		if _, err := c.kubeClient.CoreV1().Secrets(updatedSecret.Namespace).Update(ctx.Context, updatedSecret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("cannot update secret: %w", err)
+		}
+		plog.Debug("rotated keys of secret", "secret", klog.KObj(updatedSecret))
+	}
+
+	ctx.Queue.AddAfter(ctx.Key, next)
+	return nil
+}
+
+// activeJWKCreatedAt returns the time at which the active JWK of the secret was generated. The secrets which were
+// created before this time was recorded fall back to the time at which they were created.
+func activeJWKCreatedAt(secret *corev1.Secret) time.Time {
+	createdAt, err := time.Parse(time.RFC3339, string(secret.Data[activeJWKCreatedAtKey]))
+	if err != nil {
+		return secret.CreationTimestamp.Time
+	}
+	return createdAt
 }
 
 func (c *jwksWriterController) createOrUpdateSecret(
 	ctx context.Context,
 	newSecret *corev1.Secret,
+	algorithm jose.SignatureAlgorithm,
 ) error {
 	secretClient := c.kubeClient.CoreV1().Secrets(newSecret.Namespace)
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -270,7 +412,7 @@ func (c *jwksWriterController) createOrUpdateSecret(
 
 		// New secret already exists, so ensure it is up to date.
 
-		if isValid(oldSecret) {
+		if isValid(oldSecret, algorithm) {
 			// If the secret already has valid JWK's, then we are good to go and we don't need an update.
 			return nil
 		}
@@ -304,8 +446,8 @@ func (c *jwksWriterController) updateFederationDomainStatus(
 	})
 }
 
-// isValid returns whether the provided secret contains a valid active JWK and verification JWKS.
-func isValid(secret *corev1.Secret) bool {
+// isValid returns whether the provided secret contains a valid active JWK for the signing algorithm and verification JWKS.
+func isValid(secret *corev1.Secret, algorithm jose.SignatureAlgorithm) bool {
 	if secret.Type != jwksSecretTypeValue {
 		plog.Debug("secret does not have the expected type", "expectedType", jwksSecretTypeValue, "actualType", secret.Type)
 		return false
@@ -333,6 +475,11 @@ func isValid(secret *corev1.Secret) bool {
 		return false
 	}
 
+	if activeJWK.Algorithm != string(algorithm) {
+		plog.Debug("active jwk is not for the signing algorithm", "keyid", activeJWK.KeyID, "expectedAlgorithm", algorithm, "actualAlgorithm", activeJWK.Algorithm)
+		return false
+	}
+
 	jwksData, ok := secret.Data[jwksKey]
 	if !ok {
 		plog.Debug("secret does not contain valid jwks")
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -6,14 +6,22 @@ package supervisorconfig
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -21,6 +29,8 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	kubernetesfake "k8s.io/client-go/kubernetes/fake"
 	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
@@ -182,6 +192,7 @@ func TestJWKSWriterControllerFilterSecret(t *testing.T) {
 			withInformer := testutil.NewObservableWithInformerOption()
 			_ = NewJWKSWriterController(
 				nil, // labels, not needed
+				nil, // clock, not needed
 				nil, // kubeClient, not needed
 				nil, // pinnipedClient, not needed
 				secretInformer,
@@ -236,6 +247,7 @@ func TestJWKSWriterControllerFilterFederationDomain(t *testing.T) {
 			withInformer := testutil.NewObservableWithInformerOption()
 			_ = NewJWKSWriterController(
 				nil, // labels, not needed
+				nil, // clock, not needed
 				nil, // kubeClient, not needed
 				nil, // pinnipedClient, not needed
 				secretInformer,
@@ -259,6 +271,8 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 
 	const namespace = "tuna-namespace"
 
+	frozenNow := time.Date(2026, time.March, 4, 5, 6, 7, 0, time.UTC)
+
 	goodKeyPEM, err := os.ReadFile("testdata/good-ec-key.pem")
 	require.NoError(t, err)
 	block, _ := pem.Decode(goodKeyPEM)
@@ -266,6 +280,9 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 	goodKey, err := x509.ParseECPrivateKey(block.Bytes)
 	require.NoError(t, err)
 
+	var goodEdDSAJWK jose.JSONWebKey
+	require.NoError(t, goodEdDSAJWK.UnmarshalJSON(readJWKJSON(t, "testdata/good-ed25519-jwk.json")))
+
 	federationDomainGVR := schema.GroupVersionResource{
 		Group:    configv1alpha1.SchemeGroupVersion.Group,
 		Version:  configv1alpha1.SchemeGroupVersion.Version,
@@ -284,6 +301,12 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 	}
 	goodFederationDomainWithStatus := goodFederationDomain.DeepCopy()
 	goodFederationDomainWithStatus.Status.Secrets.JWKS.Name = goodFederationDomainWithStatus.Name + "-jwks"
+	goodEdDSAFederationDomainWithStatus := goodFederationDomainWithStatus.DeepCopy()
+	goodEdDSAFederationDomainWithStatus.Spec.IDTokenSigningAlgorithm = "EdDSA"
+	rotatingFederationDomainWithStatus := goodFederationDomainWithStatus.DeepCopy()
+	rotatingFederationDomainWithStatus.Spec.SigningKeyRotation.IntervalSeconds = pointer.Int32(2 * 60 * 60)
+	invalidRotatingFederationDomainWithStatus := goodFederationDomainWithStatus.DeepCopy()
+	invalidRotatingFederationDomainWithStatus.Spec.SigningKeyRotation.OverlapSeconds = pointer.Int32(60 * 60)
 
 	secretGVR := schema.GroupVersionResource{
 		Group:    corev1.SchemeGroupVersion.Group,
@@ -316,6 +339,7 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 		s.Data = make(map[string][]byte)
 		if activeJWKPath != "" {
 			s.Data["activeJWK"] = readJWKJSON(t, activeJWKPath)
+			s.Data["activeJWKCreatedAt"] = []byte(frozenNow.Format(time.RFC3339))
 		}
 		if jwksPath != "" {
 			s.Data["jwks"] = readJWKJSON(t, jwksPath)
@@ -324,6 +348,23 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 	}
 
 	goodSecret := newSecret("testdata/good-jwk.json", "testdata/good-jwks.json")
+	goodEdDSASecret := newSecret("testdata/good-ed25519-jwk.json", "testdata/good-ed25519-jwks.json")
+
+	goodJWK := jose.JSONWebKey{Key: goodKey, KeyID: "pinniped-supervisor-key", Algorithm: "ES256", Use: "sig"}
+	rotatedJWK := jose.JSONWebKey{Key: goodKey, KeyID: fmt.Sprintf("pinniped-supervisor-key-%d", frozenNow.Unix()), Algorithm: "ES256", Use: "sig"}
+	newRotatingSecret := func(activeJWKCreatedAt time.Time, activeJWK jose.JSONWebKey, previousJWKs ...jose.JSONWebKey) *corev1.Secret {
+		s := newSecret("", "")
+		activeJWKData, err := json.Marshal(activeJWK)
+		require.NoError(t, err)
+		jwksData, err := json.Marshal(jose.JSONWebKeySet{Keys: append([]jose.JSONWebKey{activeJWK.Public()}, previousJWKs...)})
+		require.NoError(t, err)
+		s.Data = map[string][]byte{
+			"activeJWK":          activeJWKData,
+			"jwks":               jwksData,
+			"activeJWKCreatedAt": []byte(activeJWKCreatedAt.Format(time.RFC3339)),
+		}
+		return s
+	}
 
 	secretWithWrongType := newSecret("testdata/good-jwk.json", "testdata/good-jwks.json")
 	secretWithWrongType.Type = "not-the-right-type"
@@ -337,6 +378,8 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 		federationDomains           []*configv1alpha1.FederationDomain
 		generateKeyErr              error
 		wantGenerateKeyCount        int
+		wantGenerateKeyAlgorithm    jose.SignatureAlgorithm
+		wantRequeueAfter            time.Duration
 		wantSecretActions           []kubetesting.Action
 		wantFederationDomainActions []kubetesting.Action
 		wantError                   string
@@ -400,6 +443,96 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 				goodSecret,
 			},
 		},
+		{
+			name: "existing federationDomain whose signing algorithm was changed",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				goodEdDSAFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				goodSecret,
+			},
+			wantGenerateKeyCount:     1,
+			wantGenerateKeyAlgorithm: jose.EdDSA,
+			wantSecretActions: []kubetesting.Action{
+				kubetesting.NewGetAction(secretGVR, namespace, goodSecret.Name),
+				kubetesting.NewUpdateAction(secretGVR, namespace, goodEdDSASecret),
+			},
+			wantFederationDomainActions: []kubetesting.Action{
+				kubetesting.NewGetAction(federationDomainGVR, namespace, goodFederationDomain.Name),
+			},
+		},
+		{
+			name: "existing federationDomain with existing secret for its signing algorithm",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				goodEdDSAFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				goodEdDSASecret,
+			},
+		},
+		{
+			name: "existing federationDomain with signing key rotation whose key is not due for rotation",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				rotatingFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				newRotatingSecret(frozenNow.Add(-30*time.Minute), goodJWK),
+			},
+			wantRequeueAfter: 90 * time.Minute,
+		},
+		{
+			name: "existing federationDomain with signing key rotation whose key is due for rotation",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				rotatingFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				newRotatingSecret(frozenNow.Add(-2*time.Hour), goodJWK),
+			},
+			wantGenerateKeyCount: 1,
+			wantSecretActions: []kubetesting.Action{
+				kubetesting.NewUpdateAction(secretGVR, namespace, newRotatingSecret(frozenNow, rotatedJWK, goodJWK.Public())),
+			},
+			wantRequeueAfter: time.Hour,
+		},
+		{
+			name: "existing federationDomain with signing key rotation whose previous key is within the overlap period",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				rotatingFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				newRotatingSecret(frozenNow.Add(-20*time.Minute), rotatedJWK, goodEdDSAJWK.Public()),
+			},
+			wantRequeueAfter: 40 * time.Minute,
+		},
+		{
+			name: "existing federationDomain with signing key rotation whose previous key is past the overlap period",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				rotatingFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				newRotatingSecret(frozenNow.Add(-70*time.Minute), rotatedJWK, goodEdDSAJWK.Public()),
+			},
+			wantSecretActions: []kubetesting.Action{
+				kubetesting.NewUpdateAction(secretGVR, namespace, newRotatingSecret(frozenNow.Add(-70*time.Minute), rotatedJWK)),
+			},
+			wantRequeueAfter: 50 * time.Minute,
+		},
+		{
+			name: "existing federationDomain with invalid signing key rotation",
+			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
+			federationDomains: []*configv1alpha1.FederationDomain{
+				invalidRotatingFederationDomainWithStatus,
+			},
+			secrets: []*corev1.Secret{
+				newRotatingSecret(frozenNow.Add(-365*24*time.Hour), goodJWK),
+			},
+		},
 		{
 			name: "deleted federationDomain",
 			key:  controllerlib.Key{Namespace: goodFederationDomain.Namespace, Name: goodFederationDomain.Name},
@@ -668,8 +801,16 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// We shouldn't run this test in parallel since it messes with a global function (generateKey).
 			generateKeyCount := 0
-			generateKey = func(_ io.Reader) (interface{}, error) {
+			generateKey = func(_ io.Reader, algorithm jose.SignatureAlgorithm) (interface{}, error) {
 				generateKeyCount++
+				wantAlgorithm := test.wantGenerateKeyAlgorithm
+				if wantAlgorithm == "" {
+					wantAlgorithm = jose.ES256
+				}
+				require.Equal(t, wantAlgorithm, algorithm)
+				if algorithm == jose.EdDSA {
+					return goodEdDSAJWK.Key, test.generateKeyErr
+				}
 				return goodKey, test.generateKeyErr
 			}
 
@@ -710,6 +851,7 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 					"myLabelKey1": "myLabelValue1",
 					"myLabelKey2": "myLabelValue2",
 				},
+				clocktesting.NewFakeClock(frozenNow),
 				kubeAPIClient,
 				pinnipedAPIClient,
 				kubeInformers.Core().V1().Secrets(),
@@ -722,9 +864,11 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 			pinnipedInformers.Start(ctx.Done())
 			controllerlib.TestRunSynchronously(t, c)
 
+			queue := &testQueue{t: t}
 			err := controllerlib.TestSync(t, c, controllerlib.Context{
 				Context: ctx,
 				Key:     test.key,
+				Queue:   queue,
 			})
 			if test.wantError != "" {
 				require.EqualError(t, err, test.wantError)
@@ -734,6 +878,14 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 
 			require.Equal(t, test.wantGenerateKeyCount, generateKeyCount)
 
+			if test.wantRequeueAfter != 0 {
+				require.True(t, queue.called)
+				require.Equal(t, test.key, queue.key)
+				require.Equal(t, test.wantRequeueAfter, queue.duration)
+			} else {
+				require.False(t, queue.called)
+			}
+
 			if test.wantSecretActions != nil {
 				require.Equal(t, test.wantSecretActions, kubeAPIClient.Actions())
 			}
@@ -744,6 +896,39 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 	}
 }
 
+func TestGenerateKeyForAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		algorithm jose.SignatureAlgorithm
+		wantKey   interface{}
+		wantError string
+	}{
+		{algorithm: jose.RS256, wantKey: &rsa.PrivateKey{}},
+		{algorithm: jose.ES256, wantKey: &ecdsa.PrivateKey{}},
+		{algorithm: jose.EdDSA, wantKey: ed25519.PrivateKey{}},
+		{algorithm: jose.HS256, wantError: `unsupported signing algorithm "HS256"`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.algorithm), func(t *testing.T) {
+			t.Parallel()
+
+			key, err := generateKeyForAlgorithm(rand.Reader, test.algorithm)
+			if test.wantError != "" {
+				require.EqualError(t, err, test.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, test.wantKey, key)
+
+			jwk := jose.JSONWebKey{Key: key, Algorithm: string(test.algorithm)}
+			require.True(t, jwk.Valid())
+			require.False(t, jwk.IsPublic())
+		})
+	}
+}
+
 func readJWKJSON(t *testing.T, path string) []byte {
 	t.Helper()
 
@@ -759,3 +944,23 @@ func readJWKJSON(t *testing.T, path string) []byte {
 }
 
 func boolPtr(b bool) *bool { return &b }
+
+type testQueue struct {
+	t *testing.T
+
+	called   bool
+	key      controllerlib.Key
+	duration time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *testQueue) AddAfter(key controllerlib.Key, duration time.Duration) {
+	q.t.Helper()
+
+	require.False(q.t, q.called, "AddAfter should only be called once")
+
+	q.called = true
+	q.key = key
+	q.duration = duration
+}
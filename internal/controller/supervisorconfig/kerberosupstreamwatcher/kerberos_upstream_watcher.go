@@ -0,0 +1,278 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kerberosupstreamwatcher implements a controller which watches KerberosIdentityProviders.
+package kerberosupstreamwatcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/constable"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/upstreamkerberos"
+)
+
+const (
+	// Setup for the name of our controller in logs.
+	kerberosControllerName = "kerberos-upstream-observer"
+
+	// Constants related to the keytab Secret.
+	kerberosKeytabSecretType corev1.SecretType = "secrets.pinniped.dev/kerberos-keytab"
+
+	keytabDataKey = "keytab"
+
+	// The kinds of identity providers which may be used for group searches.
+	ldapIdentityProviderKind            = "LDAPIdentityProvider"
+	activeDirectoryIdentityProviderKind = "ActiveDirectoryIdentityProvider"
+
+	// Constants related to conditions.
+	typeKeytabValid = "KeytabValid"
+
+	reasonInvalidKeytab = "InvalidKeytab"
+
+	// Errors that are generated by our reconcile process.
+	errKerberosFailureStatus = constable.Error("KerberosIdentityProvider has a failing condition")
+)
+
+// UpstreamKerberosIdentityProviderICache is a thread safe cache that holds a list of validated upstream Kerberos IDP
+// configurations. The LDAP and Active Directory IDPs are read from the same cache to perform group searches.
+type UpstreamKerberosIdentityProviderICache interface {
+	SetKerberosIdentityProviders([]provider.UpstreamKerberosIdentityProviderI)
+	GetLDAPIdentityProviders() []provider.UpstreamLDAPIdentityProviderI
+	GetActiveDirectoryIdentityProviders() []provider.UpstreamLDAPIdentityProviderI
+}
+
+// userSearcher is implemented by LDAP and Active Directory IDPs which can find a user without their password.
+type userSearcher interface {
+	DryRunAuthenticateUser(ctx context.Context, username string, grantedScopes []string) (*authenticators.Response, bool, error)
+}
+
+type kerberosWatcherController struct {
+	cache                            UpstreamKerberosIdentityProviderICache
+	log                              logr.Logger
+	client                           pinnipedclientset.Interface
+	kerberosIdentityProviderInformer idpinformers.KerberosIdentityProviderInformer
+	secretInformer                   corev1informers.SecretInformer
+}
+
+// New instantiates a new controllerlib.Controller which will populate the provided UpstreamKerberosIdentityProviderICache.
+func New(
+	idpCache UpstreamKerberosIdentityProviderICache,
+	client pinnipedclientset.Interface,
+	kerberosIdentityProviderInformer idpinformers.KerberosIdentityProviderInformer,
+	secretInformer corev1informers.SecretInformer,
+	log logr.Logger,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	c := kerberosWatcherController{
+		cache:                            idpCache,
+		log:                              log.WithName(kerberosControllerName),
+		client:                           client,
+		kerberosIdentityProviderInformer: kerberosIdentityProviderInformer,
+		secretInformer:                   secretInformer,
+	}
+	return controllerlib.New(
+		controllerlib.Config{Name: kerberosControllerName, Syncer: &c},
+		withInformer(
+			kerberosIdentityProviderInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypeFilter(kerberosKeytabSecretType, pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *kerberosWatcherController) Sync(ctx controllerlib.Context) error {
+	actualUpstreams, err := c.kerberosIdentityProviderInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list KerberosIdentityProviders: %w", err)
+	}
+
+	requeue := false
+	validatedUpstreams := make([]provider.UpstreamKerberosIdentityProviderI, 0, len(actualUpstreams))
+	for _, upstream := range actualUpstreams {
+		valid := c.validateUpstream(ctx, upstream)
+		if valid == nil {
+			requeue = true
+		} else {
+			validatedUpstreams = append(validatedUpstreams, provider.UpstreamKerberosIdentityProviderI(valid))
+		}
+	}
+	c.cache.SetKerberosIdentityProviders(validatedUpstreams)
+	if requeue {
+		return controllerlib.ErrSyntheticRequeue
+	}
+	return nil
+}
+
+// validateUpstream validates the provided v1alpha1.KerberosIdentityProvider and returns the validated configuration as a
+// upstreamkerberos.ProviderConfig. As a side effect, it also updates the status of the v1alpha1.KerberosIdentityProvider.
+func (c *kerberosWatcherController) validateUpstream(ctx controllerlib.Context, upstream *v1alpha1.KerberosIdentityProvider) *upstreamkerberos.ProviderConfig {
+	result := upstreamkerberos.ProviderConfig{
+		Name:                 upstream.Name,
+		ResourceUID:          upstream.UID,
+		ServicePrincipalName: upstream.Spec.ServicePrincipalName,
+		AllowedRealms:        upstream.Spec.AllowedRealms,
+		UsernameFormat:       upstream.Spec.Claims.Username,
+	}
+	if groupSearch := upstream.Spec.GroupSearch; groupSearch != nil {
+		result.GroupSearch = c.groupSearchFunc(groupSearch.IdentityProviderKind, groupSearch.IdentityProviderName)
+	}
+
+	conditions := []*v1alpha1.Condition{
+		c.validateKeytab(upstream, &result),
+	}
+
+	c.updateStatus(ctx.Context, upstream, conditions)
+
+	valid := true
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	for _, condition := range conditions {
+		if condition.Status == v1alpha1.ConditionFalse {
+			valid = false
+			log.WithValues(
+				"type", condition.Type,
+				"reason", condition.Reason,
+				"message", condition.Message,
+			).Error(errKerberosFailureStatus, "found failing condition")
+		}
+	}
+	if valid {
+		return &result
+	}
+	return nil
+}
+
+// validateKeytab validates the .spec.keytab.secretName field and returns the appropriate KeytabValid condition.
+func (c *kerberosWatcherController) validateKeytab(upstream *v1alpha1.KerberosIdentityProvider, result *upstreamkerberos.ProviderConfig) *v1alpha1.Condition {
+	secretName := upstream.Spec.Keytab.SecretName
+
+	// Fetch the Secret from informer cache.
+	secret, err := c.secretInformer.Lister().Secrets(upstream.Namespace).Get(secretName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeKeytabValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonNotFound,
+			Message: err.Error(),
+		}
+	}
+
+	// Validate the secret .type field.
+	if secret.Type != kerberosKeytabSecretType {
+		return &v1alpha1.Condition{
+			Type:    typeKeytabValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonWrongType,
+			Message: fmt.Sprintf("referenced Secret %q has wrong type %q (should be %q)", secretName, secret.Type, kerberosKeytabSecretType),
+		}
+	}
+
+	// Validate the secret .data field.
+	keytabBytes := secret.Data[keytabDataKey]
+	if len(keytabBytes) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeKeytabValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{keytabDataKey}),
+		}
+	}
+
+	kt, err := upstreamkerberos.ParseKeytab(keytabBytes, upstream.Spec.ServicePrincipalName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeKeytabValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidKeytab,
+			Message: fmt.Sprintf("referenced Secret %q has an invalid keytab: %s", secretName, err.Error()),
+		}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Keytab = kt
+	return &v1alpha1.Condition{
+		Type:    typeKeytabValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: fmt.Sprintf("loaded keytab for service principal %q", upstream.Spec.ServicePrincipalName),
+	}
+}
+
+// groupSearchFunc returns a func which finds the user's groups using the referenced LDAP or Active Directory IDP.
+// The IDP is looked up from the cache during each search, so that changes to its configuration take effect without
+// requiring any change to the KerberosIdentityProvider.
+func (c *kerberosWatcherController) groupSearchFunc(kind, name string) upstreamkerberos.GroupSearchFunc {
+	if kind == "" {
+		kind = ldapIdentityProviderKind
+	}
+	return func(ctx context.Context, username string, grantedScopes []string) ([]string, error) {
+		idps := c.cache.GetLDAPIdentityProviders()
+		if kind == activeDirectoryIdentityProviderKind {
+			idps = c.cache.GetActiveDirectoryIdentityProviders()
+		}
+		for _, idp := range idps {
+			if idp.GetName() != name {
+				continue
+			}
+			searcher, ok := idp.(userSearcher)
+			if !ok {
+				return nil, fmt.Errorf("%s %q does not support searching for users", kind, name)
+			}
+			response, found, err := searcher.DryRunAuthenticateUser(ctx, username, grantedScopes)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, fmt.Errorf("user was not found by %s %q", kind, name)
+			}
+			return response.User.GetGroups(), nil
+		}
+		return nil, fmt.Errorf("%s %q was not found or is not ready", kind, name)
+	}
+}
+
+func (c *kerberosWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.KerberosIdentityProvider, conditions []*v1alpha1.Condition) {
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	updated := upstream.DeepCopy()
+
+	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+
+	updated.Status.Phase = v1alpha1.KerberosPhaseReady
+	if hadErrorCondition {
+		updated.Status.Phase = v1alpha1.KerberosPhaseError
+	}
+
+	if equality.Semantic.DeepEqual(upstream, updated) {
+		return
+	}
+
+	_, err := c.client.
+		IDPV1alpha1().
+		KerberosIdentityProviders(upstream.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error(err, "failed to update status")
+	}
+}
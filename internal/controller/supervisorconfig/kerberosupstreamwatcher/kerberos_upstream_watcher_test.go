@@ -0,0 +1,473 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kerberosupstreamwatcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+	"go.pinniped.dev/internal/testutil/testlogger"
+	"go.pinniped.dev/internal/upstreamkerberos"
+)
+
+func TestKerberosUpstreamWatcherControllerFilterSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		secret     metav1.Object
+		wantAdd    bool
+		wantUpdate bool
+		wantDelete bool
+	}{
+		{
+			name: "a secret of the right type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/kerberos-keytab",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
+		{
+			name: "a secret of the wrong type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/not-the-kerberos-keytab-type",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+		{
+			name: "resource of wrong data type",
+			secret: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset()
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset()
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			secretInformer := kubeInformers.Core().V1().Secrets()
+			withInformer := testutil.NewObservableWithInformerOption()
+
+			New(
+				provider.NewDynamicUpstreamIDPProvider(),
+				nil,
+				pinnipedInformers.IDP().V1alpha1().KerberosIdentityProviders(),
+				secretInformer,
+				plog.Logr(), //nolint:staticcheck  // test with no log assertions
+				withInformer.WithInformer,
+			)
+
+			unrelated := corev1.Secret{}
+			filter := withInformer.GetFilterForInformer(secretInformer)
+			require.Equal(t, test.wantAdd, filter.Add(test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(&unrelated, test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(test.secret, &unrelated))
+			require.Equal(t, test.wantDelete, filter.Delete(test.secret))
+		})
+	}
+}
+
+func TestKerberosUpstreamWatcherControllerSync(t *testing.T) {
+	t.Parallel()
+	now := metav1.NewTime(time.Now().UTC())
+
+	kt := keytab.New()
+	require.NoError(t, kt.AddEntry("HTTP/supervisor.example.com", "EXAMPLE.COM", "some-password", time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96))
+	testKeytab, err := kt.Marshal()
+	require.NoError(t, err)
+
+	var (
+		testNamespace  = "test-namespace"
+		testName       = "test-name"
+		testSecretName = "test-keytab-secret"
+		testUID        = types.UID("test-uid")
+		testValidSpec  = v1alpha1.KerberosIdentityProviderSpec{
+			ServicePrincipalName: "HTTP/supervisor.example.com",
+			Keytab:               v1alpha1.KerberosKeytabSpec{SecretName: testSecretName},
+			AllowedRealms:        []string{"EXAMPLE.COM"},
+			Claims:               v1alpha1.KerberosClaims{Username: v1alpha1.KerberosUsernameShortName},
+		}
+		testValidSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+			Type:       "secrets.pinniped.dev/kerberos-keytab",
+			Data:       map[string][]byte{"keytab": testKeytab},
+		}
+	)
+
+	happyKeytabValidCondition := v1alpha1.Condition{
+		Type:               "KeytabValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            `loaded keytab for service principal "HTTP/supervisor.example.com"`,
+		LastTransitionTime: now,
+	}
+
+	specWith := func(editFunc func(spec *v1alpha1.KerberosIdentityProviderSpec)) v1alpha1.KerberosIdentityProviderSpec {
+		spec := *testValidSpec.DeepCopy()
+		editFunc(&spec)
+		return spec
+	}
+
+	tests := []struct {
+		name                   string
+		inputUpstreams         []runtime.Object
+		inputSecrets           []runtime.Object
+		wantErr                string
+		wantLogs               []string
+		wantResultingCache     []string
+		wantResultingUpstreams []v1alpha1.KerberosIdentityProvider
+	}{
+		{
+			name:               "no upstreams",
+			wantResultingCache: []string{},
+		},
+		{
+			name: "valid upstream",
+			inputUpstreams: []runtime.Object{&v1alpha1.KerberosIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantLogs: []string{
+				`kerberos-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded keytab for service principal \"HTTP/supervisor.example.com\"" "reason"="Success" "status"="True" "type"="KeytabValid"`,
+			},
+			wantResultingCache: []string{testName},
+			wantResultingUpstreams: []v1alpha1.KerberosIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Status: v1alpha1.KerberosIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: []v1alpha1.Condition{happyKeytabValidCondition},
+				},
+			}},
+		},
+		{
+			name: "secret does not exist",
+			inputUpstreams: []runtime.Object{&v1alpha1.KerberosIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`kerberos-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="secret \"test-keytab-secret\" not found" "reason"="SecretNotFound" "status"="False" "type"="KeytabValid"`,
+				`kerberos-upstream-observer "msg"="found failing condition" "error"="KerberosIdentityProvider has a failing condition" "message"="secret \"test-keytab-secret\" not found" "name"="test-name" "namespace"="test-namespace" "reason"="SecretNotFound" "type"="KeytabValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.KerberosIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.KerberosIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{{
+						Type:               "KeytabValid",
+						Status:             "False",
+						LastTransitionTime: now,
+						Reason:             "SecretNotFound",
+						Message:            `secret "test-keytab-secret" not found`,
+					}},
+				},
+			}},
+		},
+		{
+			name: "secret has the wrong type",
+			inputUpstreams: []runtime.Object{&v1alpha1.KerberosIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+				Type:       "some-other-type",
+				Data:       map[string][]byte{"keytab": testKeytab},
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`kerberos-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="referenced Secret \"test-keytab-secret\" has wrong type \"some-other-type\" (should be \"secrets.pinniped.dev/kerberos-keytab\")" "reason"="SecretWrongType" "status"="False" "type"="KeytabValid"`,
+				`kerberos-upstream-observer "msg"="found failing condition" "error"="KerberosIdentityProvider has a failing condition" "message"="referenced Secret \"test-keytab-secret\" has wrong type \"some-other-type\" (should be \"secrets.pinniped.dev/kerberos-keytab\")" "name"="test-name" "namespace"="test-namespace" "reason"="SecretWrongType" "type"="KeytabValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.KerberosIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.KerberosIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{{
+						Type:               "KeytabValid",
+						Status:             "False",
+						LastTransitionTime: now,
+						Reason:             "SecretWrongType",
+						Message:            `referenced Secret "test-keytab-secret" has wrong type "some-other-type" (should be "secrets.pinniped.dev/kerberos-keytab")`,
+					}},
+				},
+			}},
+		},
+		{
+			name: "secret is missing the keytab",
+			inputUpstreams: []runtime.Object{&v1alpha1.KerberosIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+				Type:       "secrets.pinniped.dev/kerberos-keytab",
+				Data:       map[string][]byte{"some-other-key": testKeytab},
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`kerberos-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="referenced Secret \"test-keytab-secret\" is missing required keys [\"keytab\"]" "reason"="SecretMissingKeys" "status"="False" "type"="KeytabValid"`,
+				`kerberos-upstream-observer "msg"="found failing condition" "error"="KerberosIdentityProvider has a failing condition" "message"="referenced Secret \"test-keytab-secret\" is missing required keys [\"keytab\"]" "name"="test-name" "namespace"="test-namespace" "reason"="SecretMissingKeys" "type"="KeytabValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.KerberosIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.KerberosIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{{
+						Type:               "KeytabValid",
+						Status:             "False",
+						LastTransitionTime: now,
+						Reason:             "SecretMissingKeys",
+						Message:            `referenced Secret "test-keytab-secret" is missing required keys ["keytab"]`,
+					}},
+				},
+			}},
+		},
+		{
+			name: "keytab does not contain the service principal",
+			inputUpstreams: []runtime.Object{&v1alpha1.KerberosIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.KerberosIdentityProviderSpec) {
+					spec.ServicePrincipalName = "HTTP/other.example.com"
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`kerberos-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="referenced Secret \"test-keytab-secret\" has an invalid keytab: keytab does not contain any keys for service principal \"HTTP/other.example.com\"" "reason"="InvalidKeytab" "status"="False" "type"="KeytabValid"`,
+				`kerberos-upstream-observer "msg"="found failing condition" "error"="KerberosIdentityProvider has a failing condition" "message"="referenced Secret \"test-keytab-secret\" has an invalid keytab: keytab does not contain any keys for service principal \"HTTP/other.example.com\"" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidKeytab" "type"="KeytabValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.KerberosIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.KerberosIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{{
+						Type:               "KeytabValid",
+						Status:             "False",
+						LastTransitionTime: now,
+						Reason:             "InvalidKeytab",
+						Message:            `referenced Secret "test-keytab-secret" has an invalid keytab: keytab does not contain any keys for service principal "HTTP/other.example.com"`,
+					}},
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset(tt.inputUpstreams...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset(tt.inputSecrets...)
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // uses the same log assertions as the OIDC watcher test
+			cache := provider.NewDynamicUpstreamIDPProvider()
+			cache.SetKerberosIdentityProviders([]provider.UpstreamKerberosIdentityProviderI{
+				&upstreamkerberos.ProviderConfig{Name: "initial-entry"},
+			})
+
+			controller := New(
+				cache,
+				fakePinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().KerberosIdentityProviders(),
+				kubeInformers.Core().V1().Secrets(),
+				testLog.Logger,
+				controllerlib.WithInformer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}}
+
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, strings.Join(tt.wantLogs, "\n"), strings.Join(testLog.Lines(), "\n"))
+
+			actualIDPList := cache.GetKerberosIdentityProviders()
+			actualNames := make([]string, 0, len(actualIDPList))
+			for _, actualIDP := range actualIDPList {
+				actualNames = append(actualNames, actualIDP.GetName())
+			}
+			require.Equal(t, tt.wantResultingCache, actualNames)
+			for i := range actualIDPList {
+				actualIDP := actualIDPList[i].(*upstreamkerberos.ProviderConfig)
+				require.Equal(t, testUID, actualIDP.GetResourceUID())
+				require.Equal(t, "HTTP/supervisor.example.com", actualIDP.ServicePrincipalName)
+				require.Len(t, actualIDP.Keytab.Entries, 1)
+				require.Equal(t, []string{"EXAMPLE.COM"}, actualIDP.AllowedRealms)
+				require.Equal(t, v1alpha1.KerberosUsernameShortName, actualIDP.UsernameFormat)
+				require.Nil(t, actualIDP.GroupSearch)
+			}
+
+			actualUpstreams, err := fakePinnipedClient.IDPV1alpha1().KerberosIdentityProviders(testNamespace).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+
+			// Assert on the expected Status of the upstreams. Preprocess the upstreams a bit so that they're easier to assert against.
+			require.ElementsMatch(t, tt.wantResultingUpstreams, normalizeKerberosUpstreams(actualUpstreams.Items, now))
+
+			// Running the sync() a second time should be idempotent except for logs, and should return the same error.
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+type testUserSearchingLDAPIdentityProvider struct {
+	*oidctestutil.TestUpstreamLDAPIdentityProvider
+	dryRunAuthenticateFunc func(ctx context.Context, username string) (*authenticators.Response, bool, error)
+}
+
+func (u *testUserSearchingLDAPIdentityProvider) DryRunAuthenticateUser(ctx context.Context, username string, _ []string) (*authenticators.Response, bool, error) {
+	return u.dryRunAuthenticateFunc(ctx, username)
+}
+
+func TestGroupSearchFunc(t *testing.T) {
+	userSearchingIDP := func(name string, groups []string, found bool, err error) *testUserSearchingLDAPIdentityProvider {
+		return &testUserSearchingLDAPIdentityProvider{
+			TestUpstreamLDAPIdentityProvider: &oidctestutil.TestUpstreamLDAPIdentityProvider{Name: name},
+			dryRunAuthenticateFunc: func(_ context.Context, username string) (*authenticators.Response, bool, error) {
+				require.Equal(t, "alice", username)
+				if err != nil || !found {
+					return nil, found, err
+				}
+				return &authenticators.Response{User: &user.DefaultInfo{Name: username, Groups: groups}}, true, nil
+			},
+		}
+	}
+
+	cache := provider.NewDynamicUpstreamIDPProvider()
+	cache.SetLDAPIdentityProviders([]provider.UpstreamLDAPIdentityProviderI{
+		userSearchingIDP("some-ldap", []string{"ldap-group"}, true, nil),
+		userSearchingIDP("some-ldap-without-user", nil, false, nil),
+		userSearchingIDP("some-failing-ldap", nil, false, errors.New("some LDAP error")),
+		&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "some-ldap-without-search"},
+	})
+	cache.SetActiveDirectoryIdentityProviders([]provider.UpstreamLDAPIdentityProviderI{
+		userSearchingIDP("some-ad", []string{"ad-group"}, true, nil),
+	})
+	c := &kerberosWatcherController{cache: cache}
+
+	tests := []struct {
+		name       string
+		kind       string
+		idpName    string
+		wantGroups []string
+		wantErr    string
+	}{
+		{
+			name:       "LDAPIdentityProvider is the default kind",
+			idpName:    "some-ldap",
+			wantGroups: []string{"ldap-group"},
+		},
+		{
+			name:       "ActiveDirectoryIdentityProvider",
+			kind:       "ActiveDirectoryIdentityProvider",
+			idpName:    "some-ad",
+			wantGroups: []string{"ad-group"},
+		},
+		{
+			name:    "IDP of a different kind with the same name is ignored",
+			kind:    "ActiveDirectoryIdentityProvider",
+			idpName: "some-ldap",
+			wantErr: `ActiveDirectoryIdentityProvider "some-ldap" was not found or is not ready`,
+		},
+		{
+			name:    "user not found",
+			kind:    "LDAPIdentityProvider",
+			idpName: "some-ldap-without-user",
+			wantErr: `user was not found by LDAPIdentityProvider "some-ldap-without-user"`,
+		},
+		{
+			name:    "search fails",
+			idpName: "some-failing-ldap",
+			wantErr: "some LDAP error",
+		},
+		{
+			name:    "IDP cannot search for users",
+			idpName: "some-ldap-without-search",
+			wantErr: `LDAPIdentityProvider "some-ldap-without-search" does not support searching for users`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := c.groupSearchFunc(tt.kind, tt.idpName)(context.Background(), "alice", []string{"groups"})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantGroups, groups)
+		})
+	}
+}
+
+func normalizeKerberosUpstreams(upstreams []v1alpha1.KerberosIdentityProvider, now metav1.Time) []v1alpha1.KerberosIdentityProvider {
+	result := make([]v1alpha1.KerberosIdentityProvider, 0, len(upstreams))
+	for _, u := range upstreams {
+		normalized := u.DeepCopy()
+
+		// We're only interested in comparing the status, so zero out the spec.
+		normalized.Spec = v1alpha1.KerberosIdentityProviderSpec{}
+
+		// Round down the LastTransitionTime values to `now` if they were just updated. This makes
+		// it much easier to encode assertions about the expected timestamps.
+		for i := range normalized.Status.Conditions {
+			if time.Since(normalized.Status.Conditions[i].LastTransitionTime.Time) < 5*time.Second {
+				normalized.Status.Conditions[i].LastTransitionTime = now
+			}
+		}
+		result = append(result, *normalized)
+	}
+
+	return result
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package ldapupstreamwatcher implements a controller which watches LDAPIdentityProviders.
@@ -7,11 +7,18 @@ package ldapupstreamwatcher
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/go-ldap/ldap/v3"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
@@ -45,6 +52,10 @@ func (g *ldapUpstreamGenericLDAPImpl) Name() string {
 	return g.ldapIdentityProvider.Name
 }
 
+func (g *ldapUpstreamGenericLDAPImpl) UID() types.UID {
+	return g.ldapIdentityProvider.UID
+}
+
 func (g *ldapUpstreamGenericLDAPImpl) Generation() int64 {
 	return g.ldapIdentityProvider.Generation
 }
@@ -69,6 +80,14 @@ func (s *ldapUpstreamGenericLDAPSpec) BindSecretName() string {
 	return s.ldapIdentityProvider.Spec.Bind.SecretName
 }
 
+func (s *ldapUpstreamGenericLDAPSpec) UserDNTemplate() string {
+	return s.ldapIdentityProvider.Spec.Bind.UserDNTemplate
+}
+
+func (s *ldapUpstreamGenericLDAPSpec) ClientCertificateSecretName() string {
+	return s.ldapIdentityProvider.Spec.Bind.ClientCertificateSecretName
+}
+
 func (s *ldapUpstreamGenericLDAPSpec) UserSearch() upstreamwatchers.UpstreamGenericLDAPUserSearch {
 	return &ldapUpstreamGenericLDAPUserSearch{s.ldapIdentityProvider.Spec.UserSearch}
 }
@@ -77,6 +96,18 @@ func (s *ldapUpstreamGenericLDAPSpec) GroupSearch() upstreamwatchers.UpstreamGen
 	return &ldapUpstreamGenericLDAPGroupSearch{s.ldapIdentityProvider.Spec.GroupSearch}
 }
 
+func (s *ldapUpstreamGenericLDAPSpec) ProxyURL() string {
+	return s.ldapIdentityProvider.Spec.ProxyURL
+}
+
+func (s *ldapUpstreamGenericLDAPSpec) DialTimeoutSeconds() int64 {
+	return s.ldapIdentityProvider.Spec.DialTimeoutSeconds
+}
+
+func (s *ldapUpstreamGenericLDAPSpec) SearchTimeoutSeconds() int64 {
+	return s.ldapIdentityProvider.Spec.SearchTimeoutSeconds
+}
+
 func (s *ldapUpstreamGenericLDAPSpec) DetectAndSetSearchBase(_ context.Context, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
 	config.GroupSearch.Base = s.ldapIdentityProvider.Spec.GroupSearch.Base
 	config.UserSearch.Base = s.ldapIdentityProvider.Spec.UserSearch.Base
@@ -103,6 +134,14 @@ func (u *ldapUpstreamGenericLDAPUserSearch) UIDAttribute() string {
 	return u.userSearch.Attributes.UID
 }
 
+func (u *ldapUpstreamGenericLDAPUserSearch) Scope() v1alpha1.LDAPSearchScope {
+	return u.userSearch.Scope
+}
+
+func (u *ldapUpstreamGenericLDAPUserSearch) DerefAliases() v1alpha1.LDAPDerefAliases {
+	return u.userSearch.DerefAliases
+}
+
 type ldapUpstreamGenericLDAPGroupSearch struct {
 	groupSearch v1alpha1.LDAPIdentityProviderGroupSearch
 }
@@ -119,6 +158,18 @@ func (g *ldapUpstreamGenericLDAPGroupSearch) GroupNameAttribute() string {
 	return g.groupSearch.Attributes.GroupName
 }
 
+func (g *ldapUpstreamGenericLDAPGroupSearch) Scope() v1alpha1.LDAPSearchScope {
+	return g.groupSearch.Scope
+}
+
+func (g *ldapUpstreamGenericLDAPGroupSearch) DerefAliases() v1alpha1.LDAPDerefAliases {
+	return g.groupSearch.DerefAliases
+}
+
+func (g *ldapUpstreamGenericLDAPGroupSearch) GroupNameTransformations() []v1alpha1.GroupNameTransformation {
+	return g.groupSearch.GroupNameTransformations
+}
+
 type ldapUpstreamGenericLDAPStatus struct {
 	ldapIdentityProvider v1alpha1.LDAPIdentityProvider
 }
@@ -139,6 +190,10 @@ type ldapWatcherController struct {
 	client                       pinnipedclientset.Interface
 	ldapIdentityProviderInformer idpinformers.LDAPIdentityProviderInformer
 	secretInformer               corev1informers.SecretInformer
+	configMapInformer            corev1informers.ConfigMapInformer
+	conditionEvents              *upstreamwatchers.ConditionEventRecorder
+	authenticationRateLimiters   *upstreamwatchers.AuthenticationRateLimiters
+	connectionRevalidations      *upstreamwatchers.ConnectionRevalidations
 }
 
 // New instantiates a new controllerlib.Controller which will populate the provided UpstreamLDAPIdentityProviderICache.
@@ -147,17 +202,25 @@ func New(
 	client pinnipedclientset.Interface,
 	ldapIdentityProviderInformer idpinformers.LDAPIdentityProviderInformer,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.EventRecorder,
+	validatedSettingsCache upstreamwatchers.ValidatedSettingsCacheI,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
+	if validatedSettingsCache == nil {
+		// start with an empty in-memory cache
+		validatedSettingsCache = upstreamwatchers.NewValidatedSettingsCache()
+	}
 	return newInternal(
 		idpCache,
-		// start with an empty cache
-		upstreamwatchers.NewValidatedSettingsCache(),
+		validatedSettingsCache,
 		// nil means to use a real production dialer when creating objects to add to the cache
 		nil,
 		client,
 		ldapIdentityProviderInformer,
 		secretInformer,
+		configMapInformer,
+		eventRecorder,
 		withInformer,
 	)
 }
@@ -170,6 +233,8 @@ func newInternal(
 	client pinnipedclientset.Interface,
 	ldapIdentityProviderInformer idpinformers.LDAPIdentityProviderInformer,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.EventRecorder,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
 	c := ldapWatcherController{
@@ -179,6 +244,10 @@ func newInternal(
 		client:                       client,
 		ldapIdentityProviderInformer: ldapIdentityProviderInformer,
 		secretInformer:               secretInformer,
+		configMapInformer:            configMapInformer,
+		conditionEvents:              upstreamwatchers.NewConditionEventRecorder(eventRecorder),
+		authenticationRateLimiters:   upstreamwatchers.NewAuthenticationRateLimiters(),
+		connectionRevalidations:      upstreamwatchers.NewConnectionRevalidations(clock.RealClock{}),
 	}
 	return controllerlib.New(
 		controllerlib.Config{Name: ldapControllerName, Syncer: &c},
@@ -189,7 +258,15 @@ func newInternal(
 		),
 		withInformer(
 			secretInformer,
-			pinnipedcontroller.MatchAnySecretOfTypeFilter(upstreamwatchers.LDAPBindAccountSecretType, pinnipedcontroller.SingletonQueue()),
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{upstreamwatchers.LDAPBindAccountSecretType, upstreamwatchers.LDAPClientCertificateSecretType, corev1.SecretTypeOpaque},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
 			controllerlib.InformerOption{},
 		),
 	)
@@ -203,9 +280,16 @@ func (c *ldapWatcherController) Sync(ctx controllerlib.Context) error {
 	}
 
 	requeue := false
+	var revalidationInterval time.Duration
+	upstreamUIDs := sets.New[types.UID]()
 	validatedUpstreams := make([]provider.UpstreamLDAPIdentityProviderI, 0, len(actualUpstreams))
 	for _, upstream := range actualUpstreams {
-		valid, requestedRequeue := c.validateUpstream(ctx.Context, upstream)
+		upstreamUIDs.Insert(upstream.UID)
+		revalidateConnection, nextRevalidation := c.connectionRevalidations.Due(upstream.UID, upstream.Spec.ConnectionRevalidationIntervalSeconds)
+		if nextRevalidation > 0 && (revalidationInterval == 0 || nextRevalidation < revalidationInterval) {
+			revalidationInterval = nextRevalidation
+		}
+		valid, requestedRequeue := c.validateUpstream(ctx.Context, upstream, revalidateConnection)
 		if valid != nil {
 			validatedUpstreams = append(validatedUpstreams, valid)
 		}
@@ -214,21 +298,31 @@ func (c *ldapWatcherController) Sync(ctx controllerlib.Context) error {
 		}
 	}
 
+	// Forget the authentication rate limiters and the connection revalidations of any upstreams which were deleted.
+	c.authenticationRateLimiters.Retain(upstreamUIDs)
+	c.connectionRevalidations.Retain(upstreamUIDs)
+
 	c.cache.SetLDAPIdentityProviders(validatedUpstreams)
 
+	// Sync again later to validate the connections again, since the LDAP servers may have become unavailable.
+	if revalidationInterval > 0 {
+		ctx.Queue.AddAfter(ctx.Key, revalidationInterval)
+	}
+
 	if requeue {
 		return controllerlib.ErrSyntheticRequeue
 	}
 	return nil
 }
 
-func (c *ldapWatcherController) validateUpstream(ctx context.Context, upstream *v1alpha1.LDAPIdentityProvider) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
+func (c *ldapWatcherController) validateUpstream(ctx context.Context, upstream *v1alpha1.LDAPIdentityProvider, revalidateConnection bool) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
 	spec := upstream.Spec
 
 	config := &upstreamldap.ProviderConfig{
-		Name:        upstream.Name,
-		ResourceUID: upstream.UID,
-		Host:        spec.Host,
+		Name:          upstream.Name,
+		ResourceUID:   upstream.UID,
+		Host:          spec.Host,
+		FailoverHosts: spec.Hosts,
 		UserSearch: upstreamldap.UserSearchConfig{
 			Base:              spec.UserSearch.Base,
 			Filter:            spec.UserSearch.Filter,
@@ -236,25 +330,69 @@ func (c *ldapWatcherController) validateUpstream(ctx context.Context, upstream *
 			UIDAttribute:      spec.UserSearch.Attributes.UID,
 		},
 		GroupSearch: upstreamldap.GroupSearchConfig{
-			Base:               spec.GroupSearch.Base,
-			Filter:             spec.GroupSearch.Filter,
-			GroupNameAttribute: spec.GroupSearch.Attributes.GroupName,
-			SkipGroupRefresh:   spec.GroupSearch.SkipGroupRefresh,
+			Base:                   spec.GroupSearch.Base,
+			Filter:                 spec.GroupSearch.Filter,
+			UserAttributeForFilter: spec.GroupSearch.UserAttributeForFilter,
+			GroupNameAttribute:     spec.GroupSearch.Attributes.GroupName,
+			SkipGroupRefresh:       spec.GroupSearch.SkipGroupRefresh,
+			RefreshInterval:        time.Duration(spec.GroupSearch.GroupRefreshIntervalSeconds) * time.Second,
+			PageSize:               uint32(spec.GroupSearch.PageSize),
 		},
-		Dialer: c.ldapDialer,
+		Referrals: upstreamldap.ReferralConfig{
+			Follow:    spec.Referrals.Follow,
+			MaxHops:   int(spec.Referrals.MaxHops),
+			Anonymous: spec.Referrals.CredentialPolicy == v1alpha1.ReferralCredentialPolicyAnonymous,
+		},
+		AdditionalClaimMappings:        spec.AttributeMappings.AdditionalClaims,
+		RefreshAttributeAssertions:     refreshAttributeAssertions(spec.RefreshChecks),
+		UIDAttributeParsingOverrides:   binaryAttributeParsingOverrides(spec.UserSearch.Attributes.UID, spec.UserSearch.Attributes.UIDBinaryDecoder),
+		GroupAttributeParsingOverrides: binaryAttributeParsingOverrides(spec.GroupSearch.Attributes.GroupName, spec.GroupSearch.Attributes.GroupNameBinaryDecoder),
+		Dialer:                         c.ldapDialer,
+		AuthenticationRateLimiter: c.authenticationRateLimiters.ForUpstream(upstream.UID,
+			spec.AuthenticationRateLimits.PerUsername, spec.AuthenticationRateLimits.PerClientIP),
 	}
 
-	conditions := upstreamwatchers.ValidateGenericLDAP(ctx, &ldapUpstreamGenericLDAPImpl{*upstream}, c.secretInformer, c.validatedSettingsCache, config)
+	conditions := upstreamwatchers.ValidateGenericLDAP(ctx, &ldapUpstreamGenericLDAPImpl{*upstream}, c.secretInformer, c.configMapInformer, c.validatedSettingsCache, config, revalidateConnection)
 
 	c.updateStatus(ctx, upstream, conditions.Conditions())
 
 	return upstreamwatchers.EvaluateConditions(conditions, config)
 }
 
+// refreshAttributeAssertions returns the attribute assertions from the spec. The operators are passed through as-is,
+// since an unknown operator fails the assertion.
+func refreshAttributeAssertions(refreshChecks v1alpha1.LDAPIdentityProviderRefreshChecks) []upstreamldap.AttributeAssertion {
+	if len(refreshChecks.AttributeAssertions) == 0 {
+		return nil
+	}
+	assertions := make([]upstreamldap.AttributeAssertion, 0, len(refreshChecks.AttributeAssertions))
+	for _, assertion := range refreshChecks.AttributeAssertions {
+		assertions = append(assertions, upstreamldap.AttributeAssertion{
+			Attribute: assertion.Attribute,
+			Operator:  upstreamldap.AttributeAssertionOperator(assertion.Operator),
+			Value:     assertion.Value,
+		})
+	}
+	return assertions
+}
+
+// binaryAttributeParsingOverrides returns the parsing overrides which decode the named attribute using the decoder
+// from the spec, or nil when no decoder was specified. The "dn" is not an attribute, so it is never decoded.
+func binaryAttributeParsingOverrides(attributeName string, decoder v1alpha1.LDAPBinaryAttributeDecoder) map[string]func(*ldap.Entry) (string, error) {
+	if decoder == "" || attributeName == "" || attributeName == "dn" {
+		return nil
+	}
+	return map[string]func(*ldap.Entry) (string, error){
+		attributeName: upstreamldap.BinaryAttributeParser(attributeName, upstreamldap.BinaryAttributeDecoder(decoder)),
+	}
+}
+
 func (c *ldapWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.LDAPIdentityProvider, conditions []*v1alpha1.Condition) {
 	log := plog.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
 	updated := upstream.DeepCopy()
 
+	c.conditionEvents.RecordTransitions(upstreamwatchers.ObjectReferenceForEvents("LDAPIdentityProvider", upstream), upstream.Status.Conditions, conditions)
+
 	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
 
 	updated.Status.Phase = v1alpha1.LDAPPhaseReady
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package ldapupstreamwatcher
@@ -8,6 +8,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
 	"sort"
 	"testing"
 	"time"
@@ -20,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
@@ -54,6 +57,26 @@ func TestLDAPUpstreamWatcherControllerFilterSecrets(t *testing.T) {
 			wantUpdate: true,
 			wantDelete: true,
 		},
+		{
+			name: "a secret of the client certificate type",
+			secret: &corev1.Secret{
+				Type:       corev1.SecretTypeTLS,
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
+		{
+			name: "an opaque secret which could hold a CA bundle",
+			secret: &corev1.Secret{
+				Type:       corev1.SecretTypeOpaque,
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
 		{
 			name: "a secret of the wrong type",
 			secret: &corev1.Secret{
@@ -79,9 +102,10 @@ func TestLDAPUpstreamWatcherControllerFilterSecrets(t *testing.T) {
 			fakeKubeClient := fake.NewSimpleClientset()
 			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
 			secretInformer := kubeInformers.Core().V1().Secrets()
+			configMapInformer := kubeInformers.Core().V1().ConfigMaps()
 			withInformer := testutil.NewObservableWithInformerOption()
 
-			New(nil, nil, ldapIDPInformer, secretInformer, withInformer.WithInformer)
+			New(nil, nil, ldapIDPInformer, secretInformer, configMapInformer, nil, nil, withInformer.WithInformer)
 
 			unrelated := corev1.Secret{}
 			filter := withInformer.GetFilterForInformer(secretInformer)
@@ -124,9 +148,10 @@ func TestLDAPUpstreamWatcherControllerFilterLDAPIdentityProviders(t *testing.T)
 			fakeKubeClient := fake.NewSimpleClientset()
 			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
 			secretInformer := kubeInformers.Core().V1().Secrets()
+			configMapInformer := kubeInformers.Core().V1().ConfigMaps()
 			withInformer := testutil.NewObservableWithInformerOption()
 
-			New(nil, nil, ldapIDPInformer, secretInformer, withInformer.WithInformer)
+			New(nil, nil, ldapIDPInformer, secretInformer, configMapInformer, nil, nil, withInformer.WithInformer)
 
 			unrelated := corev1.Secret{}
 			filter := withInformer.GetFilterForInformer(ldapIDPInformer)
@@ -148,20 +173,23 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 	now := metav1.NewTime(time.Now().UTC())
 
 	const (
-		testNamespace         = "test-namespace"
-		testName              = "test-name"
-		testResourceUID       = "test-resource-uid"
-		testSecretName        = "test-bind-secret"
-		testBindUsername      = "test-bind-username"
-		testBindPassword      = "test-bind-password"
-		testHost              = "ldap.example.com:123"
-		testUserSearchBase    = "test-user-search-base"
-		testUserSearchFilter  = "test-user-search-filter"
-		testGroupSearchBase   = "test-group-search-base"
-		testGroupSearchFilter = "test-group-search-filter"
-		testUsernameAttrName  = "test-username-attr"
-		testGroupNameAttrName = "test-group-name-attr"
-		testUIDAttrName       = "test-uid-attr"
+		testNamespace             = "test-namespace"
+		testName                  = "test-name"
+		testResourceUID           = "test-resource-uid"
+		testSecretName            = "test-bind-secret"
+		testClientCertSecret      = "test-client-cert-secret"
+		testBindUsername          = "test-bind-username"
+		testBindPassword          = "test-bind-password"
+		testSecondaryBindUsername = "test-secondary-bind-username"
+		testSecondaryBindPassword = "test-secondary-bind-password"
+		testHost                  = "ldap.example.com:123"
+		testUserSearchBase        = "test-user-search-base"
+		testUserSearchFilter      = "test-user-search-filter"
+		testGroupSearchBase       = "test-group-search-base"
+		testGroupSearchFilter     = "test-group-search-filter"
+		testUsernameAttrName      = "test-username-attr"
+		testGroupNameAttrName     = "test-group-name-attr"
+		testUIDAttrName           = "test-uid-attr"
 	)
 
 	testValidSecretData := map[string][]byte{"username": []byte(testBindUsername), "password": []byte(testBindPassword)}
@@ -170,6 +198,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 	require.NoError(t, err)
 	testCABundle := testCA.Bundle()
 	testCABundleBase64Encoded := base64.StdEncoding.EncodeToString(testCABundle)
+	testClientCertPEM, testClientKeyPEM, err := testCA.IssueClientCertPEM("test-client", nil, time.Hour)
+	require.NoError(t, err)
 
 	validUpstream := &v1alpha1.LDAPIdentityProvider{
 		ObjectMeta: metav1.ObjectMeta{
@@ -242,6 +272,27 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
+	activeBindCredentialsPrimaryCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "ActiveBindCredentials",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "UsingPrimaryBindCredentials",
+			Message: fmt.Sprintf(`binding as user "%s" using the primary bind credentials from the ["username" "password"] keys of Secret "%s"`,
+				testBindUsername, testSecretName),
+			ObservedGeneration: gen,
+		}
+	}
+	activeBindCredentialsUnknownCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "ActiveBindCredentials",
+			Status:             "Unknown",
+			LastTransitionTime: now,
+			Reason:             "LDAPConnectionError",
+			Message:            "could not determine which bind credentials are working because the LDAP connection could not be validated",
+			ObservedGeneration: gen,
+		}
+	}
 	ldapConnectionValidTrueCondition := func(gen int64, secretVersion string) v1alpha1.Condition {
 		return v1alpha1.Condition{
 			Type:               "LDAPConnectionValid",
@@ -272,11 +323,45 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
+	searchOptionsValidTrueCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "SearchOptionsValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "loaded search options",
+			ObservedGeneration: gen,
+		}
+	}
+	timeoutsValidTrueCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "TimeoutsValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "loaded timeouts",
+			ObservedGeneration: gen,
+		}
+	}
+	proxyURLValidTrueCondition := func(gen int64) v1alpha1.Condition {
+		return v1alpha1.Condition{
+			Type:               "ProxyURLValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "no proxy URL provided",
+			ObservedGeneration: gen,
+		}
+	}
 	allConditionsTrue := func(gen int64, secretVersion string) []v1alpha1.Condition {
 		return []v1alpha1.Condition{
+			activeBindCredentialsPrimaryCondition(gen),
 			bindSecretValidTrueCondition(gen),
 			ldapConnectionValidTrueCondition(gen, secretVersion),
+			proxyURLValidTrueCondition(gen),
+			searchOptionsValidTrueCondition(gen),
 			tlsConfigurationValidLoadedTrueCondition(gen),
+			timeoutsValidTrueCondition(gen),
 		}
 	}
 
@@ -288,17 +373,27 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 		}
 	}
 
+	validClientCertSecret := func(secretVersion string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: testClientCertSecret, Namespace: testNamespace, ResourceVersion: secretVersion},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": testClientCertPEM, "tls.key": testClientKeyPEM},
+		}
+	}
+
 	tests := []struct {
 		name                     string
 		initialValidatedSettings map[string]upstreamwatchers.ValidatedSettings
 		inputUpstreams           []runtime.Object
 		inputSecrets             []runtime.Object
+		inputConfigMaps          []runtime.Object
 		setupMocks               func(conn *mockldapconn.MockConn)
 		dialErrors               map[string]error
 		wantErr                  string
 		wantResultingCache       []*upstreamldap.ProviderConfig
 		wantResultingUpstreams   []v1alpha1.LDAPIdentityProvider
 		wantValidatedSettings    map[string]upstreamwatchers.ValidatedSettings
+		wantEvents               []string
 	}{
 		{
 			name:               "no LDAPIdentityProvider upstreams clears the cache",
@@ -329,6 +424,10 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				IDPSpecGeneration:         1234,
 				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
 			}},
+			wantEvents: []string{
+				"Normal Success condition BindSecretValid changed to True: " + bindSecretValidTrueCondition(1234).Message,
+				"Normal Success condition LDAPConnectionValid changed to True: " + ldapConnectionValidTrueCondition(1234, "4242").Message,
+			},
 		},
 		{
 			name:               "missing secret",
@@ -349,10 +448,16 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`secret "%s" not found`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
+			wantEvents: []string{
+				fmt.Sprintf(`Warning SecretNotFound condition BindSecretValid changed to False: secret "%s" not found`, testSecretName),
+			},
 		},
 		{
 			name:           "secret has wrong type",
@@ -377,7 +482,10 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" has wrong type "some-other-type" (should be "kubernetes.io/basic-auth")`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -404,10 +512,148 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" is missing required keys ["username" "password"]`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name:           "secret has only one of the secondary bind credentials keys",
+			inputUpstreams: []runtime.Object{validUpstream},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: testNamespace},
+				Type:       corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					"username":  []byte(testBindUsername),
+					"password":  []byte(testBindPassword),
+					"username2": []byte(testSecondaryBindUsername),
+				},
+			}},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "BindSecretValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretMissingKeys",
+							Message: fmt.Sprintf(`referenced Secret "%s" must contain either both or neither of the secondary bind credentials keys ["username2" "password2"]`,
+								testSecretName),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name:           "when binding with the primary bind credentials fails it uses the secondary bind credentials",
+			inputUpstreams: []runtime.Object{validUpstream},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: testNamespace, ResourceVersion: "4242"},
+				Type:       corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					"username":  []byte(testBindUsername),
+					"password":  []byte(testBindPassword),
+					"username2": []byte(testSecondaryBindUsername),
+					"password2": []byte(testSecondaryBindPassword),
+				},
+			}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind, falling back to the secondary bind credentials.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Return(errors.New("some bind error")).Times(1)
+				conn.EXPECT().Bind(testSecondaryBindUsername, testSecondaryBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:                  testName,
+					ResourceUID:           testResourceUID,
+					Host:                  testHost,
+					ConnectionProtocol:    upstreamldap.TLS,
+					CABundle:              testCABundle,
+					BindUsername:          testBindUsername,
+					BindPassword:          testBindPassword,
+					SecondaryBindUsername: testSecondaryBindUsername,
+					SecondaryBindPassword: testSecondaryBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "ActiveBindCredentials",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "UsingSecondaryBindCredentials",
+							Message: fmt.Sprintf(`binding as user "%s" using the secondary bind credentials from the ["username2" "password2"] keys of Secret "%s", `+
+								`because binding using the primary bind credentials failed`, testSecondaryBindUsername, testSecretName),
+							ObservedGeneration: 1234,
+						},
+						{
+							Type:               "BindSecretValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "loaded bind secret including secondary bind credentials",
+							ObservedGeneration: 1234,
+						},
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: fmt.Sprintf(
+								`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
+								testHost, testSecondaryBindUsername, testSecretName, "4242"),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion:     "4242",
+				LDAPConnectionProtocol:        upstreamldap.TLS,
+				UsingSecondaryBindCredentials: true,
+				UserSearchBase:                testUserSearchBase,
+				GroupSearchBase:               testGroupSearchBase,
+				IDPSpecGeneration:             1234,
+				ConnectionValidCondition: &v1alpha1.Condition{
+					Type:   "LDAPConnectionValid",
+					Status: "True",
+					Reason: "Success",
+					Message: fmt.Sprintf(
+						`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
+						testHost, testSecondaryBindUsername, testSecretName, "4242"),
+				},
+			}},
 		},
 		{
 			name: "CertificateAuthorityData is not base64 encoded",
@@ -423,6 +669,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
 						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -431,6 +679,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "certificateAuthorityData is invalid: illegal base64 data at input byte 4",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -449,6 +698,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
 						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -457,74 +708,122 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            "certificateAuthorityData is invalid: no certificates found",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
 		},
 		{
-			name: "nil TLS configuration is valid",
+			name: "CA bundle from a ConfigMap is valid",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Spec.TLS = nil
+				upstream.Spec.TLS = &v1alpha1.TLSSpec{
+					CertificateAuthorityDataSource: &v1alpha1.CertificateAuthorityDataSourceSpec{
+						Kind: v1alpha1.CertificateAuthorityDataSourceKindConfigMap,
+						Name: "test-ca-configmap",
+						Key:  "ca.crt",
+					},
+				}
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			inputConfigMaps: []runtime.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ca-configmap", Namespace: testNamespace, ResourceVersion: "9999"},
+				Data:       map[string]string{"ca.crt": string(testCABundle)},
+			}},
 			setupMocks: func(conn *mockldapconn.MockConn) {
 				// Should perform a test dial and bind.
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
 				conn.EXPECT().Close().Times(1)
 			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{
-				{
-					Name:               testName,
-					ResourceUID:        testResourceUID,
-					Host:               testHost,
-					ConnectionProtocol: upstreamldap.TLS,
-					CABundle:           nil,
-					BindUsername:       testBindUsername,
-					BindPassword:       testBindPassword,
-					UserSearch: upstreamldap.UserSearchConfig{
-						Base:              testUserSearchBase,
-						Filter:            testUserSearchFilter,
-						UsernameAttribute: testUsernameAttrName,
-						UIDAttribute:      testUIDAttrName,
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion:     "4242",
+				CABundleSourceResourceVersion: "9999",
+				LDAPConnectionProtocol:        upstreamldap.TLS,
+				UserSearchBase:                testUserSearchBase,
+				GroupSearchBase:               testGroupSearchBase,
+				IDPSpecGeneration:             1234,
+				ConnectionValidCondition:      condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "CA bundle Secret is not found",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.TLS = &v1alpha1.TLSSpec{
+					CertificateAuthorityDataSource: &v1alpha1.CertificateAuthorityDataSourceSpec{
+						Kind: v1alpha1.CertificateAuthorityDataSourceKindSecret,
+						Name: "missing-ca-secret",
+						Key:  "ca.crt",
 					},
-					GroupSearch: upstreamldap.GroupSearchConfig{
-						Base:               testGroupSearchBase,
-						Filter:             testGroupSearchFilter,
-						GroupNameAttribute: testGroupNameAttrName,
+				}
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTLSConfig",
+							Message:            `certificateAuthorityDataSource is invalid: failed to get secret "missing-ca-secret": secret "missing-ca-secret" not found`,
+							ObservedGeneration: 1234,
+						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
-			},
+			}},
+		},
+		{
+			name: "CertificateAuthorityData and CertificateAuthorityDataSource cannot both be specified",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.TLS.CertificateAuthorityDataSource = &v1alpha1.CertificateAuthorityDataSourceSpec{
+					Kind: v1alpha1.CertificateAuthorityDataSourceKindSecret,
+					Name: "test-ca-secret",
+					Key:  "ca.crt",
+				}
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
-					Phase: "Ready",
+					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
 						bindSecretValidTrueCondition(1234),
-						ldapConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
-							Status:             "True",
+							Status:             "False",
 							LastTransitionTime: now,
-							Reason:             "Success",
-							Message:            "no TLS configuration provided",
+							Reason:             "InvalidTLSConfig",
+							Message:            "certificateAuthorityData and certificateAuthorityDataSource cannot both be specified",
 							ObservedGeneration: 1234,
 						},
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
-			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-			}},
 		},
 		{
-			name: "when TLS connection fails it tries to use StartTLS instead: without a specified port it automatically switches ports",
+			name: "proxy URL is valid",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Spec.Host = "ldap.example.com" // when the port is not specified, automatically switch ports for StartTLS
+				upstream.Spec.ProxyURL = "socks5://proxy.example.com:1080"
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
 			setupMocks: func(conn *mockldapconn.MockConn) {
@@ -532,49 +831,193 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
 				conn.EXPECT().Close().Times(1)
 			},
-			dialErrors: map[string]error{
-				"ldap.example.com:" + ldap.DefaultLdapsPort: fmt.Errorf("some ldaps dial error"),
-				"ldap.example.com:" + ldap.DefaultLdapPort:  nil, // no error on the regular ldap:// port
-			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{
-				{
-					Name:               testName,
-					ResourceUID:        testResourceUID,
-					Host:               "ldap.example.com",
-					ConnectionProtocol: upstreamldap.StartTLS, // successfully fell back to using StartTLS
-					CABundle:           testCABundle,
-					BindUsername:       testBindUsername,
-					BindPassword:       testBindPassword,
-					UserSearch: upstreamldap.UserSearchConfig{
-						Base:              testUserSearchBase,
-						Filter:            testUserSearchFilter,
-						UsernameAttribute: testUsernameAttrName,
-						UIDAttribute:      testUIDAttrName,
-					},
-					GroupSearch: upstreamldap.GroupSearchConfig{
-						Base:               testGroupSearchBase,
-						Filter:             testGroupSearchFilter,
-						GroupNameAttribute: testGroupNameAttrName,
-					},
-				},
-			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{func() *upstreamldap.ProviderConfig {
+				config := *providerConfigForValidUpstreamWithTLS
+				config.ProxyURL = &url.URL{Scheme: "socks5", Host: "proxy.example.com:1080"}
+				return &config
+			}()},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
 						bindSecretValidTrueCondition(1234),
+						ldapConnectionValidTrueCondition(1234, "4242"),
 						{
-							Type:               "LDAPConnectionValid",
+							Type:               "ProxyURLValid",
 							Status:             "True",
 							LastTransitionTime: now,
 							Reason:             "Success",
-							Message: fmt.Sprintf(
+							Message:            "loaded proxy URL",
+							ObservedGeneration: 1234,
+						},
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "proxy URL has an unsupported scheme",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.ProxyURL = "https://proxy.example.com"
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "ProxyURLValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidProxyURL",
+							Message:            `proxyURL is not valid: scheme must be one of ["http" "socks5" "socks5h"]`,
+							ObservedGeneration: 1234,
+						},
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "nil TLS configuration is valid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.TLS = nil
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           nil,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						ldapConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "no TLS configuration provided",
+							ObservedGeneration: 1234,
+						},
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "when TLS connection fails it tries to use StartTLS instead: without a specified port it automatically switches ports",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Host = "ldap.example.com" // when the port is not specified, automatically switch ports for StartTLS
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			dialErrors: map[string]error{
+				"ldap.example.com:" + ldap.DefaultLdapsPort: fmt.Errorf("some ldaps dial error"),
+				"ldap.example.com:" + ldap.DefaultLdapPort:  nil, // no error on the regular ldap:// port
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               "ldap.example.com",
+					ConnectionProtocol: upstreamldap.StartTLS, // successfully fell back to using StartTLS
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: fmt.Sprintf(
 								`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
 								"ldap.example.com", testBindUsername, testSecretName, "4242"),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
@@ -635,6 +1078,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
 						bindSecretValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
@@ -646,30 +1090,37 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 								"ldap.example.com:5678", testBindUsername, "ldap.example.com:5678"),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{},
 		},
 		{
-			name: "non-nil TLS configuration with empty CertificateAuthorityData is valid",
+			name: "when the connection protocol is configured as ldaps and the TLS connection fails it does not try StartTLS",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Spec.TLS.CertificateAuthorityData = ""
+				upstream.Spec.Host = "ldap.example.com"
+				upstream.Spec.TLS.ConnectionProtocol = v1alpha1.LDAPConnectionProtocolLDAPS
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
 			setupMocks: func(conn *mockldapconn.MockConn) {
-				// Should perform a test dial and bind.
-				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
-				conn.EXPECT().Close().Times(1)
+				// The dial fails, so there should be no bind.
+			},
+			dialErrors: map[string]error{
+				"ldap.example.com:" + ldap.DefaultLdapsPort: fmt.Errorf("some ldaps dial error"),
+				"ldap.example.com:" + ldap.DefaultLdapPort:  nil, // would succeed, but should not be tried
 			},
 			wantResultingCache: []*upstreamldap.ProviderConfig{
+				// even though the connection test failed, still loads into the cache because it is treated like a warning
 				{
 					Name:               testName,
 					ResourceUID:        testResourceUID,
-					Host:               testHost,
+					Host:               "ldap.example.com",
 					ConnectionProtocol: upstreamldap.TLS,
-					CABundle:           nil,
+					CABundle:           testCABundle,
 					BindUsername:       testBindUsername,
 					BindPassword:       testBindPassword,
 					UserSearch: upstreamldap.UserSearchConfig{
@@ -685,90 +1136,13 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 					},
 				},
 			},
-			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
-				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
-				Status: v1alpha1.LDAPIdentityProviderStatus{
-					Phase:      "Ready",
-					Conditions: allConditionsTrue(1234, "4242"),
-				},
-			}},
-			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-			}},
-		},
-		{
-			name: "one valid upstream and one invalid upstream updates the cache to include only the valid upstream",
-			inputUpstreams: []runtime.Object{validUpstream, editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Name = "other-upstream"
-				upstream.Generation = 42
-				upstream.Spec.Bind.SecretName = "non-existent-secret"
-				upstream.UID = "other-uid"
-			})},
-			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
-			setupMocks: func(conn *mockldapconn.MockConn) {
-				// Should perform a test dial and bind for the one valid upstream configuration.
-				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
-				conn.EXPECT().Close().Times(1)
-			},
-			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
-			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{
-				{
-					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "other-upstream", Generation: 42, UID: "other-uid"},
-					Status: v1alpha1.LDAPIdentityProviderStatus{
-						Phase: "Error",
-						Conditions: []v1alpha1.Condition{
-							{
-								Type:               "BindSecretValid",
-								Status:             "False",
-								LastTransitionTime: now,
-								Reason:             "SecretNotFound",
-								Message:            fmt.Sprintf(`secret "%s" not found`, "non-existent-secret"),
-								ObservedGeneration: 42,
-							},
-							tlsConfigurationValidLoadedTrueCondition(42),
-						},
-					},
-				},
-				{
-					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
-					Status: v1alpha1.LDAPIdentityProviderStatus{
-						Phase:      "Ready",
-						Conditions: allConditionsTrue(1234, "4242"),
-					},
-				},
-			},
-			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-			}},
-		},
-		{
-			name:           "when testing the connection to the LDAP server fails then the upstream is still added to the cache anyway (treated like a warning) but not the validated settings cache",
-			inputUpstreams: []runtime.Object{validUpstream},
-			inputSecrets:   []runtime.Object{validBindUserSecret("")},
-			setupMocks: func(conn *mockldapconn.MockConn) {
-				// Should perform a test dial and bind.
-				// Expect two calls to each of these: once for trying TLS and once for trying StartTLS.
-				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(2).Return(errors.New("some bind error"))
-				conn.EXPECT().Close().Times(2)
-			},
-			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
 					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
 						bindSecretValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
@@ -776,36 +1150,668 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							LastTransitionTime: now,
 							Reason:             "LDAPConnectionError",
 							Message: fmt.Sprintf(
-								`could not successfully connect to "%s" and bind as user "%s": error binding as "%s": some bind error`,
-								testHost, testBindUsername, testBindUsername),
+								`could not successfully connect to "%s" using TLS and bind as user "%s" `+
+									`(other connection protocols were not tried because connectionProtocol is configured): error dialing host "%s": some ldaps dial error`,
+								"ldap.example.com", testBindUsername, "ldap.example.com:"+ldap.DefaultLdapsPort),
 							ObservedGeneration: 1234,
 						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{},
 		},
 		{
-			name: "when the LDAP server connection was already validated using TLS for the current resource generation and secret version, then do not validate it again and keep using TLS",
+			name: "when the connection protocol is configured as startTLS it does not try TLS first",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					ldapConnectionValidTrueCondition(1234, "4242"),
-				}
+				upstream.Spec.Host = "ldap.example.com"
+				upstream.Spec.TLS.ConnectionProtocol = v1alpha1.LDAPConnectionProtocolStartTLS
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
-			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{
-				testName: {BindSecretResourceVersion: "4242",
-					LDAPConnectionProtocol:   upstreamldap.TLS,
-					UserSearchBase:           testUserSearchBase,
-					GroupSearchBase:          testGroupSearchBase,
-					IDPSpecGeneration:        1234,
-					ConnectionValidCondition: condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-				}},
 			setupMocks: func(conn *mockldapconn.MockConn) {
-				// Should not perform a test dial and bind. No mocking here means the test will fail if Bind() or Close() are called.
-			},
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			dialErrors: map[string]error{
+				"ldap.example.com:" + ldap.DefaultLdapsPort: nil, // would succeed, but should not be tried
+				"ldap.example.com:" + ldap.DefaultLdapPort:  nil,
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               "ldap.example.com",
+					ConnectionProtocol: upstreamldap.StartTLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: fmt.Sprintf(
+								`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
+								"ldap.example.com", testBindUsername, testSecretName, "4242"),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.StartTLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition: &v1alpha1.Condition{
+					Type:   "LDAPConnectionValid",
+					Status: "True",
+					Reason: "Success",
+					Message: fmt.Sprintf(
+						`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
+						"ldap.example.com", testBindUsername, testSecretName, "4242"),
+				},
+			}},
+		},
+		{
+			name: "invalid connection protocol",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.TLS.ConnectionProtocol = "bogus"
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTLSConfig",
+							Message:            `connectionProtocol "bogus" is not valid`,
+							ObservedGeneration: 1234,
+						},
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "when the host cannot be reached it fails over to the next host",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Hosts = []string{"ldap-replica1.example.com:123", "ldap-replica2.example.com:123"}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind on the first replica.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			dialErrors: map[string]error{
+				testHost: fmt.Errorf("some dial error"),
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					FailoverHosts:      []string{"ldap-replica1.example.com:123", "ldap-replica2.example.com:123"},
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message: fmt.Sprintf(
+								`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
+								"ldap-replica1.example.com:123", testBindUsername, testSecretName, "4242"),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition: &v1alpha1.Condition{
+					Type:   "LDAPConnectionValid",
+					Status: "True",
+					Reason: "Success",
+					Message: fmt.Sprintf(
+						`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
+						"ldap-replica1.example.com:123", testBindUsername, testSecretName, "4242"),
+				},
+			}},
+		},
+		{
+			name: "when none of the hosts can be reached the connection condition describes each failure",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Hosts = []string{"ldap-replica.example.com:123"}
+				upstream.Spec.TLS.ConnectionProtocol = v1alpha1.LDAPConnectionProtocolLDAPS
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// All dials fail, so there should be no bind.
+			},
+			dialErrors: map[string]error{
+				testHost:                       fmt.Errorf("some dial error"),
+				"ldap-replica.example.com:123": fmt.Errorf("some other dial error"),
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				// even though the connection test failed, still loads into the cache because it is treated like a warning
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					FailoverHosts:      []string{"ldap-replica.example.com:123"},
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "LDAPConnectionError",
+							Message: fmt.Sprintf(
+								`could not successfully connect to any of ["%s" "%s"] using TLS and bind as user "%s" `+
+									`(other connection protocols were not tried because connectionProtocol is configured): `+
+									`[error dialing host "%s": some dial error, error dialing host "%s": some other dial error]`,
+								testHost, "ldap-replica.example.com:123", testBindUsername, testHost, "ldap-replica.example.com:123"),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{},
+		},
+		{
+			name: "non-nil TLS configuration with empty CertificateAuthorityData is valid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.TLS.CertificateAuthorityData = ""
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           nil,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "one valid upstream and one invalid upstream updates the cache to include only the valid upstream",
+			inputUpstreams: []runtime.Object{validUpstream, editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Name = "other-upstream"
+				upstream.Generation = 42
+				upstream.Spec.Bind.SecretName = "non-existent-secret"
+				upstream.UID = "other-uid"
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind for the one valid upstream configuration.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "other-upstream", Generation: 42, UID: "other-uid"},
+					Status: v1alpha1.LDAPIdentityProviderStatus{
+						Phase: "Error",
+						Conditions: []v1alpha1.Condition{
+							{
+								Type:               "BindSecretValid",
+								Status:             "False",
+								LastTransitionTime: now,
+								Reason:             "SecretNotFound",
+								Message:            fmt.Sprintf(`secret "%s" not found`, "non-existent-secret"),
+								ObservedGeneration: 42,
+							},
+							proxyURLValidTrueCondition(42),
+							searchOptionsValidTrueCondition(42),
+							tlsConfigurationValidLoadedTrueCondition(42),
+							timeoutsValidTrueCondition(42),
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+					Status: v1alpha1.LDAPIdentityProviderStatus{
+						Phase:      "Ready",
+						Conditions: allConditionsTrue(1234, "4242"),
+					},
+				},
+			},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name:           "when testing the connection to the LDAP server fails then the upstream is still added to the cache anyway (treated like a warning) but not the validated settings cache",
+			inputUpstreams: []runtime.Object{validUpstream},
+			inputSecrets:   []runtime.Object{validBindUserSecret("")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				// Expect two calls to each of these: once for trying TLS and once for trying StartTLS.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(2).Return(errors.New("some bind error"))
+				conn.EXPECT().Close().Times(2)
+			},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsUnknownCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "LDAPConnectionError",
+							Message: fmt.Sprintf(
+								`could not successfully connect to "%s" and bind as user "%s": error binding as "%s": some bind error`,
+								testHost, testBindUsername, testBindUsername),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{},
+		},
+		{
+			name: "when the LDAP server connection was already validated using TLS for the current resource generation and secret version, then do not validate it again and keep using TLS",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					ldapConnectionValidTrueCondition(1234, "4242"),
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{
+				testName: {BindSecretResourceVersion: "4242",
+					LDAPConnectionProtocol:   upstreamldap.TLS,
+					UserSearchBase:           testUserSearchBase,
+					GroupSearchBase:          testGroupSearchBase,
+					IDPSpecGeneration:        1234,
+					ConnectionValidCondition: condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should not perform a test dial and bind. No mocking here means the test will fail if Bind() or Close() are called.
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+			wantEvents: []string{
+				// The LDAPConnectionValid condition did not transition, so no Event is recorded for it.
+				"Normal Success condition BindSecretValid changed to True: " + bindSecretValidTrueCondition(1234).Message,
+			},
+		},
+		{
+			name: "when the LDAP server connection was already validated using StartTLS for the current resource generation and secret version, then do not validate it again and keep using StartTLS",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					ldapConnectionValidTrueCondition(1234, "4242"),
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.StartTLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should not perform a test dial and bind. No mocking here means the test will fail if Bind() or Close() are called.
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithStartTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.StartTLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "when the LDAP server connection was validated for an older resource generation, then try to validate it again",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234 // current generation
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					ldapConnectionValidTrueCondition(1233, "4242"), // older spec generation!
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				IDPSpecGeneration:         1233,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+			}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "when the LDAP server connection condition failed to update previously, then write the cached condition from the previous connection validation",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234 // current generation
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					ldapConnectionValidTrueCondition(1234, "4200"), // old version of the condition, as if the previous update of conditions had failed
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				IDPSpecGeneration:         1234,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")), // already previously validated with version 4242
+			}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// The connection had already been validated previously and the result was cached, so don't probe the server again.
+				// Should not perform a test dial and bind. No mocking here means the test will fail if Bind() or Close() are called.
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"), // updated version of the condition using the cached condition value
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "when the LDAP server connection validation previously failed for this resource generation, then try to validate it again",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					{
+						Type:               "LDAPConnectionValid",
+						Status:             "False", // failure!
+						LastTransitionTime: now,
+						Reason:             "LDAPConnectionError",
+						Message:            "some-error-message",
+						ObservedGeneration: 1234, // same (current) generation!
+					},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "when the validated settings cache is incomplete, then try to validate it again",
+			// this shouldn't happen, but if it does, just throw it out and try again.
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					{
+						Type:               "LDAPConnectionValid",
+						Status:             "False", // failure!
+						LastTransitionTime: now,
+						Reason:             "LDAPConnectionError",
+						Message:            "some-error-message",
+						ObservedGeneration: 1234, // same (current) generation!
+					},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+			}},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "when the LDAP server connection was already validated for this resource generation but the bind secret has changed, then try to validate it again",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Generation = 1234
+				upstream.Status.Conditions = []v1alpha1.Condition{
+					ldapConnectionValidTrueCondition(1234, "4241"), // same spec generation, old secret version
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")}, // newer secret version!
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4241",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+			}}, // old version was validated
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
 			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
@@ -814,6 +1820,334 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 					Conditions: allConditionsTrue(1234, "4242"),
 				},
 			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}}},
+		{
+			name: "skipping group refresh is valid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.GroupSearch.SkipGroupRefresh = true
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						SkipGroupRefresh:   true,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						bindSecretValidTrueCondition(1234),
+						ldapConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "loaded TLS configuration",
+							ObservedGeneration: 1234,
+						},
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "timeouts are configured",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.DialTimeoutSeconds = 5
+				upstream.Spec.SearchTimeoutSeconds = 120
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					DialTimeout:   5 * time.Second,
+					SearchTimeout: 2 * time.Minute,
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "group search page size is configured",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.GroupSearch.PageSize = 50
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						PageSize:           50,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "refresh attribute assertions are configured",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.RefreshChecks.AttributeAssertions = []v1alpha1.LDAPIdentityProviderAttributeAssertion{
+					{Attribute: "nsAccountLock", Operator: v1alpha1.LDAPAttributeAssertionOperatorNotEquals, Value: "true"},
+					{Attribute: "shadowExpire", Operator: v1alpha1.LDAPAttributeAssertionOperatorDaysSinceEpochInFuture},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					RefreshAttributeAssertions: []upstreamldap.AttributeAssertion{
+						{Attribute: "nsAccountLock", Operator: upstreamldap.AttributeAssertionNotEquals, Value: "true"},
+						{Attribute: "shadowExpire", Operator: upstreamldap.AttributeAssertionDaysSinceEpochInFuture},
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "group refresh interval is configured",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.GroupSearch.GroupRefreshIntervalSeconds = 600
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						RefreshInterval:    10 * time.Minute,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "binary uid and group name attributes are configured with decoders",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.UserSearch.Attributes.UIDBinaryDecoder = v1alpha1.LDAPBinaryAttributeDecoderUUID
+				upstream.Spec.GroupSearch.Attributes.GroupNameBinaryDecoder = v1alpha1.LDAPBinaryAttributeDecoderHex
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){
+						testUIDAttrName: upstreamldap.BinaryAttributeParser(testUIDAttrName, upstreamldap.BinaryAttributeDecoderUUID),
+					},
+					GroupAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){
+						testGroupNameAttrName: upstreamldap.BinaryAttributeParser(testGroupNameAttrName, upstreamldap.BinaryAttributeDecoderHex),
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
 				BindSecretResourceVersion: "4242",
 				LDAPConnectionProtocol:    upstreamldap.TLS,
@@ -824,26 +2158,40 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			}},
 		},
 		{
-			name: "when the LDAP server connection was already validated using StartTLS for the current resource generation and secret version, then do not validate it again and keep using StartTLS",
+			name: "group search is configured to use a user attribute in the filter",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					ldapConnectionValidTrueCondition(1234, "4242"),
-				}
+				upstream.Spec.GroupSearch.Filter = "&(objectClass=posixGroup)(memberUid={})"
+				upstream.Spec.GroupSearch.UserAttributeForFilter = "uid"
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
-			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.StartTLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-			}},
 			setupMocks: func(conn *mockldapconn.MockConn) {
-				// Should not perform a test dial and bind. No mocking here means the test will fail if Bind() or Close() are called.
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:                   testGroupSearchBase,
+						Filter:                 "&(objectClass=posixGroup)(memberUid={})",
+						UserAttributeForFilter: "uid",
+						GroupNameAttribute:     testGroupNameAttrName,
+					},
+				},
 			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithStartTLS},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
@@ -853,7 +2201,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
 				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.StartTLS,
+				LDAPConnectionProtocol:    upstreamldap.TLS,
 				UserSearchBase:            testUserSearchBase,
 				GroupSearchBase:           testGroupSearchBase,
 				IDPSpecGeneration:         1234,
@@ -861,27 +2209,47 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			}},
 		},
 		{
-			name: "when the LDAP server connection was validated for an older resource generation, then try to validate it again",
+			name: "referral following is configured",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234 // current generation
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					ldapConnectionValidTrueCondition(1233, "4242"), // older spec generation!
+				upstream.Spec.Referrals = v1alpha1.LDAPIdentityProviderReferrals{
+					Follow:           true,
+					MaxHops:          2,
+					CredentialPolicy: v1alpha1.ReferralCredentialPolicyAnonymous,
 				}
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
-			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				IDPSpecGeneration:         1233,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-			}},
 			setupMocks: func(conn *mockldapconn.MockConn) {
 				// Should perform a test dial and bind.
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
 				conn.EXPECT().Close().Times(1)
 			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					Referrals: upstreamldap.ReferralConfig{
+						Follow:    true,
+						MaxHops:   2,
+						Anonymous: true,
+					},
+				},
+			},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
@@ -899,32 +2267,102 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			}},
 		},
 		{
-			name: "when the LDAP server connection condition failed to update previously, then write the cached condition from the previous connection validation",
+			name: "attribute mappings for additional claims are configured",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234 // current generation
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					ldapConnectionValidTrueCondition(1234, "4200"), // old version of the condition, as if the previous update of conditions had failed
+				upstream.Spec.AttributeMappings = v1alpha1.LDAPIdentityProviderAttributeMappings{
+					AdditionalClaims: map[string]string{"email": "mail", "department": "department"},
 				}
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
-			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+					AdditionalClaimMappings: map[string]string{"email": "mail", "department": "department"},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
 				BindSecretResourceVersion: "4242",
 				LDAPConnectionProtocol:    upstreamldap.TLS,
-				IDPSpecGeneration:         1234,
 				UserSearchBase:            testUserSearchBase,
 				GroupSearchBase:           testGroupSearchBase,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")), // already previously validated with version 4242
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
 			}},
+		},
+		{
+			name: "search scope and alias dereferencing are configured",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.UserSearch.Scope = v1alpha1.LDAPSearchScopeSingleLevel
+				upstream.Spec.UserSearch.DerefAliases = v1alpha1.LDAPDerefAliasesAlways
+				upstream.Spec.GroupSearch.Scope = v1alpha1.LDAPSearchScopeBaseObject
+				upstream.Spec.GroupSearch.DerefAliases = v1alpha1.LDAPDerefAliasesFindingBaseObject
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
 			setupMocks: func(conn *mockldapconn.MockConn) {
-				// The connection had already been validated previously and the result was cached, so don't probe the server again.
-				// Should not perform a test dial and bind. No mocking here means the test will fail if Bind() or Close() are called.
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+						Scope:             upstreamldap.ScopeSingleLevel,
+						DerefAliases:      ldap.DerefAlways,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						Scope:              upstreamldap.ScopeBaseObject,
+						DerefAliases:       ldap.DerefFindingBaseObj,
+					},
+				},
 			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase:      "Ready",
-					Conditions: allConditionsTrue(1234, "4242"), // updated version of the condition using the cached condition value
+					Conditions: allConditionsTrue(1234, "4242"),
 				},
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
@@ -937,18 +2375,138 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			}},
 		},
 		{
-			name: "when the LDAP server connection validation previously failed for this resource generation, then try to validate it again",
+			name: "user DN template without a bind secret is valid",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					{
-						Type:               "LDAPConnectionValid",
-						Status:             "False", // failure!
-						LastTransitionTime: now,
-						Reason:             "LDAPConnectionError",
-						Message:            "some-error-message",
-						ObservedGeneration: 1234, // same (current) generation!
+				upstream.Spec.Bind.SecretName = ""
+				upstream.Spec.Bind.UserDNTemplate = "uid={username},ou=users,dc=pinniped,dc=dev"
+			})},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial, but no bind since there are no bind credentials.
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{func() *upstreamldap.ProviderConfig {
+				config := *providerConfigForValidUpstreamWithTLS
+				config.BindUsername = ""
+				config.BindPassword = ""
+				config.UserDNTemplate = "uid={username},ou=users,dc=pinniped,dc=dev"
+				return &config
+			}()},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "ActiveBindCredentials",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "NoBindCredentials",
+							Message:            "no bind secret is configured, so logins bind directly as the end user",
+							ObservedGeneration: 1234,
+						},
+						{
+							Type:               "BindSecretValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "no bind secret is configured, so logins will bind directly as the end user",
+							ObservedGeneration: 1234,
+						},
+						{
+							Type:               "LDAPConnectionValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            fmt.Sprintf(`successfully able to connect to "%s" (binding was not tested because no bind secret is configured)`, testHost),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				LDAPConnectionProtocol: upstreamldap.TLS,
+				UserSearchBase:         testUserSearchBase,
+				GroupSearchBase:        testGroupSearchBase,
+				IDPSpecGeneration:      1234,
+				ConnectionValidCondition: &v1alpha1.Condition{
+					Type:    "LDAPConnectionValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: fmt.Sprintf(`successfully able to connect to "%s" (binding was not tested because no bind secret is configured)`, testHost),
+				},
+			}},
+		},
+		{
+			name: "user DN template without the username placeholder is reported",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Bind.UserDNTemplate = "uid=pinny,ou=users,dc=pinniped,dc=dev"
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						{
+							Type:               "SearchOptionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidSearchOptions",
+							Message:            `bind.userDNTemplate must contain "{username}"`,
+							ObservedGeneration: 1234,
+						},
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid search scope and alias dereferencing are reported",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.UserSearch.Scope = "Everything"
+				upstream.Spec.GroupSearch.DerefAliases = "Sometimes"
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						{
+							Type:               "SearchOptionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidSearchOptions",
+							Message:            `userSearch.scope "Everything" is not valid; groupSearch.derefAliases "Sometimes" is not valid`,
+							ObservedGeneration: 1234,
+						},
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
+				},
+			}},
+		},
+		{
+			name: "group name transformations are configured",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.GroupSearch.GroupNameTransformations = []v1alpha1.GroupNameTransformation{
+					{Type: v1alpha1.GroupNameTransformationTypeStripDNComponents},
+					{Type: v1alpha1.GroupNameTransformationTypeRegexReplace, Regex: `^team-(\w+)$`, Replacement: "${1}"},
+					{Type: v1alpha1.GroupNameTransformationTypeLowercase},
+					{Type: v1alpha1.GroupNameTransformationTypePrefix, Prefix: "ldap:"},
 				}
 			})},
 			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
@@ -957,7 +2515,34 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
 				conn.EXPECT().Close().Times(1)
 			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						GroupNameTransformations: []upstreamldap.GroupNameTransformation{
+							{Type: upstreamldap.GroupNameTransformationStripDNComponents},
+							{Type: upstreamldap.GroupNameTransformationRegexReplace, Regex: regexp.MustCompile(`^team-(\w+)$`), Replacement: "${1}"},
+							{Type: upstreamldap.GroupNameTransformationLowercase},
+							{Type: upstreamldap.GroupNameTransformationPrefix, Prefix: "ldap:"},
+						},
+					},
+				},
+			},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
@@ -975,91 +2560,124 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			}},
 		},
 		{
-			name: "when the validated settings cache is incomplete, then try to validate it again",
-			// this shouldn't happen, but if it does, just throw it out and try again.
+			name: "invalid group name transformations are reported",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					{
-						Type:               "LDAPConnectionValid",
-						Status:             "False", // failure!
-						LastTransitionTime: now,
-						Reason:             "LDAPConnectionError",
-						Message:            "some-error-message",
-						ObservedGeneration: 1234, // same (current) generation!
-					},
+				upstream.Spec.GroupSearch.GroupNameTransformations = []v1alpha1.GroupNameTransformation{
+					{Type: v1alpha1.GroupNameTransformationTypePrefix},
+					{Type: v1alpha1.GroupNameTransformationTypeRegexReplace, Regex: "("},
+					{Type: v1alpha1.GroupNameTransformationTypeLowercase, Prefix: "ldap:"},
+					{Type: "Uppercase"},
 				}
 			})},
-			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
-			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-			}},
-			setupMocks: func(conn *mockldapconn.MockConn) {
-				// Should perform a test dial and bind.
-				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
-				conn.EXPECT().Close().Times(1)
-			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
-					Phase:      "Ready",
-					Conditions: allConditionsTrue(1234, "4242"),
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						{
+							Type:               "SearchOptionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidSearchOptions",
+							Message: `groupSearch.groupNameTransformations[0].prefix is required when type is "Prefix"; ` +
+								`groupSearch.groupNameTransformations[1].regex "(" is not valid: error parsing regexp: missing closing ): ` + "`(`; " +
+								`groupSearch.groupNameTransformations[2].prefix is not allowed when type is "Lowercase"; ` +
+								`groupSearch.groupNameTransformations[3].type "Uppercase" is not valid`,
+							ObservedGeneration: 1234,
+						},
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
 				},
 			}},
-			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-			}},
 		},
 		{
-			name: "when the LDAP server connection was already validated for this resource generation but the bind secret has changed, then try to validate it again",
+			name: "a client certificate is configured for the bind",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
-					ldapConnectionValidTrueCondition(1234, "4241"), // same spec generation, old secret version
-				}
+				upstream.Spec.Bind.ClientCertificateSecretName = testClientCertSecret
 			})},
-			inputSecrets: []runtime.Object{validBindUserSecret("4242")}, // newer secret version!
-			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4241",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-			}}, // old version was validated
+			inputSecrets: []runtime.Object{validBindUserSecret("4242"), validClientCertSecret("5353")},
 			setupMocks: func(conn *mockldapconn.MockConn) {
 				// Should perform a test dial and bind.
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
 				conn.EXPECT().Close().Times(1)
 			},
-			wantResultingCache: []*upstreamldap.ProviderConfig{providerConfigForValidUpstreamWithTLS},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:                  testName,
+					ResourceUID:           testResourceUID,
+					Host:                  testHost,
+					ConnectionProtocol:    upstreamldap.TLS,
+					CABundle:              testCABundle,
+					ClientCertificateData: testClientCertPEM,
+					ClientKeyData:         testClientKeyPEM,
+					BindUsername:          testBindUsername,
+					BindPassword:          testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+					},
+				},
+			},
 			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
-					Phase:      "Ready",
-					Conditions: allConditionsTrue(1234, "4242"),
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						activeBindCredentialsPrimaryCondition(1234),
+						{
+							Type:               "BindSecretValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "loaded bind secret and client certificate",
+							ObservedGeneration: 1234,
+						},
+						ldapConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
 				},
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
-			}}},
+				BindSecretResourceVersion:              "4242",
+				ClientCertificateSecretResourceVersion: "5353",
+				LDAPConnectionProtocol:                 upstreamldap.TLS,
+				UserSearchBase:                         testUserSearchBase,
+				GroupSearchBase:                        testGroupSearchBase,
+				IDPSpecGeneration:                      1234,
+				ConnectionValidCondition:               condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
 		{
-			name: "skipping group refresh is valid",
+			name: "when the client certificate secret has changed since the connection was validated, then try to validate it again",
+			initialValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion:              "4242",
+				ClientCertificateSecretResourceVersion: "1111", // old version
+				LDAPConnectionProtocol:                 upstreamldap.TLS,
+				UserSearchBase:                         testUserSearchBase,
+				GroupSearchBase:                        testGroupSearchBase,
+				IDPSpecGeneration:                      1234,
+				ConnectionValidCondition:               condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
-				upstream.Spec.GroupSearch.SkipGroupRefresh = true
+				upstream.Spec.Bind.ClientCertificateSecretName = testClientCertSecret
 			})},
-			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242"), validClientCertSecret("5353")},
 			setupMocks: func(conn *mockldapconn.MockConn) {
 				// Should perform a test dial and bind.
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
@@ -1067,13 +2685,15 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			},
 			wantResultingCache: []*upstreamldap.ProviderConfig{
 				{
-					Name:               testName,
-					ResourceUID:        testResourceUID,
-					Host:               testHost,
-					ConnectionProtocol: upstreamldap.TLS,
-					CABundle:           testCABundle,
-					BindUsername:       testBindUsername,
-					BindPassword:       testBindPassword,
+					Name:                  testName,
+					ResourceUID:           testResourceUID,
+					Host:                  testHost,
+					ConnectionProtocol:    upstreamldap.TLS,
+					CABundle:              testCABundle,
+					ClientCertificateData: testClientCertPEM,
+					ClientKeyData:         testClientKeyPEM,
+					BindUsername:          testBindUsername,
+					BindPassword:          testBindPassword,
 					UserSearch: upstreamldap.UserSearchConfig{
 						Base:              testUserSearchBase,
 						Filter:            testUserSearchFilter,
@@ -1084,7 +2704,6 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 						Base:               testGroupSearchBase,
 						Filter:             testGroupSearchFilter,
 						GroupNameAttribute: testGroupNameAttrName,
-						SkipGroupRefresh:   true,
 					},
 				},
 			},
@@ -1093,26 +2712,156 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Ready",
 					Conditions: []v1alpha1.Condition{
-						bindSecretValidTrueCondition(1234),
-						ldapConnectionValidTrueCondition(1234, "4242"),
+						activeBindCredentialsPrimaryCondition(1234),
 						{
-							Type:               "TLSConfigurationValid",
+							Type:               "BindSecretValid",
 							Status:             "True",
 							LastTransitionTime: now,
 							Reason:             "Success",
-							Message:            "loaded TLS configuration",
+							Message:            "loaded bind secret and client certificate",
 							ObservedGeneration: 1234,
 						},
+						ldapConnectionValidTrueCondition(1234, "4242"),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
 					},
 				},
 			}},
 			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
-				BindSecretResourceVersion: "4242",
-				LDAPConnectionProtocol:    upstreamldap.TLS,
-				UserSearchBase:            testUserSearchBase,
-				GroupSearchBase:           testGroupSearchBase,
-				IDPSpecGeneration:         1234,
-				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				BindSecretResourceVersion:              "4242",
+				ClientCertificateSecretResourceVersion: "5353",
+				LDAPConnectionProtocol:                 upstreamldap.TLS,
+				UserSearchBase:                         testUserSearchBase,
+				GroupSearchBase:                        testGroupSearchBase,
+				IDPSpecGeneration:                      1234,
+				ConnectionValidCondition:               condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "client certificate secret is missing",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Bind.ClientCertificateSecretName = testClientCertSecret
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "BindSecretValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretNotFound",
+							Message:            fmt.Sprintf(`client certificate secret "%s" not found`, testClientCertSecret),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "client certificate secret has wrong type",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Bind.ClientCertificateSecretName = testClientCertSecret
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242"), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: testClientCertSecret, Namespace: testNamespace},
+				Type:       "some-other-type",
+				Data:       map[string][]byte{"tls.crt": testClientCertPEM, "tls.key": testClientKeyPEM},
+			}},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "BindSecretValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretWrongType",
+							Message:            fmt.Sprintf(`referenced client certificate Secret "%s" has wrong type "some-other-type" (should be "kubernetes.io/tls")`, testClientCertSecret),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "client certificate secret does not contain a valid certificate and key",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.Bind.ClientCertificateSecretName = testClientCertSecret
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242"), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: testClientCertSecret, Namespace: testNamespace},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{"tls.crt": []byte("not a cert"), "tls.key": testClientKeyPEM},
+			}},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "BindSecretValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidClientCertificate",
+							Message:            fmt.Sprintf(`referenced client certificate Secret "%s" is invalid: tls: failed to find any PEM data in certificate input`, testClientCertSecret),
+							ObservedGeneration: 1234,
+						},
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						timeoutsValidTrueCondition(1234),
+					},
+				},
+			}},
+		},
+		{
+			name: "negative timeouts are invalid",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.DialTimeoutSeconds = -1
+				upstream.Spec.SearchTimeoutSeconds = -2
+			})},
+			inputSecrets:       []runtime.Object{validBindUserSecret("4242")},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						bindSecretValidTrueCondition(1234),
+						proxyURLValidTrueCondition(1234),
+						searchOptionsValidTrueCondition(1234),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+						{
+							Type:               "TimeoutsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTimeouts",
+							Message:            "dialTimeoutSeconds must not be negative, but was -1; searchTimeoutSeconds must not be negative, but was -2",
+							ObservedGeneration: 1234,
+						},
+					},
+				},
 			}},
 		},
 	}
@@ -1124,7 +2873,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 
 			fakePinnipedClient := pinnipedfake.NewSimpleClientset(tt.inputUpstreams...)
 			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
-			fakeKubeClient := fake.NewSimpleClientset(tt.inputSecrets...)
+			fakeKubeClient := fake.NewSimpleClientset(append(tt.inputSecrets, tt.inputConfigMaps...)...)
 			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
 			cache := provider.NewDynamicUpstreamIDPProvider()
 			cache.SetLDAPIdentityProviders([]provider.UpstreamLDAPIdentityProviderI{
@@ -1160,6 +2909,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				}
 			}
 
+			eventRecorder := events.NewFakeRecorder(100)
+
 			controller := newInternal(
 				cache,
 				validatedSettingsCache,
@@ -1167,6 +2918,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				fakePinnipedClient,
 				pinnipedInformers.IDP().V1alpha1().LDAPIdentityProviders(),
 				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
 				controllerlib.WithInformer,
 			)
 
@@ -1193,7 +2946,17 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				// The dialer that was passed in to the controller's constructor should always have been
 				// passed through to the provider.
 				copyOfExpectedValueForResultingCache.Dialer = dialer
-				require.Equal(t, copyOfExpectedValueForResultingCache, actualIDP.GetConfig())
+
+				// function equality is awkward. Do the check for equality separately from the rest of the config.
+				actualConfig := actualIDP.GetConfig()
+				requireSameParsingOverrides(t, copyOfExpectedValueForResultingCache.UIDAttributeParsingOverrides, actualConfig.UIDAttributeParsingOverrides)
+				requireSameParsingOverrides(t, copyOfExpectedValueForResultingCache.GroupAttributeParsingOverrides, actualConfig.GroupAttributeParsingOverrides)
+				copyOfExpectedValueForResultingCache.UIDAttributeParsingOverrides = nil
+				copyOfExpectedValueForResultingCache.GroupAttributeParsingOverrides = nil
+				actualConfig.UIDAttributeParsingOverrides = nil
+				actualConfig.GroupAttributeParsingOverrides = nil
+
+				require.Equal(t, copyOfExpectedValueForResultingCache, actualConfig)
 			}
 
 			actualUpstreams, err := fakePinnipedClient.IDPV1alpha1().LDAPIdentityProviders(testNamespace).List(ctx, metav1.ListOptions{})
@@ -1212,10 +2975,27 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				tt.wantValidatedSettings = map[string]upstreamwatchers.ValidatedSettings{}
 			}
 			require.Equal(t, tt.wantValidatedSettings, validatedSettingsCache.ValidatedSettingsByName)
+
+			// Check the Events which were recorded for condition transitions, when the test cares about them.
+			if tt.wantEvents != nil {
+				require.Equal(t, tt.wantEvents, recordedEvents(eventRecorder))
+			}
 		})
 	}
 }
 
+func recordedEvents(eventRecorder *events.FakeRecorder) []string {
+	recorded := []string{}
+	for {
+		select {
+		case event := <-eventRecorder.Events:
+			recorded = append(recorded, event)
+		default:
+			return recorded
+		}
+	}
+}
+
 func normalizeLDAPUpstreams(upstreams []v1alpha1.LDAPIdentityProvider, now metav1.Time) []v1alpha1.LDAPIdentityProvider {
 	result := make([]v1alpha1.LDAPIdentityProvider, 0, len(upstreams))
 	for _, u := range upstreams {
@@ -1240,3 +3020,20 @@ func normalizeLDAPUpstreams(upstreams []v1alpha1.LDAPIdentityProvider, now metav
 
 	return result
 }
+
+// requireSameParsingOverrides compares the parsing overrides by their output, since the parsers are closures
+// which cannot be compared directly.
+func requireSameParsingOverrides(t *testing.T, expected, actual map[string]func(*ldap.Entry) (string, error)) {
+	t.Helper()
+	require.Equal(t, len(expected), len(actual))
+	for k, v := range expected {
+		require.NotNil(t, actual[k])
+		entry := &ldap.Entry{Attributes: []*ldap.EntryAttribute{
+			ldap.NewEntryAttribute(k, []string{"\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f\x10"}),
+		}}
+		wantDecoded, wantErr := v(entry)
+		actualDecoded, actualErr := actual[k](entry)
+		require.Equal(t, wantDecoded, actualDecoded)
+		require.Equal(t, wantErr, actualErr)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/events"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	loginPageBrandingInvalidReason = "LoginPageBrandingInvalid"
+	loginPageBrandingEventAction   = "ValidateLoginPageBranding"
+)
+
+type loginPageBrandingObserverController struct {
+	brandingCache     *branding.Cache
+	configMapName     string
+	namespace         string
+	configMapInformer corev1informers.ConfigMapInformer
+	eventRecorder     events.EventRecorder
+
+	// The resourceVersion of the last invalid ConfigMap which was reported by an Event, so that resyncs of the same
+	// invalid ConfigMap do not report it again.
+	lastReportedResourceVersion string
+}
+
+// NewLoginPageBrandingObserverController returns a controller which validates the branding bundle in the named
+// ConfigMap and loads it into the brandingCache, so that changes to the ConfigMap are applied to the login pages
+// without a restart. When the ConfigMap is deleted, the login pages become unbranded. When the ConfigMap becomes
+// invalid, the previous valid branding stays in use and a Warning Event is recorded regarding the ConfigMap.
+func NewLoginPageBrandingObserverController(
+	brandingCache *branding.Cache,
+	configMapName string,
+	namespace string,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.EventRecorder,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "login-page-branding-observer-controller",
+			Syncer: &loginPageBrandingObserverController{
+				brandingCache:     brandingCache,
+				configMapName:     configMapName,
+				namespace:         namespace,
+				configMapInformer: configMapInformer,
+				eventRecorder:     eventRecorder,
+			},
+		},
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(configMapName, namespace),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+func (c *loginPageBrandingObserverController) Sync(_ controllerlib.Context) error {
+	configMap, err := c.configMapInformer.Lister().ConfigMaps(c.namespace).Get(c.configMapName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get %s/%s configmap: %w", c.namespace, c.configMapName, err)
+	}
+	if notFound {
+		plog.Info("loginPageBrandingObserverController Sync found that the configmap does not exist yet or was deleted",
+			"configmap", c.configMapName, "namespace", c.namespace)
+		c.brandingCache.Set(nil)
+		c.lastReportedResourceVersion = ""
+		return nil
+	}
+
+	b, err := branding.FromConfigMap(configMap)
+	if err != nil {
+		// Keep using the previous valid branding. Returning an error would only retry the same invalid ConfigMap.
+		plog.WarningErr("loginPageBrandingObserverController Sync found an invalid login page branding configmap", err,
+			"configmap", c.configMapName, "namespace", c.namespace)
+		if configMap.ResourceVersion != c.lastReportedResourceVersion {
+			c.eventRecorder.Eventf(configMap, nil, corev1.EventTypeWarning, loginPageBrandingInvalidReason, loginPageBrandingEventAction,
+				"login page branding is invalid and was not applied: %s", err.Error())
+			c.lastReportedResourceVersion = configMap.ResourceVersion
+		}
+		return nil
+	}
+
+	c.brandingCache.Set(b)
+	c.lastReportedResourceVersion = ""
+	plog.Info("loginPageBrandingObserverController Sync updated the login page branding",
+		"configmap", c.configMapName, "namespace", c.namespace)
+	return nil
+}
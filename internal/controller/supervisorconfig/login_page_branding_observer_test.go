@@ -0,0 +1,168 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestLoginPageBrandingObserverControllerInformerFilters(t *testing.T) {
+	observableWithInformerOption := testutil.NewObservableWithInformerOption()
+	configMapInformer := kubeinformers.NewSharedInformerFactory(nil, 0).Core().V1().ConfigMaps()
+	_ = NewLoginPageBrandingObserverController(
+		nil,
+		"some-configmap",
+		"some-namespace",
+		configMapInformer,
+		nil,
+		observableWithInformerOption.WithInformer, // make it possible to observe the behavior of the Filters
+	)
+	filter := observableWithInformerOption.GetFilterForInformer(configMapInformer)
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-configmap", Namespace: "some-namespace"}}
+	wrongName := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other-configmap", Namespace: "some-namespace"}}
+	wrongNamespace := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-configmap", Namespace: "other-namespace"}}
+
+	require.True(t, filter.Add(configMap))
+	require.True(t, filter.Update(wrongName, configMap))
+	require.True(t, filter.Delete(configMap))
+	require.False(t, filter.Add(wrongName))
+	require.False(t, filter.Add(wrongNamespace))
+	require.False(t, filter.Update(wrongName, wrongNamespace))
+	require.False(t, filter.Delete(wrongNamespace))
+}
+
+func TestLoginPageBrandingObserverControllerSync(t *testing.T) {
+	const (
+		configMapName = "some-configmap"
+		namespace     = "some-namespace"
+	)
+
+	previousBranding := &branding.Branding{FooterText: "previous footer"}
+
+	newConfigMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "1"},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		configMaps     []*corev1.ConfigMap
+		cachedBranding *branding.Branding
+		syncs          int
+		wantFooterText string
+		wantNoBranding bool
+		wantEvents     []string
+	}{
+		{
+			name:           "the configmap does not exist",
+			configMaps:     []*corev1.ConfigMap{newConfigMap("other-configmap", map[string]string{branding.FooterTextKey: "other footer"})},
+			cachedBranding: previousBranding,
+			wantNoBranding: true,
+			wantEvents:     []string{},
+		},
+		{
+			name:           "the configmap is valid",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{branding.FooterTextKey: "some footer"})},
+			cachedBranding: previousBranding,
+			wantFooterText: "some footer",
+			wantEvents:     []string{},
+		},
+		{
+			name:           "the configmap is invalid",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{branding.PrimaryColorKey: "red"})},
+			cachedBranding: previousBranding,
+			wantFooterText: "previous footer",
+			wantEvents: []string{
+				"Warning LoginPageBrandingInvalid login page branding is invalid and was not applied: primaryColor must be a hex color like #1a2b3c",
+			},
+		},
+		{
+			name:           "the configmap is invalid and is synced again without changing",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{branding.PrimaryColorKey: "red"})},
+			cachedBranding: previousBranding,
+			syncs:          3,
+			wantFooterText: "previous footer",
+			wantEvents: []string{
+				"Warning LoginPageBrandingInvalid login page branding is invalid and was not applied: primaryColor must be a hex color like #1a2b3c",
+			},
+		},
+		{
+			name:           "the configmap is invalid and there is no previous branding",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{"favicon": "something"})},
+			wantNoBranding: true,
+			wantEvents: []string{
+				"Warning LoginPageBrandingInvalid login page branding is invalid and was not applied: unknown keys: favicon",
+			},
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformerClient := kubernetesfake.NewSimpleClientset()
+			for _, configMap := range tt.configMaps {
+				require.NoError(t, kubeInformerClient.Tracker().Add(configMap))
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
+
+			brandingCache := branding.NewCache()
+			brandingCache.Set(tt.cachedBranding)
+			eventRecorder := events.NewFakeRecorder(100)
+
+			subject := NewLoginPageBrandingObserverController(
+				brandingCache,
+				configMapName,
+				namespace,
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
+				controllerlib.WithInformer,
+			)
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			syncContext := controllerlib.Context{
+				Context: ctx,
+				Name:    subject.Name(),
+				Key:     controllerlib.Key{Namespace: namespace, Name: configMapName},
+			}
+			syncs := tt.syncs
+			if syncs == 0 {
+				syncs = 1
+			}
+			for i := 0; i < syncs; i++ {
+				require.NoError(t, controllerlib.TestSync(t, subject, syncContext))
+			}
+
+			if tt.wantNoBranding {
+				require.Nil(t, brandingCache.Get())
+			} else {
+				require.NotNil(t, brandingCache.Get())
+				require.Equal(t, tt.wantFooterText, brandingCache.Get().FooterText)
+			}
+
+			recorded := []string{}
+			for len(eventRecorder.Events) > 0 {
+				recorded = append(recorded, <-eventRecorder.Events)
+			}
+			require.Equal(t, tt.wantEvents, recorded)
+		})
+	}
+}
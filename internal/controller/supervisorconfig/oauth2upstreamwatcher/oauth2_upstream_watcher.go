@@ -0,0 +1,413 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oauth2upstreamwatcher implements a controller which watches OAuth2IdentityProviders.
+package oauth2upstreamwatcher
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/claimexpressions"
+	"go.pinniped.dev/internal/constable"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/upstreamoauth2"
+)
+
+const (
+	// Setup for the name of our controller in logs.
+	oauth2ControllerName = "oauth2-upstream-observer"
+
+	// Constants related to the client credentials Secret.
+	oauth2ClientSecretType corev1.SecretType = "secrets.pinniped.dev/oidc-client"
+
+	clientIDDataKey     = "clientID"
+	clientSecretDataKey = "clientSecret"
+
+	// Constants related to conditions.
+	typeClientCredentialsValid             = "ClientCredentialsValid" //nolint:gosec // this is not a credential
+	typeAdditionalAuthorizeParametersValid = "AdditionalAuthorizeParametersValid"
+	typeEndpointsValid                     = "EndpointsValid"
+	typeClaimExpressionsValid              = "ClaimExpressionsValid"
+
+	reasonInvalidURL              = "InvalidURL"
+	reasonDisallowedParameterName = "DisallowedParameterName"
+	reasonInvalidClaimExpression  = "InvalidClaimExpression"
+	allParamNamesAllowedMsg       = "additionalAuthorizeParameters parameter names are allowed"
+
+	// Errors that are generated by our reconcile process.
+	errOAuth2FailureStatus = constable.Error("OAuth2IdentityProvider has a failing condition")
+)
+
+var (
+	disallowedAdditionalAuthorizeParameters = map[string]bool{ //nolint:gochecknoglobals
+		// Reject these AdditionalAuthorizeParameters to avoid allowing the user's config to overwrite the parameters
+		// that are always used by Pinniped in authcode authorization requests. Users can already set the "client_id"
+		// and "scope" params using other settings, and the others never make sense to override. This map should be
+		// treated as read-only since it is a global variable.
+		"response_type":         true,
+		"scope":                 true,
+		"client_id":             true,
+		"state":                 true,
+		"nonce":                 true,
+		"code_challenge":        true,
+		"code_challenge_method": true,
+		"redirect_uri":          true,
+	}
+)
+
+// UpstreamOAuth2IdentityProviderICache is a thread safe cache that holds a list of validated upstream OAuth2 IDP configurations.
+type UpstreamOAuth2IdentityProviderICache interface {
+	SetOAuth2IdentityProviders([]provider.UpstreamOIDCIdentityProviderI)
+}
+
+type oauth2WatcherController struct {
+	cache                          UpstreamOAuth2IdentityProviderICache
+	log                            logr.Logger
+	client                         pinnipedclientset.Interface
+	oauth2IdentityProviderInformer idpinformers.OAuth2IdentityProviderInformer
+	secretInformer                 corev1informers.SecretInformer
+	configMapInformer              corev1informers.ConfigMapInformer
+}
+
+// New instantiates a new controllerlib.Controller which will populate the provided UpstreamOAuth2IdentityProviderICache.
+func New(
+	idpCache UpstreamOAuth2IdentityProviderICache,
+	client pinnipedclientset.Interface,
+	oauth2IdentityProviderInformer idpinformers.OAuth2IdentityProviderInformer,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	log logr.Logger,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	c := oauth2WatcherController{
+		cache:                          idpCache,
+		log:                            log.WithName(oauth2ControllerName),
+		client:                         client,
+		oauth2IdentityProviderInformer: oauth2IdentityProviderInformer,
+		secretInformer:                 secretInformer,
+		configMapInformer:              configMapInformer,
+	}
+	return controllerlib.New(
+		controllerlib.Config{Name: oauth2ControllerName, Syncer: &c},
+		withInformer(
+			oauth2IdentityProviderInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{oauth2ClientSecretType, corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *oauth2WatcherController) Sync(ctx controllerlib.Context) error {
+	actualUpstreams, err := c.oauth2IdentityProviderInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list OAuth2IdentityProviders: %w", err)
+	}
+
+	requeue := false
+	validatedUpstreams := make([]provider.UpstreamOIDCIdentityProviderI, 0, len(actualUpstreams))
+	for _, upstream := range actualUpstreams {
+		valid := c.validateUpstream(ctx, upstream)
+		if valid == nil {
+			requeue = true
+		} else {
+			validatedUpstreams = append(validatedUpstreams, provider.UpstreamOIDCIdentityProviderI(valid))
+		}
+	}
+	c.cache.SetOAuth2IdentityProviders(validatedUpstreams)
+	if requeue {
+		return controllerlib.ErrSyntheticRequeue
+	}
+	return nil
+}
+
+// validateUpstream validates the provided v1alpha1.OAuth2IdentityProvider and returns the validated configuration as a
+// upstreamoauth2.ProviderConfig. As a side effect, it also updates the status of the v1alpha1.OAuth2IdentityProvider.
+func (c *oauth2WatcherController) validateUpstream(ctx controllerlib.Context, upstream *v1alpha1.OAuth2IdentityProvider) *upstreamoauth2.ProviderConfig {
+	authorizationConfig := upstream.Spec.AuthorizationConfig
+
+	additionalAuthcodeAuthorizeParameters := map[string]string{}
+	var rejectedAuthcodeAuthorizeParameters []string
+	for _, p := range authorizationConfig.AdditionalAuthorizeParameters {
+		if disallowedAdditionalAuthorizeParameters[p.Name] {
+			rejectedAuthcodeAuthorizeParameters = append(rejectedAuthcodeAuthorizeParameters, p.Name)
+		} else {
+			additionalAuthcodeAuthorizeParameters[p.Name] = p.Value
+		}
+	}
+
+	result := upstreamoauth2.ProviderConfig{
+		Name: upstream.Name,
+		Config: &oauth2.Config{
+			Scopes: authorizationConfig.Scopes,
+		},
+		AdditionalAuthcodeParams: additionalAuthcodeAuthorizeParameters,
+		ResourceUID:              upstream.UID,
+	}
+
+	conditions := []*v1alpha1.Condition{
+		c.validateSecret(upstream, &result),
+		c.validateEndpoints(upstream, &result),
+	}
+	if len(rejectedAuthcodeAuthorizeParameters) > 0 {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:   typeAdditionalAuthorizeParametersValid,
+			Status: v1alpha1.ConditionFalse,
+			Reason: reasonDisallowedParameterName,
+			Message: fmt.Sprintf("the following additionalAuthorizeParameters are not allowed: %s",
+				strings.Join(rejectedAuthcodeAuthorizeParameters, ",")),
+		})
+	} else {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    typeAdditionalAuthorizeParametersValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  upstreamwatchers.ReasonSuccess,
+			Message: allParamNamesAllowedMsg,
+		})
+	}
+	conditions = append(conditions, validateClaimExpressions(upstream, &result))
+
+	c.updateStatus(ctx.Context, upstream, conditions)
+
+	valid := true
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	for _, condition := range conditions {
+		if condition.Status == v1alpha1.ConditionFalse {
+			valid = false
+			log.WithValues(
+				"type", condition.Type,
+				"reason", condition.Reason,
+				"message", condition.Message,
+			).Error(errOAuth2FailureStatus, "found failing condition")
+		}
+	}
+	if valid {
+		return &result
+	}
+	return nil
+}
+
+// validateClaimExpressions compiles the .spec.claims fields and returns the appropriate ClaimExpressionsValid condition.
+func validateClaimExpressions(upstream *v1alpha1.OAuth2IdentityProvider, result *upstreamoauth2.ProviderConfig) *v1alpha1.Condition {
+	claims := upstream.Spec.Claims
+
+	uidExpression, err := claimexpressions.CompileString("uid", claims.UID)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClaimExpressionsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidClaimExpression,
+			Message: err.Error(),
+		}
+	}
+
+	if claims.Username == "" {
+		// The CRD validation should prevent this, but the username is required to compute an identity.
+		return &v1alpha1.Condition{
+			Type:    typeClaimExpressionsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidClaimExpression,
+			Message: "username expression must not be empty",
+		}
+	}
+	compiled, err := claimexpressions.Compile(claims.Username, claims.Groups, nil)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClaimExpressionsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidClaimExpression,
+			Message: err.Error(),
+		}
+	}
+
+	result.UIDExpression = uidExpression
+	result.ClaimExpressions = compiled
+	return &v1alpha1.Condition{
+		Type:    typeClaimExpressionsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "claim expressions are valid",
+	}
+}
+
+// validateSecret validates the .spec.client.secretName field and returns the appropriate ClientCredentialsValid condition.
+func (c *oauth2WatcherController) validateSecret(upstream *v1alpha1.OAuth2IdentityProvider, result *upstreamoauth2.ProviderConfig) *v1alpha1.Condition {
+	secretName := upstream.Spec.Client.SecretName
+
+	// Fetch the Secret from informer cache.
+	secret, err := c.secretInformer.Lister().Secrets(upstream.Namespace).Get(secretName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonNotFound,
+			Message: err.Error(),
+		}
+	}
+
+	// Validate the secret .type field.
+	if secret.Type != oauth2ClientSecretType {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonWrongType,
+			Message: fmt.Sprintf("referenced Secret %q has wrong type %q (should be %q)", secretName, secret.Type, oauth2ClientSecretType),
+		}
+	}
+
+	// Validate the secret .data field.
+	clientID := secret.Data[clientIDDataKey]
+	clientSecret := secret.Data[clientSecretDataKey]
+	if len(clientID) == 0 || len(clientSecret) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{clientIDDataKey, clientSecretDataKey}),
+		}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.ClientID = string(clientID)
+	result.Config.ClientSecret = string(clientSecret)
+	return &v1alpha1.Condition{
+		Type:    typeClientCredentialsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "loaded client credentials",
+	}
+}
+
+// validateEndpoints validates the endpoint URLs and the .spec.tls field, and returns the appropriate EndpointsValid
+// condition. Unlike OIDC issuers, OAuth2 providers do not offer discovery, so no requests are made to the provider.
+func (c *oauth2WatcherController) validateEndpoints(upstream *v1alpha1.OAuth2IdentityProvider, result *upstreamoauth2.ProviderConfig) *v1alpha1.Condition {
+	rootCAs, _, _, err := upstreamwatchers.ReadCABundle(upstream.Spec.TLS, upstream.Namespace, c.secretInformer, c.configMapInformer)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeEndpointsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonInvalidTLSConfig,
+			Message: fmt.Sprintf("spec.%s", err.Error()),
+		}
+	}
+
+	if _, condition := validateHTTPSURL(upstream.Spec.AuthorizationURL, "spec.authorizationURL"); condition != nil {
+		return condition
+	}
+	if _, condition := validateHTTPSURL(upstream.Spec.TokenURL, "spec.tokenURL"); condition != nil {
+		return condition
+	}
+	userInfoURL, condition := validateHTTPSURL(upstream.Spec.UserInfoURL, "spec.userInfoURL")
+	if condition != nil {
+		return condition
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.Endpoint = oauth2.Endpoint{
+		AuthURL:   upstream.Spec.AuthorizationURL,
+		TokenURL:  upstream.Spec.TokenURL,
+		AuthStyle: oauth2.AuthStyleAutoDetect,
+	}
+	result.UserInfoURL = userInfoURL
+	result.Client = defaultClientShortTimeout(rootCAs)
+	return &v1alpha1.Condition{
+		Type:    typeEndpointsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "endpoints are valid",
+	}
+}
+
+func (c *oauth2WatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.OAuth2IdentityProvider, conditions []*v1alpha1.Condition) {
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	updated := upstream.DeepCopy()
+
+	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+
+	updated.Status.Phase = v1alpha1.OAuth2PhaseReady
+	if hadErrorCondition {
+		updated.Status.Phase = v1alpha1.OAuth2PhaseError
+	}
+
+	if equality.Semantic.DeepEqual(upstream, updated) {
+		return
+	}
+
+	_, err := c.client.
+		IDPV1alpha1().
+		OAuth2IdentityProviders(upstream.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error(err, "failed to update status")
+	}
+}
+
+func defaultClientShortTimeout(rootCAs *x509.CertPool) *http.Client {
+	c := phttp.Default(rootCAs)
+	c.Timeout = time.Minute
+	return c
+}
+
+func validateHTTPSURL(maybeHTTPSURL, fieldName string) (*url.URL, *v1alpha1.Condition) {
+	parsedURL, err := url.Parse(maybeHTTPSURL)
+	if err != nil {
+		return nil, &v1alpha1.Condition{
+			Type:    typeEndpointsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidURL,
+			Message: fmt.Sprintf("failed to parse %s: %v", fieldName, err),
+		}
+	}
+	if parsedURL.Scheme != "https" {
+		return nil, &v1alpha1.Condition{
+			Type:    typeEndpointsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidURL,
+			Message: fmt.Sprintf(`%s '%s' must have "https" scheme, not %q`, fieldName, maybeHTTPSURL, parsedURL.Scheme),
+		}
+	}
+	if parsedURL.Fragment != "" {
+		return nil, &v1alpha1.Condition{
+			Type:    typeEndpointsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidURL,
+			Message: fmt.Sprintf(`%s '%s' cannot contain fragment component`, fieldName, maybeHTTPSURL),
+		}
+	}
+	return parsedURL, nil
+}
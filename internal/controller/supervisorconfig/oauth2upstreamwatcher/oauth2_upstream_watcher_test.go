@@ -0,0 +1,566 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2upstreamwatcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/testlogger"
+	"go.pinniped.dev/internal/upstreamoauth2"
+)
+
+func TestOAuth2UpstreamWatcherControllerFilterSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		secret     metav1.Object
+		wantAdd    bool
+		wantUpdate bool
+		wantDelete bool
+	}{
+		{
+			name: "a secret of the right type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/oidc-client",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+			wantAdd:    true,
+			wantUpdate: true,
+			wantDelete: true,
+		},
+		{
+			name: "a secret of the wrong type",
+			secret: &corev1.Secret{
+				Type:       "secrets.pinniped.dev/not-the-oidc-client-type",
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+		{
+			name: "resource of wrong data type",
+			secret: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-name", Namespace: "some-namespace"},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset()
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset()
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			secretInformer := kubeInformers.Core().V1().Secrets()
+			withInformer := testutil.NewObservableWithInformerOption()
+
+			New(
+				provider.NewDynamicUpstreamIDPProvider(),
+				nil,
+				pinnipedInformers.IDP().V1alpha1().OAuth2IdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // test with no log assertions
+				withInformer.WithInformer,
+			)
+
+			unrelated := corev1.Secret{}
+			filter := withInformer.GetFilterForInformer(secretInformer)
+			require.Equal(t, test.wantAdd, filter.Add(test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(&unrelated, test.secret))
+			require.Equal(t, test.wantUpdate, filter.Update(test.secret, &unrelated))
+			require.Equal(t, test.wantDelete, filter.Delete(test.secret))
+		})
+	}
+}
+
+func TestOAuth2UpstreamWatcherControllerSync(t *testing.T) {
+	t.Parallel()
+	now := metav1.NewTime(time.Now().UTC())
+
+	var (
+		testNamespace       = "test-namespace"
+		testName            = "test-name"
+		testSecretName      = "test-client-secret"
+		testClientID        = "test-oauth2-client-id"
+		testClientSecret    = "test-oauth2-client-secret"
+		testValidSecretData = map[string][]byte{"clientID": []byte(testClientID), "clientSecret": []byte(testClientSecret)}
+		testUID             = types.UID("test-uid")
+		testValidSpec       = v1alpha1.OAuth2IdentityProviderSpec{
+			AuthorizationURL: "https://example.com/login/oauth/authorize",
+			TokenURL:         "https://example.com/login/oauth/access_token",
+			UserInfoURL:      "https://api.example.com/user",
+			AuthorizationConfig: v1alpha1.OAuth2AuthorizationConfig{
+				Scopes:                        []string{"read:user", "read:org"},
+				AdditionalAuthorizeParameters: []v1alpha1.Parameter{{Name: "prompt", Value: "consent"}},
+			},
+			Claims: v1alpha1.OAuth2Claims{
+				UID:      "string(claims.id)",
+				Username: "claims.login",
+				Groups:   "claims.teams",
+			},
+			Client: v1alpha1.OAuth2Client{SecretName: testSecretName},
+		}
+		testValidSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+			Type:       "secrets.pinniped.dev/oidc-client",
+			Data:       testValidSecretData,
+		}
+	)
+
+	happyAdditionalAuthorizeParametersValidCondition := v1alpha1.Condition{
+		Type:               "AdditionalAuthorizeParametersValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "additionalAuthorizeParameters parameter names are allowed",
+		LastTransitionTime: now,
+	}
+	happyClaimExpressionsValidCondition := v1alpha1.Condition{
+		Type:               "ClaimExpressionsValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "claim expressions are valid",
+		LastTransitionTime: now,
+	}
+	happyClientCredentialsValidCondition := v1alpha1.Condition{
+		Type:               "ClientCredentialsValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "loaded client credentials",
+		LastTransitionTime: now,
+	}
+	happyEndpointsValidCondition := v1alpha1.Condition{
+		Type:               "EndpointsValid",
+		Status:             "True",
+		Reason:             "Success",
+		Message:            "endpoints are valid",
+		LastTransitionTime: now,
+	}
+
+	specWith := func(editFunc func(spec *v1alpha1.OAuth2IdentityProviderSpec)) v1alpha1.OAuth2IdentityProviderSpec {
+		spec := *testValidSpec.DeepCopy()
+		editFunc(&spec)
+		return spec
+	}
+
+	tests := []struct {
+		name                   string
+		inputUpstreams         []runtime.Object
+		inputSecrets           []runtime.Object
+		wantErr                string
+		wantLogs               []string
+		wantResultingCache     []string
+		wantResultingUpstreams []v1alpha1.OAuth2IdentityProvider
+	}{
+		{
+			name:               "no upstreams",
+			wantResultingCache: []string{},
+		},
+		{
+			name: "valid upstream",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="endpoints are valid" "reason"="Success" "status"="True" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="claim expressions are valid" "reason"="Success" "status"="True" "type"="ClaimExpressionsValid"`,
+			},
+			wantResultingCache: []string{testName},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testUID},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						happyClaimExpressionsValidCondition,
+						happyClientCredentialsValidCondition,
+						happyEndpointsValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "missing secret",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="secret \"test-client-secret\" not found" "reason"="SecretNotFound" "status"="False" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="endpoints are valid" "reason"="Success" "status"="True" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="claim expressions are valid" "reason"="Success" "status"="True" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="secret \"test-client-secret\" not found" "name"="test-name" "namespace"="test-namespace" "reason"="SecretNotFound" "type"="ClientCredentialsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						happyClaimExpressionsValidCondition,
+						{
+							Type:               "ClientCredentialsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretNotFound",
+							Message:            `secret "test-client-secret" not found`,
+						},
+						happyEndpointsValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "secret is missing keys",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec:       testValidSpec,
+			}},
+			inputSecrets: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+				Type:       "secrets.pinniped.dev/oidc-client",
+				Data:       map[string][]byte{"clientID": []byte(testClientID)},
+			}},
+			wantErr: controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="referenced Secret \"test-client-secret\" is missing required keys [\"clientID\" \"clientSecret\"]" "reason"="SecretMissingKeys" "status"="False" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="endpoints are valid" "reason"="Success" "status"="True" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="claim expressions are valid" "reason"="Success" "status"="True" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="referenced Secret \"test-client-secret\" is missing required keys [\"clientID\" \"clientSecret\"]" "name"="test-name" "namespace"="test-namespace" "reason"="SecretMissingKeys" "type"="ClientCredentialsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						happyClaimExpressionsValidCondition,
+						{
+							Type:               "ClientCredentialsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "SecretMissingKeys",
+							Message:            `referenced Secret "test-client-secret" is missing required keys ["clientID" "clientSecret"]`,
+						},
+						happyEndpointsValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "userinfo URL is not https",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.OAuth2IdentityProviderSpec) {
+					spec.UserInfoURL = "http://api.example.com/user"
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.userInfoURL 'http://api.example.com/user' must have \"https\" scheme, not \"http\"" "reason"="InvalidURL" "status"="False" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="claim expressions are valid" "reason"="Success" "status"="True" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="spec.userInfoURL 'http://api.example.com/user' must have \"https\" scheme, not \"http\"" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidURL" "type"="EndpointsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						happyClaimExpressionsValidCondition,
+						happyClientCredentialsValidCondition,
+						{
+							Type:               "EndpointsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidURL",
+							Message:            `spec.userInfoURL 'http://api.example.com/user' must have "https" scheme, not "http"`,
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid CA bundle",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.OAuth2IdentityProviderSpec) {
+					spec.TLS = &v1alpha1.TLSSpec{CertificateAuthorityData: "invalid-base64"}
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="spec.certificateAuthorityData is invalid: illegal base64 data at input byte 7" "reason"="InvalidTLSConfig" "status"="False" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="claim expressions are valid" "reason"="Success" "status"="True" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="spec.certificateAuthorityData is invalid: illegal base64 data at input byte 7" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidTLSConfig" "type"="EndpointsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						happyClaimExpressionsValidCondition,
+						happyClientCredentialsValidCondition,
+						{
+							Type:               "EndpointsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidTLSConfig",
+							Message:            "spec.certificateAuthorityData is invalid: illegal base64 data at input byte 7",
+						},
+					},
+				},
+			}},
+		},
+		{
+			name: "disallowed additional authorize parameters",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.OAuth2IdentityProviderSpec) {
+					spec.AuthorizationConfig.AdditionalAuthorizeParameters = []v1alpha1.Parameter{
+						{Name: "state", Value: "foo"},
+						{Name: "allow_signup", Value: "false"},
+						{Name: "redirect_uri", Value: "bar"},
+					}
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="endpoints are valid" "reason"="Success" "status"="True" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="the following additionalAuthorizeParameters are not allowed: state,redirect_uri" "reason"="DisallowedParameterName" "status"="False" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="claim expressions are valid" "reason"="Success" "status"="True" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="the following additionalAuthorizeParameters are not allowed: state,redirect_uri" "name"="test-name" "namespace"="test-namespace" "reason"="DisallowedParameterName" "type"="AdditionalAuthorizeParametersValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						{
+							Type:               "AdditionalAuthorizeParametersValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "DisallowedParameterName",
+							Message:            "the following additionalAuthorizeParameters are not allowed: state,redirect_uri",
+						},
+						happyClaimExpressionsValidCondition,
+						happyClientCredentialsValidCondition,
+						happyEndpointsValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "empty uid expression",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.OAuth2IdentityProviderSpec) {
+					spec.Claims.UID = ""
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="endpoints are valid" "reason"="Success" "status"="True" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="uid expression must not be empty" "reason"="InvalidClaimExpression" "status"="False" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="uid expression must not be empty" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidClaimExpression" "type"="ClaimExpressionsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						{
+							Type:               "ClaimExpressionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidClaimExpression",
+							Message:            "uid expression must not be empty",
+						},
+						happyClientCredentialsValidCondition,
+						happyEndpointsValidCondition,
+					},
+				},
+			}},
+		},
+		{
+			name: "empty username expression",
+			inputUpstreams: []runtime.Object{&v1alpha1.OAuth2IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Spec: specWith(func(spec *v1alpha1.OAuth2IdentityProviderSpec) {
+					spec.Claims.Username = ""
+				}),
+			}},
+			inputSecrets: []runtime.Object{testValidSecret},
+			wantErr:      controllerlib.ErrSyntheticRequeue.Error(),
+			wantLogs: []string{
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="loaded client credentials" "reason"="Success" "status"="True" "type"="ClientCredentialsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="endpoints are valid" "reason"="Success" "status"="True" "type"="EndpointsValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="additionalAuthorizeParameters parameter names are allowed" "reason"="Success" "status"="True" "type"="AdditionalAuthorizeParametersValid"`,
+				`oauth2-upstream-observer "level"=0 "msg"="updated condition" "name"="test-name" "namespace"="test-namespace" "message"="username expression must not be empty" "reason"="InvalidClaimExpression" "status"="False" "type"="ClaimExpressionsValid"`,
+				`oauth2-upstream-observer "msg"="found failing condition" "error"="OAuth2IdentityProvider has a failing condition" "message"="username expression must not be empty" "name"="test-name" "namespace"="test-namespace" "reason"="InvalidClaimExpression" "type"="ClaimExpressionsValid"`,
+			},
+			wantResultingCache: []string{},
+			wantResultingUpstreams: []v1alpha1.OAuth2IdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status: v1alpha1.OAuth2IdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []v1alpha1.Condition{
+						happyAdditionalAuthorizeParametersValidCondition,
+						{
+							Type:               "ClaimExpressionsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidClaimExpression",
+							Message:            "username expression must not be empty",
+						},
+						happyClientCredentialsValidCondition,
+						happyEndpointsValidCondition,
+					},
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset(tt.inputUpstreams...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset(tt.inputSecrets...)
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // uses the same log assertions as the OIDC watcher test
+			cache := provider.NewDynamicUpstreamIDPProvider()
+			cache.SetOAuth2IdentityProviders([]provider.UpstreamOIDCIdentityProviderI{
+				&upstreamoauth2.ProviderConfig{Name: "initial-entry"},
+			})
+
+			controller := New(
+				cache,
+				fakePinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().OAuth2IdentityProviders(),
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				testLog.Logger,
+				controllerlib.WithInformer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}}
+
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, strings.Join(tt.wantLogs, "\n"), strings.Join(testLog.Lines(), "\n"))
+
+			actualIDPList := cache.GetOIDCIdentityProviders()
+			actualNames := make([]string, 0, len(actualIDPList))
+			for _, actualIDP := range actualIDPList {
+				actualNames = append(actualNames, actualIDP.GetName())
+			}
+			require.Equal(t, tt.wantResultingCache, actualNames)
+			for i := range actualIDPList {
+				actualIDP := actualIDPList[i].(*upstreamoauth2.ProviderConfig)
+				require.Equal(t, testClientID, actualIDP.GetClientID())
+				require.Equal(t, testClientSecret, actualIDP.Config.ClientSecret)
+				require.Equal(t, "https://example.com/login/oauth/authorize", actualIDP.GetAuthorizationURL().String())
+				require.Equal(t, "https://example.com/login/oauth/access_token", actualIDP.Config.Endpoint.TokenURL)
+				require.Equal(t, "https://api.example.com/user", actualIDP.UserInfoURL.String())
+				require.Equal(t, []string{"read:user", "read:org"}, actualIDP.GetScopes())
+				require.Equal(t, map[string]string{"prompt": "consent"}, actualIDP.GetAdditionalAuthcodeParams())
+				require.Equal(t, testUID, actualIDP.GetResourceUID())
+				require.NotNil(t, actualIDP.UIDExpression)
+				require.True(t, actualIDP.GetClaimExpressions().HasUsername())
+				require.True(t, actualIDP.GetClaimExpressions().HasGroups())
+				require.Equal(t, time.Minute, actualIDP.Client.Timeout)
+			}
+
+			actualUpstreams, err := fakePinnipedClient.IDPV1alpha1().OAuth2IdentityProviders(testNamespace).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+
+			// Assert on the expected Status of the upstreams. Preprocess the upstreams a bit so that they're easier to assert against.
+			require.ElementsMatch(t, tt.wantResultingUpstreams, normalizeOAuth2Upstreams(actualUpstreams.Items, now))
+
+			// Running the sync() a second time should be idempotent except for logs, and should return the same error.
+			if err := controllerlib.TestSync(t, controller, syncCtx); tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func normalizeOAuth2Upstreams(upstreams []v1alpha1.OAuth2IdentityProvider, now metav1.Time) []v1alpha1.OAuth2IdentityProvider {
+	result := make([]v1alpha1.OAuth2IdentityProvider, 0, len(upstreams))
+	for _, u := range upstreams {
+		normalized := u.DeepCopy()
+
+		// We're only interested in comparing the status, so zero out the spec.
+		normalized.Spec = v1alpha1.OAuth2IdentityProviderSpec{}
+
+		// Round down the LastTransitionTime values to `now` if they were just updated. This makes
+		// it much easier to encode assertions about the expected timestamps.
+		for i := range normalized.Status.Conditions {
+			if time.Since(normalized.Status.Conditions[i].LastTransitionTime.Time) < 5*time.Second {
+				normalized.Status.Conditions[i].LastTransitionTime = now
+			}
+		}
+		result = append(result, *normalized)
+	}
+
+	return result
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientwatcher
@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kubeinformers "k8s.io/client-go/informers"
 	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
@@ -224,6 +225,28 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
+	happyAllowedRedirectURIsCondition := func(time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
+		return configv1alpha1.Condition{
+			Type:               "AllowedRedirectURIsValid",
+			Status:             "True",
+			LastTransitionTime: time,
+			Reason:             "Success",
+			Message:            `"allowedRedirectURIs" is valid`,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	sadAllowedRedirectURIsCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
+		return configv1alpha1.Condition{
+			Type:               "AllowedRedirectURIsValid",
+			Status:             "False",
+			LastTransitionTime: time,
+			Reason:             "InvalidRedirectURIs",
+			Message:            message,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
 	happyAllowedScopesCondition := func(time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
 		return configv1alpha1.Condition{
 			Type:               "AllowedScopesValid",
@@ -246,6 +269,50 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
+	happyClientCredentialsCondition := func(time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
+		return configv1alpha1.Condition{
+			Type:               "ClientCredentialsValid",
+			Status:             "True",
+			LastTransitionTime: time,
+			Reason:             "Success",
+			Message:            `"clientCredentials" is valid`,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	sadClientCredentialsCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
+		return configv1alpha1.Condition{
+			Type:               "ClientCredentialsValid",
+			Status:             "False",
+			LastTransitionTime: time,
+			Reason:             "InvalidClientCredentials",
+			Message:            message,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	happyTokenLifetimesCondition := func(time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
+		return configv1alpha1.Condition{
+			Type:               "TokenLifetimesValid",
+			Status:             "True",
+			LastTransitionTime: time,
+			Reason:             "Success",
+			Message:            `"tokenLifetimes" is valid`,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	sadTokenLifetimesCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
+		return configv1alpha1.Condition{
+			Type:               "TokenLifetimesValid",
+			Status:             "False",
+			LastTransitionTime: time,
+			Reason:             "InvalidTokenLifetimes",
+			Message:            message,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
 	tests := []struct {
 		name                     string
 		inputObjects             []runtime.Object
@@ -294,8 +361,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 						Phase: "Ready",
 						Conditions: []configv1alpha1.Condition{
 							happyAllowedGrantTypesCondition(now, 1234),
+							happyAllowedRedirectURIsCondition(now, 1234),
 							happyAllowedScopesCondition(now, 1234),
+							happyClientCredentialsCondition(now, 1234),
 							happyClientSecretsCondition(1, now, 1234),
+							happyTokenLifetimesCondition(now, 1234),
 						},
 						TotalClientSecrets: 1,
 					},
@@ -319,13 +389,252 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(2, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 2,
 				},
 			}},
 		},
+		{
+			name: "successfully validate OIDCClient with token lifetimes",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					TokenLifetimes: configv1alpha1.TokenLifetimes{
+						IDTokenSeconds:      pointer.Int32(3600),
+						AccessTokenSeconds:  pointer.Int32(120),
+						RefreshTokenSeconds: pointer.Int32(600),
+					},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Ready",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "successfully validate OIDCClient with wildcard and loopback redirect URIs",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedRedirectURIs: []configv1alpha1.RedirectURI{
+						"https://*.preview.example.com/callback",
+						"http://127.0.0.1/callback",
+						"http://[::1]:1234/callback",
+					},
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Ready",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "redirect URIs with invalid wildcards",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedRedirectURIs: []configv1alpha1.RedirectURI{
+						"https://*.com/callback",
+						"https://pr-*.example.com/callback",
+						"https://example.com/*",
+						"http://*.127.0.0.1/callback",
+					},
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						sadAllowedRedirectURIsCondition(now, 1234,
+							`"https://*.com/callback" in "allowedRedirectURIs" must have at least two labels after the wildcard; `+
+								`"https://pr-*.example.com/callback" in "allowedRedirectURIs" may only have a wildcard as the leftmost label of the hostname of an https URI; `+
+								`"https://example.com/*" in "allowedRedirectURIs" may only have a wildcard in its hostname; `+
+								`"http://*.127.0.0.1/callback" in "allowedRedirectURIs" must use the https scheme, unless its hostname is 127.0.0.1 or ::1`),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "successfully validate OIDCClient which may use the client credentials grant",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code", "client_credentials"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					ClientCredentials: configv1alpha1.ClientCredentials{
+						AllowedScopes:    []string{"orders:read", "orders:write"},
+						AllowedAudiences: []string{"https://orders.example.com"},
+					},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Ready",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "client credentials with user scopes and reserved audiences",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code", "client_credentials"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					ClientCredentials: configv1alpha1.ClientCredentials{
+						AllowedScopes:    []string{"orders:read", "openid", "groups", "two scopes"},
+						AllowedAudiences: []string{"cluster.pinniped.dev", "pinniped-cli", ""},
+					},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						sadClientCredentialsCondition(now, 1234,
+							`"openid" must not be included in "clientCredentials.allowedScopes"; `+
+								`"groups" must not be included in "clientCredentials.allowedScopes"; `+
+								`"two scopes" in "clientCredentials.allowedScopes" is not a valid scope; `+
+								`"cluster.pinniped.dev" in "clientCredentials.allowedAudiences" is a reserved audience; `+
+								`"pinniped-cli" in "clientCredentials.allowedAudiences" is a reserved audience; `+
+								`"" in "clientCredentials.allowedAudiences" is not a valid audience`),
+						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "token lifetimes which are out of bounds",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					TokenLifetimes: configv1alpha1.TokenLifetimes{
+						IDTokenSeconds:      pointer.Int32(119),
+						AccessTokenSeconds:  pointer.Int32(3601),
+						RefreshTokenSeconds: pointer.Int32(32401),
+					},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						sadTokenLifetimesCondition(now, 1234,
+							`"tokenLifetimes.idTokenSeconds" must be between 120 and 3600 seconds, but was 119; `+
+								`"tokenLifetimes.accessTokenSeconds" must be between 120 and 3600 seconds, but was 3601; `+
+								`"tokenLifetimes.refreshTokenSeconds" must be between 600 and 32400 seconds, but was 32401`),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "refresh token lifetime which is shorter than the access token lifetime",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					TokenLifetimes: configv1alpha1.TokenLifetimes{
+						AccessTokenSeconds:  pointer.Int32(3600),
+						RefreshTokenSeconds: pointer.Int32(600),
+					},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []configv1alpha1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						sadTokenLifetimesCondition(now, 1234,
+							`"tokenLifetimes.refreshTokenSeconds" must not be less than "tokenLifetimes.accessTokenSeconds"`),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
 		{
 			name: "an already validated OIDCClient does not have its conditions updated when everything is still valid",
 			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
@@ -338,8 +647,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(earlier, 1234),
+						happyAllowedRedirectURIsCondition(earlier, 1234),
 						happyAllowedScopesCondition(earlier, 1234),
+						happyClientCredentialsCondition(earlier, 1234),
 						happyClientSecretsCondition(1, earlier, 1234),
+						happyTokenLifetimesCondition(earlier, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -352,8 +664,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(earlier, 1234),
+						happyAllowedRedirectURIsCondition(earlier, 1234),
 						happyAllowedScopesCondition(earlier, 1234),
+						happyClientCredentialsCondition(earlier, 1234),
 						happyClientSecretsCondition(1, earlier, 1234),
+						happyTokenLifetimesCondition(earlier, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -372,8 +687,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234, `"authorization_code" must always be included in "allowedGrantTypes"`),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"openid" must always be included in "allowedScopes"`),
+						happyClientCredentialsCondition(now, 1234),
 						sadNoClientSecretsCondition(now, 1234, "no client secret found (no Secret storage found)"),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 				},
 			}},
@@ -395,8 +713,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						sadNoClientSecretsCondition(now, 1234, "error reading client secret storage: OIDC client secret storage data has wrong version: OIDC client secret storage has version wrong-version instead of 1"),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 				},
 			}},
@@ -418,8 +739,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						sadNoClientSecretsCondition(now, 1234, "no client secret found (empty list in storage)"),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 0,
 				},
@@ -445,11 +769,14 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						sadInvalidClientSecretsCondition(now, 1234,
 							"3 stored client secrets found, but some were invalid, so none will be used: "+
 								"hashed client secret at index 1: bcrypt cost 11 is below the required minimum of 12; "+
 								"hashed client secret at index 2: crypto/bcrypt: hashedSecret too short to be a bcrypted password"),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 0,
 				},
@@ -479,8 +806,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 						Phase: "Ready",
 						Conditions: []configv1alpha1.Condition{
 							happyAllowedGrantTypesCondition(now, 1234),
+							happyAllowedRedirectURIsCondition(now, 1234),
 							happyAllowedScopesCondition(now, 1234),
+							happyClientCredentialsCondition(now, 1234),
 							happyClientSecretsCondition(1, now, 1234),
+							happyTokenLifetimesCondition(now, 1234),
 						},
 						TotalClientSecrets: 1,
 					},
@@ -491,8 +821,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 						Phase: "Error",
 						Conditions: []configv1alpha1.Condition{
 							sadAllowedGrantTypesCondition(now, 4567, `"authorization_code" must always be included in "allowedGrantTypes"`),
+							happyAllowedRedirectURIsCondition(now, 4567),
 							sadAllowedScopesCondition(now, 4567, `"openid" must always be included in "allowedScopes"`),
+							happyClientCredentialsCondition(now, 4567),
 							sadNoClientSecretsCondition(now, 4567, "no client secret found (no Secret storage found)"),
+							happyTokenLifetimesCondition(now, 4567),
 						},
 						TotalClientSecrets: 0,
 					},
@@ -512,8 +845,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						sadAllowedGrantTypesCondition(earlier, 1234, `"authorization_code" must always be included in "allowedGrantTypes"`),
+						happyAllowedRedirectURIsCondition(earlier, 1234),
 						sadAllowedScopesCondition(earlier, 1234, `"openid" must always be included in "allowedScopes"`),
+						happyClientCredentialsCondition(earlier, 1234),
 						happyClientSecretsCondition(1, earlier, 1234),
+						happyTokenLifetimesCondition(earlier, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -527,8 +863,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 4567),
+						happyAllowedRedirectURIsCondition(earlier, 4567),
 						happyAllowedScopesCondition(now, 4567),
+						happyClientCredentialsCondition(earlier, 4567),
 						happyClientSecretsCondition(1, earlier, 4567), // was already validated earlier
+						happyTokenLifetimesCondition(earlier, 4567),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -551,8 +890,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234, `"refresh_token" must be included in "allowedGrantTypes" when "offline_access" is included in "allowedScopes"`),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -577,11 +919,14 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 						sadAllowedGrantTypesCondition(now, 1234,
 							`"authorization_code" must always be included in "allowedGrantTypes"; `+
 								`"urn:ietf:params:oauth:grant-type:token-exchange" must be included in "allowedGrantTypes" when "pinniped:request-audience" is included in "allowedScopes"`),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234,
 							`"openid" must always be included in "allowedScopes"; `+
 								`"offline_access" must be included in "allowedScopes" when "refresh_token" is included in "allowedGrantTypes"; `+
 								`"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -606,10 +951,13 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 						sadAllowedGrantTypesCondition(now, 1234,
 							`"authorization_code" must always be included in "allowedGrantTypes"; `+
 								`"refresh_token" must be included in "allowedGrantTypes" when "offline_access" is included in "allowedScopes"`),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234,
 							`"openid" must always be included in "allowedScopes"; `+
 								`"pinniped:request-audience" must be included in "allowedScopes" when "urn:ietf:params:oauth:grant-type:token-exchange" is included in "allowedGrantTypes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -632,8 +980,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234, `"urn:ietf:params:oauth:grant-type:token-exchange" must be included in "allowedGrantTypes" when "pinniped:request-audience" is included in "allowedScopes"`),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -656,8 +1007,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"offline_access" must be included in "allowedScopes" when "refresh_token" is included in "allowedGrantTypes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -680,8 +1034,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -704,8 +1061,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -728,8 +1088,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -752,8 +1115,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Error",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"pinniped:request-audience" must be included in "allowedScopes" when "urn:ietf:params:oauth:grant-type:token-exchange" is included in "allowedGrantTypes"`),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -776,8 +1142,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -800,8 +1169,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -824,8 +1196,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -848,8 +1223,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -872,8 +1250,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -896,8 +1277,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -920,8 +1304,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -944,8 +1331,11 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					Phase: "Ready",
 					Conditions: []configv1alpha1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedRedirectURIsCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
+						happyClientCredentialsCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyTokenLifetimesCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -0,0 +1,281 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcupstreamwatcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	oidcDiscoveryRefresherControllerName = "oidc-upstream-discovery-refresher"
+
+	// oidcDiscoveryRefreshInterval is how often the discovery document of each OIDCIdentityProvider is fetched again.
+	// It is shorter than oidcValidatorCacheTTL so that the cached discovery results are replaced before they expire.
+	oidcDiscoveryRefreshInterval = 5 * time.Minute
+
+	typeOIDCDiscoveryRefreshed = "OIDCDiscoveryRefreshed"
+
+	reasonDiscoveryChanged = "DiscoveryChanged"
+)
+
+// discoveredEndpoints are the values of the discovery document which are used by the Supervisor.
+type discoveredEndpoints struct {
+	AuthorizationEndpoint              string `json:"authorization_endpoint"`
+	TokenEndpoint                      string `json:"token_endpoint"`
+	JWKSURI                            string `json:"jwks_uri"`
+	UserInfoEndpoint                   string `json:"userinfo_endpoint"`
+	RevocationEndpoint                 string `json:"revocation_endpoint"`
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+}
+
+type oidcDiscoveryRefresherController struct {
+	log                          plog.Logger
+	client                       pinnipedclientset.Interface
+	oidcIdentityProviderInformer idpinformers.OIDCIdentityProviderInformer
+	secretInformer               corev1informers.SecretInformer
+	configMapInformer            corev1informers.ConfigMapInformer
+	discoveryCache               *DiscoveryCache
+	refreshes                    *upstreamwatchers.ConnectionRevalidations
+}
+
+// NewDiscoveryRefresher instantiates a new controllerlib.Controller which will periodically fetch the discovery
+// document of each OIDCIdentityProvider again. The fresh discovery results are put into the provided DiscoveryCache,
+// which should be shared with the controller returned by New so that it starts using them. Any changes to the
+// discovered endpoints, or failures to fetch the discovery document, are reported by a condition on the
+// OIDCIdentityProvider.
+func NewDiscoveryRefresher(
+	discoveryCache *DiscoveryCache,
+	client pinnipedclientset.Interface,
+	oidcIdentityProviderInformer idpinformers.OIDCIdentityProviderInformer,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	log plog.Logger,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return newDiscoveryRefresher(
+		discoveryCache,
+		client,
+		oidcIdentityProviderInformer,
+		secretInformer,
+		configMapInformer,
+		clock.RealClock{},
+		log,
+		withInformer,
+	)
+}
+
+func newDiscoveryRefresher(
+	discoveryCache *DiscoveryCache,
+	client pinnipedclientset.Interface,
+	oidcIdentityProviderInformer idpinformers.OIDCIdentityProviderInformer,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	c clock.PassiveClock,
+	log plog.Logger,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: oidcDiscoveryRefresherControllerName,
+			Syncer: &oidcDiscoveryRefresherController{
+				log:                          log.WithName(oidcDiscoveryRefresherControllerName),
+				client:                       client,
+				oidcIdentityProviderInformer: oidcIdentityProviderInformer,
+				secretInformer:               secretInformer,
+				configMapInformer:            configMapInformer,
+				discoveryCache:               discoveryCache,
+				refreshes:                    upstreamwatchers.NewConnectionRevalidations(c),
+			},
+		},
+		withInformer(
+			oidcIdentityProviderInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *oidcDiscoveryRefresherController) Sync(ctx controllerlib.Context) error {
+	actualUpstreams, err := c.oidcIdentityProviderInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list OIDCIdentityProviders: %w", err)
+	}
+
+	var nextRefresh time.Duration
+	upstreamUIDs := sets.New[types.UID]()
+	for _, upstream := range actualUpstreams {
+		upstreamUIDs.Insert(upstream.UID)
+		refresh, untilNextRefresh := c.refreshes.Due(upstream.UID, int64(oidcDiscoveryRefreshInterval/time.Second))
+		if nextRefresh == 0 || untilNextRefresh < nextRefresh {
+			nextRefresh = untilNextRefresh
+		}
+		if !refresh {
+			continue
+		}
+		if condition := c.refreshDiscovery(ctx.Context, upstream); condition != nil {
+			c.updateStatus(ctx.Context, upstream, condition)
+		}
+	}
+
+	// Forget the refreshes of any upstreams which were deleted.
+	c.refreshes.Retain(upstreamUIDs)
+
+	// Sync again when the next refresh is due.
+	if nextRefresh > 0 {
+		ctx.Queue.AddAfter(ctx.Key, nextRefresh)
+	}
+	return nil
+}
+
+// refreshDiscovery fetches the discovery document of the upstream again and puts the result into the discovery cache.
+// It returns nil when the upstream's issuer, TLS, or proxy config is invalid, since the other controller already reports that.
+func (c *oidcDiscoveryRefresherController) refreshDiscovery(ctx context.Context, upstream *v1alpha1.OIDCIdentityProvider) *v1alpha1.Condition {
+	rootCAs, caBundle, _, err := upstreamwatchers.ReadCABundle(upstream.Spec.TLS, upstream.Namespace, c.secretInformer, c.configMapInformer)
+	if err != nil {
+		return nil
+	}
+	if _, issuerURLCondition := validateHTTPSURL(upstream.Spec.Issuer, "issuer", reasonUnreachable); issuerURLCondition != nil {
+		return nil
+	}
+
+	proxy, err := proxyFunc(upstream.Spec.Proxy)
+	if err != nil {
+		return nil
+	}
+
+	previousProvider, _ := c.discoveryCache.getProvider(upstream.Spec.Issuer, caBundle, upstream.Spec.Proxy)
+
+	httpClient := defaultClientShortTimeout(rootCAs, proxy)
+	discoveredProvider, err := coreosoidc.NewProvider(coreosoidc.ClientContext(ctx, httpClient), upstream.Spec.Issuer)
+	if err != nil {
+		c.log.DebugErr("failed to refresh OIDC discovery", err,
+			"namespace", upstream.Namespace,
+			"name", upstream.Name,
+			"issuer", upstream.Spec.Issuer,
+		)
+		return &v1alpha1.Condition{
+			Type:    typeOIDCDiscoveryRefreshed,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonUnreachable,
+			Message: fmt.Sprintf("failed to refresh OIDC discovery against %q:\n%s", upstream.Spec.Issuer, truncateMostLongErr(err)),
+		}
+	}
+
+	changedEndpoints, err := changedDiscoveredEndpoints(previousProvider, discoveredProvider)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeOIDCDiscoveryRefreshed,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidResponse,
+			Message: fmt.Sprintf("failed to unmarshal OIDC discovery response from %q:\n%s", upstream.Spec.Issuer, truncateMostLongErr(err)),
+		}
+	}
+
+	// Update the cache, so the other controller will use the fresh discovery results the next time that it syncs.
+	c.discoveryCache.putProvider(upstream.Spec.Issuer, caBundle, upstream.Spec.Proxy, discoveredProvider, httpClient)
+
+	if len(changedEndpoints) > 0 {
+		return &v1alpha1.Condition{
+			Type:    typeOIDCDiscoveryRefreshed,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  reasonDiscoveryChanged,
+			Message: fmt.Sprintf("refreshed issuer configuration, which changed: %s", strings.Join(changedEndpoints, ", ")),
+		}
+	}
+	return &v1alpha1.Condition{
+		Type:    typeOIDCDiscoveryRefreshed,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "refreshed issuer configuration",
+	}
+}
+
+// changedDiscoveredEndpoints returns the names of the discovery claims whose values differ between the providers.
+// A nil previous provider is not considered to be a change.
+func changedDiscoveredEndpoints(previous, current *coreosoidc.Provider) ([]string, error) {
+	if previous == nil {
+		return nil, nil
+	}
+	var previousEndpoints, currentEndpoints discoveredEndpoints
+	if err := previous.Claims(&previousEndpoints); err != nil {
+		return nil, err
+	}
+	if err := current.Claims(&currentEndpoints); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, claim := range []struct {
+		name              string
+		previous, current string
+	}{
+		{"authorization_endpoint", previousEndpoints.AuthorizationEndpoint, currentEndpoints.AuthorizationEndpoint},
+		{"jwks_uri", previousEndpoints.JWKSURI, currentEndpoints.JWKSURI},
+		{"pushed_authorization_request_endpoint", previousEndpoints.PushedAuthorizationRequestEndpoint, currentEndpoints.PushedAuthorizationRequestEndpoint},
+		{"revocation_endpoint", previousEndpoints.RevocationEndpoint, currentEndpoints.RevocationEndpoint},
+		{"token_endpoint", previousEndpoints.TokenEndpoint, currentEndpoints.TokenEndpoint},
+		{"userinfo_endpoint", previousEndpoints.UserInfoEndpoint, currentEndpoints.UserInfoEndpoint},
+	} {
+		if claim.previous != claim.current {
+			changed = append(changed, claim.name)
+		}
+	}
+	return changed, nil
+}
+
+func (c *oidcDiscoveryRefresherController) updateStatus(ctx context.Context, upstream *v1alpha1.OIDCIdentityProvider, condition *v1alpha1.Condition) {
+	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
+	updated := upstream.DeepCopy()
+
+	// The phase is left alone, since the other controller can continue to use the previous discovery results.
+	_ = conditionsutil.MergeIDPConditions([]*v1alpha1.Condition{condition}, upstream.Generation, &updated.Status.Conditions, log)
+
+	if equality.Semantic.DeepEqual(upstream, updated) {
+		return
+	}
+
+	_, err := c.client.
+		IDPV1alpha1().
+		OIDCIdentityProviders(upstream.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error("failed to update status", err)
+	}
+}
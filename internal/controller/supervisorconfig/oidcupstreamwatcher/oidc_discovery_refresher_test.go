@@ -0,0 +1,246 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcupstreamwatcher
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+)
+
+type recordingQueue struct {
+	addAfterDurations []time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *recordingQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.addAfterDurations = append(q.addAfterDurations, duration)
+}
+
+func TestOIDCDiscoveryRefresherControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const (
+		testNamespace = "test-namespace"
+		testName      = "test-name"
+		testUID       = types.UID("test-uid")
+
+		originalTokenURL = "https://example.com/token"
+		changedTokenURL  = "https://example.com/changed-token"
+	)
+
+	tests := []struct {
+		name                string
+		issuerPath          string
+		invalidTLS          bool
+		seedCache           bool
+		changeTokenEndpoint bool
+
+		wantCondition      *v1alpha1.Condition // nil means that the status should not be updated
+		wantCachedTokenURL string              // empty means that nothing should be cached
+	}{
+		{
+			name:      "discovery document is unchanged",
+			seedCache: true,
+			wantCondition: &v1alpha1.Condition{
+				Type:               "OIDCDiscoveryRefreshed",
+				Status:             "True",
+				Reason:             "Success",
+				Message:            "refreshed issuer configuration",
+				ObservedGeneration: 1234,
+			},
+			wantCachedTokenURL: originalTokenURL,
+		},
+		{
+			name:                "discovery document changed",
+			seedCache:           true,
+			changeTokenEndpoint: true,
+			wantCondition: &v1alpha1.Condition{
+				Type:               "OIDCDiscoveryRefreshed",
+				Status:             "True",
+				Reason:             "DiscoveryChanged",
+				Message:            "refreshed issuer configuration, which changed: token_endpoint",
+				ObservedGeneration: 1234,
+			},
+			wantCachedTokenURL: changedTokenURL,
+		},
+		{
+			name:                "nothing was cached yet",
+			changeTokenEndpoint: true,
+			wantCondition: &v1alpha1.Condition{
+				Type:               "OIDCDiscoveryRefreshed",
+				Status:             "True",
+				Reason:             "Success",
+				Message:            "refreshed issuer configuration",
+				ObservedGeneration: 1234,
+			},
+			wantCachedTokenURL: changedTokenURL,
+		},
+		{
+			name:       "discovery document cannot be fetched",
+			issuerPath: "/broken",
+			wantCondition: &v1alpha1.Condition{
+				Type:               "OIDCDiscoveryRefreshed",
+				Status:             "False",
+				Reason:             "Unreachable",
+				Message:            "failed to refresh OIDC discovery against \"ISSUER\":\n500 Internal Server Error: some discovery error",
+				ObservedGeneration: 1234,
+			},
+		},
+		{
+			name:       "upstream with an invalid TLS config is skipped",
+			invalidTLS: true,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var lock sync.Mutex
+			tokenURL := originalTokenURL
+			var issuerURL string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				w.Header().Set("content-type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"issuer":                 issuerURL,
+					"authorization_endpoint": "https://example.com/authorize",
+					"token_endpoint":         tokenURL,
+					"jwks_uri":               "https://example.com/jwks",
+				})
+			})
+			mux.HandleFunc("/broken/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("some discovery error"))
+			})
+			caBundlePEM, testURL := testutil.TLSTestServer(t, mux.ServeHTTP)
+			issuerURL = testURL + tt.issuerPath
+
+			caData := base64.StdEncoding.EncodeToString([]byte(caBundlePEM))
+			if tt.invalidTLS {
+				caData = "invalid base64-encoded data"
+			}
+			upstream := &v1alpha1.OIDCIdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: v1alpha1.OIDCIdentityProviderSpec{
+					Issuer: issuerURL,
+					TLS:    &v1alpha1.TLSSpec{CertificateAuthorityData: caData},
+				},
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			discoveryCache := NewDiscoveryCache()
+			if tt.seedCache {
+				rootCAs := x509.NewCertPool()
+				require.True(t, rootCAs.AppendCertsFromPEM([]byte(caBundlePEM)))
+				httpClient := defaultClientShortTimeout(rootCAs, nil)
+				discoveredProvider, err := coreosoidc.NewProvider(coreosoidc.ClientContext(ctx, httpClient), issuerURL)
+				require.NoError(t, err)
+				discoveryCache.putProvider(issuerURL, []byte(caBundlePEM), nil, discoveredProvider, httpClient)
+			}
+
+			fakePinnipedClient := pinnipedfake.NewSimpleClientset(upstream)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(fakePinnipedClient, 0)
+			fakeKubeClient := fake.NewSimpleClientset()
+			kubeInformers := informers.NewSharedInformerFactory(fakeKubeClient, 0)
+			fakeClock := clocktesting.NewFakeClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+			controller := newDiscoveryRefresher(
+				discoveryCache,
+				fakePinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().OIDCIdentityProviders(),
+				kubeInformers.Core().V1().Secrets(),
+				kubeInformers.Core().V1().ConfigMaps(),
+				fakeClock,
+				plog.TestLogger(t, io.Discard),
+				controllerlib.WithInformer,
+			)
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			queue := &recordingQueue{}
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{}, Queue: queue}
+
+			// The first sync only starts counting the interval, since the discovery was just performed by the
+			// other controller when the upstream was first seen.
+			require.NoError(t, controllerlib.TestSync(t, controller, syncCtx))
+			require.Empty(t, statusUpdates(fakePinnipedClient.Actions()))
+			require.Equal(t, []time.Duration{5 * time.Minute}, queue.addAfterDurations)
+
+			if tt.changeTokenEndpoint {
+				lock.Lock()
+				tokenURL = changedTokenURL
+				lock.Unlock()
+			}
+
+			// The next sync after the interval has passed refreshes the discovery.
+			fakeClock.Step(5 * time.Minute)
+			require.NoError(t, controllerlib.TestSync(t, controller, syncCtx))
+			require.Equal(t, []time.Duration{5 * time.Minute, 5 * time.Minute}, queue.addAfterDurations)
+
+			updates := statusUpdates(fakePinnipedClient.Actions())
+			if tt.wantCondition == nil {
+				require.Empty(t, updates)
+			} else {
+				require.Len(t, updates, 1)
+				require.Len(t, updates[0].Status.Conditions, 1)
+				actualCondition := updates[0].Status.Conditions[0]
+				actualCondition.LastTransitionTime = metav1.Time{}
+				wantCondition := *tt.wantCondition
+				wantCondition.Message = strings.ReplaceAll(wantCondition.Message, "ISSUER", issuerURL)
+				require.Equal(t, wantCondition, actualCondition)
+				require.Empty(t, updates[0].Status.Phase, "the phase should be left alone")
+			}
+
+			cachedProvider, _ := discoveryCache.getProvider(issuerURL, []byte(caBundlePEM), nil)
+			if tt.wantCachedTokenURL == "" {
+				require.Nil(t, cachedProvider)
+			} else {
+				require.NotNil(t, cachedProvider)
+				require.Equal(t, tt.wantCachedTokenURL, cachedProvider.Endpoint().TokenURL)
+			}
+		})
+	}
+}
+
+func statusUpdates(actions []coretesting.Action) []*v1alpha1.OIDCIdentityProvider {
+	var updates []*v1alpha1.OIDCIdentityProvider
+	for _, action := range actions {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			updates = append(updates, action.(coretesting.UpdateAction).GetObject().(*v1alpha1.OIDCIdentityProvider))
+		}
+	}
+	return updates
+}
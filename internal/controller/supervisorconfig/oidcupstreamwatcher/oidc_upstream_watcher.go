@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package oidcupstreamwatcher implements a controller which watches OIDCIdentityProviders.
@@ -7,7 +7,6 @@ package oidcupstreamwatcher
 import (
 	"context"
 	"crypto/x509"
-	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -29,6 +28,7 @@ import (
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
 	idpinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/idp/v1alpha1"
+	"go.pinniped.dev/internal/claimexpressions"
 	"go.pinniped.dev/internal/constable"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controller/conditionsutil"
@@ -47,20 +47,39 @@ const (
 	// Constants related to the client credentials Secret.
 	oidcClientSecretType corev1.SecretType = "secrets.pinniped.dev/oidc-client"
 
+	// Constants related to the ID token decryption key Secret.
+	idTokenDecryptionKeySecretType corev1.SecretType = "secrets.pinniped.dev/oidc-id-token-decryption-key"
+	jwkDataKey                                       = "jwk"
+
 	clientIDDataKey     = "clientID"
 	clientSecretDataKey = "clientSecret"
+	privateKeyDataKey   = "privateKey"
+	keyIDDataKey        = "keyID"
 
 	// Constants related to the OIDC provider discovery cache. These do not affect the cache of JWKS.
 	oidcValidatorCacheTTL = 15 * time.Minute
 
+	// Constants related to the retry policy defaults.
+	defaultRetryInitialBackoff = 250 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+
 	// Constants related to conditions.
 	typeClientCredentialsValid             = "ClientCredentialsValid" //nolint:gosec // this is not a credential
 	typeAdditionalAuthorizeParametersValid = "AdditionalAuthorizeParametersValid"
 	typeOIDCDiscoverySucceeded             = "OIDCDiscoverySucceeded"
+	typeClaimExpressionsValid              = "ClaimExpressionsValid"
+	typeIDTokenDecryptionKeyValid          = "IDTokenDecryptionKeyValid"
+	typeRetryPolicyValid                   = "RetryPolicyValid"
 
 	reasonUnreachable             = "Unreachable"
 	reasonInvalidResponse         = "InvalidResponse"
 	reasonDisallowedParameterName = "DisallowedParameterName"
+	reasonInvalidCredentials      = "InvalidCredentials"
+	reasonUnsupportedFeature      = "UnsupportedFeature"
+	reasonInvalidClaimExpression  = "InvalidClaimExpression"
+	reasonInvalidKey              = "InvalidKey"
+	reasonInvalidProxyConfig      = "InvalidProxyConfig"
+	reasonInvalidRetryPolicy      = "InvalidRetryPolicy"
 	allParamNamesAllowedMsg       = "additionalAuthorizeParameters parameter names are allowed"
 
 	// Errors that are generated by our reconcile process.
@@ -87,6 +106,16 @@ var (
 		// performs the corresponding validation on the ID token.
 		"hd": true,
 	}
+
+	// defaultRetryableStatusCodes are retried when the retry policy does not specify its own status codes. These are
+	// the statuses which commonly indicate a temporary problem of the server or of a load balancer in front of it.
+	defaultRetryableStatusCodes = []int32{ //nolint:gochecknoglobals
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
 )
 
 // UpstreamOIDCIdentityProviderICache is a thread safe cache that holds a list of validated upstream OIDC IDP configurations.
@@ -94,31 +123,44 @@ type UpstreamOIDCIdentityProviderICache interface {
 	SetOIDCIdentityProviders([]provider.UpstreamOIDCIdentityProviderI)
 }
 
-// lruValidatorCache caches the *oidc.Provider associated with a particular issuer/TLS configuration.
+// lruValidatorCache caches the *oidc.Provider associated with a particular issuer/CA bundle/proxy.
 type lruValidatorCache struct{ cache *cache.Expiring }
 
+// DiscoveryCache caches the results of OIDC discovery for each issuer/CA bundle/proxy. It can be shared between the
+// controller which validates the OIDCIdentityProviders and the controller which periodically refreshes their
+// discovery, so that the refreshed results are used by the validating controller.
+type DiscoveryCache struct{ lruValidatorCache }
+
+func NewDiscoveryCache() *DiscoveryCache {
+	return &DiscoveryCache{lruValidatorCache{cache: cache.NewExpiring()}}
+}
+
 type lruValidatorCacheEntry struct {
 	provider *coreosoidc.Provider
 	client   *http.Client
 }
 
-func (c *lruValidatorCache) getProvider(spec *v1alpha1.OIDCIdentityProviderSpec) (*coreosoidc.Provider, *http.Client) {
-	if result, ok := c.cache.Get(c.cacheKey(spec)); ok {
+func (c *lruValidatorCache) getProvider(issuer string, caBundle []byte, proxy *v1alpha1.OIDCProxy) (*coreosoidc.Provider, *http.Client) {
+	if result, ok := c.cache.Get(c.cacheKey(issuer, caBundle, proxy)); ok {
 		entry := result.(*lruValidatorCacheEntry)
 		return entry.provider, entry.client
 	}
 	return nil, nil
 }
 
-func (c *lruValidatorCache) putProvider(spec *v1alpha1.OIDCIdentityProviderSpec, provider *coreosoidc.Provider, client *http.Client) {
-	c.cache.Set(c.cacheKey(spec), &lruValidatorCacheEntry{provider: provider, client: client}, oidcValidatorCacheTTL)
+func (c *lruValidatorCache) putProvider(issuer string, caBundle []byte, proxy *v1alpha1.OIDCProxy, provider *coreosoidc.Provider, client *http.Client) {
+	c.cache.Set(c.cacheKey(issuer, caBundle, proxy), &lruValidatorCacheEntry{provider: provider, client: client}, oidcValidatorCacheTTL)
 }
 
-func (c *lruValidatorCache) cacheKey(spec *v1alpha1.OIDCIdentityProviderSpec) interface{} {
-	var key struct{ issuer, caBundle string }
-	key.issuer = spec.Issuer
-	if spec.TLS != nil {
-		key.caBundle = spec.TLS.CertificateAuthorityData
+// cacheKey uses the CA bundle itself rather than the TLS spec, since the spec may refer to a Secret or ConfigMap
+// whose contents can change. The proxy is part of the key because the cached HTTP client uses it.
+func (c *lruValidatorCache) cacheKey(issuer string, caBundle []byte, proxy *v1alpha1.OIDCProxy) interface{} {
+	var key struct{ issuer, caBundle, proxyURL, noProxy string }
+	key.issuer = issuer
+	key.caBundle = string(caBundle)
+	if proxy != nil {
+		key.proxyURL = proxy.URL
+		key.noProxy = strings.Join(proxy.NoProxy, ",")
 	}
 	return key
 }
@@ -129,9 +171,10 @@ type oidcWatcherController struct {
 	client                       pinnipedclientset.Interface
 	oidcIdentityProviderInformer idpinformers.OIDCIdentityProviderInformer
 	secretInformer               corev1informers.SecretInformer
+	configMapInformer            corev1informers.ConfigMapInformer
 	validatorCache               interface {
-		getProvider(*v1alpha1.OIDCIdentityProviderSpec) (*coreosoidc.Provider, *http.Client)
-		putProvider(*v1alpha1.OIDCIdentityProviderSpec, *coreosoidc.Provider, *http.Client)
+		getProvider(string, []byte, *v1alpha1.OIDCProxy) (*coreosoidc.Provider, *http.Client)
+		putProvider(string, []byte, *v1alpha1.OIDCProxy, *coreosoidc.Provider, *http.Client)
 	}
 }
 
@@ -141,16 +184,23 @@ func New(
 	client pinnipedclientset.Interface,
 	oidcIdentityProviderInformer idpinformers.OIDCIdentityProviderInformer,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	discoveryCache *DiscoveryCache,
 	log logr.Logger,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
+	if discoveryCache == nil {
+		// start with an empty in-memory cache
+		discoveryCache = NewDiscoveryCache()
+	}
 	c := oidcWatcherController{
 		cache:                        idpCache,
 		log:                          log.WithName(oidcControllerName),
 		client:                       client,
 		oidcIdentityProviderInformer: oidcIdentityProviderInformer,
 		secretInformer:               secretInformer,
-		validatorCache:               &lruValidatorCache{cache: cache.NewExpiring()},
+		configMapInformer:            configMapInformer,
+		validatorCache:               discoveryCache,
 	}
 	return controllerlib.New(
 		controllerlib.Config{Name: oidcControllerName, Syncer: &c},
@@ -161,7 +211,15 @@ func New(
 		),
 		withInformer(
 			secretInformer,
-			pinnipedcontroller.MatchAnySecretOfTypeFilter(oidcClientSecretType, pinnipedcontroller.SingletonQueue()),
+			pinnipedcontroller.MatchAnySecretOfTypesFilter(
+				[]corev1.SecretType{oidcClientSecretType, idTokenDecryptionKeySecretType, corev1.SecretTypeOpaque, corev1.SecretTypeTLS},
+				pinnipedcontroller.SingletonQueue(),
+			),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
 			controllerlib.InformerOption{},
 		),
 	)
@@ -218,6 +276,12 @@ func (c *oidcWatcherController) validateUpstream(ctx controllerlib.Context, upst
 		AdditionalClaimMappings:  upstream.Spec.Claims.AdditionalClaimMappings,
 		ResourceUID:              upstream.UID,
 	}
+	if graphGroups := upstream.Spec.Claims.MicrosoftGraphGroups; graphGroups != nil {
+		result.MicrosoftGraphURL = upstreamoidc.DefaultMicrosoftGraphURL
+		if graphGroups.URL != "" {
+			result.MicrosoftGraphURL = graphGroups.URL
+		}
+	}
 
 	conditions := []*v1alpha1.Condition{
 		c.validateSecret(upstream, &result),
@@ -239,6 +303,15 @@ func (c *oidcWatcherController) validateUpstream(ctx controllerlib.Context, upst
 			Message: allParamNamesAllowedMsg,
 		})
 	}
+	if claimExpressionsCondition := validateClaimExpressions(upstream, &result); claimExpressionsCondition != nil {
+		conditions = append(conditions, claimExpressionsCondition)
+	}
+	if idTokenDecryptionCondition := c.validateIDTokenDecryption(upstream, &result); idTokenDecryptionCondition != nil {
+		conditions = append(conditions, idTokenDecryptionCondition)
+	}
+	if retryPolicyCondition := validateRetryPolicy(upstream, &result); retryPolicyCondition != nil {
+		conditions = append(conditions, retryPolicyCondition)
+	}
 
 	c.updateStatus(ctx.Context, upstream, conditions)
 
@@ -260,6 +333,149 @@ func (c *oidcWatcherController) validateUpstream(ctx controllerlib.Context, upst
 	return nil
 }
 
+// validateClaimExpressions compiles the .spec.claims.expressions field and returns the appropriate ClaimExpressionsValid
+// condition. It returns nil when there are no expressions, in which case the condition is not used.
+func validateClaimExpressions(upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+	expressions := upstream.Spec.Claims.Expressions
+	if expressions == nil {
+		return nil
+	}
+
+	compiled, err := claimexpressions.Compile(expressions.Username, expressions.Groups, expressions.AdditionalClaims)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClaimExpressionsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidClaimExpression,
+			Message: err.Error(),
+		}
+	}
+
+	result.ClaimExpressions = compiled
+	return &v1alpha1.Condition{
+		Type:    typeClaimExpressionsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "claim expressions are valid",
+	}
+}
+
+// validateRetryPolicy validates the .spec.retryPolicy field and returns the appropriate RetryPolicyValid condition.
+// It returns nil when there is no retry policy, in which case the condition is not used.
+func validateRetryPolicy(upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+	spec := upstream.Spec.RetryPolicy
+	if spec == nil {
+		return nil
+	}
+
+	policy := upstreamoidc.RetryPolicy{
+		MaxAttempts:          int(spec.MaxAttempts),
+		InitialBackoff:       defaultRetryInitialBackoff,
+		MaxBackoff:           defaultRetryMaxBackoff,
+		RetryableStatusCodes: map[int]bool{},
+	}
+	if spec.InitialBackoffMilliseconds > 0 {
+		policy.InitialBackoff = time.Duration(spec.InitialBackoffMilliseconds) * time.Millisecond
+	}
+	if spec.MaxBackoffMilliseconds > 0 {
+		policy.MaxBackoff = time.Duration(spec.MaxBackoffMilliseconds) * time.Millisecond
+	}
+	statusCodes := spec.RetryableStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryableStatusCodes
+	}
+
+	var errs []string
+	if spec.MaxAttempts < 1 {
+		errs = append(errs, fmt.Sprintf("spec.retryPolicy.maxAttempts must be at least 1, but was %d", spec.MaxAttempts))
+	}
+	if policy.InitialBackoff > policy.MaxBackoff {
+		errs = append(errs, fmt.Sprintf("spec.retryPolicy initial backoff (%s) must not be greater than max backoff (%s)",
+			policy.InitialBackoff, policy.MaxBackoff))
+	}
+	for _, code := range statusCodes {
+		if code < 400 || code > 599 {
+			errs = append(errs, fmt.Sprintf("spec.retryPolicy.retryableStatusCodes must be between 400 and 599, but contained %d", code))
+			continue
+		}
+		policy.RetryableStatusCodes[int(code)] = true
+	}
+	if len(errs) > 0 {
+		return &v1alpha1.Condition{
+			Type:    typeRetryPolicyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidRetryPolicy,
+			Message: strings.Join(errs, "; "),
+		}
+	}
+
+	result.RetryPolicy = &policy
+	return &v1alpha1.Condition{
+		Type:    typeRetryPolicyValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "retry policy is valid",
+	}
+}
+
+// validateIDTokenDecryption validates the .spec.idTokenDecryption field and returns the appropriate
+// IDTokenDecryptionKeyValid condition. It returns nil when ID token decryption is not configured, in which case
+// the condition is not used.
+func (c *oidcWatcherController) validateIDTokenDecryption(upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+	idTokenDecryption := upstream.Spec.IDTokenDecryption
+	if idTokenDecryption == nil {
+		return nil
+	}
+	secretName := idTokenDecryption.SecretName
+
+	secret, err := c.secretInformer.Lister().Secrets(upstream.Namespace).Get(secretName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeIDTokenDecryptionKeyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonNotFound,
+			Message: err.Error(),
+		}
+	}
+
+	if secret.Type != idTokenDecryptionKeySecretType {
+		return &v1alpha1.Condition{
+			Type:    typeIDTokenDecryptionKeyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonWrongType,
+			Message: fmt.Sprintf("referenced Secret %q has wrong type %q (should be %q)", secretName, secret.Type, idTokenDecryptionKeySecretType),
+		}
+	}
+
+	jwk := secret.Data[jwkDataKey]
+	if len(jwk) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeIDTokenDecryptionKeyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{jwkDataKey}),
+		}
+	}
+
+	decrypter, err := upstreamoidc.NewIDTokenDecrypter(jwk)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeIDTokenDecryptionKeyValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidKey,
+			Message: fmt.Sprintf("referenced Secret %q has invalid %q: %s", secretName, jwkDataKey, err.Error()),
+		}
+	}
+
+	result.IDTokenDecrypter = decrypter
+	return &v1alpha1.Condition{
+		Type:    typeIDTokenDecryptionKeyValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "loaded ID token decryption key",
+	}
+}
+
 // validateSecret validates the .spec.client.secretName field and returns the appropriate ClientCredentialsValid condition.
 func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
 	secretName := upstream.Spec.Client.SecretName
@@ -285,6 +501,11 @@ func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityPr
 		}
 	}
 
+	authenticationMethod := upstream.Spec.Client.AuthenticationMethod
+	if authenticationMethod == v1alpha1.OIDCClientAuthenticationMethodPrivateKeyJWT {
+		return c.validatePrivateKeySecret(secretName, secret, result)
+	}
+
 	// Validate the secret .data field.
 	clientID := secret.Data[clientIDDataKey]
 	clientSecret := secret.Data[clientSecretDataKey]
@@ -299,7 +520,58 @@ func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityPr
 
 	// If everything is valid, update the result and set the condition to true.
 	result.Config.ClientID = string(clientID)
-	result.Config.ClientSecret = string(clientSecret)
+	result.ClientAuthenticationMethod = upstreamoidc.ClientAuthenticationMethod(authenticationMethod)
+	if authenticationMethod == v1alpha1.OIDCClientAuthenticationMethodClientSecretJWT {
+		signer, err := upstreamoidc.NewClientSecretJWTSigner(string(clientSecret))
+		if err != nil {
+			// This shouldn't really happen since any non-empty secret can be used as an HMAC key.
+			return &v1alpha1.Condition{
+				Type:    typeClientCredentialsValid,
+				Status:  v1alpha1.ConditionFalse,
+				Reason:  reasonInvalidCredentials,
+				Message: fmt.Sprintf("referenced Secret %q has invalid %q: %s", secretName, clientSecretDataKey, err.Error()),
+			}
+		}
+		result.ClientAssertionSigner = signer
+	} else {
+		result.Config.ClientSecret = string(clientSecret)
+	}
+	return &v1alpha1.Condition{
+		Type:    typeClientCredentialsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  upstreamwatchers.ReasonSuccess,
+		Message: "loaded client credentials",
+	}
+}
+
+// validatePrivateKeySecret validates the .data field of a Secret which is used by the private_key_jwt client
+// authentication method and returns the appropriate ClientCredentialsValid condition.
+func (c *oidcWatcherController) validatePrivateKeySecret(secretName string, secret *corev1.Secret, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+	clientID := secret.Data[clientIDDataKey]
+	privateKey := secret.Data[privateKeyDataKey]
+	if len(clientID) == 0 || len(privateKey) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{clientIDDataKey, privateKeyDataKey}),
+		}
+	}
+
+	signer, err := upstreamoidc.NewPrivateKeyJWTSigner(privateKey, string(secret.Data[keyIDDataKey]))
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeClientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidCredentials,
+			Message: fmt.Sprintf("referenced Secret %q has invalid %q: %s", secretName, privateKeyDataKey, err.Error()),
+		}
+	}
+
+	// If everything is valid, update the result and set the condition to true.
+	result.Config.ClientID = string(clientID)
+	result.ClientAuthenticationMethod = upstreamoidc.ClientAuthenticationMethodPrivateKeyJWT
+	result.ClientAssertionSigner = signer
 	return &v1alpha1.Condition{
 		Type:    typeClientCredentialsValid,
 		Status:  v1alpha1.ConditionTrue,
@@ -310,21 +582,32 @@ func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityPr
 
 // validateIssuer validates the .spec.issuer field, performs OIDC discovery, and returns the appropriate OIDCDiscoverySucceeded condition.
 func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+	rootCAs, caBundle, _, err := upstreamwatchers.ReadCABundle(upstream.Spec.TLS, upstream.Namespace, c.secretInformer, c.configMapInformer)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeOIDCDiscoverySucceeded,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  upstreamwatchers.ReasonInvalidTLSConfig,
+			Message: fmt.Sprintf("spec.%s", err.Error()),
+		}
+	}
+
+	proxy, err := proxyFunc(upstream.Spec.Proxy)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeOIDCDiscoverySucceeded,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidProxyConfig,
+			Message: fmt.Sprintf("spec.proxy.url (%q) is invalid: %s", upstream.Spec.Proxy.URL, err.Error()),
+		}
+	}
+
 	// Get the provider and HTTP Client from cache if possible.
-	discoveredProvider, httpClient := c.validatorCache.getProvider(&upstream.Spec)
+	discoveredProvider, httpClient := c.validatorCache.getProvider(upstream.Spec.Issuer, caBundle, upstream.Spec.Proxy)
 
 	// If the provider does not exist in the cache, do a fresh discovery lookup and save to the cache.
 	if discoveredProvider == nil {
-		var err error
-		httpClient, err = getClient(upstream)
-		if err != nil {
-			return &v1alpha1.Condition{
-				Type:    typeOIDCDiscoverySucceeded,
-				Status:  v1alpha1.ConditionFalse,
-				Reason:  upstreamwatchers.ReasonInvalidTLSConfig,
-				Message: err.Error(),
-			}
-		}
+		httpClient = defaultClientShortTimeout(rootCAs, proxy)
 
 		_, issuerURLCondition := validateHTTPSURL(upstream.Spec.Issuer, "issuer", reasonUnreachable)
 		if issuerURLCondition != nil {
@@ -347,13 +630,16 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 		}
 
 		// Update the cache with the newly discovered value.
-		c.validatorCache.putProvider(&upstream.Spec, discoveredProvider, httpClient)
+		c.validatorCache.putProvider(upstream.Spec.Issuer, caBundle, upstream.Spec.Proxy, discoveredProvider, httpClient)
 	}
 
 	// Get the revocation endpoint, if there is one. Many providers do not offer a revocation endpoint.
+	// Likewise, get the pushed authorization request endpoint, if there is one.
 	var additionalDiscoveryClaims struct {
 		// "revocation_endpoint" is specified by https://datatracker.ietf.org/doc/html/rfc8414#section-2
 		RevocationEndpoint string `json:"revocation_endpoint"`
+		// "pushed_authorization_request_endpoint" is specified by https://datatracker.ietf.org/doc/html/rfc9126#section-5
+		PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
 	}
 	if err := discoveredProvider.Claims(&additionalDiscoveryClaims); err != nil {
 		// This shouldn't actually happen because the above call to NewProvider() would have already returned this error.
@@ -378,6 +664,31 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 		result.RevocationURL = revocationURL
 	}
 
+	parMode := upstream.Spec.AuthorizationConfig.PushedAuthorizationRequests
+	switch {
+	case parMode == v1alpha1.OIDCPushedAuthorizationRequestsDisabled:
+		// Do not use pushed authorization requests, even when the provider offers them.
+	case additionalDiscoveryClaims.PushedAuthorizationRequestEndpoint != "":
+		// Found a pushed authorization request URL. Validate it.
+		parURL, parURLCondition := validateHTTPSURL(
+			additionalDiscoveryClaims.PushedAuthorizationRequestEndpoint,
+			"pushed authorization request endpoint",
+			reasonInvalidResponse,
+		)
+		if parURLCondition != nil {
+			return parURLCondition
+		}
+		// Remember the URL for later use.
+		result.PushedAuthorizationRequestURL = parURL
+	case parMode == v1alpha1.OIDCPushedAuthorizationRequestsRequired:
+		return &v1alpha1.Condition{
+			Type:    typeOIDCDiscoverySucceeded,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonUnsupportedFeature,
+			Message: fmt.Sprintf("spec.authorizationConfig.pushedAuthorizationRequests is %q but the OIDC discovery response from %q does not include a pushed_authorization_request_endpoint", parMode, upstream.Spec.Issuer),
+		}
+	}
+
 	_, authorizeURLCondition := validateHTTPSURL(
 		discoveredProvider.Endpoint().AuthURL,
 		"authorization endpoint",
@@ -398,6 +709,7 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 
 	// If everything is valid, update the result and set the condition to true.
 	result.Config.Endpoint = discoveredProvider.Endpoint()
+	result.Config.Endpoint.AuthStyle = authStyle(upstream.Spec.Client.AuthenticationMethod)
 	result.Provider = discoveredProvider
 	result.Client = httpClient
 	return &v1alpha1.Condition{
@@ -432,28 +744,39 @@ func (c *oidcWatcherController) updateStatus(ctx context.Context, upstream *v1al
 	}
 }
 
-func getClient(upstream *v1alpha1.OIDCIdentityProvider) (*http.Client, error) {
-	if upstream.Spec.TLS == nil || upstream.Spec.TLS.CertificateAuthorityData == "" {
-		return defaultClientShortTimeout(nil), nil
-	}
-
-	bundle, err := base64.StdEncoding.DecodeString(upstream.Spec.TLS.CertificateAuthorityData)
-	if err != nil {
-		return nil, fmt.Errorf("spec.certificateAuthorityData is invalid: %w", err)
+func defaultClientShortTimeout(rootCAs *x509.CertPool, proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	var c *http.Client
+	if proxy != nil {
+		c = phttp.DefaultWithProxy(rootCAs, proxy)
+	} else {
+		c = phttp.Default(rootCAs)
 	}
+	c.Timeout = time.Minute
+	return c
+}
 
-	rootCAs := x509.NewCertPool()
-	if !rootCAs.AppendCertsFromPEM(bundle) {
-		return nil, fmt.Errorf("spec.certificateAuthorityData is invalid: %w", upstreamwatchers.ErrNoCertificates)
+// proxyFunc returns the function which chooses the proxy for the requests to the upstream, or nil when the
+// upstream does not configure a proxy, in which case the proxy environment variables are used.
+func proxyFunc(proxy *v1alpha1.OIDCProxy) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == nil {
+		return nil, nil
 	}
-
-	return defaultClientShortTimeout(rootCAs), nil
+	return phttp.ProxyFunc(proxy.URL, proxy.NoProxy)
 }
 
-func defaultClientShortTimeout(rootCAs *x509.CertPool) *http.Client {
-	c := phttp.Default(rootCAs)
-	c.Timeout = time.Minute
-	return c
+// authStyle returns how the oauth2 library should send the client credentials to the token endpoint. The client
+// assertions of the JWT methods are sent in the request params, so the library must not use basic auth for them.
+func authStyle(authenticationMethod v1alpha1.OIDCClientAuthenticationMethod) oauth2.AuthStyle {
+	switch authenticationMethod {
+	case v1alpha1.OIDCClientAuthenticationMethodClientSecretBasic:
+		return oauth2.AuthStyleInHeader
+	case v1alpha1.OIDCClientAuthenticationMethodClientSecretPost,
+		v1alpha1.OIDCClientAuthenticationMethodClientSecretJWT,
+		v1alpha1.OIDCClientAuthenticationMethodPrivateKeyJWT:
+		return oauth2.AuthStyleInParams
+	default:
+		return oauth2.AuthStyleAutoDetect
+	}
 }
 
 func computeScopes(additionalScopes []string) []string {
@@ -0,0 +1,100 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/events"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/i18n"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	translationsInvalidReason = "TranslationsInvalid"
+	translationsEventAction   = "ValidateTranslations"
+)
+
+type translationsObserverController struct {
+	translations      *i18n.Catalogs
+	configMapName     string
+	namespace         string
+	configMapInformer corev1informers.ConfigMapInformer
+	eventRecorder     events.EventRecorder
+
+	// The resourceVersion of the last invalid ConfigMap which was reported by an Event, so that resyncs of the same
+	// invalid ConfigMap do not report it again.
+	lastReportedResourceVersion string
+}
+
+// NewTranslationsObserverController returns a controller which validates the additional translation catalogs in
+// the named ConfigMap and loads them into the translations, so that changes to the ConfigMap are applied to the HTML
+// pages without a restart. When the ConfigMap is deleted, only the built-in catalogs are used. When the ConfigMap
+// becomes invalid, the previous valid catalogs stay in use and a Warning Event is recorded regarding the ConfigMap.
+func NewTranslationsObserverController(
+	translations *i18n.Catalogs,
+	configMapName string,
+	namespace string,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.EventRecorder,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "translations-observer-controller",
+			Syncer: &translationsObserverController{
+				translations:      translations,
+				configMapName:     configMapName,
+				namespace:         namespace,
+				configMapInformer: configMapInformer,
+				eventRecorder:     eventRecorder,
+			},
+		},
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(configMapName, namespace),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+func (c *translationsObserverController) Sync(_ controllerlib.Context) error {
+	configMap, err := c.configMapInformer.Lister().ConfigMaps(c.namespace).Get(c.configMapName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get %s/%s configmap: %w", c.namespace, c.configMapName, err)
+	}
+	if notFound {
+		plog.Info("translationsObserverController Sync found that the configmap does not exist yet or was deleted",
+			"configmap", c.configMapName, "namespace", c.namespace)
+		c.translations.SetAdditional(nil)
+		c.lastReportedResourceVersion = ""
+		return nil
+	}
+
+	catalogs, err := i18n.FromConfigMap(configMap)
+	if err != nil {
+		// Keep using the previous valid catalogs. Returning an error would only retry the same invalid ConfigMap.
+		plog.WarningErr("translationsObserverController Sync found an invalid translations configmap", err,
+			"configmap", c.configMapName, "namespace", c.namespace)
+		if configMap.ResourceVersion != c.lastReportedResourceVersion {
+			c.eventRecorder.Eventf(configMap, nil, corev1.EventTypeWarning, translationsInvalidReason, translationsEventAction,
+				"translations are invalid and were not applied: %s", err.Error())
+			c.lastReportedResourceVersion = configMap.ResourceVersion
+		}
+		return nil
+	}
+
+	c.translations.SetAdditional(catalogs)
+	c.lastReportedResourceVersion = ""
+	plog.Info("translationsObserverController Sync updated the translations",
+		"configmap", c.configMapName, "namespace", c.namespace)
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/i18n"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestTranslationsObserverControllerInformerFilters(t *testing.T) {
+	observableWithInformerOption := testutil.NewObservableWithInformerOption()
+	configMapInformer := kubeinformers.NewSharedInformerFactory(nil, 0).Core().V1().ConfigMaps()
+	_ = NewTranslationsObserverController(
+		nil,
+		"some-configmap",
+		"some-namespace",
+		configMapInformer,
+		nil,
+		observableWithInformerOption.WithInformer, // make it possible to observe the behavior of the Filters
+	)
+	filter := observableWithInformerOption.GetFilterForInformer(configMapInformer)
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-configmap", Namespace: "some-namespace"}}
+	wrongName := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other-configmap", Namespace: "some-namespace"}}
+	wrongNamespace := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-configmap", Namespace: "other-namespace"}}
+
+	require.True(t, filter.Add(configMap))
+	require.True(t, filter.Update(wrongName, configMap))
+	require.True(t, filter.Delete(configMap))
+	require.False(t, filter.Add(wrongName))
+	require.False(t, filter.Add(wrongNamespace))
+	require.False(t, filter.Update(wrongName, wrongNamespace))
+	require.False(t, filter.Delete(wrongNamespace))
+}
+
+func TestTranslationsObserverControllerSync(t *testing.T) {
+	const (
+		configMapName = "some-configmap"
+		namespace     = "some-namespace"
+	)
+
+	previousCatalogs := map[string]i18n.Catalog{"fr": {"login.submit": "Se connecter"}}
+
+	newConfigMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "1"},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		configMaps     []*corev1.ConfigMap
+		cachedCatalogs map[string]i18n.Catalog
+		syncs          int
+		wantFrench     string
+		wantEvents     []string
+	}{
+		{
+			name:           "the configmap does not exist",
+			configMaps:     []*corev1.ConfigMap{newConfigMap("other-configmap", map[string]string{"fr.yaml": "login.submit: Connexion\n"})},
+			cachedCatalogs: previousCatalogs,
+			wantFrench:     "Log in",
+			wantEvents:     []string{},
+		},
+		{
+			name:           "the configmap is valid",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{"fr.yaml": "login.submit: Connexion\n"})},
+			cachedCatalogs: previousCatalogs,
+			wantFrench:     "Connexion",
+			wantEvents:     []string{},
+		},
+		{
+			name:           "the configmap is invalid",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{"fr.yaml": "login.sumbit: Connexion\n"})},
+			cachedCatalogs: previousCatalogs,
+			wantFrench:     "Se connecter",
+			wantEvents: []string{
+				`Warning TranslationsInvalid translations are invalid and were not applied: key "fr.yaml": unknown message IDs: login.sumbit`,
+			},
+		},
+		{
+			name:           "the configmap is invalid and is synced again without changing",
+			configMaps:     []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{"fr.yaml": "login.sumbit: Connexion\n"})},
+			cachedCatalogs: previousCatalogs,
+			syncs:          3,
+			wantFrench:     "Se connecter",
+			wantEvents: []string{
+				`Warning TranslationsInvalid translations are invalid and were not applied: key "fr.yaml": unknown message IDs: login.sumbit`,
+			},
+		},
+		{
+			name:       "the configmap is invalid and there are no previous catalogs",
+			configMaps: []*corev1.ConfigMap{newConfigMap(configMapName, map[string]string{"french.json": "{}"})},
+			wantFrench: "Log in",
+			wantEvents: []string{
+				`Warning TranslationsInvalid translations are invalid and were not applied: key "french.json" must be named after a language tag, e.g. fr.yaml`,
+			},
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformerClient := kubernetesfake.NewSimpleClientset()
+			for _, configMap := range tt.configMaps {
+				require.NoError(t, kubeInformerClient.Tracker().Add(configMap))
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
+
+			translations := i18n.NewCatalogs()
+			translations.SetAdditional(tt.cachedCatalogs)
+			eventRecorder := events.NewFakeRecorder(100)
+
+			subject := NewTranslationsObserverController(
+				translations,
+				configMapName,
+				namespace,
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
+				controllerlib.WithInformer,
+			)
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			syncContext := controllerlib.Context{
+				Context: ctx,
+				Name:    subject.Name(),
+				Key:     controllerlib.Key{Namespace: namespace, Name: configMapName},
+			}
+			syncs := tt.syncs
+			if syncs == 0 {
+				syncs = 1
+			}
+			for i := 0; i < syncs; i++ {
+				require.NoError(t, controllerlib.TestSync(t, subject, syncContext))
+			}
+
+			frenchRequest := httptest.NewRequest(http.MethodGet, "/", nil)
+			frenchRequest.Header.Set("Accept-Language", "fr")
+			require.Equal(t, tt.wantFrench, translations.ForRequest(frenchRequest).Text("login.submit"))
+
+			recorded := []string{}
+			for len(eventRecorder.Events) > 0 {
+				recorded = append(recorded, <-eventRecorder.Events)
+			}
+			require.Equal(t, tt.wantEvents, recorded)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/ratelimit"
+	"go.pinniped.dev/internal/upstreamldap"
+)
+
+// AuthenticationRateLimiters holds the authentication rate limiter of each identity provider. The watchers create
+// a new Provider each time that they validate an identity provider, so the limiters must be kept here for the
+// limits to apply across those Providers.
+type AuthenticationRateLimiters struct {
+	lock  sync.Mutex
+	byUID map[types.UID]*upstreamldap.AuthenticationRateLimiter
+}
+
+func NewAuthenticationRateLimiters() *AuthenticationRateLimiters {
+	return &AuthenticationRateLimiters{byUID: map[types.UID]*upstreamldap.AuthenticationRateLimiter{}}
+}
+
+// ForUpstream returns the authentication rate limiter of the identity provider with the given UID. The existing
+// limiter is returned when the limits have not changed, or else a new limiter is created. Returns nil when
+// there are no limits.
+func (r *AuthenticationRateLimiters) ForUpstream(uid types.UID, perUsername, perClientIP *v1alpha1.AuthenticationRateLimit) *upstreamldap.AuthenticationRateLimiter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	config := upstreamldap.AuthenticationRateLimitConfig{
+		PerUsername: rateLimit(perUsername),
+		PerClientIP: rateLimit(perClientIP),
+	}
+	if config == (upstreamldap.AuthenticationRateLimitConfig{}) {
+		delete(r.byUID, uid)
+		return nil
+	}
+
+	if limiter, found := r.byUID[uid]; found && limiter.Config() == config {
+		return limiter
+	}
+	limiter := upstreamldap.NewAuthenticationRateLimiter(config)
+	r.byUID[uid] = limiter
+	return limiter
+}
+
+// Retain forgets the authentication rate limiters of the identity providers whose UIDs are not given,
+// e.g. because those identity providers were deleted.
+func (r *AuthenticationRateLimiters) Retain(uids sets.Set[types.UID]) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for uid := range r.byUID {
+		if !uids.Has(uid) {
+			delete(r.byUID, uid)
+		}
+	}
+}
+
+func rateLimit(limit *v1alpha1.AuthenticationRateLimit) ratelimit.Limit {
+	if limit == nil {
+		return ratelimit.Limit{}
+	}
+	return ratelimit.Limit{
+		AttemptsPerMinute: int(limit.AttemptsPerMinute),
+		Burst:             int(limit.Burst),
+	}
+}
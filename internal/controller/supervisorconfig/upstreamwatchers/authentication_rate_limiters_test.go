@@ -0,0 +1,53 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/ratelimit"
+	"go.pinniped.dev/internal/upstreamldap"
+)
+
+func TestAuthenticationRateLimiters(t *testing.T) {
+	subject := NewAuthenticationRateLimiters()
+
+	perUsername := &v1alpha1.AuthenticationRateLimit{AttemptsPerMinute: 5, Burst: 10}
+	perClientIP := &v1alpha1.AuthenticationRateLimit{AttemptsPerMinute: 20}
+
+	require.Nil(t, subject.ForUpstream("some-uid", nil, nil))
+
+	limiter := subject.ForUpstream("some-uid", perUsername, perClientIP)
+	require.NotNil(t, limiter)
+	require.Equal(t, upstreamldap.AuthenticationRateLimitConfig{
+		PerUsername: ratelimit.Limit{AttemptsPerMinute: 5, Burst: 10},
+		PerClientIP: ratelimit.Limit{AttemptsPerMinute: 20},
+	}, limiter.Config())
+
+	// The same limiter is returned while the limits are unchanged, so that the limits apply across validations.
+	require.Same(t, limiter, subject.ForUpstream("some-uid", perUsername.DeepCopy(), perClientIP.DeepCopy()))
+
+	// Each upstream has its own limiter.
+	otherLimiter := subject.ForUpstream("some-other-uid", perUsername, perClientIP)
+	require.NotSame(t, limiter, otherLimiter)
+
+	// A new limiter is created when the limits change.
+	changedLimiter := subject.ForUpstream("some-uid", perUsername, nil)
+	require.NotSame(t, limiter, changedLimiter)
+	require.Equal(t, ratelimit.Limit{}, changedLimiter.Config().PerClientIP)
+
+	// The limiters of upstreams which no longer exist are forgotten.
+	subject.Retain(sets.New[types.UID]("some-uid"))
+	require.Same(t, changedLimiter, subject.ForUpstream("some-uid", perUsername, nil))
+	require.NotSame(t, otherLimiter, subject.ForUpstream("some-other-uid", perUsername, perClientIP))
+
+	// Removing the limits removes the limiter.
+	require.Nil(t, subject.ForUpstream("some-uid", nil, nil))
+	require.NotSame(t, changedLimiter, subject.ForUpstream("some-uid", perUsername, nil))
+}
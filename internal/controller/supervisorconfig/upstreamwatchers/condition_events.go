@@ -0,0 +1,138 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+)
+
+const (
+	// conditionEventAction is the action of the Events which are recorded when a condition transitions.
+	conditionEventAction = "ValidateUpstream"
+
+	// maxConditionEventNoteLength is the maximum length of the note of an Event allowed by the Kubernetes API.
+	maxConditionEventNoteLength = 1024
+)
+
+type conditionEventKey struct {
+	uid           types.UID
+	conditionType string
+}
+
+type conditionEventState struct {
+	status v1alpha1.ConditionStatus
+	reason string
+}
+
+// ConditionEventRecorder records Kubernetes Events on an LDAPIdentityProvider or ActiveDirectoryIdentityProvider when
+// its BindSecretValid, LDAPConnectionValid, or SearchBaseFound conditions transition to a new status or reason.
+// It remembers the transitions for which it already recorded an Event, so the same transition will not cause
+// another Event when it is observed again, e.g. when the previous attempt to update the status failed.
+type ConditionEventRecorder struct {
+	recorder events.EventRecorder
+
+	lock     sync.Mutex
+	recorded map[conditionEventKey]conditionEventState
+}
+
+// NewConditionEventRecorder returns a ConditionEventRecorder which records Events using the provided recorder.
+func NewConditionEventRecorder(recorder events.EventRecorder) *ConditionEventRecorder {
+	return &ConditionEventRecorder{
+		recorder: recorder,
+		recorded: map[conditionEventKey]conditionEventState{},
+	}
+}
+
+// RecordTransitions compares the newly computed conditions of an identity provider to the conditions which are
+// currently in its status, and records an Event regarding the identity provider for each condition that has
+// transitioned.
+func (r *ConditionEventRecorder) RecordTransitions(
+	regarding *corev1.ObjectReference,
+	oldConditions []v1alpha1.Condition,
+	newConditions []*v1alpha1.Condition,
+) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, newCondition := range newConditions {
+		if !isEventedConditionType(newCondition.Type) {
+			continue
+		}
+
+		key := conditionEventKey{uid: regarding.UID, conditionType: newCondition.Type}
+		state := conditionEventState{status: newCondition.Status, reason: newCondition.Reason}
+
+		if oldCondition := findCondition(oldConditions, newCondition.Type); oldCondition != nil &&
+			oldCondition.Status == newCondition.Status && oldCondition.Reason == newCondition.Reason {
+			// This is not a transition, but remember it so that a later transition away from this state and then
+			// back again will be recorded.
+			r.recorded[key] = state
+			continue
+		}
+
+		if previousState, ok := r.recorded[key]; ok && previousState == state {
+			continue // already recorded an Event for this transition
+		}
+		r.recorded[key] = state
+
+		eventType := corev1.EventTypeWarning
+		if newCondition.Status == v1alpha1.ConditionTrue {
+			eventType = corev1.EventTypeNormal
+		}
+
+		r.recorder.Eventf(regarding, nil, eventType, newCondition.Reason, conditionEventAction, "%s",
+			conditionEventNote(newCondition))
+	}
+}
+
+// ObjectReferenceForEvents returns a reference to an identity provider of the given kind, to be used as the
+// regarding object of Events. This is needed because the objects from the informers do not have their kind set.
+// The reference uses the default API group, which is replaced by the API group suffix middleware when the Event
+// is created.
+func ObjectReferenceForEvents(kind string, obj metav1.Object) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+		Kind:            kind,
+		Namespace:       obj.GetNamespace(),
+		Name:            obj.GetName(),
+		UID:             obj.GetUID(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}
+}
+
+func conditionEventNote(condition *v1alpha1.Condition) string {
+	note := fmt.Sprintf("condition %s changed to %s: %s", condition.Type, condition.Status, condition.Message)
+	if len(note) > maxConditionEventNoteLength {
+		note = note[:maxConditionEventNoteLength-3] + "..."
+	}
+	return note
+}
+
+// isEventedConditionType returns true for the types of the conditions which cause an Event to be recorded when they
+// transition.
+func isEventedConditionType(conditionType string) bool {
+	switch conditionType {
+	case typeBindSecretValid, typeLDAPConnectionValid, TypeSearchBaseFound:
+		return true
+	default:
+		return false
+	}
+}
+
+func findCondition(conditions []v1alpha1.Condition, conditionType string) *v1alpha1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
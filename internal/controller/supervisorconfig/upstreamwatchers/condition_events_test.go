@@ -0,0 +1,163 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+)
+
+func TestConditionEventRecorder(t *testing.T) {
+	upstream := &v1alpha1.LDAPIdentityProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name", UID: "some-uid"},
+	}
+	otherUpstream := &v1alpha1.LDAPIdentityProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-other-name", UID: "some-other-uid"},
+	}
+
+	condition := func(conditionType string, status v1alpha1.ConditionStatus, reason, message string) v1alpha1.Condition {
+		return v1alpha1.Condition{Type: conditionType, Status: status, Reason: reason, Message: message}
+	}
+	bindSecretValid := condition("BindSecretValid", v1alpha1.ConditionTrue, "Success", "loaded bind secret")
+	bindSecretNotFound := condition("BindSecretValid", v1alpha1.ConditionFalse, "SecretNotFound", `secret "some-secret" not found`)
+	connectionValid := condition("LDAPConnectionValid", v1alpha1.ConditionTrue, "Success", "successfully able to connect")
+	connectionValidWithNewMessage := condition("LDAPConnectionValid", v1alpha1.ConditionTrue, "Success", "successfully able to connect again")
+	connectionError := condition("LDAPConnectionValid", v1alpha1.ConditionFalse, "LDAPConnectionError", "some connection error")
+	tlsConfigurationValid := condition("TLSConfigurationValid", v1alpha1.ConditionTrue, "Success", "loaded TLS configuration")
+
+	type recordTransitionsCall struct {
+		upstream      *v1alpha1.LDAPIdentityProvider
+		oldConditions []v1alpha1.Condition
+		newConditions []v1alpha1.Condition
+	}
+
+	tests := []struct {
+		name       string
+		calls      []recordTransitionsCall
+		wantEvents []string
+	}{
+		{
+			name: "records Events for the conditions which are new",
+			calls: []recordTransitionsCall{
+				{upstream: upstream, newConditions: []v1alpha1.Condition{bindSecretValid, tlsConfigurationValid, connectionValid}},
+			},
+			wantEvents: []string{
+				"Normal Success condition BindSecretValid changed to True: loaded bind secret",
+				"Normal Success condition LDAPConnectionValid changed to True: successfully able to connect",
+			},
+		},
+		{
+			name: "records Events for the conditions which changed status or reason",
+			calls: []recordTransitionsCall{
+				{
+					upstream:      upstream,
+					oldConditions: []v1alpha1.Condition{bindSecretNotFound, connectionValid},
+					newConditions: []v1alpha1.Condition{bindSecretValid, connectionError},
+				},
+			},
+			wantEvents: []string{
+				"Normal Success condition BindSecretValid changed to True: loaded bind secret",
+				"Warning LDAPConnectionError condition LDAPConnectionValid changed to False: some connection error",
+			},
+		},
+		{
+			name: "does not record Events for the conditions which only changed message",
+			calls: []recordTransitionsCall{
+				{
+					upstream:      upstream,
+					oldConditions: []v1alpha1.Condition{bindSecretValid, connectionValid},
+					newConditions: []v1alpha1.Condition{bindSecretValid, connectionValidWithNewMessage},
+				},
+			},
+			wantEvents: []string{},
+		},
+		{
+			name: "does not record another Event for the same transition, e.g. when the previous status update failed",
+			calls: []recordTransitionsCall{
+				{upstream: upstream, oldConditions: []v1alpha1.Condition{connectionValid}, newConditions: []v1alpha1.Condition{connectionError}},
+				{upstream: upstream, oldConditions: []v1alpha1.Condition{connectionValid}, newConditions: []v1alpha1.Condition{connectionError}},
+			},
+			wantEvents: []string{
+				"Warning LDAPConnectionError condition LDAPConnectionValid changed to False: some connection error",
+			},
+		},
+		{
+			name: "records another Event when the condition transitions back and forth",
+			calls: []recordTransitionsCall{
+				{upstream: upstream, oldConditions: []v1alpha1.Condition{connectionValid}, newConditions: []v1alpha1.Condition{connectionError}},
+				{upstream: upstream, oldConditions: []v1alpha1.Condition{connectionError}, newConditions: []v1alpha1.Condition{connectionValid}},
+				{upstream: upstream, oldConditions: []v1alpha1.Condition{connectionValid}, newConditions: []v1alpha1.Condition{connectionError}},
+			},
+			wantEvents: []string{
+				"Warning LDAPConnectionError condition LDAPConnectionValid changed to False: some connection error",
+				"Normal Success condition LDAPConnectionValid changed to True: successfully able to connect",
+				"Warning LDAPConnectionError condition LDAPConnectionValid changed to False: some connection error",
+			},
+		},
+		{
+			name: "records the same transition for different upstreams",
+			calls: []recordTransitionsCall{
+				{upstream: upstream, oldConditions: []v1alpha1.Condition{connectionValid}, newConditions: []v1alpha1.Condition{connectionError}},
+				{upstream: otherUpstream, oldConditions: []v1alpha1.Condition{connectionValid}, newConditions: []v1alpha1.Condition{connectionError}},
+			},
+			wantEvents: []string{
+				"Warning LDAPConnectionError condition LDAPConnectionValid changed to False: some connection error",
+				"Warning LDAPConnectionError condition LDAPConnectionValid changed to False: some connection error",
+			},
+		},
+		{
+			name: "truncates long messages",
+			calls: []recordTransitionsCall{
+				{upstream: upstream, newConditions: []v1alpha1.Condition{
+					condition("LDAPConnectionValid", v1alpha1.ConditionFalse, "LDAPConnectionError", strings.Repeat("x", 2000)),
+				}},
+			},
+			wantEvents: []string{
+				"Warning LDAPConnectionError " + ("condition LDAPConnectionValid changed to False: " + strings.Repeat("x", 2000))[:1021] + "...",
+			},
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			eventRecorder := events.NewFakeRecorder(100)
+			subject := NewConditionEventRecorder(eventRecorder)
+
+			for _, call := range tt.calls {
+				newConditions := make([]*v1alpha1.Condition, len(call.newConditions))
+				for i := range call.newConditions {
+					newConditions[i] = &call.newConditions[i]
+				}
+				subject.RecordTransitions(ObjectReferenceForEvents("LDAPIdentityProvider", call.upstream), call.oldConditions, newConditions)
+			}
+
+			recorded := []string{}
+			for len(eventRecorder.Events) > 0 {
+				recorded = append(recorded, <-eventRecorder.Events)
+			}
+			require.Equal(t, tt.wantEvents, recorded)
+		})
+	}
+}
+
+func TestObjectReferenceForEvents(t *testing.T) {
+	upstream := &v1alpha1.ActiveDirectoryIdentityProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name", UID: "some-uid", ResourceVersion: "42"},
+	}
+
+	ref := ObjectReferenceForEvents("ActiveDirectoryIdentityProvider", upstream)
+
+	require.Equal(t, "idp.supervisor.pinniped.dev/v1alpha1", ref.APIVersion)
+	require.Equal(t, "ActiveDirectoryIdentityProvider", ref.Kind)
+	require.Equal(t, "some-namespace", ref.Namespace)
+	require.Equal(t, "some-name", ref.Name)
+	require.Equal(t, "some-uid", string(ref.UID))
+	require.Equal(t, "42", ref.ResourceVersion)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+)
+
+// ConnectionRevalidations keeps track of when the connection of each identity provider to its server was last
+// validated, for the identity providers which are configured to validate their connection again periodically.
+type ConnectionRevalidations struct {
+	clock clock.PassiveClock
+
+	lock               sync.Mutex
+	lastValidatedByUID map[types.UID]time.Time
+}
+
+func NewConnectionRevalidations(c clock.PassiveClock) *ConnectionRevalidations {
+	return &ConnectionRevalidations{clock: c, lastValidatedByUID: map[types.UID]time.Time{}}
+}
+
+// Due returns true when the connection of the identity provider with the given UID should be validated again now,
+// in which case the validation is assumed to happen now. It also returns how long to wait until the following
+// validation is due, or zero when the identity provider is not configured to validate its connection again.
+func (r *ConnectionRevalidations) Due(uid types.UID, intervalSeconds int64) (bool, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if intervalSeconds <= 0 {
+		delete(r.lastValidatedByUID, uid)
+		return false, 0
+	}
+	interval := time.Duration(intervalSeconds) * time.Second
+
+	now := r.clock.Now()
+	lastValidated, found := r.lastValidatedByUID[uid]
+	if !found {
+		// The connection is validated when the identity provider is first seen, or else its validated settings
+		// were recently cached, so start counting the interval from now.
+		r.lastValidatedByUID[uid] = now
+		return false, interval
+	}
+
+	if elapsed := now.Sub(lastValidated); elapsed < interval {
+		return false, interval - elapsed
+	}
+	r.lastValidatedByUID[uid] = now
+	return true, interval
+}
+
+// Retain forgets the identity providers whose UIDs are not given, e.g. because those identity providers were deleted.
+func (r *ConnectionRevalidations) Retain(uids sets.Set[types.UID]) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for uid := range r.lastValidatedByUID {
+		if !uids.Has(uid) {
+			delete(r.lastValidatedByUID, uid)
+		}
+	}
+}
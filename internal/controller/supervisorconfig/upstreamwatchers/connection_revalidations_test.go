@@ -0,0 +1,53 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestConnectionRevalidations(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	subject := NewConnectionRevalidations(fakeClock)
+
+	requireDue := func(uid types.UID, intervalSeconds int64, wantDue bool, wantNext time.Duration) {
+		t.Helper()
+		due, next := subject.Due(uid, intervalSeconds)
+		require.Equal(t, wantDue, due)
+		require.Equal(t, wantNext, next)
+	}
+
+	// Not configured to validate again.
+	requireDue("some-uid", 0, false, 0)
+
+	// The interval starts when the identity provider is first seen.
+	requireDue("some-uid", 60, false, time.Minute)
+	fakeClock.SetTime(fakeClock.Now().Add(20 * time.Second))
+	requireDue("some-uid", 60, false, 40*time.Second)
+
+	fakeClock.SetTime(fakeClock.Now().Add(40 * time.Second))
+	requireDue("some-uid", 60, true, time.Minute)
+	requireDue("some-uid", 60, false, time.Minute)
+
+	// Each identity provider has its own interval.
+	requireDue("some-other-uid", 30, false, 30*time.Second)
+
+	// Shortening the interval can make a validation due sooner.
+	fakeClock.SetTime(fakeClock.Now().Add(30 * time.Second))
+	requireDue("some-uid", 20, true, 20*time.Second)
+
+	// Identity providers which no longer exist are forgotten.
+	subject.Retain(sets.New[types.UID]("some-uid"))
+	requireDue("some-other-uid", 30, false, 30*time.Second)
+
+	// Removing the interval forgets the identity provider.
+	requireDue("some-uid", 0, false, 0)
+	requireDue("some-uid", 20, false, 20*time.Second)
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// ValidatedSettingsSecretType is the type of the Secret in which validated settings are persisted.
+const ValidatedSettingsSecretType corev1.SecretType = "secrets.pinniped.dev/validated-settings"
+
+// ValidatedSettingsStore loads and saves the persisted entries of a ValidatedSettingsCacheI, keyed by upstream UID.
+type ValidatedSettingsStore interface {
+	Load(ctx context.Context) (map[string]string, error)
+	Save(ctx context.Context, entries map[string]string) error
+}
+
+type persistedValidatedSettings struct {
+	UpstreamName string            `json:"upstreamName"`
+	Settings     ValidatedSettings `json:"settings"`
+}
+
+// persistedValidatedSettingsCache is a ValidatedSettingsCacheI which also persists its entries using a
+// ValidatedSettingsStore, so that a restarted pod does not need to validate unchanged settings again.
+// Persisted entries are keyed by the UID of the upstream, so that they never apply to an upstream which
+// was deleted and then recreated with the same name.
+type persistedValidatedSettingsCache struct {
+	// inMemory is also keyed by the UID of the upstream, rather than by its name, so that its entries never apply
+	// to a recreated upstream either.
+	inMemory *ValidatedSettingsCache
+	store    ValidatedSettingsStore
+
+	lock      sync.Mutex
+	loaded    bool
+	persisted map[types.UID]persistedValidatedSettings
+}
+
+// NewPersistedValidatedSettingsCache returns a ValidatedSettingsCacheI which persists its entries using the store.
+// The persisted entries are loaded from the store when first needed. Failures to load or save the entries are
+// logged and otherwise ignored, since the settings can always be validated again.
+func NewPersistedValidatedSettingsCache(store ValidatedSettingsStore) ValidatedSettingsCacheI {
+	return &persistedValidatedSettingsCache{
+		inMemory:  newInMemoryValidatedSettingsCache(),
+		store:     store,
+		persisted: map[types.UID]persistedValidatedSettings{},
+	}
+}
+
+func (c *persistedValidatedSettingsCache) Get(ctx context.Context, upstreamName string, upstreamUID types.UID, resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion string, idpSpecGeneration int64) (ValidatedSettings, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if validatedSettings, found := c.inMemory.Get(ctx, string(upstreamUID), upstreamUID, resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion, idpSpecGeneration); found {
+		return validatedSettings, true
+	}
+
+	c.maybeLoad(ctx)
+
+	entry, found := c.persisted[upstreamUID]
+	if !found || entry.UpstreamName != upstreamName || !entry.Settings.matches(resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion, idpSpecGeneration) {
+		return ValidatedSettings{}, false
+	}
+
+	plog.Debug("using persisted validated settings", "upstreamName", upstreamName, "upstreamUID", upstreamUID)
+	c.inMemory.Set(ctx, string(upstreamUID), upstreamUID, entry.Settings)
+	return entry.Settings, true
+}
+
+func (c *persistedValidatedSettingsCache) Set(ctx context.Context, upstreamName string, upstreamUID types.UID, settings ValidatedSettings) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.inMemory.Set(ctx, string(upstreamUID), upstreamUID, settings)
+
+	// Load first, to avoid overwriting the entries of the other upstreams.
+	c.maybeLoad(ctx)
+	if !c.loaded {
+		return
+	}
+
+	c.persisted[upstreamUID] = persistedValidatedSettings{UpstreamName: upstreamName, Settings: settings}
+	c.save(ctx, upstreamName, upstreamUID)
+}
+
+func (c *persistedValidatedSettingsCache) Delete(ctx context.Context, upstreamName string, upstreamUID types.UID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.inMemory.Delete(ctx, string(upstreamUID), upstreamUID)
+
+	c.maybeLoad(ctx)
+	if _, found := c.persisted[upstreamUID]; !found {
+		return
+	}
+
+	delete(c.persisted, upstreamUID)
+	c.save(ctx, upstreamName, upstreamUID)
+}
+
+// save writes all persisted entries to the store. Must be called while holding the lock.
+func (c *persistedValidatedSettingsCache) save(ctx context.Context, upstreamName string, upstreamUID types.UID) {
+	entries := make(map[string]string, len(c.persisted))
+	for uid, entry := range c.persisted {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			plog.Error("could not encode validated settings", err, "upstreamName", entry.UpstreamName, "upstreamUID", uid)
+			continue
+		}
+		entries[string(uid)] = string(encoded)
+	}
+
+	if err := c.store.Save(ctx, entries); err != nil {
+		plog.Error("could not persist validated settings", err, "upstreamName", upstreamName, "upstreamUID", upstreamUID)
+	}
+}
+
+// maybeLoad loads the persisted entries from the store, unless they were already loaded. Must be called while
+// holding the lock.
+func (c *persistedValidatedSettingsCache) maybeLoad(ctx context.Context) {
+	if c.loaded {
+		return
+	}
+
+	entries, err := c.store.Load(ctx)
+	if err != nil {
+		plog.Error("could not load persisted validated settings", err)
+		return
+	}
+	c.loaded = true
+
+	for uid, encoded := range entries {
+		var entry persistedValidatedSettings
+		if err := json.Unmarshal([]byte(encoded), &entry); err != nil {
+			// Skip the entry, which will be replaced the next time that the upstream is validated.
+			plog.Error("could not decode persisted validated settings", err, "upstreamUID", uid)
+			continue
+		}
+		c.persisted[types.UID(uid)] = entry
+	}
+}
+
+type configMapValidatedSettingsStore struct {
+	client corev1client.ConfigMapInterface
+	name   string
+	labels map[string]string
+}
+
+// NewConfigMapValidatedSettingsStore returns a ValidatedSettingsStore which persists the entries in a ConfigMap.
+func NewConfigMapValidatedSettingsStore(client corev1client.ConfigMapInterface, name string, labels map[string]string) ValidatedSettingsStore {
+	return &configMapValidatedSettingsStore{client: client, name: name, labels: labels}
+}
+
+func (s *configMapValidatedSettingsStore) Load(ctx context.Context) (map[string]string, error) {
+	configMap, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap: %w", err)
+	}
+	return configMap.Data, nil
+}
+
+func (s *configMapValidatedSettingsStore) Save(ctx context.Context, entries map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			_, err = s.client.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Labels: s.labels},
+				Data:       entries,
+			}, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get configmap: %w", err)
+		}
+
+		configMap.Data = entries
+		_, err = s.client.Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+type secretValidatedSettingsStore struct {
+	client corev1client.SecretInterface
+	name   string
+	labels map[string]string
+}
+
+// NewSecretValidatedSettingsStore returns a ValidatedSettingsStore which persists the entries in a Secret.
+func NewSecretValidatedSettingsStore(client corev1client.SecretInterface, name string, labels map[string]string) ValidatedSettingsStore {
+	return &secretValidatedSettingsStore{client: client, name: name, labels: labels}
+}
+
+func (s *secretValidatedSettingsStore) Load(ctx context.Context) (map[string]string, error) {
+	secret, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	if secret.Type != ValidatedSettingsSecretType {
+		return nil, fmt.Errorf("secret has wrong type %q (should be %q)", secret.Type, ValidatedSettingsSecretType)
+	}
+
+	entries := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		entries[key] = string(value)
+	}
+	return entries, nil
+}
+
+func (s *secretValidatedSettingsStore) Save(ctx context.Context, entries map[string]string) error {
+	data := make(map[string][]byte, len(entries))
+	for key, value := range entries {
+		data[key] = []byte(value)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			_, err = s.client.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Labels: s.labels},
+				Type:       ValidatedSettingsSecretType,
+				Data:       data,
+			}, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get secret: %w", err)
+		}
+		if secret.Type != ValidatedSettingsSecretType {
+			return fmt.Errorf("secret has wrong type %q (should be %q)", secret.Type, ValidatedSettingsSecretType)
+		}
+
+		secret.Data = data
+		_, err = s.client.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/upstreamldap"
+)
+
+func TestPersistedValidatedSettingsCache(t *testing.T) {
+	const (
+		namespace = "some-namespace"
+		name      = "some-cache-name"
+	)
+
+	settings := ValidatedSettings{
+		IDPSpecGeneration:         42,
+		BindSecretResourceVersion: "some-bind-secret-rv",
+		LDAPConnectionProtocol:    upstreamldap.StartTLS,
+		UserSearchBase:            "ou=users,dc=example,dc=com",
+		GroupSearchBase:           "ou=groups,dc=example,dc=com",
+		ConnectionValidCondition: &v1alpha1.Condition{
+			Type:    "LDAPConnectionValid",
+			Status:  "True",
+			Reason:  "Success",
+			Message: "some message",
+		},
+	}
+
+	stores := map[string]func(client *kubefake.Clientset) ValidatedSettingsStore{
+		"ConfigMap": func(client *kubefake.Clientset) ValidatedSettingsStore {
+			return NewConfigMapValidatedSettingsStore(client.CoreV1().ConfigMaps(namespace), name, map[string]string{"some-label": "some-value"})
+		},
+		"Secret": func(client *kubefake.Clientset) ValidatedSettingsStore {
+			return NewSecretValidatedSettingsStore(client.CoreV1().Secrets(namespace), name, map[string]string{"some-label": "some-value"})
+		},
+	}
+
+	for storeName, newStore := range stores {
+		newStore := newStore
+		t.Run(storeName, func(t *testing.T) {
+			ctx := context.Background()
+			client := kubefake.NewSimpleClientset()
+
+			subject := NewPersistedValidatedSettingsCache(newStore(client))
+			_, found := subject.Get(ctx, "some-upstream", "some-uid", "some-bind-secret-rv", "", "", 42)
+			require.False(t, found)
+
+			subject.Set(ctx, "some-upstream", "some-uid", settings)
+			subject.Set(ctx, "some-other-upstream", "some-other-uid", settings)
+
+			// A new cache, e.g. after a restart, finds the persisted settings.
+			rehydrated := NewPersistedValidatedSettingsCache(newStore(client))
+			got, found := rehydrated.Get(ctx, "some-upstream", "some-uid", "some-bind-secret-rv", "", "", 42)
+			require.True(t, found)
+			require.Equal(t, settings, got)
+			got, found = rehydrated.Get(ctx, "some-other-upstream", "some-other-uid", "some-bind-secret-rv", "", "", 42)
+			require.True(t, found)
+			require.Equal(t, settings, got)
+
+			// The persisted settings do not apply after the upstream or its secrets change.
+			_, found = rehydrated.Get(ctx, "some-upstream", "some-recreated-uid", "some-bind-secret-rv", "", "", 42)
+			require.False(t, found)
+			_, found = rehydrated.Get(ctx, "some-upstream", "some-uid", "some-bind-secret-rv", "", "", 43)
+			require.False(t, found)
+			_, found = rehydrated.Get(ctx, "some-upstream", "some-uid", "some-other-bind-secret-rv", "", "", 42)
+			require.False(t, found)
+			_, found = rehydrated.Get(ctx, "some-upstream", "some-uid", "some-bind-secret-rv", "some-client-cert-rv", "", 42)
+			require.False(t, found)
+			_, found = rehydrated.Get(ctx, "some-upstream", "some-uid", "some-bind-secret-rv", "", "some-ca-bundle-rv", 42)
+			require.False(t, found)
+		})
+	}
+}
+
+func TestPersistedValidatedSettingsCacheStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+	client.PrependReactor("get", "configmaps", func(action coretesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("some get error")
+	})
+
+	subject := NewPersistedValidatedSettingsCache(NewConfigMapValidatedSettingsStore(client.CoreV1().ConfigMaps("some-namespace"), "some-cache-name", nil))
+
+	// The settings are still cached in memory when they cannot be persisted.
+	settings := ValidatedSettings{IDPSpecGeneration: 1, BindSecretResourceVersion: "some-bind-secret-rv"}
+	subject.Set(ctx, "some-upstream", "some-uid", settings)
+	got, found := subject.Get(ctx, "some-upstream", "some-uid", "some-bind-secret-rv", "", "", 1)
+	require.True(t, found)
+	require.Equal(t, settings, got)
+
+	for _, action := range client.Actions() {
+		require.Equal(t, "get", action.GetVerb())
+	}
+}
+
+func TestSecretValidatedSettingsStoreWrongType(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-cache-name"},
+		Type:       corev1.SecretTypeOpaque,
+	})
+	subject := NewSecretValidatedSettingsStore(client.CoreV1().Secrets("some-namespace"), "some-cache-name", nil)
+
+	_, err := subject.Load(ctx)
+	require.EqualError(t, err, `secret has wrong type "Opaque" (should be "secrets.pinniped.dev/validated-settings")`)
+	require.EqualError(t, subject.Save(ctx, map[string]string{}), `secret has wrong type "Opaque" (should be "secrets.pinniped.dev/validated-settings")`)
+}
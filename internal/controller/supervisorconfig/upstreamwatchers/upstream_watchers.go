@@ -1,108 +1,162 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package upstreamwatchers
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/go-ldap/ldap/v3"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/controller/tlsconfigutil"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/upstreamldap"
 )
 
 const (
-	ReasonNotFound         = "SecretNotFound"
-	ReasonWrongType        = "SecretWrongType"
-	ReasonMissingKeys      = "SecretMissingKeys"
-	ReasonSuccess          = "Success"
-	ReasonInvalidTLSConfig = "InvalidTLSConfig"
-
-	ErrNoCertificates = constable.Error("no certificates found")
-
-	LDAPBindAccountSecretType = corev1.SecretTypeBasicAuth
-	probeLDAPTimeout          = 90 * time.Second
+	ReasonNotFound             = "SecretNotFound"
+	ReasonWrongType            = "SecretWrongType"
+	ReasonMissingKeys          = "SecretMissingKeys"
+	ReasonSuccess              = "Success"
+	ReasonInvalidTLSConfig     = "InvalidTLSConfig"
+	ReasonInvalidProxyURL      = "InvalidProxyURL"
+	ReasonInvalidTimeouts      = "InvalidTimeouts"
+	ReasonInvalidSearchOptions = "InvalidSearchOptions"
+	ReasonInvalidClientCert    = "InvalidClientCertificate"
+
+	ErrNoCertificates                        = constable.Error("no certificates found")
+	ErrBothCertificateAuthorityDataAndSource = constable.Error("certificateAuthorityData and certificateAuthorityDataSource cannot both be specified")
+
+	LDAPBindAccountSecretType       = corev1.SecretTypeBasicAuth
+	SecondaryBindUsernameKey        = "username2"
+	SecondaryBindPasswordKey        = "password2"
+	LDAPClientCertificateSecretType = corev1.SecretTypeTLS
+	probeLDAPTimeout                = 90 * time.Second
 
 	// Constants related to conditions.
-	typeBindSecretValid              = "BindSecretValid"
-	typeTLSConfigurationValid        = "TLSConfigurationValid"
-	typeLDAPConnectionValid          = "LDAPConnectionValid"
-	TypeSearchBaseFound              = "SearchBaseFound"
-	reasonLDAPConnectionError        = "LDAPConnectionError"
-	noTLSConfigurationMessage        = "no TLS configuration provided"
-	loadedTLSConfigurationMessage    = "loaded TLS configuration"
-	ReasonUsingConfigurationFromSpec = "UsingConfigurationFromSpec"
-	ReasonErrorFetchingSearchBase    = "ErrorFetchingSearchBase"
+	typeBindSecretValid                 = "BindSecretValid"
+	typeTLSConfigurationValid           = "TLSConfigurationValid"
+	typeLDAPConnectionValid             = "LDAPConnectionValid"
+	typeActiveBindCredentials           = "ActiveBindCredentials"
+	typeProxyURLValid                   = "ProxyURLValid"
+	typeTimeoutsValid                   = "TimeoutsValid"
+	typeSearchOptionsValid              = "SearchOptionsValid"
+	TypeSearchBaseFound                 = "SearchBaseFound"
+	reasonLDAPConnectionError           = "LDAPConnectionError"
+	reasonUsingPrimaryBindCredentials   = "UsingPrimaryBindCredentials"
+	reasonUsingSecondaryBindCredentials = "UsingSecondaryBindCredentials"
+	reasonNoBindCredentials             = "NoBindCredentials"
+	noTLSConfigurationMessage           = "no TLS configuration provided"
+	loadedTLSConfigurationMessage       = "loaded TLS configuration"
+	noProxyURLMessage                   = "no proxy URL provided"
+	loadedProxyURLMessage               = "loaded proxy URL"
+	loadedTimeoutsMessage               = "loaded timeouts"
+	loadedSearchOptionsMessage          = "loaded search options"
+	ReasonUsingConfigurationFromSpec    = "UsingConfigurationFromSpec"
+	ReasonErrorFetchingSearchBase       = "ErrorFetchingSearchBase"
 )
 
 // ValidatedSettings is the struct which is cached by the ValidatedSettingsCacheI interface.
 type ValidatedSettings struct {
-	IDPSpecGeneration         int64  // which IDP spec was used during the validation
-	BindSecretResourceVersion string // which bind secret was used during the validation
+	IDPSpecGeneration                      int64  `json:"idpSpecGeneration"`                                // which IDP spec was used during the validation
+	BindSecretResourceVersion              string `json:"bindSecretResourceVersion"`                        // which bind secret was used during the validation
+	ClientCertificateSecretResourceVersion string `json:"clientCertificateSecretResourceVersion,omitempty"` // which client certificate secret was used during the validation, if any
+	CABundleSourceResourceVersion          string `json:"caBundleSourceResourceVersion,omitempty"`          // which Secret or ConfigMap holding the CA bundle was used during the validation, if any
 
-	// Cache the setting for TLS vs StartTLS. This is always auto-discovered by probing the server.
-	LDAPConnectionProtocol upstreamldap.LDAPConnectionProtocol
+	// Cache the setting for TLS vs StartTLS. This is either configured by the IDP spec, or auto-discovered
+	// by probing the server.
+	LDAPConnectionProtocol upstreamldap.LDAPConnectionProtocol `json:"ldapConnectionProtocol"`
+
+	// Cache which of the bind credentials from the bind secret were able to bind during the validation.
+	UsingSecondaryBindCredentials bool `json:"usingSecondaryBindCredentials,omitempty"`
 
 	// Cache the settings for search bases. These could be configured by the IDP spec, or in the
 	// case of AD they can also be auto-discovered by probing the server.
-	UserSearchBase, GroupSearchBase string
+	UserSearchBase  string `json:"userSearchBase"`
+	GroupSearchBase string `json:"groupSearchBase"`
 
 	// Cache copies of the conditions that were computed when the above settings were cached, so we
 	// can keep writing them to the status in the future. This matters most when the first attempt
 	// to write them to the IDP's status fails. In this case, future Syncs calls will be able to
 	// use these cached values to try writing them again.
-	ConnectionValidCondition, SearchBaseFoundCondition *v1alpha1.Condition
+	ConnectionValidCondition *v1alpha1.Condition `json:"connectionValidCondition,omitempty"`
+	SearchBaseFoundCondition *v1alpha1.Condition `json:"searchBaseFoundCondition,omitempty"`
 }
 
-// ValidatedSettingsCacheI is an interface for an in-memory cache with an entry for each upstream
-// provider. It keeps track of settings that were already validated for a given IDP spec and bind
-// secret for that upstream.
+// ValidatedSettingsCacheI is an interface for a cache with an entry for each upstream provider.
+// It keeps track of settings that were already validated for a given IDP spec and bind secret for that upstream.
+// The upstream is identified by its name in memory, and by its UID when the cache is persisted, since persisted
+// settings may outlive the upstream.
 type ValidatedSettingsCacheI interface {
 	// Get the cached settings for a given upstream at a given generation which was previously
-	// validated using a given bind secret version. If no settings have been cached for the
-	// upstream, or if the settings were cached at a different generation of the upstream or
-	// using a different version of the bind secret, then return false to indicate that the
-	// desired settings were not cached yet for that combination of spec generation and secret version.
-	Get(upstreamName, resourceVersion string, idpSpecGeneration int64) (ValidatedSettings, bool)
+	// validated using a given bind secret version, client certificate secret version, and CA bundle source
+	// version. If no settings have been cached for the upstream, or if the settings were cached at a different
+	// generation of the upstream or using a different version of any of those objects, then return false to
+	// indicate that the desired settings were not cached yet for that combination of spec generation and versions.
+	Get(ctx context.Context, upstreamName string, upstreamUID types.UID, resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion string, idpSpecGeneration int64) (ValidatedSettings, bool)
 
 	// Set some settings into the cache for a given upstream.
-	Set(upstreamName string, settings ValidatedSettings)
+	Set(ctx context.Context, upstreamName string, upstreamUID types.UID, settings ValidatedSettings)
+
+	// Delete the cached settings for a given upstream, e.g. because they could not be validated again.
+	Delete(ctx context.Context, upstreamName string, upstreamUID types.UID)
 }
 
+// ValidatedSettingsCache is an in-memory ValidatedSettingsCacheI.
 type ValidatedSettingsCache struct {
 	ValidatedSettingsByName map[string]ValidatedSettings
 }
 
 func NewValidatedSettingsCache() ValidatedSettingsCacheI {
+	return newInMemoryValidatedSettingsCache()
+}
+
+func newInMemoryValidatedSettingsCache() *ValidatedSettingsCache {
 	return &ValidatedSettingsCache{ValidatedSettingsByName: map[string]ValidatedSettings{}}
 }
 
-func (s *ValidatedSettingsCache) Get(upstreamName, resourceVersion string, idpSpecGeneration int64) (ValidatedSettings, bool) {
+func (s *ValidatedSettingsCache) Get(_ context.Context, upstreamName string, _ types.UID, resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion string, idpSpecGeneration int64) (ValidatedSettings, bool) {
 	validatedSettings, found := s.ValidatedSettingsByName[upstreamName]
-	if found && validatedSettings.BindSecretResourceVersion == resourceVersion && validatedSettings.IDPSpecGeneration == idpSpecGeneration {
+	if found && validatedSettings.matches(resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion, idpSpecGeneration) {
 		return validatedSettings, true
 	}
 	return ValidatedSettings{}, false
 }
 
-func (s *ValidatedSettingsCache) Set(upstreamName string, settings ValidatedSettings) {
+func (s *ValidatedSettingsCache) Set(_ context.Context, upstreamName string, _ types.UID, settings ValidatedSettings) {
 	s.ValidatedSettingsByName[upstreamName] = settings
 }
 
+func (s *ValidatedSettingsCache) Delete(_ context.Context, upstreamName string, _ types.UID) {
+	delete(s.ValidatedSettingsByName, upstreamName)
+}
+
+func (v *ValidatedSettings) matches(resourceVersion, clientCertificateResourceVersion, caBundleSourceResourceVersion string, idpSpecGeneration int64) bool {
+	return v.BindSecretResourceVersion == resourceVersion &&
+		v.ClientCertificateSecretResourceVersion == clientCertificateResourceVersion &&
+		v.CABundleSourceResourceVersion == caBundleSourceResourceVersion &&
+		v.IDPSpecGeneration == idpSpecGeneration
+}
+
 // UpstreamGenericLDAPIDP is a read-only interface for abstracting the differences between LDAP and Active Directory IDP types.
 type UpstreamGenericLDAPIDP interface {
 	Spec() UpstreamGenericLDAPSpec
 	Name() string
 	Namespace() string
+	UID() types.UID
 	Generation() int64
 	Status() UpstreamGenericLDAPStatus
 }
@@ -111,8 +165,13 @@ type UpstreamGenericLDAPSpec interface {
 	Host() string
 	TLSSpec() *v1alpha1.TLSSpec
 	BindSecretName() string
+	UserDNTemplate() string
+	ClientCertificateSecretName() string
 	UserSearch() UpstreamGenericLDAPUserSearch
 	GroupSearch() UpstreamGenericLDAPGroupSearch
+	ProxyURL() string
+	DialTimeoutSeconds() int64
+	SearchTimeoutSeconds() int64
 	DetectAndSetSearchBase(ctx context.Context, config *upstreamldap.ProviderConfig) *v1alpha1.Condition
 }
 
@@ -121,51 +180,313 @@ type UpstreamGenericLDAPUserSearch interface {
 	Filter() string
 	UsernameAttribute() string
 	UIDAttribute() string
+	Scope() v1alpha1.LDAPSearchScope
+	DerefAliases() v1alpha1.LDAPDerefAliases
 }
 
 type UpstreamGenericLDAPGroupSearch interface {
 	Base() string
 	Filter() string
 	GroupNameAttribute() string
+	Scope() v1alpha1.LDAPSearchScope
+	DerefAliases() v1alpha1.LDAPDerefAliases
+	GroupNameTransformations() []v1alpha1.GroupNameTransformation
 }
 
 type UpstreamGenericLDAPStatus interface {
 	Conditions() []v1alpha1.Condition
 }
 
-func ValidateTLSConfig(tlsSpec *v1alpha1.TLSSpec, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
+// ValidateTLSConfig loads the TLS configuration. When the CA bundle was read from a Secret or ConfigMap, it also
+// returns the resource version of that Secret or ConfigMap.
+func ValidateTLSConfig(
+	tlsSpec *v1alpha1.TLSSpec,
+	namespace string,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	config *upstreamldap.ProviderConfig,
+) (*v1alpha1.Condition, string) {
+	if tlsSpec == nil {
+		return validTLSCondition(noTLSConfigurationMessage), ""
+	}
+
+	switch tlsSpec.ConnectionProtocol {
+	case "", v1alpha1.LDAPConnectionProtocolAuto:
+		// Leave the protocol unset so that TestConnection will probe for it.
+	case v1alpha1.LDAPConnectionProtocolLDAPS:
+		config.ConnectionProtocol = upstreamldap.TLS
+	case v1alpha1.LDAPConnectionProtocolStartTLS:
+		config.ConnectionProtocol = upstreamldap.StartTLS
+	default:
+		return invalidTLSCondition(fmt.Sprintf("connectionProtocol %q is not valid", tlsSpec.ConnectionProtocol)), ""
+	}
+
+	_, bundle, caBundleSourceVersion, err := ReadCABundle(tlsSpec, namespace, secretInformer, configMapInformer)
+	if err != nil {
+		return invalidTLSCondition(err.Error()), ""
+	}
+
+	config.CABundle = bundle
+	return validTLSCondition(loadedTLSConfigurationMessage), caBundleSourceVersion
+}
+
+// ReadCABundle returns the CA bundle from either the certificateAuthorityData or the certificateAuthorityDataSource
+// of the TLS spec, or a nil bundle when neither was specified. When the CA bundle was read from a Secret or ConfigMap
+// in the given namespace, it also returns the resource version of that Secret or ConfigMap.
+func ReadCABundle(
+	tlsSpec *v1alpha1.TLSSpec,
+	namespace string,
+	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+) (*x509.CertPool, []byte, string, error) {
 	if tlsSpec == nil {
-		return validTLSCondition(noTLSConfigurationMessage)
+		return nil, nil, "", nil
+	}
+
+	if source := tlsSpec.CertificateAuthorityDataSource; source != nil {
+		if len(tlsSpec.CertificateAuthorityData) > 0 {
+			return nil, nil, "", ErrBothCertificateAuthorityDataAndSource
+		}
+		pool, bundle, resourceVersion, err := tlsconfigutil.ReadCABundle(tlsconfigutil.CABundleSource{
+			Kind:      string(source.Kind),
+			Name:      source.Name,
+			Namespace: namespace,
+			Key:       source.Key,
+		}, secretInformer.Lister(), configMapInformer.Lister())
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("certificateAuthorityDataSource is invalid: %w", err)
+		}
+		return pool, bundle, resourceVersion, nil
 	}
+
 	if len(tlsSpec.CertificateAuthorityData) == 0 {
-		return validTLSCondition(loadedTLSConfigurationMessage)
+		return nil, nil, "", nil
 	}
 
 	bundle, err := base64.StdEncoding.DecodeString(tlsSpec.CertificateAuthorityData)
 	if err != nil {
-		return invalidTLSCondition(fmt.Sprintf("certificateAuthorityData is invalid: %s", err.Error()))
+		return nil, nil, "", fmt.Errorf("certificateAuthorityData is invalid: %w", err)
 	}
 
-	ca := x509.NewCertPool()
-	ok := ca.AppendCertsFromPEM(bundle)
-	if !ok {
-		return invalidTLSCondition(fmt.Sprintf("certificateAuthorityData is invalid: %s", ErrNoCertificates))
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, nil, "", fmt.Errorf("certificateAuthorityData is invalid: %w", ErrNoCertificates)
 	}
+	return pool, bundle, "", nil
+}
 
-	config.CABundle = bundle
-	return validTLSCondition(loadedTLSConfigurationMessage)
+// ValidateProxyURL sets the proxy through which to connect to the LDAP server, when one is configured.
+func ValidateProxyURL(spec UpstreamGenericLDAPSpec, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
+	if len(spec.ProxyURL()) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeProxyURLValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  ReasonSuccess,
+			Message: noProxyURLMessage,
+		}
+	}
+
+	proxyURL, err := upstreamldap.ValidateProxyURL(spec.ProxyURL())
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeProxyURLValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  ReasonInvalidProxyURL,
+			Message: fmt.Sprintf("proxyURL is not valid: %s", err.Error()),
+		}
+	}
+
+	config.ProxyURL = proxyURL
+	return &v1alpha1.Condition{
+		Type:    typeProxyURLValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  ReasonSuccess,
+		Message: loadedProxyURLMessage,
+	}
+}
+
+func ValidateTimeouts(spec UpstreamGenericLDAPSpec, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
+	var errs []string
+	if spec.DialTimeoutSeconds() < 0 {
+		errs = append(errs, fmt.Sprintf("dialTimeoutSeconds must not be negative, but was %d", spec.DialTimeoutSeconds()))
+	}
+	if spec.SearchTimeoutSeconds() < 0 {
+		errs = append(errs, fmt.Sprintf("searchTimeoutSeconds must not be negative, but was %d", spec.SearchTimeoutSeconds()))
+	}
+	if len(errs) > 0 {
+		return &v1alpha1.Condition{
+			Type:    typeTimeoutsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  ReasonInvalidTimeouts,
+			Message: strings.Join(errs, "; "),
+		}
+	}
+
+	config.DialTimeout = time.Duration(spec.DialTimeoutSeconds()) * time.Second
+	config.SearchTimeout = time.Duration(spec.SearchTimeoutSeconds()) * time.Second
+	return &v1alpha1.Condition{
+		Type:    typeTimeoutsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  ReasonSuccess,
+		Message: loadedTimeoutsMessage,
+	}
+}
+
+func ValidateSearchOptions(spec UpstreamGenericLDAPSpec, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
+	var errs []string
+
+	userSearchScope, err := searchScope(spec.UserSearch().Scope())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("userSearch.%s", err))
+	}
+	userSearchDerefAliases, err := derefAliases(spec.UserSearch().DerefAliases())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("userSearch.%s", err))
+	}
+	groupSearchScope, err := searchScope(spec.GroupSearch().Scope())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("groupSearch.%s", err))
+	}
+	groupSearchDerefAliases, err := derefAliases(spec.GroupSearch().DerefAliases())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("groupSearch.%s", err))
+	}
+	groupNameTransformations, transformationErrs := groupNameTransformations(spec.GroupSearch().GroupNameTransformations())
+	for _, err := range transformationErrs {
+		errs = append(errs, fmt.Sprintf("groupSearch.%s", err))
+	}
+	if len(spec.UserDNTemplate()) > 0 && !strings.Contains(spec.UserDNTemplate(), upstreamldap.UserDNTemplateUsernamePlaceholder) {
+		errs = append(errs, fmt.Sprintf("bind.userDNTemplate must contain %q", upstreamldap.UserDNTemplateUsernamePlaceholder))
+	}
+
+	if len(errs) > 0 {
+		return &v1alpha1.Condition{
+			Type:    typeSearchOptionsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  ReasonInvalidSearchOptions,
+			Message: strings.Join(errs, "; "),
+		}
+	}
+
+	config.UserSearch.Scope = userSearchScope
+	config.UserSearch.DerefAliases = userSearchDerefAliases
+	config.GroupSearch.Scope = groupSearchScope
+	config.GroupSearch.DerefAliases = groupSearchDerefAliases
+	config.GroupSearch.GroupNameTransformations = groupNameTransformations
+	config.UserDNTemplate = spec.UserDNTemplate()
+	return &v1alpha1.Condition{
+		Type:    typeSearchOptionsValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  ReasonSuccess,
+		Message: loadedSearchOptionsMessage,
+	}
+}
+
+func searchScope(scope v1alpha1.LDAPSearchScope) (upstreamldap.SearchScope, error) {
+	switch scope {
+	case "", v1alpha1.LDAPSearchScopeWholeSubtree:
+		return upstreamldap.ScopeWholeSubtree, nil
+	case v1alpha1.LDAPSearchScopeSingleLevel:
+		return upstreamldap.ScopeSingleLevel, nil
+	case v1alpha1.LDAPSearchScopeBaseObject:
+		return upstreamldap.ScopeBaseObject, nil
+	default:
+		return 0, fmt.Errorf("scope %q is not valid", scope)
+	}
+}
+
+func derefAliases(deref v1alpha1.LDAPDerefAliases) (int, error) {
+	switch deref {
+	case "", v1alpha1.LDAPDerefAliasesNever:
+		return ldap.NeverDerefAliases, nil
+	case v1alpha1.LDAPDerefAliasesInSearching:
+		return ldap.DerefInSearching, nil
+	case v1alpha1.LDAPDerefAliasesFindingBaseObject:
+		return ldap.DerefFindingBaseObj, nil
+	case v1alpha1.LDAPDerefAliasesAlways:
+		return ldap.DerefAlways, nil
+	default:
+		return 0, fmt.Errorf("derefAliases %q is not valid", deref)
+	}
+}
+
+// groupNameTransformations converts the API representation of the group name transformations, compiling any regular
+// expressions. It returns an error for each invalid transformation.
+func groupNameTransformations(specs []v1alpha1.GroupNameTransformation) ([]upstreamldap.GroupNameTransformation, []error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	transformations := make([]upstreamldap.GroupNameTransformation, 0, len(specs))
+	for i, spec := range specs {
+		field := fmt.Sprintf("groupNameTransformations[%d]", i)
+		transformation := upstreamldap.GroupNameTransformation{}
+		switch spec.Type {
+		case v1alpha1.GroupNameTransformationTypePrefix:
+			transformation.Type = upstreamldap.GroupNameTransformationPrefix
+			transformation.Prefix = spec.Prefix
+			if len(spec.Prefix) == 0 {
+				errs = append(errs, fmt.Errorf("%s.prefix is required when type is %q", field, spec.Type))
+			}
+		case v1alpha1.GroupNameTransformationTypeStripDNComponents:
+			transformation.Type = upstreamldap.GroupNameTransformationStripDNComponents
+		case v1alpha1.GroupNameTransformationTypeLowercase:
+			transformation.Type = upstreamldap.GroupNameTransformationLowercase
+		case v1alpha1.GroupNameTransformationTypeRegexReplace:
+			transformation.Type = upstreamldap.GroupNameTransformationRegexReplace
+			transformation.Replacement = spec.Replacement
+			if len(spec.Regex) == 0 {
+				errs = append(errs, fmt.Errorf("%s.regex is required when type is %q", field, spec.Type))
+				break
+			}
+			regex, err := regexp.Compile(spec.Regex)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s.regex %q is not valid: %w", field, spec.Regex, err))
+				break
+			}
+			transformation.Regex = regex
+		default:
+			errs = append(errs, fmt.Errorf("%s.type %q is not valid", field, spec.Type))
+			continue
+		}
+		if spec.Type != v1alpha1.GroupNameTransformationTypePrefix && len(spec.Prefix) != 0 {
+			errs = append(errs, fmt.Errorf("%s.prefix is not allowed when type is %q", field, spec.Type))
+		}
+		if spec.Type != v1alpha1.GroupNameTransformationTypeRegexReplace && (len(spec.Regex) != 0 || len(spec.Replacement) != 0) {
+			errs = append(errs, fmt.Errorf("%s.regex and replacement are not allowed when type is %q", field, spec.Type))
+		}
+		transformations = append(transformations, transformation)
+	}
+	return transformations, errs
 }
 
+// probeTimeout returns how long to wait for each probe of the LDAP server. It allows enough time for
+// the configured dial and search timeouts to elapse, but never less than the default probe timeout.
+func probeTimeout(config *upstreamldap.ProviderConfig) time.Duration {
+	if timeout := config.DialTimeout + config.SearchTimeout; timeout > probeLDAPTimeout {
+		return timeout
+	}
+	return probeLDAPTimeout
+}
+
+// TestConnection tests the connection to the LDAP server, trying each connection protocol unless one was configured.
+// It also returns whether the secondary bind credentials were needed to bind.
 func TestConnection(
 	ctx context.Context,
 	bindSecretName string,
 	config *upstreamldap.ProviderConfig,
 	currentSecretVersion string,
-) *v1alpha1.Condition {
+) (*v1alpha1.Condition, bool) {
+	if config.ConnectionProtocol != "" {
+		// The protocol was explicitly configured, so never try the other protocol.
+		return testConnectionWithProtocol(ctx, bindSecretName, config, currentSecretVersion)
+	}
+
 	// First try using TLS.
 	config.ConnectionProtocol = upstreamldap.TLS
-	tlsLDAPProvider := upstreamldap.New(*config)
-	err := tlsLDAPProvider.TestConnection(ctx)
+	ldapProvider := upstreamldap.New(*config)
+	err := ldapProvider.TestConnection(ctx)
 	if err != nil {
 		plog.InfoErr("testing LDAP connection using TLS failed, so trying again with StartTLS", err, "host", config.Host)
 		// If there was any error, try again with StartTLS instead.
@@ -177,6 +498,7 @@ func TestConnection(
 			// Successfully able to fall back to using StartTLS, so clear the original
 			// error and consider the connection test to be successful.
 			err = nil
+			ldapProvider = startTLSLDAPProvider
 		} else {
 			plog.InfoErr("testing LDAP connection using StartTLS also failed", err, "host", config.Host)
 			// Falling back to StartTLS also failed, so put TLS back into the config
@@ -190,18 +512,131 @@ func TestConnection(
 			Type:   typeLDAPConnectionValid,
 			Status: v1alpha1.ConditionFalse,
 			Reason: reasonLDAPConnectionError,
-			Message: fmt.Sprintf(`could not successfully connect to "%s" and bind as user "%s": %s`,
-				config.Host, config.BindUsername, err.Error()),
-		}
+			Message: fmt.Sprintf(`could not successfully connect to %s%s: %s`,
+				hostsForMessage(config), bindForMessage(config), err.Error()),
+		}, false
 	}
 
+	return connectionValidCondition(ldapProvider, bindSecretName, config, currentSecretVersion)
+}
+
+// testConnectionWithProtocol tests the connection using only the protocol which is already set in the config.
+func testConnectionWithProtocol(
+	ctx context.Context,
+	bindSecretName string,
+	config *upstreamldap.ProviderConfig,
+	currentSecretVersion string,
+) (*v1alpha1.Condition, bool) {
+	ldapProvider := upstreamldap.New(*config)
+	err := ldapProvider.TestConnection(ctx)
+	if err != nil {
+		plog.InfoErr("testing LDAP connection using the configured connection protocol failed", err,
+			"host", config.Host, "connectionProtocol", config.ConnectionProtocol)
+		return &v1alpha1.Condition{
+			Type:   typeLDAPConnectionValid,
+			Status: v1alpha1.ConditionFalse,
+			Reason: reasonLDAPConnectionError,
+			Message: fmt.Sprintf(`could not successfully connect to %s using %s%s `+
+				`(other connection protocols were not tried because connectionProtocol is configured): %s`,
+				hostsForMessage(config), config.ConnectionProtocol, bindForMessage(config), err.Error()),
+		}, false
+	}
+
+	return connectionValidCondition(ldapProvider, bindSecretName, config, currentSecretVersion)
+}
+
+// connectionValidCondition describes a successful connection test, including which of the hosts and which of the
+// bind credentials were used.
+func connectionValidCondition(
+	ldapProvider *upstreamldap.Provider,
+	bindSecretName string,
+	config *upstreamldap.ProviderConfig,
+	currentSecretVersion string,
+) (*v1alpha1.Condition, bool) {
+	if len(bindSecretName) == 0 {
+		return &v1alpha1.Condition{
+			Type:   typeLDAPConnectionValid,
+			Status: v1alpha1.ConditionTrue,
+			Reason: ReasonSuccess,
+			Message: fmt.Sprintf(`successfully able to connect to "%s" (binding was not tested because no bind secret is configured)`,
+				ldapProvider.LastUsedHost()),
+		}, false
+	}
+
+	usingSecondaryBindCredentials := ldapProvider.UsingSecondaryBindCredentials()
 	return &v1alpha1.Condition{
 		Type:   typeLDAPConnectionValid,
 		Status: v1alpha1.ConditionTrue,
 		Reason: ReasonSuccess,
 		Message: fmt.Sprintf(`successfully able to connect to "%s" and bind as user "%s" [validated with Secret "%s" at version "%s"]`,
-			config.Host, config.BindUsername, bindSecretName, currentSecretVersion),
+			ldapProvider.LastUsedHost(), activeBindUsername(config, usingSecondaryBindCredentials), bindSecretName, currentSecretVersion),
+	}, usingSecondaryBindCredentials
+}
+
+// bindForMessage describes the bind which is tested along with the connection, for use in a condition message.
+func bindForMessage(config *upstreamldap.ProviderConfig) string {
+	if len(config.BindUsername) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(` and bind as user "%s"`, config.BindUsername)
+}
+
+func activeBindUsername(config *upstreamldap.ProviderConfig, usingSecondaryBindCredentials bool) string {
+	if usingSecondaryBindCredentials {
+		return config.SecondaryBindUsername
 	}
+	return config.BindUsername
+}
+
+// activeBindCredentialsCondition describes which of the bind credentials from the bind secret are being used,
+// which is only known when the connection test was successful.
+func activeBindCredentialsCondition(
+	ldapConnectionValidCondition *v1alpha1.Condition,
+	bindSecretName string,
+	config *upstreamldap.ProviderConfig,
+	usingSecondaryBindCredentials bool,
+) *v1alpha1.Condition {
+	if ldapConnectionValidCondition.Status != v1alpha1.ConditionTrue {
+		return &v1alpha1.Condition{
+			Type:    typeActiveBindCredentials,
+			Status:  v1alpha1.ConditionUnknown,
+			Reason:  reasonLDAPConnectionError,
+			Message: "could not determine which bind credentials are working because the LDAP connection could not be validated",
+		}
+	}
+	if len(bindSecretName) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeActiveBindCredentials,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  reasonNoBindCredentials,
+			Message: "no bind secret is configured, so logins bind directly as the end user",
+		}
+	}
+	if usingSecondaryBindCredentials {
+		return &v1alpha1.Condition{
+			Type:   typeActiveBindCredentials,
+			Status: v1alpha1.ConditionTrue,
+			Reason: reasonUsingSecondaryBindCredentials,
+			Message: fmt.Sprintf(`binding as user "%s" using the secondary bind credentials from the %q keys of Secret "%s", `+
+				`because binding using the primary bind credentials failed`,
+				config.SecondaryBindUsername, []string{SecondaryBindUsernameKey, SecondaryBindPasswordKey}, bindSecretName),
+		}
+	}
+	return &v1alpha1.Condition{
+		Type:   typeActiveBindCredentials,
+		Status: v1alpha1.ConditionTrue,
+		Reason: reasonUsingPrimaryBindCredentials,
+		Message: fmt.Sprintf(`binding as user "%s" using the primary bind credentials from the %q keys of Secret "%s"`,
+			config.BindUsername, []string{corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey}, bindSecretName),
+	}
+}
+
+// hostsForMessage describes the configured hosts for use in a condition message.
+func hostsForMessage(config *upstreamldap.ProviderConfig) string {
+	if len(config.FailoverHosts) == 0 {
+		return fmt.Sprintf("%q", config.Host)
+	}
+	return fmt.Sprintf("any of %q", append([]string{config.Host}, config.FailoverHosts...))
 }
 
 func validTLSCondition(message string) *v1alpha1.Condition {
@@ -255,11 +690,86 @@ func ValidateSecret(secretInformer corev1informers.SecretInformer, secretName st
 		}, secret.ResourceVersion
 	}
 
+	// The secondary bind credentials are optional, but when either key is present then both are required.
+	config.SecondaryBindUsername = string(secret.Data[SecondaryBindUsernameKey])
+	config.SecondaryBindPassword = string(secret.Data[SecondaryBindPasswordKey])
+	if len(config.SecondaryBindUsername) == 0 && len(config.SecondaryBindPassword) == 0 {
+		return &v1alpha1.Condition{
+			Type:    typeBindSecretValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  ReasonSuccess,
+			Message: "loaded bind secret",
+		}, secret.ResourceVersion
+	}
+	if len(config.SecondaryBindUsername) == 0 || len(config.SecondaryBindPassword) == 0 {
+		return &v1alpha1.Condition{
+			Type:   typeBindSecretValid,
+			Status: v1alpha1.ConditionFalse,
+			Reason: ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced Secret %q must contain either both or neither of the secondary bind credentials keys %q",
+				secretName, []string{SecondaryBindUsernameKey, SecondaryBindPasswordKey}),
+		}, secret.ResourceVersion
+	}
+
 	return &v1alpha1.Condition{
 		Type:    typeBindSecretValid,
 		Status:  v1alpha1.ConditionTrue,
 		Reason:  ReasonSuccess,
-		Message: "loaded bind secret",
+		Message: "loaded bind secret including secondary bind credentials",
+	}, secret.ResourceVersion
+}
+
+// ValidateClientCertificateSecret loads the client certificate and private key from the given Secret. It returns
+// a bind secret condition, since the client certificate is part of the credentials used to bind to the server.
+func ValidateClientCertificateSecret(secretInformer corev1informers.SecretInformer, secretName string, secretNamespace string, config *upstreamldap.ProviderConfig) (*v1alpha1.Condition, string) {
+	secret, err := secretInformer.Lister().Secrets(secretNamespace).Get(secretName)
+	if err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeBindSecretValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  ReasonNotFound,
+			Message: fmt.Sprintf("client certificate %s", err.Error()),
+		}, ""
+	}
+
+	if secret.Type != LDAPClientCertificateSecretType {
+		return &v1alpha1.Condition{
+			Type:   typeBindSecretValid,
+			Status: v1alpha1.ConditionFalse,
+			Reason: ReasonWrongType,
+			Message: fmt.Sprintf("referenced client certificate Secret %q has wrong type %q (should be %q)",
+				secretName, secret.Type, LDAPClientCertificateSecretType),
+		}, secret.ResourceVersion
+	}
+
+	certPEM := secret.Data[corev1.TLSCertKey]
+	keyPEM := secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return &v1alpha1.Condition{
+			Type:   typeBindSecretValid,
+			Status: v1alpha1.ConditionFalse,
+			Reason: ReasonMissingKeys,
+			Message: fmt.Sprintf("referenced client certificate Secret %q is missing required keys %q",
+				secretName, []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}),
+		}, secret.ResourceVersion
+	}
+
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return &v1alpha1.Condition{
+			Type:    typeBindSecretValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  ReasonInvalidClientCert,
+			Message: fmt.Sprintf("referenced client certificate Secret %q is invalid: %s", secretName, err.Error()),
+		}, secret.ResourceVersion
+	}
+
+	config.ClientCertificateData = certPEM
+	config.ClientKeyData = keyPEM
+	return &v1alpha1.Condition{
+		Type:    typeBindSecretValid,
+		Status:  v1alpha1.ConditionTrue,
+		Reason:  ReasonSuccess,
+		Message: "loaded bind secret and client certificate",
 	}, secret.ResourceVersion
 }
 
@@ -290,22 +800,55 @@ func ValidateGenericLDAP(
 	ctx context.Context,
 	upstream UpstreamGenericLDAPIDP,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
 	validatedSettingsCache ValidatedSettingsCacheI,
 	config *upstreamldap.ProviderConfig,
+	revalidateConnection bool,
 ) GradatedConditions {
 	conditions := GradatedConditions{}
 
-	secretValidCondition, currentSecretVersion := ValidateSecret(secretInformer, upstream.Spec().BindSecretName(), upstream.Namespace(), config)
+	var secretValidCondition *v1alpha1.Condition
+	var currentSecretVersion string
+	if len(upstream.Spec().BindSecretName()) == 0 && len(upstream.Spec().UserDNTemplate()) > 0 {
+		// Logins bind directly as the end user, so the bind secret is optional.
+		secretValidCondition = &v1alpha1.Condition{
+			Type:    typeBindSecretValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  ReasonSuccess,
+			Message: "no bind secret is configured, so logins will bind directly as the end user",
+		}
+	} else {
+		secretValidCondition, currentSecretVersion = ValidateSecret(secretInformer, upstream.Spec().BindSecretName(), upstream.Namespace(), config)
+	}
+	var currentClientCertificateSecretVersion string
+	if secretValidCondition.Status == v1alpha1.ConditionTrue && len(upstream.Spec().ClientCertificateSecretName()) > 0 {
+		secretValidCondition, currentClientCertificateSecretVersion = ValidateClientCertificateSecret(
+			secretInformer, upstream.Spec().ClientCertificateSecretName(), upstream.Namespace(), config)
+	}
 	conditions.Append(secretValidCondition, true)
 
-	tlsValidCondition := ValidateTLSConfig(upstream.Spec().TLSSpec(), config)
+	tlsValidCondition, currentCABundleSourceVersion := ValidateTLSConfig(upstream.Spec().TLSSpec(), upstream.Namespace(), secretInformer, configMapInformer, config)
 	conditions.Append(tlsValidCondition, true)
 
-	var ldapConnectionValidCondition, searchBaseFoundCondition *v1alpha1.Condition
+	proxyURLValidCondition := ValidateProxyURL(upstream.Spec(), config)
+	conditions.Append(proxyURLValidCondition, true)
+
+	timeoutsValidCondition := ValidateTimeouts(upstream.Spec(), config)
+	conditions.Append(timeoutsValidCondition, true)
+
+	searchOptionsValidCondition := ValidateSearchOptions(upstream.Spec(), config)
+	conditions.Append(searchOptionsValidCondition, true)
+
+	var ldapConnectionValidCondition, activeBindCredentialsCondition, searchBaseFoundCondition *v1alpha1.Condition
 	// No point in trying to connect to the server if the config was already determined to be invalid.
-	if secretValidCondition.Status == v1alpha1.ConditionTrue && tlsValidCondition.Status == v1alpha1.ConditionTrue {
-		ldapConnectionValidCondition, searchBaseFoundCondition = validateAndSetLDAPServerConnectivityAndSearchBase(ctx, validatedSettingsCache, upstream, config, currentSecretVersion)
+	if secretValidCondition.Status == v1alpha1.ConditionTrue &&
+		tlsValidCondition.Status == v1alpha1.ConditionTrue &&
+		proxyURLValidCondition.Status == v1alpha1.ConditionTrue &&
+		timeoutsValidCondition.Status == v1alpha1.ConditionTrue &&
+		searchOptionsValidCondition.Status == v1alpha1.ConditionTrue {
+		ldapConnectionValidCondition, activeBindCredentialsCondition, searchBaseFoundCondition = validateAndSetLDAPServerConnectivityAndSearchBase(ctx, validatedSettingsCache, upstream, config, currentSecretVersion, currentClientCertificateSecretVersion, currentCABundleSourceVersion, revalidateConnection)
 		conditions.Append(ldapConnectionValidCondition, false)
+		conditions.Append(activeBindCredentialsCondition, false)
 		if searchBaseFoundCondition != nil { // currently, only used for AD, so may be nil
 			conditions.Append(searchBaseFoundCondition, true)
 		}
@@ -319,24 +862,29 @@ func validateAndSetLDAPServerConnectivityAndSearchBase(
 	upstream UpstreamGenericLDAPIDP,
 	config *upstreamldap.ProviderConfig,
 	currentSecretVersion string,
-) (*v1alpha1.Condition, *v1alpha1.Condition) {
-	validatedSettings, hasPreviousValidatedSettings := validatedSettingsCache.Get(upstream.Name(), currentSecretVersion, upstream.Generation())
+	currentClientCertificateSecretVersion string,
+	currentCABundleSourceVersion string,
+	revalidateConnection bool,
+) (*v1alpha1.Condition, *v1alpha1.Condition, *v1alpha1.Condition) {
+	validatedSettings, hasPreviousValidatedSettings := validatedSettingsCache.Get(ctx, upstream.Name(), upstream.UID(), currentSecretVersion, currentClientCertificateSecretVersion, currentCABundleSourceVersion, upstream.Generation())
 	var ldapConnectionValidCondition, searchBaseFoundCondition *v1alpha1.Condition
+	var usingSecondaryBindCredentials bool
 
-	if hasPreviousValidatedSettings && validatedSettings.UserSearchBase != "" && validatedSettings.GroupSearchBase != "" {
+	if !revalidateConnection && hasPreviousValidatedSettings && validatedSettings.UserSearchBase != "" && validatedSettings.GroupSearchBase != "" {
 		// Found previously validated settings in the cache (which is also not missing search base fields), so use them.
 		config.ConnectionProtocol = validatedSettings.LDAPConnectionProtocol
 		config.UserSearch.Base = validatedSettings.UserSearchBase
 		config.GroupSearch.Base = validatedSettings.GroupSearchBase
 		ldapConnectionValidCondition = validatedSettings.ConnectionValidCondition.DeepCopy()
+		usingSecondaryBindCredentials = validatedSettings.UsingSecondaryBindCredentials
 		searchBaseFoundCondition = validatedSettings.SearchBaseFoundCondition.DeepCopy()
 	} else {
-		// Did not find previously validated settings in the cache, so probe the LDAP server.
-		testConnectionTimeout, cancelFunc := context.WithTimeout(ctx, probeLDAPTimeout)
+		// Did not find previously validated settings in the cache, or it is time to validate them again, so probe the LDAP server.
+		testConnectionTimeout, cancelFunc := context.WithTimeout(ctx, probeTimeout(config))
 		defer cancelFunc()
-		ldapConnectionValidCondition = TestConnection(testConnectionTimeout, upstream.Spec().BindSecretName(), config, currentSecretVersion)
+		ldapConnectionValidCondition, usingSecondaryBindCredentials = TestConnection(testConnectionTimeout, upstream.Spec().BindSecretName(), config, currentSecretVersion)
 
-		searchBaseTimeout, cancelFunc := context.WithTimeout(ctx, probeLDAPTimeout)
+		searchBaseTimeout, cancelFunc := context.WithTimeout(ctx, probeTimeout(config))
 		defer cancelFunc()
 		searchBaseFoundCondition = upstream.Spec().DetectAndSetSearchBase(searchBaseTimeout, config)
 
@@ -345,22 +893,32 @@ func validateAndSetLDAPServerConnectivityAndSearchBase(
 		// but if it exists make sure it was not a failure.
 		if ldapConnectionValidCondition.Status == v1alpha1.ConditionTrue &&
 			(searchBaseFoundCondition == nil || (searchBaseFoundCondition.Status == v1alpha1.ConditionTrue)) {
-			// Remember (in-memory for this pod) that the controller has successfully validated the LDAP or AD provider
-			// using this version of the Secret. This is for performance reasons, to avoid attempting to connect to
-			// the LDAP server more than is needed. If the pod restarts, it will attempt this validation again.
-			validatedSettingsCache.Set(upstream.Name(), ValidatedSettings{
-				IDPSpecGeneration:         upstream.Generation(),
-				BindSecretResourceVersion: currentSecretVersion,
-				LDAPConnectionProtocol:    config.ConnectionProtocol,
-				UserSearchBase:            config.UserSearch.Base,
-				GroupSearchBase:           config.GroupSearch.Base,
-				ConnectionValidCondition:  ldapConnectionValidCondition.DeepCopy(),
-				SearchBaseFoundCondition:  searchBaseFoundCondition.DeepCopy(), // currently, only used for AD, so may be nil
+			// Remember (in-memory for this pod, and optionally persisted) that the controller has successfully validated
+			// the LDAP or AD provider using this version of the Secret. This is for performance reasons, to avoid attempting
+			// to connect to the LDAP server more than is needed. Unless the cache is persisted, if the pod restarts, it will
+			// attempt this validation again.
+			validatedSettingsCache.Set(ctx, upstream.Name(), upstream.UID(), ValidatedSettings{
+				IDPSpecGeneration:                      upstream.Generation(),
+				BindSecretResourceVersion:              currentSecretVersion,
+				ClientCertificateSecretResourceVersion: currentClientCertificateSecretVersion,
+				CABundleSourceResourceVersion:          currentCABundleSourceVersion,
+				LDAPConnectionProtocol:                 config.ConnectionProtocol,
+				UsingSecondaryBindCredentials:          usingSecondaryBindCredentials,
+				UserSearchBase:                         config.UserSearch.Base,
+				GroupSearchBase:                        config.GroupSearch.Base,
+				ConnectionValidCondition:               ldapConnectionValidCondition.DeepCopy(),
+				SearchBaseFoundCondition:               searchBaseFoundCondition.DeepCopy(), // currently, only used for AD, so may be nil
 			})
+		} else if hasPreviousValidatedSettings {
+			// The previously validated settings are no longer valid, so stop using them until they are validated again.
+			validatedSettingsCache.Delete(ctx, upstream.Name(), upstream.UID())
 		}
 	}
 
-	return ldapConnectionValidCondition, searchBaseFoundCondition
+	activeBindCredentialsCondition := activeBindCredentialsCondition(
+		ldapConnectionValidCondition, upstream.Spec().BindSecretName(), config, usingSecondaryBindCredentials)
+
+	return ldapConnectionValidCondition, activeBindCredentialsCondition, searchBaseFoundCondition
 }
 
 func EvaluateConditions(conditions GradatedConditions, config *upstreamldap.ProviderConfig) (provider.UpstreamLDAPIdentityProviderI, bool) {
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorstorage
@@ -7,38 +7,63 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/ory/fosite"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/clock"
 	clocktesting "k8s.io/utils/clock/testing"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/consentstorage"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/fositestorage/accesstoken"
 	"go.pinniped.dev/internal/fositestorage/authorizationcode"
+	"go.pinniped.dev/internal/fositestorage/devicecode"
 	"go.pinniped.dev/internal/fositestorage/openidconnect"
 	"go.pinniped.dev/internal/fositestorage/pkce"
 	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc/backchannellogout"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/psession"
 )
 
-const minimumRepeatInterval = 30 * time.Second
+const (
+	// garbageCollectAfterIndexName is the name of the index of the Secret informer which groups the Secrets which
+	// requested garbage collection by the minute in which they expire, so that each sweep only needs to look at the
+	// Secrets which may have expired, instead of at every Secret.
+	garbageCollectAfterIndexName = "garbageCollectAfter"
+
+	// garbageCollectAfterIndexBucketSize is the granularity of the index values of garbageCollectAfterIndexName.
+	garbageCollectAfterIndexBucketSize = time.Minute
+
+	// unparsableGarbageCollectAfterIndexValue is the index value of the Secrets whose annotation cannot be parsed.
+	unparsableGarbageCollectAfterIndexValue = "unparsable"
+
+	// delayBetweenBatches is how long to wait before deleting the next batch of expired Secrets, when there were
+	// more expired Secrets than the batch size.
+	delayBetweenBatches = time.Second
+)
 
 type garbageCollectorController struct {
-	idpCache              UpstreamOIDCIdentityProviderICache
-	secretInformer        corev1informers.SecretInformer
-	kubeClient            kubernetes.Interface
-	clock                 clock.Clock
-	timeOfMostRecentSweep time.Time
+	idpCache        UpstreamOIDCIdentityProviderICache
+	logoutNotifier  backchannellogout.NotifierI
+	secretInformer  corev1informers.SecretInformer
+	kubeClient      kubernetes.Interface
+	clock           clock.Clock
+	interval        time.Duration
+	batchSize       int
+	timeOfNextSweep time.Time
 }
 
 // UpstreamOIDCIdentityProviderICache is a thread safe cache that holds a list of validated upstream OIDC IDP configurations.
@@ -46,9 +71,15 @@ type UpstreamOIDCIdentityProviderICache interface {
 	GetOIDCIdentityProviders() []provider.UpstreamOIDCIdentityProviderI
 }
 
+// GarbageCollectorController deletes the Secrets which requested garbage collection via annotations once they have
+// expired. It sweeps at most once per interval, and it deletes at most batchSize Secrets per sweep. When more Secrets
+// have expired, the next sweep happens shortly afterwards instead of after the interval.
 func GarbageCollectorController(
 	idpCache UpstreamOIDCIdentityProviderICache,
+	logoutNotifier backchannellogout.NotifierI,
 	clock clock.Clock,
+	interval time.Duration,
+	batchSize int,
 	kubeClient kubernetes.Interface,
 	secretInformer corev1informers.SecretInformer,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
@@ -61,14 +92,26 @@ func GarbageCollectorController(
 		_, ok = secret.Annotations[crud.SecretLifetimeAnnotationKey]
 		return ok
 	}
+
+	// The index must be added before the informer starts. When it could not be added, each sweep falls back
+	// to looking at every Secret.
+	if err := secretInformer.Informer().AddIndexers(cache.Indexers{
+		garbageCollectAfterIndexName: garbageCollectAfterIndexFunc,
+	}); err != nil {
+		plog.Error("could not add garbage collection index to Secret informer", err)
+	}
+
 	return controllerlib.New(
 		controllerlib.Config{
 			Name: "garbage-collector-controller",
 			Syncer: &garbageCollectorController{
 				idpCache:       idpCache,
+				logoutNotifier: logoutNotifier,
 				secretInformer: secretInformer,
 				kubeClient:     kubeClient,
 				clock:          clock,
+				interval:       interval,
+				batchSize:      batchSize,
 			},
 		},
 		withInformer(
@@ -86,6 +129,25 @@ func GarbageCollectorController(
 	)
 }
 
+// garbageCollectAfterIndexFunc indexes the Secrets which requested garbage collection by the start of the minute
+// in which they expire.
+func garbageCollectAfterIndexFunc(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return nil, nil
+	}
+	timeString, ok := secret.Annotations[crud.SecretLifetimeAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	garbageCollectAfterTime, err := time.Parse(crud.SecretLifetimeAnnotationDateFormat, timeString)
+	if err != nil {
+		// Still index it, so that each sweep can warn about it.
+		return []string{unparsableGarbageCollectAfterIndexValue}, nil
+	}
+	return []string{garbageCollectAfterTime.UTC().Truncate(garbageCollectAfterIndexBucketSize).Format(time.RFC3339)}, nil
+}
+
 func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 	// make sure we have a consistent, static meaning for the current time during the sync loop
 	frozenClock := clocktesting.NewFakeClock(c.clock.Now())
@@ -94,19 +156,20 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 	// controller too chatty, so it rate limits itself to a more reasonable interval.
 	// Note that even during a period when no secrets are changing, it will still run
 	// at the informer's full-resync interval (as long as there are some secrets).
-	if since := frozenClock.Since(c.timeOfMostRecentSweep); since < minimumRepeatInterval {
-		ctx.Queue.AddAfter(ctx.Key, minimumRepeatInterval-since)
+	if until := c.timeOfNextSweep.Sub(frozenClock.Now()); until > 0 {
+		ctx.Queue.AddAfter(ctx.Key, until)
 		return nil
 	}
 
 	plog.Info("starting storage garbage collection sweep")
-	c.timeOfMostRecentSweep = frozenClock.Now()
+	c.timeOfNextSweep = frozenClock.Now().Add(c.interval)
 
-	listOfSecrets, err := c.secretInformer.Lister().List(labels.Everything())
+	listOfSecrets, err := c.listSecretsWhichMayHaveExpired(frozenClock.Now())
 	if err != nil {
 		return err
 	}
 
+	deleted := 0
 	for i := range listOfSecrets {
 		secret := listOfSecrets[i]
 
@@ -128,6 +191,15 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 			continue
 		}
 
+		if deleted >= c.batchSize {
+			// Delete the remaining expired Secrets in the next batch, to avoid overloading the Kubernetes API server.
+			plog.Info("storage garbage collector reached its batch size, continuing with the next batch soon",
+				"batchSize", c.batchSize, "delay", delayBetweenBatches)
+			c.timeOfNextSweep = frozenClock.Now().Add(delayBetweenBatches)
+			ctx.Queue.AddAfter(ctx.Key, delayBetweenBatches)
+			return nil
+		}
+
 		// The Secret has expired. Check if it is a downstream session storage Secret, which may require extra processing.
 		storageType, isSessionStorage := secret.Labels[crud.SecretLabelKey]
 		if isSessionStorage {
@@ -152,6 +224,7 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 		}
 
 		// Garbage collect the Secret.
+		deleted++
 		err = c.kubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx.Context, secret.Name, metav1.DeleteOptions{
 			Preconditions: &metav1.Preconditions{
 				UID:             &secret.UID,
@@ -163,11 +236,96 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 			continue
 		}
 		plog.Info("storage garbage collector deleted resource", logKV(secret)...)
+
+		if isSessionStorage {
+			if notifyErr := c.maybeNotifySessionEnded(ctx.Context, storageType, secret); notifyErr != nil {
+				plog.WarningErr("garbage collector could not send back-channel logout token", notifyErr, logKV(secret)...)
+			}
+		}
 	}
 
 	return nil
 }
 
+// listSecretsWhichMayHaveExpired returns the Secrets which requested garbage collection and which may have expired
+// by the given time, oldest first. This also includes the Secrets whose expiration time cannot be parsed.
+func (c *garbageCollectorController) listSecretsWhichMayHaveExpired(now time.Time) ([]*v1.Secret, error) {
+	indexer := c.secretInformer.Informer().GetIndexer()
+	if _, ok := indexer.GetIndexers()[garbageCollectAfterIndexName]; !ok {
+		return c.secretInformer.Lister().List(labels.Everything())
+	}
+
+	indexValues := indexer.ListIndexFuncValues(garbageCollectAfterIndexName)
+	// The index values are UTC timestamps in RFC3339 format, so sorting them also sorts them by time.
+	sort.Strings(indexValues)
+
+	var secrets []*v1.Secret
+	for _, indexValue := range indexValues {
+		if indexValue != unparsableGarbageCollectAfterIndexValue {
+			bucketStart, err := time.Parse(time.RFC3339, indexValue)
+			if err != nil || !bucketStart.Before(now) {
+				// None of the Secrets in this bucket have expired yet.
+				continue
+			}
+		}
+		objs, err := indexer.ByIndex(garbageCollectAfterIndexName, indexValue)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if secret, ok := obj.(*v1.Secret); ok {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// maybeNotifySessionEnded audits the end of the downstream session and notifies its client, when the deleted
+// Secret was the storage of the latest tokens of the session.
+func (c *garbageCollectorController) maybeNotifySessionEnded(ctx context.Context, storageType string, secret *v1.Secret) error {
+	request, err := endedSessionRequest(storageType, secret)
+	if err != nil || request == nil {
+		return err
+	}
+
+	auditlog.Log(auditlog.NewRecord(auditlog.EventSessionGarbageCollected, nil, request))
+
+	if c.logoutNotifier == nil {
+		return nil
+	}
+	return c.logoutNotifier.NotifySessionEnded(ctx, request)
+}
+
+// endedSessionRequest returns the request of the downstream session which ended because the Secret was deleted, or
+// nil when the session did not end. A session ends exactly once, using the same reasoning as for upstream token
+// revocation above: the session ends when the storage which holds its latest tokens is deleted.
+func endedSessionRequest(storageType string, secret *v1.Secret) (fosite.Requester, error) {
+	switch storageType {
+	case accesstoken.TypeLabelValue:
+		accessTokenSession, err := accesstoken.ReadFromSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		if accessTokenSession.Request.GetGrantedScopes().Has(oidcapi.ScopeOfflineAccess) {
+			// The refresh token storage of this session will end the session instead.
+			return nil, nil
+		}
+		return accessTokenSession.Request, nil
+
+	case refreshtoken.TypeLabelValue:
+		refreshTokenSession, err := refreshtoken.ReadFromSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		return refreshTokenSession.Request, nil
+
+	default:
+		// The other storage types expire before a session has any tokens, so there is no session to end.
+		return nil, nil
+	}
+}
+
 func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Context, storageType string, secret *v1.Secret) error {
 	// All downstream session storage types hold upstream tokens when the upstream IDP is an OIDC provider.
 	// However, some of them will be outdated because they are not updated by fosite after creation.
@@ -231,6 +389,25 @@ func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Co
 		// be revoked by one of the other cases above.
 		return nil
 
+	case devicecode.TypeLabelValue:
+		// For device code storage, there is no upstream token inside. At most, it holds a downstream authcode,
+		// whose upstream token revocation is handled by the authcode storage case above.
+		return nil
+
+	case consentstorage.TypeLabelValue:
+		// For consent storage, there is no upstream token inside. It only remembers the decisions of the users.
+		return nil
+
+	case consentstorage.PendingTypeLabelValue:
+		// For pending consent storage, its existence means that the user never decided on the consent page, because
+		// these are deleted when the user decides. The login never finished, so this storage holds the only copy
+		// of the upstream token.
+		pendingConsent, err := consentstorage.ReadPendingConsentFromSecret(secret)
+		if err != nil {
+			return err
+		}
+		return c.tryRevokeUpstreamOIDCToken(ctx, pendingConsent.Session.Custom, secret)
+
 	default:
 		// There are no other storage types, so this should never happen in practice.
 		return errors.New("garbage collector saw invalid label on Secret when trying to determine if upstream revocation was needed")
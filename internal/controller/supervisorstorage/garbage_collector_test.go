@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorstorage
@@ -15,6 +15,7 @@ import (
 	"github.com/sclevine/spec/report"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -48,8 +49,11 @@ func TestGarbageCollectorControllerInformerFilters(t *testing.T) {
 			observableWithInformerOption = testutil.NewObservableWithInformerOption()
 			secretsInformer := kubeinformers.NewSharedInformerFactory(nil, 0).Core().V1().Secrets()
 			_ = GarbageCollectorController(
+				nil,
 				nil,
 				clock.RealClock{},
+				30*time.Second,
+				100,
 				nil,
 				secretsInformer,
 				observableWithInformerOption.WithInformer, // make it possible to observe the behavior of the Filters
@@ -133,6 +137,8 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			syncContext             *controllerlib.Context
 			fakeClock               *clocktesting.FakeClock
 			frozenNow               time.Time
+			logoutNotifier          *fakeLogoutNotifier
+			batchSize               int
 		)
 
 		// Defer starting the informers until the last possible moment so that the
@@ -141,7 +147,10 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			// Set this at the last second to allow for injection of server override.
 			subject = GarbageCollectorController(
 				idpCache,
+				logoutNotifier,
 				fakeClock,
+				30*time.Second,
+				batchSize,
 				kubeClient,
 				kubeInformers.Core().V1().Secrets(),
 				controllerlib.WithInformer,
@@ -173,6 +182,8 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			kubeInformers = kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
 			frozenNow = time.Now().UTC()
 			fakeClock = clocktesting.NewFakeClock(frozenNow)
+			logoutNotifier = &fakeLogoutNotifier{}
+			batchSize = 100
 
 			unrelatedSecret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -263,11 +274,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there are valid, expired authcode secrets which contain upstream refresh tokens", func() {
 			it.Before(func() {
 				activeOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -308,11 +319,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 				r.NoError(kubeClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
 
 				inactiveOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  false,
 					Request: &fosite.Request{
 						ID:     "request-id-2",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -381,17 +392,20 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					kubeClient.Actions(),
 				)
+
+				// Authcode storage never ends a downstream session which had tokens.
+				r.Empty(logoutNotifier.notifiedRequestIDs)
 			})
 		})
 
 		when("there are valid, expired authcode secrets which contain upstream access tokens", func() {
 			it.Before(func() {
 				activeOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -432,11 +446,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 				r.NoError(kubeClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
 
 				inactiveOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  false,
 					Request: &fosite.Request{
 						ID:     "request-id-2",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -511,11 +525,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there is an invalid, expired authcode secret", func() {
 			it.Before(func() {
 				invalidOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "", // it is invalid for there to be a missing request ID
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -580,11 +594,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there is a valid, expired authcode secret but its upstream name does not match any existing upstream", func() {
 			it.Before(func() {
 				wrongProviderNameOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -651,11 +665,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there is a valid, expired authcode secret but its upstream UID does not match any existing upstream", func() {
 			it.Before(func() {
 				wrongProviderNameOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -722,11 +736,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there is a valid, recently expired authcode secret but the upstream revocation fails", func() {
 			it.Before(func() {
 				activeOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -827,11 +841,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there is a valid, long-since expired authcode secret but the upstream revocation fails", func() {
 			it.Before(func() {
 				activeOIDCAuthcodeSession := &authorizationcode.Session{
-					Version: "4",
+					Version: "16",
 					Active:  true,
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -906,11 +920,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there are valid, expired access token secrets which contain upstream refresh tokens", func() {
 			it.Before(func() {
 				offlineAccessGrantedOIDCAccessTokenSession := &accesstoken.Session{
-					Version: "4",
+					Version: "16",
 					Request: &fosite.Request{
 						GrantedScope: fosite.Arguments{"scope1", "scope2", "offline_access"},
 						ID:           "request-id-1",
-						Client:       &clientregistry.Client{},
+						Client:       newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -951,11 +965,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 				r.NoError(kubeClient.Tracker().Add(offlineAccessGrantedOIDCAccessTokenSessionSecret))
 
 				offlineAccessNotGrantedOIDCAccessTokenSession := &accesstoken.Session{
-					Version: "4",
+					Version: "16",
 					Request: &fosite.Request{
 						GrantedScope: fosite.Arguments{"scope1", "scope2"},
 						ID:           "request-id-2",
-						Client:       &clientregistry.Client{},
+						Client:       newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -1024,17 +1038,20 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					kubeClient.Actions(),
 				)
+
+				// Only the downstream session which had no offline_access granted ends with its access token.
+				r.Equal([]string{"request-id-2"}, logoutNotifier.notifiedRequestIDs)
 			})
 		})
 
 		when("there are valid, expired access token secrets which contain upstream access tokens", func() {
 			it.Before(func() {
 				offlineAccessGrantedOIDCAccessTokenSession := &accesstoken.Session{
-					Version: "4",
+					Version: "16",
 					Request: &fosite.Request{
 						GrantedScope: fosite.Arguments{"scope1", "scope2", "offline_access"},
 						ID:           "request-id-1",
-						Client:       &clientregistry.Client{},
+						Client:       newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -1075,11 +1092,11 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 				r.NoError(kubeClient.Tracker().Add(offlineAccessGrantedOIDCAccessTokenSessionSecret))
 
 				offlineAccessNotGrantedOIDCAccessTokenSession := &accesstoken.Session{
-					Version: "4",
+					Version: "16",
 					Request: &fosite.Request{
 						GrantedScope: fosite.Arguments{"scope1", "scope2"},
 						ID:           "request-id-2",
-						Client:       &clientregistry.Client{},
+						Client:       newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -1154,10 +1171,10 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 		when("there are valid, expired refresh secrets which contain upstream refresh tokens", func() {
 			it.Before(func() {
 				oidcRefreshSession := &refreshtoken.Session{
-					Version: "4",
+					Version: "16",
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -1225,16 +1242,19 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					kubeClient.Actions(),
 				)
+
+				// The client is notified that the downstream session has ended.
+				r.Equal([]string{"request-id-1"}, logoutNotifier.notifiedRequestIDs)
 			})
 		})
 
 		when("there are valid, expired refresh secrets which contain upstream access tokens", func() {
 			it.Before(func() {
 				oidcRefreshSession := &refreshtoken.Session{
-					Version: "4",
+					Version: "16",
 					Request: &fosite.Request{
 						ID:     "request-id-1",
-						Client: &clientregistry.Client{},
+						Client: newTestClient(),
 						Session: &psession.PinnipedSession{
 							Custom: &psession.CustomSessionData{
 								Username:     "should be ignored by garbage collector",
@@ -1362,6 +1382,86 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			})
 		})
 
+		when("there are more expired secrets than the batch size", func() {
+			it.Before(func() {
+				batchSize = 1
+				olderExpiredSecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "older expired secret",
+						Namespace:       installedInNamespace,
+						UID:             "uid-121",
+						ResourceVersion: "rv-131",
+						Annotations: map[string]string{
+							"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(-2 * time.Hour).Format(time.RFC3339),
+						},
+					},
+				}
+				r.NoError(kubeInformerClient.Tracker().Add(olderExpiredSecret))
+				r.NoError(kubeClient.Tracker().Add(olderExpiredSecret))
+				newerExpiredSecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "newer expired secret",
+						Namespace:       installedInNamespace,
+						UID:             "uid-141",
+						ResourceVersion: "rv-151",
+						Annotations: map[string]string{
+							"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(-time.Hour).Format(time.RFC3339),
+						},
+					},
+				}
+				r.NoError(kubeInformerClient.Tracker().Add(newerExpiredSecret))
+				r.NoError(kubeClient.Tracker().Add(newerExpiredSecret))
+			})
+
+			it("deletes the oldest secrets first and deletes the remaining secrets in the next batch soon afterwards", func() {
+				startInformersAndController(nil)
+
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+				r.Equal(
+					[]kubetesting.Action{
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "older expired secret", testutil.NewPreconditions("uid-121", "rv-131")),
+					},
+					kubeClient.Actions(),
+				)
+				r.True(syncContext.Queue.(*testQueue).called)
+				r.Equal(time.Second, syncContext.Queue.(*testQueue).duration)
+
+				syncContext.Queue = &testQueue{t: t} // reset the queue for the next sync
+
+				// Let the informer observe the deletion.
+				r.NoError(kubeInformerClient.Tracker().Delete(secretsGVR, installedInNamespace, "older expired secret"))
+				r.Eventually(func() bool {
+					_, err := kubeInformers.Core().V1().Secrets().Lister().Secrets(installedInNamespace).Get("older expired secret")
+					return apierrors.IsNotFound(err)
+				}, 10*time.Second, 10*time.Millisecond)
+
+				// The next batch is not deleted before the delay has passed.
+				fakeClock.Step(500 * time.Millisecond)
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+				r.Len(kubeClient.Actions(), 1)
+				r.Equal(500*time.Millisecond, syncContext.Queue.(*testQueue).duration)
+
+				syncContext.Queue = &testQueue{t: t} // reset the queue for the next sync
+
+				fakeClock.Step(500 * time.Millisecond)
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+				r.Equal(
+					[]kubetesting.Action{
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "older expired secret", testutil.NewPreconditions("uid-121", "rv-131")),
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "newer expired secret", testutil.NewPreconditions("uid-141", "rv-151")),
+					},
+					kubeClient.Actions(),
+				)
+				// All expired secrets were deleted, so the next sweep happens after the usual interval.
+				r.False(syncContext.Queue.(*testQueue).called)
+
+				list, err := kubeClient.CoreV1().Secrets(installedInNamespace).List(context.Background(), metav1.ListOptions{})
+				r.NoError(err)
+				r.Len(list.Items, 1)
+				r.Equal("some other unrelated secret", list.Items[0].Name)
+			})
+		})
+
 		when("there is a secret with a malformed garbage-collect-after date", func() {
 			it.Before(func() {
 				malformedSecret := &corev1.Secret{
@@ -1463,6 +1563,63 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 	}, spec.Parallel(), spec.Report(report.Terminal{}))
 }
 
+func TestGarbageCollectAfterIndexFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want []string
+	}{
+		{
+			name: "secret with the garbage-collect-after annotation is indexed by the minute in which it expires",
+			obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"storage.pinniped.dev/garbage-collect-after": "2030-01-02T03:04:05Z",
+			}}},
+			want: []string{"2030-01-02T03:04:00Z"},
+		},
+		{
+			name: "timestamps in other time zones are indexed in UTC",
+			obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"storage.pinniped.dev/garbage-collect-after": "2030-01-02T05:04:05+02:00",
+			}}},
+			want: []string{"2030-01-02T03:04:00Z"},
+		},
+		{
+			name: "secret with a malformed garbage-collect-after annotation is still indexed",
+			obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"storage.pinniped.dev/garbage-collect-after": "not a timestamp",
+			}}},
+			want: []string{"unparsable"},
+		},
+		{
+			name: "secret without the garbage-collect-after annotation is not indexed",
+			obj:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"other": "annotation"}}},
+			want: nil,
+		},
+		{
+			name: "other types are not indexed",
+			obj:  &corev1.ConfigMap{},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := garbageCollectAfterIndexFunc(test.obj)
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+// newTestClient returns a client of a stored session, which always has the DefaultClient that fosite stored.
+func newTestClient() *clientregistry.Client {
+	return &clientregistry.Client{
+		DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: "pinniped-cli"}},
+	}
+}
+
 type testQueue struct {
 	t *testing.T
 
@@ -1482,3 +1639,12 @@ func (q *testQueue) AddAfter(key controllerlib.Key, duration time.Duration) {
 	q.key = key
 	q.duration = duration
 }
+
+type fakeLogoutNotifier struct {
+	notifiedRequestIDs []string
+}
+
+func (f *fakeLogoutNotifier) NotifySessionEnded(_ context.Context, requester fosite.Requester) error {
+	f.notifiedRequestIDs = append(f.notifiedRequestIDs, requester.GetID())
+	return nil
+}
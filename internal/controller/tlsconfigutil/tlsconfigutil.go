@@ -0,0 +1,81 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tlsconfigutil contains helpers for loading the CA bundles which are referenced by TLS configurations.
+package tlsconfigutil
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// KindSecret is the kind of a CABundleSource which refers to a Secret.
+	KindSecret = "Secret"
+
+	// KindConfigMap is the kind of a CABundleSource which refers to a ConfigMap.
+	KindConfigMap = "ConfigMap"
+)
+
+// CABundleSource identifies a key of a Secret or ConfigMap whose value is a PEM-encoded CA bundle.
+type CABundleSource struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Key       string
+}
+
+// IsCABundleSecretType returns true when a Secret of the given type may hold a CA bundle.
+func IsCABundleSecretType(secretType corev1.SecretType) bool {
+	return secretType == corev1.SecretTypeOpaque || secretType == corev1.SecretTypeTLS
+}
+
+// ReadCABundle reads the CA bundle from the Secret or ConfigMap which is referenced by the source. Along with the
+// bundle, it returns the resource version of the Secret or ConfigMap, so callers can tell when the bundle may have
+// changed.
+func ReadCABundle(
+	source CABundleSource,
+	secretLister corev1listers.SecretLister,
+	configMapLister corev1listers.ConfigMapLister,
+) (*x509.CertPool, []byte, string, error) {
+	var bundle []byte
+	var resourceVersion string
+	switch source.Kind {
+	case KindSecret:
+		secret, err := secretLister.Secrets(source.Namespace).Get(source.Name)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get secret %q: %w", source.Name, err)
+		}
+		if !IsCABundleSecretType(secret.Type) {
+			return nil, nil, "", fmt.Errorf("secret %q has wrong type %q (should be %q or %q)",
+				source.Name, secret.Type, corev1.SecretTypeOpaque, corev1.SecretTypeTLS)
+		}
+		value, ok := secret.Data[source.Key]
+		if !ok {
+			return nil, nil, "", fmt.Errorf("key %q not found in secret %q", source.Key, source.Name)
+		}
+		bundle, resourceVersion = value, secret.ResourceVersion
+	case KindConfigMap:
+		configMap, err := configMapLister.ConfigMaps(source.Namespace).Get(source.Name)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get configmap %q: %w", source.Name, err)
+		}
+		value, ok := configMap.Data[source.Key]
+		if !ok {
+			return nil, nil, "", fmt.Errorf("key %q not found in configmap %q", source.Key, source.Name)
+		}
+		bundle, resourceVersion = []byte(value), configMap.ResourceVersion
+	default:
+		return nil, nil, "", fmt.Errorf("kind %q is not valid", source.Kind)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, nil, "", fmt.Errorf("key %q of %s %q does not contain any valid PEM-encoded certificates",
+			source.Key, source.Kind, source.Name)
+	}
+	return pool, bundle, resourceVersion, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tlsconfigutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/certauthority"
+)
+
+func TestReadCABundle(t *testing.T) {
+	const namespace = "some-namespace"
+
+	ca, err := certauthority.New("Test CA", time.Hour)
+	require.NoError(t, err)
+	bundle := ca.Bundle()
+
+	objects := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "opaque-secret", Namespace: namespace, ResourceVersion: "1"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"ca.crt": bundle, "not-a-bundle": []byte("hello")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-secret", Namespace: namespace, ResourceVersion: "2"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"ca.crt": bundle},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic-auth-secret", Namespace: namespace, ResourceVersion: "3"},
+			Type:       corev1.SecretTypeBasicAuth,
+			Data:       map[string][]byte{"ca.crt": bundle},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-configmap", Namespace: namespace, ResourceVersion: "4"},
+			Data:       map[string]string{"ca.crt": string(bundle)},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-configmap", Namespace: "other-namespace", ResourceVersion: "5"},
+			Data:       map[string]string{"ca.crt": string(bundle)},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		source              CABundleSource
+		wantBundle          []byte
+		wantResourceVersion string
+		wantErr             string
+	}{
+		{
+			name:                "opaque secret",
+			source:              CABundleSource{Kind: KindSecret, Name: "opaque-secret", Namespace: namespace, Key: "ca.crt"},
+			wantBundle:          bundle,
+			wantResourceVersion: "1",
+		},
+		{
+			name:                "tls secret",
+			source:              CABundleSource{Kind: KindSecret, Name: "tls-secret", Namespace: namespace, Key: "ca.crt"},
+			wantBundle:          bundle,
+			wantResourceVersion: "2",
+		},
+		{
+			name:                "configmap",
+			source:              CABundleSource{Kind: KindConfigMap, Name: "some-configmap", Namespace: namespace, Key: "ca.crt"},
+			wantBundle:          bundle,
+			wantResourceVersion: "4",
+		},
+		{
+			name:    "secret of the wrong type",
+			source:  CABundleSource{Kind: KindSecret, Name: "basic-auth-secret", Namespace: namespace, Key: "ca.crt"},
+			wantErr: `secret "basic-auth-secret" has wrong type "kubernetes.io/basic-auth" (should be "Opaque" or "kubernetes.io/tls")`,
+		},
+		{
+			name:    "secret not found",
+			source:  CABundleSource{Kind: KindSecret, Name: "missing-secret", Namespace: namespace, Key: "ca.crt"},
+			wantErr: `failed to get secret "missing-secret": secret "missing-secret" not found`,
+		},
+		{
+			name:    "configmap in another namespace is not found",
+			source:  CABundleSource{Kind: KindConfigMap, Name: "some-configmap", Namespace: "yet-another-namespace", Key: "ca.crt"},
+			wantErr: `failed to get configmap "some-configmap": configmap "some-configmap" not found`,
+		},
+		{
+			name:    "missing key",
+			source:  CABundleSource{Kind: KindConfigMap, Name: "some-configmap", Namespace: namespace, Key: "missing.crt"},
+			wantErr: `key "missing.crt" not found in configmap "some-configmap"`,
+		},
+		{
+			name:    "value is not a PEM bundle",
+			source:  CABundleSource{Kind: KindSecret, Name: "opaque-secret", Namespace: namespace, Key: "not-a-bundle"},
+			wantErr: `key "not-a-bundle" of Secret "opaque-secret" does not contain any valid PEM-encoded certificates`,
+		},
+		{
+			name:    "invalid kind",
+			source:  CABundleSource{Kind: "Pod", Name: "some-pod", Namespace: namespace, Key: "ca.crt"},
+			wantErr: `kind "Pod" is not valid`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kubeInformers := informers.NewSharedInformerFactory(fake.NewSimpleClientset(objects...), 0)
+			secretInformer := kubeInformers.Core().V1().Secrets()
+			configMapInformer := kubeInformers.Core().V1().ConfigMaps()
+			// Register the informers before starting them.
+			secretInformer.Informer()
+			configMapInformer.Informer()
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			kubeInformers.Start(ctx.Done())
+			kubeInformers.WaitForCacheSync(ctx.Done())
+
+			pool, gotBundle, gotResourceVersion, err := ReadCABundle(tt.source, secretInformer.Lister(), configMapInformer.Lister())
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, pool)
+				require.Nil(t, gotBundle)
+				require.Empty(t, gotResourceVersion)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ca.Pool().Equal(pool))
+			require.Equal(t, tt.wantBundle, gotBundle)
+			require.Equal(t, tt.wantResourceVersion, gotResourceVersion)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package controller
@@ -44,6 +44,22 @@ func MatchAnySecretOfTypeFilter(secretType v1.SecretType, parentFunc controllerl
 	return SimpleFilter(isSecretOfType, parentFunc)
 }
 
+func MatchAnySecretOfTypesFilter(secretTypes []v1.SecretType, parentFunc controllerlib.ParentFunc) controllerlib.Filter {
+	isSecretOfTypes := func(obj metav1.Object) bool {
+		secret, ok := obj.(*v1.Secret)
+		if !ok {
+			return false
+		}
+		for _, secretType := range secretTypes {
+			if secret.Type == secretType {
+				return true
+			}
+		}
+		return false
+	}
+	return SimpleFilter(isSecretOfTypes, parentFunc)
+}
+
 func SecretIsControlledByParentFunc(matchFunc func(obj metav1.Object) bool) func(obj metav1.Object) controllerlib.Key {
 	return func(obj metav1.Object) controllerlib.Key {
 		if matchFunc(obj) {
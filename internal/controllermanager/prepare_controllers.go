@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package controllermanager provides an entrypoint into running all of the controllers that run as
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"time"
 
+	"k8s.io/apiserver/pkg/server/healthz"
 	k8sinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/clock"
@@ -16,21 +17,30 @@ import (
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
 	"go.pinniped.dev/internal/apiserviceref"
+	"go.pinniped.dev/internal/certauthority/csrcertauthority"
 	"go.pinniped.dev/internal/concierge/impersonator"
 	"go.pinniped.dev/internal/config/concierge"
 	"go.pinniped.dev/internal/controller/apicerts"
 	"go.pinniped.dev/internal/controller/authenticator/authncache"
 	"go.pinniped.dev/internal/controller/authenticator/cachecleaner"
 	"go.pinniped.dev/internal/controller/authenticator/jwtcachefiller"
+	"go.pinniped.dev/internal/controller/authenticator/satokencachefiller"
 	"go.pinniped.dev/internal/controller/authenticator/webhookcachefiller"
+	"go.pinniped.dev/internal/controller/authenticator/x509cachefiller"
+	"go.pinniped.dev/internal/controller/clientcertconfig"
+	"go.pinniped.dev/internal/controller/credentialpolicyconfig"
+	"go.pinniped.dev/internal/controller/csrstrategy"
 	"go.pinniped.dev/internal/controller/impersonatorconfig"
 	"go.pinniped.dev/internal/controller/kubecertagent"
 	"go.pinniped.dev/internal/controllerinit"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/credentialpolicy"
 	"go.pinniped.dev/internal/deploymentref"
 	"go.pinniped.dev/internal/downward"
 	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/healthcheck"
+	"go.pinniped.dev/internal/issuer"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/leaderelection"
 	"go.pinniped.dev/internal/plog"
@@ -92,25 +102,35 @@ type Config struct {
 	// AuthenticatorCache is a cache of authenticators shared amongst various authenticated-related controllers.
 	AuthenticatorCache *authncache.Cache
 
+	// ClientCertLifetime holds the lifetime of the client certificates issued by the TokenCredentialRequest API.
+	ClientCertLifetime *issuer.DynamicClientCertLifetime
+
+	// CredentialPolicy holds the policy which decides whether the TokenCredentialRequest API may issue client certificates.
+	CredentialPolicy *credentialpolicy.DynamicPolicy
+
+	// CSRCertAuthority issues client certs using the certificates.k8s.io API after it is configured by a controller.
+	CSRCertAuthority *csrcertauthority.CA
+
 	// Labels are labels that should be added to any resources created by the controllers.
 	Labels map[string]string
 }
 
 // PrepareControllers prepares the controllers and their informers and returns a function that will start them when called.
-func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nolint:funlen // Eh, fair, it is a really long function...but it is wiring the world...so...
+// It also returns the readiness checks of the controllers.
+func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, []healthz.HealthChecker, error) { //nolint:funlen // Eh, fair, it is a really long function...but it is wiring the world...so...
 	loginConciergeGroupData, identityConciergeGroupData := groupsuffix.ConciergeAggregatedGroups(c.APIGroupSuffix)
 
 	dref, deployment, _, err := deploymentref.New(c.ServerInstallationInfo)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create deployment ref: %w", err)
+		return nil, nil, fmt.Errorf("cannot create deployment ref: %w", err)
 	}
 
 	apiServiceRef, err := apiserviceref.New(loginConciergeGroupData.APIServiceName())
 	if err != nil {
-		return nil, fmt.Errorf("cannot create API service ref: %w", err)
+		return nil, nil, fmt.Errorf("cannot create API service ref: %w", err)
 	}
 
-	client, leaderElector, err := leaderelection.New(
+	client, leaderElector, _, leaderElectionCheck, err := leaderelection.New(
 		c.ServerInstallationInfo,
 		deployment,
 		dref,          // first try to use the deployment as an owner ref (for namespace scoped resources)
@@ -118,7 +138,7 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 		kubeclient.WithMiddleware(groupsuffix.New(c.APIGroupSuffix)),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("could not create clients for the controllers: %w", err)
+		return nil, nil, fmt.Errorf("could not create clients for the controllers: %w", err)
 	}
 
 	// Create informers. Don't forget to make sure they get started in the function returned below.
@@ -135,6 +155,14 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 		DiscoveryURLOverride:      c.DiscoveryURLOverride,
 	}
 
+	// The signing keys of the JWTAuthenticators are shared by the controller which creates the authenticators
+	// and the controller which refreshes the keys.
+	jwksCache := jwtcachefiller.NewJWKSCache(clock.RealClock{})
+
+	// The circuit breakers of the WebhookAuthenticators are shared by the controller which creates the authenticators
+	// and the controller which reports the state of the circuit breakers.
+	circuitBreakers := webhookcachefiller.NewCircuitBreakers()
+
 	// Create controller manager.
 	controllerManager := controllerlib.
 		NewManager().
@@ -231,15 +259,64 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 		WithController(
 			webhookcachefiller.New(
 				c.AuthenticatorCache,
+				circuitBreakers,
 				informers.pinniped.Authentication().V1alpha1().WebhookAuthenticators(),
+				c.ServerInstallationInfo.Namespace,
+				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceK8s.Core().V1().ConfigMaps(),
+				clock.RealClock{},
 				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
 			),
 			singletonWorker,
 		).
+		WithController(
+			webhookcachefiller.NewCircuitBreakerStatus(
+				circuitBreakers,
+				client.PinnipedConcierge,
+				informers.pinniped.Authentication().V1alpha1().WebhookAuthenticators(),
+				plog.New(),
+			),
+			singletonWorker,
+		).
 		WithController(
 			jwtcachefiller.New(
 				c.AuthenticatorCache,
+				jwksCache,
+				client.PinnipedConcierge,
+				informers.pinniped.Authentication().V1alpha1().JWTAuthenticators(),
+				c.ServerInstallationInfo.Namespace,
+				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceK8s.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
+			),
+			singletonWorker,
+		).
+		WithController(
+			jwtcachefiller.NewJWKSRefresher(
+				jwksCache,
+				client.PinnipedConcierge,
 				informers.pinniped.Authentication().V1alpha1().JWTAuthenticators(),
+				plog.New(),
+			),
+			singletonWorker,
+		).
+		WithController(
+			x509cachefiller.New(
+				c.AuthenticatorCache,
+				informers.pinniped.Authentication().V1alpha1().X509ClientCertificateAuthenticators(),
+				c.ServerInstallationInfo.Namespace,
+				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceK8s.Core().V1().ConfigMaps(),
+				clock.RealClock{},
+				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
+			),
+			singletonWorker,
+		).
+		WithController(
+			satokencachefiller.New(
+				c.AuthenticatorCache,
+				informers.pinniped.Authentication().V1alpha1().ServiceAccountTokenAuthenticators(),
+				client.Kubernetes.AuthenticationV1().TokenReviews(),
 				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
 			),
 			singletonWorker,
@@ -249,6 +326,8 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 				c.AuthenticatorCache,
 				informers.pinniped.Authentication().V1alpha1().WebhookAuthenticators(),
 				informers.pinniped.Authentication().V1alpha1().JWTAuthenticators(),
+				informers.pinniped.Authentication().V1alpha1().X509ClientCertificateAuthenticators(),
+				informers.pinniped.Authentication().V1alpha1().ServiceAccountTokenAuthenticators(),
 				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
 			),
 			singletonWorker,
@@ -279,6 +358,60 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 			),
 			singletonWorker,
 		).
+
+		// The impersonator CA rotation controller periodically replaces the serving CA of the impersonation proxy.
+		WithController(
+			impersonatorconfig.NewCARotationController(
+				c.ServerInstallationInfo.Namespace,
+				c.NamesConfig.CredentialIssuer,
+				c.NamesConfig.ImpersonationCACertificateSecret,
+				client.Kubernetes,
+				informers.pinniped.Config().V1alpha1().CredentialIssuers(),
+				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+				clock.RealClock{},
+				plog.New(),
+			),
+			singletonWorker,
+		).
+
+		// The client cert config controller keeps the lifetime of the issued client certs in sync with the CredentialIssuer.
+		WithController(
+			clientcertconfig.NewClientCertConfigController(
+				c.NamesConfig.CredentialIssuer,
+				informers.pinniped.Config().V1alpha1().CredentialIssuers(),
+				c.ClientCertLifetime,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
+
+		// The credential policy config controller keeps the credential issuance policy in sync with the CredentialIssuer.
+		WithController(
+			credentialpolicyconfig.NewCredentialPolicyConfigController(
+				c.NamesConfig.CredentialIssuer,
+				informers.pinniped.Config().V1alpha1().CredentialIssuers(),
+				c.CredentialPolicy,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
+
+		// The CSR strategy controller configures the issuing of client certs using the certificates.k8s.io API.
+		WithController(
+			csrstrategy.NewCSRStrategyController(
+				c.NamesConfig.CredentialIssuer,
+				csrcertauthority.DefaultSignerName,
+				c.DiscoveryURLOverride,
+				client.PinnipedConcierge,
+				informers.pinniped.Config().V1alpha1().CredentialIssuers(),
+				informers.kubePublicNamespaceK8s.Core().V1().ConfigMaps(),
+				c.CSRCertAuthority,
+				clock.RealClock{},
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
 		WithController(
 			apicerts.NewCertsManagerController(
 				c.ServerInstallationInfo.Namespace,
@@ -308,12 +441,23 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 			singletonWorker,
 		)
 
+	// The aggregated API server already has an "informer-sync" check for its own informers.
+	readyzChecks := []healthz.HealthChecker{
+		healthcheck.InformerSync("controller-informer-sync",
+			informers.kubePublicNamespaceK8s,
+			informers.kubeSystemNamespaceK8s,
+			informers.installationNamespaceK8s,
+			informers.pinniped,
+		),
+		leaderElectionCheck,
+	}
+
 	return controllerinit.Prepare(controllerManager.Start, leaderElector,
 		informers.kubePublicNamespaceK8s,
 		informers.kubeSystemNamespaceK8s,
 		informers.installationNamespaceK8s,
 		informers.pinniped,
-	), nil
+	), readyzChecks, nil
 }
 
 type informers struct {
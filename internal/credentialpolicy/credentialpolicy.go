@@ -0,0 +1,296 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package credentialpolicy decides whether the TokenCredentialRequest API may issue a credential to a user who was
+// already authenticated, using a CEL expression, an authorization webhook, or both.
+// See https://github.com/google/cel-spec for the expression language.
+package credentialpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultWebhookTimeout is how long to wait for the webhook when Config does not specify a timeout.
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// SourceIPExtraKey is the key of the extra info of the SubjectAccessReview which holds the IP address of
+	// the client, when it is known.
+	SourceIPExtraKey = "credentialissuancepolicy.concierge.pinniped.dev/source-ip"
+
+	// AuthenticatorExtraKey is the key of the extra info of the SubjectAccessReview which holds the kind and name
+	// of the authenticator which authenticated the user, separated by a slash.
+	AuthenticatorExtraKey = "credentialissuancepolicy.concierge.pinniped.dev/authenticator"
+
+	// costLimit limits how much work an expression may do each time that it is evaluated, to avoid having
+	// an expression consume too many resources. It is the same limit which Kubernetes uses for its CEL expressions.
+	costLimit = 1000000
+
+	// maxWebhookResponseBytes limits how much of the response of the webhook is read.
+	maxWebhookResponseBytes = 1 << 20
+)
+
+// Request describes a credential which is about to be issued.
+type Request struct {
+	Username string
+	Groups   []string
+
+	// SourceIP is the IP address of the client, or empty when it is not known.
+	SourceIP string
+
+	AuthenticatorAPIGroup string
+	AuthenticatorKind     string
+	AuthenticatorName     string
+}
+
+// Decision is the result of evaluating a Policy.
+type Decision struct {
+	Allowed bool
+
+	// Reason explains why the credential was denied. It is empty when the credential is allowed.
+	Reason string
+}
+
+// Config configures a Policy. Any of the settings may be empty, in which case they do not deny anything.
+type Config struct {
+	// Expression is a CEL expression which must evaluate to true for a credential to be allowed.
+	Expression string
+
+	// WebhookURL is the URL to which a SubjectAccessReview is posted for each credential.
+	WebhookURL string
+
+	// WebhookClient is used to post to the webhook.
+	WebhookClient *http.Client
+
+	// WebhookTimeout is how long to wait for the webhook. When zero, DefaultWebhookTimeout is used.
+	WebhookTimeout time.Duration
+}
+
+// Policy decides whether credentials may be issued. A nil *Policy allows every credential.
+type Policy struct {
+	expression cel.Program
+
+	webhookURL     string
+	webhookClient  *http.Client
+	webhookTimeout time.Duration
+
+	denyReason string
+}
+
+// New compiles the expression of the config and returns a Policy which evaluates the expression first, and then
+// posts to the webhook. Both must allow a credential for it to be allowed.
+func New(config Config) (*Policy, error) {
+	p := &Policy{
+		webhookURL:     config.WebhookURL,
+		webhookClient:  config.WebhookClient,
+		webhookTimeout: config.WebhookTimeout,
+	}
+	if p.webhookTimeout == 0 {
+		p.webhookTimeout = DefaultWebhookTimeout
+	}
+	if p.webhookURL != "" && p.webhookClient == nil {
+		return nil, errors.New("webhook client must be set when the webhook URL is set")
+	}
+
+	if config.Expression != "" {
+		program, err := compile(config.Expression)
+		if err != nil {
+			return nil, err
+		}
+		p.expression = program
+	}
+
+	return p, nil
+}
+
+// DenyAll returns a Policy which denies every credential with the reason, e.g. when the configuration of the policy
+// is invalid, so that an invalid configuration never allows credentials which a valid one would have denied.
+func DenyAll(reason string) *Policy {
+	return &Policy{denyReason: reason}
+}
+
+// Evaluate decides whether the credential may be issued. Errors, such as a webhook which cannot be reached,
+// deny the credential.
+func (p *Policy) Evaluate(ctx context.Context, req Request) Decision {
+	if p == nil {
+		return Decision{Allowed: true}
+	}
+
+	if p.denyReason != "" {
+		return Decision{Reason: p.denyReason}
+	}
+
+	if p.expression != nil {
+		allowed, err := p.evalExpression(req)
+		if err != nil {
+			return Decision{Reason: err.Error()}
+		}
+		if !allowed {
+			return Decision{Reason: "denied by expression"}
+		}
+	}
+
+	if p.webhookURL != "" {
+		decision, err := p.postToWebhook(ctx, req)
+		if err != nil {
+			return Decision{Reason: fmt.Sprintf("could not call webhook: %s", err.Error())}
+		}
+		return decision
+	}
+
+	return Decision{Allowed: true}
+}
+
+func compile(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("username", cel.StringType),
+		cel.Variable("groups", cel.ListType(cel.StringType)),
+		cel.Variable("sourceIP", cel.StringType),
+		cel.Variable("authenticator", cel.MapType(cel.StringType, cel.StringType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("could not compile expression: %w", issues.Err())
+	}
+	if !cel.BoolType.IsAssignableType(ast.OutputType()) {
+		return nil, fmt.Errorf("expression must evaluate to a bool, but evaluates to type %s", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("could not create program for expression: %w", err)
+	}
+	return program, nil
+}
+
+func (p *Policy) evalExpression(req Request) (bool, error) {
+	groups := req.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	result, _, err := p.expression.Eval(map[string]interface{}{
+		"username": req.Username,
+		"groups":   groups,
+		"sourceIP": req.SourceIP,
+		"authenticator": map[string]string{
+			"apiGroup": req.AuthenticatorAPIGroup,
+			"kind":     req.AuthenticatorKind,
+			"name":     req.AuthenticatorName,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not evaluate expression: %w", err)
+	}
+	allowed, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to a bool, but evaluated to type %s", result.Type().TypeName())
+	}
+	return allowed, nil
+}
+
+func (p *Policy) postToWebhook(ctx context.Context, req Request) (Decision, error) {
+	extra := map[string]authorizationv1.ExtraValue{
+		AuthenticatorExtraKey: {req.AuthenticatorKind + "/" + req.AuthenticatorName},
+	}
+	if req.SourceIP != "" {
+		extra[SourceIPExtraKey] = authorizationv1.ExtraValue{req.SourceIP}
+	}
+	review := authorizationv1.SubjectAccessReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: authorizationv1.SchemeGroupVersion.String(), Kind: "SubjectAccessReview"},
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     "create",
+				Group:    "login.concierge.pinniped.dev",
+				Version:  "v1alpha1",
+				Resource: "tokencredentialrequests",
+			},
+			User:   req.Username,
+			Groups: req.Groups,
+			Extra:  extra,
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.webhookTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := p.webhookClient.Do(httpReq)
+	if err != nil {
+		return Decision{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Decision{}, fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+
+	var result authorizationv1.SubjectAccessReview
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxWebhookResponseBytes)).Decode(&result); err != nil {
+		return Decision{}, fmt.Errorf("could not decode response: %w", err)
+	}
+
+	if !result.Status.Allowed || result.Status.Denied {
+		reason := result.Status.Reason
+		if reason == "" {
+			reason = "denied by webhook"
+		}
+		return Decision{Reason: reason}, nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// DynamicPolicy holds the current Policy, which may be changed at any time by the controller which watches the
+// CredentialIssuer. It is safe for concurrent use.
+type DynamicPolicy struct {
+	mu     sync.RWMutex
+	policy *Policy
+}
+
+// NewDynamicPolicy returns a DynamicPolicy which allows every credential until a Policy is set.
+func NewDynamicPolicy() *DynamicPolicy {
+	return &DynamicPolicy{}
+}
+
+// Get returns the current Policy, which is nil when every credential is allowed. A nil DynamicPolicy returns nil.
+func (d *DynamicPolicy) Get() *Policy {
+	if d == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.policy
+}
+
+// Set replaces the current Policy. A nil Policy allows every credential.
+func (d *DynamicPolicy) Set(policy *Policy) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.policy = policy
+}
@@ -0,0 +1,284 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "valid expression",
+			config: Config{Expression: `username != "root"`},
+		},
+		{
+			name:    "invalid expression",
+			config:  Config{Expression: `username ==`},
+			wantErr: "could not compile expression: ERROR: <input>:1:12: Syntax error: mismatched input '<EOF>'",
+		},
+		{
+			name:    "expression which does not evaluate to a bool",
+			config:  Config{Expression: `username`},
+			wantErr: "expression must evaluate to a bool, but evaluates to type string",
+		},
+		{
+			name:    "webhook without client",
+			config:  Config{WebhookURL: "https://example.com"},
+			wantErr: "webhook client must be set when the webhook URL is set",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := New(tt.config)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, policy)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, policy)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	request := Request{
+		Username:              "some-user",
+		Groups:                []string{"some-group", "other-group"},
+		SourceIP:              "10.1.2.3",
+		AuthenticatorAPIGroup: "authentication.concierge.pinniped.dev",
+		AuthenticatorKind:     "JWTAuthenticator",
+		AuthenticatorName:     "some-authenticator",
+	}
+
+	tests := []struct {
+		name           string
+		config         *Config // the webhook URL and client are set when there is a webhookHandler
+		denyAllReason  string
+		webhookHandler http.HandlerFunc
+		want           Decision
+		wantReasonSub  string
+	}{
+		{
+			name: "nil policy",
+			want: Decision{Allowed: true},
+		},
+		{
+			name:          "deny all",
+			denyAllReason: "some reason",
+			want:          Decision{Reason: "some reason"},
+		},
+		{
+			name: "expression allows",
+			config: &Config{
+				Expression: `username == "some-user" && "some-group" in groups && sourceIP.startsWith("10.") && ` +
+					`authenticator.kind == "JWTAuthenticator" && authenticator.name == "some-authenticator"`,
+			},
+			want: Decision{Allowed: true},
+		},
+		{
+			name:   "expression denies",
+			config: &Config{Expression: `!("other-group" in groups)`},
+			want:   Decision{Reason: "denied by expression"},
+		},
+		{
+			name:   "webhook allows",
+			config: &Config{},
+			webhookHandler: func(w http.ResponseWriter, r *http.Request) {
+				var review authorizationv1.SubjectAccessReview
+				if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				allowed := review.Spec.User == "some-user" &&
+					review.Spec.ResourceAttributes.Resource == "tokencredentialrequests" &&
+					review.Spec.Extra[SourceIPExtraKey][0] == "10.1.2.3" &&
+					review.Spec.Extra[AuthenticatorExtraKey][0] == "JWTAuthenticator/some-authenticator"
+				_ = json.NewEncoder(w).Encode(authorizationv1.SubjectAccessReview{
+					Status: authorizationv1.SubjectAccessReviewStatus{Allowed: allowed},
+				})
+			},
+			want: Decision{Allowed: true},
+		},
+		{
+			name:   "webhook denies",
+			config: &Config{},
+			webhookHandler: func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(authorizationv1.SubjectAccessReview{
+					Status: authorizationv1.SubjectAccessReviewStatus{Denied: true, Reason: "not during business hours"},
+				})
+			},
+			want: Decision{Reason: "not during business hours"},
+		},
+		{
+			name:   "webhook has no opinion",
+			config: &Config{},
+			webhookHandler: func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(authorizationv1.SubjectAccessReview{})
+			},
+			want: Decision{Reason: "denied by webhook"},
+		},
+		{
+			name:   "webhook fails",
+			config: &Config{},
+			webhookHandler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "oops", http.StatusInternalServerError)
+			},
+			want: Decision{Reason: `could not call webhook: unexpected response status "500 Internal Server Error"`},
+		},
+		{
+			name:   "webhook times out",
+			config: &Config{WebhookTimeout: time.Millisecond},
+			webhookHandler: func(w http.ResponseWriter, r *http.Request) {
+				// The context of the request is only canceled when the client goes away after the body was read.
+				_, _ = io.Copy(io.Discard, r.Body)
+				<-r.Context().Done()
+			},
+			wantReasonSub: "context deadline exceeded",
+		},
+		{
+			name:   "expression denies before the webhook is called",
+			config: &Config{Expression: `false`},
+			webhookHandler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "should not have been called", http.StatusInternalServerError)
+			},
+			want: Decision{Reason: "denied by expression"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var policy *Policy
+			switch {
+			case tt.denyAllReason != "":
+				policy = DenyAll(tt.denyAllReason)
+			case tt.config != nil:
+				config := *tt.config
+				if tt.webhookHandler != nil {
+					server := httptest.NewServer(tt.webhookHandler)
+					t.Cleanup(server.Close)
+					config.WebhookURL = server.URL
+					config.WebhookClient = server.Client()
+				}
+				var err error
+				policy, err = New(config)
+				require.NoError(t, err)
+			}
+
+			got := policy.Evaluate(context.Background(), request)
+			if tt.wantReasonSub != "" {
+				require.False(t, got.Allowed)
+				require.Contains(t, got.Reason, tt.wantReasonSub)
+				return
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDynamicPolicy(t *testing.T) {
+	t.Parallel()
+
+	var nilDynamic *DynamicPolicy
+	require.Nil(t, nilDynamic.Get())
+
+	d := NewDynamicPolicy()
+	require.Nil(t, d.Get())
+
+	policy := DenyAll("some reason")
+	d.Set(policy)
+	require.Same(t, policy, d.Get())
+
+	d.Set(nil)
+	require.Nil(t, d.Get())
+}
+
+func TestWithSourceIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		wantSourceIP string
+	}{
+		{
+			name:         "peer address",
+			remoteAddr:   "10.1.2.3:12345",
+			wantSourceIP: "10.1.2.3",
+		},
+		{
+			name:         "IPv6 peer address",
+			remoteAddr:   "[2001:db8::1]:12345",
+			wantSourceIP: "2001:db8::1",
+		},
+		{
+			name:         "forwarded by the Kubernetes API server",
+			remoteAddr:   "10.1.2.3:12345",
+			forwardedFor: []string{"192.0.2.1, 198.51.100.2"},
+			wantSourceIP: "198.51.100.2",
+		},
+		{
+			name:         "multiple headers",
+			remoteAddr:   "10.1.2.3:12345",
+			forwardedFor: []string{"192.0.2.1", "198.51.100.2"},
+			wantSourceIP: "198.51.100.2",
+		},
+		{
+			name:         "invalid forwarded address",
+			remoteAddr:   "10.1.2.3:12345",
+			forwardedFor: []string{"not-an-ip"},
+			wantSourceIP: "10.1.2.3",
+		},
+		{
+			name:       "unknown",
+			remoteAddr: "pipe",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotSourceIP string
+			handler := WithSourceIP(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotSourceIP = SourceIPFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			require.Equal(t, tt.wantSourceIP, gotSourceIP)
+		})
+	}
+}
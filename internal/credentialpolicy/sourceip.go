@@ -0,0 +1,53 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialpolicy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type sourceIPContextKey struct{}
+
+// WithSourceIP wraps a handler so that the IP address of the client of each request can be retrieved from the
+// context of the request by SourceIPFromContext.
+//
+// The requests of the aggregated API are proxied by the Kubernetes API server, which appends the address of its own
+// client to the X-Forwarded-For header, so the last address of that header is used. Any earlier addresses were sent
+// by the client itself, so they cannot be trusted. When the header is not present, the address of the peer is used.
+func WithSourceIP(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := sourceIP(r); ip != "" {
+			r = r.WithContext(context.WithValue(r.Context(), sourceIPContextKey{}, ip))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// SourceIPFromContext returns the IP address of the client which was stored by WithSourceIP, or an empty string
+// when it is not known.
+func SourceIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(sourceIPContextKey{}).(string)
+	return ip
+}
+
+func sourceIP(r *http.Request) string {
+	if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		addresses := strings.Split(values[len(values)-1], ",")
+		if ip := net.ParseIP(strings.TrimSpace(addresses[len(addresses)-1])); ip != nil {
+			return ip.String()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
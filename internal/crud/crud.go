@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package crud
@@ -13,12 +13,15 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/tracing"
 )
 
 //nolint:gosec // ignore lint warnings that these are credentials
@@ -50,6 +53,14 @@ type Storage interface {
 
 type JSON interface{} // document that we need valid JSON types
 
+// ExpiringJSON may be implemented by data which should be garbage collected before the lifetime of its storage
+// has passed, e.g. because the session to which it belongs ends earlier.
+type ExpiringJSON interface {
+	JSON
+	// ExpiresAt returns when the data expires. Zero means that it expires after the lifetime of its storage.
+	ExpiresAt() time.Time
+}
+
 func New(resource string, secrets corev1client.SecretInterface, clock func() time.Time, lifetime time.Duration) Storage {
 	return &secretsStorage{
 		resource:   resource,
@@ -68,7 +79,10 @@ type secretsStorage struct {
 	lifetime   time.Duration
 }
 
-func (s *secretsStorage) Create(ctx context.Context, signature string, data JSON, additionalLabels map[string]string, ownerReferences []metav1.OwnerReference) (string, error) {
+func (s *secretsStorage) Create(ctx context.Context, signature string, data JSON, additionalLabels map[string]string, ownerReferences []metav1.OwnerReference) (_ string, err error) {
+	ctx, span := s.startSpan(ctx, "storage create")
+	defer func() { tracing.End(span, err) }()
+
 	secret, err := s.toSecret(signature, "", data, additionalLabels, ownerReferences)
 	if err != nil {
 		return "", err
@@ -95,7 +109,10 @@ func (s *secretsStorage) Get(ctx context.Context, signature string, data JSON) (
 
 // Update takes a resourceVersion because it assumes Get has been recently called to obtain the latest resource version.
 // This is to ensure that concurrent edits are treated as conflict errors (only one will win).
-func (s *secretsStorage) Update(ctx context.Context, signature, resourceVersion string, data JSON) (string, error) {
+func (s *secretsStorage) Update(ctx context.Context, signature, resourceVersion string, data JSON) (_ string, err error) {
+	ctx, span := s.startSpan(ctx, "storage update")
+	defer func() { tracing.End(span, err) }()
+
 	secret, err := s.toSecret(signature, resourceVersion, data, nil, nil)
 	if err != nil {
 		return "", err
@@ -118,14 +135,20 @@ func (s *secretsStorage) Update(ctx context.Context, signature, resourceVersion
 	return secret.ResourceVersion, nil
 }
 
-func (s *secretsStorage) Delete(ctx context.Context, signature string) error {
+func (s *secretsStorage) Delete(ctx context.Context, signature string) (err error) {
+	ctx, span := s.startSpan(ctx, "storage delete")
+	defer func() { tracing.End(span, err) }()
+
 	if err := s.secrets.Delete(ctx, s.GetName(signature), metav1.DeleteOptions{}); err != nil {
 		return fmt.Errorf("failed to delete %s for signature %s: %w", s.resource, signature, err)
 	}
 	return nil
 }
 
-func (s *secretsStorage) DeleteByLabel(ctx context.Context, labelName string, labelValue string) error {
+func (s *secretsStorage) DeleteByLabel(ctx context.Context, labelName string, labelValue string) (err error) {
+	ctx, span := s.startSpan(ctx, "storage delete by label")
+	defer func() { tracing.End(span, err) }()
+
 	list, err := s.secrets.List(ctx, metav1.ListOptions{
 		LabelSelector: labels.Set{
 			SecretLabelKey: s.resource,
@@ -181,6 +204,11 @@ func validateSecret(resource string, secret *corev1.Secret) error {
 //nolint:gochecknoglobals
 var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
 
+// startSpan traces a write to the storage, so that slow writes to the Kubernetes API show up in the traces of logins.
+func (s *secretsStorage) startSpan(ctx context.Context, spanName string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, spanName, attribute.String("pinniped.storage.type", s.resource))
+}
+
 func (s *secretsStorage) GetName(signature string) string {
 	// try to decode base64 signatures to prevent double encoding of binary data
 	signatureBytes := maybeBase64Decode(signature)
@@ -203,8 +231,14 @@ func (s *secretsStorage) toSecret(signature, resourceVersion string, data JSON,
 
 	var annotations map[string]string
 	if s.lifetime > 0 {
+		garbageCollectAfter := s.clock().Add(s.lifetime)
+		if expiringData, ok := data.(ExpiringJSON); ok {
+			if expiresAt := expiringData.ExpiresAt(); !expiresAt.IsZero() && expiresAt.Before(garbageCollectAfter) {
+				garbageCollectAfter = expiresAt
+			}
+		}
 		annotations = map[string]string{
-			SecretLifetimeAnnotationKey: s.clock().Add(s.lifetime).UTC().Format(SecretLifetimeAnnotationDateFormat),
+			SecretLifetimeAnnotationKey: garbageCollectAfter.UTC().Format(SecretLifetimeAnnotationDateFormat),
 		}
 	}
 
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 //go:build !fips_strict
@@ -11,7 +11,18 @@ import (
 	"crypto/x509"
 )
 
+// The TLS policy can be configured unless built in FIPS-only mode.
+const policySupported = true
+
+// Default returns the config of the connections which are not made to or from the Kubernetes API server, to which
+// the TLS policy of this process applies.
 func Default(rootCAs *x509.CertPool) *tls.Config {
+	c := defaultWithoutPolicy(rootCAs)
+	currentPolicy.Load().apply(c)
+	return c
+}
+
+func defaultWithoutPolicy(rootCAs *x509.CertPool) *tls.Config {
 	return &tls.Config{
 		// Can't use SSLv3 because of POODLE and BEAST
 		// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
@@ -60,6 +71,9 @@ func Default(rootCAs *x509.CertPool) *tls.Config {
 
 func DefaultLDAP(rootCAs *x509.CertPool) *tls.Config {
 	c := Default(rootCAs)
+	if currentPolicy.Load().hasCipherSuites() {
+		return c // the configured cipher suites replace all of the defaults
+	}
 	// add less secure ciphers to support the default AWS Active Directory config
 	c.CipherSuites = append(c.CipherSuites,
 		// CBC with ECDHE
@@ -0,0 +1,118 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ptls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync/atomic"
+)
+
+// Policy overrides the minimum TLS version, the cipher suites, and the curve preferences of the Default and
+// DefaultLDAP configs, i.e. of the connections which are not made to or from the Kubernetes API server.
+type Policy struct {
+	minVersion       uint16
+	cipherSuites     []uint16
+	curvePreferences []tls.CurveID
+}
+
+//nolint:gochecknoglobals
+var currentPolicy atomic.Pointer[Policy]
+
+//nolint:gochecknoglobals
+var policyVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+//nolint:gochecknoglobals
+var policyCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
+
+// NewPolicy returns the Policy described by a minimum TLS version ("1.2" or "1.3"), the names of TLS 1.2 cipher suites
+// as returned by tls.CipherSuiteName, and the names of curves ("X25519", "P256", "P384", or "P521"). Empty values keep
+// the defaults. Only the secure cipher suites of crypto/tls can be used, and TLS 1.3 cipher suites cannot be
+// configured at all.
+func NewPolicy(minVersion string, cipherSuites []string, curvePreferences []string) (*Policy, error) {
+	p := &Policy{}
+	if minVersion == "" && len(cipherSuites) == 0 && len(curvePreferences) == 0 {
+		return p, nil
+	}
+	if !policySupported {
+		return nil, fmt.Errorf("the TLS policy cannot be configured when built in FIPS-only mode")
+	}
+
+	if minVersion != "" {
+		version, ok := policyVersions[minVersion]
+		if !ok {
+			return nil, fmt.Errorf("minVersion %q is invalid: must be 1.2 or 1.3", minVersion)
+		}
+		p.minVersion = version
+	}
+
+	if len(cipherSuites) > 0 && p.minVersion == tls.VersionTLS13 {
+		return nil, fmt.Errorf("cipherSuites cannot be configured when minVersion is 1.3")
+	}
+	for _, name := range cipherSuites {
+		id, err := tls12CipherSuite(name)
+		if err != nil {
+			return nil, err
+		}
+		p.cipherSuites = append(p.cipherSuites, id)
+	}
+
+	for _, name := range curvePreferences {
+		curve, ok := policyCurves[name]
+		if !ok {
+			return nil, fmt.Errorf("curve %q is invalid: must be one of X25519, P256, P384, or P521", name)
+		}
+		p.curvePreferences = append(p.curvePreferences, curve)
+	}
+
+	return p, nil
+}
+
+func tls12CipherSuite(name string) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name != name {
+			continue
+		}
+		for _, version := range suite.SupportedVersions {
+			if version == tls.VersionTLS12 {
+				return suite.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("cipher suite %q is a TLS 1.3 cipher suite, which cannot be configured", name)
+	}
+	return 0, fmt.Errorf("cipher suite %q is unknown or insecure", name)
+}
+
+// SetPolicy replaces the Policy of this process. The configs which are returned by Default and DefaultLDAP afterwards
+// use the new Policy. A nil Policy restores the defaults.
+func SetPolicy(p *Policy) {
+	currentPolicy.Store(p)
+}
+
+func (p *Policy) apply(c *tls.Config) {
+	if p == nil {
+		return
+	}
+	if p.minVersion != 0 {
+		c.MinVersion = p.minVersion
+	}
+	if len(p.cipherSuites) > 0 {
+		c.CipherSuites = append([]uint16(nil), p.cipherSuites...)
+	}
+	if len(p.curvePreferences) > 0 {
+		c.CurvePreferences = append([]tls.CurveID(nil), p.curvePreferences...)
+	}
+}
+
+func (p *Policy) hasCipherSuites() bool {
+	return p != nil && len(p.cipherSuites) > 0
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !fips_strict
+// +build !fips_strict
+
+package ptls
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/server/options"
+)
+
+func TestNewPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		minVersion       string
+		cipherSuites     []string
+		curvePreferences []string
+		want             *Policy
+		wantErr          string
+	}{
+		{
+			name: "empty",
+			want: &Policy{},
+		},
+		{
+			name:             "everything",
+			minVersion:       "1.2",
+			cipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+			curvePreferences: []string{"X25519", "P384"},
+			want: &Policy{
+				minVersion:       tls.VersionTLS12,
+				cipherSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+				curvePreferences: []tls.CurveID{tls.X25519, tls.CurveP384},
+			},
+		},
+		{
+			name:       "tls 1.3",
+			minVersion: "1.3",
+			want:       &Policy{minVersion: tls.VersionTLS13},
+		},
+		{
+			name:       "invalid version",
+			minVersion: "1.1",
+			wantErr:    `minVersion "1.1" is invalid: must be 1.2 or 1.3`,
+		},
+		{
+			name:         "cipher suites with tls 1.3",
+			minVersion:   "1.3",
+			cipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+			wantErr:      "cipherSuites cannot be configured when minVersion is 1.3",
+		},
+		{
+			name:         "tls 1.3 cipher suite",
+			cipherSuites: []string{"TLS_AES_128_GCM_SHA256"},
+			wantErr:      `cipher suite "TLS_AES_128_GCM_SHA256" is a TLS 1.3 cipher suite, which cannot be configured`,
+		},
+		{
+			name:         "insecure cipher suite",
+			cipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			wantErr:      `cipher suite "TLS_RSA_WITH_RC4_128_SHA" is unknown or insecure`,
+		},
+		{
+			name:             "invalid curve",
+			curvePreferences: []string{"P224"},
+			wantErr:          `curve "P224" is invalid: must be one of X25519, P256, P384, or P521`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewPolicy(tt.minVersion, tt.cipherSuites, tt.curvePreferences)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyToServingOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		minVersion        string
+		cipherSuites      []string
+		wantMinTLSVersion string
+		wantCipherSuites  []string
+	}{
+		{
+			name:              "nil policy",
+			wantMinTLSVersion: "VersionTLS12",
+			wantCipherSuites: []string{
+				"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+				"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+				"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+				"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+				"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+				"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+			},
+		},
+		{
+			name:              "cipher suites",
+			cipherSuites:      []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			wantMinTLSVersion: "VersionTLS12",
+			wantCipherSuites:  []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+		},
+		{
+			name:              "tls 1.3",
+			minVersion:        "1.3",
+			wantMinTLSVersion: "VersionTLS13",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var policy *Policy
+			if tt.minVersion != "" || len(tt.cipherSuites) > 0 {
+				var err error
+				policy, err = NewPolicy(tt.minVersion, tt.cipherSuites, nil)
+				require.NoError(t, err)
+			}
+
+			opts := &options.SecureServingOptionsWithLoopback{SecureServingOptions: &options.SecureServingOptions{}}
+			defaultServing(opts)
+			policy.ApplyToServingOptions(opts)
+			require.Equal(t, tt.wantMinTLSVersion, opts.MinTLSVersion)
+			require.Equal(t, tt.wantCipherSuites, opts.CipherSuites)
+		})
+	}
+}
+
+// Not parallel, because it changes the policy of the whole process. The other tests of this package are parallel, so
+// they run after it has restored the defaults.
+func TestSetPolicy(t *testing.T) {
+	t.Cleanup(func() { SetPolicy(nil) })
+
+	policy, err := NewPolicy("", []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}, []string{"P256"})
+	require.NoError(t, err)
+	SetPolicy(policy)
+
+	for _, c := range []*tls.Config{Default(nil), DefaultLDAP(nil)} {
+		require.Equal(t, uint16(tls.VersionTLS12), c.MinVersion)
+		require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, c.CipherSuites)
+		require.Equal(t, []tls.CurveID{tls.CurveP256}, c.CurvePreferences)
+	}
+
+	// The Kubernetes API server connections keep their own config.
+	secure := Secure(nil)
+	require.Equal(t, uint16(tls.VersionTLS13), secure.MinVersion)
+	require.Nil(t, secure.CurvePreferences)
+
+	policy, err = NewPolicy("1.3", nil, nil)
+	require.NoError(t, err)
+	SetPolicy(policy)
+	require.Equal(t, uint16(tls.VersionTLS13), Default(nil).MinVersion)
+	require.Len(t, DefaultLDAP(nil).CipherSuites, 10)
+
+	SetPolicy(nil)
+	require.Equal(t, defaultWithoutPolicy(nil), Default(nil))
+}
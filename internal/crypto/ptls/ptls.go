@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package ptls
@@ -82,6 +82,26 @@ func defaultServing(opts *options.SecureServingOptionsWithLoopback) {
 	opts.MinTLSVersion = defaultServingOptionsMinTLSVersion
 }
 
+// ApplyToServingOptions overrides the minimum TLS version and the cipher suites of serving options which were
+// configured by DefaultRecommendedOptions. The curve preferences of the Policy are ignored, since they cannot be
+// configured by serving options.
+func (p *Policy) ApplyToServingOptions(opts *options.SecureServingOptionsWithLoopback) {
+	if p == nil {
+		return
+	}
+	if p.minVersion == tls.VersionTLS13 {
+		secureServing(opts)
+		return
+	}
+	if len(p.cipherSuites) > 0 {
+		cipherSuites := make([]string, 0, len(p.cipherSuites))
+		for _, id := range p.cipherSuites {
+			cipherSuites = append(cipherSuites, tls.CipherSuiteName(id))
+		}
+		opts.CipherSuites = cipherSuites
+	}
+}
+
 func secureClient(opts *options.RecommendedOptions, f RestConfigFunc) error {
 	inClusterClient, inClusterConfig, err := f(nil)
 	if err != nil {
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 //go:build !fips_strict
@@ -33,7 +33,7 @@ func Secure(rootCAs *x509.CertPool) *tls.Config {
 	// - Opera 57
 	// - Safari 12.1
 	// https://ssl-config.mozilla.org/#server=go&version=1.17.2&config=modern&guideline=5.6
-	c := Default(rootCAs)
+	c := defaultWithoutPolicy(rootCAs)          // the TLS policy does not apply to the Kubernetes API server
 	c.MinVersion = SecureTLSConfigMinTLSVersion // max out the security
 	c.CipherSuites = []uint16{
 		// TLS 1.3 ciphers are not configurable, but we need to explicitly set them here to make our client hello behave correctly
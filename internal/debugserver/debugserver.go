@@ -0,0 +1,62 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package debugserver serves the pprof and expvar endpoints on a listener which only accepts connections from within
+// the pod, so heap and CPU profiles can be captured through kubectl port-forward during incidents.
+package debugserver
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"time"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// Start serves the debug endpoints on the loopback interface of the pod until the context is canceled.
+func Start(ctx context.Context, port int64) error {
+	_, err := start(ctx, net.JoinHostPort("127.0.0.1", strconv.FormatInt(port, 10)))
+	return err
+}
+
+func start(ctx context.Context, address string) (net.Addr, error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for debug requests: %w", err)
+	}
+
+	// There is no write timeout, since CPU profiles and traces take as long as the client asks for.
+	server := &http.Server{
+		Handler:           newHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		err := server.Serve(l)
+		plog.Debug("debug server exited", "err", err)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	plog.Info("serving debug endpoints", "address", l.Addr().String())
+	return l.Addr(), nil
+}
+
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index) // also serves the heap, goroutine, and other named profiles
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package debugserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantContains string
+	}{
+		{
+			name:         "pprof index",
+			path:         "/debug/pprof/",
+			wantStatus:   http.StatusOK,
+			wantContains: "heap",
+		},
+		{
+			name:         "heap profile",
+			path:         "/debug/pprof/heap?debug=1",
+			wantStatus:   http.StatusOK,
+			wantContains: "heap profile",
+		},
+		{
+			name:         "expvar",
+			path:         "/debug/vars",
+			wantStatus:   http.StatusOK,
+			wantContains: `"memstats"`,
+		},
+		{
+			name:       "other path",
+			path:       "/healthz",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			newHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantContains)
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := start(ctx, "127.0.0.1:0")
+	require.NoError(t, err)
+
+	status, body, err := get(ctx, "http://"+addr.String()+"/debug/vars")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `"cmdline"`)
+
+	_, err = start(ctx, addr.String())
+	require.ErrorContains(t, err, "cannot listen for debug requests: ")
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, _, err := get(context.Background(), "http://"+addr.String()+"/debug/vars")
+		return err != nil
+	}, time.Minute, 10*time.Millisecond)
+}
+
+func get(ctx context.Context, url string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package execcredcache
@@ -100,18 +100,7 @@ func (c *credCache) normalized() *credCache {
 	result.Entries = make([]entry, 0, len(c.Entries))
 
 	for _, e := range c.Entries {
-		// Eliminate any cache entries that are missing a credential or an expiration timestamp.
-		if e.Credential == nil || e.Credential.ExpirationTimestamp == nil {
-			continue
-		}
-
-		// Eliminate any expired credentials.
-		if e.Credential.ExpirationTimestamp.Time.Before(time.Now()) {
-			continue
-		}
-
-		// Eliminate any entries older than maxCacheDuration.
-		if e.CreationTimestamp.Time.Before(now.Add(-maxCacheDuration)) {
+		if !e.valid(now) {
 			continue
 		}
 		result.Entries = append(result.Entries, e)
@@ -124,3 +113,19 @@ func (c *credCache) normalized() *credCache {
 
 	return result
 }
+
+// valid returns whether the entry can still be used at the given time.
+func (e *entry) valid(now time.Time) bool {
+	// Eliminate any cache entries that are missing a credential or an expiration timestamp.
+	if e.Credential == nil || e.Credential.ExpirationTimestamp == nil {
+		return false
+	}
+
+	// Eliminate any expired credentials.
+	if e.Credential.ExpirationTimestamp.Time.Before(now) {
+		return false
+	}
+
+	// Eliminate any entries older than maxCacheDuration.
+	return !e.CreationTimestamp.Time.Before(now.Add(-maxCacheDuration))
+}
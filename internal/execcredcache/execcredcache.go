@@ -1,4 +1,4 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package execcredcache implements a cache for Kubernetes ExecCredential data.
@@ -111,6 +111,17 @@ func (c *Cache) Put(key interface{}, cred *clientauthenticationv1beta1.ExecCrede
 	})
 }
 
+// Clear deletes all the cached credentials.
+func (c *Cache) Clear() {
+	// If the cache file does not exist, exit immediately with no error log
+	if _, err := os.Stat(c.path); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	c.withCache(func(cache *credCache) {
+		cache.Entries = nil
+	})
+}
+
 func jsonSHA256Hex(key interface{}) string {
 	hash := sha256.New()
 	if err := json.NewEncoder(hash).Encode(key); err != nil {
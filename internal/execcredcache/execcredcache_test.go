@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package execcredcache
@@ -359,6 +359,36 @@ func TestHashing(t *testing.T) {
 	require.Panics(t, func() { jsonSHA256Hex(&unmarshalable{}) })
 }
 
+func TestClear(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/cachedir/credentials.yaml"
+	errors := errorCollector{t: t}
+	c := New(tmp)
+	c.errReporter = errors.report
+
+	// Clearing a missing cache file does not create it.
+	c.Clear()
+	require.NoFileExists(t, tmp)
+
+	cred := &clientauthenticationv1beta1.ExecCredential{
+		Status: &clientauthenticationv1beta1.ExecCredentialStatus{
+			Token:               "test-token",
+			ExpirationTimestamp: timePtr(time.Now().Add(1 * time.Hour)),
+		},
+	}
+	c.Put("key-1", cred)
+	c.Put("key-2", cred)
+	require.NotNil(t, c.Get("key-1"))
+
+	c.Clear()
+	errors.require(nil)
+	require.Nil(t, c.Get("key-1"))
+	require.Nil(t, c.Get("key-2"))
+	cache, err := readCache(tmp)
+	require.NoError(t, err)
+	require.Empty(t, cache.Entries)
+}
+
 type errorCollector struct {
 	t   *testing.T
 	saw []error
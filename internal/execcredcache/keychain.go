@@ -0,0 +1,103 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package execcredcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/keychain"
+)
+
+// keychainService is the keychain service under which the credentials are stored. Each credential is stored under an
+// account which is derived from its cache key.
+const keychainService = "pinniped-credential"
+
+// KeychainCache is a credential cache which stores the credentials in the keychain of the operating system. Whenever
+// the keychain cannot be used, the fallback Cache is used instead.
+type KeychainCache struct {
+	keychain    *keychain.Indexed
+	fallback    *Cache
+	errReporter func(error)
+}
+
+func NewKeychain(kc keychain.Keychain, fallback *Cache) *KeychainCache {
+	return &KeychainCache{
+		keychain:    keychain.NewIndexed(kc),
+		fallback:    fallback,
+		errReporter: func(_ error) {},
+	}
+}
+
+func (c *KeychainCache) Get(key interface{}) *clientauthenticationv1beta1.ExecCredential {
+	account := jsonSHA256Hex(key)
+	secret, err := c.keychain.Get(keychainService, account)
+	if err != nil {
+		if !errors.Is(err, keychain.ErrNotFound) {
+			c.errReporter(fmt.Errorf("could not read credential from keychain, using fallback cache: %w", err))
+		}
+		// The credential may have been stored in the fallback cache when the keychain could not be used.
+		return c.fallback.Get(key)
+	}
+
+	var e entry
+	if err := json.Unmarshal([]byte(secret), &e); err != nil {
+		c.errReporter(fmt.Errorf("could not decode credential from keychain: %w", err))
+		return nil
+	}
+
+	// The keychain is not pruned like the cache file, so remove the stale entry when we come across it.
+	if !e.valid(time.Now()) {
+		if err := c.keychain.Delete(keychainService, account); err != nil && !errors.Is(err, keychain.ErrNotFound) {
+			c.errReporter(fmt.Errorf("could not delete expired credential from keychain: %w", err))
+		}
+		return nil
+	}
+
+	return &clientauthenticationv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ExecCredential",
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		},
+		Status: e.Credential,
+	}
+}
+
+func (c *KeychainCache) Put(key interface{}, cred *clientauthenticationv1beta1.ExecCredential) {
+	account := jsonSHA256Hex(key)
+	now := metav1.Now()
+	secret, err := json.Marshal(entry{
+		Key:               account,
+		CreationTimestamp: now,
+		LastUsedTimestamp: now,
+		Credential:        cred.Status,
+	})
+	if err != nil {
+		c.errReporter(fmt.Errorf("could not encode credential: %w", err))
+		return
+	}
+	if err := c.keychain.Set(keychainService, account, string(secret)); err != nil {
+		c.errReporter(fmt.Errorf("could not write credential to keychain, using fallback cache: %w", err))
+		c.fallback.Put(key, cred)
+	}
+}
+
+// Clear deletes all the credentials from the keychain. It does not delete credentials from the fallback Cache.
+func (c *KeychainCache) Clear() {
+	accounts, err := c.keychain.Accounts(keychainService)
+	if err != nil {
+		c.errReporter(fmt.Errorf("could not list credentials in keychain: %w", err))
+		return
+	}
+	for _, account := range accounts {
+		if err := c.keychain.Delete(keychainService, account); err != nil && !errors.Is(err, keychain.ErrNotFound) {
+			c.errReporter(fmt.Errorf("could not delete credential from keychain: %w", err))
+		}
+	}
+}
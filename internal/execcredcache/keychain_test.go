@@ -0,0 +1,170 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package execcredcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/fakekeychain"
+)
+
+func TestKeychainCache(t *testing.T) {
+	t.Parallel()
+	now := time.Now().Round(1 * time.Second)
+
+	type testKey struct{ K1, K2 string }
+	key := testKey{K1: "v1", K2: "v2"}
+
+	cred := &clientauthenticationv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ExecCredential",
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		},
+		Status: &clientauthenticationv1beta1.ExecCredentialStatus{
+			Token:               "test-token",
+			ExpirationTimestamp: timePtr(now.Add(1 * time.Hour)),
+		},
+	}
+
+	storedEntry := func(t *testing.T, e entry) string {
+		t.Helper()
+		data, err := json.Marshal(e)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	tests := []struct {
+		name            string
+		keychainSecret  func(t *testing.T) string
+		keychainErr     error
+		put             bool
+		want            *clientauthenticationv1beta1.ExecCredential
+		wantErrors      []string
+		wantKeychainLen int
+		wantInFallback  bool
+	}{
+		{
+			name: "not found",
+		},
+		{
+			name:            "stores and finds the credential in the keychain",
+			put:             true,
+			want:            cred,
+			wantKeychainLen: 2, // the credential and the index
+		},
+		{
+			name: "invalid credential in the keychain",
+			keychainSecret: func(t *testing.T) string {
+				return "invalid"
+			},
+			wantErrors:      []string{"could not decode credential from keychain: invalid character 'i' looking for beginning of value"},
+			wantKeychainLen: 1,
+		},
+		{
+			name: "expired credential in the keychain",
+			keychainSecret: func(t *testing.T) string {
+				return storedEntry(t, entry{
+					CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Minute)),
+					Credential: &clientauthenticationv1beta1.ExecCredentialStatus{
+						Token:               "test-token",
+						ExpirationTimestamp: timePtr(now.Add(-1 * time.Minute)),
+					},
+				})
+			},
+		},
+		{
+			name: "credential in the keychain older than the maximum cache duration",
+			keychainSecret: func(t *testing.T) string {
+				return storedEntry(t, entry{
+					CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+					Credential:        cred.Status,
+				})
+			},
+		},
+		{
+			name:        "keychain fails",
+			keychainErr: fmt.Errorf("some keychain error"),
+			put:         true,
+			want:        cred,
+			wantErrors: []string{
+				"could not write credential to keychain, using fallback cache: some keychain error",
+				"could not read credential from keychain, using fallback cache: some keychain error",
+			},
+			wantInFallback: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmp := testutil.TempDir(t) + "/credentials.yaml"
+
+			kc := fakekeychain.New()
+			if tt.keychainSecret != nil {
+				require.NoError(t, kc.Set(keychainService, jsonSHA256Hex(key), tt.keychainSecret(t)))
+			}
+			kc.Err = tt.keychainErr
+
+			errors := errorCollector{t: t}
+			fallback := New(tmp)
+			c := NewKeychain(kc, fallback)
+			c.errReporter = errors.report
+
+			if tt.put {
+				c.Put(key, cred)
+			}
+			got := c.Get(key)
+			require.Equal(t, tt.want, got)
+			errors.require(tt.wantErrors)
+
+			kc.Err = nil
+			require.Equal(t, tt.wantKeychainLen, kc.Len())
+			if tt.wantInFallback {
+				require.Equal(t, cred, fallback.Get(key))
+			} else {
+				require.NoFileExists(t, tmp)
+			}
+		})
+	}
+}
+
+func TestKeychainCacheClear(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/credentials.yaml"
+
+	cred := &clientauthenticationv1beta1.ExecCredential{
+		Status: &clientauthenticationv1beta1.ExecCredentialStatus{
+			Token:               "test-token",
+			ExpirationTimestamp: timePtr(time.Now().Add(1 * time.Hour)),
+		},
+	}
+	kc := fakekeychain.New()
+	fallback := New(tmp)
+	c := NewKeychain(kc, fallback)
+	errors := errorCollector{t: t}
+	c.errReporter = errors.report
+
+	c.Put("key-1", cred)
+	c.Put("key-2", cred)
+	require.Equal(t, 3, kc.Len())
+	fallback.Put("key-3", cred)
+
+	c.Clear()
+	errors.require(nil)
+	require.Zero(t, kc.Len())
+	require.Nil(t, c.Get("key-1"))
+	require.NotNil(t, c.Get("key-3"))
+
+	kc.Err = fmt.Errorf("some keychain error")
+	c.Clear()
+	errors.require([]string{"could not list credentials in keychain: some keychain error"})
+}
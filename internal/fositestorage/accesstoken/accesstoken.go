@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package accesstoken
@@ -31,7 +31,19 @@ const (
 	// Version 2 is when we switched to storing psession.PinnipedSession inside the fosite request.
 	// Version 3 is when we added the Username field to the psession.CustomSessionData.
 	// Version 4 is when fosite added json tags to their openid.DefaultSession struct.
-	accessTokenStorageVersion = "4"
+	// Version 5 is when we added the LastGroupRefreshTime field to the psession.LDAPSessionData and psession.ActiveDirectorySessionData.
+	// Version 6 is when we added the Kerberos field to the psession.CustomSessionData.
+	// Version 7 is when we added the UpstreamUsername and UpstreamGroups fields to the psession.CustomSessionData.
+	// Version 8 is when we added the PostLogoutRedirectURIs field to the clientregistry.Client.
+	// Version 9 is when we added the BackchannelLogoutURI field to the clientregistry.Client.
+	// Version 10 is when we added the IDTokenLifespan, AccessTokenLifespan, and RefreshTokenLifespan fields to the clientregistry.Client.
+	// Version 11 is when we added the SessionExpiresAt field to the psession.CustomSessionData.
+	// Version 12 is when we added the TokenExchangeAudiences field to the clientregistry.Client.
+	// Version 13 is when we added the AllowedGroupNames and AllowedGroupPrefixes fields to the clientregistry.Client.
+	// Version 14 is when we added the JWTAccessTokens field to the clientregistry.Client.
+	// Version 15 is when we added the RequireConsent field to the clientregistry.Client.
+	// Version 16 is when we added the ClientCredentialsScopes and ClientCredentialsAudiences fields to the clientregistry.Client.
+	accessTokenStorageVersion = "16"
 )
 
 type RevocationStorage interface {
@@ -50,6 +62,13 @@ type Session struct {
 	Version string          `json:"version"`
 }
 
+var _ crud.ExpiringJSON = &Session{}
+
+// ExpiresAt returns when the downstream session ends, so that this access token storage is garbage collected no later than that.
+func (s *Session) ExpiresAt() time.Time {
+	return fositestorage.SessionExpiresAt(s.Request)
+}
+
 func New(secrets corev1client.SecretInterface, clock func() time.Time, sessionStorageLifetime time.Duration) RevocationStorage {
 	return &accessTokenStorage{storage: crud.New(TypeLabelValue, secrets, clock, sessionStorageLifetime)}
 }
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package authorizationcode
@@ -32,7 +32,19 @@ const (
 	// Version 2 is when we switched to storing psession.PinnipedSession inside the fosite request.
 	// Version 3 is when we added the Username field to the psession.CustomSessionData.
 	// Version 4 is when fosite added json tags to their openid.DefaultSession struct.
-	authorizeCodeStorageVersion = "4"
+	// Version 5 is when we added the LastGroupRefreshTime field to the psession.LDAPSessionData and psession.ActiveDirectorySessionData.
+	// Version 6 is when we added the Kerberos field to the psession.CustomSessionData.
+	// Version 7 is when we added the UpstreamUsername and UpstreamGroups fields to the psession.CustomSessionData.
+	// Version 8 is when we added the PostLogoutRedirectURIs field to the clientregistry.Client.
+	// Version 9 is when we added the BackchannelLogoutURI field to the clientregistry.Client.
+	// Version 10 is when we added the IDTokenLifespan, AccessTokenLifespan, and RefreshTokenLifespan fields to the clientregistry.Client.
+	// Version 11 is when we added the SessionExpiresAt field to the psession.CustomSessionData.
+	// Version 12 is when we added the TokenExchangeAudiences field to the clientregistry.Client.
+	// Version 13 is when we added the AllowedGroupNames and AllowedGroupPrefixes fields to the clientregistry.Client.
+	// Version 14 is when we added the JWTAccessTokens field to the clientregistry.Client.
+	// Version 15 is when we added the RequireConsent field to the clientregistry.Client.
+	// Version 16 is when we added the ClientCredentialsScopes and ClientCredentialsAudiences fields to the clientregistry.Client.
+	authorizeCodeStorageVersion = "16"
 )
 
 var _ oauth2.AuthorizeCodeStorage = &authorizeCodeStorage{}
@@ -236,12 +248,12 @@ const ExpectedAuthorizeCodeSessionJSONFromFuzzing = `{
 							"User": null,
 							"Host": "",
 							"Path": "",
+							"Fragment": "",
+							"RawQuery": "",
 							"RawPath": "",
-							"OmitHost": false,
+							"RawFragment": "",
 							"ForceQuery": false,
-							"RawQuery": "",
-							"Fragment": "",
-							"RawFragment": ""
+							"OmitHost": false
 						}
 					},
 					{
@@ -254,12 +266,12 @@ const ExpectedAuthorizeCodeSessionJSONFromFuzzing = `{
 							"User": null,
 							"Host": "",
 							"Path": "",
+							"Fragment": "",
+							"RawQuery": "",
 							"RawPath": "",
-							"OmitHost": false,
+							"RawFragment": "",
 							"ForceQuery": false,
-							"RawQuery": "",
-							"Fragment": "",
-							"RawFragment": ""
+							"OmitHost": false
 						}
 					},
 					{
@@ -272,12 +284,12 @@ const ExpectedAuthorizeCodeSessionJSONFromFuzzing = `{
 							"User": null,
 							"Host": "",
 							"Path": "",
+							"Fragment": "",
+							"RawQuery": "",
 							"RawPath": "",
-							"OmitHost": false,
+							"RawFragment": "",
 							"ForceQuery": false,
-							"RawQuery": "",
-							"Fragment": "",
-							"RawFragment": ""
+							"OmitHost": false
 						}
 					}
 				]
@@ -289,125 +301,162 @@ const ExpectedAuthorizeCodeSessionJSONFromFuzzing = `{
 				"Q7钎漡臧n栀,i"
 			],
 			"request_object_signing_alg": "廜+v,淬Ʋ4Dʧ呩锏緍场脋",
-			"token_endpoint_auth_signing_alg": "ưƓǴ罷ǹ~]ea胠Ĺĩv絹b垇I"
+			"token_endpoint_auth_signing_alg": "ưƓǴ罷ǹ~]ea胠Ĺĩv絹b垇I",
+			"post_logout_redirect_uris": [
+				"D凘ǳ[甿",
+				"頂箨J-a稆涒聽ȑǕÄŮǻ并峸Tćɇ}"
+			],
+			"backchannel_logout_uri": "ÉhOǹ冟[ǟ褾攚ŝlĆ厦",
+			"id_token_lifespan": -4012995938037213450,
+			"access_token_lifespan": -3537317724966064222,
+			"refresh_token_lifespan": -7195157730346949894,
+			"token_exchange_audiences": [
+				"挮9凚Ła卦牟懧¥ɂĵ~Čy"
+			],
+			"allowed_group_names": [
+				"Ú慂+槰蚪i齥篗裢?霃谥vƘ:ƿ/"
+			],
+			"allowed_group_prefixes": [
+				"yJǽȭ$奍囀",
+				"UǦȾ舸*ɲ3@ƍ行"
+			],
+			"client_credentials_scopes": [
+				"汗狲N\u003cCq罉ZPſĝE",
+				"mĔ櫓Ǩ療騃Ǐ}ɟ8嗤ʓȞʂ櫩\"Łȗɉ",
+				"裄@搿ùŶ褰ʎ"
+			],
+			"client_credentials_audiences": [
+				"sčɦƦ诱ļ攬林Ñz焁糳¿o\u003eQ鱙翑Ȳ",
+				"#锰劝旣樎Ȱ鍌#ȳńƩŴȭ;"
+			]
 		},
 		"scopes": [
-			"ĩǀŻQ'k頂箨J-a",
-			"ɓ啶#昏Q遐*\\髎bŸ1慂U"
+			"褗6巽ēđų蓼tùZ蛆鬣a\"ÙǞ0觢",
+			"j¦鲶H股ƲLŋZ-{"
 		],
 		"grantedScopes": [
-			"ƼĮǡ鑻Z¥篚h°ʣ£ǖ%\"砬ʍ"
+			"hpƧ蓟炆ç"
 		],
 		"form": {
-			"¡": [
-				"Ła卦牟懧¥ɂĵ",
-				"ɎǛƍdÚ慂+槰蚪i齥篗裢?霃谥vƘ:",
-				"/濔Aʉ\u003cS獾蔀OƭUǦ"
+			"~ů崧軒q腟u尿宲!N": [
+				"嫯R"
 			],
-			"民撲ʓeŘ嬀j¤囡莒汗狲N\u003cCq": [
-				"5ȏ樛ȧ.mĔ櫓Ǩ療騃Ǐ}ɟ",
-				"潠[ĝU噤'",
-				"ŁȗɉY妶ǵ!ȁ"
-			],
-			"褰ʎɰ癟VĎĢ婄磫绒u妔隤ʑƍš駎竪": [
-				"鱙翑ȲŻ麤ã桒嘞\\摗Ǘū稖咾鎅ǸÖ"
+			"¤'+ʣ": [
+				"\u0026ɽ艄ʬʏ"
 			]
 		},
 		"session": {
 			"fosite": {
 				"id_token_claims": {
-					"jti": "褗6巽ēđų蓼tùZ蛆鬣a\"ÙǞ0觢",
-					"iss": "j¦鲶H股ƲLŋZ-{",
-					"sub": "ehpƧ蓟",
+					"jti": "ǫ\\aȊ4ț髄AlȒ",
+					"iss": "_袻vÓG-壧丵礴鋈k蟵pAɂʅ噪",
+					"sub": "\u0026PƢ曰l騌蘙螤\\阏Đ镴Ƥm蔻ǭ\\鿞Č",
 					"aud": [
-						"驜Ŗ~ů崧軒q腟u尿宲!"
+						"騒濒鑳绪HrǓ\\BRë_g\"ʎ啴SƇ",
+						"Č{Ȩʦ4撎胬龯,t猟"
 					],
-					"nonce": "ǎ^嫯R忑隯ƗƋ*L\u0026",
-					"exp": "1989-06-02T14:40:29.613836765Z",
-					"iat": "2052-03-26T02:39:27.882495556Z",
-					"rat": "2038-04-06T10:46:24.698586972Z",
-					"auth_time": "2003-01-05T11:30:18.206004879Z",
-					"at_hash": "ğǫ\\aȊ4ț髄Al",
-					"acr": "曓蓳n匟鯘磹*金爃鶴滱ůĮǐ_c3#",
+					"nonce": "郂üţ",
+					"exp": "2056-02-18T11:02:06.41772941Z",
+					"iat": "2019-10-31T12:28:35.603806848Z",
+					"rat": "2090-12-04T13:45:43.757115889Z",
+					"auth_time": "1982-04-18T09:24:15.317737386Z",
+					"at_hash": "Ǫ飘ȱF?",
+					"acr": "ğ~劰û橸",
 					"amr": [
-						"装ƹýĸŴB岺Ð嫹Sx镯荫őł疂ư墫"
+						"旎Ȳ濡胉室癑勦e骲v0H晦XŘO溪"
 					],
-					"c_hash": "\u0026鶡",
+					"c_hash": "屃ȹ碼Ǫ曞耕ȣ甽4Ǟ",
 					"ext": {
-						"rǓ\\BRë_g\"ʎ啴SƇMǃļū": {
-							"4撎胬龯,t猟i\u0026\u0026Q@ǤǟǗ": [
-								1239190737
+						"Bd謺錳4帳ŅǃĊdŘ鸨EJ毕": 3703211980,
+						"řĬń戹%c%稒趘ɆƊ#XɗD愌铵ĸY": {
+							",Ǘ饮欥": [
+								367102170
 							],
-							"飘ȱF?Ƈ畋": {
-								"劰û橸ɽ銐ƭ?}HƟ玈鳚": null,
-								"骲v0H晦XŘO溪V蔓Ȍ+~ē埅Ȝ": {
-									"4Ǟ": false
-								}
+							"膘)渽圭": {
+								".醋fʜ3": {
+									"ɦüHêQ仏1őƖ2Ė暮唍ǞʜƢú4": false
+								},
+								"a|载ǰɱ汶C]ɲ'=ĸ闒NȢ": null
 							}
-						},
-						"鑳绪": 2738428764
+						}
 					}
 				},
 				"headers": {
 					"extra": {
-						"d謺錳4帳ŅǃĊ": 663773398,
-						"Ř鸨EJ": {
-							"Ǽǟ迍阊v\"豑觳翢砜": [
-								995342744
-							],
-							"ȏl鐉诳DT=3骜Ǹ": {
-								"厷ɁOƪ穋嶿鳈恱va|载ǰɱ汶C]ɲ": null,
-								"荤Ý呐ʣ®ǅȪǣǎǔ爣縗ɦü": {
-									"H :靥湤庤毩fɤȆʪ融ƆuŤn": true
-								}
-							}
+						"+韁臯氃妪婝rȤ\"h丬鎒ơ娻}ɼƟȥE": 1475316937,
+						"嫌ɶȤ\u0026¥潝邎": {
+							"檄¬mrŉ2": {
+								".悃UƎȣ掘ʃƸ澺": {
+									"©Ź榨Q|ôɵt毇妬\u003e6鉢緋u": false
+								},
+								"餧Ĭ倏4ĵ嶼仒篻ɥ闣ʬ橳(ý綃ʃʚƟ": null
+							},
+							"莛8嘶×姮c恭企Ź邖ɐ": [
+								2398748717
+							]
 						}
 					}
 				},
 				"expires_at": {
-					"韁臯氃妪婝rȤ\"h丬鎒ơ娻}ɼƟ": "1970-04-27T04:31:30.902468229Z"
+					"ʚ£:設虝27就伒犘c钡ɏȫ齁š%O": "2067-07-23T06:07:32.637553013Z",
+					"概÷驣7Ʀ澉": "1979-11-11T20:59:28.367928651Z"
 				},
-				"username": "髉龳ǽÙ",
-				"subject": "\u0026¥潝邎Ȗ莅ŝǔ盕戙鵮碡ʯiŬŽ"
+				"username": "]ȗ韚ʫ繕ȫ碰+ʫ怓曥",
+				"subject": "帴ʘ赱ŕ瑹xȢ~1Įx欼笝?ú"
 			},
 			"custom": {
-				"username": "Ĝ眧Ĭ",
-				"providerUID": "ŉ2ƋŢ觛ǂ焺nŐǛ",
-				"providerName": "ɥ闣ʬ橳(ý綃ʃʚƟ覣k眐4",
-				"providerType": "ȣ掘ʃƸ澺淗a紽ǒ|鰽",
+				"username": "妼É4İ\u003e×1飞O+î艔垎0OƉ",
+				"upstreamUsername": "%Ä摱ìÓȐĨf跞@)¿,ɭS隑i",
+				"upstreamGroups": [
+					"艱iYn面@yȝƋ鬯犦獢9c5¤.岵",
+					"浛a齙\\蹼偦歛"
+				],
+				"providerUID": " 皦pSǬŝ社Vƅȭǝ*擦28ǅ",
+				"providerName": "vư",
+				"providerType": "ć",
 				"warnings": [
-					"t毇妬\u003e6鉢緋uƴŤȱʀļÂ",
-					"虝27就伒犘c钡ɏȫ齁š"
+					"ņ抰蛖a³2ʫ"
 				],
+				"sessionExpiresAt": "2079-08-27T20:01:03Z",
 				"oidc": {
-					"upstreamRefreshToken": "OpKȱ藚ɏ¬Ê蒭堜]ȗ韚ʫ繕ȫ碰+ʫ",
-					"upstreamAccessToken": "k9帴",
-					"upstreamSubject": "磊ůď逳鞪?3)藵睋邔\u0026Ű惫蜀Ģ",
-					"upstreamIssuer": "4İ"
+					"upstreamRefreshToken": "ʬ)ġ,TÀqy_º",
+					"upstreamAccessToken": "Ã轘屔挝ʌ鼂",
+					"upstreamSubject": "崓ļ憽-蹐È",
+					"upstreamIssuer": "駝重EȫʆɵʮGɃɫ囤"
 				},
 				"ldap": {
-					"userDN": "×",
+					"userDN": "+,Ȳ齠@ɍB鳛",
 					"extraRefreshAttributes": {
-						"ʥ笿0D": "s"
-					}
+						"0緃責": "腿tʏƲ%}ſ¯Ɣ 籌Tǘ乚Ȥ2Ķě",
+						"©硇焰õC嶃ĩŦʀ宍D挟霾H6b": "Ȟ2\\袓,5JƊ津x荃墎]ac[¡",
+						"融貵": "给"
+					},
+					"lastGroupRefreshTime": null
 				},
 				"activedirectory": {
-					"userDN": "ĝ",
+					"userDN": "=瑅ƍ逤ŔfȀ箬+橇",
 					"extraRefreshAttributes": {
-						"IȽ齤士bEǎ": "跞@)¿,ɭS隑ip偶宾儮猷V麹",
-						"ȝƋ鬯犦獢9c5¤.岵": "浛a齙\\蹼偦歛"
-					}
+						"ā鲴ļt}% B駚ǛSĘ驧ml婆Ĵ鴾o": "Ȟ崂硠CqƜľHYÖ鷭驖5ƭ,",
+						"莈此ŵGvęř": "ȮɄp貧ɔǟC½ư3f赘ȶ",
+						"鑇Å睰ǎƳ": "A匹ǂ熒Ƕ\u003e¨|Y弴hǇ觃趿Ȝa榏熷戒"
+					},
+					"lastGroupRefreshTime": null
+				},
+				"kerberos": {
+					"principal": "Ĳƺ燅ňƳÏg"
 				}
 			}
 		},
 		"requestedAudience": [
-			" 皦pSǬŝ社Vƅȭǝ*擦28ǅ",
-			"vư"
+			"ÑWɸǝ0ǏȀ5Ưī",
+			"ɪ\u003c%WqC",
+			"ɨÃLǗ庱~暣LP郺戥ėx"
 		],
 		"grantedAudience": [
-			"置b",
-			"筫MN\u0026錝D肁Ŷɽ蔒PR}Ųʓl{"
+			"Ȫq嶬ʣƙ",
+			"円緊",
+			"欥Ɓ象5柩Ȍ[Ʃ郌韣Ǣ27"
 		]
 	},
-	"version": "4"
+	"version": "16"
 }`
@@ -0,0 +1,147 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package devicecode stores the state of RFC8628 device authorization requests.
+package devicecode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/ory/fosite"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
+)
+
+const (
+	TypeLabelValue = "device-code"
+
+	ErrInvalidDeviceCodeRequestVersion = constable.Error("device code request data has wrong version")
+	ErrInvalidDeviceCodeRequestData    = constable.Error("device code request data must be present")
+
+	// Version 1 was the initial release of storage.
+	deviceCodeStorageVersion = "1"
+)
+
+// Status describes how far the user has come with a device authorization request.
+type Status string
+
+const (
+	// StatusPending means that the user has not finished logging in yet.
+	StatusPending Status = "pending"
+
+	// StatusApproved means that the user has logged in, so the device may redeem its device code.
+	StatusApproved Status = "approved"
+
+	// StatusDenied means that the login of the user failed, so the device must start over.
+	StatusDenied Status = "denied"
+)
+
+// Session is the stored state of one device authorization request.
+type Session struct {
+	// ClientID is the ID of the client which made the device authorization request.
+	ClientID string `json:"clientID"`
+
+	// Scopes are the scopes which were requested by the client.
+	Scopes []string `json:"scopes"`
+
+	// DeviceCodeSignature is the signature of the device code which was issued to the client. The device code itself
+	// is never stored.
+	DeviceCodeSignature string `json:"deviceCodeSignature"`
+
+	// Status is how far the user has come with the device authorization request.
+	Status Status `json:"status"`
+
+	// Expiry is when the device code expires.
+	Expiry time.Time `json:"expiry"`
+
+	// Interval is how long the client must wait between its requests to the token endpoint.
+	Interval time.Duration `json:"interval"`
+
+	// LastPolledAt is when the client last asked the token endpoint for its tokens.
+	LastPolledAt time.Time `json:"lastPolledAt"`
+
+	// State is the state param of the downstream authorization request which was started by the verification page.
+	State string `json:"state"`
+
+	// PKCECodeVerifier is the PKCE code verifier of the downstream authorization request which was started by the
+	// verification page.
+	PKCECodeVerifier string `json:"pkceCodeVerifier"`
+
+	// Authcode is the downstream authcode which was issued when the user logged in. The token endpoint redeems it
+	// on behalf of the client, when the client redeems its device code.
+	Authcode string `json:"authcode"`
+
+	Version string `json:"version"`
+}
+
+// Storage stores the state of device authorization requests, keyed by their user codes.
+type Storage interface {
+	CreateDeviceCodeSession(ctx context.Context, userCode string, session *Session) error
+	GetDeviceCodeSession(ctx context.Context, userCode string) (*Session, string, error)
+	UpdateDeviceCodeSession(ctx context.Context, userCode string, resourceVersion string, session *Session) error
+	DeleteDeviceCodeSession(ctx context.Context, userCode string) error
+}
+
+type deviceCodeStorage struct {
+	storage crud.Storage
+}
+
+var _ Storage = &deviceCodeStorage{}
+
+func New(secrets corev1client.SecretInterface, clock func() time.Time, sessionStorageLifetime time.Duration) Storage {
+	return &deviceCodeStorage{storage: crud.New(TypeLabelValue, secrets, clock, sessionStorageLifetime)}
+}
+
+// Signature returns the value which is stored in place of a secret value, such as a user code or a device code.
+func Signature(secretValue string) string {
+	sum := sha256.Sum256([]byte(secretValue))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (d *deviceCodeStorage) CreateDeviceCodeSession(ctx context.Context, userCode string, session *Session) error {
+	session.Version = deviceCodeStorageVersion
+	_, err := d.storage.Create(ctx, Signature(userCode), session, nil, nil)
+	return err
+}
+
+func (d *deviceCodeStorage) GetDeviceCodeSession(ctx context.Context, userCode string) (*Session, string, error) {
+	signature := Signature(userCode)
+	session := &Session{}
+	rv, err := d.storage.Get(ctx, signature, session)
+
+	if errors.IsNotFound(err) {
+		return nil, "", fosite.ErrNotFound.WithWrap(err).WithDebug(err.Error())
+	}
+
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get device code session for %s: %w", signature, err)
+	}
+
+	if version := session.Version; version != deviceCodeStorageVersion {
+		return nil, "", fmt.Errorf("%w: device code session for %s has version %s instead of %s",
+			ErrInvalidDeviceCodeRequestVersion, signature, version, deviceCodeStorageVersion)
+	}
+
+	if session.ClientID == "" {
+		return nil, "", fmt.Errorf("malformed device code session for %s: %w", signature, ErrInvalidDeviceCodeRequestData)
+	}
+
+	return session, rv, nil
+}
+
+func (d *deviceCodeStorage) UpdateDeviceCodeSession(ctx context.Context, userCode string, resourceVersion string, session *Session) error {
+	session.Version = deviceCodeStorageVersion
+	_, err := d.storage.Update(ctx, Signature(userCode), resourceVersion, session)
+	return err
+}
+
+func (d *deviceCodeStorage) DeleteDeviceCodeSession(ctx context.Context, userCode string) error {
+	return d.storage.Delete(ctx, Signature(userCode))
+}
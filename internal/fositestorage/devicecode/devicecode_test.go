@@ -0,0 +1,185 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package devicecode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	coretesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/testutil"
+)
+
+const (
+	namespace = "test-ns"
+
+	userCode = "BCDFGHJK"
+
+	// The name of the Secret which stores the session of the userCode.
+	secretName = "pinniped-storage-device-code-erqpg2kb4pq6yfs6cclbmmtfmj6olbd6ia326tvakhfo5hwhkvuq"
+)
+
+var fakeNow = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+var lifetime = time.Minute * 11
+var fakeNowPlusLifetimeAsString = metav1.Time{Time: fakeNow.Add(lifetime)}.Format(time.RFC3339)
+
+func TestDeviceCodeStorage(t *testing.T) {
+	secretsGVR := schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "secrets",
+	}
+
+	wantActions := []coretesting.Action{
+		coretesting.NewCreateAction(secretsGVR, namespace, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName,
+				ResourceVersion: "",
+				Labels: map[string]string{
+					"storage.pinniped.dev/type": "device-code",
+				},
+				Annotations: map[string]string{
+					"storage.pinniped.dev/garbage-collect-after": fakeNowPlusLifetimeAsString,
+				},
+			},
+			Data: map[string][]byte{
+				"pinniped-storage-data":    []byte(`{"clientID":"pinniped-cli","scopes":["openid","offline_access"],"deviceCodeSignature":"fake-device-code-signature","status":"pending","expiry":"2030-01-01T00:10:00Z","interval":5000000000,"lastPolledAt":"0001-01-01T00:00:00Z","state":"","pkceCodeVerifier":"","authcode":"","version":"1"}`),
+				"pinniped-storage-version": []byte("1"),
+			},
+			Type: "storage.pinniped.dev/device-code",
+		}),
+		coretesting.NewGetAction(secretsGVR, namespace, secretName),
+		coretesting.NewGetAction(secretsGVR, namespace, secretName),
+		coretesting.NewUpdateAction(secretsGVR, namespace, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName,
+				ResourceVersion: "",
+				Labels: map[string]string{
+					"storage.pinniped.dev/type": "device-code",
+				},
+				Annotations: map[string]string{
+					"storage.pinniped.dev/garbage-collect-after": fakeNowPlusLifetimeAsString,
+				},
+			},
+			Data: map[string][]byte{
+				"pinniped-storage-data":    []byte(`{"clientID":"pinniped-cli","scopes":["openid","offline_access"],"deviceCodeSignature":"fake-device-code-signature","status":"approved","expiry":"2030-01-01T00:10:00Z","interval":5000000000,"lastPolledAt":"0001-01-01T00:00:00Z","state":"fake-state","pkceCodeVerifier":"fake-pkce-code-verifier","authcode":"fake-authcode","version":"1"}`),
+				"pinniped-storage-version": []byte("1"),
+			},
+			Type: "storage.pinniped.dev/device-code",
+		}),
+		coretesting.NewGetAction(secretsGVR, namespace, secretName),
+		coretesting.NewDeleteAction(secretsGVR, namespace, secretName),
+	}
+
+	ctx, client, _, storage := makeTestSubject()
+
+	session := &Session{
+		ClientID:            "pinniped-cli",
+		Scopes:              []string{"openid", "offline_access"},
+		DeviceCodeSignature: "fake-device-code-signature",
+		Status:              StatusPending,
+		Expiry:              fakeNow.Add(10 * time.Minute),
+		Interval:            5 * time.Second,
+	}
+	err := storage.CreateDeviceCodeSession(ctx, userCode, session)
+	require.NoError(t, err)
+
+	gotSession, rv, err := storage.GetDeviceCodeSession(ctx, userCode)
+	require.NoError(t, err)
+	require.Equal(t, session, gotSession)
+
+	gotSession.Status = StatusApproved
+	gotSession.State = "fake-state"
+	gotSession.PKCECodeVerifier = "fake-pkce-code-verifier"
+	gotSession.Authcode = "fake-authcode"
+	err = storage.UpdateDeviceCodeSession(ctx, userCode, rv, gotSession)
+	require.NoError(t, err)
+
+	updatedSession, _, err := storage.GetDeviceCodeSession(ctx, userCode)
+	require.NoError(t, err)
+	require.Equal(t, gotSession, updatedSession)
+
+	err = storage.DeleteDeviceCodeSession(ctx, userCode)
+	require.NoError(t, err)
+
+	testutil.LogActualJSONFromCreateAction(t, client, 0) // makes it easier to update expected values when needed
+	require.Equal(t, wantActions, client.Actions())
+}
+
+func TestGetNotFound(t *testing.T) {
+	ctx, _, _, storage := makeTestSubject()
+
+	_, _, notFoundErr := storage.GetDeviceCodeSession(ctx, "NONEXIST")
+	require.EqualError(t, notFoundErr, "not_found")
+	require.True(t, errors.Is(notFoundErr, fosite.ErrNotFound))
+}
+
+func TestWrongVersion(t *testing.T) {
+	ctx, _, secrets, storage := makeTestSubject()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secretName,
+			ResourceVersion: "",
+			Labels: map[string]string{
+				"storage.pinniped.dev/type": "device-code",
+			},
+		},
+		Data: map[string][]byte{
+			"pinniped-storage-data":    []byte(`{"clientID":"pinniped-cli","version":"not-the-right-version"}`),
+			"pinniped-storage-version": []byte("1"),
+		},
+		Type: "storage.pinniped.dev/device-code",
+	}
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, _, err = storage.GetDeviceCodeSession(ctx, userCode)
+	require.EqualError(t, err, "device code request data has wrong version: device code session for JGDzaUHj4ewWXhCWFjJlYnzlhH5AN69OoFHK7p7HVWk has version not-the-right-version instead of 1")
+}
+
+func TestMissingClientID(t *testing.T) {
+	ctx, _, secrets, storage := makeTestSubject()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secretName,
+			ResourceVersion: "",
+			Labels: map[string]string{
+				"storage.pinniped.dev/type": "device-code",
+			},
+		},
+		Data: map[string][]byte{
+			"pinniped-storage-data":    []byte(`{"nonsense-key": "nonsense-value","version":"1"}`),
+			"pinniped-storage-version": []byte("1"),
+		},
+		Type: "storage.pinniped.dev/device-code",
+	}
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, _, err = storage.GetDeviceCodeSession(ctx, userCode)
+	require.EqualError(t, err, "malformed device code session for JGDzaUHj4ewWXhCWFjJlYnzlhH5AN69OoFHK7p7HVWk: device code request data must be present")
+}
+
+func TestSignature(t *testing.T) {
+	require.Equal(t, "JGDzaUHj4ewWXhCWFjJlYnzlhH5AN69OoFHK7p7HVWk", Signature(userCode))
+}
+
+func makeTestSubject() (context.Context, *fake.Clientset, corev1client.SecretInterface, Storage) {
+	client := fake.NewSimpleClientset()
+	secrets := client.CoreV1().Secrets(namespace)
+	return context.Background(), client, secrets, New(secrets, clocktesting.NewFakeClock(fakeNow).Now, lifetime)
+}
@@ -1,9 +1,11 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package fositestorage
 
 import (
+	"time"
+
 	"github.com/ory/fosite"
 
 	"go.pinniped.dev/internal/constable"
@@ -34,3 +36,14 @@ func ValidateAndExtractAuthorizeRequest(requester fosite.Requester) (*fosite.Req
 
 	return request, nil
 }
+
+// SessionExpiresAt returns when the downstream session of the request ends because of the session lifetimes of its
+// FederationDomain, or zero when the session has no such limit. Session storage which holds the tokens of a session
+// should not outlive the session, so that the garbage collector can clean up after the session soon after it ends.
+func SessionExpiresAt(request *fosite.Request) time.Time {
+	session, ok := request.Session.(*psession.PinnipedSession)
+	if !ok || session.Custom == nil || session.Custom.SessionExpiresAt == nil {
+		return time.Time{}
+	}
+	return session.Custom.SessionExpiresAt.Time
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package groupsuffix
@@ -9,6 +9,8 @@ import (
 	identityv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/identity/v1alpha1"
 	loginv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/login/v1alpha1"
 	clientsecretv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/clientsecret/v1alpha1"
+	logincheckv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/logincheck/v1alpha1"
+	sessionv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/session/v1alpha1"
 )
 
 type GroupData schema.GroupVersion
@@ -26,23 +28,31 @@ func ConciergeAggregatedGroups(apiGroupSuffix string) (login, identity GroupData
 	}
 
 	return GroupData{
-			Group:   loginConciergeAPIGroup,
-			Version: loginv1alpha1.SchemeGroupVersion.Version,
-		}, GroupData{
-			Group:   identityConciergeAPIGroup,
-			Version: identityv1alpha1.SchemeGroupVersion.Version,
-		}
+		Group:   loginConciergeAPIGroup,
+		Version: loginv1alpha1.SchemeGroupVersion.Version,
+	}, GroupData{
+		Group:   identityConciergeAPIGroup,
+		Version: identityv1alpha1.SchemeGroupVersion.Version,
+	}
 }
 
-func SupervisorAggregatedGroups(apiGroupSuffix string) (clientSecret GroupData) {
+func SupervisorAggregatedGroups(apiGroupSuffix string) (clientSecret, loginCheck, session GroupData) {
 	clientSecretVirtualSupervisorAPIGroup, ok1 := Replace(clientsecretv1alpha1.GroupName, apiGroupSuffix)
+	loginCheckVirtualSupervisorAPIGroup, ok2 := Replace(logincheckv1alpha1.GroupName, apiGroupSuffix)
+	sessionVirtualSupervisorAPIGroup, ok3 := Replace(sessionv1alpha1.GroupName, apiGroupSuffix)
 
-	if !ok1 {
+	if valid := ok1 && ok2 && ok3; !valid {
 		panic("static group input is invalid")
 	}
 
 	return GroupData{
 		Group:   clientSecretVirtualSupervisorAPIGroup,
 		Version: clientsecretv1alpha1.SchemeGroupVersion.Version,
+	}, GroupData{
+		Group:   loginCheckVirtualSupervisorAPIGroup,
+		Version: logincheckv1alpha1.SchemeGroupVersion.Version,
+	}, GroupData{
+		Group:   sessionVirtualSupervisorAPIGroup,
+		Version: sessionv1alpha1.SchemeGroupVersion.Version,
 	}
 }
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package groupsuffix
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/errors"
@@ -110,6 +112,34 @@ func New(apiGroupSuffix string) kubeclient.Middleware {
 			})
 		}),
 
+		kubeclient.MiddlewareFunc(func(_ context.Context, rt kubeclient.RoundTrip) {
+			// we only care if this is a create on an Event without a subresource, since Events may be regarding our objects
+			if (rt.Resource() != eventsv1.SchemeGroupVersion.WithResource("events") &&
+				rt.Resource() != corev1.SchemeGroupVersion.WithResource("events")) ||
+				rt.Verb() != kubeclient.VerbCreate ||
+				rt.Subresource() != "" {
+				return
+			}
+
+			rt.MutateRequest(func(obj kubeclient.Object) error {
+				switch event := obj.(type) {
+				case *eventsv1.Event:
+					replaceObjectRefGroup(&event.Regarding, apiGroupSuffix)
+					if event.Related != nil {
+						replaceObjectRefGroup(event.Related, apiGroupSuffix)
+					}
+				case *corev1.Event:
+					replaceObjectRefGroup(&event.InvolvedObject, apiGroupSuffix)
+					if event.Related != nil {
+						replaceObjectRefGroup(event.Related, apiGroupSuffix)
+					}
+				default:
+					return fmt.Errorf("cannot cast obj of type %T to an Event", obj)
+				}
+				return nil
+			})
+		}),
+
 		kubeclient.MiddlewareFunc(func(_ context.Context, rt kubeclient.RoundTrip) {
 			// always unreplace owner refs with apiGroupSuffix because we can consume those objects across all verbs
 			rt.MutateResponse(mutateOwnerRefs(Unreplace, apiGroupSuffix))
@@ -152,6 +182,17 @@ func mutateOwnerRefs(replaceFunc func(baseAPIGroup, apiGroupSuffix string) (stri
 	}
 }
 
+// replaceObjectRefGroup replaces the API group of the referenced object with apiGroupSuffix, when the referenced
+// object is one of ours.
+func replaceObjectRefGroup(ref *corev1.ObjectReference, apiGroupSuffix string) {
+	gv, _ := schema.ParseGroupVersion(ref.APIVersion) // error is safe to ignore, empty gv is fine
+
+	if newGroup, ok := Replace(gv.Group, apiGroupSuffix); ok {
+		gv.Group = newGroup
+		ref.APIVersion = gv.String()
+	}
+}
+
 // Replace constructs an API group from a baseAPIGroup and a parameterized apiGroupSuffix.
 //
 // We assume that all baseAPIGroup's will end in "pinniped.dev", and therefore we can safely replace
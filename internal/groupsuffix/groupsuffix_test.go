@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package groupsuffix
@@ -11,12 +11,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	authv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
 	loginv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/login/v1alpha1"
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/testutil"
 )
@@ -173,6 +175,35 @@ func TestMiddlware(t *testing.T) {
 		authenticatorAPIGroup(replaceGV(t, authv1alpha1.SchemeGroupVersion, newSuffix).Group),
 	)
 
+	pinnipedObjectRef := corev1.ObjectReference{
+		APIVersion: idpv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "LDAPIdentityProvider",
+		Name:       "some-name",
+		UID:        "some-uid",
+	}
+	pinnipedObjectRefWithNewGroup := pinnipedObjectRef
+	pinnipedObjectRefWithNewGroup.APIVersion = replaceGV(t, idpv1alpha1.SchemeGroupVersion, newSuffix).String()
+	nonPinnipedObjectRef := corev1.ObjectReference{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       "Pod",
+		Name:       "some-name",
+		UID:        "some-uid",
+	}
+	eventRegardingPinnipedObject := &eventsv1.Event{
+		Regarding: pinnipedObjectRef,
+		Related:   &nonPinnipedObjectRef,
+	}
+	eventRegardingPinnipedObjectWithNewGroup := &eventsv1.Event{
+		Regarding: pinnipedObjectRefWithNewGroup,
+		Related:   &nonPinnipedObjectRef,
+	}
+	legacyEventRegardingPinnipedObject := &corev1.Event{
+		InvolvedObject: pinnipedObjectRef,
+	}
+	legacyEventRegardingPinnipedObjectWithNewGroup := &corev1.Event{
+		InvolvedObject: pinnipedObjectRefWithNewGroup,
+	}
+
 	tests := []struct {
 		name                                              string
 		apiGroupSuffix                                    string
@@ -444,6 +475,59 @@ func TestMiddlware(t *testing.T) {
 			wantRequestObj:      tokenCredentialRequestWithNewGroup,
 			wantResponseObj:     tokenCredentialRequestWithNewGroup, // the middleware will reset object GVK for us
 		},
+		{
+			name:           "create event regarding pinniped.dev resource",
+			apiGroupSuffix: newSuffix,
+			rt: (&testutil.RoundTrip{}).
+				WithVerb(kubeclient.VerbCreate).
+				WithNamespace("some-namespace").
+				WithResource(eventsv1.SchemeGroupVersion.WithResource("events")),
+			requestObj:          eventRegardingPinnipedObject,
+			responseObj:         eventRegardingPinnipedObjectWithNewGroup,
+			wantMutateRequests:  2,
+			wantMutateResponses: 1,
+			wantRequestObj:      eventRegardingPinnipedObjectWithNewGroup,
+			wantResponseObj:     eventRegardingPinnipedObjectWithNewGroup, // the referenced object is not unreplaced on the way back in
+		},
+		{
+			name:           "create legacy event regarding pinniped.dev resource",
+			apiGroupSuffix: newSuffix,
+			rt: (&testutil.RoundTrip{}).
+				WithVerb(kubeclient.VerbCreate).
+				WithNamespace("some-namespace").
+				WithResource(corev1.SchemeGroupVersion.WithResource("events")),
+			requestObj:          legacyEventRegardingPinnipedObject,
+			responseObj:         legacyEventRegardingPinnipedObjectWithNewGroup,
+			wantMutateRequests:  2,
+			wantMutateResponses: 1,
+			wantRequestObj:      legacyEventRegardingPinnipedObjectWithNewGroup,
+			wantResponseObj:     legacyEventRegardingPinnipedObjectWithNewGroup, // the referenced object is not unreplaced on the way back in
+		},
+		{
+			name:           "patch event regarding pinniped.dev resource",
+			apiGroupSuffix: newSuffix,
+			rt: (&testutil.RoundTrip{}).
+				WithVerb(kubeclient.VerbPatch).
+				WithNamespace("some-namespace").
+				WithResource(eventsv1.SchemeGroupVersion.WithResource("events")),
+			responseObj:         eventRegardingPinnipedObjectWithNewGroup,
+			wantMutateResponses: 1,
+			wantResponseObj:     eventRegardingPinnipedObjectWithNewGroup,
+		},
+		{
+			name:           "create event with non-*eventsv1.Event",
+			apiGroupSuffix: newSuffix,
+			rt: (&testutil.RoundTrip{}).
+				WithVerb(kubeclient.VerbCreate).
+				WithNamespace("some-namespace").
+				WithResource(eventsv1.SchemeGroupVersion.WithResource("events")),
+			requestObj:              podWithoutOwner,
+			responseObj:             podWithoutOwner,
+			wantMutateRequests:      2,
+			wantMutateResponses:     1,
+			wantMutateRequestErrors: []string{`cannot cast obj of type *v1.Pod to an Event`},
+			wantResponseObj:         podWithoutOwner,
+		},
 		{
 			name:           "create tokencredentialrequest with pinniped.dev authenticator api group",
 			apiGroupSuffix: newSuffix,
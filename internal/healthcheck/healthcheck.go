@@ -0,0 +1,94 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package healthcheck implements the checks of the readiness endpoints of the Supervisor and the Concierge, which make
+// the pods unready when they are running but cannot do their job.
+package healthcheck
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/server/healthz"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/dynamiccert"
+)
+
+const (
+	errNoServingCertificate = constable.Error("no serving certificate has been loaded yet")
+
+	// How long the storage check may take, which must be shorter than the timeout of the readiness probe.
+	storageCheckTimeout = 2 * time.Second
+)
+
+// CacheSyncWaiter is implemented by the shared informer factories.
+type CacheSyncWaiter interface {
+	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+}
+
+// InformerSync returns a check which fails until every started informer of the factories has synced its cache.
+func InformerSync(name string, factories ...CacheSyncWaiter) healthz.HealthChecker {
+	return healthz.NamedCheck(name, func(_ *http.Request) error {
+		// A closed channel makes WaitForCacheSync return immediately.
+		stopped := make(chan struct{})
+		close(stopped)
+
+		var notSynced []string
+		for _, factory := range factories {
+			for informerType, synced := range factory.WaitForCacheSync(stopped) {
+				if !synced {
+					notSynced = append(notSynced, informerType.String())
+				}
+			}
+		}
+		if len(notSynced) > 0 {
+			sort.Strings(notSynced)
+			return fmt.Errorf("%d informers have not synced yet: %s", len(notSynced), strings.Join(notSynced, ", "))
+		}
+		return nil
+	})
+}
+
+// ServingCertificate returns a check which fails when the provider does not have a serving certificate, or when its
+// certificate has expired.
+func ServingCertificate(provider dynamiccert.Private, clock clock.PassiveClock) healthz.HealthChecker {
+	return healthz.NamedCheck("serving-certificate", func(_ *http.Request) error {
+		certPEM, _ := provider.CurrentCertKeyContent()
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			return errNoServingCertificate
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("could not parse serving certificate: %w", err)
+		}
+		if now := clock.Now(); now.After(cert.NotAfter) {
+			return fmt.Errorf("serving certificate expired at %s", cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		return nil
+	})
+}
+
+// Storage returns a check which fails when the Secrets, in which the sessions are stored, cannot be read.
+func Storage(secrets corev1client.SecretInterface) healthz.HealthChecker {
+	return healthz.NamedCheck("storage", func(r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), storageCheckTimeout)
+		defer cancel()
+
+		if _, err := secrets.List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+			return fmt.Errorf("could not list secrets: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/dynamiccert"
+)
+
+type fakeFactory map[reflect.Type]bool
+
+func (f fakeFactory) WaitForCacheSync(_ <-chan struct{}) map[reflect.Type]bool {
+	return f
+}
+
+func TestInformerSync(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		factories []CacheSyncWaiter
+		wantErr   string
+	}{
+		{
+			name: "no factories",
+		},
+		{
+			name: "all synced",
+			factories: []CacheSyncWaiter{
+				fakeFactory{reflect.TypeOf(&corev1.Secret{}): true},
+				fakeFactory{reflect.TypeOf(&corev1.ConfigMap{}): true},
+			},
+		},
+		{
+			name: "some not synced",
+			factories: []CacheSyncWaiter{
+				fakeFactory{reflect.TypeOf(&corev1.Secret{}): false, reflect.TypeOf(&corev1.Pod{}): true},
+				fakeFactory{reflect.TypeOf(&corev1.ConfigMap{}): false},
+			},
+			wantErr: "2 informers have not synced yet: *v1.ConfigMap, *v1.Secret",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			check := InformerSync("some-name", tt.factories...)
+			require.Equal(t, "some-name", check.Name())
+
+			err := check.Check(&http.Request{})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServingCertificate(t *testing.T) {
+	t.Parallel()
+
+	ca, err := certauthority.New("test-ca", time.Hour)
+	require.NoError(t, err)
+	certPEM, keyPEM, err := ca.IssueServerCertPEM([]string{"example.com"}, nil, time.Hour)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		certPEM []byte
+		keyPEM  []byte
+		now     time.Time
+		wantErr string
+	}{
+		{
+			name:    "no certificate",
+			now:     now,
+			wantErr: "no serving certificate has been loaded yet",
+		},
+		{
+			name:    "valid certificate",
+			certPEM: certPEM,
+			keyPEM:  keyPEM,
+			now:     now,
+		},
+		{
+			name:    "expired certificate",
+			certPEM: certPEM,
+			keyPEM:  keyPEM,
+			now:     now.Add(2 * time.Hour),
+			wantErr: "serving certificate expired at ",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := dynamiccert.NewServingCert("test-serving-cert")
+			if tt.certPEM != nil {
+				require.NoError(t, provider.SetCertKeyContent(tt.certPEM, tt.keyPEM))
+			}
+
+			check := ServingCertificate(provider, clocktesting.NewFakePassiveClock(tt.now))
+			require.Equal(t, "serving-certificate", check.Name())
+
+			err := check.Check(&http.Request{})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		listErr error
+		wantErr string
+	}{
+		{
+			name: "secrets can be listed",
+		},
+		{
+			name:    "secrets cannot be listed",
+			listErr: errors.New("some list error"),
+			wantErr: "could not list secrets: some list error",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kubeClient := kubefake.NewSimpleClientset()
+			kubeClient.PrependReactor("list", "secrets", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+				if tt.listErr != nil {
+					return true, nil, tt.listErr
+				}
+				return false, nil, nil
+			})
+
+			check := Storage(kubeClient.CoreV1().Secrets("some-namespace"))
+			require.Equal(t, "storage", check.Name())
+
+			req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			err = check.Check(req)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clientip implements an HTTP middleware which learns the IP addresses of the clients of a server that is
+// behind trusted proxies, such as ingresses and load balancers which do not preserve the addresses of the clients.
+package clientip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultHeader is the header in which the trusted proxies pass on the client IP addresses by default.
+const DefaultHeader = "X-Forwarded-For"
+
+// Wrap returns a handler which replaces the RemoteAddr of the requests from one of the trusted proxies with the
+// address of the client which the proxies passed on in the header, before passing the requests to the next handler.
+// The header may list several addresses, separated by commas, which were each added by one proxy. The client is the
+// last address which is not a trusted proxy itself, since any address before it could have been sent by the client.
+// Requests from other peers are passed on unchanged, so that clients cannot choose their own addresses. When header
+// is empty, DefaultHeader is used. When there are no trusted proxies, next is returned.
+func Wrap(next http.Handler, trustedProxies []*net.IPNet, header string) http.Handler {
+	if len(trustedProxies) == 0 {
+		return next
+	}
+	if header == "" {
+		header = DefaultHeader
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clientIP := forwardedClientIP(r, trustedProxies, header); clientIP != nil {
+			r = r.WithContext(r.Context()) // shallow copy, so that the request of the caller is not changed
+			r.RemoteAddr = net.JoinHostPort(clientIP.String(), "0")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClientIP returns the address of the client which was passed on by the trusted proxies, or nil when the
+// request does not come from a trusted proxy or does not have a valid header.
+func forwardedClientIP(r *http.Request, trustedProxies []*net.IPNet, header string) net.IP {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || !trusted(net.ParseIP(peer), trustedProxies) {
+		return nil
+	}
+
+	var addresses []string
+	for _, value := range r.Header.Values(header) {
+		addresses = append(addresses, strings.Split(value, ",")...)
+	}
+	for i := len(addresses) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(addresses[i]))
+		if ip == nil {
+			return nil
+		}
+		if i == 0 || !trusted(ip, trustedProxies) {
+			return ip
+		}
+	}
+	return nil
+}
+
+func trusted(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
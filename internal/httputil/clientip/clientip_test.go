@@ -0,0 +1,116 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clientip
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	_, proxyNetwork, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	_, ipv6ProxyNetwork, err := net.ParseCIDR("fd00::/8")
+	require.NoError(t, err)
+	trustedProxies := []*net.IPNet{proxyNetwork, ipv6ProxyNetwork}
+
+	tests := []struct {
+		name           string
+		trustedProxies []*net.IPNet
+		header         string
+		remoteAddr     string
+		requestHeaders map[string][]string
+		wantRemoteAddr string
+	}{
+		{
+			name:           "no trusted proxies",
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			wantRemoteAddr: "10.1.2.3:1234",
+		},
+		{
+			name:           "request from a trusted proxy",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			wantRemoteAddr: "203.0.113.7:0",
+		},
+		{
+			name:           "request from a peer which is not a trusted proxy",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "192.0.2.1:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			wantRemoteAddr: "192.0.2.1:1234",
+		},
+		{
+			name:           "request from a trusted proxy without the header",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.1.2.3:1234",
+			wantRemoteAddr: "10.1.2.3:1234",
+		},
+		{
+			name:           "the addresses sent by the client and the addresses of the trusted proxies are skipped",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"198.51.100.1, 203.0.113.7", "10.4.5.6"}},
+			wantRemoteAddr: "203.0.113.7:0",
+		},
+		{
+			name:           "every address is a trusted proxy",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"10.7.8.9, 10.4.5.6"}},
+			wantRemoteAddr: "10.7.8.9:0",
+		},
+		{
+			name:           "invalid address in the header",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"203.0.113.7, not-an-ip"}},
+			wantRemoteAddr: "10.1.2.3:1234",
+		},
+		{
+			name:           "ipv6 addresses",
+			trustedProxies: trustedProxies,
+			remoteAddr:     "[fd00::1]:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"2001:db8::7"}},
+			wantRemoteAddr: "[2001:db8::7]:0",
+		},
+		{
+			name:           "configured header",
+			trustedProxies: trustedProxies,
+			header:         "X-Real-IP",
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string][]string{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-Ip": {"203.0.113.7"}},
+			wantRemoteAddr: "203.0.113.7:0",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotRemoteAddr string
+			handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRemoteAddr = r.RemoteAddr
+			}), tt.trustedProxies, tt.header)
+
+			r := httptest.NewRequest(http.MethodGet, "/some/path", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for key, values := range tt.requestHeaders {
+				for _, value := range values {
+					r.Header.Add(key, value)
+				}
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			require.Equal(t, tt.wantRemoteAddr, gotRemoteAddr)
+			require.Equal(t, tt.remoteAddr, r.RemoteAddr, "the request of the caller should not be changed")
+		})
+	}
+}
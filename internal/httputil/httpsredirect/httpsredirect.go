@@ -0,0 +1,40 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httpsredirect implements an HTTP handler which redirects every request to the same URL over HTTPS.
+package httpsredirect
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// New returns a handler which permanently redirects every request to the same host and path on the default HTTPS
+// port. It does not serve anything itself, so it is safe to expose on a plaintext port. The 308 status code makes
+// clients repeat the request with the same method and body.
+func New() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+			if ip := net.ParseIP(h); ip != nil && ip.To4() == nil {
+				host = "[" + h + "]"
+			}
+		}
+		if host == "" {
+			http.Error(w, "missing Host header", http.StatusBadRequest)
+			return
+		}
+
+		target := url.URL{
+			Scheme:   "https",
+			Host:     host,
+			Path:     r.URL.Path,
+			RawPath:  r.URL.RawPath,
+			RawQuery: r.URL.RawQuery,
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
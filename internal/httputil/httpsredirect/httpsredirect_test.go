@@ -0,0 +1,69 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsredirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		host         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "host without port",
+			method:       http.MethodGet,
+			target:       "/issuer/.well-known/openid-configuration",
+			host:         "issuer.example.com",
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://issuer.example.com/issuer/.well-known/openid-configuration",
+		},
+		{
+			name:         "host with the plaintext port and a query",
+			method:       http.MethodPost,
+			target:       "/issuer/oauth2/authorize?client_id=pinniped-cli&state=abc",
+			host:         "issuer.example.com:8080",
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://issuer.example.com/issuer/oauth2/authorize?client_id=pinniped-cli&state=abc",
+		},
+		{
+			name:         "ipv6 host with port",
+			method:       http.MethodGet,
+			target:       "/issuer",
+			host:         "[2001:db8::1]:8080",
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://[2001:db8::1]/issuer",
+		},
+		{
+			name:       "missing host",
+			method:     http.MethodGet,
+			target:     "/issuer",
+			host:       "",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Host = tt.host
+			rsp := httptest.NewRecorder()
+			New().ServeHTTP(rsp, req)
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			require.Equal(t, tt.wantLocation, rsp.Header().Get("Location"))
+		})
+	}
+}
@@ -1,13 +1,27 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package securityheader implements an HTTP middleware for setting security-related response headers.
 package securityheader
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// Options configures the headers which WrapWithOptions sets on every response.
+type Options struct {
+	// HSTSMaxAge enables the Strict-Transport-Security header of the responses to HTTPS requests when it is positive.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubDomains bool
+	HSTSPreload           bool
+
+	// ReferrerPolicy is the Referrer-Policy of every response. Defaults to "no-referrer".
+	ReferrerPolicy string
+}
+
 // Wrap the provided http.Handler so it sets appropriate security-related response headers.
 func Wrap(wrapped http.Handler) http.Handler {
 	return WrapWithCustomCSP(wrapped, "default-src 'none'; frame-ancestors 'none'")
@@ -28,3 +42,68 @@ func WrapWithCustomCSP(wrapped http.Handler, cspHeader string) http.Handler {
 		wrapped.ServeHTTP(w, r)
 	})
 }
+
+// WrapWithOptions wraps the provided http.Handler so that every response has the security-related headers, even the
+// responses of handlers which are not wrapped by Wrap or WrapWithCustomCSP themselves. Handlers which set their own
+// Content-Security-Policy keep it, but the Referrer-Policy and Strict-Transport-Security headers of the Options
+// always win, so that they are consistent across all responses.
+func WrapWithOptions(wrapped http.Handler, opts Options) http.Handler {
+	referrerPolicy := opts.ReferrerPolicy
+	if referrerPolicy == "" {
+		referrerPolicy = "no-referrer"
+	}
+
+	var hsts string
+	if opts.HSTSMaxAge > 0 {
+		directives := []string{fmt.Sprintf("max-age=%d", int64(opts.HSTSMaxAge.Seconds()))}
+		if opts.HSTSIncludeSubDomains {
+			directives = append(directives, "includeSubDomains")
+		}
+		if opts.HSTSPreload {
+			directives = append(directives, "preload")
+		}
+		hsts = strings.Join(directives, "; ")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		// The wrapped handler may replace these defaults.
+		h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		h.Set("X-Content-Type-Options", "nosniff")
+
+		fw := &finalHeadersWriter{ResponseWriter: w, setFinalHeaders: func(h http.Header) {
+			h.Set("Referrer-Policy", referrerPolicy)
+			// Browsers ignore the header on plain HTTP responses.
+			if hsts != "" && r.TLS != nil {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+		}}
+		wrapped.ServeHTTP(fw, r)
+		if !fw.wroteHeader { // the wrapped handler wrote an empty response
+			fw.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// finalHeadersWriter sets some headers right before the wrapped handler writes the response, so that they override
+// the headers which the wrapped handler has set.
+type finalHeadersWriter struct {
+	http.ResponseWriter
+	setFinalHeaders func(http.Header)
+	wroteHeader     bool
+}
+
+func (w *finalHeadersWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.setFinalHeaders(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *finalHeadersWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
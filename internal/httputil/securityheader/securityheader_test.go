@@ -1,10 +1,11 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package securityheader
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -84,3 +85,82 @@ func TestWrap(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapWithOptions(t *testing.T) {
+	writeBody := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	})
+
+	for _, tt := range []struct {
+		name          string
+		handler       http.Handler
+		opts          Options
+		https         bool
+		expectHeaders http.Header
+	}{
+		{
+			name:    "defaults",
+			handler: writeBody,
+			https:   true,
+			expectHeaders: http.Header{
+				"Content-Security-Policy": []string{"default-src 'none'; frame-ancestors 'none'"},
+				"Referrer-Policy":         []string{"no-referrer"},
+				"X-Content-Type-Options":  []string{"nosniff"},
+			},
+		},
+		{
+			name:    "options override the headers of the wrapped handler except the CSP",
+			handler: WrapWithCustomCSP(writeBody, "my-custom-csp-header"),
+			opts: Options{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				HSTSIncludeSubDomains: true,
+				HSTSPreload:           true,
+				ReferrerPolicy:        "strict-origin",
+			},
+			https: true,
+			expectHeaders: http.Header{
+				"Content-Security-Policy":   []string{"my-custom-csp-header"},
+				"Referrer-Policy":           []string{"strict-origin"},
+				"Strict-Transport-Security": []string{"max-age=31536000; includeSubDomains; preload"},
+				"X-Content-Type-Options":    []string{"nosniff"},
+				"X-Frame-Options":           []string{"DENY"},
+			},
+		},
+		{
+			name:    "hsts is not sent over plain http",
+			handler: writeBody,
+			opts:    Options{HSTSMaxAge: time.Hour},
+			expectHeaders: http.Header{
+				"Referrer-Policy":           []string{"no-referrer"},
+				"Strict-Transport-Security": nil,
+			},
+		},
+		{
+			name: "empty response",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Referrer-Policy", "unsafe-url")
+			}),
+			opts:  Options{HSTSMaxAge: time.Hour},
+			https: true,
+			expectHeaders: http.Header{
+				"Referrer-Policy":           []string{"no-referrer"},
+				"Strict-Transport-Security": []string{"max-age=3600"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/some/path", nil)
+			if tt.https {
+				req.TLS = &tls.ConnectionState{}
+			}
+			rsp := httptest.NewRecorder()
+			WrapWithOptions(tt.handler, tt.opts).ServeHTTP(rsp, req)
+			require.Equal(t, http.StatusOK, rsp.Code)
+
+			for key, values := range tt.expectHeaders {
+				assert.Equalf(t, values, rsp.Header().Values(key), "unexpected values for header %s", key)
+			}
+		})
+	}
+}
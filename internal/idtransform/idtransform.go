@@ -0,0 +1,209 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package idtransform applies the identity transformations and policies of a FederationDomain to the identities of
+// the users who log in using any upstream IDP. The transformations are CEL expressions, which are evaluated in order.
+// See https://github.com/google/cel-spec for the expression language.
+package idtransform
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// UsernameVariableName is the name of the variable which holds the username in the expressions.
+	UsernameVariableName = "username"
+
+	// GroupsVariableName is the name of the variable which holds the groups in the expressions.
+	GroupsVariableName = "groups"
+
+	// DefaultPolicyRejectedAuthMessage is the message which is returned to the user when a policy expression
+	// rejects their authentication and the policy does not have a message.
+	DefaultPolicyRejectedAuthMessage = "authentication was rejected by a configured policy"
+
+	// costLimit limits how much work an expression may do each time that it is evaluated, to avoid having
+	// an expression consume too many resources. It is the same limit which Kubernetes uses for its CEL expressions.
+	costLimit = 1000000
+
+	// interruptCheckFrequency is how many iterations of a comprehension are evaluated between checks of
+	// whether the context has been cancelled.
+	interruptCheckFrequency = 100
+)
+
+// ExpressionType determines what an Expression computes.
+type ExpressionType string
+
+const (
+	// UsernameTransformation is an expression which must evaluate to a non-empty string, which becomes the username.
+	UsernameTransformation ExpressionType = "username/v1"
+
+	// GroupsTransformation is an expression which must evaluate to a list of strings, which become the groups.
+	GroupsTransformation ExpressionType = "groups/v1"
+
+	// PolicyTransformation is an expression which must evaluate to a boolean. When it is false, the authentication
+	// is rejected and the remaining expressions are not evaluated.
+	PolicyTransformation ExpressionType = "policy/v1"
+)
+
+// Expression is one of the expressions of a TransformationPipeline.
+type Expression struct {
+	Type       ExpressionType
+	Expression string
+
+	// Message is returned to the user when a PolicyTransformation rejects their authentication.
+	// It is ignored for the other types of expressions.
+	Message string
+}
+
+// TransformationResult is the identity which results from evaluating a TransformationPipeline.
+type TransformationResult struct {
+	Username                      string
+	Groups                        []string
+	AuthenticationAllowed         bool
+	RejectedAuthenticationMessage string
+}
+
+// TransformationPipeline is a compiled list of expressions which are evaluated in order. Each expression sees the
+// username and groups which resulted from the previous expressions. A nil *TransformationPipeline is valid and
+// does not change any identities.
+type TransformationPipeline struct {
+	transforms []compiledExpression
+}
+
+type compiledExpression struct {
+	Expression
+	program cel.Program
+}
+
+// NewTransformationPipeline compiles the expressions. The error describes every expression which failed to compile,
+// so that all of them can be fixed at once.
+func NewTransformationPipeline(expressions []Expression) (*TransformationPipeline, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(UsernameVariableName, cel.StringType),
+		cel.Variable(GroupsVariableName, cel.ListType(cel.StringType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create CEL environment: %w", err)
+	}
+
+	p := &TransformationPipeline{}
+	var errs []error
+	for i, e := range expressions {
+		program, err := compile(env, e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("expressions[%d]: %w", i, err))
+			continue
+		}
+		p.transforms = append(p.transforms, compiledExpression{Expression: e, program: program})
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return p, nil
+}
+
+func compile(env *cel.Env, e Expression) (cel.Program, error) {
+	var wantType *cel.Type
+	switch e.Type {
+	case UsernameTransformation:
+		wantType = cel.StringType
+	case GroupsTransformation:
+		wantType = cel.ListType(cel.StringType)
+	case PolicyTransformation:
+		wantType = cel.BoolType
+	default:
+		return nil, fmt.Errorf("unsupported expression type %q", e.Type)
+	}
+
+	if e.Expression == "" {
+		return nil, fmt.Errorf("%s expression must not be empty", e.Type)
+	}
+	ast, issues := env.Compile(e.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("could not compile %s expression: %w", e.Type, issues.Err())
+	}
+	if !wantType.IsAssignableType(ast.OutputType()) {
+		return nil, fmt.Errorf("%s expression must evaluate to type %s, but evaluates to type %s", e.Type, wantType, ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit), cel.InterruptCheckFrequency(interruptCheckFrequency))
+	if err != nil {
+		return nil, fmt.Errorf("could not create program for %s expression: %w", e.Type, err)
+	}
+	return program, nil
+}
+
+// HasTransforms returns true when the pipeline has any expressions.
+func (p *TransformationPipeline) HasTransforms() bool {
+	return p != nil && len(p.transforms) > 0
+}
+
+// Evaluate evaluates the expressions in order, starting with the given username and groups. Evaluation stops at
+// the first policy expression which rejects the authentication. The resulting groups do not contain any empty or
+// duplicate group names, and are sorted.
+func (p *TransformationPipeline) Evaluate(ctx context.Context, username string, groups []string) (*TransformationResult, error) {
+	result := &TransformationResult{
+		Username:              username,
+		Groups:                groups,
+		AuthenticationAllowed: true,
+	}
+	if !p.HasTransforms() {
+		return result, nil
+	}
+
+	result.Groups = normalizeGroups(groups)
+	for i, t := range p.transforms {
+		value, _, err := t.program.ContextEval(ctx, map[string]interface{}{
+			UsernameVariableName: result.Username,
+			GroupsVariableName:   result.Groups,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate expressions[%d]: %w", i, err)
+		}
+
+		switch t.Type {
+		case UsernameTransformation:
+			newUsername, ok := value.Value().(string)
+			if !ok {
+				return nil, fmt.Errorf("expressions[%d] must evaluate to a string, but evaluated to type %s", i, value.Type().TypeName())
+			}
+			if newUsername == "" {
+				return nil, fmt.Errorf("expressions[%d] evaluated to an empty username", i)
+			}
+			result.Username = newUsername
+		case GroupsTransformation:
+			native, err := value.ConvertToNative(reflect.TypeOf([]string{}))
+			if err != nil {
+				return nil, fmt.Errorf("expressions[%d] must evaluate to a list of strings, but evaluated to type %s", i, value.Type().TypeName())
+			}
+			result.Groups = normalizeGroups(native.([]string))
+		case PolicyTransformation:
+			allowed, ok := value.Value().(bool)
+			if !ok {
+				return nil, fmt.Errorf("expressions[%d] must evaluate to a bool, but evaluated to type %s", i, value.Type().TypeName())
+			}
+			if !allowed {
+				result.AuthenticationAllowed = false
+				result.RejectedAuthenticationMessage = t.Message
+				if result.RejectedAuthenticationMessage == "" {
+					result.RejectedAuthenticationMessage = DefaultPolicyRejectedAuthMessage
+				}
+				return result, nil
+			}
+		}
+	}
+	return result, nil
+}
+
+func normalizeGroups(groups []string) []string {
+	normalized := sets.NewString(groups...)
+	normalized.Delete("")
+	return normalized.List()
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package idtransform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransformationPipeline(t *testing.T) {
+	tests := []struct {
+		name        string
+		expressions []Expression
+		wantErr     string
+	}{
+		{
+			name: "no expressions",
+		},
+		{
+			name: "valid expressions",
+			expressions: []Expression{
+				{Type: UsernameTransformation, Expression: `"ldap:" + username`},
+				{Type: GroupsTransformation, Expression: `groups.filter(g, g.startsWith("k8s-"))`},
+				{Type: PolicyTransformation, Expression: `!("contractors" in groups)`, Message: "contractors may not log in"},
+			},
+		},
+		{
+			name:        "unsupported type",
+			expressions: []Expression{{Type: "email/v1", Expression: `username`}},
+			wantErr:     `expressions[0]: unsupported expression type "email/v1"`,
+		},
+		{
+			name:        "empty expression",
+			expressions: []Expression{{Type: UsernameTransformation}},
+			wantErr:     "expressions[0]: username/v1 expression must not be empty",
+		},
+		{
+			name:        "syntax error",
+			expressions: []Expression{{Type: UsernameTransformation, Expression: `username +`}},
+			wantErr:     "expressions[0]: could not compile username/v1 expression: ",
+		},
+		{
+			name:        "unknown variable",
+			expressions: []Expression{{Type: UsernameTransformation, Expression: `email`}},
+			wantErr:     "expressions[0]: could not compile username/v1 expression: ",
+		},
+		{
+			name:        "username expression of the wrong type",
+			expressions: []Expression{{Type: UsernameTransformation, Expression: `groups`}},
+			wantErr:     "expressions[0]: username/v1 expression must evaluate to type string, but evaluates to type list(string)",
+		},
+		{
+			name:        "groups expression of the wrong type",
+			expressions: []Expression{{Type: GroupsTransformation, Expression: `username`}},
+			wantErr:     "expressions[0]: groups/v1 expression must evaluate to type list(string), but evaluates to type string",
+		},
+		{
+			name:        "policy expression of the wrong type",
+			expressions: []Expression{{Type: PolicyTransformation, Expression: `username`}},
+			wantErr:     "expressions[0]: policy/v1 expression must evaluate to type bool, but evaluates to type string",
+		},
+		{
+			name: "reports every invalid expression",
+			expressions: []Expression{
+				{Type: UsernameTransformation, Expression: `username +`},
+				{Type: PolicyTransformation, Expression: `true`},
+				{Type: GroupsTransformation, Expression: `username`},
+			},
+			wantErr: "[expressions[0]: could not compile username/v1 expression: ",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewTransformationPipeline(tt.expressions)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, p)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expressions) > 0, p.HasTransforms())
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		expressions []Expression
+		username    string
+		groups      []string
+		want        *TransformationResult
+		wantErr     string
+	}{
+		{
+			name:     "no expressions leaves the identity unchanged",
+			username: "ryan",
+			groups:   []string{"b", "a", "b"},
+			want: &TransformationResult{
+				Username:              "ryan",
+				Groups:                []string{"b", "a", "b"},
+				AuthenticationAllowed: true,
+			},
+		},
+		{
+			name: "expressions are evaluated in order",
+			expressions: []Expression{
+				{Type: UsernameTransformation, Expression: `"ldap:" + username`},
+				{Type: GroupsTransformation, Expression: `groups.map(g, username + ":" + g)`},
+				{Type: PolicyTransformation, Expression: `username.startsWith("ldap:")`},
+			},
+			username: "ryan",
+			groups:   []string{"b", "a"},
+			want: &TransformationResult{
+				Username:              "ldap:ryan",
+				Groups:                []string{"ldap:ryan:a", "ldap:ryan:b"},
+				AuthenticationAllowed: true,
+			},
+		},
+		{
+			name: "groups are de-duplicated and sorted and empty groups are removed",
+			expressions: []Expression{
+				{Type: GroupsTransformation, Expression: `groups.map(g, g.lowerAscii())`},
+			},
+			username: "ryan",
+			groups:   []string{"B", "", "a", "b"},
+			want: &TransformationResult{
+				Username:              "ryan",
+				Groups:                []string{"a", "b"},
+				AuthenticationAllowed: true,
+			},
+		},
+		{
+			name: "policy rejects the authentication with its message",
+			expressions: []Expression{
+				{Type: UsernameTransformation, Expression: `"ldap:" + username`},
+				{Type: PolicyTransformation, Expression: `!("contractors" in groups)`, Message: "contractors may not log in"},
+				{Type: UsernameTransformation, Expression: `"not-evaluated"`},
+			},
+			username: "ryan",
+			groups:   []string{"contractors"},
+			want: &TransformationResult{
+				Username:                      "ldap:ryan",
+				Groups:                        []string{"contractors"},
+				AuthenticationAllowed:         false,
+				RejectedAuthenticationMessage: "contractors may not log in",
+			},
+		},
+		{
+			name: "policy rejects the authentication with the default message",
+			expressions: []Expression{
+				{Type: PolicyTransformation, Expression: `username != "ryan"`},
+			},
+			username: "ryan",
+			want: &TransformationResult{
+				Username:                      "ryan",
+				Groups:                        []string{},
+				AuthenticationAllowed:         false,
+				RejectedAuthenticationMessage: DefaultPolicyRejectedAuthMessage,
+			},
+		},
+		{
+			name: "empty username is an error",
+			expressions: []Expression{
+				{Type: UsernameTransformation, Expression: `username.replace("ryan", "")`},
+			},
+			username: "ryan",
+			wantErr:  "expressions[0] evaluated to an empty username",
+		},
+		{
+			name: "runtime error",
+			expressions: []Expression{
+				{Type: UsernameTransformation, Expression: `groups[5]`},
+			},
+			username: "ryan",
+			groups:   []string{"a"},
+			wantErr:  "could not evaluate expressions[0]: ",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewTransformationPipeline(tt.expressions)
+			require.NoError(t, err)
+
+			result, err := p.Evaluate(context.Background(), tt.username, tt.groups)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestNilTransformationPipeline(t *testing.T) {
+	var p *TransformationPipeline
+	require.False(t, p.HasTransforms())
+
+	result, err := p.Evaluate(context.Background(), "ryan", []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, &TransformationResult{Username: "ryan", Groups: []string{"a"}, AuthenticationAllowed: true}, result)
+}
@@ -17,7 +17,10 @@ const defaultCertIssuerErr = constable.Error("failed to issue cert")
 
 type ClientCertIssuer interface {
 	Name() string
-	IssueClientCertPEM(username string, groups []string, ttl time.Duration) (certPEM, keyPEM []byte, err error)
+
+	// IssueClientCertPEM issues a client certificate for the given identity and duration. It also returns the
+	// NotAfter of the certificate, which may be later than requested when the issuer enforces a minimum lifetime.
+	IssueClientCertPEM(username string, groups []string, ttl time.Duration) (certPEM, keyPEM []byte, notAfter time.Time, err error)
 }
 
 var _ ClientCertIssuer = ClientCertIssuers{}
@@ -37,21 +40,21 @@ func (c ClientCertIssuers) Name() string {
 	return strings.Join(names, ",")
 }
 
-func (c ClientCertIssuers) IssueClientCertPEM(username string, groups []string, ttl time.Duration) ([]byte, []byte, error) {
+func (c ClientCertIssuers) IssueClientCertPEM(username string, groups []string, ttl time.Duration) ([]byte, []byte, time.Time, error) {
 	var errs []error
 
 	for _, issuer := range c {
-		certPEM, keyPEM, err := issuer.IssueClientCertPEM(username, groups, ttl)
+		certPEM, keyPEM, notAfter, err := issuer.IssueClientCertPEM(username, groups, ttl)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("%s failed to issue client cert: %w", issuer.Name(), err))
 			continue
 		}
-		return certPEM, keyPEM, nil
+		return certPEM, keyPEM, notAfter, nil
 	}
 
 	if err := errors.NewAggregate(errs); err != nil {
-		return nil, nil, err
+		return nil, nil, time.Time{}, err
 	}
 
-	return nil, nil, defaultCertIssuerErr
+	return nil, nil, time.Time{}, defaultCertIssuerErr
 }
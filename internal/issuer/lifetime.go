@@ -0,0 +1,52 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package issuer
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultClientCertLifetime is used when the CredentialIssuer does not configure the lifetimes of the client
+// certificates, or when its configuration is invalid.
+var DefaultClientCertLifetime = ClientCertLifetime{TTL: 5 * time.Minute} //nolint:gochecknoglobals
+
+// ClientCertLifetime describes how long the client certificates which are issued by the Concierge are valid.
+type ClientCertLifetime struct {
+	// TTL is how long each client certificate is valid.
+	TTL time.Duration
+
+	// RefreshMargin is how much earlier than the end of its TTL each client certificate is reported to expire,
+	// so that clients get a new certificate before the old one is rejected.
+	RefreshMargin time.Duration
+}
+
+// DynamicClientCertLifetime holds the current ClientCertLifetime, which may be changed at any time by the controller
+// which watches the CredentialIssuer. It is safe for concurrent use.
+type DynamicClientCertLifetime struct {
+	mu       sync.RWMutex
+	lifetime ClientCertLifetime
+}
+
+// NewDynamicClientCertLifetime returns a DynamicClientCertLifetime which holds the DefaultClientCertLifetime.
+func NewDynamicClientCertLifetime() *DynamicClientCertLifetime {
+	return &DynamicClientCertLifetime{lifetime: DefaultClientCertLifetime}
+}
+
+// Get returns the current ClientCertLifetime. A nil DynamicClientCertLifetime returns the DefaultClientCertLifetime.
+func (d *DynamicClientCertLifetime) Get() ClientCertLifetime {
+	if d == nil {
+		return DefaultClientCertLifetime
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lifetime
+}
+
+// Set replaces the current ClientCertLifetime.
+func (d *DynamicClientCertLifetime) Set(lifetime ClientCertLifetime) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lifetime = lifetime
+}
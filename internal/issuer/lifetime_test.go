@@ -0,0 +1,22 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package issuer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicClientCertLifetime(t *testing.T) {
+	var nilLifetime *DynamicClientCertLifetime
+	require.Equal(t, ClientCertLifetime{TTL: 5 * time.Minute}, nilLifetime.Get())
+
+	lifetime := NewDynamicClientCertLifetime()
+	require.Equal(t, ClientCertLifetime{TTL: 5 * time.Minute}, lifetime.Get())
+
+	lifetime.Set(ClientCertLifetime{TTL: time.Hour, RefreshMargin: time.Minute})
+	require.Equal(t, ClientCertLifetime{TTL: time.Hour, RefreshMargin: time.Minute}, lifetime.Get())
+}
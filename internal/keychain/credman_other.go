@@ -0,0 +1,10 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package keychain
+
+func newCredentialManager() (Keychain, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package keychain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// indexAccount is the account of the item which lists the accounts of all the other items of a service.
+const indexAccount = "index"
+
+// Indexed is a Keychain which can also list the accounts of the items which it stored. Not all OS keychains can be
+// searched, so the accounts of each service are recorded in an extra item of that service. Updates of the index are
+// not atomic, so it may miss items which are concurrently written by other processes.
+type Indexed struct {
+	Keychain
+}
+
+// NewIndexed wraps the given Keychain.
+func NewIndexed(kc Keychain) *Indexed {
+	return &Indexed{Keychain: kc}
+}
+
+// Set records the account in the index before storing the secret, so that an item is never stored without being
+// listed.
+func (k *Indexed) Set(service, account, secret string) error {
+	if account == indexAccount {
+		return fmt.Errorf("invalid keychain item name %q", account)
+	}
+	if err := k.updateIndex(service, func(accounts []string) ([]string, bool) {
+		for _, a := range accounts {
+			if a == account {
+				return accounts, false
+			}
+		}
+		return append(accounts, account), true
+	}); err != nil {
+		return err
+	}
+	return k.Keychain.Set(service, account, secret)
+}
+
+// Delete removes the account from the index after deleting its item, including when the item was already gone.
+func (k *Indexed) Delete(service, account string) error {
+	err := k.Keychain.Delete(service, account)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if indexErr := k.updateIndex(service, func(accounts []string) ([]string, bool) {
+		for i, a := range accounts {
+			if a == account {
+				return append(accounts[:i], accounts[i+1:]...), true
+			}
+		}
+		return accounts, false
+	}); indexErr != nil {
+		return indexErr
+	}
+	return err
+}
+
+// Accounts lists the accounts of the items of the given service.
+func (k *Indexed) Accounts(service string) ([]string, error) {
+	secret, err := k.Keychain.Get(service, indexAccount)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var accounts []string
+	if err := json.Unmarshal([]byte(secret), &accounts); err != nil {
+		return nil, fmt.Errorf("could not decode keychain index: %w", err)
+	}
+	return accounts, nil
+}
+
+func (k *Indexed) updateIndex(service string, update func([]string) ([]string, bool)) error {
+	accounts, err := k.Accounts(service)
+	if err != nil {
+		return err
+	}
+	accounts, changed := update(accounts)
+	switch {
+	case !changed:
+		return nil
+	case len(accounts) == 0:
+		if err := k.Keychain.Delete(service, indexAccount); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return nil
+	default:
+		secret, err := json.Marshal(accounts)
+		if err != nil {
+			return err
+		}
+		return k.Keychain.Set(service, indexAccount, string(secret))
+	}
+}
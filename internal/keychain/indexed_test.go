@@ -0,0 +1,80 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package keychain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapKeychain map[string]string
+
+func (m mapKeychain) Get(service, account string) (string, error) {
+	secret, ok := m[service+"/"+account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m mapKeychain) Set(service, account, secret string) error {
+	m[service+"/"+account] = secret
+	return nil
+}
+
+func (m mapKeychain) Delete(service, account string) error {
+	if _, ok := m[service+"/"+account]; !ok {
+		return ErrNotFound
+	}
+	delete(m, service+"/"+account)
+	return nil
+}
+
+type failingKeychain struct{ Keychain }
+
+func (failingKeychain) Set(_, _, _ string) error { return errors.New("some keychain error") }
+
+func TestIndexed(t *testing.T) {
+	t.Parallel()
+
+	m := mapKeychain{}
+	k := NewIndexed(m)
+
+	accounts, err := k.Accounts("some-service")
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	require.NoError(t, k.Set("some-service", "account-1", "secret-1"))
+	require.NoError(t, k.Set("some-service", "account-2", "secret-2"))
+	require.NoError(t, k.Set("some-service", "account-1", "secret-1-updated"))
+	require.NoError(t, k.Set("other-service", "account-3", "secret-3"))
+	require.EqualError(t, k.Set("some-service", "index", "secret"), `invalid keychain item name "index"`)
+
+	accounts, err = k.Accounts("some-service")
+	require.NoError(t, err)
+	require.Equal(t, []string{"account-1", "account-2"}, accounts)
+	secret, err := k.Get("some-service", "account-1")
+	require.NoError(t, err)
+	require.Equal(t, "secret-1-updated", secret)
+
+	// Items which were already deleted by someone else are also removed from the index.
+	require.NoError(t, m.Delete("some-service", "account-2"))
+	require.ErrorIs(t, k.Delete("some-service", "account-2"), ErrNotFound)
+	accounts, err = k.Accounts("some-service")
+	require.NoError(t, err)
+	require.Equal(t, []string{"account-1"}, accounts)
+
+	// The index is deleted along with the last item.
+	require.NoError(t, k.Delete("some-service", "account-1"))
+	require.Equal(t, mapKeychain{"other-service/account-3": "secret-3", "other-service/index": `["account-3"]`}, m)
+
+	m["some-service/index"] = "invalid"
+	_, err = k.Accounts("some-service")
+	require.EqualError(t, err, "could not decode keychain index: invalid character 'i' looking for beginning of value")
+
+	// Nothing is stored when the index cannot be updated.
+	require.EqualError(t, NewIndexed(failingKeychain{mapKeychain{}}).Set("some-service", "account-1", "secret-1"), "some keychain error")
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package keychain stores secrets in the keychain of the operating system, i.e. the macOS Keychain, the Windows
+// Credential Manager, or a Secret Service such as GNOME Keyring on Linux (using libsecret).
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+var (
+	// ErrNotFound is returned by Keychain.Get and Keychain.Delete when there is no secret for the service and account.
+	ErrNotFound = errors.New("secret not found in keychain")
+
+	// ErrUnsupported is returned by New when there is no supported keychain on this system.
+	ErrUnsupported = errors.New("no supported keychain is available on this system")
+)
+
+// Keychain stores secrets, each of which is identified by a service and an account.
+type Keychain interface {
+	// Get returns the secret of the service and account, or ErrNotFound.
+	Get(service, account string) (string, error)
+
+	// Set creates or replaces the secret of the service and account.
+	Set(service, account, secret string) error
+
+	// Delete deletes the secret of the service and account, or returns ErrNotFound.
+	Delete(service, account string) error
+}
+
+// New returns the keychain of the operating system, or ErrUnsupported when there is none. On macOS and Linux, this
+// requires the security or secret-tool command, respectively.
+func New() (Keychain, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		path, err := exec.LookPath("security")
+		if err != nil {
+			return nil, ErrUnsupported
+		}
+		return &macOSKeychain{run: execRunner(path)}, nil
+	case "windows":
+		return newCredentialManager()
+	default:
+		path, err := exec.LookPath("secret-tool")
+		if err != nil {
+			return nil, ErrUnsupported
+		}
+		return &secretService{run: execRunner(path)}, nil
+	}
+}
+
+// commandResult is the outcome of running a command which exited, successfully or not.
+type commandResult struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// runner runs a command with the given stdin and arguments. It only returns an error when the command could not run.
+type runner func(stdin string, args ...string) (*commandResult, error)
+
+func execRunner(path string) runner {
+	return func(stdin string, args ...string) (*commandResult, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(path, args...) //nolint:gosec // the path is one of our known keychain commands
+		cmd.Stdin = bytes.NewBufferString(stdin)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		return &commandResult{stdout: stdout.String(), stderr: stderr.String(), exitCode: cmd.ProcessState.ExitCode()}, nil
+	}
+}
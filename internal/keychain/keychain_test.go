@@ -0,0 +1,233 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package keychain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCall struct {
+	stdin string
+	args  []string
+}
+
+func fakeRunner(t *testing.T, result *commandResult, err error, calls *[]fakeCall) runner {
+	t.Helper()
+	return func(stdin string, args ...string) (*commandResult, error) {
+		*calls = append(*calls, fakeCall{stdin: stdin, args: args})
+		return result, err
+	}
+}
+
+func TestMacOSKeychain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		operation func(k Keychain) (string, error)
+		result    *commandResult
+		runErr    error
+		want      string
+		wantErr   string
+		wantCalls []fakeCall
+	}{
+		{
+			name:      "get encoded secret",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{stdout: "pinniped-base64:c29tZQpzZWNyZXQ=\n"},
+			want:      "some\nsecret",
+			wantCalls: []fakeCall{{args: []string{"find-generic-password", "-s", "some-service", "-a", "some-account", "-w"}}},
+		},
+		{
+			name:      "get plain secret",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{stdout: "some-secret\n"},
+			want:      "some-secret",
+			wantCalls: []fakeCall{{args: []string{"find-generic-password", "-s", "some-service", "-a", "some-account", "-w"}}},
+		},
+		{
+			name:      "get missing secret",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{exitCode: 44, stderr: "security: SecKeychainSearchCopyNext: The specified item could not be found in the keychain.\n"},
+			wantErr:   ErrNotFound.Error(),
+			wantCalls: []fakeCall{{args: []string{"find-generic-password", "-s", "some-service", "-a", "some-account", "-w"}}},
+		},
+		{
+			name:      "get fails",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{exitCode: 51, stderr: "some error\n"},
+			wantErr:   "security exited with code 51: some error",
+			wantCalls: []fakeCall{{args: []string{"find-generic-password", "-s", "some-service", "-a", "some-account", "-w"}}},
+		},
+		{
+			name:      "get cannot run",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			runErr:    errors.New("some run error"),
+			wantErr:   "could not run security: some run error",
+			wantCalls: []fakeCall{{args: []string{"find-generic-password", "-s", "some-service", "-a", "some-account", "-w"}}},
+		},
+		{
+			name: "set",
+			operation: func(k Keychain) (string, error) {
+				return "", k.Set("some-service", "some-account", "some\nsecret")
+			},
+			result: &commandResult{},
+			wantCalls: []fakeCall{{
+				stdin: "add-generic-password -U -s 'some-service' -a 'some-account' -w 'pinniped-base64:c29tZQpzZWNyZXQ='\n",
+				args:  []string{"-i"},
+			}},
+		},
+		{
+			name: "set fails",
+			operation: func(k Keychain) (string, error) {
+				return "", k.Set("some-service", "some-account", "some-secret")
+			},
+			result:  &commandResult{stderr: "some error\n"},
+			wantErr: "security exited with code 0: some error",
+			wantCalls: []fakeCall{{
+				stdin: "add-generic-password -U -s 'some-service' -a 'some-account' -w 'pinniped-base64:c29tZS1zZWNyZXQ='\n",
+				args:  []string{"-i"},
+			}},
+		},
+		{
+			name: "set with invalid account",
+			operation: func(k Keychain) (string, error) {
+				return "", k.Set("some-service", "some'account", "some-secret")
+			},
+			wantErr: `invalid keychain item name "some'account"`,
+		},
+		{
+			name:      "delete",
+			operation: func(k Keychain) (string, error) { return "", k.Delete("some-service", "some-account") },
+			result:    &commandResult{},
+			wantCalls: []fakeCall{{args: []string{"delete-generic-password", "-s", "some-service", "-a", "some-account"}}},
+		},
+		{
+			name:      "delete missing secret",
+			operation: func(k Keychain) (string, error) { return "", k.Delete("some-service", "some-account") },
+			result:    &commandResult{exitCode: 44},
+			wantErr:   ErrNotFound.Error(),
+			wantCalls: []fakeCall{{args: []string{"delete-generic-password", "-s", "some-service", "-a", "some-account"}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls []fakeCall
+			got, err := tt.operation(&macOSKeychain{run: fakeRunner(t, tt.result, tt.runErr, &calls)})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestSecretService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		operation func(k Keychain) (string, error)
+		result    *commandResult
+		runErr    error
+		want      string
+		wantErr   string
+		wantCalls []fakeCall
+	}{
+		{
+			name:      "get",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{stdout: "some\nsecret"},
+			want:      "some\nsecret",
+			wantCalls: []fakeCall{{args: []string{"lookup", "service", "some-service", "account", "some-account"}}},
+		},
+		{
+			name:      "get missing secret",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{exitCode: 1},
+			wantErr:   ErrNotFound.Error(),
+			wantCalls: []fakeCall{{args: []string{"lookup", "service", "some-service", "account", "some-account"}}},
+		},
+		{
+			name:      "get fails",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			result:    &commandResult{exitCode: 1, stderr: "secret-tool: Cannot autolaunch D-Bus without X11 $DISPLAY\n"},
+			wantErr:   "secret-tool exited with code 1: secret-tool: Cannot autolaunch D-Bus without X11 $DISPLAY",
+			wantCalls: []fakeCall{{args: []string{"lookup", "service", "some-service", "account", "some-account"}}},
+		},
+		{
+			name:      "get cannot run",
+			operation: func(k Keychain) (string, error) { return k.Get("some-service", "some-account") },
+			runErr:    errors.New("some run error"),
+			wantErr:   "could not run secret-tool: some run error",
+			wantCalls: []fakeCall{{args: []string{"lookup", "service", "some-service", "account", "some-account"}}},
+		},
+		{
+			name: "set",
+			operation: func(k Keychain) (string, error) {
+				return "", k.Set("some-service", "some-account", "some-secret")
+			},
+			result: &commandResult{},
+			wantCalls: []fakeCall{{
+				stdin: "some-secret",
+				args:  []string{"store", "--label=Pinniped: some-service", "service", "some-service", "account", "some-account"},
+			}},
+		},
+		{
+			name: "set fails",
+			operation: func(k Keychain) (string, error) {
+				return "", k.Set("some-service", "some-account", "some-secret")
+			},
+			result:  &commandResult{exitCode: 1, stderr: "some error"},
+			wantErr: "secret-tool exited with code 1: some error",
+			wantCalls: []fakeCall{{
+				stdin: "some-secret",
+				args:  []string{"store", "--label=Pinniped: some-service", "service", "some-service", "account", "some-account"},
+			}},
+		},
+		{
+			name:      "delete missing secret",
+			operation: func(k Keychain) (string, error) { return "", k.Delete("some-service", "some-account") },
+			result:    &commandResult{exitCode: 1},
+			wantErr:   ErrNotFound.Error(),
+			wantCalls: []fakeCall{{args: []string{"lookup", "service", "some-service", "account", "some-account"}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls []fakeCall
+			got, err := tt.operation(&secretService{run: fakeRunner(t, tt.result, tt.runErr, &calls)})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	t.Parallel()
+
+	result, err := execRunner("/bin/sh")("some-input", "-c", `cat; echo some-error >&2; exit 3`)
+	require.NoError(t, err)
+	require.Equal(t, &commandResult{stdout: "some-input", stderr: "some-error\n", exitCode: 3}, result)
+
+	_, err = execRunner("/does/not/exist")("")
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package keychain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	// macOSErrItemNotFound is the exit code of the security command when the item does not exist.
+	macOSErrItemNotFound = 44
+
+	// macOSEncodingPrefix marks the secrets which are base64 encoded. The security command prints secrets which are not
+	// plain ASCII in hex, so all secrets are stored base64 encoded.
+	macOSEncodingPrefix = "pinniped-base64:"
+)
+
+// macOSKeychain stores secrets as generic passwords in the default keychain of the user, using the security command.
+type macOSKeychain struct {
+	run runner
+}
+
+var _ Keychain = &macOSKeychain{}
+
+func (k *macOSKeychain) Get(service, account string) (string, error) {
+	result, err := k.run("", "find-generic-password", "-s", service, "-a", account, "-w")
+	if err != nil {
+		return "", fmt.Errorf("could not run security: %w", err)
+	}
+	if result.exitCode == macOSErrItemNotFound {
+		return "", ErrNotFound
+	}
+	if result.exitCode != 0 {
+		return "", commandError("security", result)
+	}
+
+	secret := strings.TrimSuffix(result.stdout, "\n")
+	if !strings.HasPrefix(secret, macOSEncodingPrefix) {
+		return secret, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, macOSEncodingPrefix))
+	if err != nil {
+		return "", fmt.Errorf("could not decode secret: %w", err)
+	}
+	return string(decoded), nil
+}
+
+func (k *macOSKeychain) Set(service, account, secret string) error {
+	if err := validateMacOSArg(service); err != nil {
+		return err
+	}
+	if err := validateMacOSArg(account); err != nil {
+		return err
+	}
+
+	// Pass the secret on stdin using the interactive mode of the security command, so that it does not appear in the
+	// arguments of the process, where other users could see it.
+	encoded := macOSEncodingPrefix + base64.StdEncoding.EncodeToString([]byte(secret))
+	command := fmt.Sprintf("add-generic-password -U -s '%s' -a '%s' -w '%s'\n", service, account, encoded)
+	result, err := k.run(command, "-i")
+	if err != nil {
+		return fmt.Errorf("could not run security: %w", err)
+	}
+	if result.exitCode != 0 || result.stderr != "" {
+		return commandError("security", result)
+	}
+	return nil
+}
+
+func (k *macOSKeychain) Delete(service, account string) error {
+	result, err := k.run("", "delete-generic-password", "-s", service, "-a", account)
+	if err != nil {
+		return fmt.Errorf("could not run security: %w", err)
+	}
+	if result.exitCode == macOSErrItemNotFound {
+		return ErrNotFound
+	}
+	if result.exitCode != 0 {
+		return commandError("security", result)
+	}
+	return nil
+}
+
+// validateMacOSArg checks that the argument can be quoted for the interactive mode of the security command.
+func validateMacOSArg(arg string) error {
+	if strings.ContainsAny(arg, "'\n") {
+		return fmt.Errorf("invalid keychain item name %q", arg)
+	}
+	return nil
+}
+
+func commandError(name string, result *commandResult) error {
+	return fmt.Errorf("%s exited with code %d: %s", name, result.exitCode, strings.TrimSpace(result.stderr))
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package keychain
+
+import (
+	"fmt"
+)
+
+// secretService stores secrets in the Secret Service of the user's session, e.g. GNOME Keyring or KWallet, using the
+// secret-tool command of libsecret.
+type secretService struct {
+	run runner
+}
+
+var _ Keychain = &secretService{}
+
+func (k *secretService) Get(service, account string) (string, error) {
+	result, err := k.run("", "lookup", "service", service, "account", account)
+	if err != nil {
+		return "", fmt.Errorf("could not run secret-tool: %w", err)
+	}
+	// secret-tool exits with an error and no message when there is no matching secret.
+	if result.exitCode != 0 && result.stderr == "" {
+		return "", ErrNotFound
+	}
+	if result.exitCode != 0 {
+		return "", commandError("secret-tool", result)
+	}
+	return result.stdout, nil
+}
+
+func (k *secretService) Set(service, account, secret string) error {
+	// secret-tool reads the secret from stdin, so that it does not appear in the arguments of the process.
+	result, err := k.run(secret, "store", "--label=Pinniped: "+service, "service", service, "account", account)
+	if err != nil {
+		return fmt.Errorf("could not run secret-tool: %w", err)
+	}
+	if result.exitCode != 0 {
+		return commandError("secret-tool", result)
+	}
+	return nil
+}
+
+func (k *secretService) Delete(service, account string) error {
+	if _, err := k.Get(service, account); err != nil {
+		return err
+	}
+	result, err := k.run("", "clear", "service", service, "account", account)
+	if err != nil {
+		return fmt.Errorf("could not run secret-tool: %w", err)
+	}
+	if result.exitCode != 0 {
+		return commandError("secret-tool", result)
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package leaderelection
@@ -6,11 +6,13 @@ package leaderelection
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync/atomic"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/server/healthz"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -24,6 +26,9 @@ import (
 
 const ErrNotLeader constable.Error = "write attempt rejected as client is not leader"
 
+// leaseDuration is how long the other pods wait for the leader to renew its lease before they may take it over.
+const leaseDuration = 137 * time.Second
+
 // New returns a client that has a leader election middleware injected into it.
 // This middleware will prevent all non-read requests to the Kubernetes API when
 // the current process does not hold the leader election lock.  Unlike normal
@@ -35,17 +40,29 @@ const ErrNotLeader constable.Error = "write attempt rejected as client is not le
 //
 // The returned function is blocking and will run the leader election polling
 // logic and will coordinate lease release with the input controller starter function.
+//
+// The returned isLeader function reports whether this process currently holds
+// the lock.  It is meant for work which must only be done by the leader but
+// which is not a write to the Kubernetes API, and which the middleware can
+// therefore not prevent.
+//
+// The returned health check fails when this process is the leader but has not
+// renewed its lease in time, in which case another process may already have
+// taken over the lease.  It is meant for readiness and not for liveness, since
+// we do not want to exit the process if the leader changes.
 func New(podInfo *downward.PodInfo, deployment *appsv1.Deployment, opts ...kubeclient.Option) (
 	*kubeclient.Client,
 	controllerinit.RunnerWrapper,
+	func() bool,
+	healthz.HealthChecker,
 	error,
 ) {
 	internalClient, err := kubeclient.New(opts...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not create internal client for leader election: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("could not create internal client for leader election: %w", err)
 	}
 
-	isLeader := &isLeaderTracker{tracker: &atomic.Bool{}}
+	isLeader := &isLeaderTracker{tracker: &atomic.Bool{}, lastRenewed: &atomic.Int64{}}
 
 	identity := podInfo.Name
 	leaseName := deployment.Name
@@ -54,7 +71,7 @@ func New(podInfo *downward.PodInfo, deployment *appsv1.Deployment, opts ...kubec
 
 	// validate our config here before we rely on it being functioning below
 	if _, err := leaderelection.NewLeaderElector(leaderElectionConfig); err != nil {
-		return nil, nil, fmt.Errorf("invalid config - could not create leader elector: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("invalid config - could not create leader elector: %w", err)
 	}
 
 	writeOnlyWhenLeader := kubeclient.MiddlewareFunc(func(_ context.Context, rt kubeclient.RoundTrip) {
@@ -87,7 +104,7 @@ func New(podInfo *downward.PodInfo, deployment *appsv1.Deployment, opts ...kubec
 
 	client, err := kubeclient.New(leaderElectionOpts...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not create leader election client: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("could not create leader election client: %w", err)
 	}
 
 	controllersWithLeaderElector := func(ctx context.Context, controllers controllerinit.Runner) {
@@ -120,7 +137,19 @@ func New(podInfo *downward.PodInfo, deployment *appsv1.Deployment, opts ...kubec
 		}
 	}
 
-	return client, controllersWithLeaderElector, nil
+	return client, controllersWithLeaderElector, isLeader.canWrite, newHealthCheck(isLeader, time.Now), nil
+}
+
+func newHealthCheck(isLeader *isLeaderTracker, now func() time.Time) healthz.HealthChecker {
+	return healthz.NamedCheck("leader-election", func(_ *http.Request) error {
+		if !isLeader.canWrite() {
+			return nil // the other processes do not need the lease to do their job
+		}
+		if sinceRenewed := now().Sub(isLeader.renewedAt()); sinceRenewed > leaseDuration {
+			return fmt.Errorf("leader has not renewed its lease for %s", sinceRenewed.Round(time.Second))
+		}
+		return nil
+	})
 }
 
 func newLeaderElectionConfig(namespace, leaseName, identity string, internalClient kubernetes.Interface, isLeader *isLeaderTracker) leaderelection.LeaderElectionConfig {
@@ -143,7 +172,7 @@ func newLeaderElectionConfig(namespace, leaseName, identity string, internalClie
 
 		// Copied from defaults used in OpenShift since we want the same semantics:
 		// https://github.com/openshift/library-go/blob/e14e06ba8d476429b10cc6f6c0fcfe6ea4f2c591/pkg/config/leaderelection/leaderelection.go#L87-L109
-		LeaseDuration: 137 * time.Second,
+		LeaseDuration: leaseDuration,
 		RenewDeadline: 107 * time.Second,
 		RetryPeriod:   26 * time.Second,
 
@@ -172,7 +201,8 @@ func newLeaderElectionConfig(namespace, leaseName, identity string, internalClie
 }
 
 type isLeaderTracker struct {
-	tracker *atomic.Bool
+	tracker     *atomic.Bool
+	lastRenewed *atomic.Int64 // in unix nanoseconds
 }
 
 func (t *isLeaderTracker) canWrite() bool {
@@ -180,9 +210,18 @@ func (t *isLeaderTracker) canWrite() bool {
 }
 
 func (t *isLeaderTracker) start() {
+	t.renewed(time.Now()) // the lease was just acquired
 	t.tracker.Store(true)
 }
 
+func (t *isLeaderTracker) renewed(at time.Time) {
+	t.lastRenewed.Store(at.UnixNano())
+}
+
+func (t *isLeaderTracker) renewedAt() time.Time {
+	return time.Unix(0, t.lastRenewed.Load())
+}
+
 func (t *isLeaderTracker) stop() (didStop bool) {
 	return t.tracker.CompareAndSwap(true, false)
 }
@@ -221,7 +260,14 @@ func (r *releaseLock) Update(ctx context.Context, ler resourcelock.LeaderElectio
 		plog.Debug("leader lost", "identity", r.identity, "reason", "release")
 	}
 
-	return r.delegate.Update(ctx, ler)
+	if err := r.delegate.Update(ctx, ler); err != nil {
+		return err
+	}
+
+	if len(ler.HolderIdentity) != 0 {
+		r.isLeader.renewed(time.Now())
+	}
+	return nil
 }
 
 // boilerplate passthrough methods below
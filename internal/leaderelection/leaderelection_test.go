@@ -1,4 +1,4 @@
-// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package leaderelection
@@ -6,6 +6,7 @@ package leaderelection
 import (
 	"context"
 	"errors"
+	"net/http"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -63,7 +64,7 @@ func Test_releaseLock_Update(t *testing.T) {
 			t.Parallel()
 
 			internalClient := kubefake.NewSimpleClientset()
-			isLeader := &isLeaderTracker{tracker: &atomic.Bool{}}
+			isLeader := &isLeaderTracker{tracker: &atomic.Bool{}, lastRenewed: &atomic.Int64{}}
 
 			leaderElectorCtx, cancel := context.WithCancel(context.Background())
 
@@ -81,3 +82,53 @@ func Test_releaseLock_Update(t *testing.T) {
 		})
 	}
 }
+
+func Test_newHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	lastRenewed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		isLeader bool
+		now      time.Time
+		wantErr  string
+	}{
+		{
+			name:     "not the leader",
+			isLeader: false,
+			now:      lastRenewed.Add(time.Hour),
+		},
+		{
+			name:     "leader which renewed recently",
+			isLeader: true,
+			now:      lastRenewed.Add(leaseDuration),
+		},
+		{
+			name:     "leader which did not renew in time",
+			isLeader: true,
+			now:      lastRenewed.Add(leaseDuration + time.Second),
+			wantErr:  "leader has not renewed its lease for 2m18s",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			isLeader := &isLeaderTracker{tracker: &atomic.Bool{}, lastRenewed: &atomic.Int64{}}
+			isLeader.tracker.Store(tt.isLeader)
+			isLeader.renewed(lastRenewed)
+
+			check := newHealthCheck(isLeader, func() time.Time { return tt.now })
+			require.Equal(t, "leader-election", check.Name())
+
+			err := check.Check(&http.Request{})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
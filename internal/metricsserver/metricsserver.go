@@ -0,0 +1,57 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metricsserver serves the Prometheus metrics of the global registry on a dedicated listener, so they can
+// be scraped without the credentials which are needed to read the /metrics endpoint of the aggregated API server.
+package metricsserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// Start serves the metrics endpoint on every interface of the pod until the context is canceled.
+func Start(ctx context.Context, port int64) error {
+	_, err := start(ctx, net.JoinHostPort("", strconv.FormatInt(port, 10)))
+	return err
+}
+
+func start(ctx context.Context, address string) (net.Addr, error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for metrics requests: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           newHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      time.Minute,
+	}
+
+	go func() {
+		err := server.Serve(l)
+		plog.Debug("metrics server exited", "err", err)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	plog.Info("serving metrics endpoint", "address", l.Addr().String())
+	return l.Addr(), nil
+}
+
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", legacyregistry.Handler())
+	return mux
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 //
 
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	claimexpressions "go.pinniped.dev/internal/claimexpressions"
 	provider "go.pinniped.dev/internal/oidc/provider"
 	nonce "go.pinniped.dev/pkg/oidcclient/nonce"
 	oidctypes "go.pinniped.dev/pkg/oidcclient/oidctypes"
@@ -116,6 +117,20 @@ func (mr *MockUpstreamOIDCIdentityProviderIMockRecorder) GetAuthorizationURL() *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorizationURL", reflect.TypeOf((*MockUpstreamOIDCIdentityProviderI)(nil).GetAuthorizationURL))
 }
 
+// GetClaimExpressions mocks base method.
+func (m *MockUpstreamOIDCIdentityProviderI) GetClaimExpressions() *claimexpressions.Expressions {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClaimExpressions")
+	ret0, _ := ret[0].(*claimexpressions.Expressions)
+	return ret0
+}
+
+// GetClaimExpressions indicates an expected call of GetClaimExpressions.
+func (mr *MockUpstreamOIDCIdentityProviderIMockRecorder) GetClaimExpressions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClaimExpressions", reflect.TypeOf((*MockUpstreamOIDCIdentityProviderI)(nil).GetClaimExpressions))
+}
+
 // GetClientID mocks base method.
 func (m *MockUpstreamOIDCIdentityProviderI) GetClientID() string {
 	m.ctrl.T.Helper()
@@ -244,6 +259,21 @@ func (mr *MockUpstreamOIDCIdentityProviderIMockRecorder) PerformRefresh(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PerformRefresh", reflect.TypeOf((*MockUpstreamOIDCIdentityProviderI)(nil).PerformRefresh), arg0, arg1)
 }
 
+// PushAuthorizationRequest mocks base method.
+func (m *MockUpstreamOIDCIdentityProviderI) PushAuthorizationRequest(arg0 context.Context, arg1 url.Values) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushAuthorizationRequest", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushAuthorizationRequest indicates an expected call of PushAuthorizationRequest.
+func (mr *MockUpstreamOIDCIdentityProviderIMockRecorder) PushAuthorizationRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushAuthorizationRequest", reflect.TypeOf((*MockUpstreamOIDCIdentityProviderI)(nil).PushAuthorizationRequest), arg0, arg1)
+}
+
 // RevokeToken mocks base method.
 func (m *MockUpstreamOIDCIdentityProviderI) RevokeToken(arg0 context.Context, arg1 string, arg2 provider.RevocableTokenType) error {
 	m.ctrl.T.Helper()
@@ -258,6 +288,20 @@ func (mr *MockUpstreamOIDCIdentityProviderIMockRecorder) RevokeToken(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockUpstreamOIDCIdentityProviderI)(nil).RevokeToken), arg0, arg1, arg2)
 }
 
+// UsesPushedAuthorizationRequests mocks base method.
+func (m *MockUpstreamOIDCIdentityProviderI) UsesPushedAuthorizationRequests() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsesPushedAuthorizationRequests")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// UsesPushedAuthorizationRequests indicates an expected call of UsesPushedAuthorizationRequests.
+func (mr *MockUpstreamOIDCIdentityProviderIMockRecorder) UsesPushedAuthorizationRequests() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsesPushedAuthorizationRequests", reflect.TypeOf((*MockUpstreamOIDCIdentityProviderI)(nil).UsesPushedAuthorizationRequests))
+}
+
 // ValidateTokenAndMergeWithUserInfo mocks base method.
 func (m *MockUpstreamOIDCIdentityProviderI) ValidateTokenAndMergeWithUserInfo(arg0 context.Context, arg1 *oauth2.Token, arg2 nonce.Nonce, arg3, arg4 bool) (*oidctypes.Token, error) {
 	m.ctrl.T.Helper()
@@ -6,6 +6,7 @@ package phttp
 import (
 	"crypto/x509"
 	"net/http"
+	"net/url"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/net"
@@ -17,16 +18,25 @@ import (
 )
 
 func Default(rootCAs *x509.CertPool) *http.Client {
-	return buildClient(ptls.Default, rootCAs)
+	return buildClient(ptls.Default, rootCAs, nil)
 }
 
 func Secure(rootCAs *x509.CertPool) *http.Client {
-	return buildClient(ptls.Secure, rootCAs)
+	return buildClient(ptls.Secure, rootCAs, nil)
 }
 
-func buildClient(tlsConfigFunc ptls.ConfigFunc, rootCAs *x509.CertPool) *http.Client {
+// DefaultWithProxy is like Default, but the returned client uses the given proxy function (see ProxyFunc)
+// instead of the proxy configured by the environment variables of the process.
+func DefaultWithProxy(rootCAs *x509.CertPool, proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	return buildClient(ptls.Default, rootCAs, proxy)
+}
+
+func buildClient(tlsConfigFunc ptls.ConfigFunc, rootCAs *x509.CertPool, proxy func(*http.Request) (*url.URL, error)) *http.Client {
 	baseRT := defaultTransport()
 	baseRT.TLSClientConfig = tlsConfigFunc(rootCAs)
+	if proxy != nil {
+		baseRT.Proxy = proxy
+	}
 
 	return &http.Client{
 		Transport: defaultWrap(baseRT),
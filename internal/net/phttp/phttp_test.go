@@ -36,6 +36,12 @@ func TestUnwrap(t *testing.T) {
 			name: "secure",
 			f:    Secure,
 		},
+		{
+			name: "default with proxy",
+			f: func(rootCAs *x509.CertPool) *http.Client {
+				return DefaultWithProxy(rootCAs, http.ProxyFromEnvironment)
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
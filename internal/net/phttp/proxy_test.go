@@ -0,0 +1,102 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package phttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyFunc(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		proxyURL   string
+		noProxy    []string
+		requestURL string
+		wantProxy  string
+		wantErr    string
+	}{
+		{
+			name:       "http proxy",
+			proxyURL:   "http://proxy.example.com:3128",
+			requestURL: "https://issuer.example.com/.well-known/openid-configuration",
+			wantProxy:  "http://proxy.example.com:3128",
+		},
+		{
+			name:       "socks5 proxy for an http request",
+			proxyURL:   "socks5://proxy.example.com:1080",
+			requestURL: "http://issuer.example.com/token",
+			wantProxy:  "socks5://proxy.example.com:1080",
+		},
+		{
+			name:       "host matched by a domain in noProxy",
+			proxyURL:   "https://proxy.example.com",
+			noProxy:    []string{".internal.example.com", "10.0.0.0/8"},
+			requestURL: "https://issuer.internal.example.com/token",
+		},
+		{
+			name:       "IP matched by a CIDR in noProxy",
+			proxyURL:   "https://proxy.example.com",
+			noProxy:    []string{".internal.example.com", "10.0.0.0/8"},
+			requestURL: "https://10.1.2.3/token",
+		},
+		{
+			name:       "host not matched by noProxy",
+			proxyURL:   "https://proxy.example.com",
+			noProxy:    []string{".internal.example.com", "10.0.0.0/8"},
+			requestURL: "https://issuer.example.com/token",
+			wantProxy:  "https://proxy.example.com",
+		},
+		{
+			name:       "localhost is never proxied",
+			proxyURL:   "http://proxy.example.com:3128",
+			requestURL: "https://localhost:8443/token",
+		},
+		{
+			name:     "unsupported scheme",
+			proxyURL: "ftp://proxy.example.com",
+			wantErr:  `unsupported scheme "ftp"`,
+		},
+		{
+			name:     "missing host",
+			proxyURL: "http://",
+			wantErr:  "missing host",
+		},
+		{
+			name:     "not a URL",
+			proxyURL: "http://proxy.example.com:port",
+			wantErr:  `parse "http://proxy.example.com:port": invalid port ":port" after host`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			proxy, err := ProxyFunc(tt.proxyURL, tt.noProxy)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, proxy)
+				return
+			}
+			require.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tt.requestURL, nil)
+			require.NoError(t, err)
+
+			got, err := proxy(req)
+			require.NoError(t, err)
+			if tt.wantProxy == "" {
+				require.Nil(t, got)
+				return
+			}
+			require.Equal(t, tt.wantProxy, got.String())
+		})
+	}
+}
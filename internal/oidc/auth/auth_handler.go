@@ -1,10 +1,11 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package auth provides a handler for the OIDC authorization endpoint.
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -13,12 +14,18 @@ import (
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/fosite/token/jwt"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/oauth2"
+	"k8s.io/apimachinery/pkg/types"
 
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/chooseidp"
 	"go.pinniped.dev/internal/oidc/csrftoken"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/oidc/login"
@@ -26,6 +33,7 @@ import (
 	"go.pinniped.dev/internal/oidc/provider/formposthtml"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/tracing"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
 )
@@ -37,7 +45,7 @@ const (
 
 func NewHandler(
 	downstreamIssuer string,
-	idpLister oidc.UpstreamIdentityProvidersLister,
+	idpLister oidc.FederationDomainIdentityProvidersListerI,
 	oauthHelperWithoutStorage fosite.OAuth2Provider,
 	oauthHelperWithStorage fosite.OAuth2Provider,
 	generateCSRF func() (csrftoken.CSRFToken, error),
@@ -45,6 +53,7 @@ func NewHandler(
 	generateNonce func() (nonce.Nonce, error),
 	upstreamStateEncoder oidc.Encoder,
 	cookieCodec oidc.Codec,
+	identityTransforms *idtransform.TransformationPipeline,
 ) http.Handler {
 	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
 		if r.Method != http.MethodPost && r.Method != http.MethodGet {
@@ -57,20 +66,26 @@ func NewHandler(
 		// Note that the client might have used oidcapi.AuthorizeUpstreamIDPNameParamName and
 		// oidcapi.AuthorizeUpstreamIDPTypeParamName query params to request a certain upstream IDP.
 		// The Pinniped CLI has been sending these params since v0.9.0.
-		// Currently, these are ignored because the Supervisor does not yet support logins when multiple IDPs
-		// are configured. However, these params should be honored in the future when choosing an upstream
-		// here, e.g. by calling oidcapi.FindUpstreamIDPByNameAndType() when the params are present.
-		oidcUpstream, ldapUpstream, idpType, err := chooseUpstreamIDP(idpLister)
+		oidcUpstream, ldapUpstream, kerberosUpstream, idpType, err := chooseUpstreamIDP(r, idpLister)
+		if errors.Is(err, errUpstreamIDPNotChosen) && !hasUsernameOrPasswordHeaders(r) {
+			// The user is logging in with a browser, so they can choose the upstream IDP themselves.
+			return handleAuthRequestToChooseUpstreamIDP(r, w,
+				oauthHelperWithoutStorage,
+				generateCSRF, generateNonce, generatePKCE,
+				downstreamIssuer,
+				upstreamStateEncoder,
+				cookieCodec,
+			)
+		}
 		if err != nil {
 			plog.WarningErr("authorize upstream config", err)
 			return err
 		}
 
 		if idpType == psession.ProviderTypeOIDC {
-			if len(r.Header.Values(oidcapi.AuthorizeUsernameHeaderName)) > 0 ||
-				len(r.Header.Values(oidcapi.AuthorizePasswordHeaderName)) > 0 {
+			if hasUsernameOrPasswordHeaders(r) {
 				// The client set a username header, so they are trying to log in with a username/password.
-				return handleAuthRequestForOIDCUpstreamPasswordGrant(r, w, oauthHelperWithStorage, oidcUpstream)
+				return handleAuthRequestForOIDCUpstreamPasswordGrant(r, w, oauthHelperWithStorage, oidcUpstream, identityTransforms)
 			}
 			return handleAuthRequestForOIDCUpstreamBrowserFlow(r, w,
 				oauthHelperWithoutStorage,
@@ -82,14 +97,29 @@ func NewHandler(
 			)
 		}
 
+		if idpType == psession.ProviderTypeKerberos {
+			if hasUsernameOrPasswordHeaders(r) {
+				// The client set a username header, but Kerberos users cannot log in with a username/password.
+				return handleAuthRequestForKerberosUpstreamWithPassword(r, w, oauthHelperWithStorage)
+			}
+			return handleAuthRequestForKerberosUpstreamBrowserFlow(r, w,
+				oauthHelperWithoutStorage,
+				generateCSRF, generateNonce, generatePKCE,
+				kerberosUpstream,
+				downstreamIssuer,
+				upstreamStateEncoder,
+				cookieCodec,
+			)
+		}
+
 		// We know it's an AD/LDAP upstream.
-		if len(r.Header.Values(oidcapi.AuthorizeUsernameHeaderName)) > 0 ||
-			len(r.Header.Values(oidcapi.AuthorizePasswordHeaderName)) > 0 {
+		if hasUsernameOrPasswordHeaders(r) {
 			// The client set a username header, so they are trying to log in with a username/password.
 			return handleAuthRequestForLDAPUpstreamCLIFlow(r, w,
 				oauthHelperWithStorage,
 				ldapUpstream,
 				idpType,
+				identityTransforms,
 			)
 		}
 		return handleAuthRequestForLDAPUpstreamBrowserFlow(
@@ -113,12 +143,67 @@ func NewHandler(
 	return securityheader.WrapWithCustomCSP(handler, formposthtml.ContentSecurityPolicy())
 }
 
+// hasUsernameOrPasswordHeaders returns true when the client is trying to log in with a username/password,
+// rather than with a browser.
+func hasUsernameOrPasswordHeaders(r *http.Request) bool {
+	return len(r.Header.Values(oidcapi.AuthorizeUsernameHeaderName)) > 0 ||
+		len(r.Header.Values(oidcapi.AuthorizePasswordHeaderName)) > 0
+}
+
+// RateLimitedRequest is an oidc.RateLimitedRequestFunc for the authorization endpoint. Only the requests which try
+// to log in with a username/password are subject to the rate limits, since the others never check a password.
+func RateLimitedRequest(r *http.Request) (string, bool) {
+	if !hasUsernameOrPasswordHeaders(r) {
+		return "", false
+	}
+	return r.Header.Get(oidcapi.AuthorizeUsernameHeaderName), true
+}
+
+// handleAuthRequestToChooseUpstreamIDP validates the authorization request and then redirects the browser to the
+// page on which the user chooses an upstream IDP. The state param holds the params of the authorization request,
+// without an upstream name or type, so that the page can send the browser back to this endpoint after the user
+// has chosen.
+func handleAuthRequestToChooseUpstreamIDP(
+	r *http.Request,
+	w http.ResponseWriter,
+	oauthHelper fosite.OAuth2Provider,
+	generateCSRF func() (csrftoken.CSRFToken, error),
+	generateNonce func() (nonce.Nonce, error),
+	generatePKCE func() (pkce.Code, error),
+	downstreamIssuer string,
+	upstreamStateEncoder oidc.Encoder,
+	cookieCodec oidc.Codec,
+) error {
+	authRequestState, err := handleBrowserFlowAuthRequest(
+		r,
+		w,
+		oauthHelper,
+		generateCSRF,
+		generateNonce,
+		generatePKCE,
+		"",
+		"",
+		cookieCodec,
+		upstreamStateEncoder,
+	)
+	if err != nil {
+		return err
+	}
+	if authRequestState == nil {
+		// There was an error but handleBrowserFlowAuthRequest() already took care of writing the response for it.
+		return nil
+	}
+
+	return chooseidp.RedirectToChooseIDPPage(r, w, downstreamIssuer, authRequestState.encodedStateParam)
+}
+
 func handleAuthRequestForLDAPUpstreamCLIFlow(
 	r *http.Request,
 	w http.ResponseWriter,
 	oauthHelper fosite.OAuth2Provider,
 	ldapUpstream provider.UpstreamLDAPIdentityProviderI,
 	idpType psession.ProviderType,
+	identityTransforms *idtransform.TransformationPipeline,
 ) error {
 	authorizeRequester, created := newAuthorizeRequest(r, w, oauthHelper, true)
 	if !created {
@@ -134,12 +219,19 @@ func handleAuthRequestForLDAPUpstreamCLIFlow(
 		return nil
 	}
 
-	authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(r.Context(), username, password, authorizeRequester.GetGrantedScopes())
+	authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(oidc.AuthenticationContext(r), username, password, authorizeRequester.GetGrantedScopes())
+	if errors.Is(err, authenticators.ErrThrottled) {
+		oidc.AuditLoginFailed(r, authorizeRequester, username, ldapUpstream.GetName(), idpType, "too many recent authentication attempts")
+		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+			fosite.ErrAccessDenied.WithHintf("Too many recent authentication attempts. Please try again later."), true)
+		return nil
+	}
 	if err != nil {
 		plog.WarningErr("unexpected error during upstream LDAP authentication", err, "upstreamName", ldapUpstream.GetName())
 		return httperr.New(http.StatusBadGateway, "unexpected error during upstream authentication")
 	}
 	if !authenticated {
+		oidc.AuditLoginFailed(r, authorizeRequester, username, ldapUpstream.GetName(), idpType, "username/password not accepted by upstream")
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithHintf("Username/password not accepted by LDAP provider."), true)
 		return nil
@@ -149,8 +241,16 @@ func handleAuthRequestForLDAPUpstreamCLIFlow(
 	username = authenticateResponse.User.GetName()
 	groups := authenticateResponse.User.GetGroups()
 	customSessionData := downstreamsession.MakeDownstreamLDAPOrADCustomSessionData(ldapUpstream, idpType, authenticateResponse, username)
-	openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
-		authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, map[string]interface{}{})
+	username, groups, err = downstreamsession.ApplyIdentityTransforms(r.Context(), identityTransforms, customSessionData, username, groups)
+	if err != nil {
+		oidc.AuditLoginFailed(r, authorizeRequester, customSessionData.UpstreamUsername, ldapUpstream.GetName(), idpType, err.Error())
+		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+			fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true,
+		)
+		return nil
+	}
+	openIDSession := downstreamsession.MakeDownstreamSession(authorizeRequester.GetID(), subject, username, groups,
+		authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, authenticateResponse.AdditionalClaims)
 	oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, true)
 
 	return nil
@@ -192,11 +292,64 @@ func handleAuthRequestForLDAPUpstreamBrowserFlow(
 	return login.RedirectToLoginPage(r, w, downstreamIssuer, authRequestState.encodedStateParam, login.ShowNoError)
 }
 
+func handleAuthRequestForKerberosUpstreamWithPassword(
+	r *http.Request,
+	w http.ResponseWriter,
+	oauthHelper fosite.OAuth2Provider,
+) error {
+	authorizeRequester, created := newAuthorizeRequest(r, w, oauthHelper, true)
+	if !created {
+		return nil
+	}
+
+	// Return a user-friendly error for this case which is entirely within our control.
+	oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+		fosite.ErrAccessDenied.WithHint(
+			"Username/password logins are not supported for Kerberos upstream providers. Please use a browser-based login."), true)
+	return nil
+}
+
+func handleAuthRequestForKerberosUpstreamBrowserFlow(
+	r *http.Request,
+	w http.ResponseWriter,
+	oauthHelper fosite.OAuth2Provider,
+	generateCSRF func() (csrftoken.CSRFToken, error),
+	generateNonce func() (nonce.Nonce, error),
+	generatePKCE func() (pkce.Code, error),
+	kerberosUpstream provider.UpstreamKerberosIdentityProviderI,
+	downstreamIssuer string,
+	upstreamStateEncoder oidc.Encoder,
+	cookieCodec oidc.Codec,
+) error {
+	authRequestState, err := handleBrowserFlowAuthRequest(
+		r,
+		w,
+		oauthHelper,
+		generateCSRF,
+		generateNonce,
+		generatePKCE,
+		kerberosUpstream.GetName(),
+		psession.ProviderTypeKerberos,
+		cookieCodec,
+		upstreamStateEncoder,
+	)
+	if err != nil {
+		return err
+	}
+	if authRequestState == nil {
+		// There was an error but handleBrowserFlowAuthRequest() already took care of writing the response for it.
+		return nil
+	}
+
+	return login.RedirectToKerberosLoginPage(r, w, downstreamIssuer, authRequestState.encodedStateParam)
+}
+
 func handleAuthRequestForOIDCUpstreamPasswordGrant(
 	r *http.Request,
 	w http.ResponseWriter,
 	oauthHelper fosite.OAuth2Provider,
 	oidcUpstream provider.UpstreamOIDCIdentityProviderI,
+	identityTransforms *idtransform.TransformationPipeline,
 ) error {
 	authorizeRequester, created := newAuthorizeRequest(r, w, oauthHelper, true)
 	if !created {
@@ -229,13 +382,17 @@ func handleAuthRequestForOIDCUpstreamPasswordGrant(
 		// However, the exact response is undefined in the sense that there is no such thing as a password grant in
 		// the OIDC spec, so we don't try too hard to read the upstream errors in this case. (E.g. Dex departs from the
 		// spec and returns something other than an "invalid_grant" error for bad resource owner credentials.)
+		oidc.AuditLoginFailed(r, authorizeRequester, username, oidcUpstream.GetName(), psession.ProviderTypeOIDC, "password grant failed at upstream")
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithDebug(err.Error()), true) // WithDebug hides the error from the client
 		return nil
 	}
 
+	// Remember the username which the user logged in with, before it is replaced by the downstream username.
+	upstreamUsername := username
 	subject, username, groups, err := downstreamsession.GetDownstreamIdentityFromUpstreamIDToken(oidcUpstream, token.IDToken.Claims)
 	if err != nil {
+		oidc.AuditLoginFailed(r, authorizeRequester, upstreamUsername, oidcUpstream.GetName(), psession.ProviderTypeOIDC, err.Error())
 		// Return a user-friendly error for this case which is entirely within our control.
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true,
@@ -247,13 +404,23 @@ func handleAuthRequestForOIDCUpstreamPasswordGrant(
 
 	customSessionData, err := downstreamsession.MakeDownstreamOIDCCustomSessionData(oidcUpstream, token, username)
 	if err != nil {
+		oidc.AuditLoginFailed(r, authorizeRequester, upstreamUsername, oidcUpstream.GetName(), psession.ProviderTypeOIDC, err.Error())
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true,
 		)
 		return nil
 	}
 
-	openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
+	username, groups, err = downstreamsession.ApplyIdentityTransforms(r.Context(), identityTransforms, customSessionData, username, groups)
+	if err != nil {
+		oidc.AuditLoginFailed(r, authorizeRequester, customSessionData.UpstreamUsername, oidcUpstream.GetName(), psession.ProviderTypeOIDC, err.Error())
+		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+			fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true,
+		)
+		return nil
+	}
+
+	openIDSession := downstreamsession.MakeDownstreamSession(authorizeRequester.GetID(), subject, username, groups,
 		authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, additionalClaims)
 
 	oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, true)
@@ -312,17 +479,55 @@ func handleAuthRequestForOIDCUpstreamBrowserFlow(
 		authCodeOptions = append(authCodeOptions, oauth2.SetAuthURLParam(key, val))
 	}
 
+	redirectURL := upstreamOAuthConfig.AuthCodeURL(
+		authRequestState.encodedStateParam,
+		authCodeOptions...,
+	)
+
+	if oidcUpstream.UsesPushedAuthorizationRequests() {
+		ctx, span := tracing.Start(r.Context(), "upstream pushed authorization request",
+			attribute.String("pinniped.upstream.name", oidcUpstream.GetName()),
+			attribute.String("pinniped.upstream.type", string(psession.ProviderTypeOIDC)),
+		)
+		redirectURL, err = pushAuthorizationRequest(r.WithContext(ctx), oidcUpstream, redirectURL)
+		tracing.End(span, err)
+		if err != nil {
+			return err
+		}
+	}
+
 	http.Redirect(w, r,
-		upstreamOAuthConfig.AuthCodeURL(
-			authRequestState.encodedStateParam,
-			authCodeOptions...,
-		),
+		redirectURL,
 		http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
 	)
 
 	return nil
 }
 
+// pushAuthorizationRequest sends the params of the upstream authorization request to the upstream's pushed
+// authorization request endpoint, and returns an authorization URL which only refers to the pushed request.
+// See https://datatracker.ietf.org/doc/html/rfc9126#section-4.
+func pushAuthorizationRequest(r *http.Request, oidcUpstream provider.UpstreamOIDCIdentityProviderI, authCodeURL string) (string, error) {
+	parsedAuthCodeURL, err := url.Parse(authCodeURL)
+	if err != nil {
+		return "", httperr.Wrap(http.StatusInternalServerError, "error parsing upstream authorization URL", err)
+	}
+
+	requestURI, err := oidcUpstream.PushAuthorizationRequest(r.Context(), parsedAuthCodeURL.Query())
+	if err != nil {
+		plog.WarningErr("error pushing authorization request to upstream provider", err, "upstreamName", oidcUpstream.GetName())
+		return "", httperr.New(http.StatusBadGateway, "error pushing authorization request to upstream provider")
+	}
+
+	// Start from the configured authorization URL to keep any query params that it already had.
+	redirectURL := *oidcUpstream.GetAuthorizationURL()
+	query := redirectURL.Query()
+	query.Set("client_id", oidcUpstream.GetClientID())
+	query.Set("request_uri", requestURI)
+	redirectURL.RawQuery = query.Encode()
+	return redirectURL.String(), nil
+}
+
 func requireStaticClientForUsernameAndPasswordHeaders(r *http.Request, w http.ResponseWriter, oauthHelper fosite.OAuth2Provider, authorizeRequester fosite.AuthorizeRequester) bool {
 	isStaticClient := authorizeRequester.GetClient().GetID() == oidcapi.ClientIDPinnipedCLI
 	if !isStaticClient {
@@ -380,19 +585,58 @@ func readCSRFCookie(r *http.Request, codec oidc.Decoder) csrftoken.CSRFToken {
 	return csrfFromCookie
 }
 
-// chooseUpstreamIDP selects either an OIDC, an LDAP, or an AD IDP, or returns an error.
+// errUpstreamIDPNotChosen is returned by chooseUpstreamIDP when more than one upstream IDP is available and the
+// client did not choose one.
+var errUpstreamIDPNotChosen = httperr.New( //nolint:gochecknoglobals // used as a sentinel error
+	http.StatusUnprocessableEntity,
+	fmt.Sprintf("Too many upstream providers are configured (use the %s param to choose one)", oidcapi.AuthorizeUpstreamIDPNameParamName),
+)
+
+// upstreamIDP is implemented by every type of upstream IDP.
+type upstreamIDP interface {
+	GetName() string
+	GetResourceUID() types.UID
+}
+
+// chooseUpstreamIDP selects either an OIDC, an LDAP, an AD, or a Kerberos IDP, or returns an error.
 // Note that AD and LDAP IDPs both return the same interface type, but different ProviderTypes values.
-func chooseUpstreamIDP(idpLister oidc.UpstreamIdentityProvidersLister) (provider.UpstreamOIDCIdentityProviderI, provider.UpstreamLDAPIdentityProviderI, psession.ProviderType, error) {
+// When more than one upstream IDP is available, the client must choose one using the
+// oidcapi.AuthorizeUpstreamIDPNameParamName param, whose value is the display name of the IDP. When only one
+// upstream IDP is available, the params are ignored, for compatibility with clients which send stale params.
+// When the client did not choose an upstream IDP, the error is errUpstreamIDPNotChosen.
+func chooseUpstreamIDP(r *http.Request, idpLister oidc.FederationDomainIdentityProvidersListerI) (
+	provider.UpstreamOIDCIdentityProviderI,
+	provider.UpstreamLDAPIdentityProviderI,
+	provider.UpstreamKerberosIdentityProviderI,
+	psession.ProviderType,
+	error,
+) {
 	oidcUpstreams := idpLister.GetOIDCIdentityProviders()
 	ldapUpstreams := idpLister.GetLDAPIdentityProviders()
 	adUpstreams := idpLister.GetActiveDirectoryIdentityProviders()
-	switch {
-	case len(oidcUpstreams)+len(ldapUpstreams)+len(adUpstreams) == 0:
-		return nil, nil, "", httperr.New(
+	kerberosUpstreams := idpLister.GetKerberosIdentityProviders()
+	switch len(oidcUpstreams) + len(ldapUpstreams) + len(adUpstreams) + len(kerberosUpstreams) {
+	case 0:
+		return nil, nil, nil, "", httperr.New(
 			http.StatusUnprocessableEntity,
 			"No upstream providers are configured",
 		)
-	case len(oidcUpstreams)+len(ldapUpstreams)+len(adUpstreams) > 1:
+	case 1:
+		switch {
+		case len(oidcUpstreams) == 1:
+			return oidcUpstreams[0], nil, nil, psession.ProviderTypeOIDC, nil
+		case len(adUpstreams) == 1:
+			return nil, adUpstreams[0], nil, psession.ProviderTypeActiveDirectory, nil
+		case len(kerberosUpstreams) == 1:
+			return nil, nil, kerberosUpstreams[0], psession.ProviderTypeKerberos, nil
+		default:
+			return nil, ldapUpstreams[0], nil, psession.ProviderTypeLDAP, nil
+		}
+	}
+
+	requestedName := r.FormValue(oidcapi.AuthorizeUpstreamIDPNameParamName)
+	requestedType := r.FormValue(oidcapi.AuthorizeUpstreamIDPTypeParamName)
+	if requestedName == "" {
 		var upstreamIDPNames []string
 		for _, idp := range oidcUpstreams {
 			upstreamIDPNames = append(upstreamIDPNames, idp.GetName())
@@ -403,18 +647,41 @@ func chooseUpstreamIDP(idpLister oidc.UpstreamIdentityProvidersLister) (provider
 		for _, idp := range adUpstreams {
 			upstreamIDPNames = append(upstreamIDPNames, idp.GetName())
 		}
-		plog.Warning("Too many upstream providers are configured (found: %s)", upstreamIDPNames)
-		return nil, nil, "", httperr.New(
-			http.StatusUnprocessableEntity,
-			"Too many upstream providers are configured (support for multiple upstreams is not yet implemented)",
-		)
-	case len(oidcUpstreams) == 1:
-		return oidcUpstreams[0], nil, psession.ProviderTypeOIDC, nil
-	case len(adUpstreams) == 1:
-		return nil, adUpstreams[0], psession.ProviderTypeActiveDirectory, nil
-	default:
-		return nil, ldapUpstreams[0], psession.ProviderTypeLDAP, nil
+		for _, idp := range kerberosUpstreams {
+			upstreamIDPNames = append(upstreamIDPNames, idp.GetName())
+		}
+		plog.Debug("Too many upstream providers are configured and the client did not choose one", "found", upstreamIDPNames)
+		return nil, nil, nil, "", errUpstreamIDPNotChosen
+	}
+
+	matches := func(idp upstreamIDP, idpType idpdiscoveryv1alpha1.IDPType) bool {
+		return idpLister.GetDisplayName(idp.GetResourceUID(), idp.GetName()) == requestedName &&
+			(requestedType == "" || requestedType == string(idpType))
 	}
+	for _, idp := range oidcUpstreams {
+		if matches(idp, idpdiscoveryv1alpha1.IDPTypeOIDC) {
+			return idp, nil, nil, psession.ProviderTypeOIDC, nil
+		}
+	}
+	for _, idp := range ldapUpstreams {
+		if matches(idp, idpdiscoveryv1alpha1.IDPTypeLDAP) {
+			return nil, idp, nil, psession.ProviderTypeLDAP, nil
+		}
+	}
+	for _, idp := range adUpstreams {
+		if matches(idp, idpdiscoveryv1alpha1.IDPTypeActiveDirectory) {
+			return nil, idp, nil, psession.ProviderTypeActiveDirectory, nil
+		}
+	}
+	for _, idp := range kerberosUpstreams {
+		if matches(idp, idpdiscoveryv1alpha1.IDPTypeKerberos) {
+			return nil, nil, idp, psession.ProviderTypeKerberos, nil
+		}
+	}
+	return nil, nil, nil, "", httperr.New(
+		http.StatusUnprocessableEntity,
+		fmt.Sprintf("Requested upstream provider was not found: %q", requestedName),
+	)
 }
 
 type browserFlowAuthRequestState struct {
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package auth
@@ -53,6 +53,8 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		ldapUpstreamResourceUID              = "ldap-resource-uid"
 		activeDirectoryUpstreamName          = "some-active-directory-idp"
 		activeDirectoryUpstreamResourceUID   = "active-directory-resource-uid"
+		kerberosUpstreamName                 = "some-kerberos-idp"
+		kerberosUpstreamResourceUID          = "kerberos-resource-uid"
 
 		oidcUpstreamIssuer                    = "https://my-upstream-issuer.com"
 		oidcUpstreamSubject                   = "abc123-some guid" // has a space character which should get escaped in URL
@@ -160,6 +162,12 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			"state":             happyState,
 		}
 
+		fositeAccessDeniedWithThrottledHintErrorQuery = map[string]string{
+			"error":             "access_denied",
+			"error_description": "The resource owner or authorization server denied the request. Too many recent authentication attempts. Please try again later.",
+			"state":             happyState,
+		}
+
 		fositeAccessDeniedWithMissingUsernamePasswordHintErrorQuery = map[string]string{
 			"error":             "access_denied",
 			"error_description": "The resource owner or authorization server denied the request. Missing or blank username or password.",
@@ -184,6 +192,12 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			"state":             happyState,
 		}
 
+		fositeAccessDeniedWithKerberosPasswordHintErrorQuery = map[string]string{
+			"error":             "access_denied",
+			"error_description": "The resource owner or authorization server denied the request. Username/password logins are not supported for Kerberos upstream providers. Please use a browser-based login.",
+			"state":             happyState,
+		}
+
 		fositeAccessDeniedWithUsernamePasswordHeadersDisallowedHintErrorQuery = map[string]string{
 			"error":             "access_denied",
 			"error_description": "The resource owner or authorization server denied the request. This client is not allowed to submit username or password headers to this endpoint.",
@@ -237,14 +251,14 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		// Inject this into our test subject at the last second so we get a fresh storage for every test.
 		// Use lower minimum required bcrypt cost than we would use in production to keep unit the tests fast.
 		kubeOauthStore := oidc.NewKubeStorage(secretsClient, oidcClientsClient, timeoutsConfiguration, bcrypt.MinCost)
-		return oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration), kubeOauthStore
+		return oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration, nil, nil), kubeOauthStore
 	}
 
 	createOauthHelperWithNullStorage := func(secretsClient v1.SecretInterface, oidcClientsClient v1alpha1.OIDCClientInterface) (fosite.OAuth2Provider, *oidc.NullStorage) {
 		// Configure fosite the same way that the production code would, using NullStorage to turn off storage.
 		// Use lower minimum required bcrypt cost than we would use in production to keep unit the tests fast.
 		nullOauthStore := oidc.NewNullStorage(secretsClient, oidcClientsClient, bcrypt.MinCost)
-		return oidc.FositeOauth2Helper(nullOauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration), nullOauthStore
+		return oidc.FositeOauth2Helper(nullOauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration, nil, nil), nullOauthStore
 	}
 
 	upstreamAuthURL, err := url.Parse("https://some-upstream-idp:8443/auth")
@@ -337,6 +351,11 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		AuthenticateFunc: ldapAuthenticateFunc,
 	}
 
+	upstreamKerberosIdentityProvider := oidctestutil.TestUpstreamKerberosIdentityProvider{
+		Name:        kerberosUpstreamName,
+		ResourceUID: kerberosUpstreamResourceUID,
+	}
+
 	erroringUpstreamLDAPIdentityProvider := oidctestutil.TestUpstreamLDAPIdentityProvider{
 		Name:        ldapUpstreamName,
 		ResourceUID: ldapUpstreamResourceUID,
@@ -345,6 +364,18 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		},
 	}
 
+	throttledUpstreamLDAPIdentityProvider := oidctestutil.TestUpstreamLDAPIdentityProvider{
+		Name:        ldapUpstreamName,
+		ResourceUID: ldapUpstreamResourceUID,
+		AuthenticateFunc: func(ctx context.Context, username, password string) (*authenticators.Response, bool, error) {
+			// The IP address of the client is made available to the authenticator.
+			if clientIP := authenticators.ClientIPFromContext(ctx); clientIP != "192.0.2.1" {
+				return nil, false, fmt.Errorf("unexpected client IP %q", clientIP)
+			}
+			return nil, false, fmt.Errorf("%w: some reason", authenticators.ErrThrottled)
+		},
+	}
+
 	happyCSRF := "test-csrf"
 	happyPKCE := "test-pkce"
 	happyNonce := "test-nonce"
@@ -542,6 +573,7 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		name string
 
 		idps                 *oidctestutil.UpstreamIDPListerBuilder
+		federationDomainIDPs []provider.FederationDomainIdentityProvider // nil means that every upstream IDP is available
 		kubeResources        func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset)
 		generateCSRF         func() (csrftoken.CSRFToken, error)
 		generatePKCE         func() (pkce.Code, error)
@@ -565,6 +597,7 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		wantBodyStringWithLocationInHref       bool
 		wantLocationHeader                     string
 		wantUpstreamStateParamInLocationHeader bool
+		wantPushedAuthorizationRequestParams   map[string]string // all pushed params except for the state param
 
 		// Assertions for when an authcode should be returned, i.e. the request was authenticated by an
 		// upstream LDAP provider or an upstream OIDC password grant flow.
@@ -637,6 +670,23 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantUpstreamStateParamInLocationHeader: true,
 			wantBodyStringWithLocationInHref:       true,
 		},
+		{
+			name:                                   "Kerberos upstream browser flow happy path using GET without a CSRF cookie",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/login/kerberos", map[string]string{"state": expectedUpstreamStateParam(nil, "", kerberosUpstreamName, "kerberos")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
+		},
 		{
 			name:                                   "LDAP upstream browser flow happy path using GET without a CSRF cookie using a dynamic client",
 			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
@@ -1080,7 +1130,7 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			stateEncoder:                           happyStateEncoder,
 			cookieEncoder:                          happyCookieEncoder,
 			method:                                 http.MethodGet,
-			path:                                   modifiedHappyGetRequestPath(map[string]string{"pinniped_idp_name": "currently-ignored", "pinniped_idp_type": "oidc"}),
+			path:                                   modifiedHappyGetRequestPath(map[string]string{"pinniped_idp_name": "ignored-when-only-one-idp", "pinniped_idp_type": "oidc"}),
 			contentType:                            formContentType,
 			wantStatus:                             http.StatusSeeOther,
 			wantContentType:                        htmlContentType,
@@ -1106,6 +1156,49 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantLocationHeader:                     expectedRedirectLocationForUpstreamOIDC(expectedUpstreamStateParam(map[string]string{"prompt": "login"}, "", oidcUpstreamName, "oidc"), map[string]string{"prompt": "consent", "abc": "123", "def": "456"}),
 			wantUpstreamStateParamInLocationHeader: true,
 		},
+		{
+			name:                             "OIDC upstream browser flow happy path with pushed authorization requests",
+			idps:                             oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().WithPushedAuthorizationRequestURI("urn:ietf:params:oauth:request_uri:some-request-uri").Build()),
+			generateCSRF:                     happyCSRFGenerator,
+			generatePKCE:                     happyPKCEGenerator,
+			generateNonce:                    happyNonceGenerator,
+			stateEncoder:                     happyStateEncoder,
+			cookieEncoder:                    happyCookieEncoder,
+			method:                           http.MethodGet,
+			path:                             happyGetRequestPath,
+			wantStatus:                       http.StatusSeeOther,
+			wantContentType:                  htmlContentType,
+			wantBodyStringWithLocationInHref: true,
+			wantCSRFValueInCookieHeader:      happyCSRF,
+			wantLocationHeader: urlWithQuery(upstreamAuthURL.String(), map[string]string{
+				"client_id":   "some-client-id",
+				"request_uri": "urn:ietf:params:oauth:request_uri:some-request-uri",
+			}),
+			wantPushedAuthorizationRequestParams: map[string]string{
+				"response_type":         "code",
+				"scope":                 "scope1 scope2",
+				"client_id":             "some-client-id",
+				"nonce":                 happyNonce,
+				"code_challenge":        expectedUpstreamCodeChallenge,
+				"code_challenge_method": downstreamPKCEChallengeMethod,
+				"redirect_uri":          downstreamIssuer + "/callback",
+			},
+		},
+		{
+			name:                        "OIDC upstream browser flow when pushing the authorization request fails",
+			idps:                        oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().WithPushAuthorizationRequestError(errors.New("some push error")).Build()),
+			generateCSRF:                happyCSRFGenerator,
+			generatePKCE:                happyPKCEGenerator,
+			generateNonce:               happyNonceGenerator,
+			stateEncoder:                happyStateEncoder,
+			cookieEncoder:               happyCookieEncoder,
+			method:                      http.MethodGet,
+			path:                        happyGetRequestPath,
+			wantStatus:                  http.StatusBadGateway,
+			wantContentType:             "text/plain; charset=utf-8",
+			wantCSRFValueInCookieHeader: happyCSRF,
+			wantBodyString:              "Bad Gateway: error pushing authorization request to upstream provider\n",
+		},
 		{
 			name:               "OIDC upstream browser flow with prompt param none throws an error because we want to independently decide the upstream prompt param",
 			idps:               oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()),
@@ -1352,6 +1445,18 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantContentType:      htmlContentType,
 			wantBodyString:       "Bad Gateway: unexpected error during upstream authentication\n",
 		},
+		{
+			name:                 "upstream LDAP authentication is throttled",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&throttledUpstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithThrottledHintErrorQuery),
+			wantBodyString:       "",
+		},
 		{
 			name: "wrong upstream credentials for OIDC password grant authentication",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(
@@ -1387,6 +1492,18 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithBadUsernamePasswordHintErrorQuery),
 			wantBodyString:       "",
 		},
+		{
+			name:                 "username and password headers for Kerberos upstream",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithKerberosPasswordHintErrorQuery),
+			wantBodyString:       "",
+		},
 		{
 			name:                 "wrong upstream password for Active Directory authentication",
 			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithActiveDirectory(&upstreamActiveDirectoryIdentityProvider),
@@ -3039,49 +3156,228 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantBodyString:  "Unprocessable Entity: No upstream providers are configured\n",
 		},
 		{
-			name:            "too many upstream providers are configured: multiple OIDC",
-			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build(), upstreamOIDCIdentityProviderBuilder().Build()), // more than one not allowed
-			method:          http.MethodGet,
-			path:            happyGetRequestPath,
-			wantStatus:      http.StatusUnprocessableEntity,
-			wantContentType: "text/plain; charset=utf-8",
-			wantBodyString:  "Unprocessable Entity: Too many upstream providers are configured (support for multiple upstreams is not yet implemented)\n",
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow: multiple OIDC",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build(), upstreamOIDCIdentityProviderBuilder().Build()),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, "", "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
 		},
 		{
-			name:            "too many upstream providers are configured: multiple LDAP",
-			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider, &upstreamLDAPIdentityProvider), // more than one not allowed
-			method:          http.MethodGet,
-			path:            happyGetRequestPath,
-			wantStatus:      http.StatusUnprocessableEntity,
-			wantContentType: "text/plain; charset=utf-8",
-			wantBodyString:  "Unprocessable Entity: Too many upstream providers are configured (support for multiple upstreams is not yet implemented)\n",
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow: multiple LDAP",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider, &upstreamLDAPIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, "", "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
 		},
 		{
-			name:            "too many upstream providers are configured: multiple Active Directory",
-			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithActiveDirectory(&upstreamLDAPIdentityProvider, &upstreamLDAPIdentityProvider), // more than one not allowed
-			method:          http.MethodGet,
-			path:            happyGetRequestPath,
-			wantStatus:      http.StatusUnprocessableEntity,
-			wantContentType: "text/plain; charset=utf-8",
-			wantBodyString:  "Unprocessable Entity: Too many upstream providers are configured (support for multiple upstreams is not yet implemented)\n",
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow: multiple Active Directory",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithActiveDirectory(&upstreamLDAPIdentityProvider, &upstreamLDAPIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, "", "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
 		},
 		{
-			name:            "too many upstream providers are configured: both OIDC and LDAP",
-			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider), // more than one not allowed
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow: both OIDC and LDAP",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, "", "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
+		},
+		{
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow: OIDC, LDAP and AD",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider).WithActiveDirectory(&upstreamActiveDirectoryIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, "", "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
+		},
+		{
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow: both LDAP and Kerberos",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider).WithKerberos(&upstreamKerberosIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, "", "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
+		},
+		{
+			name:                                   "multiple upstream providers are configured and the client does not choose one using browser flow with a CSRF cookie",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   happyGetRequestPath,
+			csrfCookie:                             "__Host-pinniped-csrf=" + encodedIncomingCookieCSRFValue + " ",
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantLocationHeader:                     urlWithQuery(downstreamIssuer+"/choose_identity_provider", map[string]string{"state": expectedUpstreamStateParam(nil, incomingCookieCSRFValue, "", "")}),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
+		},
+		{
+			name:                 "multiple upstream providers are configured and the client does not choose one using CLI flow",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			wantStatus:           http.StatusUnprocessableEntity,
+			wantContentType:      "text/plain; charset=utf-8",
+			wantBodyString:       "Unprocessable Entity: Too many upstream providers are configured (use the pinniped_idp_name param to choose one)\n",
+		},
+		{
+			name:                                   "multiple upstream providers are configured and the client chooses one by its name",
+			idps:                                   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
+			generateCSRF:                           happyCSRFGenerator,
+			generatePKCE:                           happyPKCEGenerator,
+			generateNonce:                          happyNonceGenerator,
+			stateEncoder:                           happyStateEncoder,
+			cookieEncoder:                          happyCookieEncoder,
+			method:                                 http.MethodGet,
+			path:                                   modifiedHappyGetRequestPath(map[string]string{"pinniped_idp_name": oidcUpstreamName, "pinniped_idp_type": "oidc"}),
+			wantStatus:                             http.StatusSeeOther,
+			wantContentType:                        htmlContentType,
+			wantCSRFValueInCookieHeader:            happyCSRF,
+			wantLocationHeader:                     expectedRedirectLocationForUpstreamOIDC(expectedUpstreamStateParam(nil, "", oidcUpstreamName, "oidc"), nil),
+			wantUpstreamStateParamInLocationHeader: true,
+			wantBodyStringWithLocationInHref:       true,
+		},
+		{
+			name: "multiple upstream providers are listed by the FederationDomain and the client chooses one by its display name",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
+			federationDomainIDPs: []provider.FederationDomainIdentityProvider{
+				{DisplayName: "My OIDC", UID: oidcUpstreamResourceUID},
+				{DisplayName: "My LDAP", UID: ldapUpstreamResourceUID},
+			},
+			method:                            http.MethodGet,
+			path:                              modifiedHappyGetRequestPath(map[string]string{"pinniped_idp_name": "My LDAP"}),
+			customUsernameHeader:              pointer.String(happyLDAPUsername),
+			customPasswordHeader:              pointer.String(happyLDAPPassword),
+			wantStatus:                        http.StatusFound,
+			wantContentType:                   htmlContentType,
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       happyLDAPGroups,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+		},
+		{
+			name: "the upstream provider which is not listed by the FederationDomain is not available",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
+			federationDomainIDPs: []provider.FederationDomainIdentityProvider{
+				{DisplayName: "My LDAP", UID: ldapUpstreamResourceUID},
+			},
+			method:                            http.MethodGet,
+			path:                              happyGetRequestPath,
+			customUsernameHeader:              pointer.String(happyLDAPUsername),
+			customPasswordHeader:              pointer.String(happyLDAPPassword),
+			wantStatus:                        http.StatusFound,
+			wantContentType:                   htmlContentType,
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       happyLDAPGroups,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+		},
+		{
+			name:                 "no upstream providers are available when none of the upstream providers listed by the FederationDomain exist",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()),
+			federationDomainIDPs: []provider.FederationDomainIdentityProvider{{DisplayName: "My LDAP", UID: ldapUpstreamResourceUID}},
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			wantStatus:           http.StatusUnprocessableEntity,
+			wantContentType:      "text/plain; charset=utf-8",
+			wantBodyString:       "Unprocessable Entity: No upstream providers are configured\n",
+		},
+		{
+			name:            "multiple upstream providers are configured and the client chooses one which does not exist",
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
 			method:          http.MethodGet,
-			path:            happyGetRequestPath,
+			path:            modifiedHappyGetRequestPath(map[string]string{"pinniped_idp_name": "does-not-exist"}),
 			wantStatus:      http.StatusUnprocessableEntity,
 			wantContentType: "text/plain; charset=utf-8",
-			wantBodyString:  "Unprocessable Entity: Too many upstream providers are configured (support for multiple upstreams is not yet implemented)\n",
+			wantBodyString:  "Unprocessable Entity: Requested upstream provider was not found: \"does-not-exist\"\n",
 		},
 		{
-			name:            "too many upstream providers are configured: OIDC, LDAP and AD",
-			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider).WithActiveDirectory(&upstreamActiveDirectoryIdentityProvider), // more than one not allowed
+			name:            "multiple upstream providers are configured and the client chooses one by name but with the wrong type",
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(upstreamOIDCIdentityProviderBuilder().Build()).WithLDAP(&upstreamLDAPIdentityProvider),
 			method:          http.MethodGet,
-			path:            happyGetRequestPath,
+			path:            modifiedHappyGetRequestPath(map[string]string{"pinniped_idp_name": oidcUpstreamName, "pinniped_idp_type": "ldap"}),
 			wantStatus:      http.StatusUnprocessableEntity,
 			wantContentType: "text/plain; charset=utf-8",
-			wantBodyString:  "Unprocessable Entity: Too many upstream providers are configured (support for multiple upstreams is not yet implemented)\n",
+			wantBodyString:  fmt.Sprintf("Unprocessable Entity: Requested upstream provider was not found: %q\n", oidcUpstreamName),
 		},
 		{
 			name:            "PUT is a bad method",
@@ -3149,6 +3445,20 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			test.idps.RequireExactlyZeroCallsToPasswordCredentialsGrantAndValidateTokens(t)
 		}
 
+		if test.wantPushedAuthorizationRequestParams != nil {
+			actualArgs := test.idps.RequireExactlyOneCallToPushAuthorizationRequest(t, oidcUpstreamName)
+			// The push is traced in its own span, so its context is derived from the context of the request.
+			require.Equal(t, "request-context", actualArgs.Ctx.Value(struct{ name string }{name: "test"}))
+			actualParams := map[string]string{}
+			for key := range actualArgs.Params {
+				actualParams[key] = actualArgs.Params.Get(key)
+			}
+			// The upstream state param is encoded using a timestamp, so only check that it was pushed.
+			require.NotEmpty(t, actualParams["state"])
+			delete(actualParams, "state")
+			require.Equal(t, test.wantPushedAuthorizationRequestParams, actualParams)
+		}
+
 		actualLocation := rsp.Header().Get("Location")
 		switch {
 		case test.wantLocationHeader != "":
@@ -3248,10 +3558,11 @@ func TestAuthorizationEndpoint(t *testing.T) {
 
 			subject := NewHandler(
 				downstreamIssuer,
-				idps,
+				provider.NewFederationDomainIdentityProvidersLister(idps, test.federationDomainIDPs),
 				oauthHelperWithNullStorage, oauthHelperWithRealStorage,
 				test.generateCSRF, test.generatePKCE, test.generateNonce,
 				test.stateEncoder, test.cookieEncoder,
+				nil,
 			)
 			runOneTestCase(t, test, subject, kubeOauthStore, supervisorClient, kubeClient, secretsClient)
 		})
@@ -3271,10 +3582,11 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		idpLister := test.idps.Build()
 		subject := NewHandler(
 			downstreamIssuer,
-			idpLister,
+			provider.NewFederationDomainIdentityProvidersLister(idpLister, nil),
 			oauthHelperWithNullStorage, oauthHelperWithRealStorage,
 			test.generateCSRF, test.generatePKCE, test.generateNonce,
 			test.stateEncoder, test.cookieEncoder,
+			nil,
 		)
 
 		runOneTestCase(t, test, subject, kubeOauthStore, supervisorClient, kubeClient, secretsClient)
@@ -0,0 +1,208 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package backchannellogout sends logout tokens to the back-channel logout URIs of OIDCClients when downstream
+// sessions end, as described by https://openid.net/specs/openid-connect-backchannel-1_0.html.
+package backchannellogout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"k8s.io/utils/clock"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+const (
+	// LogoutTokenParamName is the name of the form param which holds the logout token.
+	LogoutTokenParamName = "logout_token"
+
+	// LogoutTokenType is the value of the typ header of logout tokens, as recommended by
+	// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken.
+	LogoutTokenType = "logout+jwt"
+
+	// LogoutEventName is the member of the events claim which identifies a JWT as a logout token.
+	LogoutEventName = "http://schemas.openid.net/event/backchannel-logout"
+
+	logoutTokenLifetime = 2 * time.Minute
+
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = time.Second
+	defaultAttemptTimeout = 10 * time.Second
+)
+
+// NotifierI notifies the OIDCClient of a downstream session when that session has ended.
+type NotifierI interface {
+	// NotifySessionEnded sends a logout token for the session of the given requester to the back-channel logout
+	// URI of its client. It does nothing when the client did not register a back-channel logout URI.
+	NotifySessionEnded(ctx context.Context, requester fosite.Requester) error
+}
+
+type notifier struct {
+	jwksProvider   jwks.DynamicJWKSProvider
+	clients        fosite.ClientManager
+	httpClient     *http.Client
+	clock          clock.Clock
+	maxAttempts    int
+	initialBackoff time.Duration
+	attemptTimeout time.Duration
+}
+
+var _ NotifierI = (*notifier)(nil)
+
+// New returns a NotifierI which signs logout tokens with the active JWK of the issuer of each session, and which
+// sends them with the given http.Client. Failed deliveries are retried a few times with exponential backoff.
+func New(jwksProvider jwks.DynamicJWKSProvider, clients fosite.ClientManager, httpClient *http.Client) NotifierI {
+	return &notifier{
+		jwksProvider:   jwksProvider,
+		clients:        clients,
+		httpClient:     httpClient,
+		clock:          clock.RealClock{},
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		attemptTimeout: defaultAttemptTimeout,
+	}
+}
+
+func (n *notifier) NotifySessionEnded(ctx context.Context, requester fosite.Requester) error {
+	session, ok := requester.GetSession().(*psession.PinnipedSession)
+	if !ok || session.Fosite == nil || session.Fosite.Claims == nil {
+		return errors.New("session has unexpected type")
+	}
+
+	issuer := session.Fosite.Claims.Issuer
+	if issuer == "" {
+		// Sessions which were stored before the issuer was recorded in the session storage cannot be
+		// signed for, so there is nothing to send.
+		plog.Debug("skipping back-channel logout for session without issuer", "requestID", requester.GetID())
+		return nil
+	}
+
+	clientID := requester.GetClient().GetID()
+	client, err := n.clients.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, fosite.ErrNotFound) {
+			// The client was deleted, so there is nobody to notify.
+			return nil
+		}
+		return fmt.Errorf("could not get client %q: %w", clientID, err)
+	}
+	pinnipedClient, ok := client.(*clientregistry.Client)
+	if !ok || pinnipedClient.GetBackchannelLogoutURI() == "" {
+		return nil
+	}
+
+	logoutToken, err := n.logoutToken(issuer, clientID, session.Fosite.Claims.Subject, requester.GetID())
+	if err != nil {
+		return fmt.Errorf("could not create logout token: %w", err)
+	}
+
+	if err := n.send(ctx, pinnipedClient.GetBackchannelLogoutURI(), logoutToken); err != nil {
+		return fmt.Errorf("could not send logout token to client %q: %w", clientID, err)
+	}
+
+	plog.Debug("sent back-channel logout token", "clientID", clientID, "requestID", requester.GetID())
+	return nil
+}
+
+// logoutToken returns a logout token as described by
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken.
+func (n *notifier) logoutToken(issuer, clientID, subject, sessionID string) (string, error) {
+	_, activeJWK := n.jwksProvider.GetJWKS(issuer)
+	if activeJWK == nil {
+		return "", fmt.Errorf("no active signing key for issuer %q", issuer)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.SignatureAlgorithm(activeJWK.Algorithm), Key: activeJWK},
+		(&jose.SignerOptions{}).WithType(LogoutTokenType),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	now := n.clock.Now()
+	return jwt.Signed(signer).
+		Claims(jwt.Claims{
+			Issuer:   issuer,
+			Subject:  subject,
+			Audience: jwt.Audience{clientID},
+			ID:       uuid.NewString(),
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(logoutTokenLifetime)),
+		}).
+		Claims(map[string]interface{}{
+			oidcapi.IDTokenClaimSessionID: sessionID,
+			"events":                      map[string]interface{}{LogoutEventName: map[string]interface{}{}},
+		}).
+		CompactSerialize()
+}
+
+// send posts the logout token to the back-channel logout URI, retrying after network errors and server errors.
+func (n *notifier) send(ctx context.Context, backchannelLogoutURI, logoutToken string) error {
+	body := url.Values{LogoutTokenParamName: []string{logoutToken}}.Encode()
+
+	backoff := n.initialBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := n.sendOnce(ctx, backchannelLogoutURI, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= n.maxAttempts {
+			return err
+		}
+
+		plog.Debug("retrying back-channel logout request",
+			"backchannelLogoutURI", backchannelLogoutURI, "attempt", attempt, "backoff", backoff, "error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-n.clock.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// sendOnce makes one attempt to post the logout token, and returns whether a failed attempt may be retried.
+func (n *notifier) sendOnce(ctx context.Context, backchannelLogoutURI, body string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.attemptTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, backchannelLogoutURI, strings.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent:
+		return false, nil
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected response status %q", resp.Status)
+	default:
+		return false, fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+}
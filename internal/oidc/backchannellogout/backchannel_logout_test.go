@@ -0,0 +1,291 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package backchannellogout
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	fositejwt "github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/psession"
+)
+
+type fakeClientManager struct {
+	clients map[string]fosite.Client
+	err     error
+}
+
+func (f *fakeClientManager) GetClient(_ context.Context, id string) (fosite.Client, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	client, ok := f.clients[id]
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+	return client, nil
+}
+
+func (f *fakeClientManager) ClientAssertionJWTValid(_ context.Context, _ string) error {
+	return nil
+}
+
+func (f *fakeClientManager) SetClientAssertionJWT(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+
+func TestNotifySessionEnded(t *testing.T) {
+	const (
+		issuer            = "https://some-issuer.com/some-path"
+		clientID          = "client.oauth.pinniped.dev-test-name"
+		downstreamSubject = "some-subject"
+		requestID         = "some-request-id"
+	)
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	activeJWK := &jose.JSONWebKey{Key: signingKey, KeyID: "some-kid", Algorithm: "ES256", Use: "sig"}
+
+	makeRequester := func(sessionIssuer string) *fosite.Request {
+		return &fosite.Request{
+			ID:     requestID,
+			Client: &clientregistry.Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: clientID}}},
+			Session: &psession.PinnipedSession{
+				Fosite: &openid.DefaultSession{Claims: &fositejwt.IDTokenClaims{Issuer: sessionIssuer, Subject: downstreamSubject}},
+				Custom: &psession.CustomSessionData{},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		requester            fosite.Requester
+		noBackchannelURI     bool
+		clientsErr           error
+		noActiveJWK          bool
+		responseStatuses     []int
+		wantRequests         int
+		wantErr              string
+		wantValidLogoutToken bool
+	}{
+		{
+			name:                 "happy path",
+			requester:            makeRequester(issuer),
+			responseStatuses:     []int{http.StatusOK},
+			wantRequests:         1,
+			wantValidLogoutToken: true,
+		},
+		{
+			name:                 "no content is also success",
+			requester:            makeRequester(issuer),
+			responseStatuses:     []int{http.StatusNoContent},
+			wantRequests:         1,
+			wantValidLogoutToken: true,
+		},
+		{
+			name:                 "server errors are retried",
+			requester:            makeRequester(issuer),
+			responseStatuses:     []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK},
+			wantRequests:         3,
+			wantValidLogoutToken: true,
+		},
+		{
+			name:             "server errors are retried until max attempts",
+			requester:        makeRequester(issuer),
+			responseStatuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			wantRequests:     3,
+			wantErr:          `could not send logout token to client "client.oauth.pinniped.dev-test-name": unexpected response status "500 Internal Server Error"`,
+		},
+		{
+			name:             "client errors are not retried",
+			requester:        makeRequester(issuer),
+			responseStatuses: []int{http.StatusBadRequest, http.StatusOK},
+			wantRequests:     1,
+			wantErr:          `could not send logout token to client "client.oauth.pinniped.dev-test-name": unexpected response status "400 Bad Request"`,
+		},
+		{
+			name:         "session without issuer is skipped",
+			requester:    makeRequester(""),
+			wantRequests: 0,
+		},
+		{
+			name:             "client without back-channel logout URI is skipped",
+			requester:        makeRequester(issuer),
+			noBackchannelURI: true,
+			wantRequests:     0,
+		},
+		{
+			name:         "client which no longer exists is skipped",
+			requester:    makeRequester(issuer),
+			clientsErr:   fosite.ErrNotFound,
+			wantRequests: 0,
+		},
+		{
+			name:         "error getting client",
+			requester:    makeRequester(issuer),
+			clientsErr:   errors.New("some client error"),
+			wantRequests: 0,
+			wantErr:      `could not get client "client.oauth.pinniped.dev-test-name": some client error`,
+		},
+		{
+			name:         "no active signing key for issuer",
+			requester:    makeRequester(issuer),
+			noActiveJWK:  true,
+			wantRequests: 0,
+			wantErr:      `could not create logout token: no active signing key for issuer "https://some-issuer.com/some-path"`,
+		},
+		{
+			name: "session of unexpected type",
+			requester: &fosite.Request{
+				ID:      requestID,
+				Client:  &clientregistry.Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: clientID}}},
+				Session: &openid.DefaultSession{},
+			},
+			wantRequests: 0,
+			wantErr:      "session has unexpected type",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var logoutTokens []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+				require.NoError(t, r.ParseForm())
+				logoutTokens = append(logoutTokens, r.PostForm.Get(LogoutTokenParamName))
+				w.WriteHeader(test.responseStatuses[len(logoutTokens)-1])
+			}))
+			t.Cleanup(server.Close)
+
+			client := &clientregistry.Client{
+				DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: clientID}},
+			}
+			if !test.noBackchannelURI {
+				client.BackchannelLogoutURI = server.URL + "/backchannel_logout"
+			}
+
+			jwksProvider := jwks.NewDynamicJWKSProvider()
+			activeJWKs := map[string]*jose.JSONWebKey{issuer: activeJWK}
+			if test.noActiveJWK {
+				activeJWKs = nil
+			}
+			jwksProvider.SetIssuerToJWKSMap(nil, activeJWKs)
+
+			subject := &notifier{
+				jwksProvider:   jwksProvider,
+				clients:        &fakeClientManager{clients: map[string]fosite.Client{clientID: client}, err: test.clientsErr},
+				httpClient:     server.Client(),
+				clock:          clock.RealClock{},
+				maxAttempts:    defaultMaxAttempts,
+				initialBackoff: time.Millisecond,
+				attemptTimeout: defaultAttemptTimeout,
+			}
+
+			err := subject.NotifySessionEnded(context.Background(), test.requester)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Len(t, logoutTokens, test.wantRequests)
+
+			if test.wantValidLogoutToken {
+				for _, logoutToken := range logoutTokens {
+					requireValidLogoutToken(t, logoutToken, &signingKey.PublicKey, issuer, clientID, downstreamSubject, requestID)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifySessionEndedStopsRetryingWhenContextIsDone(t *testing.T) {
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	t.Cleanup(cancel)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	jwksProvider := jwks.NewDynamicJWKSProvider()
+	jwksProvider.SetIssuerToJWKSMap(nil, map[string]*jose.JSONWebKey{
+		"https://some-issuer.com": {Key: signingKey, KeyID: "some-kid", Algorithm: "ES256", Use: "sig"},
+	})
+
+	subject := &notifier{
+		jwksProvider: jwksProvider,
+		clients: &fakeClientManager{clients: map[string]fosite.Client{"some-client": &clientregistry.Client{
+			DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: "some-client"}},
+			BackchannelLogoutURI:       server.URL,
+		}}},
+		httpClient:     server.Client(),
+		clock:          clock.RealClock{},
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: time.Hour,
+		attemptTimeout: defaultAttemptTimeout,
+	}
+
+	err = subject.NotifySessionEnded(ctx, &fosite.Request{
+		ID:     "some-request-id",
+		Client: &fosite.DefaultClient{ID: "some-client"},
+		Session: &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{Claims: &fositejwt.IDTokenClaims{Issuer: "https://some-issuer.com", Subject: "some-subject"}},
+		},
+	})
+	require.EqualError(t, err, `could not send logout token to client "some-client": context deadline exceeded (last error: unexpected response status "503 Service Unavailable")`)
+}
+
+func requireValidLogoutToken(t *testing.T, logoutToken string, publicKey *ecdsa.PublicKey, issuer, clientID, subject, sessionID string) {
+	t.Helper()
+
+	token, err := jwt.ParseSigned(logoutToken)
+	require.NoError(t, err)
+	require.Len(t, token.Headers, 1)
+	require.Equal(t, "some-kid", token.Headers[0].KeyID)
+	require.Equal(t, "ES256", token.Headers[0].Algorithm)
+	require.Equal(t, LogoutTokenType, token.Headers[0].ExtraHeaders[jose.HeaderType])
+
+	var claims jwt.Claims
+	var extraClaims map[string]interface{}
+	require.NoError(t, token.Claims(publicKey, &claims, &extraClaims))
+	require.NoError(t, claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   issuer,
+		Subject:  subject,
+		Audience: jwt.Audience{clientID},
+		Time:     time.Now(),
+	}, 0))
+	require.NotEmpty(t, claims.ID)
+	require.NotNil(t, claims.IssuedAt)
+	require.WithinDuration(t, time.Now().Add(logoutTokenLifetime), claims.Expiry.Time(), time.Minute)
+
+	require.Equal(t, sessionID, extraClaims["sid"])
+	require.Equal(t, map[string]interface{}{LogoutEventName: map[string]interface{}{}}, extraClaims["events"])
+	require.NotContains(t, extraClaims, "nonce")
+}
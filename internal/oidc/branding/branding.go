@@ -0,0 +1,202 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package branding customizes the look of the Supervisor's login form and identity provider chooser pages,
+// using a bundle of a logo, colors, footer text, and custom CSS from a ConfigMap.
+package branding
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tdewolff/minify/v2/minify"
+	corev1 "k8s.io/api/core/v1"
+
+	"go.pinniped.dev/internal/oidc/provider/csp"
+)
+
+// The keys of the ConfigMap which holds the branding bundle. Every key is optional.
+const (
+	// LogoKey is the binaryData key of a PNG, JPEG, GIF, or WebP image which is shown above the page's heading.
+	LogoKey = "logo"
+
+	// PrimaryColorKey is the data key of the color of the buttons, as a CSS hex color, e.g. "#1a2b3c".
+	PrimaryColorKey = "primaryColor"
+
+	// BackgroundColorKey is the data key of the color of the page's background, as a CSS hex color.
+	BackgroundColorKey = "backgroundColor"
+
+	// FooterTextKey is the data key of plain text which is shown below the page's box, e.g. a support contact.
+	FooterTextKey = "footerText"
+
+	// CustomCSSKey is the data key of CSS which is added after the page's own CSS.
+	CustomCSSKey = "custom.css"
+)
+
+const (
+	maxLogoBytes       = 256 * 1024
+	maxFooterTextBytes = 512
+	maxCustomCSSBytes  = 64 * 1024
+
+	// baseCSS positions the logo and the footer, which are not on the unbranded pages.
+	baseCSS = `.logo{display:block;max-width:100%;max-height:80px;margin:0 auto 30px}` +
+		`.footer{margin:30px 20px;font-size:12px;color:#666;text-align:center}`
+)
+
+//nolint:gochecknoglobals
+var (
+	hexColorRegexp = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+	allowedLogoContentTypes = map[string]bool{
+		"image/png":  true,
+		"image/jpeg": true,
+		"image/gif":  true,
+		"image/webp": true,
+	}
+)
+
+// Branding is a validated branding bundle, ready to be rendered by the templates of the pages.
+// A nil Branding leaves the pages unbranded.
+type Branding struct {
+	// CSS is added after the page's own CSS. It never contains "<", so it cannot end the page's style element.
+	CSS template.CSS
+
+	// LogoURL is a data URL of the logo, or empty when there is no logo.
+	LogoURL template.URL
+
+	// FooterText is plain text, which is escaped when it is rendered.
+	FooterText string
+}
+
+// FromConfigMap validates the branding bundle in the ConfigMap.
+func FromConfigMap(configMap *corev1.ConfigMap) (*Branding, error) {
+	if err := validateKeys(configMap); err != nil {
+		return nil, err
+	}
+
+	b := &Branding{FooterText: configMap.Data[FooterTextKey]}
+	if len(b.FooterText) > maxFooterTextBytes {
+		return nil, fmt.Errorf("%s must not be longer than %d bytes", FooterTextKey, maxFooterTextBytes)
+	}
+
+	if logo, ok := configMap.BinaryData[LogoKey]; ok {
+		if len(logo) > maxLogoBytes {
+			return nil, fmt.Errorf("%s must not be larger than %d bytes", LogoKey, maxLogoBytes)
+		}
+		contentType := http.DetectContentType(logo)
+		if !allowedLogoContentTypes[contentType] {
+			return nil, fmt.Errorf("%s must be a PNG, JPEG, GIF, or WebP image, but it was %q", LogoKey, contentType)
+		}
+		//nolint:gosec // the content type was checked above, and the base64 encoding cannot contain quotes
+		b.LogoURL = template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(logo))
+	}
+
+	css := strings.Builder{}
+	css.WriteString(baseCSS)
+	if color, ok := configMap.Data[PrimaryColorKey]; ok {
+		if !hexColorRegexp.MatchString(color) {
+			return nil, fmt.Errorf("%s must be a hex color like #1a2b3c", PrimaryColorKey)
+		}
+		css.WriteString(`.form-field input[type=submit],.form-field input[type=submit]:focus,.form-field input[type=submit]:hover{background-color:` + color + `}`)
+	}
+	if color, ok := configMap.Data[BackgroundColorKey]; ok {
+		if !hexColorRegexp.MatchString(color) {
+			return nil, fmt.Errorf("%s must be a hex color like #1a2b3c", BackgroundColorKey)
+		}
+		css.WriteString(`body{background:` + color + `}`)
+	}
+	if customCSS, ok := configMap.Data[CustomCSSKey]; ok {
+		if len(customCSS) > maxCustomCSSBytes {
+			return nil, fmt.Errorf("%s must not be larger than %d bytes", CustomCSSKey, maxCustomCSSBytes)
+		}
+		if strings.Contains(customCSS, "<") {
+			return nil, fmt.Errorf(`%s must not contain "<"`, CustomCSSKey)
+		}
+		if strings.Contains(strings.ToLower(customCSS), "@import") {
+			return nil, fmt.Errorf("%s must not import other stylesheets", CustomCSSKey)
+		}
+		css.WriteString(customCSS)
+	}
+
+	minifiedCSS, err := minify.CSS(css.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid CSS: %w", CustomCSSKey, err)
+	}
+	// Minifying could in theory turn escape sequences into characters, so check again.
+	if strings.Contains(minifiedCSS, "<") {
+		return nil, fmt.Errorf(`%s must not contain "<"`, CustomCSSKey)
+	}
+	b.CSS = template.CSS(minifiedCSS) //nolint:gosec // the CSS was checked above
+
+	return b, nil
+}
+
+func validateKeys(configMap *corev1.ConfigMap) error {
+	var unknown []string
+	for key := range configMap.Data {
+		switch key {
+		case PrimaryColorKey, BackgroundColorKey, FooterTextKey, CustomCSSKey:
+		default:
+			unknown = append(unknown, key)
+		}
+	}
+	for key := range configMap.BinaryData {
+		if key != LogoKey {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// ContentSecurityPolicy returns the Content-Security-Policy header value for a page whose own CSS is pageCSS,
+// when it is rendered with this branding.
+func (b *Branding) ContentSecurityPolicy(pageCSS string) string {
+	directives := []string{`default-src 'none'`}
+	if b == nil {
+		directives = append(directives, `style-src '`+csp.Hash(pageCSS)+`'`)
+	} else {
+		directives = append(directives, `style-src '`+csp.Hash(pageCSS+string(b.CSS))+`'`)
+		if b.LogoURL != "" {
+			directives = append(directives, `img-src data:`)
+		}
+	}
+	directives = append(directives, `frame-ancestors 'none'`)
+	return strings.Join(directives, "; ")
+}
+
+// Cache holds the current branding. It is safe for concurrent use. A nil Cache always holds no branding.
+type Cache struct {
+	mu       sync.RWMutex
+	branding *Branding
+}
+
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// Get returns the current branding, or nil when the pages are unbranded.
+func (c *Cache) Get() *Branding {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.branding
+}
+
+// Set replaces the current branding. Setting nil leaves the pages unbranded.
+func (c *Cache) Set(branding *Branding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.branding = branding
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package branding
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"go.pinniped.dev/internal/oidc/provider/csp"
+)
+
+// A PNG image only needs its signature to be detected as a PNG.
+const testPNG = "\x89PNG\r\n\x1a\nsome-image-data"
+
+func TestFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       map[string]string
+		binaryData map[string][]byte
+		wantErr    string
+		wantCSS    []string
+		wantLogo   template.URL
+		wantFooter string
+	}{
+		{
+			name:    "empty bundle",
+			wantCSS: []string{".logo{", ".footer{"},
+		},
+		{
+			name: "full bundle",
+			data: map[string]string{
+				PrimaryColorKey:    "#1a2b3c",
+				BackgroundColorKey: "#4d5e6f",
+				FooterTextKey:      "Need help? Contact the <help desk>.",
+				CustomCSSKey:       "h1 { font-weight: bold; }",
+			},
+			binaryData: map[string][]byte{LogoKey: []byte(testPNG)},
+			wantCSS: []string{
+				"input[type=submit]:hover{background-color:#1a2b3c}",
+				"body{background:#4d5e6f}",
+				"h1{font-weight:700}",
+			},
+			wantLogo:   "data:image/png;base64,iVBORw0KGgpzb21lLWltYWdlLWRhdGE=",
+			wantFooter: "Need help? Contact the <help desk>.",
+		},
+		{
+			name:    "unknown keys",
+			data:    map[string]string{"primaryColour": "#1a2b3c", FooterTextKey: "some footer"},
+			wantErr: "unknown keys: primaryColour",
+		},
+		{
+			name:       "logo in data instead of binaryData",
+			data:       map[string]string{LogoKey: testPNG},
+			binaryData: map[string][]byte{"favicon": []byte(testPNG)},
+			wantErr:    "unknown keys: favicon, logo",
+		},
+		{
+			name:       "logo which is not an image",
+			binaryData: map[string][]byte{LogoKey: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)},
+			wantErr:    `logo must be a PNG, JPEG, GIF, or WebP image, but it was "text/plain; charset=utf-8"`,
+		},
+		{
+			name:       "logo which is too large",
+			binaryData: map[string][]byte{LogoKey: []byte(testPNG + strings.Repeat("x", maxLogoBytes))},
+			wantErr:    "logo must not be larger than 262144 bytes",
+		},
+		{
+			name:    "color which is not a hex color",
+			data:    map[string]string{PrimaryColorKey: "red;}body{display:none"},
+			wantErr: "primaryColor must be a hex color like #1a2b3c",
+		},
+		{
+			name:    "background color which is not a hex color",
+			data:    map[string]string{BackgroundColorKey: "#12345"},
+			wantErr: "backgroundColor must be a hex color like #1a2b3c",
+		},
+		{
+			name:    "footer text which is too long",
+			data:    map[string]string{FooterTextKey: strings.Repeat("x", 513)},
+			wantErr: "footerText must not be longer than 512 bytes",
+		},
+		{
+			name:    "custom CSS which could end the style element",
+			data:    map[string]string{CustomCSSKey: "h1{color:red}</style><script>alert(1)</script>"},
+			wantErr: `custom.css must not contain "<"`,
+		},
+		{
+			name:    "custom CSS which imports other stylesheets",
+			data:    map[string]string{CustomCSSKey: `@IMPORT url("https://example.com/evil.css");`},
+			wantErr: "custom.css must not import other stylesheets",
+		},
+		{
+			name:    "custom CSS which is too large",
+			data:    map[string]string{CustomCSSKey: strings.Repeat(" ", maxCustomCSSBytes+1)},
+			wantErr: "custom.css must not be larger than 65536 bytes",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromConfigMap(&corev1.ConfigMap{Data: tt.data, BinaryData: tt.binaryData})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, b)
+				return
+			}
+			require.NoError(t, err)
+			for _, wantCSS := range tt.wantCSS {
+				require.Contains(t, string(b.CSS), wantCSS)
+			}
+			require.NotContains(t, string(b.CSS), "<")
+			require.Equal(t, tt.wantLogo, b.LogoURL)
+			require.Equal(t, tt.wantFooter, b.FooterText)
+		})
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	const pageCSS = "h1{margin:0}"
+
+	var unbranded *Branding
+	require.Equal(t, "default-src 'none'; style-src '"+csp.Hash(pageCSS)+"'; frame-ancestors 'none'",
+		unbranded.ContentSecurityPolicy(pageCSS))
+
+	withoutLogo := &Branding{CSS: "body{background:#4d5e6f}"}
+	require.Equal(t, "default-src 'none'; style-src '"+csp.Hash(pageCSS+"body{background:#4d5e6f}")+"'; frame-ancestors 'none'",
+		withoutLogo.ContentSecurityPolicy(pageCSS))
+
+	withLogo := &Branding{CSS: "body{background:#4d5e6f}", LogoURL: "data:image/png;base64,iVBORw0KGgo="}
+	require.Equal(t, "default-src 'none'; style-src '"+csp.Hash(pageCSS+"body{background:#4d5e6f}")+"'; img-src data:; frame-ancestors 'none'",
+		withLogo.ContentSecurityPolicy(pageCSS))
+}
+
+func TestCache(t *testing.T) {
+	var nilCache *Cache
+	require.Nil(t, nilCache.Get())
+
+	cache := NewCache()
+	require.Nil(t, cache.Get())
+
+	b := &Branding{FooterText: "some footer"}
+	cache.Set(b)
+	require.Same(t, b, cache.Get())
+
+	cache.Set(nil)
+	require.Nil(t, cache.Get())
+}
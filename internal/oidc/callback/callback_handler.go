@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package callback provides a handler for the OIDC callback endpoint.
@@ -10,13 +10,17 @@ import (
 
 	"github.com/ory/fosite"
 
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/consent"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/oidc/provider/formposthtml"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
 )
 
 func NewHandler(
@@ -24,6 +28,8 @@ func NewHandler(
 	oauthHelper fosite.OAuth2Provider,
 	stateDecoder, cookieDecoder oidc.Decoder,
 	redirectURI string,
+	identityTransforms *idtransform.TransformationPipeline,
+	consentPrompter *consent.Prompter,
 ) http.Handler {
 	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
 		state, err := validateRequest(r, stateDecoder, cookieDecoder)
@@ -66,6 +72,7 @@ func NewHandler(
 		)
 		if err != nil {
 			plog.WarningErr("error exchanging and validating upstream tokens", err, "upstreamName", upstreamIDPConfig.GetName())
+			oidc.AuditLoginFailed(r, authorizeRequester, "", upstreamIDPConfig.GetName(), psession.ProviderTypeOIDC, "upstream token exchange failed")
 			return httperr.New(http.StatusBadGateway, "error exchanging and validating upstream tokens")
 		}
 
@@ -81,9 +88,22 @@ func NewHandler(
 			return httperr.Wrap(http.StatusUnprocessableEntity, err.Error(), err)
 		}
 
-		openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
+		username, groups, err = downstreamsession.ApplyIdentityTransforms(r.Context(), identityTransforms, customSessionData, username, groups)
+		if err != nil {
+			oidc.AuditLoginFailed(r, authorizeRequester, customSessionData.UpstreamUsername, upstreamIDPConfig.GetName(), psession.ProviderTypeOIDC, err.Error())
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), false,
+			)
+			return nil
+		}
+
+		openIDSession := downstreamsession.MakeDownstreamSession(authorizeRequester.GetID(), subject, username, groups,
 			authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, additionalClaims)
 
+		if prompted, err := consentPrompter.PromptIfRequired(w, r, authorizeRequester, openIDSession); err != nil || prompted {
+			return err
+		}
+
 		authorizeResponder, err := oauthHelper.NewAuthorizeResponse(r.Context(), authorizeRequester, openIDSession)
 		if err != nil {
 			plog.WarningErr("error while generating and saving authcode", err,
@@ -91,6 +111,7 @@ func NewHandler(
 			return httperr.Wrap(http.StatusInternalServerError, "error while generating and saving authcode", err)
 		}
 
+		auditlog.Log(auditlog.NewRecord(auditlog.EventLoginSucceeded, r, authorizeRequester))
 		oauthHelper.WriteAuthorizeResponse(r.Context(), w, authorizeRequester, authorizeResponder)
 
 		return nil
@@ -0,0 +1,182 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package chooseidp provides a handler for the page on which users choose an upstream IDP during a browser-based
+// login, when more than one upstream IDP is available and the client did not choose one.
+package chooseidp
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/chooseidp/chooseidphtml"
+	"go.pinniped.dev/internal/oidc/i18n"
+	"go.pinniped.dev/internal/plog"
+)
+
+const stateParamName = "state"
+
+// NewHandler returns a http.Handler that serves the page on which users choose an upstream IDP.
+//
+// The authorization endpoint redirects browsers to this page with a state param which holds the params of the
+// original authorization request, and which is protected by the CSRF cookie in the same way as the state param
+// of the login page. A GET request renders the available upstream IDPs by their display names. A POST request
+// chooses one of them, and redirects back to the authorization endpoint with the params of the original
+// authorization request, plus the params which choose the upstream IDP. From there, the login continues as if
+// the client had chosen the upstream IDP itself. The branding in the brandingCache, if any, is applied to the page,
+// and the page is translated to the language of the request using the translations.
+func NewHandler(
+	downstreamIssuer string,
+	postPath string,
+	idpLister oidc.FederationDomainIdentityProvidersListerI,
+	stateDecoder oidc.Decoder,
+	cookieDecoder oidc.Decoder,
+	brandingCache *branding.Cache,
+	translations *i18n.Catalogs,
+) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try GET or POST)", r.Method)
+		}
+
+		encodedState, decodedState, err := oidc.ReadStateParamAndValidateCSRFCookie(r, cookieDecoder, stateDecoder)
+		if err != nil {
+			plog.InfoErr("state or CSRF error", err)
+			return err
+		}
+
+		if decodedState.UpstreamName != "" || decodedState.UpstreamType != "" {
+			// This state param was made for an upstream IDP which was already chosen.
+			return httperr.New(http.StatusBadRequest, "state param was not made for choosing an upstream provider")
+		}
+
+		// The cache of IDPs could change at any time, so always recalculate the list.
+		identityProviders := listIdentityProviders(idpLister)
+
+		if r.Method == http.MethodGet {
+			// The branding can change at any time, so the CSP header must match the branding of this render.
+			brand := brandingCache.Get()
+			w.Header().Set("Content-Security-Policy", brand.ContentSecurityPolicy(chooseidphtml.CSS()))
+			return chooseidphtml.Template().Execute(w, &chooseidphtml.PageData{
+				State:             encodedState,
+				IdentityProviders: identityProviders,
+				PostPath:          postPath,
+				Branding:          brand,
+				Messages:          translations.ForRequest(r),
+			})
+		}
+
+		return redirectToAuthorizationEndpoint(w, r, downstreamIssuer, decodedState, identityProviders)
+	})
+
+	return securityheader.WrapWithCustomCSP(handler, chooseidphtml.ContentSecurityPolicy())
+}
+
+func listIdentityProviders(idpLister oidc.FederationDomainIdentityProvidersListerI) []chooseidphtml.IdentityProvider {
+	identityProviders := []chooseidphtml.IdentityProvider{}
+	for _, p := range idpLister.GetOIDCIdentityProviders() {
+		identityProviders = append(identityProviders, chooseidphtml.IdentityProvider{
+			DisplayName: idpLister.GetDisplayName(p.GetResourceUID(), p.GetName()),
+			Type:        string(idpdiscoveryv1alpha1.IDPTypeOIDC),
+		})
+	}
+	for _, p := range idpLister.GetLDAPIdentityProviders() {
+		identityProviders = append(identityProviders, chooseidphtml.IdentityProvider{
+			DisplayName: idpLister.GetDisplayName(p.GetResourceUID(), p.GetName()),
+			Type:        string(idpdiscoveryv1alpha1.IDPTypeLDAP),
+		})
+	}
+	for _, p := range idpLister.GetActiveDirectoryIdentityProviders() {
+		identityProviders = append(identityProviders, chooseidphtml.IdentityProvider{
+			DisplayName: idpLister.GetDisplayName(p.GetResourceUID(), p.GetName()),
+			Type:        string(idpdiscoveryv1alpha1.IDPTypeActiveDirectory),
+		})
+	}
+	for _, p := range idpLister.GetKerberosIdentityProviders() {
+		identityProviders = append(identityProviders, chooseidphtml.IdentityProvider{
+			DisplayName: idpLister.GetDisplayName(p.GetResourceUID(), p.GetName()),
+			Type:        string(idpdiscoveryv1alpha1.IDPTypeKerberos),
+		})
+	}
+
+	// List them in the same order as the IDP discovery endpoint.
+	sort.SliceStable(identityProviders, func(i, j int) bool {
+		return identityProviders[i].DisplayName < identityProviders[j].DisplayName
+	})
+	return identityProviders
+}
+
+func redirectToAuthorizationEndpoint(
+	w http.ResponseWriter,
+	r *http.Request,
+	downstreamIssuer string,
+	decodedState *oidc.UpstreamStateParamData,
+	identityProviders []chooseidphtml.IdentityProvider,
+) error {
+	chosen := chooseidphtml.IdentityProvider{
+		DisplayName: r.PostFormValue(oidcapi.AuthorizeUpstreamIDPNameParamName),
+		Type:        r.PostFormValue(oidcapi.AuthorizeUpstreamIDPTypeParamName),
+	}
+	found := false
+	for _, idp := range identityProviders {
+		if idp == chosen {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return httperr.Newf(http.StatusUnprocessableEntity, "Requested upstream provider was not found: %q", chosen.DisplayName)
+	}
+
+	authParams, err := url.ParseQuery(decodedState.AuthParams)
+	if err != nil {
+		return httperr.Wrap(http.StatusBadRequest, "error reading state", err)
+	}
+	authParams.Set(oidcapi.AuthorizeUpstreamIDPNameParamName, chosen.DisplayName)
+	authParams.Set(oidcapi.AuthorizeUpstreamIDPTypeParamName, chosen.Type)
+
+	authorizationURL, err := url.Parse(downstreamIssuer + oidc.AuthorizationEndpointPath)
+	if err != nil {
+		return err
+	}
+	authorizationURL.RawQuery = authParams.Encode()
+
+	http.Redirect(w, r,
+		authorizationURL.String(),
+		http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
+	)
+	return nil
+}
+
+// RedirectToChooseIDPPage redirects to the page on which users choose an upstream IDP, for the specified issuer.
+// The specified issuer should never end with a "/", which is validated by provider.FederationDomainIssuer when
+// the issuer string comes from that type.
+func RedirectToChooseIDPPage(
+	r *http.Request,
+	w http.ResponseWriter,
+	downstreamIssuer string,
+	encodedStateParamValue string,
+) error {
+	chooseIDPURL, err := url.Parse(downstreamIssuer + oidc.PinnipedChooseIDPPath)
+	if err != nil {
+		return err
+	}
+
+	q := chooseIDPURL.Query()
+	q.Set(stateParamName, encodedStateParamValue)
+	chooseIDPURL.RawQuery = q.Encode()
+
+	http.Redirect(w, r,
+		chooseIDPURL.String(),
+		http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
+	)
+
+	return nil
+}
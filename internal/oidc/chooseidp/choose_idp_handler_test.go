@@ -0,0 +1,325 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package chooseidp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/securecookie"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/chooseidp/chooseidphtml"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+const (
+	htmlContentType = "text/html; charset=utf-8"
+)
+
+func TestChooseIDPEndpoint(t *testing.T) {
+	const (
+		downstreamIssuer = "https://my-downstream-issuer.com/some-path"
+		happyPostPath    = "/some-path/choose_identity_provider"
+
+		happyDownstreamCSRF         = "test-csrf"
+		happyDownstreamPKCE         = "test-pkce"
+		happyDownstreamNonce        = "test-nonce"
+		happyDownstreamStateVersion = "2"
+
+		oidcUpstreamName = "some-oidc-idp"
+		oidcUpstreamUID  = types.UID("oidc-resource-uid")
+		ldapUpstreamName = "some-ldap-idp"
+		ldapUpstreamUID  = types.UID("ldap-resource-uid")
+	)
+
+	happyDownstreamRequestParamsQuery := url.Values{
+		"response_type":         []string{"code"},
+		"scope":                 []string{"openid"},
+		"client_id":             []string{"pinniped-cli"},
+		"state":                 []string{"8b-state"},
+		"nonce":                 []string{"some-nonce-value"},
+		"code_challenge":        []string{"some-challenge"},
+		"code_challenge_method": []string{"S256"},
+		"redirect_uri":          []string{"http://127.0.0.1/callback"},
+	}
+
+	happyUpstreamStateParam := func() *oidctestutil.UpstreamStateParamBuilder {
+		return &oidctestutil.UpstreamStateParamBuilder{
+			P: happyDownstreamRequestParamsQuery.Encode(),
+			N: happyDownstreamNonce,
+			C: happyDownstreamCSRF,
+			K: happyDownstreamPKCE,
+			V: happyDownstreamStateVersion,
+		}
+	}
+
+	expectedRedirectToAuthorizationEndpoint := func(idpName, idpType string) string {
+		query := url.Values{}
+		for k, v := range happyDownstreamRequestParamsQuery {
+			query[k] = v
+		}
+		query.Set("pinniped_idp_name", idpName)
+		query.Set("pinniped_idp_type", idpType)
+		return downstreamIssuer + "/oauth2/authorize?" + query.Encode()
+	}
+
+	stateEncoderHashKey := []byte("fake-hash-secret")
+	stateEncoderBlockKey := []byte("0123456789ABCDEF") // block encryption requires 16/24/32 bytes for AES
+	cookieEncoderHashKey := []byte("fake-hash-secret2")
+	cookieEncoderBlockKey := []byte("0123456789ABCDE2") // block encryption requires 16/24/32 bytes for AES
+
+	happyStateCodec := securecookie.New(stateEncoderHashKey, stateEncoderBlockKey)
+	happyStateCodec.SetSerializer(securecookie.JSONEncoder{})
+	happyCookieCodec := securecookie.New(cookieEncoderHashKey, cookieEncoderBlockKey)
+	happyCookieCodec.SetSerializer(securecookie.JSONEncoder{})
+
+	happyState := happyUpstreamStateParam().Build(t, happyStateCodec)
+
+	encodedIncomingCookieCSRFValue, err := happyCookieCodec.Encode("csrf", happyDownstreamCSRF)
+	require.NoError(t, err)
+	happyCSRFCookie := "__Host-pinniped-csrf=" + encodedIncomingCookieCSRFValue
+
+	happyIDPs := oidctestutil.NewUpstreamIDPListerBuilder().
+		WithOIDC(oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().WithName(oidcUpstreamName).WithResourceUID(oidcUpstreamUID).Build()).
+		WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: ldapUpstreamName, ResourceUID: ldapUpstreamUID})
+
+	tests := []struct {
+		name                 string
+		method               string
+		state                string
+		form                 url.Values
+		csrfCookie           string
+		federationDomainIDPs []provider.FederationDomainIdentityProvider
+		branding             *branding.Branding
+
+		wantStatus         int
+		wantContentType    string
+		wantBody           string
+		wantBodyContains   []string
+		wantLocationHeader string
+	}{
+		{
+			name:            "PUT method is invalid",
+			method:          http.MethodPut,
+			state:           happyState,
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantContentType: htmlContentType,
+			wantBody:        "Method Not Allowed: PUT (try GET or POST)\n",
+		},
+		{
+			name:            "state param was not included on GET request",
+			method:          http.MethodGet,
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: htmlContentType,
+			wantBody:        "Bad Request: state param not found\n",
+		},
+		{
+			name:            "state param was not signed correctly, has expired, or otherwise cannot be decoded for any reason on POST request",
+			method:          http.MethodPost,
+			state:           "this-will-not-decode",
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: htmlContentType,
+			wantBody:        "Bad Request: error reading state\n",
+		},
+		{
+			name:            "the CSRF cookie does not exist on GET request",
+			method:          http.MethodGet,
+			state:           happyState,
+			wantStatus:      http.StatusForbidden,
+			wantContentType: htmlContentType,
+			wantBody:        "Forbidden: CSRF cookie is missing\n",
+		},
+		{
+			name:            "the CSRF cookie does not exist on POST request",
+			method:          http.MethodPost,
+			state:           happyState,
+			form:            url.Values{"pinniped_idp_name": []string{ldapUpstreamName}, "pinniped_idp_type": []string{"ldap"}},
+			wantStatus:      http.StatusForbidden,
+			wantContentType: htmlContentType,
+			wantBody:        "Forbidden: CSRF cookie is missing\n",
+		},
+		{
+			name:            "cookie csrf value does not match state csrf value on POST request",
+			method:          http.MethodPost,
+			state:           happyUpstreamStateParam().WithCSRF("wrong-csrf-value").Build(t, happyStateCodec),
+			form:            url.Values{"pinniped_idp_name": []string{ldapUpstreamName}, "pinniped_idp_type": []string{"ldap"}},
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusForbidden,
+			wantContentType: htmlContentType,
+			wantBody:        "Forbidden: CSRF value does not match\n",
+		},
+		{
+			name:            "state param was made for an upstream IDP which was already chosen",
+			method:          http.MethodGet,
+			state:           happyUpstreamStateParam().WithUpstreamIDPType("ldap").Build(t, happyStateCodec),
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: htmlContentType,
+			wantBody:        "Bad Request: state param was not made for choosing an upstream provider\n",
+		},
+		{
+			name:            "happy GET request lists the upstream IDPs by name when the FederationDomain does not list any",
+			method:          http.MethodGet,
+			state:           happyState,
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBodyContains: []string{
+				`<form action="` + happyPostPath + `" method="post">`,
+				`<input type="hidden" name="state" value="` + happyState + `">`,
+				`<input type="hidden" name="pinniped_idp_name" value="` + ldapUpstreamName + `">`,
+				`<input type="hidden" name="pinniped_idp_type" value="ldap">`,
+				`<input type="hidden" name="pinniped_idp_name" value="` + oidcUpstreamName + `">`,
+				`<input type="hidden" name="pinniped_idp_type" value="oidc">`,
+			},
+		},
+		{
+			name:            "happy GET request applies the branding",
+			method:          http.MethodGet,
+			state:           happyState,
+			csrfCookie:      happyCSRFCookie,
+			branding:        &branding.Branding{CSS: "body{background:#4d5e6f}", FooterText: "some footer"},
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBodyContains: []string{
+				`body{background:#4d5e6f}</style>`,
+				`<footer class="footer">some footer</footer>`,
+			},
+		},
+		{
+			name:       "happy GET request lists the upstream IDPs by display name when the FederationDomain lists them",
+			method:     http.MethodGet,
+			state:      happyState,
+			csrfCookie: happyCSRFCookie,
+			federationDomainIDPs: []provider.FederationDomainIdentityProvider{
+				{DisplayName: "My Directory", UID: ldapUpstreamUID},
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBodyContains: []string{
+				`<input type="hidden" name="pinniped_idp_name" value="My Directory">`,
+				`<input type="submit" value="Log in with My Directory"/>`,
+			},
+		},
+		{
+			name:               "happy POST request redirects to the authorization endpoint with the chosen upstream IDP",
+			method:             http.MethodPost,
+			state:              happyState,
+			form:               url.Values{"pinniped_idp_name": []string{ldapUpstreamName}, "pinniped_idp_type": []string{"ldap"}},
+			csrfCookie:         happyCSRFCookie,
+			wantStatus:         http.StatusSeeOther,
+			wantContentType:    "",
+			wantLocationHeader: expectedRedirectToAuthorizationEndpoint(ldapUpstreamName, "ldap"),
+		},
+		{
+			name:       "happy POST request chooses an upstream IDP by its display name",
+			method:     http.MethodPost,
+			state:      happyState,
+			form:       url.Values{"pinniped_idp_name": []string{"My OIDC"}, "pinniped_idp_type": []string{"oidc"}},
+			csrfCookie: happyCSRFCookie,
+			federationDomainIDPs: []provider.FederationDomainIdentityProvider{
+				{DisplayName: "My OIDC", UID: oidcUpstreamUID},
+				{DisplayName: "My LDAP", UID: ldapUpstreamUID},
+			},
+			wantStatus:         http.StatusSeeOther,
+			wantContentType:    "",
+			wantLocationHeader: expectedRedirectToAuthorizationEndpoint("My OIDC", "oidc"),
+		},
+		{
+			name:            "POST request chooses an upstream IDP which does not exist",
+			method:          http.MethodPost,
+			state:           happyState,
+			form:            url.Values{"pinniped_idp_name": []string{"does-not-exist"}, "pinniped_idp_type": []string{"ldap"}},
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: htmlContentType,
+			wantBody:        "Unprocessable Entity: Requested upstream provider was not found: \"does-not-exist\"\n",
+		},
+		{
+			name:            "POST request chooses an upstream IDP by name but with the wrong type",
+			method:          http.MethodPost,
+			state:           happyState,
+			form:            url.Values{"pinniped_idp_name": []string{ldapUpstreamName}, "pinniped_idp_type": []string{"oidc"}},
+			csrfCookie:      happyCSRFCookie,
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: htmlContentType,
+			wantBody:        "Unprocessable Entity: Requested upstream provider was not found: \"" + ldapUpstreamName + "\"\n",
+		},
+		{
+			name:       "POST request chooses an upstream IDP which is not listed by the FederationDomain",
+			method:     http.MethodPost,
+			state:      happyState,
+			form:       url.Values{"pinniped_idp_name": []string{oidcUpstreamName}, "pinniped_idp_type": []string{"oidc"}},
+			csrfCookie: happyCSRFCookie,
+			federationDomainIDPs: []provider.FederationDomainIdentityProvider{
+				{DisplayName: "My LDAP", UID: ldapUpstreamUID},
+			},
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: htmlContentType,
+			wantBody:        "Unprocessable Entity: Requested upstream provider was not found: \"" + oidcUpstreamName + "\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			form := url.Values{}
+			for k, v := range tt.form {
+				form[k] = v
+			}
+			if tt.state != "" {
+				form.Set("state", tt.state)
+			}
+
+			var req *http.Request
+			if tt.method == http.MethodPost {
+				req = httptest.NewRequest(tt.method, "/choose_identity_provider", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(tt.method, "/choose_identity_provider?"+form.Encode(), nil)
+			}
+			if tt.csrfCookie != "" {
+				req.Header.Set("Cookie", tt.csrfCookie)
+			}
+			rsp := httptest.NewRecorder()
+
+			idpLister := provider.NewFederationDomainIdentityProvidersLister(happyIDPs.Build(), tt.federationDomainIDPs)
+			brandingCache := branding.NewCache()
+			brandingCache.Set(tt.branding)
+			subject := NewHandler(downstreamIssuer, happyPostPath, idpLister, happyStateCodec, happyCookieCodec, brandingCache, nil)
+
+			subject.ServeHTTP(rsp, req)
+
+			testutil.RequireSecurityHeadersWithLoginPageCSPs(t, rsp)
+			if tt.method == http.MethodGet && tt.wantStatus == http.StatusOK {
+				require.Equal(t, tt.branding.ContentSecurityPolicy(chooseidphtml.CSS()), rsp.Header().Get("Content-Security-Policy"))
+			}
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			testutil.RequireEqualContentType(t, rsp.Header().Get("Content-Type"), tt.wantContentType)
+			require.Equal(t, tt.wantLocationHeader, rsp.Header().Get("Location"))
+			if tt.wantBodyContains != nil {
+				for _, want := range tt.wantBodyContains {
+					require.Contains(t, rsp.Body.String(), want)
+				}
+			} else if tt.wantLocationHeader == "" {
+				require.Equal(t, tt.wantBody, rsp.Body.String())
+			}
+		})
+	}
+}
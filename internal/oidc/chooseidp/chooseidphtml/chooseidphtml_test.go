@@ -0,0 +1,142 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package chooseidphtml
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/i18n"
+)
+
+var (
+	testExpectedCSS = `html{height:100%}body{font-family:metropolis-light,Helvetica,sans-serif;display:flex;flex-flow:column wrap;justify-content:flex-start;align-items:center;background:linear-gradient(to top,#f8f8f8,white);min-height:100%}h1{font-size:20px;margin:0}.box{display:flex;flex-direction:column;flex-wrap:nowrap;border-radius:4px;border-color:#ddd;border-width:1px;border-style:solid;width:400px;padding:30px 30px 0;margin:60px 20px 0;background:#fff;font-size:14px}input{color:inherit;font:inherit;border:0;margin:0;outline:0;padding:0}.form-field{display:flex;margin-bottom:30px}.form-field input[type=submit]{width:100%;padding:1em;background-color:#218fcf;color:#eee;font-weight:700;cursor:pointer;transition:all .3s}.form-field input[type=submit]:focus,.form-field input[type=submit]:hover{background-color:#1abfd3}.form-field input[type=submit]:active{transform:scale(.99)}`
+
+	// It's okay if this changes in the future, but this gives us a chance to eyeball the formatting.
+	// Our browser-based integration tests should find any incompatibilities.
+	testExpectedCSP = `default-src 'none'; ` +
+		`style-src 'sha256-DH2nV1Mouyvt/oUL1XcxRpkIeZ+UJ1u5yhFwCMkfzjo='; ` +
+		`frame-ancestors 'none'`
+)
+
+func TestTemplate(t *testing.T) {
+	const (
+		testPath         = "test-post-path"
+		testEncodedState = "test-encoded-state"
+	)
+
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		PostPath: testPath,
+		State:    testEncodedState,
+		IdentityProviders: []IdentityProvider{
+			{DisplayName: "test-oidc-idp", Type: "oidc"},
+			{DisplayName: "test-ldap-idp", Type: "ldap"},
+		},
+	}
+
+	expectedHTML := here.Docf(`<!DOCTYPE html>
+        <html lang="en">
+        <head>
+            <title>Pinniped Login</title>
+            <meta charset="UTF-8">
+            <style>%s</style>
+        </head>
+        <body>
+        <div class="box" aria-label="choose identity provider" role="main">
+            <div class="form-field">
+                <h1>Choose an identity provider</h1>
+            </div>
+            <form action="%s" method="post">
+                <input type="hidden" name="state" value="%s">
+                <input type="hidden" name="pinniped_idp_name" value="test-oidc-idp">
+                <input type="hidden" name="pinniped_idp_type" value="oidc">
+                <div class="form-field">
+                    <input type="submit" value="Log in with test-oidc-idp"/>
+                </div>
+            </form>
+            <form action="%s" method="post">
+                <input type="hidden" name="state" value="%s">
+                <input type="hidden" name="pinniped_idp_name" value="test-ldap-idp">
+                <input type="hidden" name="pinniped_idp_type" value="ldap">
+                <div class="form-field">
+                    <input type="submit" value="Log in with test-ldap-idp"/>
+                </div>
+            </form>
+        </div>
+        </body>
+        </html>
+	`,
+		testExpectedCSS,
+		testPath, testEncodedState,
+		testPath, testEncodedState,
+	)
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	// t.Logf("actual value:\n%s", buf.String()) // useful when updating minify library causes new output
+	require.Equal(t, expectedHTML, buf.String())
+}
+
+func TestTemplateWithBranding(t *testing.T) {
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		PostPath:          "test-post-path",
+		State:             "test-encoded-state",
+		IdentityProviders: []IdentityProvider{{DisplayName: "test-oidc-idp", Type: "oidc"}},
+		Branding: &branding.Branding{
+			CSS:        "body{background:#4d5e6f}",
+			FooterText: "Contact the <help desk>",
+		},
+	}
+
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	require.Contains(t, buf.String(), "<style>"+testExpectedCSS+"body{background:#4d5e6f}</style>")
+	require.Contains(t, buf.String(), `<div class="box" aria-label="choose identity provider" role="main">`+"\n")
+	require.NotContains(t, buf.String(), "<img")
+	require.Contains(t, buf.String(), "</div><footer class=\"footer\">Contact the &lt;help desk&gt;</footer>\n</body>")
+}
+
+func TestTemplateInAnotherLanguage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		PostPath:          "test-post-path",
+		State:             "test-encoded-state",
+		IdentityProviders: []IdentityProvider{{DisplayName: "test-oidc-idp", Type: "oidc"}},
+		Messages:          i18n.NewCatalogs().ForRequest(req),
+	}
+
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	for _, want := range []string{
+		`<html lang="de">`,
+		`<title>Pinniped-Anmeldung</title>`,
+		`<div class="box" aria-label="Identitätsanbieter auswählen" role="main">`,
+		`<h1>Wählen Sie einen Identitätsanbieter</h1>`,
+		`<input type="hidden" name="pinniped_idp_name" value="test-oidc-idp">`,
+		`<input type="submit" value="Mit test-oidc-idp anmelden"/>`,
+	} {
+		require.Contains(t, buf.String(), want)
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	require.Equal(t, testExpectedCSP, ContentSecurityPolicy())
+}
+
+func TestCSS(t *testing.T) {
+	require.Equal(t, testExpectedCSS, CSS())
+}
+
+func TestHelpers(t *testing.T) {
+	require.Equal(t, "test", panicOnError("test", nil))
+	require.PanicsWithError(t, "some error", func() { panicOnError("", fmt.Errorf("some error")) })
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/pkg/errors"
+	"k8s.io/utils/strings/slices"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/psession"
+)
+
+// NewClientCredentialsFactory returns a factory for the ClientCredentialsHandler.
+func NewClientCredentialsFactory() compose.Factory {
+	return func(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+		return &ClientCredentialsHandler{
+			accessTokenStrategy: strategy.(oauth2.AccessTokenStrategy),
+			accessTokenStorage:  storage.(oauth2.AccessTokenStorage),
+			fositeConfig:        config,
+		}
+	}
+}
+
+// ClientCredentialsHandler handles the client_credentials grant of RFC6749, which OIDCClients use to get access tokens
+// for themselves, e.g. to call other services. Unlike fosite's handler for this grant, it only grants the scopes and
+// audiences which were configured for this grant on the OIDCClient, and it marks the session of the access token as
+// the identity of a client, so that resource servers can never mistake the access token for the one of a user.
+type ClientCredentialsHandler struct {
+	accessTokenStrategy oauth2.AccessTokenStrategy
+	accessTokenStorage  oauth2.AccessTokenStorage
+	fositeConfig        fosite.Configurator
+}
+
+var _ fosite.TokenEndpointHandler = (*ClientCredentialsHandler)(nil)
+
+func (c *ClientCredentialsHandler) HandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errors.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	// Check that the client is allowed to perform this grant type. Only OIDCClients can be allowed to.
+	client, ok := requester.GetClient().(*clientregistry.Client)
+	if !ok || !client.GetGrantTypes().Has(oidcapi.GrantTypeClientCredentials) {
+		// This error message is trying to be similar to the analogous one in fosite's flow_authorize_code_token.go.
+		return errors.WithStack(fosite.ErrUnauthorizedClient.WithHintf(`The OAuth 2.0 Client is not allowed to use authorization grant "%s".`, oidcapi.GrantTypeClientCredentials))
+	}
+
+	// Public clients cannot authenticate, so they cannot prove that they are the identity of the access token.
+	if client.IsPublic() {
+		return errors.WithStack(fosite.ErrInvalidGrant.WithHintf(`The OAuth 2.0 Client is public and is thus not allowed to use authorization grant "%s".`, oidcapi.GrantTypeClientCredentials))
+	}
+
+	// Check that the client is allowed to request each scope and each audience. Note that these are not the allowed
+	// scopes of the client, which are about users.
+	for _, scope := range requester.GetRequestedScopes() {
+		if !slices.Contains(client.ClientCredentialsScopes, scope) {
+			return errors.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+	}
+	for _, audience := range requester.GetRequestedAudience() {
+		if !slices.Contains(client.ClientCredentialsAudiences, audience) {
+			return errors.WithStack(errInvalidTarget().WithHintf("The requested audience %q is not allowed for this client.", audience))
+		}
+	}
+
+	session, ok := requester.GetSession().(*psession.PinnipedSession)
+	if !ok || session.Fosite == nil || session.Fosite.Claims == nil {
+		// This shouldn't really happen.
+		return errors.WithStack(fosite.ErrServerError.WithHint("Invalid session storage."))
+	}
+
+	for _, scope := range requester.GetRequestedScopes() {
+		requester.GrantScope(scope)
+	}
+	for _, audience := range requester.GetRequestedAudience() {
+		requester.GrantAudience(audience)
+	}
+
+	// The client is the subject of its own access tokens. There is no user and no upstream identity provider, so the
+	// custom session data stays empty, which also means that there is nothing upstream to revoke later.
+	session.Fosite.Subject = client.GetID()
+	session.Fosite.Claims.Subject = client.GetID()
+	if session.Fosite.Claims.Extra == nil {
+		session.Fosite.Claims.Extra = map[string]interface{}{}
+	}
+	session.Fosite.Claims.Extra[oidcapi.ClaimIdentityType] = oidcapi.IdentityTypeClient
+	session.Custom = &psession.CustomSessionData{}
+
+	lifespan := fosite.GetEffectiveLifespan(client, oidcapi.GrantTypeClientCredentials, fosite.AccessToken, c.fositeConfig.GetAccessTokenLifespan(ctx))
+	session.SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(lifespan).Round(time.Second))
+
+	return nil
+}
+
+func (c *ClientCredentialsHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) error {
+	// Skip this request if it's for a different grant type.
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errors.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	token, signature, err := c.accessTokenStrategy.GenerateAccessToken(ctx, requester)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// Store the access token, so it can be introspected and revoked like the access tokens of users.
+	if err := c.accessTokenStorage.CreateAccessTokenSession(ctx, signature, requester.Sanitize([]string{})); err != nil {
+		return errors.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	record := auditlog.NewRecord(auditlog.EventClientTokenIssued, nil, requester)
+	record.SourceIP = authenticators.ClientIPFromContext(ctx)
+	record.Audience = strings.Join(requester.GetGrantedAudience(), " ")
+	auditlog.Log(record)
+
+	responder.SetAccessToken(token)
+	responder.SetTokenType("bearer")
+	responder.SetExpiresIn(time.Until(requester.GetSession().GetExpiresAt(fosite.AccessToken)).Round(time.Second))
+	responder.SetScopes(requester.GetGrantedScopes())
+	return nil
+}
+
+func (c *ClientCredentialsHandler) CanSkipClientAuth(_ context.Context, _ fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *ClientCredentialsHandler) CanHandleTokenEndpointRequest(_ context.Context, requester fosite.AccessRequester) bool {
+	return requester.GetGrantTypes().ExactOne(oidcapi.GrantTypeClientCredentials)
+}
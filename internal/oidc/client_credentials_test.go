@@ -0,0 +1,141 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/psession"
+)
+
+func TestClientCredentialsHandler_HandleTokenEndpointRequest(t *testing.T) {
+	const clientID = "client.oauth.pinniped.dev-some-service"
+
+	newClient := func(grantTypes ...string) *clientregistry.Client {
+		return &clientregistry.Client{
+			DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{
+				ID:         clientID,
+				GrantTypes: grantTypes,
+				Scopes:     fosite.Arguments{"openid"},
+			}},
+			AccessTokenLifespan:        10 * time.Minute,
+			ClientCredentialsScopes:    []string{"orders:read", "orders:write"},
+			ClientCredentialsAudiences: []string{"https://orders.example.com"},
+		}
+	}
+
+	publicClient := newClient("authorization_code", "client_credentials")
+	publicClient.Public = true
+
+	tests := []struct {
+		name              string
+		grantType         string
+		client            fosite.Client
+		requestedScopes   fosite.Arguments
+		requestedAudience fosite.Arguments
+		wantErr           *fosite.RFC6749Error
+		wantErrHint       string
+	}{
+		{
+			name:              "grants the requested scopes and audience",
+			grantType:         "client_credentials",
+			client:            newClient("authorization_code", "client_credentials"),
+			requestedScopes:   fosite.Arguments{"orders:read"},
+			requestedAudience: fosite.Arguments{"https://orders.example.com"},
+		},
+		{
+			name:      "grants nothing when nothing was requested",
+			grantType: "client_credentials",
+			client:    newClient("authorization_code", "client_credentials"),
+		},
+		{
+			name:      "other grant types are not handled",
+			grantType: "authorization_code",
+			client:    newClient("authorization_code", "client_credentials"),
+			wantErr:   fosite.ErrUnknownRequest,
+		},
+		{
+			name:        "public clients are rejected",
+			grantType:   "client_credentials",
+			client:      publicClient,
+			wantErr:     fosite.ErrInvalidGrant,
+			wantErrHint: `The OAuth 2.0 Client is public and is thus not allowed to use authorization grant "client_credentials".`,
+		},
+		{
+			name:        "clients which are not from the client registry are rejected",
+			grantType:   "client_credentials",
+			client:      &fosite.DefaultClient{ID: clientID, GrantTypes: fosite.Arguments{"client_credentials"}},
+			wantErr:     fosite.ErrUnauthorizedClient,
+			wantErrHint: `The OAuth 2.0 Client is not allowed to use authorization grant "client_credentials".`,
+		},
+		{
+			name:        "clients which are not allowed to use the grant are rejected",
+			grantType:   "client_credentials",
+			client:      newClient("authorization_code"),
+			wantErr:     fosite.ErrUnauthorizedClient,
+			wantErrHint: `The OAuth 2.0 Client is not allowed to use authorization grant "client_credentials".`,
+		},
+		{
+			name:            "scopes which are not allowed for the grant are rejected, even when they are allowed for the client",
+			grantType:       "client_credentials",
+			client:          newClient("authorization_code", "client_credentials"),
+			requestedScopes: fosite.Arguments{"orders:read", "openid"},
+			wantErr:         fosite.ErrInvalidScope,
+			wantErrHint:     "The OAuth 2.0 Client is not allowed to request scope 'openid'.",
+		},
+		{
+			name:              "audiences which are not allowed for the grant are rejected",
+			grantType:         "client_credentials",
+			client:            newClient("authorization_code", "client_credentials"),
+			requestedAudience: fosite.Arguments{"https://payments.example.com"},
+			wantErr:           errInvalidTarget(),
+			wantErrHint:       `The requested audience "https://payments.example.com" is not allowed for this client.`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			subject := &ClientCredentialsHandler{fositeConfig: &fosite.Config{AccessTokenLifespan: time.Minute}}
+
+			session := psession.NewPinnipedSession()
+			requester := fosite.NewAccessRequest(session)
+			requester.GrantTypes = fosite.Arguments{tt.grantType}
+			requester.Client = tt.client
+			requester.RequestedScope = tt.requestedScopes
+			requester.RequestedAudience = tt.requestedAudience
+
+			before := time.Now().UTC()
+			err := subject.HandleTokenEndpointRequest(context.Background(), requester)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), "unexpected error: %v", err)
+				if tt.wantErrHint != "" {
+					var rfc6749Error *fosite.RFC6749Error
+					require.True(t, errors.As(err, &rfc6749Error))
+					require.Equal(t, tt.wantErrHint, rfc6749Error.HintField)
+				}
+				require.Empty(t, requester.GetGrantedScopes())
+				require.Empty(t, requester.GetGrantedAudience())
+				return
+			}
+			require.NoError(t, err)
+
+			require.ElementsMatch(t, tt.requestedScopes, requester.GetGrantedScopes())
+			require.ElementsMatch(t, tt.requestedAudience, requester.GetGrantedAudience())
+			require.Equal(t, clientID, session.GetSubject())
+			require.Equal(t, clientID, session.IDTokenClaims().Subject)
+			require.Equal(t, "client", session.IDTokenClaims().Extra["identity_type"])
+			require.Nil(t, session.IDTokenClaims().Extra["username"])
+			require.Equal(t, &psession.CustomSessionData{}, session.Custom)
+			// The lifespan of the access token is the one of the client.
+			require.WithinDuration(t, before.Add(10*time.Minute), session.GetExpiresAt(fosite.AccessToken), 2*time.Second)
+		})
+	}
+}
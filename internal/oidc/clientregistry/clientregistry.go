@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package clientregistry defines Pinniped's OAuth2/OIDC clients.
@@ -27,15 +27,125 @@ import (
 // or a dynamic client defined by an OIDCClient CR.
 type Client struct {
 	fosite.DefaultOpenIDConnectClient
+
+	// PostLogoutRedirectURIs are the allowed post_logout_redirect_uri param values for the end session endpoint.
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris,omitempty"`
+
+	// BackchannelLogoutURI is where logout tokens are sent when a downstream session of this client ends.
+	BackchannelLogoutURI string `json:"backchannel_logout_uri,omitempty"`
+
+	// IDTokenLifespan, AccessTokenLifespan, and RefreshTokenLifespan are the lifespans of the tokens issued to
+	// this client. Zero means that the lifespan configured for the FederationDomain should be used.
+	IDTokenLifespan      time.Duration `json:"id_token_lifespan,omitempty"`
+	AccessTokenLifespan  time.Duration `json:"access_token_lifespan,omitempty"`
+	RefreshTokenLifespan time.Duration `json:"refresh_token_lifespan,omitempty"`
+
+	// TokenExchangeAudiences are the audiences which this client may request during token exchanges. Empty means
+	// that the audiences configured for the FederationDomain should be used.
+	TokenExchangeAudiences []string `json:"token_exchange_audiences,omitempty"`
+
+	// AllowedGroupNames and AllowedGroupPrefixes select the groups which are included in the ID tokens issued to
+	// this client. When both are empty, all groups are included.
+	AllowedGroupNames    []string `json:"allowed_group_names,omitempty"`
+	AllowedGroupPrefixes []string `json:"allowed_group_prefixes,omitempty"`
+
+	// JWTAccessTokens is true when the access tokens issued to this client should be signed JWTs instead of
+	// opaque tokens.
+	JWTAccessTokens bool `json:"jwt_access_tokens,omitempty"`
+
+	// RequireConsent is true when users must approve the scopes requested by this client on the consent page before
+	// this client receives an authorization code.
+	RequireConsent bool `json:"require_consent,omitempty"`
+
+	// ClientCredentialsScopes and ClientCredentialsAudiences are the scopes and audiences which this client may
+	// request when it gets access tokens for itself using the client_credentials grant.
+	ClientCredentialsScopes    []string `json:"client_credentials_scopes,omitempty"`
+	ClientCredentialsAudiences []string `json:"client_credentials_audiences,omitempty"`
 }
 
-// Client implements the base, OIDC, and response_mode client interfaces of Fosite.
+// MaxJWTAccessTokenLifespan is the longest lifespan of JWT access tokens. JWT access tokens can be validated locally
+// by resource servers even after they are revoked, so they are always short-lived.
+const MaxJWTAccessTokenLifespan = 5 * time.Minute
+
+// Client implements the base, OIDC, response_mode, and custom token lifespan client interfaces of Fosite.
 var (
-	_ fosite.Client              = (*Client)(nil)
-	_ fosite.OpenIDConnectClient = (*Client)(nil)
-	_ fosite.ResponseModeClient  = (*Client)(nil)
+	_ fosite.Client                         = (*Client)(nil)
+	_ fosite.OpenIDConnectClient            = (*Client)(nil)
+	_ fosite.ResponseModeClient             = (*Client)(nil)
+	_ fosite.ClientWithCustomTokenLifespans = (*Client)(nil)
 )
 
+// GetPostLogoutRedirectURIs returns the allowed post_logout_redirect_uri param values for the end session endpoint.
+func (c *Client) GetPostLogoutRedirectURIs() []string {
+	return c.PostLogoutRedirectURIs
+}
+
+// GetBackchannelLogoutURI returns the URI to which logout tokens should be sent, or "" when the client did not
+// register for back-channel logout.
+func (c *Client) GetBackchannelLogoutURI() string {
+	return c.BackchannelLogoutURI
+}
+
+// GetEffectiveLifespan returns the lifespan of the given type of token for this client, or the fallback
+// when the client does not configure its own lifespan for that type of token.
+func (c *Client) GetEffectiveLifespan(_ fosite.GrantType, tt fosite.TokenType, fallback time.Duration) time.Duration {
+	var lifespan time.Duration
+	switch tt { //nolint:exhaustive // the other token types always use the fallback
+	case fosite.IDToken:
+		lifespan = c.IDTokenLifespan
+	case fosite.AccessToken:
+		lifespan = c.AccessTokenLifespan
+	case fosite.RefreshToken:
+		lifespan = c.RefreshTokenLifespan
+	}
+	if lifespan <= 0 {
+		lifespan = fallback
+	}
+	if tt == fosite.AccessToken && c.JWTAccessTokens && lifespan > MaxJWTAccessTokenLifespan {
+		return MaxJWTAccessTokenLifespan
+	}
+	return lifespan
+}
+
+// UsesJWTAccessTokens returns true when the access tokens issued to this client should be signed JWTs.
+func (c *Client) UsesJWTAccessTokens() bool {
+	return c.JWTAccessTokens
+}
+
+// FiltersGroups returns true when this client only allows some groups to be included in its ID tokens.
+func (c *Client) FiltersGroups() bool {
+	return len(c.AllowedGroupNames) > 0 || len(c.AllowedGroupPrefixes) > 0
+}
+
+// FilterGroups returns those of the given groups which may be included in the ID tokens issued to this client,
+// in their original order.
+func (c *Client) FilterGroups(groups []string) []string {
+	if !c.FiltersGroups() {
+		return groups
+	}
+	filtered := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if c.groupIsAllowed(group) {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered
+}
+
+func (c *Client) groupIsAllowed(group string) bool {
+	for _, name := range c.AllowedGroupNames {
+		if group == name {
+			return true
+		}
+	}
+	for _, prefix := range c.AllowedGroupPrefixes {
+		if strings.HasPrefix(group, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Client) GetResponseModes() []fosite.ResponseModeType {
 	if c.ID == oidcapi.ClientIDPinnipedCLI {
 		// The pinniped-cli client supports "" (unspecified), "query", and "form_post" response modes.
@@ -74,7 +184,7 @@ func NewClientManager(
 func (m *ClientManager) GetClient(ctx context.Context, id string) (fosite.Client, error) {
 	if id == oidcapi.ClientIDPinnipedCLI {
 		// Return the static client. No lookups needed.
-		return PinnipedCLI(), nil
+		return limitRedirectURIsToRequest(ctx, PinnipedCLI()), nil
 	}
 
 	if !strings.HasPrefix(id, oidcapi.ClientIDRequiredOIDCClientPrefix) {
@@ -111,7 +221,7 @@ func (m *ClientManager) GetClient(ctx context.Context, id string) (fosite.Client
 	}
 
 	// Everything is valid, so return the client. Note that it has at least one client secret to be considered valid.
-	return oidcClientCRToFositeClient(oidcClient, clientSecrets), nil
+	return limitRedirectURIsToRequest(ctx, oidcClientCRToFositeClient(oidcClient, clientSecrets)), nil
 }
 
 // ClientAssertionJWTValid returns an error if the JTI is
@@ -144,6 +254,7 @@ func PinnipedCLI() *Client {
 					oidcapi.GrantTypeAuthorizationCode,
 					oidcapi.GrantTypeRefreshToken,
 					oidcapi.GrantTypeTokenExchange,
+					oidcapi.GrantTypeDeviceCode,
 				},
 				ResponseTypes: []string{"code"},
 				Scopes: fosite.Arguments{
@@ -192,6 +303,18 @@ func oidcClientCRToFositeClient(oidcClient *configv1alpha1.OIDCClient, clientSec
 			TokenEndpointAuthSigningAlgorithm: coreosoidc.RS256,
 			TokenEndpointAuthMethod:           "client_secret_basic",
 		},
+		PostLogoutRedirectURIs:     redirectURIsToStrings(oidcClient.Spec.AllowedPostLogoutRedirectURIs),
+		BackchannelLogoutURI:       string(oidcClient.Spec.BackchannelLogoutURI),
+		IDTokenLifespan:            secondsToDuration(oidcClient.Spec.TokenLifetimes.IDTokenSeconds),
+		AccessTokenLifespan:        secondsToDuration(oidcClient.Spec.TokenLifetimes.AccessTokenSeconds),
+		RefreshTokenLifespan:       secondsToDuration(oidcClient.Spec.TokenLifetimes.RefreshTokenSeconds),
+		TokenExchangeAudiences:     tokenExchangeAudiencesToStrings(oidcClient.Spec.AllowedTokenExchangeAudiences),
+		AllowedGroupNames:          oidcClient.Spec.GroupsFilter.Names,
+		AllowedGroupPrefixes:       oidcClient.Spec.GroupsFilter.Prefixes,
+		JWTAccessTokens:            oidcClient.Spec.AccessTokenFormat == configv1alpha1.AccessTokenFormatJWT,
+		RequireConsent:             oidcClient.Spec.RequireConsent,
+		ClientCredentialsScopes:    oidcClient.Spec.ClientCredentials.AllowedScopes,
+		ClientCredentialsAudiences: oidcClient.Spec.ClientCredentials.AllowedAudiences,
 	}
 }
 
@@ -219,6 +342,14 @@ func redirectURIsToStrings(uris []configv1alpha1.RedirectURI) []string {
 	return s
 }
 
+func tokenExchangeAudiencesToStrings(audiences []configv1alpha1.TokenExchangeAudience) []string {
+	s := make([]string, len(audiences))
+	for i, audience := range audiences {
+		s[i] = string(audience)
+	}
+	return s
+}
+
 func stringSliceToByteSlices(s []string) [][]byte {
 	b := make([][]byte, len(s))
 	for i, str := range s {
@@ -226,3 +357,10 @@ func stringSliceToByteSlices(s []string) [][]byte {
 	}
 	return b
 }
+
+func secondsToDuration(seconds *int32) time.Duration {
+	if seconds == nil {
+		return 0
+	}
+	return time.Duration(*seconds) * time.Second
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package clientregistry
@@ -18,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
@@ -185,9 +186,17 @@ func TestClientManager(t *testing.T) {
 				{
 					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 					Spec: configv1alpha1.OIDCClientSpec{
-						AllowedGrantTypes:   []configv1alpha1.GrantType{"authorization_code", "urn:ietf:params:oauth:grant-type:token-exchange", "refresh_token"},
-						AllowedScopes:       []configv1alpha1.Scope{"openid", "offline_access", "pinniped:request-audience", "username", "groups"},
-						AllowedRedirectURIs: []configv1alpha1.RedirectURI{"http://localhost:80", "https://foobar.com/callback"},
+						AllowedGrantTypes:             []configv1alpha1.GrantType{"authorization_code", "urn:ietf:params:oauth:grant-type:token-exchange", "refresh_token"},
+						AllowedScopes:                 []configv1alpha1.Scope{"openid", "offline_access", "pinniped:request-audience", "username", "groups"},
+						AllowedRedirectURIs:           []configv1alpha1.RedirectURI{"http://127.0.0.1:80", "https://foobar.com/callback"},
+						AllowedPostLogoutRedirectURIs: []configv1alpha1.RedirectURI{"https://foobar.com/logged_out"},
+						BackchannelLogoutURI:          "https://foobar.com/backchannel_logout",
+						AllowedTokenExchangeAudiences: []configv1alpha1.TokenExchangeAudience{"cluster-1", "cluster-2"},
+						GroupsFilter:                  configv1alpha1.GroupsFilter{Names: []string{"admins"}, Prefixes: []string{"team-"}},
+						TokenLifetimes: configv1alpha1.TokenLifetimes{
+							IDTokenSeconds:      pointer.Int32(300),
+							RefreshTokenSeconds: pointer.Int32(3600),
+						},
 					},
 				},
 				{
@@ -208,7 +217,7 @@ func TestClientManager(t *testing.T) {
 				require.Len(t, c.GetRotatedHashes(), 2)
 				require.Equal(t, testutil.HashedPassword1AtSupervisorMinCost, string(c.GetRotatedHashes()[0]))
 				require.Equal(t, testutil.HashedPassword2AtSupervisorMinCost, string(c.GetRotatedHashes()[1]))
-				require.Equal(t, []string{"http://localhost:80", "https://foobar.com/callback"}, c.GetRedirectURIs())
+				require.Equal(t, []string{"http://127.0.0.1:80", "https://foobar.com/callback"}, c.GetRedirectURIs())
 				require.Equal(t, fosite.Arguments{"authorization_code", "urn:ietf:params:oauth:grant-type:token-exchange", "refresh_token"}, c.GetGrantTypes())
 				require.Equal(t, fosite.Arguments{"code"}, c.GetResponseTypes())
 				require.Equal(t, fosite.Arguments{"openid", "offline_access", "pinniped:request-audience", "username", "groups"}, c.GetScopes())
@@ -221,6 +230,85 @@ func TestClientManager(t *testing.T) {
 				require.Equal(t, "client_secret_basic", c.GetTokenEndpointAuthMethod())
 				require.Equal(t, "RS256", c.GetTokenEndpointAuthSigningAlgorithm())
 				require.Equal(t, []fosite.ResponseModeType{"", "query"}, c.GetResponseModes())
+				require.Equal(t, []string{"https://foobar.com/logged_out"}, c.GetPostLogoutRedirectURIs())
+				require.Equal(t, "https://foobar.com/backchannel_logout", c.GetBackchannelLogoutURI())
+				require.Equal(t, []string{"cluster-1", "cluster-2"}, c.TokenExchangeAudiences)
+				require.Equal(t, []string{"admins", "team-a"}, c.FilterGroups([]string{"admins", "users", "team-a"}))
+				require.Equal(t, 5*time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.IDToken, time.Minute))
+				require.Equal(t, time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.AccessToken, time.Minute))
+				require.Equal(t, time.Hour, c.GetEffectiveLifespan(fosite.GrantTypeRefreshToken, fosite.RefreshToken, time.Minute))
+				require.Equal(t, time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.AuthorizeCode, time.Minute))
+				require.False(t, c.UsesJWTAccessTokens())
+				require.False(t, c.RequireConsent)
+			},
+		},
+		{
+			name: "find a valid dynamic client which uses JWT access tokens and requires consent",
+			oidcClients: []*configv1alpha1.OIDCClient{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+					Spec: configv1alpha1.OIDCClientSpec{
+						AllowedGrantTypes:   []configv1alpha1.GrantType{"authorization_code", "refresh_token"},
+						AllowedScopes:       []configv1alpha1.Scope{"openid", "offline_access"},
+						AllowedRedirectURIs: []configv1alpha1.RedirectURI{"https://foobar.com/callback"},
+						AccessTokenFormat:   configv1alpha1.AccessTokenFormatJWT,
+						RequireConsent:      true,
+						TokenLifetimes: configv1alpha1.TokenLifetimes{
+							AccessTokenSeconds: pointer.Int32(3600),
+						},
+					},
+				},
+			},
+			secrets: []*corev1.Secret{
+				testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost}),
+			},
+			run: func(t *testing.T, subject *ClientManager) {
+				got, err := subject.GetClient(ctx, testName)
+				require.NoError(t, err)
+				require.IsType(t, &Client{}, got)
+				c := got.(*Client)
+
+				require.True(t, c.UsesJWTAccessTokens())
+				require.True(t, c.RequireConsent)
+				// The lifespan of JWT access tokens is capped, no matter where it was configured.
+				require.Equal(t, 5*time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.AccessToken, time.Minute))
+				require.Equal(t, time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.IDToken, time.Minute))
+				c.AccessTokenLifespan = 0
+				require.Equal(t, 2*time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeRefreshToken, fosite.AccessToken, 2*time.Minute))
+				require.Equal(t, 5*time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeRefreshToken, fosite.AccessToken, time.Hour))
+			},
+		},
+		{
+			name: "find a valid dynamic client which may use the client credentials grant",
+			oidcClients: []*configv1alpha1.OIDCClient{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+					Spec: configv1alpha1.OIDCClientSpec{
+						AllowedGrantTypes:   []configv1alpha1.GrantType{"authorization_code", "client_credentials"},
+						AllowedScopes:       []configv1alpha1.Scope{"openid"},
+						AllowedRedirectURIs: []configv1alpha1.RedirectURI{"https://foobar.com/callback"},
+						ClientCredentials: configv1alpha1.ClientCredentials{
+							AllowedScopes:    []string{"orders:read", "orders:write"},
+							AllowedAudiences: []string{"https://orders.example.com"},
+						},
+					},
+				},
+			},
+			secrets: []*corev1.Secret{
+				testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost}),
+			},
+			run: func(t *testing.T, subject *ClientManager) {
+				got, err := subject.GetClient(ctx, testName)
+				require.NoError(t, err)
+				require.IsType(t, &Client{}, got)
+				c := got.(*Client)
+
+				require.Equal(t, fosite.Arguments{"authorization_code", "client_credentials"}, c.GetGrantTypes())
+				require.Equal(t, []string{"orders:read", "orders:write"}, c.ClientCredentialsScopes)
+				require.Equal(t, []string{"https://orders.example.com"}, c.ClientCredentialsAudiences)
+				// The allowed scopes of the client are unchanged, since these scopes are only for client credentials grants.
+				require.Equal(t, fosite.Arguments{"openid"}, c.GetScopes())
+				require.Nil(t, c.GetAudience())
 			},
 		},
 	}
@@ -267,7 +355,7 @@ func requireEqualsPinnipedCLI(t *testing.T, c *Client) {
 	require.Equal(t, "pinniped-cli", c.GetID())
 	require.Nil(t, c.GetHashedSecret())
 	require.Equal(t, []string{"http://127.0.0.1/callback"}, c.GetRedirectURIs())
-	require.Equal(t, fosite.Arguments{"authorization_code", "refresh_token", "urn:ietf:params:oauth:grant-type:token-exchange"}, c.GetGrantTypes())
+	require.Equal(t, fosite.Arguments{"authorization_code", "refresh_token", "urn:ietf:params:oauth:grant-type:token-exchange", "urn:ietf:params:oauth:grant-type:device_code"}, c.GetGrantTypes())
 	require.Equal(t, fosite.Arguments{"code"}, c.GetResponseTypes())
 	require.Equal(t, fosite.Arguments{oidc.ScopeOpenID, oidc.ScopeOfflineAccess, "profile", "email", "pinniped:request-audience", "username", "groups"}, c.GetScopes())
 	require.True(t, c.IsPublic())
@@ -279,6 +367,11 @@ func requireEqualsPinnipedCLI(t *testing.T, c *Client) {
 	require.Equal(t, "none", c.GetTokenEndpointAuthMethod())
 	require.Equal(t, "RS256", c.GetTokenEndpointAuthSigningAlgorithm())
 	require.Equal(t, []fosite.ResponseModeType{"", "query", "form_post"}, c.GetResponseModes())
+	require.Equal(t, time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.IDToken, time.Minute))
+	require.Equal(t, time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeAuthorizationCode, fosite.AccessToken, time.Minute))
+	require.Equal(t, time.Minute, c.GetEffectiveLifespan(fosite.GrantTypeRefreshToken, fosite.RefreshToken, time.Minute))
+	require.False(t, c.UsesJWTAccessTokens())
+	require.False(t, c.RequireConsent)
 
 	marshaled, err := json.Marshal(c)
 	require.NoError(t, err)
@@ -291,7 +384,8 @@ func requireEqualsPinnipedCLI(t *testing.T, c *Client) {
 		  "grant_types": [
 			"authorization_code",
 			"refresh_token",
-			"urn:ietf:params:oauth:grant-type:token-exchange"
+			"urn:ietf:params:oauth:grant-type:token-exchange",
+			"urn:ietf:params:oauth:grant-type:device_code"
 		  ],
 		  "response_types": [
 			"code"
@@ -315,3 +409,129 @@ func requireEqualsPinnipedCLI(t *testing.T, c *Client) {
 		  "token_endpoint_auth_signing_alg": "RS256"
 		}`, string(marshaled))
 }
+
+func TestClientFilterGroups(t *testing.T) {
+	tests := []struct {
+		name              string
+		client            *Client
+		groups            []string
+		want              []string
+		wantFiltersGroups bool
+	}{
+		{
+			name:   "no filter returns all groups",
+			client: &Client{},
+			groups: []string{"a", "b"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:              "names and prefixes select groups in their original order",
+			client:            &Client{AllowedGroupNames: []string{"admins"}, AllowedGroupPrefixes: []string{"team-", "org:"}},
+			groups:            []string{"org:eng", "users", "admins", "team-a", "team"},
+			want:              []string{"org:eng", "admins", "team-a"},
+			wantFiltersGroups: true,
+		},
+		{
+			name:              "no selected groups",
+			client:            &Client{AllowedGroupNames: []string{"admins"}},
+			groups:            []string{"users"},
+			want:              []string{},
+			wantFiltersGroups: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantFiltersGroups, tt.client.FiltersGroups())
+			require.Equal(t, tt.want, tt.client.FilterGroups(tt.groups))
+		})
+	}
+}
+
+func TestClientMatchRedirectURI(t *testing.T) {
+	client := &Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{
+		RedirectURIs: []string{
+			"https://example.com/callback",
+			"https://*.preview.example.com/callback",
+			"https://*.ports.example.com:8443/callback",
+			"http://127.0.0.1/callback",
+			"http://[::1]/callback?q=1",
+		},
+	}}}
+
+	tests := []struct {
+		name        string
+		redirectURI string
+		want        bool
+	}{
+		{name: "exact match", redirectURI: "https://example.com/callback", want: true},
+		{name: "different path", redirectURI: "https://example.com/other", want: false},
+		{name: "wildcard replaced by one label", redirectURI: "https://pr-123.preview.example.com/callback", want: true},
+		{name: "wildcard is case-insensitive", redirectURI: "https://PR-123.Preview.Example.com/callback", want: true},
+		{name: "wildcard replaced by two labels", redirectURI: "https://a.b.preview.example.com/callback", want: false},
+		{name: "wildcard replaced by nothing", redirectURI: "https://preview.example.com/callback", want: false},
+		{name: "wildcard replaced by an empty label", redirectURI: "https://.preview.example.com/callback", want: false},
+		{name: "wildcard replaced by an invalid label", redirectURI: "https://a_b.preview.example.com/callback", want: false},
+		{name: "wildcard with literal wildcard", redirectURI: "https://*.preview.example.com/callback", want: false},
+		{name: "wildcard with different path", redirectURI: "https://a.preview.example.com/other", want: false},
+		{name: "wildcard with http scheme", redirectURI: "http://a.preview.example.com/callback", want: false},
+		{name: "wildcard with different port", redirectURI: "https://a.preview.example.com:8443/callback", want: false},
+		{name: "wildcard with same port", redirectURI: "https://a.ports.example.com:8443/callback", want: true},
+		{name: "wildcard with user info", redirectURI: "https://user@a.preview.example.com/callback", want: false},
+		{name: "wildcard with fragment", redirectURI: "https://a.preview.example.com/callback#fragment", want: false},
+		{name: "ipv4 loopback with any port", redirectURI: "http://127.0.0.1:12345/callback", want: true},
+		{name: "ipv4 loopback without port", redirectURI: "http://127.0.0.1/callback", want: true},
+		{name: "ipv4 loopback with different path", redirectURI: "http://127.0.0.1:12345/other", want: false},
+		{name: "ipv4 loopback with https scheme", redirectURI: "https://127.0.0.1:12345/callback", want: false},
+		{name: "ipv6 loopback with any port", redirectURI: "http://[::1]:12345/callback?q=1", want: true},
+		{name: "ipv6 loopback with different query", redirectURI: "http://[::1]:12345/callback?q=2", want: false},
+		{name: "localhost is not a loopback IP", redirectURI: "http://localhost:12345/callback", want: false},
+		{name: "invalid URI", redirectURI: "https://example.com/%zz", want: false},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, client.MatchRedirectURI(tt.redirectURI))
+		})
+	}
+}
+
+func TestLimitRedirectURIsToRequest(t *testing.T) {
+	redirectURIs := []string{"https://example.com/callback", "https://*.preview.example.com/callback"}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		wantRedirectURIs []string
+	}{
+		{
+			name:             "not an authorization request",
+			ctx:              context.Background(),
+			wantRedirectURIs: redirectURIs,
+		},
+		{
+			name:             "no redirect URI was requested",
+			ctx:              WithRequestedRedirectURI(context.Background(), ""),
+			wantRedirectURIs: []string{"https://example.com/callback"},
+		},
+		{
+			name:             "requested redirect URI matches a wildcard",
+			ctx:              WithRequestedRedirectURI(context.Background(), "https://pr-1.preview.example.com/callback"),
+			wantRedirectURIs: []string{"https://pr-1.preview.example.com/callback"},
+		},
+		{
+			name:             "requested redirect URI does not match",
+			ctx:              WithRequestedRedirectURI(context.Background(), "https://evil.example.com/callback"),
+			wantRedirectURIs: []string{},
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{
+				RedirectURIs: append([]string(nil), redirectURIs...),
+			}}}
+			require.Equal(t, tt.wantRedirectURIs, limitRedirectURIsToRequest(tt.ctx, client).RedirectURIs)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clientregistry
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// wildcardPrefix starts the hostname of a registered redirect URI which matches any single DNS label in its place.
+const wildcardPrefix = "*."
+
+type requestedRedirectURIContextKey struct{}
+
+// WithRequestedRedirectURI returns a copy of the context which carries the redirect_uri param of an authorization
+// request. Fosite only matches the redirect_uri param exactly, or as a loopback URI, against the redirect URIs of the
+// client. When the context carries the param, GetClient applies the matching rules of MatchRedirectURI instead, by
+// returning a client whose only redirect URI is the requested one when it matched, or none when it did not.
+func WithRequestedRedirectURI(ctx context.Context, redirectURI string) context.Context {
+	return context.WithValue(ctx, requestedRedirectURIContextKey{}, redirectURI)
+}
+
+// MatchRedirectURI returns true when the redirect_uri param of an authorization request matches one of the
+// redirect URIs of the client. A registered redirect URI matches when:
+//   - it is exactly the same as the requested URI.
+//   - it is an http URI on the 127.0.0.1 or ::1 loopback address, and the requested URI only differs in its port,
+//     so native apps can listen on any free port as described in RFC 8252 section 7.3.
+//   - it is an https URI whose hostname starts with "*.", and the requested URI only differs by having a single DNS
+//     label in place of the "*", e.g. https://*.preview.example.com/callback matches
+//     https://pr-123.preview.example.com/callback but not https://a.b.preview.example.com/callback.
+func (c *Client) MatchRedirectURI(requestedURI string) bool {
+	requested, err := url.Parse(requestedURI)
+	if err != nil || requested.User != nil || requested.Fragment != "" || requested.Opaque != "" {
+		return false
+	}
+
+	for _, registeredURI := range c.RedirectURIs {
+		if registeredURI == requestedURI && !isWildcardRedirectURI(registeredURI) {
+			return true
+		}
+		registered, err := url.Parse(registeredURI)
+		if err != nil {
+			continue
+		}
+		if matchesAsLoopback(registered, requested) || matchesAsWildcard(registered, requested) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAsLoopback(registered, requested *url.URL) bool {
+	return registered.Scheme == "http" && requested.Scheme == "http" &&
+		isLoopbackIP(registered.Hostname()) &&
+		registered.Hostname() == requested.Hostname() &&
+		registered.Path == requested.Path &&
+		registered.RawQuery == requested.RawQuery
+}
+
+func matchesAsWildcard(registered, requested *url.URL) bool {
+	if registered.Scheme != "https" || requested.Scheme != "https" ||
+		registered.Port() != requested.Port() ||
+		registered.Path != requested.Path ||
+		registered.RawQuery != requested.RawQuery {
+		return false
+	}
+
+	registeredHost := strings.ToLower(registered.Hostname())
+	if !strings.HasPrefix(registeredHost, wildcardPrefix) {
+		return false
+	}
+	suffix := strings.TrimPrefix(registeredHost, "*") // keeps the leading dot
+
+	requestedHost := strings.ToLower(requested.Hostname())
+	if !strings.HasSuffix(requestedHost, suffix) {
+		return false
+	}
+	return len(validation.IsDNS1123Label(strings.TrimSuffix(requestedHost, suffix))) == 0
+}
+
+func isWildcardRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && strings.HasPrefix(u.Hostname(), wildcardPrefix)
+}
+
+func isLoopbackIP(hostname string) bool {
+	return hostname == "127.0.0.1" || hostname == "::1"
+}
+
+// limitRedirectURIsToRequest replaces the redirect URIs of a client which was just created for this request, when the
+// context carries the redirect_uri param of an authorization request, see WithRequestedRedirectURI.
+func limitRedirectURIsToRequest(ctx context.Context, c *Client) *Client {
+	requestedURI, ok := ctx.Value(requestedRedirectURIContextKey{}).(string)
+	if !ok {
+		return c
+	}
+
+	if requestedURI == "" {
+		// Fosite uses the only redirect URI of the client when the param is missing, which must not be a wildcard.
+		redirectURIs := make([]string, 0, len(c.RedirectURIs))
+		for _, uri := range c.RedirectURIs {
+			if !isWildcardRedirectURI(uri) {
+				redirectURIs = append(redirectURIs, uri)
+			}
+		}
+		c.RedirectURIs = redirectURIs
+		return c
+	}
+
+	if c.MatchRedirectURI(requestedURI) {
+		c.RedirectURIs = []string{requestedURI}
+	} else {
+		c.RedirectURIs = []string{}
+	}
+	return c
+}
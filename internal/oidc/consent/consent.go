@@ -0,0 +1,321 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consent provides the consent page, on which users decide whether an OIDCClient which requires consent may
+// receive the scopes which it requested, and the Prompter which sends users there at the end of their logins.
+package consent
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/consentstorage"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/consent/consenthtml"
+	"go.pinniped.dev/internal/oidc/csrftoken"
+	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/i18n"
+	"go.pinniped.dev/internal/oidc/provider/formposthtml"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+const (
+	idParamName       = "id"
+	csrfParamName     = "csrf"
+	decisionParamName = "decision"
+
+	decisionAllow = "allow"
+	decisionDeny  = "deny"
+)
+
+// Prompter sends users to the consent page at the end of their logins, when the client requires consent and the
+// users have not yet allowed it to receive all of the scopes which it requested. A nil Prompter never prompts.
+type Prompter struct {
+	downstreamIssuer string
+	storage          consentstorage.Storage
+	cookieDecoder    oidc.Decoder
+	generateID       func() (string, error)
+	nowFunc          func() time.Time
+	pendingLifespan  time.Duration
+}
+
+// NewPrompter returns a Prompter which redirects to the consent page of the downstreamIssuer. The users must decide
+// within the pendingLifespan, after which they must log in again.
+func NewPrompter(
+	downstreamIssuer string,
+	storage consentstorage.Storage,
+	cookieDecoder oidc.Decoder,
+	generateID func() (string, error),
+	nowFunc func() time.Time,
+	pendingLifespan time.Duration,
+) *Prompter {
+	return &Prompter{
+		downstreamIssuer: downstreamIssuer,
+		storage:          storage,
+		cookieDecoder:    cookieDecoder,
+		generateID:       generateID,
+		nowFunc:          nowFunc,
+		pendingLifespan:  pendingLifespan,
+	}
+}
+
+// PromptIfRequired is called after the user has logged in, instead of writing the authcode response. When the user
+// must be asked for consent, it remembers the login and redirects to the consent page, and then returns true, in
+// which case the caller must not write any other response. Otherwise, it returns false, and the caller continues
+// as usual.
+func (p *Prompter) PromptIfRequired(
+	w http.ResponseWriter,
+	r *http.Request,
+	authorizeRequester fosite.AuthorizeRequester,
+	session *psession.PinnipedSession,
+) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+
+	client, ok := authorizeRequester.GetClient().(*clientregistry.Client)
+	if !ok || !client.RequireConsent {
+		return false, nil
+	}
+
+	scopes := []string(authorizeRequester.GetGrantedScopes())
+	subject := session.Fosite.Claims.Subject
+
+	remembered, err := p.storage.GetConsent(r.Context(), subject, client.GetID())
+	if err != nil {
+		plog.Error("error reading consent", err)
+		return false, httperr.New(http.StatusInternalServerError, "error reading consent")
+	}
+	if remembered.Covers(scopes) {
+		return false, nil
+	}
+
+	csrfValue := readCSRFCookie(r, p.cookieDecoder)
+	if csrfValue == "" {
+		// This shouldn't happen, because the CSRF cookie was already validated during the login.
+		return false, httperr.New(http.StatusForbidden, "CSRF cookie is missing")
+	}
+
+	id, err := p.generateID()
+	if err != nil {
+		return false, httperr.Wrap(http.StatusInternalServerError, "error generating consent ID", err)
+	}
+
+	if err = p.storage.CreatePendingConsent(r.Context(), id, &consentstorage.PendingConsent{
+		ClientID:   client.GetID(),
+		Scopes:     scopes,
+		AuthParams: authorizeRequester.GetRequestForm().Encode(),
+		CSRFToken:  string(csrfValue),
+		Session:    session,
+		Expiry:     p.nowFunc().Add(p.pendingLifespan),
+	}); err != nil {
+		plog.Error("error saving pending consent", err)
+		return false, httperr.New(http.StatusInternalServerError, "error saving pending consent")
+	}
+
+	consentURL, err := url.Parse(p.downstreamIssuer + oidc.PinnipedConsentPath)
+	if err != nil {
+		return false, err
+	}
+	consentURL.RawQuery = url.Values{idParamName: []string{id}}.Encode()
+
+	http.Redirect(w, r,
+		consentURL.String(),
+		http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
+	)
+	return true, nil
+}
+
+// NewHandler returns a http.Handler that serves the consent page.
+//
+// The Prompter redirects browsers to this page with the ID of a pending consent, which may only be used from the
+// browser which logged in, as proven by the CSRF cookie. A GET request renders the client and the scopes which it
+// requested. A POST request records the decision of the user. When the user allows the client, the decision is
+// remembered and the login finishes with the usual authcode response. When the user denies the client, the login
+// finishes with an access_denied error response. Either way, the pending consent can only be used once. The branding
+// in the brandingCache, if any, is applied to the page, and the page is translated to the language of the request
+// using the translations.
+func NewHandler(
+	postPath string,
+	oauthHelper fosite.OAuth2Provider,
+	storage consentstorage.Storage,
+	cookieDecoder oidc.Decoder,
+	brandingCache *branding.Cache,
+	translations *i18n.Catalogs,
+	nowFunc func() time.Time,
+) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try GET or POST)", r.Method)
+		}
+
+		id := r.FormValue(idParamName)
+		if id == "" {
+			return httperr.New(http.StatusBadRequest, "id param not found")
+		}
+
+		pending, err := storage.GetPendingConsent(r.Context(), id)
+		if err != nil && !errors.Is(err, fosite.ErrNotFound) {
+			plog.Error("error reading pending consent", err)
+			return httperr.New(http.StatusInternalServerError, "error reading pending consent")
+		}
+		if err != nil || !nowFunc().Before(pending.Expiry) {
+			// The user already decided, or took too long to decide. Either way, the user must log in again.
+			return httperr.New(http.StatusUnprocessableEntity, "consent request was not found or has expired")
+		}
+
+		csrfValue := readCSRFCookie(r, cookieDecoder)
+		if csrfValue == "" || subtle.ConstantTimeCompare([]byte(csrfValue), []byte(pending.CSRFToken)) != 1 {
+			return httperr.New(http.StatusForbidden, "CSRF value does not match")
+		}
+
+		if r.Method == http.MethodGet {
+			messages := translations.ForRequest(r)
+			// The branding can change at any time, so the CSP header must match the branding of this render.
+			brand := brandingCache.Get()
+			w.Header().Set("Content-Security-Policy", brand.ContentSecurityPolicy(consenthtml.CSS()))
+			return consenthtml.Template().Execute(w, &consenthtml.PageData{
+				ClientName: strings.TrimPrefix(pending.ClientID, oidcapi.ClientIDRequiredOIDCClientPrefix),
+				Scopes:     describeScopes(messages, pending.Scopes),
+				ID:         id,
+				CSRFToken:  string(csrfValue),
+				PostPath:   postPath,
+				Branding:   brand,
+				Messages:   messages,
+			})
+		}
+
+		if subtle.ConstantTimeCompare([]byte(csrfValue), []byte(r.PostFormValue(csrfParamName))) != 1 {
+			return httperr.New(http.StatusForbidden, "CSRF value does not match")
+		}
+
+		decision := r.PostFormValue(decisionParamName)
+		if decision != decisionAllow && decision != decisionDeny {
+			return httperr.Newf(http.StatusBadRequest, "decision param must be %q or %q", decisionAllow, decisionDeny)
+		}
+
+		// The pending consent may only be used once, whatever the user decided.
+		if err = storage.DeletePendingConsent(r.Context(), id); err != nil {
+			plog.Error("error deleting pending consent", err)
+			return httperr.New(http.StatusInternalServerError, "error deleting pending consent")
+		}
+
+		downstreamAuthParams, err := url.ParseQuery(pending.AuthParams)
+		if err != nil {
+			// This shouldn't really happen because the Prompter encoded these query params correctly.
+			plog.Error("error reading pending consent downstream auth params", err)
+			return httperr.New(http.StatusBadRequest, "error reading pending consent downstream auth params")
+		}
+
+		// Recreate enough of the original authorize request so we can pass it to NewAuthorizeRequest().
+		reconstitutedAuthRequest := &http.Request{Form: downstreamAuthParams}
+		authorizeRequester, err := oauthHelper.NewAuthorizeRequest(r.Context(), reconstitutedAuthRequest)
+		if err != nil {
+			// This shouldn't really happen unless the client was changed while the user was deciding.
+			plog.Error("error using pending consent downstream auth params", err,
+				"fositeErr", oidc.FositeErrorForLog(err))
+			return httperr.New(http.StatusBadRequest, "error using pending consent downstream auth params")
+		}
+		downstreamsession.AutoApproveScopes(authorizeRequester)
+
+		if decision == decisionDeny {
+			oidc.AuditLoginFailed(r, authorizeRequester, pending.Session.Custom.UpstreamUsername,
+				pending.Session.Custom.ProviderName, pending.Session.Custom.ProviderType, "user denied consent")
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHint("The user denied the request."), false)
+			return nil
+		}
+
+		if err = rememberConsent(r, storage, pending); err != nil {
+			plog.Error("error saving consent", err)
+			return httperr.New(http.StatusInternalServerError, "error saving consent")
+		}
+
+		// The session was made for the original authorize request, so it must identify the recreated one instead.
+		pending.Session.IDTokenClaims().Extra[oidcapi.IDTokenClaimSessionID] = authorizeRequester.GetID()
+
+		oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, pending.Session, false)
+		return nil
+	})
+
+	// The success and error responses of a POST may be the form_post html page, so allow it with CSP headers.
+	// The GET responses set the CSP header of the consent page instead.
+	return securityheader.WrapWithCustomCSP(handler, formposthtml.ContentSecurityPolicy())
+}
+
+// rememberConsent adds the scopes of the pending consent to the scopes which the user allowed the client before.
+func rememberConsent(r *http.Request, storage consentstorage.Storage, pending *consentstorage.PendingConsent) error {
+	subject := pending.Session.Fosite.Claims.Subject
+
+	remembered, err := storage.GetConsent(r.Context(), subject, pending.ClientID)
+	if err != nil {
+		return err
+	}
+
+	scopes := pending.Scopes
+	if remembered != nil {
+		scopes = append([]string{}, remembered.Scopes...)
+		for _, scope := range pending.Scopes {
+			if !fosite.Arguments(scopes).Has(scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return storage.SetConsent(r.Context(), &consentstorage.Consent{
+		Subject:  subject,
+		ClientID: pending.ClientID,
+		Scopes:   scopes,
+	})
+}
+
+// describeScopes translates the scopes which are known to the Supervisor, and leaves other scopes as they are.
+func describeScopes(messages *i18n.Messages, scopes []string) []string {
+	descriptions := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		switch scope {
+		case oidcapi.ScopeOpenID:
+			descriptions = append(descriptions, messages.Text(i18n.ConsentScopeOpenID))
+		case oidcapi.ScopeOfflineAccess:
+			descriptions = append(descriptions, messages.Text(i18n.ConsentScopeOfflineAccess))
+		case oidcapi.ScopeUsername:
+			descriptions = append(descriptions, messages.Text(i18n.ConsentScopeUsername))
+		case oidcapi.ScopeGroups:
+			descriptions = append(descriptions, messages.Text(i18n.ConsentScopeGroups))
+		case oidcapi.ScopeRequestAudience:
+			descriptions = append(descriptions, messages.Text(i18n.ConsentScopeRequestAudience))
+		default:
+			descriptions = append(descriptions, scope)
+		}
+	}
+	return descriptions
+}
+
+// readCSRFCookie returns the value of the CSRF cookie, or an empty value when the cookie is missing or could not be
+// decoded.
+func readCSRFCookie(r *http.Request, codec oidc.Decoder) csrftoken.CSRFToken {
+	receivedCSRFCookie, err := r.Cookie(oidc.CSRFCookieName)
+	if err != nil {
+		// Error means that the cookie was not found
+		return ""
+	}
+
+	var csrfFromCookie csrftoken.CSRFToken
+	if err = codec.Decode(oidc.CSRFCookieEncodingName, receivedCSRFCookie.Value, &csrfFromCookie); err != nil {
+		return ""
+	}
+
+	return csrfFromCookie
+}
@@ -0,0 +1,393 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/consentstorage"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+)
+
+const (
+	testNamespace        = "some-namespace"
+	testIssuer           = "https://my-downstream-issuer.com/some-path"
+	testPostPath         = "/some-path/consent"
+	testConsentClientID  = "client.oauth.pinniped.dev-test-name"
+	testTrustedClientID  = "client.oauth.pinniped.dev-trusted"
+	testRedirectURI      = "https://client.example.com/callback"
+	testSubject          = "https://upstream.example.com?idpName=some-ldap-idp&sub=some-user-uid"
+	testCSRFToken        = "test-csrf"
+	testPendingConsentID = "test-pending-consent-id"
+)
+
+func TestPromptIfRequired(t *testing.T) {
+	fakeNow := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		nilPrompter      bool
+		clientID         string
+		rememberedScopes []string
+		noCSRFCookie     bool
+		wantPrompted     bool
+		wantErr          string
+	}{
+		{
+			name:        "nil prompter",
+			nilPrompter: true,
+			clientID:    testConsentClientID,
+		},
+		{
+			name:     "client which does not require consent",
+			clientID: testTrustedClientID,
+		},
+		{
+			name:         "client which requires consent, when the user never decided",
+			clientID:     testConsentClientID,
+			wantPrompted: true,
+		},
+		{
+			name:             "client which requires consent, when the user allowed fewer scopes before",
+			clientID:         testConsentClientID,
+			rememberedScopes: []string{"openid"},
+			wantPrompted:     true,
+		},
+		{
+			name:             "client which requires consent, when the user allowed all of the scopes before",
+			clientID:         testConsentClientID,
+			rememberedScopes: []string{"openid", "username", "groups"},
+		},
+		{
+			name:         "client which requires consent, without a CSRF cookie",
+			clientID:     testConsentClientID,
+			noCSRFCookie: true,
+			wantErr:      "CSRF cookie is missing",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			oauthHelper := makeTestOAuthHelper(t)
+			storage := consentstorage.New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() time.Time { return fakeNow }, time.Hour, 11*time.Minute)
+			cookieCodec := makeTestCookieCodec()
+
+			if test.rememberedScopes != nil {
+				require.NoError(t, storage.SetConsent(ctx, &consentstorage.Consent{Subject: testSubject, ClientID: test.clientID, Scopes: test.rememberedScopes}))
+			}
+
+			var subject *Prompter
+			if !test.nilPrompter {
+				subject = NewPrompter(testIssuer, storage, cookieCodec,
+					func() (string, error) { return testPendingConsentID, nil },
+					func() time.Time { return fakeNow },
+					10*time.Minute,
+				)
+			}
+
+			authorizeRequester, session := makeTestLogin(t, oauthHelper, test.clientID)
+			req := httptest.NewRequest(http.MethodGet, testIssuer+"/callback", nil)
+			if !test.noCSRFCookie {
+				addCSRFCookie(t, req, cookieCodec)
+			}
+			rsp := httptest.NewRecorder()
+
+			prompted, err := subject.PromptIfRequired(rsp, req, authorizeRequester, session)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantPrompted, prompted)
+
+			pending, err := storage.GetPendingConsent(ctx, testPendingConsentID)
+			if !test.wantPrompted {
+				require.ErrorIs(t, err, fosite.ErrNotFound)
+				require.Empty(t, rsp.Header().Get("Location"))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, http.StatusSeeOther, rsp.Code)
+			require.Equal(t, testIssuer+"/consent?id="+testPendingConsentID, rsp.Header().Get("Location"))
+			require.Equal(t, test.clientID, pending.ClientID)
+			require.Equal(t, []string{"openid", "username", "groups"}, pending.Scopes)
+			require.Equal(t, testCSRFToken, pending.CSRFToken)
+			require.Equal(t, fakeNow.Add(10*time.Minute), pending.Expiry)
+			require.Equal(t, testSubject, pending.Session.Fosite.Claims.Subject)
+			require.Equal(t, authorizeRequester.GetRequestForm().Encode(), pending.AuthParams)
+		})
+	}
+}
+
+func TestConsentHandler(t *testing.T) {
+	fakeNow := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		method           string
+		id               string
+		form             url.Values
+		csrfCookie       string
+		pendingExpired   bool
+		rememberedScopes []string
+		wantStatus       int
+		wantBodyContains []string
+		wantLocation     string
+		wantConsent      []string
+		wantPendingGone  bool
+	}{
+		{
+			name:       "GET renders the consent page",
+			method:     http.MethodGet,
+			id:         testPendingConsentID,
+			csrfCookie: testCSRFToken,
+			wantStatus: http.StatusOK,
+			wantBodyContains: []string{
+				`<h1>Allow test-name to access your account?</h1>`,
+				`<li>Your identity</li>`,
+				`<li>Your username</li>`,
+				`<li>Your group memberships</li>`,
+				`<input type="hidden" name="id" value="test-pending-consent-id">`,
+				`<input type="hidden" name="csrf" value="test-csrf">`,
+				`<form action="/some-path/consent" method="post">`,
+			},
+		},
+		{
+			name:       "GET without an id",
+			method:     http.MethodGet,
+			csrfCookie: testCSRFToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "GET with an unknown id",
+			method:     http.MethodGet,
+			id:         "some-other-id",
+			csrfCookie: testCSRFToken,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "GET after the pending consent expired",
+			method:         http.MethodGet,
+			id:             testPendingConsentID,
+			csrfCookie:     testCSRFToken,
+			pendingExpired: true,
+			wantStatus:     http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "GET from another browser",
+			method:     http.MethodGet,
+			id:         testPendingConsentID,
+			csrfCookie: "some-other-csrf",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "GET without a CSRF cookie",
+			method:     http.MethodGet,
+			id:         testPendingConsentID,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:             "POST allow",
+			method:           http.MethodPost,
+			form:             url.Values{"id": {testPendingConsentID}, "csrf": {testCSRFToken}, "decision": {"allow"}},
+			csrfCookie:       testCSRFToken,
+			rememberedScopes: []string{"offline_access"},
+			wantStatus:       http.StatusSeeOther,
+			wantLocation:     testRedirectURI + "?code=",
+			wantConsent:      []string{"offline_access", "openid", "username", "groups"},
+			wantPendingGone:  true,
+		},
+		{
+			name:            "POST deny",
+			method:          http.MethodPost,
+			form:            url.Values{"id": {testPendingConsentID}, "csrf": {testCSRFToken}, "decision": {"deny"}},
+			csrfCookie:      testCSRFToken,
+			wantStatus:      http.StatusSeeOther,
+			wantLocation:    testRedirectURI + "?error=access_denied",
+			wantPendingGone: true,
+		},
+		{
+			name:       "POST with the wrong CSRF form value",
+			method:     http.MethodPost,
+			form:       url.Values{"id": {testPendingConsentID}, "csrf": {"some-other-csrf"}, "decision": {"allow"}},
+			csrfCookie: testCSRFToken,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "POST with an unknown decision",
+			method:     http.MethodPost,
+			form:       url.Values{"id": {testPendingConsentID}, "csrf": {testCSRFToken}, "decision": {"maybe"}},
+			csrfCookie: testCSRFToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bad method",
+			method:     http.MethodPut,
+			id:         testPendingConsentID,
+			csrfCookie: testCSRFToken,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			oauthHelper := makeTestOAuthHelper(t)
+			storage := consentstorage.New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() time.Time { return fakeNow }, time.Hour, 11*time.Minute)
+			cookieCodec := makeTestCookieCodec()
+
+			authorizeRequester, session := makeTestLogin(t, oauthHelper, testConsentClientID)
+			expiry := fakeNow.Add(10 * time.Minute)
+			if test.pendingExpired {
+				expiry = fakeNow
+			}
+			require.NoError(t, storage.CreatePendingConsent(ctx, testPendingConsentID, &consentstorage.PendingConsent{
+				ClientID:   testConsentClientID,
+				Scopes:     []string{"openid", "username", "groups"},
+				AuthParams: authorizeRequester.GetRequestForm().Encode(),
+				CSRFToken:  testCSRFToken,
+				Session:    session,
+				Expiry:     expiry,
+			}))
+			if test.rememberedScopes != nil {
+				require.NoError(t, storage.SetConsent(ctx, &consentstorage.Consent{Subject: testSubject, ClientID: testConsentClientID, Scopes: test.rememberedScopes}))
+			}
+
+			subject := NewHandler(testPostPath, oauthHelper, storage, cookieCodec, nil, nil, func() time.Time { return fakeNow })
+
+			target := testIssuer + "/consent"
+			if test.id != "" {
+				target += "?id=" + test.id
+			}
+			req := httptest.NewRequest(test.method, target, strings.NewReader(test.form.Encode()))
+			if test.form != nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if test.csrfCookie != "" {
+				addCSRFCookieWithValue(t, req, cookieCodec, test.csrfCookie)
+			}
+			rsp := httptest.NewRecorder()
+
+			subject.ServeHTTP(rsp, req)
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			for _, want := range test.wantBodyContains {
+				require.Contains(t, rsp.Body.String(), want)
+			}
+			if test.wantLocation != "" {
+				require.True(t, strings.HasPrefix(rsp.Header().Get("Location"), test.wantLocation), rsp.Header().Get("Location"))
+			}
+
+			remembered, err := storage.GetConsent(ctx, testSubject, testConsentClientID)
+			require.NoError(t, err)
+			if test.wantConsent != nil {
+				require.Equal(t, test.wantConsent, remembered.Scopes)
+			} else if test.rememberedScopes == nil {
+				require.Nil(t, remembered)
+			}
+
+			_, err = storage.GetPendingConsent(ctx, testPendingConsentID)
+			if test.wantPendingGone {
+				require.ErrorIs(t, err, fosite.ErrNotFound)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// makeTestOAuthHelper returns an oauth helper which knows about a dynamic client which requires consent, and another
+// dynamic client which does not.
+func makeTestOAuthHelper(t *testing.T) fosite.OAuth2Provider {
+	t.Helper()
+
+	kubeClient := fake.NewSimpleClientset()
+	supervisorClient := supervisorfake.NewSimpleClientset()
+
+	for _, clientID := range []string{testConsentClientID, testTrustedClientID} {
+		oidcClient, secret := testutil.OIDCClientAndStorageSecret(t,
+			testNamespace, clientID, clientID+"-uid",
+			[]configv1alpha1.GrantType{"authorization_code", "refresh_token"},
+			[]configv1alpha1.Scope{"openid", "offline_access", "username", "groups"},
+			testRedirectURI,
+			[]string{testutil.HashedPassword1AtGoMinCost}, oidcclientvalidator.Validate)
+		oidcClient.Spec.RequireConsent = clientID == testConsentClientID
+		require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+		require.NoError(t, kubeClient.Tracker().Add(secret))
+	}
+
+	// Use lower minimum required bcrypt cost than we would use in production to keep unit the tests fast.
+	kubeStorage := oidc.NewKubeStorage(
+		kubeClient.CoreV1().Secrets(testNamespace),
+		supervisorClient.ConfigV1alpha1().OIDCClients(testNamespace),
+		oidc.DefaultOIDCTimeoutsConfiguration(),
+		bcrypt.MinCost,
+	)
+	hmacSecretFunc := func() []byte { return []byte("some secret - must have at least 32 bytes") }
+	return oidc.FositeOauth2Helper(kubeStorage, testIssuer, hmacSecretFunc, nil, oidc.DefaultOIDCTimeoutsConfiguration(), nil, nil)
+}
+
+// makeTestLogin returns the authorize request and the downstream session of a user who just logged in.
+func makeTestLogin(t *testing.T, oauthHelper fosite.OAuth2Provider, clientID string) (fosite.AuthorizeRequester, *psession.PinnipedSession) {
+	t.Helper()
+
+	authorizeRequester, err := oauthHelper.NewAuthorizeRequest(context.Background(), &http.Request{Form: url.Values{
+		"response_type":         {"code"},
+		"scope":                 {"openid username groups"},
+		"client_id":             {clientID},
+		"state":                 {"some-state-value-with-enough-bytes-to-exceed-min-allowed"},
+		"nonce":                 {"some-nonce-value-with-enough-bytes-to-exceed-min-allowed"},
+		"code_challenge":        {"some-challenge"},
+		"code_challenge_method": {"S256"},
+		"redirect_uri":          {testRedirectURI},
+	}})
+	require.NoError(t, err)
+	downstreamsession.AutoApproveScopes(authorizeRequester)
+
+	session := downstreamsession.MakeDownstreamSession(authorizeRequester.GetID(), testSubject, "some-user", []string{"some-group"},
+		authorizeRequester.GetGrantedScopes(), clientID, &psession.CustomSessionData{
+			Username:         "some-user",
+			UpstreamUsername: "some-user",
+			ProviderUID:      "some-ldap-idp-uid",
+			ProviderName:     "some-ldap-idp",
+			ProviderType:     psession.ProviderTypeLDAP,
+			LDAP:             &psession.LDAPSessionData{UserDN: "cn=some-user"},
+		}, nil)
+	return authorizeRequester, session
+}
+
+func makeTestCookieCodec() *securecookie.SecureCookie {
+	return securecookie.New([]byte("fake-hash-secret"), []byte("0123456789ABCDEF"))
+}
+
+func addCSRFCookie(t *testing.T, req *http.Request, cookieCodec oidc.Encoder) {
+	t.Helper()
+	addCSRFCookieWithValue(t, req, cookieCodec, testCSRFToken)
+}
+
+func addCSRFCookieWithValue(t *testing.T, req *http.Request, cookieCodec oidc.Encoder, value string) {
+	t.Helper()
+	encoded, err := cookieCodec.Encode(oidc.CSRFCookieEncodingName, value)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: oidc.CSRFCookieName, Value: encoded})
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package consenthtml
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/i18n"
+)
+
+var (
+	testExpectedCSS = `html{height:100%}body{font-family:metropolis-light,Helvetica,sans-serif;display:flex;flex-flow:column wrap;justify-content:flex-start;align-items:center;background:linear-gradient(to top,#f8f8f8,white);min-height:100%}h1{font-size:20px;margin:0}.box{display:flex;flex-direction:column;flex-wrap:nowrap;border-radius:4px;border-color:#ddd;border-width:1px;border-style:solid;width:400px;padding:30px 30px 0;margin:60px 20px 0;background:#fff;font-size:14px}input{color:inherit;font:inherit;border:0;margin:0;outline:0;padding:0}.form-field{display:flex;margin-bottom:30px}.form-field input[type=submit]{width:100%;padding:1em;background-color:#218fcf;color:#eee;font-weight:700;cursor:pointer;transition:all .3s}.form-field input[type=submit]:focus,.form-field input[type=submit]:hover{background-color:#1abfd3}.form-field input[type=submit]:active{transform:scale(.99)}.form-field input[type=submit].deny{background-color:#fff;color:#218fcf;border:1px solid #218fcf}.form-field input[type=submit].deny:focus,.form-field input[type=submit].deny:hover{background-color:#f8f8f8}.scopes{margin:0 0 30px;padding-left:20px}.scopes li{margin-bottom:6px}`
+
+	// It's okay if this changes in the future, but this gives us a chance to eyeball the formatting.
+	// Our browser-based integration tests should find any incompatibilities.
+	testExpectedCSP = `default-src 'none'; ` +
+		`style-src 'sha256-u+4vmBzDdvEhF6dTnZpRFZSPcWf3YV912YFg0o4onm0='; ` +
+		`frame-ancestors 'none'`
+)
+
+func TestTemplate(t *testing.T) {
+	const (
+		testPath      = "test-post-path"
+		testID        = "test-id"
+		testCSRFToken = "test-csrf-token"
+	)
+
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		ClientName: "test-client",
+		Scopes:     []string{"Your identity", "Your <group> memberships"},
+		ID:         testID,
+		CSRFToken:  testCSRFToken,
+		PostPath:   testPath,
+	}
+
+	expectedHTML := here.Docf(`<!DOCTYPE html>
+        <html lang="en">
+        <head>
+            <title>Pinniped Login</title>
+            <meta charset="UTF-8">
+            <style>%s</style>
+        </head>
+        <body>
+        <div class="box" aria-label="consent form" role="main">
+            <div class="form-field">
+                <h1>Allow test-client to access your account?</h1>
+            </div>
+            <div class="form-field">
+                <span id="message">The application will receive the following information about you.</span>
+            </div>
+            <ul class="scopes" id="scopes">
+                <li>Your identity</li>
+                <li>Your &lt;group&gt; memberships</li>
+            </ul>
+            <form action="%s" method="post">
+                <input type="hidden" name="id" value="%s">
+                <input type="hidden" name="csrf" value="%s">
+                <input type="hidden" name="decision" value="allow">
+                <div class="form-field">
+                    <input type="submit" id="allow" value="Allow"/>
+                </div>
+            </form>
+            <form action="%s" method="post">
+                <input type="hidden" name="id" value="%s">
+                <input type="hidden" name="csrf" value="%s">
+                <input type="hidden" name="decision" value="deny">
+                <div class="form-field">
+                    <input type="submit" class="deny" id="deny" value="Deny"/>
+                </div>
+            </form>
+        </div>
+        </body>
+        </html>
+	`,
+		testExpectedCSS,
+		testPath, testID, testCSRFToken,
+		testPath, testID, testCSRFToken,
+	)
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	// t.Logf("actual value:\n%s", buf.String()) // useful when updating minify library causes new output
+	require.Equal(t, expectedHTML, buf.String())
+}
+
+func TestTemplateWithBranding(t *testing.T) {
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		ClientName: "test-client",
+		Scopes:     []string{"Your identity"},
+		ID:         "test-id",
+		CSRFToken:  "test-csrf-token",
+		PostPath:   "test-post-path",
+		Branding: &branding.Branding{
+			CSS:        "body{background:#4d5e6f}",
+			LogoURL:    "https://example.com/logo.png",
+			FooterText: "Contact the <help desk>",
+		},
+	}
+
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	require.Contains(t, buf.String(), "<style>"+testExpectedCSS+"body{background:#4d5e6f}</style>")
+	require.Contains(t, buf.String(), `<div class="box" aria-label="consent form" role="main"><img class="logo" src="https://example.com/logo.png" alt="">`+"\n")
+	require.Contains(t, buf.String(), "</div><footer class=\"footer\">Contact the &lt;help desk&gt;</footer>\n</body>")
+}
+
+func TestTemplateInAnotherLanguage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		ClientName: "test-client",
+		Scopes:     []string{"Ihre Identität"},
+		ID:         "test-id",
+		CSRFToken:  "test-csrf-token",
+		PostPath:   "test-post-path",
+		Messages:   i18n.NewCatalogs().ForRequest(req),
+	}
+
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	for _, want := range []string{
+		`<html lang="de">`,
+		`<title>Pinniped-Anmeldung</title>`,
+		`<div class="box" aria-label="Zustimmungsformular" role="main">`,
+		`<h1>Zugriff auf Ihr Konto für test-client erlauben?</h1>`,
+		`<span id="message">Die Anwendung erhält die folgenden Informationen über Sie.</span>`,
+		`<li>Ihre Identität</li>`,
+		`<input type="submit" id="allow" value="Erlauben"/>`,
+		`<input type="submit" class="deny" id="deny" value="Ablehnen"/>`,
+	} {
+		require.Contains(t, buf.String(), want)
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	require.Equal(t, testExpectedCSP, ContentSecurityPolicy())
+}
+
+func TestCSS(t *testing.T) {
+	require.Equal(t, testExpectedCSS, CSS())
+}
+
+func TestHelpers(t *testing.T) {
+	require.Equal(t, "test", panicOnError("test", nil))
+	require.PanicsWithError(t, "some error", func() { panicOnError("", fmt.Errorf("some error")) })
+}
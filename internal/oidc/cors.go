@@ -0,0 +1,82 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s.io/utils/strings/slices"
+
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+// CORSHandler returns a http.Handler which allows the origins of the CORS policy of a FederationDomain to make
+// cross-origin requests to an endpoint. The endpoint only allows cross-origin requests which use the given method,
+// and only when the policy allows that method. Preflight requests may only ask for the given request headers.
+// Cross-origin requests are never allowed to include credentials, such as cookies, so an endpoint which relies on
+// the cookies of the user's browser behaves as if the user had none. When the policy does not allow any cross-origin
+// requests to the endpoint, the next handler is returned unchanged.
+func CORSHandler(next http.Handler, policy provider.CORSPolicy, method string, allowedHeaders ...string) http.Handler {
+	if len(policy.AllowedOrigins) == 0 || !slices.Contains(policy.AllowedMethods, method) {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The responses depend on the origin of the request, so caches must not share them between origins.
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || !slices.Contains(policy.AllowedOrigins, origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if r.Header.Get("Access-Control-Request-Method") != method ||
+				!corsRequestHeadersAllowed(r.Header.Get("Access-Control-Request-Headers"), allowedHeaders) {
+				// Leaving out the CORS headers causes the browser to refuse to send the actual request.
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", method)
+			if len(allowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+			}
+			if policy.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if r.Method == method {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsRequestHeadersAllowed returns whether each of the comma-separated header names from the
+// Access-Control-Request-Headers header of a preflight request is one of the allowed header names.
+func corsRequestHeadersAllowed(requestHeaders string, allowedHeaders []string) bool {
+	for _, name := range strings.Split(requestHeaders, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		allowed := false
+		for _, allowedHeader := range allowedHeaders {
+			if strings.EqualFold(name, allowedHeader) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
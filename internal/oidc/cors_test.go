@@ -0,0 +1,189 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+func TestCORSHandler(t *testing.T) {
+	policy := provider.CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		MaxAge:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name           string
+		policy         provider.CORSPolicy
+		method         string
+		allowedHeaders []string
+		requestMethod  string
+		requestHeaders map[string]string
+		wantNextCalled bool
+		wantStatus     int
+		wantHeaders    map[string]string
+	}{
+		{
+			name:           "no policy",
+			method:         http.MethodGet,
+			requestMethod:  http.MethodGet,
+			requestHeaders: map[string]string{"Origin": "https://app.example.com"},
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    map[string]string{"Vary": "", "Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "policy which does not allow the method of the endpoint",
+			policy:         provider.CORSPolicy{AllowedOrigins: policy.AllowedOrigins, AllowedMethods: []string{http.MethodGet}},
+			method:         http.MethodPost,
+			requestMethod:  http.MethodPost,
+			requestHeaders: map[string]string{"Origin": "https://app.example.com"},
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    map[string]string{"Vary": "", "Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "same-origin request",
+			policy:         policy,
+			method:         http.MethodGet,
+			requestMethod:  http.MethodGet,
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    map[string]string{"Vary": "Origin", "Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "request from an allowed origin",
+			policy:         policy,
+			method:         http.MethodGet,
+			requestMethod:  http.MethodGet,
+			requestHeaders: map[string]string{"Origin": "https://app.example.com"},
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders: map[string]string{
+				"Vary":                             "Origin",
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:           "request from another origin",
+			policy:         policy,
+			method:         http.MethodGet,
+			requestMethod:  http.MethodGet,
+			requestHeaders: map[string]string{"Origin": "https://evil.example.com"},
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    map[string]string{"Vary": "Origin", "Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "request from an allowed origin with another method",
+			policy:         policy,
+			method:         http.MethodPost,
+			requestMethod:  http.MethodGet,
+			requestHeaders: map[string]string{"Origin": "https://app.example.com"},
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    map[string]string{"Vary": "Origin", "Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "preflight request from an allowed origin",
+			policy:         policy,
+			method:         http.MethodPost,
+			allowedHeaders: []string{"Content-Type"},
+			requestMethod:  http.MethodOptions,
+			requestHeaders: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type",
+			},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Vary":                             "Origin",
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "POST",
+				"Access-Control-Allow-Headers":     "Content-Type",
+				"Access-Control-Max-Age":           "600",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:           "preflight request from an allowed origin without a max age",
+			policy:         provider.CORSPolicy{AllowedOrigins: policy.AllowedOrigins, AllowedMethods: policy.AllowedMethods},
+			method:         http.MethodGet,
+			requestMethod:  http.MethodOptions,
+			requestHeaders: map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "GET"},
+			wantStatus:     http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET",
+				"Access-Control-Allow-Headers": "",
+				"Access-Control-Max-Age":       "",
+			},
+		},
+		{
+			name:           "preflight request for another method",
+			policy:         policy,
+			method:         http.MethodGet,
+			requestMethod:  http.MethodOptions,
+			requestHeaders: map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "POST"},
+			wantStatus:     http.StatusForbidden,
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:          "preflight request for headers which are not allowed",
+			policy:        policy,
+			method:        http.MethodGet,
+			requestMethod: http.MethodOptions,
+			requestHeaders: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "pinniped-username, pinniped-password",
+			},
+			wantStatus:  http.StatusForbidden,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:           "preflight request from another origin",
+			policy:         policy,
+			method:         http.MethodGet,
+			requestMethod:  http.MethodOptions,
+			requestHeaders: map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "GET"},
+			wantNextCalled: true,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			nextCalled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.requestMethod, "https://issuer.example.com/some/path", nil)
+			for k, v := range tt.requestHeaders {
+				req.Header.Set(k, v)
+			}
+			rsp := httptest.NewRecorder()
+
+			CORSHandler(next, tt.policy, tt.method, tt.allowedHeaders...).ServeHTTP(rsp, req)
+
+			require.Equal(t, tt.wantNextCalled, nextCalled)
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			for k, v := range tt.wantHeaders {
+				require.Equal(t, v, rsp.Header().Get(k), "header %s", k)
+			}
+		})
+	}
+}
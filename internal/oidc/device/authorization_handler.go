@@ -0,0 +1,173 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/utils/strings/slices"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/plog"
+)
+
+// AuthorizationResponse is the device authorization response, as defined by
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2.
+type AuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// NewAuthorizationHandler returns a http.Handler that serves the device authorization endpoint.
+//
+// Clients must authenticate in the same way as they would at the token endpoint, and they must be allowed to use the
+// device authorization grant.
+func NewAuthorizationHandler(
+	downstreamIssuer string,
+	clientManager fosite.ClientManager,
+	oauthHelper fosite.OAuth2Provider,
+	storage devicecode.Storage,
+	deviceCodeLifespan time.Duration,
+	generateUserCode func() (string, error),
+	generateSecret func() (string, error),
+	nowFunc func() time.Time,
+) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try POST)", r.Method)
+		}
+
+		if err := r.ParseForm(); err != nil {
+			return httperr.Wrap(http.StatusBadRequest, "error parsing request params", err)
+		}
+
+		client, err := authenticateClient(r.Context(), r, clientManager)
+		if err == nil {
+			err = validateClientAndScopes(client, r.PostForm.Get("scope"))
+		}
+		if err != nil {
+			plog.Info("device authorization request error", oidc.FositeErrorForLog(err)...)
+			oauthHelper.WriteAccessError(r.Context(), w, fosite.NewAccessRequest(nil), err)
+			return nil
+		}
+
+		userCode, err := generateUserCode()
+		if err != nil {
+			return httperr.Wrap(http.StatusInternalServerError, "error generating user code", err)
+		}
+		deviceCodeSecret, err := generateSecret()
+		if err != nil {
+			return httperr.Wrap(http.StatusInternalServerError, "error generating device code", err)
+		}
+		deviceCode := joinCode(userCode, deviceCodeSecret)
+
+		err = storage.CreateDeviceCodeSession(r.Context(), userCode, &devicecode.Session{
+			ClientID:            client.GetID(),
+			Scopes:              fosite.RemoveEmpty(strings.Split(r.PostForm.Get("scope"), " ")),
+			DeviceCodeSignature: devicecode.Signature(deviceCode),
+			Status:              devicecode.StatusPending,
+			Expiry:              nowFunc().Add(deviceCodeLifespan),
+			Interval:            defaultInterval,
+		})
+		if err != nil {
+			plog.Error("error saving device code session", err)
+			return httperr.New(http.StatusInternalServerError, "error saving device code session")
+		}
+
+		verificationURI := downstreamIssuer + oidc.PinnipedDeviceVerificationPath
+		response := &AuthorizationResponse{
+			DeviceCode:              deviceCode,
+			UserCode:                formatUserCode(userCode),
+			VerificationURI:         verificationURI,
+			VerificationURIComplete: verificationURI + "?" + url.Values{"user_code": {formatUserCode(userCode)}}.Encode(),
+			ExpiresIn:               int64(deviceCodeLifespan.Seconds()),
+			Interval:                int64(defaultInterval.Seconds()),
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		return json.NewEncoder(w).Encode(response)
+	})
+
+	return securityheader.Wrap(handler)
+}
+
+// authenticateClient authenticates the client in the same way as the token endpoint would. Confidential clients must
+// use HTTP basic auth with one of their client secrets, while public clients only need to send their client ID.
+func authenticateClient(ctx context.Context, r *http.Request, clientManager fosite.ClientManager) (fosite.Client, error) {
+	clientID, clientSecret, usedBasicAuth := r.BasicAuth()
+	if usedBasicAuth {
+		// Client credentials in basic auth are form encoded, as per https://datatracker.ietf.org/doc/html/rfc6749#section-2.3.1.
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err != nil {
+			return nil, fosite.ErrInvalidRequest.WithHint("The client ID in the HTTP authorization header could not be decoded.").WithWrap(err)
+		}
+		if clientSecret, err = url.QueryUnescape(clientSecret); err != nil {
+			return nil, fosite.ErrInvalidRequest.WithHint("The client secret in the HTTP authorization header could not be decoded.").WithWrap(err)
+		}
+	} else {
+		clientID = r.PostForm.Get("client_id")
+	}
+	if clientID == "" {
+		return nil, fosite.ErrInvalidRequest.WithHint("Client credentials missing or malformed in both HTTP Authorization header and HTTP POST body.")
+	}
+
+	client, err := clientManager.GetClient(ctx, clientID)
+	if err != nil {
+		return nil, fosite.ErrInvalidClient.WithWrap(err).WithDebug(err.Error())
+	}
+
+	if client.IsPublic() {
+		return client, nil
+	}
+
+	if !usedBasicAuth {
+		return nil, fosite.ErrInvalidClient.WithHint("The client must authenticate using HTTP basic auth.")
+	}
+	if !secretMatches(client, clientSecret) {
+		return nil, fosite.ErrInvalidClient.WithHint("The client secret is not valid.")
+	}
+
+	return client, nil
+}
+
+// secretMatches returns true when the secret matches the current or any of the rotated hashed secrets of the client.
+func secretMatches(client fosite.Client, secret string) bool {
+	hashes := [][]byte{client.GetHashedSecret()}
+	if rotatingClient, ok := client.(fosite.ClientWithSecretRotation); ok {
+		hashes = append(hashes, rotatingClient.GetRotatedHashes()...)
+	}
+	for _, hash := range hashes {
+		if len(hash) > 0 && bcrypt.CompareHashAndPassword(hash, []byte(secret)) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func validateClientAndScopes(client fosite.Client, scope string) error {
+	if !slices.Contains(client.GetGrantTypes(), oidcapi.GrantTypeDeviceCode) {
+		return fosite.ErrUnauthorizedClient.WithHintf("The client is not allowed to request the %q grant type.", oidcapi.GrantTypeDeviceCode)
+	}
+	for _, requestedScope := range fosite.RemoveEmpty(strings.Split(scope, " ")) {
+		if !slices.Contains(client.GetScopes(), requestedScope) {
+			return fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", requestedScope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestAuthorizationHandler(t *testing.T) {
+	const (
+		fakeUserCode         = "BCDFGHJK"
+		fakeDeviceCodeSecret = "fake-device-code-secret"
+	)
+
+	fakeNow := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	hmacSecretFunc := func() []byte { return []byte("some secret - must have at least 32 bytes") }
+
+	tests := []struct {
+		name           string
+		method         string
+		form           url.Values
+		basicAuthID    string
+		basicAuthPass  string
+		wantStatus     int
+		wantBodyJSON   string
+		wantErrorField string
+		wantSession    *devicecode.Session
+	}{
+		{
+			name:       "public client",
+			form:       url.Values{"client_id": {"pinniped-cli"}, "scope": {"openid offline_access"}},
+			wantStatus: http.StatusOK,
+			wantBodyJSON: `{
+				"device_code": "BCDFGHJK.fake-device-code-secret",
+				"user_code": "BCDF-GHJK",
+				"verification_uri": "https://my-downstream-issuer.com/some-path/device",
+				"verification_uri_complete": "https://my-downstream-issuer.com/some-path/device?user_code=BCDF-GHJK",
+				"expires_in": 600,
+				"interval": 5
+			}`,
+			wantSession: &devicecode.Session{
+				ClientID:            "pinniped-cli",
+				Scopes:              []string{"openid", "offline_access"},
+				DeviceCodeSignature: devicecode.Signature("BCDFGHJK.fake-device-code-secret"),
+				Status:              devicecode.StatusPending,
+				Expiry:              fakeNow.Add(10 * time.Minute),
+				Interval:            5 * time.Second,
+				Version:             "1",
+			},
+		},
+		{
+			name:          "confidential client using basic auth",
+			form:          url.Values{"scope": {"openid groups"}},
+			basicAuthID:   testDynamicClientID,
+			basicAuthPass: testutil.PlaintextPassword1,
+			wantStatus:    http.StatusOK,
+			wantBodyJSON: `{
+				"device_code": "BCDFGHJK.fake-device-code-secret",
+				"user_code": "BCDF-GHJK",
+				"verification_uri": "https://my-downstream-issuer.com/some-path/device",
+				"verification_uri_complete": "https://my-downstream-issuer.com/some-path/device?user_code=BCDF-GHJK",
+				"expires_in": 600,
+				"interval": 5
+			}`,
+			wantSession: &devicecode.Session{
+				ClientID:            testDynamicClientID,
+				Scopes:              []string{"openid", "groups"},
+				DeviceCodeSignature: devicecode.Signature("BCDFGHJK.fake-device-code-secret"),
+				Status:              devicecode.StatusPending,
+				Expiry:              fakeNow.Add(10 * time.Minute),
+				Interval:            5 * time.Second,
+				Version:             "1",
+			},
+		},
+		{
+			name:           "confidential client without basic auth",
+			form:           url.Values{"client_id": {testDynamicClientID}, "scope": {"openid"}},
+			wantStatus:     http.StatusUnauthorized,
+			wantErrorField: "invalid_client",
+		},
+		{
+			name:           "confidential client with the wrong client secret",
+			form:           url.Values{"scope": {"openid"}},
+			basicAuthID:    testDynamicClientID,
+			basicAuthPass:  testutil.PlaintextPassword2,
+			wantStatus:     http.StatusUnauthorized,
+			wantErrorField: "invalid_client",
+		},
+		{
+			name:           "client which is not allowed to use the device authorization grant",
+			form:           url.Values{"scope": {"openid"}},
+			basicAuthID:    testDynamicClientID + "-without-device-grant",
+			basicAuthPass:  testutil.PlaintextPassword1,
+			wantStatus:     http.StatusBadRequest,
+			wantErrorField: "unauthorized_client",
+		},
+		{
+			name:           "client which is not allowed to request a scope",
+			form:           url.Values{"scope": {"openid offline_access"}},
+			basicAuthID:    testDynamicClientID,
+			basicAuthPass:  testutil.PlaintextPassword1,
+			wantStatus:     http.StatusBadRequest,
+			wantErrorField: "invalid_scope",
+		},
+		{
+			name:           "unknown client",
+			form:           url.Values{"client_id": {"client.oauth.pinniped.dev-does-not-exist"}},
+			wantStatus:     http.StatusUnauthorized,
+			wantErrorField: "invalid_client",
+		},
+		{
+			name:           "missing client ID",
+			form:           url.Values{"scope": {"openid"}},
+			wantStatus:     http.StatusBadRequest,
+			wantErrorField: "invalid_request",
+		},
+		{
+			name:       "bad method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			fositeStorage := makeTestFositeStorage(t)
+			oauthHelper := oidc.FositeOauth2Helper(fositeStorage, testIssuer, hmacSecretFunc, nil, oidc.DefaultOIDCTimeoutsConfiguration(), nil, nil)
+			storage := devicecode.New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() time.Time { return fakeNow }, 11*time.Minute)
+
+			subject := NewAuthorizationHandler(
+				testIssuer,
+				fositeStorage,
+				oauthHelper,
+				storage,
+				10*time.Minute,
+				func() (string, error) { return fakeUserCode, nil },
+				func() (string, error) { return fakeDeviceCodeSecret, nil },
+				func() time.Time { return fakeNow },
+			)
+
+			method := http.MethodPost
+			if test.method != "" {
+				method = test.method
+			}
+			req := httptest.NewRequest(method, testIssuer+oidc.DeviceAuthorizationEndpointPath, strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.basicAuthID != "" {
+				req.SetBasicAuth(test.basicAuthID, test.basicAuthPass)
+			}
+			rsp := httptest.NewRecorder()
+
+			subject.ServeHTTP(rsp, req)
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			testutil.RequireSecurityHeadersWithoutCustomCSPs(t, rsp)
+
+			if test.wantBodyJSON != "" {
+				require.Equal(t, "application/json; charset=utf-8", rsp.Header().Get("Content-Type"))
+				require.JSONEq(t, test.wantBodyJSON, rsp.Body.String())
+			}
+			if test.wantErrorField != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &body))
+				require.Equal(t, test.wantErrorField, body["error"])
+			}
+
+			session, _, err := storage.GetDeviceCodeSession(ctx, fakeUserCode)
+			if test.wantSession == nil {
+				require.ErrorIs(t, err, fosite.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantSession, session)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/ory/fosite"
+
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc/device/devicehtml"
+	"go.pinniped.dev/internal/oidc/i18n"
+	"go.pinniped.dev/internal/plog"
+)
+
+// NewCallbackHandler returns a http.Handler that serves the device callback endpoint, which is the redirect_uri of
+// the downstream authorization requests which were started by the verification page.
+//
+// The state param identifies the device authorization request. When the login succeeded, the downstream authcode
+// is remembered with the device authorization request, so that the token endpoint can redeem it when the device
+// polls for its tokens. When the login failed, the device authorization request is denied. The resulting page is
+// translated to the language of the request using the translations.
+func NewCallbackHandler(storage devicecode.Storage, translations *i18n.Catalogs) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try GET)", r.Method)
+		}
+
+		state := r.FormValue("state")
+		userCode, ok := splitCode(state)
+		if !ok {
+			return httperr.New(http.StatusBadRequest, "state param not found")
+		}
+
+		session, rv, err := storage.GetDeviceCodeSession(r.Context(), userCode)
+		if errors.Is(err, fosite.ErrNotFound) {
+			return httperr.New(http.StatusBadRequest, "device authorization request not found")
+		}
+		if err != nil {
+			plog.Error("error reading device code session", err)
+			return httperr.New(http.StatusInternalServerError, "error reading device code session")
+		}
+
+		if session.State == "" || subtle.ConstantTimeCompare([]byte(session.State), []byte(state)) != 1 {
+			return httperr.New(http.StatusBadRequest, "state param does not match")
+		}
+		if session.Status != devicecode.StatusPending {
+			return httperr.New(http.StatusBadRequest, "device authorization request was already completed")
+		}
+
+		messages := translations.ForRequest(r)
+		pageData := &devicehtml.PageData{
+			Heading:  messages.Text(i18n.DeviceApprovedHeading),
+			Message:  messages.Text(i18n.DeviceApprovedMessage),
+			Messages: messages,
+		}
+		if errorParam := r.FormValue("error"); errorParam != "" {
+			plog.Info("device authorization request was denied",
+				"clientID", session.ClientID,
+				"error", errorParam,
+				"errorDescription", r.FormValue("error_description"))
+			session.Status = devicecode.StatusDenied
+			pageData = &devicehtml.PageData{
+				Heading:  messages.Text(i18n.DeviceDeniedHeading),
+				Message:  messages.Text(i18n.DeviceDeniedMessage),
+				Messages: messages,
+			}
+		} else {
+			authcode := r.FormValue("code")
+			if authcode == "" {
+				return httperr.New(http.StatusBadRequest, "code param not found")
+			}
+			session.Status = devicecode.StatusApproved
+			session.Authcode = authcode
+		}
+
+		if err = storage.UpdateDeviceCodeSession(r.Context(), userCode, rv, session); err != nil {
+			plog.Error("error saving device code session", err)
+			return httperr.New(http.StatusInternalServerError, "error saving device code session")
+		}
+
+		return devicehtml.Template().Execute(w, pageData)
+	})
+
+	return securityheader.WrapWithCustomCSP(handler, devicehtml.ContentSecurityPolicy())
+}
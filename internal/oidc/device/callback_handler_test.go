@@ -0,0 +1,201 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestCallbackHandler(t *testing.T) {
+	const (
+		htmlContentType  = "text/html; charset=utf-8"
+		plainContentType = "text/plain; charset=utf-8"
+		happyUserCode    = "BCDFGHJK"
+		happyState       = happyUserCode + ".test-state-secret"
+	)
+
+	fakeNow := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	startedSession := func(modify func(s *devicecode.Session)) *devicecode.Session {
+		s := &devicecode.Session{
+			ClientID:            "pinniped-cli",
+			Scopes:              []string{"openid", "offline_access"},
+			DeviceCodeSignature: "fake-device-code-signature",
+			Status:              devicecode.StatusPending,
+			Expiry:              fakeNow.Add(5 * time.Minute),
+			Interval:            5 * time.Second,
+			State:               happyState,
+			PKCECodeVerifier:    "test-pkce",
+			Version:             "1",
+		}
+		if modify != nil {
+			modify(s)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		query          url.Values
+		acceptLanguage string
+		storedSession  *devicecode.Session
+
+		wantStatus       int
+		wantContentType  string
+		wantBody         string
+		wantBodyContains []string
+		wantSession      *devicecode.Session
+	}{
+		{
+			name:            "successful login approves the device authorization request",
+			query:           url.Values{"state": {happyState}, "code": {"some-authcode"}},
+			storedSession:   startedSession(nil),
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBodyContains: []string{
+				`<html lang="en">`,
+				"<h1>Login succeeded</h1>",
+				"You have logged in. You may now close this page and return to your device.",
+			},
+			wantSession: startedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+			}),
+		},
+		{
+			name:             "successful login in the language of the browser",
+			query:            url.Values{"state": {happyState}, "code": {"some-authcode"}},
+			acceptLanguage:   "de-DE,de;q=0.9,en;q=0.8",
+			storedSession:    startedSession(nil),
+			wantStatus:       http.StatusOK,
+			wantContentType:  htmlContentType,
+			wantBodyContains: []string{`<html lang="de">`, "<h1>Anmeldung erfolgreich</h1>"},
+			wantSession: startedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+			}),
+		},
+		{
+			name:             "failed login denies the device authorization request",
+			query:            url.Values{"state": {happyState}, "error": {"access_denied"}, "error_description": {"some description"}},
+			storedSession:    startedSession(nil),
+			wantStatus:       http.StatusOK,
+			wantContentType:  htmlContentType,
+			wantBodyContains: []string{"<h1>Login failed</h1>", "Your device was not logged in. Please start over on your device."},
+			wantSession: startedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusDenied
+			}),
+		},
+		{
+			name:            "missing code param",
+			query:           url.Values{"state": {happyState}},
+			storedSession:   startedSession(nil),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: plainContentType,
+			wantBody:        "Bad Request: code param not found\n",
+			wantSession:     startedSession(nil),
+		},
+		{
+			name:            "wrong state param",
+			query:           url.Values{"state": {happyUserCode + ".some-other-secret"}, "code": {"some-authcode"}},
+			storedSession:   startedSession(nil),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: plainContentType,
+			wantBody:        "Bad Request: state param does not match\n",
+			wantSession:     startedSession(nil),
+		},
+		{
+			name:            "malformed state param",
+			query:           url.Values{"state": {"some-state"}, "code": {"some-authcode"}},
+			storedSession:   startedSession(nil),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: plainContentType,
+			wantBody:        "Bad Request: state param not found\n",
+			wantSession:     startedSession(nil),
+		},
+		{
+			name:            "unknown device authorization request",
+			query:           url.Values{"state": {"BCDFGHJL.test-state-secret"}, "code": {"some-authcode"}},
+			storedSession:   startedSession(nil),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: plainContentType,
+			wantBody:        "Bad Request: device authorization request not found\n",
+			wantSession:     startedSession(nil),
+		},
+		{
+			name:  "device authorization request which was already completed",
+			query: url.Values{"state": {happyState}, "code": {"some-other-authcode"}},
+			storedSession: startedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+			}),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: plainContentType,
+			wantBody:        "Bad Request: device authorization request was already completed\n",
+			wantSession: startedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+			}),
+		},
+		{
+			name:            "POST method is invalid",
+			method:          http.MethodPost,
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantContentType: plainContentType,
+			wantBody:        "Method Not Allowed: POST (try GET)\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			storage := devicecode.New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() time.Time { return fakeNow }, 11*time.Minute)
+			if test.storedSession != nil {
+				require.NoError(t, storage.CreateDeviceCodeSession(ctx, happyUserCode, test.storedSession))
+			}
+
+			method := http.MethodGet
+			if test.method != "" {
+				method = test.method
+			}
+			req := httptest.NewRequest(method, "/some-path/device/callback?"+test.query.Encode(), nil)
+			if test.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", test.acceptLanguage)
+			}
+			rsp := httptest.NewRecorder()
+
+			NewCallbackHandler(storage, nil).ServeHTTP(rsp, req)
+
+			testutil.RequireSecurityHeadersWithLoginPageCSPs(t, rsp)
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			testutil.RequireEqualContentType(t, rsp.Header().Get("Content-Type"), test.wantContentType)
+			if test.wantBody != "" {
+				require.Equal(t, test.wantBody, rsp.Body.String())
+			}
+			for _, want := range test.wantBodyContains {
+				require.Contains(t, rsp.Body.String(), want)
+			}
+
+			if test.wantSession != nil {
+				session, _, err := storage.GetDeviceCodeSession(ctx, happyUserCode)
+				require.NoError(t, err)
+				require.Equal(t, test.wantSession, session)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package device provides the handlers for RFC8628 device authorization grants, which allow users to log in on
+// hosts without a web browser by completing their login in a web browser on another device.
+//
+// The client starts at the device authorization endpoint, which issues a device code to the client and a user code
+// for the user. The user enters the user code on the verification page, which starts a downstream authorization
+// request for the client, with the device callback endpoint of the FederationDomain as its redirect_uri. From there,
+// the user logs in as usual. When the login is done, the device callback endpoint receives the downstream authcode and
+// remembers it with the device code. Meanwhile, the client polls the token endpoint with its device code, and once the
+// authcode has arrived, the token endpoint redeems it on behalf of the client. This way, the device grant issues
+// exactly the same tokens as the authorization code grant.
+package device
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/fositestoragei"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+)
+
+const (
+	// defaultInterval is how long clients must wait between their requests to the token endpoint, unless they were
+	// told to slow down.
+	defaultInterval = 5 * time.Second
+
+	// slowDownIncrement is added to the interval of a client each time that it is told to slow down, as required by
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-3.5.
+	slowDownIncrement = 5 * time.Second
+
+	// userCodeCharset is the charset for user codes which is recommended by
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-6.1. It has no vowels, to avoid accidentally forming
+	// words, and no characters which are easily confused with each other.
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+	// userCodeLength is the number of characters in a user code, which gives about 34 bits of entropy.
+	userCodeLength = 8
+
+	// codeSeparator separates the user code from the random part of device codes and state params.
+	codeSeparator = "."
+)
+
+// GenerateUserCode generates a new random user code, e.g. "WDJBMJHT".
+func GenerateUserCode() (string, error) { return generateUserCode(rand.Reader) }
+
+func generateUserCode(rand io.Reader) (string, error) {
+	var buf [userCodeLength]byte
+	if _, err := io.ReadFull(rand, buf[:]); err != nil {
+		return "", fmt.Errorf("could not generate user code: %w", err)
+	}
+	userCode := make([]byte, userCodeLength)
+	for i, b := range buf {
+		// The modulo bias is negligible, because 256 is almost a multiple of 20.
+		userCode[i] = userCodeCharset[int(b)%len(userCodeCharset)]
+	}
+	return string(userCode), nil
+}
+
+// GenerateSecret generates a new random value which is hard to guess, for use in device codes and state params.
+func GenerateSecret() (string, error) { return generateSecret(rand.Reader) }
+
+func generateSecret(rand io.Reader) (string, error) {
+	var buf [32]byte
+	if _, err := io.ReadFull(rand, buf[:]); err != nil {
+		return "", fmt.Errorf("could not generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf[:]), nil
+}
+
+// formatUserCode formats a user code for display, e.g. "WDJB-MJHT".
+func formatUserCode(userCode string) string {
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+// normalizeUserCode turns a user code which was typed by a user into its canonical form, since users may type it
+// in lower case, or with the dash, or with spaces.
+func normalizeUserCode(typed string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(typed) {
+		if strings.ContainsRune(userCodeCharset, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// joinCode prefixes a secret value with the user code to which it belongs, which allows the storage of the device
+// authorization request to be found by the holder of the value.
+func joinCode(userCode, secret string) string {
+	return userCode + codeSeparator + secret
+}
+
+// splitCode returns the user code from the prefix of a value which was made by joinCode.
+func splitCode(code string) (string, bool) {
+	userCode, _, found := strings.Cut(code, codeSeparator)
+	return userCode, found && len(userCode) == userCodeLength
+}
+
+// signatureMatches returns true when the given value has the given signature, in constant time.
+func signatureMatches(value, signature string) bool {
+	return subtle.ConstantTimeCompare([]byte(devicecode.Signature(value)), []byte(signature)) == 1
+}
+
+// NewDeviceCallbackStorage wraps the storage of an oauth helper, so that the clients which are allowed to use the
+// device authorization grant also allow the device callback endpoint of the FederationDomain as their redirect_uri.
+// This allows the verification page to start downstream authorization requests on behalf of those clients.
+func NewDeviceCallbackStorage(storage fositestoragei.AllFositeStorage, deviceCallbackURL string) fositestoragei.AllFositeStorage {
+	return &deviceCallbackStorage{
+		AllFositeStorage:  storage,
+		deviceCallbackURL: deviceCallbackURL,
+	}
+}
+
+type deviceCallbackStorage struct {
+	fositestoragei.AllFositeStorage
+	deviceCallbackURL string
+}
+
+func (s *deviceCallbackStorage) GetClient(ctx context.Context, id string) (fosite.Client, error) {
+	client, err := s.AllFositeStorage.GetClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := client.(*clientregistry.Client)
+	if !ok || !c.GetGrantTypes().Has(oidcapi.GrantTypeDeviceCode) {
+		return client, nil
+	}
+	// Make a copy, since the client may be shared, e.g. the static pinniped-cli client.
+	clientCopy := *c
+	defaultClientCopy := *c.DefaultClient
+	defaultClientCopy.RedirectURIs = append(append([]string{}, c.RedirectURIs...), s.deviceCallbackURL)
+	clientCopy.DefaultClient = &defaultClientCopy
+	return &clientCopy, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/fositestoragei"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
+	"go.pinniped.dev/internal/testutil"
+)
+
+const (
+	testNamespace         = "some-namespace"
+	testIssuer            = "https://my-downstream-issuer.com/some-path"
+	testDeviceCallbackURL = testIssuer + "/device/callback"
+	testDynamicClientID   = "client.oauth.pinniped.dev-test-name"
+	testDynamicClientUID  = "fake-client-uid"
+)
+
+func TestGenerateUserCode(t *testing.T) {
+	userCode, err := GenerateUserCode()
+	require.NoError(t, err)
+	require.Len(t, userCode, userCodeLength)
+	require.Equal(t, userCode, normalizeUserCode(userCode))
+
+	userCode, err = generateUserCode(strings.NewReader("\x00\x01\x02\x03\x13\x14\x15\xff"))
+	require.NoError(t, err)
+	require.Equal(t, "BCDFZBCT", userCode)
+
+	var empty bytes.Buffer
+	userCode, err = generateUserCode(&empty)
+	require.EqualError(t, err, "could not generate user code: EOF")
+	require.Empty(t, userCode)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 43)
+
+	var empty bytes.Buffer
+	secret, err = generateSecret(&empty)
+	require.EqualError(t, err, "could not generate random value: EOF")
+	require.Empty(t, secret)
+}
+
+func TestUserCodeFormatting(t *testing.T) {
+	require.Equal(t, "WDJB-MJHT", formatUserCode("WDJBMJHT"))
+
+	tests := []struct {
+		typed string
+		want  string
+	}{
+		{typed: "WDJBMJHT", want: "WDJBMJHT"},
+		{typed: "WDJB-MJHT", want: "WDJBMJHT"},
+		{typed: " wdjb mjht\n", want: "WDJBMJHT"},
+		{typed: "AEIOU-WDJB", want: "WDJB"},
+		{typed: "", want: ""},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, normalizeUserCode(test.typed), "typed: %q", test.typed)
+	}
+}
+
+func TestSplitCode(t *testing.T) {
+	tests := []struct {
+		code         string
+		wantUserCode string
+		wantOK       bool
+	}{
+		{code: joinCode("WDJBMJHT", "some-secret"), wantUserCode: "WDJBMJHT", wantOK: true},
+		{code: "WDJBMJHT.", wantUserCode: "WDJBMJHT", wantOK: true},
+		{code: "WDJBMJHT", wantUserCode: "WDJBMJHT", wantOK: false},
+		{code: "WDJB.some-secret", wantUserCode: "WDJB", wantOK: false},
+		{code: "", wantUserCode: "", wantOK: false},
+	}
+	for _, test := range tests {
+		userCode, ok := splitCode(test.code)
+		require.Equal(t, test.wantUserCode, userCode, "code: %q", test.code)
+		require.Equal(t, test.wantOK, ok, "code: %q", test.code)
+	}
+}
+
+func TestDeviceCallbackStorage(t *testing.T) {
+	ctx := context.Background()
+	storage := makeTestFositeStorage(t)
+
+	client, err := storage.GetClient(ctx, "pinniped-cli")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://127.0.0.1/callback", testDeviceCallbackURL}, client.GetRedirectURIs())
+	require.Equal(t, []string{"http://127.0.0.1/callback"}, clientregistry.PinnipedCLI().GetRedirectURIs())
+
+	client, err = storage.GetClient(ctx, testDynamicClientID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://client.example.com/callback", testDeviceCallbackURL}, client.GetRedirectURIs())
+
+	client, err = storage.GetClient(ctx, testDynamicClientID+"-without-device-grant")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://client.example.com/callback"}, client.GetRedirectURIs())
+
+	client, err = storage.GetClient(ctx, "client.oauth.pinniped.dev-does-not-exist")
+	require.ErrorIs(t, err, fosite.ErrNotFound)
+	require.Nil(t, client)
+}
+
+// makeTestFositeStorage returns the storage of an oauth helper which knows about the pinniped-cli client, a dynamic
+// client which may use the device authorization grant, and another dynamic client which may not.
+func makeTestFositeStorage(t *testing.T) fositestoragei.AllFositeStorage {
+	t.Helper()
+
+	kubeClient := fake.NewSimpleClientset()
+	supervisorClient := supervisorfake.NewSimpleClientset()
+
+	oidcClient, secret := testutil.OIDCClientAndStorageSecret(t,
+		testNamespace, testDynamicClientID, testDynamicClientUID,
+		[]configv1alpha1.GrantType{"authorization_code", "urn:ietf:params:oauth:grant-type:device_code"},
+		[]configv1alpha1.Scope{"openid", "username", "groups"},
+		"https://client.example.com/callback",
+		[]string{testutil.HashedPassword1AtGoMinCost}, oidcclientvalidator.Validate)
+	require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+	require.NoError(t, kubeClient.Tracker().Add(secret))
+
+	otherOIDCClient, otherSecret := testutil.OIDCClientAndStorageSecret(t,
+		testNamespace, testDynamicClientID+"-without-device-grant", testDynamicClientUID+"-without-device-grant",
+		[]configv1alpha1.GrantType{"authorization_code"},
+		[]configv1alpha1.Scope{"openid", "username", "groups"},
+		"https://client.example.com/callback",
+		[]string{testutil.HashedPassword1AtGoMinCost}, oidcclientvalidator.Validate)
+	require.NoError(t, supervisorClient.Tracker().Add(otherOIDCClient))
+	require.NoError(t, kubeClient.Tracker().Add(otherSecret))
+
+	// Use lower minimum required bcrypt cost than we would use in production to keep unit the tests fast.
+	return NewDeviceCallbackStorage(
+		oidc.NewKubeStorage(
+			kubeClient.CoreV1().Secrets(testNamespace),
+			supervisorClient.ConfigV1alpha1().OIDCClients(testNamespace),
+			oidc.DefaultOIDCTimeoutsConfiguration(),
+			bcrypt.MinCost,
+		),
+		testDeviceCallbackURL,
+	)
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package devicehtml
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/oidc/i18n"
+)
+
+var (
+	testExpectedCSS = `html{height:100%}body{font-family:metropolis-light,Helvetica,sans-serif;display:flex;flex-flow:column wrap;justify-content:flex-start;align-items:center;background:linear-gradient(to top,#f8f8f8,white);min-height:100%}h1{font-size:20px;margin:0}.box{display:flex;flex-direction:column;flex-wrap:nowrap;border-radius:4px;border-color:#ddd;border-width:1px;border-style:solid;width:400px;padding:30px 30px 0;margin:60px 20px 0;background:#fff;font-size:14px}input{color:inherit;font:inherit;border:0;margin:0;outline:0;padding:0}.form-field{display:flex;margin-bottom:30px}.form-field input[type=password],.form-field input[type=text],.form-field input[type=submit]{width:100%;padding:1em}.form-field input[type=password],.form-field input[type=text]{border-radius:3px;border-width:1px;border-style:solid;border-color:#a6a6a6}.form-field input[type=submit]{background-color:#218fcf;color:#eee;font-weight:700;cursor:pointer;transition:all .3s}.form-field input[type=submit]:focus,.form-field input[type=submit]:hover{background-color:#1abfd3}.form-field input[type=submit]:active{transform:scale(.99)}.hidden{border:0;clip:rect(0 0 0 0);height:1px;margin:-1px;overflow:hidden;padding:0;position:absolute;width:1px}.alert{color:crimson}`
+
+	// It's okay if this changes in the future, but this gives us a chance to eyeball the formatting.
+	// Our browser-based integration tests should find any incompatibilities.
+	testExpectedCSP = `default-src 'none'; ` +
+		`style-src 'sha256-QC9ckaUFAdcN0Ysmu8q8iqCazYFgrJSQDJPa/przPXU='; ` +
+		`frame-ancestors 'none'`
+)
+
+func TestTemplate(t *testing.T) {
+	germanRequest := httptest.NewRequest(http.MethodGet, "/", nil)
+	germanRequest.Header.Set("Accept-Language", "de")
+
+	tests := []struct {
+		name         string
+		pageInputs   *PageData
+		expectedHTML string
+	}{
+		{
+			name: "form with an alert",
+			pageInputs: &PageData{
+				Heading:      "test-heading",
+				AlertMessage: "test-alert-message",
+				ShowForm:     true,
+				PostPath:     "test-post-path",
+				CSRFToken:    "test-csrf-token",
+				UserCode:     "WDJB-MJHT",
+			},
+			expectedHTML: here.Docf(`<!DOCTYPE html>
+                <html lang="en">
+                <head>
+                    <title>Pinniped Login</title>
+                    <meta charset="UTF-8">
+                    <style>%s</style>
+                </head>
+                <body>
+                <div class="box" aria-label="device login" role="main">
+                    <div class="form-field">
+                        <h1>test-heading</h1>
+                    </div>
+                    <div class="form-field">
+                        <span class="alert" role="alert" aria-label="login error message" id="alert">test-alert-message</span>
+                    </div>
+                    <form action="test-post-path" method="post">
+                        <input type="hidden" name="csrf" id="csrf" value="test-csrf-token">
+                        <div class="form-field">
+                            <label for="user_code"><span class="hidden" aria-hidden="true">Code</span></label>
+                            <input type="text" name="user_code" id="user_code" value="WDJB-MJHT"
+                                   autocomplete="off" placeholder="Code" required>
+                        </div>
+                        <div class="form-field">
+                            <input type="submit" name="submit" id="submit" value="Continue"/>
+                        </div>
+                    </form>
+                </div>
+                </body>
+                </html>
+			`, testExpectedCSS),
+		},
+		{
+			name: "message without a form",
+			pageInputs: &PageData{
+				Heading: "test-heading",
+				Message: "test-message",
+			},
+			expectedHTML: here.Docf(`<!DOCTYPE html>
+                <html lang="en">
+                <head>
+                    <title>Pinniped Login</title>
+                    <meta charset="UTF-8">
+                    <style>%s</style>
+                </head>
+                <body>
+                <div class="box" aria-label="device login" role="main">
+                    <div class="form-field">
+                        <h1>test-heading</h1>
+                    </div>
+                    <div class="form-field">
+                        <span id="message">test-message</span>
+                    </div>
+                </div>
+                </body>
+                </html>
+			`, testExpectedCSS),
+		},
+		{
+			name: "form in another language",
+			pageInputs: &PageData{
+				Heading:  "test-heading",
+				ShowForm: true,
+				PostPath: "test-post-path",
+				Messages: i18n.NewCatalogs().ForRequest(germanRequest),
+			},
+			expectedHTML: here.Docf(`<!DOCTYPE html>
+                <html lang="de">
+                <head>
+                    <title>Pinniped-Anmeldung</title>
+                    <meta charset="UTF-8">
+                    <style>%s</style>
+                </head>
+                <body>
+                <div class="box" aria-label="Geräteanmeldung" role="main">
+                    <div class="form-field">
+                        <h1>test-heading</h1>
+                    </div>
+                    <form action="test-post-path" method="post">
+                        <input type="hidden" name="csrf" id="csrf" value="">
+                        <div class="form-field">
+                            <label for="user_code"><span class="hidden" aria-hidden="true">Code</span></label>
+                            <input type="text" name="user_code" id="user_code" value=""
+                                   autocomplete="off" placeholder="Code" required>
+                        </div>
+                        <div class="form-field">
+                            <input type="submit" name="submit" id="submit" value="Weiter"/>
+                        </div>
+                    </form>
+                </div>
+                </body>
+                </html>
+			`, testExpectedCSS),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Template().Execute(&buf, test.pageInputs))
+			// t.Logf("actual value:\n%s", buf.String()) // useful when updating minify library causes new output
+			require.Equal(t, test.expectedHTML, buf.String())
+		})
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	require.Equal(t, testExpectedCSP, ContentSecurityPolicy())
+}
+
+func TestCSS(t *testing.T) {
+	require.Equal(t, testExpectedCSS, CSS())
+}
+
+func TestHelpers(t *testing.T) {
+	require.Equal(t, "test", panicOnError("test", nil))
+	require.PanicsWithError(t, "some error", func() { panicOnError("", fmt.Errorf("some error")) })
+}
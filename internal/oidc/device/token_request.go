@@ -0,0 +1,137 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ory/fosite"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/plog"
+)
+
+const deviceCodeParamName = "device_code"
+
+// RewriteTokenRequest handles a token request which uses the device authorization grant, before it is handled by
+// fosite, which does not support that grant.
+//
+// While the user has not finished logging in, an error is returned which tells the client to keep polling, as
+// described by https://datatracker.ietf.org/doc/html/rfc8628#section-3.5. Once the user has logged in, the request
+// is rewritten into an authorization code grant which redeems the downstream authcode on behalf of the client, so
+// that fosite can handle it as usual. The device code can only be redeemed once, like an authcode.
+func RewriteTokenRequest(ctx context.Context, r *http.Request, storage devicecode.Storage, deviceCallbackURL string, now time.Time) error {
+	deviceCode := r.PostFormValue(deviceCodeParamName)
+	userCode, ok := splitCode(deviceCode)
+	if !ok {
+		return fosite.ErrInvalidGrant.WithHint("The device code is malformed.")
+	}
+
+	session, rv, err := storage.GetDeviceCodeSession(ctx, userCode)
+	if errors.Is(err, fosite.ErrNotFound) {
+		return fosite.ErrInvalidGrant.WithHint("The device code is not valid.")
+	}
+	if err != nil {
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+	if !signatureMatches(deviceCode, session.DeviceCodeSignature) {
+		return fosite.ErrInvalidGrant.WithHint("The device code is not valid.")
+	}
+	if requestingClientID(r) != session.ClientID {
+		return fosite.ErrInvalidGrant.WithHint("The device code was issued to another client.")
+	}
+
+	if !now.Before(session.Expiry) {
+		deleteSession(ctx, storage, userCode)
+		return errExpiredToken()
+	}
+
+	switch session.Status {
+	case devicecode.StatusDenied:
+		deleteSession(ctx, storage, userCode)
+		return fosite.ErrAccessDenied.WithHint("The user did not complete the login.")
+	case devicecode.StatusPending:
+		tooSoon := now.Sub(session.LastPolledAt) < session.Interval
+		if tooSoon {
+			session.Interval += slowDownIncrement
+		}
+		session.LastPolledAt = now
+		if err = storage.UpdateDeviceCodeSession(ctx, userCode, rv, session); err != nil {
+			return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+		}
+		if tooSoon {
+			return errSlowDown()
+		}
+		return errAuthorizationPending()
+	case devicecode.StatusApproved:
+		// Delete first, so that the device code cannot be redeemed twice.
+		if err = storage.DeleteDeviceCodeSession(ctx, userCode); err != nil {
+			return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+		}
+	default:
+		return fosite.ErrServerError.WithHintf("The device code has an unknown status %q.", session.Status)
+	}
+
+	for _, form := range []url.Values{r.PostForm, r.Form} {
+		if form == nil {
+			continue
+		}
+		form.Del(deviceCodeParamName)
+		form.Set("grant_type", oidcapi.GrantTypeAuthorizationCode)
+		form.Set("code", session.Authcode)
+		form.Set("code_verifier", session.PKCECodeVerifier)
+		form.Set("redirect_uri", deviceCallbackURL)
+	}
+
+	return nil
+}
+
+// requestingClientID returns the client ID of the request, which is only a claim at this point. Fosite will
+// authenticate the client later, as usual.
+func requestingClientID(r *http.Request) string {
+	if username, _, ok := r.BasicAuth(); ok {
+		if clientID, err := url.QueryUnescape(username); err == nil {
+			return clientID
+		}
+		return ""
+	}
+	return r.PostFormValue("client_id")
+}
+
+// deleteSession deletes a device authorization request which can never succeed. Failures are only logged, since the
+// storage will be garbage collected anyway.
+func deleteSession(ctx context.Context, storage devicecode.Storage, userCode string) {
+	if err := storage.DeleteDeviceCodeSession(ctx, userCode); err != nil {
+		plog.Error("error deleting device code session", err)
+	}
+}
+
+func errAuthorizationPending() *fosite.RFC6749Error {
+	return &fosite.RFC6749Error{
+		ErrorField:       "authorization_pending",
+		DescriptionField: "The user has not finished logging in yet.",
+		CodeField:        http.StatusBadRequest,
+	}
+}
+
+func errSlowDown() *fosite.RFC6749Error {
+	return &fosite.RFC6749Error{
+		ErrorField:       "slow_down",
+		DescriptionField: "The client is polling too quickly, so the polling interval was increased.",
+		CodeField:        http.StatusBadRequest,
+	}
+}
+
+func errExpiredToken() *fosite.RFC6749Error {
+	return &fosite.RFC6749Error{
+		ErrorField:       "expired_token",
+		DescriptionField: "The device code has expired.",
+		CodeField:        http.StatusBadRequest,
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+)
+
+func TestRewriteTokenRequest(t *testing.T) {
+	const (
+		happyUserCode   = "BCDFGHJK"
+		happyDeviceCode = happyUserCode + ".test-device-code-secret"
+	)
+
+	fakeNow := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	storedSession := func(modify func(s *devicecode.Session)) *devicecode.Session {
+		s := &devicecode.Session{
+			ClientID:            "pinniped-cli",
+			Scopes:              []string{"openid", "offline_access"},
+			DeviceCodeSignature: devicecode.Signature(happyDeviceCode),
+			Status:              devicecode.StatusPending,
+			Expiry:              fakeNow.Add(5 * time.Minute),
+			Interval:            5 * time.Second,
+			State:               happyUserCode + ".test-state-secret",
+			PKCECodeVerifier:    "test-pkce",
+			Version:             "1",
+		}
+		if modify != nil {
+			modify(s)
+		}
+		return s
+	}
+
+	happyForm := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {happyDeviceCode},
+		"client_id":   {"pinniped-cli"},
+	}
+
+	tests := []struct {
+		name          string
+		form          url.Values
+		basicAuthID   string
+		storedSession *devicecode.Session
+
+		wantErrorField  string
+		wantForm        url.Values
+		wantSession     *devicecode.Session
+		wantSessionGone bool
+	}{
+		{
+			name: "approved request is rewritten into an authorization code grant",
+			form: happyForm,
+			storedSession: storedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+			}),
+			wantForm: url.Values{
+				"grant_type":    {"authorization_code"},
+				"code":          {"some-authcode"},
+				"code_verifier": {"test-pkce"},
+				"redirect_uri":  {testDeviceCallbackURL},
+				"client_id":     {"pinniped-cli"},
+			},
+			wantSessionGone: true,
+		},
+		{
+			name:        "approved request from a confidential client using basic auth",
+			form:        url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {happyDeviceCode}},
+			basicAuthID: testDynamicClientID,
+			storedSession: storedSession(func(s *devicecode.Session) {
+				s.ClientID = testDynamicClientID
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+			}),
+			wantForm: url.Values{
+				"grant_type":    {"authorization_code"},
+				"code":          {"some-authcode"},
+				"code_verifier": {"test-pkce"},
+				"redirect_uri":  {testDeviceCallbackURL},
+			},
+			wantSessionGone: true,
+		},
+		{
+			name:           "pending request which is polled for the first time",
+			form:           happyForm,
+			storedSession:  storedSession(nil),
+			wantErrorField: "authorization_pending",
+			wantSession: storedSession(func(s *devicecode.Session) {
+				s.LastPolledAt = fakeNow
+			}),
+		},
+		{
+			name: "pending request which is polled after the interval",
+			form: happyForm,
+			storedSession: storedSession(func(s *devicecode.Session) {
+				s.LastPolledAt = fakeNow.Add(-5 * time.Second)
+			}),
+			wantErrorField: "authorization_pending",
+			wantSession: storedSession(func(s *devicecode.Session) {
+				s.LastPolledAt = fakeNow
+			}),
+		},
+		{
+			name: "pending request which is polled too quickly",
+			form: happyForm,
+			storedSession: storedSession(func(s *devicecode.Session) {
+				s.LastPolledAt = fakeNow.Add(-4 * time.Second)
+			}),
+			wantErrorField: "slow_down",
+			wantSession: storedSession(func(s *devicecode.Session) {
+				s.LastPolledAt = fakeNow
+				s.Interval = 10 * time.Second
+			}),
+		},
+		{
+			name: "denied request",
+			form: happyForm,
+			storedSession: storedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusDenied
+			}),
+			wantErrorField:  "access_denied",
+			wantSessionGone: true,
+		},
+		{
+			name: "expired request",
+			form: happyForm,
+			storedSession: storedSession(func(s *devicecode.Session) {
+				s.Status = devicecode.StatusApproved
+				s.Authcode = "some-authcode"
+				s.Expiry = fakeNow
+			}),
+			wantErrorField:  "expired_token",
+			wantSessionGone: true,
+		},
+		{
+			name:           "device code which was issued to another client",
+			form:           url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {happyDeviceCode}, "client_id": {testDynamicClientID}},
+			storedSession:  storedSession(nil),
+			wantErrorField: "invalid_grant",
+			wantSession:    storedSession(nil),
+		},
+		{
+			name:           "device code with the wrong secret",
+			form:           url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {happyUserCode + ".some-other-secret"}, "client_id": {"pinniped-cli"}},
+			storedSession:  storedSession(nil),
+			wantErrorField: "invalid_grant",
+			wantSession:    storedSession(nil),
+		},
+		{
+			name:           "unknown device code",
+			form:           url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {"BCDFGHJL.test-device-code-secret"}, "client_id": {"pinniped-cli"}},
+			storedSession:  storedSession(nil),
+			wantErrorField: "invalid_grant",
+			wantSession:    storedSession(nil),
+		},
+		{
+			name:           "malformed device code",
+			form:           url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {"some-device-code"}, "client_id": {"pinniped-cli"}},
+			storedSession:  storedSession(nil),
+			wantErrorField: "invalid_grant",
+			wantSession:    storedSession(nil),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			storage := devicecode.New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() time.Time { return fakeNow }, 11*time.Minute)
+			require.NoError(t, storage.CreateDeviceCodeSession(ctx, happyUserCode, test.storedSession))
+
+			req := httptest.NewRequest(http.MethodPost, testIssuer+"/oauth2/token", strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.basicAuthID != "" {
+				req.SetBasicAuth(test.basicAuthID, "some-client-secret")
+			}
+
+			err := RewriteTokenRequest(ctx, req, storage, testDeviceCallbackURL, fakeNow)
+
+			if test.wantErrorField != "" {
+				var rfc6749Error *fosite.RFC6749Error
+				require.True(t, errors.As(err, &rfc6749Error), "wanted a fosite error but got: %v", err)
+				require.Equal(t, test.wantErrorField, rfc6749Error.ErrorField)
+				require.Equal(t, test.form, req.PostForm)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.wantForm, req.PostForm)
+				require.Equal(t, test.wantForm, req.Form)
+			}
+
+			session, _, err := storage.GetDeviceCodeSession(ctx, happyUserCode)
+			if test.wantSessionGone {
+				require.ErrorIs(t, err, fosite.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantSession, session)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ory/fosite"
+	"golang.org/x/oauth2"
+
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/csrftoken"
+	"go.pinniped.dev/internal/oidc/device/devicehtml"
+	"go.pinniped.dev/internal/oidc/i18n"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+const (
+	userCodeParamName = "user_code"
+	csrfParamName     = "csrf"
+)
+
+// NewVerificationHandler returns a http.Handler that serves the verification page, on which users enter the user
+// codes of their devices.
+//
+// A GET request renders the form, prefilled with the user_code query param when the user followed the
+// verification_uri_complete link. The form is protected by the CSRF cookie, so that other websites cannot start
+// logins on behalf of the user. A POST request finds the device authorization request of the user code, and
+// redirects the browser to the authorization endpoint of the FederationDomain, with the client and scopes of that
+// request, and with the device callback endpoint as the redirect_uri. From there, the user logs in as usual.
+// The page is translated to the language of the request using the translations.
+func NewVerificationHandler(
+	downstreamIssuer string,
+	postPath string,
+	storage devicecode.Storage,
+	generateCSRF func() (csrftoken.CSRFToken, error),
+	generatePKCE func() (pkce.Code, error),
+	generateNonce func() (nonce.Nonce, error),
+	generateSecret func() (string, error),
+	cookieCodec oidc.Codec,
+	nowFunc func() time.Time,
+	translations *i18n.Catalogs,
+) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try GET or POST)", r.Method)
+		}
+
+		messages := translations.ForRequest(r)
+
+		if r.Method == http.MethodGet {
+			csrfValue := readCSRFCookie(r, cookieCodec)
+			if csrfValue == "" {
+				var err error
+				if csrfValue, err = generateCSRF(); err != nil {
+					return httperr.Wrap(http.StatusInternalServerError, "error generating CSRF token", err)
+				}
+				if err = addCSRFSetCookieHeader(w, csrfValue, cookieCodec); err != nil {
+					return err
+				}
+			}
+			return renderForm(w, messages, postPath, csrfValue, r.FormValue(userCodeParamName), "")
+		}
+
+		csrfValue := readCSRFCookie(r, cookieCodec)
+		if csrfValue == "" || subtle.ConstantTimeCompare([]byte(csrfValue), []byte(r.PostFormValue(csrfParamName))) != 1 {
+			return httperr.New(http.StatusForbidden, "CSRF value does not match")
+		}
+
+		userCode := normalizeUserCode(r.PostFormValue(userCodeParamName))
+		session, rv, err := storage.GetDeviceCodeSession(r.Context(), userCode)
+		if err != nil && !errors.Is(err, fosite.ErrNotFound) {
+			plog.Error("error reading device code session", err)
+			return httperr.New(http.StatusInternalServerError, "error reading device code session")
+		}
+		if err != nil || session.Status != devicecode.StatusPending || session.State != "" || !nowFunc().Before(session.Expiry) {
+			// The code was mistyped, or it was already used, or it expired. In all of these cases, the user may
+			// simply try again, so do not reveal which one it was.
+			return renderForm(w, messages, postPath, csrfValue, r.PostFormValue(userCodeParamName),
+				messages.Text(i18n.DeviceVerificationErrorInvalidCode))
+		}
+
+		stateSecret, err := generateSecret()
+		if err != nil {
+			return httperr.Wrap(http.StatusInternalServerError, "error generating state param", err)
+		}
+		pkceValue, err := generatePKCE()
+		if err != nil {
+			return httperr.Wrap(http.StatusInternalServerError, "error generating PKCE param", err)
+		}
+		nonceValue, err := generateNonce()
+		if err != nil {
+			return httperr.Wrap(http.StatusInternalServerError, "error generating nonce param", err)
+		}
+
+		session.State = joinCode(userCode, stateSecret)
+		session.PKCECodeVerifier = string(pkceValue)
+		if err = storage.UpdateDeviceCodeSession(r.Context(), userCode, rv, session); err != nil {
+			plog.Error("error saving device code session", err)
+			return httperr.New(http.StatusInternalServerError, "error saving device code session")
+		}
+
+		authorizationConfig := &oauth2.Config{
+			ClientID: session.ClientID,
+			Endpoint: oauth2.Endpoint{
+				AuthURL: downstreamIssuer + oidc.AuthorizationEndpointPath,
+			},
+			RedirectURL: downstreamIssuer + oidc.PinnipedDeviceCallbackPath,
+			Scopes:      session.Scopes,
+		}
+
+		http.Redirect(w, r,
+			authorizationConfig.AuthCodeURL(
+				session.State,
+				nonceValue.Param(),
+				pkceValue.Challenge(),
+				pkceValue.Method(),
+			),
+			http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
+		)
+		return nil
+	})
+
+	return securityheader.WrapWithCustomCSP(handler, devicehtml.ContentSecurityPolicy())
+}
+
+func renderForm(
+	w http.ResponseWriter,
+	messages *i18n.Messages,
+	postPath string,
+	csrfValue csrftoken.CSRFToken,
+	userCode string,
+	alertMessage string,
+) error {
+	return devicehtml.Template().Execute(w, &devicehtml.PageData{
+		Heading:      messages.Text(i18n.DeviceVerificationHeading),
+		Message:      messages.Text(i18n.DeviceVerificationMessage),
+		AlertMessage: alertMessage,
+		ShowForm:     true,
+		PostPath:     postPath,
+		CSRFToken:    string(csrfValue),
+		UserCode:     userCode,
+		Messages:     messages,
+	})
+}
+
+// readCSRFCookie returns the value of the CSRF cookie, or an empty value when the cookie is missing or could not be
+// decoded, in which case a new cookie should be made.
+func readCSRFCookie(r *http.Request, codec oidc.Decoder) csrftoken.CSRFToken {
+	receivedCSRFCookie, err := r.Cookie(oidc.CSRFCookieName)
+	if err != nil {
+		// Error means that the cookie was not found
+		return ""
+	}
+
+	var csrfFromCookie csrftoken.CSRFToken
+	if err = codec.Decode(oidc.CSRFCookieEncodingName, receivedCSRFCookie.Value, &csrfFromCookie); err != nil {
+		return ""
+	}
+
+	return csrfFromCookie
+}
+
+// addCSRFSetCookieHeader sets the same CSRF cookie as the authorization endpoint, so that the authorization
+// endpoint will keep using it when the browser is redirected there.
+func addCSRFSetCookieHeader(w http.ResponseWriter, csrfValue csrftoken.CSRFToken, codec oidc.Encoder) error {
+	encodedCSRFValue, err := codec.Encode(oidc.CSRFCookieEncodingName, csrfValue)
+	if err != nil {
+		return httperr.Wrap(http.StatusInternalServerError, "error encoding CSRF cookie", err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidc.CSRFCookieName,
+		Value:    encodedCSRFValue,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   true,
+		Path:     "/",
+	})
+
+	return nil
+}
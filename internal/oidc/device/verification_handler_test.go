@@ -0,0 +1,307 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package device
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/fositestorage/devicecode"
+	"go.pinniped.dev/internal/oidc/csrftoken"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+func TestVerificationHandler(t *testing.T) {
+	const (
+		htmlContentType = "text/html; charset=utf-8"
+		happyPostPath   = "/some-path/device"
+		happyUserCode   = "BCDFGHJK"
+		happyCSRF       = "test-csrf"
+		otherCSRF       = "test-other-csrf"
+		happyPKCE       = "test-pkce"
+		happyNonce      = "test-nonce"
+		happyState      = happyUserCode + ".test-state-secret"
+
+		invalidUserCodeAlert = "The code is not valid, or it has expired. Please try again."
+	)
+
+	fakeNow := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	happyCookieCodec := securecookie.New([]byte("fake-hash-secret2"), []byte("0123456789ABCDE2"))
+	happyCookieCodec.SetSerializer(securecookie.JSONEncoder{})
+	encodedIncomingCookieCSRFValue, err := happyCookieCodec.Encode("csrf", happyCSRF)
+	require.NoError(t, err)
+	happyCSRFCookie := "__Host-pinniped-csrf=" + encodedIncomingCookieCSRFValue
+
+	pendingSession := func() *devicecode.Session {
+		return &devicecode.Session{
+			ClientID:            "pinniped-cli",
+			Scopes:              []string{"openid", "offline_access"},
+			DeviceCodeSignature: "fake-device-code-signature",
+			Status:              devicecode.StatusPending,
+			Expiry:              fakeNow.Add(5 * time.Minute),
+			Interval:            5 * time.Second,
+		}
+	}
+
+	expectedRedirectToAuthorizationEndpoint := testIssuer + "/oauth2/authorize?" + url.Values{
+		"client_id":             {"pinniped-cli"},
+		"code_challenge":        {testutil.SHA256(happyPKCE)},
+		"code_challenge_method": {"S256"},
+		"nonce":                 {happyNonce},
+		"redirect_uri":          {testDeviceCallbackURL},
+		"response_type":         {"code"},
+		"scope":                 {"openid offline_access"},
+		"state":                 {happyState},
+	}.Encode()
+
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		form           url.Values
+		csrfCookie     string
+		acceptLanguage string
+		storedSession  *devicecode.Session
+
+		wantStatus                  int
+		wantContentType             string
+		wantBody                    string
+		wantBodyContains            []string
+		wantLocationHeader          string
+		wantCSRFValueInCookieHeader string
+		wantSession                 *devicecode.Session
+	}{
+		{
+			name:                        "GET request without a CSRF cookie sets a new CSRF cookie",
+			method:                      http.MethodGet,
+			wantStatus:                  http.StatusOK,
+			wantContentType:             htmlContentType,
+			wantBodyContains:            []string{`name="csrf" id="csrf" value="test-csrf"`, `id="user_code" value=""`},
+			wantCSRFValueInCookieHeader: happyCSRF,
+		},
+		{
+			name:                        "GET request with an undecodable CSRF cookie sets a new CSRF cookie",
+			method:                      http.MethodGet,
+			csrfCookie:                  "__Host-pinniped-csrf=this-value-was-not-signed-by-pinniped",
+			wantStatus:                  http.StatusOK,
+			wantContentType:             htmlContentType,
+			wantBodyContains:            []string{`name="csrf" id="csrf" value="test-csrf"`},
+			wantCSRFValueInCookieHeader: happyCSRF,
+		},
+		{
+			name:             "GET request with a CSRF cookie reuses it and prefills the user code",
+			method:           http.MethodGet,
+			query:            "?user_code=BCDF-GHJK",
+			csrfCookie:       "__Host-pinniped-csrf=" + mustEncode(t, happyCookieCodec, otherCSRF),
+			wantStatus:       http.StatusOK,
+			wantContentType:  htmlContentType,
+			wantBodyContains: []string{`name="csrf" id="csrf" value="test-other-csrf"`, `id="user_code" value="BCDF-GHJK"`},
+		},
+		{
+			name:               "POST request starts the downstream authorization request",
+			method:             http.MethodPost,
+			form:               url.Values{"csrf": {happyCSRF}, "user_code": {"bcdf-ghjk"}},
+			csrfCookie:         happyCSRFCookie,
+			storedSession:      pendingSession(),
+			wantStatus:         http.StatusSeeOther,
+			wantContentType:    "",
+			wantLocationHeader: expectedRedirectToAuthorizationEndpoint,
+			wantSession: func() *devicecode.Session {
+				s := pendingSession()
+				s.State = happyState
+				s.PKCECodeVerifier = happyPKCE
+				s.Version = "1"
+				return s
+			}(),
+		},
+		{
+			name:             "POST request for an unknown user code shows the form again",
+			method:           http.MethodPost,
+			form:             url.Values{"csrf": {happyCSRF}, "user_code": {"BCDF-GHJL"}},
+			csrfCookie:       happyCSRFCookie,
+			storedSession:    pendingSession(),
+			wantStatus:       http.StatusOK,
+			wantContentType:  htmlContentType,
+			wantBodyContains: []string{invalidUserCodeAlert, `id="user_code" value="BCDF-GHJL"`},
+			wantSession:      func() *devicecode.Session { s := pendingSession(); s.Version = "1"; return s }(),
+		},
+		{
+			name:            "POST request for an unknown user code shows the form again in the language of the browser",
+			method:          http.MethodPost,
+			form:            url.Values{"csrf": {happyCSRF}, "user_code": {"BCDF-GHJL"}},
+			csrfCookie:      happyCSRFCookie,
+			acceptLanguage:  "es",
+			storedSession:   pendingSession(),
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBodyContains: []string{
+				`<html lang="es">`,
+				"<h1>Inicie sesión en su dispositivo</h1>",
+				"El código no es válido o ha caducado. Vuelva a intentarlo.",
+				`value="Continuar"`,
+			},
+			wantSession: func() *devicecode.Session { s := pendingSession(); s.Version = "1"; return s }(),
+		},
+		{
+			name:       "POST request for an expired user code shows the form again",
+			method:     http.MethodPost,
+			form:       url.Values{"csrf": {happyCSRF}, "user_code": {"BCDF-GHJK"}},
+			csrfCookie: happyCSRFCookie,
+			storedSession: func() *devicecode.Session {
+				s := pendingSession()
+				s.Expiry = fakeNow
+				return s
+			}(),
+			wantStatus:       http.StatusOK,
+			wantContentType:  htmlContentType,
+			wantBodyContains: []string{invalidUserCodeAlert},
+			wantSession: func() *devicecode.Session {
+				s := pendingSession()
+				s.Expiry = fakeNow
+				s.Version = "1"
+				return s
+			}(),
+		},
+		{
+			name:       "POST request for a user code which was already entered shows the form again",
+			method:     http.MethodPost,
+			form:       url.Values{"csrf": {happyCSRF}, "user_code": {"BCDF-GHJK"}},
+			csrfCookie: happyCSRFCookie,
+			storedSession: func() *devicecode.Session {
+				s := pendingSession()
+				s.State = "BCDFGHJK.some-earlier-state-secret"
+				return s
+			}(),
+			wantStatus:       http.StatusOK,
+			wantContentType:  htmlContentType,
+			wantBodyContains: []string{invalidUserCodeAlert},
+			wantSession: func() *devicecode.Session {
+				s := pendingSession()
+				s.State = "BCDFGHJK.some-earlier-state-secret"
+				s.Version = "1"
+				return s
+			}(),
+		},
+		{
+			name:            "POST request with the wrong CSRF value",
+			method:          http.MethodPost,
+			form:            url.Values{"csrf": {otherCSRF}, "user_code": {"BCDF-GHJK"}},
+			csrfCookie:      happyCSRFCookie,
+			storedSession:   pendingSession(),
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Forbidden: CSRF value does not match\n",
+			wantSession:     func() *devicecode.Session { s := pendingSession(); s.Version = "1"; return s }(),
+		},
+		{
+			name:            "POST request without a CSRF cookie",
+			method:          http.MethodPost,
+			form:            url.Values{"csrf": {happyCSRF}, "user_code": {"BCDF-GHJK"}},
+			storedSession:   pendingSession(),
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Forbidden: CSRF value does not match\n",
+			wantSession:     func() *devicecode.Session { s := pendingSession(); s.Version = "1"; return s }(),
+		},
+		{
+			name:            "PUT method is invalid",
+			method:          http.MethodPut,
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Method Not Allowed: PUT (try GET or POST)\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			storage := devicecode.New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() time.Time { return fakeNow }, 11*time.Minute)
+			if test.storedSession != nil {
+				require.NoError(t, storage.CreateDeviceCodeSession(ctx, happyUserCode, test.storedSession))
+			}
+
+			subject := NewVerificationHandler(
+				testIssuer,
+				happyPostPath,
+				storage,
+				func() (csrftoken.CSRFToken, error) { return happyCSRF, nil },
+				func() (pkce.Code, error) { return happyPKCE, nil },
+				func() (nonce.Nonce, error) { return happyNonce, nil },
+				func() (string, error) { return "test-state-secret", nil },
+				happyCookieCodec,
+				func() time.Time { return fakeNow },
+				nil,
+			)
+
+			var req *http.Request
+			if test.method == http.MethodPost {
+				req = httptest.NewRequest(test.method, happyPostPath, strings.NewReader(test.form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(test.method, happyPostPath+test.query, nil)
+			}
+			if test.csrfCookie != "" {
+				req.Header.Set("Cookie", test.csrfCookie)
+			}
+			if test.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", test.acceptLanguage)
+			}
+			rsp := httptest.NewRecorder()
+
+			subject.ServeHTTP(rsp, req)
+
+			testutil.RequireSecurityHeadersWithLoginPageCSPs(t, rsp)
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			testutil.RequireEqualContentType(t, rsp.Header().Get("Content-Type"), test.wantContentType)
+			require.Equal(t, test.wantLocationHeader, rsp.Header().Get("Location"))
+			if test.wantBody != "" {
+				require.Equal(t, test.wantBody, rsp.Body.String())
+			}
+			for _, want := range test.wantBodyContains {
+				require.Contains(t, rsp.Body.String(), want)
+			}
+
+			if test.wantCSRFValueInCookieHeader != "" {
+				require.Len(t, rsp.Header().Values("Set-Cookie"), 1)
+				actualCookie := rsp.Header().Get("Set-Cookie")
+				regex := regexp.MustCompile("__Host-pinniped-csrf=([^;]+); Path=/; HttpOnly; Secure; SameSite=Lax")
+				submatches := regex.FindStringSubmatch(actualCookie)
+				require.Len(t, submatches, 2)
+				var decodedCSRFCookieValue string
+				require.NoError(t, happyCookieCodec.Decode("csrf", submatches[1], &decodedCSRFCookieValue))
+				require.Equal(t, test.wantCSRFValueInCookieHeader, decodedCSRFCookieValue)
+			} else {
+				require.Empty(t, rsp.Header().Values("Set-Cookie"))
+			}
+
+			if test.wantSession != nil {
+				session, _, err := storage.GetDeviceCodeSession(ctx, happyUserCode)
+				require.NoError(t, err)
+				require.Equal(t, test.wantSession, session)
+			}
+		})
+	}
+}
+
+func mustEncode(t *testing.T, codec *securecookie.SecureCookie, value string) string {
+	t.Helper()
+	encoded, err := codec.Encode("csrf", value)
+	require.NoError(t, err)
+	return encoded
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package discovery provides a handler for the OIDC discovery endpoint.
@@ -39,6 +39,22 @@ type Metadata struct {
 	ScopesSupported                   []string `json:"scopes_supported"`
 	ClaimsSupported                   []string `json:"claims_supported"`
 
+	// https://openid.net/specs/openid-connect-rpinitiated-1_0.html#OPMetadata
+	EndSessionEndpoint string `json:"end_session_endpoint"`
+
+	// https://datatracker.ietf.org/doc/html/rfc8414#section-2
+	IntrospectionEndpoint                     string   `json:"introspection_endpoint"`
+	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported"`
+	RevocationEndpoint                        string   `json:"revocation_endpoint"`
+	RevocationEndpointAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported"`
+
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-4
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+
+	// https://openid.net/specs/openid-connect-backchannel-1_0.html#BCSupport
+	BackchannelLogoutSupported        bool `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported bool `json:"backchannel_logout_session_supported"`
+
 	// https://datatracker.ietf.org/doc/html/rfc8414#section-2 says, “If omitted, the authorization server does not support PKCE.”
 	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
 
@@ -51,26 +67,35 @@ type Metadata struct {
 	// ^^^ Custom ^^^
 }
 
-// NewHandler returns an http.Handler that serves an OIDC discovery endpoint.
-func NewHandler(issuerURL string) http.Handler {
+// NewHandler returns an http.Handler that serves an OIDC discovery endpoint. The idTokenSigningAlgorithm is the only
+// algorithm which is advertised for signing ID tokens, since the FederationDomain signs all of its ID tokens with it.
+func NewHandler(issuerURL string, idTokenSigningAlgorithm string) http.Handler {
 	oidcConfig := Metadata{
-		Issuer:                issuerURL,
-		AuthorizationEndpoint: issuerURL + oidc.AuthorizationEndpointPath,
-		TokenEndpoint:         issuerURL + oidc.TokenEndpointPath,
-		JWKSURI:               issuerURL + oidc.JWKSEndpointPath,
+		Issuer:                      issuerURL,
+		AuthorizationEndpoint:       issuerURL + oidc.AuthorizationEndpointPath,
+		TokenEndpoint:               issuerURL + oidc.TokenEndpointPath,
+		JWKSURI:                     issuerURL + oidc.JWKSEndpointPath,
+		EndSessionEndpoint:          issuerURL + oidc.EndSessionEndpointPath,
+		IntrospectionEndpoint:       issuerURL + oidc.IntrospectionEndpointPath,
+		RevocationEndpoint:          issuerURL + oidc.RevocationEndpointPath,
+		DeviceAuthorizationEndpoint: issuerURL + oidc.DeviceAuthorizationEndpointPath,
 		OIDCDiscoveryResponse: v1alpha1.OIDCDiscoveryResponse{
 			SupervisorDiscovery: v1alpha1.OIDCDiscoveryResponseIDPEndpoint{
 				PinnipedIDPsEndpoint: issuerURL + oidc.PinnipedIDPsPathV1Alpha1,
 			},
 		},
-		ResponseTypesSupported:            []string{"code"},
-		ResponseModesSupported:            []string{"query", "form_post"},
-		SubjectTypesSupported:             []string{"public"},
-		IDTokenSigningAlgValuesSupported:  []string{"ES256"},
-		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic"},
-		CodeChallengeMethodsSupported:     []string{"S256"},
-		ScopesSupported:                   []string{oidcapi.ScopeOpenID, oidcapi.ScopeOfflineAccess, oidcapi.ScopeRequestAudience, oidcapi.ScopeUsername, oidcapi.ScopeGroups},
-		ClaimsSupported:                   []string{oidcapi.IDTokenClaimUsername, oidcapi.IDTokenClaimGroups, oidcapi.IDTokenClaimAdditionalClaims},
+		ResponseTypesSupported:                    []string{"code"},
+		ResponseModesSupported:                    []string{"query", "form_post"},
+		SubjectTypesSupported:                     []string{"public"},
+		IDTokenSigningAlgValuesSupported:          []string{idTokenSigningAlgorithm},
+		TokenEndpointAuthMethodsSupported:         []string{"client_secret_basic"},
+		IntrospectionEndpointAuthMethodsSupported: []string{"client_secret_basic"},
+		RevocationEndpointAuthMethodsSupported:    []string{"client_secret_basic"},
+		CodeChallengeMethodsSupported:             []string{"S256"},
+		BackchannelLogoutSupported:                true,
+		BackchannelLogoutSessionSupported:         true,
+		ScopesSupported:                           []string{oidcapi.ScopeOpenID, oidcapi.ScopeOfflineAccess, oidcapi.ScopeRequestAudience, oidcapi.ScopeUsername, oidcapi.ScopeGroups},
+		ClaimsSupported:                           []string{oidcapi.IDTokenClaimUsername, oidcapi.IDTokenClaimGroups, oidcapi.IDTokenClaimAdditionalClaims},
 	}
 
 	var b bytes.Buffer
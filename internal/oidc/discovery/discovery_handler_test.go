@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package discovery
@@ -18,9 +18,10 @@ func TestDiscovery(t *testing.T) {
 	tests := []struct {
 		name string
 
-		issuer string
-		method string
-		path   string
+		issuer                  string
+		idTokenSigningAlgorithm string
+		method                  string
+		path                    string
 
 		wantStatus      int
 		wantContentType string
@@ -28,18 +29,27 @@ func TestDiscovery(t *testing.T) {
 		wantBodyString  string
 	}{
 		{
-			name:            "happy path",
-			issuer:          "https://some-issuer.com/some/path",
-			method:          http.MethodGet,
-			path:            "/some/path" + oidc.WellKnownEndpointPath,
-			wantStatus:      http.StatusOK,
-			wantContentType: "application/json",
+			name:                    "happy path",
+			issuer:                  "https://some-issuer.com/some/path",
+			idTokenSigningAlgorithm: "ES256",
+			method:                  http.MethodGet,
+			path:                    "/some/path" + oidc.WellKnownEndpointPath,
+			wantStatus:              http.StatusOK,
+			wantContentType:         "application/json",
 			wantBodyJSON: here.Doc(`
 			{
 				"issuer": "https://some-issuer.com/some/path",
 				"authorization_endpoint": "https://some-issuer.com/some/path/oauth2/authorize",
 				"token_endpoint": "https://some-issuer.com/some/path/oauth2/token",
 				"jwks_uri": "https://some-issuer.com/some/path/jwks.json",
+				"end_session_endpoint": "https://some-issuer.com/some/path/oauth2/logout",
+				"introspection_endpoint": "https://some-issuer.com/some/path/oauth2/introspect",
+				"introspection_endpoint_auth_methods_supported": ["client_secret_basic"],
+				"revocation_endpoint": "https://some-issuer.com/some/path/oauth2/revoke",
+				"revocation_endpoint_auth_methods_supported": ["client_secret_basic"],
+				"device_authorization_endpoint": "https://some-issuer.com/some/path/oauth2/device_authorization",
+				"backchannel_logout_supported": true,
+				"backchannel_logout_session_supported": true,
 				"response_types_supported": ["code"],
 				"response_modes_supported": ["query", "form_post"],
 				"subject_types_supported": ["public"],
@@ -55,19 +65,56 @@ func TestDiscovery(t *testing.T) {
 			`),
 		},
 		{
-			name:            "bad method",
-			issuer:          "https://some-issuer.com",
-			method:          http.MethodPost,
-			path:            oidc.WellKnownEndpointPath,
-			wantStatus:      http.StatusMethodNotAllowed,
-			wantContentType: "text/plain; charset=utf-8",
-			wantBodyString:  "Method not allowed (try GET)\n",
+			name:                    "happy path with another ID token signing algorithm",
+			issuer:                  "https://some-issuer.com/some/path",
+			idTokenSigningAlgorithm: "EdDSA",
+			method:                  http.MethodGet,
+			path:                    "/some/path" + oidc.WellKnownEndpointPath,
+			wantStatus:              http.StatusOK,
+			wantContentType:         "application/json",
+			wantBodyJSON: here.Doc(`
+			{
+				"issuer": "https://some-issuer.com/some/path",
+				"authorization_endpoint": "https://some-issuer.com/some/path/oauth2/authorize",
+				"token_endpoint": "https://some-issuer.com/some/path/oauth2/token",
+				"jwks_uri": "https://some-issuer.com/some/path/jwks.json",
+				"end_session_endpoint": "https://some-issuer.com/some/path/oauth2/logout",
+				"introspection_endpoint": "https://some-issuer.com/some/path/oauth2/introspect",
+				"introspection_endpoint_auth_methods_supported": ["client_secret_basic"],
+				"revocation_endpoint": "https://some-issuer.com/some/path/oauth2/revoke",
+				"revocation_endpoint_auth_methods_supported": ["client_secret_basic"],
+				"device_authorization_endpoint": "https://some-issuer.com/some/path/oauth2/device_authorization",
+				"backchannel_logout_supported": true,
+				"backchannel_logout_session_supported": true,
+				"response_types_supported": ["code"],
+				"response_modes_supported": ["query", "form_post"],
+				"subject_types_supported": ["public"],
+				"id_token_signing_alg_values_supported": ["EdDSA"],
+				"token_endpoint_auth_methods_supported": ["client_secret_basic"],
+				"scopes_supported": ["openid", "offline_access", "pinniped:request-audience", "username", "groups"],
+				"code_challenge_methods_supported": ["S256"],
+				"claims_supported": ["username", "groups", "additionalClaims"],
+				"discovery.supervisor.pinniped.dev/v1alpha1": {
+					"pinniped_identity_providers_endpoint": "https://some-issuer.com/some/path/v1alpha1/pinniped_identity_providers"
+				}
+			}
+			`),
+		},
+		{
+			name:                    "bad method",
+			issuer:                  "https://some-issuer.com",
+			idTokenSigningAlgorithm: "ES256",
+			method:                  http.MethodPost,
+			path:                    oidc.WellKnownEndpointPath,
+			wantStatus:              http.StatusMethodNotAllowed,
+			wantContentType:         "text/plain; charset=utf-8",
+			wantBodyString:          "Method not allowed (try GET)\n",
 		},
 	}
 	for _, test := range tests {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			handler := NewHandler(test.issuer)
+			handler := NewHandler(test.issuer, test.idTokenSigningAlgorithm)
 			req := httptest.NewRequest(test.method, test.path, nil)
 			rsp := httptest.NewRecorder()
 			handler.ServeHTTP(rsp, req)
@@ -1,13 +1,15 @@
-// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package downstreamsession provides some shared helpers for creating downstream OIDC sessions.
 package downstreamsession
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/ory/fosite"
@@ -19,6 +21,7 @@ import (
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
 	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
@@ -38,10 +41,13 @@ const (
 	requiredClaimEmptyErr              = constable.Error("required claim in upstream ID token is empty")
 	emailVerifiedClaimInvalidFormatErr = constable.Error("email_verified claim in upstream ID token has invalid format")
 	emailVerifiedClaimFalseErr         = constable.Error("email_verified claim in upstream ID token has false value")
+	usernameExpressionErr              = constable.Error("username expression could not be evaluated against upstream ID token")
 )
 
-// MakeDownstreamSession creates a downstream OIDC session.
+// MakeDownstreamSession creates a downstream OIDC session. The sessionID should be the ID of the authorize request,
+// which fosite keeps for the lifetime of the downstream session, including all of its refreshes.
 func MakeDownstreamSession(
+	sessionID string,
 	subject string,
 	username string,
 	groups []string,
@@ -67,6 +73,7 @@ func MakeDownstreamSession(
 
 	extras := map[string]interface{}{}
 	extras[oidcapi.IDTokenClaimAuthorizedParty] = clientID
+	extras[oidcapi.IDTokenClaimSessionID] = sessionID
 	if slices.Contains(grantedScopes, oidcapi.ScopeUsername) {
 		extras[oidcapi.IDTokenClaimUsername] = username
 	}
@@ -111,6 +118,21 @@ func MakeDownstreamLDAPOrADCustomSessionData(
 	return customSessionData
 }
 
+func MakeDownstreamKerberosCustomSessionData(
+	kerberosUpstream provider.UpstreamKerberosIdentityProviderI,
+	authenticateResponse *authenticators.Response,
+) *psession.CustomSessionData {
+	return &psession.CustomSessionData{
+		Username:     authenticateResponse.User.GetName(),
+		ProviderUID:  kerberosUpstream.GetResourceUID(),
+		ProviderName: kerberosUpstream.GetName(),
+		ProviderType: psession.ProviderTypeKerberos,
+		Kerberos: &psession.KerberosSessionData{
+			Principal: authenticateResponse.User.GetUID(),
+		},
+	}
+}
+
 func MakeDownstreamOIDCCustomSessionData(
 	oidcUpstream provider.UpstreamOIDCIdentityProviderI,
 	token *oidctypes.Token,
@@ -198,6 +220,38 @@ func AutoApproveScopes(authorizeRequester fosite.AuthorizeRequester) {
 	}
 }
 
+// ApplyIdentityTransforms applies the identity transformations and policies of the FederationDomain to the username
+// and groups from the upstream IDP, and returns the resulting downstream username and groups. When there are
+// transformations, the upstream identity is saved in the customSessionData so that the transformations can be applied
+// again upon refresh, and the username in the customSessionData is updated to the downstream username. The returned
+// error is safe to show to the user.
+func ApplyIdentityTransforms(
+	ctx context.Context,
+	identityTransforms *idtransform.TransformationPipeline,
+	customSessionData *psession.CustomSessionData,
+	username string,
+	groups []string,
+) (string, []string, error) {
+	if !identityTransforms.HasTransforms() {
+		return username, groups, nil
+	}
+
+	result, err := identityTransforms.Evaluate(ctx, username, groups)
+	if err != nil {
+		plog.Error("unexpected identity transformation error during authentication", err, "inputUsername", username)
+		return "", nil, errors.New("identity transformation error")
+	}
+	if !result.AuthenticationAllowed {
+		plog.Debug("authentication rejected by configured identity policy", "inputUsername", username)
+		return "", nil, fmt.Errorf("configured identity policy rejected this authentication: %s", result.RejectedAuthenticationMessage)
+	}
+
+	customSessionData.UpstreamUsername = username
+	customSessionData.UpstreamGroups = groups
+	customSessionData.Username = result.Username
+	return result.Username, result.Groups, nil
+}
+
 // GetDownstreamIdentityFromUpstreamIDToken returns the mapped subject, username, and group names, in that order.
 func GetDownstreamIdentityFromUpstreamIDToken(
 	upstreamIDPConfig provider.UpstreamOIDCIdentityProviderI,
@@ -217,10 +271,24 @@ func GetDownstreamIdentityFromUpstreamIDToken(
 }
 
 // MapAdditionalClaimsFromUpstreamIDToken returns the additionalClaims mapped from the upstream token, if any.
+// When additional claims expressions are configured, they are used instead of the additional claim mappings.
 func MapAdditionalClaimsFromUpstreamIDToken(
 	upstreamIDPConfig provider.UpstreamOIDCIdentityProviderI,
 	idTokenClaims map[string]interface{},
 ) map[string]interface{} {
+	if claimExpressions := upstreamIDPConfig.GetClaimExpressions(); claimExpressions.HasAdditionalClaims() {
+		mapped, errs := claimExpressions.AdditionalClaims(idTokenClaims)
+		for downstreamClaimName, err := range errs {
+			plog.Warning(
+				"additionalClaims expression could not be evaluated against upstream ID token",
+				"upstreamName", upstreamIDPConfig.GetName(),
+				"claimName", downstreamClaimName,
+				"err", err.Error(),
+			)
+		}
+		return mapped
+	}
+
 	mapped := make(map[string]interface{}, len(upstreamIDPConfig.GetAdditionalClaimMappings()))
 	for downstreamClaimName, upstreamClaimName := range upstreamIDPConfig.GetAdditionalClaimMappings() {
 		upstreamClaimValue, ok := idTokenClaims[upstreamClaimName]
@@ -253,6 +321,20 @@ func getSubjectAndUsernameFromUpstreamIDToken(
 	}
 	subject := downstreamSubjectFromUpstreamOIDC(upstreamIssuer, upstreamSubject)
 
+	if claimExpressions := upstreamIDPConfig.GetClaimExpressions(); claimExpressions.HasUsername() {
+		// The expression decides which claims to trust, so the email_verified claim is not checked here.
+		username, err := claimExpressions.Username(idTokenClaims)
+		if err != nil {
+			plog.Warning(
+				"username expression could not be evaluated against upstream ID token",
+				"upstreamName", upstreamIDPConfig.GetName(),
+				"err", err.Error(),
+			)
+			return "", "", usernameExpressionErr
+		}
+		return subject, username, nil
+	}
+
 	usernameClaimName := upstreamIDPConfig.GetUsernameClaim()
 	if usernameClaimName == "" {
 		return subject, subject, nil
@@ -335,6 +417,17 @@ func DownstreamLDAPSubject(uid string, ldapURL url.URL) string {
 	return ldapURL.String()
 }
 
+// DownstreamKerberosSubject returns a subject which is unique across all Kerberos realms for the given principal,
+// e.g. "krb5://EXAMPLE.COM?sub=alice" for "alice@EXAMPLE.COM".
+func DownstreamKerberosSubject(principal string) string {
+	name, realm, _ := strings.Cut(principal, "@")
+	kerberosURL := url.URL{Scheme: "krb5", Host: realm}
+	q := url.Values{}
+	q.Set(oidcapi.IDTokenClaimSubject, name)
+	kerberosURL.RawQuery = q.Encode()
+	return kerberosURL.String()
+}
+
 func downstreamSubjectFromUpstreamOIDC(upstreamIssuerAsString string, upstreamSubject string) string {
 	return fmt.Sprintf("%s?%s=%s", upstreamIssuerAsString, oidcapi.IDTokenClaimSubject, url.QueryEscape(upstreamSubject))
 }
@@ -342,10 +435,25 @@ func downstreamSubjectFromUpstreamOIDC(upstreamIssuerAsString string, upstreamSu
 // GetGroupsFromUpstreamIDToken returns mapped group names coerced into a slice of strings.
 // It returns nil when there is no configured groups claim name, or then when the configured claim name is not found
 // in the provided map of claims. It returns an error when the claim exists but its value cannot be parsed.
+// When a groups expression is configured, it is used instead of the groups claim name, and it returns nil when
+// the expression cannot be evaluated against the provided map of claims.
 func GetGroupsFromUpstreamIDToken(
 	upstreamIDPConfig provider.UpstreamOIDCIdentityProviderI,
 	idTokenClaims map[string]interface{},
 ) ([]string, error) {
+	if claimExpressions := upstreamIDPConfig.GetClaimExpressions(); claimExpressions.HasGroups() {
+		groups, err := claimExpressions.Groups(idTokenClaims)
+		if err != nil {
+			plog.Warning(
+				"groups expression could not be evaluated against upstream ID token",
+				"upstreamName", upstreamIDPConfig.GetName(),
+				"err", err.Error(),
+			)
+			return nil, nil // like a missing groups claim, since the claims used by the expression may have been omitted
+		}
+		return groups, nil
+	}
+
 	groupsClaimName := upstreamIDPConfig.GetGroupsClaim()
 	if groupsClaimName == "" {
 		return nil, nil
@@ -1,4 +1,4 @@
-// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2023-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package downstreamsession
@@ -8,15 +8,17 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"go.pinniped.dev/internal/claimexpressions"
 	"go.pinniped.dev/internal/testutil/oidctestutil"
 )
 
 func TestMapAdditionalClaimsFromUpstreamIDToken(t *testing.T) {
 	tests := []struct {
-		name                    string
-		additionalClaimMappings map[string]string
-		upstreamClaims          map[string]interface{}
-		wantClaims              map[string]interface{}
+		name                       string
+		additionalClaimMappings    map[string]string
+		additionalClaimExpressions map[string]string
+		upstreamClaims             map[string]interface{}
+		wantClaims                 map[string]interface{}
 	}{
 		{
 			name: "happy path",
@@ -54,6 +56,22 @@ func TestMapAdditionalClaimsFromUpstreamIDToken(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "expressions are used instead of mappings",
+			additionalClaimMappings: map[string]string{
+				"email": "email",
+			},
+			additionalClaimExpressions: map[string]string{
+				"domain":  `claims.email.split("@")[1]`,
+				"missing": `claims.missing`,
+			},
+			upstreamClaims: map[string]interface{}{
+				"email": "test@example.com",
+			},
+			wantClaims: map[string]interface{}{
+				"domain": "example.com",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -61,8 +79,11 @@ func TestMapAdditionalClaimsFromUpstreamIDToken(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
+			claimExpressions, err := claimexpressions.Compile("", "", test.additionalClaimExpressions)
+			require.NoError(t, err)
 			idp := oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().
 				WithAdditionalClaimMappings(test.additionalClaimMappings).
+				WithClaimExpressions(claimExpressions).
 				Build()
 			actual := MapAdditionalClaimsFromUpstreamIDToken(idp, test.upstreamClaims)
 
@@ -70,3 +91,83 @@ func TestMapAdditionalClaimsFromUpstreamIDToken(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDownstreamIdentityFromUpstreamIDTokenWithClaimExpressions(t *testing.T) {
+	upstreamClaims := map[string]interface{}{
+		"iss":            "https://issuer.example.com",
+		"sub":            "some-subject",
+		"email":          "jane@example.com",
+		"email_verified": false,
+		"groups":         []interface{}{"k8s-admins", "other"},
+	}
+
+	tests := []struct {
+		name               string
+		usernameExpression string
+		groupsExpression   string
+		usernameClaim      string
+		groupsClaim        string
+		wantUsername       string
+		wantGroups         []string
+		wantErr            string
+	}{
+		{
+			name:               "expressions compute the username and groups, without checking email_verified",
+			usernameExpression: `claims.email.split("@")[0]`,
+			groupsExpression:   `claims.groups.filter(g, g.startsWith("k8s-"))`,
+			usernameClaim:      "email",
+			groupsClaim:        "groups",
+			wantUsername:       "jane",
+			wantGroups:         []string{"k8s-admins"},
+		},
+		{
+			name:               "username expression cannot be evaluated",
+			usernameExpression: `claims.preferred_username`,
+			wantErr:            "username expression could not be evaluated against upstream ID token",
+		},
+		{
+			name:               "groups expression cannot be evaluated, like a missing groups claim",
+			usernameExpression: `claims.email`,
+			groupsExpression:   `claims.roles`,
+			wantUsername:       "jane@example.com",
+			wantGroups:         nil,
+		},
+		{
+			name:             "claim names are used when there is no corresponding expression",
+			groupsExpression: `claims.groups`,
+			usernameClaim:    "sub",
+			wantUsername:     "some-subject",
+			wantGroups:       []string{"k8s-admins", "other"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			claimExpressions, err := claimexpressions.Compile(test.usernameExpression, test.groupsExpression, nil)
+			require.NoError(t, err)
+			idp := oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().
+				WithUsernameClaim(test.usernameClaim).
+				WithGroupsClaim(test.groupsClaim).
+				WithClaimExpressions(claimExpressions).
+				Build()
+
+			subject, username, groups, err := GetDownstreamIdentityFromUpstreamIDToken(idp, upstreamClaims)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "https://issuer.example.com?sub=some-subject", subject)
+			require.Equal(t, test.wantUsername, username)
+			require.Equal(t, test.wantGroups, groups)
+		})
+	}
+}
+
+func TestDownstreamKerberosSubject(t *testing.T) {
+	require.Equal(t, "krb5://EXAMPLE.COM?sub=alice", DownstreamKerberosSubject("alice@EXAMPLE.COM"))
+	require.Equal(t, "krb5://EXAMPLE.COM?sub=host%2Fweb.example.com", DownstreamKerberosSubject("host/web.example.com@EXAMPLE.COM"))
+}
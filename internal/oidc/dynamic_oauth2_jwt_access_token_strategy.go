@@ -0,0 +1,191 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	errorsx "github.com/pkg/errors"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+// JWTAccessTokenType is the typ header of JWT access tokens, as defined by
+// https://datatracker.ietf.org/doc/html/rfc9068#section-2.1.
+const JWTAccessTokenType = "at+jwt"
+
+// dynamicOauth2JWTAccessTokenStrategy is an oauth2.CoreStrategy which issues signed JWT access tokens to the clients
+// which asked for them, and which delegates everything else to another oauth2.CoreStrategy. The JWTs are signed by
+// the active signing key of the FederationDomain, so resource servers can validate them locally using the JWKS of the
+// FederationDomain. Like the opaque access tokens, the JWT access tokens are also stored, so they can still be
+// introspected and revoked, and so they can be told apart from opaque access tokens by their lack of a prefix.
+type dynamicOauth2JWTAccessTokenStrategy struct {
+	oauth2.CoreStrategy
+
+	fositeConfig *fosite.Config
+	jwksProvider jwks.DynamicJWKSProvider
+}
+
+var _ oauth2.CoreStrategy = &dynamicOauth2JWTAccessTokenStrategy{}
+
+func newDynamicOauth2JWTAccessTokenStrategy(
+	delegate oauth2.CoreStrategy,
+	fositeConfig *fosite.Config,
+	jwksProvider jwks.DynamicJWKSProvider,
+) *dynamicOauth2JWTAccessTokenStrategy {
+	return &dynamicOauth2JWTAccessTokenStrategy{
+		CoreStrategy: delegate,
+		fositeConfig: fositeConfig,
+		jwksProvider: jwksProvider,
+	}
+}
+
+func (s *dynamicOauth2JWTAccessTokenStrategy) AccessTokenSignature(ctx context.Context, token string) string {
+	if isOpaqueAccessToken(token) {
+		return s.CoreStrategy.AccessTokenSignature(ctx, token)
+	}
+	// Like fosite's JWT strategy, use the signature of the JWT as the signature of the token.
+	split := strings.Split(token, ".")
+	if len(split) != 3 {
+		return ""
+	}
+	return split[2]
+}
+
+func (s *dynamicOauth2JWTAccessTokenStrategy) GenerateAccessToken(
+	ctx context.Context,
+	requester fosite.Requester,
+) (string, string, error) {
+	client, ok := requester.GetClient().(*clientregistry.Client)
+	if !ok || !client.UsesJWTAccessTokens() {
+		return s.CoreStrategy.GenerateAccessToken(ctx, requester)
+	}
+
+	token, err := s.jwtAccessToken(client, requester)
+	if err != nil {
+		return "", "", err
+	}
+	return token, s.AccessTokenSignature(ctx, token), nil
+}
+
+func (s *dynamicOauth2JWTAccessTokenStrategy) ValidateAccessToken(
+	ctx context.Context,
+	requester fosite.Requester,
+	token string,
+) error {
+	if isOpaqueAccessToken(token) {
+		return s.CoreStrategy.ValidateAccessToken(ctx, requester, token)
+	}
+
+	if err := s.verifyJWTSignature(token); err != nil {
+		return errorsx.WithStack(fosite.ErrTokenSignatureMismatch.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if expiresAt := requester.GetSession().GetExpiresAt(fosite.AccessToken); !expiresAt.IsZero() && expiresAt.Before(time.Now().UTC()) {
+		return errorsx.WithStack(fosite.ErrTokenExpired.WithHintf("Access token expired at '%s'.", expiresAt))
+	}
+	return nil
+}
+
+// jwtAccessToken returns a JWT access token as described by https://datatracker.ietf.org/doc/html/rfc9068#section-2.2.
+// The username and groups are only included when they were granted via the username and groups scopes, in the same
+// way as they would be included in ID tokens. The access tokens of client credentials grants instead have the
+// identity_type claim, and their audience is the granted audience, when the client requested one.
+func (s *dynamicOauth2JWTAccessTokenStrategy) jwtAccessToken(client *clientregistry.Client, requester fosite.Requester) (string, error) {
+	issuer := s.fositeConfig.IDTokenIssuer
+	_, activeJWK := s.jwksProvider.GetJWKS(issuer)
+	if activeJWK == nil {
+		plog.Debug("no JWK found for issuer", "issuer", issuer)
+		return "", fosite.ErrTemporarilyUnavailable.WithWrap(constable.Error("no JWK found for issuer"))
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.SignatureAlgorithm(activeJWK.Algorithm), Key: activeJWK},
+		(&jose.SignerOptions{}).WithType(JWTAccessTokenType),
+	)
+	if err != nil {
+		return "", fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	session := requester.GetSession()
+	issuedAt := time.Now().UTC()
+	expiresAt := session.GetExpiresAt(fosite.AccessToken)
+	if expiresAt.IsZero() {
+		expiresAt = issuedAt.Add(client.GetEffectiveLifespan(fosite.GrantType(requester.GetRequestForm().Get("grant_type")), fosite.AccessToken, s.fositeConfig.AccessTokenLifespan))
+	}
+
+	extraClaims := map[string]interface{}{
+		"client_id": client.GetID(),
+		"scope":     strings.Join(requester.GetGrantedScopes(), " "),
+	}
+	if pinnipedSession, ok := session.(*psession.PinnipedSession); ok && pinnipedSession.Fosite != nil && pinnipedSession.Fosite.Claims != nil {
+		extra := pinnipedSession.Fosite.Claims.Extra
+		if username, ok := extra[oidcapi.IDTokenClaimUsername].(string); ok {
+			extraClaims[oidcapi.IDTokenClaimUsername] = username
+		}
+		if groups, ok := groupsFromExtraClaims(extra); ok {
+			extraClaims[oidcapi.IDTokenClaimGroups] = client.FilterGroups(groups)
+		}
+		if identityType, ok := extra[oidcapi.ClaimIdentityType].(string); ok {
+			extraClaims[oidcapi.ClaimIdentityType] = identityType
+		}
+	}
+
+	audience := jwt.Audience{client.GetID()}
+	if grantedAudience := requester.GetGrantedAudience(); len(grantedAudience) > 0 {
+		audience = jwt.Audience(grantedAudience)
+	}
+
+	token, err := jwt.Signed(signer).
+		Claims(jwt.Claims{
+			Issuer:   issuer,
+			Subject:  session.GetSubject(),
+			Audience: audience,
+			ID:       uuid.NewString(),
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+			Expiry:   jwt.NewNumericDate(expiresAt),
+		}).
+		Claims(extraClaims).
+		CompactSerialize()
+	if err != nil {
+		return "", fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+	return token, nil
+}
+
+// verifyJWTSignature checks that the JWT was signed by any of the keys of the FederationDomain, including keys which
+// are no longer active but which are still published, since the JWT may have been issued before a key rotation.
+func (s *dynamicOauth2JWTAccessTokenStrategy) verifyJWTSignature(token string) error {
+	signed, err := jose.ParseSigned(token)
+	if err != nil {
+		return err
+	}
+	keySet, _ := s.jwksProvider.GetJWKS(s.fositeConfig.IDTokenIssuer)
+	if keySet == nil {
+		return constable.Error("no JWKS found for issuer")
+	}
+	for _, key := range keySet.Keys {
+		if _, err := signed.Verify(key.Public()); err == nil {
+			return nil
+		}
+	}
+	return constable.Error("JWT was not signed by any key of the issuer")
+}
+
+// isOpaqueAccessToken returns true for the access tokens which are issued by the dynamicOauth2HMACStrategy.
+func isOpaqueAccessToken(token string) bool {
+	return strings.HasPrefix(token, pinAccessTokenPrefix)
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/psession"
+)
+
+func TestDynamicOauth2JWTAccessTokenStrategy(t *testing.T) {
+	const (
+		goodIssuer   = "https://some-good-issuer.com"
+		clientID     = "client.oauth.pinniped.dev-some-client"
+		goodSubject  = "some-subject"
+		goodUsername = "some-username"
+	)
+
+	activeKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	previousKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	unknownKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	activeJWK := &jose.JSONWebKey{Key: activeKey, KeyID: "active-kid", Algorithm: "ES256", Use: "sig"}
+	previousJWK := &jose.JSONWebKey{Key: previousKey, KeyID: "previous-kid", Algorithm: "ES256", Use: "sig"}
+	unknownJWK := &jose.JSONWebKey{Key: unknownKey, KeyID: "unknown-kid", Algorithm: "ES256", Use: "sig"}
+
+	publicJWKS := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{activeJWK.Public(), previousJWK.Public()}}
+
+	newRequester := func(client fosite.Client, expiresAt time.Time) *fosite.Request {
+		session := &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{
+				Claims: &jwt.IDTokenClaims{
+					Subject: goodSubject,
+					Extra: map[string]interface{}{
+						"username": goodUsername,
+						"groups":   []interface{}{"admins", "team-a", "users"},
+					},
+				},
+				Subject: goodSubject,
+			},
+			Custom: &psession.CustomSessionData{},
+		}
+		session.SetExpiresAt(fosite.AccessToken, expiresAt)
+		return &fosite.Request{
+			Client:       client,
+			Session:      session,
+			GrantedScope: fosite.Arguments{"openid", "username", "groups"},
+		}
+	}
+
+	jwtClient := &clientregistry.Client{
+		DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: clientID}},
+		AllowedGroupPrefixes:       []string{"team-"},
+		JWTAccessTokens:            true,
+	}
+	opaqueClient := &clientregistry.Client{
+		DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: clientID}},
+	}
+
+	newStrategy := func(activeJWK *jose.JSONWebKey) *dynamicOauth2JWTAccessTokenStrategy {
+		jwksProvider := jwks.NewDynamicJWKSProvider()
+		if activeJWK != nil {
+			jwksProvider.SetIssuerToJWKSMap(
+				map[string]*jose.JSONWebKeySet{goodIssuer: publicJWKS},
+				map[string]*jose.JSONWebKey{goodIssuer: activeJWK},
+			)
+		}
+		fositeConfig := &fosite.Config{IDTokenIssuer: goodIssuer}
+		return newDynamicOauth2JWTAccessTokenStrategy(
+			newDynamicOauth2HMACStrategy(fositeConfig, func() []byte { return []byte("12345678901234567890123456789012") }),
+			fositeConfig,
+			jwksProvider,
+		)
+	}
+
+	t.Run("clients which did not ask for JWT access tokens get opaque access tokens", func(t *testing.T) {
+		t.Parallel()
+		s := newStrategy(activeJWK)
+		requester := newRequester(opaqueClient, time.Now().Add(time.Minute))
+
+		token, signature, err := s.GenerateAccessToken(context.Background(), requester)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(token, "pin_at_"))
+		require.Equal(t, signature, s.AccessTokenSignature(context.Background(), token))
+		require.NoError(t, s.ValidateAccessToken(context.Background(), requester, token))
+	})
+
+	t.Run("clients which asked for JWT access tokens get signed JWT access tokens", func(t *testing.T) {
+		t.Parallel()
+		s := newStrategy(activeJWK)
+		expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+		requester := newRequester(jwtClient, expiresAt)
+
+		token, signature, err := s.GenerateAccessToken(context.Background(), requester)
+		require.NoError(t, err)
+		require.Len(t, strings.Split(token, "."), 3)
+		require.Equal(t, strings.Split(token, ".")[2], signature)
+		require.Equal(t, signature, s.AccessTokenSignature(context.Background(), token))
+		require.NoError(t, s.ValidateAccessToken(context.Background(), requester, token))
+
+		signed, err := jose.ParseSigned(token)
+		require.NoError(t, err)
+		require.Len(t, signed.Signatures, 1)
+		require.Equal(t, "active-kid", signed.Signatures[0].Header.KeyID)
+		require.Equal(t, "ES256", signed.Signatures[0].Header.Algorithm)
+		require.Equal(t, JWTAccessTokenType, signed.Signatures[0].Header.ExtraHeaders[jose.HeaderType])
+
+		payload, err := signed.Verify(&activeKey.PublicKey)
+		require.NoError(t, err)
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		require.NotEmpty(t, claims["jti"])
+		require.NotEmpty(t, claims["iat"])
+		delete(claims, "jti")
+		delete(claims, "iat")
+		require.Equal(t, map[string]interface{}{
+			"iss":       goodIssuer,
+			"sub":       goodSubject,
+			"aud":       []interface{}{clientID},
+			"exp":       float64(expiresAt.Unix()),
+			"client_id": clientID,
+			"scope":     "openid username groups",
+			"username":  goodUsername,
+			"groups":    []interface{}{"team-a"},
+		}, claims)
+	})
+
+	t.Run("JWT access tokens of client credentials grants identify the client and have the granted audience", func(t *testing.T) {
+		t.Parallel()
+		s := newStrategy(activeJWK)
+		expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+		session := &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{
+				Claims: &jwt.IDTokenClaims{
+					Subject: clientID,
+					Extra:   map[string]interface{}{"identity_type": "client"},
+				},
+				Subject: clientID,
+			},
+			Custom: &psession.CustomSessionData{},
+		}
+		session.SetExpiresAt(fosite.AccessToken, expiresAt)
+		requester := &fosite.Request{
+			Client:          jwtClient,
+			Session:         session,
+			GrantedScope:    fosite.Arguments{"orders:read"},
+			GrantedAudience: fosite.Arguments{"https://orders.example.com"},
+		}
+
+		token, _, err := s.GenerateAccessToken(context.Background(), requester)
+		require.NoError(t, err)
+
+		signed, err := jose.ParseSigned(token)
+		require.NoError(t, err)
+		payload, err := signed.Verify(&activeKey.PublicKey)
+		require.NoError(t, err)
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		delete(claims, "jti")
+		delete(claims, "iat")
+		require.Equal(t, map[string]interface{}{
+			"iss":           goodIssuer,
+			"sub":           clientID,
+			"aud":           []interface{}{"https://orders.example.com"},
+			"exp":           float64(expiresAt.Unix()),
+			"client_id":     clientID,
+			"scope":         "orders:read",
+			"identity_type": "client",
+		}, claims)
+	})
+
+	t.Run("JWT access tokens which were signed by a previous key are still valid", func(t *testing.T) {
+		t.Parallel()
+		requester := newRequester(jwtClient, time.Now().Add(time.Minute))
+
+		token, _, err := newStrategy(previousJWK).GenerateAccessToken(context.Background(), requester)
+		require.NoError(t, err)
+		require.NoError(t, newStrategy(activeJWK).ValidateAccessToken(context.Background(), requester, token))
+	})
+
+	t.Run("JWT access tokens which were not signed by any key of the issuer are invalid", func(t *testing.T) {
+		t.Parallel()
+		requester := newRequester(jwtClient, time.Now().Add(time.Minute))
+
+		token, _, err := newStrategy(unknownJWK).GenerateAccessToken(context.Background(), requester)
+		require.NoError(t, err)
+		err = newStrategy(activeJWK).ValidateAccessToken(context.Background(), requester, token)
+		require.True(t, errors.Is(err, fosite.ErrTokenSignatureMismatch))
+	})
+
+	t.Run("JWT access tokens whose sessions have expired are invalid", func(t *testing.T) {
+		t.Parallel()
+		s := newStrategy(activeJWK)
+		requester := newRequester(jwtClient, time.Now().Add(-time.Minute))
+
+		token, _, err := s.GenerateAccessToken(context.Background(), requester)
+		require.NoError(t, err)
+		err = s.ValidateAccessToken(context.Background(), requester, token)
+		require.True(t, errors.Is(err, fosite.ErrTokenExpired))
+	})
+
+	t.Run("JWT access tokens can not be issued when the issuer has no signing key", func(t *testing.T) {
+		t.Parallel()
+		s := newStrategy(nil)
+		requester := newRequester(jwtClient, time.Now().Add(time.Minute))
+
+		_, _, err := s.GenerateAccessToken(context.Background(), requester)
+		require.True(t, errors.Is(err, fosite.ErrTemporarilyUnavailable))
+		require.EqualError(t, err.(*fosite.RFC6749Error).Cause(), "no JWK found for issuer")
+	})
+}
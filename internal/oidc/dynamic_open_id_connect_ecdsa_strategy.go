@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
@@ -6,29 +6,38 @@ package oidc
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"reflect"
 	"time"
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
 	"github.com/ory/fosite/handler/openid"
+	"gopkg.in/square/go-jose.v2"
 
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/claimmappings"
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/oidc/jwks"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
 )
 
 // dynamicOpenIDConnectECDSAStrategy is an openid.OpenIDConnectTokenStrategy that can dynamically
 // load a signing key to issue ID tokens. We want this dynamic capability since our controllers for
 // loading FederationDomain's and signing keys run in parallel, and thus the signing key might not be
-// ready when an FederationDomain is otherwise ready.
+// ready when an FederationDomain is otherwise ready. Despite its name, it signs ID tokens using whichever
+// ECDSA (ES256), RSA (RS256), or Ed25519 (EdDSA) key is the active key of the FederationDomain.
 //
 // If we ever update FederationDomain's to hold their signing key, we might not need this type, since we
 // could have an invariant that routes to an FederationDomain's endpoints are only wired up if an
 // FederationDomain has a valid signing key.
 type dynamicOpenIDConnectECDSAStrategy struct {
-	fositeConfig *fosite.Config
-	jwksProvider jwks.DynamicJWKSProvider
+	fositeConfig  *fosite.Config
+	jwksProvider  jwks.DynamicJWKSProvider
+	claimMappings claimmappings.Mappings
 }
 
 var _ openid.OpenIDConnectTokenStrategy = &dynamicOpenIDConnectECDSAStrategy{}
@@ -36,10 +45,12 @@ var _ openid.OpenIDConnectTokenStrategy = &dynamicOpenIDConnectECDSAStrategy{}
 func newDynamicOpenIDConnectECDSAStrategy(
 	fositeConfig *fosite.Config,
 	jwksProvider jwks.DynamicJWKSProvider,
+	claimMappings claimmappings.Mappings,
 ) *dynamicOpenIDConnectECDSAStrategy {
 	return &dynamicOpenIDConnectECDSAStrategy{
-		fositeConfig: fositeConfig,
-		jwksProvider: jwksProvider,
+		fositeConfig:  fositeConfig,
+		jwksProvider:  jwksProvider,
+		claimMappings: claimMappings,
 	}
 }
 
@@ -53,20 +64,27 @@ func (s *dynamicOpenIDConnectECDSAStrategy) GenerateIDToken(
 		plog.Debug("no JWK found for issuer", "issuer", s.fositeConfig.IDTokenIssuer)
 		return "", fosite.ErrTemporarilyUnavailable.WithWrap(constable.Error("no JWK found for issuer"))
 	}
-	key, ok := activeJwk.Key.(*ecdsa.PrivateKey)
-	if !ok {
+	var key interface{}
+	switch k := activeJwk.Key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		// Fosite chooses ES256 or RS256 for these types of keys.
+		key = k
+	case ed25519.PrivateKey:
+		// Fosite only signs using EdDSA when it is given the algorithm along with the key.
+		key = &jose.JSONWebKey{Key: k, KeyID: activeJwk.KeyID, Algorithm: string(jose.EdDSA), Use: "sig"}
+	default:
 		actualType := "nil"
 		if t := reflect.TypeOf(activeJwk.Key); t != nil {
 			actualType = t.String()
 		}
 		plog.Debug(
-			"JWK must be of type ecdsa",
+			"JWK must be of type ecdsa, rsa, or ed25519",
 			"issuer",
 			s.fositeConfig.IDTokenIssuer,
 			"actualType",
 			actualType,
 		)
-		return "", fosite.ErrServerError.WithWrap(constable.Error("JWK must be of type ecdsa"))
+		return "", fosite.ErrServerError.WithWrap(constable.Error("JWK must be of type ecdsa, rsa, or ed25519"))
 	}
 
 	keyGetter := func(context.Context) (interface{}, error) {
@@ -74,5 +92,76 @@ func (s *dynamicOpenIDConnectECDSAStrategy) GenerateIDToken(
 	}
 	strategy := compose.NewOpenIDConnectStrategy(keyGetter, s.fositeConfig)
 
-	return strategy.GenerateIDToken(ctx, lifespan, requester)
+	return strategy.GenerateIDToken(ctx, lifespan, s.idTokenRequester(requester))
+}
+
+// idTokenRequester returns a requester whose session has the claims of the ID token for the client of the requester:
+// the groups are filtered by the groups filter of the client, and the claims which are mapped by the claim mappings
+// of the FederationDomain are added. These changes are made to a copy of the session, so they are never stored.
+func (s *dynamicOpenIDConnectECDSAStrategy) idTokenRequester(requester fosite.Requester) fosite.Requester {
+	session, ok := requester.GetSession().(*psession.PinnipedSession)
+	if !ok {
+		return requester
+	}
+
+	idTokenSession := filterGroupsForClient(session, requester.GetClient())
+	if mapped := s.claimMappings.Apply(idTokenSession); len(mapped) > 0 {
+		if idTokenSession == session {
+			idTokenSession = session.Clone().(*psession.PinnipedSession)
+		}
+		for claim, value := range mapped {
+			idTokenSession.IDTokenClaims().Add(claim, value)
+		}
+	}
+
+	if idTokenSession == session {
+		return requester
+	}
+	return &requesterWithSession{Requester: requester, session: idTokenSession}
+}
+
+// filterGroupsForClient returns a copy of the session whose groups are filtered by the groups filter of the client,
+// or the session itself when the client does not filter groups or when the session has no groups.
+func filterGroupsForClient(session *psession.PinnipedSession, client fosite.Client) *psession.PinnipedSession {
+	c, ok := client.(*clientregistry.Client)
+	if !ok || !c.FiltersGroups() || session.Fosite == nil || session.Fosite.Claims == nil {
+		return session
+	}
+	groups, ok := groupsFromExtraClaims(session.Fosite.Claims.Extra)
+	if !ok {
+		return session
+	}
+
+	filtered := session.Clone().(*psession.PinnipedSession)
+	filtered.IDTokenClaims().Extra[oidcapi.IDTokenClaimGroups] = c.FilterGroups(groups)
+	return filtered
+}
+
+// groupsFromExtraClaims returns the groups claim of the session. The groups are a []string when the session was
+// created during this request, but they are a []interface{} when the session was loaded from storage.
+func groupsFromExtraClaims(extra map[string]interface{}) ([]string, bool) {
+	switch groups := extra[oidcapi.IDTokenClaimGroups].(type) {
+	case []string:
+		return groups, true
+	case []interface{}:
+		s := make([]string, 0, len(groups))
+		for _, group := range groups {
+			if g, ok := group.(string); ok {
+				s = append(s, g)
+			}
+		}
+		return s, true
+	default:
+		return nil, false
+	}
+}
+
+// requesterWithSession is a fosite.Requester which replaces the session of another fosite.Requester.
+type requesterWithSession struct {
+	fosite.Requester
+	session fosite.Session
+}
+
+func (r *requesterWithSession) GetSession() fosite.Session {
+	return r.session
 }
@@ -1,11 +1,13 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
 
 import (
 	"context"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -20,7 +22,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/square/go-jose.v2"
 
+	"go.pinniped.dev/internal/claimmappings"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/psession"
 	"go.pinniped.dev/internal/testutil/oidctestutil"
 )
 
@@ -39,13 +43,20 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
 
+	_, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
 	tests := []struct {
 		name           string
 		issuer         string
 		jwksProvider   func(jwks.DynamicJWKSProvider)
+		claimMappings  claimmappings.Mappings
+		session        fosite.Session
 		wantErrorType  *fosite.RFC6749Error
 		wantErrorCause string
 		wantSigningJWK *jose.JSONWebKey
+		wantSigningAlg string
+		wantClaims     map[string]interface{}
 	}{
 		{
 			name:   "jwks provider does contain signing key for issuer",
@@ -64,6 +75,83 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 				Key: ecPrivateKey,
 			},
 		},
+		{
+			name:   "jwks provider contains an rsa signing key for issuer",
+			issuer: goodIssuer,
+			jwksProvider: func(provider jwks.DynamicJWKSProvider) {
+				provider.SetIssuerToJWKSMap(
+					nil,
+					map[string]*jose.JSONWebKey{
+						goodIssuer: {
+							Key:       rsaPrivateKey,
+							Algorithm: "RS256",
+						},
+					},
+				)
+			},
+			wantSigningJWK: &jose.JSONWebKey{
+				Key: rsaPrivateKey,
+			},
+			wantSigningAlg: "RS256",
+		},
+		{
+			name:   "jwks provider contains an ed25519 signing key for issuer",
+			issuer: goodIssuer,
+			jwksProvider: func(provider jwks.DynamicJWKSProvider) {
+				provider.SetIssuerToJWKSMap(
+					nil,
+					map[string]*jose.JSONWebKey{
+						goodIssuer: {
+							Key:       ed25519PrivateKey,
+							Algorithm: "EdDSA",
+						},
+					},
+				)
+			},
+			wantSigningJWK: &jose.JSONWebKey{
+				Key: ed25519PrivateKey,
+			},
+			wantSigningAlg: "EdDSA",
+		},
+		{
+			name:   "claim mappings add their claims to the ID token without changing the stored session",
+			issuer: goodIssuer,
+			jwksProvider: func(provider jwks.DynamicJWKSProvider) {
+				provider.SetIssuerToJWKSMap(
+					nil,
+					map[string]*jose.JSONWebKey{
+						goodIssuer: {
+							Key: ecPrivateKey,
+						},
+					},
+				)
+			},
+			claimMappings: claimmappings.Mappings{
+				{Claim: "email", Source: "additionalClaims.upstreamEmail"},
+				{Claim: "idp", Source: "upstreamProviderName"},
+				{Claim: "missing", Source: "additionalClaims.notInTheSession"},
+			},
+			session: &psession.PinnipedSession{
+				Fosite: &openid.DefaultSession{
+					Claims: &jwt.IDTokenClaims{
+						Subject: goodSubject,
+						Extra: map[string]interface{}{
+							"additionalClaims": map[string]interface{}{"upstreamEmail": "user@example.com"},
+						},
+					},
+					Subject:  goodSubject,
+					Username: goodUsername,
+				},
+				Custom: &psession.CustomSessionData{ProviderName: "some-idp"},
+			},
+			wantSigningJWK: &jose.JSONWebKey{
+				Key: ecPrivateKey,
+			},
+			wantClaims: map[string]interface{}{
+				"email": "user@example.com",
+				"idp":   "some-idp",
+			},
+		},
 		{
 			name:           "jwks provider does not contain signing key for issuer",
 			issuer:         goodIssuer,
@@ -78,13 +166,13 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 					nil,
 					map[string]*jose.JSONWebKey{
 						goodIssuer: {
-							Key: rsaPrivateKey,
+							Key: []byte("some-symmetric-key"),
 						},
 					},
 				)
 			},
 			wantErrorType:  fosite.ErrServerError,
-			wantErrorCause: "JWK must be of type ecdsa",
+			wantErrorCause: "JWK must be of type ecdsa, rsa, or ed25519",
 		},
 	}
 	for _, test := range tests {
@@ -97,19 +185,24 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 			s := newDynamicOpenIDConnectECDSAStrategy(
 				&fosite.Config{IDTokenIssuer: test.issuer},
 				jwksProvider,
+				test.claimMappings,
 			)
 
-			requester := &fosite.Request{
-				Client: &fosite.DefaultClient{
-					ID: clientID,
-				},
-				Session: &openid.DefaultSession{
+			session := test.session
+			if session == nil {
+				session = &openid.DefaultSession{
 					Claims: &jwt.IDTokenClaims{
 						Subject: goodSubject,
 					},
 					Subject:  goodSubject,
 					Username: goodUsername,
+				}
+			}
+			requester := &fosite.Request{
+				Client: &fosite.DefaultClient{
+					ID: clientID,
 				},
+				Session: session,
 				Form: url.Values{
 					"nonce": {goodNonce},
 				},
@@ -121,15 +214,32 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 
-				privateKey, ok := test.wantSigningJWK.Key.(*ecdsa.PrivateKey)
-				require.True(t, ok, "wanted private key to be *ecdsa.PrivateKey, but was %T", test.wantSigningJWK)
+				privateKey, ok := test.wantSigningJWK.Key.(crypto.Signer)
+				require.True(t, ok, "wanted private key to be a crypto.Signer, but was %T", test.wantSigningJWK)
+
+				wantSigningAlg := test.wantSigningAlg
+				if wantSigningAlg == "" {
+					wantSigningAlg = "ES256"
+				}
 
 				// Perform a light validation on the token to make sure 1) we passed through the correct
 				// signing key and 2) we forwarded the fosite.Requester correctly. Token generation is
 				// tested more expansively in the token endpoint.
-				token := oidctestutil.VerifyECDSAIDToken(t, goodIssuer, clientID, privateKey, idToken)
+				token := oidctestutil.VerifyIDToken(t, goodIssuer, clientID, privateKey, wantSigningAlg, idToken)
 				require.Equal(t, goodSubject, token.Subject)
 				require.Equal(t, goodNonce, token.Nonce)
+
+				var claims map[string]interface{}
+				require.NoError(t, token.Claims(&claims))
+				for claim, value := range test.wantClaims {
+					require.Equal(t, value, claims[claim])
+				}
+				require.NotContains(t, claims, "missing")
+				if pinnipedSession, ok := session.(*psession.PinnipedSession); ok {
+					for claim := range test.wantClaims {
+						require.NotContains(t, pinnipedSession.IDTokenClaims().Extra, claim)
+					}
+				}
 			}
 		})
 	}
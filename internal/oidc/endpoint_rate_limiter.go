@@ -0,0 +1,95 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/ratelimit"
+)
+
+// EndpointRateLimitConfig contains the limits on the rate of requests to the Supervisor endpoints which accept
+// passwords or issue tokens. Requests beyond the limits are rejected before they reach the upstream identity
+// providers, to protect them and the Supervisor from credential stuffing.
+type EndpointRateLimitConfig struct {
+	// PerClientIP limits the rate of requests from each client IP address. The client IP address is the address of the
+	// peer of the connection, so the clients behind a proxy share its limit unless the proxy is trusted to pass on their
+	// addresses, see clientip.Wrap.
+	PerClientIP ratelimit.Limit
+
+	// PerUsername limits the rate of requests for each username, for the requests which carry a username.
+	PerUsername ratelimit.Limit
+}
+
+// RateLimitedRequestFunc decides whether a request is subject to the rate limits of an endpoint. When it is,
+// it also returns the username which the request is trying to log in as, or an empty string when the request
+// does not carry a username.
+type RateLimitedRequestFunc func(r *http.Request) (username string, limited bool)
+
+// EndpointRateLimiter limits the rate of requests to an endpoint per client IP address and per username.
+// It is safe for concurrent use. A nil EndpointRateLimiter does not limit requests.
+type EndpointRateLimiter struct {
+	perClientIP *ratelimit.KeyedLimiter
+	perUsername *ratelimit.KeyedLimiter
+}
+
+// NewEndpointRateLimiter returns an EndpointRateLimiter which enforces the configured limits,
+// or nil when there are no limits.
+func NewEndpointRateLimiter(config EndpointRateLimitConfig) *EndpointRateLimiter {
+	return newEndpointRateLimiterWithClock(config, clock.RealClock{})
+}
+
+func newEndpointRateLimiterWithClock(config EndpointRateLimitConfig, c clock.PassiveClock) *EndpointRateLimiter {
+	if config == (EndpointRateLimitConfig{}) {
+		return nil
+	}
+	return &EndpointRateLimiter{
+		perClientIP: ratelimit.NewKeyedLimiter(config.PerClientIP, c),
+		perUsername: ratelimit.NewKeyedLimiter(config.PerUsername, c),
+	}
+}
+
+// Handler returns a http.Handler which passes the requests which are within the limits to the next handler.
+// Requests beyond the limits get a 429 Too Many Requests response with a Retry-After header.
+func (l *EndpointRateLimiter) Handler(next http.Handler, rateLimited RateLimitedRequestFunc) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, limited := rateLimited(r)
+		if !limited {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clientIP := auditlog.SourceIP(r)
+		if !l.perClientIP.TryAccept(clientIP) {
+			plog.Info("throttled request from client IP", "path", r.URL.Path, "clientIP", clientIP)
+			writeTooManyRequests(w, l.perClientIP.Limit().RetryAfter())
+			return
+		}
+
+		// Usernames are case-insensitive in most directories, so changing the case must not avoid the limit.
+		if username != "" && !l.perUsername.TryAccept(strings.ToLower(username)) {
+			plog.Info("throttled request for username", "path", r.URL.Path, "clientIP", clientIP)
+			writeTooManyRequests(w, l.perUsername.Limit().RetryAfter())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	// http.Error is important here because it prevents content sniffing by forcing text/plain.
+	http.Error(w, http.StatusText(http.StatusTooManyRequests)+": too many recent requests, please try again later", http.StatusTooManyRequests)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/httputil/clientip"
+	"go.pinniped.dev/internal/ratelimit"
+)
+
+func TestEndpointRateLimiter(t *testing.T) {
+	require.Nil(t, NewEndpointRateLimiter(EndpointRateLimitConfig{}))
+
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	subject := newEndpointRateLimiterWithClock(EndpointRateLimitConfig{
+		PerClientIP: ratelimit.Limit{AttemptsPerMinute: 3},
+		PerUsername: ratelimit.Limit{AttemptsPerMinute: 2},
+	}, fakeClock)
+
+	served := 0
+	handler := subject.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.WriteHeader(http.StatusNoContent)
+	}), func(r *http.Request) (string, bool) {
+		return r.Header.Get("Username"), r.Method == http.MethodPost
+	})
+
+	serve := func(method, clientIP, username string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, "/some/path", nil)
+		r.RemoteAddr = clientIP + ":1234"
+		if username != "" {
+			r.Header.Set("Username", username)
+		}
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, r)
+		return rsp
+	}
+
+	requireThrottled := func(rsp *httptest.ResponseRecorder, wantRetryAfter string) {
+		t.Helper()
+		require.Equal(t, http.StatusTooManyRequests, rsp.Code)
+		require.Equal(t, wantRetryAfter, rsp.Header().Get("Retry-After"))
+		require.Equal(t, "Too Many Requests: too many recent requests, please try again later\n", rsp.Body.String())
+	}
+
+	// Requests which are not subject to the limits are never throttled, and do not count towards the limits.
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusNoContent, serve(http.MethodGet, "192.0.2.1", "some-username").Code)
+	}
+
+	// Changing the case of the username does not avoid the limit.
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "192.0.2.1", "some-username").Code)
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "192.0.2.2", "Some-Username").Code)
+	requireThrottled(serve(http.MethodPost, "192.0.2.3", "SOME-USERNAME"), "30")
+
+	// Requests without a username are only limited per client IP.
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "192.0.2.1", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "192.0.2.1", "").Code)
+	requireThrottled(serve(http.MethodPost, "192.0.2.1", ""), "20")
+	requireThrottled(serve(http.MethodPost, "192.0.2.1", "some-other-username"), "20")
+
+	require.Equal(t, 14, served)
+
+	// More requests are allowed as time passes.
+	fakeClock.SetTime(fakeClock.Now().Add(30 * time.Second))
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "192.0.2.1", "some-username").Code)
+	require.Equal(t, 15, served)
+}
+
+func TestEndpointRateLimiterWithForwardedClientIP(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	subject := newEndpointRateLimiterWithClock(EndpointRateLimitConfig{
+		PerClientIP: ratelimit.Limit{AttemptsPerMinute: 1},
+	}, fakeClock)
+
+	_, proxyNetwork, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	handler := clientip.Wrap(subject.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), func(r *http.Request) (string, bool) {
+		return "", true
+	}), []*net.IPNet{proxyNetwork}, "")
+
+	serve := func(peerIP, forwardedFor string) int {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/some/path", nil)
+		r.RemoteAddr = peerIP + ":1234"
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, r)
+		return rsp.Code
+	}
+
+	// The clients behind a trusted proxy each have their own limit.
+	require.Equal(t, http.StatusNoContent, serve("10.1.2.3", "203.0.113.1"))
+	require.Equal(t, http.StatusNoContent, serve("10.1.2.3", "203.0.113.2"))
+	require.Equal(t, http.StatusTooManyRequests, serve("10.4.5.6", "203.0.113.1"))
+
+	// Other clients cannot avoid their limit by sending the header themselves.
+	require.Equal(t, http.StatusNoContent, serve("192.0.2.1", "203.0.113.3"))
+	require.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1", "203.0.113.4"))
+}
+
+func TestNilEndpointRateLimiter(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	var subject *EndpointRateLimiter
+	handler := subject.Handler(next, func(r *http.Request) (string, bool) {
+		require.FailNow(t, "should not be called")
+		return "", false
+	})
+
+	rsp := httptest.NewRecorder()
+	handler.ServeHTTP(rsp, httptest.NewRequest(http.MethodPost, "/some/path", nil))
+	require.Equal(t, http.StatusOK, rsp.Code)
+}
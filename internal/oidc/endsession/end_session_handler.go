@@ -0,0 +1,187 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package endsession provides a handler for the end session endpoint, as defined by the OIDC RP-Initiated Logout
+// specification: https://openid.net/specs/openid-connect-rpinitiated-1_0.html.
+package endsession
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/ory/fosite"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/strings/slices"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/revocation"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	idTokenHintParamName           = "id_token_hint"
+	clientIDParamName              = "client_id"
+	postLogoutRedirectURIParamName = "post_logout_redirect_uri"
+	stateParamName                 = "state"
+)
+
+// idTokenHintClaims are the claims of the id_token_hint which are needed to end the session.
+type idTokenHintClaims struct {
+	AuthorizedParty string `json:"azp"`
+	SessionID       string `json:"sid"`
+}
+
+// NewHandler returns a http.Handler that serves the end session endpoint.
+//
+// The id_token_hint param is required, and it must be an ID token which was issued by this FederationDomain, although
+// it may be expired. The downstream session identified by its sid claim is ended by deleting all of its access and
+// refresh tokens, after revoking the upstream tokens which were stored in that session. When the optional
+// post_logout_redirect_uri param is present, then it must exactly match one of the post logout redirect URIs of the
+// client to which the ID token was issued, and the user's browser is redirected to it after logout.
+func NewHandler(
+	downstreamIssuer string,
+	jwksProvider jwks.DynamicJWKSProvider,
+	idpLister oidc.UpstreamOIDCIdentityProvidersLister,
+	clients fosite.ClientManager,
+	secrets corev1client.SecretInterface,
+) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try GET or POST)", r.Method)
+		}
+
+		if err := r.ParseForm(); err != nil {
+			return httperr.Wrap(http.StatusBadRequest, "error parsing request params", err)
+		}
+
+		idTokenHint := r.Form.Get(idTokenHintParamName)
+		if idTokenHint == "" {
+			return httperr.New(http.StatusBadRequest, "id_token_hint param not found")
+		}
+
+		claims, err := verifyIDTokenHint(r.Context(), downstreamIssuer, jwksProvider, idTokenHint)
+		if err != nil {
+			plog.Info("end session endpoint received invalid id_token_hint", "err", err.Error())
+			return httperr.New(http.StatusBadRequest, "id_token_hint param is invalid")
+		}
+
+		if clientID := r.Form.Get(clientIDParamName); clientID != "" && clientID != claims.AuthorizedParty {
+			return httperr.New(http.StatusBadRequest, "client_id param does not match id_token_hint")
+		}
+
+		postLogoutRedirectURI := r.Form.Get(postLogoutRedirectURIParamName)
+		if postLogoutRedirectURI != "" {
+			if err := validatePostLogoutRedirectURI(r.Context(), clients, claims.AuthorizedParty, postLogoutRedirectURI); err != nil {
+				return err
+			}
+		}
+
+		if claims.SessionID != "" {
+			if err := revocation.RevokeSession(oidc.AuthenticationContext(r), idpLister, secrets, claims.SessionID); err != nil {
+				plog.WarningErr("end session endpoint could not revoke session", err, "sessionID", claims.SessionID)
+				return httperr.Wrap(http.StatusInternalServerError, "error while revoking session", err)
+			}
+		}
+
+		plog.Info("end session endpoint ended session", "clientID", claims.AuthorizedParty, "sessionID", claims.SessionID)
+
+		if postLogoutRedirectURI == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintln(w, "You have been logged out.")
+			return nil
+		}
+
+		return redirectAfterLogout(w, r, postLogoutRedirectURI, r.Form.Get(stateParamName))
+	})
+
+	return securityheader.Wrap(handler)
+}
+
+func verifyIDTokenHint(
+	ctx context.Context,
+	downstreamIssuer string,
+	jwksProvider jwks.DynamicJWKSProvider,
+	idTokenHint string,
+) (*idTokenHintClaims, error) {
+	jwkSet, _ := jwksProvider.GetJWKS(downstreamIssuer)
+	if jwkSet == nil || len(jwkSet.Keys) == 0 {
+		return nil, errors.New("no JWKS found for issuer")
+	}
+
+	publicKeys := make([]crypto.PublicKey, 0, len(jwkSet.Keys))
+	signingAlgs := []string{}
+	for _, key := range jwkSet.Keys {
+		publicKeys = append(publicKeys, key.Key)
+		if key.Algorithm != "" && !slices.Contains(signingAlgs, key.Algorithm) {
+			signingAlgs = append(signingAlgs, key.Algorithm)
+		}
+	}
+
+	// The id_token_hint is allowed to be expired, since the RP might want to log out a user whose ID token has
+	// already expired. The audience is checked below by comparing the azp claim to the client_id param, if any.
+	verifier := coreosoidc.NewVerifier(downstreamIssuer, &coreosoidc.StaticKeySet{PublicKeys: publicKeys}, &coreosoidc.Config{
+		SkipClientIDCheck:    true,
+		SkipExpiryCheck:      true,
+		SupportedSigningAlgs: signingAlgs,
+	})
+
+	idToken, err := verifier.Verify(ctx, idTokenHint)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims idTokenHintClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	if claims.AuthorizedParty == "" {
+		return nil, fmt.Errorf("id_token_hint is missing the %s claim", oidcapi.IDTokenClaimAuthorizedParty)
+	}
+
+	return &claims, nil
+}
+
+func validatePostLogoutRedirectURI(ctx context.Context, clients fosite.ClientManager, clientID string, postLogoutRedirectURI string) error {
+	client, err := clients.GetClient(ctx, clientID)
+	if err != nil {
+		plog.Info("end session endpoint could not find client", "clientID", clientID, "err", err.Error())
+		return httperr.New(http.StatusBadRequest, "client of id_token_hint was not found")
+	}
+
+	pinnipedClient, ok := client.(*clientregistry.Client)
+	if !ok || !slices.Contains(pinnipedClient.GetPostLogoutRedirectURIs(), postLogoutRedirectURI) {
+		return httperr.New(http.StatusBadRequest, "post_logout_redirect_uri param is not allowed for this client")
+	}
+
+	return nil
+}
+
+func redirectAfterLogout(w http.ResponseWriter, r *http.Request, postLogoutRedirectURI string, state string) error {
+	redirectURL, err := url.Parse(postLogoutRedirectURI)
+	if err != nil {
+		return httperr.Wrap(http.StatusBadRequest, "post_logout_redirect_uri param is invalid", err)
+	}
+
+	if state != "" {
+		q := redirectURL.Query()
+		q.Set(stateParamName, state)
+		redirectURL.RawQuery = q.Encode()
+	}
+
+	http.Redirect(w, r,
+		redirectURL.String(),
+		http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
+	)
+	return nil
+}
@@ -0,0 +1,494 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package endsession
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+func TestEndSessionEndpoint(t *testing.T) {
+	const (
+		downstreamIssuer = "https://my-downstream-issuer.com/some-path"
+		otherIssuer      = "https://other-issuer.com"
+
+		namespace             = "some-namespace"
+		dynamicClientID       = "client.oauth.pinniped.dev-test-name"
+		dynamicClientUID      = "fake-client-uid"
+		postLogoutRedirectURI = "https://client.example.com/logged_out"
+
+		sessionID      = "fake-session-id"
+		otherSessionID = "other-session-id"
+
+		oidcUpstreamName = "some-oidc-idp"
+		oidcUpstreamUID  = types.UID("oidc-resource-uid")
+
+		upstreamRefreshToken = "fake-upstream-refresh-token"
+		upstreamAccessToken  = "fake-upstream-access-token"
+	)
+
+	// The upstream tokens are revoked using the context of the request, along with the IP address of its client.
+	revokeContext := authenticators.ContextWithClientIP(context.Background(), "192.0.2.1")
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherSigningKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksProvider := jwks.NewDynamicJWKSProvider()
+	jwksProvider.SetIssuerToJWKSMap(
+		map[string]*jose.JSONWebKeySet{
+			downstreamIssuer: {Keys: []jose.JSONWebKey{{Key: &signingKey.PublicKey, KeyID: "some-kid", Algorithm: "ES256", Use: "sig"}}},
+		},
+		nil, // active private JWK unused
+	)
+
+	makeIDToken := func(t *testing.T, key *ecdsa.PrivateKey, claims map[string]interface{}) string {
+		t.Helper()
+		sig, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: key},
+			(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "some-kid"),
+		)
+		require.NoError(t, err)
+		idToken, err := jwt.Signed(sig).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return idToken
+	}
+
+	happyClaims := func(modify func(claims map[string]interface{})) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss": downstreamIssuer,
+			"sub": "some-subject",
+			"aud": dynamicClientID,
+			"azp": dynamicClientID,
+			"sid": sessionID,
+			"iat": time.Now().Add(-time.Minute).Unix(),
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+		if modify != nil {
+			modify(claims)
+		}
+		return claims
+	}
+
+	happyIDToken := makeIDToken(t, signingKey, happyClaims(nil))
+
+	makeSession := func(providerType psession.ProviderType) *psession.PinnipedSession {
+		return &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{Claims: nil, Subject: "some-subject"},
+			Custom: &psession.CustomSessionData{
+				Username:     "some-username",
+				ProviderUID:  oidcUpstreamUID,
+				ProviderName: oidcUpstreamName,
+				ProviderType: providerType,
+				OIDC: &psession.OIDCSessionData{
+					UpstreamRefreshToken: upstreamRefreshToken,
+					UpstreamSubject:      "some-upstream-subject",
+					UpstreamIssuer:       "https://some-upstream-issuer.com",
+				},
+			},
+		}
+	}
+
+	makeRequester := func(requestID string, grantedScopes []string, session *psession.PinnipedSession) *fosite.Request {
+		return &fosite.Request{
+			ID:           requestID,
+			RequestedAt:  time.Now(),
+			Client:       &clientregistry.Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: dynamicClientID}}},
+			GrantedScope: grantedScopes,
+			Form:         url.Values{},
+			Session:      session,
+		}
+	}
+
+	addSessionWithRefreshToken := func(t *testing.T, kubeClient *fake.Clientset, requestID string, providerType psession.ProviderType) {
+		t.Helper()
+		secrets := kubeClient.CoreV1().Secrets(namespace)
+		requester := makeRequester(requestID, []string{"openid", "offline_access"}, makeSession(providerType))
+		require.NoError(t, accesstoken.New(secrets, time.Now, time.Hour).CreateAccessTokenSession(context.Background(), "access-"+requestID, requester))
+		require.NoError(t, refreshtoken.New(secrets, time.Now, time.Hour).CreateRefreshTokenSession(context.Background(), "refresh-"+requestID, requester))
+	}
+
+	addSessionWithoutRefreshToken := func(t *testing.T, kubeClient *fake.Clientset, requestID string) {
+		t.Helper()
+		secrets := kubeClient.CoreV1().Secrets(namespace)
+		session := makeSession(psession.ProviderTypeOIDC)
+		session.Custom.OIDC.UpstreamRefreshToken = ""
+		session.Custom.OIDC.UpstreamAccessToken = upstreamAccessToken
+		requester := makeRequester(requestID, []string{"openid"}, session)
+		require.NoError(t, accesstoken.New(secrets, time.Now, time.Hour).CreateAccessTokenSession(context.Background(), "access-"+requestID, requester))
+	}
+
+	addDynamicClient := func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+		t.Helper()
+		oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+			namespace, dynamicClientID, dynamicClientUID, "https://client.example.com/callback",
+			[]string{testutil.HashedPassword1AtGoMinCost}, oidcclientvalidator.Validate)
+		oidcClient.Spec.AllowedPostLogoutRedirectURIs = []configv1alpha1.RedirectURI{postLogoutRedirectURI}
+		require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+		require.NoError(t, kubeClient.Tracker().Add(secret))
+	}
+
+	happyUpstream := func() *oidctestutil.TestUpstreamOIDCIdentityProviderBuilder {
+		return oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().
+			WithName(oidcUpstreamName).
+			WithResourceUID(oidcUpstreamUID).
+			WithRevokeTokenError(nil)
+	}
+
+	tests := []struct {
+		name string
+
+		method        string
+		params        url.Values
+		idps          *oidctestutil.UpstreamIDPListerBuilder
+		kubeResources func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset)
+
+		wantStatus                  int
+		wantContentType             string
+		wantBody                    string
+		wantLocation                string
+		wantRevokeTokenArgs         *oidctestutil.RevokeTokenArgs
+		wantRemainingSessionStorage []string
+	}{
+		{
+			name:   "GET with only id_token_hint ends the session and revokes the upstream refresh token",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {happyIDToken}},
+			idps:   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeOIDC)
+				addSessionWithRefreshToken(t, kubeClient, otherSessionID, psession.ProviderTypeOIDC)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "You have been logged out.\n",
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Ctx:       revokeContext,
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+			wantRemainingSessionStorage: []string{"access-token/" + otherSessionID, "refresh-token/" + otherSessionID},
+		},
+		{
+			name:   "POST with post_logout_redirect_uri and state redirects to the client after ending the session",
+			method: http.MethodPost,
+			params: url.Values{
+				"id_token_hint":            {happyIDToken},
+				"client_id":                {dynamicClientID},
+				"post_logout_redirect_uri": {postLogoutRedirectURI},
+				"state":                    {"some-state"},
+			},
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addDynamicClient(t, supervisorClient, kubeClient)
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeOIDC)
+			},
+			wantStatus:   http.StatusSeeOther,
+			wantLocation: postLogoutRedirectURI + "?state=some-state",
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Ctx:       revokeContext,
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+		},
+		{
+			name:   "GET with post_logout_redirect_uri and without state redirects to the client after ending the session",
+			method: http.MethodGet,
+			params: url.Values{
+				"id_token_hint":            {happyIDToken},
+				"post_logout_redirect_uri": {postLogoutRedirectURI},
+			},
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addDynamicClient(t, supervisorClient, kubeClient)
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeOIDC)
+			},
+			wantStatus:   http.StatusSeeOther,
+			wantLocation: postLogoutRedirectURI,
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Ctx:       revokeContext,
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+		},
+		{
+			name:   "expired id_token_hint is allowed",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {makeIDToken(t, signingKey, happyClaims(func(claims map[string]interface{}) {
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			}))}},
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeOIDC)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "You have been logged out.\n",
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Ctx:       revokeContext,
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+		},
+		{
+			name:   "session without offline_access revokes the upstream access token from the access token storage",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {happyIDToken}},
+			idps:   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addSessionWithoutRefreshToken(t, kubeClient, sessionID)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "You have been logged out.\n",
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Ctx:       revokeContext,
+				Token:     upstreamAccessToken,
+				TokenType: provider.AccessTokenType,
+			},
+		},
+		{
+			name:   "session from a non-OIDC upstream does not revoke upstream tokens",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {happyIDToken}},
+			idps:   oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeLDAP)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "You have been logged out.\n",
+		},
+		{
+			name:   "error from upstream revocation still ends the downstream session",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {happyIDToken}},
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(
+				happyUpstream().WithRevokeTokenError(errors.New("some upstream revocation error")).Build(),
+			),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeOIDC)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "You have been logged out.\n",
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Ctx:       revokeContext,
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+		},
+		{
+			name:            "session which was already ended",
+			method:          http.MethodGet,
+			params:          url.Values{"id_token_hint": {happyIDToken}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "You have been logged out.\n",
+		},
+		{
+			name:            "bad method",
+			method:          http.MethodPut,
+			params:          url.Values{"id_token_hint": {happyIDToken}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Method Not Allowed: PUT (try GET or POST)\n",
+		},
+		{
+			name:            "missing id_token_hint",
+			method:          http.MethodGet,
+			params:          url.Values{"post_logout_redirect_uri": {postLogoutRedirectURI}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: id_token_hint param not found\n",
+		},
+		{
+			name:            "id_token_hint which is not a JWT",
+			method:          http.MethodGet,
+			params:          url.Values{"id_token_hint": {"not-a-jwt"}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: id_token_hint param is invalid\n",
+		},
+		{
+			name:            "id_token_hint signed by an unknown key",
+			method:          http.MethodGet,
+			params:          url.Values{"id_token_hint": {makeIDToken(t, otherSigningKey, happyClaims(nil))}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: id_token_hint param is invalid\n",
+		},
+		{
+			name:   "id_token_hint from another issuer",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {makeIDToken(t, signingKey, happyClaims(func(claims map[string]interface{}) {
+				claims["iss"] = otherIssuer
+			}))}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: id_token_hint param is invalid\n",
+		},
+		{
+			name:   "id_token_hint without azp claim",
+			method: http.MethodGet,
+			params: url.Values{"id_token_hint": {makeIDToken(t, signingKey, happyClaims(func(claims map[string]interface{}) {
+				delete(claims, "azp")
+			}))}},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: id_token_hint param is invalid\n",
+		},
+		{
+			name:   "client_id does not match id_token_hint",
+			method: http.MethodGet,
+			params: url.Values{
+				"id_token_hint": {happyIDToken},
+				"client_id":     {"pinniped-cli"},
+			},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: client_id param does not match id_token_hint\n",
+		},
+		{
+			name:   "post_logout_redirect_uri which is not registered for the client",
+			method: http.MethodGet,
+			params: url.Values{
+				"id_token_hint":            {happyIDToken},
+				"post_logout_redirect_uri": {postLogoutRedirectURI + "/other"},
+			},
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+				addDynamicClient(t, supervisorClient, kubeClient)
+				addSessionWithRefreshToken(t, kubeClient, sessionID, psession.ProviderTypeOIDC)
+			},
+			wantStatus:                  http.StatusBadRequest,
+			wantContentType:             "text/plain; charset=utf-8",
+			wantBody:                    "Bad Request: post_logout_redirect_uri param is not allowed for this client\n",
+			wantRemainingSessionStorage: []string{"access-token/" + sessionID, "refresh-token/" + sessionID},
+		},
+		{
+			name:   "post_logout_redirect_uri for the pinniped-cli client, which has none registered",
+			method: http.MethodGet,
+			params: url.Values{
+				"id_token_hint": {makeIDToken(t, signingKey, happyClaims(func(claims map[string]interface{}) {
+					claims["aud"] = "pinniped-cli"
+					claims["azp"] = "pinniped-cli"
+				}))},
+				"post_logout_redirect_uri": {postLogoutRedirectURI},
+			},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: post_logout_redirect_uri param is not allowed for this client\n",
+		},
+		{
+			name:   "post_logout_redirect_uri for a client which does not exist",
+			method: http.MethodGet,
+			params: url.Values{
+				"id_token_hint":            {happyIDToken},
+				"post_logout_redirect_uri": {postLogoutRedirectURI},
+			},
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: client of id_token_hint was not found\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			supervisorClient := supervisorfake.NewSimpleClientset()
+			secrets := kubeClient.CoreV1().Secrets(namespace)
+			if test.kubeResources != nil {
+				test.kubeResources(t, supervisorClient, kubeClient)
+			}
+
+			kubeStorage := oidc.NewKubeStorage(secrets, supervisorClient.ConfigV1alpha1().OIDCClients(namespace),
+				oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+
+			subject := NewHandler(downstreamIssuer, jwksProvider, test.idps.Build(), kubeStorage, secrets)
+
+			var req *http.Request
+			if test.method == http.MethodPost {
+				req = httptest.NewRequest(test.method, "/some-path/oauth2/logout", strings.NewReader(test.params.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(test.method, "/some-path/oauth2/logout?"+test.params.Encode(), nil)
+			}
+			rsp := httptest.NewRecorder()
+			subject.ServeHTTP(rsp, req)
+
+			require.Equal(t, test.wantStatus, rsp.Code)
+			testutil.RequireSecurityHeadersWithoutCustomCSPs(t, rsp)
+			if test.wantLocation != "" {
+				require.Equal(t, test.wantLocation, rsp.Header().Get("Location"))
+			} else {
+				require.Empty(t, rsp.Header().Get("Location"))
+				require.Equal(t, test.wantContentType, rsp.Header().Get("Content-Type"))
+				require.Equal(t, test.wantBody, rsp.Body.String())
+			}
+
+			if test.wantRevokeTokenArgs != nil {
+				test.idps.RequireExactlyOneCallToRevokeToken(t, oidcUpstreamName, test.wantRevokeTokenArgs)
+			} else {
+				test.idps.RequireExactlyZeroCallsToRevokeToken(t)
+			}
+
+			// Only the storage of other sessions, or of sessions which were not ended due to errors, should remain.
+			remainingSecrets, err := secrets.List(context.Background(), metav1.ListOptions{LabelSelector: "storage.pinniped.dev/request-id"})
+			require.NoError(t, err)
+			remainingSessionStorage := []string{}
+			for _, secret := range remainingSecrets.Items {
+				remainingSessionStorage = append(remainingSessionStorage,
+					secret.Labels["storage.pinniped.dev/type"]+"/"+secret.Labels["storage.pinniped.dev/request-id"])
+			}
+			require.ElementsMatch(t, test.wantRemainingSessionStorage, remainingSessionStorage)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package i18n translates the messages of the Supervisor's HTML pages, i.e. the login form, the identity provider
+// chooser page, the pages of the device authorization grant, and the consent page. The language of each page is
+// negotiated using the Accept-Language header of the request. Operators may supply additional catalogs of
+// translations in a ConfigMap.
+package i18n
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultLanguage is the language of the pages when none of the languages of the request are available.
+const DefaultLanguage = "en"
+
+// NamePlaceholder is replaced by the name of an identity provider, or of a client, in the messages which contain it.
+const NamePlaceholder = "{name}"
+
+// The IDs of the messages which are rendered by the handlers. The messages which are rendered only by the templates
+// are referenced by their IDs in the templates.
+const (
+	LoginErrorInternal                    = "login.error.internal"
+	LoginErrorIncorrectUsernameOrPassword = "login.error.incorrectUsernameOrPassword"
+	LoginErrorThrottled                   = "login.error.throttled"
+
+	DeviceVerificationHeading          = "device.verification.heading"
+	DeviceVerificationMessage          = "device.verification.message"
+	DeviceVerificationErrorInvalidCode = "device.verification.error.invalidCode"
+	DeviceApprovedHeading              = "device.approved.heading"
+	DeviceApprovedMessage              = "device.approved.message"
+	DeviceDeniedHeading                = "device.denied.heading"
+	DeviceDeniedMessage                = "device.denied.message"
+
+	ConsentScopeOpenID          = "consent.scope.openid"
+	ConsentScopeOfflineAccess   = "consent.scope.offlineAccess"
+	ConsentScopeUsername        = "consent.scope.username"
+	ConsentScopeGroups          = "consent.scope.groups"
+	ConsentScopeRequestAudience = "consent.scope.requestAudience"
+)
+
+const maxCatalogBytes = 64 * 1024
+
+//nolint:gochecknoglobals // This package uses globals to ensure that the built-in catalogs are parsed at init.
+var (
+	//go:embed catalogs/*.yaml
+	builtinCatalogFiles embed.FS
+
+	// builtinCatalogs are the catalogs which ship with the Supervisor, by language.
+	builtinCatalogs = mustLoadBuiltinCatalogs()
+
+	// english is the catalog of the default language, which has every message.
+	english = builtinCatalogs[DefaultLanguage]
+
+	// builtinOnly is used to negotiate the language when there are no additional catalogs.
+	builtinOnly = NewCatalogs()
+)
+
+// Catalog is a set of messages in one language, by message ID.
+type Catalog map[string]string
+
+// ParseCatalog parses and validates a YAML catalog of messages. It may translate any subset of the messages.
+func ParseCatalog(data []byte) (Catalog, error) {
+	if len(data) > maxCatalogBytes {
+		return nil, fmt.Errorf("must not be larger than %d bytes", maxCatalogBytes)
+	}
+	catalog := Catalog{}
+	if err := yaml.UnmarshalStrict(data, &catalog); err != nil {
+		return nil, fmt.Errorf("must be a YAML map of message IDs to messages: %w", err)
+	}
+	if catalog == nil {
+		catalog = Catalog{} // an empty document
+	}
+	var unknown []string
+	for id, message := range catalog {
+		englishMessage, ok := english[id]
+		if !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+		if strings.TrimSpace(message) == "" {
+			return nil, fmt.Errorf("message %s must not be empty", id)
+		}
+		if strings.Contains(englishMessage, NamePlaceholder) && !strings.Contains(message, NamePlaceholder) {
+			return nil, fmt.Errorf("message %s must contain %s", id, NamePlaceholder)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown message IDs: %s", strings.Join(unknown, ", "))
+	}
+	return catalog, nil
+}
+
+// FromConfigMap parses and validates the catalogs in the ConfigMap. Each key of the ConfigMap's data is named after
+// a language tag, e.g. "fr.yaml" or "pt-BR.yaml", and holds a YAML catalog of that language.
+func FromConfigMap(configMap *corev1.ConfigMap) (map[string]Catalog, error) {
+	if len(configMap.BinaryData) > 0 {
+		return nil, errors.New("catalogs must be in data, not binaryData")
+	}
+	catalogs := map[string]Catalog{}
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lang, err := languageOfKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := catalogs[lang]; ok {
+			return nil, fmt.Errorf("key %q is a duplicate catalog of language %s", key, lang)
+		}
+		catalog, err := ParseCatalog([]byte(configMap.Data[key]))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		catalogs[lang] = catalog
+	}
+	return catalogs, nil
+}
+
+func languageOfKey(key string) (string, error) {
+	if path.Ext(key) != ".yaml" {
+		return "", fmt.Errorf("key %q must be named after a language tag, e.g. fr.yaml", key)
+	}
+	tag, err := language.Parse(strings.TrimSuffix(key, ".yaml"))
+	if err != nil {
+		return "", fmt.Errorf("key %q must be named after a language tag, e.g. fr.yaml: %w", key, err)
+	}
+	return tag.String(), nil
+}
+
+func mustLoadBuiltinCatalogs() map[string]Catalog {
+	files, err := builtinCatalogFiles.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+	catalogs := map[string]Catalog{}
+	for _, file := range files {
+		data, err := builtinCatalogFiles.ReadFile("catalogs/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+		// The built-in catalogs are validated by the unit tests, so they only need to be unmarshalled here.
+		catalog := Catalog{}
+		if err := yaml.UnmarshalStrict(data, &catalog); err != nil {
+			panic(fmt.Errorf("built-in catalog %s: %w", file.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(file.Name(), ".yaml")] = catalog
+	}
+	return catalogs
+}
+
+// Catalogs are the catalogs of every available language, i.e. the built-in catalogs and the additional catalogs
+// which were supplied by the operator. It is safe for concurrent use. A nil Catalogs has only the built-in catalogs.
+type Catalogs struct {
+	mu        sync.RWMutex
+	languages []string // the first language is the default language
+	catalogs  []Catalog
+	matcher   language.Matcher
+}
+
+// NewCatalogs returns Catalogs which have only the built-in catalogs.
+func NewCatalogs() *Catalogs {
+	c := &Catalogs{}
+	c.SetAdditional(nil)
+	return c
+}
+
+// SetAdditional replaces the additional catalogs. An additional catalog either adds a language, or replaces some
+// messages of a built-in language.
+func (c *Catalogs) SetAdditional(additional map[string]Catalog) {
+	merged := map[string]Catalog{}
+	for lang, catalog := range builtinCatalogs {
+		merged[lang] = catalog
+	}
+	for lang, catalog := range additional {
+		combined := Catalog{}
+		for id, message := range merged[lang] {
+			combined[id] = message
+		}
+		for id, message := range catalog {
+			combined[id] = message
+		}
+		merged[lang] = combined
+	}
+
+	languages := make([]string, 0, len(merged))
+	for lang := range merged {
+		if lang != DefaultLanguage {
+			languages = append(languages, lang)
+		}
+	}
+	sort.Strings(languages)
+	languages = append([]string{DefaultLanguage}, languages...)
+
+	catalogs := make([]Catalog, len(languages))
+	tags := make([]language.Tag, len(languages))
+	for i, lang := range languages {
+		catalogs[i] = merged[lang]
+		tags[i] = language.Make(lang)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.languages = languages
+	c.catalogs = catalogs
+	c.matcher = language.NewMatcher(tags)
+}
+
+// ForRequest returns the messages in the language which best matches the Accept-Language header of the request.
+func (c *Catalogs) ForRequest(r *http.Request) *Messages {
+	if c == nil {
+		return builtinOnly.ForRequest(r)
+	}
+	// Invalid parts of the header are skipped, and an empty or invalid header matches the default language.
+	preferred, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	// When nothing matches, the matcher returns the index of the default language.
+	_, index, _ := c.matcher.Match(preferred...)
+	return &Messages{lang: c.languages[index], catalog: c.catalogs[index]}
+}
+
+// Messages are the messages of the pages in one language. Messages which are not translated to that language are
+// in the default language. A nil Messages has the messages of the default language.
+type Messages struct {
+	lang    string
+	catalog Catalog
+}
+
+// Lang returns the language tag of the messages, for the lang attribute of the pages.
+func (m *Messages) Lang() string {
+	if m == nil {
+		return DefaultLanguage
+	}
+	return m.lang
+}
+
+// Text returns the message with the given ID.
+func (m *Messages) Text(id string) string {
+	if m != nil {
+		if message, ok := m.catalog[id]; ok {
+			return message
+		}
+	}
+	return english[id]
+}
+
+// TextWithName returns the message with the given ID, with its placeholder replaced by the name.
+func (m *Messages) TextWithName(id string, name string) string {
+	return strings.ReplaceAll(m.Text(id), NamePlaceholder, name)
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuiltinCatalogs(t *testing.T) {
+	englishIDs := make([]string, 0, len(english))
+	for id := range english {
+		englishIDs = append(englishIDs, id)
+	}
+	sort.Strings(englishIDs)
+
+	require.ElementsMatch(t, []string{"de", "en", "es"}, keys(builtinCatalogs))
+	for lang, catalog := range builtinCatalogs {
+		data, err := builtinCatalogFiles.ReadFile("catalogs/" + lang + ".yaml")
+		require.NoError(t, err)
+		_, err = ParseCatalog(data)
+		require.NoError(t, err, "built-in catalog %s is invalid", lang)
+
+		// The built-in catalogs translate every message.
+		require.Equal(t, englishIDs, keys(catalog), "built-in catalog %s is incomplete", lang)
+	}
+
+	// Every message which is rendered by the handlers exists.
+	for _, id := range []string{
+		LoginErrorInternal, LoginErrorIncorrectUsernameOrPassword, LoginErrorThrottled,
+		DeviceVerificationHeading, DeviceVerificationMessage, DeviceVerificationErrorInvalidCode,
+		DeviceApprovedHeading, DeviceApprovedMessage, DeviceDeniedHeading, DeviceDeniedMessage,
+		ConsentScopeOpenID, ConsentScopeOfflineAccess, ConsentScopeUsername, ConsentScopeGroups, ConsentScopeRequestAudience,
+	} {
+		require.Contains(t, english, id)
+	}
+}
+
+func TestParseCatalog(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		wantCatalog Catalog
+		wantErr     string
+	}{
+		{
+			name:        "some messages",
+			yaml:        "login.username: Nom d'utilisateur\nlogin.heading: Se connecter à {name}\n",
+			wantCatalog: Catalog{"login.username": "Nom d'utilisateur", "login.heading": "Se connecter à {name}"},
+		},
+		{
+			name:        "no messages",
+			yaml:        "",
+			wantCatalog: Catalog{},
+		},
+		{
+			name:    "unknown message IDs",
+			yaml:    "login.user: Nom d'utilisateur\nlogin.pass: Mot de passe\nlogin.submit: Se connecter\n",
+			wantErr: "unknown message IDs: login.pass, login.user",
+		},
+		{
+			name:    "empty message",
+			yaml:    "login.submit: ' '\n",
+			wantErr: "message login.submit must not be empty",
+		},
+		{
+			name:    "message without the name placeholder",
+			yaml:    "chooseIDP.submit: Se connecter\n",
+			wantErr: "message chooseIDP.submit must contain {name}",
+		},
+		{
+			name:    "not a map of strings",
+			yaml:    "login.submit:\n  text: Se connecter\n",
+			wantErr: "must be a YAML map of message IDs to messages: ",
+		},
+		{
+			name:    "too large",
+			yaml:    "login.submit: " + strings.Repeat("x", maxCatalogBytes),
+			wantErr: "must not be larger than 65536 bytes",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, err := ParseCatalog([]byte(tt.yaml))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, catalog)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCatalog, catalog)
+		})
+	}
+}
+
+func TestFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         map[string]string
+		binaryData   map[string][]byte
+		wantCatalogs map[string]Catalog
+		wantErr      string
+	}{
+		{
+			name: "catalogs of several languages",
+			data: map[string]string{
+				"fr.yaml":    "login.submit: Se connecter\n",
+				"pt-br.yaml": "login.submit: Entrar\n",
+				"de.yaml":    "login.submit: Einloggen\n",
+			},
+			wantCatalogs: map[string]Catalog{
+				"fr":    {"login.submit": "Se connecter"},
+				"pt-BR": {"login.submit": "Entrar"},
+				"de":    {"login.submit": "Einloggen"},
+			},
+		},
+		{
+			name:         "empty",
+			wantCatalogs: map[string]Catalog{},
+		},
+		{
+			name:    "key which is not yaml",
+			data:    map[string]string{"fr.json": "{}"},
+			wantErr: `key "fr.json" must be named after a language tag, e.g. fr.yaml`,
+		},
+		{
+			name:    "key which is not a language tag",
+			data:    map[string]string{"12.yaml": ""},
+			wantErr: `key "12.yaml" must be named after a language tag, e.g. fr.yaml: `,
+		},
+		{
+			name:    "duplicate language",
+			data:    map[string]string{"pt-BR.yaml": "", "pt-br.yaml": ""},
+			wantErr: `key "pt-br.yaml" is a duplicate catalog of language pt-BR`,
+		},
+		{
+			name:    "invalid catalog",
+			data:    map[string]string{"fr.yaml": "login.user: Nom d'utilisateur\n"},
+			wantErr: `key "fr.yaml": unknown message IDs: login.user`,
+		},
+		{
+			name:       "binary data",
+			binaryData: map[string][]byte{"fr.yaml": []byte("login.submit: Se connecter\n")},
+			wantErr:    "catalogs must be in data, not binaryData",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			catalogs, err := FromConfigMap(&corev1.ConfigMap{Data: tt.data, BinaryData: tt.binaryData})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, catalogs)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCatalogs, catalogs)
+		})
+	}
+}
+
+func TestForRequest(t *testing.T) {
+	additional := map[string]Catalog{
+		"fr": {"login.submit": "Se connecter"},
+		"de": {"login.submit": "Einloggen"},
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		additional     map[string]Catalog
+		wantLang       string
+		wantSubmit     string
+		wantUsername   string
+	}{
+		{
+			name:         "no Accept-Language header",
+			wantLang:     "en",
+			wantSubmit:   "Log in",
+			wantUsername: "Username",
+		},
+		{
+			name:           "invalid Accept-Language header",
+			acceptLanguage: "%%%",
+			wantLang:       "en",
+			wantSubmit:     "Log in",
+			wantUsername:   "Username",
+		},
+		{
+			name:           "a built-in language",
+			acceptLanguage: "de",
+			wantLang:       "de",
+			wantSubmit:     "Anmelden",
+			wantUsername:   "Benutzername",
+		},
+		{
+			name:           "a regional variant of a built-in language",
+			acceptLanguage: "es-MX,es;q=0.9",
+			wantLang:       "es",
+			wantSubmit:     "Iniciar sesión",
+			wantUsername:   "Nombre de usuario",
+		},
+		{
+			name:           "the first available language by quality",
+			acceptLanguage: "ja;q=0.9, es;q=0.5, de;q=0.8",
+			wantLang:       "de",
+			wantSubmit:     "Anmelden",
+			wantUsername:   "Benutzername",
+		},
+		{
+			name:           "an unavailable language",
+			acceptLanguage: "fr",
+			wantLang:       "en",
+			wantSubmit:     "Log in",
+			wantUsername:   "Username",
+		},
+		{
+			name:           "an additional language falls back to English for the messages which it does not translate",
+			acceptLanguage: "fr-CA",
+			additional:     additional,
+			wantLang:       "fr",
+			wantSubmit:     "Se connecter",
+			wantUsername:   "Username",
+		},
+		{
+			name:           "an additional catalog replaces some messages of a built-in language",
+			acceptLanguage: "de",
+			additional:     additional,
+			wantLang:       "de",
+			wantSubmit:     "Einloggen",
+			wantUsername:   "Benutzername",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			catalogs := NewCatalogs()
+			catalogs.SetAdditional(tt.additional)
+			messages := catalogs.ForRequest(req)
+
+			require.Equal(t, tt.wantLang, messages.Lang())
+			require.Equal(t, tt.wantSubmit, messages.Text("login.submit"))
+			require.Equal(t, tt.wantUsername, messages.Text("login.username"))
+
+			if tt.additional == nil {
+				// A nil Catalogs has only the built-in catalogs.
+				var nilCatalogs *Catalogs
+				require.Equal(t, messages, nilCatalogs.ForRequest(req))
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	var unset *Messages
+	require.Equal(t, "en", unset.Lang())
+	require.Equal(t, "Log in", unset.Text("login.submit"))
+	require.Equal(t, "Log in to my-ldap", unset.TextWithName("login.heading", "my-ldap"))
+
+	messages := &Messages{lang: "fr", catalog: Catalog{"login.heading": "Se connecter à {name}"}}
+	require.Equal(t, "fr", messages.Lang())
+	require.Equal(t, "Se connecter à my-ldap", messages.TextWithName("login.heading", "my-ldap"))
+	require.Equal(t, "Log in", messages.Text("login.submit"))
+	require.Empty(t, messages.Text("no.such.message"))
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package idpdiscovery provides a handler for the upstream IDP discovery endpoint.
@@ -14,8 +14,9 @@ import (
 	"go.pinniped.dev/internal/oidc"
 )
 
-// NewHandler returns an http.Handler that serves the upstream IDP discovery endpoint.
-func NewHandler(upstreamIDPs oidc.UpstreamIdentityProvidersLister) http.Handler {
+// NewHandler returns an http.Handler that serves the upstream IDP discovery endpoint. The IDPs are listed by the
+// display names which the FederationDomain gave them.
+func NewHandler(upstreamIDPs oidc.FederationDomainIdentityProvidersListerI) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, `Method not allowed (try GET)`, http.StatusMethodNotAllowed)
@@ -36,20 +37,20 @@ func NewHandler(upstreamIDPs oidc.UpstreamIdentityProvidersLister) http.Handler
 	})
 }
 
-func responseAsJSON(upstreamIDPs oidc.UpstreamIdentityProvidersLister) ([]byte, error) {
+func responseAsJSON(upstreamIDPs oidc.FederationDomainIdentityProvidersListerI) ([]byte, error) {
 	r := v1alpha1.IDPDiscoveryResponse{PinnipedIDPs: []v1alpha1.PinnipedIDP{}}
 
 	// The cache of IDPs could change at any time, so always recalculate the list.
 	for _, provider := range upstreamIDPs.GetLDAPIdentityProviders() {
 		r.PinnipedIDPs = append(r.PinnipedIDPs, v1alpha1.PinnipedIDP{
-			Name:  provider.GetName(),
+			Name:  upstreamIDPs.GetDisplayName(provider.GetResourceUID(), provider.GetName()),
 			Type:  v1alpha1.IDPTypeLDAP,
 			Flows: []v1alpha1.IDPFlow{v1alpha1.IDPFlowCLIPassword, v1alpha1.IDPFlowBrowserAuthcode},
 		})
 	}
 	for _, provider := range upstreamIDPs.GetActiveDirectoryIdentityProviders() {
 		r.PinnipedIDPs = append(r.PinnipedIDPs, v1alpha1.PinnipedIDP{
-			Name:  provider.GetName(),
+			Name:  upstreamIDPs.GetDisplayName(provider.GetResourceUID(), provider.GetName()),
 			Type:  v1alpha1.IDPTypeActiveDirectory,
 			Flows: []v1alpha1.IDPFlow{v1alpha1.IDPFlowCLIPassword, v1alpha1.IDPFlowBrowserAuthcode},
 		})
@@ -60,11 +61,19 @@ func responseAsJSON(upstreamIDPs oidc.UpstreamIdentityProvidersLister) ([]byte,
 			flows = append(flows, v1alpha1.IDPFlowCLIPassword)
 		}
 		r.PinnipedIDPs = append(r.PinnipedIDPs, v1alpha1.PinnipedIDP{
-			Name:  provider.GetName(),
+			Name:  upstreamIDPs.GetDisplayName(provider.GetResourceUID(), provider.GetName()),
 			Type:  v1alpha1.IDPTypeOIDC,
 			Flows: flows,
 		})
 	}
+	for _, provider := range upstreamIDPs.GetKerberosIdentityProviders() {
+		// Kerberos logins require SPNEGO, which only browsers perform, so there is no CLI password flow.
+		r.PinnipedIDPs = append(r.PinnipedIDPs, v1alpha1.PinnipedIDP{
+			Name:  upstreamIDPs.GetDisplayName(provider.GetResourceUID(), provider.GetName()),
+			Type:  v1alpha1.IDPTypeKerberos,
+			Flows: []v1alpha1.IDPFlow{v1alpha1.IDPFlowBrowserAuthcode},
+		})
+	}
 
 	// Nobody like an API that changes the results unnecessarily. :)
 	sort.SliceStable(r.PinnipedIDPs, func(i, j int) bool {
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package idpdiscovery
@@ -39,6 +39,7 @@ func TestIDPDiscovery(t *testing.T) {
 				"pinniped_identity_providers": [
 					{"name": "a-some-ldap-idp", "type": "ldap",            "flows": ["cli_password", "browser_authcode"]},
 					{"name": "a-some-oidc-idp", "type": "oidc",            "flows": ["browser_authcode"]},
+					{"name": "k-some-krb-idp",  "type": "kerberos",        "flows": ["browser_authcode"]},
 					{"name": "x-some-idp",      "type": "ldap",            "flows": ["cli_password", "browser_authcode"]},
 					{"name": "x-some-idp",      "type": "oidc",            "flows": ["browser_authcode"]},
 					{"name": "y-some-ad-idp",   "type": "activedirectory", "flows": ["cli_password", "browser_authcode"]},
@@ -51,6 +52,7 @@ func TestIDPDiscovery(t *testing.T) {
 				"pinniped_identity_providers": [
 					{"name": "some-other-ad-idp-1",   "type": "activedirectory", "flows": ["cli_password", "browser_authcode"]},
 					{"name": "some-other-ad-idp-2",   "type": "activedirectory", "flows": ["cli_password", "browser_authcode"]},
+					{"name": "some-other-krb-idp-1",  "type": "kerberos",        "flows": ["browser_authcode"]},
 					{"name": "some-other-ldap-idp-1", "type": "ldap",            "flows": ["cli_password", "browser_authcode"]},
 					{"name": "some-other-ldap-idp-2", "type": "ldap",            "flows": ["cli_password", "browser_authcode"]},
 					{"name": "some-other-oidc-idp-1", "type": "oidc",            "flows": ["browser_authcode", "cli_password"]},
@@ -79,9 +81,10 @@ func TestIDPDiscovery(t *testing.T) {
 				WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "x-some-idp"}).
 				WithActiveDirectory(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "z-some-ad-idp"}).
 				WithActiveDirectory(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "y-some-ad-idp"}).
+				WithKerberos(&oidctestutil.TestUpstreamKerberosIdentityProvider{Name: "k-some-krb-idp"}).
 				Build()
 
-			handler := NewHandler(idpLister)
+			handler := NewHandler(provider.NewFederationDomainIdentityProvidersLister(idpLister, nil))
 			req := httptest.NewRequest(test.method, test.path, nil)
 			rsp := httptest.NewRecorder()
 			handler.ServeHTTP(rsp, req)
@@ -113,6 +116,10 @@ func TestIDPDiscovery(t *testing.T) {
 				&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "some-other-ad-idp-1"},
 			})
 
+			idpLister.SetKerberosIdentityProviders([]provider.UpstreamKerberosIdentityProviderI{
+				&oidctestutil.TestUpstreamKerberosIdentityProvider{Name: "some-other-krb-idp-1"},
+			})
+
 			// Make the same request to the same handler instance again, and expect different results.
 			rsp = httptest.NewRecorder()
 			handler.ServeHTTP(rsp, req)
@@ -131,3 +138,33 @@ func TestIDPDiscovery(t *testing.T) {
 		})
 	}
 }
+
+func TestIDPDiscoveryWithFederationDomainIdentityProviders(t *testing.T) {
+	idpLister := oidctestutil.NewUpstreamIDPListerBuilder().
+		WithOIDC(&oidctestutil.TestUpstreamOIDCIdentityProvider{Name: "some-oidc-idp", ResourceUID: "oidc-uid", AllowPasswordGrant: true}).
+		WithOIDC(&oidctestutil.TestUpstreamOIDCIdentityProvider{Name: "some-unlisted-oidc-idp", ResourceUID: "unlisted-oidc-uid"}).
+		WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "some-ldap-idp", ResourceUID: "ldap-uid"}).
+		WithActiveDirectory(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "some-unlisted-ad-idp", ResourceUID: "unlisted-ad-uid"}).
+		WithKerberos(&oidctestutil.TestUpstreamKerberosIdentityProvider{Name: "some-krb-idp", ResourceUID: "krb-uid"}).
+		Build()
+
+	handler := NewHandler(provider.NewFederationDomainIdentityProvidersLister(idpLister, []provider.FederationDomainIdentityProvider{
+		{DisplayName: "My Company OIDC", UID: "oidc-uid"},
+		{DisplayName: "My Company LDAP", UID: "ldap-uid"},
+		{DisplayName: "My Company Kerberos", UID: "krb-uid"},
+		{DisplayName: "Deleted IDP", UID: "deleted-uid"},
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/some/path"+oidc.WellKnownEndpointPath, nil)
+	rsp := httptest.NewRecorder()
+	handler.ServeHTTP(rsp, req)
+
+	require.Equal(t, http.StatusOK, rsp.Code)
+	require.Equal(t, "application/json", rsp.Header().Get("Content-Type"))
+	require.JSONEq(t, here.Doc(`{
+		"pinniped_identity_providers": [
+			{"name": "My Company Kerberos", "type": "kerberos", "flows": ["browser_authcode"]},
+			{"name": "My Company LDAP",     "type": "ldap",     "flows": ["cli_password", "browser_authcode"]},
+			{"name": "My Company OIDC",     "type": "oidc",     "flows": ["browser_authcode", "cli_password"]}
+		]
+	}`), rsp.Body.String())
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package introspection provides a handler for the token introspection endpoint, as defined by
+// https://datatracker.ietf.org/doc/html/rfc7662.
+package introspection
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ory/fosite"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+// Response is the introspection response for an active access token, as defined by
+// https://datatracker.ietf.org/doc/html/rfc7662#section-2.2. For an inactive token, only Active is returned.
+type Response struct {
+	Active    bool        `json:"active"`
+	Scope     string      `json:"scope,omitempty"`
+	ClientID  string      `json:"client_id,omitempty"`
+	Username  string      `json:"username,omitempty"`
+	Groups    interface{} `json:"groups,omitempty"`
+	TokenType string      `json:"token_type,omitempty"`
+	ExpiresAt int64       `json:"exp,omitempty"`
+	IssuedAt  int64       `json:"iat,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  []string    `json:"aud,omitempty"`
+	Issuer    string      `json:"iss,omitempty"`
+
+	// IdentityType is "client" for the access tokens which clients got for themselves using client credentials
+	// grants. It is empty for the access tokens of users.
+	IdentityType string `json:"identity_type,omitempty"`
+}
+
+// NewHandler returns a http.Handler that serves the token introspection endpoint.
+//
+// Resource servers use this endpoint to validate the access tokens issued by this FederationDomain, including JWT
+// access tokens, which are stored just like opaque access tokens. The caller must authenticate as an OIDCClient using
+// HTTP basic auth with its client ID and one of its client secrets. Only access tokens can be active. The username
+// and groups are only returned when they were granted to the token via the username and groups scopes, in the same
+// way as they would be returned in ID tokens.
+func NewHandler(downstreamIssuer string, oauthHelper fosite.OAuth2Provider) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try POST)", r.Method)
+		}
+
+		// Fosite would also accept another access token as the credential of the caller, but only OIDCClients
+		// are allowed to introspect tokens.
+		if _, _, ok := r.BasicAuth(); !ok {
+			err := fosite.ErrRequestUnauthorized.WithHint("The client must authenticate using HTTP basic auth.")
+			plog.Info("introspection request error", oidc.FositeErrorForLog(err)...)
+			oauthHelper.WriteIntrospectionError(r.Context(), w, err)
+			return nil
+		}
+
+		introspectionResponse, err := oauthHelper.NewIntrospectionRequest(r.Context(), r, psession.NewPinnipedSession())
+		if err != nil {
+			plog.Info("introspection request error", oidc.FositeErrorForLog(err)...)
+			if isRequestError(err) {
+				oauthHelper.WriteIntrospectionError(r.Context(), w, err)
+				return nil
+			}
+			// Expired, unknown, and otherwise invalid tokens are only reported as inactive, as required by
+			// https://datatracker.ietf.org/doc/html/rfc7662#section-2.2.
+			return writeResponse(w, &Response{Active: false})
+		}
+
+		response := &Response{Active: false}
+		if introspectionResponse.IsActive() && introspectionResponse.GetTokenUse() == fosite.AccessToken {
+			response = activeResponse(downstreamIssuer, introspectionResponse.GetAccessRequester())
+		}
+
+		return writeResponse(w, response)
+	})
+
+	return securityheader.Wrap(handler)
+}
+
+// isRequestError returns true for the errors which are about the introspection request itself, such as failed client
+// authentication, rather than about the token which was introspected.
+func isRequestError(err error) bool {
+	return !errors.Is(err, fosite.ErrInactiveToken) &&
+		(errors.Is(err, fosite.ErrInvalidRequest) || errors.Is(err, fosite.ErrRequestUnauthorized))
+}
+
+func writeResponse(w http.ResponseWriter, response *Response) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+func activeResponse(downstreamIssuer string, accessRequester fosite.AccessRequester) *Response {
+	response := &Response{
+		Active:    true,
+		Scope:     strings.Join(accessRequester.GetGrantedScopes(), " "),
+		ClientID:  accessRequester.GetClient().GetID(),
+		TokenType: "Bearer",
+		IssuedAt:  accessRequester.GetRequestedAt().Unix(),
+		Audience:  accessRequester.GetGrantedAudience(),
+		Issuer:    downstreamIssuer,
+	}
+
+	session := accessRequester.GetSession()
+	if expiresAt := session.GetExpiresAt(fosite.AccessToken); !expiresAt.IsZero() {
+		response.ExpiresAt = expiresAt.Unix()
+	}
+
+	if pinnipedSession, ok := session.(*psession.PinnipedSession); ok && pinnipedSession.Fosite != nil && pinnipedSession.Fosite.Claims != nil {
+		claims := pinnipedSession.Fosite.Claims
+		response.Subject = claims.Subject
+		// These extra claims are only present when their scopes were granted.
+		if username, ok := claims.Extra[oidcapi.IDTokenClaimUsername].(string); ok {
+			response.Username = username
+		}
+		if groups, ok := claims.Extra[oidcapi.IDTokenClaimGroups]; ok {
+			response.Groups = groups
+		}
+		if identityType, ok := claims.Extra[oidcapi.ClaimIdentityType].(string); ok {
+			response.IdentityType = identityType
+		}
+	}
+
+	return response
+}
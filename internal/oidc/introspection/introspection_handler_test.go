@@ -0,0 +1,258 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/openid"
+	fositejwt "github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/client-go/kubernetes/fake"
+
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestIntrospectionEndpoint(t *testing.T) {
+	const (
+		downstreamIssuer = "https://my-downstream-issuer.com/some-path"
+		namespace        = "some-namespace"
+		dynamicClientID  = "client.oauth.pinniped.dev-test-name"
+		dynamicClientUID = "fake-client-uid"
+	)
+
+	hmacSecret := []byte("some secret - must have at least 32 bytes")
+	hmacSecretFunc := func() []byte { return hmacSecret }
+	tokenStrategy := compose.NewOAuth2HMACStrategy(oidc.NewDynamicGlobalSecretConfig(&fosite.Config{}, hmacSecretFunc))
+
+	requestedAt := time.Now().Add(-time.Minute).UTC().Round(time.Second)
+
+	makeRequester := func(extra map[string]interface{}, grantedScopes []string, expiresAt time.Time) *fosite.Request {
+		session := &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{
+				Claims:  &fositejwt.IDTokenClaims{Subject: "some-subject", Extra: extra},
+				Subject: "some-subject",
+			},
+			Custom: &psession.CustomSessionData{Username: "some-username"},
+		}
+		session.SetExpiresAt(fosite.AccessToken, expiresAt)
+		session.SetExpiresAt(fosite.RefreshToken, expiresAt)
+		return &fosite.Request{
+			ID:           "some-request-id",
+			RequestedAt:  requestedAt,
+			Client:       &clientregistry.Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: dynamicClientID}}},
+			GrantedScope: grantedScopes,
+			Form:         url.Values{},
+			Session:      session,
+		}
+	}
+
+	happyExtra := map[string]interface{}{
+		"username": "some-username",
+		"groups":   []interface{}{"group1", "group2"},
+		"azp":      dynamicClientID,
+	}
+	happyScopes := []string{"openid", "offline_access", "username", "groups"}
+	inOneHour := time.Now().Add(time.Hour).UTC().Round(time.Second)
+
+	clientCredentialsRequester := makeRequester(map[string]interface{}{"identity_type": "client"}, []string{"orders:read"}, inOneHour)
+	clientCredentialsRequester.GrantedAudience = fosite.Arguments{"https://orders.example.com"}
+
+	tests := []struct {
+		name            string
+		method          string
+		noBasicAuth     bool
+		clientSecret    string
+		requester       *fosite.Request
+		useRefreshToken bool
+		useUnknownToken bool
+		wantStatus      int
+		wantBodyJSON    string
+		wantBodyString  string
+		wantErrorField  string
+	}{
+		{
+			name:       "active access token with username and groups",
+			requester:  makeRequester(happyExtra, happyScopes, inOneHour),
+			wantStatus: http.StatusOK,
+			wantBodyJSON: `{
+				"active": true,
+				"scope": "openid offline_access username groups",
+				"client_id": "client.oauth.pinniped.dev-test-name",
+				"username": "some-username",
+				"groups": ["group1", "group2"],
+				"token_type": "Bearer",
+				"exp": ` + jsonInt(inOneHour.Unix()) + `,
+				"iat": ` + jsonInt(requestedAt.Unix()) + `,
+				"sub": "some-subject",
+				"iss": "https://my-downstream-issuer.com/some-path"
+			}`,
+		},
+		{
+			name:       "active access token which was not granted the username and groups scopes",
+			requester:  makeRequester(map[string]interface{}{"azp": dynamicClientID}, []string{"openid"}, inOneHour),
+			wantStatus: http.StatusOK,
+			wantBodyJSON: `{
+				"active": true,
+				"scope": "openid",
+				"client_id": "client.oauth.pinniped.dev-test-name",
+				"token_type": "Bearer",
+				"exp": ` + jsonInt(inOneHour.Unix()) + `,
+				"iat": ` + jsonInt(requestedAt.Unix()) + `,
+				"sub": "some-subject",
+				"iss": "https://my-downstream-issuer.com/some-path"
+			}`,
+		},
+		{
+			name:       "active access token of a client credentials grant",
+			requester:  clientCredentialsRequester,
+			wantStatus: http.StatusOK,
+			wantBodyJSON: `{
+				"active": true,
+				"scope": "orders:read",
+				"client_id": "client.oauth.pinniped.dev-test-name",
+				"token_type": "Bearer",
+				"exp": ` + jsonInt(inOneHour.Unix()) + `,
+				"iat": ` + jsonInt(requestedAt.Unix()) + `,
+				"sub": "some-subject",
+				"aud": ["https://orders.example.com"],
+				"iss": "https://my-downstream-issuer.com/some-path",
+				"identity_type": "client"
+			}`,
+		},
+		{
+			name:         "expired access token",
+			requester:    makeRequester(happyExtra, happyScopes, time.Now().Add(-time.Second)),
+			wantStatus:   http.StatusOK,
+			wantBodyJSON: `{"active": false}`,
+		},
+		{
+			name:            "unknown access token",
+			requester:       makeRequester(happyExtra, happyScopes, inOneHour),
+			useUnknownToken: true,
+			wantStatus:      http.StatusOK,
+			wantBodyJSON:    `{"active": false}`,
+		},
+		{
+			name:            "refresh tokens are never active",
+			requester:       makeRequester(happyExtra, happyScopes, inOneHour),
+			useRefreshToken: true,
+			wantStatus:      http.StatusOK,
+			wantBodyJSON:    `{"active": false}`,
+		},
+		{
+			name:           "client did not authenticate",
+			requester:      makeRequester(happyExtra, happyScopes, inOneHour),
+			noBasicAuth:    true,
+			wantStatus:     http.StatusUnauthorized,
+			wantErrorField: "request_unauthorized",
+		},
+		{
+			name:           "wrong client secret",
+			requester:      makeRequester(happyExtra, happyScopes, inOneHour),
+			clientSecret:   "wrong-secret",
+			wantStatus:     http.StatusUnauthorized,
+			wantErrorField: "request_unauthorized",
+		},
+		{
+			name:           "bad method",
+			method:         http.MethodGet,
+			requester:      makeRequester(happyExtra, happyScopes, inOneHour),
+			wantStatus:     http.StatusMethodNotAllowed,
+			wantBodyString: "Method Not Allowed: GET (try POST)\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			kubeClient := fake.NewSimpleClientset()
+			supervisorClient := supervisorfake.NewSimpleClientset()
+			secrets := kubeClient.CoreV1().Secrets(namespace)
+			oidcClientsClient := supervisorClient.ConfigV1alpha1().OIDCClients(namespace)
+
+			oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+				namespace, dynamicClientID, dynamicClientUID, "https://client.example.com/callback",
+				[]string{testutil.HashedPassword1AtSupervisorMinCost}, oidcclientvalidator.Validate)
+			require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+			require.NoError(t, kubeClient.Tracker().Add(secret))
+
+			// Use lower minimum required bcrypt cost than we would use in production to keep unit the tests fast.
+			oauthStore := oidc.NewKubeStorage(secrets, oidcClientsClient, oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+			oauthHelper := oidc.FositeOauth2Helper(oauthStore, downstreamIssuer, hmacSecretFunc, nil, oidc.DefaultOIDCTimeoutsConfiguration(), nil, nil)
+
+			accessToken, accessTokenSignature, err := tokenStrategy.GenerateAccessToken(ctx, test.requester)
+			require.NoError(t, err)
+			require.NoError(t, oauthStore.CreateAccessTokenSession(ctx, accessTokenSignature, test.requester))
+			refreshToken, refreshTokenSignature, err := tokenStrategy.GenerateRefreshToken(ctx, test.requester)
+			require.NoError(t, err)
+			require.NoError(t, oauthStore.CreateRefreshTokenSession(ctx, refreshTokenSignature, test.requester))
+
+			token := "pin_at_" + strings.TrimPrefix(accessToken, "ory_at_")
+			switch {
+			case test.useRefreshToken:
+				token = "pin_rt_" + strings.TrimPrefix(refreshToken, "ory_rt_")
+			case test.useUnknownToken:
+				otherToken, _, err := tokenStrategy.GenerateAccessToken(ctx, test.requester)
+				require.NoError(t, err)
+				token = "pin_at_" + strings.TrimPrefix(otherToken, "ory_at_")
+			}
+
+			method := http.MethodPost
+			if test.method != "" {
+				method = test.method
+			}
+			req := httptest.NewRequest(method, downstreamIssuer+oidc.IntrospectionEndpointPath,
+				strings.NewReader(url.Values{"token": []string{token}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if !test.noBasicAuth {
+				clientSecret := testutil.PlaintextPassword1
+				if test.clientSecret != "" {
+					clientSecret = test.clientSecret
+				}
+				req.SetBasicAuth(dynamicClientID, clientSecret)
+			}
+			rsp := httptest.NewRecorder()
+
+			NewHandler(downstreamIssuer, oauthHelper).ServeHTTP(rsp, req)
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			testutil.RequireSecurityHeadersWithoutCustomCSPs(t, rsp)
+
+			if test.wantBodyJSON != "" {
+				require.Equal(t, "application/json; charset=utf-8", rsp.Header().Get("Content-Type"))
+				require.JSONEq(t, test.wantBodyJSON, rsp.Body.String())
+			}
+			if test.wantBodyString != "" {
+				require.Equal(t, test.wantBodyString, rsp.Body.String())
+			}
+			if test.wantErrorField != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &body))
+				require.Equal(t, test.wantErrorField, body["error"])
+			}
+		})
+	}
+}
+
+func jsonInt(i int64) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
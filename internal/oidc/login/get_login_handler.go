@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -7,17 +7,22 @@ import (
 	"net/http"
 
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/i18n"
 	"go.pinniped.dev/internal/oidc/login/loginhtml"
 )
 
-const (
-	internalErrorMessage                    = "An internal error occurred. Please contact your administrator for help."
-	incorrectUsernameOrPasswordErrorMessage = "Incorrect username or password."
-)
-
-func NewGetHandler(loginPath string) HandlerFunc {
+// NewGetHandler returns the HandlerFunc which renders the login form. The branding in the brandingCache,
+// if any, is applied to each render, and the form is translated to the language of the request using the
+// translations.
+func NewGetHandler(loginPath string, brandingCache *branding.Cache, translations *i18n.Catalogs) HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request, encodedState string, decodedState *oidc.UpstreamStateParamData) error {
-		alertMessage, hasAlert := getAlert(r)
+		messages := translations.ForRequest(r)
+		alertMessage, hasAlert := getAlert(r, messages)
+
+		// The branding can change at any time, so the CSP header must match the branding of this render.
+		brand := brandingCache.Get()
+		w.Header().Set("Content-Security-Policy", brand.ContentSecurityPolicy(loginhtml.CSS()))
 
 		pageInputs := &loginhtml.PageData{
 			PostPath:      loginPath,
@@ -25,18 +30,23 @@ func NewGetHandler(loginPath string) HandlerFunc {
 			IDPName:       decodedState.UpstreamName,
 			HasAlertError: hasAlert,
 			AlertMessage:  alertMessage,
+			Branding:      brand,
+			Messages:      messages,
 		}
 		return loginhtml.Template().Execute(w, pageInputs)
 	}
 }
 
-func getAlert(r *http.Request) (string, bool) {
+func getAlert(r *http.Request, messages *i18n.Messages) (string, bool) {
 	errorParamValue := r.URL.Query().Get(errParamName)
 
-	message := internalErrorMessage
-	if errorParamValue == string(ShowBadUserPassErr) {
-		message = incorrectUsernameOrPasswordErrorMessage
+	messageID := i18n.LoginErrorInternal
+	switch errorParamValue {
+	case string(ShowBadUserPassErr):
+		messageID = i18n.LoginErrorIncorrectUsernameOrPassword
+	case string(ShowThrottledErr):
+		messageID = i18n.LoginErrorThrottled
 	}
 
-	return message, errorParamValue != ""
+	return messages.Text(messageID), errorParamValue != ""
 }
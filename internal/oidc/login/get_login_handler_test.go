@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -11,6 +11,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/i18n"
 	"go.pinniped.dev/internal/oidc/login/loginhtml"
 	"go.pinniped.dev/internal/testutil"
 )
@@ -72,6 +74,20 @@ func TestGetLogin(t *testing.T) {
 				"An internal error occurred. Please contact your administrator for help.",
 			),
 		},
+		{
+			name: "displays error banner when err=throttled_error param is sent",
+			decodedState: &oidc.UpstreamStateParamData{
+				UpstreamName: testUpstreamName,
+				UpstreamType: testUpstreamType,
+			},
+			encodedState:    testEncodedState,
+			errParam:        "throttled_error",
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBody: testutil.ExpectedLoginPageHTML(loginhtml.CSS(), testUpstreamName, testPath, testEncodedState,
+				"Too many recent login attempts. Please try again later.",
+			),
+		},
 		{
 			// If we get an error that we don't recognize, that's also an error, so we
 			// should probably just tell you to contact your administrator...
@@ -96,7 +112,7 @@ func TestGetLogin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			handler := NewGetHandler(testPath)
+			handler := NewGetHandler(testPath, nil, nil)
 			target := testPath + "?state=" + tt.encodedState
 			if tt.errParam != "" {
 				target += "&err=" + tt.errParam
@@ -108,9 +124,64 @@ func TestGetLogin(t *testing.T) {
 
 			require.Equal(t, tt.wantStatus, rsp.Code)
 			testutil.RequireEqualContentType(t, rsp.Header().Get("Content-Type"), tt.wantContentType)
+			require.Equal(t, loginhtml.ContentSecurityPolicy(), rsp.Header().Get("Content-Security-Policy"))
 			body := rsp.Body.String()
 			// t.Log("actual body:", body) // useful when updating expected values
 			require.Equal(t, tt.wantBody, body)
 		})
 	}
 }
+
+func TestGetLoginWithBranding(t *testing.T) {
+	brand := &branding.Branding{
+		CSS:        "body{background:#4d5e6f}",
+		LogoURL:    "data:image/png;base64,iVBORw0KGgo=",
+		FooterText: "some footer",
+	}
+	brandingCache := branding.NewCache()
+	handler := NewGetHandler("/some/path/login", brandingCache, nil)
+	decodedState := &oidc.UpstreamStateParamData{UpstreamName: "some-ldap-idp", UpstreamType: "ldap"}
+
+	// The branding is applied to the next render after it changes.
+	for _, b := range []*branding.Branding{brand, nil} {
+		brandingCache.Set(b)
+		req := httptest.NewRequest(http.MethodGet, "/some/path/login?state=fake-encoded-state-value", nil)
+		rsp := httptest.NewRecorder()
+		require.NoError(t, handler(rsp, req, "fake-encoded-state-value", decodedState))
+
+		require.Equal(t, http.StatusOK, rsp.Code)
+		require.Equal(t, b.ContentSecurityPolicy(loginhtml.CSS()), rsp.Header().Get("Content-Security-Policy"))
+		if b != nil {
+			require.Contains(t, rsp.Body.String(), "<style>"+loginhtml.CSS()+"body{background:#4d5e6f}</style>")
+			require.Contains(t, rsp.Body.String(), `<img class="logo" src="data:image/png;base64,iVBORw0KGgo=" alt="">`)
+			require.Contains(t, rsp.Body.String(), `<footer class="footer">some footer</footer>`)
+		} else {
+			require.Equal(t, testutil.ExpectedLoginPageHTML(loginhtml.CSS(), "some-ldap-idp", "/some/path/login", "fake-encoded-state-value", ""), rsp.Body.String())
+		}
+	}
+}
+
+func TestGetLoginInTheLanguageOfTheBrowser(t *testing.T) {
+	translations := i18n.NewCatalogs()
+	translations.SetAdditional(map[string]i18n.Catalog{
+		"fr": {
+			"login.heading": "Se connecter à {name}",
+			i18n.LoginErrorIncorrectUsernameOrPassword: "Nom d'utilisateur ou mot de passe incorrect.",
+		},
+	})
+	handler := NewGetHandler("/some/path/login", nil, translations)
+	decodedState := &oidc.UpstreamStateParamData{UpstreamName: "some-ldap-idp", UpstreamType: "ldap"}
+
+	req := httptest.NewRequest(http.MethodGet, "/some/path/login?state=fake-encoded-state-value&err=login_error", nil)
+	req.Header.Set("Accept-Language", "fr-FR, fr;q=0.9, en;q=0.8")
+	rsp := httptest.NewRecorder()
+	require.NoError(t, handler(rsp, req, "fake-encoded-state-value", decodedState))
+
+	require.Equal(t, http.StatusOK, rsp.Code)
+	body := rsp.Body.String()
+	require.Contains(t, body, `<html lang="fr">`)
+	require.Contains(t, body, "<h1>Se connecter à some-ldap-idp</h1>")
+	require.Contains(t, body, "Nom d&#39;utilisateur ou mot de passe incorrect.")
+	// The messages which the catalog does not translate are in English.
+	require.Contains(t, body, `<input type="submit" name="submit" id="submit" value="Log in"/>`)
+}
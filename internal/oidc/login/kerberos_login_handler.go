@@ -0,0 +1,143 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package login
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ory/fosite"
+
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/idtransform"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/consent"
+	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/provider/formposthtml"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+const negotiateAuthScheme = "Negotiate"
+
+// NewKerberosHandler returns a http.Handler that serves the Kerberos login endpoint. Users are redirected to this
+// endpoint from the authorization endpoint when they log in using a Kerberos IDP. It asks the browser to authenticate
+// the user using SPNEGO, which happens without any user interaction when the browser is able to get a Kerberos service
+// ticket for the Supervisor. Like the login endpoint, it checks the CSRF cookie and decodes the state param.
+func NewKerberosHandler(
+	stateDecoder oidc.Decoder,
+	cookieDecoder oidc.Decoder,
+	upstreamIDPs oidc.UpstreamIdentityProvidersLister,
+	oauthHelper fosite.OAuth2Provider,
+	identityTransforms *idtransform.TransformationPipeline,
+	consentPrompter *consent.Prompter,
+) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try GET)", r.Method)
+		}
+
+		_, decodedState, err := oidc.ReadStateParamAndValidateCSRFCookie(r, cookieDecoder, stateDecoder)
+		if err != nil {
+			plog.InfoErr("state or CSRF error", err)
+			return err
+		}
+
+		if decodedState.UpstreamType != string(idpdiscoveryv1alpha1.IDPTypeKerberos) {
+			return httperr.Newf(http.StatusBadRequest, "not a supported upstream IDP type for this endpoint: %q", decodedState.UpstreamType)
+		}
+
+		kerberosUpstream, err := oidc.FindUpstreamKerberosIDPByName(upstreamIDPs, decodedState.UpstreamName)
+		if err != nil {
+			// This shouldn't normally happen because the authorization endpoint ensured that this provider existed
+			// at that time. It would be possible in the unlikely event that the provider was deleted during the login.
+			plog.Error("error finding upstream provider", err)
+			return httperr.Wrap(http.StatusUnprocessableEntity, "error finding upstream provider", err)
+		}
+
+		negotiateToken, hasToken := negotiateTokenFromRequest(r)
+		if !hasToken {
+			// Ask the browser to authenticate the user. The browser will repeat this request with the
+			// Authorization header when it is able to get a Kerberos service ticket for this host. Otherwise,
+			// the browser will show the body of this response to the user.
+			w.Header().Set("WWW-Authenticate", negotiateAuthScheme)
+			return httperr.New(http.StatusUnauthorized,
+				"Kerberos authentication is required, but your browser did not provide a Kerberos ticket. "+
+					"Make sure that you are logged in to your Kerberos realm and that your browser is configured to "+
+					"allow Kerberos authentication for this site.")
+		}
+
+		// Get the original params that were used at the authorization endpoint.
+		downstreamAuthParams, err := url.ParseQuery(decodedState.AuthParams)
+		if err != nil {
+			// This shouldn't really happen because the authorization endpoint encoded these query params correctly.
+			plog.Error("error reading state downstream auth params", err)
+			return httperr.New(http.StatusBadRequest, "error reading state downstream auth params")
+		}
+
+		// Recreate enough of the original authorize request so we can pass it to NewAuthorizeRequest().
+		reconstitutedAuthRequest := &http.Request{Form: downstreamAuthParams}
+		authorizeRequester, err := oauthHelper.NewAuthorizeRequest(r.Context(), reconstitutedAuthRequest)
+		if err != nil {
+			// This shouldn't really happen because the authorization endpoint has already validated these params
+			// by calling NewAuthorizeRequest() itself.
+			plog.Error("error using state downstream auth params", err,
+				"fositeErr", oidc.FositeErrorForLog(err))
+			return httperr.New(http.StatusBadRequest, "error using state downstream auth params")
+		}
+
+		// Automatically grant certain scopes, but only if they were requested.
+		downstreamsession.AutoApproveScopes(authorizeRequester)
+
+		authenticateResponse, err := kerberosUpstream.AuthenticateNegotiateToken(r.Context(), negotiateToken, authorizeRequester.GetGrantedScopes())
+		if err != nil {
+			plog.WarningErr("error during upstream Kerberos authentication", err, "upstreamName", kerberosUpstream.GetName())
+			oidc.AuditLoginFailed(r, authorizeRequester, "", kerberosUpstream.GetName(), psession.ProviderTypeKerberos, "kerberos authentication failed")
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHint("Kerberos authentication failed.").WithDebug(err.Error()), false)
+			return nil
+		}
+
+		subject := downstreamsession.DownstreamKerberosSubject(authenticateResponse.User.GetUID())
+		username := authenticateResponse.User.GetName()
+		groups := authenticateResponse.User.GetGroups()
+		customSessionData := downstreamsession.MakeDownstreamKerberosCustomSessionData(kerberosUpstream, authenticateResponse)
+		username, groups, err = downstreamsession.ApplyIdentityTransforms(r.Context(), identityTransforms, customSessionData, username, groups)
+		if err != nil {
+			oidc.AuditLoginFailed(r, authorizeRequester, customSessionData.UpstreamUsername, kerberosUpstream.GetName(), psession.ProviderTypeKerberos, err.Error())
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), false,
+			)
+			return nil
+		}
+		openIDSession := downstreamsession.MakeDownstreamSession(authorizeRequester.GetID(), subject, username, groups,
+			authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, nil)
+		if prompted, err := consentPrompter.PromptIfRequired(w, r, authorizeRequester, openIDSession); err != nil || prompted {
+			return err
+		}
+		oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, false)
+
+		return nil
+	})
+
+	// Both the error and success responses of this endpoint may be the form_post html page, so allow it with CSP headers.
+	return securityheader.WrapWithCustomCSP(handler, formposthtml.ContentSecurityPolicy())
+}
+
+// negotiateTokenFromRequest returns the decoded token from the "Authorization: Negotiate <token>" request header.
+func negotiateTokenFromRequest(r *http.Request) ([]byte, bool) {
+	scheme, encodedToken, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, negotiateAuthScheme) {
+		return nil, false
+	}
+	token, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedToken))
+	if err != nil || len(token) == 0 {
+		return nil, false
+	}
+	return token, true
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package login
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/securecookie"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+func TestKerberosLoginEndpoint(t *testing.T) {
+	const (
+		downstreamIssuer              = "https://my-downstream-issuer.com/path"
+		downstreamRedirectURI         = "http://127.0.0.1/callback"
+		downstreamPinnipedCLIClientID = "pinniped-cli"
+		happyDownstreamState          = "8b-state"
+		downstreamNonce               = "some-nonce-value"
+		downstreamPKCEChallenge       = "some-challenge"
+		downstreamPKCEChallengeMethod = "S256"
+
+		happyDownstreamCSRF         = "test-csrf"
+		happyDownstreamPKCE         = "test-pkce"
+		happyDownstreamNonce        = "test-nonce"
+		happyDownstreamStateVersion = "2"
+
+		kerberosUpstreamName        = "some-kerberos-idp"
+		kerberosUpstreamType        = "kerberos"
+		kerberosUpstreamResourceUID = "kerberos-resource-uid"
+
+		happyPrincipal = "alice@EXAMPLE.COM"
+	)
+
+	happyNegotiateToken := []byte("some-negotiate-token")
+	happyAuthorizationHeader := "Negotiate " + base64.StdEncoding.EncodeToString(happyNegotiateToken)
+	happyGroups := []string{"group1", "group2"}
+
+	happyDownstreamScopesRequested := []string{"openid", "username", "groups"}
+	happyDownstreamRequestParams := url.Values{
+		"response_type":         []string{"code"},
+		"scope":                 []string{strings.Join(happyDownstreamScopesRequested, " ")},
+		"client_id":             []string{downstreamPinnipedCLIClientID},
+		"state":                 []string{happyDownstreamState},
+		"nonce":                 []string{downstreamNonce},
+		"code_challenge":        []string{downstreamPKCEChallenge},
+		"code_challenge_method": []string{downstreamPKCEChallengeMethod},
+		"redirect_uri":          []string{downstreamRedirectURI},
+	}.Encode()
+
+	happyStateCodec := securecookie.New([]byte("fake-hash-secret"), []byte("0123456789ABCDEF"))
+	happyStateCodec.SetSerializer(securecookie.JSONEncoder{})
+	happyCookieCodec := securecookie.New([]byte("fake-hash-secret2"), []byte("0123456789ABCDE2"))
+	happyCookieCodec.SetSerializer(securecookie.JSONEncoder{})
+
+	happyUpstreamStateParam := func() *oidctestutil.UpstreamStateParamBuilder {
+		return &oidctestutil.UpstreamStateParamBuilder{
+			U: kerberosUpstreamName,
+			T: kerberosUpstreamType,
+			P: happyDownstreamRequestParams,
+			N: happyDownstreamNonce,
+			C: happyDownstreamCSRF,
+			K: happyDownstreamPKCE,
+			V: happyDownstreamStateVersion,
+		}
+	}
+	happyState := happyUpstreamStateParam().Build(t, happyStateCodec)
+	happyLDAPState := happyUpstreamStateParam().WithUpstreamIDPType("ldap").Build(t, happyStateCodec)
+
+	encodedIncomingCookieCSRFValue, err := happyCookieCodec.Encode("csrf", happyDownstreamCSRF)
+	require.NoError(t, err)
+	happyCSRFCookie := "__Host-pinniped-csrf=" + encodedIncomingCookieCSRFValue
+
+	upstreamKerberosIdentityProvider := oidctestutil.TestUpstreamKerberosIdentityProvider{
+		Name:        kerberosUpstreamName,
+		ResourceUID: kerberosUpstreamResourceUID,
+		AuthenticateFunc: func(ctx context.Context, negotiateToken []byte, grantedScopes []string) (*authenticators.Response, error) {
+			if string(negotiateToken) != string(happyNegotiateToken) {
+				return nil, fmt.Errorf("unexpected negotiate token %q", negotiateToken)
+			}
+			return &authenticators.Response{
+				User: &user.DefaultInfo{Name: "alice", UID: happyPrincipal, Groups: happyGroups},
+			}, nil
+		},
+	}
+
+	erroringUpstreamKerberosIdentityProvider := oidctestutil.TestUpstreamKerberosIdentityProvider{
+		Name:        kerberosUpstreamName,
+		ResourceUID: kerberosUpstreamResourceUID,
+		AuthenticateFunc: func(ctx context.Context, negotiateToken []byte, grantedScopes []string) (*authenticators.Response, error) {
+			return nil, errors.New("some kerberos upstream auth error")
+		},
+	}
+
+	tests := []struct {
+		name                string
+		idps                *oidctestutil.UpstreamIDPListerBuilder
+		method              string
+		state               string
+		csrfCookie          string
+		authorizationHeader string
+
+		wantStatusCode            int
+		wantContentType           string
+		wantBody                  string
+		wantWWWAuthenticateHeader string
+		wantRedirectLocation      string
+		wantRedirectRegexp        string
+	}{
+		{
+			name:                "happy path",
+			idps:                oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:              http.MethodGet,
+			state:               happyState,
+			csrfCookie:          happyCSRFCookie,
+			authorizationHeader: happyAuthorizationHeader,
+			wantStatusCode:      http.StatusSeeOther,
+			wantContentType:     htmlContentType,
+			// Note that fosite puts the granted scopes as a param in the redirect URI even though the spec doesn't seem to require it
+			wantRedirectRegexp: downstreamRedirectURI + `\?code=([^&]+)&scope=openid\+username\+groups&state=` + happyDownstreamState,
+		},
+		{
+			name:                      "no Authorization header asks the browser to negotiate",
+			idps:                      oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:                    http.MethodGet,
+			state:                     happyState,
+			csrfCookie:                happyCSRFCookie,
+			wantStatusCode:            http.StatusUnauthorized,
+			wantContentType:           "text/plain; charset=utf-8",
+			wantWWWAuthenticateHeader: "Negotiate",
+			wantBody: "Unauthorized: Kerberos authentication is required, but your browser did not provide a Kerberos ticket. " +
+				"Make sure that you are logged in to your Kerberos realm and that your browser is configured to " +
+				"allow Kerberos authentication for this site.\n",
+		},
+		{
+			name:                      "Authorization header with another scheme asks the browser to negotiate",
+			idps:                      oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:                    http.MethodGet,
+			state:                     happyState,
+			csrfCookie:                happyCSRFCookie,
+			authorizationHeader:       "Basic dXNlcjpwYXNz",
+			wantStatusCode:            http.StatusUnauthorized,
+			wantContentType:           "text/plain; charset=utf-8",
+			wantWWWAuthenticateHeader: "Negotiate",
+			wantBody: "Unauthorized: Kerberos authentication is required, but your browser did not provide a Kerberos ticket. " +
+				"Make sure that you are logged in to your Kerberos realm and that your browser is configured to " +
+				"allow Kerberos authentication for this site.\n",
+		},
+		{
+			name:                "upstream authentication fails",
+			idps:                oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&erroringUpstreamKerberosIdentityProvider),
+			method:              http.MethodGet,
+			state:               happyState,
+			csrfCookie:          happyCSRFCookie,
+			authorizationHeader: happyAuthorizationHeader,
+			wantStatusCode:      http.StatusSeeOther,
+			wantContentType:     htmlContentType,
+			wantRedirectLocation: downstreamRedirectURI + "?" + url.Values{
+				"error":             []string{"access_denied"},
+				"error_description": []string{"The resource owner or authorization server denied the request. Kerberos authentication failed."},
+				"state":             []string{happyDownstreamState},
+			}.Encode(),
+		},
+		{
+			name:            "POST method is invalid",
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:          http.MethodPost,
+			state:           happyState,
+			csrfCookie:      happyCSRFCookie,
+			wantStatusCode:  http.StatusMethodNotAllowed,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Method Not Allowed: POST (try GET)\n",
+		},
+		{
+			name:            "the CSRF cookie does not exist",
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:          http.MethodGet,
+			state:           happyState,
+			wantStatusCode:  http.StatusForbidden,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Forbidden: CSRF cookie is missing\n",
+		},
+		{
+			name:            "state is for another type of upstream",
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&upstreamKerberosIdentityProvider),
+			method:          http.MethodGet,
+			state:           happyLDAPState,
+			csrfCookie:      happyCSRFCookie,
+			wantStatusCode:  http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: not a supported upstream IDP type for this endpoint: \"ldap\"\n",
+		},
+		{
+			name:            "upstream provider cannot be found",
+			idps:            oidctestutil.NewUpstreamIDPListerBuilder(),
+			method:          http.MethodGet,
+			state:           happyState,
+			csrfCookie:      happyCSRFCookie,
+			wantStatusCode:  http.StatusUnprocessableEntity,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Unprocessable Entity: error finding upstream provider\n",
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kubeClient := fake.NewSimpleClientset()
+			supervisorClient := supervisorfake.NewSimpleClientset()
+			secretsClient := kubeClient.CoreV1().Secrets("some-namespace")
+			oidcClientsClient := supervisorClient.ConfigV1alpha1().OIDCClients("some-namespace")
+
+			// Configure fosite the same way that the production code would.
+			timeoutsConfiguration := oidc.DefaultOIDCTimeoutsConfiguration()
+			kubeOauthStore := oidc.NewKubeStorage(secretsClient, oidcClientsClient, timeoutsConfiguration, bcrypt.MinCost)
+			hmacSecretFunc := func() []byte { return []byte("some secret - must have at least 32 bytes") }
+			oauthHelper := oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwks.NewDynamicJWKSProvider(), timeoutsConfiguration, nil, nil)
+
+			req := httptest.NewRequest(tt.method, oidc.PinnipedKerberosLoginPath+"?"+url.Values{"state": []string{tt.state}}.Encode(), nil)
+			if tt.csrfCookie != "" {
+				req.Header.Set("Cookie", tt.csrfCookie)
+			}
+			if tt.authorizationHeader != "" {
+				req.Header.Set("Authorization", tt.authorizationHeader)
+			}
+			rsp := httptest.NewRecorder()
+
+			subject := NewKerberosHandler(happyStateCodec, happyCookieCodec, tt.idps.Build(), oauthHelper, nil, nil)
+			subject.ServeHTTP(rsp, req)
+
+			testutil.RequireSecurityHeadersWithFormPostPageCSPs(t, rsp)
+			require.Equal(t, tt.wantStatusCode, rsp.Code)
+			testutil.RequireEqualContentType(t, rsp.Header().Get("Content-Type"), tt.wantContentType)
+			require.Equal(t, tt.wantWWWAuthenticateHeader, rsp.Header().Get("WWW-Authenticate"))
+
+			switch {
+			case tt.wantRedirectRegexp != "":
+				oidctestutil.RequireAuthCodeRegexpMatch(
+					t,
+					rsp.Header().Get("Location"),
+					tt.wantRedirectRegexp,
+					kubeClient,
+					secretsClient,
+					kubeOauthStore,
+					happyDownstreamScopesRequested,
+					"krb5://EXAMPLE.COM?sub=alice",
+					"alice",
+					happyGroups,
+					happyDownstreamScopesRequested,
+					downstreamPKCEChallenge,
+					downstreamPKCEChallengeMethod,
+					downstreamNonce,
+					downstreamPinnipedCLIClientID,
+					downstreamRedirectURI,
+					&psession.CustomSessionData{
+						Username:     "alice",
+						ProviderUID:  kerberosUpstreamResourceUID,
+						ProviderName: kerberosUpstreamName,
+						ProviderType: psession.ProviderTypeKerberos,
+						Kerberos:     &psession.KerberosSessionData{Principal: happyPrincipal},
+					},
+					map[string]interface{}{},
+				)
+			case tt.wantRedirectLocation != "":
+				require.Equal(t, tt.wantRedirectLocation, rsp.Header().Get("Location"))
+				require.Empty(t, oidctestutil.FilterClientSecretCreateActions(kubeClient.Actions()))
+			default:
+				require.Equal(t, tt.wantBody, rsp.Body.String())
+				require.Empty(t, oidctestutil.FilterClientSecretCreateActions(kubeClient.Actions()))
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -27,6 +27,7 @@ const (
 	ShowNoError        ErrorParamValue = ""
 	ShowInternalError  ErrorParamValue = "internal_error"
 	ShowBadUserPassErr ErrorParamValue = "login_error"
+	ShowThrottledErr   ErrorParamValue = "throttled_error"
 )
 
 // HandlerFunc is a function that can handle either a GET or POST request for the login endpoint.
@@ -83,6 +84,15 @@ func NewHandler(
 	return wrapSecurityHeaders(loginHandler)
 }
 
+// RateLimitedRequest is an oidc.RateLimitedRequestFunc for the login endpoint. Only the POST requests of the login
+// form are subject to the rate limits, since they carry the username and password.
+func RateLimitedRequest(r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		return "", false
+	}
+	return r.PostFormValue(usernameParamName), true
+}
+
 func wrapSecurityHeaders(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		wrapped := securityheader.WrapWithCustomCSP(handler, loginhtml.ContentSecurityPolicy())
@@ -123,3 +133,27 @@ func RedirectToLoginPage(
 
 	return nil
 }
+
+// RedirectToKerberosLoginPage redirects to the Kerberos login endpoint of the specified issuer.
+func RedirectToKerberosLoginPage(
+	r *http.Request,
+	w http.ResponseWriter,
+	downstreamIssuer string,
+	encodedStateParamValue string,
+) error {
+	loginURL, err := url.Parse(downstreamIssuer + oidc.PinnipedKerberosLoginPath)
+	if err != nil {
+		return err
+	}
+
+	q := loginURL.Query()
+	q.Set(stateParamName, encodedStateParamValue)
+	loginURL.RawQuery = q.Encode()
+
+	http.Redirect(w, r,
+		loginURL.String(),
+		http.StatusSeeOther, // match fosite and https://tools.ietf.org/id/draft-ietf-oauth-security-topics-18.html#section-4.11
+	)
+
+	return nil
+}
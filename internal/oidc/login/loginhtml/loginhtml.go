@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package loginhtml defines HTML templates used by the Supervisor.
@@ -11,6 +11,8 @@ import (
 
 	"github.com/tdewolff/minify/v2/minify"
 
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/i18n"
 	"go.pinniped.dev/internal/oidc/provider/csp"
 )
 
@@ -62,4 +64,10 @@ type PageData struct {
 	AlertMessage  string
 	MinifiedCSS   template.CSS
 	PostPath      string
+
+	// Branding customizes the look of the page. Optional.
+	Branding *branding.Branding
+
+	// Messages translate the text of the page. Optional, defaults to English.
+	Messages *i18n.Messages
 }
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package loginhtml
@@ -6,8 +6,12 @@ package loginhtml
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/i18n"
 	"go.pinniped.dev/internal/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -54,6 +58,54 @@ func TestTemplate(t *testing.T) {
 	require.Equal(t, expectedHTMLWithoutAlert, buf.String())
 }
 
+func TestTemplateWithBranding(t *testing.T) {
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		PostPath: "test-post-path",
+		State:    "test-encoded-state",
+		IDPName:  "test-idp-name",
+		Branding: &branding.Branding{
+			CSS:        "body{background:#4d5e6f}",
+			LogoURL:    "data:image/png;base64,iVBORw0KGgo=",
+			FooterText: "Contact the <help desk>",
+		},
+	}
+
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	require.Contains(t, buf.String(), "<style>"+testExpectedCSS+"body{background:#4d5e6f}</style>")
+	require.Contains(t, buf.String(), `<div class="box" aria-label="login form" role="main"><img class="logo" src="data:image/png;base64,iVBORw0KGgo=" alt="">`+"\n")
+	require.Contains(t, buf.String(), "</div><footer class=\"footer\">Contact the &lt;help desk&gt;</footer>\n</body>")
+}
+
+func TestTemplateInAnotherLanguage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "es-ES")
+
+	var buf bytes.Buffer
+	pageInputs := &PageData{
+		PostPath:      "test-post-path",
+		State:         "test-encoded-state",
+		IDPName:       "test-idp-name",
+		HasAlertError: true,
+		AlertMessage:  "test-alert-message",
+		Messages:      i18n.NewCatalogs().ForRequest(req),
+	}
+
+	require.NoError(t, Template().Execute(&buf, pageInputs))
+	for _, want := range []string{
+		`<html lang="es">`,
+		`<title>Inicio de sesión de Pinniped</title>`,
+		`<div class="box" aria-label="formulario de inicio de sesión" role="main">`,
+		`<h1>Iniciar sesión en test-idp-name</h1>`,
+		`aria-label="mensaje de error de inicio de sesión" id="alert">test-alert-message</span>`,
+		`<span class="hidden" aria-hidden="true">Nombre de usuario</span>`,
+		`placeholder="Contraseña" required>`,
+		`<input type="submit" name="submit" id="submit" value="Iniciar sesión"/>`,
+	} {
+		require.Contains(t, buf.String(), want)
+	}
+}
+
 func TestContentSecurityPolicy(t *testing.T) {
 	require.Equal(t, testExpectedCSP, ContentSecurityPolicy())
 }
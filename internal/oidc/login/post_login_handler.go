@@ -1,21 +1,31 @@
-// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
 
 import (
+	"errors"
 	"net/http"
 	"net/url"
 
 	"github.com/ory/fosite"
 
+	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/consent"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/plog"
 )
 
-func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvidersLister, oauthHelper fosite.OAuth2Provider) HandlerFunc {
+func NewPostHandler(
+	issuerURL string,
+	upstreamIDPs oidc.UpstreamIdentityProvidersLister,
+	oauthHelper fosite.OAuth2Provider,
+	identityTransforms *idtransform.TransformationPipeline,
+	consentPrompter *consent.Prompter,
+) HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request, encodedState string, decodedState *oidc.UpstreamStateParamData) error {
 		// Note that the login handler prevents this handler from being called with OIDC upstreams.
 		_, ldapUpstream, idpType, err := oidc.FindUpstreamIDPByNameAndType(upstreamIDPs, decodedState.UpstreamName, decodedState.UpstreamType)
@@ -62,7 +72,13 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 		}
 
 		// Attempt to authenticate the user with the upstream IDP.
-		authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(r.Context(), username, password, authorizeRequester.GetGrantedScopes())
+		authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(oidc.AuthenticationContext(r), username, password, authorizeRequester.GetGrantedScopes())
+		if errors.Is(err, authenticators.ErrThrottled) {
+			oidc.AuditLoginFailed(r, authorizeRequester, username, ldapUpstream.GetName(), idpType, "too many recent authentication attempts")
+			// The upstream was not asked to authenticate the user because there were too many recent attempts.
+			// The user may try to log in again later, so redirect back to the login page with an error.
+			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowThrottledErr)
+		}
 		if err != nil {
 			plog.WarningErr("unexpected error during upstream LDAP authentication", err, "upstreamName", ldapUpstream.GetName())
 			// There was some problem during authentication with the upstream, aside from bad username/password.
@@ -70,6 +86,7 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
 		}
 		if !authenticated {
+			oidc.AuditLoginFailed(r, authorizeRequester, username, ldapUpstream.GetName(), idpType, "username/password not accepted by upstream")
 			// The upstream did not accept the username/password combination.
 			// The user may try to log in again if they'd like, so redirect back to the login page with an error.
 			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowBadUserPassErr)
@@ -83,8 +100,19 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 		username = authenticateResponse.User.GetName()
 		groups := authenticateResponse.User.GetGroups()
 		customSessionData := downstreamsession.MakeDownstreamLDAPOrADCustomSessionData(ldapUpstream, idpType, authenticateResponse, username)
-		openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
-			authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, map[string]interface{}{})
+		username, groups, err = downstreamsession.ApplyIdentityTransforms(r.Context(), identityTransforms, customSessionData, username, groups)
+		if err != nil {
+			oidc.AuditLoginFailed(r, authorizeRequester, customSessionData.UpstreamUsername, ldapUpstream.GetName(), idpType, err.Error())
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), false,
+			)
+			return nil
+		}
+		openIDSession := downstreamsession.MakeDownstreamSession(authorizeRequester.GetID(), subject, username, groups,
+			authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, authenticateResponse.AdditionalClaims)
+		if prompted, err := consentPrompter.PromptIfRequired(w, r, authorizeRequester, openIDSession); err != nil || prompted {
+			return err
+		}
 		oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, false)
 
 		return nil
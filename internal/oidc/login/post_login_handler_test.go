@@ -1,4 +1,4 @@
-// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -20,6 +20,7 @@ import (
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
 	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/jwks"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
@@ -60,6 +61,7 @@ func TestPostLoginEndpoint(t *testing.T) {
 		passParam                = "password"
 		badUserPassErrParamValue = "login_error"
 		internalErrParamValue    = "internal_error"
+		throttledErrParamValue   = "throttled_error"
 	)
 
 	var (
@@ -193,6 +195,18 @@ func TestPostLoginEndpoint(t *testing.T) {
 		},
 	}
 
+	throttledUpstreamLDAPIdentityProvider := oidctestutil.TestUpstreamLDAPIdentityProvider{
+		Name:        ldapUpstreamName,
+		ResourceUID: ldapUpstreamResourceUID,
+		AuthenticateFunc: func(ctx context.Context, username, password string) (*authenticators.Response, bool, error) {
+			// The IP address of the client is made available to the authenticator.
+			if clientIP := authenticators.ClientIPFromContext(ctx); clientIP != "192.0.2.1" {
+				return nil, false, fmt.Errorf("unexpected client IP %q", clientIP)
+			}
+			return nil, false, fmt.Errorf("%w: some reason", authenticators.ErrThrottled)
+		},
+	}
+
 	expectedHappyActiveDirectoryUpstreamCustomSession := &psession.CustomSessionData{
 		Username:     happyLDAPUsernameFromAuthenticator,
 		ProviderUID:  activeDirectoryUpstreamResourceUID,
@@ -254,6 +268,7 @@ func TestPostLoginEndpoint(t *testing.T) {
 		decodedState  *oidc.UpstreamStateParamData
 		formParams    url.Values
 		reqURIQuery   url.Values
+		transforms    []idtransform.Expression
 
 		wantStatus      int
 		wantContentType string
@@ -381,6 +396,55 @@ func TestPostLoginEndpoint(t *testing.T) {
 			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
 			wantDownstreamCustomSessionData:   expectedHappyActiveDirectoryUpstreamCustomSession,
 		},
+		{
+			name: "happy LDAP login with identity transforms",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			transforms: []idtransform.Expression{
+				{Type: idtransform.UsernameTransformation, Expression: `"ldap:" + username`},
+				{Type: idtransform.GroupsTransformation, Expression: `groups.filter(g, g != "group2")`},
+			},
+			decodedState:                      happyLDAPDecodedState,
+			formParams:                        happyUsernamePasswordFormParams,
+			wantStatus:                        http.StatusSeeOther,
+			wantContentType:                   htmlContentType,
+			wantBodyString:                    "",
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     "ldap:" + happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       []string{"group1", "group3"},
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamClient:              downstreamPinnipedCLIClientID,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData: func() *psession.CustomSessionData {
+				copyOfCustomSession := *expectedHappyLDAPUpstreamCustomSession
+				copyOfCustomSession.Username = "ldap:" + happyLDAPUsernameFromAuthenticator
+				copyOfCustomSession.UpstreamUsername = happyLDAPUsernameFromAuthenticator
+				copyOfCustomSession.UpstreamGroups = happyLDAPGroups
+				return &copyOfCustomSession
+			}(),
+		},
+		{
+			name: "LDAP login rejected by identity policy",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			transforms: []idtransform.Expression{
+				{Type: idtransform.PolicyTransformation, Expression: `!("group2" in groups)`, Message: "members of group2 may not log in"},
+			},
+			decodedState:    happyLDAPDecodedState,
+			formParams:      happyUsernamePasswordFormParams,
+			wantStatus:      http.StatusSeeOther,
+			wantContentType: htmlContentType,
+			wantBodyString:  "",
+			wantRedirectLocationString: urlWithQuery(downstreamRedirectURI, map[string]string{
+				"error": "access_denied",
+				"error_description": "The resource owner or authorization server denied the request. " +
+					"Reason: configured identity policy rejected this authentication: members of group2 may not log in.",
+				"state": happyDownstreamState,
+			}),
+		},
 		{
 			name: "happy LDAP login when downstream response_mode=form_post returns 200 with HTML+JS form",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
@@ -695,6 +759,16 @@ func TestPostLoginEndpoint(t *testing.T) {
 			wantBodyString:               "",
 			wantRedirectToLoginPageError: internalErrParamValue,
 		},
+		{
+			name:                         "upstream LDAP authentication is throttled",
+			idps:                         oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&throttledUpstreamLDAPIdentityProvider),
+			decodedState:                 happyLDAPDecodedState,
+			formParams:                   happyUsernamePasswordFormParams,
+			wantStatus:                   http.StatusSeeOther,
+			wantContentType:              htmlContentType,
+			wantBodyString:               "",
+			wantRedirectToLoginPageError: throttledErrParamValue,
+		},
 		{
 			name: "downstream redirect uri does not match what is configured for client",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
@@ -978,7 +1052,7 @@ func TestPostLoginEndpoint(t *testing.T) {
 			hmacSecretFunc := func() []byte { return []byte("some secret - must have at least 32 bytes") }
 			require.GreaterOrEqual(t, len(hmacSecretFunc()), 32, "fosite requires that hmac secrets have at least 32 bytes")
 			jwksProviderIsUnused := jwks.NewDynamicJWKSProvider()
-			oauthHelper := oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration)
+			oauthHelper := oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration, nil, nil)
 
 			req := httptest.NewRequest(http.MethodPost, "/ignored", strings.NewReader(tt.formParams.Encode()))
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -988,9 +1062,12 @@ func TestPostLoginEndpoint(t *testing.T) {
 
 			rsp := httptest.NewRecorder()
 
-			subject := NewPostHandler(downstreamIssuer, tt.idps.Build(), oauthHelper)
+			identityTransforms, err := idtransform.NewTransformationPipeline(tt.transforms)
+			require.NoError(t, err)
+
+			subject := NewPostHandler(downstreamIssuer, tt.idps.Build(), oauthHelper, identityTransforms, nil)
 
-			err := subject(rsp, req, happyEncodedUpstreamState, tt.decodedState)
+			err = subject(rsp, req, happyEncodedUpstreamState, tt.decodedState)
 			if tt.wantErr != "" {
 				require.EqualError(t, err, tt.wantErr)
 				require.Empty(t, oidctestutil.FilterClientSecretCreateActions(kubeClient.Actions()))
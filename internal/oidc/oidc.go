@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package oidc contains common OIDC functionality needed by Pinniped.
@@ -17,10 +17,15 @@ import (
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
 	errorsx "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/claimmappings"
 	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/oidc/csrftoken"
 	"go.pinniped.dev/internal/oidc/jwks"
 	"go.pinniped.dev/internal/oidc/provider"
@@ -32,13 +37,22 @@ import (
 )
 
 const (
-	WellKnownEndpointPath     = "/.well-known/openid-configuration"
-	AuthorizationEndpointPath = "/oauth2/authorize"
-	TokenEndpointPath         = "/oauth2/token" //nolint:gosec // ignore lint warning that this is a credential
-	CallbackEndpointPath      = "/callback"
-	JWKSEndpointPath          = "/jwks.json"
-	PinnipedIDPsPathV1Alpha1  = "/v1alpha1/pinniped_identity_providers"
-	PinnipedLoginPath         = "/login"
+	WellKnownEndpointPath           = "/.well-known/openid-configuration"
+	AuthorizationEndpointPath       = "/oauth2/authorize"
+	TokenEndpointPath               = "/oauth2/token" //nolint:gosec // ignore lint warning that this is a credential
+	CallbackEndpointPath            = "/callback"
+	JWKSEndpointPath                = "/jwks.json"
+	EndSessionEndpointPath          = "/oauth2/logout"
+	IntrospectionEndpointPath       = "/oauth2/introspect"
+	RevocationEndpointPath          = "/oauth2/revoke"
+	DeviceAuthorizationEndpointPath = "/oauth2/device_authorization"
+	PinnipedIDPsPathV1Alpha1        = "/v1alpha1/pinniped_identity_providers"
+	PinnipedLoginPath               = "/login"
+	PinnipedKerberosLoginPath       = "/login/kerberos"
+	PinnipedChooseIDPPath           = "/choose_identity_provider"
+	PinnipedDeviceVerificationPath  = "/device"
+	PinnipedDeviceCallbackPath      = "/device/callback"
+	PinnipedConsentPath             = "/consent"
 )
 
 const (
@@ -115,6 +129,18 @@ type TimeoutsConfiguration struct {
 	// has to come back to exchange the authcode for tokens at the token endpoint.
 	AuthorizeCodeLifespan time.Duration
 
+	// How long a device code issued by the device authorization endpoint is valid. This determines how much time the
+	// end user has to log in using a web browser on another device, before the device needs to start over.
+	DeviceCodeLifespan time.Duration
+
+	// How long the consent page waits for the user to decide whether a client may receive the scopes which it
+	// requested, after the user has logged in. After this time, the user must start over.
+	PendingConsentLifespan time.Duration
+
+	// How long the decision of a user to allow a client to receive some scopes is remembered. After this time, the
+	// user is asked again on the consent page.
+	ConsentLifespan time.Duration
+
 	// The lifetime of an downstream access token issued by the token endpoint. Access tokens should generally
 	// be fairly short-lived.
 	AccessTokenLifespan time.Duration
@@ -154,6 +180,18 @@ type TimeoutsConfiguration struct {
 	// as AuthorizeCodeLifespan to avoid any chance of the garbage collector deleting it while it is being used.
 	OIDCSessionStorageLifetime time.Duration
 
+	// DeviceCodeSessionStorageLifetime is the length of time after which the storage of a device code is allowed to be
+	// garbage collected. The storage is explicitly deleted when the device redeems the device code, so this only
+	// matters for device codes which are never redeemed. Therefore, this can be just slightly longer than the
+	// DeviceCodeLifespan, for reasons similar to the PKCESessionStorageLifetime.
+	DeviceCodeSessionStorageLifetime time.Duration
+
+	// PendingConsentSessionStorageLifetime is the length of time after which the storage of a login which waits on the
+	// consent page is allowed to be garbage collected. The storage is explicitly deleted when the user decides, so this
+	// only matters for logins which are abandoned on the consent page. Therefore, this can be just slightly longer than
+	// the PendingConsentLifespan, for reasons similar to the PKCESessionStorageLifetime.
+	PendingConsentSessionStorageLifetime time.Duration
+
 	// AccessTokenSessionStorageLifetime is the length of time after which an access token's session data is allowed
 	// to be garbage collected from storage.  These must exist in storage for as long as the refresh token is valid
 	// or else the refresh flow will not work properly. So this must be longer than RefreshTokenLifespan.
@@ -174,17 +212,24 @@ type TimeoutsConfiguration struct {
 func DefaultOIDCTimeoutsConfiguration() TimeoutsConfiguration {
 	accessTokenLifespan := 2 * time.Minute
 	authorizationCodeLifespan := 10 * time.Minute
+	deviceCodeLifespan := 10 * time.Minute
+	pendingConsentLifespan := 10 * time.Minute
 	refreshTokenLifespan := 9 * time.Hour
 
 	return TimeoutsConfiguration{
 		UpstreamStateParamLifespan:              90 * time.Minute,
 		AuthorizeCodeLifespan:                   authorizationCodeLifespan,
+		DeviceCodeLifespan:                      deviceCodeLifespan,
+		PendingConsentLifespan:                  pendingConsentLifespan,
+		ConsentLifespan:                         30 * 24 * time.Hour,
 		AccessTokenLifespan:                     accessTokenLifespan,
 		IDTokenLifespan:                         accessTokenLifespan,
 		RefreshTokenLifespan:                    refreshTokenLifespan,
 		AuthorizationCodeSessionStorageLifetime: authorizationCodeLifespan + refreshTokenLifespan,
 		PKCESessionStorageLifetime:              authorizationCodeLifespan + (1 * time.Minute),
 		OIDCSessionStorageLifetime:              authorizationCodeLifespan + (1 * time.Minute),
+		DeviceCodeSessionStorageLifetime:        deviceCodeLifespan + (1 * time.Minute),
+		PendingConsentSessionStorageLifetime:    pendingConsentLifespan + (1 * time.Minute),
 		AccessTokenSessionStorageLifetime:       refreshTokenLifespan + accessTokenLifespan,
 		RefreshTokenSessionStorageLifetime:      refreshTokenLifespan + accessTokenLifespan,
 	}
@@ -196,6 +241,8 @@ func FositeOauth2Helper(
 	hmacSecretOfLengthAtLeast32Func func() []byte,
 	jwksProvider jwks.DynamicJWKSProvider,
 	timeoutsConfiguration TimeoutsConfiguration,
+	tokenExchangeAudiences []string,
+	claimMappings claimmappings.Mappings,
 ) fosite.OAuth2Provider {
 	isRedirectURISecureStrict := func(_ context.Context, uri *url.URL) bool {
 		return fosite.IsRedirectURISecureStrict(uri)
@@ -237,18 +284,39 @@ func FositeOauth2Helper(
 		oauthStore,
 		&compose.CommonStrategy{
 			// Note that Fosite requires the HMAC secret to be at least 32 bytes.
-			CoreStrategy:               newDynamicOauth2HMACStrategy(oauthConfig, hmacSecretOfLengthAtLeast32Func),
-			OpenIDConnectTokenStrategy: newDynamicOpenIDConnectECDSAStrategy(oauthConfig, jwksProvider),
+			// Access tokens are opaque HMAC tokens, unless the client asked for JWT access tokens.
+			CoreStrategy: newDynamicOauth2JWTAccessTokenStrategy(
+				newDynamicOauth2HMACStrategy(oauthConfig, hmacSecretOfLengthAtLeast32Func),
+				oauthConfig,
+				jwksProvider,
+			),
+			OpenIDConnectTokenStrategy: newDynamicOpenIDConnectECDSAStrategy(oauthConfig, jwksProvider, claimMappings),
 		},
 		compose.OAuth2AuthorizeExplicitFactory,
 		compose.OAuth2RefreshTokenGrantFactory,
 		compose.OpenIDConnectExplicitFactory,
 		compose.OpenIDConnectRefreshFactory,
 		compose.OAuth2PKCEFactory,
-		TokenExchangeFactory, // handle the "urn:ietf:params:oauth:grant-type:token-exchange" grant type
+		NewTokenExchangeFactory(tokenExchangeAudiences), // handle the "urn:ietf:params:oauth:grant-type:token-exchange" grant type
+		NewClientCredentialsFactory(),                   // handle the "client_credentials" grant type
+		compose.OAuth2TokenIntrospectionFactory,
+		compose.OAuth2TokenRevocationFactory,
 	)
 
-	return oAuth2Provider
+	return &redirectURIMatchingProvider{OAuth2Provider: oAuth2Provider}
+}
+
+// redirectURIMatchingProvider passes the redirect_uri param of each authorization request to the ClientManager, so
+// the registered redirect URIs of the clients can have wildcards, see clientregistry.WithRequestedRedirectURI.
+type redirectURIMatchingProvider struct {
+	fosite.OAuth2Provider
+}
+
+func (p *redirectURIMatchingProvider) NewAuthorizeRequest(ctx context.Context, r *http.Request) (fosite.AuthorizeRequester, error) {
+	if err := r.ParseForm(); err == nil {
+		ctx = clientregistry.WithRequestedRedirectURI(ctx, r.Form.Get("redirect_uri"))
+	}
+	return p.OAuth2Provider.NewAuthorizeRequest(ctx, r)
 }
 
 // FositeErrorForLog generates a list of information about the provided Fosite error that can be
@@ -291,10 +359,25 @@ type UpstreamActiveDirectoryIdentityProviderLister interface {
 	GetActiveDirectoryIdentityProviders() []provider.UpstreamLDAPIdentityProviderI
 }
 
+type UpstreamKerberosIdentityProvidersLister interface {
+	GetKerberosIdentityProviders() []provider.UpstreamKerberosIdentityProviderI
+}
+
 type UpstreamIdentityProvidersLister interface {
 	UpstreamOIDCIdentityProvidersLister
 	UpstreamLDAPIdentityProvidersLister
 	UpstreamActiveDirectoryIdentityProviderLister
+	UpstreamKerberosIdentityProvidersLister
+}
+
+// FederationDomainIdentityProvidersListerI lists the upstream IDPs which are available for use by one
+// FederationDomain, and knows the names by which the clients of the FederationDomain refer to them.
+type FederationDomainIdentityProvidersListerI interface {
+	UpstreamIdentityProvidersLister
+
+	// GetDisplayName returns the name by which the clients of the FederationDomain refer to the upstream IDP which
+	// has the given resource UID and name.
+	GetDisplayName(resourceUID types.UID, name string) string
 }
 
 func GrantScopeIfRequested(authorizeRequester fosite.AuthorizeRequester, scopeName string) {
@@ -412,6 +495,43 @@ func FindUpstreamIDPByNameAndType(
 	return nil, nil, "", errors.New("provider not found")
 }
 
+// FindUpstreamKerberosIDPByName finds the requested Kerberos IDP by name, or returns an error.
+func FindUpstreamKerberosIDPByName(
+	idpLister UpstreamKerberosIdentityProvidersLister,
+	upstreamName string,
+) (provider.UpstreamKerberosIdentityProviderI, error) {
+	for _, p := range idpLister.GetKerberosIdentityProviders() {
+		if p.GetName() == upstreamName {
+			return p, nil
+		}
+	}
+	return nil, errors.New("provider not found")
+}
+
+// AuthenticationContext returns the context of the request with the IP address of the client added, so that an
+// upstream authenticator can limit the rate of authentication attempts from each client.
+func AuthenticationContext(r *http.Request) context.Context {
+	return authenticators.ContextWithClientIP(r.Context(), auditlog.SourceIP(r))
+}
+
+// AuditLoginFailed records in the audit log that a user could not log in using the upstream identity provider.
+// The upstreamUsername is the username which the user tried to log in with, if known.
+func AuditLoginFailed(
+	r *http.Request,
+	authorizeRequester fosite.AuthorizeRequester,
+	upstreamUsername string,
+	idpName string,
+	idpType psession.ProviderType,
+	reason string,
+) {
+	record := auditlog.NewRecord(auditlog.EventLoginFailed, r, authorizeRequester)
+	record.UpstreamUsername = upstreamUsername
+	record.IdentityProviderName = idpName
+	record.IdentityProviderType = string(idpType)
+	record.Reason = reason
+	auditlog.Log(record)
+}
+
 // WriteAuthorizeError writes an authorization error as it should be returned by the authorization endpoint and other
 // similar endpoints that are the end of the downstream authcode flow. Errors responses are written in the usual fosite style.
 func WriteAuthorizeError(r *http.Request, w http.ResponseWriter, oauthHelper fosite.OAuth2Provider, authorizeRequester fosite.AuthorizeRequester, err error, isBrowserless bool) {
@@ -451,6 +571,7 @@ func PerformAuthcodeRedirect(
 		WriteAuthorizeError(r, w, oauthHelper, authorizeRequester, err, isBrowserless)
 		return
 	}
+	auditlog.Log(auditlog.NewRecord(auditlog.EventLoginSucceeded, r, authorizeRequester))
 	if isBrowserless {
 		w = rewriteStatusSeeOtherToStatusFoundForBrowserless(w)
 	}
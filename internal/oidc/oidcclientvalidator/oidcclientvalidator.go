@@ -1,14 +1,17 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientvalidator
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
@@ -18,17 +21,36 @@ import (
 const (
 	DefaultMinBcryptCost = 12
 
-	clientSecretExists     = "ClientSecretExists"
-	allowedGrantTypesValid = "AllowedGrantTypesValid"
-	allowedScopesValid     = "AllowedScopesValid"
+	clientSecretExists       = "ClientSecretExists"
+	allowedGrantTypesValid   = "AllowedGrantTypesValid"
+	allowedRedirectURIsValid = "AllowedRedirectURIsValid"
+	allowedScopesValid       = "AllowedScopesValid"
+	clientCredentialsValid   = "ClientCredentialsValid"
+	tokenLifetimesValid      = "TokenLifetimesValid"
 
 	reasonSuccess                  = "Success"
 	reasonMissingRequiredValue     = "MissingRequiredValue"
 	reasonNoClientSecretFound      = "NoClientSecretFound"
 	reasonInvalidClientSecretFound = "InvalidClientSecretFound"
+	reasonInvalidTokenLifetimes    = "InvalidTokenLifetimes"
+	reasonInvalidRedirectURIs      = "InvalidRedirectURIs"
+	reasonInvalidClientCredentials = "InvalidClientCredentials"
 
-	allowedGrantTypesFieldName = "allowedGrantTypes"
-	allowedScopesFieldName     = "allowedScopes"
+	allowedGrantTypesFieldName   = "allowedGrantTypes"
+	allowedRedirectURIsFieldName = "allowedRedirectURIs"
+	allowedScopesFieldName       = "allowedScopes"
+	clientCredentialsFieldName   = "clientCredentials"
+	tokenLifetimesFieldName      = "tokenLifetimes"
+
+	// The bounds of the configurable token lifetimes. The maximum refresh token lifetime must not be longer than
+	// the default lifetimes of the session storage, or else the storage could be garbage collected while its
+	// refresh token is still valid.
+	minIDTokenSeconds      = 120
+	maxIDTokenSeconds      = 3600
+	minAccessTokenSeconds  = 120
+	maxAccessTokenSeconds  = 3600
+	minRefreshTokenSeconds = 600
+	maxRefreshTokenSeconds = 9 * 60 * 60
 )
 
 // Validate validates the OIDCClient and its corresponding client secret storage Secret.
@@ -37,11 +59,14 @@ const (
 // along with a slice of conditions containing more details, and the list of client secrets in the
 // case that the client was valid.
 func Validate(oidcClient *v1alpha1.OIDCClient, secret *v1.Secret, minBcryptCost int) (bool, []*v1alpha1.Condition, []string) {
-	conds := make([]*v1alpha1.Condition, 0, 3)
+	conds := make([]*v1alpha1.Condition, 0, 6)
 
 	conds, clientSecrets := validateSecret(secret, conds, minBcryptCost)
 	conds = validateAllowedGrantTypes(oidcClient, conds)
+	conds = validateAllowedRedirectURIs(oidcClient, conds)
 	conds = validateAllowedScopes(oidcClient, conds)
+	conds = validateClientCredentials(oidcClient, conds)
+	conds = validateTokenLifetimes(oidcClient, conds)
 
 	valid := true
 	for _, cond := range conds {
@@ -129,6 +154,177 @@ func validateAllowedGrantTypes(oidcClient *v1alpha1.OIDCClient, conditions []*v1
 	return conditions
 }
 
+// validateAllowedRedirectURIs checks if allowedRedirectURIs is valid on the OIDCClient. The CRD already checks the
+// schemes of the URIs, so this mostly checks the wildcards, which may only replace the leftmost label of a hostname.
+func validateAllowedRedirectURIs(oidcClient *v1alpha1.OIDCClient, conditions []*v1alpha1.Condition) []*v1alpha1.Condition {
+	m := make([]string, 0, len(oidcClient.Spec.AllowedRedirectURIs))
+
+	for _, uri := range oidcClient.Spec.AllowedRedirectURIs {
+		if problem := validateRedirectURI(string(uri)); problem != "" {
+			m = append(m, fmt.Sprintf("%q in %q %s", uri, allowedRedirectURIsFieldName, problem))
+		}
+	}
+
+	if len(m) == 0 {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    allowedRedirectURIsValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: fmt.Sprintf("%q is valid", allowedRedirectURIsFieldName),
+		})
+	} else {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    allowedRedirectURIsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidRedirectURIs,
+			Message: strings.Join(m, "; "),
+		})
+	}
+
+	return conditions
+}
+
+// validateRedirectURI returns the problem with a redirect URI, or an empty string when it can be registered.
+// Only https URIs, and http URIs on the 127.0.0.1 or ::1 loopback addresses, can be registered. The hostname of an
+// https URI may start with a "*." wildcard, which must be followed by at least two more labels, so that it cannot
+// match every hostname of a top-level domain.
+func validateRedirectURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "is not a valid URI"
+	}
+	if u.Host == "" || u.Opaque != "" || u.User != nil || u.Fragment != "" {
+		return "must be an absolute URI without user info or a fragment"
+	}
+
+	hostname := u.Hostname()
+	if u.Scheme != "https" && !(u.Scheme == "http" && (hostname == "127.0.0.1" || hostname == "::1")) {
+		return "must use the https scheme, unless its hostname is 127.0.0.1 or ::1"
+	}
+	if strings.Contains(u.Port(), "*") || strings.Contains(u.EscapedPath(), "*") || strings.Contains(u.RawQuery, "*") {
+		return "may only have a wildcard in its hostname"
+	}
+	if !strings.Contains(hostname, "*") {
+		return ""
+	}
+
+	domain := strings.TrimPrefix(hostname, "*.")
+	if u.Scheme != "https" || domain == hostname || strings.Contains(domain, "*") {
+		return "may only have a wildcard as the leftmost label of the hostname of an https URI"
+	}
+	if net.ParseIP(domain) != nil || len(validation.IsDNS1123Subdomain(domain)) > 0 {
+		return "must have a valid DNS name after the wildcard"
+	}
+	if !strings.Contains(domain, ".") {
+		return "must have at least two labels after the wildcard"
+	}
+	return ""
+}
+
+// validateClientCredentials checks if clientCredentials is valid on the OIDCClient. The access tokens which are issued
+// by client credentials grants do not represent users, so they may not have the scopes which are about users, and
+// they may not have the audiences which are reserved for the Supervisor's own clients.
+func validateClientCredentials(oidcClient *v1alpha1.OIDCClient, conditions []*v1alpha1.Condition) []*v1alpha1.Condition {
+	m := make([]string, 0, 4)
+
+	scopesFieldName := clientCredentialsFieldName + ".allowedScopes"
+	for _, scope := range oidcClient.Spec.ClientCredentials.AllowedScopes {
+		switch {
+		case scope == "" || strings.ContainsAny(scope, " \t\n\""):
+			m = append(m, fmt.Sprintf("%q in %q is not a valid scope", scope, scopesFieldName))
+		case isUserScope(scope):
+			m = append(m, fmt.Sprintf("%q must not be included in %q", scope, scopesFieldName))
+		}
+	}
+
+	audiencesFieldName := clientCredentialsFieldName + ".allowedAudiences"
+	for _, audience := range oidcClient.Spec.ClientCredentials.AllowedAudiences {
+		switch {
+		case audience == "":
+			m = append(m, fmt.Sprintf("%q in %q is not a valid audience", audience, audiencesFieldName))
+		case strings.Contains(audience, ".pinniped.dev") || audience == oidcapi.ClientIDPinnipedCLI:
+			m = append(m, fmt.Sprintf("%q in %q is a reserved audience", audience, audiencesFieldName))
+		}
+	}
+
+	if len(m) == 0 {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    clientCredentialsValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: fmt.Sprintf("%q is valid", clientCredentialsFieldName),
+		})
+	} else {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    clientCredentialsValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidClientCredentials,
+			Message: strings.Join(m, "; "),
+		})
+	}
+
+	return conditions
+}
+
+func isUserScope(scope string) bool {
+	switch scope {
+	case oidcapi.ScopeOpenID, oidcapi.ScopeOfflineAccess, oidcapi.ScopeUsername, oidcapi.ScopeGroups, oidcapi.ScopeRequestAudience:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateTokenLifetimes checks if tokenLifetimes is valid on the OIDCClient.
+func validateTokenLifetimes(oidcClient *v1alpha1.OIDCClient, conditions []*v1alpha1.Condition) []*v1alpha1.Condition {
+	m := ValidateTokenLifetimes(tokenLifetimesFieldName, oidcClient.Spec.TokenLifetimes)
+
+	if len(m) == 0 {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    tokenLifetimesValid,
+			Status:  v1alpha1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: fmt.Sprintf("%q is valid", tokenLifetimesFieldName),
+		})
+	} else {
+		conditions = append(conditions, &v1alpha1.Condition{
+			Type:    tokenLifetimesValid,
+			Status:  v1alpha1.ConditionFalse,
+			Reason:  reasonInvalidTokenLifetimes,
+			Message: strings.Join(m, "; "),
+		})
+	}
+
+	return conditions
+}
+
+// ValidateTokenLifetimes checks that each of the configured token lifetimes is within its bounds. It is shared by
+// OIDCClients and FederationDomains. It returns a message for each problem found, or an empty slice when the lifetimes
+// are valid. The messages refer to the lifetimes using the given field name as a prefix.
+func ValidateTokenLifetimes(fieldName string, lifetimes v1alpha1.TokenLifetimes) []string {
+	m := make([]string, 0, 4)
+
+	m = validateTokenLifetime(m, fieldName+".idTokenSeconds", lifetimes.IDTokenSeconds, minIDTokenSeconds, maxIDTokenSeconds)
+	m = validateTokenLifetime(m, fieldName+".accessTokenSeconds", lifetimes.AccessTokenSeconds, minAccessTokenSeconds, maxAccessTokenSeconds)
+	m = validateTokenLifetime(m, fieldName+".refreshTokenSeconds", lifetimes.RefreshTokenSeconds, minRefreshTokenSeconds, maxRefreshTokenSeconds)
+
+	if lifetimes.AccessTokenSeconds != nil && lifetimes.RefreshTokenSeconds != nil &&
+		*lifetimes.RefreshTokenSeconds < *lifetimes.AccessTokenSeconds {
+		m = append(m, fmt.Sprintf("%q must not be less than %q",
+			fieldName+".refreshTokenSeconds", fieldName+".accessTokenSeconds"))
+	}
+
+	return m
+}
+
+func validateTokenLifetime(m []string, fieldName string, seconds *int32, minSeconds, maxSeconds int32) []string {
+	if seconds != nil && (*seconds < minSeconds || *seconds > maxSeconds) {
+		m = append(m, fmt.Sprintf("%q must be between %d and %d seconds, but was %d",
+			fieldName, minSeconds, maxSeconds, *seconds))
+	}
+	return m
+}
+
 // validateSecret checks if the client secret storage Secret is valid and contains at least one client secret.
 // It returns the updated conditions slice along with the client secrets found in that case that it is valid.
 func validateSecret(secret *v1.Secret, conditions []*v1alpha1.Condition, minBcryptCost int) ([]*v1alpha1.Condition, []string) {
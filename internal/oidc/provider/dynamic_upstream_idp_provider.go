@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package provider
@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"net/url"
 	"sync"
+	"time"
 
 	"golang.org/x/oauth2"
 	"k8s.io/apimachinery/pkg/types"
 
 	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/claimexpressions"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
@@ -64,6 +66,19 @@ type UpstreamOIDCIdentityProviderI interface {
 	// GetAdditionalClaimMappings returns additional claims to be mapped from the upstream ID token.
 	GetAdditionalClaimMappings() map[string]string
 
+	// GetClaimExpressions returns the compiled expressions which compute the username, groups, and additional claims
+	// from the upstream claims. May return nil, in which case only the claim names and mappings above are used.
+	// Configured expressions take precedence over the corresponding claim names and mappings.
+	GetClaimExpressions() *claimexpressions.Expressions
+
+	// UsesPushedAuthorizationRequests returns true when the parameters of browser-based authcode requests should be
+	// sent to the provider using PushAuthorizationRequest, instead of being sent as query params of the authorization URL.
+	UsesPushedAuthorizationRequests() bool
+
+	// PushAuthorizationRequest sends the authorization request params to the provider's pushed authorization request
+	// endpoint (see https://datatracker.ietf.org/doc/html/rfc9126), and returns the request_uri which refers to them.
+	PushAuthorizationRequest(ctx context.Context, params url.Values) (string, error)
+
 	// PasswordCredentialsGrantAndValidateTokens performs upstream OIDC resource owner password credentials grant and
 	// token validation. Returns the validated raw tokens as well as the parsed claims of the ID token.
 	PasswordCredentialsGrantAndValidateTokens(ctx context.Context, username, password string) (*oidctypes.Token, error)
@@ -110,7 +125,28 @@ type UpstreamLDAPIdentityProviderI interface {
 	// UserAuthenticator adds an interface method for performing user authentication against the upstream LDAP provider.
 	authenticators.UserAuthenticator
 
-	// PerformRefresh performs a refresh against the upstream LDAP identity provider
+	// PerformRefresh performs a refresh against the upstream LDAP identity provider. It returns the user's current
+	// groups and the current values of the user's additional claims.
+	PerformRefresh(ctx context.Context, storedRefreshAttributes RefreshAttributes) (groups []string, additionalClaims map[string]interface{}, err error)
+
+	// GetGroupRefreshInterval returns the minimum amount of time between group searches during refreshes.
+	// Zero means that the groups are searched on every refresh.
+	GetGroupRefreshInterval() time.Duration
+}
+
+type UpstreamKerberosIdentityProviderI interface {
+	// GetName returns a name for this upstream provider.
+	GetName() string
+
+	// GetResourceUID returns the Kubernetes resource ID
+	GetResourceUID() types.UID
+
+	// AuthenticateNegotiateToken validates the SPNEGO token which a browser sent in its "Authorization: Negotiate"
+	// header. It returns the authenticated user, including their groups when a group search is configured.
+	AuthenticateNegotiateToken(ctx context.Context, negotiateToken []byte, grantedScopes []string) (*authenticators.Response, error)
+
+	// PerformRefresh searches for the user's current groups when a group search is configured, and otherwise
+	// returns the user's stored groups unchanged.
 	PerformRefresh(ctx context.Context, storedRefreshAttributes RefreshAttributes) (groups []string, err error)
 }
 
@@ -123,29 +159,58 @@ type RefreshAttributes struct {
 	Groups               []string
 	AdditionalAttributes map[string]string
 	GrantedScopes        []string
+
+	// LastGroupRefresh is when the user's groups were last searched, either during login or during a refresh.
+	LastGroupRefresh time.Time
+}
+
+// GroupRefreshIsDue returns true when the groups should be searched again during this refresh, because the given
+// interval has elapsed since the groups were last searched. An interval of zero means that a refresh is always due.
+func (a RefreshAttributes) GroupRefreshIsDue(interval time.Duration) bool {
+	return interval <= 0 || !time.Now().Before(a.LastGroupRefresh.Add(interval))
 }
 
 type DynamicUpstreamIDPProvider interface {
 	SetOIDCIdentityProviders(oidcIDPs []UpstreamOIDCIdentityProviderI)
+	// SetOAuth2IdentityProviders sets the upstream OAuth2 providers, which do not implement OIDC. They implement
+	// the same interface as the OIDC providers and are used by the same endpoints, so they are returned by
+	// GetOIDCIdentityProviders along with the OIDC providers.
+	SetOAuth2IdentityProviders(oauth2IDPs []UpstreamOIDCIdentityProviderI)
+	// SetGitHubIdentityProviders sets the upstream GitHub providers, which are likewise returned by
+	// GetOIDCIdentityProviders along with the OIDC providers.
+	SetGitHubIdentityProviders(githubIDPs []UpstreamOIDCIdentityProviderI)
+	// SetGitLabIdentityProviders sets the upstream GitLab providers, which are likewise returned by
+	// GetOIDCIdentityProviders along with the OIDC providers.
+	SetGitLabIdentityProviders(gitlabIDPs []UpstreamOIDCIdentityProviderI)
 	GetOIDCIdentityProviders() []UpstreamOIDCIdentityProviderI
 	SetLDAPIdentityProviders(ldapIDPs []UpstreamLDAPIdentityProviderI)
 	GetLDAPIdentityProviders() []UpstreamLDAPIdentityProviderI
 	SetActiveDirectoryIdentityProviders(adIDPs []UpstreamLDAPIdentityProviderI)
 	GetActiveDirectoryIdentityProviders() []UpstreamLDAPIdentityProviderI
+	SetKerberosIdentityProviders(kerberosIDPs []UpstreamKerberosIdentityProviderI)
+	GetKerberosIdentityProviders() []UpstreamKerberosIdentityProviderI
 }
 
 type dynamicUpstreamIDPProvider struct {
 	oidcUpstreams            []UpstreamOIDCIdentityProviderI
+	oauth2Upstreams          []UpstreamOIDCIdentityProviderI
+	githubUpstreams          []UpstreamOIDCIdentityProviderI
+	gitlabUpstreams          []UpstreamOIDCIdentityProviderI
 	ldapUpstreams            []UpstreamLDAPIdentityProviderI
 	activeDirectoryUpstreams []UpstreamLDAPIdentityProviderI
+	kerberosUpstreams        []UpstreamKerberosIdentityProviderI
 	mutex                    sync.RWMutex
 }
 
 func NewDynamicUpstreamIDPProvider() DynamicUpstreamIDPProvider {
 	return &dynamicUpstreamIDPProvider{
 		oidcUpstreams:            []UpstreamOIDCIdentityProviderI{},
+		oauth2Upstreams:          []UpstreamOIDCIdentityProviderI{},
+		githubUpstreams:          []UpstreamOIDCIdentityProviderI{},
+		gitlabUpstreams:          []UpstreamOIDCIdentityProviderI{},
 		ldapUpstreams:            []UpstreamLDAPIdentityProviderI{},
 		activeDirectoryUpstreams: []UpstreamLDAPIdentityProviderI{},
+		kerberosUpstreams:        []UpstreamKerberosIdentityProviderI{},
 	}
 }
 
@@ -155,10 +220,36 @@ func (p *dynamicUpstreamIDPProvider) SetOIDCIdentityProviders(oidcIDPs []Upstrea
 	p.oidcUpstreams = oidcIDPs
 }
 
+func (p *dynamicUpstreamIDPProvider) SetOAuth2IdentityProviders(oauth2IDPs []UpstreamOIDCIdentityProviderI) {
+	p.mutex.Lock() // acquire a write lock
+	defer p.mutex.Unlock()
+	p.oauth2Upstreams = oauth2IDPs
+}
+
+func (p *dynamicUpstreamIDPProvider) SetGitHubIdentityProviders(githubIDPs []UpstreamOIDCIdentityProviderI) {
+	p.mutex.Lock() // acquire a write lock
+	defer p.mutex.Unlock()
+	p.githubUpstreams = githubIDPs
+}
+
+func (p *dynamicUpstreamIDPProvider) SetGitLabIdentityProviders(gitlabIDPs []UpstreamOIDCIdentityProviderI) {
+	p.mutex.Lock() // acquire a write lock
+	defer p.mutex.Unlock()
+	p.gitlabUpstreams = gitlabIDPs
+}
+
 func (p *dynamicUpstreamIDPProvider) GetOIDCIdentityProviders() []UpstreamOIDCIdentityProviderI {
 	p.mutex.RLock() // acquire a read lock
 	defer p.mutex.RUnlock()
-	return p.oidcUpstreams
+	if len(p.oauth2Upstreams) == 0 && len(p.githubUpstreams) == 0 && len(p.gitlabUpstreams) == 0 {
+		return p.oidcUpstreams
+	}
+	upstreams := make([]UpstreamOIDCIdentityProviderI, 0,
+		len(p.oidcUpstreams)+len(p.oauth2Upstreams)+len(p.githubUpstreams)+len(p.gitlabUpstreams))
+	upstreams = append(upstreams, p.oidcUpstreams...)
+	upstreams = append(upstreams, p.oauth2Upstreams...)
+	upstreams = append(upstreams, p.githubUpstreams...)
+	return append(upstreams, p.gitlabUpstreams...)
 }
 
 func (p *dynamicUpstreamIDPProvider) SetLDAPIdentityProviders(ldapIDPs []UpstreamLDAPIdentityProviderI) {
@@ -185,6 +276,18 @@ func (p *dynamicUpstreamIDPProvider) GetActiveDirectoryIdentityProviders() []Ups
 	return p.activeDirectoryUpstreams
 }
 
+func (p *dynamicUpstreamIDPProvider) SetKerberosIdentityProviders(kerberosIDPs []UpstreamKerberosIdentityProviderI) {
+	p.mutex.Lock() // acquire a write lock
+	defer p.mutex.Unlock()
+	p.kerberosUpstreams = kerberosIDPs
+}
+
+func (p *dynamicUpstreamIDPProvider) GetKerberosIdentityProviders() []UpstreamKerberosIdentityProviderI {
+	p.mutex.RLock() // acquire a read lock
+	defer p.mutex.RUnlock()
+	return p.kerberosUpstreams
+}
+
 type RetryableRevocationError struct {
 	wrapped error
 }
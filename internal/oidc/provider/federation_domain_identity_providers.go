@@ -0,0 +1,111 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package provider
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// FederationDomainIdentityProvider is an upstream IDP which was listed in the spec.identityProviders of a
+// FederationDomain.
+type FederationDomainIdentityProvider struct {
+	// DisplayName is the name by which the clients of the FederationDomain refer to the upstream IDP.
+	DisplayName string
+
+	// UID is the resource UID of the upstream IDP.
+	UID types.UID
+}
+
+type upstreamIdentityProvidersGetter interface {
+	GetOIDCIdentityProviders() []UpstreamOIDCIdentityProviderI
+	GetLDAPIdentityProviders() []UpstreamLDAPIdentityProviderI
+	GetActiveDirectoryIdentityProviders() []UpstreamLDAPIdentityProviderI
+	GetKerberosIdentityProviders() []UpstreamKerberosIdentityProviderI
+}
+
+// FederationDomainIdentityProvidersLister lists the upstream IDPs which are available for use by one
+// FederationDomain, out of all the upstream IDPs in the cache.
+type FederationDomainIdentityProvidersLister struct {
+	wrapped upstreamIdentityProvidersGetter
+
+	// identityProviders is nil when the FederationDomain did not list any identity providers, in which case every
+	// upstream IDP is available, and the name of its resource is its display name.
+	identityProviders []FederationDomainIdentityProvider
+}
+
+// NewFederationDomainIdentityProvidersLister returns a lister which only lists the given identityProviders out of all
+// the upstream IDPs listed by wrapped. When identityProviders is nil, it lists all upstream IDPs listed by wrapped.
+func NewFederationDomainIdentityProvidersLister(
+	wrapped upstreamIdentityProvidersGetter,
+	identityProviders []FederationDomainIdentityProvider,
+) *FederationDomainIdentityProvidersLister {
+	return &FederationDomainIdentityProvidersLister{wrapped: wrapped, identityProviders: identityProviders}
+}
+
+// GetDisplayName returns the name by which the clients of the FederationDomain refer to the upstream IDP which has
+// the given resource UID and name, or an empty string when that upstream IDP is not available.
+func (l *FederationDomainIdentityProvidersLister) GetDisplayName(resourceUID types.UID, name string) string {
+	if l.identityProviders == nil {
+		return name
+	}
+	for _, idp := range l.identityProviders {
+		if idp.UID == resourceUID {
+			return idp.DisplayName
+		}
+	}
+	return ""
+}
+
+func (l *FederationDomainIdentityProvidersLister) isAvailable(resourceUID types.UID) bool {
+	return l.identityProviders == nil || l.GetDisplayName(resourceUID, "") != ""
+}
+
+func (l *FederationDomainIdentityProvidersLister) GetOIDCIdentityProviders() []UpstreamOIDCIdentityProviderI {
+	all := l.wrapped.GetOIDCIdentityProviders()
+	if l.identityProviders == nil {
+		return all
+	}
+	available := []UpstreamOIDCIdentityProviderI{}
+	for _, p := range all {
+		if l.isAvailable(p.GetResourceUID()) {
+			available = append(available, p)
+		}
+	}
+	return available
+}
+
+func (l *FederationDomainIdentityProvidersLister) GetLDAPIdentityProviders() []UpstreamLDAPIdentityProviderI {
+	return l.filterLDAP(l.wrapped.GetLDAPIdentityProviders())
+}
+
+func (l *FederationDomainIdentityProvidersLister) GetActiveDirectoryIdentityProviders() []UpstreamLDAPIdentityProviderI {
+	return l.filterLDAP(l.wrapped.GetActiveDirectoryIdentityProviders())
+}
+
+func (l *FederationDomainIdentityProvidersLister) filterLDAP(all []UpstreamLDAPIdentityProviderI) []UpstreamLDAPIdentityProviderI {
+	if l.identityProviders == nil {
+		return all
+	}
+	available := []UpstreamLDAPIdentityProviderI{}
+	for _, p := range all {
+		if l.isAvailable(p.GetResourceUID()) {
+			available = append(available, p)
+		}
+	}
+	return available
+}
+
+func (l *FederationDomainIdentityProvidersLister) GetKerberosIdentityProviders() []UpstreamKerberosIdentityProviderI {
+	all := l.wrapped.GetKerberosIdentityProviders()
+	if l.identityProviders == nil {
+		return all
+	}
+	available := []UpstreamKerberosIdentityProviderI{}
+	for _, p := range all {
+		if l.isAvailable(p.GetResourceUID()) {
+			available = append(available, p)
+		}
+	}
+	return available
+}
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package provider
@@ -7,20 +7,110 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
+	"go.pinniped.dev/internal/claimmappings"
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/idtransform"
 )
 
+// DefaultIDTokenSigningAlgorithm is the algorithm which is used to sign ID tokens when a FederationDomain does not
+// configure one.
+const DefaultIDTokenSigningAlgorithm = "ES256"
+
+// TokenLifetimes are the default lifetimes of the tokens issued by a FederationDomain. Zero means that the
+// Supervisor's default lifetime should be used for that type of token.
+type TokenLifetimes struct {
+	IDToken      time.Duration
+	AccessToken  time.Duration
+	RefreshToken time.Duration
+}
+
+// SessionLifetimes limit how long the downstream sessions of a FederationDomain may be refreshed. Zero means
+// that there is no such limit.
+type SessionLifetimes struct {
+	IdleTimeout time.Duration
+	MaxLifetime time.Duration
+}
+
+// CORSPolicy describes which cross-origin requests may be made to the endpoints of a FederationDomain. A policy
+// without any allowed origins does not allow any cross-origin requests.
+type CORSPolicy struct {
+	// AllowedOrigins are compared exactly to the Origin headers of the requests.
+	AllowedOrigins []string
+
+	// AllowedMethods are the HTTP methods which may be used in cross-origin requests.
+	AllowedMethods []string
+
+	// MaxAge is how long browsers may cache the responses to their preflight requests. Zero means that the
+	// browsers should use their own default.
+	MaxAge time.Duration
+}
+
 // FederationDomainIssuer represents all of the settings and state for a downstream OIDC provider
 // as defined by a FederationDomain.
 type FederationDomainIssuer struct {
 	issuer     string
 	issuerHost string
 	issuerPath string
+
+	// identityProviders are the upstream IDPs which were listed in the spec of the FederationDomain. It is nil when
+	// the FederationDomain did not list any, in which case every upstream IDP may be used.
+	identityProviders []FederationDomainIdentityProvider
+
+	// identityTransforms are applied to the identities of the users who log in using this FederationDomain.
+	identityTransforms *idtransform.TransformationPipeline
+
+	// tokenLifetimes are the default lifetimes of the tokens issued by this FederationDomain.
+	tokenLifetimes TokenLifetimes
+
+	// sessionLifetimes limit how long the downstream sessions of this FederationDomain may be refreshed.
+	sessionLifetimes SessionLifetimes
+
+	// tokenExchangeAudiences are the audiences which may be requested during token exchanges by the clients which do
+	// not configure their own. It is empty when any audience may be requested.
+	tokenExchangeAudiences []string
+
+	// claimMappings copy values from the sessions of the users into the ID tokens issued by this FederationDomain.
+	claimMappings claimmappings.Mappings
+
+	// idTokenSigningAlgorithm is the algorithm which this FederationDomain uses to sign ID tokens.
+	idTokenSigningAlgorithm string
+
+	// corsPolicy describes which cross-origin requests may be made to the endpoints of this FederationDomain.
+	corsPolicy CORSPolicy
 }
 
 func NewFederationDomainIssuer(issuer string) (*FederationDomainIssuer, error) {
-	p := FederationDomainIssuer{issuer: issuer}
+	return NewFederationDomainIssuerWithSettings(issuer, nil, nil, TokenLifetimes{}, SessionLifetimes{}, nil, nil, DefaultIDTokenSigningAlgorithm, CORSPolicy{})
+}
+
+// NewFederationDomainIssuerWithSettings is like NewFederationDomainIssuer, but also configures the upstream IDPs,
+// the identity transformations and policies, the default token lifetimes, the session lifetimes, the default
+// token exchange audiences, the claim mappings, the ID token signing algorithm, and the CORS policy of the
+// FederationDomain. Each of identityProviders, identityTransforms, tokenExchangeAudiences, and claimMappings may be nil.
+func NewFederationDomainIssuerWithSettings(
+	issuer string,
+	identityProviders []FederationDomainIdentityProvider,
+	identityTransforms *idtransform.TransformationPipeline,
+	tokenLifetimes TokenLifetimes,
+	sessionLifetimes SessionLifetimes,
+	tokenExchangeAudiences []string,
+	claimMappings claimmappings.Mappings,
+	idTokenSigningAlgorithm string,
+	corsPolicy CORSPolicy,
+) (*FederationDomainIssuer, error) {
+	p := FederationDomainIssuer{
+		issuer:                  issuer,
+		identityProviders:       identityProviders,
+		identityTransforms:      identityTransforms,
+		tokenLifetimes:          tokenLifetimes,
+		sessionLifetimes:        sessionLifetimes,
+		tokenExchangeAudiences:  tokenExchangeAudiences,
+		claimMappings:           claimMappings,
+		idTokenSigningAlgorithm: idTokenSigningAlgorithm,
+		corsPolicy:              corsPolicy,
+	}
 	err := p.validate()
 	if err != nil {
 		return nil, err
@@ -75,3 +165,49 @@ func (p *FederationDomainIssuer) IssuerHost() string {
 func (p *FederationDomainIssuer) IssuerPath() string {
 	return p.issuerPath
 }
+
+// IdentityProviders returns the upstream IDPs which were listed in the spec of the FederationDomain. The result is nil
+// when the FederationDomain did not list any, which means that every upstream IDP may be used.
+func (p *FederationDomainIssuer) IdentityProviders() []FederationDomainIdentityProvider {
+	return p.identityProviders
+}
+
+// IdentityTransforms returns the identity transformations and policies of the FederationDomain. The result may be nil,
+// which means that there are no transformations.
+func (p *FederationDomainIssuer) IdentityTransforms() *idtransform.TransformationPipeline {
+	return p.identityTransforms
+}
+
+// TokenLifetimes returns the default lifetimes of the tokens issued by this FederationDomain. Each lifetime which
+// is zero should use the Supervisor's default.
+func (p *FederationDomainIssuer) TokenLifetimes() TokenLifetimes {
+	return p.tokenLifetimes
+}
+
+// SessionLifetimes returns the limits on how long the downstream sessions of this FederationDomain may be refreshed.
+func (p *FederationDomainIssuer) SessionLifetimes() SessionLifetimes {
+	return p.sessionLifetimes
+}
+
+// TokenExchangeAudiences returns the audiences which may be requested during token exchanges by the clients which do
+// not configure their own. The result is empty when any audience may be requested.
+func (p *FederationDomainIssuer) TokenExchangeAudiences() []string {
+	return p.tokenExchangeAudiences
+}
+
+// ClaimMappings returns the mappings which copy values from the sessions of the users into the ID tokens issued by
+// this FederationDomain. The result may be nil, which means that nothing is mapped.
+func (p *FederationDomainIssuer) ClaimMappings() claimmappings.Mappings {
+	return p.claimMappings
+}
+
+// IDTokenSigningAlgorithm returns the algorithm which this FederationDomain uses to sign ID tokens, e.g. ES256.
+func (p *FederationDomainIssuer) IDTokenSigningAlgorithm() string {
+	return p.idTokenSigningAlgorithm
+}
+
+// CORSPolicy returns the policy which describes which cross-origin requests may be made to the endpoints of this
+// FederationDomain.
+func (p *FederationDomainIssuer) CORSPolicy() CORSPolicy {
+	return p.corsPolicy
+}
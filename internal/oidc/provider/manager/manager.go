@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package manager
@@ -7,24 +7,37 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	"go.pinniped.dev/internal/consentstorage"
+	"go.pinniped.dev/internal/fositestorage/devicecode"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/auth"
+	"go.pinniped.dev/internal/oidc/backchannellogout"
+	"go.pinniped.dev/internal/oidc/branding"
 	"go.pinniped.dev/internal/oidc/callback"
+	"go.pinniped.dev/internal/oidc/chooseidp"
+	"go.pinniped.dev/internal/oidc/consent"
 	"go.pinniped.dev/internal/oidc/csrftoken"
+	"go.pinniped.dev/internal/oidc/device"
 	"go.pinniped.dev/internal/oidc/discovery"
 	"go.pinniped.dev/internal/oidc/dynamiccodec"
+	"go.pinniped.dev/internal/oidc/endsession"
+	"go.pinniped.dev/internal/oidc/i18n"
 	"go.pinniped.dev/internal/oidc/idpdiscovery"
+	"go.pinniped.dev/internal/oidc/introspection"
 	"go.pinniped.dev/internal/oidc/jwks"
 	"go.pinniped.dev/internal/oidc/login"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/oidc/revocation"
 	"go.pinniped.dev/internal/oidc/token"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/secret"
+	"go.pinniped.dev/internal/tracing"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
 )
@@ -42,12 +55,24 @@ type Manager struct {
 	secretCache         *secret.Cache                        // in-memory cache of cryptographic material
 	secretsClient       corev1client.SecretInterface
 	oidcClientsClient   v1alpha1.OIDCClientInterface
+	logoutNotifier      backchannellogout.NotifierI // notifies clients when their sessions end
+	brandingCache       *branding.Cache             // in-memory cache of the branding of the login pages
+	translations        *i18n.Catalogs              // in-memory cache of the translations of the HTML pages
+
+	// Each rate limited endpoint has its own limits, which apply across all providers.
+	authorizeRateLimiter *oidc.EndpointRateLimiter
+	loginRateLimiter     *oidc.EndpointRateLimiter
+	tokenRateLimiter     *oidc.EndpointRateLimiter
 }
 
 // NewManager returns an empty Manager.
 // nextHandler will be invoked for any requests that could not be handled by this manager's providers.
 // dynamicJWKSProvider will be used as an in-memory cache for per-issuer JWKS data.
 // upstreamIDPs will be used as an in-memory cache of currently configured upstream IDPs.
+// logoutNotifier will be used to notify clients when their sessions end, and may be nil.
+// endpointRateLimits will be enforced on the endpoints which accept passwords or issue tokens.
+// brandingCache will be used as an in-memory cache of the branding of the login pages, and may be nil.
+// translations will be used as an in-memory cache of the translations of the HTML pages, and may be nil.
 func NewManager(
 	nextHandler http.Handler,
 	dynamicJWKSProvider jwks.DynamicJWKSProvider,
@@ -55,15 +80,25 @@ func NewManager(
 	secretCache *secret.Cache,
 	secretsClient corev1client.SecretInterface,
 	oidcClientsClient v1alpha1.OIDCClientInterface,
+	logoutNotifier backchannellogout.NotifierI,
+	endpointRateLimits oidc.EndpointRateLimitConfig,
+	brandingCache *branding.Cache,
+	translations *i18n.Catalogs,
 ) *Manager {
 	return &Manager{
-		providerHandlers:    make(map[string]http.Handler),
-		nextHandler:         nextHandler,
-		dynamicJWKSProvider: dynamicJWKSProvider,
-		upstreamIDPs:        upstreamIDPs,
-		secretCache:         secretCache,
-		secretsClient:       secretsClient,
-		oidcClientsClient:   oidcClientsClient,
+		providerHandlers:     make(map[string]http.Handler),
+		nextHandler:          nextHandler,
+		dynamicJWKSProvider:  dynamicJWKSProvider,
+		upstreamIDPs:         upstreamIDPs,
+		secretCache:          secretCache,
+		secretsClient:        secretsClient,
+		oidcClientsClient:    oidcClientsClient,
+		logoutNotifier:       logoutNotifier,
+		brandingCache:        brandingCache,
+		translations:         translations,
+		authorizeRateLimiter: oidc.NewEndpointRateLimiter(endpointRateLimits),
+		loginRateLimiter:     oidc.NewEndpointRateLimiter(endpointRateLimits),
+		tokenRateLimiter:     oidc.NewEndpointRateLimiter(endpointRateLimits),
 	}
 }
 
@@ -90,29 +125,58 @@ func (m *Manager) SetProviders(federationDomains ...*provider.FederationDomainIs
 
 	for _, incomingProvider := range federationDomains {
 		issuer := incomingProvider.Issuer()
+		identityTransforms := incomingProvider.IdentityTransforms()
+		// Only the upstream IDPs which are available for use by this FederationDomain may be used by its endpoints.
+		idpLister := provider.NewFederationDomainIdentityProvidersLister(m.upstreamIDPs, incomingProvider.IdentityProviders())
 		issuerHostWithPath := strings.ToLower(incomingProvider.IssuerHost()) + "/" + incomingProvider.IssuerPath()
 
 		tokenHMACKeyGetter := wrapGetter(incomingProvider.Issuer(), m.secretCache.GetTokenHMACKey)
 
 		timeoutsConfiguration := oidc.DefaultOIDCTimeoutsConfiguration()
+		// The FederationDomain may override the default token lifespans, and each OIDCClient may further override
+		// them for its own tokens. The session storage lifetimes do not need to change, because the allowed
+		// token lifespans are never longer than the defaults which were used to choose the storage lifetimes.
+		tokenLifetimes := incomingProvider.TokenLifetimes()
+		if tokenLifetimes.IDToken > 0 {
+			timeoutsConfiguration.IDTokenLifespan = tokenLifetimes.IDToken
+		}
+		if tokenLifetimes.AccessToken > 0 {
+			timeoutsConfiguration.AccessTokenLifespan = tokenLifetimes.AccessToken
+		}
+		if tokenLifetimes.RefreshToken > 0 {
+			timeoutsConfiguration.RefreshTokenLifespan = tokenLifetimes.RefreshToken
+		}
+
+		// Clients which may use the device authorization grant also accept the device callback endpoint as their
+		// redirect_uri, because the verification page starts the downstream authorization requests for them.
+		deviceCallbackURL := issuer + oidc.PinnipedDeviceCallbackPath
+		deviceCodeStorage := devicecode.New(m.secretsClient, time.Now, timeoutsConfiguration.DeviceCodeSessionStorageLifetime)
 
 		// Use NullStorage for the authorize endpoint because we do not actually want to store anything until
 		// the upstream callback endpoint is called later.
 		oauthHelperWithNullStorage := oidc.FositeOauth2Helper(
-			oidc.NewNullStorage(m.secretsClient, m.oidcClientsClient, oidcclientvalidator.DefaultMinBcryptCost),
+			device.NewDeviceCallbackStorage(
+				oidc.NewNullStorage(m.secretsClient, m.oidcClientsClient, oidcclientvalidator.DefaultMinBcryptCost),
+				deviceCallbackURL,
+			),
 			issuer,
 			tokenHMACKeyGetter,
 			nil,
 			timeoutsConfiguration,
+			incomingProvider.TokenExchangeAudiences(),
+			incomingProvider.ClaimMappings(),
 		)
 
 		// For all the other endpoints, make another oauth helper with exactly the same settings except use real storage.
+		kubeStorage := oidc.NewKubeStorage(m.secretsClient, m.oidcClientsClient, timeoutsConfiguration, oidcclientvalidator.DefaultMinBcryptCost)
 		oauthHelperWithKubeStorage := oidc.FositeOauth2Helper(
-			oidc.NewKubeStorage(m.secretsClient, m.oidcClientsClient, timeoutsConfiguration, oidcclientvalidator.DefaultMinBcryptCost),
+			device.NewDeviceCallbackStorage(kubeStorage, deviceCallbackURL),
 			issuer,
 			tokenHMACKeyGetter,
 			m.dynamicJWKSProvider,
 			timeoutsConfiguration,
+			incomingProvider.TokenExchangeAudiences(),
+			incomingProvider.ClaimMappings(),
 		)
 
 		var upstreamStateEncoder = dynamiccodec.New(
@@ -121,15 +185,38 @@ func (m *Manager) SetProviders(federationDomains ...*provider.FederationDomainIs
 			wrapGetter(incomingProvider.Issuer(), m.secretCache.GetStateEncoderBlockKey),
 		)
 
-		m.providerHandlers[(issuerHostWithPath + oidc.WellKnownEndpointPath)] = discovery.NewHandler(issuer)
+		// OIDCClients which require consent send their users to the consent page at the end of their logins.
+		consentStorage := consentstorage.New(m.secretsClient, time.Now, timeoutsConfiguration.ConsentLifespan, timeoutsConfiguration.PendingConsentSessionStorageLifetime)
+		consentPrompter := consent.NewPrompter(
+			issuer,
+			consentStorage,
+			csrfCookieEncoder,
+			device.GenerateSecret,
+			time.Now,
+			timeoutsConfiguration.PendingConsentLifespan,
+		)
 
-		m.providerHandlers[(issuerHostWithPath + oidc.JWKSEndpointPath)] = jwks.NewHandler(issuer, m.dynamicJWKSProvider)
+		// Browser-based clients of other origins may call the endpoints which they need for logins with PKCE, when
+		// the FederationDomain allows it. The authorization endpoint does not allow any request headers, so that the
+		// headers which carry passwords can never be sent to it by cross-origin requests. The token endpoint only
+		// allows the request headers which clients need to authenticate themselves and to send their forms.
+		corsPolicy := incomingProvider.CORSPolicy()
 
-		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedIDPsPathV1Alpha1)] = idpdiscovery.NewHandler(m.upstreamIDPs)
+		m.providerHandlers[(issuerHostWithPath + oidc.WellKnownEndpointPath)] = oidc.CORSHandler(
+			discovery.NewHandler(issuer, incomingProvider.IDTokenSigningAlgorithm()),
+			corsPolicy, http.MethodGet,
+		)
 
-		m.providerHandlers[(issuerHostWithPath + oidc.AuthorizationEndpointPath)] = auth.NewHandler(
+		m.providerHandlers[(issuerHostWithPath + oidc.JWKSEndpointPath)] = oidc.CORSHandler(
+			jwks.NewHandler(issuer, m.dynamicJWKSProvider),
+			corsPolicy, http.MethodGet,
+		)
+
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedIDPsPathV1Alpha1)] = idpdiscovery.NewHandler(idpLister)
+
+		m.providerHandlers[(issuerHostWithPath + oidc.AuthorizationEndpointPath)] = oidc.CORSHandler(tracing.Handler("authorize", m.authorizeRateLimiter.Handler(auth.NewHandler(
 			issuer,
-			m.upstreamIDPs,
+			idpLister,
 			oauthHelperWithNullStorage,
 			oauthHelperWithKubeStorage,
 			csrftoken.Generate,
@@ -137,26 +224,116 @@ func (m *Manager) SetProviders(federationDomains ...*provider.FederationDomainIs
 			nonce.Generate,
 			upstreamStateEncoder,
 			csrfCookieEncoder,
-		)
+			identityTransforms,
+		), auth.RateLimitedRequest)), corsPolicy, http.MethodGet)
 
-		m.providerHandlers[(issuerHostWithPath + oidc.CallbackEndpointPath)] = callback.NewHandler(
-			m.upstreamIDPs,
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedChooseIDPPath)] = tracing.Handler("choose identity provider", chooseidp.NewHandler(
+			issuer,
+			incomingProvider.IssuerPath()+oidc.PinnipedChooseIDPPath,
+			idpLister,
+			upstreamStateEncoder,
+			csrfCookieEncoder,
+			m.brandingCache,
+			m.translations,
+		))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.CallbackEndpointPath)] = tracing.Handler("callback", callback.NewHandler(
+			idpLister,
 			oauthHelperWithKubeStorage,
 			upstreamStateEncoder,
 			csrfCookieEncoder,
 			issuer+oidc.CallbackEndpointPath,
-		)
+			identityTransforms,
+			consentPrompter,
+		))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedConsentPath)] = tracing.Handler("consent", consent.NewHandler(
+			incomingProvider.IssuerPath()+oidc.PinnipedConsentPath,
+			oauthHelperWithKubeStorage,
+			consentStorage,
+			csrfCookieEncoder,
+			m.brandingCache,
+			m.translations,
+			time.Now,
+		))
 
-		m.providerHandlers[(issuerHostWithPath + oidc.TokenEndpointPath)] = token.NewHandler(
-			m.upstreamIDPs,
+		m.providerHandlers[(issuerHostWithPath + oidc.TokenEndpointPath)] = oidc.CORSHandler(tracing.Handler("token", m.tokenRateLimiter.Handler(token.NewHandler(
+			issuer,
+			idpLister,
 			oauthHelperWithKubeStorage,
+			identityTransforms,
+			incomingProvider.SessionLifetimes(),
+			m.logoutNotifier,
+			deviceCodeStorage,
+		), token.RateLimitedRequest)), corsPolicy, http.MethodPost, "Authorization", "Content-Type")
+
+		m.providerHandlers[(issuerHostWithPath + oidc.DeviceAuthorizationEndpointPath)] = tracing.Handler("device authorization", device.NewAuthorizationHandler(
+			issuer,
+			kubeStorage,
+			oauthHelperWithKubeStorage,
+			deviceCodeStorage,
+			timeoutsConfiguration.DeviceCodeLifespan,
+			device.GenerateUserCode,
+			device.GenerateSecret,
+			time.Now,
+		))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedDeviceVerificationPath)] = tracing.Handler("device verification", device.NewVerificationHandler(
+			issuer,
+			incomingProvider.IssuerPath()+oidc.PinnipedDeviceVerificationPath,
+			deviceCodeStorage,
+			csrftoken.Generate,
+			pkce.Generate,
+			nonce.Generate,
+			device.GenerateSecret,
+			csrfCookieEncoder,
+			time.Now,
+			m.translations,
+		))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedDeviceCallbackPath)] = tracing.Handler("device callback", device.NewCallbackHandler(deviceCodeStorage, m.translations))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.IntrospectionEndpointPath)] = introspection.NewHandler(
+			issuer,
+			oauthHelperWithKubeStorage,
+		)
+
+		// The revocation endpoint gets its own oauth helper, because its storage also revokes the upstream
+		// tokens of the revoked sessions, which must not happen when fosite revokes refresh tokens during refreshes.
+		m.providerHandlers[(issuerHostWithPath + oidc.RevocationEndpointPath)] = revocation.NewHandler(
+			oidc.FositeOauth2Helper(
+				revocation.NewUpstreamRevokingStorage(kubeStorage, idpLister, m.secretsClient),
+				issuer,
+				tokenHMACKeyGetter,
+				m.dynamicJWKSProvider,
+				timeoutsConfiguration,
+				incomingProvider.TokenExchangeAudiences(),
+				incomingProvider.ClaimMappings(),
+			),
 		)
 
-		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedLoginPath)] = login.NewHandler(
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedLoginPath)] = tracing.Handler("login", m.loginRateLimiter.Handler(login.NewHandler(
+			upstreamStateEncoder,
+			csrfCookieEncoder,
+			login.NewGetHandler(incomingProvider.IssuerPath()+oidc.PinnipedLoginPath, m.brandingCache, m.translations),
+			login.NewPostHandler(issuer, idpLister, oauthHelperWithKubeStorage, identityTransforms, consentPrompter),
+		), login.RateLimitedRequest))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedKerberosLoginPath)] = tracing.Handler("kerberos login", login.NewKerberosHandler(
 			upstreamStateEncoder,
 			csrfCookieEncoder,
-			login.NewGetHandler(incomingProvider.IssuerPath()+oidc.PinnipedLoginPath),
-			login.NewPostHandler(issuer, m.upstreamIDPs, oauthHelperWithKubeStorage),
+			idpLister,
+			oauthHelperWithKubeStorage,
+			identityTransforms,
+			consentPrompter,
+		))
+
+		m.providerHandlers[(issuerHostWithPath + oidc.EndSessionEndpointPath)] = endsession.NewHandler(
+			issuer,
+			m.dynamicJWKSProvider,
+			idpLister,
+			kubeStorage,
+			m.secretsClient,
 		)
 
 		plog.Debug("oidc provider manager added or updated issuer", "issuer", issuer)
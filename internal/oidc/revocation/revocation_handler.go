@@ -0,0 +1,103 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package revocation provides a handler for the token revocation endpoint, as defined by
+// https://datatracker.ietf.org/doc/html/rfc7009, along with helpers for revoking downstream sessions.
+package revocation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ory/fosite"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/fositestoragei"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/httputil/securityheader"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/plog"
+)
+
+// NewHandler returns a http.Handler that serves the token revocation endpoint.
+//
+// Clients use this endpoint to revoke their own access and refresh tokens. OIDCClients must authenticate in the
+// same way as at the token endpoint. The pinniped-cli client is public, so it only sends its client_id param, which
+// allows "pinniped logout" to end the session of the user. A client can only revoke the tokens which were issued to it.
+// Revoking either kind of token ends the whole downstream session, so all of the access and refresh tokens of the
+// session are deleted, after revoking the upstream OIDC tokens which are held by the session. The given oauthHelper
+// should use storage made by NewUpstreamRevokingStorage.
+func NewHandler(oauthHelper fosite.OAuth2Provider) http.Handler {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodPost {
+			return httperr.Newf(http.StatusMethodNotAllowed, "%s (try POST)", r.Method)
+		}
+
+		if err := r.ParseForm(); err != nil {
+			return httperr.Wrap(http.StatusBadRequest, "error parsing request params", err)
+		}
+
+		// The client IP in the context allows the storage to audit the source IP of the revocation.
+		err := oauthHelper.NewRevocationRequest(oidc.AuthenticationContext(r), r)
+		if err != nil {
+			plog.Info("revocation request error", oidc.FositeErrorForLog(err)...)
+		}
+		if errors.Is(err, fosite.ErrUnauthorizedClient) {
+			// Fosite would respond with success, but RFC 7009 requires the request to be refused when the token
+			// was issued to another client. See https://datatracker.ietf.org/doc/html/rfc7009#section-2.1.
+			oauthHelper.WriteAccessError(r.Context(), w, fosite.NewAccessRequest(nil), err)
+			return nil
+		}
+		// Per RFC 7009, this also responds with success when the token was invalid or unknown.
+		oauthHelper.WriteRevocationResponse(r.Context(), w, err)
+		return nil
+	})
+
+	return securityheader.Wrap(handler)
+}
+
+// upstreamRevokingStorage is the fosite storage of the revocation endpoint. Whenever fosite revokes a downstream
+// session, it also revokes the upstream OIDC tokens which are held by that session.
+type upstreamRevokingStorage struct {
+	fositestoragei.AllFositeStorage
+	idpLister oidc.UpstreamOIDCIdentityProvidersLister
+	secrets   corev1client.SecretInterface
+}
+
+var _ fositestoragei.AllFositeStorage = (*upstreamRevokingStorage)(nil)
+
+// NewUpstreamRevokingStorage wraps the given storage for use by the revocation endpoint. It must not be used
+// by the token endpoint, because fosite also revokes the old refresh token during every refresh grant, which
+// must not revoke the upstream tokens of the session.
+func NewUpstreamRevokingStorage(
+	storage fositestoragei.AllFositeStorage,
+	idpLister oidc.UpstreamOIDCIdentityProvidersLister,
+	secrets corev1client.SecretInterface,
+) fositestoragei.AllFositeStorage {
+	return &upstreamRevokingStorage{
+		AllFositeStorage: storage,
+		idpLister:        idpLister,
+		secrets:          secrets,
+	}
+}
+
+// RevokeRefreshToken is called by fosite for both access and refresh token revocation, before RevokeAccessToken,
+// so this is the time to revoke the upstream tokens while all the storage of the session still exists.
+func (s *upstreamRevokingStorage) RevokeRefreshToken(ctx context.Context, requestID string) error {
+	list, err := listSessionStorage(ctx, s.secrets, requestID)
+	if err != nil {
+		// Still revoke the downstream session even when the upstream tokens could not be revoked.
+		plog.WarningErr("revocation endpoint could not revoke upstream OIDC tokens", err, "requestID", requestID)
+	} else {
+		revokeUpstreamOIDCTokens(ctx, s.idpLister, list)
+	}
+
+	if err := s.AllFositeStorage.RevokeRefreshToken(ctx, requestID); err != nil {
+		return err
+	}
+
+	auditSession(ctx, auditlog.EventTokenRevoked, requestID, list)
+	return nil
+}
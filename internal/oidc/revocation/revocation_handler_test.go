@@ -0,0 +1,346 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+func TestRevocationEndpoint(t *testing.T) {
+	const (
+		downstreamIssuer = "https://my-downstream-issuer.com/some-path"
+		namespace        = "some-namespace"
+		dynamicClientID  = "client.oauth.pinniped.dev-test-name"
+		dynamicClientUID = "fake-client-uid"
+		otherClientID    = "client.oauth.pinniped.dev-other-name"
+
+		sessionID      = "fake-session-id"
+		otherSessionID = "other-session-id"
+
+		oidcUpstreamName = "some-oidc-idp"
+		oidcUpstreamUID  = types.UID("oidc-resource-uid")
+
+		upstreamRefreshToken = "fake-upstream-refresh-token"
+		upstreamAccessToken  = "fake-upstream-access-token"
+	)
+
+	hmacSecret := []byte("some secret - must have at least 32 bytes")
+	hmacSecretFunc := func() []byte { return hmacSecret }
+	tokenStrategy := compose.NewOAuth2HMACStrategy(oidc.NewDynamicGlobalSecretConfig(&fosite.Config{}, hmacSecretFunc))
+
+	makeRequester := func(requestID, clientID string, grantedScopes []string, providerType psession.ProviderType) *fosite.Request {
+		session := &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{Subject: "some-subject"},
+			Custom: &psession.CustomSessionData{
+				Username:     "some-username",
+				ProviderUID:  oidcUpstreamUID,
+				ProviderName: oidcUpstreamName,
+				ProviderType: providerType,
+				OIDC: &psession.OIDCSessionData{
+					UpstreamSubject: "some-upstream-subject",
+					UpstreamIssuer:  "https://some-upstream-issuer.com",
+				},
+			},
+		}
+		if fosite.Arguments(grantedScopes).Has("offline_access") {
+			session.Custom.OIDC.UpstreamRefreshToken = upstreamRefreshToken
+		} else {
+			session.Custom.OIDC.UpstreamAccessToken = upstreamAccessToken
+		}
+		session.SetExpiresAt(fosite.AccessToken, time.Now().Add(time.Hour))
+		session.SetExpiresAt(fosite.RefreshToken, time.Now().Add(time.Hour))
+		return &fosite.Request{
+			ID:           requestID,
+			RequestedAt:  time.Now(),
+			Client:       &clientregistry.Client{DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: clientID}}},
+			GrantedScope: grantedScopes,
+			Form:         url.Values{},
+			Session:      session,
+		}
+	}
+
+	withOfflineAccess := []string{"openid", "offline_access"}
+	withoutOfflineAccess := []string{"openid"}
+
+	happyUpstream := func() *oidctestutil.TestUpstreamOIDCIdentityProviderBuilder {
+		return oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().
+			WithName(oidcUpstreamName).
+			WithResourceUID(oidcUpstreamUID).
+			WithRevokeTokenError(nil)
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		requester       *fosite.Request
+		useRefreshToken bool
+		useUnknownToken bool
+		basicAuthSecret string
+		noBasicAuth     bool
+		formClientID    string
+
+		wantStatus                  int
+		wantBodyString              string
+		wantErrorField              string
+		wantRevokeTokenArgs         *oidctestutil.RevokeTokenArgs
+		wantRemainingSessionStorage []string
+	}{
+		{
+			name:            "revoking a refresh token ends the session and revokes the upstream refresh token",
+			requester:       makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			wantStatus:      http.StatusOK,
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+			wantRemainingSessionStorage: []string{"access-token/" + otherSessionID, "refresh-token/" + otherSessionID},
+		},
+		{
+			name:       "revoking an access token ends the session and revokes the upstream refresh token",
+			requester:  makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			wantStatus: http.StatusOK,
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+			wantRemainingSessionStorage: []string{"access-token/" + otherSessionID, "refresh-token/" + otherSessionID},
+		},
+		{
+			name:       "revoking an access token of a session without offline_access revokes the upstream access token",
+			requester:  makeRequester(sessionID, dynamicClientID, withoutOfflineAccess, psession.ProviderTypeOIDC),
+			wantStatus: http.StatusOK,
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Token:     upstreamAccessToken,
+				TokenType: provider.AccessTokenType,
+			},
+			wantRemainingSessionStorage: []string{"access-token/" + otherSessionID, "refresh-token/" + otherSessionID},
+		},
+		{
+			name:                        "session from a non-OIDC upstream does not revoke upstream tokens",
+			requester:                   makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeLDAP),
+			useRefreshToken:             true,
+			wantStatus:                  http.StatusOK,
+			wantRemainingSessionStorage: []string{"access-token/" + otherSessionID, "refresh-token/" + otherSessionID},
+		},
+		{
+			name:            "unknown tokens are also a success, as required by RFC 7009",
+			requester:       makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useUnknownToken: true,
+			wantStatus:      http.StatusOK,
+			wantRemainingSessionStorage: []string{
+				"access-token/" + sessionID, "refresh-token/" + sessionID,
+				"access-token/" + otherSessionID, "refresh-token/" + otherSessionID,
+			},
+		},
+		{
+			name:            "token which was issued to another client",
+			requester:       makeRequester(sessionID, otherClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			wantStatus:      fosite.ErrUnauthorizedClient.CodeField,
+			wantErrorField:  "unauthorized_client",
+			wantRemainingSessionStorage: []string{
+				"access-token/" + sessionID, "refresh-token/" + sessionID,
+				"access-token/" + otherSessionID, "refresh-token/" + otherSessionID,
+			},
+		},
+		{
+			name:            "wrong client secret",
+			requester:       makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			basicAuthSecret: "wrong-secret",
+			wantStatus:      fosite.ErrInvalidClient.CodeField,
+			wantErrorField:  "invalid_client",
+			wantRemainingSessionStorage: []string{
+				"access-token/" + sessionID, "refresh-token/" + sessionID,
+				"access-token/" + otherSessionID, "refresh-token/" + otherSessionID,
+			},
+		},
+		{
+			name:            "client sent its ID without authenticating",
+			requester:       makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			noBasicAuth:     true,
+			wantStatus:      fosite.ErrInvalidClient.CodeField,
+			wantErrorField:  "invalid_client",
+			wantRemainingSessionStorage: []string{
+				"access-token/" + sessionID, "refresh-token/" + sessionID,
+				"access-token/" + otherSessionID, "refresh-token/" + otherSessionID,
+			},
+		},
+		{
+			name:            "the pinniped-cli client revokes its own refresh token without authenticating",
+			requester:       makeRequester(sessionID, "pinniped-cli", withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			noBasicAuth:     true,
+			formClientID:    "pinniped-cli",
+			wantStatus:      http.StatusOK,
+			wantRevokeTokenArgs: &oidctestutil.RevokeTokenArgs{
+				Token:     upstreamRefreshToken,
+				TokenType: provider.RefreshTokenType,
+			},
+			wantRemainingSessionStorage: []string{"access-token/" + otherSessionID, "refresh-token/" + otherSessionID},
+		},
+		{
+			name:            "the pinniped-cli client cannot revoke the tokens of another client",
+			requester:       makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			noBasicAuth:     true,
+			formClientID:    "pinniped-cli",
+			wantStatus:      fosite.ErrUnauthorizedClient.CodeField,
+			wantErrorField:  "unauthorized_client",
+			wantRemainingSessionStorage: []string{
+				"access-token/" + sessionID, "refresh-token/" + sessionID,
+				"access-token/" + otherSessionID, "refresh-token/" + otherSessionID,
+			},
+		},
+		{
+			name:            "bad method",
+			method:          http.MethodGet,
+			requester:       makeRequester(sessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC),
+			useRefreshToken: true,
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantBodyString:  "Method Not Allowed: GET (try POST)\n",
+			wantRemainingSessionStorage: []string{
+				"access-token/" + sessionID, "refresh-token/" + sessionID,
+				"access-token/" + otherSessionID, "refresh-token/" + otherSessionID,
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			kubeClient := fake.NewSimpleClientset()
+			supervisorClient := supervisorfake.NewSimpleClientset()
+			secrets := kubeClient.CoreV1().Secrets(namespace)
+			oidcClientsClient := supervisorClient.ConfigV1alpha1().OIDCClients(namespace)
+
+			oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+				namespace, dynamicClientID, dynamicClientUID, "https://client.example.com/callback",
+				[]string{testutil.HashedPassword1AtSupervisorMinCost}, oidcclientvalidator.Validate)
+			require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+			require.NoError(t, kubeClient.Tracker().Add(secret))
+
+			idps := oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build())
+
+			// Use lower minimum required bcrypt cost than we would use in production to keep unit the tests fast.
+			kubeStorage := oidc.NewKubeStorage(secrets, oidcClientsClient, oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+			oauthHelper := oidc.FositeOauth2Helper(
+				NewUpstreamRevokingStorage(kubeStorage, idps.Build(), secrets),
+				downstreamIssuer, hmacSecretFunc, nil, oidc.DefaultOIDCTimeoutsConfiguration(), nil, nil,
+			)
+
+			// Refresh tokens are only handed out when offline_access was granted.
+			createTokens := func(requester *fosite.Request) (string, string) {
+				accessToken, accessTokenSignature, err := tokenStrategy.GenerateAccessToken(ctx, requester)
+				require.NoError(t, err)
+				require.NoError(t, kubeStorage.CreateAccessTokenSession(ctx, accessTokenSignature, requester))
+				if !requester.GetGrantedScopes().Has("offline_access") {
+					return "pin_at_" + strings.TrimPrefix(accessToken, "ory_at_"), ""
+				}
+				refreshToken, refreshTokenSignature, err := tokenStrategy.GenerateRefreshToken(ctx, requester)
+				require.NoError(t, err)
+				require.NoError(t, kubeStorage.CreateRefreshTokenSession(ctx, refreshTokenSignature, requester))
+				return "pin_at_" + strings.TrimPrefix(accessToken, "ory_at_"), "pin_rt_" + strings.TrimPrefix(refreshToken, "ory_rt_")
+			}
+
+			accessToken, refreshToken := createTokens(test.requester)
+			createTokens(makeRequester(otherSessionID, dynamicClientID, withOfflineAccess, psession.ProviderTypeOIDC))
+
+			token := accessToken
+			switch {
+			case test.useRefreshToken:
+				token = refreshToken
+			case test.useUnknownToken:
+				otherToken, _, err := tokenStrategy.GenerateAccessToken(ctx, test.requester)
+				require.NoError(t, err)
+				token = "pin_at_" + strings.TrimPrefix(otherToken, "ory_at_")
+			}
+
+			method := http.MethodPost
+			if test.method != "" {
+				method = test.method
+			}
+			params := url.Values{"token": []string{token}}
+			if test.noBasicAuth {
+				clientID := dynamicClientID
+				if test.formClientID != "" {
+					clientID = test.formClientID
+				}
+				params.Set("client_id", clientID)
+			}
+			req := httptest.NewRequest(method, downstreamIssuer+oidc.RevocationEndpointPath, strings.NewReader(params.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if !test.noBasicAuth {
+				clientSecret := testutil.PlaintextPassword1
+				if test.basicAuthSecret != "" {
+					clientSecret = test.basicAuthSecret
+				}
+				req.SetBasicAuth(dynamicClientID, clientSecret)
+			}
+			rsp := httptest.NewRecorder()
+
+			NewHandler(oauthHelper).ServeHTTP(rsp, req)
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			testutil.RequireSecurityHeadersWithoutCustomCSPs(t, rsp)
+
+			if test.wantBodyString != "" {
+				require.Equal(t, test.wantBodyString, rsp.Body.String())
+			}
+			if test.wantErrorField != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &body))
+				require.Equal(t, test.wantErrorField, body["error"])
+			}
+
+			if test.wantRevokeTokenArgs != nil {
+				// Fosite passes the request to the storage in the context, along with the client IP which the handler added.
+				test.wantRevokeTokenArgs.Ctx = context.WithValue(
+					authenticators.ContextWithClientIP(req.Context(), "192.0.2.1"), fosite.RequestContextKey, req,
+				)
+				idps.RequireExactlyOneCallToRevokeToken(t, oidcUpstreamName, test.wantRevokeTokenArgs)
+			} else {
+				idps.RequireExactlyZeroCallsToRevokeToken(t)
+			}
+
+			remainingSecrets, err := secrets.List(ctx, metav1.ListOptions{LabelSelector: "storage.pinniped.dev/request-id"})
+			require.NoError(t, err)
+			remainingSessionStorage := []string{}
+			for _, secret := range remainingSecrets.Items {
+				remainingSessionStorage = append(remainingSessionStorage,
+					secret.Labels["storage.pinniped.dev/type"]+"/"+secret.Labels["storage.pinniped.dev/request-id"])
+			}
+			require.ElementsMatch(t, test.wantRemainingSessionStorage, remainingSessionStorage)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ory/fosite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/fositestorage"
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+// RevokeSession ends the downstream session early by deleting all of its access and refresh tokens, which fosite
+// labels with the ID of the original authorize request. Before deleting them, it revokes the upstream OIDC tokens
+// held by the session. The revocation is audited with the client IP of the context, if any.
+func RevokeSession(
+	ctx context.Context,
+	idpLister oidc.UpstreamOIDCIdentityProvidersLister,
+	secrets corev1client.SecretInterface,
+	requestID string,
+) error {
+	list, err := listSessionStorage(ctx, secrets, requestID)
+	if err != nil {
+		return err
+	}
+
+	revokeUpstreamOIDCTokens(ctx, idpLister, list)
+
+	for _, secret := range list.Items {
+		if err := secrets.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete session storage %s: %w", secret.Name, err)
+		}
+	}
+
+	auditSession(ctx, auditlog.EventSessionRevoked, requestID, list)
+	return nil
+}
+
+// auditSession records the event of the session in the audit log, describing the session using its access or
+// refresh token storage from the list.
+func auditSession(ctx context.Context, event auditlog.Event, requestID string, list *corev1.SecretList) {
+	var requester fosite.Requester
+	if list != nil {
+		requester = findSessionRequest(list)
+	}
+	record := auditlog.NewRecord(event, nil, requester)
+	record.SessionID = requestID
+	record.SourceIP = authenticators.ClientIPFromContext(ctx)
+	auditlog.Log(record)
+}
+
+func findSessionRequest(list *corev1.SecretList) fosite.Requester {
+	for i := range list.Items {
+		secret := &list.Items[i]
+		switch secret.Labels[crud.SecretLabelKey] {
+		case accesstoken.TypeLabelValue:
+			if accessTokenSession, err := accesstoken.ReadFromSecret(secret); err == nil {
+				return accessTokenSession.Request
+			}
+		case refreshtoken.TypeLabelValue:
+			if refreshTokenSession, err := refreshtoken.ReadFromSecret(secret); err == nil {
+				return refreshTokenSession.Request
+			}
+		}
+	}
+	return nil
+}
+
+func listSessionStorage(ctx context.Context, secrets corev1client.SecretInterface, requestID string) (*corev1.SecretList, error) {
+	list, err := secrets.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{fositestorage.StorageRequestIDLabelName: requestID}.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session storage: %w", err)
+	}
+	return list, nil
+}
+
+func revokeUpstreamOIDCTokens(ctx context.Context, idpLister oidc.UpstreamOIDCIdentityProvidersLister, list *corev1.SecretList) {
+	for i := range list.Items {
+		secret := &list.Items[i]
+		if err := maybeRevokeUpstreamOIDCToken(ctx, idpLister, secret); err != nil {
+			// Keep going, so that the downstream session will be ended even when the upstream IDP cannot
+			// revoke its tokens right now.
+			plog.WarningErr("could not revoke upstream OIDC token of downstream session", err,
+				"secretName", secret.Name, "storageTypeLabelValue", secret.Labels[crud.SecretLabelKey])
+		}
+	}
+}
+
+// maybeRevokeUpstreamOIDCToken revokes the latest upstream token of the session, using the same rules as the
+// garbage collector for deciding which storage type holds the latest upstream token.
+func maybeRevokeUpstreamOIDCToken(ctx context.Context, idpLister oidc.UpstreamOIDCIdentityProvidersLister, secret *corev1.Secret) error {
+	switch secret.Labels[crud.SecretLabelKey] {
+	case accesstoken.TypeLabelValue:
+		accessTokenSession, err := accesstoken.ReadFromSecret(secret)
+		if err != nil {
+			return err
+		}
+		// When offline_access was granted, then the refresh token storage holds the latest upstream token instead.
+		if accessTokenSession.Request.GetGrantedScopes().Has(oidcapi.ScopeOfflineAccess) {
+			return nil
+		}
+		return revokeUpstreamOIDCToken(ctx, idpLister, accessTokenSession.Request.Session.(*psession.PinnipedSession).Custom)
+
+	case refreshtoken.TypeLabelValue:
+		refreshTokenSession, err := refreshtoken.ReadFromSecret(secret)
+		if err != nil {
+			return err
+		}
+		return revokeUpstreamOIDCToken(ctx, idpLister, refreshTokenSession.Request.Session.(*psession.PinnipedSession).Custom)
+
+	default:
+		return nil
+	}
+}
+
+func revokeUpstreamOIDCToken(ctx context.Context, idpLister oidc.UpstreamOIDCIdentityProvidersLister, customSessionData *psession.CustomSessionData) error {
+	// When session was for another upstream IDP type, e.g. LDAP, there is no upstream OIDC token involved.
+	if customSessionData.ProviderType != psession.ProviderTypeOIDC {
+		return nil
+	}
+
+	var foundOIDCIdentityProviderI provider.UpstreamOIDCIdentityProviderI
+	for _, p := range idpLister.GetOIDCIdentityProviders() {
+		if p.GetName() == customSessionData.ProviderName && p.GetResourceUID() == customSessionData.ProviderUID {
+			foundOIDCIdentityProviderI = p
+			break
+		}
+	}
+	if foundOIDCIdentityProviderI == nil {
+		return fmt.Errorf("could not find upstream OIDC provider named %q with resource UID %q", customSessionData.ProviderName, customSessionData.ProviderUID)
+	}
+
+	if upstreamRefreshToken := customSessionData.OIDC.UpstreamRefreshToken; upstreamRefreshToken != "" {
+		if err := foundOIDCIdentityProviderI.RevokeToken(ctx, upstreamRefreshToken, provider.RefreshTokenType); err != nil {
+			return err
+		}
+	}
+
+	if upstreamAccessToken := customSessionData.OIDC.UpstreamAccessToken; upstreamAccessToken != "" {
+		if err := foundOIDCIdentityProviderI.RevokeToken(ctx, upstreamAccessToken, provider.AccessTokenType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sessionindex indexes the storage of the downstream sessions of the Supervisor by downstream username and
+// by upstream identity provider, so that the sessions of a user or of an identity provider can be found without
+// reading every Secret.
+package sessionindex
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ory/fosite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/psession"
+)
+
+const (
+	// UsernameIndexName is the name of the index of the Secret informer which groups the session storage Secrets
+	// by the downstream username of their session.
+	UsernameIndexName = "sessionDownstreamUsername"
+
+	// IdentityProviderIndexName is the name of the index of the Secret informer which groups the session storage
+	// Secrets by the name of the upstream identity provider which was used to start their session.
+	IdentityProviderIndexName = "sessionUpstreamIdentityProviderName"
+
+	ErrNotSynced = constable.Error("session storage has not been loaded yet")
+)
+
+// Selector selects downstream sessions. A session is selected when it matches all the non-empty fields.
+type Selector struct {
+	Username             string
+	ClientID             string
+	IdentityProviderName string
+}
+
+// SelectorFromAPI converts the SessionSelector of the session API to a Selector.
+func SelectorFromAPI(selector sessionapi.SessionSelector) Selector {
+	return Selector{
+		Username:             selector.Username,
+		ClientID:             selector.ClientID,
+		IdentityProviderName: selector.IdentityProviderName,
+	}
+}
+
+// IsEmpty returns true when the Selector selects every session.
+func (s Selector) IsEmpty() bool {
+	return s == Selector{}
+}
+
+// Session describes a downstream session using the data which is held by its storage, without any of its tokens.
+type Session struct {
+	// ID is the ID of the authorize request which started the session, which is shared by all of its storage.
+	ID                   string
+	Username             string
+	UpstreamUsername     string
+	ClientID             string
+	IdentityProviderName string
+	IdentityProviderType psession.ProviderType
+	Scopes               []string
+	StartedAt            time.Time
+	// ExpiresAt is zero when the FederationDomain does not limit the lifetime of sessions.
+	ExpiresAt time.Time
+}
+
+// ToAPI converts the Session to the DownstreamSession of the session API.
+func (s Session) ToAPI() sessionapi.DownstreamSession {
+	downstreamSession := sessionapi.DownstreamSession{
+		ID:                   s.ID,
+		Username:             s.Username,
+		UpstreamUsername:     s.UpstreamUsername,
+		ClientID:             s.ClientID,
+		IdentityProviderName: s.IdentityProviderName,
+		IdentityProviderType: string(s.IdentityProviderType),
+		Scopes:               s.Scopes,
+		StartedAt:            metav1.NewTime(s.StartedAt),
+	}
+	if !s.ExpiresAt.IsZero() {
+		expiresAt := metav1.NewTime(s.ExpiresAt)
+		downstreamSession.ExpiresAt = &expiresAt
+	}
+	return downstreamSession
+}
+
+// AddIndexers adds the indexes of this package to the Secret informer. It must be called before the informer starts.
+func AddIndexers(informer cache.SharedIndexInformer) error {
+	return informer.AddIndexers(cache.Indexers{
+		UsernameIndexName:         usernameIndexFunc,
+		IdentityProviderIndexName: identityProviderIndexFunc,
+	})
+}
+
+func usernameIndexFunc(obj interface{}) ([]string, error) {
+	request, customSessionData := readSessionStorage(obj)
+	if request == nil {
+		return nil, nil
+	}
+	return []string{customSessionData.Username}, nil
+}
+
+func identityProviderIndexFunc(obj interface{}) ([]string, error) {
+	request, customSessionData := readSessionStorage(obj)
+	if request == nil {
+		return nil, nil
+	}
+	return []string{customSessionData.ProviderName}, nil
+}
+
+// Lister lists the downstream sessions of the Supervisor using the indexes of a Secret informer.
+type Lister struct {
+	informer cache.SharedIndexInformer
+}
+
+func NewLister(informer cache.SharedIndexInformer) *Lister {
+	return &Lister{informer: informer}
+}
+
+// List returns the sessions which are selected by the Selector, ordered by when they were started.
+func (l *Lister) List(selector Selector) ([]Session, error) {
+	if !l.informer.HasSynced() {
+		return nil, ErrNotSynced
+	}
+
+	secrets, err := l.listCandidates(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each session may be stored by several Secrets, e.g. by its access token and by its refresh token.
+	sessionsByID := map[string]*Session{}
+	for _, obj := range secrets {
+		request, customSessionData := readSessionStorage(obj)
+		if request == nil {
+			continue
+		}
+		session := toSession(request, customSessionData)
+		if !selected(selector, session) {
+			continue
+		}
+		if existing, ok := sessionsByID[session.ID]; ok {
+			mergeSession(existing, session)
+			continue
+		}
+		sessionsByID[session.ID] = session
+	}
+
+	sessions := make([]Session, 0, len(sessionsByID))
+	for _, session := range sessionsByID {
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartedAt.Equal(sessions[j].StartedAt) {
+			return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
+
+// listCandidates uses the most selective available index to find the Secrets which may belong to selected sessions.
+func (l *Lister) listCandidates(selector Selector) ([]interface{}, error) {
+	indexer := l.informer.GetIndexer()
+	indexers := indexer.GetIndexers()
+	if _, ok := indexers[UsernameIndexName]; ok && selector.Username != "" {
+		return indexer.ByIndex(UsernameIndexName, selector.Username)
+	}
+	if _, ok := indexers[IdentityProviderIndexName]; ok && selector.IdentityProviderName != "" {
+		return indexer.ByIndex(IdentityProviderIndexName, selector.IdentityProviderName)
+	}
+	return indexer.List(), nil
+}
+
+// readSessionStorage returns the request and the custom session data of the access token and refresh token storage
+// Secrets, which are the storage which lives as long as the session. It returns nils for any other object.
+func readSessionStorage(obj interface{}) (*fosite.Request, *psession.CustomSessionData) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, nil
+	}
+
+	var request *fosite.Request
+	switch secret.Labels[crud.SecretLabelKey] {
+	case accesstoken.TypeLabelValue:
+		session, err := accesstoken.ReadFromSecret(secret)
+		if err != nil {
+			return nil, nil
+		}
+		request = session.Request
+	case refreshtoken.TypeLabelValue:
+		session, err := refreshtoken.ReadFromSecret(secret)
+		if err != nil {
+			return nil, nil
+		}
+		request = session.Request
+	default:
+		return nil, nil
+	}
+
+	pinnipedSession, ok := request.Session.(*psession.PinnipedSession)
+	if !ok || pinnipedSession.Custom == nil {
+		return nil, nil
+	}
+	return request, pinnipedSession.Custom
+}
+
+func toSession(request *fosite.Request, customSessionData *psession.CustomSessionData) *Session {
+	session := &Session{
+		ID:                   request.GetID(),
+		Username:             customSessionData.Username,
+		UpstreamUsername:     customSessionData.UpstreamUsername,
+		IdentityProviderName: customSessionData.ProviderName,
+		IdentityProviderType: customSessionData.ProviderType,
+		Scopes:               request.GetGrantedScopes(),
+		StartedAt:            request.GetRequestedAt(),
+	}
+	if client := request.GetClient(); client != nil {
+		session.ClientID = client.GetID()
+	}
+	if customSessionData.SessionExpiresAt != nil {
+		session.ExpiresAt = customSessionData.SessionExpiresAt.Time
+	}
+	return session
+}
+
+// mergeSession combines what two storage Secrets of the same session know about the session. Refreshes move the
+// requested time and the expiration time of the newer storage forward, so the earliest start and the latest
+// expiration are kept.
+func mergeSession(existing, other *Session) {
+	if other.StartedAt.Before(existing.StartedAt) {
+		existing.StartedAt = other.StartedAt
+	}
+	if other.ExpiresAt.After(existing.ExpiresAt) {
+		existing.ExpiresAt = other.ExpiresAt
+	}
+}
+
+func selected(selector Selector, session *Session) bool {
+	return (selector.Username == "" || selector.Username == session.Username) &&
+		(selector.ClientID == "" || selector.ClientID == session.ClientID) &&
+		(selector.IdentityProviderName == "" || selector.IdentityProviderName == session.IdentityProviderName)
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package sessionindex
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/authorizationcode"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/psession"
+)
+
+const namespace = "some-namespace"
+
+func TestList(t *testing.T) {
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	refreshedAt := startedAt.Add(time.Hour)
+	expiresAt := startedAt.Add(8 * time.Hour)
+
+	request := func(id, clientID, username, idpName string, requestedAt time.Time, sessionExpiresAt *time.Time) *fosite.Request {
+		customSessionData := &psession.CustomSessionData{
+			Username:         username,
+			UpstreamUsername: "upstream-" + username,
+			ProviderUID:      types.UID(idpName + "-uid"),
+			ProviderName:     idpName,
+			ProviderType:     psession.ProviderTypeLDAP,
+			LDAP:             &psession.LDAPSessionData{},
+		}
+		if sessionExpiresAt != nil {
+			customSessionData.SessionExpiresAt = &metav1.Time{Time: *sessionExpiresAt}
+		}
+		return &fosite.Request{
+			ID:          id,
+			RequestedAt: requestedAt,
+			Client: &clientregistry.Client{
+				DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{
+					DefaultClient: &fosite.DefaultClient{ID: clientID},
+				},
+			},
+			GrantedScope: fosite.Arguments{"openid", "offline_access"},
+			Session:      &psession.PinnipedSession{Custom: customSessionData},
+		}
+	}
+
+	storageSecret := func(t *testing.T, name, typeLabelValue string, session interface{}) *corev1.Secret {
+		t.Helper()
+		sessionJSON, err := json.Marshal(session)
+		require.NoError(t, err)
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"storage.pinniped.dev/type": typeLabelValue},
+			},
+			Data: map[string][]byte{
+				"pinniped-storage-data":    sessionJSON,
+				"pinniped-storage-version": []byte("1"),
+			},
+			Type: corev1.SecretType("storage.pinniped.dev/" + typeLabelValue),
+		}
+	}
+
+	secrets := func(t *testing.T) []*corev1.Secret {
+		return []*corev1.Secret{
+			// The access token of a session which was refreshed, so its request was updated by the refresh.
+			storageSecret(t, "session-1-access-token", accesstoken.TypeLabelValue, &accesstoken.Session{
+				Version: "16",
+				Request: request("request-1", "pinniped-cli", "pinny", "some-ldap-idp", refreshedAt, &expiresAt),
+			}),
+			storageSecret(t, "session-1-refresh-token", refreshtoken.TypeLabelValue, &refreshtoken.Session{
+				Version: "16",
+				Request: request("request-1", "pinniped-cli", "pinny", "some-ldap-idp", startedAt, &expiresAt),
+			}),
+			storageSecret(t, "session-2-access-token", accesstoken.TypeLabelValue, &accesstoken.Session{
+				Version: "16",
+				Request: request("request-2", "some-client", "pinny", "other-ldap-idp", refreshedAt, nil),
+			}),
+			storageSecret(t, "session-3-refresh-token", refreshtoken.TypeLabelValue, &refreshtoken.Session{
+				Version: "16",
+				Request: request("request-3", "pinniped-cli", "someone-else", "some-ldap-idp", startedAt, nil),
+			}),
+			// Other storage types do not describe sessions which can be used or revoked.
+			storageSecret(t, "session-4-authcode", authorizationcode.TypeLabelValue, &authorizationcode.Session{
+				Version: "16",
+				Active:  true,
+				Request: request("request-4", "pinniped-cli", "pinny", "some-ldap-idp", startedAt, nil),
+			}),
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-unrelated-secret", Namespace: namespace},
+			},
+		}
+	}
+
+	session1 := Session{
+		ID:                   "request-1",
+		Username:             "pinny",
+		UpstreamUsername:     "upstream-pinny",
+		ClientID:             "pinniped-cli",
+		IdentityProviderName: "some-ldap-idp",
+		IdentityProviderType: psession.ProviderTypeLDAP,
+		Scopes:               []string{"openid", "offline_access"},
+		StartedAt:            startedAt,
+		ExpiresAt:            expiresAt.Local(), // metav1.Time reads times in the local time zone
+	}
+	session2 := Session{
+		ID:                   "request-2",
+		Username:             "pinny",
+		UpstreamUsername:     "upstream-pinny",
+		ClientID:             "some-client",
+		IdentityProviderName: "other-ldap-idp",
+		IdentityProviderType: psession.ProviderTypeLDAP,
+		Scopes:               []string{"openid", "offline_access"},
+		StartedAt:            refreshedAt,
+	}
+	session3 := Session{
+		ID:                   "request-3",
+		Username:             "someone-else",
+		UpstreamUsername:     "upstream-someone-else",
+		ClientID:             "pinniped-cli",
+		IdentityProviderName: "some-ldap-idp",
+		IdentityProviderType: psession.ProviderTypeLDAP,
+		Scopes:               []string{"openid", "offline_access"},
+		StartedAt:            startedAt,
+	}
+
+	tests := []struct {
+		name           string
+		selector       Selector
+		skipIndexers   bool
+		skipStart      bool
+		wantSessions   []Session
+		wantErr        error
+		wantIsEmptySel bool
+	}{
+		{
+			name:           "empty selector lists every session",
+			selector:       Selector{},
+			wantSessions:   []Session{session1, session3, session2},
+			wantIsEmptySel: true,
+		},
+		{
+			name:         "by username",
+			selector:     Selector{Username: "pinny"},
+			wantSessions: []Session{session1, session2},
+		},
+		{
+			name:         "by identity provider",
+			selector:     Selector{IdentityProviderName: "some-ldap-idp"},
+			wantSessions: []Session{session1, session3},
+		},
+		{
+			name:         "by client",
+			selector:     Selector{ClientID: "pinniped-cli"},
+			wantSessions: []Session{session1, session3},
+		},
+		{
+			name:         "by every field",
+			selector:     Selector{Username: "pinny", ClientID: "pinniped-cli", IdentityProviderName: "some-ldap-idp"},
+			wantSessions: []Session{session1},
+		},
+		{
+			name:         "nothing selected",
+			selector:     Selector{Username: "nobody"},
+			wantSessions: []Session{},
+		},
+		{
+			name:         "without the indexes",
+			selector:     Selector{Username: "pinny"},
+			skipIndexers: true,
+			wantSessions: []Session{session1, session2},
+		},
+		{
+			name:      "informer not synced",
+			selector:  Selector{Username: "pinny"},
+			skipStart: true,
+			wantErr:   ErrNotSynced,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset()
+			for _, secret := range secrets(t) {
+				require.NoError(t, kubeClient.Tracker().Add(secret))
+			}
+			informers := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+			secretInformer := informers.Core().V1().Secrets().Informer()
+			if !tt.skipIndexers {
+				require.NoError(t, AddIndexers(secretInformer))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			if !tt.skipStart {
+				informers.Start(ctx.Done())
+				informers.WaitForCacheSync(ctx.Done())
+			}
+
+			require.Equal(t, tt.wantIsEmptySel, tt.selector.IsEmpty())
+
+			sessions, err := NewLister(secretInformer).List(tt.selector)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, sessions)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSessions, sessions)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package token provides a handler for the OIDC token endpoint.
@@ -9,28 +9,58 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ory/fosite"
 	errorsx "github.com/pkg/errors"
 	"golang.org/x/oauth2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/warning"
 	"k8s.io/utils/strings/slices"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/fositestorage/devicecode"
 	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/backchannellogout"
+	"go.pinniped.dev/internal/oidc/device"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/tracing"
 )
 
+// RateLimitedRequest is an oidc.RateLimitedRequestFunc for the token endpoint. Every request is subject to the
+// per client IP rate limit. None of the grants carry a username, so the per username rate limit does not apply.
+func RateLimitedRequest(_ *http.Request) (string, bool) {
+	return "", true
+}
+
 func NewHandler(
+	downstreamIssuer string,
 	idpLister oidc.UpstreamIdentityProvidersLister,
 	oauthHelper fosite.OAuth2Provider,
+	identityTransforms *idtransform.TransformationPipeline,
+	sessionLifetimes provider.SessionLifetimes,
+	logoutNotifier backchannellogout.NotifierI,
+	deviceCodeStorage devicecode.Storage,
 ) http.Handler {
 	return httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		// Fosite does not support the device authorization grant, so turn it into an authorization code grant
+		// when the user has finished logging in, or else tell the client to keep polling.
+		if deviceCodeStorage != nil && r.PostFormValue("grant_type") == oidcapi.GrantTypeDeviceCode {
+			err := device.RewriteTokenRequest(r.Context(), r, deviceCodeStorage, downstreamIssuer+oidc.PinnipedDeviceCallbackPath, time.Now())
+			if err != nil {
+				plog.Info("device token request error", oidc.FositeErrorForLog(err)...)
+				oauthHelper.WriteAccessError(r.Context(), w, fosite.NewAccessRequest(nil), err)
+				return nil
+			}
+		}
+
 		session := psession.NewPinnipedSession()
 		accessRequest, err := oauthHelper.NewAccessRequest(r.Context(), r, session)
 		if err != nil {
@@ -39,15 +69,30 @@ func NewHandler(
 			return nil
 		}
 
+		// Remember the issuer in the session storage of the tokens, so that logout tokens can be signed for this
+		// session after it ends, e.g. by the garbage collector, which otherwise would not know the FederationDomain.
+		if storedSession, ok := accessRequest.GetSession().(*psession.PinnipedSession); ok && storedSession.Fosite != nil && storedSession.Fosite.Claims != nil {
+			storedSession.Fosite.Claims.Issuer = downstreamIssuer
+		}
+
 		// Check if we are performing a refresh grant.
 		if accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
 			// The above call to NewAccessRequest has loaded the session from storage into the accessRequest variable.
 			// The session, requested scopes, and requested audience from the original authorize request was retrieved
 			// from the Kube storage layer and added to the accessRequest. Additionally, the audience and scopes may
 			// have already been granted on the accessRequest.
-			err = upstreamRefresh(r.Context(), accessRequest, idpLister)
+			err = checkSessionLifetimes(accessRequest, sessionLifetimes, time.Now())
+			if err != nil {
+				// The storage of the session will be garbage collected soon, which will also end its upstream session
+				// and notify the client, so there is nothing else to do here.
+				plog.Info("downstream session expired", oidc.FositeErrorForLog(err)...)
+				oauthHelper.WriteAccessError(r.Context(), w, accessRequest, err)
+				return nil
+			}
+			err = upstreamRefresh(r.Context(), accessRequest, idpLister, identityTransforms)
 			if err != nil {
 				plog.Info("upstream refresh error", oidc.FositeErrorForLog(err)...)
+				notifySessionEndedByUpstreamRefreshError(r.Context(), logoutNotifier, accessRequest, err)
 				oauthHelper.WriteAccessError(r.Context(), w, accessRequest, err)
 				return nil
 			}
@@ -65,19 +110,99 @@ func NewHandler(
 			}
 		}
 
-		accessResponse, err := oauthHelper.NewAccessResponse(r.Context(), accessRequest)
+		// Both of these grants store new tokens for the session, which should not outlive the session.
+		if accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeAuthorizationCode) ||
+			accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
+			extendSession(accessRequest, sessionLifetimes, time.Now())
+		}
+
+		// The client IP in the context allows the token exchange handler to audit the source IP of the request.
+		ctx, span := tracing.Start(oidc.AuthenticationContext(r), "mint downstream tokens")
+		accessResponse, err := oauthHelper.NewAccessResponse(ctx, accessRequest)
+		tracing.End(span, err)
 		if err != nil {
 			plog.Info("token response error", oidc.FositeErrorForLog(err)...)
 			oauthHelper.WriteAccessError(r.Context(), w, accessRequest, err)
 			return nil
 		}
 
+		// Token exchanges are audited by their handler, which knows the session of the exchanged token.
+		if accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
+			auditlog.Log(auditlog.NewRecord(auditlog.EventTokenRefreshed, r, accessRequest))
+		}
+
 		oauthHelper.WriteAccessResponse(r.Context(), w, accessRequest, accessResponse)
 
 		return nil
 	})
 }
 
+// notifySessionEndedByUpstreamRefreshError notifies the client that its session has ended when the upstream refresh
+// failed because the upstream session is no longer valid. Internal errors do not end the session, because the
+// client may try its refresh again later.
+func notifySessionEndedByUpstreamRefreshError(
+	ctx context.Context,
+	logoutNotifier backchannellogout.NotifierI,
+	accessRequest fosite.AccessRequester,
+	err error,
+) {
+	var rfc6749Error *fosite.RFC6749Error
+	if logoutNotifier == nil || !errors.As(err, &rfc6749Error) || rfc6749Error.DescriptionField != errUpstreamRefreshError().DescriptionField {
+		return
+	}
+	if notifyErr := logoutNotifier.NotifySessionEnded(ctx, accessRequest); notifyErr != nil {
+		plog.WarningErr("could not send back-channel logout token after upstream refresh error", notifyErr,
+			"clientID", accessRequest.GetClient().GetID(), "requestID", accessRequest.GetID())
+	}
+}
+
+// checkSessionLifetimes returns an error when the session has reached its idle timeout or its maximum lifetime.
+// The maximum lifetime is also checked against the current settings of the FederationDomain, in case they were
+// changed since the session was last extended.
+func checkSessionLifetimes(accessRequest fosite.AccessRequester, sessionLifetimes provider.SessionLifetimes, now time.Time) error {
+	session := accessRequest.GetSession().(*psession.PinnipedSession)
+	if session.Custom == nil {
+		return errorsx.WithStack(errMissingUpstreamSessionInternalError())
+	}
+
+	if expiresAt := session.Custom.SessionExpiresAt; expiresAt != nil && now.After(expiresAt.Time) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The session has reached its idle timeout or its maximum lifetime."))
+	}
+
+	authTime := session.IDTokenClaims().AuthTime
+	if sessionLifetimes.MaxLifetime > 0 && !authTime.IsZero() && now.After(authTime.Add(sessionLifetimes.MaxLifetime)) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The session has reached its maximum lifetime."))
+	}
+
+	return nil
+}
+
+// extendSession moves the end of the session forward by the idle timeout, but never beyond the maximum lifetime.
+// It does nothing when the FederationDomain does not limit the lifetime of its sessions.
+func extendSession(accessRequest fosite.AccessRequester, sessionLifetimes provider.SessionLifetimes, now time.Time) {
+	session := accessRequest.GetSession().(*psession.PinnipedSession)
+	if session.Custom == nil {
+		return
+	}
+
+	var expiresAt time.Time
+	if sessionLifetimes.IdleTimeout > 0 {
+		expiresAt = now.Add(sessionLifetimes.IdleTimeout)
+	}
+	if authTime := session.IDTokenClaims().AuthTime; sessionLifetimes.MaxLifetime > 0 && !authTime.IsZero() {
+		if maxExpiresAt := authTime.Add(sessionLifetimes.MaxLifetime); expiresAt.IsZero() || maxExpiresAt.Before(expiresAt) {
+			expiresAt = maxExpiresAt
+		}
+	}
+
+	if expiresAt.IsZero() {
+		session.Custom.SessionExpiresAt = nil
+		return
+	}
+	t := metav1.NewTime(expiresAt.UTC())
+	session.Custom.SessionExpiresAt = &t
+}
+
 func errMissingUpstreamSessionInternalError() *fosite.RFC6749Error {
 	return &fosite.RFC6749Error{
 		ErrorField:       "error",
@@ -95,7 +220,12 @@ func errUpstreamRefreshError() *fosite.RFC6749Error {
 	}
 }
 
-func upstreamRefresh(ctx context.Context, accessRequest fosite.AccessRequester, providerCache oidc.UpstreamIdentityProvidersLister) error {
+func upstreamRefresh(
+	ctx context.Context,
+	accessRequest fosite.AccessRequester,
+	providerCache oidc.UpstreamIdentityProvidersLister,
+	identityTransforms *idtransform.TransformationPipeline,
+) error {
 	session := accessRequest.GetSession().(*psession.PinnipedSession)
 
 	customSessionData := session.Custom
@@ -113,11 +243,13 @@ func upstreamRefresh(ctx context.Context, accessRequest fosite.AccessRequester,
 
 	switch customSessionData.ProviderType {
 	case psession.ProviderTypeOIDC:
-		return upstreamOIDCRefresh(ctx, session, providerCache, grantedScopes, clientID)
+		return upstreamOIDCRefresh(ctx, session, providerCache, identityTransforms, grantedScopes, clientID)
 	case psession.ProviderTypeLDAP:
-		return upstreamLDAPRefresh(ctx, providerCache, session, grantedScopes, clientID)
+		return upstreamLDAPRefresh(ctx, providerCache, identityTransforms, session, grantedScopes, clientID)
 	case psession.ProviderTypeActiveDirectory:
-		return upstreamLDAPRefresh(ctx, providerCache, session, grantedScopes, clientID)
+		return upstreamLDAPRefresh(ctx, providerCache, identityTransforms, session, grantedScopes, clientID)
+	case psession.ProviderTypeKerberos:
+		return upstreamKerberosRefresh(ctx, providerCache, identityTransforms, session, grantedScopes, clientID)
 	default:
 		return errorsx.WithStack(errMissingUpstreamSessionInternalError())
 	}
@@ -127,6 +259,7 @@ func upstreamOIDCRefresh(
 	ctx context.Context,
 	session *psession.PinnipedSession,
 	providerCache oidc.UpstreamIdentityProvidersLister,
+	identityTransforms *idtransform.TransformationPipeline,
 	grantedScopes []string,
 	clientID string,
 ) error {
@@ -183,13 +316,14 @@ func upstreamOIDCRefresh(
 	mergedClaims := validatedTokens.IDToken.Claims
 
 	// To the extent possible, check that the user's basic identity hasn't changed.
-	err = validateIdentityUnchangedSinceInitialLogin(mergedClaims, session, p.GetUsernameClaim())
+	err = validateIdentityUnchangedSinceInitialLogin(mergedClaims, session, p)
 	if err != nil {
 		return err
 	}
 
 	groupsScope := slices.Contains(grantedScopes, oidcapi.ScopeGroups)
-	if groupsScope { //nolint:nestif
+	var refreshedGroups []string
+	if groupsScope || identityTransforms.HasTransforms() {
 		// If possible, update the user's group memberships. The configured groups claim name (if there is one) may or
 		// may not be included in the newly fetched and merged claims. It could be missing due to a misconfiguration of the
 		// claim name. It could also be missing because the claim was originally found in the ID token during login, but
@@ -197,24 +331,16 @@ func upstreamOIDCRefresh(
 		// If the claim is found, then use it to update the user's group membership in the session.
 		// If the claim is not found, then we have no new information about groups, so skip updating the group membership
 		// and let any old groups memberships in the session remain.
-		refreshedGroups, err := downstreamsession.GetGroupsFromUpstreamIDToken(p, mergedClaims)
+		refreshedGroups, err = downstreamsession.GetGroupsFromUpstreamIDToken(p, mergedClaims)
 		if err != nil {
 			return errUpstreamRefreshError().WithHintf(
 				"Upstream refresh error while extracting groups claim.").WithTrace(err).
 				WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
 		}
-		if refreshedGroups != nil {
-			oldGroups, err := getDownstreamGroupsFromPinnipedSession(session)
-			if err != nil {
-				return err
-			}
-			username, err := getDownstreamUsernameFromPinnipedSession(session)
-			if err != nil {
-				return err
-			}
-			warnIfGroupsChanged(ctx, oldGroups, refreshedGroups, username, clientID)
-			session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = refreshedGroups
-		}
+	}
+	err = updateDownstreamIdentity(ctx, session, identityTransforms, refreshedGroups, refreshedGroups != nil, groupsScope, clientID)
+	if err != nil {
+		return err
 	}
 
 	// Upstream refresh may or may not return a new refresh token. If we got a new refresh token, then update it in
@@ -238,7 +364,7 @@ func diffSortedGroups(oldGroups, newGroups []string) ([]string, []string) {
 	return added.List(), removed.List()
 }
 
-func validateIdentityUnchangedSinceInitialLogin(mergedClaims map[string]interface{}, session *psession.PinnipedSession, usernameClaimName string) error {
+func validateIdentityUnchangedSinceInitialLogin(mergedClaims map[string]interface{}, session *psession.PinnipedSession, p provider.UpstreamOIDCIdentityProviderI) error {
 	s := session.Custom
 
 	// If we have any claims at all, we better have a subject, and it better match the previous value.
@@ -260,8 +386,14 @@ func validateIdentityUnchangedSinceInitialLogin(mergedClaims map[string]interfac
 			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
 	}
 
-	newUsername, hasUsername := getString(mergedClaims, usernameClaimName)
-	oldUsername, err := getDownstreamUsernameFromPinnipedSession(session)
+	newUsername, hasUsername := getString(mergedClaims, p.GetUsernameClaim())
+	if claimExpressions := p.GetClaimExpressions(); claimExpressions.HasUsername() {
+		// The expression might not be able to compute the username when the claims that it uses were not returned
+		// during refresh, which is treated the same as the username claim not being returned.
+		computedUsername, err := claimExpressions.Username(mergedClaims)
+		newUsername, hasUsername = computedUsername, err == nil
+	}
+	oldUsername, err := getUpstreamUsernameFromPinnipedSession(session)
 	if err != nil {
 		return err
 	}
@@ -311,18 +443,19 @@ func findOIDCProviderByNameAndValidateUID(
 func upstreamLDAPRefresh(
 	ctx context.Context,
 	providerCache oidc.UpstreamIdentityProvidersLister,
+	identityTransforms *idtransform.TransformationPipeline,
 	session *psession.PinnipedSession,
 	grantedScopes []string,
 	clientID string,
 ) error {
-	username, err := getDownstreamUsernameFromPinnipedSession(session)
+	username, err := getUpstreamUsernameFromPinnipedSession(session)
 	if err != nil {
 		return err
 	}
 	subject := session.Fosite.Claims.Subject
 	var oldGroups []string
 	if slices.Contains(grantedScopes, oidcapi.ScopeGroups) {
-		oldGroups, err = getDownstreamGroupsFromPinnipedSession(session)
+		oldGroups, err = getUpstreamGroupsFromPinnipedSession(session)
 		if err != nil {
 			return err
 		}
@@ -338,10 +471,13 @@ func upstreamLDAPRefresh(
 	}
 
 	var additionalAttributes map[string]string
+	var lastGroupRefreshTime *metav1.Time
 	if s.ProviderType == psession.ProviderTypeLDAP {
 		additionalAttributes = s.LDAP.ExtraRefreshAttributes
+		lastGroupRefreshTime = &s.LDAP.LastGroupRefreshTime
 	} else {
 		additionalAttributes = s.ActiveDirectory.ExtraRefreshAttributes
+		lastGroupRefreshTime = &s.ActiveDirectory.LastGroupRefreshTime
 	}
 
 	// get ldap/ad provider out of cache
@@ -352,25 +488,44 @@ func upstreamLDAPRefresh(
 	if session.IDTokenClaims().AuthTime.IsZero() {
 		return errorsx.WithStack(errMissingUpstreamSessionInternalError())
 	}
-	// run PerformRefresh
-	groups, err := p.PerformRefresh(ctx, provider.RefreshAttributes{
+	// The groups were last searched at login, unless a later refresh has searched for them again.
+	lastGroupRefresh := session.IDTokenClaims().AuthTime
+	if !lastGroupRefreshTime.IsZero() {
+		lastGroupRefresh = lastGroupRefreshTime.Time
+	}
+	refreshAttributes := provider.RefreshAttributes{
 		Username:             username,
 		Subject:              subject,
 		DN:                   dn,
 		Groups:               oldGroups,
 		AdditionalAttributes: additionalAttributes,
 		GrantedScopes:        grantedScopes,
-	})
+		LastGroupRefresh:     lastGroupRefresh,
+	}
+	groupRefreshInterval := p.GetGroupRefreshInterval()
+	groupRefreshIsDue := refreshAttributes.GroupRefreshIsDue(groupRefreshInterval)
+	// run PerformRefresh
+	groups, additionalClaims, err := p.PerformRefresh(ctx, refreshAttributes)
 	if err != nil {
 		return errUpstreamRefreshError().WithHint(
 			"Upstream refresh failed.").WithTrace(err).
 			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
 	}
 	groupsScope := slices.Contains(grantedScopes, oidcapi.ScopeGroups)
-	if groupsScope {
-		warnIfGroupsChanged(ctx, oldGroups, groups, username, clientID)
-		// Replace the old value with the new value.
-		session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = groups
+	err = updateDownstreamIdentity(ctx, session, identityTransforms, groups, groupsScope, groupsScope, clientID)
+	if err != nil {
+		return err
+	}
+	if groupsScope && groupRefreshInterval > 0 && groupRefreshIsDue {
+		// Remember when the groups were searched, so the next search can wait until the interval elapses again.
+		*lastGroupRefreshTime = metav1.NewTime(time.Now().UTC())
+	}
+
+	// Replace the old additional claims with the current values of the mapped attributes, if any.
+	if len(additionalClaims) > 0 {
+		session.Fosite.Claims.Extra[oidcapi.IDTokenClaimAdditionalClaims] = additionalClaims
+	} else {
+		delete(session.Fosite.Claims.Extra, oidcapi.IDTokenClaimAdditionalClaims)
 	}
 
 	return nil
@@ -406,6 +561,175 @@ func findLDAPProviderByNameAndValidateUID(
 		WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType))
 }
 
+func upstreamKerberosRefresh(
+	ctx context.Context,
+	providerCache oidc.UpstreamIdentityProvidersLister,
+	identityTransforms *idtransform.TransformationPipeline,
+	session *psession.PinnipedSession,
+	grantedScopes []string,
+	clientID string,
+) error {
+	username, err := getUpstreamUsernameFromPinnipedSession(session)
+	if err != nil {
+		return err
+	}
+	var oldGroups []string
+	groupsScope := slices.Contains(grantedScopes, oidcapi.ScopeGroups)
+	if groupsScope {
+		oldGroups, err = getUpstreamGroupsFromPinnipedSession(session)
+		if err != nil {
+			return err
+		}
+	}
+
+	s := session.Custom
+	if s.Kerberos == nil || s.Kerberos.Principal == "" {
+		return errorsx.WithStack(errMissingUpstreamSessionInternalError())
+	}
+
+	p, err := findKerberosProviderByNameAndValidateUID(s, providerCache)
+	if err != nil {
+		return err
+	}
+
+	groups, err := p.PerformRefresh(ctx, provider.RefreshAttributes{
+		Username:      username,
+		Subject:       session.Fosite.Claims.Subject,
+		Groups:        oldGroups,
+		GrantedScopes: grantedScopes,
+	})
+	if err != nil {
+		return errUpstreamRefreshError().WithHint(
+			"Upstream refresh failed.").WithTrace(err).
+			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
+	}
+
+	return updateDownstreamIdentity(ctx, session, identityTransforms, groups, groupsScope, groupsScope, clientID)
+}
+
+func findKerberosProviderByNameAndValidateUID(
+	s *psession.CustomSessionData,
+	providerCache oidc.UpstreamIdentityProvidersLister,
+) (provider.UpstreamKerberosIdentityProviderI, error) {
+	for _, p := range providerCache.GetKerberosIdentityProviders() {
+		if p.GetName() == s.ProviderName {
+			if p.GetResourceUID() != s.ProviderUID {
+				return nil, errorsx.WithStack(errUpstreamRefreshError().WithHint(
+					"Provider from upstream session data has changed its resource UID since authentication.").
+					WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType))
+			}
+			return p, nil
+		}
+	}
+
+	return nil, errorsx.WithStack(errUpstreamRefreshError().
+		WithHint("Provider from upstream session data was not found.").
+		WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType))
+}
+
+// updateDownstreamIdentity updates the downstream identity in the session using the identity from the upstream refresh.
+// The refreshedUpstreamGroups are only used when haveRefreshedGroups is true. Otherwise, the upstream refresh did not
+// provide any new information about the groups, so the groups from the initial login or the previous refresh are kept.
+// When the FederationDomain has identity transformations, they are applied again, and they may reject the refresh.
+// The downstream username may not change during a refresh, so the refresh is also rejected when the transformations
+// now compute a different username, e.g. because the transformations were changed since the initial login.
+func updateDownstreamIdentity(
+	ctx context.Context,
+	session *psession.PinnipedSession,
+	identityTransforms *idtransform.TransformationPipeline,
+	refreshedUpstreamGroups []string,
+	haveRefreshedGroups bool,
+	groupsScope bool,
+	clientID string,
+) error {
+	s := session.Custom
+
+	upstreamUsername, err := getUpstreamUsernameFromPinnipedSession(session)
+	if err != nil {
+		return err
+	}
+
+	if !identityTransforms.HasTransforms() {
+		if upstreamUsername != s.Username {
+			// The identity transformations which were applied at login have since been removed from the FederationDomain.
+			return errUpstreamRefreshError().WithHint(
+				"Upstream refresh failed.").WithTrace(errors.New("username in upstream refresh does not match previous value")).
+				WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
+		}
+		if groupsScope && haveRefreshedGroups {
+			oldGroups, err := getDownstreamGroupsFromPinnipedSession(session)
+			if err != nil {
+				return err
+			}
+			warnIfGroupsChanged(ctx, oldGroups, refreshedUpstreamGroups, s.Username, clientID)
+			// Replace the old value with the new value.
+			session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = refreshedUpstreamGroups
+		}
+		return nil
+	}
+
+	upstreamGroups := refreshedUpstreamGroups
+	if !haveRefreshedGroups {
+		switch {
+		case s.UpstreamUsername != "":
+			upstreamGroups = s.UpstreamGroups
+		case groupsScope:
+			upstreamGroups, err = getDownstreamGroupsFromPinnipedSession(session)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	result, err := identityTransforms.Evaluate(ctx, upstreamUsername, upstreamGroups)
+	if err != nil {
+		return errUpstreamRefreshError().WithHint(
+			"Upstream refresh error while applying configured identity transformations.").WithTrace(err).
+			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
+	}
+	if !result.AuthenticationAllowed {
+		return errUpstreamRefreshError().WithHintf(
+			"Upstream refresh rejected by configured identity policy: %s.", result.RejectedAuthenticationMessage).
+			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
+	}
+	if result.Username != s.Username {
+		return errUpstreamRefreshError().WithHint(
+			"Upstream refresh failed.").WithTrace(errors.New("username in upstream refresh does not match previous value")).
+			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
+	}
+
+	s.UpstreamUsername = upstreamUsername
+	s.UpstreamGroups = upstreamGroups
+	if groupsScope {
+		oldGroups, err := getDownstreamGroupsFromPinnipedSession(session)
+		if err != nil {
+			return err
+		}
+		warnIfGroupsChanged(ctx, oldGroups, result.Groups, s.Username, clientID)
+		// Replace the old value with the new value.
+		session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = result.Groups
+	}
+	return nil
+}
+
+// getUpstreamUsernameFromPinnipedSession returns the username from the upstream IDP before any identity
+// transformations were applied to it. When there were no transformations, it is the same as the downstream username.
+func getUpstreamUsernameFromPinnipedSession(session *psession.PinnipedSession) (string, error) {
+	if session.Custom.UpstreamUsername != "" {
+		return session.Custom.UpstreamUsername, nil
+	}
+	return getDownstreamUsernameFromPinnipedSession(session)
+}
+
+// getUpstreamGroupsFromPinnipedSession returns the groups from the upstream IDP before any identity
+// transformations were applied to them. When there were no transformations, they are the same as the downstream groups.
+func getUpstreamGroupsFromPinnipedSession(session *psession.PinnipedSession) ([]string, error) {
+	if session.Custom.UpstreamUsername != "" {
+		return session.Custom.UpstreamGroups, nil
+	}
+	return getDownstreamGroupsFromPinnipedSession(session)
+}
+
 func getDownstreamUsernameFromPinnipedSession(session *psession.PinnipedSession) (string, error) {
 	downstreamUsername := session.Custom.Username
 	if len(downstreamUsername) == 0 {
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package token
@@ -50,6 +50,7 @@ import (
 	"go.pinniped.dev/internal/fositestoragei"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/idtransform"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/oidc/jwks"
@@ -286,6 +287,16 @@ type tokenEndpointResponseExpectedValues struct {
 	wantCustomSessionDataStored       *psession.CustomSessionData
 	wantWarnings                      []RecordedWarning
 	wantAdditionalClaims              map[string]interface{}
+	// wantIDTokenGroups are the groups in the ID tokens when they differ from the stored groups, e.g. because the
+	// client filters groups. When nil, the ID tokens are expected to have the wantGroups.
+	wantIDTokenGroups []string
+}
+
+func (v tokenEndpointResponseExpectedValues) idTokenGroups() []string {
+	if v.wantIDTokenGroups != nil {
+		return v.wantIDTokenGroups
+	}
+	return v.wantGroups
 }
 
 type authcodeExchangeInputs struct {
@@ -294,6 +305,7 @@ type authcodeExchangeInputs struct {
 	makeJwksSigningKeyAndProvider MakeJwksSigningKeyAndProviderFunc
 	customSessionData             *psession.CustomSessionData
 	modifySession                 func(*psession.PinnipedSession)
+	identityTransforms            []idtransform.Expression
 	want                          tokenEndpointResponseExpectedValues
 }
 
@@ -310,6 +322,34 @@ func addFullyCapableDynamicClientAndSecretToKubeResources(t *testing.T, supervis
 	require.NoError(t, kubeClient.Tracker().Add(secret))
 }
 
+func addDynamicClientWithTokenExchangeAudiencesAndSecretToKubeResources(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+	oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+		"some-namespace",
+		dynamicClientID,
+		dynamicClientUID,
+		goodRedirectURI,
+		[]string{testutil.HashedPassword1AtGoMinCost, testutil.HashedPassword2AtGoMinCost},
+		oidcclientvalidator.Validate,
+	)
+	oidcClient.Spec.AllowedTokenExchangeAudiences = []configv1alpha1.TokenExchangeAudience{"allowed-workload-cluster", "other-allowed-workload-cluster"}
+	require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+	require.NoError(t, kubeClient.Tracker().Add(secret))
+}
+
+func addDynamicClientWithGroupsFilterAndSecretToKubeResources(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+	oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+		"some-namespace",
+		dynamicClientID,
+		dynamicClientUID,
+		goodRedirectURI,
+		[]string{testutil.HashedPassword1AtGoMinCost, testutil.HashedPassword2AtGoMinCost},
+		oidcclientvalidator.Validate,
+	)
+	oidcClient.Spec.GroupsFilter = configv1alpha1.GroupsFilter{Names: []string{"groups2"}, Prefixes: []string{"other-"}}
+	require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+	require.NoError(t, kubeClient.Tracker().Add(secret))
+}
+
 func modifyAuthcodeTokenRequestWithDynamicClientAuth(r *http.Request, authCode string) {
 	r.Body = happyAuthcodeRequestBody(authCode).WithClientID("").ReadCloser() // No client_id in body.
 	r.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)              // Use basic auth header instead.
@@ -1170,6 +1210,63 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 			requestedAudience: "some-workload-cluster",
 			wantStatus:        http.StatusOK,
 		},
+		{
+			name:             "happy path with dynamic client which lists the requested audience in its allowed token exchange audiences",
+			kubeResources:    addDynamicClientWithTokenExchangeAudiencesAndSecretToKubeResources,
+			authcodeExchange: doValidAuthCodeExchangeUsingDynamicClient,
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Del("client_id") // client auth for dynamic clients must be in basic auth header
+			},
+			modifyRequestHeaders: func(r *http.Request) {
+				r.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)
+			},
+			requestedAudience: "other-allowed-workload-cluster",
+			wantStatus:        http.StatusOK,
+		},
+		{
+			name:          "happy path with dynamic client which filters groups, so gets only some groups in ID tokens",
+			kubeResources: addDynamicClientWithGroupsFilterAndSecretToKubeResources,
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(authRequest *http.Request) {
+					addDynamicClientIDToFormPostBody(authRequest)
+					authRequest.Form.Set("scope", "openid pinniped:request-audience username groups")
+				},
+				modifyTokenRequest: modifyAuthcodeTokenRequestWithDynamicClientAuth,
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus:            http.StatusOK,
+					wantClientID:          dynamicClientID,
+					wantSuccessBodyFields: []string{"id_token", "access_token", "token_type", "expires_in", "scope"},
+					wantRequestedScopes:   []string{"openid", "pinniped:request-audience", "username", "groups"},
+					wantGrantedScopes:     []string{"openid", "pinniped:request-audience", "username", "groups"},
+					wantUsername:          goodUsername,
+					wantGroups:            goodGroups,          // all groups are stored in the session
+					wantIDTokenGroups:     []string{"groups2"}, // but the ID tokens only contain the groups selected by the filter
+				},
+			},
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Del("client_id") // client auth for dynamic clients must be in basic auth header
+			},
+			modifyRequestHeaders: func(r *http.Request) {
+				r.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)
+			},
+			requestedAudience: "some-workload-cluster",
+			wantStatus:        http.StatusOK,
+		},
+		{
+			name:             "dynamic client which does not list the requested audience in its allowed token exchange audiences",
+			kubeResources:    addDynamicClientWithTokenExchangeAudiencesAndSecretToKubeResources,
+			authcodeExchange: doValidAuthCodeExchangeUsingDynamicClient,
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Del("client_id") // client auth for dynamic clients must be in basic auth header
+			},
+			modifyRequestHeaders: func(r *http.Request) {
+				r.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)
+			},
+			requestedAudience:     "some-workload-cluster",
+			wantStatus:            http.StatusBadRequest,
+			wantErrorType:         "invalid_target",
+			wantErrorDescContains: `The authorization server is unwilling to issue a token for the requested audience. The requested audience 'some-workload-cluster' is not allowed for this client.`,
+		},
 		{
 			name: "dynamic client lacks the required urn:ietf:params:oauth:grant-type:token-exchange grant type",
 			kubeResources: func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
@@ -1675,7 +1772,7 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 				require.Nil(t, tokenClaims["username"])
 			}
 			if test.authcodeExchange.want.wantGroups != nil {
-				require.Equal(t, toSliceOfInterface(test.authcodeExchange.want.wantGroups), tokenClaims["groups"])
+				require.Equal(t, toSliceOfInterface(test.authcodeExchange.want.idTokenGroups()), tokenClaims["groups"])
 			} else {
 				require.Nil(t, tokenClaims["groups"])
 			}
@@ -1747,6 +1844,11 @@ func TestRefreshGrant(t *testing.T) {
 		activeDirectoryUpstreamResourceUID = "ad-resource-uid"
 		activeDirectoryUpstreamType        = "activedirectory"
 		activeDirectoryUpstreamDN          = "some-ad-user-dn"
+
+		kerberosUpstreamName        = "some-kerberos-idp"
+		kerberosUpstreamResourceUID = "kerberos-resource-uid"
+		kerberosUpstreamType        = "kerberos"
+		kerberosUpstreamPrincipal   = "some-user@EXAMPLE.COM"
 	)
 
 	ldapUpstreamURL, _ := url.Parse("some-url")
@@ -1889,6 +1991,23 @@ func TestRefreshGrant(t *testing.T) {
 		return want
 	}
 
+	happyKerberosUpstreamRefreshCall := func() *expectedUpstreamRefresh {
+		return &expectedUpstreamRefresh{
+			performedByUpstreamName: kerberosUpstreamName,
+			args: &oidctestutil.PerformRefreshArgs{
+				Ctx:              nil,
+				ExpectedSubject:  goodSubject,
+				ExpectedUsername: goodUsername,
+			},
+		}
+	}
+
+	happyRefreshTokenResponseForKerberos := func(wantCustomSessionDataStored *psession.CustomSessionData) tokenEndpointResponseExpectedValues {
+		want := happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(wantCustomSessionDataStored)
+		want.wantUpstreamRefreshCall = happyKerberosUpstreamRefreshCall()
+		return want
+	}
+
 	happyRefreshTokenResponseForActiveDirectory := func(wantCustomSessionDataStored *psession.CustomSessionData) tokenEndpointResponseExpectedValues {
 		want := happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(wantCustomSessionDataStored)
 		want.wantUpstreamRefreshCall = happyActiveDirectoryUpstreamRefreshCall()
@@ -1935,6 +2054,28 @@ func TestRefreshGrant(t *testing.T) {
 		},
 	}
 
+	happyLDAPCustomSessionDataWithUpstreamIdentity := &psession.CustomSessionData{
+		Username:         goodUsername,
+		UpstreamUsername: goodUsername,
+		UpstreamGroups:   goodGroups,
+		ProviderUID:      ldapUpstreamResourceUID,
+		ProviderName:     ldapUpstreamName,
+		ProviderType:     ldapUpstreamType,
+		LDAP: &psession.LDAPSessionData{
+			UserDN: ldapUpstreamDN,
+		},
+	}
+
+	happyKerberosCustomSessionData := &psession.CustomSessionData{
+		Username:     goodUsername,
+		ProviderUID:  kerberosUpstreamResourceUID,
+		ProviderName: kerberosUpstreamName,
+		ProviderType: kerberosUpstreamType,
+		Kerberos: &psession.KerberosSessionData{
+			Principal: kerberosUpstreamPrincipal,
+		},
+	}
+
 	happyAuthcodeExchangeInputsForOIDCUpstream := authcodeExchangeInputs{
 		customSessionData: initialUpstreamOIDCRefreshTokenCustomSessionData(),
 		modifyAuthRequest: func(r *http.Request) { r.Form.Set("scope", "openid offline_access username groups") },
@@ -1949,6 +2090,14 @@ func TestRefreshGrant(t *testing.T) {
 		),
 	}
 
+	happyAuthcodeExchangeInputsForKerberosUpstream := authcodeExchangeInputs{
+		modifyAuthRequest: func(r *http.Request) { r.Form.Set("scope", "openid offline_access username groups") },
+		customSessionData: happyKerberosCustomSessionData,
+		want: happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(
+			happyKerberosCustomSessionData,
+		),
+	}
+
 	tests := []struct {
 		name                      string
 		idps                      *oidctestutil.UpstreamIDPListerBuilder
@@ -2491,6 +2640,37 @@ func TestRefreshGrant(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "happy path refresh grant when the upstream refresh returns new additional claims from LDAP, it updates the additional claims",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+				Name:                 ldapUpstreamName,
+				ResourceUID:          ldapUpstreamResourceUID,
+				URL:                  ldapUpstreamURL,
+				PerformRefreshGroups: goodGroups,
+				PerformRefreshAdditionalClaims: map[string]interface{}{
+					"email":      "some-new-email@example.com",
+					"department": []string{"some-department", "some-other-department"},
+				},
+			}),
+			authcodeExchange: happyAuthcodeExchangeInputsForLDAPUpstream,
+			refreshRequest: refreshRequestInputs{
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus:                  http.StatusOK,
+					wantClientID:                pinnipedCLIClientID,
+					wantSuccessBodyFields:       []string{"refresh_token", "access_token", "id_token", "token_type", "expires_in", "scope"},
+					wantRequestedScopes:         []string{"openid", "offline_access", "username", "groups"},
+					wantGrantedScopes:           []string{"openid", "offline_access", "username", "groups"},
+					wantUsername:                goodUsername,
+					wantGroups:                  goodGroups,
+					wantUpstreamRefreshCall:     happyLDAPUpstreamRefreshCall(),
+					wantCustomSessionDataStored: happyLDAPCustomSessionData,
+					wantAdditionalClaims: map[string]interface{}{
+						"email":      "some-new-email@example.com",
+						"department": []interface{}{"some-department", "some-other-department"},
+					},
+				},
+			},
+		},
 		{
 			name: "happy path refresh grant when the upstream refresh returns new group memberships from LDAP, it updates groups, using dynamic client - updates groups without outputting warnings",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
@@ -3482,6 +3662,100 @@ func TestRefreshGrant(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "upstream ldap refresh with identity transforms applies the transforms to the refreshed upstream groups",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+				Name:                 ldapUpstreamName,
+				ResourceUID:          ldapUpstreamResourceUID,
+				URL:                  ldapUpstreamURL,
+				PerformRefreshGroups: []string{"group1", "group3"},
+			}),
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(r *http.Request) { r.Form.Set("scope", "openid offline_access username groups") },
+				customSessionData: happyLDAPCustomSessionDataWithUpstreamIdentity,
+				identityTransforms: []idtransform.Expression{
+					{Type: idtransform.GroupsTransformation, Expression: `groups.map(g, "pre:" + g)`},
+				},
+				want: happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(
+					happyLDAPCustomSessionDataWithUpstreamIdentity,
+				),
+			},
+			refreshRequest: refreshRequestInputs{
+				want: func() tokenEndpointResponseExpectedValues {
+					sessionData := *happyLDAPCustomSessionDataWithUpstreamIdentity
+					sessionData.UpstreamGroups = []string{"group1", "group3"}
+					want := happyRefreshTokenResponseForLDAP(&sessionData)
+					want.wantGroups = []string{"pre:group1", "pre:group3"}
+					want.wantWarnings = []RecordedWarning{
+						{Text: `User "some-username" has been added to the following groups: ["pre:group1" "pre:group3"]`},
+						{Text: `User "some-username" has been removed from the following groups: ["group1" "groups2"]`},
+					}
+					return want
+				}(),
+			},
+		},
+		{
+			name: "upstream ldap refresh with identity transforms is rejected by the identity policy",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+				Name:                 ldapUpstreamName,
+				ResourceUID:          ldapUpstreamResourceUID,
+				URL:                  ldapUpstreamURL,
+				PerformRefreshGroups: []string{"group1", "group3"},
+			}),
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(r *http.Request) { r.Form.Set("scope", "openid offline_access username groups") },
+				customSessionData: happyLDAPCustomSessionDataWithUpstreamIdentity,
+				identityTransforms: []idtransform.Expression{
+					{Type: idtransform.PolicyTransformation, Expression: `!("group3" in groups)`, Message: "members of group3 may not log in"},
+				},
+				want: happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(
+					happyLDAPCustomSessionDataWithUpstreamIdentity,
+				),
+			},
+			refreshRequest: refreshRequestInputs{
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus:              http.StatusUnauthorized,
+					wantUpstreamRefreshCall: happyLDAPUpstreamRefreshCall(),
+					wantErrorResponseBody: here.Doc(`
+						{
+							"error":             "error",
+							"error_description": "Error during upstream refresh. Upstream refresh rejected by configured identity policy: members of group3 may not log in."
+						}
+					`),
+				},
+			},
+		},
+		{
+			name: "upstream ldap refresh with identity transforms fails when the transforms compute a different username",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+				Name:                 ldapUpstreamName,
+				ResourceUID:          ldapUpstreamResourceUID,
+				URL:                  ldapUpstreamURL,
+				PerformRefreshGroups: goodGroups,
+			}),
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(r *http.Request) { r.Form.Set("scope", "openid offline_access username groups") },
+				customSessionData: happyLDAPCustomSessionDataWithUpstreamIdentity,
+				identityTransforms: []idtransform.Expression{
+					{Type: idtransform.UsernameTransformation, Expression: `"changed:" + username`},
+				},
+				want: happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(
+					happyLDAPCustomSessionDataWithUpstreamIdentity,
+				),
+			},
+			refreshRequest: refreshRequestInputs{
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus:              http.StatusUnauthorized,
+					wantUpstreamRefreshCall: happyLDAPUpstreamRefreshCall(),
+					wantErrorResponseBody: here.Doc(`
+						{
+							"error":             "error",
+							"error_description": "Error during upstream refresh. Upstream refresh failed."
+						}
+					`),
+				},
+			},
+		},
 		{
 			name: "upstream ldap refresh happy path using dynamic client",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
@@ -3742,6 +4016,57 @@ func TestRefreshGrant(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "upstream kerberos refresh happy path",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&oidctestutil.TestUpstreamKerberosIdentityProvider{
+				Name:                 kerberosUpstreamName,
+				ResourceUID:          kerberosUpstreamResourceUID,
+				PerformRefreshGroups: goodGroups,
+			}),
+			authcodeExchange: happyAuthcodeExchangeInputsForKerberosUpstream,
+			refreshRequest: refreshRequestInputs{
+				want: happyRefreshTokenResponseForKerberos(
+					happyKerberosCustomSessionData,
+				),
+			},
+		},
+		{
+			name: "upstream kerberos refresh returns an error",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithKerberos(&oidctestutil.TestUpstreamKerberosIdentityProvider{
+				Name:              kerberosUpstreamName,
+				ResourceUID:       kerberosUpstreamResourceUID,
+				PerformRefreshErr: errors.New("Some error performing upstream refresh"),
+			}),
+			authcodeExchange: happyAuthcodeExchangeInputsForKerberosUpstream,
+			refreshRequest: refreshRequestInputs{
+				want: tokenEndpointResponseExpectedValues{
+					wantUpstreamRefreshCall: happyKerberosUpstreamRefreshCall(),
+					wantStatus:              http.StatusUnauthorized,
+					wantErrorResponseBody: here.Doc(`
+						{
+							"error":             "error",
+							"error_description": "Error during upstream refresh. Upstream refresh failed."
+						}
+					`),
+				},
+			},
+		},
+		{
+			name:             "upstream kerberos idp not found",
+			idps:             oidctestutil.NewUpstreamIDPListerBuilder(),
+			authcodeExchange: happyAuthcodeExchangeInputsForKerberosUpstream,
+			refreshRequest: refreshRequestInputs{
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus: http.StatusUnauthorized,
+					wantErrorResponseBody: here.Doc(`
+						{
+							"error":             "error",
+							"error_description": "Error during upstream refresh. Provider from upstream session data was not found."
+						}
+					`),
+				},
+			},
+		},
 		{
 			name: "upstream active directory refresh returns an error",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithActiveDirectory(&oidctestutil.TestUpstreamLDAPIdentityProvider{
@@ -4075,9 +4400,10 @@ func TestRefreshGrant(t *testing.T) {
 
 			requireTokenEndpointBehavior(t,
 				test.refreshRequest.want,
-				test.authcodeExchange.want.wantUsername, // the old username from the initial login
-				test.authcodeExchange.want.wantGroups,   // the old groups from the initial login
-				test.authcodeExchange.customSessionData, // the old custom session data from the initial login
+				test.authcodeExchange.want.wantUsername,         // the old username from the initial login
+				test.authcodeExchange.want.wantGroups,           // the old groups from the initial login
+				test.authcodeExchange.want.wantAdditionalClaims, // the old additional claims from the initial login
+				test.authcodeExchange.customSessionData,         // the old custom session data from the initial login
 				wantNonceValueInIDToken,
 				refreshResponse,
 				authCode,
@@ -4184,7 +4510,10 @@ func exchangeAuthcodeForTokens(
 	// Note that makeHappyOauthHelper() calls simulateAuthEndpointHavingAlreadyRun() to preload the session storage.
 	oauthHelper, authCode, jwtSigningKey = makeHappyOauthHelper(t, authRequest, oauthStore, test.makeJwksSigningKeyAndProvider, test.customSessionData, test.modifySession)
 
-	subject = NewHandler(idps, oauthHelper)
+	identityTransforms, err := idtransform.NewTransformationPipeline(test.identityTransforms)
+	require.NoError(t, err)
+
+	subject = NewHandler(goodIssuer, idps, oauthHelper, identityTransforms, provider.SessionLifetimes{}, nil, nil)
 
 	authorizeEndpointGrantedOpenIDScope := strings.Contains(authRequest.Form.Get("scope"), "openid")
 	expectedNumberOfIDSessionsStored := 0
@@ -4214,9 +4543,10 @@ func exchangeAuthcodeForTokens(
 
 	requireTokenEndpointBehavior(t,
 		test.want,
-		test.want.wantUsername, // the old username from the initial login
-		test.want.wantGroups,   // the old groups from the initial login
-		test.customSessionData, // the old custom session data from the initial login
+		test.want.wantUsername,         // the old username from the initial login
+		test.want.wantGroups,           // the old groups from the initial login
+		test.want.wantAdditionalClaims, // the old additional claims from the initial login
+		test.customSessionData,         // the old custom session data from the initial login
 		wantNonceValueInIDToken,
 		rsp,
 		authCode,
@@ -4234,6 +4564,7 @@ func requireTokenEndpointBehavior(
 	test tokenEndpointResponseExpectedValues,
 	oldUsername string,
 	oldGroups []string,
+	oldAdditionalClaims map[string]interface{},
 	oldCustomSessionData *psession.CustomSessionData,
 	wantNonceValueInIDToken bool,
 	tokenEndpointResponse *httptest.ResponseRecorder,
@@ -4260,7 +4591,7 @@ func requireTokenEndpointBehavior(
 		requireValidAccessTokenStorage(t, parsedResponseBody, oauthStore, test.wantClientID, test.wantRequestedScopes, test.wantGrantedScopes, test.wantUsername, test.wantGroups, test.wantCustomSessionDataStored, test.wantAdditionalClaims, secrets, requestTime)
 		requireInvalidPKCEStorage(t, authCode, oauthStore)
 		// Performing a refresh does not update the OIDC storage, so after a refresh it should still have the old custom session data and old username and groups from the initial login.
-		requireValidOIDCStorage(t, parsedResponseBody, authCode, oauthStore, test.wantClientID, test.wantRequestedScopes, test.wantGrantedScopes, oldUsername, oldGroups, oldCustomSessionData, test.wantAdditionalClaims, requestTime)
+		requireValidOIDCStorage(t, parsedResponseBody, authCode, oauthStore, test.wantClientID, test.wantRequestedScopes, test.wantGrantedScopes, oldUsername, oldGroups, oldCustomSessionData, oldAdditionalClaims, requestTime)
 
 		expectedNumberOfRefreshTokenSessionsStored := 0
 		if wantRefreshToken {
@@ -4269,7 +4600,7 @@ func requireTokenEndpointBehavior(
 		expectedNumberOfIDSessionsStored := 0
 		if wantIDToken {
 			expectedNumberOfIDSessionsStored = 1
-			requireValidIDToken(t, parsedResponseBody, jwtSigningKey, test.wantClientID, wantNonceValueInIDToken, test.wantUsername, test.wantGroups, test.wantAdditionalClaims, parsedResponseBody["access_token"].(string), requestTime)
+			requireValidIDToken(t, parsedResponseBody, jwtSigningKey, test.wantClientID, wantNonceValueInIDToken, test.wantUsername, test.idTokenGroups(), test.wantAdditionalClaims, parsedResponseBody["access_token"].(string), requestTime)
 		}
 		if wantRefreshToken {
 			requireValidRefreshTokenStorage(t, parsedResponseBody, oauthStore, test.wantClientID, test.wantRequestedScopes, test.wantGrantedScopes, test.wantUsername, test.wantGroups, test.wantCustomSessionDataStored, test.wantAdditionalClaims, secrets, requestTime)
@@ -4387,7 +4718,7 @@ func makeHappyOauthHelper(
 	t.Helper()
 
 	jwtSigningKey, jwkProvider := makeJwksSigningKeyAndProvider(t, goodIssuer)
-	oauthHelper := oidc.FositeOauth2Helper(store, goodIssuer, hmacSecretFunc, jwkProvider, oidc.DefaultOIDCTimeoutsConfiguration())
+	oauthHelper := oidc.FositeOauth2Helper(store, goodIssuer, hmacSecretFunc, jwkProvider, oidc.DefaultOIDCTimeoutsConfiguration(), nil, nil)
 	authResponder := simulateAuthEndpointHavingAlreadyRun(t, authRequest, oauthHelper, initialCustomSessionData, modifySession)
 	return oauthHelper, authResponder.GetCode(), jwtSigningKey
 }
@@ -4539,6 +4870,7 @@ func requireValidRefreshTokenStorage(
 		wantCustomSessionData,
 		wantAdditionalClaims,
 		requestTime,
+		goodIssuer,
 	)
 
 	requireGarbageCollectTimeInDelta(t, refreshTokenString, "refresh-token", secrets, requestTime.Add(9*time.Hour).Add(2*time.Minute), 1*time.Minute)
@@ -4605,6 +4937,7 @@ func requireValidAccessTokenStorage(
 		wantCustomSessionData,
 		wantAdditionalClaims,
 		requestTime,
+		goodIssuer,
 	)
 
 	requireGarbageCollectTimeInDelta(t, accessTokenString, "access-token", secrets, requestTime.Add(9*time.Hour).Add(2*time.Minute), 1*time.Minute)
@@ -4680,6 +5013,7 @@ func requireValidOIDCStorage(
 			wantCustomSessionData,
 			wantAdditionalClaims,
 			requestTime,
+			"",
 		)
 	} else {
 		_, err := storage.GetOpenIDConnectSession(context.Background(), code, nil)
@@ -4700,6 +5034,7 @@ func requireValidStoredRequest(
 	wantCustomSessionData *psession.CustomSessionData,
 	wantAdditionalClaims map[string]interface{},
 	requestTime time.Time,
+	wantIssuer string,
 ) {
 	t.Helper()
 
@@ -4744,8 +5079,9 @@ func requireValidStoredRequest(
 	// These fields will all be given good defaults by fosite at runtime and we only need to use them
 	// if we want to override the default behaviors. We currently don't need to override these defaults,
 	// so they do not end up being stored. Fosite sets its defaults at runtime in openid.DefaultStrategy's
-	// GenerateIDToken() method.
-	require.Empty(t, claims.Issuer)
+	// GenerateIDToken() method. The exception is the issuer, which the token endpoint sets to remember the issuer
+	// of the session in the storage of the access and refresh tokens.
+	require.Equal(t, wantIssuer, claims.Issuer)
 	require.Empty(t, claims.Audience)
 	require.Empty(t, claims.Nonce)
 	require.Zero(t, claims.ExpiresAt)
@@ -4987,6 +5323,150 @@ func TestDiffSortedGroups(t *testing.T) {
 	}
 }
 
+type fakeLogoutNotifier struct {
+	notifiedRequestIDs []string
+	err                error
+}
+
+func (f *fakeLogoutNotifier) NotifySessionEnded(_ context.Context, requester fosite.Requester) error {
+	f.notifiedRequestIDs = append(f.notifiedRequestIDs, requester.GetID())
+	return f.err
+}
+
+func TestNotifySessionEndedByUpstreamRefreshError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		notifierErr  error
+		wantNotified bool
+	}{
+		{
+			name:         "upstream refresh error",
+			err:          errUpstreamRefreshError().WithHint("Upstream refresh failed."),
+			wantNotified: true,
+		},
+		{
+			name:         "wrapped upstream refresh error",
+			err:          errors.WithStack(errUpstreamRefreshError().WithHint("Provider from upstream session data was not found.")),
+			wantNotified: true,
+		},
+		{
+			name:         "notifier error is only logged",
+			err:          errUpstreamRefreshError(),
+			notifierErr:  errors.New("some notifier error"),
+			wantNotified: true,
+		},
+		{
+			name:         "internal errors do not end the session",
+			err:          errors.WithStack(errMissingUpstreamSessionInternalError()),
+			wantNotified: false,
+		},
+		{
+			name:         "other errors do not end the session",
+			err:          errors.New("some other error"),
+			wantNotified: false,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			notifier := &fakeLogoutNotifier{err: test.notifierErr}
+			request := &fosite.AccessRequest{Request: fosite.Request{ID: "some-request-id", Client: &fosite.DefaultClient{ID: "some-client"}}}
+
+			notifySessionEndedByUpstreamRefreshError(context.Background(), notifier, request, test.err)
+			if test.wantNotified {
+				require.Equal(t, []string{"some-request-id"}, notifier.notifiedRequestIDs)
+			} else {
+				require.Empty(t, notifier.notifiedRequestIDs)
+			}
+
+			// A nil notifier is allowed.
+			notifySessionEndedByUpstreamRefreshError(context.Background(), nil, request, test.err)
+		})
+	}
+}
+
+func TestSessionLifetimes(t *testing.T) {
+	authTime := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	metaTime := func(t time.Time) *metav1.Time {
+		m := metav1.NewTime(t)
+		return &m
+	}
+
+	tests := []struct {
+		name                 string
+		sessionLifetimes     provider.SessionLifetimes
+		sessionExpiresAt     *metav1.Time
+		now                  time.Time
+		wantErr              string
+		wantSessionExpiresAt *metav1.Time
+	}{
+		{
+			name:                 "no session lifetimes",
+			now:                  authTime.Add(100 * time.Hour),
+			wantSessionExpiresAt: nil,
+		},
+		{
+			name:                 "no session lifetimes anymore",
+			sessionExpiresAt:     metaTime(authTime.Add(time.Hour)),
+			now:                  authTime.Add(time.Minute),
+			wantSessionExpiresAt: nil,
+		},
+		{
+			name:                 "idle timeout extends the session",
+			sessionLifetimes:     provider.SessionLifetimes{IdleTimeout: 30 * time.Minute},
+			sessionExpiresAt:     metaTime(authTime.Add(time.Hour)),
+			now:                  authTime.Add(50 * time.Minute),
+			wantSessionExpiresAt: metaTime(authTime.Add(80 * time.Minute)),
+		},
+		{
+			name:             "idle timeout reached",
+			sessionLifetimes: provider.SessionLifetimes{IdleTimeout: 30 * time.Minute},
+			sessionExpiresAt: metaTime(authTime.Add(time.Hour)),
+			now:              authTime.Add(time.Hour + time.Second),
+			wantErr:          "The provided authorization grant (e.g., authorization code, resource owner credentials) or refresh token is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client. The session has reached its idle timeout or its maximum lifetime.",
+		},
+		{
+			name:                 "idle timeout does not extend the session beyond its maximum lifetime",
+			sessionLifetimes:     provider.SessionLifetimes{IdleTimeout: 30 * time.Minute, MaxLifetime: 12 * time.Hour},
+			sessionExpiresAt:     metaTime(authTime.Add(12 * time.Hour)),
+			now:                  authTime.Add(11*time.Hour + 50*time.Minute),
+			wantSessionExpiresAt: metaTime(authTime.Add(12 * time.Hour)),
+		},
+		{
+			name:             "maximum lifetime which was configured after the session was last extended",
+			sessionLifetimes: provider.SessionLifetimes{MaxLifetime: 12 * time.Hour},
+			now:              authTime.Add(12*time.Hour + time.Second),
+			wantErr:          "The provided authorization grant (e.g., authorization code, resource owner credentials) or refresh token is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client. The session has reached its maximum lifetime.",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			session := &psession.PinnipedSession{
+				Fosite: &openid.DefaultSession{Claims: &jwt.IDTokenClaims{AuthTime: authTime}},
+				Custom: &psession.CustomSessionData{SessionExpiresAt: test.sessionExpiresAt},
+			}
+			request := &fosite.AccessRequest{Request: fosite.Request{ID: "some-request-id", Session: session}}
+
+			err := checkSessionLifetimes(request, test.sessionLifetimes, test.now)
+			if test.wantErr != "" {
+				require.EqualError(t, err, "invalid_grant")
+				require.Equal(t, test.wantErr, fosite.ErrorToRFC6749Error(err).GetDescription())
+				return
+			}
+			require.NoError(t, err)
+
+			extendSession(request, test.sessionLifetimes, test.now)
+			require.Equal(t, test.wantSessionExpiresAt, session.Custom.SessionExpiresAt)
+		})
+	}
+}
+
 type RecordedWarning struct {
 	Agent string
 	Text  string
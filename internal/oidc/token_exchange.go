@@ -1,19 +1,25 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
 	"github.com/ory/fosite/handler/oauth2"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/pkg/errors"
+	"k8s.io/utils/strings/slices"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/psession"
 )
 
@@ -27,20 +33,27 @@ type stsParams struct {
 	requestedAudience  string
 }
 
-func TokenExchangeFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
-	return &TokenExchangeHandler{
-		idTokenStrategy:     strategy.(openid.OpenIDConnectTokenStrategy),
-		accessTokenStrategy: strategy.(oauth2.AccessTokenStrategy),
-		accessTokenStorage:  storage.(oauth2.AccessTokenStorage),
-		fositeConfig:        config,
+// NewTokenExchangeFactory returns a factory for the TokenExchangeHandler. The defaultAllowedAudiences are the audiences
+// which may be requested by the clients which do not configure their own allowed audiences. Any audience may be
+// requested when neither the client nor the defaultAllowedAudiences list any audiences.
+func NewTokenExchangeFactory(defaultAllowedAudiences []string) compose.Factory {
+	return func(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+		return &TokenExchangeHandler{
+			idTokenStrategy:         strategy.(openid.OpenIDConnectTokenStrategy),
+			accessTokenStrategy:     strategy.(oauth2.AccessTokenStrategy),
+			accessTokenStorage:      storage.(oauth2.AccessTokenStorage),
+			fositeConfig:            config,
+			defaultAllowedAudiences: defaultAllowedAudiences,
+		}
 	}
 }
 
 type TokenExchangeHandler struct {
-	idTokenStrategy     openid.OpenIDConnectTokenStrategy
-	accessTokenStrategy oauth2.AccessTokenStrategy
-	accessTokenStorage  oauth2.AccessTokenStorage
-	fositeConfig        fosite.Configurator
+	idTokenStrategy         openid.OpenIDConnectTokenStrategy
+	accessTokenStrategy     oauth2.AccessTokenStrategy
+	accessTokenStorage      oauth2.AccessTokenStorage
+	fositeConfig            fosite.Configurator
+	defaultAllowedAudiences []string
 }
 
 var _ fosite.TokenEndpointHandler = (*TokenExchangeHandler)(nil)
@@ -82,6 +95,11 @@ func (t *TokenExchangeHandler) PopulateTokenEndpointResponse(ctx context.Context
 		return errors.WithStack(fosite.ErrUnauthorizedClient.WithHintf(`The OAuth 2.0 Client is not allowed to use token exchange grant "%s".`, oidcapi.GrantTypeTokenExchange))
 	}
 
+	// Check that the client is allowed to request the audience.
+	if !t.audienceIsAllowed(requester.GetClient(), params.requestedAudience) {
+		return errors.WithStack(errInvalidTarget().WithHintf("The requested audience %q is not allowed for this client.", params.requestedAudience))
+	}
+
 	// Require that the incoming access token has the pinniped:request-audience and OpenID scopes.
 	if !originalRequester.GetGrantedScopes().Has(oidcapi.ScopeRequestAudience) {
 		return errors.WithStack(fosite.ErrAccessDenied.WithHintf("Missing the %q scope.", oidcapi.ScopeRequestAudience))
@@ -96,11 +114,19 @@ func (t *TokenExchangeHandler) PopulateTokenEndpointResponse(ctx context.Context
 	}
 
 	// Use the original authorize request information, along with the requested audience, to mint a new JWT.
-	responseToken, err := t.mintJWT(ctx, originalRequester, params.requestedAudience)
+	// The lifespan and the groups of the new JWT are determined by the currently authenticated client, since it may
+	// have a custom ID token lifespan and a groups filter.
+	responseToken, err := t.mintJWT(ctx, requester.GetClient(), originalRequester, params.requestedAudience)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	// The requester of the token exchange does not hold the session, so audit the original requester instead.
+	record := auditlog.NewRecord(auditlog.EventTokenExchanged, nil, originalRequester)
+	record.SourceIP = authenticators.ClientIPFromContext(ctx)
+	record.Audience = params.requestedAudience
+	auditlog.Log(record)
+
 	// Format the response parameters according to RFC8693.
 	responder.SetAccessToken(responseToken)
 	responder.SetTokenType("N_A")
@@ -108,17 +134,41 @@ func (t *TokenExchangeHandler) PopulateTokenEndpointResponse(ctx context.Context
 	return nil
 }
 
-func (t *TokenExchangeHandler) mintJWT(ctx context.Context, requester fosite.Requester, audience string) (string, error) {
-	downscoped := fosite.NewAccessRequest(requester.GetSession())
+func (t *TokenExchangeHandler) mintJWT(ctx context.Context, client fosite.Client, requester fosite.Requester, audience string) (string, error) {
+	session := requester.GetSession()
+	if pSession, ok := session.(*psession.PinnipedSession); ok {
+		// The new JWT is for the requested audience, but it only contains the groups which the client may see.
+		session = filterGroupsForClient(pSession, client)
+	}
+	downscoped := fosite.NewAccessRequest(session)
 	downscoped.Client.(*fosite.DefaultClient).ID = audience
 
-	// Note: if we wanted to support clients with custom token lifespans, then we would need to call
-	// fosite.GetEffectiveLifespan() to determine the lifespan here.
-	idTokenLifespan := t.fositeConfig.GetIDTokenLifespan(ctx)
+	idTokenLifespan := fosite.GetEffectiveLifespan(client,
+		oidcapi.GrantTypeTokenExchange, fosite.IDToken, t.fositeConfig.GetIDTokenLifespan(ctx))
 
 	return t.idTokenStrategy.GenerateIDToken(ctx, idTokenLifespan, downscoped)
 }
 
+// audienceIsAllowed checks the requested audience against the allowed audiences of the client, or against the default
+// allowed audiences when the client does not configure its own.
+func (t *TokenExchangeHandler) audienceIsAllowed(client fosite.Client, audience string) bool {
+	allowedAudiences := t.defaultAllowedAudiences
+	if c, ok := client.(*clientregistry.Client); ok && len(c.TokenExchangeAudiences) > 0 {
+		allowedAudiences = c.TokenExchangeAudiences
+	}
+	return len(allowedAudiences) == 0 || slices.Contains(allowedAudiences, audience)
+}
+
+// errInvalidTarget is the invalid_target error which is defined by RFC8693 for the case when the authorization server
+// is unwilling to issue a token for the requested audience.
+func errInvalidTarget() *fosite.RFC6749Error {
+	return &fosite.RFC6749Error{
+		ErrorField:       "invalid_target",
+		DescriptionField: "The authorization server is unwilling to issue a token for the requested audience.",
+		CodeField:        http.StatusBadRequest,
+	}
+}
+
 func (t *TokenExchangeHandler) validateSession(requester fosite.Requester) error {
 	pSession, ok := requester.GetSession().(*psession.PinnipedSession)
 	if !ok {
@@ -0,0 +1,75 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"testing"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/oidc/clientregistry"
+)
+
+func TestTokenExchangeHandler_AudienceIsAllowed(t *testing.T) {
+	clientWithoutAudiences := &clientregistry.Client{}
+	clientWithAudiences := &clientregistry.Client{TokenExchangeAudiences: []string{"client-cluster"}}
+
+	tests := []struct {
+		name             string
+		defaultAudiences []string
+		client           fosite.Client
+		audience         string
+		want             bool
+	}{
+		{
+			name:     "no default and no client audiences allows any audience",
+			client:   clientWithoutAudiences,
+			audience: "any-cluster",
+			want:     true,
+		},
+		{
+			name:             "default audiences allow their audiences",
+			defaultAudiences: []string{"default-cluster", "other-default-cluster"},
+			client:           clientWithoutAudiences,
+			audience:         "other-default-cluster",
+			want:             true,
+		},
+		{
+			name:             "default audiences reject other audiences",
+			defaultAudiences: []string{"default-cluster"},
+			client:           clientWithoutAudiences,
+			audience:         "any-cluster",
+			want:             false,
+		},
+		{
+			name:             "default audiences apply to clients which are not from the client registry",
+			defaultAudiences: []string{"default-cluster"},
+			client:           &fosite.DefaultClient{},
+			audience:         "any-cluster",
+			want:             false,
+		},
+		{
+			name:             "client audiences take precedence over the default audiences",
+			defaultAudiences: []string{"default-cluster"},
+			client:           clientWithAudiences,
+			audience:         "client-cluster",
+			want:             true,
+		},
+		{
+			name:             "client audiences replace the default audiences",
+			defaultAudiences: []string{"default-cluster"},
+			client:           clientWithAudiences,
+			audience:         "default-cluster",
+			want:             false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			subject := &TokenExchangeHandler{defaultAllowedAudiences: tt.defaultAudiences}
+			require.Equal(t, tt.want, subject.audienceIsAllowed(tt.client, tt.audience))
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientsecretstorage
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -38,11 +39,34 @@ type OIDCClientSecretStorage struct {
 type storedClientSecret struct {
 	// List of bcrypt hashes.
 	SecretHashes []string `json:"hashes"`
+	// Optional details about the hashes, keyed by hash. Older versions of the Supervisor did not store these details,
+	// and ignore them, so they treat client secrets which expire as valid until they are revoked.
+	SecretDetails map[string]storedClientSecretDetails `json:"details,omitempty"`
 	// The format version. Take care when updating. We cannot simply bump the storage version and drop/ignore old data.
 	// Updating this would require some form of migration of existing stored data.
 	Version string `json:"version"`
 }
 
+type storedClientSecretDetails struct {
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// ClientSecret is a hashed client secret of an OIDCClient.
+type ClientSecret struct {
+	// The bcrypt hash of the client secret.
+	Hash string
+	// When the client secret was generated, or nil when it was stored by an older version of the Supervisor.
+	CreatedAt *metav1.Time
+	// When the client secret expires, or nil when it remains valid until it is revoked.
+	ExpiresAt *metav1.Time
+}
+
+// IsExpired returns true when the client secret has expired at the given time.
+func (c ClientSecret) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(c.ExpiresAt.Time)
+}
+
 func New(secrets corev1client.SecretInterface) *OIDCClientSecretStorage {
 	return &OIDCClientSecretStorage{
 		storage: crud.New(TypeLabelValue, secrets, nil, 0), // can use nil clock because we are using infinite lifetime
@@ -54,6 +78,16 @@ func New(secrets corev1client.SecretInterface) *OIDCClientSecretStorage {
 // When the storage secret is not found, it will simply return "", nil, nil to make it easy to pass the
 // results of Get directly to Set.
 func (s *OIDCClientSecretStorage) Get(ctx context.Context, oidcClientUID types.UID) (string, []string, error) {
+	rv, clientSecrets, err := s.GetClientSecrets(ctx, oidcClientUID)
+	if err != nil {
+		return "", nil, err
+	}
+	return rv, hashes(clientSecrets), nil
+}
+
+// GetClientSecrets is like Get, but returns the client secrets along with their details, including the client secrets
+// which have expired.
+func (s *OIDCClientSecretStorage) GetClientSecrets(ctx context.Context, oidcClientUID types.UID) (string, []ClientSecret, error) {
 	clientSecret := &storedClientSecret{}
 	rv, err := s.storage.Get(ctx, uidToName(oidcClientUID), clientSecret)
 	if errors.IsNotFound(err) {
@@ -66,7 +100,7 @@ func (s *OIDCClientSecretStorage) Get(ctx context.Context, oidcClientUID types.U
 		return "", nil, fmt.Errorf("%w: OIDC client secret storage has version %s instead of %s",
 			ErrOIDCClientSecretStorageVersion, clientSecret.Version, oidcClientSecretStorageVersion)
 	}
-	return rv, clientSecret.SecretHashes, nil
+	return rv, clientSecret.toClientSecrets(), nil
 }
 
 // Set will create or update the values of the storage secret associated with an OIDCClient.
@@ -74,10 +108,31 @@ func (s *OIDCClientSecretStorage) Get(ctx context.Context, oidcClientUID types.U
 // Set takes the oidcClientName to set up the owner reference of the storage secret to that of the OIDCClient.
 // Set takes the oidcClientUID to find the correct storage secret.
 func (s *OIDCClientSecretStorage) Set(ctx context.Context, resourceVersion, oidcClientName string, oidcClientUID types.UID, secretHashes []string) error {
+	clientSecrets := make([]ClientSecret, 0, len(secretHashes))
+	for _, hash := range secretHashes {
+		clientSecrets = append(clientSecrets, ClientSecret{Hash: hash})
+	}
+	return s.SetClientSecrets(ctx, resourceVersion, oidcClientName, oidcClientUID, clientSecrets)
+}
+
+// SetClientSecrets is like Set, but also stores the details of the client secrets.
+func (s *OIDCClientSecretStorage) SetClientSecrets(ctx context.Context, resourceVersion, oidcClientName string, oidcClientUID types.UID, clientSecrets []ClientSecret) error {
 	secret := &storedClientSecret{
-		SecretHashes: secretHashes,
+		SecretHashes: hashes(clientSecrets),
 		Version:      oidcClientSecretStorageVersion,
 	}
+	for _, clientSecret := range clientSecrets {
+		if clientSecret.CreatedAt == nil && clientSecret.ExpiresAt == nil {
+			continue
+		}
+		if secret.SecretDetails == nil {
+			secret.SecretDetails = map[string]storedClientSecretDetails{}
+		}
+		secret.SecretDetails[clientSecret.Hash] = storedClientSecretDetails{
+			CreatedAt: clientSecret.CreatedAt,
+			ExpiresAt: clientSecret.ExpiresAt,
+		}
+	}
 	name := uidToName(oidcClientUID)
 
 	if mustBeCreate := len(resourceVersion) == 0; mustBeCreate {
@@ -126,8 +181,26 @@ func uidToName(oidcClientUID types.UID) string {
 	return base64.RawURLEncoding.EncodeToString([]byte(oidcClientUID))
 }
 
-// ReadFromSecret reads the contents of a Secret as a storedClientSecret and returns the associated hashes.
+// ReadFromSecret reads the contents of a Secret as a storedClientSecret and returns the associated hashes, except for
+// the hashes of the client secrets which have expired.
 func ReadFromSecret(secret *corev1.Secret) ([]string, error) {
+	clientSecrets, err := ReadClientSecretsFromSecret(secret)
+	if err != nil || clientSecrets == nil {
+		return nil, err
+	}
+	now := time.Now()
+	activeHashes := make([]string, 0, len(clientSecrets))
+	for _, clientSecret := range clientSecrets {
+		if !clientSecret.IsExpired(now) {
+			activeHashes = append(activeHashes, clientSecret.Hash)
+		}
+	}
+	return activeHashes, nil
+}
+
+// ReadClientSecretsFromSecret reads the contents of a Secret as a storedClientSecret and returns all the associated
+// client secrets along with their details.
+func ReadClientSecretsFromSecret(secret *corev1.Secret) ([]ClientSecret, error) {
 	clientSecret := &storedClientSecret{}
 	err := crud.FromSecret(TypeLabelValue, secret, clientSecret)
 	if err != nil {
@@ -137,5 +210,32 @@ func ReadFromSecret(secret *corev1.Secret) ([]string, error) {
 		return nil, fmt.Errorf("%w: OIDC client secret storage has version %s instead of %s",
 			ErrOIDCClientSecretStorageVersion, clientSecret.Version, oidcClientSecretStorageVersion)
 	}
-	return clientSecret.SecretHashes, nil
+	return clientSecret.toClientSecrets(), nil
+}
+
+func (s *storedClientSecret) toClientSecrets() []ClientSecret {
+	if s.SecretHashes == nil {
+		return nil
+	}
+	clientSecrets := make([]ClientSecret, 0, len(s.SecretHashes))
+	for _, hash := range s.SecretHashes {
+		details := s.SecretDetails[hash]
+		clientSecrets = append(clientSecrets, ClientSecret{
+			Hash:      hash,
+			CreatedAt: details.CreatedAt,
+			ExpiresAt: details.ExpiresAt,
+		})
+	}
+	return clientSecrets
+}
+
+func hashes(clientSecrets []ClientSecret) []string {
+	if clientSecrets == nil {
+		return nil
+	}
+	result := make([]string, 0, len(clientSecrets))
+	for _, clientSecret := range clientSecrets {
+		result = append(result, clientSecret.Hash)
+	}
+	return result
 }
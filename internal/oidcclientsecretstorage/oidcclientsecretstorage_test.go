@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientsecretstorage
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -422,6 +423,60 @@ func TestGetName(t *testing.T) {
 		subject.GetName("some-example-uid2"))
 }
 
+func TestSetClientSecretsAndGetClientSecrets(t *testing.T) {
+	ctx := context.Background()
+	subject := New(fake.NewSimpleClientset().CoreV1().Secrets("some-namespace"))
+
+	// Times are read back in the local time zone, see metav1.Time.UnmarshalJSON.
+	createdAt := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Local())
+	expiresAt := metav1.NewTime(createdAt.Add(time.Hour))
+	clientSecrets := []ClientSecret{
+		{Hash: "new-hash", CreatedAt: &createdAt},
+		{Hash: "old-hash", CreatedAt: &createdAt, ExpiresAt: &expiresAt},
+		{Hash: "hash-from-older-supervisor"},
+	}
+	require.NoError(t, subject.SetClientSecrets(ctx, "", "some-client", "some-uid", clientSecrets))
+
+	secret, err := subject.GetStorageSecret(ctx, "some-uid")
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"hashes": ["new-hash", "old-hash", "hash-from-older-supervisor"],
+		"details": {
+			"new-hash": {"createdAt": "2026-01-02T03:04:05Z"},
+			"old-hash": {"createdAt": "2026-01-02T03:04:05Z", "expiresAt": "2026-01-02T04:04:05Z"}
+		},
+		"version": "1"
+	}`, string(secret.Data["pinniped-storage-data"]))
+
+	rv, gotClientSecrets, err := subject.GetClientSecrets(ctx, "some-uid")
+	require.NoError(t, err)
+	require.Equal(t, secret.ResourceVersion, rv)
+	require.Equal(t, clientSecrets, gotClientSecrets)
+
+	_, gotHashes, err := subject.Get(ctx, "some-uid")
+	require.NoError(t, err)
+	require.Equal(t, []string{"new-hash", "old-hash", "hash-from-older-supervisor"}, gotHashes)
+
+	gotClientSecrets, err = ReadClientSecretsFromSecret(secret)
+	require.NoError(t, err)
+	require.Equal(t, clientSecrets, gotClientSecrets)
+
+	// The old client secret has expired by now.
+	gotHashes, err = ReadFromSecret(secret)
+	require.NoError(t, err)
+	require.Equal(t, []string{"new-hash", "hash-from-older-supervisor"}, gotHashes)
+}
+
+func TestClientSecretIsExpired(t *testing.T) {
+	now := time.Now()
+	expiresAt := metav1.NewTime(now)
+
+	require.False(t, ClientSecret{Hash: "some-hash"}.IsExpired(now))
+	require.False(t, ClientSecret{Hash: "some-hash", ExpiresAt: &expiresAt}.IsExpired(now.Add(-time.Second)))
+	require.True(t, ClientSecret{Hash: "some-hash", ExpiresAt: &expiresAt}.IsExpired(now))
+	require.True(t, ClientSecret{Hash: "some-hash", ExpiresAt: &expiresAt}.IsExpired(now.Add(time.Second)))
+}
+
 func TestReadFromSecret(t *testing.T) {
 	tests := []struct {
 		name       string
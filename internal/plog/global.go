@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package plog
@@ -17,10 +17,11 @@ import (
 
 //nolint:gochecknoglobals
 var (
-	// note that these globals have no locks on purpose - they are expected to be set at init and then again after config parsing.
+	// the logger is set at init, again after config parsing, and again whenever the log config is reloaded.
 	globalLevel  zap.AtomicLevel
 	globalLogger logr.Logger
 	globalFlush  func()
+	globalLock   sync.RWMutex // guards globalLogger and globalFlush, because they may be reloaded while in use
 
 	// used as a temporary storage for a buffer per call of newLogr. see the init function below for more details.
 	sinkMap sync.Map
@@ -57,6 +58,8 @@ func init() {
 // Deprecated: Use New instead.  This is meant for old code only.
 // New provides a more ergonomic API and correctly responds to global log config change.
 func Logr() logr.Logger {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 	return globalLogger
 }
 
@@ -64,14 +67,23 @@ func Setup() func() {
 	logs.InitLogs()
 	return func() {
 		logs.FlushLogs()
-		globalFlush()
+		flushGlobalLogger()
 	}
 }
 
-// setGlobalLoggers sets the plog and klog global loggers.  it is *not* go routine safe.
+// setGlobalLoggers sets the plog and klog global loggers.
 func setGlobalLoggers(log logr.Logger, flush func()) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
 	// a contextual logger does its own level based enablement checks, which is true for all of our loggers
 	klog.SetLoggerWithOptions(log, klog.ContextualLogger(true), klog.FlushLogger(flush))
 	globalLogger = log
 	globalFlush = flush
 }
+
+func flushGlobalLogger() {
+	globalLock.RLock()
+	flush := globalFlush
+	globalLock.RUnlock()
+	flush()
+}
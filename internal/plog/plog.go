@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package plog implements a thin layer over logr to help enforce pinniped's logging convention.
@@ -254,6 +254,6 @@ func WithName(name string) Logger {
 
 func Fatal(err error, keysAndValues ...interface{}) {
 	logger.Error("unrecoverable error encountered", err, keysAndValues...)
-	globalFlush()
+	flushGlobalLogger()
 	os.Exit(1)
 }
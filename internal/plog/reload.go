@@ -0,0 +1,86 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
+)
+
+// Kubernetes updates the config file of a pod about a minute after its ConfigMap changed.
+const configFileReloadInterval = time.Minute
+
+// WatchConfigFile periodically reads the log level and format from the config file at path, which Kubernetes updates
+// in place when its ConfigMap changes, and applies any changes to them without a restart. The Supervisor and the
+// Concierge share this, because their config files have the same log settings. Invalid log settings are logged and
+// ignored, so the previous log settings stay in effect.
+func WatchConfigFile(ctx context.Context, path string, current LogSpec) {
+	w := &configFileWatcher{path: path, current: current}
+	go wait.UntilWithContext(ctx, w.reload, configFileReloadInterval)
+}
+
+type configFileWatcher struct {
+	path    string
+	current LogSpec
+
+	// cancel stops the go routines which flush the logger of the previous reload.
+	cancel context.CancelFunc
+}
+
+func (w *configFileWatcher) reload(ctx context.Context) {
+	spec, err := logSpecFromPath(w.path)
+	if err != nil {
+		WarningErr("could not reload log config, continuing to use the previous log config", err, "path", w.path)
+		return
+	}
+	if spec == w.current {
+		return
+	}
+
+	loggerCtx, cancel := context.WithCancel(ctx)
+	if err := ValidateAndSetLogLevelAndFormatGlobally(loggerCtx, spec); err != nil {
+		cancel()
+		WarningErr("could not reload log config, continuing to use the previous log config", err, "path", w.path)
+		return
+	}
+	if w.cancel != nil {
+		w.cancel() // also flushes the previous logger one last time
+	}
+	w.cancel = cancel
+	w.current = spec
+
+	Always("reloaded log config", "logLevel", spec.Level, "logFormat", spec.Format)
+}
+
+// logSpecFromPath reads only the log settings of the config file of the Supervisor or the Concierge.
+func logSpecFromPath(path string) (LogSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LogSpec{}, fmt.Errorf("read file: %w", err)
+	}
+
+	var config struct {
+		// Deprecated: use log.level instead
+		LogLevel *LogLevel `json:"logLevel"`
+		Log      LogSpec   `json:"log"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return LogSpec{}, fmt.Errorf("decode yaml: %w", err)
+	}
+
+	// Like MaybeSetDeprecatedLogLevel, but without warning again about the deprecation on every reload.
+	if config.LogLevel != nil {
+		config.Log.Level = *config.LogLevel
+	}
+	if klogLevelForPlogLevel(config.Log.Level) < 0 {
+		return LogSpec{}, errInvalidLogLevel
+	}
+
+	return config.Log, nil
+}
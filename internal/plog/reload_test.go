@@ -0,0 +1,74 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFileWatcher(t *testing.T) {
+	originalLogLevel := getKlogLevel()
+	t.Cleanup(func() {
+		undoGlobalLogLevelChanges(t, originalLogLevel)
+	})
+
+	var buf bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ctx = TestZapOverrides(ctx, t, &buf, nil)
+	require.NoError(t, ValidateAndSetLogLevelAndFormatGlobally(ctx, LogSpec{Level: LevelInfo}))
+
+	path := filepath.Join(t.TempDir(), "pinniped.yaml")
+	writeConfig := func(config string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+	}
+	w := &configFileWatcher{path: path, current: LogSpec{Level: LevelInfo}}
+
+	// Nothing happens while the log config is unchanged.
+	writeConfig("apiGroupSuffix: pinniped.dev\nlog:\n  level: info\n")
+	w.reload(ctx)
+	require.Empty(t, buf.String())
+	require.True(t, Enabled(LevelInfo))
+	require.False(t, Enabled(LevelDebug))
+
+	writeConfig("apiGroupSuffix: pinniped.dev\nlog:\n  level: debug\n")
+	w.reload(ctx)
+	require.Contains(t, buf.String(), `"message":"reloaded log config","logLevel":"debug"`)
+	require.True(t, Enabled(LevelDebug))
+	require.False(t, Enabled(LevelTrace))
+	require.Equal(t, LogSpec{Level: LevelDebug}, w.current)
+
+	// The deprecated logLevel still works.
+	writeConfig("logLevel: trace\n")
+	w.reload(ctx)
+	require.True(t, Enabled(LevelTrace))
+	require.Equal(t, LogSpec{Level: LevelTrace}, w.current)
+
+	// Invalid log configs are ignored.
+	buf.Reset()
+	writeConfig("log:\n  level: panda\n")
+	w.reload(ctx)
+	require.Contains(t, buf.String(), `"message":"could not reload log config, continuing to use the previous log config"`)
+	require.Contains(t, buf.String(), errInvalidLogLevel.Error())
+	require.True(t, Enabled(LevelTrace))
+
+	buf.Reset()
+	writeConfig("log:\n  format: xml\n")
+	w.reload(ctx)
+	require.Contains(t, buf.String(), errInvalidLogFormat.Error())
+	require.Equal(t, LogSpec{Level: LevelTrace}, w.current)
+
+	buf.Reset()
+	require.NoError(t, os.Remove(path))
+	w.reload(ctx)
+	require.Contains(t, buf.String(), "read file: open "+path)
+	require.True(t, Enabled(LevelTrace))
+}
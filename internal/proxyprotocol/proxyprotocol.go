@@ -0,0 +1,304 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package proxyprotocol implements a net.Listener which accepts connections that begin with a PROXY protocol
+// header, as sent by L4 load balancers, so that the addresses of the original clients are known.
+//
+// See https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt for the specification of versions 1 and 2.
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHeaderTimeout is how long to wait for the PROXY protocol header of each connection.
+	DefaultHeaderTimeout = 10 * time.Second
+
+	// v1MaxLength is the maximum length of a version 1 header, including the CRLF.
+	v1MaxLength = 107
+
+	v1Prefix    = "PROXY "
+	v2Signature = "\r\n\r\n\x00\r\nQUIT\n"
+)
+
+// Config configures a Listener.
+type Config struct {
+	// TrustedNetworks are the networks of the load balancers which may send PROXY protocol headers. Connections
+	// from any other peer are closed. When empty, every peer is trusted.
+	TrustedNetworks []*net.IPNet
+
+	// HeaderTimeout is how long to wait for the PROXY protocol header of each connection. When zero,
+	// DefaultHeaderTimeout is used.
+	HeaderTimeout time.Duration
+}
+
+// NewListener wraps a listener so that every accepted connection must begin with a PROXY protocol header. The
+// header is read when the connection is first used, rather than in Accept, so that a slow peer does not block
+// other connections from being accepted. The RemoteAddr and LocalAddr of the connections are the addresses
+// which are described by the header.
+func NewListener(inner net.Listener, config Config) net.Listener {
+	if config.HeaderTimeout == 0 {
+		config.HeaderTimeout = DefaultHeaderTimeout
+	}
+	return &listener{Listener: inner, config: config}
+}
+
+type listener struct {
+	net.Listener
+	config Config
+}
+
+// Accept implements net.Listener.
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, config: &l.config, reader: bufio.NewReader(c)}, nil
+}
+
+type conn struct {
+	net.Conn
+	config *Config
+	reader *bufio.Reader
+
+	once       sync.Once
+	err        error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+
+	deadlineLock sync.Mutex
+	readDeadline time.Time
+}
+
+// Read implements net.Conn.
+func (c *conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr implements net.Conn. It returns the address of the original client.
+func (c *conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr implements net.Conn. It returns the address to which the original client connected.
+func (c *conn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+// SetDeadline implements net.Conn.
+func (c *conn) SetDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.readDeadline = t
+	c.deadlineLock.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline implements net.Conn.
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.readDeadline = t
+	c.deadlineLock.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *conn) readHeader() {
+	if !c.trusted() {
+		c.err = fmt.Errorf("proxy protocol: connection from untrusted peer %s", c.Conn.RemoteAddr())
+		return
+	}
+
+	// Limit how long reading the header may take, without losing any deadline which the caller already set.
+	c.deadlineLock.Lock()
+	callerDeadline := c.readDeadline
+	c.deadlineLock.Unlock()
+	deadline := time.Now().Add(c.config.HeaderTimeout)
+	if !callerDeadline.IsZero() && callerDeadline.Before(deadline) {
+		deadline = callerDeadline
+	}
+	if err := c.Conn.SetReadDeadline(deadline); err != nil {
+		c.err = fmt.Errorf("proxy protocol: %w", err)
+		return
+	}
+	defer func() { _ = c.Conn.SetReadDeadline(callerDeadline) }()
+
+	remoteAddr, localAddr, err := readHeader(c.reader)
+	if err != nil {
+		c.err = fmt.Errorf("proxy protocol: %w", err)
+		return
+	}
+	c.remoteAddr, c.localAddr = remoteAddr, localAddr
+}
+
+func (c *conn) trusted() bool {
+	if len(c.config.TrustedNetworks) == 0 {
+		return true
+	}
+	addr, ok := c.Conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, network := range c.config.TrustedNetworks {
+		if network.Contains(addr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// readHeader reads a version 1 or version 2 header. It returns nil addresses when the header does not describe
+// the original connection, e.g. for health checks of the load balancer, in which case the addresses of the
+// connection itself should be used.
+func readHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	// Decide by the first byte, so that a client which does not send a header is rejected without waiting for
+	// more bytes than it sent.
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read header: %w", err)
+	}
+
+	var signature string
+	switch first[0] {
+	case v1Prefix[0]:
+		signature = v1Prefix
+	case v2Signature[0]:
+		signature = v2Signature
+	default:
+		return nil, nil, errors.New("missing header")
+	}
+
+	peek, err := r.Peek(len(signature))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read header: %w", err)
+	}
+	if string(peek) != signature {
+		return nil, nil, errors.New("missing header")
+	}
+	if signature == v1Prefix {
+		return readV1Header(r)
+	}
+	return readV2Header(r)
+}
+
+func readV1Header(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= v1MaxLength {
+			return nil, nil, errors.New("invalid version 1 header: too long")
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("invalid version 1 header: missing CRLF")
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("invalid version 1 header: %q", line)
+	}
+
+	source, err := parseV1Addr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid version 1 header: %w", err)
+	}
+	destination, err := parseV1Addr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid version 1 header: %w", err)
+	}
+	return source, destination, nil
+}
+
+func parseV1Addr(protocol, host, port string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(host)
+	if ip == nil || (protocol == "TCP4") != (ip.To4() != nil) {
+		return nil, fmt.Errorf("invalid %s address %q", protocol, host)
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", port)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(portNumber)}, nil
+}
+
+func readV2Header(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, len(v2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("could not read header: %w", err)
+	}
+	versionAndCommand, family := header[12], header[13]
+	length := int(binary.BigEndian.Uint16(header[14:16]))
+
+	if versionAndCommand>>4 != 2 {
+		return nil, nil, fmt.Errorf("invalid version 2 header: unsupported version %d", versionAndCommand>>4)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, fmt.Errorf("could not read header: %w", err)
+	}
+
+	switch versionAndCommand & 0x0f {
+	case 0x0: // LOCAL, e.g. a health check of the load balancer
+		return nil, nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, nil, fmt.Errorf("invalid version 2 header: unsupported command %d", versionAndCommand&0x0f)
+	}
+
+	var ipLength int
+	switch family {
+	case 0x11: // TCP over IPv4
+		ipLength = net.IPv4len
+	case 0x21: // TCP over IPv6
+		ipLength = net.IPv6len
+	default: // UNSPEC, UDP, or UNIX sockets, which do not describe a TCP client
+		return nil, nil, nil
+	}
+
+	// The addresses are followed by optional TLVs, which are ignored.
+	if length < 2*ipLength+4 {
+		return nil, nil, errors.New("invalid version 2 header: addresses are truncated")
+	}
+	source := &net.TCPAddr{
+		IP:   net.IP(append([]byte(nil), payload[:ipLength]...)),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLength:])),
+	}
+	destination := &net.TCPAddr{
+		IP:   net.IP(append([]byte(nil), payload[ipLength:2*ipLength]...)),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLength+2:])),
+	}
+	return source, destination, nil
+}
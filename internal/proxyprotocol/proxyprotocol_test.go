@@ -0,0 +1,247 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package proxyprotocol
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeader(t *testing.T) {
+	t.Parallel()
+
+	v2Header := func(versionAndCommand, family byte, payload ...byte) string {
+		header := []byte(v2Signature)
+		header = append(header, versionAndCommand, family, byte(len(payload)>>8), byte(len(payload)))
+		return string(append(header, payload...))
+	}
+
+	tests := []struct {
+		name            string
+		input           string
+		wantRemoteAddr  string
+		wantLocalAddr   string
+		wantErr         string
+		wantRemainingIn string
+	}{
+		{
+			name:            "version 1 TCP4",
+			input:           "PROXY TCP4 192.0.2.1 198.51.100.2 56324 443\r\nGET /",
+			wantRemoteAddr:  "192.0.2.1:56324",
+			wantLocalAddr:   "198.51.100.2:443",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name:            "version 1 TCP6",
+			input:           "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\nGET /",
+			wantRemoteAddr:  "[2001:db8::1]:56324",
+			wantLocalAddr:   "[2001:db8::2]:443",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name:            "version 1 UNKNOWN",
+			input:           "PROXY UNKNOWN\r\nGET /",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name:    "version 1 with an IPv6 address for TCP4",
+			input:   "PROXY TCP4 2001:db8::1 198.51.100.2 56324 443\r\n",
+			wantErr: `invalid version 1 header: invalid TCP4 address "2001:db8::1"`,
+		},
+		{
+			name:    "version 1 with an invalid port",
+			input:   "PROXY TCP4 192.0.2.1 198.51.100.2 70000 443\r\n",
+			wantErr: `invalid version 1 header: invalid port "70000"`,
+		},
+		{
+			name:    "version 1 with missing fields",
+			input:   "PROXY TCP4 192.0.2.1\r\n",
+			wantErr: `invalid version 1 header: "PROXY TCP4 192.0.2.1\r\n"`,
+		},
+		{
+			name:    "version 1 without CRLF",
+			input:   "PROXY TCP4 192.0.2.1 198.51.100.2 56324 443\n",
+			wantErr: "invalid version 1 header: missing CRLF",
+		},
+		{
+			name:    "version 1 which is too long",
+			input:   "PROXY " + strings.Repeat("x", 200),
+			wantErr: "invalid version 1 header: too long",
+		},
+		{
+			name: "version 2 TCP4 with TLVs",
+			input: v2Header(0x21, 0x11,
+				192, 0, 2, 1, 198, 51, 100, 2, 0xdc, 0x04, 0x01, 0xbb,
+				0x04, 0x00, 0x01, 0x00, // a TLV which is ignored
+			) + "GET /",
+			wantRemoteAddr:  "192.0.2.1:56324",
+			wantLocalAddr:   "198.51.100.2:443",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name: "version 2 TCP6",
+			input: v2Header(0x21, 0x21,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2,
+				0xdc, 0x04, 0x01, 0xbb,
+			) + "GET /",
+			wantRemoteAddr:  "[2001:db8::1]:56324",
+			wantLocalAddr:   "[2001:db8::2]:443",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name:            "version 2 LOCAL",
+			input:           v2Header(0x20, 0x00) + "GET /",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name:            "version 2 UNIX socket",
+			input:           v2Header(0x21, 0x31, make([]byte, 216)...) + "GET /",
+			wantRemainingIn: "GET /",
+		},
+		{
+			name:    "version 2 with truncated addresses",
+			input:   v2Header(0x21, 0x11, 192, 0, 2, 1),
+			wantErr: "invalid version 2 header: addresses are truncated",
+		},
+		{
+			name:    "version 2 with an unsupported version",
+			input:   v2Header(0x31, 0x11),
+			wantErr: "invalid version 2 header: unsupported version 3",
+		},
+		{
+			name:    "version 2 with an unsupported command",
+			input:   v2Header(0x22, 0x11),
+			wantErr: "invalid version 2 header: unsupported command 2",
+		},
+		{
+			name:    "missing header",
+			input:   "GET / HTTP/1.1\r\n",
+			wantErr: "missing header",
+		},
+		{
+			name:    "connection closed before the header",
+			input:   "PRO",
+			wantErr: "could not read header: EOF",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			remoteAddr, localAddr, err := readHeader(r)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantRemoteAddr == "" {
+				require.Nil(t, remoteAddr)
+				require.Nil(t, localAddr)
+			} else {
+				require.Equal(t, tt.wantRemoteAddr, remoteAddr.String())
+				require.Equal(t, tt.wantLocalAddr, localAddr.String())
+			}
+
+			remaining, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRemainingIn, string(remaining))
+		})
+	}
+}
+
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		trustedNetworks []string
+		send            string
+		wantRemoteAddr  string
+		wantData        string
+		wantErr         string
+	}{
+		{
+			name:           "header from any peer",
+			send:           "PROXY TCP4 192.0.2.1 198.51.100.2 56324 443\r\nhello",
+			wantRemoteAddr: "192.0.2.1:56324",
+			wantData:       "hello",
+		},
+		{
+			name:            "header from a trusted peer",
+			trustedNetworks: []string{"10.0.0.0/8", "127.0.0.0/8"},
+			send:            "PROXY TCP4 192.0.2.1 198.51.100.2 56324 443\r\nhello",
+			wantRemoteAddr:  "192.0.2.1:56324",
+			wantData:        "hello",
+		},
+		{
+			name:            "untrusted peer",
+			trustedNetworks: []string{"10.0.0.0/8"},
+			send:            "PROXY TCP4 192.0.2.1 198.51.100.2 56324 443\r\nhello",
+			wantErr:         "proxy protocol: connection from untrusted peer 127.0.0.1:",
+		},
+		{
+			name:    "missing header",
+			send:    "hello",
+			wantErr: "proxy protocol: missing header",
+		},
+		{
+			name:    "header timeout",
+			wantErr: "proxy protocol: could not read header: read tcp 127.0.0.1:",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var trustedNetworks []*net.IPNet
+			for _, cidr := range tt.trustedNetworks {
+				_, network, err := net.ParseCIDR(cidr)
+				require.NoError(t, err)
+				trustedNetworks = append(trustedNetworks, network)
+			}
+
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			l := NewListener(inner, Config{TrustedNetworks: trustedNetworks, HeaderTimeout: 100 * time.Millisecond})
+			t.Cleanup(func() { require.NoError(t, l.Close()) })
+
+			client, err := net.Dial("tcp", l.Addr().String())
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = client.Close() })
+			_, err = client.Write([]byte(tt.send))
+			require.NoError(t, err)
+
+			server, err := l.Accept()
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = server.Close() })
+
+			// A deadline which was set before the header was read is kept afterwards.
+			require.NoError(t, server.SetReadDeadline(time.Now().Add(time.Minute)))
+
+			if tt.wantErr != "" {
+				_, err = server.Read(make([]byte, 1))
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Equal(t, client.LocalAddr().String(), server.RemoteAddr().String())
+				return
+			}
+			data := make([]byte, len(tt.wantData))
+			_, err = io.ReadFull(server, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantData, string(data))
+			require.Equal(t, tt.wantRemoteAddr, server.RemoteAddr().String())
+			require.Equal(t, "198.51.100.2:443", server.LocalAddr().String())
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package psession
@@ -10,6 +10,7 @@ import (
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/fosite/token/jwt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -32,6 +33,12 @@ type CustomSessionData struct {
 	// all users must have a username.
 	Username string `json:"username"`
 
+	// UpstreamUsername and UpstreamGroups will contain the identity from the upstream IDP before the identity
+	// transformations of the FederationDomain were applied to it. They are only stored when the FederationDomain
+	// has identity transformations, so that the transformations can be applied again upon refresh.
+	UpstreamUsername string   `json:"upstreamUsername,omitempty"`
+	UpstreamGroups   []string `json:"upstreamGroups,omitempty"`
+
 	// The Kubernetes resource UID of the identity provider CRD for the upstream IDP used to start this session.
 	// This should be validated again upon downstream refresh to make sure that we are not refreshing against
 	// a different identity provider CRD which just happens to have the same name.
@@ -52,12 +59,20 @@ type CustomSessionData struct {
 	// These will be RFC 2616-formatted errors with error code 299.
 	Warnings []string `json:"warnings"`
 
+	// SessionExpiresAt is when this session ends because of the session lifetimes of the FederationDomain, i.e. its
+	// idle timeout or its maximum lifetime, whichever comes first. It is moved forward by each refresh, but never
+	// beyond the maximum lifetime. Nil means that the FederationDomain did not limit the lifetime of the session.
+	SessionExpiresAt *metav1.Time `json:"sessionExpiresAt,omitempty"`
+
 	// Only used when ProviderType == "oidc".
 	OIDC *OIDCSessionData `json:"oidc,omitempty"`
 
 	LDAP *LDAPSessionData `json:"ldap,omitempty"`
 
 	ActiveDirectory *ActiveDirectorySessionData `json:"activedirectory,omitempty"`
+
+	// Only used when ProviderType == "kerberos".
+	Kerberos *KerberosSessionData `json:"kerberos,omitempty"`
 }
 
 type ProviderType string
@@ -66,6 +81,7 @@ const (
 	ProviderTypeOIDC            ProviderType = "oidc"
 	ProviderTypeLDAP            ProviderType = "ldap"
 	ProviderTypeActiveDirectory ProviderType = "activedirectory"
+	ProviderTypeKerberos        ProviderType = "kerberos"
 )
 
 // OIDCSessionData is the additional data needed by Pinniped when the upstream IDP is an OIDC provider.
@@ -97,12 +113,26 @@ type OIDCSessionData struct {
 type LDAPSessionData struct {
 	UserDN                 string            `json:"userDN"`
 	ExtraRefreshAttributes map[string]string `json:"extraRefreshAttributes,omitempty"`
+
+	// LastGroupRefreshTime is when the user's groups were last searched during a refresh. It is only set when the
+	// provider has a group refresh interval. Zero means that the groups have not been searched since the login.
+	LastGroupRefreshTime metav1.Time `json:"lastGroupRefreshTime"`
 }
 
 // ActiveDirectorySessionData is the additional data needed by Pinniped when the upstream IDP is an Active Directory provider.
 type ActiveDirectorySessionData struct {
 	UserDN                 string            `json:"userDN"`
 	ExtraRefreshAttributes map[string]string `json:"extraRefreshAttributes,omitempty"`
+
+	// LastGroupRefreshTime is when the user's groups were last searched during a refresh. It is only set when the
+	// provider has a group refresh interval. Zero means that the groups have not been searched since the login.
+	LastGroupRefreshTime metav1.Time `json:"lastGroupRefreshTime"`
+}
+
+// KerberosSessionData is the additional data needed by Pinniped when the upstream IDP is a Kerberos realm.
+type KerberosSessionData struct {
+	// Principal is the user's Kerberos principal including the realm, e.g. "alice@EXAMPLE.COM".
+	Principal string `json:"principal"`
 }
 
 // NewPinnipedSession returns a new empty session.
@@ -0,0 +1,161 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomon(t *testing.T) {
+	t.Parallel()
+
+	// The example of a version 1-M symbol from the QR code specification.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	require.Equal(t,
+		[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		reedSolomonRemainder(data, reedSolomonDivisor(10)),
+	)
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		text        string
+		wantVersion int
+		wantErr     error
+	}{
+		{
+			name:        "empty",
+			text:        "",
+			wantVersion: 1,
+		},
+		{
+			name:        "largest text of version 1",
+			text:        strings.Repeat("a", 17),
+			wantVersion: 1,
+		},
+		{
+			name:        "smallest text of version 2",
+			text:        strings.Repeat("a", 18),
+			wantVersion: 2,
+		},
+		{
+			name:        "verification URL",
+			text:        "https://example.com/some/path/oauth2/device?user_code=ABCD-EFGH",
+			wantVersion: 4,
+		},
+		{
+			name:        "version with version information",
+			text:        strings.Repeat("a", 200),
+			wantVersion: 9,
+		},
+		{
+			name:        "largest text",
+			text:        strings.Repeat("a", 2953),
+			wantVersion: 40,
+		},
+		{
+			name:    "too long",
+			text:    strings.Repeat("a", 2954),
+			wantErr: ErrTooLong,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, err := Encode(tt.text)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVersion, code.version)
+			require.Equal(t, tt.wantVersion*4+17, code.Size())
+
+			// The finder patterns are in three corners.
+			for _, corner := range [][2]int{{0, 0}, {code.Size() - 7, 0}, {0, code.Size() - 7}} {
+				for dy := 0; dy < 7; dy++ {
+					for dx := 0; dx < 7; dx++ {
+						dist := maxInt(absInt(dx-3), absInt(dy-3))
+						require.Equal(t, dist != 2, code.Dark(corner[0]+dx, corner[1]+dy))
+					}
+				}
+			}
+
+			// The timing patterns alternate between the finder patterns.
+			for i := 8; i < code.Size()-8; i++ {
+				require.Equal(t, i%2 == 0, code.Dark(6, i))
+				require.Equal(t, i%2 == 0, code.Dark(i, 6))
+			}
+
+			// Both copies of the format information are the same.
+			var first, second int
+			for i := 0; i <= 5; i++ {
+				first |= boolToInt(code.Dark(8, i)) << i
+			}
+			first |= boolToInt(code.Dark(8, 7))<<6 | boolToInt(code.Dark(8, 8))<<7 | boolToInt(code.Dark(7, 8))<<8
+			for i := 9; i < 15; i++ {
+				first |= boolToInt(code.Dark(14-i, 8)) << i
+			}
+			for i := 0; i < 8; i++ {
+				second |= boolToInt(code.Dark(code.Size()-1-i, 8)) << i
+			}
+			for i := 8; i < 15; i++ {
+				second |= boolToInt(code.Dark(8, code.Size()-15+i)) << i
+			}
+			require.Equal(t, first, second)
+			require.Equal(t, formatBitsLow, (first^0x5412)>>13, "the error correction level should be low")
+
+			require.True(t, code.Dark(8, code.Size()-8), "the dark module should be dark")
+			require.False(t, code.Dark(-1, 0))
+			require.False(t, code.Dark(0, code.Size()))
+		})
+	}
+}
+
+func TestNumDataCodewords(t *testing.T) {
+	t.Parallel()
+
+	// The capacities of some versions at the low error correction level, from the QR code specification.
+	for version, want := range map[int]int{1: 19, 2: 34, 7: 156, 10: 274, 27: 1468, 40: 2956} {
+		require.Equal(t, want, numDataCodewords(version), "version %d", version)
+	}
+}
+
+func TestWriteTerminal(t *testing.T) {
+	t.Parallel()
+
+	code, err := Encode("https://example.com")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, code.WriteTerminal(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, (code.Size()+4+1)/2)
+	for _, line := range lines {
+		require.True(t, strings.HasPrefix(line, "\x1b[97;40m"))
+		require.True(t, strings.HasSuffix(line, "\x1b[0m"))
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "\x1b[97;40m"), "\x1b[0m")
+		require.Equal(t, code.Size()+4, len([]rune(line)))
+	}
+
+	// The quiet zone is drawn in light modules.
+	require.Equal(t, strings.Repeat("█", code.Size()+4), strings.TrimSuffix(strings.TrimPrefix(lines[0], "\x1b[97;40m"), "\x1b[0m"))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
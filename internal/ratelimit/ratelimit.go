@@ -0,0 +1,120 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ratelimit limits the rate of events, such as authentication attempts, separately for each key, such as
+// a username or a client IP address.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/utils/clock"
+)
+
+// pruneInterval is how often idle rate limiters are forgotten, to bound the memory used by the limiters.
+const pruneInterval = time.Minute
+
+// Limit is a limit on the rate of attempts.
+type Limit struct {
+	// AttemptsPerMinute is the sustained number of attempts which are allowed per minute. Zero means no limit.
+	AttemptsPerMinute int
+
+	// Burst is the number of attempts which are allowed in quick succession. Zero means to use AttemptsPerMinute.
+	Burst int
+}
+
+func (l Limit) burst() int {
+	if l.Burst <= 0 {
+		return l.AttemptsPerMinute
+	}
+	return l.Burst
+}
+
+// RetryAfter returns how long a rejected client should wait until the limit allows another attempt, rounded up
+// to whole seconds. Zero means no limit.
+func (l Limit) RetryAfter() time.Duration {
+	if l.AttemptsPerMinute <= 0 {
+		return 0
+	}
+	seconds := (int(time.Minute/time.Second) + l.AttemptsPerMinute - 1) / l.AttemptsPerMinute
+	return time.Duration(seconds) * time.Second
+}
+
+// KeyedLimiter limits the rate of events separately for each key. It is safe for concurrent use.
+// A nil KeyedLimiter allows all events.
+type KeyedLimiter struct {
+	limit Limit
+	clock clock.PassiveClock
+
+	mu        sync.Mutex
+	limiters  map[string]*keyedLimiterEntry
+	lastPrune time.Time
+}
+
+type keyedLimiterEntry struct {
+	limiter  flowcontrol.PassiveRateLimiter
+	lastUsed time.Time
+}
+
+// NewKeyedLimiter returns a KeyedLimiter for the limit, or nil when the limit is zero.
+func NewKeyedLimiter(limit Limit, c clock.PassiveClock) *KeyedLimiter {
+	if limit.AttemptsPerMinute <= 0 {
+		return nil
+	}
+	return &KeyedLimiter{
+		limit:     limit,
+		clock:     c,
+		limiters:  map[string]*keyedLimiterEntry{},
+		lastPrune: c.Now(),
+	}
+}
+
+// Limit returns the limit which is enforced for each key. A nil KeyedLimiter has a zero limit.
+func (k *KeyedLimiter) Limit() Limit {
+	if k == nil {
+		return Limit{}
+	}
+	return k.limit
+}
+
+// TryAccept returns true when another event is allowed for the key, in which case the event is counted.
+func (k *KeyedLimiter) TryAccept(key string) bool {
+	if k == nil {
+		return true
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.clock.Now()
+	k.prune(now)
+
+	entry, found := k.limiters[key]
+	if !found {
+		qps := float32(k.limit.AttemptsPerMinute) / float32(time.Minute/time.Second)
+		entry = &keyedLimiterEntry{
+			limiter: flowcontrol.NewTokenBucketPassiveRateLimiterWithClock(qps, k.limit.burst(), k.clock),
+		}
+		k.limiters[key] = entry
+	}
+	entry.lastUsed = now
+	return entry.limiter.TryAccept()
+}
+
+// prune forgets the limiters which have been idle for long enough that they would allow a full burst again,
+// since forgetting them does not change the outcome of later events. Must be called while holding the lock.
+func (k *KeyedLimiter) prune(now time.Time) {
+	if now.Sub(k.lastPrune) < pruneInterval {
+		return
+	}
+	k.lastPrune = now
+
+	refillDuration := time.Duration(k.limit.burst()) * time.Minute / time.Duration(k.limit.AttemptsPerMinute)
+	for key, entry := range k.limiters {
+		if now.Sub(entry.lastUsed) >= refillDuration {
+			delete(k.limiters, key)
+		}
+	}
+}
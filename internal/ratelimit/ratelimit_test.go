@@ -0,0 +1,72 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestKeyedLimiter(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	t.Run("nil limiter allows everything", func(t *testing.T) {
+		subject := NewKeyedLimiter(Limit{}, fakeClock)
+		require.Nil(t, subject)
+		require.Equal(t, Limit{}, subject.Limit())
+		for i := 0; i < 100; i++ {
+			require.True(t, subject.TryAccept("some-key"))
+		}
+	})
+
+	t.Run("limits each key separately and allows more events as time passes", func(t *testing.T) {
+		subject := NewKeyedLimiter(Limit{AttemptsPerMinute: 2, Burst: 3}, fakeClock)
+		require.Equal(t, Limit{AttemptsPerMinute: 2, Burst: 3}, subject.Limit())
+
+		require.True(t, subject.TryAccept("some-key"))
+		require.True(t, subject.TryAccept("some-key"))
+		require.True(t, subject.TryAccept("some-key"))
+		require.False(t, subject.TryAccept("some-key"))
+
+		require.True(t, subject.TryAccept("some-other-key"))
+
+		fakeClock.SetTime(fakeClock.Now().Add(30 * time.Second))
+		require.True(t, subject.TryAccept("some-key"))
+		require.False(t, subject.TryAccept("some-key"))
+	})
+
+	t.Run("burst defaults to the attempts per minute", func(t *testing.T) {
+		subject := NewKeyedLimiter(Limit{AttemptsPerMinute: 2}, fakeClock)
+
+		require.True(t, subject.TryAccept("some-key"))
+		require.True(t, subject.TryAccept("some-key"))
+		require.False(t, subject.TryAccept("some-key"))
+	})
+
+	t.Run("forgets the limiters which could allow a full burst again", func(t *testing.T) {
+		subject := NewKeyedLimiter(Limit{AttemptsPerMinute: 1, Burst: 2}, fakeClock)
+
+		require.True(t, subject.TryAccept("some-key"))
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+		require.True(t, subject.TryAccept("some-other-key"))
+		require.Len(t, subject.limiters, 2)
+
+		// The first key has been idle for two minutes, which is long enough to refill its burst.
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+		require.True(t, subject.TryAccept("some-other-key"))
+		require.Len(t, subject.limiters, 1)
+		require.Contains(t, subject.limiters, "some-other-key")
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, time.Duration(0), Limit{}.RetryAfter())
+	require.Equal(t, time.Minute, Limit{AttemptsPerMinute: 1, Burst: 5}.RetryAfter())
+	require.Equal(t, 30*time.Second, Limit{AttemptsPerMinute: 2}.RetryAfter())
+	require.Equal(t, 9*time.Second, Limit{AttemptsPerMinute: 7}.RetryAfter())
+	require.Equal(t, time.Second, Limit{AttemptsPerMinute: 1000}.RetryAfter())
+}
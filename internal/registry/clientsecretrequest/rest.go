@@ -1,4 +1,4 @@
-// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package clientsecretrequest provides REST functionality for the CredentialRequest resource.
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -19,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
@@ -147,14 +149,27 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	t.Step("oidcClientsClient.Get")
 
 	// Using the OIDCClient's UID, check to see if the storage Secret for its client secrets already exists.
-	// Note that when it does not exist, this Get() function will not return an error, and will return nil rv and hashes.
-	rv, hashes, err := r.secretStorage.Get(ctx, oidcClient.UID)
+	// Note that when it does not exist, this GetClientSecrets() function will not return an error, and will return nil rv and client secrets.
+	rv, clientSecrets, err := r.secretStorage.GetClientSecrets(ctx, oidcClient.UID)
 	if err != nil {
 		traceFailureWithError(t, "secretStorage.Get", err)
 		return nil, apierrors.NewInternalError(fmt.Errorf("getting secret for client %q failed", req.Name))
 	}
 	t.Step("secretStorage.Get")
 
+	// The storage keeps times with a precision of seconds.
+	now := r.timeNowFunc().Rfc3339Copy()
+
+	// Forget about the client secrets which have already expired.
+	clientSecrets, numExpired := withoutExpiredClientSecrets(clientSecrets, now)
+
+	// If requested, remove individual client secrets from the list.
+	clientSecrets, errs := withoutRevokedClientSecrets(clientSecrets, req.Spec.RevokeSecretHashes)
+	if len(errs) > 0 {
+		traceFailure(t, "revokeSecretHashes", errs.ToAggregate().Error())
+		return nil, apierrors.NewInvalid(kindFromContext(ctx), req.Name, errs)
+	}
+
 	// If requested, generate a new client secret and add it to the list.
 	var secret string
 	if req.Spec.GenerateNewSecret {
@@ -172,26 +187,36 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		}
 		t.Step("bcrypt.GenerateFromPassword")
 
-		hashes = append([]string{string(hash)}, hashes...)
+		// If requested, the old client secrets remain valid for a while, unless they were already going to expire sooner.
+		if req.Spec.OldSecretsLifetimeSeconds > 0 {
+			expiresAt := metav1.NewTime(now.Add(time.Duration(req.Spec.OldSecretsLifetimeSeconds) * time.Second))
+			for i := range clientSecrets {
+				if clientSecrets[i].ExpiresAt == nil || expiresAt.Before(clientSecrets[i].ExpiresAt) {
+					clientSecrets[i].ExpiresAt = &expiresAt
+				}
+			}
+		}
+
+		clientSecrets = append([]oidcclientsecretstorage.ClientSecret{{Hash: string(hash), CreatedAt: &now}}, clientSecrets...)
 	}
 
 	// If requested, remove all client secrets except for the most recent one.
-	needsRevoke := req.Spec.RevokeOldSecrets && len(hashes) > 0
+	needsRevoke := req.Spec.RevokeOldSecrets && len(clientSecrets) > 0
 	if needsRevoke {
-		hashes = []string{hashes[0]}
+		clientSecrets = clientSecrets[:1]
 	}
 
-	// If anything was requested to change...
-	if req.Spec.GenerateNewSecret || needsRevoke {
+	// If anything was requested to change, or if some client secrets have expired...
+	if req.Spec.GenerateNewSecret || needsRevoke || len(req.Spec.RevokeSecretHashes) > 0 || numExpired > 0 {
 		// Each bcrypt comparison is expensive, and we do not want a large list to cause wasted CPU.
-		if len(hashes) > 5 {
+		if len(clientSecrets) > 5 {
 			msg := fmt.Sprintf("OIDCClient %s has too many secrets, spec.revokeOldSecrets must be true", oidcClient.Name)
 			traceFailure(t, "secretStorage.Set", msg)
 			return nil, apierrors.NewBadRequest(msg)
 		}
 
 		// Create or update the storage Secret for client secrets.
-		if err := r.secretStorage.Set(ctx, rv, oidcClient.Name, oidcClient.UID, hashes); err != nil {
+		if err := r.secretStorage.SetClientSecrets(ctx, rv, oidcClient.Name, oidcClient.UID, clientSecrets); err != nil {
 			if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
 				traceFailureWithError(t, "secretStorage.Set", err)
 				return nil, apierrors.NewConflict(qualifiedResourceFromContext(ctx), req.Name,
@@ -204,7 +229,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		t.Step("secretStorage.Set")
 	}
 
-	// Return the new secret in plaintext, if one was generated, along with the total number of secrets.
+	// Return the new secret in plaintext, if one was generated, along with the remaining secrets.
 	return &clientsecretapi.OIDCClientSecretRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:              req.Name,
@@ -212,16 +237,69 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			CreationTimestamp: r.timeNowFunc(),
 		},
 		Spec: clientsecretapi.OIDCClientSecretRequestSpec{
-			GenerateNewSecret: req.Spec.GenerateNewSecret,
-			RevokeOldSecrets:  req.Spec.RevokeOldSecrets,
+			GenerateNewSecret:         req.Spec.GenerateNewSecret,
+			RevokeOldSecrets:          req.Spec.RevokeOldSecrets,
+			OldSecretsLifetimeSeconds: req.Spec.OldSecretsLifetimeSeconds,
+			RevokeSecretHashes:        req.Spec.RevokeSecretHashes,
 		},
 		Status: clientsecretapi.OIDCClientSecretRequestStatus{
 			GeneratedSecret:    secret,
-			TotalClientSecrets: len(hashes),
+			TotalClientSecrets: len(clientSecrets),
+			ClientSecrets:      clientSecretStatuses(clientSecrets),
 		},
 	}, nil
 }
 
+func withoutExpiredClientSecrets(clientSecrets []oidcclientsecretstorage.ClientSecret, now metav1.Time) ([]oidcclientsecretstorage.ClientSecret, int) {
+	result := make([]oidcclientsecretstorage.ClientSecret, 0, len(clientSecrets))
+	for _, clientSecret := range clientSecrets {
+		if !clientSecret.IsExpired(now.Time) {
+			result = append(result, clientSecret)
+		}
+	}
+	return result, len(clientSecrets) - len(result)
+}
+
+func withoutRevokedClientSecrets(clientSecrets []oidcclientsecretstorage.ClientSecret, revokeHashes []string) ([]oidcclientsecretstorage.ClientSecret, field.ErrorList) {
+	if len(revokeHashes) == 0 {
+		return clientSecrets, nil
+	}
+
+	revoke := sets.New[string](revokeHashes...)
+	revoked := sets.New[string]()
+	result := make([]oidcclientsecretstorage.ClientSecret, 0, len(clientSecrets))
+	for _, clientSecret := range clientSecrets {
+		if revoke.Has(clientSecret.Hash) {
+			revoked.Insert(clientSecret.Hash)
+			continue
+		}
+		result = append(result, clientSecret)
+	}
+
+	var errs field.ErrorList
+	for i, hash := range revokeHashes {
+		if !revoked.Has(hash) {
+			errs = append(errs, field.NotFound(field.NewPath("spec", "revokeSecretHashes").Index(i), hash))
+		}
+	}
+	return result, errs
+}
+
+func clientSecretStatuses(clientSecrets []oidcclientsecretstorage.ClientSecret) []clientsecretapi.ClientSecretStatus {
+	if len(clientSecrets) == 0 {
+		return nil
+	}
+	result := make([]clientsecretapi.ClientSecretStatus, 0, len(clientSecrets))
+	for _, clientSecret := range clientSecrets {
+		result = append(result, clientsecretapi.ClientSecretStatus{
+			Hash:                clientSecret.Hash,
+			CreationTimestamp:   clientSecret.CreatedAt,
+			ExpirationTimestamp: clientSecret.ExpiresAt,
+		})
+	}
+	return result
+}
+
 func (r *REST) validateRequest(
 	ctx context.Context,
 	obj runtime.Object,
@@ -275,6 +353,11 @@ func (r *REST) validateRequest(
 		return nil, apierrors.NewInvalid(kindFromContext(ctx), clientSecretRequest.Name, errs)
 	}
 
+	if errs := validateSpec(clientSecretRequest.Spec); len(errs) > 0 {
+		traceValidationFailure(tracer, errs.ToAggregate().Error())
+		return nil, apierrors.NewInvalid(kindFromContext(ctx), clientSecretRequest.Name, errs)
+	}
+
 	// just a sanity check, not sure how to honor a dry run on a virtual API
 	if options != nil {
 		if len(options.DryRun) != 0 {
@@ -294,6 +377,20 @@ func (r *REST) validateRequest(
 	return clientSecretRequest, nil
 }
 
+func validateSpec(spec clientsecretapi.OIDCClientSecretRequestSpec) field.ErrorList {
+	var errs field.ErrorList
+	lifetimePath := field.NewPath("spec", "oldSecretsLifetimeSeconds")
+	switch {
+	case spec.OldSecretsLifetimeSeconds < 0:
+		errs = append(errs, field.Invalid(lifetimePath, spec.OldSecretsLifetimeSeconds, "must not be negative"))
+	case spec.OldSecretsLifetimeSeconds > 0 && !spec.GenerateNewSecret:
+		errs = append(errs, field.Invalid(lifetimePath, spec.OldSecretsLifetimeSeconds, "may only be set when spec.generateNewSecret is true"))
+	case spec.OldSecretsLifetimeSeconds > 0 && spec.RevokeOldSecrets:
+		errs = append(errs, field.Invalid(lifetimePath, spec.OldSecretsLifetimeSeconds, "must not be set when spec.revokeOldSecrets is true"))
+	}
+	return errs
+}
+
 func traceFailure(t *trace.Trace, failureType string, msg string) {
 	t.Step("failure",
 		trace.Field{Key: "failureType", Value: failureType},
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package clientsecretrequest
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -107,8 +108,14 @@ func TestCreate(t *testing.T) {
 	fakeBcryptRandomBytes := fakeHexEncodedRandomBytes + ":4-fake-hash"
 
 	fakeNow := metav1.Now()
+	fakeNowInSeconds := fakeNow.Rfc3339Copy()
 	fakeTimeNowFunc := func() metav1.Time { return fakeNow }
 
+	// Times which are read back from the storage are in the local time zone, see metav1.Time.UnmarshalJSON.
+	storedExpired := metav1.NewTime(fakeNowInSeconds.Add(-time.Second).Local())
+	storedExpiresSoon := metav1.NewTime(fakeNowInSeconds.Add(10 * time.Second).Local())
+	expiresInAnHour := metav1.NewTime(fakeNowInSeconds.Add(time.Hour))
+
 	tests := []struct {
 		name              string
 		args              args
@@ -696,6 +703,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+					},
 				},
 			},
 			wantErrStatus: nil,
@@ -772,6 +782,11 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 3,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+						{Hash: "hashed-password-1"},
+						{Hash: "hashed-password-2"},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -838,6 +853,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -904,6 +922,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    "",
 					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: "hashed-password-1"},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1340,6 +1361,10 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    "",
 					TotalClientSecrets: 2,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: "hashed-password-1"},
+						{Hash: "hashed-password-2"},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1402,6 +1427,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1471,6 +1499,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1541,6 +1572,193 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`generateSecret`,
+				`bcrypt.GenerateFromPassword`,
+				`secretStorage.Set`,
+				`END`,
+			},
+		},
+		{
+			name: "validation: oldSecretsLifetimeSeconds must not be negative",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret:         true,
+						OldSecretsLifetimeSeconds: -1,
+					},
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.oldSecretsLifetimeSeconds: Invalid value: -1: must not be negative`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Message: `Invalid value: -1: must not be negative`,
+						Field:   "spec.oldSecretsLifetimeSeconds",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`failureType:request validation,msg:spec.oldSecretsLifetimeSeconds: Invalid value: -1: must not be negative`,
+				`END`,
+			},
+		},
+		{
+			name: "validation: oldSecretsLifetimeSeconds cannot be used with revokeOldSecrets",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret:         true,
+						RevokeOldSecrets:          true,
+						OldSecretsLifetimeSeconds: 60,
+					},
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.oldSecretsLifetimeSeconds: Invalid value: 60: must not be set when spec.revokeOldSecrets is true`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Message: `Invalid value: 60: must not be set when spec.revokeOldSecrets is true`,
+						Field:   "spec.oldSecretsLifetimeSeconds",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`failureType:request validation,msg:spec.oldSecretsLifetimeSeconds: Invalid value: 60: must not be set when spec.revokeOldSecrets is true`,
+				`END`,
+			},
+		},
+		{
+			name: "validation: oldSecretsLifetimeSeconds requires generateNewSecret",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						OldSecretsLifetimeSeconds: 60,
+					},
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.oldSecretsLifetimeSeconds: Invalid value: 60: may only be set when spec.generateNewSecret is true`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Message: `Invalid value: 60: may only be set when spec.generateNewSecret is true`,
+						Field:   "spec.oldSecretsLifetimeSeconds",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`failureType:request validation,msg:spec.oldSecretsLifetimeSeconds: Invalid value: 60: may only be set when spec.generateNewSecret is true`,
+				`END`,
+			},
+		},
+		{
+			name: "happy path: generate new secret and keep the old secrets valid for a while, unless they expire sooner",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret:         true,
+						OldSecretsLifetimeSeconds: 3600,
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.SetClientSecrets(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]oidcclientsecretstorage.ClientSecret{
+							{Hash: "hashed-password-1"},
+							{Hash: "hashed-password-2", ExpiresAt: &storedExpiresSoon},
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					fakeBcryptRandomBytes,
+					"hashed-password-1",
+					"hashed-password-2",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+					GenerateNewSecret:         true,
+					OldSecretsLifetimeSeconds: 3600,
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					GeneratedSecret:    fakeHexEncodedRandomBytes,
+					TotalClientSecrets: 3,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: fakeBcryptRandomBytes, CreationTimestamp: &fakeNowInSeconds},
+						{Hash: "hashed-password-1", ExpirationTimestamp: &expiresInAnHour},
+						{Hash: "hashed-password-2", ExpirationTimestamp: &storedExpiresSoon},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1554,6 +1772,194 @@ func TestCreate(t *testing.T) {
 				`END`,
 			},
 		},
+		{
+			name: "happy path: expired secrets are removed",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.SetClientSecrets(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]oidcclientsecretstorage.ClientSecret{
+							{Hash: "hashed-password-1", ExpiresAt: &storedExpiresSoon},
+							{Hash: "hashed-password-2", ExpiresAt: &storedExpired},
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-1",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: "hashed-password-1", ExpirationTimestamp: &storedExpiresSoon},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`secretStorage.Set`,
+				`END`,
+			},
+		},
+		{
+			name: "happy path: revoke individual secrets",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						RevokeSecretHashes: []string{"hashed-password-3", "hashed-password-1"},
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+							"hashed-password-3",
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-2",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+					RevokeSecretHashes: []string{"hashed-password-3", "hashed-password-1"},
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					TotalClientSecrets: 1,
+					ClientSecrets: []clientsecretapi.ClientSecretStatus{
+						{Hash: "hashed-password-2"},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`secretStorage.Set`,
+				`END`,
+			},
+		},
+		{
+			name: "revoking a secret which does not exist fails, secret is not updated",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret:  true,
+						RevokeSecretHashes: []string{"hashed-password-1", "does-not-exist"},
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-1",
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.revokeSecretHashes[1]: Not found: "does-not-exist"`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueNotFound,
+						Message: `Not found: "does-not-exist"`,
+						Field:   "spec.revokeSecretHashes[1]",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`failureType:revokeSecretHashes,msg:spec.revokeSecretHashes[1]: Not found: "does-not-exist"`,
+				`END`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
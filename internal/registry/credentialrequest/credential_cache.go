@@ -0,0 +1,83 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/utils/clock"
+
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+)
+
+// credentialCacheMaxEntries bounds the memory which is used by the cache of the issued credentials.
+// The least recently used credentials are evicted first.
+const credentialCacheMaxEntries = 1000
+
+// credentialCache remembers the credentials which were recently issued for each token and authenticator, so that
+// clients which repeat the same TokenCredentialRequest many times in a row do not cause the token to be validated
+// and a new certificate to be issued each time. The user to whom each credential was issued is remembered too, so
+// that the credential issuance policy can be evaluated again before a cached credential is returned. A cached
+// credential is only returned while at least half of its lifetime remains, so that the clients always have time to
+// use it. Returning the same credential again never gives a client access for longer than it already had, since the
+// credential was already issued to that client. The credentials are cached per version of the authenticator, so
+// that they are not returned anymore once the authenticator was changed or replaced.
+type credentialCache struct {
+	cache *cache.LRUExpireCache
+	clock clock.PassiveClock
+}
+
+func newCredentialCache(c clock.PassiveClock) *credentialCache {
+	return &credentialCache{
+		cache: cache.NewLRUExpireCacheWithClock(credentialCacheMaxEntries, c),
+		clock: c,
+	}
+}
+
+type cachedCredential struct {
+	credential *loginapi.ClusterCredential
+	userInfo   user.Info
+}
+
+// get returns a copy of the credential which was cached for the token and the version of the authenticator of the
+// request, and the user to whom it was issued, or nil when there is none.
+func (c *credentialCache) get(req *loginapi.TokenCredentialRequest, authenticatorVersion uint64) (*loginapi.ClusterCredential, user.Info) {
+	cached, ok := c.cache.Get(credentialCacheKey(req, authenticatorVersion))
+	if !ok {
+		return nil, nil
+	}
+	entry := cached.(*cachedCredential)
+	return entry.credential.DeepCopy(), entry.userInfo
+}
+
+// put caches a copy of the credential which was issued to the user for the token and the version of the
+// authenticator of the request, until half of the remaining lifetime of the credential has passed.
+func (c *credentialCache) put(req *loginapi.TokenCredentialRequest, authenticatorVersion uint64, userInfo user.Info, credential *loginapi.ClusterCredential) {
+	ttl := credential.ExpirationTimestamp.Sub(c.clock.Now()) / 2
+	if ttl <= 0 {
+		return
+	}
+	c.cache.Add(credentialCacheKey(req, authenticatorVersion), &cachedCredential{credential: credential.DeepCopy(), userInfo: userInfo}, ttl)
+}
+
+// credentialCacheKey hashes the token, the reference to the authenticator of the request and the version of that
+// authenticator, so that the tokens are not kept in memory by the cache.
+func credentialCacheKey(req *loginapi.TokenCredentialRequest, authenticatorVersion uint64) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatUint(authenticatorVersion, 10)))
+	h.Write([]byte{0})
+	authenticator := req.Spec.Authenticator
+	if authenticator.APIGroup != nil {
+		h.Write([]byte(*authenticator.APIGroup))
+	}
+	for _, s := range []string{authenticator.Kind, authenticator.Name, req.Spec.Token} {
+		h.Write([]byte{0})
+		h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
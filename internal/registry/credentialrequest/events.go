@@ -0,0 +1,123 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
+
+	authenticationv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/credentialpolicy"
+	"go.pinniped.dev/internal/ratelimit"
+)
+
+const (
+	// outcomeEventAction is the action of the Events which are recorded for TokenCredentialRequests.
+	outcomeEventAction = "IssueClusterCredential"
+
+	// maxOutcomeEventNoteLength is the maximum length of the note of an Event allowed by the Kubernetes API.
+	maxOutcomeEventNoteLength = 1024
+
+	// outcomeEventsPerMinute limits the Events which are recorded for each authenticator, reason, and user, so that
+	// a client which repeats the same request, or which keeps sending invalid tokens, cannot flood the Kubernetes API
+	// with Events.
+	outcomeEventsPerMinute = 10
+)
+
+// The reasons of the Events which are recorded for TokenCredentialRequests.
+const (
+	reasonCredentialIssued         = "CredentialIssued"
+	reasonAuthenticationFailed     = "AuthenticationFailed"
+	reasonCredentialDenied         = "CredentialDenied"
+	reasonCredentialIssuanceFailed = "CredentialIssuanceFailed"
+)
+
+// outcomeEventRecorder records a Kubernetes Event regarding the authenticator of each TokenCredentialRequest, so
+// that the issuance of cluster credentials can be traced. The Events never hold any tokens or credentials.
+// A nil outcomeEventRecorder does not record any Events.
+type outcomeEventRecorder struct {
+	recorder events.EventRecorder
+	limiter  *ratelimit.KeyedLimiter
+}
+
+// newOutcomeEventRecorder returns an outcomeEventRecorder which records Events using the provided recorder, or nil
+// when the recorder is nil.
+func newOutcomeEventRecorder(recorder events.EventRecorder, c clock.PassiveClock) *outcomeEventRecorder {
+	if recorder == nil {
+		return nil
+	}
+	return &outcomeEventRecorder{
+		recorder: recorder,
+		limiter:  ratelimit.NewKeyedLimiter(ratelimit.Limit{AttemptsPerMinute: outcomeEventsPerMinute}, c),
+	}
+}
+
+// record records an Event for the outcome of a valid TokenCredentialRequest. The userInfo is the authenticated user,
+// which is nil when authentication failed. Nothing is recorded for requests which name an authenticator that does
+// not exist, since there is nothing which the Event could be regarding.
+func (r *outcomeEventRecorder) record(ctx context.Context, req *loginapi.TokenCredentialRequest, outcome string, userInfo user.Info) {
+	if r == nil || outcome == outcomeUnknownAuthenticator {
+		return
+	}
+
+	username := ""
+	if userInfo != nil {
+		username = userInfo.GetName()
+	}
+	sourceIP := credentialpolicy.SourceIPFromContext(ctx)
+	if sourceIP == "" {
+		sourceIP = "an unknown address"
+	}
+
+	eventType, reason, note := corev1.EventTypeNormal, "", ""
+	switch outcome {
+	case outcomeIssued, outcomeCached:
+		reason = reasonCredentialIssued
+		note = fmt.Sprintf("issued a cluster credential to user %q from %s", username, sourceIP)
+	case outcomeAuthenticationFailed:
+		eventType, reason = corev1.EventTypeWarning, reasonAuthenticationFailed
+		note = fmt.Sprintf("could not authenticate a token from %s", sourceIP)
+	case outcomeDenied:
+		eventType, reason = corev1.EventTypeWarning, reasonCredentialDenied
+		note = fmt.Sprintf("the credential issuance policy denied a cluster credential to user %q from %s", username, sourceIP)
+	case outcomeIssuanceFailed:
+		eventType, reason = corev1.EventTypeWarning, reasonCredentialIssuanceFailed
+		note = fmt.Sprintf("could not issue a cluster credential to user %q from %s", username, sourceIP)
+	default:
+		return
+	}
+
+	authenticator := req.Spec.Authenticator
+	if !r.limiter.TryAccept(strings.Join([]string{authenticator.Kind, authenticator.Name, reason, username}, "/")) {
+		return
+	}
+
+	if len(note) > maxOutcomeEventNoteLength {
+		note = note[:maxOutcomeEventNoteLength-3] + "..."
+	}
+	r.recorder.Eventf(authenticatorReference(req), nil, eventType, reason, outcomeEventAction, "%s", note)
+}
+
+// authenticatorReference returns a reference to the authenticator of the request, to be used as the regarding
+// object of Events. The authenticators are cluster-scoped, so their Events are recorded in the default namespace.
+func authenticatorReference(req *loginapi.TokenCredentialRequest) *corev1.ObjectReference {
+	authenticator := req.Spec.Authenticator
+	apiGroup := authenticationv1alpha1.SchemeGroupVersion.Group
+	if authenticator.APIGroup != nil {
+		apiGroup = *authenticator.APIGroup
+	}
+	return &corev1.ObjectReference{
+		APIVersion: schema.GroupVersion{Group: apiGroup, Version: authenticationv1alpha1.SchemeGroupVersion.Version}.String(),
+		Kind:       authenticator.Kind,
+		Name:       authenticator.Name,
+	}
+}
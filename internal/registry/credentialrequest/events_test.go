@@ -0,0 +1,168 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/events"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/credentialpolicy"
+)
+
+func TestOutcomeEventRecorder(t *testing.T) {
+	t.Parallel()
+
+	req := credentialRequest(loginapi.TokenCredentialRequestSpec{
+		Token:         "some-secret-token",
+		Authenticator: corev1.TypedLocalObjectReference{Kind: "JWTAuthenticator", Name: "some-authenticator"},
+	})
+	userInfo := &user.DefaultInfo{Name: "some-user", Groups: []string{"some-group"}}
+
+	tests := []struct {
+		name      string
+		outcome   string
+		userInfo  user.Info
+		wantEvent string
+	}{
+		{
+			name:      "issued",
+			outcome:   outcomeIssued,
+			userInfo:  userInfo,
+			wantEvent: `Normal CredentialIssued issued a cluster credential to user "some-user" from 10.1.2.3`,
+		},
+		{
+			name:      "cached",
+			outcome:   outcomeCached,
+			userInfo:  userInfo,
+			wantEvent: `Normal CredentialIssued issued a cluster credential to user "some-user" from 10.1.2.3`,
+		},
+		{
+			name:      "authentication failed",
+			outcome:   outcomeAuthenticationFailed,
+			wantEvent: `Warning AuthenticationFailed could not authenticate a token from 10.1.2.3`,
+		},
+		{
+			name:      "denied",
+			outcome:   outcomeDenied,
+			userInfo:  userInfo,
+			wantEvent: `Warning CredentialDenied the credential issuance policy denied a cluster credential to user "some-user" from 10.1.2.3`,
+		},
+		{
+			name:      "issuance failed",
+			outcome:   outcomeIssuanceFailed,
+			userInfo:  userInfo,
+			wantEvent: `Warning CredentialIssuanceFailed could not issue a cluster credential to user "some-user" from 10.1.2.3`,
+		},
+		{
+			name:    "unknown authenticator",
+			outcome: outcomeUnknownAuthenticator,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			eventRecorder := events.NewFakeRecorder(100)
+			subject := newOutcomeEventRecorder(eventRecorder, clocktesting.NewFakeClock(time.Now()))
+
+			subject.record(contextWithSourceIP(t, "10.1.2.3:12345"), req, tt.outcome, tt.userInfo)
+
+			close(eventRecorder.Events)
+			var gotEvents []string
+			for event := range eventRecorder.Events {
+				require.NotContains(t, event, "some-secret-token")
+				gotEvents = append(gotEvents, event)
+			}
+			if tt.wantEvent == "" {
+				require.Empty(t, gotEvents)
+				return
+			}
+			require.Equal(t, []string{tt.wantEvent}, gotEvents)
+		})
+	}
+}
+
+func TestOutcomeEventRecorderIsRateLimited(t *testing.T) {
+	t.Parallel()
+
+	req := credentialRequest(loginapi.TokenCredentialRequestSpec{
+		Token:         "some-token",
+		Authenticator: corev1.TypedLocalObjectReference{Kind: "JWTAuthenticator", Name: "some-authenticator"},
+	})
+
+	eventRecorder := events.NewFakeRecorder(100)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	subject := newOutcomeEventRecorder(eventRecorder, fakeClock)
+
+	for i := 0; i < outcomeEventsPerMinute+5; i++ {
+		subject.record(context.Background(), req, outcomeIssued, &user.DefaultInfo{Name: "some-user"})
+		subject.record(context.Background(), req, outcomeAuthenticationFailed, nil)
+	}
+	// Other users are limited separately.
+	subject.record(context.Background(), req, outcomeIssued, &user.DefaultInfo{Name: "other-user"})
+	require.Len(t, eventRecorder.Events, 2*outcomeEventsPerMinute+1)
+
+	// More Events are allowed as time passes.
+	fakeClock.Step(time.Minute)
+	subject.record(context.Background(), req, outcomeIssued, &user.DefaultInfo{Name: "some-user"})
+	require.Len(t, eventRecorder.Events, 2*outcomeEventsPerMinute+2)
+}
+
+func TestNilOutcomeEventRecorder(t *testing.T) {
+	t.Parallel()
+
+	subject := newOutcomeEventRecorder(nil, clocktesting.NewFakeClock(time.Now()))
+	require.Nil(t, subject)
+	subject.record(context.Background(), validCredentialRequest(), outcomeIssued, &user.DefaultInfo{Name: "some-user"})
+}
+
+func TestAuthenticatorReference(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, &corev1.ObjectReference{
+		APIVersion: "authentication.concierge.pinniped.dev/v1alpha1",
+		Kind:       "WebhookAuthenticator",
+		Name:       "some-authenticator",
+	}, authenticatorReference(credentialRequest(loginapi.TokenCredentialRequestSpec{
+		Authenticator: corev1.TypedLocalObjectReference{Kind: "WebhookAuthenticator", Name: "some-authenticator"},
+	})))
+
+	require.Equal(t, &corev1.ObjectReference{
+		APIVersion: "authentication.concierge.example.com/v1alpha1",
+		Kind:       "JWTAuthenticator",
+		Name:       "some-authenticator",
+	}, authenticatorReference(credentialRequest(loginapi.TokenCredentialRequestSpec{
+		Authenticator: corev1.TypedLocalObjectReference{
+			APIGroup: pointer.String("authentication.concierge.example.com"),
+			Kind:     "JWTAuthenticator",
+			Name:     "some-authenticator",
+		},
+	})))
+}
+
+// contextWithSourceIP returns a context which holds the source IP of a request from the given remote address.
+func contextWithSourceIP(t *testing.T, remoteAddr string) context.Context {
+	t.Helper()
+
+	var ctx context.Context
+	handler := credentialpolicy.WithSourceIP(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.RemoteAddr = remoteAddr
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return ctx
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+)
+
+const (
+	metricsNamespace = "pinniped_concierge"
+	metricsSubsystem = "token_credential_request"
+
+	metricsLabelAuthenticatorKind = "authenticator_kind"
+	metricsLabelAuthenticatorName = "authenticator_name"
+	metricsLabelOutcome           = "outcome"
+)
+
+// The outcomes of valid TokenCredentialRequests.
+const (
+	outcomeIssued               = "issued"
+	outcomeCached               = "cached"
+	outcomeAuthenticationFailed = "authentication_failed"
+	outcomeUnknownAuthenticator = "unknown_authenticator"
+	outcomeDenied               = "denied"
+	outcomeIssuanceFailed       = "issuance_failed"
+)
+
+//nolint:gochecknoglobals // Metrics are registered once with the global registry which is served by the Concierge.
+var (
+	requestsTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      metricsNamespace,
+		Subsystem:      metricsSubsystem,
+		Name:           "requests_total",
+		Help:           "Number of valid TokenCredentialRequests, partitioned by authenticator kind, authenticator name, and outcome.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{metricsLabelAuthenticatorKind, metricsLabelAuthenticatorName, metricsLabelOutcome})
+
+	requestDuration = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "duration_seconds",
+		Help:      "Latency of valid TokenCredentialRequests in seconds, partitioned by authenticator kind, authenticator name, and outcome.",
+		// From 5ms to about 41s, which covers the timeouts of the webhooks which may be called to validate tokens.
+		Buckets:        metrics.ExponentialBuckets(0.005, 2, 14),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{metricsLabelAuthenticatorKind, metricsLabelAuthenticatorName, metricsLabelOutcome})
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the TokenCredentialRequest API with the global registry, which is served
+// on the /metrics endpoint of the Concierge's aggregated API server and on its metrics listener. It is safe to call
+// more than once.
+func RegisterMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(requestsTotal, requestDuration)
+	})
+}
+
+// observeTokenCredentialRequest records one valid TokenCredentialRequest which took the given time.
+func observeTokenCredentialRequest(req *loginapi.TokenCredentialRequest, outcome string, duration time.Duration) {
+	kind, name := req.Spec.Authenticator.Kind, req.Spec.Authenticator.Name
+	if outcome == outcomeUnknownAuthenticator {
+		// Any client can name any authenticator, so do not let them create new label values.
+		kind, name = "", ""
+	}
+	requestsTotal.WithLabelValues(kind, name, outcome).Inc()
+	requestDuration.WithLabelValues(kind, name, outcome).Observe(duration.Seconds())
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/component-base/metrics/testutil"
+
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/mocks/credentialrequestmocks"
+)
+
+func TestTokenCredentialRequestMetrics(t *testing.T) {
+	RegisterMetrics()
+	RegisterMetrics() // registering again is a no-op
+
+	const (
+		kind = "WebhookAuthenticator"
+		name = "some-instrumented-authenticator"
+	)
+
+	requireCount := func(kind, name, outcome string, want int) {
+		t.Helper()
+		total, err := testutil.GetCounterMetricValue(requestsTotal.WithLabelValues(kind, name, outcome))
+		require.NoError(t, err)
+		require.Equal(t, float64(want), total)
+		observations, err := testutil.GetHistogramMetricCount(requestDuration.WithLabelValues(kind, name, outcome))
+		require.NoError(t, err)
+		require.Equal(t, uint64(want), observations)
+	}
+
+	request := func(token, authenticatorName string) *loginapi.TokenCredentialRequest {
+		return credentialRequest(loginapi.TokenCredentialRequestSpec{
+			Token:         token,
+			Authenticator: corev1.TypedLocalObjectReference{Kind: kind, Name: authenticatorName},
+		})
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+	requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), request("good-token", name)).
+		Return(&user.DefaultInfo{Name: "test-user"}, nil)
+	requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), request("bad-token", name)).
+		Return(nil, errors.New("some authentication error"))
+	requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), request("good-token", "no-such-authenticator")).
+		Return(nil, authncache.ErrNoSuchAuthenticator)
+
+	storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, nil, nil, schema.GroupResource{})
+
+	for _, req := range []*loginapi.TokenCredentialRequest{
+		request("good-token", name),
+		request("good-token", name), // returned from the cache
+		request("bad-token", name),
+		request("good-token", "no-such-authenticator"),
+	} {
+		_, err := callCreate(context.Background(), storage, req)
+		require.NoError(t, err)
+	}
+
+	// Invalid requests are not recorded.
+	_, err := callCreate(context.Background(), storage, request("", name))
+	require.Error(t, err)
+
+	requireCount(kind, name, outcomeIssued, 1)
+	requireCount(kind, name, outcomeCached, 1)
+	requireCount(kind, name, outcomeAuthenticationFailed, 1)
+	requireCount(kind, "no-such-authenticator", outcomeUnknownAuthenticator, 0)
+	requireCount("", "", outcomeUnknownAuthenticator, 1)
+}
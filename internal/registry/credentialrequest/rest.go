@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package credentialrequest provides REST functionality for the CredentialRequest resource.
@@ -6,8 +6,8 @@ package credentialrequest
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
@@ -15,34 +15,71 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/audit"
 	"k8s.io/apiserver/pkg/authentication/user"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/pointer"
 	"k8s.io/utils/trace"
 
 	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/credentialpolicy"
 	"go.pinniped.dev/internal/issuer"
+	"go.pinniped.dev/internal/plog"
 )
 
-// clientCertificateTTL is the TTL for short-lived client certificates returned by this API.
-const clientCertificateTTL = 5 * time.Minute
+const (
+	// policyDecisionAuditAnnotation and policyReasonAuditAnnotation record the decisions of the credential issuance
+	// policy in the audit events of the TokenCredentialRequests.
+	policyDecisionAuditAnnotation = "credentialissuancepolicy.concierge.pinniped.dev/decision"
+	policyReasonAuditAnnotation   = "credentialissuancepolicy.concierge.pinniped.dev/reason"
+)
 
 type TokenCredentialRequestAuthenticator interface {
 	AuthenticateTokenCredentialRequest(ctx context.Context, req *loginapi.TokenCredentialRequest) (user.Info, error)
 }
 
-func NewREST(authenticator TokenCredentialRequestAuthenticator, issuer issuer.ClientCertIssuer, resource schema.GroupResource) *REST {
+// versionedAuthenticator is implemented by the authenticators which can tell when the authenticator of a request was
+// changed, e.g. authncache.Cache, so that the credentials which were cached for its previous version are not
+// returned anymore.
+type versionedAuthenticator interface {
+	AuthenticatorVersion(req *loginapi.TokenCredentialRequest) uint64
+}
+
+// NewREST returns the storage of the TokenCredentialRequest API. The short-lived client certificates which it issues
+// use the current lifetime from certLifetime, which may be nil to always use the default lifetime. They are only
+// issued when the current policy from policy allows it, which may be nil to issue them to every authenticated user.
+// The outcomes of the requests are recorded as Events regarding their authenticators using recorder, which may be
+// nil to not record any Events.
+func NewREST(authenticator TokenCredentialRequestAuthenticator, issuer issuer.ClientCertIssuer, certLifetime *issuer.DynamicClientCertLifetime, policy *credentialpolicy.DynamicPolicy, recorder events.EventRecorder, resource schema.GroupResource) *REST {
+	return newRESTWithClock(authenticator, issuer, certLifetime, policy, recorder, resource, clock.RealClock{})
+}
+
+func newRESTWithClock(authenticator TokenCredentialRequestAuthenticator, issuer issuer.ClientCertIssuer, certLifetime *issuer.DynamicClientCertLifetime, policy *credentialpolicy.DynamicPolicy, recorder events.EventRecorder, resource schema.GroupResource, c clock.PassiveClock) *REST {
 	return &REST{
-		authenticator:  authenticator,
-		issuer:         issuer,
-		tableConvertor: rest.NewDefaultTableConvertor(resource),
+		authenticator:   authenticator,
+		issuer:          issuer,
+		certLifetime:    certLifetime,
+		policy:          policy,
+		outcomeEvents:   newOutcomeEventRecorder(recorder, c),
+		tableConvertor:  rest.NewDefaultTableConvertor(resource),
+		credentialCache: newCredentialCache(c),
+		clock:           c,
 	}
 }
 
 type REST struct {
-	authenticator  TokenCredentialRequestAuthenticator
-	issuer         issuer.ClientCertIssuer
-	tableConvertor rest.TableConvertor
+	authenticator   TokenCredentialRequestAuthenticator
+	issuer          issuer.ClientCertIssuer
+	certLifetime    *issuer.DynamicClientCertLifetime
+	policy          *credentialpolicy.DynamicPolicy
+	outcomeEvents   *outcomeEventRecorder
+	tableConvertor  rest.TableConvertor
+	credentialCache *credentialCache
+	clock           clock.PassiveClock
 }
 
 // Assert that our *REST implements all the optional interfaces that we expect it to implement.
@@ -98,40 +135,77 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	})
 	defer t.Log()
 
+	start := r.clock.Now()
+
 	credentialRequest, err := validateRequest(ctx, obj, createValidation, options, t)
 	if err != nil {
 		return nil, err
 	}
 
+	response, outcome, userInfo := r.create(ctx, t, credentialRequest)
+	observeTokenCredentialRequest(credentialRequest, outcome, r.clock.Since(start))
+	r.outcomeEvents.record(ctx, credentialRequest, outcome, userInfo)
+	return response, nil
+}
+
+// create returns the response to a valid TokenCredentialRequest, the outcome of the request for its metrics and
+// Events, and the authenticated user, which is nil when authentication failed.
+func (r *REST) create(ctx context.Context, t *trace.Trace, credentialRequest *loginapi.TokenCredentialRequest) (*loginapi.TokenCredentialRequest, string, user.Info) {
+	authenticatorVersion := r.authenticatorVersion(credentialRequest)
+	if credential, userInfo := r.credentialCache.get(credentialRequest, authenticatorVersion); credential != nil {
+		if !r.isAllowedByPolicy(ctx, t, credentialRequest, userInfo) {
+			return deniedResponse(), outcomeDenied, userInfo
+		}
+		traceCacheHit(t)
+		return successResponse(credential), outcomeCached, userInfo
+	}
+
 	userInfo, err := r.authenticator.AuthenticateTokenCredentialRequest(ctx, credentialRequest)
 	if err != nil {
 		traceFailureWithError(t, "token authentication", err)
-		return failureResponse(), nil
+		if errors.Is(err, authncache.ErrNoSuchAuthenticator) {
+			return failureResponse(), outcomeUnknownAuthenticator, nil
+		}
+		return failureResponse(), outcomeAuthenticationFailed, nil
 	}
 	if ok := isUserInfoValid(userInfo); !ok {
 		traceSuccess(t, userInfo, false)
-		return failureResponse(), nil
+		return failureResponse(), outcomeAuthenticationFailed, nil
+	}
+
+	if !r.isAllowedByPolicy(ctx, t, credentialRequest, userInfo) {
+		return deniedResponse(), outcomeDenied, userInfo
 	}
 
-	// this timestamp should be returned from IssueClientCertPEM but this is a safe approximation
-	expires := metav1.NewTime(time.Now().UTC().Add(clientCertificateTTL))
-	certPEM, keyPEM, err := r.issuer.IssueClientCertPEM(userInfo.GetName(), userInfo.GetGroups(), clientCertificateTTL)
+	lifetime := r.certLifetime.Get()
+	certPEM, keyPEM, notAfter, err := r.issuer.IssueClientCertPEM(userInfo.GetName(), userInfo.GetGroups(), lifetime.TTL)
 	if err != nil {
 		traceFailureWithError(t, "cert issuer", err)
-		return failureResponse(), nil
+		return failureResponse(), outcomeIssuanceFailed, userInfo
 	}
+	// The refresh margin makes the clients get a new certificate before the old one is rejected.
+	expires := metav1.NewTime(notAfter.UTC().Add(-lifetime.RefreshMargin))
 
 	traceSuccess(t, userInfo, true)
 
-	return &loginapi.TokenCredentialRequest{
-		Status: loginapi.TokenCredentialRequestStatus{
-			Credential: &loginapi.ClusterCredential{
-				ExpirationTimestamp:   expires,
-				ClientCertificateData: string(certPEM),
-				ClientKeyData:         string(keyPEM),
-			},
-		},
-	}, nil
+	credential := &loginapi.ClusterCredential{
+		ExpirationTimestamp:   expires,
+		ClientCertificateData: string(certPEM),
+		ClientKeyData:         string(keyPEM),
+	}
+	r.credentialCache.put(credentialRequest, authenticatorVersion, userInfo, credential)
+
+	return successResponse(credential), outcomeIssued, userInfo
+}
+
+// authenticatorVersion returns the version of the authenticator of the request when the authenticator can tell it,
+// or zero otherwise.
+func (r *REST) authenticatorVersion(req *loginapi.TokenCredentialRequest) uint64 {
+	versioned, ok := r.authenticator.(versionedAuthenticator)
+	if !ok {
+		return 0
+	}
+	return versioned.AuthenticatorVersion(req)
 }
 
 func validateRequest(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions, t *trace.Trace) (*loginapi.TokenCredentialRequest, error) {
@@ -178,6 +252,49 @@ func validateRequest(ctx context.Context, obj runtime.Object, createValidation r
 	return credentialRequest, nil
 }
 
+// isAllowedByPolicy evaluates the credential issuance policy, when one is configured, and records its decision in the
+// audit event of the request and in the log.
+func (r *REST) isAllowedByPolicy(ctx context.Context, t *trace.Trace, req *loginapi.TokenCredentialRequest, userInfo user.Info) bool {
+	policy := r.policy.Get()
+	if policy == nil {
+		return true
+	}
+
+	authenticator := req.Spec.Authenticator
+	policyRequest := credentialpolicy.Request{
+		Username:              userInfo.GetName(),
+		Groups:                userInfo.GetGroups(),
+		SourceIP:              credentialpolicy.SourceIPFromContext(ctx),
+		AuthenticatorAPIGroup: pointer.StringDeref(authenticator.APIGroup, ""),
+		AuthenticatorKind:     authenticator.Kind,
+		AuthenticatorName:     authenticator.Name,
+	}
+	decision := policy.Evaluate(ctx, policyRequest)
+
+	logKeysAndValues := []interface{}{
+		"allowed", decision.Allowed,
+		"username", policyRequest.Username,
+		"groups", policyRequest.Groups,
+		"sourceIP", policyRequest.SourceIP,
+		"authenticatorKind", policyRequest.AuthenticatorKind,
+		"authenticatorName", policyRequest.AuthenticatorName,
+	}
+	if decision.Allowed {
+		audit.AddAuditAnnotation(ctx, policyDecisionAuditAnnotation, "allow")
+		plog.Info("credential issuance policy allowed credential", logKeysAndValues...)
+		return true
+	}
+
+	audit.AddAuditAnnotation(ctx, policyDecisionAuditAnnotation, "deny")
+	audit.AddAuditAnnotation(ctx, policyReasonAuditAnnotation, decision.Reason)
+	plog.Info("credential issuance policy denied credential", append(logKeysAndValues, "reason", decision.Reason)...)
+	t.Step("failure",
+		trace.Field{Key: "failureType", Value: "credential issuance policy"},
+		trace.Field{Key: "msg", Value: decision.Reason},
+	)
+	return false
+}
+
 func isUserInfoValid(userInfo user.Info) bool {
 	switch {
 	case userInfo == nil, // must be non-nil
@@ -205,6 +322,13 @@ func traceSuccess(t *trace.Trace, userInfo user.Info, authenticated bool) {
 	)
 }
 
+func traceCacheHit(t *trace.Trace) {
+	t.Step("success",
+		trace.Field{Key: "cached", Value: true},
+		trace.Field{Key: "authenticated", Value: true},
+	)
+}
+
 func traceValidationFailure(t *trace.Trace, msg string) {
 	t.Step("failure",
 		trace.Field{Key: "failureType", Value: "request validation"},
@@ -219,6 +343,24 @@ func traceFailureWithError(t *trace.Trace, failureType string, err error) {
 	)
 }
 
+func successResponse(credential *loginapi.ClusterCredential) *loginapi.TokenCredentialRequest {
+	return &loginapi.TokenCredentialRequest{
+		Status: loginapi.TokenCredentialRequestStatus{
+			Credential: credential,
+		},
+	}
+}
+
+func deniedResponse() *loginapi.TokenCredentialRequest {
+	m := "credential issuance denied by policy"
+	return &loginapi.TokenCredentialRequest{
+		Status: loginapi.TokenCredentialRequestStatus{
+			Credential: nil,
+			Message:    &m,
+		},
+	}
+}
+
 func failureResponse() *loginapi.TokenCredentialRequest {
 	m := "authentication failed"
 	return &loginapi.TokenCredentialRequest{
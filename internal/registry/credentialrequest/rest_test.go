@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package credentialrequest
@@ -14,25 +14,32 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/sclevine/spec"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
+	clocktesting "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/credentialpolicy"
 	"go.pinniped.dev/internal/issuer"
 	"go.pinniped.dev/internal/mocks/credentialrequestmocks"
 	"go.pinniped.dev/internal/mocks/issuermocks"
+	"go.pinniped.dev/internal/mocks/mocktokenauthenticator"
 	"go.pinniped.dev/internal/testutil"
 )
 
 func TestNew(t *testing.T) {
-	r := NewREST(nil, nil, schema.GroupResource{Group: "bears", Resource: "panda"})
+	r := NewREST(nil, nil, nil, nil, nil, schema.GroupResource{Group: "bears", Resource: "panda"})
 	require.NotNil(t, r)
 	require.False(t, r.NamespaceScoped())
 	require.Equal(t, []string{"pinniped"}, r.Categories())
@@ -96,9 +103,9 @@ func TestCreate(t *testing.T) {
 				"test-user",
 				[]string{"test-group-1", "test-group-2"},
 				5*time.Minute,
-			).Return([]byte("test-cert"), []byte("test-key"), nil)
+			).Return([]byte("test-cert"), []byte("test-key"), time.Now().Add(5*time.Minute), nil)
 
-			storage := NewREST(requestAuthenticator, clientCertIssuer, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, clientCertIssuer, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -135,22 +142,297 @@ func TestCreate(t *testing.T) {
 			clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
 			clientCertIssuer.EXPECT().
 				IssueClientCertPEM(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(nil, nil, fmt.Errorf("some certificate authority error"))
+				Return(nil, nil, time.Time{}, fmt.Errorf("some certificate authority error"))
 
-			storage := NewREST(requestAuthenticator, clientCertIssuer, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, clientCertIssuer, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 			requireSuccessfulResponseWithAuthenticationFailureMessage(t, err, response)
 			requireOneLogStatement(r, logger, `"failure" failureType:cert issuer,msg:some certificate authority error`)
 		})
 
+		it("CreateUsesTheConfiguredClientCertLifetime", func() {
+			req := validCredentialRequest()
+			fakeClock := clocktesting.NewFakeClock(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil)
+
+			clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
+			clientCertIssuer.EXPECT().IssueClientCertPEM("test-user", nil, 30*time.Minute).
+				Return([]byte("test-cert"), []byte("test-key"), fakeClock.Now().Add(30*time.Minute), nil)
+
+			certLifetime := issuer.NewDynamicClientCertLifetime()
+			certLifetime.Set(issuer.ClientCertLifetime{TTL: 30 * time.Minute, RefreshMargin: 2 * time.Minute})
+
+			storage := newRESTWithClock(requestAuthenticator, clientCertIssuer, certLifetime, nil, nil, schema.GroupResource{}, fakeClock)
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			// The clients are told that the certificate expires before the end of its TTL.
+			r.Equal(&loginapi.TokenCredentialRequest{
+				Status: loginapi.TokenCredentialRequestStatus{
+					Credential: &loginapi.ClusterCredential{
+						ExpirationTimestamp:   metav1.NewTime(fakeClock.Now().Add(28 * time.Minute)),
+						ClientCertificateData: "test-cert",
+						ClientKeyData:         "test-key",
+					},
+				},
+			}, response)
+		})
+
+		it("CreateUsesTheExpirationOfTheIssuedCertificate", func() {
+			req := validCredentialRequest()
+			fakeClock := clocktesting.NewFakeClock(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil)
+
+			// The issuer may issue a certificate for longer than requested, e.g. to meet a minimum lifetime.
+			clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
+			clientCertIssuer.EXPECT().IssueClientCertPEM("test-user", nil, 5*time.Minute).
+				Return([]byte("test-cert"), []byte("test-key"), fakeClock.Now().Add(10*time.Minute), nil)
+
+			storage := newRESTWithClock(requestAuthenticator, clientCertIssuer, nil, nil, nil, schema.GroupResource{}, fakeClock)
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.Equal(&loginapi.TokenCredentialRequest{
+				Status: loginapi.TokenCredentialRequestStatus{
+					Credential: &loginapi.ClusterCredential{
+						ExpirationTimestamp:   metav1.NewTime(fakeClock.Now().Add(10 * time.Minute)),
+						ClientCertificateData: "test-cert",
+						ClientKeyData:         "test-key",
+					},
+				},
+			}, response)
+		})
+
+		it("CreateReturnsTheCachedCredentialWhileAtLeastHalfOfItsLifetimeRemains", func() {
+			req := validCredentialRequest()
+			fakeClock := clocktesting.NewFakeClock(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil).Times(2)
+
+			clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
+			gomock.InOrder(
+				clientCertIssuer.EXPECT().IssueClientCertPEM("test-user", nil, 5*time.Minute).
+					Return([]byte("test-cert-1"), []byte("test-key-1"), fakeClock.Now().Add(5*time.Minute), nil),
+				clientCertIssuer.EXPECT().IssueClientCertPEM("test-user", nil, 5*time.Minute).
+					Return([]byte("test-cert-2"), []byte("test-key-2"), fakeClock.Now().Add(2*time.Minute+31*time.Second+5*time.Minute), nil),
+			)
+
+			storage := newRESTWithClock(requestAuthenticator, clientCertIssuer, nil, nil, nil, schema.GroupResource{}, fakeClock)
+
+			wantFirstResponse := &loginapi.TokenCredentialRequest{
+				Status: loginapi.TokenCredentialRequestStatus{
+					Credential: &loginapi.ClusterCredential{
+						ExpirationTimestamp:   metav1.NewTime(fakeClock.Now().Add(5 * time.Minute)),
+						ClientCertificateData: "test-cert-1",
+						ClientKeyData:         "test-key-1",
+					},
+				},
+			}
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.Equal(wantFirstResponse, response)
+
+			// The same token gets the same credential, without being authenticated again.
+			fakeClock.Step(2*time.Minute + 29*time.Second)
+			response, err = callCreate(context.Background(), storage, validCredentialRequest())
+			r.NoError(err)
+			r.Equal(wantFirstResponse, response)
+
+			// Once less than half of the lifetime of the credential remains, a new credential is issued.
+			fakeClock.Step(2 * time.Second)
+			response, err = callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.Equal(&loginapi.TokenCredentialRequest{
+				Status: loginapi.TokenCredentialRequestStatus{
+					Credential: &loginapi.ClusterCredential{
+						ExpirationTimestamp:   metav1.NewTime(fakeClock.Now().Add(5 * time.Minute)),
+						ClientCertificateData: "test-cert-2",
+						ClientKeyData:         "test-key-2",
+					},
+				},
+			}, response)
+		})
+
+		it("CreateDoesNotReturnTheCachedCredentialAfterTheAuthenticatorWasChanged", func() {
+			req := validCredentialRequest()
+			fakeClock := clocktesting.NewFakeClock(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+			tokenAuthenticator := mocktokenauthenticator.NewMockToken(ctrl)
+			tokenAuthenticator.EXPECT().AuthenticateToken(gomock.Any(), "some token").
+				Return(&authenticator.Response{User: &user.DefaultInfo{Name: "test-user"}}, true, nil).Times(2)
+			authenticators := authncache.New()
+			authenticators.Store(authncache.Key{}, tokenAuthenticator)
+
+			clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
+			gomock.InOrder(
+				clientCertIssuer.EXPECT().IssueClientCertPEM("test-user", nil, 5*time.Minute).
+					Return([]byte("test-cert-1"), []byte("test-key-1"), fakeClock.Now().Add(5*time.Minute), nil),
+				clientCertIssuer.EXPECT().IssueClientCertPEM("test-user", nil, 5*time.Minute).
+					Return([]byte("test-cert-2"), []byte("test-key-2"), fakeClock.Now().Add(5*time.Minute), nil),
+			)
+
+			storage := newRESTWithClock(authenticators, clientCertIssuer, nil, nil, nil, schema.GroupResource{}, fakeClock)
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.Equal("test-cert-1", response.(*loginapi.TokenCredentialRequest).Status.Credential.ClientCertificateData)
+
+			// Storing the authenticator again, e.g. after its resource was changed, causes the token to be authenticated again.
+			authenticators.Store(authncache.Key{}, tokenAuthenticator)
+			response, err = callCreate(context.Background(), storage, validCredentialRequest())
+			r.NoError(err)
+			r.Equal("test-cert-2", response.(*loginapi.TokenCredentialRequest).Status.Credential.ClientCertificateData)
+		})
+
+		it("CreateDoesNotReturnTheCachedCredentialForAnotherTokenOrAuthenticator", func() {
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), gomock.Any()).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil).Times(3)
+
+			clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
+			clientCertIssuer.EXPECT().IssueClientCertPEM(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]byte("test-cert"), []byte("test-key"), time.Now().Add(5*time.Minute), nil).Times(3)
+
+			storage := NewREST(requestAuthenticator, clientCertIssuer, nil, nil, nil, schema.GroupResource{})
+
+			for _, req := range []*loginapi.TokenCredentialRequest{
+				validCredentialRequest(),
+				validCredentialRequestWithToken("some other token"),
+				credentialRequest(loginapi.TokenCredentialRequestSpec{
+					Token:         "some token",
+					Authenticator: corev1.TypedLocalObjectReference{Kind: "JWTAuthenticator", Name: "some-authenticator"},
+				}),
+			} {
+				response, err := callCreate(context.Background(), storage, req)
+				r.NoError(err)
+				r.NotNil(response.(*loginapi.TokenCredentialRequest).Status.Credential)
+			}
+		})
+
+		it("CreateIssuesACredentialWhenThePolicyAllowsIt", func() {
+			req := validCredentialRequest()
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user", Groups: []string{"test-group"}}, nil)
+
+			policy, err := credentialpolicy.New(credentialpolicy.Config{Expression: `username == "test-user" && "test-group" in groups`})
+			r.NoError(err)
+			dynamicPolicy := credentialpolicy.NewDynamicPolicy()
+			dynamicPolicy.Set(policy)
+
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, dynamicPolicy, nil, schema.GroupResource{})
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.NotNil(response.(*loginapi.TokenCredentialRequest).Status.Credential)
+		})
+
+		it("CreateDoesNotIssueACredentialWhenThePolicyDeniesIt", func() {
+			req := validCredentialRequest()
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil)
+
+			dynamicPolicy := credentialpolicy.NewDynamicPolicy()
+			dynamicPolicy.Set(credentialpolicy.DenyAll("some reason"))
+
+			// The issuer must not be called.
+			storage := NewREST(requestAuthenticator, issuermocks.NewMockClientCertIssuer(ctrl), nil, dynamicPolicy, nil, schema.GroupResource{})
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.Equal(&loginapi.TokenCredentialRequest{
+				Status: loginapi.TokenCredentialRequestStatus{
+					Message: pointer.String("credential issuance denied by policy"),
+				},
+			}, response)
+		})
+
+		it("CreateEvaluatesThePolicyAgainBeforeReturningTheCachedCredential", func() {
+			req := validCredentialRequest()
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil)
+
+			dynamicPolicy := credentialpolicy.NewDynamicPolicy()
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, dynamicPolicy, nil, schema.GroupResource{})
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.NotNil(response.(*loginapi.TokenCredentialRequest).Status.Credential)
+
+			// The policy now denies the user of the cached credential, so it is not returned.
+			dynamicPolicy.Set(credentialpolicy.DenyAll("some reason"))
+			response, err = callCreate(context.Background(), storage, validCredentialRequest())
+			r.NoError(err)
+			r.Equal(&loginapi.TokenCredentialRequest{
+				Status: loginapi.TokenCredentialRequestStatus{
+					Message: pointer.String("credential issuance denied by policy"),
+				},
+			}, response)
+		})
+
+		it("CreateRecordsAnEventRegardingTheAuthenticator", func() {
+			req := credentialRequest(loginapi.TokenCredentialRequestSpec{
+				Token:         "some token",
+				Authenticator: corev1.TypedLocalObjectReference{Kind: "JWTAuthenticator", Name: "some-authenticator"},
+			})
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+				Return(&user.DefaultInfo{Name: "test-user"}, nil)
+
+			eventRecorder := events.NewFakeRecorder(10)
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, nil, eventRecorder, schema.GroupResource{})
+
+			response, err := callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.NotNil(response.(*loginapi.TokenCredentialRequest).Status.Credential)
+			r.Len(eventRecorder.Events, 1)
+			r.Equal(`Normal CredentialIssued issued a cluster credential to user "test-user" from an unknown address`, <-eventRecorder.Events)
+		})
+
+		it("CreateDoesNotCacheFailedAuthentications", func() {
+			req := validCredentialRequest()
+
+			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
+			gomock.InOrder(
+				requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+					Return(nil, errors.New("some webhook error")),
+				requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
+					Return(&user.DefaultInfo{Name: "test-user"}, nil),
+			)
+
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, nil, nil, schema.GroupResource{})
+
+			response, err := callCreate(context.Background(), storage, req)
+			requireSuccessfulResponseWithAuthenticationFailureMessage(t, err, response)
+
+			response, err = callCreate(context.Background(), storage, req)
+			r.NoError(err)
+			r.Equal("test-cert", response.(*loginapi.TokenCredentialRequest).Status.Credential.ClientCertificateData)
+		})
+
 		it("CreateSucceedsWithAnUnauthenticatedStatusWhenGivenATokenAndTheWebhookReturnsNilUser", func() {
 			req := validCredentialRequest()
 
 			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).Return(nil, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -165,7 +447,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
 				Return(nil, errors.New("some webhook error"))
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -180,7 +462,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
 				Return(&user.DefaultInfo{Name: ""}, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -199,7 +481,7 @@ func TestCreate(t *testing.T) {
 					Groups: []string{"test-group-1", "test-group-2"},
 				}, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -218,7 +500,7 @@ func TestCreate(t *testing.T) {
 					Extra:  map[string][]string{"test-key": {"test-val-1", "test-val-2"}},
 				}, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, nil, nil, nil, schema.GroupResource{})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -228,7 +510,7 @@ func TestCreate(t *testing.T) {
 
 		it("CreateFailsWhenGivenTheWrongInputType", func() {
 			notACredentialRequest := runtime.Unknown{}
-			response, err := NewREST(nil, nil, schema.GroupResource{}).Create(
+			response, err := NewREST(nil, nil, nil, nil, nil, schema.GroupResource{}).Create(
 				genericapirequest.NewContext(),
 				&notACredentialRequest,
 				rest.ValidateAllObjectFunc,
@@ -239,7 +521,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenTokenValueIsEmptyInRequest", func() {
-			storage := NewREST(nil, nil, schema.GroupResource{})
+			storage := NewREST(nil, nil, nil, nil, nil, schema.GroupResource{})
 			response, err := callCreate(context.Background(), storage, credentialRequest(loginapi.TokenCredentialRequestSpec{
 				Token: "",
 			}))
@@ -250,7 +532,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenValidationFails", func() {
-			storage := NewREST(nil, nil, schema.GroupResource{})
+			storage := NewREST(nil, nil, nil, nil, nil, schema.GroupResource{})
 			response, err := storage.Create(
 				context.Background(),
 				validCredentialRequest(),
@@ -270,7 +552,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req.DeepCopy()).
 				Return(&user.DefaultInfo{Name: "test-user"}, nil)
 
-			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, nil, nil, schema.GroupResource{})
 			response, err := storage.Create(
 				context.Background(),
 				req,
@@ -291,7 +573,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req.DeepCopy()).
 				Return(&user.DefaultInfo{Name: "test-user"}, nil)
 
-			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), nil, nil, nil, schema.GroupResource{})
 			validationFunctionWasCalled := false
 			var validationFunctionSawTokenValue string
 			response, err := storage.Create(
@@ -311,7 +593,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenRequestOptionsDryRunIsNotEmpty", func() {
-			response, err := NewREST(nil, nil, schema.GroupResource{}).Create(
+			response, err := NewREST(nil, nil, nil, nil, nil, schema.GroupResource{}).Create(
 				genericapirequest.NewContext(),
 				validCredentialRequest(),
 				rest.ValidateAllObjectFunc,
@@ -325,7 +607,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenNamespaceIsNotEmpty", func() {
-			response, err := NewREST(nil, nil, schema.GroupResource{}).Create(
+			response, err := NewREST(nil, nil, nil, nil, nil, schema.GroupResource{}).Create(
 				genericapirequest.WithNamespace(genericapirequest.NewContext(), "some-ns"),
 				validCredentialRequest(),
 				rest.ValidateAllObjectFunc,
@@ -396,6 +678,6 @@ func successfulIssuer(ctrl *gomock.Controller) issuer.ClientCertIssuer {
 	clientCertIssuer := issuermocks.NewMockClientCertIssuer(ctrl)
 	clientCertIssuer.EXPECT().
 		IssueClientCertPEM(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return([]byte("test-cert"), []byte("test-key"), nil)
+		Return([]byte("test-cert"), []byte("test-key"), time.Now().Add(5*time.Minute), nil)
 	return clientCertIssuer
 }
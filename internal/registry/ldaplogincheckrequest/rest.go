@@ -0,0 +1,300 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ldaplogincheckrequest provides REST functionality for the LDAPLoginCheckRequest resource.
+package ldaplogincheckrequest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/utils/trace"
+
+	logincheckapi "go.pinniped.dev/generated/latest/apis/supervisor/logincheck"
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// KindLDAPIdentityProvider is the kind of identity provider for LDAPIdentityProviders.
+	KindLDAPIdentityProvider = "LDAPIdentityProvider"
+
+	// KindActiveDirectoryIdentityProvider is the kind of identity provider for ActiveDirectoryIdentityProviders.
+	KindActiveDirectoryIdentityProvider = "ActiveDirectoryIdentityProvider"
+)
+
+type timeNowFunc func() metav1.Time
+
+func NewREST(
+	resource schema.GroupResource,
+	idpProvider provider.DynamicUpstreamIDPProvider,
+	namespace string,
+	timeNowFunc timeNowFunc,
+) *REST {
+	return &REST{
+		idpProvider:    idpProvider,
+		namespace:      namespace,
+		tableConvertor: rest.NewDefaultTableConvertor(resource),
+		timeNowFunc:    timeNowFunc,
+	}
+}
+
+type REST struct {
+	idpProvider    provider.DynamicUpstreamIDPProvider
+	namespace      string
+	tableConvertor rest.TableConvertor
+	timeNowFunc    timeNowFunc
+}
+
+// Assert that our *REST implements all the optional interfaces that we expect it to implement.
+var _ interface {
+	rest.Creater
+	rest.NamespaceScopedStrategy
+	rest.Scoper
+	rest.Storage
+	rest.CategoriesProvider
+	rest.SingularNameProvider
+	rest.Lister
+	rest.TableConvertor
+} = (*REST)(nil)
+
+func (*REST) New() runtime.Object {
+	return &logincheckapi.LDAPLoginCheckRequest{}
+}
+
+func (*REST) Destroy() {}
+
+func (*REST) NewList() runtime.Object {
+	return &logincheckapi.LDAPLoginCheckRequestList{}
+}
+
+// List implements the list verb. Support the list verb to support `kubectl get pinniped`, to make sure all resources
+// are in the pinniped category, and avoid kubectl errors when kubectl lists.
+func (*REST) List(_ context.Context, _ *metainternalversion.ListOptions) (runtime.Object, error) {
+	return &logincheckapi.LDAPLoginCheckRequestList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion: "0", // this resource version means "from the API server cache"
+		},
+		Items: []logincheckapi.LDAPLoginCheckRequest{}, // avoid sending nil items list
+	}, nil
+}
+
+func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return r.tableConvertor.ConvertToTable(ctx, obj, tableOptions)
+}
+
+func (*REST) NamespaceScoped() bool {
+	return true
+}
+
+func (*REST) Categories() []string {
+	return []string{"pinniped"}
+}
+
+func (*REST) GetSingularName() string {
+	return "ldaplogincheckrequest"
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	t := trace.FromContext(ctx).Nest("create",
+		trace.Field{Key: "kind", Value: "LDAPLoginCheckRequest"},
+		trace.Field{Key: "metadata.name", Value: name(obj)},
+	)
+	defer t.Log()
+
+	// Validate the create request before honoring it.
+	// This function is provided from kube kube-api server calling validating admission webhooks if there are any registered.
+	req, err := r.validateRequest(ctx, obj, createValidation, options, t)
+	if err != nil {
+		return nil, err
+	}
+	t.Step("validateRequest")
+
+	// Find the specified identity provider among those which were successfully loaded by the watcher controllers.
+	upstream := r.findUpstream(req.Spec.IdentityProvider)
+	if upstream == nil {
+		msg := fmt.Sprintf("%s %q was not found or is not ready", req.Spec.IdentityProvider.Kind, req.Spec.IdentityProvider.Name)
+		traceFailure(t, "findUpstream", msg)
+		errs := field.ErrorList{field.NotFound(field.NewPath("spec", "identityProvider", "name"), req.Spec.IdentityProvider.Name)}
+		return nil, apierrors.NewInvalid(kindFromContext(ctx), req.Name, errs)
+	}
+	t.Step("findUpstream")
+
+	// Perform the same user search, bind, and group search as a login would. The groups scope is always granted,
+	// so that the result shows which groups the user would get.
+	status := logincheckapi.LDAPLoginCheckRequestStatus{}
+	authenticateResponse, authenticated, err := upstream.AuthenticateUser(ctx, req.Spec.Username, req.Spec.Password, []string{oidcapi.ScopeGroups})
+	switch {
+	case errors.Is(err, authenticators.ErrThrottled):
+		traceFailureWithError(t, "AuthenticateUser", err)
+		return nil, apierrors.NewTooManyRequests("too many recent authentication attempts, please try again later",
+			int(authenticators.RetryAfter(err)/time.Second))
+	case err != nil:
+		traceFailureWithError(t, "AuthenticateUser", err)
+		plog.WarningErr("unexpected error during upstream LDAP login check", err,
+			"upstreamName", upstream.GetName(), "upstreamKind", req.Spec.IdentityProvider.Kind)
+		// Do not return the details of the error, which could include details of the LDAP server's responses.
+		status.Message = "unexpected error while checking the login, see the Supervisor logs for details"
+	case !authenticated:
+		t.Step("AuthenticateUser")
+		status.Message = "username or password not accepted by the identity provider"
+	default:
+		t.Step("AuthenticateUser")
+		status.Authenticated = true
+		status.Message = "login succeeded"
+		status.Username = authenticateResponse.User.GetName()
+		status.DN = authenticateResponse.DN
+		status.Groups = authenticateResponse.User.GetGroups()
+	}
+
+	// Never return the password.
+	return &logincheckapi.LDAPLoginCheckRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              req.Name,
+			Namespace:         req.Namespace,
+			CreationTimestamp: r.timeNowFunc(),
+		},
+		Spec: logincheckapi.LDAPLoginCheckRequestSpec{
+			IdentityProvider: req.Spec.IdentityProvider,
+			Username:         req.Spec.Username,
+		},
+		Status: status,
+	}, nil
+}
+
+func (r *REST) findUpstream(idp logincheckapi.LDAPLoginCheckIdentityProvider) provider.UpstreamLDAPIdentityProviderI {
+	var upstreams []provider.UpstreamLDAPIdentityProviderI
+	switch idp.Kind {
+	case KindLDAPIdentityProvider:
+		upstreams = r.idpProvider.GetLDAPIdentityProviders()
+	case KindActiveDirectoryIdentityProvider:
+		upstreams = r.idpProvider.GetActiveDirectoryIdentityProviders()
+	}
+	for _, upstream := range upstreams {
+		if upstream.GetName() == idp.Name {
+			return upstream
+		}
+	}
+	return nil
+}
+
+func (r *REST) validateRequest(
+	ctx context.Context,
+	obj runtime.Object,
+	createValidation rest.ValidateObjectFunc,
+	options *metav1.CreateOptions,
+	tracer *trace.Trace,
+) (*logincheckapi.LDAPLoginCheckRequest, error) {
+	loginCheckRequest, ok := obj.(*logincheckapi.LDAPLoginCheckRequest)
+	if !ok {
+		traceValidationFailure(tracer, "not an LDAPLoginCheckRequest")
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not an LDAPLoginCheckRequest: %#v", obj))
+	}
+
+	// Ensure namespace on the object is correct, or error if a conflicting namespace was set in the object.
+	requestNamespace, ok := genericapirequest.NamespaceFrom(ctx)
+	if !ok {
+		msg := "no namespace information found in request context"
+		traceValidationFailure(tracer, msg)
+		return nil, apierrors.NewInternalError(fmt.Errorf(msg))
+	}
+	if err := rest.EnsureObjectNamespaceMatchesRequestNamespace(requestNamespace, loginCheckRequest); err != nil {
+		traceValidationFailure(tracer, err.Error())
+		return nil, err
+	}
+	// Identity providers only exist in the supervisor's namespace.
+	if requestNamespace != r.namespace {
+		msg := fmt.Sprintf("namespace must be %s on LDAPLoginCheckRequest, was %s", r.namespace, requestNamespace)
+		traceValidationFailure(tracer, msg)
+		return nil, apierrors.NewBadRequest(msg)
+	}
+
+	var errs field.ErrorList
+	idpPath := field.NewPath("spec", "identityProvider")
+	switch loginCheckRequest.Spec.IdentityProvider.Kind {
+	case KindLDAPIdentityProvider, KindActiveDirectoryIdentityProvider:
+	case "":
+		errs = append(errs, field.Required(idpPath.Child("kind"), ""))
+	default:
+		errs = append(errs, field.NotSupported(idpPath.Child("kind"), loginCheckRequest.Spec.IdentityProvider.Kind,
+			[]string{KindLDAPIdentityProvider, KindActiveDirectoryIdentityProvider}))
+	}
+	if loginCheckRequest.Spec.IdentityProvider.Name == "" {
+		errs = append(errs, field.Required(idpPath.Child("name"), ""))
+	}
+	if loginCheckRequest.Spec.Username == "" {
+		errs = append(errs, field.Required(field.NewPath("spec", "username"), ""))
+	}
+	if loginCheckRequest.Spec.Password == "" {
+		errs = append(errs, field.Required(field.NewPath("spec", "password"), ""))
+	}
+	if len(errs) > 0 {
+		traceValidationFailure(tracer, errs.ToAggregate().Error())
+		return nil, apierrors.NewInvalid(kindFromContext(ctx), loginCheckRequest.Name, errs)
+	}
+
+	// just a sanity check, not sure how to honor a dry run on a virtual API
+	if options != nil {
+		if len(options.DryRun) != 0 {
+			traceValidationFailure(tracer, "dryRun not supported")
+			errs := field.ErrorList{field.NotSupported(field.NewPath("dryRun"), options.DryRun, nil)}
+			return nil, apierrors.NewInvalid(kindFromContext(ctx), loginCheckRequest.Name, errs)
+		}
+	}
+
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			traceFailureWithError(tracer, "validation webhook", err)
+			return nil, err
+		}
+	}
+
+	return loginCheckRequest, nil
+}
+
+func traceFailure(t *trace.Trace, failureType string, msg string) {
+	t.Step("failure",
+		trace.Field{Key: "failureType", Value: failureType},
+		trace.Field{Key: "msg", Value: msg},
+	)
+}
+
+func traceValidationFailure(t *trace.Trace, msg string) {
+	traceFailure(t, "request validation", msg)
+}
+
+func traceFailureWithError(t *trace.Trace, failureType string, err error) {
+	t.Step("failure",
+		trace.Field{Key: "failureType", Value: failureType},
+		trace.Field{Key: "msg", Value: err.Error()},
+	)
+}
+
+func name(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "<unknown>"
+	}
+	return accessor.GetName()
+}
+
+func kindFromContext(ctx context.Context) schema.GroupKind {
+	if info, ok := genericapirequest.RequestInfoFrom(ctx); ok {
+		return schema.GroupKind{Group: info.APIGroup, Kind: "LDAPLoginCheckRequest"}
+	}
+	// this should never happen in practice
+	return logincheckapi.Kind("LDAPLoginCheckRequest")
+}
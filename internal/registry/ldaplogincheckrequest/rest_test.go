@@ -0,0 +1,245 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ldaplogincheckrequest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	logincheckapi "go.pinniped.dev/generated/latest/apis/supervisor/logincheck"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+func TestNew(t *testing.T) {
+	r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, nil, "foobar", nil)
+
+	require.NotNil(t, r)
+	require.True(t, r.NamespaceScoped())
+	require.Equal(t, []string{"pinniped"}, r.Categories())
+	require.Equal(t, "ldaplogincheckrequest", r.GetSingularName())
+
+	require.IsType(t, &logincheckapi.LDAPLoginCheckRequest{}, r.New())
+	require.IsType(t, &logincheckapi.LDAPLoginCheckRequestList{}, r.NewList())
+
+	ctx := context.Background()
+
+	// check the simple invariants of our no-op list
+	list, err := r.List(ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, list)
+	require.IsType(t, &logincheckapi.LDAPLoginCheckRequestList{}, list)
+	require.Equal(t, "0", list.(*logincheckapi.LDAPLoginCheckRequestList).ResourceVersion)
+	require.NotNil(t, list.(*logincheckapi.LDAPLoginCheckRequestList).Items)
+	require.Len(t, list.(*logincheckapi.LDAPLoginCheckRequestList).Items, 0)
+
+	// make sure we can turn lists into tables if needed
+	table, err := r.ConvertToTable(ctx, list, nil)
+	require.NoError(t, err)
+	require.NotNil(t, table)
+	require.Equal(t, "0", table.ResourceVersion)
+	require.Nil(t, table.Rows)
+}
+
+func TestCreate(t *testing.T) {
+	namespace := "some-namespace"
+	namespacedContext := genericapirequest.WithNamespace(
+		genericapirequest.WithRequestInfo(
+			genericapirequest.NewContext(),
+			&genericapirequest.RequestInfo{
+				APIGroup:   "logincheck.supervisor.pinniped.dev",
+				APIVersion: "v1alpha1",
+				Resource:   "ldaplogincheckrequests",
+			},
+		),
+		namespace,
+	)
+
+	fakeNow := metav1.Now()
+	fakeTimeNowFunc := func() metav1.Time { return fakeNow }
+
+	authenticateFunc := func(_ context.Context, username, password string) (*authenticators.Response, bool, error) {
+		switch {
+		case username == "some-user" && password == "some-password":
+			return &authenticators.Response{
+				User: &user.DefaultInfo{Name: "some-downstream-username", UID: "some-uid", Groups: []string{"group1", "group2"}},
+				DN:   "cn=some-user,ou=users,dc=example,dc=com",
+			}, true, nil
+		case username == "throttled-user":
+			return nil, false, &authenticators.ThrottledError{Reason: "username exceeded its authentication rate limit", RetryAfter: 30 * time.Second}
+		case username == "broken-user":
+			return nil, false, errors.New("some LDAP error which mentions ldap.example.com")
+		default:
+			return nil, false, nil
+		}
+	}
+	idpProvider := oidctestutil.NewUpstreamIDPListerBuilder().
+		WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "some-ldap-idp", AuthenticateFunc: authenticateFunc}).
+		WithActiveDirectory(&oidctestutil.TestUpstreamLDAPIdentityProvider{Name: "some-ad-idp", AuthenticateFunc: authenticateFunc}).
+		Build()
+
+	request := func(kind, idpName, username, password string) *logincheckapi.LDAPLoginCheckRequest {
+		return &logincheckapi.LDAPLoginCheckRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-check"},
+			Spec: logincheckapi.LDAPLoginCheckRequestSpec{
+				IdentityProvider: logincheckapi.LDAPLoginCheckIdentityProvider{Kind: kind, Name: idpName},
+				Username:         username,
+				Password:         password,
+			},
+		}
+	}
+	response := func(kind, idpName, username string, status logincheckapi.LDAPLoginCheckRequestStatus) *logincheckapi.LDAPLoginCheckRequest {
+		return &logincheckapi.LDAPLoginCheckRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-check", Namespace: namespace, CreationTimestamp: fakeNow},
+			Spec: logincheckapi.LDAPLoginCheckRequestSpec{
+				IdentityProvider: logincheckapi.LDAPLoginCheckIdentityProvider{Kind: kind, Name: idpName},
+				Username:         username,
+			},
+			Status: status,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		obj              runtime.Object
+		createValidation rest.ValidateObjectFunc
+		options          *metav1.CreateOptions
+		want             runtime.Object
+		wantErr          string
+		wantErrCode      int32
+
+		wantRetryAfterSeconds int32
+	}{
+		{
+			name: "successful login check against an LDAPIdentityProvider",
+			ctx:  namespacedContext,
+			obj:  request("LDAPIdentityProvider", "some-ldap-idp", "some-user", "some-password"),
+			want: response("LDAPIdentityProvider", "some-ldap-idp", "some-user", logincheckapi.LDAPLoginCheckRequestStatus{
+				Authenticated: true,
+				Message:       "login succeeded",
+				Username:      "some-downstream-username",
+				DN:            "cn=some-user,ou=users,dc=example,dc=com",
+				Groups:        []string{"group1", "group2"},
+			}),
+		},
+		{
+			name: "successful login check against an ActiveDirectoryIdentityProvider",
+			ctx:  namespacedContext,
+			obj:  request("ActiveDirectoryIdentityProvider", "some-ad-idp", "some-user", "some-password"),
+			want: response("ActiveDirectoryIdentityProvider", "some-ad-idp", "some-user", logincheckapi.LDAPLoginCheckRequestStatus{
+				Authenticated: true,
+				Message:       "login succeeded",
+				Username:      "some-downstream-username",
+				DN:            "cn=some-user,ou=users,dc=example,dc=com",
+				Groups:        []string{"group1", "group2"},
+			}),
+		},
+		{
+			name: "wrong password",
+			ctx:  namespacedContext,
+			obj:  request("LDAPIdentityProvider", "some-ldap-idp", "some-user", "wrong-password"),
+			want: response("LDAPIdentityProvider", "some-ldap-idp", "some-user", logincheckapi.LDAPLoginCheckRequestStatus{
+				Message: "username or password not accepted by the identity provider",
+			}),
+		},
+		{
+			name: "unexpected error from the identity provider is not returned",
+			ctx:  namespacedContext,
+			obj:  request("LDAPIdentityProvider", "some-ldap-idp", "broken-user", "some-password"),
+			want: response("LDAPIdentityProvider", "some-ldap-idp", "broken-user", logincheckapi.LDAPLoginCheckRequestStatus{
+				Message: "unexpected error while checking the login, see the Supervisor logs for details",
+			}),
+		},
+		{
+			name:                  "throttled",
+			ctx:                   namespacedContext,
+			obj:                   request("LDAPIdentityProvider", "some-ldap-idp", "throttled-user", "some-password"),
+			wantErr:               "too many recent authentication attempts, please try again later",
+			wantErrCode:           http.StatusTooManyRequests,
+			wantRetryAfterSeconds: 30,
+		},
+		{
+			name:        "identity provider of the wrong kind",
+			ctx:         namespacedContext,
+			obj:         request("ActiveDirectoryIdentityProvider", "some-ldap-idp", "some-user", "some-password"),
+			wantErr:     `LDAPLoginCheckRequest.logincheck.supervisor.pinniped.dev "some-check" is invalid: spec.identityProvider.name: Not found: "some-ldap-idp"`,
+			wantErrCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "invalid spec",
+			ctx:  namespacedContext,
+			obj:  request("OIDCIdentityProvider", "", "", ""),
+			wantErr: `LDAPLoginCheckRequest.logincheck.supervisor.pinniped.dev "some-check" is invalid: [` +
+				`spec.identityProvider.kind: Unsupported value: "OIDCIdentityProvider": supported values: "LDAPIdentityProvider", "ActiveDirectoryIdentityProvider", ` +
+				`spec.identityProvider.name: Required value, spec.username: Required value, spec.password: Required value]`,
+			wantErrCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "wrong type of request object provided",
+			ctx:         namespacedContext,
+			obj:         &metav1.Status{},
+			wantErr:     "not an LDAPLoginCheckRequest: ",
+			wantErrCode: http.StatusBadRequest,
+		},
+		{
+			name:        "incorrect namespace on request context",
+			ctx:         genericapirequest.WithNamespace(genericapirequest.NewContext(), "wrong-namespace"),
+			obj:         request("LDAPIdentityProvider", "some-ldap-idp", "some-user", "some-password"),
+			wantErr:     "namespace must be some-namespace on LDAPLoginCheckRequest, was wrong-namespace",
+			wantErrCode: http.StatusBadRequest,
+		},
+		{
+			name:        "bad options for dry run",
+			ctx:         namespacedContext,
+			obj:         request("LDAPIdentityProvider", "some-ldap-idp", "some-user", "some-password"),
+			options:     &metav1.CreateOptions{DryRun: []string{"stuff"}},
+			wantErr:     `LDAPLoginCheckRequest.logincheck.supervisor.pinniped.dev "some-check" is invalid: dryRun: Unsupported value: []string{"stuff"}`,
+			wantErrCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "create validation fails",
+			ctx:  namespacedContext,
+			obj:  request("LDAPIdentityProvider", "some-ldap-idp", "some-user", "some-password"),
+			createValidation: func(ctx context.Context, obj runtime.Object) error {
+				return apierrors.NewForbidden(schema.GroupResource{}, "some-check", errors.New("some webhook error"))
+			},
+			wantErr:     `forbidden: some webhook error`,
+			wantErrCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, idpProvider, namespace, fakeTimeNowFunc)
+
+			got, err := r.Create(tt.ctx, tt.obj, tt.createValidation, tt.options)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				var statusErr *apierrors.StatusError
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, tt.wantErrCode, statusErr.ErrStatus.Code)
+				if tt.wantRetryAfterSeconds != 0 {
+					require.Equal(t, tt.wantRetryAfterSeconds, statusErr.ErrStatus.Details.RetryAfterSeconds)
+				}
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sessionlistrequest provides REST functionality for the SessionListRequest resource.
+package sessionlistrequest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/utils/trace"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/oidc/sessionindex"
+)
+
+type timeNowFunc func() metav1.Time
+
+// SessionLister lists the downstream sessions which are selected by a sessionindex.Selector.
+type SessionLister interface {
+	List(selector sessionindex.Selector) ([]sessionindex.Session, error)
+}
+
+func NewREST(
+	resource schema.GroupResource,
+	sessionLister SessionLister,
+	namespace string,
+	timeNowFunc timeNowFunc,
+) *REST {
+	return &REST{
+		sessionLister:  sessionLister,
+		namespace:      namespace,
+		tableConvertor: rest.NewDefaultTableConvertor(resource),
+		timeNowFunc:    timeNowFunc,
+	}
+}
+
+type REST struct {
+	sessionLister  SessionLister
+	namespace      string
+	tableConvertor rest.TableConvertor
+	timeNowFunc    timeNowFunc
+}
+
+// Assert that our *REST implements all the optional interfaces that we expect it to implement.
+var _ interface {
+	rest.Creater
+	rest.NamespaceScopedStrategy
+	rest.Scoper
+	rest.Storage
+	rest.CategoriesProvider
+	rest.SingularNameProvider
+	rest.Lister
+	rest.TableConvertor
+} = (*REST)(nil)
+
+func (*REST) New() runtime.Object {
+	return &sessionapi.SessionListRequest{}
+}
+
+func (*REST) Destroy() {}
+
+func (*REST) NewList() runtime.Object {
+	return &sessionapi.SessionListRequestList{}
+}
+
+// List implements the list verb. Support the list verb to support `kubectl get pinniped`, to make sure all resources
+// are in the pinniped category, and avoid kubectl errors when kubectl lists.
+func (*REST) List(_ context.Context, _ *metainternalversion.ListOptions) (runtime.Object, error) {
+	return &sessionapi.SessionListRequestList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion: "0", // this resource version means "from the API server cache"
+		},
+		Items: []sessionapi.SessionListRequest{}, // avoid sending nil items list
+	}, nil
+}
+
+func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return r.tableConvertor.ConvertToTable(ctx, obj, tableOptions)
+}
+
+func (*REST) NamespaceScoped() bool {
+	return true
+}
+
+func (*REST) Categories() []string {
+	return []string{"pinniped"}
+}
+
+func (*REST) GetSingularName() string {
+	return "sessionlistrequest"
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	t := trace.FromContext(ctx).Nest("create",
+		trace.Field{Key: "kind", Value: "SessionListRequest"},
+		trace.Field{Key: "metadata.name", Value: name(obj)},
+	)
+	defer t.Log()
+
+	// Validate the create request before honoring it.
+	// This function is provided from kube kube-api server calling validating admission webhooks if there are any registered.
+	req, err := r.validateRequest(ctx, obj, createValidation, options, t)
+	if err != nil {
+		return nil, err
+	}
+	t.Step("validateRequest")
+
+	sessions, err := r.sessionLister.List(sessionindex.SelectorFromAPI(req.Spec.Selector))
+	if errors.Is(err, sessionindex.ErrNotSynced) {
+		traceFailureWithError(t, "listSessions", err)
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	if err != nil {
+		traceFailureWithError(t, "listSessions", err)
+		return nil, apierrors.NewInternalError(err)
+	}
+	t.Step("listSessions")
+
+	downstreamSessions := make([]sessionapi.DownstreamSession, 0, len(sessions))
+	for _, session := range sessions {
+		downstreamSessions = append(downstreamSessions, session.ToAPI())
+	}
+
+	return &sessionapi.SessionListRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              req.Name,
+			Namespace:         req.Namespace,
+			CreationTimestamp: r.timeNowFunc(),
+		},
+		Spec: req.Spec,
+		Status: sessionapi.SessionListRequestStatus{
+			Sessions: downstreamSessions,
+		},
+	}, nil
+}
+
+func (r *REST) validateRequest(
+	ctx context.Context,
+	obj runtime.Object,
+	createValidation rest.ValidateObjectFunc,
+	options *metav1.CreateOptions,
+	tracer *trace.Trace,
+) (*sessionapi.SessionListRequest, error) {
+	listRequest, ok := obj.(*sessionapi.SessionListRequest)
+	if !ok {
+		traceValidationFailure(tracer, "not a SessionListRequest")
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a SessionListRequest: %#v", obj))
+	}
+
+	// Ensure namespace on the object is correct, or error if a conflicting namespace was set in the object.
+	requestNamespace, ok := genericapirequest.NamespaceFrom(ctx)
+	if !ok {
+		msg := "no namespace information found in request context"
+		traceValidationFailure(tracer, msg)
+		return nil, apierrors.NewInternalError(fmt.Errorf(msg))
+	}
+	if err := rest.EnsureObjectNamespaceMatchesRequestNamespace(requestNamespace, listRequest); err != nil {
+		traceValidationFailure(tracer, err.Error())
+		return nil, err
+	}
+	// Sessions are only stored in the supervisor's namespace.
+	if requestNamespace != r.namespace {
+		msg := fmt.Sprintf("namespace must be %s on SessionListRequest, was %s", r.namespace, requestNamespace)
+		traceValidationFailure(tracer, msg)
+		return nil, apierrors.NewBadRequest(msg)
+	}
+
+	// just a sanity check, not sure how to honor a dry run on a virtual API
+	if options != nil {
+		if len(options.DryRun) != 0 {
+			traceValidationFailure(tracer, "dryRun not supported")
+			errs := field.ErrorList{field.NotSupported(field.NewPath("dryRun"), options.DryRun, nil)}
+			return nil, apierrors.NewInvalid(kindFromContext(ctx), listRequest.Name, errs)
+		}
+	}
+
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			traceFailureWithError(tracer, "validation webhook", err)
+			return nil, err
+		}
+	}
+
+	return listRequest, nil
+}
+
+func traceValidationFailure(t *trace.Trace, msg string) {
+	t.Step("failure",
+		trace.Field{Key: "failureType", Value: "request validation"},
+		trace.Field{Key: "msg", Value: msg},
+	)
+}
+
+func traceFailureWithError(t *trace.Trace, failureType string, err error) {
+	t.Step("failure",
+		trace.Field{Key: "failureType", Value: failureType},
+		trace.Field{Key: "msg", Value: err.Error()},
+	)
+}
+
+func name(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "<unknown>"
+	}
+	return accessor.GetName()
+}
+
+func kindFromContext(ctx context.Context) schema.GroupKind {
+	if info, ok := genericapirequest.RequestInfoFrom(ctx); ok {
+		return schema.GroupKind{Group: info.APIGroup, Kind: "SessionListRequest"}
+	}
+	// this should never happen in practice
+	return sessionapi.Kind("SessionListRequest")
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package sessionlistrequest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/oidc/sessionindex"
+	"go.pinniped.dev/internal/psession"
+)
+
+type fakeSessionLister struct {
+	sessions []sessionindex.Session
+	err      error
+	selector *sessionindex.Selector
+}
+
+func (f *fakeSessionLister) List(selector sessionindex.Selector) ([]sessionindex.Session, error) {
+	f.selector = &selector
+	return f.sessions, f.err
+}
+
+func TestNew(t *testing.T) {
+	r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, nil, "foobar", nil)
+
+	require.NotNil(t, r)
+	require.True(t, r.NamespaceScoped())
+	require.Equal(t, []string{"pinniped"}, r.Categories())
+	require.Equal(t, "sessionlistrequest", r.GetSingularName())
+
+	require.IsType(t, &sessionapi.SessionListRequest{}, r.New())
+	require.IsType(t, &sessionapi.SessionListRequestList{}, r.NewList())
+
+	ctx := context.Background()
+
+	// check the simple invariants of our no-op list
+	list, err := r.List(ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, list)
+	require.IsType(t, &sessionapi.SessionListRequestList{}, list)
+	require.Equal(t, "0", list.(*sessionapi.SessionListRequestList).ResourceVersion)
+	require.NotNil(t, list.(*sessionapi.SessionListRequestList).Items)
+	require.Len(t, list.(*sessionapi.SessionListRequestList).Items, 0)
+
+	// make sure we can turn lists into tables if needed
+	table, err := r.ConvertToTable(ctx, list, nil)
+	require.NoError(t, err)
+	require.NotNil(t, table)
+	require.Equal(t, "0", table.ResourceVersion)
+	require.Nil(t, table.Rows)
+}
+
+func TestCreate(t *testing.T) {
+	namespace := "some-namespace"
+	namespacedContext := genericapirequest.WithNamespace(
+		genericapirequest.WithRequestInfo(
+			genericapirequest.NewContext(),
+			&genericapirequest.RequestInfo{
+				APIGroup:   "session.supervisor.pinniped.dev",
+				APIVersion: "v1alpha1",
+				Resource:   "sessionlistrequests",
+			},
+		),
+		namespace,
+	)
+
+	fakeNow := metav1.Now()
+	fakeTimeNowFunc := func() metav1.Time { return fakeNow }
+
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := startedAt.Add(8 * time.Hour)
+	sessions := []sessionindex.Session{
+		{
+			ID:                   "some-request-id",
+			Username:             "some-username",
+			UpstreamUsername:     "some-upstream-username",
+			ClientID:             "pinniped-cli",
+			IdentityProviderName: "some-oidc-idp",
+			IdentityProviderType: psession.ProviderTypeOIDC,
+			Scopes:               []string{"openid", "offline_access"},
+			StartedAt:            startedAt,
+			ExpiresAt:            expiresAt,
+		},
+		{
+			ID:                   "other-request-id",
+			Username:             "some-username",
+			UpstreamUsername:     "some-upstream-username",
+			ClientID:             "some-client",
+			IdentityProviderName: "some-ldap-idp",
+			IdentityProviderType: psession.ProviderTypeLDAP,
+			Scopes:               []string{"openid"},
+			StartedAt:            startedAt,
+		},
+	}
+
+	request := func(selector sessionapi.SessionSelector) *sessionapi.SessionListRequest {
+		return &sessionapi.SessionListRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-list"},
+			Spec:       sessionapi.SessionListRequestSpec{Selector: selector},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		obj              runtime.Object
+		createValidation rest.ValidateObjectFunc
+		options          *metav1.CreateOptions
+		listerSessions   []sessionindex.Session
+		listerErr        error
+		wantSelector     *sessionindex.Selector
+		want             runtime.Object
+		wantErr          string
+		wantErrCode      int32
+	}{
+		{
+			name:           "lists the selected sessions",
+			ctx:            namespacedContext,
+			obj:            request(sessionapi.SessionSelector{Username: "some-username", ClientID: "pinniped-cli"}),
+			listerSessions: sessions,
+			wantSelector:   &sessionindex.Selector{Username: "some-username", ClientID: "pinniped-cli"},
+			want: &sessionapi.SessionListRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-list", Namespace: namespace, CreationTimestamp: fakeNow},
+				Spec: sessionapi.SessionListRequestSpec{
+					Selector: sessionapi.SessionSelector{Username: "some-username", ClientID: "pinniped-cli"},
+				},
+				Status: sessionapi.SessionListRequestStatus{
+					Sessions: []sessionapi.DownstreamSession{
+						{
+							ID:                   "some-request-id",
+							Username:             "some-username",
+							UpstreamUsername:     "some-upstream-username",
+							ClientID:             "pinniped-cli",
+							IdentityProviderName: "some-oidc-idp",
+							IdentityProviderType: "oidc",
+							Scopes:               []string{"openid", "offline_access"},
+							StartedAt:            metav1.NewTime(startedAt),
+							ExpiresAt:            &metav1.Time{Time: expiresAt},
+						},
+						{
+							ID:                   "other-request-id",
+							Username:             "some-username",
+							UpstreamUsername:     "some-upstream-username",
+							ClientID:             "some-client",
+							IdentityProviderName: "some-ldap-idp",
+							IdentityProviderType: "ldap",
+							Scopes:               []string{"openid"},
+							StartedAt:            metav1.NewTime(startedAt),
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "no sessions found",
+			ctx:          namespacedContext,
+			obj:          request(sessionapi.SessionSelector{}),
+			wantSelector: &sessionindex.Selector{},
+			want: &sessionapi.SessionListRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-list", Namespace: namespace, CreationTimestamp: fakeNow},
+				Status: sessionapi.SessionListRequestStatus{
+					Sessions: []sessionapi.DownstreamSession{},
+				},
+			},
+		},
+		{
+			name:         "session storage not loaded yet",
+			ctx:          namespacedContext,
+			obj:          request(sessionapi.SessionSelector{}),
+			listerErr:    sessionindex.ErrNotSynced,
+			wantSelector: &sessionindex.Selector{},
+			wantErr:      "session storage has not been loaded yet",
+			wantErrCode:  http.StatusServiceUnavailable,
+		},
+		{
+			name:         "unexpected error while listing sessions",
+			ctx:          namespacedContext,
+			obj:          request(sessionapi.SessionSelector{}),
+			listerErr:    errors.New("some index error"),
+			wantSelector: &sessionindex.Selector{},
+			wantErr:      "some index error",
+			wantErrCode:  http.StatusInternalServerError,
+		},
+		{
+			name:        "wrong type of request object provided",
+			ctx:         namespacedContext,
+			obj:         &metav1.Status{},
+			wantErr:     "not a SessionListRequest: ",
+			wantErrCode: http.StatusBadRequest,
+		},
+		{
+			name:        "incorrect namespace on request context",
+			ctx:         genericapirequest.WithNamespace(genericapirequest.NewContext(), "wrong-namespace"),
+			obj:         request(sessionapi.SessionSelector{}),
+			wantErr:     "namespace must be some-namespace on SessionListRequest, was wrong-namespace",
+			wantErrCode: http.StatusBadRequest,
+		},
+		{
+			name:        "bad options for dry run",
+			ctx:         namespacedContext,
+			obj:         request(sessionapi.SessionSelector{}),
+			options:     &metav1.CreateOptions{DryRun: []string{"stuff"}},
+			wantErr:     `SessionListRequest.session.supervisor.pinniped.dev "some-list" is invalid: dryRun: Unsupported value: []string{"stuff"}`,
+			wantErrCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "create validation fails",
+			ctx:  namespacedContext,
+			obj:  request(sessionapi.SessionSelector{}),
+			createValidation: func(ctx context.Context, obj runtime.Object) error {
+				return apierrors.NewForbidden(schema.GroupResource{}, "some-list", errors.New("some webhook error"))
+			},
+			wantErr:     `forbidden: some webhook error`,
+			wantErrCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lister := &fakeSessionLister{sessions: tt.listerSessions, err: tt.listerErr}
+			r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, lister, namespace, fakeTimeNowFunc)
+
+			got, err := r.Create(tt.ctx, tt.obj, tt.createValidation, tt.options)
+			require.Equal(t, tt.wantSelector, lister.selector)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				var statusErr *apierrors.StatusError
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, tt.wantErrCode, statusErr.ErrStatus.Code)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
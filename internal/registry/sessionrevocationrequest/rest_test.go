@@ -0,0 +1,274 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package sessionrevocationrequest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/fositestorage"
+	"go.pinniped.dev/internal/oidc/sessionindex"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+type fakeSessionLister struct {
+	sessions []sessionindex.Session
+	err      error
+}
+
+func (f *fakeSessionLister) List(_ sessionindex.Selector) ([]sessionindex.Session, error) {
+	return f.sessions, f.err
+}
+
+func TestNew(t *testing.T) {
+	r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, nil, nil, nil, "foobar", nil)
+
+	require.NotNil(t, r)
+	require.True(t, r.NamespaceScoped())
+	require.Equal(t, []string{"pinniped"}, r.Categories())
+	require.Equal(t, "sessionrevocationrequest", r.GetSingularName())
+
+	require.IsType(t, &sessionapi.SessionRevocationRequest{}, r.New())
+	require.IsType(t, &sessionapi.SessionRevocationRequestList{}, r.NewList())
+
+	ctx := context.Background()
+
+	// check the simple invariants of our no-op list
+	list, err := r.List(ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, list)
+	require.IsType(t, &sessionapi.SessionRevocationRequestList{}, list)
+	require.Equal(t, "0", list.(*sessionapi.SessionRevocationRequestList).ResourceVersion)
+	require.NotNil(t, list.(*sessionapi.SessionRevocationRequestList).Items)
+	require.Len(t, list.(*sessionapi.SessionRevocationRequestList).Items, 0)
+
+	// make sure we can turn lists into tables if needed
+	table, err := r.ConvertToTable(ctx, list, nil)
+	require.NoError(t, err)
+	require.NotNil(t, table)
+	require.Equal(t, "0", table.ResourceVersion)
+	require.Nil(t, table.Rows)
+}
+
+func TestCreate(t *testing.T) {
+	namespace := "some-namespace"
+	namespacedContext := genericapirequest.WithNamespace(
+		genericapirequest.WithRequestInfo(
+			genericapirequest.NewContext(),
+			&genericapirequest.RequestInfo{
+				APIGroup:   "session.supervisor.pinniped.dev",
+				APIVersion: "v1alpha1",
+				Resource:   "sessionrevocationrequests",
+			},
+		),
+		namespace,
+	)
+
+	fakeNow := metav1.Now()
+	fakeTimeNowFunc := func() metav1.Time { return fakeNow }
+
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	session := sessionindex.Session{
+		ID:                   "some-request-id",
+		Username:             "some-username",
+		ClientID:             "pinniped-cli",
+		IdentityProviderName: "some-ldap-idp",
+		IdentityProviderType: psession.ProviderTypeLDAP,
+		Scopes:               []string{"openid", "offline_access"},
+		StartedAt:            startedAt,
+	}
+	storageSecret := func(name, requestID string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{fositestorage.StorageRequestIDLabelName: requestID},
+			},
+		}
+	}
+
+	request := func(selector sessionapi.SessionSelector) *sessionapi.SessionRevocationRequest {
+		return &sessionapi.SessionRevocationRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-revocation"},
+			Spec:       sessionapi.SessionRevocationRequestSpec{Selector: selector},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		ctx                 context.Context
+		obj                 runtime.Object
+		createValidation    rest.ValidateObjectFunc
+		options             *metav1.CreateOptions
+		listerSessions      []sessionindex.Session
+		listerErr           error
+		failDeletes         bool
+		want                runtime.Object
+		wantErr             string
+		wantErrCode         int32
+		wantRemainingSecret []string
+	}{
+		{
+			name:                "revokes the selected sessions",
+			ctx:                 namespacedContext,
+			obj:                 request(sessionapi.SessionSelector{Username: "some-username"}),
+			listerSessions:      []sessionindex.Session{session},
+			wantRemainingSecret: []string{"other-session-storage"},
+			want: &sessionapi.SessionRevocationRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-revocation", Namespace: namespace, CreationTimestamp: fakeNow},
+				Spec: sessionapi.SessionRevocationRequestSpec{
+					Selector: sessionapi.SessionSelector{Username: "some-username"},
+				},
+				Status: sessionapi.SessionRevocationRequestStatus{
+					RevokedSessions: []sessionapi.DownstreamSession{
+						{
+							ID:                   "some-request-id",
+							Username:             "some-username",
+							ClientID:             "pinniped-cli",
+							IdentityProviderName: "some-ldap-idp",
+							IdentityProviderType: "ldap",
+							Scopes:               []string{"openid", "offline_access"},
+							StartedAt:            metav1.NewTime(startedAt),
+						},
+					},
+				},
+			},
+		},
+		{
+			name:                "no sessions selected",
+			ctx:                 namespacedContext,
+			obj:                 request(sessionapi.SessionSelector{ClientID: "some-client"}),
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+			want: &sessionapi.SessionRevocationRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-revocation", Namespace: namespace, CreationTimestamp: fakeNow},
+				Spec: sessionapi.SessionRevocationRequestSpec{
+					Selector: sessionapi.SessionSelector{ClientID: "some-client"},
+				},
+				Status: sessionapi.SessionRevocationRequestStatus{
+					RevokedSessions: []sessionapi.DownstreamSession{},
+				},
+			},
+		},
+		{
+			name:                "failure to delete the session storage",
+			ctx:                 namespacedContext,
+			obj:                 request(sessionapi.SessionSelector{Username: "some-username"}),
+			listerSessions:      []sessionindex.Session{session},
+			failDeletes:         true,
+			wantErr:             "failed to revoke 1 of 1 selected sessions",
+			wantErrCode:         http.StatusInternalServerError,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+		{
+			name:                "session storage not loaded yet",
+			ctx:                 namespacedContext,
+			obj:                 request(sessionapi.SessionSelector{Username: "some-username"}),
+			listerErr:           sessionindex.ErrNotSynced,
+			wantErr:             "session storage has not been loaded yet",
+			wantErrCode:         http.StatusServiceUnavailable,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+		{
+			name:                "empty selector",
+			ctx:                 namespacedContext,
+			obj:                 request(sessionapi.SessionSelector{}),
+			listerSessions:      []sessionindex.Session{session},
+			wantErr:             `SessionRevocationRequest.session.supervisor.pinniped.dev "some-revocation" is invalid: spec.selector: Required value: at least one of username, clientID, or identityProviderName is required`,
+			wantErrCode:         http.StatusUnprocessableEntity,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+		{
+			name:                "wrong type of request object provided",
+			ctx:                 namespacedContext,
+			obj:                 &metav1.Status{},
+			wantErr:             "not a SessionRevocationRequest: ",
+			wantErrCode:         http.StatusBadRequest,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+		{
+			name:                "incorrect namespace on request context",
+			ctx:                 genericapirequest.WithNamespace(genericapirequest.NewContext(), "wrong-namespace"),
+			obj:                 request(sessionapi.SessionSelector{Username: "some-username"}),
+			wantErr:             "namespace must be some-namespace on SessionRevocationRequest, was wrong-namespace",
+			wantErrCode:         http.StatusBadRequest,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+		{
+			name:                "bad options for dry run",
+			ctx:                 namespacedContext,
+			obj:                 request(sessionapi.SessionSelector{Username: "some-username"}),
+			options:             &metav1.CreateOptions{DryRun: []string{"stuff"}},
+			wantErr:             `SessionRevocationRequest.session.supervisor.pinniped.dev "some-revocation" is invalid: dryRun: Unsupported value: []string{"stuff"}`,
+			wantErrCode:         http.StatusUnprocessableEntity,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+		{
+			name: "create validation fails",
+			ctx:  namespacedContext,
+			obj:  request(sessionapi.SessionSelector{Username: "some-username"}),
+			createValidation: func(ctx context.Context, obj runtime.Object) error {
+				return apierrors.NewForbidden(schema.GroupResource{}, "some-revocation", errors.New("some webhook error"))
+			},
+			wantErr:             `forbidden: some webhook error`,
+			wantErrCode:         http.StatusForbidden,
+			wantRemainingSecret: []string{"access-token-storage", "refresh-token-storage", "other-session-storage"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset(
+				storageSecret("access-token-storage", "some-request-id"),
+				storageSecret("refresh-token-storage", "some-request-id"),
+				storageSecret("other-session-storage", "other-request-id"),
+			)
+			if tt.failDeletes {
+				kubeClient.PrependReactor("delete", "secrets", func(action coretesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("some delete error")
+				})
+			}
+			secrets := kubeClient.CoreV1().Secrets(namespace)
+			lister := &fakeSessionLister{sessions: tt.listerSessions, err: tt.listerErr}
+			idpLister := oidctestutil.NewUpstreamIDPListerBuilder().Build()
+			r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, lister, idpLister, secrets, namespace, fakeTimeNowFunc)
+
+			got, err := r.Create(tt.ctx, tt.obj, tt.createValidation, tt.options)
+
+			remaining, listErr := secrets.List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, listErr)
+			remainingNames := []string{}
+			for _, secret := range remaining.Items {
+				remainingNames = append(remainingNames, secret.Name)
+			}
+			require.ElementsMatch(t, tt.wantRemainingSecret, remainingNames)
+
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				var statusErr *apierrors.StatusError
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, tt.wantErrCode, statusErr.ErrStatus.Code)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package apiserver
@@ -21,8 +21,13 @@ import (
 
 	configv1alpha1clientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
 	"go.pinniped.dev/internal/controllerinit"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/oidc/sessionindex"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/registry/clientsecretrequest"
+	"go.pinniped.dev/internal/registry/ldaplogincheckrequest"
+	"go.pinniped.dev/internal/registry/sessionlistrequest"
+	"go.pinniped.dev/internal/registry/sessionrevocationrequest"
 )
 
 type Config struct {
@@ -35,8 +40,12 @@ type ExtraConfig struct {
 	Scheme                             *runtime.Scheme
 	NegotiatedSerializer               runtime.NegotiatedSerializer
 	ClientSecretSupervisorGroupVersion schema.GroupVersion
+	LoginCheckSupervisorGroupVersion   schema.GroupVersion
+	SessionSupervisorGroupVersion      schema.GroupVersion
 	Secrets                            corev1client.SecretInterface
+	Sessions                           *sessionindex.Lister
 	OIDCClients                        configv1alpha1clientset.OIDCClientInterface
+	UpstreamIDPs                       provider.DynamicUpstreamIDPProvider
 	Namespace                          string
 }
 
@@ -79,8 +88,8 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 	}
 
 	var errs []error //nolint:prealloc
-	for _, f := range []func() (schema.GroupVersionResource, rest.Storage){
-		func() (schema.GroupVersionResource, rest.Storage) {
+	for _, f := range []func() (schema.GroupVersion, map[string]rest.Storage){
+		func() (schema.GroupVersion, map[string]rest.Storage) {
 			clientSecretReqGVR := c.ExtraConfig.ClientSecretSupervisorGroupVersion.WithResource("oidcclientsecretrequests")
 			clientSecretReqStorage := clientsecretrequest.NewREST(
 				clientSecretReqGVR.GroupResource(),
@@ -92,15 +101,47 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 				bcrypt.GenerateFromPassword,
 				metav1.Now,
 			)
-			return clientSecretReqGVR, clientSecretReqStorage
+			return clientSecretReqGVR.GroupVersion(), map[string]rest.Storage{clientSecretReqGVR.Resource: clientSecretReqStorage}
+		},
+		func() (schema.GroupVersion, map[string]rest.Storage) {
+			loginCheckReqGVR := c.ExtraConfig.LoginCheckSupervisorGroupVersion.WithResource("ldaplogincheckrequests")
+			loginCheckReqStorage := ldaplogincheckrequest.NewREST(
+				loginCheckReqGVR.GroupResource(),
+				c.ExtraConfig.UpstreamIDPs,
+				c.ExtraConfig.Namespace,
+				metav1.Now,
+			)
+			return loginCheckReqGVR.GroupVersion(), map[string]rest.Storage{loginCheckReqGVR.Resource: loginCheckReqStorage}
+		},
+		func() (schema.GroupVersion, map[string]rest.Storage) {
+			sessionListReqGVR := c.ExtraConfig.SessionSupervisorGroupVersion.WithResource("sessionlistrequests")
+			sessionListReqStorage := sessionlistrequest.NewREST(
+				sessionListReqGVR.GroupResource(),
+				c.ExtraConfig.Sessions,
+				c.ExtraConfig.Namespace,
+				metav1.Now,
+			)
+			sessionRevocationReqGVR := c.ExtraConfig.SessionSupervisorGroupVersion.WithResource("sessionrevocationrequests")
+			sessionRevocationReqStorage := sessionrevocationrequest.NewREST(
+				sessionRevocationReqGVR.GroupResource(),
+				c.ExtraConfig.Sessions,
+				c.ExtraConfig.UpstreamIDPs,
+				c.ExtraConfig.Secrets,
+				c.ExtraConfig.Namespace,
+				metav1.Now,
+			)
+			return c.ExtraConfig.SessionSupervisorGroupVersion, map[string]rest.Storage{
+				sessionListReqGVR.Resource:       sessionListReqStorage,
+				sessionRevocationReqGVR.Resource: sessionRevocationReqStorage,
+			}
 		},
 	} {
-		gvr, storage := f()
+		gv, storage := f()
 		errs = append(errs,
 			s.GenericAPIServer.InstallAPIGroup(
 				&genericapiserver.APIGroupInfo{
-					PrioritizedVersions:          []schema.GroupVersion{gvr.GroupVersion()},
-					VersionedResourcesStorageMap: map[string]map[string]rest.Storage{gvr.Version: {gvr.Resource: storage}},
+					PrioritizedVersions:          []schema.GroupVersion{gv},
+					VersionedResourcesStorageMap: map[string]map[string]rest.Storage{gv.Version: storage},
 					OptionsExternalVersion:       &schema.GroupVersion{Version: "v1"},
 					Scheme:                       c.ExtraConfig.Scheme,
 					ParameterCodec:               metav1.ParameterCodec,
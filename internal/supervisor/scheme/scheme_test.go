@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package scheme
@@ -14,6 +14,10 @@ import (
 
 	clientsecretapi "go.pinniped.dev/generated/latest/apis/supervisor/clientsecret"
 	clientsecretv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/clientsecret/v1alpha1"
+	logincheckapi "go.pinniped.dev/generated/latest/apis/supervisor/logincheck"
+	logincheckv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/logincheck/v1alpha1"
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	sessionv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/session/v1alpha1"
 )
 
 func TestNew(t *testing.T) {
@@ -26,6 +30,22 @@ func TestNew(t *testing.T) {
 		Group:   "clientsecret.supervisor.pinniped.dev",
 		Version: runtime.APIVersionInternal,
 	}
+	regularLoginCheckGV := schema.GroupVersion{
+		Group:   "logincheck.supervisor.pinniped.dev",
+		Version: "v1alpha1",
+	}
+	regularLoginCheckGVInternal := schema.GroupVersion{
+		Group:   "logincheck.supervisor.pinniped.dev",
+		Version: runtime.APIVersionInternal,
+	}
+	regularSessionGV := schema.GroupVersion{
+		Group:   "session.supervisor.pinniped.dev",
+		Version: "v1alpha1",
+	}
+	regularSessionGVInternal := schema.GroupVersion{
+		Group:   "session.supervisor.pinniped.dev",
+		Version: runtime.APIVersionInternal,
+	}
 
 	// the canonical other group
 	otherClientSecretGV := schema.GroupVersion{
@@ -36,6 +56,22 @@ func TestNew(t *testing.T) {
 		Group:   "clientsecret.supervisor.walrus.tld",
 		Version: runtime.APIVersionInternal,
 	}
+	otherLoginCheckGV := schema.GroupVersion{
+		Group:   "logincheck.supervisor.walrus.tld",
+		Version: "v1alpha1",
+	}
+	otherLoginCheckGVInternal := schema.GroupVersion{
+		Group:   "logincheck.supervisor.walrus.tld",
+		Version: runtime.APIVersionInternal,
+	}
+	otherSessionGV := schema.GroupVersion{
+		Group:   "session.supervisor.walrus.tld",
+		Version: "v1alpha1",
+	}
+	otherSessionGVInternal := schema.GroupVersion{
+		Group:   "session.supervisor.walrus.tld",
+		Version: runtime.APIVersionInternal,
+	}
 
 	// kube's core internal
 	internalGV := schema.GroupVersion{
@@ -48,6 +84,8 @@ func TestNew(t *testing.T) {
 		apiGroupSuffix               string
 		want                         map[schema.GroupVersionKind]reflect.Type
 		wantClientSecretGroupVersion schema.GroupVersion
+		wantLoginCheckGroupVersion   schema.GroupVersion
+		wantSessionGroupVersion      schema.GroupVersion
 	}{
 		{
 			name:           "regular api group",
@@ -71,6 +109,42 @@ func TestNew(t *testing.T) {
 
 				regularClientSecretGVInternal.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
 
+				regularLoginCheckGV.WithKind("LDAPLoginCheckRequest"):     reflect.TypeOf(&logincheckv1alpha1.LDAPLoginCheckRequest{}).Elem(),
+				regularLoginCheckGV.WithKind("LDAPLoginCheckRequestList"): reflect.TypeOf(&logincheckv1alpha1.LDAPLoginCheckRequestList{}).Elem(),
+
+				regularLoginCheckGVInternal.WithKind("LDAPLoginCheckRequest"):     reflect.TypeOf(&logincheckapi.LDAPLoginCheckRequest{}).Elem(),
+				regularLoginCheckGVInternal.WithKind("LDAPLoginCheckRequestList"): reflect.TypeOf(&logincheckapi.LDAPLoginCheckRequestList{}).Elem(),
+
+				regularLoginCheckGV.WithKind("CreateOptions"): reflect.TypeOf(&metav1.CreateOptions{}).Elem(),
+				regularLoginCheckGV.WithKind("DeleteOptions"): reflect.TypeOf(&metav1.DeleteOptions{}).Elem(),
+				regularLoginCheckGV.WithKind("GetOptions"):    reflect.TypeOf(&metav1.GetOptions{}).Elem(),
+				regularLoginCheckGV.WithKind("ListOptions"):   reflect.TypeOf(&metav1.ListOptions{}).Elem(),
+				regularLoginCheckGV.WithKind("PatchOptions"):  reflect.TypeOf(&metav1.PatchOptions{}).Elem(),
+				regularLoginCheckGV.WithKind("UpdateOptions"): reflect.TypeOf(&metav1.UpdateOptions{}).Elem(),
+				regularLoginCheckGV.WithKind("WatchEvent"):    reflect.TypeOf(&metav1.WatchEvent{}).Elem(),
+
+				regularLoginCheckGVInternal.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
+
+				regularSessionGV.WithKind("SessionListRequest"):           reflect.TypeOf(&sessionv1alpha1.SessionListRequest{}).Elem(),
+				regularSessionGV.WithKind("SessionListRequestList"):       reflect.TypeOf(&sessionv1alpha1.SessionListRequestList{}).Elem(),
+				regularSessionGV.WithKind("SessionRevocationRequest"):     reflect.TypeOf(&sessionv1alpha1.SessionRevocationRequest{}).Elem(),
+				regularSessionGV.WithKind("SessionRevocationRequestList"): reflect.TypeOf(&sessionv1alpha1.SessionRevocationRequestList{}).Elem(),
+
+				regularSessionGVInternal.WithKind("SessionListRequest"):           reflect.TypeOf(&sessionapi.SessionListRequest{}).Elem(),
+				regularSessionGVInternal.WithKind("SessionListRequestList"):       reflect.TypeOf(&sessionapi.SessionListRequestList{}).Elem(),
+				regularSessionGVInternal.WithKind("SessionRevocationRequest"):     reflect.TypeOf(&sessionapi.SessionRevocationRequest{}).Elem(),
+				regularSessionGVInternal.WithKind("SessionRevocationRequestList"): reflect.TypeOf(&sessionapi.SessionRevocationRequestList{}).Elem(),
+
+				regularSessionGV.WithKind("CreateOptions"): reflect.TypeOf(&metav1.CreateOptions{}).Elem(),
+				regularSessionGV.WithKind("DeleteOptions"): reflect.TypeOf(&metav1.DeleteOptions{}).Elem(),
+				regularSessionGV.WithKind("GetOptions"):    reflect.TypeOf(&metav1.GetOptions{}).Elem(),
+				regularSessionGV.WithKind("ListOptions"):   reflect.TypeOf(&metav1.ListOptions{}).Elem(),
+				regularSessionGV.WithKind("PatchOptions"):  reflect.TypeOf(&metav1.PatchOptions{}).Elem(),
+				regularSessionGV.WithKind("UpdateOptions"): reflect.TypeOf(&metav1.UpdateOptions{}).Elem(),
+				regularSessionGV.WithKind("WatchEvent"):    reflect.TypeOf(&metav1.WatchEvent{}).Elem(),
+
+				regularSessionGVInternal.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
+
 				// the types below this line do not really matter to us because they are in the core group
 
 				internalGV.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
@@ -89,6 +163,8 @@ func TestNew(t *testing.T) {
 				metav1.Unversioned.WithKind("WatchEvent"):      reflect.TypeOf(&metav1.WatchEvent{}).Elem(),
 			},
 			wantClientSecretGroupVersion: regularClientSecretGV,
+			wantLoginCheckGroupVersion:   regularLoginCheckGV,
+			wantSessionGroupVersion:      regularSessionGV,
 		},
 		{
 			name:           "other api group",
@@ -112,6 +188,42 @@ func TestNew(t *testing.T) {
 
 				otherClientSecretGVInternal.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
 
+				otherLoginCheckGV.WithKind("LDAPLoginCheckRequest"):     reflect.TypeOf(&logincheckv1alpha1.LDAPLoginCheckRequest{}).Elem(),
+				otherLoginCheckGV.WithKind("LDAPLoginCheckRequestList"): reflect.TypeOf(&logincheckv1alpha1.LDAPLoginCheckRequestList{}).Elem(),
+
+				otherLoginCheckGVInternal.WithKind("LDAPLoginCheckRequest"):     reflect.TypeOf(&logincheckapi.LDAPLoginCheckRequest{}).Elem(),
+				otherLoginCheckGVInternal.WithKind("LDAPLoginCheckRequestList"): reflect.TypeOf(&logincheckapi.LDAPLoginCheckRequestList{}).Elem(),
+
+				otherLoginCheckGV.WithKind("CreateOptions"): reflect.TypeOf(&metav1.CreateOptions{}).Elem(),
+				otherLoginCheckGV.WithKind("DeleteOptions"): reflect.TypeOf(&metav1.DeleteOptions{}).Elem(),
+				otherLoginCheckGV.WithKind("GetOptions"):    reflect.TypeOf(&metav1.GetOptions{}).Elem(),
+				otherLoginCheckGV.WithKind("ListOptions"):   reflect.TypeOf(&metav1.ListOptions{}).Elem(),
+				otherLoginCheckGV.WithKind("PatchOptions"):  reflect.TypeOf(&metav1.PatchOptions{}).Elem(),
+				otherLoginCheckGV.WithKind("UpdateOptions"): reflect.TypeOf(&metav1.UpdateOptions{}).Elem(),
+				otherLoginCheckGV.WithKind("WatchEvent"):    reflect.TypeOf(&metav1.WatchEvent{}).Elem(),
+
+				otherLoginCheckGVInternal.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
+
+				otherSessionGV.WithKind("SessionListRequest"):           reflect.TypeOf(&sessionv1alpha1.SessionListRequest{}).Elem(),
+				otherSessionGV.WithKind("SessionListRequestList"):       reflect.TypeOf(&sessionv1alpha1.SessionListRequestList{}).Elem(),
+				otherSessionGV.WithKind("SessionRevocationRequest"):     reflect.TypeOf(&sessionv1alpha1.SessionRevocationRequest{}).Elem(),
+				otherSessionGV.WithKind("SessionRevocationRequestList"): reflect.TypeOf(&sessionv1alpha1.SessionRevocationRequestList{}).Elem(),
+
+				otherSessionGVInternal.WithKind("SessionListRequest"):           reflect.TypeOf(&sessionapi.SessionListRequest{}).Elem(),
+				otherSessionGVInternal.WithKind("SessionListRequestList"):       reflect.TypeOf(&sessionapi.SessionListRequestList{}).Elem(),
+				otherSessionGVInternal.WithKind("SessionRevocationRequest"):     reflect.TypeOf(&sessionapi.SessionRevocationRequest{}).Elem(),
+				otherSessionGVInternal.WithKind("SessionRevocationRequestList"): reflect.TypeOf(&sessionapi.SessionRevocationRequestList{}).Elem(),
+
+				otherSessionGV.WithKind("CreateOptions"): reflect.TypeOf(&metav1.CreateOptions{}).Elem(),
+				otherSessionGV.WithKind("DeleteOptions"): reflect.TypeOf(&metav1.DeleteOptions{}).Elem(),
+				otherSessionGV.WithKind("GetOptions"):    reflect.TypeOf(&metav1.GetOptions{}).Elem(),
+				otherSessionGV.WithKind("ListOptions"):   reflect.TypeOf(&metav1.ListOptions{}).Elem(),
+				otherSessionGV.WithKind("PatchOptions"):  reflect.TypeOf(&metav1.PatchOptions{}).Elem(),
+				otherSessionGV.WithKind("UpdateOptions"): reflect.TypeOf(&metav1.UpdateOptions{}).Elem(),
+				otherSessionGV.WithKind("WatchEvent"):    reflect.TypeOf(&metav1.WatchEvent{}).Elem(),
+
+				otherSessionGVInternal.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
+
 				// the types below this line do not really matter to us because they are in the core group
 
 				internalGV.WithKind("WatchEvent"): reflect.TypeOf(&metav1.InternalEvent{}).Elem(),
@@ -130,14 +242,18 @@ func TestNew(t *testing.T) {
 				metav1.Unversioned.WithKind("WatchEvent"):      reflect.TypeOf(&metav1.WatchEvent{}).Elem(),
 			},
 			wantClientSecretGroupVersion: otherClientSecretGV,
+			wantLoginCheckGroupVersion:   otherLoginCheckGV,
+			wantSessionGroupVersion:      otherSessionGV,
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			scheme, clientSecretGV := New(tt.apiGroupSuffix)
+			scheme, clientSecretGV, loginCheckGV, sessionGV := New(tt.apiGroupSuffix)
 			require.Equal(t, tt.want, scheme.AllKnownTypes())
 			require.Equal(t, tt.wantClientSecretGroupVersion, clientSecretGV)
+			require.Equal(t, tt.wantLoginCheckGroupVersion, loginCheckGV)
+			require.Equal(t, tt.wantSessionGroupVersion, sessionGV)
 		})
 	}
 }
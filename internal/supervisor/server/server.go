@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package server defines the entrypoint for the Pinniped Supervisor server.
@@ -8,11 +8,14 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,16 +28,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/wait"
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	genericapifilters "k8s.io/apiserver/pkg/endpoints/filters"
 	openapinamer "k8s.io/apiserver/pkg/endpoints/openapi"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
 	genericoptions "k8s.io/apiserver/pkg/server/options"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
 	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
 	"k8s.io/utils/clock"
 
@@ -43,42 +50,71 @@ import (
 	"go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
 	supervisoropenapi "go.pinniped.dev/generated/latest/client/supervisor/openapi"
+	"go.pinniped.dev/internal/acmecert"
 	"go.pinniped.dev/internal/apiserviceref"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/config/supervisor"
+	"go.pinniped.dev/internal/constable"
 	"go.pinniped.dev/internal/controller/apicerts"
 	"go.pinniped.dev/internal/controller/supervisorconfig"
 	"go.pinniped.dev/internal/controller/supervisorconfig/activedirectoryupstreamwatcher"
 	"go.pinniped.dev/internal/controller/supervisorconfig/generator"
+	"go.pinniped.dev/internal/controller/supervisorconfig/githubupstreamwatcher"
+	"go.pinniped.dev/internal/controller/supervisorconfig/gitlabupstreamwatcher"
+	"go.pinniped.dev/internal/controller/supervisorconfig/kerberosupstreamwatcher"
 	"go.pinniped.dev/internal/controller/supervisorconfig/ldapupstreamwatcher"
+	"go.pinniped.dev/internal/controller/supervisorconfig/oauth2upstreamwatcher"
 	"go.pinniped.dev/internal/controller/supervisorconfig/oidcclientwatcher"
 	"go.pinniped.dev/internal/controller/supervisorconfig/oidcupstreamwatcher"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
 	"go.pinniped.dev/internal/controller/supervisorstorage"
 	"go.pinniped.dev/internal/controllerinit"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/debugserver"
 	"go.pinniped.dev/internal/deploymentref"
 	"go.pinniped.dev/internal/downward"
 	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/healthcheck"
+	"go.pinniped.dev/internal/httputil/clientip"
+	"go.pinniped.dev/internal/httputil/httpsredirect"
+	"go.pinniped.dev/internal/httputil/securityheader"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/leaderelection"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/backchannellogout"
+	"go.pinniped.dev/internal/oidc/branding"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/oidc/i18n"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/oidc/provider/manager"
+	"go.pinniped.dev/internal/oidc/sessionindex"
+	"go.pinniped.dev/internal/oidcclientsecretstorage"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/ratelimit"
 	"go.pinniped.dev/internal/secret"
 	"go.pinniped.dev/internal/supervisor/apiserver"
 	supervisorscheme "go.pinniped.dev/internal/supervisor/scheme"
+	"go.pinniped.dev/internal/tracing"
+	"go.pinniped.dev/internal/upstreamldap"
+	"go.pinniped.dev/internal/upstreamoidc"
 )
 
 const (
 	singletonWorker       = 1
 	defaultResyncInterval = 3 * time.Minute
+
+	// Kubernetes updates the config file about a minute after its ConfigMap changed.
+	tlsPolicyReloadInterval = time.Minute
 )
 
 func startServer(ctx context.Context, shutdown *sync.WaitGroup, l net.Listener, handler http.Handler) {
 	handler = genericapifilters.WithWarningRecorder(handler)
-	handler = withBootstrapPaths(handler, "/healthz") // only health checks are allowed for bootstrap connections
+	handler = withBootstrapPaths(handler, "/healthz", "/livez", "/readyz") // only health checks are allowed for bootstrap connections
 
 	server := http.Server{
 		Handler:           handler,
@@ -126,6 +162,30 @@ func signalCtx() context.Context {
 	return ctx
 }
 
+// newValidatedSettingsCache returns the cache of validated upstream settings for the LDAP or Active Directory
+// watcher, or nil to use an in-memory cache when persistence is not configured. Each watcher persists its cache
+// in a separate object.
+func newValidatedSettingsCache(
+	cfg *supervisor.Config,
+	kubeClient kubernetes.Interface,
+	namespace string,
+	nameSuffix string,
+) upstreamwatchers.ValidatedSettingsCacheI {
+	name := cfg.NamesConfig.ValidatedSettingsCache + "-" + nameSuffix
+	switch cfg.ValidatedSettingsCache.Persistence {
+	case supervisor.ValidatedSettingsCachePersistenceConfigMap:
+		return upstreamwatchers.NewPersistedValidatedSettingsCache(
+			upstreamwatchers.NewConfigMapValidatedSettingsStore(kubeClient.CoreV1().ConfigMaps(namespace), name, cfg.Labels),
+		)
+	case supervisor.ValidatedSettingsCachePersistenceSecret:
+		return upstreamwatchers.NewPersistedValidatedSettingsCache(
+			upstreamwatchers.NewSecretValidatedSettingsStore(kubeClient.CoreV1().Secrets(namespace), name, cfg.Labels),
+		)
+	default:
+		return nil
+	}
+}
+
 //nolint:funlen
 func prepareControllers(
 	cfg *supervisor.Config,
@@ -133,6 +193,7 @@ func prepareControllers(
 	dynamicJWKSProvider jwks.DynamicJWKSProvider,
 	dynamicTLSCertProvider provider.DynamicTLSCertProvider,
 	dynamicUpstreamIDPProvider provider.DynamicUpstreamIDPProvider,
+	logoutNotifier backchannellogout.NotifierI,
 	dynamicServingCertProvider dynamiccert.Private,
 	secretCache *secret.Cache,
 	supervisorDeployment *appsv1.Deployment,
@@ -142,13 +203,19 @@ func prepareControllers(
 	kubeInformers kubeinformers.SharedInformerFactory,
 	pinnipedInformers pinnipedinformers.SharedInformerFactory,
 	leaderElector controllerinit.RunnerWrapper,
+	isLeader func() bool,
 	podInfo *downward.PodInfo,
+	eventRecorder events.EventRecorder,
+	brandingCache *branding.Cache,
+	translations *i18n.Catalogs,
+	acmeIssuer *acmecert.Issuer,
 ) controllerinit.RunnerBuilder {
 	const certificateName string = "pinniped-supervisor-api-tls-serving-certificate"
-	clientSecretSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
+	clientSecretSupervisorGroupData, loginCheckSupervisorGroupData, sessionSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
 	federationDomainInformer := pinnipedInformers.Config().V1alpha1().FederationDomains()
 	oidcClientInformer := pinnipedInformers.Config().V1alpha1().OIDCClients()
 	secretInformer := kubeInformers.Core().V1().Secrets()
+	oidcDiscoveryCache := oidcupstreamwatcher.NewDiscoveryCache()
 
 	// Create controller manager.
 	controllerManager := controllerlib.
@@ -156,7 +223,10 @@ func prepareControllers(
 		WithController(
 			supervisorstorage.GarbageCollectorController(
 				dynamicUpstreamIDPProvider,
+				logoutNotifier,
 				clock.RealClock{},
+				time.Duration(*cfg.StorageGarbageCollection.IntervalSeconds)*time.Second,
+				int(*cfg.StorageGarbageCollection.BatchSize),
 				kubeClient,
 				secretInformer,
 				controllerlib.WithInformer,
@@ -166,9 +236,11 @@ func prepareControllers(
 		WithController(
 			supervisorconfig.NewFederationDomainWatcherController(
 				issuerManager,
+				*cfg.APIGroupSuffix,
 				clock.RealClock{},
 				pinnipedClient,
 				federationDomainInformer,
+				pinnipedInformers.IDP().V1alpha1(),
 				controllerlib.WithInformer,
 			),
 			singletonWorker,
@@ -176,6 +248,7 @@ func prepareControllers(
 		WithController(
 			supervisorconfig.NewJWKSWriterController(
 				cfg.Labels,
+				clock.RealClock{},
 				kubeClient,
 				pinnipedClient,
 				secretInformer,
@@ -293,16 +366,75 @@ func prepareControllers(
 				pinnipedClient,
 				pinnipedInformers.IDP().V1alpha1().OIDCIdentityProviders(),
 				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				oidcDiscoveryCache,
 				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
 				controllerlib.WithInformer,
 			),
 			singletonWorker).
+		WithController(
+			oidcupstreamwatcher.NewDiscoveryRefresher(
+				oidcDiscoveryCache,
+				pinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().OIDCIdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.New(),
+				controllerlib.WithInformer,
+			),
+			singletonWorker).
+		WithController(
+			oauth2upstreamwatcher.New(
+				dynamicUpstreamIDPProvider,
+				pinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().OAuth2IdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // shares its status logging with the OIDC watcher
+				controllerlib.WithInformer,
+			),
+			singletonWorker).
+		WithController(
+			githubupstreamwatcher.New(
+				dynamicUpstreamIDPProvider,
+				pinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().GitHubIdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // shares its status logging with the OIDC watcher
+				controllerlib.WithInformer,
+			),
+			singletonWorker).
+		WithController(
+			gitlabupstreamwatcher.New(
+				dynamicUpstreamIDPProvider,
+				pinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().GitLabIdentityProviders(),
+				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				plog.Logr(), //nolint:staticcheck  // shares its status logging with the OIDC watcher
+				controllerlib.WithInformer,
+			),
+			singletonWorker).
+		WithController(
+			kerberosupstreamwatcher.New(
+				dynamicUpstreamIDPProvider,
+				pinnipedClient,
+				pinnipedInformers.IDP().V1alpha1().KerberosIdentityProviders(),
+				secretInformer,
+				plog.Logr(), //nolint:staticcheck  // shares its status logging with the OIDC watcher
+				controllerlib.WithInformer,
+			),
+			singletonWorker).
 		WithController(
 			ldapupstreamwatcher.New(
 				dynamicUpstreamIDPProvider,
 				pinnipedClient,
 				pinnipedInformers.IDP().V1alpha1().LDAPIdentityProviders(),
 				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
+				newValidatedSettingsCache(cfg, kubeClient, podInfo.Namespace, "ldap"),
 				controllerlib.WithInformer,
 			),
 			singletonWorker).
@@ -312,6 +444,9 @@ func prepareControllers(
 				pinnipedClient,
 				pinnipedInformers.IDP().V1alpha1().ActiveDirectoryIdentityProviders(),
 				secretInformer,
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
+				newValidatedSettingsCache(cfg, kubeClient, podInfo.Namespace, "activedirectory"),
 				controllerlib.WithInformer,
 			),
 			singletonWorker).
@@ -341,6 +476,28 @@ func prepareControllers(
 			),
 			singletonWorker,
 		).
+		WithController(
+			apicerts.NewAPIServiceUpdaterController(
+				podInfo.Namespace,
+				certificateName,
+				loginCheckSupervisorGroupData.APIServiceName(),
+				aggregatorClient,
+				secretInformer,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
+		WithController(
+			apicerts.NewAPIServiceUpdaterController(
+				podInfo.Namespace,
+				certificateName,
+				sessionSupervisorGroupData.APIServiceName(),
+				aggregatorClient,
+				secretInformer,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
 		WithController(
 			apicerts.NewCertsObserverController(
 				podInfo.Namespace,
@@ -374,13 +531,189 @@ func prepareControllers(
 			singletonWorker,
 		)
 
+	if cfg.LoginPageBranding.ConfigMapName != "" {
+		controllerManager.WithController(
+			supervisorconfig.NewLoginPageBrandingObserverController(
+				brandingCache,
+				cfg.LoginPageBranding.ConfigMapName,
+				podInfo.Namespace,
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
+	if cfg.ACME.DirectoryURL != "" {
+		controllerManager.WithController(
+			supervisorconfig.NewACMECertificateController(
+				acmeIssuer,
+				isLeader,
+				cfg.NamesConfig.ACMEAccount,
+				time.Duration(*cfg.ACME.RenewBeforeDays)*24*time.Hour,
+				cfg.Labels,
+				clock.RealClock{},
+				kubeClient,
+				secretInformer,
+				federationDomainInformer,
+				eventRecorder,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
+	if cfg.Translations.ConfigMapName != "" {
+		controllerManager.WithController(
+			supervisorconfig.NewTranslationsObserverController(
+				translations,
+				cfg.Translations.ConfigMapName,
+				podInfo.Namespace,
+				kubeInformers.Core().V1().ConfigMaps(),
+				eventRecorder,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
 	return controllerinit.Prepare(controllerManager.Start, leaderElector, kubeInformers, pinnipedInformers)
 }
 
+// setAuditSinks configures the destinations of the security audit log. The webhook sinks stop when ctx is cancelled.
+func setAuditSinks(ctx context.Context, spec supervisor.AuditSpec) error {
+	sinks := make([]auditlog.Sink, 0, len(spec.Sinks))
+	for _, sinkSpec := range spec.Sinks {
+		switch sinkSpec.Type {
+		case supervisor.AuditSinkTypeStdout:
+			sinks = append(sinks, auditlog.NewWriterSink(os.Stdout))
+		case supervisor.AuditSinkTypeFile:
+			sink, err := auditlog.NewFileSink(sinkSpec.File.Path, *sinkSpec.File.MaxSizeMegabytes*1024*1024, int(*sinkSpec.File.MaxBackups))
+			if err != nil {
+				return err
+			}
+			sinks = append(sinks, sink)
+		case supervisor.AuditSinkTypeWebhook:
+			var rootCAs *x509.CertPool
+			if sinkSpec.Webhook.CertificateAuthorityData != "" {
+				// The config was already validated, so this is valid base64.
+				caBundle, _ := base64.StdEncoding.DecodeString(sinkSpec.Webhook.CertificateAuthorityData)
+				rootCAs = x509.NewCertPool()
+				if !rootCAs.AppendCertsFromPEM(caBundle) {
+					return fmt.Errorf("certificateAuthorityData of audit webhook %q does not contain any certificates", sinkSpec.Webhook.URL)
+				}
+			}
+			sinks = append(sinks, auditlog.NewWebhookSink(ctx, sinkSpec.Webhook.URL, phttp.Default(rootCAs)))
+		}
+	}
+	auditlog.SetSinks(sinks...)
+	return nil
+}
+
+// newACMEIssuer returns the issuer of the TLS certificates of the FederationDomains, or nil when ACME is disabled.
+// The HTTP-01 challenges are written to a Secret with the kubeClient, and served by every pod from the secretLister.
+func newACMEIssuer(
+	cfg *supervisor.Config,
+	kubeClient kubernetes.Interface,
+	secretLister corev1listers.SecretNamespaceLister,
+	namespace string,
+) (*acmecert.Issuer, error) {
+	spec := cfg.ACME
+	if spec.DirectoryURL == "" {
+		return nil, nil
+	}
+
+	var solver acmecert.ChallengeSolver
+	if spec.HTTP01 != nil {
+		solver = acmecert.NewHTTP01Solver(kubeClient.CoreV1().Secrets(namespace), secretLister, cfg.NamesConfig.ACMEHTTP01Challenges, cfg.Labels)
+	} else {
+		rootCAs, err := certPoolFromCertificateAuthorityData(spec.DNS01.Webhook.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("certificateAuthorityData of ACME DNS-01 webhook %q %w", spec.DNS01.Webhook.URL, err)
+		}
+		propagationDelay := time.Duration(*spec.DNS01.PropagationSeconds) * time.Second
+		solver = acmecert.NewDNS01WebhookSolver(spec.DNS01.Webhook.URL, phttp.Default(rootCAs), propagationDelay)
+	}
+
+	rootCAs, err := certPoolFromCertificateAuthorityData(spec.CertificateAuthorityData)
+	if err != nil {
+		return nil, fmt.Errorf("certificateAuthorityData of ACME server %q %w", spec.DirectoryURL, err)
+	}
+	return acmecert.NewIssuer(spec.DirectoryURL, spec.Email, solver, phttp.Default(rootCAs)), nil
+}
+
+// certPoolFromCertificateAuthorityData returns the CAs of the base64-encoded PEM bundle, or nil to use the system's
+// trusted CAs when the bundle is empty.
+func certPoolFromCertificateAuthorityData(certificateAuthorityData string) (*x509.CertPool, error) {
+	if certificateAuthorityData == "" {
+		return nil, nil
+	}
+	// The config was already validated, so this is valid base64.
+	caBundle, _ := base64.StdEncoding.DecodeString(certificateAuthorityData)
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, constable.Error("does not contain any certificates")
+	}
+	return rootCAs, nil
+}
+
+// endpointRateLimitConfig returns the rate limits of the password and token endpoints. Limits which are not
+// configured are zero, which means no limit.
+func endpointRateLimitConfig(spec supervisor.EndpointRateLimitsSpec) oidc.EndpointRateLimitConfig {
+	limit := func(limitSpec *supervisor.RateLimitSpec) ratelimit.Limit {
+		if limitSpec == nil {
+			return ratelimit.Limit{}
+		}
+		return ratelimit.Limit{
+			AttemptsPerMinute: int(limitSpec.AttemptsPerMinute),
+			Burst:             int(limitSpec.Burst),
+		}
+	}
+	return oidc.EndpointRateLimitConfig{
+		PerClientIP: limit(spec.PerClientIP),
+		PerUsername: limit(spec.PerUsername),
+	}
+}
+
+// trustedProxyCIDRs returns the networks of the proxies which are trusted to pass on the addresses of the clients.
+// They were already validated when the config was loaded.
+func trustedProxyCIDRs(spec supervisor.ClientIPSpec) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(spec.TrustedProxyCIDRs))
+	for _, cidr := range spec.TrustedProxyCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
 //nolint:funlen
 func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervisor.Config) error {
 	serverInstallationNamespace := podInfo.Namespace
-	clientSecretSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
+
+	var tracingSamplingRatePerMillion int32
+	if cfg.Tracing.SamplingRatePerMillion != nil {
+		tracingSamplingRatePerMillion = *cfg.Tracing.SamplingRatePerMillion
+	}
+	shutdownTracing, err := tracing.Setup(ctx, "pinniped-supervisor", cfg.Tracing.Endpoint, cfg.Tracing.Insecure, tracingSamplingRatePerMillion)
+	if err != nil {
+		return fmt.Errorf("cannot set up tracing: %w", err)
+	}
+	defer func() {
+		// The context of the server has already been cancelled by now, so allow a little time to flush the spans.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			plog.Error("could not flush traces", err)
+		}
+	}()
+
+	if err := setAuditSinks(ctx, cfg.Audit); err != nil {
+		return fmt.Errorf("cannot set up audit log: %w", err)
+	}
+
+	clientSecretSupervisorGroupData, _, _ := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
 
 	apiServiceRef, err := apiserviceref.New(clientSecretSupervisorGroupData.APIServiceName())
 	if err != nil {
@@ -398,7 +731,7 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		kubeclient.WithMiddleware(groupsuffix.New(*cfg.APIGroupSuffix)),
 	}
 
-	client, leaderElector, err := leaderelection.New(
+	client, leaderElector, isLeader, leaderElectionCheck, err := leaderelection.New(
 		podInfo,
 		supervisorDeployment,
 		opts...,
@@ -424,18 +757,51 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		pinnipedinformers.WithNamespace(serverInstallationNamespace),
 	)
 
-	// Serve the /healthz endpoint and make all other paths result in 404.
-	healthMux := http.NewServeMux()
-	healthMux.Handle("/healthz", http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		_, _ = writer.Write([]byte("ok"))
-	}))
+	// Record Events about the Supervisor's custom resources, e.g. when the conditions of an identity provider change.
+	eventBroadcaster := events.NewEventBroadcasterAdapter(client.Kubernetes)
+	eventBroadcaster.StartRecordingToSink(ctx.Done())
+	defer eventBroadcaster.Shutdown()
 
 	dynamicServingCertProvider := dynamiccert.NewServingCert("supervisor-serving-cert")
 
+	// Serve the /healthz, /livez, and /readyz endpoints and make all other paths result in 404.
+	// The liveness checks only fail when the process is stuck, while the readiness checks also
+	// fail when the Supervisor cannot do its job. Add ?verbose to see the result of each check.
+	healthMux := http.NewServeMux()
+	healthz.InstallHandler(healthMux, healthz.PingHealthz)
+	healthz.InstallLivezHandler(healthMux, healthz.PingHealthz)
+	healthz.InstallReadyzHandler(healthMux,
+		healthz.PingHealthz,
+		healthcheck.InformerSync("informer-sync", kubeInformers, pinnipedInformers),
+		leaderElectionCheck,
+		healthcheck.ServingCertificate(dynamicServingCertProvider, clock.RealClock{}),
+		healthcheck.Storage(clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace)),
+	)
+
 	dynamicJWKSProvider := jwks.NewDynamicJWKSProvider()
 	dynamicTLSCertProvider := provider.NewDynamicTLSCertProvider()
 	dynamicUpstreamIDPProvider := provider.NewDynamicUpstreamIDPProvider()
 	secretCache := secret.Cache{}
+	brandingCache := branding.NewCache()
+	translations := i18n.NewCatalogs()
+
+	// Only the leader obtains the certificates, but every pod serves the HTTP-01 challenges from the Secret informer.
+	acmeChallengeSecretLister := kubeInformers.Core().V1().Secrets().Lister().Secrets(serverInstallationNamespace)
+	acmeIssuer, err := newACMEIssuer(cfg, client.Kubernetes, acmeChallengeSecretLister, serverInstallationNamespace)
+	if err != nil {
+		return fmt.Errorf("cannot set up ACME: %w", err)
+	}
+
+	// Logout tokens will be sent to the back-channel logout URIs of OIDCClients when their downstream sessions end.
+	logoutNotifier := backchannellogout.New(
+		dynamicJWKSProvider,
+		clientregistry.NewClientManager(
+			client.PinnipedSupervisor.ConfigV1alpha1().OIDCClients(serverInstallationNamespace),
+			oidcclientsecretstorage.New(client.Kubernetes.CoreV1().Secrets(serverInstallationNamespace)),
+			oidcclientvalidator.DefaultMinBcryptCost,
+		),
+		phttp.Default(nil),
+	)
 
 	// OIDC endpoints will be served by the oidProvidersManager, and any non-OIDC paths will fallback to the healthMux.
 	oidProvidersManager := manager.NewManager(
@@ -445,11 +811,22 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		&secretCache,
 		clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace), // writes to kube storage are allowed for non-leaders
 		client.PinnipedSupervisor.ConfigV1alpha1().OIDCClients(serverInstallationNamespace),
+		logoutNotifier,
+		endpointRateLimitConfig(cfg.EndpointRateLimits),
+		brandingCache,
+		translations,
 	)
 
-	// Get the "real" name of the client secret supervisor API group (i.e., the API group name with the
-	// injected suffix).
-	scheme, clientSecretGV := supervisorscheme.New(*cfg.APIGroupSuffix)
+	// Get the "real" names of the client secret, login check, and session supervisor API groups (i.e., the API group
+	// names with the injected suffix).
+	scheme, clientSecretGV, loginCheckGV, sessionGV := supervisorscheme.New(*cfg.APIGroupSuffix)
+
+	// The session APIs find the downstream sessions using indexes of the Secret informer, which must be added
+	// before the informer starts. When they could not be added, the session APIs fall back to looking at every Secret.
+	secretInformer := kubeInformers.Core().V1().Secrets().Informer()
+	if err := sessionindex.AddIndexers(secretInformer); err != nil {
+		plog.Error("could not add session indexes to Secret informer", err)
+	}
 
 	buildControllersFunc := prepareControllers(
 		cfg,
@@ -457,6 +834,7 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		dynamicJWKSProvider,
 		dynamicTLSCertProvider,
 		dynamicUpstreamIDPProvider,
+		logoutNotifier,
 		dynamicServingCertProvider,
 		&secretCache,
 		supervisorDeployment,
@@ -466,11 +844,21 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		kubeInformers,
 		pinnipedInformers,
 		leaderElector,
+		isLeader,
 		podInfo,
+		eventBroadcaster.NewRecorder("pinniped-supervisor"),
+		brandingCache,
+		translations,
+		acmeIssuer,
 	)
 
 	shutdown := &sync.WaitGroup{}
 
+	// Serve the metrics of the upstream LDAP operations and of the retried upstream OIDC requests on the /metrics
+	// endpoint of the aggregated API server.
+	upstreamldap.RegisterMetrics()
+	upstreamoidc.RegisterMetrics()
+
 	// Get the aggregated API server config.
 	aggregatedAPIServerConfig, err := getAggregatedAPIServerConfig(
 		dynamicServingCertProvider,
@@ -479,8 +867,12 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		*cfg.AggregatedAPIServerPort,
 		scheme,
 		clientSecretGV,
+		loginCheckGV,
+		sessionGV,
 		clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace),
+		sessionindex.NewLister(secretInformer),
 		client.PinnipedSupervisor.ConfigV1alpha1().OIDCClients(serverInstallationNamespace),
+		dynamicUpstreamIDPProvider,
 		serverInstallationNamespace,
 	)
 	if err != nil {
@@ -493,6 +885,13 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		return fmt.Errorf("could not create aggregated API server: %w", err)
 	}
 
+	// Every response of the Supervisor's endpoints gets the same security headers. The addresses of the clients behind
+	// trusted proxies are learned first, for the rate limits per client IP address and the audit log.
+	supervisorHandler := securityheader.WrapWithOptions(
+		clientip.Wrap(oidProvidersManager, trustedProxyCIDRs(cfg.ClientIP), cfg.ClientIP.Header),
+		securityHeaderOptions(cfg.HTTPHardening),
+	)
+
 	if e := cfg.Endpoints.HTTP; e.Network != supervisor.NetworkDisabled {
 		finishSetupPerms := maybeSetupUnixPerms(e, supervisorPod)
 
@@ -506,8 +905,26 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		}
 
 		defer func() { _ = httpListener.Close() }()
-		startServer(ctx, shutdown, httpListener, oidProvidersManager)
-		plog.Debug("supervisor http listener started", "address", httpListener.Addr().String())
+		httpHandler := supervisorHandler
+		if cfg.HTTPHardening.RedirectHTTPToHTTPS {
+			httpHandler = httpsredirect.New()
+		}
+		startServer(ctx, shutdown, httpListener, httpHandler)
+		plog.Debug("supervisor http listener started",
+			"address", httpListener.Addr().String(),
+			"redirectToHTTPS", cfg.HTTPHardening.RedirectHTTPToHTTPS,
+		)
+	}
+
+	if cfg.ACME.DirectoryURL != "" && cfg.ACME.HTTP01 != nil {
+		acmeHTTP01Listener, err := net.Listen(supervisor.NetworkTCP, cfg.ACME.HTTP01.Address)
+		if err != nil {
+			return fmt.Errorf("cannot create ACME HTTP-01 listener with address %q: %w", cfg.ACME.HTTP01.Address, err)
+		}
+
+		defer func() { _ = acmeHTTP01Listener.Close() }()
+		startServer(ctx, shutdown, acmeHTTP01Listener, acmecert.NewHTTP01Handler(acmeChallengeSecretLister, cfg.NamesConfig.ACMEHTTP01Challenges))
+		plog.Debug("supervisor ACME HTTP-01 listener started", "address", acmeHTTP01Listener.Addr().String())
 	}
 
 	if e := cfg.Endpoints.HTTPS; e.Network != supervisor.NetworkDisabled { //nolint:nestif
@@ -547,6 +964,12 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 
 			return cert, nil
 		}
+		// Build the config of each connection from the current TLS policy, so that changes to it apply without a restart.
+		c.GetConfigForClient = func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+			connConfig := ptls.Default(nil)
+			connConfig.GetCertificate = c.GetCertificate
+			return connConfig, nil
+		}
 
 		httpsListener, err := tls.Listen(e.Network, e.Address, c)
 		if err != nil {
@@ -558,7 +981,7 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		}
 
 		defer func() { _ = httpsListener.Close() }()
-		startServer(ctx, shutdown, httpsListener, oidProvidersManager)
+		startServer(ctx, shutdown, httpsListener, supervisorHandler)
 		plog.Debug("supervisor https listener started", "address", httpsListener.Addr().String())
 	}
 
@@ -582,8 +1005,12 @@ func getAggregatedAPIServerConfig(
 	aggregatedAPIServerPort int64,
 	scheme *runtime.Scheme,
 	clientSecretSupervisorGroupVersion schema.GroupVersion,
+	loginCheckSupervisorGroupVersion schema.GroupVersion,
+	sessionSupervisorGroupVersion schema.GroupVersion,
 	secrets corev1client.SecretInterface,
+	sessions *sessionindex.Lister,
 	oidcClients v1alpha1.OIDCClientInterface,
+	upstreamIDPs provider.DynamicUpstreamIDPProvider,
 	serverInstallationNamespace string,
 ) (*apiserver.Config, error) {
 	codecs := serializer.NewCodecFactory(scheme)
@@ -593,7 +1020,7 @@ func getAggregatedAPIServerConfig(
 
 	recommendedOptions := genericoptions.NewRecommendedOptions(
 		defaultEtcdPathPrefix,
-		codecs.LegacyCodec(clientSecretSupervisorGroupVersion),
+		codecs.LegacyCodec(clientSecretSupervisorGroupVersion, loginCheckSupervisorGroupVersion, sessionSupervisorGroupVersion),
 	)
 	recommendedOptions.Etcd = nil // turn off etcd storage because we don't need it yet
 	recommendedOptions.SecureServing.ServerCert.GeneratedCert = dynamicCertProvider
@@ -633,8 +1060,12 @@ func getAggregatedAPIServerConfig(
 			Scheme:                             scheme,
 			NegotiatedSerializer:               codecs,
 			ClientSecretSupervisorGroupVersion: clientSecretSupervisorGroupVersion,
+			LoginCheckSupervisorGroupVersion:   loginCheckSupervisorGroupVersion,
+			SessionSupervisorGroupVersion:      sessionSupervisorGroupVersion,
 			Secrets:                            secrets,
+			Sessions:                           sessions,
 			OIDCClients:                        oidcClients,
+			UpstreamIDPs:                       upstreamIDPs,
 			Namespace:                          serverInstallationNamespace,
 		},
 	}
@@ -695,9 +1126,62 @@ func main() error { // return an error instead of plog.Fatal to allow defer stat
 		return fmt.Errorf("could not load config: %w", err)
 	}
 
+	tlsPolicy, err := cfg.TLS.Policy()
+	if err != nil {
+		return fmt.Errorf("could not load TLS policy: %w", err)
+	}
+	ptls.SetPolicy(tlsPolicy)
+
+	// Apply changes to the TLS policy, the log level, and the log format without a restart.
+	go reloadTLSPolicy(ctx, os.Args[2], cfg.TLS)
+	plog.WatchConfigFile(ctx, os.Args[2], cfg.Log)
+
+	if cfg.Debug.Port != nil {
+		if err := debugserver.Start(ctx, *cfg.Debug.Port); err != nil {
+			return fmt.Errorf("could not start debug server: %w", err)
+		}
+	}
+
 	return runSupervisor(ctx, podInfo, cfg)
 }
 
+func securityHeaderOptions(spec supervisor.HTTPHardeningSpec) securityheader.Options {
+	return securityheader.Options{
+		HSTSMaxAge:            time.Duration(spec.HSTS.MaxAgeSeconds) * time.Second,
+		HSTSIncludeSubDomains: spec.HSTS.IncludeSubDomains,
+		HSTSPreload:           spec.HSTS.Preload,
+		ReferrerPolicy:        spec.ReferrerPolicy,
+	}
+}
+
+// reloadTLSPolicy periodically reads the TLS policy from the config file again, which is updated in place when its
+// ConfigMap changes. An invalid policy is logged and ignored, so the previous policy stays in effect.
+func reloadTLSPolicy(ctx context.Context, path string, current supervisor.TLSSpec) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		spec, err := supervisor.TLSFromPath(path)
+		if err != nil {
+			plog.WarningErr("could not reload TLS policy, continuing to use the previous policy", err, "path", path)
+			return
+		}
+		if reflect.DeepEqual(*spec, current) {
+			return
+		}
+
+		tlsPolicy, err := spec.Policy()
+		if err != nil { // should not happen, because the policy was already validated
+			plog.WarningErr("could not reload TLS policy, continuing to use the previous policy", err, "path", path)
+			return
+		}
+		ptls.SetPolicy(tlsPolicy)
+		current = *spec
+		plog.Info("reloaded TLS policy",
+			"minVersion", spec.MinVersion,
+			"cipherSuites", spec.CipherSuites,
+			"curvePreferences", spec.CurvePreferences,
+		)
+	}, tlsPolicyReloadInterval)
+}
+
 func Main() {
 	if err := main(); err != nil {
 		plog.Fatal(err)
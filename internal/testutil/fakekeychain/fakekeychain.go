@@ -0,0 +1,69 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fakekeychain implements an in-memory keychain.Keychain for tests.
+package fakekeychain
+
+import (
+	"sync"
+
+	"go.pinniped.dev/internal/keychain"
+)
+
+// Keychain is an in-memory keychain.Keychain. When Err is set, all operations fail with it.
+type Keychain struct {
+	Err error
+
+	mu      sync.Mutex
+	secrets map[[2]string]string
+}
+
+var _ keychain.Keychain = &Keychain{}
+
+// New returns an empty Keychain.
+func New() *Keychain {
+	return &Keychain{secrets: map[[2]string]string{}}
+}
+
+func (k *Keychain) Get(service, account string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.Err != nil {
+		return "", k.Err
+	}
+	secret, ok := k.secrets[[2]string{service, account}]
+	if !ok {
+		return "", keychain.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k *Keychain) Set(service, account, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.Err != nil {
+		return k.Err
+	}
+	k.secrets[[2]string{service, account}] = secret
+	return nil
+}
+
+func (k *Keychain) Delete(service, account string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.Err != nil {
+		return k.Err
+	}
+	if _, ok := k.secrets[[2]string{service, account}]; !ok {
+		return keychain.ErrNotFound
+	}
+	delete(k.secrets, [2]string{service, account})
+	return nil
+}
+
+// Len returns the number of secrets in the keychain.
+func (k *Keychain) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.secrets)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidctestutil
@@ -29,6 +29,7 @@ import (
 	"k8s.io/utils/strings/slices"
 
 	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/claimexpressions"
 	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/fositestorage/authorizationcode"
 	"go.pinniped.dev/internal/fositestorage/openidconnect"
@@ -80,6 +81,13 @@ type RevokeTokenArgs struct {
 	TokenType provider.RevocableTokenType
 }
 
+// PushAuthorizationRequestArgs is used to spy on calls to
+// TestUpstreamOIDCIdentityProvider.PushAuthorizationRequestFunc().
+type PushAuthorizationRequestArgs struct {
+	Ctx    context.Context
+	Params url.Values
+}
+
 // ValidateTokenAndMergeWithUserInfoArgs is used to spy on calls to
 // TestUpstreamOIDCIdentityProvider.ValidateTokenAndMergeWithUserInfoFunc().
 type ValidateTokenAndMergeWithUserInfoArgs struct {
@@ -97,14 +105,16 @@ type ValidateRefreshArgs struct {
 }
 
 type TestUpstreamLDAPIdentityProvider struct {
-	Name                    string
-	ResourceUID             types.UID
-	URL                     *url.URL
-	AuthenticateFunc        func(ctx context.Context, username, password string) (*authenticators.Response, bool, error)
-	performRefreshCallCount int
-	performRefreshArgs      []*PerformRefreshArgs
-	PerformRefreshErr       error
-	PerformRefreshGroups    []string
+	Name                           string
+	ResourceUID                    types.UID
+	URL                            *url.URL
+	AuthenticateFunc               func(ctx context.Context, username, password string) (*authenticators.Response, bool, error)
+	performRefreshCallCount        int
+	performRefreshArgs             []*PerformRefreshArgs
+	PerformRefreshErr              error
+	PerformRefreshGroups           []string
+	PerformRefreshAdditionalClaims map[string]interface{}
+	GroupRefreshInterval           time.Duration
 }
 
 var _ provider.UpstreamLDAPIdentityProviderI = &TestUpstreamLDAPIdentityProvider{}
@@ -125,7 +135,7 @@ func (u *TestUpstreamLDAPIdentityProvider) GetURL() *url.URL {
 	return u.URL
 }
 
-func (u *TestUpstreamLDAPIdentityProvider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, error) {
+func (u *TestUpstreamLDAPIdentityProvider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, map[string]interface{}, error) {
 	if u.performRefreshArgs == nil {
 		u.performRefreshArgs = make([]*PerformRefreshArgs, 0)
 	}
@@ -137,9 +147,13 @@ func (u *TestUpstreamLDAPIdentityProvider) PerformRefresh(ctx context.Context, s
 		ExpectedSubject:  storedRefreshAttributes.Subject,
 	})
 	if u.PerformRefreshErr != nil {
-		return nil, u.PerformRefreshErr
+		return nil, nil, u.PerformRefreshErr
 	}
-	return u.PerformRefreshGroups, nil
+	return u.PerformRefreshGroups, u.PerformRefreshAdditionalClaims, nil
+}
+
+func (u *TestUpstreamLDAPIdentityProvider) GetGroupRefreshInterval() time.Duration {
+	return u.GroupRefreshInterval
 }
 
 func (u *TestUpstreamLDAPIdentityProvider) PerformRefreshCallCount() int {
@@ -153,6 +167,57 @@ func (u *TestUpstreamLDAPIdentityProvider) PerformRefreshArgs(call int) *Perform
 	return u.performRefreshArgs[call]
 }
 
+type TestUpstreamKerberosIdentityProvider struct {
+	Name                    string
+	ResourceUID             types.UID
+	AuthenticateFunc        func(ctx context.Context, negotiateToken []byte, grantedScopes []string) (*authenticators.Response, error)
+	performRefreshCallCount int
+	performRefreshArgs      []*PerformRefreshArgs
+	PerformRefreshErr       error
+	PerformRefreshGroups    []string
+}
+
+var _ provider.UpstreamKerberosIdentityProviderI = &TestUpstreamKerberosIdentityProvider{}
+
+func (u *TestUpstreamKerberosIdentityProvider) GetResourceUID() types.UID {
+	return u.ResourceUID
+}
+
+func (u *TestUpstreamKerberosIdentityProvider) GetName() string {
+	return u.Name
+}
+
+func (u *TestUpstreamKerberosIdentityProvider) AuthenticateNegotiateToken(ctx context.Context, negotiateToken []byte, grantedScopes []string) (*authenticators.Response, error) {
+	return u.AuthenticateFunc(ctx, negotiateToken, grantedScopes)
+}
+
+func (u *TestUpstreamKerberosIdentityProvider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, error) {
+	if u.performRefreshArgs == nil {
+		u.performRefreshArgs = make([]*PerformRefreshArgs, 0)
+	}
+	u.performRefreshCallCount++
+	u.performRefreshArgs = append(u.performRefreshArgs, &PerformRefreshArgs{
+		Ctx:              ctx,
+		ExpectedUsername: storedRefreshAttributes.Username,
+		ExpectedSubject:  storedRefreshAttributes.Subject,
+	})
+	if u.PerformRefreshErr != nil {
+		return nil, u.PerformRefreshErr
+	}
+	return u.PerformRefreshGroups, nil
+}
+
+func (u *TestUpstreamKerberosIdentityProvider) PerformRefreshCallCount() int {
+	return u.performRefreshCallCount
+}
+
+func (u *TestUpstreamKerberosIdentityProvider) PerformRefreshArgs(call int) *PerformRefreshArgs {
+	if u.performRefreshArgs == nil {
+		u.performRefreshArgs = make([]*PerformRefreshArgs, 0)
+	}
+	return u.performRefreshArgs[call]
+}
+
 type TestUpstreamOIDCIdentityProvider struct {
 	Name                     string
 	ClientID                 string
@@ -167,6 +232,9 @@ type TestUpstreamOIDCIdentityProvider struct {
 	AdditionalClaimMappings  map[string]string
 	AllowPasswordGrant       bool
 
+	UsePushedAuthorizationRequests bool
+	ClaimExpressions               *claimexpressions.Expressions
+
 	ExchangeAuthcodeAndValidateTokensFunc func(
 		ctx context.Context,
 		authcode string,
@@ -186,6 +254,8 @@ type TestUpstreamOIDCIdentityProvider struct {
 
 	ValidateTokenAndMergeWithUserInfoFunc func(ctx context.Context, tok *oauth2.Token, expectedIDTokenNonce nonce.Nonce) (*oidctypes.Token, error)
 
+	PushAuthorizationRequestFunc func(ctx context.Context, params url.Values) (string, error)
+
 	exchangeAuthcodeAndValidateTokensCallCount         int
 	exchangeAuthcodeAndValidateTokensArgs              []*ExchangeAuthcodeAndValidateTokenArgs
 	passwordCredentialsGrantAndValidateTokensCallCount int
@@ -196,6 +266,8 @@ type TestUpstreamOIDCIdentityProvider struct {
 	revokeTokenArgs                                    []*RevokeTokenArgs
 	validateTokenAndMergeWithUserInfoCallCount         int
 	validateTokenAndMergeWithUserInfoArgs              []*ValidateTokenAndMergeWithUserInfoArgs
+	pushAuthorizationRequestCallCount                  int
+	pushAuthorizationRequestArgs                       []*PushAuthorizationRequestArgs
 }
 
 var _ provider.UpstreamOIDCIdentityProviderI = &TestUpstreamOIDCIdentityProvider{}
@@ -212,6 +284,10 @@ func (u *TestUpstreamOIDCIdentityProvider) GetAdditionalClaimMappings() map[stri
 	return u.AdditionalClaimMappings
 }
 
+func (u *TestUpstreamOIDCIdentityProvider) GetClaimExpressions() *claimexpressions.Expressions {
+	return u.ClaimExpressions
+}
+
 func (u *TestUpstreamOIDCIdentityProvider) GetName() string {
 	return u.Name
 }
@@ -248,6 +324,33 @@ func (u *TestUpstreamOIDCIdentityProvider) AllowsPasswordGrant() bool {
 	return u.AllowPasswordGrant
 }
 
+func (u *TestUpstreamOIDCIdentityProvider) UsesPushedAuthorizationRequests() bool {
+	return u.UsePushedAuthorizationRequests
+}
+
+func (u *TestUpstreamOIDCIdentityProvider) PushAuthorizationRequest(ctx context.Context, params url.Values) (string, error) {
+	if u.pushAuthorizationRequestArgs == nil {
+		u.pushAuthorizationRequestArgs = make([]*PushAuthorizationRequestArgs, 0)
+	}
+	u.pushAuthorizationRequestCallCount++
+	u.pushAuthorizationRequestArgs = append(u.pushAuthorizationRequestArgs, &PushAuthorizationRequestArgs{
+		Ctx:    ctx,
+		Params: params,
+	})
+	return u.PushAuthorizationRequestFunc(ctx, params)
+}
+
+func (u *TestUpstreamOIDCIdentityProvider) PushAuthorizationRequestCallCount() int {
+	return u.pushAuthorizationRequestCallCount
+}
+
+func (u *TestUpstreamOIDCIdentityProvider) PushAuthorizationRequestArgs(call int) *PushAuthorizationRequestArgs {
+	if u.pushAuthorizationRequestArgs == nil {
+		u.pushAuthorizationRequestArgs = make([]*PushAuthorizationRequestArgs, 0)
+	}
+	return u.pushAuthorizationRequestArgs[call]
+}
+
 func (u *TestUpstreamOIDCIdentityProvider) PasswordCredentialsGrantAndValidateTokens(ctx context.Context, username, password string) (*oidctypes.Token, error) {
 	u.passwordCredentialsGrantAndValidateTokensCallCount++
 	u.passwordCredentialsGrantAndValidateTokensArgs = append(u.passwordCredentialsGrantAndValidateTokensArgs, &PasswordCredentialsGrantAndValidateTokensArgs{
@@ -367,6 +470,7 @@ type UpstreamIDPListerBuilder struct {
 	upstreamOIDCIdentityProviders            []*TestUpstreamOIDCIdentityProvider
 	upstreamLDAPIdentityProviders            []*TestUpstreamLDAPIdentityProvider
 	upstreamActiveDirectoryIdentityProviders []*TestUpstreamLDAPIdentityProvider
+	upstreamKerberosIdentityProviders        []*TestUpstreamKerberosIdentityProvider
 }
 
 func (b *UpstreamIDPListerBuilder) WithOIDC(upstreamOIDCIdentityProviders ...*TestUpstreamOIDCIdentityProvider) *UpstreamIDPListerBuilder {
@@ -384,6 +488,11 @@ func (b *UpstreamIDPListerBuilder) WithActiveDirectory(upstreamActiveDirectoryId
 	return b
 }
 
+func (b *UpstreamIDPListerBuilder) WithKerberos(upstreamKerberosIdentityProviders ...*TestUpstreamKerberosIdentityProvider) *UpstreamIDPListerBuilder {
+	b.upstreamKerberosIdentityProviders = append(b.upstreamKerberosIdentityProviders, upstreamKerberosIdentityProviders...)
+	return b
+}
+
 func (b *UpstreamIDPListerBuilder) Build() provider.DynamicUpstreamIDPProvider {
 	idpProvider := provider.NewDynamicUpstreamIDPProvider()
 
@@ -405,6 +514,12 @@ func (b *UpstreamIDPListerBuilder) Build() provider.DynamicUpstreamIDPProvider {
 	}
 	idpProvider.SetActiveDirectoryIdentityProviders(adUpstreams)
 
+	kerberosUpstreams := make([]provider.UpstreamKerberosIdentityProviderI, len(b.upstreamKerberosIdentityProviders))
+	for i := range b.upstreamKerberosIdentityProviders {
+		kerberosUpstreams[i] = provider.UpstreamKerberosIdentityProviderI(b.upstreamKerberosIdentityProviders[i])
+	}
+	idpProvider.SetKerberosIdentityProviders(kerberosUpstreams)
+
 	return idpProvider
 }
 
@@ -515,6 +630,14 @@ func (b *UpstreamIDPListerBuilder) RequireExactlyOneCallToPerformRefresh(
 			actualArgs = upstreamAD.performRefreshArgs[0]
 		}
 	}
+	for _, upstreamKerberos := range b.upstreamKerberosIdentityProviders {
+		callCountOnThisUpstream := upstreamKerberos.performRefreshCallCount
+		actualCallCountAcrossAllUpstreams += callCountOnThisUpstream
+		if callCountOnThisUpstream == 1 {
+			actualNameOfUpstreamWhichMadeCall = upstreamKerberos.Name
+			actualArgs = upstreamKerberos.performRefreshArgs[0]
+		}
+	}
 	require.Equal(t, 1, actualCallCountAcrossAllUpstreams,
 		"should have been exactly one call to PerformRefresh() by all upstreams",
 	)
@@ -536,6 +659,9 @@ func (b *UpstreamIDPListerBuilder) RequireExactlyZeroCallsToPerformRefresh(t *te
 	for _, upstreamActiveDirectory := range b.upstreamActiveDirectoryIdentityProviders {
 		actualCallCountAcrossAllUpstreams += upstreamActiveDirectory.performRefreshCallCount
 	}
+	for _, upstreamKerberos := range b.upstreamKerberosIdentityProviders {
+		actualCallCountAcrossAllUpstreams += upstreamKerberos.performRefreshCallCount
+	}
 
 	require.Equal(t, 0, actualCallCountAcrossAllUpstreams,
 		"expected exactly zero calls to PerformRefresh()",
@@ -616,6 +742,31 @@ func (b *UpstreamIDPListerBuilder) RequireExactlyZeroCallsToRevokeToken(t *testi
 	)
 }
 
+func (b *UpstreamIDPListerBuilder) RequireExactlyOneCallToPushAuthorizationRequest(
+	t *testing.T,
+	expectedPerformedByUpstreamName string,
+) *PushAuthorizationRequestArgs {
+	t.Helper()
+	var actualArgs *PushAuthorizationRequestArgs
+	var actualNameOfUpstreamWhichMadeCall string
+	actualCallCountAcrossAllOIDCUpstreams := 0
+	for _, upstreamOIDC := range b.upstreamOIDCIdentityProviders {
+		callCountOnThisUpstream := upstreamOIDC.pushAuthorizationRequestCallCount
+		actualCallCountAcrossAllOIDCUpstreams += callCountOnThisUpstream
+		if callCountOnThisUpstream == 1 {
+			actualNameOfUpstreamWhichMadeCall = upstreamOIDC.Name
+			actualArgs = upstreamOIDC.pushAuthorizationRequestArgs[0]
+		}
+	}
+	require.Equal(t, 1, actualCallCountAcrossAllOIDCUpstreams,
+		"should have been exactly one call to PushAuthorizationRequest() by all OIDC upstreams",
+	)
+	require.Equal(t, expectedPerformedByUpstreamName, actualNameOfUpstreamWhichMadeCall,
+		"PushAuthorizationRequest() was called on the wrong OIDC upstream",
+	)
+	return actualArgs
+}
+
 func NewUpstreamIDPListerBuilder() *UpstreamIDPListerBuilder {
 	return &UpstreamIDPListerBuilder{}
 }
@@ -642,6 +793,9 @@ type TestUpstreamOIDCIdentityProviderBuilder struct {
 	performRefreshErr                    error
 	revokeTokenErr                       error
 	validateTokenAndMergeWithUserInfoErr error
+	pushedAuthorizationRequestURI        string
+	pushAuthorizationRequestErr          error
+	claimExpressions                     *claimexpressions.Expressions
 }
 
 func (u *TestUpstreamOIDCIdentityProviderBuilder) WithName(value string) *TestUpstreamOIDCIdentityProviderBuilder {
@@ -727,6 +881,11 @@ func (u *TestUpstreamOIDCIdentityProviderBuilder) WithAdditionalClaimMappings(m
 	return u
 }
 
+func (u *TestUpstreamOIDCIdentityProviderBuilder) WithClaimExpressions(e *claimexpressions.Expressions) *TestUpstreamOIDCIdentityProviderBuilder {
+	u.claimExpressions = e
+	return u
+}
+
 func (u *TestUpstreamOIDCIdentityProviderBuilder) WithRefreshToken(token string) *TestUpstreamOIDCIdentityProviderBuilder {
 	u.refreshToken = &oidctypes.RefreshToken{Token: token}
 	return u
@@ -791,6 +950,16 @@ func (u *TestUpstreamOIDCIdentityProviderBuilder) WithRevokeTokenError(err error
 	return u
 }
 
+func (u *TestUpstreamOIDCIdentityProviderBuilder) WithPushedAuthorizationRequestURI(requestURI string) *TestUpstreamOIDCIdentityProviderBuilder {
+	u.pushedAuthorizationRequestURI = requestURI
+	return u
+}
+
+func (u *TestUpstreamOIDCIdentityProviderBuilder) WithPushAuthorizationRequestError(err error) *TestUpstreamOIDCIdentityProviderBuilder {
+	u.pushAuthorizationRequestErr = err
+	return u
+}
+
 func (u *TestUpstreamOIDCIdentityProviderBuilder) Build() *TestUpstreamOIDCIdentityProvider {
 	return &TestUpstreamOIDCIdentityProvider{
 		Name:                     u.name,
@@ -831,6 +1000,14 @@ func (u *TestUpstreamOIDCIdentityProviderBuilder) Build() *TestUpstreamOIDCIdent
 			}
 			return u.validatedAndMergedWithUserInfoTokens, nil
 		},
+		UsePushedAuthorizationRequests: u.pushedAuthorizationRequestURI != "" || u.pushAuthorizationRequestErr != nil,
+		PushAuthorizationRequestFunc: func(ctx context.Context, params url.Values) (string, error) {
+			if u.pushAuthorizationRequestErr != nil {
+				return "", u.pushAuthorizationRequestErr
+			}
+			return u.pushedAuthorizationRequestURI, nil
+		},
+		ClaimExpressions: u.claimExpressions,
 	}
 }
 
@@ -919,8 +1096,22 @@ func VerifyECDSAIDToken(
 ) *coreosoidc.IDToken {
 	t.Helper()
 
+	return VerifyIDToken(t, issuer, clientID, jwtSigningKey, coreosoidc.ES256, idToken)
+}
+
+// VerifyIDToken is like VerifyECDSAIDToken, but it verifies an idToken which was signed with any type of
+// jwtSigningKey using the provided signing algorithm, e.g. RS256 or EdDSA.
+func VerifyIDToken(
+	t *testing.T,
+	issuer, clientID string,
+	jwtSigningKey crypto.Signer,
+	algorithm string,
+	idToken string,
+) *coreosoidc.IDToken {
+	t.Helper()
+
 	keySet := newStaticKeySet(jwtSigningKey.Public())
-	verifyConfig := coreosoidc.Config{ClientID: clientID, SupportedSigningAlgs: []string{coreosoidc.ES256}}
+	verifyConfig := coreosoidc.Config{ClientID: clientID, SupportedSigningAlgs: []string{algorithm}}
 	verifier := coreosoidc.NewVerifier(issuer, keySet, &verifyConfig)
 	token, err := verifier.Verify(context.Background(), idToken)
 	require.NoError(t, err)
@@ -1082,6 +1273,10 @@ func validateAuthcodeStorage(
 	require.Equal(t, wantDownstreamClientID, actualClaims.Extra["azp"])
 	wantDownstreamIDTokenExtraClaimsCount := 1 // should always have azp claim
 
+	// Should always have a sid claim, which identifies the downstream session by the ID of its authorize request.
+	require.Equal(t, storedRequestFromAuthcode.ID, actualClaims.Extra["sid"])
+	wantDownstreamIDTokenExtraClaimsCount++
+
 	if len(wantDownstreamAdditionalClaims) > 0 {
 		wantDownstreamIDTokenExtraClaimsCount++
 	}
@@ -0,0 +1,87 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing exports OpenTelemetry traces of the Supervisor's login and token flows to an OTLP collector.
+// When tracing is not configured, the spans which are started by this package are no-ops.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/plog"
+)
+
+const instrumentationName = "go.pinniped.dev/internal/tracing"
+
+// Setup starts exporting the spans of this process to the OTLP gRPC collector at the endpoint, tracing the given
+// number of requests per million unless the caller already decided. The returned function flushes the remaining
+// spans and stops the export. When the endpoint is empty, Setup does nothing.
+func Setup(
+	ctx context.Context,
+	serviceName string,
+	endpoint string,
+	insecure bool,
+	samplingRatePerMillion int32,
+) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(ptls.Default(nil))))
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP trace exporter: %w", err)
+	}
+
+	samplingRatio := float64(samplingRatePerMillion) / 1000000
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	plog.Info("exporting traces", "endpoint", endpoint, "samplingRatePerMillion", samplingRatePerMillion)
+
+	return tracerProvider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span of the context, if any.
+func Start(ctx context.Context, spanName string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, spanName, trace.WithAttributes(attributes...))
+}
+
+// End ends the span, marking it as failed when err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Handler starts a server span for every request which is served by the handler, continuing the trace of the
+// caller when the request propagates one.
+func Handler(spanName string, handler http.Handler) http.Handler {
+	return otelhttp.NewHandler(handler, spanName)
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "some-service", "", false, 1000000)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+	handler := Handler("some-endpoint", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, okSpan := Start(r.Context(), "some-child", attribute.String("some-key", "some-value"))
+		End(okSpan, nil)
+		_, failedSpan := Start(r.Context(), "some-failed-child")
+		End(failedSpan, errors.New("some error"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/some/path", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	okSpan, failedSpan, serverSpan := spans[0], spans[1], spans[2]
+	require.Equal(t, "some-endpoint", serverSpan.Name())
+
+	require.Equal(t, "some-child", okSpan.Name())
+	require.Equal(t, serverSpan.SpanContext().SpanID(), okSpan.Parent().SpanID())
+	require.Equal(t, []attribute.KeyValue{attribute.String("some-key", "some-value")}, okSpan.Attributes())
+	require.Equal(t, codes.Unset, okSpan.Status().Code)
+
+	require.Equal(t, "some-failed-child", failedSpan.Name())
+	require.Equal(t, serverSpan.SpanContext().SpanID(), failedSpan.Parent().SpanID())
+	require.Equal(t, codes.Error, failedSpan.Status().Code)
+	require.Equal(t, "some error", failedSpan.Status().Description)
+	require.Len(t, failedSpan.Events(), 1) // the recorded error
+}
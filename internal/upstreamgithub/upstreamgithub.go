@@ -0,0 +1,368 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package upstreamgithub implements an abstraction of upstream GitHub provider interactions.
+package upstreamgithub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/claimexpressions"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+const (
+	// UsernameClaim and GroupsClaim are the names of the claims computed from the GitHub API responses.
+	UsernameClaim = "username"
+	GroupsClaim   = "groups"
+
+	// The supported values of UsernameAttribute.
+	UsernameAttributeLogin      = "login"
+	UsernameAttributeID         = "id"
+	UsernameAttributeLoginAndID = "login:id"
+
+	// The supported values of GroupNameAttribute.
+	GroupNameAttributeName = "name"
+	GroupNameAttributeSlug = "slug"
+
+	// See https://docs.github.com/en/rest/about-the-rest-api/api-versions.
+	apiVersionHeader = "X-GitHub-Api-Version"
+	apiVersion       = "2022-11-28"
+
+	// The maximum page size of the GitHub list APIs.
+	perPage = "100"
+)
+
+// ProviderConfig holds the active configuration of an upstream GitHub provider. It implements the same interface as
+// the upstream OIDC providers, so it can be used by the same Supervisor endpoints. Since GitHub does not return
+// ID tokens, the claims are computed from the GitHub API responses instead: the "sub" claim is the user's numeric ID,
+// the "iss" claim is the API base URL, and the UsernameClaim and GroupsClaim hold the user's username and groups.
+type ProviderConfig struct {
+	Name        string
+	ResourceUID types.UID
+	Config      *oauth2.Config
+	Client      *http.Client
+
+	// APIBaseURL is the base URL of the GitHub API, e.g. "https://api.github.com" for github.com, or
+	// "https://github.example.com/api/v3" for GitHub Enterprise Server.
+	APIBaseURL *url.URL
+
+	// UsernameAttribute is one of the UsernameAttribute constants.
+	UsernameAttribute string
+
+	// GroupNameAttribute is one of the GroupNameAttribute constants.
+	GroupNameAttribute string
+
+	// AllowedOrganizations are the lowercase login names of the organizations whose members may log in.
+	// Only teams from these organizations are used as groups. When empty, all GitHub users may log in,
+	// and teams from all organizations are used as groups.
+	AllowedOrganizations sets.Set[string]
+}
+
+var _ provider.UpstreamOIDCIdentityProviderI = (*ProviderConfig)(nil)
+
+func (p *ProviderConfig) GetName() string {
+	return p.Name
+}
+
+func (p *ProviderConfig) GetClientID() string {
+	return p.Config.ClientID
+}
+
+func (p *ProviderConfig) GetResourceUID() types.UID {
+	return p.ResourceUID
+}
+
+func (p *ProviderConfig) GetAuthorizationURL() *url.URL {
+	result, _ := url.Parse(p.Config.Endpoint.AuthURL)
+	return result
+}
+
+// HasUserInfoURL always returns true, since the GitHub API takes the place of the userinfo endpoint.
+func (p *ProviderConfig) HasUserInfoURL() bool {
+	return true
+}
+
+func (p *ProviderConfig) GetScopes() []string {
+	return p.Config.Scopes
+}
+
+func (p *ProviderConfig) GetUsernameClaim() string {
+	return UsernameClaim
+}
+
+func (p *ProviderConfig) GetGroupsClaim() string {
+	return GroupsClaim
+}
+
+func (p *ProviderConfig) AllowsPasswordGrant() bool {
+	return false
+}
+
+func (p *ProviderConfig) GetAdditionalAuthcodeParams() map[string]string {
+	return nil
+}
+
+func (p *ProviderConfig) GetAdditionalClaimMappings() map[string]string {
+	return nil
+}
+
+func (p *ProviderConfig) GetClaimExpressions() *claimexpressions.Expressions {
+	return nil
+}
+
+func (p *ProviderConfig) UsesPushedAuthorizationRequests() bool {
+	return false
+}
+
+func (p *ProviderConfig) PushAuthorizationRequest(_ context.Context, _ url.Values) (string, error) {
+	return "", errors.New("pushed authorization requests are not supported for GitHub upstream providers")
+}
+
+func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(_ context.Context, _, _ string) (*oidctypes.Token, error) {
+	return nil, errors.New("resource owner password credentials grant is not allowed for GitHub upstream providers")
+}
+
+// ExchangeAuthcodeAndValidateTokens exchanges the authcode and then computes the user's claims using the GitHub API.
+// The nonce is ignored, since there is no ID token in which it could be validated.
+func (p *ProviderConfig) ExchangeAuthcodeAndValidateTokens(
+	ctx context.Context,
+	authcode string,
+	pkceCodeVerifier pkce.Code,
+	_ nonce.Nonce,
+	redirectURI string,
+) (*oidctypes.Token, error) {
+	tok, err := p.Config.Exchange(
+		coreosoidc.ClientContext(ctx, p.Client),
+		authcode,
+		pkceCodeVerifier.Verifier(),
+		oauth2.SetAuthURLParam("redirect_uri", redirectURI),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ValidateTokenAndMergeWithUserInfo(ctx, tok, "", false, true)
+}
+
+func (p *ProviderConfig) PerformRefresh(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	// Use the provided HTTP client to benefit from its CA, proxy, and other settings.
+	httpClientContext := coreosoidc.ClientContext(ctx, p.Client)
+	// Create a TokenSource without an access token, so it thinks that a refresh is immediately required.
+	// Then ask it for the tokens to cause it to perform the refresh and return the results.
+	return p.Config.TokenSource(httpClientContext, &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+// RevokeToken does nothing, since GitHub does not offer a standard revocation endpoint.
+func (p *ProviderConfig) RevokeToken(_ context.Context, _ string, tokenType provider.RevocableTokenType) error {
+	plog.Trace("RevokeToken() was called but upstream GitHub provider has no revocation endpoint",
+		"providerName", p.Name,
+		"tokenType", tokenType,
+	)
+	return nil
+}
+
+// ValidateTokenAndMergeWithUserInfo uses the access token to compute the user's claims using the GitHub API.
+// The GitHub API is always called regardless of requireIDToken and requireUserInfo, since GitHub does not return
+// ID tokens. This is also called during refreshes, so a user who has since left all the allowed organizations
+// will fail to refresh their session.
+func (p *ProviderConfig) ValidateTokenAndMergeWithUserInfo(ctx context.Context, tok *oauth2.Token, _ nonce.Nonce, _ bool, _ bool) (*oidctypes.Token, error) {
+	var user struct {
+		Login string `json:"login"`
+		ID    int64  `json:"id"`
+	}
+	if _, err := p.get(ctx, tok, p.apiURL("user"), &user); err != nil {
+		return nil, err
+	}
+	if user.Login == "" || user.ID == 0 {
+		return nil, errors.New("GitHub user response is missing login or id")
+	}
+	id := strconv.FormatInt(user.ID, 10)
+
+	if p.AllowedOrganizations.Len() > 0 {
+		allowed, err := p.isMemberOfAllowedOrganization(ctx, tok)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("GitHub user %q is not a member of any of the allowed organizations", user.Login)
+		}
+	}
+
+	groups, err := p.getGroups(ctx, tok)
+	if err != nil {
+		return nil, err
+	}
+
+	var username string
+	switch p.UsernameAttribute {
+	case UsernameAttributeLogin:
+		username = user.Login
+	case UsernameAttributeID:
+		username = id
+	default:
+		username = user.Login + ":" + id
+	}
+
+	claims := map[string]interface{}{
+		// The ID is only unique for this GitHub installation, so the API base URL is used as the issuer to make
+		// the downstream subject globally unique. The ID is used rather than the login, since users may change
+		// their login names.
+		oidcapi.IDTokenClaimIssuer:  p.APIBaseURL.String(),
+		oidcapi.IDTokenClaimSubject: id,
+		UsernameClaim:               username,
+		GroupsClaim:                 groups,
+	}
+	plog.Debug("computed claims from GitHub API", "providerName", p.Name, "username", username, "groupsCount", len(groups))
+
+	return &oidctypes.Token{
+		AccessToken: &oidctypes.AccessToken{
+			Token:  tok.AccessToken,
+			Type:   tok.TokenType,
+			Expiry: metav1.NewTime(tok.Expiry),
+		},
+		RefreshToken: &oidctypes.RefreshToken{
+			Token: tok.RefreshToken,
+		},
+		IDToken: &oidctypes.IDToken{
+			Claims: claims,
+		},
+	}, nil
+}
+
+func (p *ProviderConfig) isMemberOfAllowedOrganization(ctx context.Context, tok *oauth2.Token) (bool, error) {
+	next := p.apiURL("user/orgs")
+	for next != "" {
+		var orgs []struct {
+			Login string `json:"login"`
+		}
+		var err error
+		if next, err = p.get(ctx, tok, next, &orgs); err != nil {
+			return false, err
+		}
+		for _, org := range orgs {
+			if p.AllowedOrganizations.Has(strings.ToLower(org.Login)) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// getGroups returns the user's teams as "org/team" group names, sorted and without duplicates.
+func (p *ProviderConfig) getGroups(ctx context.Context, tok *oauth2.Token) ([]string, error) {
+	groups := sets.New[string]()
+	next := p.apiURL("user/teams")
+	for next != "" {
+		var teams []struct {
+			Name         string `json:"name"`
+			Slug         string `json:"slug"`
+			Organization struct {
+				Login string `json:"login"`
+			} `json:"organization"`
+		}
+		var err error
+		if next, err = p.get(ctx, tok, next, &teams); err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			org := team.Organization.Login
+			if p.AllowedOrganizations.Len() > 0 && !p.AllowedOrganizations.Has(strings.ToLower(org)) {
+				continue
+			}
+			teamName := team.Slug
+			if p.GroupNameAttribute == GroupNameAttributeName {
+				teamName = team.Name
+			}
+			groups.Insert(org + "/" + teamName)
+		}
+	}
+	return sets.List(groups), nil
+}
+
+func (p *ProviderConfig) apiURL(path string) string {
+	u := p.APIBaseURL.JoinPath(path)
+	u.RawQuery = url.Values{"per_page": []string{perPage}}.Encode()
+	return u.String()
+}
+
+// get calls the GitHub API and decodes the JSON response into result. It returns the URL of the next page
+// of results, or an empty string when there are no more pages.
+func (p *ProviderConfig) get(ctx context.Context, tok *oauth2.Token, requestURL string, result interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create GitHub API request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set(apiVersionHeader, apiVersion)
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not call GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read GitHub API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API request to %q failed with status %d with body: %s",
+			req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return "", fmt.Errorf("could not unmarshal GitHub API response from %q: %w", req.URL.Path, err)
+	}
+
+	return p.nextPageURL(resp.Header.Get("Link"))
+}
+
+// nextPageURL parses the "next" URL from a Link header. The URL must be on the same host as the API, since
+// the user's access token will be sent to it.
+// See https://docs.github.com/en/rest/using-the-rest-api/using-pagination-in-the-rest-api.
+func (p *ProviderConfig) nextPageURL(linkHeader string) (string, error) {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		isNext := false
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+		next, err := url.Parse(strings.Trim(strings.TrimSpace(parts[0]), "<>"))
+		if err != nil {
+			return "", fmt.Errorf("could not parse next page URL from GitHub API response: %w", err)
+		}
+		if next.Scheme != p.APIBaseURL.Scheme || next.Host != p.APIBaseURL.Host {
+			return "", fmt.Errorf("next page URL %q from GitHub API response is not on the API host", next.String())
+		}
+		return next.String(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamgithub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+func TestProviderConfig(t *testing.T) {
+	t.Run("getters", func(t *testing.T) {
+		p := ProviderConfig{
+			Name:        "test-name",
+			ResourceUID: "test-uid",
+			Config: &oauth2.Config{
+				ClientID: "test-client-id",
+				Endpoint: oauth2.Endpoint{AuthURL: "https://github.com/login/oauth/authorize"},
+				Scopes:   []string{"read:user", "read:org"},
+			},
+		}
+		require.Equal(t, "test-name", p.GetName())
+		require.Equal(t, "test-uid", string(p.GetResourceUID()))
+		require.Equal(t, "test-client-id", p.GetClientID())
+		require.Equal(t, "https://github.com/login/oauth/authorize", p.GetAuthorizationURL().String())
+		require.Equal(t, []string{"read:user", "read:org"}, p.GetScopes())
+		require.Nil(t, p.GetAdditionalAuthcodeParams())
+		require.Nil(t, p.GetClaimExpressions())
+		require.True(t, p.HasUserInfoURL())
+		require.Equal(t, "username", p.GetUsernameClaim())
+		require.Equal(t, "groups", p.GetGroupsClaim())
+		require.Nil(t, p.GetAdditionalClaimMappings())
+		require.False(t, p.AllowsPasswordGrant())
+		require.False(t, p.UsesPushedAuthorizationRequests())
+
+		_, err := p.PasswordCredentialsGrantAndValidateTokens(context.Background(), "user", "pass")
+		require.EqualError(t, err, "resource owner password credentials grant is not allowed for GitHub upstream providers")
+		_, err = p.PushAuthorizationRequest(context.Background(), url.Values{})
+		require.EqualError(t, err, "pushed authorization requests are not supported for GitHub upstream providers")
+		require.NoError(t, p.RevokeToken(context.Background(), "some-token", "access_token"))
+	})
+
+	t.Run("ExchangeAuthcodeAndValidateTokens", func(t *testing.T) {
+		tests := []struct {
+			name                 string
+			usernameAttribute    string
+			groupNameAttribute   string
+			allowedOrganizations []string
+			userStatus           int
+			userResponse         string
+			nextPageHost         string
+
+			wantUsername string
+			wantGroups   []string
+			wantErr      string
+		}{
+			{
+				name:         "uses login:id as the username and team slugs from all organizations as groups by default",
+				userResponse: `{"login":"octocat","id":1234}`,
+				wantUsername: "octocat:1234",
+				wantGroups:   []string{"org1/team-a", "org1/team-b", "org2/team-c"},
+			},
+			{
+				name:               "uses login as the username and team names as groups",
+				usernameAttribute:  "login",
+				groupNameAttribute: "name",
+				userResponse:       `{"login":"octocat","id":1234}`,
+				wantUsername:       "octocat",
+				wantGroups:         []string{"org1/Team A", "org1/Team B", "org2/Team C"},
+			},
+			{
+				name:                 "uses id as the username and only includes teams from the allowed organizations",
+				usernameAttribute:    "id",
+				allowedOrganizations: []string{"org2"},
+				userResponse:         `{"login":"octocat","id":1234}`,
+				wantUsername:         "1234",
+				wantGroups:           []string{"org2/team-c"},
+			},
+			{
+				name:                 "user is not a member of any allowed organization",
+				allowedOrganizations: []string{"other-org"},
+				userResponse:         `{"login":"octocat","id":1234}`,
+				wantErr:              `GitHub user "octocat" is not a member of any of the allowed organizations`,
+			},
+			{
+				name:         "user endpoint returns an error",
+				userStatus:   http.StatusUnauthorized,
+				userResponse: `{"message":"Bad credentials"}`,
+				wantErr:      `GitHub API request to "/api/v3/user" failed with status 401 with body: {"message":"Bad credentials"}`,
+			},
+			{
+				name:         "user endpoint returns a response without an id",
+				userResponse: `{"login":"octocat"}`,
+				wantErr:      "GitHub user response is missing login or id",
+			},
+			{
+				name:         "next page link is on another host",
+				userResponse: `{"login":"octocat","id":1234}`,
+				nextPageHost: "evil.example.com",
+				wantErr:      `next page URL "https://evil.example.com/api/v3/user/teams?per_page=100&page=2" from GitHub API response is not on the API host`,
+			},
+		}
+		for _, test := range tests {
+			tt := test
+			t.Run(tt.name, func(t *testing.T) {
+				mux := http.NewServeMux()
+				mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.NoError(t, r.ParseForm())
+					require.Equal(t, "authorization_code", r.Form.Get("grant_type"))
+					require.Equal(t, "test-authcode", r.Form.Get("code"))
+					require.Equal(t, "test-pkce", r.Form.Get("code_verifier"))
+					require.Equal(t, "https://example.com/callback", r.Form.Get("redirect_uri"))
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"test-access-token","token_type":"bearer"}`))
+				})
+				mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, "Bearer test-access-token", r.Header.Get("Authorization"))
+					require.Equal(t, "2022-11-28", r.Header.Get("X-GitHub-Api-Version"))
+					if tt.userStatus != 0 {
+						w.WriteHeader(tt.userStatus)
+					}
+					_, _ = w.Write([]byte(tt.userResponse))
+				})
+				mux.HandleFunc("/api/v3/user/orgs", func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`[{"login":"Org1"},{"login":"org2"}]`))
+				})
+				mux.HandleFunc("/api/v3/user/teams", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, "100", r.URL.Query().Get("per_page"))
+					if r.URL.Query().Get("page") == "2" {
+						_, _ = w.Write([]byte(`[{"name":"Team C","slug":"team-c","organization":{"login":"org2"}}]`))
+						return
+					}
+					nextPageHost := tt.nextPageHost
+					if nextPageHost == "" {
+						nextPageHost = r.Host
+					}
+					// Like GitHub, the next page URLs keep the per_page param of the request.
+					w.Header().Set("Link", fmt.Sprintf(`<https://%s/api/v3/user/teams?per_page=100&page=2>; rel="next", <https://%s/api/v3/user/teams?per_page=100&page=2>; rel="last"`, nextPageHost, nextPageHost))
+					_, _ = w.Write([]byte(`[` +
+						`{"name":"Team B","slug":"team-b","organization":{"login":"org1"}},` +
+						`{"name":"Team A","slug":"team-a","organization":{"login":"org1"}}` +
+						`]`))
+				})
+				server := httptest.NewTLSServer(mux)
+				t.Cleanup(server.Close)
+				serverURL := server.URL
+
+				apiBaseURL, err := url.Parse(serverURL + "/api/v3")
+				require.NoError(t, err)
+				p := ProviderConfig{
+					Name: "test-name",
+					Config: &oauth2.Config{
+						ClientID:     "test-client-id",
+						ClientSecret: "test-client-secret",
+						Endpoint:     oauth2.Endpoint{TokenURL: serverURL + "/login/oauth/access_token", AuthStyle: oauth2.AuthStyleInParams},
+					},
+					Client:               server.Client(),
+					APIBaseURL:           apiBaseURL,
+					UsernameAttribute:    tt.usernameAttribute,
+					GroupNameAttribute:   tt.groupNameAttribute,
+					AllowedOrganizations: sets.New(tt.allowedOrganizations...),
+				}
+
+				tok, err := p.ExchangeAuthcodeAndValidateTokens(context.Background(), "test-authcode", pkce.Code("test-pkce"), "test-nonce", "https://example.com/callback")
+				if tt.wantErr != "" {
+					require.EqualError(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, "test-access-token", tok.AccessToken.Token)
+				require.Empty(t, tok.IDToken.Token)
+				require.Equal(t, map[string]interface{}{
+					"iss":      apiBaseURL.String(),
+					"sub":      "1234",
+					"username": tt.wantUsername,
+					"groups":   tt.wantGroups,
+				}, tok.IDToken.Claims)
+			})
+		}
+	})
+}
@@ -0,0 +1,354 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package upstreamgitlab implements an abstraction of upstream GitLab provider interactions.
+package upstreamgitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/claimexpressions"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+const (
+	// UsernameClaim and GroupsClaim are the names of the claims computed from the GitLab API responses.
+	UsernameClaim = "username"
+	GroupsClaim   = "groups"
+
+	// The supported values of UsernameAttribute.
+	UsernameAttributeUsername = "username"
+	UsernameAttributeID       = "id"
+	UsernameAttributeEmail    = "email"
+
+	// The maximum page size of the GitLab list APIs.
+	perPage = "100"
+
+	// The minimum access level of the groups which are included as the user's groups. This is the "Guest" level,
+	// so all groups of which the user is a direct or inherited member are included.
+	// See https://docs.gitlab.com/ee/api/members.html#roles.
+	minAccessLevel = "10"
+)
+
+// ProviderConfig holds the active configuration of an upstream GitLab provider. It implements the same interface as
+// the upstream OIDC providers, so it can be used by the same Supervisor endpoints. The claims are computed from the
+// GitLab API responses: the "sub" claim is the user's numeric ID, the "iss" claim is the API base URL, and the
+// UsernameClaim and GroupsClaim hold the user's username and the full paths of their groups and subgroups.
+type ProviderConfig struct {
+	Name        string
+	ResourceUID types.UID
+	Config      *oauth2.Config
+	Client      *http.Client
+
+	// APIBaseURL is the base URL of the GitLab API, e.g. "https://gitlab.com/api/v4".
+	APIBaseURL *url.URL
+
+	// RevocationURL is the GitLab token revocation endpoint, e.g. "https://gitlab.com/oauth/revoke".
+	RevocationURL *url.URL
+
+	// UsernameAttribute is one of the UsernameAttribute constants.
+	UsernameAttribute string
+}
+
+var _ provider.UpstreamOIDCIdentityProviderI = (*ProviderConfig)(nil)
+
+func (p *ProviderConfig) GetName() string {
+	return p.Name
+}
+
+func (p *ProviderConfig) GetClientID() string {
+	return p.Config.ClientID
+}
+
+func (p *ProviderConfig) GetResourceUID() types.UID {
+	return p.ResourceUID
+}
+
+func (p *ProviderConfig) GetAuthorizationURL() *url.URL {
+	result, _ := url.Parse(p.Config.Endpoint.AuthURL)
+	return result
+}
+
+// HasUserInfoURL always returns true, since the GitLab API takes the place of the userinfo endpoint.
+func (p *ProviderConfig) HasUserInfoURL() bool {
+	return true
+}
+
+func (p *ProviderConfig) GetScopes() []string {
+	return p.Config.Scopes
+}
+
+func (p *ProviderConfig) GetUsernameClaim() string {
+	return UsernameClaim
+}
+
+func (p *ProviderConfig) GetGroupsClaim() string {
+	return GroupsClaim
+}
+
+func (p *ProviderConfig) AllowsPasswordGrant() bool {
+	return false
+}
+
+func (p *ProviderConfig) GetAdditionalAuthcodeParams() map[string]string {
+	return nil
+}
+
+func (p *ProviderConfig) GetAdditionalClaimMappings() map[string]string {
+	return nil
+}
+
+func (p *ProviderConfig) GetClaimExpressions() *claimexpressions.Expressions {
+	return nil
+}
+
+func (p *ProviderConfig) UsesPushedAuthorizationRequests() bool {
+	return false
+}
+
+func (p *ProviderConfig) PushAuthorizationRequest(_ context.Context, _ url.Values) (string, error) {
+	return "", errors.New("pushed authorization requests are not supported for GitLab upstream providers")
+}
+
+func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(_ context.Context, _, _ string) (*oidctypes.Token, error) {
+	return nil, errors.New("resource owner password credentials grant is not allowed for GitLab upstream providers")
+}
+
+// ExchangeAuthcodeAndValidateTokens exchanges the authcode and then computes the user's claims using the GitLab API.
+// The nonce is ignored, since the ID token is not requested.
+func (p *ProviderConfig) ExchangeAuthcodeAndValidateTokens(
+	ctx context.Context,
+	authcode string,
+	pkceCodeVerifier pkce.Code,
+	_ nonce.Nonce,
+	redirectURI string,
+) (*oidctypes.Token, error) {
+	tok, err := p.Config.Exchange(
+		coreosoidc.ClientContext(ctx, p.Client),
+		authcode,
+		pkceCodeVerifier.Verifier(),
+		oauth2.SetAuthURLParam("redirect_uri", redirectURI),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ValidateTokenAndMergeWithUserInfo(ctx, tok, "", false, true)
+}
+
+func (p *ProviderConfig) PerformRefresh(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	// Use the provided HTTP client to benefit from its CA, proxy, and other settings.
+	httpClientContext := coreosoidc.ClientContext(ctx, p.Client)
+	// Create a TokenSource without an access token, so it thinks that a refresh is immediately required.
+	// Then ask it for the tokens to cause it to perform the refresh and return the results.
+	return p.Config.TokenSource(httpClientContext, &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+// RevokeToken revokes the token using the GitLab revocation endpoint, which follows RFC 7009.
+// See https://docs.gitlab.com/ee/api/oauth2.html#revoke-a-token.
+func (p *ProviderConfig) RevokeToken(ctx context.Context, token string, tokenType provider.RevocableTokenType) error {
+	if p.RevocationURL == nil {
+		plog.Trace("RevokeToken() was called but upstream GitLab provider has no revocation endpoint",
+			"providerName", p.Name,
+			"tokenType", tokenType,
+		)
+		return nil
+	}
+
+	params := url.Values{
+		"token":           []string{token},
+		"token_type_hint": []string{string(tokenType)},
+		"client_id":       []string{p.Config.ClientID},
+		"client_secret":   []string{p.Config.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.RevocationURL.String(), strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		// Could be a temporary network problem, so it might be worth retrying.
+		return provider.NewRetryableRevocationError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server responded with status %d", resp.StatusCode)
+	}
+	plog.Trace("RevokeToken() got 200 OK response from GitLab revocation endpoint", "providerName", p.Name)
+	return nil
+}
+
+// ValidateTokenAndMergeWithUserInfo uses the access token to compute the user's claims using the GitLab API.
+// The GitLab API is always called regardless of requireIDToken and requireUserInfo. This is also called during
+// refreshes, so the user's groups are updated from GitLab every time their session is refreshed.
+func (p *ProviderConfig) ValidateTokenAndMergeWithUserInfo(ctx context.Context, tok *oauth2.Token, _ nonce.Nonce, _ bool, _ bool) (*oidctypes.Token, error) {
+	var user struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	if _, err := p.get(ctx, tok, p.apiURL("user", nil), &user); err != nil {
+		return nil, err
+	}
+	if user.Username == "" || user.ID == 0 {
+		return nil, errors.New("GitLab user response is missing username or id")
+	}
+	id := strconv.FormatInt(user.ID, 10)
+
+	var username string
+	switch p.UsernameAttribute {
+	case UsernameAttributeID:
+		username = id
+	case UsernameAttributeEmail:
+		if user.Email == "" {
+			return nil, fmt.Errorf("GitLab user %q does not have a public email address", user.Username)
+		}
+		username = user.Email
+	default:
+		username = user.Username
+	}
+
+	groups, err := p.getGroups(ctx, tok)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{
+		// The ID is only unique for this GitLab instance, so the API base URL is used as the issuer to make
+		// the downstream subject globally unique. The ID is used rather than the username, since users may
+		// change their usernames.
+		oidcapi.IDTokenClaimIssuer:  p.APIBaseURL.String(),
+		oidcapi.IDTokenClaimSubject: id,
+		UsernameClaim:               username,
+		GroupsClaim:                 groups,
+	}
+	plog.Debug("computed claims from GitLab API", "providerName", p.Name, "username", username, "groupsCount", len(groups))
+
+	return &oidctypes.Token{
+		AccessToken: &oidctypes.AccessToken{
+			Token:  tok.AccessToken,
+			Type:   tok.TokenType,
+			Expiry: metav1.NewTime(tok.Expiry),
+		},
+		RefreshToken: &oidctypes.RefreshToken{
+			Token: tok.RefreshToken,
+		},
+		IDToken: &oidctypes.IDToken{
+			Claims: claims,
+		},
+	}, nil
+}
+
+// getGroups returns the full paths of the user's groups and subgroups, sorted and without duplicates.
+func (p *ProviderConfig) getGroups(ctx context.Context, tok *oauth2.Token) ([]string, error) {
+	groups := sets.New[string]()
+	next := p.apiURL("groups", url.Values{"min_access_level": []string{minAccessLevel}})
+	for next != "" {
+		var page []struct {
+			FullPath string `json:"full_path"`
+		}
+		var err error
+		if next, err = p.get(ctx, tok, next, &page); err != nil {
+			return nil, err
+		}
+		for _, group := range page {
+			if group.FullPath != "" {
+				groups.Insert(group.FullPath)
+			}
+		}
+	}
+	return sets.List(groups), nil
+}
+
+func (p *ProviderConfig) apiURL(path string, query url.Values) string {
+	u := p.APIBaseURL.JoinPath(path)
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("per_page", perPage)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// get calls the GitLab API and decodes the JSON response into result. It returns the URL of the next page
+// of results, or an empty string when there are no more pages.
+func (p *ProviderConfig) get(ctx context.Context, tok *oauth2.Token, requestURL string, result interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create GitLab API request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not call GitLab API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read GitLab API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitLab API request to %q failed with status %d with body: %s",
+			req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return "", fmt.Errorf("could not unmarshal GitLab API response from %q: %w", req.URL.Path, err)
+	}
+
+	return p.nextPageURL(resp.Header.Get("Link"))
+}
+
+// nextPageURL parses the "next" URL from a Link header. The URL must be on the same host as the API, since
+// the user's access token will be sent to it.
+// See https://docs.gitlab.com/ee/api/rest/#pagination-link-header.
+func (p *ProviderConfig) nextPageURL(linkHeader string) (string, error) {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		isNext := false
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+		next, err := url.Parse(strings.Trim(strings.TrimSpace(parts[0]), "<>"))
+		if err != nil {
+			return "", fmt.Errorf("could not parse next page URL from GitLab API response: %w", err)
+		}
+		if next.Scheme != p.APIBaseURL.Scheme || next.Host != p.APIBaseURL.Host {
+			return "", fmt.Errorf("next page URL %q from GitLab API response is not on the API host", next.String())
+		}
+		return next.String(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamgitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+func TestProviderConfig(t *testing.T) {
+	t.Run("getters", func(t *testing.T) {
+		p := ProviderConfig{
+			Name:        "test-name",
+			ResourceUID: "test-uid",
+			Config: &oauth2.Config{
+				ClientID: "test-client-id",
+				Endpoint: oauth2.Endpoint{AuthURL: "https://gitlab.com/oauth/authorize"},
+				Scopes:   []string{"read_api"},
+			},
+		}
+		require.Equal(t, "test-name", p.GetName())
+		require.Equal(t, "test-uid", string(p.GetResourceUID()))
+		require.Equal(t, "test-client-id", p.GetClientID())
+		require.Equal(t, "https://gitlab.com/oauth/authorize", p.GetAuthorizationURL().String())
+		require.Equal(t, []string{"read_api"}, p.GetScopes())
+		require.Nil(t, p.GetAdditionalAuthcodeParams())
+		require.Nil(t, p.GetClaimExpressions())
+		require.True(t, p.HasUserInfoURL())
+		require.Equal(t, "username", p.GetUsernameClaim())
+		require.Equal(t, "groups", p.GetGroupsClaim())
+		require.Nil(t, p.GetAdditionalClaimMappings())
+		require.False(t, p.AllowsPasswordGrant())
+		require.False(t, p.UsesPushedAuthorizationRequests())
+
+		_, err := p.PasswordCredentialsGrantAndValidateTokens(context.Background(), "user", "pass")
+		require.EqualError(t, err, "resource owner password credentials grant is not allowed for GitLab upstream providers")
+		_, err = p.PushAuthorizationRequest(context.Background(), url.Values{})
+		require.EqualError(t, err, "pushed authorization requests are not supported for GitLab upstream providers")
+		require.NoError(t, p.RevokeToken(context.Background(), "some-token", "access_token"))
+	})
+
+	t.Run("RevokeToken", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			status     int
+			wantErr    string
+			wantRetry  bool
+			stopServer bool
+		}{
+			{
+				name:   "success",
+				status: http.StatusOK,
+			},
+			{
+				name:    "server returns an error",
+				status:  http.StatusBadRequest,
+				wantErr: "server responded with status 400",
+			},
+			{
+				name:       "server cannot be reached",
+				stopServer: true,
+				wantRetry:  true,
+			},
+		}
+		for _, test := range tests {
+			tt := test
+			t.Run(tt.name, func(t *testing.T) {
+				server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.Equal(t, "/oauth/revoke", r.URL.Path)
+					require.NoError(t, r.ParseForm())
+					require.Equal(t, "some-token", r.Form.Get("token"))
+					require.Equal(t, "refresh_token", r.Form.Get("token_type_hint"))
+					require.Equal(t, "test-client-id", r.Form.Get("client_id"))
+					require.Equal(t, "test-client-secret", r.Form.Get("client_secret"))
+					w.WriteHeader(tt.status)
+				}))
+				t.Cleanup(server.Close)
+				revocationURL, err := url.Parse(server.URL + "/oauth/revoke")
+				require.NoError(t, err)
+				p := ProviderConfig{
+					Name:          "test-name",
+					Config:        &oauth2.Config{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
+					Client:        server.Client(),
+					RevocationURL: revocationURL,
+				}
+				if tt.stopServer {
+					server.Close()
+				}
+
+				err = p.RevokeToken(context.Background(), "some-token", "refresh_token")
+				switch {
+				case tt.wantRetry:
+					require.ErrorAs(t, err, &provider.RetryableRevocationError{})
+				case tt.wantErr != "":
+					require.EqualError(t, err, tt.wantErr)
+				default:
+					require.NoError(t, err)
+				}
+			})
+		}
+	})
+
+	t.Run("ExchangeAuthcodeAndValidateTokens", func(t *testing.T) {
+		tests := []struct {
+			name              string
+			usernameAttribute string
+			userStatus        int
+			userResponse      string
+			nextPageHost      string
+
+			wantUsername string
+			wantErr      string
+		}{
+			{
+				name:         "uses the username as the username by default",
+				userResponse: `{"username":"tanuki","id":1234,"email":"tanuki@example.com"}`,
+				wantUsername: "tanuki",
+			},
+			{
+				name:              "uses id as the username",
+				usernameAttribute: "id",
+				userResponse:      `{"username":"tanuki","id":1234,"email":"tanuki@example.com"}`,
+				wantUsername:      "1234",
+			},
+			{
+				name:              "uses email as the username",
+				usernameAttribute: "email",
+				userResponse:      `{"username":"tanuki","id":1234,"email":"tanuki@example.com"}`,
+				wantUsername:      "tanuki@example.com",
+			},
+			{
+				name:              "uses email as the username but the user has no public email",
+				usernameAttribute: "email",
+				userResponse:      `{"username":"tanuki","id":1234,"email":""}`,
+				wantErr:           `GitLab user "tanuki" does not have a public email address`,
+			},
+			{
+				name:         "user endpoint returns an error",
+				userStatus:   http.StatusUnauthorized,
+				userResponse: `{"message":"401 Unauthorized"}`,
+				wantErr:      `GitLab API request to "/api/v4/user" failed with status 401 with body: {"message":"401 Unauthorized"}`,
+			},
+			{
+				name:         "user endpoint returns a response without an id",
+				userResponse: `{"username":"tanuki"}`,
+				wantErr:      "GitLab user response is missing username or id",
+			},
+			{
+				name:         "next page link is on another host",
+				userResponse: `{"username":"tanuki","id":1234}`,
+				nextPageHost: "evil.example.com",
+				wantErr:      `next page URL "https://evil.example.com/api/v4/groups?page=2" from GitLab API response is not on the API host`,
+			},
+		}
+		for _, test := range tests {
+			tt := test
+			t.Run(tt.name, func(t *testing.T) {
+				mux := http.NewServeMux()
+				mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.NoError(t, r.ParseForm())
+					require.Equal(t, "authorization_code", r.Form.Get("grant_type"))
+					require.Equal(t, "test-authcode", r.Form.Get("code"))
+					require.Equal(t, "test-pkce", r.Form.Get("code_verifier"))
+					require.Equal(t, "https://example.com/callback", r.Form.Get("redirect_uri"))
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"test-access-token","token_type":"Bearer","refresh_token":"test-refresh-token"}`))
+				})
+				mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, "Bearer test-access-token", r.Header.Get("Authorization"))
+					if tt.userStatus != 0 {
+						w.WriteHeader(tt.userStatus)
+					}
+					_, _ = w.Write([]byte(tt.userResponse))
+				})
+				mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, "Bearer test-access-token", r.Header.Get("Authorization"))
+					if r.URL.Query().Get("page") == "2" {
+						_, _ = w.Write([]byte(`[{"full_path":"group-b"}]`))
+						return
+					}
+					require.Equal(t, "100", r.URL.Query().Get("per_page"))
+					require.Equal(t, "10", r.URL.Query().Get("min_access_level"))
+					nextPageHost := tt.nextPageHost
+					if nextPageHost == "" {
+						nextPageHost = r.Host
+					}
+					w.Header().Set("Link", fmt.Sprintf(`<https://%s/api/v4/groups?page=2>; rel="next", <https://%s/api/v4/groups?page=2>; rel="last"`, nextPageHost, nextPageHost))
+					_, _ = w.Write([]byte(`[{"full_path":"group-a/subgroup"},{"full_path":"group-a"}]`))
+				})
+				server := httptest.NewTLSServer(mux)
+				t.Cleanup(server.Close)
+				serverURL := server.URL
+
+				apiBaseURL, err := url.Parse(serverURL + "/api/v4")
+				require.NoError(t, err)
+				p := ProviderConfig{
+					Name: "test-name",
+					Config: &oauth2.Config{
+						ClientID:     "test-client-id",
+						ClientSecret: "test-client-secret",
+						Endpoint:     oauth2.Endpoint{TokenURL: serverURL + "/oauth/token", AuthStyle: oauth2.AuthStyleInParams},
+					},
+					Client:            server.Client(),
+					APIBaseURL:        apiBaseURL,
+					UsernameAttribute: tt.usernameAttribute,
+				}
+
+				tok, err := p.ExchangeAuthcodeAndValidateTokens(context.Background(), "test-authcode", pkce.Code("test-pkce"), "test-nonce", "https://example.com/callback")
+				if tt.wantErr != "" {
+					require.EqualError(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, "test-access-token", tok.AccessToken.Token)
+				require.Equal(t, "test-refresh-token", tok.RefreshToken.Token)
+				require.Empty(t, tok.IDToken.Token)
+				require.Equal(t, map[string]interface{}{
+					"iss":      apiBaseURL.String(),
+					"sub":      "1234",
+					"username": tt.wantUsername,
+					"groups":   []string{"group-a", "group-a/subgroup", "group-b"},
+				}, tok.IDToken.Claims)
+			})
+		}
+	})
+}
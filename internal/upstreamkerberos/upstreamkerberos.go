@@ -0,0 +1,179 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package upstreamkerberos implements an abstraction of upstream Kerberos realm interactions.
+package upstreamkerberos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+)
+
+// maxClockSkew is the maximum difference between the clocks of the client and the Supervisor which is tolerated
+// when validating the authenticator of a service ticket. This is the customary default of Kerberos implementations.
+const maxClockSkew = 5 * time.Minute
+
+// GroupSearchFunc searches for the groups of an authenticated user by their username.
+type GroupSearchFunc func(ctx context.Context, username string, grantedScopes []string) ([]string, error)
+
+// ProviderConfig holds the active configuration of an upstream Kerberos realm.
+type ProviderConfig struct {
+	Name        string
+	ResourceUID types.UID
+
+	// ServicePrincipalName is the name of the Supervisor's service principal without the realm,
+	// e.g. "HTTP/supervisor.example.com".
+	ServicePrincipalName string
+
+	// Keytab contains the keys of the service principal.
+	Keytab *keytab.Keytab
+
+	// AllowedRealms lists the realms of the users who are allowed to log in.
+	AllowedRealms []string
+
+	// UsernameFormat determines how the user's principal is mapped to their username.
+	UsernameFormat idpv1alpha1.KerberosUsernameFormat
+
+	// GroupSearch searches for the user's groups. When it is nil, users do not have any groups.
+	GroupSearch GroupSearchFunc
+}
+
+var _ provider.UpstreamKerberosIdentityProviderI = (*ProviderConfig)(nil)
+
+func (p *ProviderConfig) GetName() string {
+	return p.Name
+}
+
+func (p *ProviderConfig) GetResourceUID() types.UID {
+	return p.ResourceUID
+}
+
+// AuthenticateNegotiateToken validates the Kerberos service ticket contained in the SPNEGO token using the keytab.
+// Raw Kerberos tokens which are not wrapped in SPNEGO are also accepted. The returned user's UID is the user's
+// full principal name, e.g. "alice@EXAMPLE.COM", regardless of the username format.
+func (p *ProviderConfig) AuthenticateNegotiateToken(ctx context.Context, negotiateToken []byte, grantedScopes []string) (*authenticators.Response, error) {
+	krb5Token, err := unwrapKRB5Token(negotiateToken)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := service.NewSettings(p.Keytab,
+		service.KeytabPrincipal(p.ServicePrincipalName),
+		service.MaxClockSkew(maxClockSkew),
+		// Group memberships are searched using the configured group search rather than the PAC.
+		service.DecodePAC(false),
+	)
+	ok, creds, err := service.VerifyAPREQ(&krb5Token.APReq, settings)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify Kerberos service ticket: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("could not verify Kerberos service ticket")
+	}
+
+	shortName, realm := creds.UserName(), creds.Domain()
+	if shortName == "" || realm == "" {
+		return nil, errors.New("the Kerberos service ticket is missing the client principal name or realm")
+	}
+	if !sets.New(p.AllowedRealms...).Has(realm) {
+		return nil, fmt.Errorf("realm %q is not an allowed Kerberos realm", realm)
+	}
+	principal := shortName + "@" + realm
+
+	username := principal
+	if p.UsernameFormat == idpv1alpha1.KerberosUsernameShortName {
+		username = shortName
+	}
+
+	groups, err := p.searchGroups(ctx, username, grantedScopes)
+	if err != nil {
+		return nil, err
+	}
+	plog.Debug("authenticated Kerberos user", "providerName", p.Name, "principal", principal, "groupsCount", len(groups))
+
+	return &authenticators.Response{
+		User: &user.DefaultInfo{
+			Name:   username,
+			UID:    principal,
+			Groups: groups,
+		},
+	}, nil
+}
+
+// PerformRefresh searches for the user's current groups when a group search is configured. Otherwise, the user's
+// groups cannot change, so the stored groups are returned unchanged.
+func (p *ProviderConfig) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, error) {
+	if p.GroupSearch == nil {
+		return storedRefreshAttributes.Groups, nil
+	}
+	return p.searchGroups(ctx, storedRefreshAttributes.Username, storedRefreshAttributes.GrantedScopes)
+}
+
+func (p *ProviderConfig) searchGroups(ctx context.Context, username string, grantedScopes []string) ([]string, error) {
+	if p.GroupSearch == nil {
+		return []string{}, nil
+	}
+	groups, err := p.GroupSearch(ctx, username, grantedScopes)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for the groups of user %q: %w", username, err)
+	}
+	return groups, nil
+}
+
+// ParseKeytab parses the keytab and checks that it contains at least one key for the service principal.
+func ParseKeytab(keytabBytes []byte, servicePrincipalName string) (*keytab.Keytab, error) {
+	kt := keytab.New()
+	if err := kt.Unmarshal(keytabBytes); err != nil {
+		return nil, fmt.Errorf("could not parse keytab: %w", err)
+	}
+	for _, e := range kt.Entries {
+		if strings.Join(e.Principal.Components, "/") == servicePrincipalName {
+			return kt, nil
+		}
+	}
+	return nil, fmt.Errorf("keytab does not contain any keys for service principal %q", servicePrincipalName)
+}
+
+// unwrapKRB5Token returns the Kerberos AP-REQ token contained in the SPNEGO token. Browsers always wrap the
+// Kerberos token in SPNEGO, but some other clients send raw Kerberos tokens, so those are accepted too.
+func unwrapKRB5Token(negotiateToken []byte) (*spnego.KRB5Token, error) {
+	var spnegoToken spnego.SPNEGOToken
+	mechTokenBytes := negotiateToken
+	if err := spnegoToken.Unmarshal(negotiateToken); err == nil {
+		if !spnegoToken.Init {
+			return nil, errors.New("SPNEGO token is not a NegTokenInit")
+		}
+		mechTypes := spnegoToken.NegTokenInit.MechTypes
+		if len(mechTypes) == 0 ||
+			!(mechTypes[0].Equal(gssapi.OIDKRB5.OID()) || mechTypes[0].Equal(gssapi.OIDMSLegacyKRB5.OID())) {
+			// Browsers fall back to NTLM when they cannot get a Kerberos service ticket, which is not supported.
+			return nil, errors.New("SPNEGO token does not use the Kerberos mechanism, which could mean that the client could not get a Kerberos service ticket")
+		}
+		mechTokenBytes = spnegoToken.NegTokenInit.MechTokenBytes
+	}
+
+	var krb5Token spnego.KRB5Token
+	if err := krb5Token.Unmarshal(mechTokenBytes); err != nil {
+		return nil, fmt.Errorf("could not parse Kerberos token: %w", err)
+	}
+	if !krb5Token.IsAPReq() {
+		return nil, errors.New("the Kerberos token is not an AP-REQ")
+	}
+	return &krb5Token, nil
+}
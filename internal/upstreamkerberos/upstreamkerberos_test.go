@@ -0,0 +1,224 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamkerberos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+const (
+	testSPN   = "HTTP/supervisor.example.com"
+	testRealm = "EXAMPLE.COM"
+)
+
+func TestProviderConfig(t *testing.T) {
+	t.Run("getters", func(t *testing.T) {
+		p := ProviderConfig{
+			Name:        "test-name",
+			ResourceUID: "test-uid",
+		}
+		require.Equal(t, "test-name", p.GetName())
+		require.Equal(t, "test-uid", string(p.GetResourceUID()))
+	})
+
+	t.Run("AuthenticateNegotiateToken", func(t *testing.T) {
+		kt := newTestKeytab(t, "some-password")
+		otherKT := newTestKeytab(t, "some-other-password")
+
+		tests := []struct {
+			name           string
+			token          func(t *testing.T) []byte
+			usernameFormat idpv1alpha1.KerberosUsernameFormat
+			groupSearch    GroupSearchFunc
+			wantResponse   *authenticators.Response
+			wantErr        string
+		}{
+			{
+				name:  "SPNEGO token with principal username format and no group search",
+				token: func(t *testing.T) []byte { return newTestNegotiateToken(t, kt, "alice", testRealm, true) },
+				wantResponse: &authenticators.Response{
+					User: &user.DefaultInfo{Name: "alice@EXAMPLE.COM", UID: "alice@EXAMPLE.COM", Groups: []string{}},
+				},
+			},
+			{
+				name:           "SPNEGO token with short name username format and group search",
+				token:          func(t *testing.T) []byte { return newTestNegotiateToken(t, kt, "bob", testRealm, true) },
+				usernameFormat: idpv1alpha1.KerberosUsernameShortName,
+				groupSearch: func(_ context.Context, username string, grantedScopes []string) ([]string, error) {
+					require.Equal(t, "bob", username)
+					require.Equal(t, []string{"groups"}, grantedScopes)
+					return []string{"group1", "group2"}, nil
+				},
+				wantResponse: &authenticators.Response{
+					User: &user.DefaultInfo{Name: "bob", UID: "bob@EXAMPLE.COM", Groups: []string{"group1", "group2"}},
+				},
+			},
+			{
+				name:  "raw Kerberos token",
+				token: func(t *testing.T) []byte { return newTestNegotiateToken(t, kt, "carol", testRealm, false) },
+				wantResponse: &authenticators.Response{
+					User: &user.DefaultInfo{Name: "carol@EXAMPLE.COM", UID: "carol@EXAMPLE.COM", Groups: []string{}},
+				},
+			},
+			{
+				name:  "group search fails",
+				token: func(t *testing.T) []byte { return newTestNegotiateToken(t, kt, "dave", testRealm, true) },
+				groupSearch: func(_ context.Context, _ string, _ []string) ([]string, error) {
+					return nil, errors.New("some group search error")
+				},
+				wantErr: `could not search for the groups of user "dave@EXAMPLE.COM": some group search error`,
+			},
+			{
+				name:    "realm is not allowed",
+				token:   func(t *testing.T) []byte { return newTestNegotiateToken(t, kt, "eve", "OTHER.EXAMPLE.COM", true) },
+				wantErr: `realm "OTHER.EXAMPLE.COM" is not an allowed Kerberos realm`,
+			},
+			{
+				name:    "ticket is encrypted with a different key",
+				token:   func(t *testing.T) []byte { return newTestNegotiateToken(t, otherKT, "frank", testRealm, true) },
+				wantErr: "could not verify Kerberos service ticket: ",
+			},
+			{
+				name: "SPNEGO token uses NTLM",
+				token: func(t *testing.T) []byte {
+					spnegoToken := spnego.SPNEGOToken{
+						Init: true,
+						NegTokenInit: spnego.NegTokenInit{
+							MechTypes:      []asn1.ObjectIdentifier{{1, 3, 6, 1, 4, 1, 311, 2, 2, 10}},
+							MechTokenBytes: []byte("NTLMSSP"),
+						},
+					}
+					b, err := spnegoToken.Marshal()
+					require.NoError(t, err)
+					return b
+				},
+				wantErr: "SPNEGO token does not use the Kerberos mechanism, which could mean that the client could not get a Kerberos service ticket",
+			},
+			{
+				name:    "token is not valid",
+				token:   func(t *testing.T) []byte { return []byte("not a token") },
+				wantErr: "could not parse Kerberos token: ",
+			},
+		}
+		for _, test := range tests {
+			tt := test
+			t.Run(tt.name, func(t *testing.T) {
+				p := &ProviderConfig{
+					Name:                 "test-name",
+					ServicePrincipalName: testSPN,
+					Keytab:               kt,
+					AllowedRealms:        []string{testRealm},
+					UsernameFormat:       tt.usernameFormat,
+					GroupSearch:          tt.groupSearch,
+				}
+				response, err := p.AuthenticateNegotiateToken(context.Background(), tt.token(t), []string{"groups"})
+				if tt.wantErr != "" {
+					require.ErrorContains(t, err, tt.wantErr)
+					require.Nil(t, response)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.wantResponse, response)
+			})
+		}
+	})
+
+	t.Run("PerformRefresh", func(t *testing.T) {
+		storedAttributes := provider.RefreshAttributes{
+			Username:      "alice",
+			Groups:        []string{"old-group"},
+			GrantedScopes: []string{"groups"},
+		}
+
+		p := &ProviderConfig{}
+		groups, err := p.PerformRefresh(context.Background(), storedAttributes)
+		require.NoError(t, err)
+		require.Equal(t, []string{"old-group"}, groups)
+
+		p.GroupSearch = func(_ context.Context, username string, grantedScopes []string) ([]string, error) {
+			require.Equal(t, "alice", username)
+			require.Equal(t, []string{"groups"}, grantedScopes)
+			return []string{"new-group"}, nil
+		}
+		groups, err = p.PerformRefresh(context.Background(), storedAttributes)
+		require.NoError(t, err)
+		require.Equal(t, []string{"new-group"}, groups)
+
+		p.GroupSearch = func(_ context.Context, _ string, _ []string) ([]string, error) {
+			return nil, errors.New("some group search error")
+		}
+		_, err = p.PerformRefresh(context.Background(), storedAttributes)
+		require.EqualError(t, err, `could not search for the groups of user "alice": some group search error`)
+	})
+}
+
+func TestParseKeytab(t *testing.T) {
+	keytabBytes, err := newTestKeytab(t, "some-password").Marshal()
+	require.NoError(t, err)
+
+	kt, err := ParseKeytab(keytabBytes, testSPN)
+	require.NoError(t, err)
+	require.Len(t, kt.Entries, 1)
+
+	_, err = ParseKeytab(keytabBytes, "HTTP/other.example.com")
+	require.EqualError(t, err, `keytab does not contain any keys for service principal "HTTP/other.example.com"`)
+
+	_, err = ParseKeytab([]byte("not a keytab"), testSPN)
+	require.ErrorContains(t, err, "could not parse keytab: ")
+}
+
+func newTestKeytab(t *testing.T, password string) *keytab.Keytab {
+	t.Helper()
+	kt := keytab.New()
+	require.NoError(t, kt.AddEntry(testSPN, testRealm, password, time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96))
+	return kt
+}
+
+// newTestNegotiateToken returns a token like the ones which clients send after getting a service ticket for
+// the service principal from the KDC, but the service ticket is created directly using the keytab instead.
+func newTestNegotiateToken(t *testing.T, kt *keytab.Keytab, username, realm string, wrapInSPNEGO bool) []byte {
+	t.Helper()
+	cl := client.NewWithPassword(username, realm, "unused-password", config.New())
+	sname := types.NewPrincipalName(nametype.KRB_NT_SRV_INST, testSPN)
+	now := time.Now().UTC()
+	tkt, sessionKey, err := messages.NewTicket(cl.Credentials.CName(), cl.Credentials.Domain(),
+		sname, testRealm, types.NewKrbFlags(), kt, etypeID.AES256_CTS_HMAC_SHA1_96, 1,
+		now, now, now.Add(time.Hour), now.Add(2*time.Hour),
+	)
+	require.NoError(t, err)
+
+	if !wrapInSPNEGO {
+		krb5Token, err := spnego.NewKRB5TokenAPREQ(cl, tkt, sessionKey, nil, nil)
+		require.NoError(t, err)
+		b, err := krb5Token.Marshal()
+		require.NoError(t, err)
+		return b
+	}
+
+	negTokenInit, err := spnego.NewNegTokenInitKRB5(cl, tkt, sessionKey)
+	require.NoError(t, err)
+	spnegoToken := spnego.SPNEGOToken{Init: true, NegTokenInit: negTokenInit}
+	b, err := spnegoToken.Marshal()
+	require.NoError(t, err)
+	return b
+}
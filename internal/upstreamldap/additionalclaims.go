@@ -0,0 +1,58 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"sort"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// additionalClaimAttributes returns the names of the attributes which are mapped to additional claims, so they can be
+// requested during the user search. The names are sorted and de-duplicated so that the search request is stable.
+func (p *Provider) additionalClaimAttributes() []string {
+	attributes := make([]string, 0, len(p.c.AdditionalClaimMappings))
+	seen := map[string]bool{}
+	for _, attributeName := range p.c.AdditionalClaimMappings {
+		if attributeName == distinguishedNameAttributeName || seen[attributeName] {
+			continue
+		}
+		seen[attributeName] = true
+		attributes = append(attributes, attributeName)
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+// additionalClaims returns the values of the attributes of the user's entry which are mapped to additional claims,
+// keyed by claim name. Attributes with a single value become string claims, and attributes with multiple values
+// become string array claims. Attributes which are not present on the user's entry are skipped, since not every
+// user is expected to have every attribute.
+func (p *Provider) additionalClaims(entry *ldap.Entry) map[string]interface{} {
+	if len(p.c.AdditionalClaimMappings) == 0 {
+		return nil
+	}
+
+	claims := make(map[string]interface{}, len(p.c.AdditionalClaimMappings))
+	for claimName, attributeName := range p.c.AdditionalClaimMappings {
+		if attributeName == distinguishedNameAttributeName {
+			claims[claimName] = entry.DN
+			continue
+		}
+
+		values := entry.GetAttributeValues(attributeName)
+		switch len(values) {
+		case 0:
+			plog.Debug("additionalClaims mapping attribute missing from user entry",
+				"upstreamName", p.GetName(), "attributeName", attributeName, "dn", entry.DN)
+		case 1:
+			claims[claimName] = values[0]
+		default:
+			claims[claimName] = values
+		}
+	}
+	return claims
+}
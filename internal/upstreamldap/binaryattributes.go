@@ -0,0 +1,81 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+)
+
+// BinaryAttributeDecoder determines how the value of a binary attribute is converted into a string. The decoders have
+// the same names as in the LDAPIdentityProvider API.
+type BinaryAttributeDecoder string
+
+const (
+	// BinaryAttributeDecoderUUID formats a 16 byte value as a UUID.
+	BinaryAttributeDecoderUUID = BinaryAttributeDecoder("UUID")
+
+	// BinaryAttributeDecoderUUIDLittleEndian formats a 16 byte value as a UUID whose first three fields are stored
+	// in little-endian byte order, like the objectGUID attribute of Active Directory.
+	BinaryAttributeDecoderUUIDLittleEndian = BinaryAttributeDecoder("UUIDLittleEndian")
+
+	// BinaryAttributeDecoderBase64 encodes the value using standard base64.
+	BinaryAttributeDecoderBase64 = BinaryAttributeDecoder("Base64")
+
+	// BinaryAttributeDecoderHex encodes the value using lowercase hexadecimal.
+	BinaryAttributeDecoderHex = BinaryAttributeDecoder("Hex")
+)
+
+// BinaryAttributeParser returns a function which decodes the binary value of the named attribute of an entry, for use
+// in the UIDAttributeParsingOverrides and GroupAttributeParsingOverrides of a ProviderConfig.
+func BinaryAttributeParser(attributeName string, decoder BinaryAttributeDecoder) func(*ldap.Entry) (string, error) {
+	return func(entry *ldap.Entry) (string, error) {
+		attributeValues := entry.GetRawAttributeValues(attributeName)
+		if len(attributeValues) != 1 {
+			return "", fmt.Errorf(`found %d values for attribute %q, but expected 1 result`,
+				len(attributeValues), attributeName,
+			)
+		}
+		if len(attributeValues[0]) == 0 {
+			return "", fmt.Errorf(`found empty value for attribute %q, but expected value to be non-empty`,
+				attributeName,
+			)
+		}
+		decoded, err := decoder.decode(attributeValues[0])
+		if err != nil {
+			return "", fmt.Errorf(`could not decode value of attribute %q: %w`, attributeName, err)
+		}
+		return decoded, nil
+	}
+}
+
+func (d BinaryAttributeDecoder) decode(value []byte) (string, error) {
+	switch d {
+	case BinaryAttributeDecoderUUID:
+		uuidVal, err := uuid.FromBytes(value)
+		if err != nil {
+			return "", err
+		}
+		return uuidVal.String(), nil
+	case BinaryAttributeDecoderUUIDLittleEndian:
+		uuidVal, err := uuid.FromBytes(value)
+		if err != nil {
+			return "", err
+		}
+		uuidVal[0], uuidVal[1], uuidVal[2], uuidVal[3] = uuidVal[3], uuidVal[2], uuidVal[1], uuidVal[0]
+		uuidVal[4], uuidVal[5] = uuidVal[5], uuidVal[4]
+		uuidVal[6], uuidVal[7] = uuidVal[7], uuidVal[6]
+		return uuidVal.String(), nil
+	case BinaryAttributeDecoderBase64:
+		return base64.StdEncoding.EncodeToString(value), nil
+	case BinaryAttributeDecoderHex:
+		return hex.EncodeToString(value), nil
+	default:
+		return "", fmt.Errorf(`unknown decoder %q`, d)
+	}
+}
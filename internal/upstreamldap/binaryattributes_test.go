@@ -0,0 +1,99 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryAttributeParser(t *testing.T) {
+	guid := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+
+	tests := []struct {
+		name    string
+		decoder BinaryAttributeDecoder
+		values  [][]byte
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "uuid",
+			decoder: BinaryAttributeDecoderUUID,
+			values:  [][]byte{guid},
+			want:    "01020304-0506-0708-090a-0b0c0d0e0f10",
+		},
+		{
+			name:    "little-endian uuid",
+			decoder: BinaryAttributeDecoderUUIDLittleEndian,
+			values:  [][]byte{guid},
+			want:    "04030201-0605-0807-090a-0b0c0d0e0f10",
+		},
+		{
+			name:    "uuid with the wrong length",
+			decoder: BinaryAttributeDecoderUUID,
+			values:  [][]byte{guid[:8]},
+			wantErr: `could not decode value of attribute "attr": invalid UUID (got 8 bytes)`,
+		},
+		{
+			name:    "little-endian uuid with the wrong length",
+			decoder: BinaryAttributeDecoderUUIDLittleEndian,
+			values:  [][]byte{guid[:8]},
+			wantErr: `could not decode value of attribute "attr": invalid UUID (got 8 bytes)`,
+		},
+		{
+			name:    "base64",
+			decoder: BinaryAttributeDecoderBase64,
+			values:  [][]byte{{0xfb, 0xff, 0x01}},
+			want:    "+/8B",
+		},
+		{
+			name:    "hex",
+			decoder: BinaryAttributeDecoderHex,
+			values:  [][]byte{{0xfb, 0xff, 0x01}},
+			want:    "fbff01",
+		},
+		{
+			name:    "missing attribute",
+			decoder: BinaryAttributeDecoderHex,
+			wantErr: `found 0 values for attribute "attr", but expected 1 result`,
+		},
+		{
+			name:    "multiple values",
+			decoder: BinaryAttributeDecoderHex,
+			values:  [][]byte{{0x01}, {0x02}},
+			wantErr: `found 2 values for attribute "attr", but expected 1 result`,
+		},
+		{
+			name:    "empty value",
+			decoder: BinaryAttributeDecoderHex,
+			values:  [][]byte{{}},
+			wantErr: `found empty value for attribute "attr", but expected value to be non-empty`,
+		},
+		{
+			name:    "unknown decoder",
+			decoder: "Whatever",
+			values:  [][]byte{guid},
+			wantErr: `could not decode value of attribute "attr": unknown decoder "Whatever"`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &ldap.Entry{DN: "cn=pinny,ou=users,dc=pinniped,dc=dev"}
+			if tt.values != nil {
+				entry.Attributes = []*ldap.EntryAttribute{{Name: "attr", ByteValues: tt.values}}
+			}
+			got, err := BinaryAttributeParser("attr", tt.decoder)(entry)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
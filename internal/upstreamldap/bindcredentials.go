@@ -0,0 +1,48 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"fmt"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// hasSecondaryBindCredentials returns true when secondary bind credentials were configured.
+func (p *Provider) hasSecondaryBindCredentials() bool {
+	return len(p.c.SecondaryBindUsername) > 0
+}
+
+// bindAsServiceAccount binds using the primary bind credentials. When that fails and secondary bind credentials were
+// configured, then it tries again using the secondary bind credentials. This allows the bind account's password to be
+// rotated without interrupting logins, since only one of the two credentials needs to work at any given time.
+func (p *Provider) bindAsServiceAccount(conn Conn) error {
+	err := conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	if err == nil {
+		p.usingSecondaryBindCredentials.Store(false)
+		return nil
+	}
+
+	if !p.hasSecondaryBindCredentials() {
+		return err
+	}
+
+	secondaryErr := conn.Bind(p.c.SecondaryBindUsername, p.c.SecondaryBindPassword)
+	if secondaryErr != nil {
+		return fmt.Errorf(`%w (binding as %q using the secondary bind credentials also failed: %s)`,
+			err, p.c.SecondaryBindUsername, secondaryErr.Error())
+	}
+
+	plog.DebugErr("binding using the primary bind credentials failed, so used the secondary bind credentials", err,
+		"upstreamName", p.GetName(), "secondaryBindUsername", p.c.SecondaryBindUsername)
+	p.usingSecondaryBindCredentials.Store(true)
+	return nil
+}
+
+// UsingSecondaryBindCredentials returns true when the most recent successful bind as the bind account used the
+// secondary bind credentials, or false when it used the primary bind credentials or when there has been no
+// successful bind yet.
+func (p *Provider) UsingSecondaryBindCredentials() bool {
+	return p.usingSecondaryBindCredentials.Load()
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+)
+
+func TestBindAsServiceAccount(t *testing.T) {
+	const (
+		testSecondaryBindUsername = "cn=some-secondary-bind-username,dc=pinniped,dc=dev"
+		testSecondaryBindPassword = "some-secondary-bind-password"
+	)
+
+	tests := []struct {
+		name                    string
+		withSecondary           bool
+		setupMocks              func(conn *mockldapconn.MockConn)
+		wantErr                 string
+		wantUsingSecondaryCreds bool
+	}{
+		{
+			name: "binding with the primary bind credentials succeeds",
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+			},
+		},
+		{
+			name:          "binding with the primary bind credentials succeeds, so the secondary bind credentials are not tried",
+			withSecondary: true,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+			},
+		},
+		{
+			name: "binding with the primary bind credentials fails and there are no secondary bind credentials",
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Return(errors.New("some bind error")).Times(1)
+			},
+			wantErr: "some bind error",
+		},
+		{
+			name:          "binding with the primary bind credentials fails and binding with the secondary bind credentials succeeds",
+			withSecondary: true,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Return(errors.New("some bind error")).Times(1)
+				conn.EXPECT().Bind(testSecondaryBindUsername, testSecondaryBindPassword).Times(1)
+			},
+			wantUsingSecondaryCreds: true,
+		},
+		{
+			name:          "binding with both the primary and the secondary bind credentials fails",
+			withSecondary: true,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Return(errors.New("some bind error")).Times(1)
+				conn.EXPECT().Bind(testSecondaryBindUsername, testSecondaryBindPassword).Return(errors.New("some other bind error")).Times(1)
+			},
+			wantErr: `some bind error (binding as "cn=some-secondary-bind-username,dc=pinniped,dc=dev" using the secondary bind credentials also failed: some other bind error)`,
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			conn := mockldapconn.NewMockConn(ctrl)
+			tt.setupMocks(conn)
+
+			config := ProviderConfig{
+				Name:         "some-provider-name",
+				BindUsername: testBindUsername,
+				BindPassword: testBindPassword,
+			}
+			if tt.withSecondary {
+				config.SecondaryBindUsername = testSecondaryBindUsername
+				config.SecondaryBindPassword = testSecondaryBindPassword
+			}
+			ldapProvider := New(config)
+
+			err := ldapProvider.bindAsServiceAccount(conn)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantUsingSecondaryCreds, ldapProvider.UsingSecondaryBindCredentials())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/plog"
+)
+
+// UserDNTemplateUsernamePlaceholder is replaced by the end user's username in the UserDNTemplate.
+const UserDNTemplateUsernamePlaceholder = "{username}"
+
+// usingDirectBind returns true when logins bind directly as the end user instead of searching for the user's entry.
+func (p *Provider) usingDirectBind() bool {
+	return len(p.c.UserDNTemplate) > 0
+}
+
+// hasBindAccount returns true when there are credentials for binding as the bind account. There may be none
+// when logins bind directly as the end user.
+func (p *Provider) hasBindAccount() bool {
+	return len(p.c.BindUsername) > 0
+}
+
+// userDN returns the DN of the end user with the given username according to the UserDNTemplate.
+func (p *Provider) userDN(username string) string {
+	// The username is end user input, so it must be escaped before being included in a DN to prevent
+	// the user from choosing a different DN.
+	return strings.ReplaceAll(p.c.UserDNTemplate, UserDNTemplateUsernamePlaceholder, escapeDNAttributeValue(username))
+}
+
+// bindAndReadUser binds as the end user using the DN from the UserDNTemplate, and then reads the user's entry
+// and searches for the user's groups as that user. It is used instead of searchAndBindUser when there is a
+// UserDNTemplate.
+func (p *Provider) bindAndReadUser(ctx context.Context, conn Conn, username string, grantedScopes []string, bindFunc func(conn Conn, foundUserDN string) error) (*authenticators.Response, error) {
+	userDN := p.userDN(username)
+
+	// Caution: Note that any other LDAP commands after this bind will be run as this user!
+	err := bindFunc(conn, userDN)
+	if err != nil {
+		plog.DebugErr("error binding for user (if this is not the expected dn for this username, please check the user DN template)",
+			err, "upstreamName", p.GetName(), "username", username, "dn", userDN)
+		ldapErr := &ldap.Error{}
+		if errors.As(err, &ldapErr) && ldapErr.ResultCode == ldap.LDAPResultInvalidCredentials {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(`error binding for user %q using provided password against DN %q: %w`, username, userDN, err)
+	}
+
+	userEntries, err := p.performUserRefreshSearch(ctx, conn, userDN)
+	if err != nil {
+		return nil, err
+	}
+	if len(userEntries) != 1 {
+		return nil, fmt.Errorf(`searching for user %q resulted in %d search results, but expected 1 result`,
+			username, len(userEntries),
+		)
+	}
+	userEntry := userEntries[0].Entry
+	if len(userEntry.DN) == 0 {
+		return nil, fmt.Errorf(`searching for user %q resulted in search result without DN`, username)
+	}
+
+	response, err := p.responseForUserEntry(ctx, conn, userEntry, username, grantedScopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.User.GetName()) == 0 || len(response.User.GetUID()) == 0 {
+		return nil, nil
+	}
+
+	return response, nil
+}
+
+// escapeDNAttributeValue escapes a string for use as an attribute value in a DN, as described in RFC 4514 section 2.4.
+func escapeDNAttributeValue(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\x00':
+			b.WriteString(`\00`)
+			continue
+		case strings.ContainsRune(`"+,;<=>\`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(value)-1 && r == ' ':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+func TestDirectBindAuthentication(t *testing.T) {
+	const (
+		testUserDNTemplate = "uid={username},ou=users,dc=pinniped,dc=dev"
+		testUserDN         = "uid=some-upstream-username,ou=users,dc=pinniped,dc=dev"
+	)
+
+	expectedUserEntryRead := &ldap.SearchRequest{
+		BaseDN:       testUserDN,
+		Scope:        ldap.ScopeBaseObject,
+		DerefAliases: ldap.NeverDerefAliases,
+		SizeLimit:    2,
+		TimeLimit:    90,
+		Filter:       "(objectClass=*)",
+		Attributes:   []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute},
+	}
+
+	expectedGroupSearch := &ldap.SearchRequest{
+		BaseDN:       testGroupSearchBase,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		TimeLimit:    90,
+		Filter:       "(member=" + testUserDN + ")",
+		Attributes:   []string{testGroupSearchGroupNameAttribute},
+	}
+
+	userEntryResult := &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			{
+				DN: testUserDN,
+				Attributes: []*ldap.EntryAttribute{
+					ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+					ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+				},
+			},
+		},
+	}
+
+	groupSearchResult := &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			{
+				DN: testGroupSearchResultDNValue1,
+				Attributes: []*ldap.EntryAttribute{
+					ldap.NewEntryAttribute(testGroupSearchGroupNameAttribute, []string{testGroupSearchResultGroupNameAttributeValue1}),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		username            string
+		grantedScopes       []string
+		setupMocks          func(conn *mockldapconn.MockConn)
+		wantError           string
+		wantUnauthenticated bool
+		wantAuthResponse    *authenticators.Response
+	}{
+		{
+			name:          "happy path binds as the end user before reading their entry and searching for their groups",
+			username:      testUpstreamUsername,
+			grantedScopes: []string{"groups"},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				gomock.InOrder(
+					conn.EXPECT().Bind(testUserDN, testUpstreamPassword).Times(1),
+					conn.EXPECT().Search(expectedUserEntryRead).Return(userEntryResult, nil).Times(1),
+					conn.EXPECT().SearchWithPaging(expectedGroupSearch, expectedGroupSearchPageSize).Return(groupSearchResult, nil).Times(1),
+					conn.EXPECT().Close().Times(1),
+				)
+			},
+			wantAuthResponse: &authenticators.Response{
+				User: &user.DefaultInfo{
+					Name:   testUserSearchResultUsernameAttributeValue,
+					UID:    base64.RawURLEncoding.EncodeToString([]byte(testUserSearchResultUIDAttributeValue)),
+					Groups: []string{testGroupSearchResultGroupNameAttributeValue1},
+				},
+				DN:                     testUserDN,
+				ExtraRefreshAttributes: map[string]string{},
+			},
+		},
+		{
+			name:          "does not search for groups when the groups scope was not granted",
+			username:      testUpstreamUsername,
+			grantedScopes: []string{},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserDN, testUpstreamPassword).Times(1)
+				conn.EXPECT().Search(expectedUserEntryRead).Return(userEntryResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantAuthResponse: &authenticators.Response{
+				User: &user.DefaultInfo{
+					Name: testUserSearchResultUsernameAttributeValue,
+					UID:  base64.RawURLEncoding.EncodeToString([]byte(testUserSearchResultUIDAttributeValue)),
+				},
+				DN:                     testUserDN,
+				ExtraRefreshAttributes: map[string]string{},
+			},
+		},
+		{
+			name:     "escapes the username when constructing the DN",
+			username: "evil,ou=admins",
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(`uid=evil\,ou\=admins,ou=users,dc=pinniped,dc=dev`, testUpstreamPassword).
+					Return(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("some bind error"))).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantUnauthenticated: true,
+		},
+		{
+			name:     "wrong password",
+			username: testUpstreamUsername,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserDN, testUpstreamPassword).
+					Return(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("some bind error"))).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantUnauthenticated: true,
+		},
+		{
+			name:     "other bind errors",
+			username: testUpstreamUsername,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserDN, testUpstreamPassword).Return(errors.New("some bind error")).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: `error binding for user "some-upstream-username" using provided password against DN "uid=some-upstream-username,ou=users,dc=pinniped,dc=dev": some bind error`,
+		},
+		{
+			name:     "reading the user's entry fails",
+			username: testUpstreamUsername,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserDN, testUpstreamPassword).Times(1)
+				conn.EXPECT().Search(expectedUserEntryRead).Return(nil, errors.New("some search error")).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: `error searching for user "uid=some-upstream-username,ou=users,dc=pinniped,dc=dev": some search error`,
+		},
+		{
+			name:     "empty username",
+			username: "",
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should not dial.
+			},
+			wantUnauthenticated: true,
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			conn := mockldapconn.NewMockConn(ctrl)
+			tt.setupMocks(conn)
+
+			ldapProvider := New(ProviderConfig{
+				Name:               "some-provider-name",
+				Host:               testHost,
+				ConnectionProtocol: TLS,
+				UserDNTemplate:     testUserDNTemplate,
+				UserSearch: UserSearchConfig{
+					UsernameAttribute: testUserSearchUsernameAttribute,
+					UIDAttribute:      testUserSearchUIDAttribute,
+				},
+				GroupSearch: GroupSearchConfig{
+					Base:               testGroupSearchBase,
+					GroupNameAttribute: testGroupSearchGroupNameAttribute,
+				},
+				Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+					return conn, nil
+				}),
+			})
+
+			authResponse, authenticated, err := ldapProvider.AuthenticateUser(context.Background(), tt.username, testUpstreamPassword, tt.grantedScopes)
+			switch {
+			case tt.wantError != "":
+				require.EqualError(t, err, tt.wantError)
+				require.False(t, authenticated)
+				require.Nil(t, authResponse)
+			case tt.wantUnauthenticated:
+				require.NoError(t, err)
+				require.False(t, authenticated)
+				require.Nil(t, authResponse)
+			default:
+				require.NoError(t, err)
+				require.True(t, authenticated)
+				require.Equal(t, tt.wantAuthResponse, authResponse)
+			}
+		})
+	}
+}
+
+func TestDirectBindWithoutBindAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	conn := mockldapconn.NewMockConn(ctrl)
+	dialCount := 0
+	ldapProvider := New(ProviderConfig{
+		Name:               "some-provider-name",
+		Host:               testHost,
+		ConnectionProtocol: TLS,
+		UserDNTemplate:     "uid={username},ou=users,dc=pinniped,dc=dev",
+		Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+			dialCount++
+			return conn, nil
+		}),
+	})
+
+	// Testing the connection only dials, since there are no bind credentials to test.
+	conn.EXPECT().Close().Times(1)
+	require.NoError(t, ldapProvider.TestConnection(context.Background()))
+	require.Equal(t, 1, dialCount)
+
+	// Refreshing keeps the groups from the login without connecting, since the user's entry cannot be read.
+	groups, additionalClaims, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+		DN:            "uid=some-upstream-username,ou=users,dc=pinniped,dc=dev",
+		Groups:        []string{"group1", "group2"},
+		GrantedScopes: []string{"groups"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"group1", "group2"}, groups)
+	require.Nil(t, additionalClaims)
+	require.Equal(t, 1, dialCount)
+
+	groups, _, err = ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+		DN:     "uid=some-upstream-username,ou=users,dc=pinniped,dc=dev",
+		Groups: []string{"group1", "group2"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, groups)
+}
+
+func TestEscapeDNAttributeValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "pinny", want: "pinny"},
+		{value: "pinny,ou=admins", want: `pinny\,ou\=admins`},
+		{value: `a+b"c;d<e>f\g`, want: `a\+b\"c\;d\<e\>f\\g`},
+		{value: " leading and trailing spaces ", want: `\ leading and trailing spaces\ `},
+		{value: "#leading hash", want: `\#leading hash`},
+		{value: "middle # hash", want: "middle # hash"},
+		{value: "nul\x00byte", want: `nul\00byte`},
+		{value: "ünïcödé", want: "ünïcödé"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, escapeDNAttributeValue(tt.value), "value: %q", tt.value)
+	}
+}
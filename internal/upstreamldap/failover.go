@@ -0,0 +1,131 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/plog"
+)
+
+// unhealthyHostRetryInterval is how long a host which could not be dialed will be tried only after all other hosts.
+const unhealthyHostRetryInterval = 30 * time.Second
+
+// hostHealth remembers which of the configured hosts recently could not be dialed, so that subsequent dials
+// can skip directly to a host which is more likely to be available. It is safe for concurrent use.
+type hostHealth struct {
+	mu             sync.Mutex
+	unhealthyUntil map[string]time.Time
+	lastUsedHost   string
+	now            func() time.Time
+}
+
+func newHostHealth() *hostHealth {
+	return &hostHealth{unhealthyUntil: map[string]time.Time{}, now: time.Now}
+}
+
+// orderedHosts returns the given hosts in the order in which they should be tried. Healthy hosts are tried in
+// their configured order, followed by any unhealthy hosts, also in their configured order.
+func (h *hostHealth) orderedHosts(hosts []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	healthy := make([]string, 0, len(hosts))
+	var unhealthy []string
+	for _, host := range hosts {
+		if until, found := h.unhealthyUntil[host]; found && now.Before(until) {
+			unhealthy = append(unhealthy, host)
+			continue
+		}
+		healthy = append(healthy, host)
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (h *hostHealth) markUnhealthy(host string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unhealthyUntil[host] = h.now().Add(unhealthyHostRetryInterval)
+}
+
+func (h *hostHealth) markUsed(host string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.unhealthyUntil, host)
+	h.lastUsedHost = host
+}
+
+func (h *hostHealth) lastUsed() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastUsedHost
+}
+
+// hosts returns the configured host followed by the configured failover hosts.
+func (p *Provider) hosts() []string {
+	return append([]string{p.c.Host}, p.c.FailoverHosts...)
+}
+
+// LastUsedHost returns the host to which this provider most recently connected successfully,
+// or an empty string if it has not connected yet.
+func (p *Provider) LastUsedHost() string {
+	return p.health.lastUsed()
+}
+
+// dial connects to the first available host, trying each of the configured hosts in order and preferring
+// hosts which have not recently failed.
+func (p *Provider) dial(ctx context.Context) (Conn, error) {
+	return p.dialFirstAvailable(ctx, p.health, p.dialHost, p.hostAddr)
+}
+
+// dialFirstAvailable connects to the first available host using dialHost, preferring hosts which have not recently
+// failed according to the given health. The errors name the address which was dialed for each host, according
+// to hostAddr, since the configured host may not include the port.
+func (p *Provider) dialFirstAvailable(
+	ctx context.Context,
+	health *hostHealth,
+	dialHost func(ctx context.Context, host string) (Conn, error),
+	hostAddr func(host string) (endpointaddr.HostPort, error),
+) (Conn, error) {
+	hosts := health.orderedHosts(p.hosts())
+
+	var errs []error
+	for _, host := range hosts {
+		conn, err := p.instrumentedDial(func() (Conn, error) { return dialHost(ctx, host) })
+		if err != nil {
+			health.markUnhealthy(host)
+			errs = append(errs, fmt.Errorf(`error dialing host %q: %w`, dialedAddr(host, hostAddr), err))
+			if ctx.Err() != nil {
+				break // no point in trying other hosts
+			}
+			if len(hosts) > 1 {
+				plog.InfoErr("could not dial LDAP host, so trying the next host", err, "upstreamName", p.GetName(), "host", host)
+			}
+			continue
+		}
+		health.markUsed(host)
+		return conn, nil
+	}
+
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, utilerrors.NewAggregate(errs)
+}
+
+// dialedAddr returns the host:port which was dialed for the host, or the host itself when it could not be parsed.
+func dialedAddr(host string, hostAddr func(host string) (endpointaddr.HostPort, error)) string {
+	addr, err := hostAddr(host)
+	if err != nil {
+		return host
+	}
+	return addr.Endpoint()
+}
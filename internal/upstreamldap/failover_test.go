@@ -0,0 +1,143 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+)
+
+func TestHostHealthOrderedHosts(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := newHostHealth()
+	h.now = func() time.Time { return now }
+
+	hosts := []string{"ldap1.example.com", "ldap2.example.com", "ldap3.example.com"}
+	require.Equal(t, hosts, h.orderedHosts(hosts))
+
+	h.markUnhealthy("ldap1.example.com")
+	require.Equal(t, []string{"ldap2.example.com", "ldap3.example.com", "ldap1.example.com"}, h.orderedHosts(hosts))
+
+	h.markUnhealthy("ldap3.example.com")
+	require.Equal(t, []string{"ldap2.example.com", "ldap1.example.com", "ldap3.example.com"}, h.orderedHosts(hosts))
+
+	// A host which is used successfully is healthy again.
+	h.markUsed("ldap3.example.com")
+	require.Equal(t, []string{"ldap2.example.com", "ldap3.example.com", "ldap1.example.com"}, h.orderedHosts(hosts))
+	require.Equal(t, "ldap3.example.com", h.lastUsed())
+
+	// After the retry interval, the host is tried in its configured order again.
+	now = now.Add(unhealthyHostRetryInterval)
+	require.Equal(t, hosts, h.orderedHosts(hosts))
+}
+
+func TestDialWithFailoverHosts(t *testing.T) {
+	tests := []struct {
+		name             string
+		failoverHosts    []string
+		dialErrors       map[string]error
+		wantDialed       []string
+		wantLastUsedHost string
+		wantError        string
+		wantSecondDialed []string
+	}{
+		{
+			name:             "only the primary host is configured and is available",
+			wantDialed:       []string{"ldap1.example.com:636"},
+			wantLastUsedHost: "ldap1.example.com",
+			wantSecondDialed: []string{"ldap1.example.com:636"},
+		},
+		{
+			name:             "the primary host is available so the failover hosts are not tried",
+			failoverHosts:    []string{"ldap2.example.com", "ldap3.example.com:1234"},
+			wantDialed:       []string{"ldap1.example.com:636"},
+			wantLastUsedHost: "ldap1.example.com",
+			wantSecondDialed: []string{"ldap1.example.com:636"},
+		},
+		{
+			name:          "the primary host is unavailable so the failover hosts are tried in order, and the failed host is tried last next time",
+			failoverHosts: []string{"ldap2.example.com", "ldap3.example.com:1234"},
+			dialErrors: map[string]error{
+				"ldap1.example.com:636": errors.New("some dial error"),
+			},
+			wantDialed:       []string{"ldap1.example.com:636", "ldap2.example.com:636"},
+			wantLastUsedHost: "ldap2.example.com",
+			wantSecondDialed: []string{"ldap2.example.com:636"},
+		},
+		{
+			name:          "all hosts are unavailable",
+			failoverHosts: []string{"ldap2.example.com", "ldap3.example.com:1234"},
+			dialErrors: map[string]error{
+				"ldap1.example.com:636":  errors.New("some dial error 1"),
+				"ldap2.example.com:636":  errors.New("some dial error 2"),
+				"ldap3.example.com:1234": errors.New("some dial error 3"),
+			},
+			wantDialed: []string{"ldap1.example.com:636", "ldap2.example.com:636", "ldap3.example.com:1234"},
+			wantError: `[error dialing host "ldap1.example.com:636": some dial error 1, ` +
+				`error dialing host "ldap2.example.com:636": some dial error 2, ` +
+				`error dialing host "ldap3.example.com:1234": some dial error 3]`,
+		},
+		{
+			name: "only the primary host is configured and is unavailable",
+			dialErrors: map[string]error{
+				"ldap1.example.com:636": errors.New("some dial error"),
+			},
+			wantDialed: []string{"ldap1.example.com:636"},
+			wantError:  `error dialing host "ldap1.example.com:636": some dial error`,
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			conn := mockldapconn.NewMockConn(ctrl)
+
+			var dialed []string
+			ldapProvider := New(ProviderConfig{
+				Name:               "some-provider-name",
+				Host:               "ldap1.example.com",
+				FailoverHosts:      tt.failoverHosts,
+				ConnectionProtocol: TLS,
+				BindUsername:       testBindUsername,
+				BindPassword:       testBindPassword,
+				Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+					dialed = append(dialed, addr.Endpoint())
+					if err := tt.dialErrors[addr.Endpoint()]; err != nil {
+						return nil, err
+					}
+					return conn, nil
+				}),
+			})
+
+			if tt.wantError == "" {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(2)
+				conn.EXPECT().Close().Times(2)
+			}
+
+			err := ldapProvider.TestConnection(context.Background())
+			require.Equal(t, tt.wantDialed, dialed)
+			require.Equal(t, tt.wantLastUsedHost, ldapProvider.LastUsedHost())
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+
+			dialed = nil
+			require.NoError(t, ldapProvider.TestConnection(context.Background()))
+			require.Equal(t, tt.wantSecondDialed, dialed)
+			require.Equal(t, tt.wantLastUsedHost, ldapProvider.LastUsedHost())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"go.pinniped.dev/internal/endpointaddr"
+)
+
+const (
+	// The Active Directory Global Catalog is served on different ports than the usual LDAP ports.
+	// See https://learn.microsoft.com/en-us/windows/win32/ad/global-catalog
+	globalCatalogPort    = uint16(3268)
+	globalCatalogTLSPort = uint16(3269)
+)
+
+// dialGlobalCatalogHost connects to the Global Catalog port of the host using the configured connection protocol.
+func (p *Provider) dialGlobalCatalogHost(ctx context.Context, host string) (Conn, error) {
+	addr, err := p.globalCatalogAddr(host)
+	if err != nil {
+		return nil, ldap.NewError(ldap.ErrorNetwork, err)
+	}
+
+	var dialFunc LDAPDialerFunc
+	switch p.c.ConnectionProtocol {
+	case TLS:
+		dialFunc = p.dialTLS
+	case StartTLS:
+		dialFunc = p.dialStartTLS
+	default:
+		return nil, ldap.NewError(ldap.ErrorNetwork, fmt.Errorf("did not specify valid ConnectionProtocol"))
+	}
+
+	// Override the real dialer for testing purposes sometimes.
+	if p.c.Dialer != nil {
+		dialFunc = p.c.Dialer.Dial
+	}
+
+	return dialFunc(ctx, addr)
+}
+
+// globalCatalogAddr returns the address which dialGlobalCatalogHost dials for the host. Any port which is specified
+// in the host is ignored, since it is the port of the usual LDAP service.
+func (p *Provider) globalCatalogAddr(host string) (endpointaddr.HostPort, error) {
+	addr, err := endpointaddr.Parse(host, defaultLDAPSPort)
+	if err != nil {
+		return endpointaddr.HostPort{}, err
+	}
+	addr.Port = globalCatalogPort
+	if p.c.ConnectionProtocol == TLS {
+		addr.Port = globalCatalogTLSPort
+	}
+	return addr, nil
+}
+
+// userSearchConn returns the connection which should be used to search for users. This is the given connection,
+// unless the Global Catalog is configured, in which case it is a new connection to the Global Catalog which is
+// bound as the bind account. The returned func must be called to close the connection when it is no longer needed.
+func (p *Provider) userSearchConn(ctx context.Context, conn Conn) (Conn, func(), error) {
+	if !p.c.UserSearch.UseGlobalCatalog {
+		return conn, func() {}, nil
+	}
+
+	globalCatalogConn, err := p.dialFirstAvailable(ctx, p.globalCatalogHealth, p.dialGlobalCatalogHost, p.globalCatalogAddr)
+	if err != nil {
+		return nil, nil, fmt.Errorf(`error connecting to global catalog: %w`, err)
+	}
+
+	err = p.bindAsServiceAccount(globalCatalogConn)
+	if err != nil {
+		globalCatalogConn.Close()
+		return nil, nil, fmt.Errorf(`error binding as %q to global catalog before user search: %w`, p.c.BindUsername, err)
+	}
+
+	return globalCatalogConn, globalCatalogConn.Close, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+func TestGlobalCatalog(t *testing.T) {
+	const (
+		userDN         = "CN=some-user,OU=users,DC=child,DC=example,DC=com"
+		forestRootBase = "DC=example,DC=com"
+	)
+
+	userEntry := &ldap.Entry{
+		DN: userDN,
+		Attributes: []*ldap.EntryAttribute{
+			ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+			ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+		},
+	}
+	groupSearchResult := &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			{
+				DN: testGroupSearchResultDNValue1,
+				Attributes: []*ldap.EntryAttribute{
+					ldap.NewEntryAttribute(testGroupSearchGroupNameAttribute, []string{testGroupSearchResultGroupNameAttributeValue1}),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		connectionProtocol LDAPConnectionProtocol
+		refresh            bool
+		dialErrors         map[string]error
+		setupMocks         func(conn, globalCatalogConn *mockldapconn.MockConn)
+		wantDialed         []string
+		wantGroups         []string
+		wantErr            string
+	}{
+		{
+			name:               "users are searched for on the global catalog using TLS, while group searches and binds use the usual port",
+			connectionProtocol: TLS,
+			setupMocks: func(conn, globalCatalogConn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Search(gomock.Any()).DoAndReturn(func(r *ldap.SearchRequest) (*ldap.SearchResult, error) {
+					require.Equal(t, forestRootBase, r.BaseDN)
+					return &ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil
+				}).Times(1)
+				conn.EXPECT().SearchWithPaging(gomock.Any(), expectedGroupSearchPageSize).Return(groupSearchResult, nil).Times(1)
+				conn.EXPECT().Bind(userDN, testUpstreamPassword).Times(1)
+				globalCatalogConn.EXPECT().Close().Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantDialed: []string{"ldap.example.com:8443", "ldap.example.com:3269"},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1},
+		},
+		{
+			name:               "users are searched for on the global catalog using StartTLS",
+			connectionProtocol: StartTLS,
+			setupMocks: func(conn, globalCatalogConn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Search(gomock.Any()).Return(&ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(gomock.Any(), expectedGroupSearchPageSize).Return(groupSearchResult, nil).Times(1)
+				conn.EXPECT().Bind(userDN, testUpstreamPassword).Times(1)
+				globalCatalogConn.EXPECT().Close().Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantDialed: []string{"ldap.example.com:8443", "ldap.example.com:3268"},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1},
+		},
+		{
+			name:               "the user's entry is read from the global catalog during refresh",
+			connectionProtocol: TLS,
+			refresh:            true,
+			setupMocks: func(conn, globalCatalogConn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Search(gomock.Any()).DoAndReturn(func(r *ldap.SearchRequest) (*ldap.SearchResult, error) {
+					require.Equal(t, userDN, r.BaseDN)
+					return &ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil
+				}).Times(1)
+				conn.EXPECT().SearchWithPaging(gomock.Any(), expectedGroupSearchPageSize).Return(groupSearchResult, nil).Times(1)
+				globalCatalogConn.EXPECT().Close().Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantDialed: []string{"ldap.example.com:8443", "ldap.example.com:3269"},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1},
+		},
+		{
+			name:               "the global catalog cannot be reached",
+			connectionProtocol: TLS,
+			dialErrors: map[string]error{
+				"ldap.example.com:3269": errors.New("some dial error"),
+			},
+			setupMocks: func(conn, globalCatalogConn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantDialed: []string{"ldap.example.com:8443", "ldap.example.com:3269"},
+			wantErr:    `error connecting to global catalog: error dialing host "ldap.example.com:3269": some dial error`,
+		},
+		{
+			name:               "binding to the global catalog fails",
+			connectionProtocol: TLS,
+			refresh:            true,
+			setupMocks: func(conn, globalCatalogConn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				globalCatalogConn.EXPECT().Bind(testBindUsername, testBindPassword).Return(errors.New("some bind error")).Times(1)
+				globalCatalogConn.EXPECT().Close().Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantDialed: []string{"ldap.example.com:8443", "ldap.example.com:3269"},
+			wantErr:    `error binding as "cn=some-bind-username,dc=pinniped,dc=dev" to global catalog before user search: some bind error`,
+		},
+	}
+
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			conn := mockldapconn.NewMockConn(ctrl)
+			globalCatalogConn := mockldapconn.NewMockConn(ctrl)
+			tt.setupMocks(conn, globalCatalogConn)
+
+			var dialed []string
+			ldapProvider := New(ProviderConfig{
+				Name:               "some-provider-name",
+				Host:               testHost,
+				ConnectionProtocol: tt.connectionProtocol,
+				BindUsername:       testBindUsername,
+				BindPassword:       testBindPassword,
+				UserSearch: UserSearchConfig{
+					Base:              forestRootBase,
+					Filter:            testUserSearchFilter,
+					UsernameAttribute: testUserSearchUsernameAttribute,
+					UIDAttribute:      testUserSearchUIDAttribute,
+					UseGlobalCatalog:  true,
+				},
+				GroupSearch: GroupSearchConfig{
+					Base:               testGroupSearchBase,
+					Filter:             testGroupSearchFilter,
+					GroupNameAttribute: testGroupSearchGroupNameAttribute,
+				},
+				Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+					dialed = append(dialed, addr.Endpoint())
+					if err := tt.dialErrors[addr.Endpoint()]; err != nil {
+						return nil, err
+					}
+					if addr.Port == globalCatalogPort || addr.Port == globalCatalogTLSPort {
+						return globalCatalogConn, nil
+					}
+					return conn, nil
+				}),
+			})
+
+			var groups []string
+			var err error
+			if tt.refresh {
+				groups, _, err = ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+					Username: testUserSearchResultUsernameAttributeValue,
+					Subject: "ldaps://ldap.example.com:8443?base=DC%3Dexample%2CDC%3Dcom&sub=" +
+						base64.RawURLEncoding.EncodeToString([]byte(testUserSearchResultUIDAttributeValue)),
+					DN:            userDN,
+					GrantedScopes: []string{"groups"},
+				})
+			} else {
+				var response *authenticators.Response
+				var authenticated bool
+				response, authenticated, err = ldapProvider.AuthenticateUser(context.Background(),
+					testUpstreamUsername, testUpstreamPassword, []string{"groups"})
+				if authenticated {
+					groups = response.User.GetGroups()
+				}
+			}
+
+			require.Equal(t, tt.wantDialed, dialed)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantGroups, groups)
+		})
+	}
+}
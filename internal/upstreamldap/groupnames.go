@@ -0,0 +1,89 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// GroupNameTransformationType is the type of a GroupNameTransformation.
+type GroupNameTransformationType int
+
+const (
+	// GroupNameTransformationPrefix adds the Prefix to the start of the group name.
+	GroupNameTransformationPrefix GroupNameTransformationType = iota
+
+	// GroupNameTransformationStripDNComponents replaces a group name which is a DN with the value of its first RDN.
+	GroupNameTransformationStripDNComponents
+
+	// GroupNameTransformationLowercase converts the group name to lowercase.
+	GroupNameTransformationLowercase
+
+	// GroupNameTransformationRegexReplace replaces all matches of the Regex in the group name with the Replacement.
+	GroupNameTransformationRegexReplace
+)
+
+// GroupNameTransformation is a transformation which is applied to each group name found by the group search.
+type GroupNameTransformation struct {
+	Type GroupNameTransformationType
+
+	// Prefix is used by GroupNameTransformationPrefix.
+	Prefix string
+
+	// Regex and Replacement are used by GroupNameTransformationRegexReplace. The Replacement may refer to
+	// capture groups of the Regex, as described by regexp.Regexp.Expand.
+	Regex       *regexp.Regexp
+	Replacement string
+}
+
+func (t GroupNameTransformation) apply(groupName string) string {
+	switch t.Type {
+	case GroupNameTransformationPrefix:
+		return t.Prefix + groupName
+	case GroupNameTransformationStripDNComponents:
+		return firstRDNValue(groupName)
+	case GroupNameTransformationLowercase:
+		return strings.ToLower(groupName)
+	case GroupNameTransformationRegexReplace:
+		return t.Regex.ReplaceAllString(groupName, t.Replacement)
+	default:
+		return groupName
+	}
+}
+
+// firstRDNValue returns the value of the first RDN of the DN, e.g. "admins" for "cn=admins,ou=groups,dc=example,dc=com".
+// Group names which cannot be parsed as a DN are returned unchanged.
+func firstRDNValue(groupName string) string {
+	dn, err := ldap.ParseDN(groupName)
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return groupName
+	}
+	return dn.RDNs[0].Attributes[0].Value
+}
+
+// transformGroupNames applies the configured transformations, in order, to each group name. Group names which
+// are empty, or which become empty after any transformation, are dropped.
+func (p *Provider) transformGroupNames(groups []string) []string {
+	if len(p.c.GroupSearch.GroupNameTransformations) == 0 {
+		return groups
+	}
+
+	transformed := make([]string, 0, len(groups))
+	for _, groupName := range groups {
+		for _, transformation := range p.c.GroupSearch.GroupNameTransformations {
+			if len(groupName) == 0 {
+				break
+			}
+			groupName = transformation.apply(groupName)
+		}
+		if len(groupName) == 0 {
+			continue
+		}
+		transformed = append(transformed, groupName)
+	}
+	return transformed
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformGroupNames(t *testing.T) {
+	groups := []string{
+		"cn=Admins,ou=groups,dc=example,dc=com",
+		"CN=Developers+uid=123,OU=groups,DC=example,DC=com",
+		"Viewers",
+		"",
+	}
+
+	tests := []struct {
+		name            string
+		transformations []GroupNameTransformation
+		groups          []string
+		wantGroups      []string
+	}{
+		{
+			name:       "no transformations leaves the group names unchanged",
+			groups:     groups,
+			wantGroups: groups,
+		},
+		{
+			name:            "prefix",
+			transformations: []GroupNameTransformation{{Type: GroupNameTransformationPrefix, Prefix: "ldap:"}},
+			groups:          []string{"admins", "viewers"},
+			wantGroups:      []string{"ldap:admins", "ldap:viewers"},
+		},
+		{
+			name:            "strip DN components uses the value of the first RDN and leaves other names unchanged",
+			transformations: []GroupNameTransformation{{Type: GroupNameTransformationStripDNComponents}},
+			groups:          groups,
+			wantGroups:      []string{"Admins", "Developers", "Viewers"},
+		},
+		{
+			name:            "lowercase",
+			transformations: []GroupNameTransformation{{Type: GroupNameTransformationLowercase}},
+			groups:          []string{"Admins", "VIEWERS"},
+			wantGroups:      []string{"admins", "viewers"},
+		},
+		{
+			name: "regex replace with capture groups",
+			transformations: []GroupNameTransformation{{
+				Type:        GroupNameTransformationRegexReplace,
+				Regex:       regexp.MustCompile(`^team-(\w+)-(\w+)$`),
+				Replacement: "${2}-${1}",
+			}},
+			groups:     []string{"team-blue-admins", "other"},
+			wantGroups: []string{"admins-blue", "other"},
+		},
+		{
+			name: "group names which become empty are removed",
+			transformations: []GroupNameTransformation{{
+				Type:  GroupNameTransformationRegexReplace,
+				Regex: regexp.MustCompile(`^ignored-.*$`),
+			}},
+			groups:     []string{"ignored-group", "kept-group"},
+			wantGroups: []string{"kept-group"},
+		},
+		{
+			name: "transformations are applied in order",
+			transformations: []GroupNameTransformation{
+				{Type: GroupNameTransformationStripDNComponents},
+				{Type: GroupNameTransformationLowercase},
+				{Type: GroupNameTransformationPrefix, Prefix: "Corp:"},
+			},
+			groups:     groups,
+			wantGroups: []string{"Corp:admins", "Corp:developers", "Corp:viewers"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(ProviderConfig{GroupSearch: GroupSearchConfig{GroupNameTransformations: tt.transformations}})
+			require.Equal(t, tt.wantGroups, p.transformGroupNames(tt.groups))
+		})
+	}
+}
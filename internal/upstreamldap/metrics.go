@@ -0,0 +1,123 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "pinniped_supervisor"
+	metricsSubsystem = "upstream_ldap"
+
+	metricsLabelIDP    = "idp"
+	metricsLabelResult = "result"
+
+	metricsResultSuccess = "success"
+	metricsResultFailure = "failure"
+)
+
+// operationMetrics are the metrics for one kind of operation performed against upstream LDAP IDPs.
+type operationMetrics struct {
+	total    *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+func newOperationMetrics(operation, description string) *operationMetrics {
+	labels := []string{metricsLabelIDP, metricsLabelResult}
+	return &operationMetrics{
+		total: metrics.NewCounterVec(&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           operation + "s_total",
+			Help:           "Number of " + description + ", partitioned by identity provider name and result.",
+			StabilityLevel: metrics.ALPHA,
+		}, labels),
+		duration: metrics.NewHistogramVec(&metrics.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      operation + "_duration_seconds",
+			Help:      "Latency of " + description + " in seconds, partitioned by identity provider name and result.",
+			// From 5ms to about 82s, which covers the default dial and search timeouts.
+			Buckets:        metrics.ExponentialBuckets(0.005, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, labels),
+	}
+}
+
+// observe records one operation for the named identity provider which started at the given time.
+func (m *operationMetrics) observe(idpName string, start time.Time, err error) {
+	result := metricsResultSuccess
+	if err != nil {
+		result = metricsResultFailure
+	}
+	m.total.WithLabelValues(idpName, result).Inc()
+	m.duration.WithLabelValues(idpName, result).Observe(time.Since(start).Seconds())
+}
+
+//nolint:gochecknoglobals // Metrics are registered once with the global registry which is served by the Supervisor.
+var (
+	dialMetrics    = newOperationMetrics("dial", "connections dialed to upstream LDAP servers")
+	bindMetrics    = newOperationMetrics("bind", "binds performed on upstream LDAP servers")
+	searchMetrics  = newOperationMetrics("search", "searches performed on upstream LDAP servers")
+	refreshMetrics = newOperationMetrics("refresh", "upstream LDAP refreshes of downstream sessions")
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the upstream LDAP operations with the global registry, which is
+// served on the /metrics endpoint of the Supervisor's aggregated API server. It is safe to call more than once.
+func RegisterMetrics() {
+	registerMetricsOnce.Do(func() {
+		for _, m := range []*operationMetrics{dialMetrics, bindMetrics, searchMetrics, refreshMetrics} {
+			legacyregistry.MustRegister(m.total, m.duration)
+		}
+	})
+}
+
+// instrumentedConn is a Conn which records metrics for its binds and searches.
+type instrumentedConn struct {
+	Conn
+	idpName string
+}
+
+var _ Conn = &instrumentedConn{}
+
+func (c *instrumentedConn) Bind(username, password string) error {
+	start := time.Now()
+	err := c.Conn.Bind(username, password)
+	bindMetrics.observe(c.idpName, start, err)
+	return err
+}
+
+func (c *instrumentedConn) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	start := time.Now()
+	result, err := c.Conn.Search(searchRequest)
+	searchMetrics.observe(c.idpName, start, err)
+	return result, err
+}
+
+func (c *instrumentedConn) SearchWithPaging(searchRequest *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error) {
+	start := time.Now()
+	result, err := c.Conn.SearchWithPaging(searchRequest, pagingSize)
+	searchMetrics.observe(c.idpName, start, err)
+	return result, err
+}
+
+// instrumentedDial dials using dialFunc, recording metrics for the dial and for the binds and searches which are
+// later performed on the resulting Conn.
+func (p *Provider) instrumentedDial(dialFunc func() (Conn, error)) (Conn, error) {
+	start := time.Now()
+	conn, err := dialFunc()
+	dialMetrics.observe(p.GetName(), start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, idpName: p.GetName()}, nil
+}
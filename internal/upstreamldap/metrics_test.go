@@ -0,0 +1,65 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/metrics/testutil"
+
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+)
+
+func TestInstrumentedDial(t *testing.T) {
+	RegisterMetrics()
+	RegisterMetrics() // registering again is a no-op
+
+	const idpName = "some-instrumented-idp"
+	p := New(ProviderConfig{Name: idpName})
+
+	requireCount := func(m *operationMetrics, result string, want int) {
+		t.Helper()
+		total, err := testutil.GetCounterMetricValue(m.total.WithLabelValues(idpName, result))
+		require.NoError(t, err)
+		require.Equal(t, float64(want), total)
+		observations, err := testutil.GetHistogramMetricCount(m.duration.WithLabelValues(idpName, result))
+		require.NoError(t, err)
+		require.Equal(t, uint64(want), observations)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	mockConn := mockldapconn.NewMockConn(ctrl)
+	mockConn.EXPECT().Bind("some-username", "some-password").Return(nil).Times(1)
+	mockConn.EXPECT().Bind("some-username", "wrong-password").Return(errors.New("some bind error")).Times(1)
+	mockConn.EXPECT().Search(gomock.Any()).Return(&ldap.SearchResult{}, nil).Times(1)
+	mockConn.EXPECT().SearchWithPaging(gomock.Any(), uint32(42)).Return(nil, errors.New("some search error")).Times(1)
+	mockConn.EXPECT().Close().Times(1)
+
+	_, err := p.instrumentedDial(func() (Conn, error) { return nil, errors.New("some dial error") })
+	require.EqualError(t, err, "some dial error")
+
+	conn, err := p.instrumentedDial(func() (Conn, error) { return mockConn, nil })
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Bind("some-username", "some-password"))
+	require.EqualError(t, conn.Bind("some-username", "wrong-password"), "some bind error")
+	_, err = conn.Search(&ldap.SearchRequest{})
+	require.NoError(t, err)
+	_, err = conn.SearchWithPaging(&ldap.SearchRequest{}, 42)
+	require.EqualError(t, err, "some search error")
+	conn.Close()
+
+	requireCount(dialMetrics, metricsResultSuccess, 1)
+	requireCount(dialMetrics, metricsResultFailure, 1)
+	requireCount(bindMetrics, metricsResultSuccess, 1)
+	requireCount(bindMetrics, metricsResultFailure, 1)
+	requireCount(searchMetrics, metricsResultSuccess, 1)
+	requireCount(searchMetrics, metricsResultFailure, 1)
+	requireCount(refreshMetrics, metricsResultSuccess, 0)
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+	"k8s.io/utils/strings/slices"
+)
+
+const (
+	proxySchemeHTTP    = "http"
+	proxySchemeSOCKS5  = "socks5"
+	proxySchemeSOCKS5H = "socks5h"
+
+	defaultHTTPProxyPort = "80"
+)
+
+// SupportedProxyURLSchemes are the URL schemes which may be used in the ProxyURL of a ProviderConfig.
+func SupportedProxyURLSchemes() []string {
+	return []string{proxySchemeHTTP, proxySchemeSOCKS5, proxySchemeSOCKS5H}
+}
+
+// ValidateProxyURL parses the URL of a proxy through which connections to an LDAP server should be made.
+func ValidateProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		// Avoid repeating the URL in the error, since it may contain the credentials for the proxy.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	if !slices.Contains(SupportedProxyURLSchemes(), u.Scheme) {
+		return nil, fmt.Errorf("scheme must be one of %q", SupportedProxyURLSchemes())
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("host must not be empty")
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("must not contain a path, query, or fragment")
+	}
+	return u, nil
+}
+
+// dialThroughProxy opens a TCP connection to the address through the proxy configured by ProxyURL.
+func (p *Provider) dialThroughProxy(ctx context.Context, address string) (net.Conn, error) {
+	proxyURL := p.c.ProxyURL
+	switch proxyURL.Scheme {
+	case proxySchemeSOCKS5, proxySchemeSOCKS5H:
+		dialer, err := proxy.FromURL(proxyURL, p.netDialer())
+		if err != nil {
+			return nil, err
+		}
+		contextDialer, ok := dialer.(proxy.ContextDialer)
+		if !ok {
+			return nil, fmt.Errorf("proxy dialer for scheme %q does not support contexts", proxyURL.Scheme)
+		}
+		return contextDialer.DialContext(ctx, "tcp", address)
+	case proxySchemeHTTP:
+		return p.dialHTTPConnectProxy(ctx, address)
+	default:
+		return nil, fmt.Errorf("unsupported proxy URL scheme %q", proxyURL.Scheme)
+	}
+}
+
+// dialHTTPConnectProxy asks an HTTP proxy to open a tunnel to the address using the CONNECT method.
+func (p *Provider) dialHTTPConnectProxy(ctx context.Context, address string) (net.Conn, error) {
+	proxyURL := p.c.ProxyURL
+
+	proxyPort := proxyURL.Port()
+	if proxyPort == "" {
+		proxyPort = defaultHTTPProxyPort
+	}
+
+	c, err := p.netDialer().DialContext(ctx, "tcp", net.JoinHostPort(proxyURL.Hostname(), proxyPort))
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit the time spent on the CONNECT exchange by the same deadline as the dial itself.
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(p.netDialer().Timeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(c); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("error writing CONNECT request to proxy: %w", err)
+	}
+
+	br := bufio.NewReader(c)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("error reading CONNECT response from proxy: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = c.Close()
+		return nil, fmt.Errorf("proxy refused to connect to %s: %s", address, resp.Status)
+	}
+
+	// LDAP clients speak first, so the LDAP server cannot have sent anything yet.
+	if br.Buffered() > 0 {
+		_ = c.Close()
+		return nil, fmt.Errorf("proxy sent unexpected data after CONNECT response")
+	}
+
+	if err := c.SetDeadline(time.Time{}); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
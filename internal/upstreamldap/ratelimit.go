@@ -0,0 +1,81 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/ratelimit"
+)
+
+// AuthenticationRateLimitConfig contains the limits on the rate of authentication attempts using an upstream
+// LDAP IDP. Attempts beyond the limits are rejected without contacting the upstream LDAP IDP.
+type AuthenticationRateLimitConfig struct {
+	// PerUsername limits the rate of authentication attempts for each username.
+	PerUsername ratelimit.Limit
+
+	// PerClientIP limits the rate of authentication attempts from each client IP address.
+	PerClientIP ratelimit.Limit
+}
+
+// AuthenticationRateLimiter limits the rate of authentication attempts per username and per client IP address.
+// It is safe for concurrent use. A nil AuthenticationRateLimiter does not limit authentication attempts.
+type AuthenticationRateLimiter struct {
+	config      AuthenticationRateLimitConfig
+	perUsername *ratelimit.KeyedLimiter
+	perClientIP *ratelimit.KeyedLimiter
+}
+
+// NewAuthenticationRateLimiter returns an AuthenticationRateLimiter which enforces the configured limits.
+func NewAuthenticationRateLimiter(config AuthenticationRateLimitConfig) *AuthenticationRateLimiter {
+	return newAuthenticationRateLimiterWithClock(config, clock.RealClock{})
+}
+
+func newAuthenticationRateLimiterWithClock(config AuthenticationRateLimitConfig, c clock.PassiveClock) *AuthenticationRateLimiter {
+	return &AuthenticationRateLimiter{
+		config:      config,
+		perUsername: ratelimit.NewKeyedLimiter(config.PerUsername, c),
+		perClientIP: ratelimit.NewKeyedLimiter(config.PerClientIP, c),
+	}
+}
+
+// Config returns the limits which are enforced by the AuthenticationRateLimiter.
+func (l *AuthenticationRateLimiter) Config() AuthenticationRateLimitConfig {
+	return l.config
+}
+
+// checkAuthenticationRateLimits returns an *authenticators.ThrottledError when an authentication attempt for the
+// username, from the client IP address found in the context, exceeds the configured limits.
+func (p *Provider) checkAuthenticationRateLimits(ctx context.Context, username string) error {
+	limiter := p.c.AuthenticationRateLimiter
+	if limiter == nil {
+		return nil
+	}
+
+	// Usernames are case-insensitive in most directories, so changing the case must not avoid the limit.
+	if !limiter.perUsername.TryAccept(strings.ToLower(username)) {
+		plog.Info("throttled authentication attempt for username", "upstreamName", p.GetName())
+		return &authenticators.ThrottledError{
+			Reason:     "username exceeded its authentication rate limit",
+			RetryAfter: limiter.config.PerUsername.RetryAfter(),
+		}
+	}
+
+	// Clients without a known IP address share a single limit.
+	clientIP := authenticators.ClientIPFromContext(ctx)
+	if !limiter.perClientIP.TryAccept(clientIP) {
+		plog.Info("throttled authentication attempt from client IP", "upstreamName", p.GetName(), "clientIP", clientIP)
+		return &authenticators.ThrottledError{
+			Reason:     "client IP exceeded its authentication rate limit",
+			RetryAfter: limiter.config.PerClientIP.RetryAfter(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/ratelimit"
+)
+
+func TestAuthenticateUserRateLimits(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	dialed := 0
+
+	provider := New(ProviderConfig{
+		Name:               "some-provider-name",
+		Host:               "ldap.example.com",
+		ConnectionProtocol: TLS,
+		Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+			dialed++
+			return nil, errors.New("some dial error")
+		}),
+		AuthenticationRateLimiter: newAuthenticationRateLimiterWithClock(AuthenticationRateLimitConfig{
+			PerUsername: ratelimit.Limit{AttemptsPerMinute: 2},
+			PerClientIP: ratelimit.Limit{AttemptsPerMinute: 2},
+		}, fakeClock),
+	})
+
+	authenticate := func(clientIP, username string) error {
+		t.Helper()
+		ctx := authenticators.ContextWithClientIP(context.Background(), clientIP)
+		response, authenticated, err := provider.AuthenticateUser(ctx, username, "some-password", nil)
+		require.Nil(t, response)
+		require.False(t, authenticated)
+		require.Error(t, err)
+		return err
+	}
+
+	// The attempts which are allowed reach the server, which fails to be dialed in this test.
+	require.NotErrorIs(t, authenticate("192.0.2.1", "some-username"), authenticators.ErrThrottled)
+	require.NotErrorIs(t, authenticate("192.0.2.2", "Some-Username"), authenticators.ErrThrottled)
+	require.Equal(t, 2, dialed)
+
+	// Changing the case of the username does not avoid the limit.
+	err := authenticate("192.0.2.3", "SOME-USERNAME")
+	require.ErrorIs(t, err, authenticators.ErrThrottled)
+	require.EqualError(t, err, "too many recent authentication attempts, please try again later: username exceeded its authentication rate limit")
+	require.Equal(t, 30*time.Second, authenticators.RetryAfter(err))
+
+	require.NotErrorIs(t, authenticate("192.0.2.1", "some-other-username"), authenticators.ErrThrottled)
+	require.Equal(t, 3, dialed)
+
+	err = authenticate("192.0.2.1", "yet-another-username")
+	require.ErrorIs(t, err, authenticators.ErrThrottled)
+	require.EqualError(t, err, "too many recent authentication attempts, please try again later: client IP exceeded its authentication rate limit")
+	require.Equal(t, 3, dialed)
+
+	// DryRunAuthenticateUser does not count towards, nor is it subject to, the limits.
+	_, _, err = provider.DryRunAuthenticateUser(context.Background(), "some-username", nil)
+	require.NotErrorIs(t, err, authenticators.ErrThrottled)
+	require.Equal(t, 4, dialed)
+}
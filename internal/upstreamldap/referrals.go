@@ -0,0 +1,218 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	ldapScheme              = "ldap"
+	defaultReferralMaxHops  = 3
+	ldapResultReferralField = ber.Tag(3) // the context-specific tag of the optional referral field of an LDAPResult
+)
+
+// searchFunc performs a search request using the given connection, e.g. with or without paging.
+type searchFunc func(conn Conn, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+
+// referralTarget describes where to connect to when following a referral.
+type referralTarget struct {
+	addr     endpointaddr.HostPort
+	protocol LDAPConnectionProtocol
+	baseDN   string
+}
+
+// foundEntry is a search result entry along with the referred server on which it was found.
+// The referral is nil when the entry was found on the configured server.
+type foundEntry struct {
+	*ldap.Entry
+	referral *referralTarget
+}
+
+// parseReferral parses an LDAP URL (RFC 4516) from a referral, e.g. "ldaps://ldap.example.com/ou=users,dc=example,dc=com".
+func parseReferral(referral string) (*referralTarget, error) {
+	u, err := url.Parse(referral)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse referral URL %q: %w", referral, err)
+	}
+
+	target := &referralTarget{baseDN: strings.TrimPrefix(u.Path, "/")}
+	var defaultPort uint16
+	switch strings.ToLower(u.Scheme) {
+	case ldapsScheme:
+		target.protocol, defaultPort = TLS, defaultLDAPSPort
+	case ldapScheme:
+		// Never follow a referral over an unencrypted connection, since we may send credentials to the referred server.
+		target.protocol, defaultPort = StartTLS, defaultLDAPPort
+	default:
+		return nil, fmt.Errorf("referral URL %q has unsupported scheme %q", referral, u.Scheme)
+	}
+
+	if len(u.Host) == 0 {
+		return nil, fmt.Errorf("referral URL %q does not specify a host", referral)
+	}
+	target.addr, err = endpointaddr.Parse(u.Host, defaultPort)
+	if err != nil {
+		return nil, fmt.Errorf("referral URL %q has invalid host: %w", referral, err)
+	}
+
+	return target, nil
+}
+
+// referralsFromError returns the referral URLs from an LDAP referral result, which a server returns instead of
+// search results when the base DN of the search is held by another server.
+func referralsFromError(err error) []string {
+	ldapErr := &ldap.Error{}
+	if !errors.As(err, &ldapErr) || ldapErr.ResultCode != ldap.LDAPResultReferral {
+		return nil
+	}
+	if ldapErr.Packet == nil || len(ldapErr.Packet.Children) < 2 {
+		return nil
+	}
+
+	var referrals []string
+	for _, field := range ldapErr.Packet.Children[1].Children {
+		if field.ClassType != ber.ClassContext || field.Tag != ldapResultReferralField {
+			continue
+		}
+		for _, uri := range field.Children {
+			if referral, ok := uri.Value.(string); ok {
+				referrals = append(referrals, referral)
+			}
+		}
+	}
+	return referrals
+}
+
+func (p *Provider) referralMaxHops() int {
+	if p.c.Referrals.MaxHops <= 0 {
+		return defaultReferralMaxHops
+	}
+	return p.c.Referrals.MaxHops
+}
+
+// searchFollowingReferrals performs the search using the given connection and, when enabled, repeats the search on
+// any referred servers. It returns the entries found on all servers. Errors from the initial search are returned
+// unwrapped so that callers can describe them.
+func (p *Provider) searchFollowingReferrals(ctx context.Context, conn Conn, searchRequest *ldap.SearchRequest, search searchFunc) ([]foundEntry, error) {
+	return p.searchServer(ctx, conn, nil, searchRequest, search, 0)
+}
+
+func (p *Provider) searchServer(
+	ctx context.Context,
+	conn Conn,
+	referral *referralTarget,
+	searchRequest *ldap.SearchRequest,
+	search searchFunc,
+	hop int,
+) ([]foundEntry, error) {
+	searchResult, err := search(conn, searchRequest)
+	if err != nil {
+		referrals := referralsFromError(err)
+		if len(referrals) == 0 || !p.c.Referrals.Follow {
+			return nil, err
+		}
+		return p.followReferrals(ctx, referrals, searchRequest, search, hop+1)
+	}
+
+	entries := make([]foundEntry, 0, len(searchResult.Entries))
+	for _, entry := range searchResult.Entries {
+		entries = append(entries, foundEntry{Entry: entry, referral: referral})
+	}
+
+	referredEntries, err := p.followReferrals(ctx, searchResult.Referrals, searchRequest, search, hop+1)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, referredEntries...), nil
+}
+
+func (p *Provider) followReferrals(ctx context.Context, referrals []string, searchRequest *ldap.SearchRequest, search searchFunc, hop int) ([]foundEntry, error) {
+	if !p.c.Referrals.Follow || len(referrals) == 0 {
+		return nil, nil
+	}
+	if hop > p.referralMaxHops() {
+		plog.Debug("not following referrals because the maximum number of referral hops was reached",
+			"upstreamName", p.GetName(), "referrals", referrals, "maxHops", p.referralMaxHops())
+		return nil, nil
+	}
+
+	var entries []foundEntry
+	for _, referral := range referrals {
+		target, err := parseReferral(referral)
+		if err != nil {
+			return nil, err
+		}
+
+		referredEntries, err := p.searchReferral(ctx, target, searchRequest, search, hop)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, referredEntries...)
+	}
+	return entries, nil
+}
+
+func (p *Provider) searchReferral(ctx context.Context, target *referralTarget, searchRequest *ldap.SearchRequest, search searchFunc, hop int) ([]foundEntry, error) {
+	conn, err := p.dialReferral(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Copy the request to avoid sharing any controls which were added by a previous search, e.g. a paging control.
+	referredRequest := *searchRequest
+	referredRequest.Controls = nil
+	if len(target.baseDN) > 0 {
+		referredRequest.BaseDN = target.baseDN
+	}
+
+	entries, err := p.searchServer(ctx, conn, target, &referredRequest, search, hop)
+	if err != nil {
+		return nil, fmt.Errorf(`error searching referred host %q: %w`, target.addr.Endpoint(), err)
+	}
+	return entries, nil
+}
+
+// dialReferral connects to a referred server and, unless configured to search referred servers anonymously,
+// binds as the bind account.
+func (p *Provider) dialReferral(ctx context.Context, target *referralTarget) (Conn, error) {
+	var dialFunc LDAPDialerFunc
+	switch target.protocol {
+	case TLS:
+		dialFunc = p.dialTLS
+	default:
+		dialFunc = p.dialStartTLS
+	}
+
+	// Override the real dialer for testing purposes sometimes.
+	if p.c.Dialer != nil {
+		dialFunc = p.c.Dialer.Dial
+	}
+
+	conn, err := p.instrumentedDial(func() (Conn, error) { return dialFunc(ctx, target.addr) })
+	if err != nil {
+		return nil, fmt.Errorf(`error dialing referred host %q: %w`, target.addr.Endpoint(), err)
+	}
+
+	if !p.c.Referrals.Anonymous {
+		err = p.bindAsServiceAccount(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf(`error binding as %q to referred host %q: %w`, p.c.BindUsername, target.addr.Endpoint(), err)
+		}
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,476 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+const (
+	testReferredHost     = "ldap2.example.com"
+	testReferredEndpoint = "ldap2.example.com:636"
+	testReferredBase     = "ou=users,dc=partition2,dc=example,dc=com"
+	testReferralURL      = "ldaps://" + testReferredHost + "/" + testReferredBase
+)
+
+// referralErr returns the same error that go-ldap would return when a server responds to a search with a referral result.
+func referralErr(referrals ...string) error {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(1), "MessageID"))
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultDone, nil, "Search Result Done")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(ldap.LDAPResultReferral), "resultCode"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	referralField := ber.Encode(ber.ClassContext, ber.TypeConstructed, 3, nil, "referral")
+	for _, referral := range referrals {
+		referralField.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, referral, "URI"))
+	}
+	response.AppendChild(referralField)
+	packet.AppendChild(response)
+	return ldap.GetLDAPError(packet)
+}
+
+func TestParseReferral(t *testing.T) {
+	tests := []struct {
+		name     string
+		referral string
+		want     *referralTarget
+		wantErr  string
+	}{
+		{
+			name:     "ldaps URL with base DN",
+			referral: "ldaps://ldap.example.com/ou=users,dc=example,dc=com",
+			want: &referralTarget{
+				addr:     endpointaddr.HostPort{Host: "ldap.example.com", Port: 636},
+				protocol: TLS,
+				baseDN:   "ou=users,dc=example,dc=com",
+			},
+		},
+		{
+			name:     "ldap URL with port, escaped base DN, and search parameters",
+			referral: "ldap://ldap.example.com:1389/ou=my%20users,dc=example,dc=com??sub",
+			want: &referralTarget{
+				addr:     endpointaddr.HostPort{Host: "ldap.example.com", Port: 1389},
+				protocol: StartTLS,
+				baseDN:   "ou=my users,dc=example,dc=com",
+			},
+		},
+		{
+			name:     "URL without base DN",
+			referral: "ldaps://ldap.example.com",
+			want: &referralTarget{
+				addr:     endpointaddr.HostPort{Host: "ldap.example.com", Port: 636},
+				protocol: TLS,
+			},
+		},
+		{
+			name:     "unsupported scheme",
+			referral: "https://ldap.example.com/dc=example,dc=com",
+			wantErr:  `referral URL "https://ldap.example.com/dc=example,dc=com" has unsupported scheme "https"`,
+		},
+		{
+			name:     "missing host",
+			referral: "ldap:///dc=example,dc=com",
+			wantErr:  `referral URL "ldap:///dc=example,dc=com" does not specify a host`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReferral(tt.referral)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReferralsFromError(t *testing.T) {
+	require.Equal(t, []string{"ldaps://a.example.com/dc=a", "ldaps://b.example.com/dc=b"},
+		referralsFromError(referralErr("ldaps://a.example.com/dc=a", "ldaps://b.example.com/dc=b")))
+	require.Nil(t, referralsFromError(ldap.NewError(ldap.LDAPResultOperationsError, errors.New("some error"))))
+	require.Nil(t, referralsFromError(nil))
+}
+
+func TestFollowingReferrals(t *testing.T) {
+	providerConfig := func(editFunc func(p *ProviderConfig)) *ProviderConfig {
+		config := &ProviderConfig{
+			Name:               "some-provider-name",
+			Host:               testHost,
+			ConnectionProtocol: TLS,
+			BindUsername:       testBindUsername,
+			BindPassword:       testBindPassword,
+			UserSearch: UserSearchConfig{
+				Base:              testUserSearchBase,
+				Filter:            testUserSearchFilter,
+				UsernameAttribute: testUserSearchUsernameAttribute,
+				UIDAttribute:      testUserSearchUIDAttribute,
+			},
+			GroupSearch: GroupSearchConfig{
+				Base:               testGroupSearchBase,
+				Filter:             testGroupSearchFilter,
+				GroupNameAttribute: testGroupSearchGroupNameAttribute,
+			},
+			Referrals: ReferralConfig{Follow: true},
+		}
+		if editFunc != nil {
+			editFunc(config)
+		}
+		return config
+	}
+
+	expectedUserSearch := func(editFunc func(r *ldap.SearchRequest)) *ldap.SearchRequest {
+		request := &ldap.SearchRequest{
+			BaseDN:       testUserSearchBase,
+			Scope:        ldap.ScopeWholeSubtree,
+			DerefAliases: ldap.NeverDerefAliases,
+			SizeLimit:    2,
+			TimeLimit:    90,
+			Filter:       testUserSearchFilterInterpolated,
+			Attributes:   []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute},
+		}
+		if editFunc != nil {
+			editFunc(request)
+		}
+		return request
+	}
+
+	expectedGroupSearch := func(editFunc func(r *ldap.SearchRequest)) *ldap.SearchRequest {
+		request := &ldap.SearchRequest{
+			BaseDN:       testGroupSearchBase,
+			Scope:        ldap.ScopeWholeSubtree,
+			DerefAliases: ldap.NeverDerefAliases,
+			SizeLimit:    0,
+			TimeLimit:    90,
+			Filter:       testGroupSearchFilterInterpolated,
+			Attributes:   []string{testGroupSearchGroupNameAttribute},
+		}
+		if editFunc != nil {
+			editFunc(request)
+		}
+		return request
+	}
+
+	userEntry := &ldap.Entry{
+		DN: testUserSearchResultDNValue,
+		Attributes: []*ldap.EntryAttribute{
+			ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+			ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+		},
+	}
+
+	groupEntry := func(dn, name string) *ldap.Entry {
+		return &ldap.Entry{
+			DN:         dn,
+			Attributes: []*ldap.EntryAttribute{ldap.NewEntryAttribute(testGroupSearchGroupNameAttribute, []string{name})},
+		}
+	}
+
+	expectedAuthResponse := func(groups ...string) *authenticators.Response {
+		if groups == nil {
+			groups = []string{}
+		}
+		return &authenticators.Response{
+			User: &user.DefaultInfo{
+				Name:   testUserSearchResultUsernameAttributeValue,
+				UID:    base64.RawURLEncoding.EncodeToString([]byte(testUserSearchResultUIDAttributeValue)),
+				Groups: groups,
+			},
+			DN:                     testUserSearchResultDNValue,
+			ExtraRefreshAttributes: map[string]string{},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		providerConfig      *ProviderConfig
+		setupMocks          func(conns map[string][]*mockldapconn.MockConn)
+		wantDials           map[string]int
+		wantAuthResponse    *authenticators.Response
+		wantUnauthenticated bool
+		wantErr             string
+	}{
+		{
+			name:           "user is found on the referred server and the end user bind happens on the referred server",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{Referrals: []string{testReferralURL}}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).
+					Return(&ldap.SearchResult{Entries: []*ldap.Entry{groupEntry(testGroupSearchResultDNValue1, testGroupSearchResultGroupNameAttributeValue1)}}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+
+				referredSearchConn := conns[testReferredEndpoint][0]
+				referredSearchConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				referredSearchConn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.BaseDN = testReferredBase
+				})).Return(&ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil).Times(1)
+				referredSearchConn.EXPECT().Close().Times(1)
+
+				referredBindConn := conns[testReferredEndpoint][1]
+				referredBindConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				referredBindConn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+				referredBindConn.EXPECT().Close().Times(1)
+			},
+			wantDials:        map[string]int{testHost: 1, testReferredEndpoint: 2},
+			wantAuthResponse: expectedAuthResponse(testGroupSearchResultGroupNameAttributeValue1),
+		},
+		{
+			name: "when configured for anonymous referrals then the bind account is not used on the referred server",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.Referrals.Anonymous = true
+			}),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{Referrals: []string{testReferralURL}}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).Return(&ldap.SearchResult{}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+
+				referredSearchConn := conns[testReferredEndpoint][0]
+				referredSearchConn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.BaseDN = testReferredBase
+				})).Return(&ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil).Times(1)
+				referredSearchConn.EXPECT().Close().Times(1)
+
+				referredBindConn := conns[testReferredEndpoint][1]
+				referredBindConn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+				referredBindConn.EXPECT().Close().Times(1)
+			},
+			wantDials:        map[string]int{testHost: 1, testReferredEndpoint: 2},
+			wantAuthResponse: expectedAuthResponse(),
+		},
+		{
+			name: "when following referrals is not enabled then referrals are ignored",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.Referrals.Follow = false
+			}),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{Referrals: []string{testReferralURL}}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantDials:           map[string]int{testHost: 1},
+			wantUnauthenticated: true,
+		},
+		{
+			name: "referrals from referred servers are not followed beyond the maximum number of hops",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.Referrals.MaxHops = 1
+			}),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{Referrals: []string{testReferralURL}}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+
+				referredSearchConn := conns[testReferredEndpoint][0]
+				referredSearchConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				referredSearchConn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.BaseDN = testReferredBase
+				})).Return(&ldap.SearchResult{Referrals: []string{"ldaps://ldap3.example.com/dc=example,dc=com"}}, nil).Times(1)
+				referredSearchConn.EXPECT().Close().Times(1)
+			},
+			wantDials:           map[string]int{testHost: 1, testReferredEndpoint: 1},
+			wantUnauthenticated: true,
+		},
+		{
+			name:           "groups found on referred servers are combined with groups found on the configured server",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).Return(&ldap.SearchResult{
+					Entries:   []*ldap.Entry{groupEntry(testGroupSearchResultDNValue1, testGroupSearchResultGroupNameAttributeValue1)},
+					Referrals: []string{"ldaps://" + testReferredHost + "/ou=groups,dc=partition2,dc=example,dc=com"},
+				}, nil).Times(1)
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+
+				referredSearchConn := conns[testReferredEndpoint][0]
+				referredSearchConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				referredSearchConn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.BaseDN = "ou=groups,dc=partition2,dc=example,dc=com"
+				}), expectedGroupSearchPageSize).Return(&ldap.SearchResult{
+					Entries: []*ldap.Entry{groupEntry(testGroupSearchResultDNValue2, testGroupSearchResultGroupNameAttributeValue2)},
+				}, nil).Times(1)
+				referredSearchConn.EXPECT().Close().Times(1)
+			},
+			wantDials:        map[string]int{testHost: 1, testReferredEndpoint: 1},
+			wantAuthResponse: expectedAuthResponse(testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2),
+		},
+		{
+			name:           "when the configured server returns a referral result instead of entries then it is followed",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(nil, referralErr(testReferralURL)).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).Return(&ldap.SearchResult{}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+
+				referredSearchConn := conns[testReferredEndpoint][0]
+				referredSearchConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				referredSearchConn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.BaseDN = testReferredBase
+				})).Return(&ldap.SearchResult{Entries: []*ldap.Entry{userEntry}}, nil).Times(1)
+				referredSearchConn.EXPECT().Close().Times(1)
+
+				referredBindConn := conns[testReferredEndpoint][1]
+				referredBindConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				referredBindConn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+				referredBindConn.EXPECT().Close().Times(1)
+			},
+			wantDials:        map[string]int{testHost: 1, testReferredEndpoint: 2},
+			wantAuthResponse: expectedAuthResponse(),
+		},
+		{
+			name:           "when the bind to the referred server fails then authentication fails",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conns map[string][]*mockldapconn.MockConn) {
+				conn := conns[testHost][0]
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{Referrals: []string{testReferralURL}}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+
+				referredSearchConn := conns[testReferredEndpoint][0]
+				referredSearchConn.EXPECT().Bind(testBindUsername, testBindPassword).Return(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("some bind error"))).Times(1)
+				referredSearchConn.EXPECT().Close().Times(1)
+			},
+			wantDials: map[string]int{testHost: 1, testReferredEndpoint: 1},
+			wantErr: `error searching for user: error binding as "cn=some-bind-username,dc=pinniped,dc=dev" to referred host "ldap2.example.com:636": ` +
+				`LDAP Result Code 49 "Invalid Credentials": some bind error`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			conns := map[string][]*mockldapconn.MockConn{}
+			for endpoint, count := range tt.wantDials {
+				for i := 0; i < count; i++ {
+					conns[endpoint] = append(conns[endpoint], mockldapconn.NewMockConn(ctrl))
+				}
+			}
+			tt.setupMocks(conns)
+
+			dials := map[string]int{}
+			tt.providerConfig.Dialer = LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+				endpoint := addr.Endpoint()
+				require.Less(t, dials[endpoint], len(conns[endpoint]), "unexpected dial to %s", endpoint)
+				conn := conns[endpoint][dials[endpoint]]
+				dials[endpoint]++
+				return conn, nil
+			})
+
+			authResponse, authenticated, err := New(*tt.providerConfig).AuthenticateUser(
+				context.Background(), testUpstreamUsername, testUpstreamPassword, []string{"groups"},
+			)
+			require.Equal(t, tt.wantDials, dials)
+			switch {
+			case tt.wantErr != "":
+				require.EqualError(t, err, tt.wantErr)
+				require.False(t, authenticated)
+				require.Nil(t, authResponse)
+			case tt.wantUnauthenticated:
+				require.NoError(t, err)
+				require.False(t, authenticated)
+				require.Nil(t, authResponse)
+			default:
+				require.NoError(t, err)
+				require.True(t, authenticated)
+				require.Equal(t, tt.wantAuthResponse, authResponse)
+			}
+		})
+	}
+}
+
+func TestRefreshFollowingReferrals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	expectedRefreshSearch := func(baseDN string) *ldap.SearchRequest {
+		return &ldap.SearchRequest{
+			BaseDN:       baseDN,
+			Scope:        ldap.ScopeBaseObject,
+			DerefAliases: ldap.NeverDerefAliases,
+			SizeLimit:    2,
+			TimeLimit:    90,
+			Filter:       "(objectClass=*)",
+			Attributes:   []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute},
+		}
+	}
+
+	conn := mockldapconn.NewMockConn(ctrl)
+	conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+	conn.EXPECT().Search(expectedRefreshSearch(testUserSearchResultDNValue)).
+		Return(nil, referralErr("ldaps://"+testReferredHost+"/"+testUserSearchResultDNValue)).Times(1)
+	conn.EXPECT().Close().Times(1)
+
+	referredConn := mockldapconn.NewMockConn(ctrl)
+	referredConn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+	referredConn.EXPECT().Search(expectedRefreshSearch(testUserSearchResultDNValue)).Return(&ldap.SearchResult{
+		Entries: []*ldap.Entry{{
+			DN: testUserSearchResultDNValue,
+			Attributes: []*ldap.EntryAttribute{
+				ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+				ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+			},
+		}},
+	}, nil).Times(1)
+	referredConn.EXPECT().Close().Times(1)
+
+	ldapProvider := New(ProviderConfig{
+		Name:               "some-provider-name",
+		Host:               testHost,
+		ConnectionProtocol: TLS,
+		BindUsername:       testBindUsername,
+		BindPassword:       testBindPassword,
+		UserSearch: UserSearchConfig{
+			Base:              testUserSearchBase,
+			UsernameAttribute: testUserSearchUsernameAttribute,
+			UIDAttribute:      testUserSearchUIDAttribute,
+		},
+		Referrals: ReferralConfig{Follow: true},
+		Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+			if addr.Endpoint() == testReferredEndpoint {
+				return referredConn, nil
+			}
+			require.Equal(t, testHost, addr.Endpoint())
+			return conn, nil
+		}),
+	})
+
+	groups, _, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+		Username: testUserSearchResultUsernameAttributeValue,
+		Subject:  "ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU",
+		DN:       testUserSearchResultDNValue,
+	})
+	require.NoError(t, err)
+	require.Nil(t, groups)
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// AttributeAssertionOperator is the operator of an AttributeAssertion. The operators have the same names as in the
+// LDAPIdentityProvider API.
+type AttributeAssertionOperator string
+
+const (
+	// AttributeAssertionEquals requires one of the attribute's values to equal the Value, ignoring case.
+	AttributeAssertionEquals = AttributeAssertionOperator("Equals")
+
+	// AttributeAssertionNotEquals requires none of the attribute's values to equal the Value, ignoring case.
+	AttributeAssertionNotEquals = AttributeAssertionOperator("NotEquals")
+
+	// AttributeAssertionAbsent requires the attribute to have no values.
+	AttributeAssertionAbsent = AttributeAssertionOperator("Absent")
+
+	// AttributeAssertionDaysSinceEpochInFuture requires the attribute's value, a number of days since the Unix epoch
+	// like shadowExpire, to be after the current day. A missing or non-positive value means that it never expires.
+	AttributeAssertionDaysSinceEpochInFuture = AttributeAssertionOperator("DaysSinceEpochInFuture")
+
+	// AttributeAssertionGeneralizedTimeInFuture requires the attribute's value, an LDAP GeneralizedTime, to be after
+	// the current time. A missing value means that it never expires.
+	AttributeAssertionGeneralizedTimeInFuture = AttributeAssertionOperator("GeneralizedTimeInFuture")
+)
+
+const secondsPerDay = int64(24 * time.Hour / time.Second)
+
+// generalizedTimeLayouts are the layouts of the GeneralizedTime syntax (RFC 4517 section 3.3.13) which are
+// accepted, with and without fractional seconds.
+func generalizedTimeLayouts() []string {
+	return []string{"20060102150405Z0700", "20060102150405.999999999Z0700"}
+}
+
+// AttributeAssertion is an assertion about an attribute of the user's entry which must hold for a refresh to succeed.
+type AttributeAssertion struct {
+	Attribute string
+	Operator  AttributeAssertionOperator
+
+	// Value is used by AttributeAssertionEquals and AttributeAssertionNotEquals.
+	Value string
+}
+
+func (a AttributeAssertion) check(entry *ldap.Entry, now time.Time) error {
+	values := entry.GetAttributeValues(a.Attribute)
+
+	switch a.Operator {
+	case AttributeAssertionEquals:
+		if !containsEqualFold(values, a.Value) {
+			return fmt.Errorf(`expected a value of %q, but found %q`, a.Value, values)
+		}
+	case AttributeAssertionNotEquals:
+		if containsEqualFold(values, a.Value) {
+			return fmt.Errorf(`found the value %q`, a.Value)
+		}
+	case AttributeAssertionAbsent:
+		if len(values) != 0 {
+			return fmt.Errorf(`expected no values, but found %q`, values)
+		}
+	case AttributeAssertionDaysSinceEpochInFuture:
+		if len(values) == 0 {
+			return nil
+		}
+		if len(values) != 1 {
+			return fmt.Errorf(`expected at most 1 value, but found %d`, len(values))
+		}
+		days, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf(`value %q is not a number of days: %w`, values[0], err)
+		}
+		if days > 0 && now.Unix()/secondsPerDay >= days {
+			return fmt.Errorf(`user's account expired %s`, time.Unix(days*secondsPerDay, 0).UTC().Format(time.RFC3339))
+		}
+	case AttributeAssertionGeneralizedTimeInFuture:
+		if len(values) == 0 {
+			return nil
+		}
+		if len(values) != 1 {
+			return fmt.Errorf(`expected at most 1 value, but found %d`, len(values))
+		}
+		expiration, err := parseGeneralizedTime(values[0])
+		if err != nil {
+			return err
+		}
+		if !now.Before(expiration) {
+			return fmt.Errorf(`user's account expired %s`, expiration.UTC().Format(time.RFC3339))
+		}
+	default:
+		return fmt.Errorf(`unknown operator %q`, a.Operator)
+	}
+	return nil
+}
+
+// checkRefreshAttributeAssertions returns an error for the first of the RefreshAttributeAssertions which does not hold
+// for the user's entry.
+func (p *Provider) checkRefreshAttributeAssertions(entry *ldap.Entry) error {
+	now := time.Now()
+	for _, assertion := range p.c.RefreshAttributeAssertions {
+		if err := assertion.check(entry, now); err != nil {
+			return fmt.Errorf(`assertion for attribute %q failed during upstream refresh: %w`, assertion.Attribute, err)
+		}
+	}
+	return nil
+}
+
+// refreshAttributeAssertionAttributes returns the names of the attributes which are needed to check the
+// RefreshAttributeAssertions.
+func (p *Provider) refreshAttributeAssertionAttributes() []string {
+	attributes := make([]string, 0, len(p.c.RefreshAttributeAssertions))
+	for _, assertion := range p.c.RefreshAttributeAssertions {
+		attributes = append(attributes, assertion.Attribute)
+	}
+	return attributes
+}
+
+func parseGeneralizedTime(value string) (time.Time, error) {
+	for _, layout := range generalizedTimeLayouts() {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(`value %q is not a GeneralizedTime`, value)
+}
+
+func containsEqualFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
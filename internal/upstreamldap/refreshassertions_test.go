@@ -0,0 +1,235 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamldap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/mocks/mockldapconn"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+func TestAttributeAssertionCheck(t *testing.T) {
+	now := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+	today := "20516" // the number of days from the Unix epoch to now
+
+	tests := []struct {
+		name      string
+		assertion AttributeAssertion
+		values    []string
+		wantErr   string
+	}{
+		{
+			name:      "equals matches ignoring case",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionEquals, Value: "active"},
+			values:    []string{"other", "ACTIVE"},
+		},
+		{
+			name:      "equals does not match",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionEquals, Value: "active"},
+			values:    []string{"inactive"},
+			wantErr:   `expected a value of "active", but found ["inactive"]`,
+		},
+		{
+			name:      "equals with a missing attribute",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionEquals, Value: "active"},
+			wantErr:   `expected a value of "active", but found []`,
+		},
+		{
+			name:      "not equals with a missing attribute",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionNotEquals, Value: "true"},
+		},
+		{
+			name:      "not equals with a different value",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionNotEquals, Value: "true"},
+			values:    []string{"false"},
+		},
+		{
+			name:      "not equals matches ignoring case",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionNotEquals, Value: "true"},
+			values:    []string{"TRUE"},
+			wantErr:   `found the value "true"`,
+		},
+		{
+			name:      "absent with a missing attribute",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionAbsent},
+		},
+		{
+			name:      "absent with a value",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionAbsent},
+			values:    []string{"20260101000000Z"},
+			wantErr:   `expected no values, but found ["20260101000000Z"]`,
+		},
+		{
+			name:      "days since epoch tomorrow",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionDaysSinceEpochInFuture},
+			values:    []string{"20517"},
+		},
+		{
+			name:      "days since epoch today",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionDaysSinceEpochInFuture},
+			values:    []string{today},
+			wantErr:   `user's account expired 2026-03-04T00:00:00Z`,
+		},
+		{
+			name:      "days since epoch which never expires",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionDaysSinceEpochInFuture},
+			values:    []string{"-1"},
+		},
+		{
+			name:      "days since epoch with a missing attribute",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionDaysSinceEpochInFuture},
+		},
+		{
+			name:      "days since epoch which is not a number",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionDaysSinceEpochInFuture},
+			values:    []string{"soon"},
+			wantErr:   `value "soon" is not a number of days: strconv.ParseInt: parsing "soon": invalid syntax`,
+		},
+		{
+			name:      "days since epoch with multiple values",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionDaysSinceEpochInFuture},
+			values:    []string{"20517", "20518"},
+			wantErr:   `expected at most 1 value, but found 2`,
+		},
+		{
+			name:      "generalized time in the future",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionGeneralizedTimeInFuture},
+			values:    []string{"20260304130000Z"},
+		},
+		{
+			name:      "generalized time with fractional seconds and a time zone offset in the future",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionGeneralizedTimeInFuture},
+			values:    []string{"20260304083000.5-0400"},
+		},
+		{
+			name:      "generalized time in the past",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionGeneralizedTimeInFuture},
+			values:    []string{"20260304110000Z"},
+			wantErr:   `user's account expired 2026-03-04T11:00:00Z`,
+		},
+		{
+			name:      "generalized time with a missing attribute",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionGeneralizedTimeInFuture},
+		},
+		{
+			name:      "generalized time which cannot be parsed",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: AttributeAssertionGeneralizedTimeInFuture},
+			values:    []string{"tomorrow"},
+			wantErr:   `value "tomorrow" is not a GeneralizedTime`,
+		},
+		{
+			name:      "unknown operator",
+			assertion: AttributeAssertion{Attribute: "attr", Operator: "Whatever"},
+			wantErr:   `unknown operator "Whatever"`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &ldap.Entry{DN: "uid=pinny,ou=users,dc=pinniped,dc=dev"}
+			if tt.values != nil {
+				entry.Attributes = []*ldap.EntryAttribute{ldap.NewEntryAttribute("attr", tt.values)}
+			}
+			err := tt.assertion.check(entry, now)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRefreshWithAttributeAssertions(t *testing.T) {
+	expectedUserSearch := &ldap.SearchRequest{
+		BaseDN:       testUserSearchResultDNValue,
+		Scope:        ldap.ScopeBaseObject,
+		DerefAliases: ldap.NeverDerefAliases,
+		SizeLimit:    2,
+		TimeLimit:    90,
+		Filter:       "(objectClass=*)",
+		Attributes:   []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute, "nsAccountLock"},
+	}
+
+	userSearchResult := func(nsAccountLock string) *ldap.SearchResult {
+		return &ldap.SearchResult{
+			Entries: []*ldap.Entry{
+				{
+					DN: testUserSearchResultDNValue,
+					Attributes: []*ldap.EntryAttribute{
+						ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+						ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+						ldap.NewEntryAttribute("nsAccountLock", []string{nsAccountLock}),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		nsAccountLock string
+		wantErr       string
+	}{
+		{
+			name:          "the assertion holds",
+			nsAccountLock: "false",
+		},
+		{
+			name:          "the assertion fails",
+			nsAccountLock: "true",
+			wantErr:       `assertion for attribute "nsAccountLock" failed during upstream refresh: found the value "true"`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			conn := mockldapconn.NewMockConn(ctrl)
+			conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+			conn.EXPECT().Search(expectedUserSearch).Return(userSearchResult(tt.nsAccountLock), nil).Times(1)
+			conn.EXPECT().Close().Times(1)
+
+			ldapProvider := New(ProviderConfig{
+				Name:               "some-provider-name",
+				Host:               testHost,
+				ConnectionProtocol: TLS,
+				BindUsername:       testBindUsername,
+				BindPassword:       testBindPassword,
+				UserSearch: UserSearchConfig{
+					Base:              testUserSearchBase,
+					UsernameAttribute: testUserSearchUsernameAttribute,
+					UIDAttribute:      testUserSearchUIDAttribute,
+				},
+				RefreshAttributeAssertions: []AttributeAssertion{
+					{Attribute: "nsAccountLock", Operator: AttributeAssertionNotEquals, Value: "true"},
+				},
+				Dialer: LDAPDialerFunc(func(ctx context.Context, addr endpointaddr.HostPort) (Conn, error) {
+					return conn, nil
+				}),
+			})
+
+			_, _, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+				Username: testUserSearchResultUsernameAttributeValue,
+				Subject:  "ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU",
+				DN:       testUserSearchResultDNValue,
+			})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package upstreamldap implements an abstraction of upstream LDAP IDP interactions.
@@ -14,9 +14,11 @@ import (
 	"net"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-ldap/ldap/v3"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/authentication/user"
@@ -30,15 +32,18 @@ import (
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/tracing"
 )
 
 const (
 	ldapsScheme                             = "ldaps"
 	distinguishedNameAttributeName          = "dn"
 	searchFilterInterpolationLocationMarker = "{}"
-	groupSearchPageSize                     = uint32(250)
+	defaultGroupSearchPageSize              = uint32(250)
 	defaultLDAPPort                         = uint16(389)
 	defaultLDAPSPort                        = uint16(636)
+	defaultDialTimeout                      = time.Minute
+	defaultSearchTimeout                    = 90 * time.Second
 )
 
 // Conn abstracts the upstream LDAP communication protocol (mostly for testing).
@@ -71,6 +76,27 @@ func (f LDAPDialerFunc) Dial(ctx context.Context, addr endpointaddr.HostPort) (C
 
 type LDAPConnectionProtocol string
 
+// SearchScope is the scope of a user or group search. The zero value is ScopeWholeSubtree.
+type SearchScope int
+
+const (
+	ScopeWholeSubtree SearchScope = iota
+	ScopeSingleLevel
+	ScopeBaseObject
+)
+
+// ldapScope returns the go-ldap constant for the scope.
+func (s SearchScope) ldapScope() int {
+	switch s {
+	case ScopeSingleLevel:
+		return ldap.ScopeSingleLevel
+	case ScopeBaseObject:
+		return ldap.ScopeBaseObject
+	default:
+		return ldap.ScopeWholeSubtree
+	}
+}
+
 const (
 	StartTLS = LDAPConnectionProtocol("StartTLS")
 	TLS      = LDAPConnectionProtocol("TLS")
@@ -90,24 +116,63 @@ type ProviderConfig struct {
 	// the default LDAP port will be used.
 	Host string
 
+	// FailoverHosts are the hostnames or "hostname:port" of replicas of the LDAP server, which will be tried
+	// in order when the Host cannot be reached. Can be nil.
+	FailoverHosts []string
+
+	// DiscoveredDomain is the DNS domain from which the Host and FailoverHosts were discovered, if they were discovered.
+	// When set, it is used instead of the Host to identify this provider, since the discovered hosts may change over time.
+	DiscoveredDomain string
+
 	// ConnectionProtocol determines how to establish the connection to the server. Either StartTLS or TLS.
 	ConnectionProtocol LDAPConnectionProtocol
 
 	// PEM-encoded CA cert bundle to trust when connecting to the LDAP server. Can be nil.
 	CABundle []byte
 
+	// PEM-encoded client certificate and private key to present to the LDAP server during the TLS handshake.
+	// Both can be nil, in which case no client certificate will be presented.
+	ClientCertificateData []byte
+	ClientKeyData         []byte
+
+	// ProxyURL is the URL of a SOCKS5 or HTTP CONNECT proxy through which to connect to the LDAP server.
+	// Nil means to connect to the LDAP server directly.
+	ProxyURL *url.URL
+
 	// BindUsername is the username to use when performing a bind with the upstream LDAP IDP.
 	BindUsername string
 
 	// BindPassword is the password to use when performing a bind with the upstream LDAP IDP.
 	BindPassword string
 
+	// SecondaryBindUsername and SecondaryBindPassword are optional credentials to use when performing a bind with the
+	// upstream LDAP IDP after a bind using BindUsername and BindPassword has failed, e.g. while the credentials are
+	// being rotated. Both are empty when there are no secondary credentials.
+	SecondaryBindUsername string
+	SecondaryBindPassword string
+
+	// UserDNTemplate is a template for the DN of an end user, in which UserDNTemplateUsernamePlaceholder is replaced
+	// by the user's username. When set, logins bind directly as the end user using this DN instead of searching for
+	// the user's entry, and BindUsername and BindPassword may be empty. Empty means to search for the user's entry.
+	UserDNTemplate string
+
 	// UserSearch contains information about how to search for users in the upstream LDAP IDP.
 	UserSearch UserSearchConfig
 
 	// GroupSearch contains information about how to search for group membership in the upstream LDAP IDP.
 	GroupSearch GroupSearchConfig
 
+	// DialTimeout is the maximum amount of time to wait while establishing a network connection to the
+	// upstream LDAP IDP. Zero means to use the default of one minute.
+	DialTimeout time.Duration
+
+	// SearchTimeout is the time limit which will be requested of the upstream LDAP IDP for each search.
+	// Zero means to use the default of 90 seconds.
+	SearchTimeout time.Duration
+
+	// Referrals contains information about how to follow referrals which are returned by the upstream LDAP IDP.
+	Referrals ReferralConfig
+
 	// Dialer exists to enable testing. When nil, will use a default appropriate for production use.
 	Dialer LDAPDialer
 
@@ -121,6 +186,19 @@ type ProviderConfig struct {
 
 	// RefreshAttributeChecks are extra checks that attributes in a refresh response are as expected.
 	RefreshAttributeChecks map[string]func(*ldap.Entry, provider.RefreshAttributes) error
+
+	// RefreshAttributeAssertions are assertions about the attributes of the user's entry which must all hold for a
+	// refresh to succeed. Unlike RefreshAttributeChecks, the attributes are not required to be present at login.
+	RefreshAttributeAssertions []AttributeAssertion
+
+	// AuthenticationRateLimiter limits the rate of authentication attempts using this upstream LDAP IDP. It should be
+	// shared by the Providers which are created for the same upstream LDAP IDP over time, so that the limits apply
+	// across them. Nil means that the rate of authentication attempts is not limited.
+	AuthenticationRateLimiter *AuthenticationRateLimiter
+
+	// AdditionalClaimMappings are mappings from the names of additional claims for downstream ID tokens to the
+	// names of the attributes of the user's entry whose values should be used for those claims.
+	AdditionalClaimMappings map[string]string
 }
 
 // UserSearchConfig contains information about how to search for users in the upstream LDAP IDP.
@@ -138,6 +216,18 @@ type UserSearchConfig struct {
 	// UIDAttribute is the attribute in the LDAP entry from which the user's unique ID should be
 	// retrieved.
 	UIDAttribute string
+
+	// Scope is the scope of the user search. The zero value means to search the whole subtree.
+	Scope SearchScope
+
+	// DerefAliases is one of the go-ldap alias dereferencing constants, e.g. ldap.DerefAlways. The zero value is
+	// ldap.NeverDerefAliases.
+	DerefAliases int
+
+	// UseGlobalCatalog causes the user search to be performed on the Active Directory Global Catalog port of the
+	// hosts, which allows users from every domain of the forest to be found. Binds and group searches are still
+	// performed on the usual port of the hosts.
+	UseGlobalCatalog bool
 }
 
 // GroupSearchConfig contains information about how to search for group membership for users in the upstream LDAP IDP.
@@ -149,6 +239,10 @@ type GroupSearchConfig struct {
 	// Filter is the filter to use for the group search in the upstream LDAP IDP. Empty means to use `member={}`.
 	Filter string
 
+	// UserAttributeForFilter is the name of the user attribute whose value should be used to replace the placeholder
+	// in the Filter. Empty means to use 'dn'.
+	UserAttributeForFilter string
+
 	// GroupNameAttribute is the attribute in the LDAP group entry from which the group name should be
 	// retrieved. Empty means to use 'cn'.
 	GroupNameAttribute string
@@ -157,10 +251,47 @@ type GroupSearchConfig struct {
 	// (every 5 minutes). This can be done if group search is very slow or resource intensive for the LDAP
 	// server.
 	SkipGroupRefresh bool
+
+	// RefreshInterval is the minimum amount of time between group searches during refreshes. The groups from
+	// the previous group search are kept until it has elapsed. Zero means to search for groups on every refresh.
+	RefreshInterval time.Duration
+
+	// PageSize is the number of group entries to request per page of paged search results. All pages are
+	// combined into the final result. Zero means to use the default page size.
+	PageSize uint32
+
+	// Scope is the scope of the group search. The zero value means to search the whole subtree.
+	Scope SearchScope
+
+	// DerefAliases is one of the go-ldap alias dereferencing constants, e.g. ldap.DerefAlways. The zero value is
+	// ldap.NeverDerefAliases.
+	DerefAliases int
+
+	// GroupNameTransformations are applied, in order, to each group name found by the group search. Empty means
+	// to use the group names as they were found.
+	GroupNameTransformations []GroupNameTransformation
+}
+
+// ReferralConfig contains information about how to follow referrals which are returned by the upstream LDAP IDP
+// during searches.
+type ReferralConfig struct {
+	// Follow enables following referrals. When false, referrals are ignored.
+	Follow bool
+
+	// MaxHops is the maximum length of a chain of referrals to follow. Zero means to use the default of 3.
+	MaxHops int
+
+	// Anonymous means to search referred servers without first binding as the bind account.
+	Anonymous bool
 }
 
 type Provider struct {
-	c ProviderConfig
+	c                   ProviderConfig
+	health              *hostHealth
+	globalCatalogHealth *hostHealth
+
+	// usingSecondaryBindCredentials remembers which of the bind credentials were used by the most recent successful bind.
+	usingSecondaryBindCredentials *atomic.Bool
 }
 
 var _ provider.UpstreamLDAPIdentityProviderI = &Provider{}
@@ -169,7 +300,12 @@ var _ authenticators.UserAuthenticator = &Provider{}
 // Create a Provider. The config is not a pointer to ensure that a copy of the config is created,
 // making the resulting Provider use an effectively read-only configuration.
 func New(config ProviderConfig) *Provider {
-	return &Provider{c: config}
+	return &Provider{
+		c:                             config,
+		health:                        newHostHealth(),
+		globalCatalogHealth:           newHostHealth(),
+		usingSecondaryBindCredentials: &atomic.Bool{},
+	}
 }
 
 // A reader for the config. Returns a copy of the config to keep the underlying config read-only.
@@ -177,113 +313,142 @@ func (p *Provider) GetConfig() ProviderConfig {
 	return p.c
 }
 
-func (p *Provider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, error) {
+func (p *Provider) GetGroupRefreshInterval() time.Duration {
+	return p.c.GroupSearch.RefreshInterval
+}
+
+func (p *Provider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, map[string]interface{}, error) {
+	start := time.Now()
+	groups, additionalClaims, err := p.performRefresh(ctx, storedRefreshAttributes)
+	refreshMetrics.observe(p.GetName(), start, err)
+	return groups, additionalClaims, err
+}
+
+func (p *Provider) performRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, map[string]interface{}, error) {
 	t := trace.FromContext(ctx).Nest("slow ldap refresh attempt", trace.Field{Key: "providerName", Value: p.GetName()})
 	defer t.LogIfLong(500 * time.Millisecond) // to help users debug slow LDAP searches
 	userDN := storedRefreshAttributes.DN
 
+	if !p.hasBindAccount() {
+		// The user's entry cannot be read again without the user's password, so keep the groups from the login.
+		plog.Debug("skipping the refresh of the user's entry because there is no bind account",
+			"upstreamName", p.GetName(), "dn", userDN)
+		if !slices.Contains(storedRefreshAttributes.GrantedScopes, oidcapi.ScopeGroups) {
+			return nil, nil, nil
+		}
+		return storedRefreshAttributes.Groups, nil, nil
+	}
+
 	conn, err := p.dial(ctx)
 	if err != nil {
-		return nil, fmt.Errorf(`error dialing host %q: %w`, p.c.Host, err)
+		return nil, nil, err
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	err = p.bindAsServiceAccount(conn)
 	if err != nil {
-		return nil, fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
+		return nil, nil, fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
 	}
 
-	searchResult, err := p.performUserRefreshSearch(conn, userDN)
+	searchConn, closeSearchConn, err := p.userSearchConn(ctx, conn)
 	if err != nil {
 		p.traceRefreshFailure(t, err)
-		return nil, err
+		return nil, nil, err
+	}
+	defer closeSearchConn()
+
+	userEntries, err := p.performUserRefreshSearch(ctx, searchConn, userDN)
+	if err != nil {
+		p.traceRefreshFailure(t, err)
+		return nil, nil, err
 	}
 
 	// if any more or less than one entry, error.
 	// we don't need to worry about logging this because we know it's a dn.
-	if len(searchResult.Entries) != 1 {
-		return nil, fmt.Errorf(`searching for user %q resulted in %d search results, but expected 1 result`,
-			userDN, len(searchResult.Entries),
+	if len(userEntries) != 1 {
+		return nil, nil, fmt.Errorf(`searching for user %q resulted in %d search results, but expected 1 result`,
+			userDN, len(userEntries),
 		)
 	}
 
-	userEntry := searchResult.Entries[0]
+	userEntry := userEntries[0].Entry
 	if len(userEntry.DN) == 0 {
-		return nil, fmt.Errorf(`searching for user with original DN %q resulted in search result without DN`, userDN)
+		return nil, nil, fmt.Errorf(`searching for user with original DN %q resulted in search result without DN`, userDN)
 	}
 
 	newUsername, err := p.getSearchResultAttributeValue(p.c.UserSearch.UsernameAttribute, userEntry, userDN)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if newUsername != storedRefreshAttributes.Username {
-		return nil, fmt.Errorf(`searching for user %q returned a different username than the previous value. expected: %q, actual: %q`,
+		return nil, nil, fmt.Errorf(`searching for user %q returned a different username than the previous value. expected: %q, actual: %q`,
 			userDN, storedRefreshAttributes.Username, newUsername,
 		)
 	}
 
 	newUID, err := p.getSearchResultAttributeRawValueEncoded(p.c.UserSearch.UIDAttribute, userEntry, userDN)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	newSubject := downstreamsession.DownstreamLDAPSubject(newUID, *p.GetURL())
 	if newSubject != storedRefreshAttributes.Subject {
-		return nil, fmt.Errorf(`searching for user %q produced a different subject than the previous value. expected: %q, actual: %q`, userDN, storedRefreshAttributes.Subject, newSubject)
+		return nil, nil, fmt.Errorf(`searching for user %q produced a different subject than the previous value. expected: %q, actual: %q`, userDN, storedRefreshAttributes.Subject, newSubject)
 	}
 	for attribute, validateFunc := range p.c.RefreshAttributeChecks {
 		err = validateFunc(userEntry, storedRefreshAttributes)
 		if err != nil {
-			return nil, fmt.Errorf(`validation for attribute %q failed during upstream refresh: %w`, attribute, err)
+			return nil, nil, fmt.Errorf(`validation for attribute %q failed during upstream refresh: %w`, attribute, err)
 		}
 	}
+	if err := p.checkRefreshAttributeAssertions(userEntry); err != nil {
+		return nil, nil, err
+	}
+
+	additionalClaims := p.additionalClaims(userEntry)
 
-	if p.c.GroupSearch.SkipGroupRefresh {
-		return storedRefreshAttributes.Groups, nil
+	if p.c.GroupSearch.SkipGroupRefresh || !storedRefreshAttributes.GroupRefreshIsDue(p.c.GroupSearch.RefreshInterval) {
+		return storedRefreshAttributes.Groups, additionalClaims, nil
 	}
 	// if we were not granted the groups scope, we should not search for groups or return any.
 	if !slices.Contains(storedRefreshAttributes.GrantedScopes, oidcapi.ScopeGroups) {
-		return nil, nil
+		return nil, additionalClaims, nil
 	}
 
-	mappedGroupNames, err := p.searchGroupsForUserDN(conn, userDN)
+	groupSearchFilterValue, err := p.groupSearchFilterValue(userEntry, userDN)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	mappedGroupNames, err := p.searchGroupsForUserMembership(ctx, conn, userDN, groupSearchFilterValue)
+	if err != nil {
+		return nil, nil, err
 	}
-	return mappedGroupNames, nil
+	return mappedGroupNames, additionalClaims, nil
 }
 
-func (p *Provider) performUserRefreshSearch(conn Conn, userDN string) (*ldap.SearchResult, error) {
+func (p *Provider) performUserRefreshSearch(ctx context.Context, conn Conn, userDN string) ([]foundEntry, error) {
 	search := p.refreshUserSearchRequest(userDN)
 
-	searchResult, err := conn.Search(search)
+	userEntries, err := p.searchFollowingReferrals(ctx, conn, search, Conn.Search)
 
 	if err != nil {
 		return nil, fmt.Errorf(`error searching for user %q: %w`, userDN, err)
 	}
-	return searchResult, nil
+	return userEntries, nil
 }
 
-func (p *Provider) dial(ctx context.Context) (Conn, error) {
-	tlsAddr, err := endpointaddr.Parse(p.c.Host, defaultLDAPSPort)
-	if err != nil {
-		return nil, ldap.NewError(ldap.ErrorNetwork, err)
-	}
-
-	startTLSAddr, err := endpointaddr.Parse(p.c.Host, defaultLDAPPort)
+func (p *Provider) dialHost(ctx context.Context, host string) (Conn, error) {
+	addr, err := p.hostAddr(host)
 	if err != nil {
 		return nil, ldap.NewError(ldap.ErrorNetwork, err)
 	}
 
-	// Choose how and where to dial based on TLS vs. StartTLS config option.
+	// Choose how to dial based on TLS vs. StartTLS config option.
 	var dialFunc LDAPDialerFunc
-	var addr endpointaddr.HostPort
 	switch {
 	case p.c.ConnectionProtocol == TLS:
 		dialFunc = p.dialTLS
-		addr = tlsAddr
 	case p.c.ConnectionProtocol == StartTLS:
 		dialFunc = p.dialStartTLS
-		addr = startTLSAddr
 	default:
 		return nil, ldap.NewError(ldap.ErrorNetwork, fmt.Errorf("did not specify valid ConnectionProtocol"))
 	}
@@ -296,6 +461,15 @@ func (p *Provider) dial(ctx context.Context) (Conn, error) {
 	return dialFunc(ctx, addr)
 }
 
+// hostAddr returns the address which dialHost dials for the host, using the default port of the configured
+// connection protocol when the host does not specify a port.
+func (p *Provider) hostAddr(host string) (endpointaddr.HostPort, error) {
+	if p.c.ConnectionProtocol == TLS {
+		return endpointaddr.Parse(host, defaultLDAPSPort)
+	}
+	return endpointaddr.Parse(host, defaultLDAPPort)
+}
+
 // dialTLS is a default implementation of the Dialer, used when Dialer is nil and ConnectionProtocol is TLS.
 // Unfortunately, the go-ldap library does not seem to support dialing with a context.Context,
 // so we implement it ourselves, heavily inspired by ldap.DialURL.
@@ -305,8 +479,13 @@ func (p *Provider) dialTLS(ctx context.Context, addr endpointaddr.HostPort) (Con
 		return nil, ldap.NewError(ldap.ErrorNetwork, err)
 	}
 
-	dialer := &tls.Dialer{NetDialer: netDialer(), Config: tlsConfig}
-	c, err := dialer.DialContext(ctx, "tcp", addr.Endpoint())
+	var c net.Conn
+	if p.c.ProxyURL == nil {
+		dialer := &tls.Dialer{NetDialer: p.netDialer(), Config: tlsConfig}
+		c, err = dialer.DialContext(ctx, "tcp", addr.Endpoint())
+	} else {
+		c, err = p.dialTLSThroughProxy(ctx, addr, tlsConfig)
+	}
 	if err != nil {
 		return nil, ldap.NewError(ldap.ErrorNetwork, err)
 	}
@@ -328,7 +507,12 @@ func (p *Provider) dialStartTLS(ctx context.Context, addr endpointaddr.HostPort)
 	// Unfortunately, this seems to be required for StartTLS, even though it is not needed for regular TLS.
 	tlsConfig.ServerName = addr.Host
 
-	c, err := netDialer().DialContext(ctx, "tcp", addr.Endpoint())
+	var c net.Conn
+	if p.c.ProxyURL == nil {
+		c, err = p.netDialer().DialContext(ctx, "tcp", addr.Endpoint())
+	} else {
+		c, err = p.dialThroughProxy(ctx, addr.Endpoint())
+	}
 	if err != nil {
 		return nil, ldap.NewError(ldap.ErrorNetwork, err)
 	}
@@ -343,8 +527,49 @@ func (p *Provider) dialStartTLS(ctx context.Context, addr endpointaddr.HostPort)
 	return conn, nil
 }
 
-func netDialer() *net.Dialer {
-	return &net.Dialer{Timeout: time.Minute}
+// dialTLSThroughProxy connects to the address through the proxy and then performs the TLS handshake,
+// like tls.Dialer would do for a direct connection.
+func (p *Provider) dialTLSThroughProxy(ctx context.Context, addr endpointaddr.HostPort, tlsConfig *tls.Config) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.netDialer().Timeout)
+	defer cancel()
+
+	c, err := p.dialThroughProxy(ctx, addr.Endpoint())
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig.ServerName = addr.Host
+	tlsConn := tls.Client(c, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+func (p *Provider) netDialer() *net.Dialer {
+	timeout := p.c.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+	return &net.Dialer{Timeout: timeout}
+}
+
+// searchTimeLimitSeconds returns the time limit to include in search requests, which LDAP expresses in whole seconds.
+func (p *Provider) searchTimeLimitSeconds() int {
+	timeout := p.c.SearchTimeout
+	if timeout <= 0 {
+		timeout = defaultSearchTimeout
+	}
+	return int(timeout.Seconds())
+}
+
+func (p *Provider) groupSearchPageSize() uint32 {
+	if p.c.GroupSearch.PageSize == 0 {
+		return defaultGroupSearchPageSize
+	}
+	return p.c.GroupSearch.PageSize
 }
 
 func (p *Provider) tlsConfig() (*tls.Config, error) {
@@ -355,7 +580,15 @@ func (p *Provider) tlsConfig() (*tls.Config, error) {
 			return nil, fmt.Errorf("could not parse CA bundle")
 		}
 	}
-	return ptls.DefaultLDAP(rootCAs), nil
+	tlsConfig := ptls.DefaultLDAP(rootCAs)
+	if len(p.c.ClientCertificateData) > 0 || len(p.c.ClientKeyData) > 0 {
+		clientCert, err := tls.X509KeyPair(p.c.ClientCertificateData, p.c.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	return tlsConfig, nil
 }
 
 // A name for this upstream provider.
@@ -371,7 +604,11 @@ func (p *Provider) GetResourceUID() types.UID {
 // This URL is not used for connecting to the provider, but rather is used for creating a globally unique user
 // identifier by being combined with the user's UID, since user UIDs are only unique within one provider.
 func (p *Provider) GetURL() *url.URL {
-	u := &url.URL{Scheme: ldapsScheme, Host: p.c.Host}
+	host := p.c.Host
+	if len(p.c.DiscoveredDomain) > 0 {
+		host = p.c.DiscoveredDomain
+	}
+	u := &url.URL{Scheme: ldapsScheme, Host: host}
 	q := u.Query()
 	q.Set("base", p.c.UserSearch.Base)
 	u.RawQuery = q.Encode()
@@ -379,7 +616,7 @@ func (p *Provider) GetURL() *url.URL {
 }
 
 // TestConnection provides a method for testing the connection and bind settings. It performs a dial and bind
-// and returns any errors that we encountered.
+// and returns any errors that we encountered. When there is no bind account, it only performs a dial.
 func (p *Provider) TestConnection(ctx context.Context) error {
 	err := p.validateConfig()
 	if err != nil {
@@ -388,11 +625,16 @@ func (p *Provider) TestConnection(ctx context.Context) error {
 
 	conn, err := p.dial(ctx)
 	if err != nil {
-		return fmt.Errorf(`error dialing host %q: %w`, p.c.Host, err)
+		return err
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	if !p.hasBindAccount() {
+		// Logins bind directly as the end user, so there are no credentials which could be tested.
+		return nil
+	}
+
+	err = p.bindAsServiceAccount(conn)
 	if err != nil {
 		return fmt.Errorf(`error binding as %q: %w`, p.c.BindUsername, err)
 	}
@@ -403,7 +645,8 @@ func (p *Provider) TestConnection(ctx context.Context) error {
 // DryRunAuthenticateUser provides a method for testing all of the Provider settings in a kind of dry run of
 // authentication for a given end user's username. It runs the same logic as AuthenticateUser except it does
 // not bind as that user, so it does not test their password. It returns the same values that a real call to
-// AuthenticateUser with the correct password would return.
+// AuthenticateUser with the correct password would return. When logins bind directly as the end user, the user's
+// entry is read without binding, so the LDAP server must allow it to be read anonymously.
 func (p *Provider) DryRunAuthenticateUser(ctx context.Context, username string, grantedScopes []string) (*authenticators.Response, bool, error) {
 	endUserBindFunc := func(conn Conn, foundUserDN string) error {
 		// Act as if the end user bind always succeeds.
@@ -413,7 +656,17 @@ func (p *Provider) DryRunAuthenticateUser(ctx context.Context, username string,
 }
 
 // Authenticate an end user and return their mapped username, groups, and UID. Implements authenticators.UserAuthenticator.
-func (p *Provider) AuthenticateUser(ctx context.Context, username, password string, grantedScopes []string) (*authenticators.Response, bool, error) {
+func (p *Provider) AuthenticateUser(ctx context.Context, username, password string, grantedScopes []string) (_ *authenticators.Response, _ bool, err error) {
+	ctx, span := tracing.Start(ctx, "upstream LDAP authentication",
+		attribute.String("pinniped.upstream.name", p.GetName()),
+		attribute.String("pinniped.upstream.type", "ldap"),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.checkAuthenticationRateLimits(ctx, username); err != nil {
+		return nil, false, err
+	}
+
 	endUserBindFunc := func(conn Conn, foundUserDN string) error {
 		return conn.Bind(foundUserDN, password)
 	}
@@ -439,17 +692,22 @@ func (p *Provider) authenticateUserImpl(ctx context.Context, username string, gr
 	conn, err := p.dial(ctx)
 	if err != nil {
 		p.traceAuthFailure(t, err)
-		return nil, false, fmt.Errorf(`error dialing host %q: %w`, p.c.Host, err)
+		return nil, false, err
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
-	if err != nil {
-		p.traceAuthFailure(t, err)
-		return nil, false, fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
-	}
+	var response *authenticators.Response
+	if p.usingDirectBind() {
+		response, err = p.bindAndReadUser(ctx, conn, username, grantedScopes, bindFunc)
+	} else {
+		err = p.bindAsServiceAccount(conn)
+		if err != nil {
+			p.traceAuthFailure(t, err)
+			return nil, false, fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
+		}
 
-	response, err := p.searchAndBindUser(conn, username, grantedScopes, bindFunc)
+		response, err = p.searchAndBindUser(ctx, conn, username, grantedScopes, bindFunc)
+	}
 	if err != nil {
 		p.traceAuthFailure(t, err)
 		return nil, false, err
@@ -463,13 +721,24 @@ func (p *Provider) authenticateUserImpl(ctx context.Context, username string, gr
 	return response, true, nil
 }
 
-func (p *Provider) searchGroupsForUserDN(conn Conn, userDN string) ([]string, error) {
+// groupSearchFilterValue returns the value from the user's entry which should be interpolated into the group search filter.
+func (p *Provider) groupSearchFilterValue(userEntry *ldap.Entry, username string) (string, error) {
+	if len(p.c.GroupSearch.UserAttributeForFilter) == 0 {
+		return userEntry.DN, nil
+	}
+	return p.getSearchResultAttributeValue(p.c.GroupSearch.UserAttributeForFilter, userEntry, username)
+}
+
+func (p *Provider) searchGroupsForUserMembership(ctx context.Context, conn Conn, userDN string, groupSearchFilterValue string) ([]string, error) {
 	// If we do not have group search configured, skip this search.
 	if len(p.c.GroupSearch.Base) == 0 {
 		return []string{}, nil
 	}
 
-	searchResult, err := conn.SearchWithPaging(p.groupSearchRequest(userDN), groupSearchPageSize)
+	pagedSearch := func(conn Conn, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+		return conn.SearchWithPaging(searchRequest, p.groupSearchPageSize())
+	}
+	groupEntries, err := p.searchFollowingReferrals(ctx, conn, p.groupSearchRequest(groupSearchFilterValue), pagedSearch)
 	if err != nil {
 		return nil, fmt.Errorf(`error searching for group memberships for user with DN %q: %w`, userDN, err)
 	}
@@ -481,12 +750,12 @@ func (p *Provider) searchGroupsForUserDN(conn Conn, userDN string) ([]string, er
 
 	groups := []string{}
 entries:
-	for _, groupEntry := range searchResult.Entries {
+	for _, groupEntry := range groupEntries {
 		if len(groupEntry.DN) == 0 {
 			return nil, fmt.Errorf(`searching for group memberships for user with DN %q resulted in search result without DN`, userDN)
 		}
 		if overrideFunc := p.c.GroupAttributeParsingOverrides[groupAttributeName]; overrideFunc != nil {
-			overrideGroupName, err := overrideFunc(groupEntry)
+			overrideGroupName, err := overrideFunc(groupEntry.Entry)
 			if err != nil {
 				return nil, fmt.Errorf("error finding groups for user %s: %w", userDN, err)
 			}
@@ -494,7 +763,7 @@ entries:
 			continue entries
 		}
 		// if none of the overrides matched, use the default behavior (no mapping)
-		mappedGroupName, err := p.getSearchResultAttributeValue(groupAttributeName, groupEntry, userDN)
+		mappedGroupName, err := p.getSearchResultAttributeValue(groupAttributeName, groupEntry.Entry, userDN)
 		if err != nil {
 			return nil, fmt.Errorf(`error searching for group memberships for user with DN %q: %w`, userDN, err)
 		}
@@ -502,10 +771,16 @@ entries:
 	}
 	// de-duplicate the list of groups by turning it into a set,
 	// then turn it back into a sorted list.
-	return sets.NewString(groups...).List(), nil
+	return sets.NewString(p.transformGroupNames(groups)...).List(), nil
 }
 
 func (p *Provider) validateConfig() error {
+	if p.usingDirectBind() {
+		if !strings.Contains(p.c.UserDNTemplate, UserDNTemplateUsernamePlaceholder) {
+			return fmt.Errorf(`UserDNTemplate must contain %q`, UserDNTemplateUsernamePlaceholder)
+		}
+		return nil
+	}
 	if p.c.UserSearch.UsernameAttribute == distinguishedNameAttributeName && len(p.c.UserSearch.Filter) == 0 {
 		// LDAP search filters do not allow searching by DN, so we would have no reasonable default for Filter.
 		return fmt.Errorf(`must specify UserSearch Filter when UserSearch UsernameAttribute is "dn"`)
@@ -514,40 +789,56 @@ func (p *Provider) validateConfig() error {
 }
 
 func (p *Provider) SearchForDefaultNamingContext(ctx context.Context) (string, error) {
-	t := trace.FromContext(ctx).Nest("slow ldap attempt when searching for default naming context", trace.Field{Key: "providerName", Value: p.GetName()})
+	return p.searchRootDSEForNamingContext(ctx, "defaultNamingContext", "default naming context")
+}
+
+// SearchForRootDomainNamingContext returns the DN of the root domain of the Active Directory forest, which can be
+// used as a search base which includes the domains of the forest when searching the Global Catalog.
+func (p *Provider) SearchForRootDomainNamingContext(ctx context.Context) (string, error) {
+	return p.searchRootDSEForNamingContext(ctx, "rootDomainNamingContext", "root domain naming context")
+}
+
+func (p *Provider) searchRootDSEForNamingContext(ctx context.Context, attributeName string, description string) (string, error) {
+	t := trace.FromContext(ctx).Nest("slow ldap attempt when searching for "+description, trace.Field{Key: "providerName", Value: p.GetName()})
 	defer t.LogIfLong(500 * time.Millisecond) // to help users debug slow LDAP searches
 
 	conn, err := p.dial(ctx)
 	if err != nil {
 		p.traceSearchBaseDiscoveryFailure(t, err)
-		return "", fmt.Errorf(`error dialing host %q: %w`, p.c.Host, err)
+		return "", err
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	err = p.bindAsServiceAccount(conn)
 	if err != nil {
 		p.traceSearchBaseDiscoveryFailure(t, err)
-		return "", fmt.Errorf(`error binding as %q before querying for defaultNamingContext: %w`, p.c.BindUsername, err)
+		return "", fmt.Errorf(`error binding as %q before querying for %s: %w`, p.c.BindUsername, attributeName, err)
 	}
 
-	searchResult, err := conn.Search(p.defaultNamingContextRequest())
+	searchResult, err := conn.Search(p.rootDSERequest(attributeName))
 	if err != nil {
-		return "", fmt.Errorf(`error querying RootDSE for defaultNamingContext: %w`, err)
+		return "", fmt.Errorf(`error querying RootDSE for %s: %w`, attributeName, err)
 	}
 
 	if len(searchResult.Entries) != 1 {
-		return "", fmt.Errorf(`error querying RootDSE for defaultNamingContext: expected to find 1 entry but found %d`, len(searchResult.Entries))
+		return "", fmt.Errorf(`error querying RootDSE for %s: expected to find 1 entry but found %d`, attributeName, len(searchResult.Entries))
 	}
-	searchBase := searchResult.Entries[0].GetAttributeValue("defaultNamingContext")
+	searchBase := searchResult.Entries[0].GetAttributeValue(attributeName)
 	if searchBase == "" {
 		// if we get an empty search base back, treat it like an error. Otherwise we might make too broad of a search.
-		return "", fmt.Errorf(`error querying RootDSE for defaultNamingContext: empty search base DN found`)
+		return "", fmt.Errorf(`error querying RootDSE for %s: empty search base DN found`, attributeName)
 	}
 	return searchBase, nil
 }
 
-func (p *Provider) searchAndBindUser(conn Conn, username string, grantedScopes []string, bindFunc func(conn Conn, foundUserDN string) error) (*authenticators.Response, error) {
-	searchResult, err := conn.Search(p.userSearchRequest(username))
+func (p *Provider) searchAndBindUser(ctx context.Context, conn Conn, username string, grantedScopes []string, bindFunc func(conn Conn, foundUserDN string) error) (*authenticators.Response, error) {
+	searchConn, closeSearchConn, err := p.userSearchConn(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSearchConn()
+
+	userEntries, err := p.searchFollowingReferrals(ctx, searchConn, p.userSearchRequest(username), Conn.Search)
 	if err != nil {
 		plog.All(`error searching for user`,
 			"upstreamName", p.GetName(),
@@ -556,7 +847,7 @@ func (p *Provider) searchAndBindUser(conn Conn, username string, grantedScopes [
 		)
 		return nil, fmt.Errorf(`error searching for user: %w`, err)
 	}
-	if len(searchResult.Entries) == 0 {
+	if len(userEntries) == 0 {
 		if plog.Enabled(plog.LevelAll) {
 			plog.All("error finding user: user not found (if this username is valid, please check the user search configuration)",
 				"upstreamName", p.GetName(),
@@ -570,16 +861,44 @@ func (p *Provider) searchAndBindUser(conn Conn, username string, grantedScopes [
 
 	// At this point, we have matched at least one entry, so we can be confident that the username is not actually
 	// someone's password mistakenly entered into the username field, so we can log it without concern.
-	if len(searchResult.Entries) > 1 {
+	if len(userEntries) > 1 {
 		return nil, fmt.Errorf(`searching for user %q resulted in %d search results, but expected 1 result`,
-			username, len(searchResult.Entries),
+			username, len(userEntries),
 		)
 	}
-	userEntry := searchResult.Entries[0]
+	userEntry := userEntries[0].Entry
 	if len(userEntry.DN) == 0 {
 		return nil, fmt.Errorf(`searching for user %q resulted in search result without DN`, username)
 	}
 
+	response, err := p.responseForUserEntry(ctx, conn, userEntry, username, grantedScopes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caution: Note that any other LDAP commands after this bind will be run as this user instead of as the configured BindUsername!
+	err = p.bindEndUser(ctx, conn, userEntries[0].referral, userEntry.DN, bindFunc)
+	if err != nil {
+		plog.DebugErr("error binding for user (if this is not the expected dn for this username, please check the user search configuration)",
+			err, "upstreamName", p.GetName(), "username", username, "dn", userEntry.DN)
+		ldapErr := &ldap.Error{}
+		if errors.As(err, &ldapErr) && ldapErr.ResultCode == ldap.LDAPResultInvalidCredentials {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(`error binding for user %q using provided password against DN %q: %w`, username, userEntry.DN, err)
+	}
+
+	if len(response.User.GetName()) == 0 || len(response.User.GetUID()) == 0 {
+		// Couldn't find the username or couldn't bind using the password.
+		return nil, nil
+	}
+
+	return response, nil
+}
+
+// responseForUserEntry maps the user's entry to the username, UID, groups, and other attributes of the user.
+// The groups are searched using the given connection.
+func (p *Provider) responseForUserEntry(ctx context.Context, conn Conn, userEntry *ldap.Entry, username string, grantedScopes []string) (*authenticators.Response, error) {
 	mappedUsername, err := p.getSearchResultAttributeValue(p.c.UserSearch.UsernameAttribute, userEntry, username)
 	if err != nil {
 		return nil, err
@@ -594,7 +913,11 @@ func (p *Provider) searchAndBindUser(conn Conn, username string, grantedScopes [
 
 	var mappedGroupNames []string
 	if slices.Contains(grantedScopes, oidcapi.ScopeGroups) {
-		mappedGroupNames, err = p.searchGroupsForUserDN(conn, userEntry.DN)
+		groupSearchFilterValue, err := p.groupSearchFilterValue(userEntry, username)
+		if err != nil {
+			return nil, err
+		}
+		mappedGroupNames, err = p.searchGroupsForUserMembership(ctx, conn, userEntry.DN, groupSearchFilterValue)
 		if err != nil {
 			return nil, err
 		}
@@ -609,24 +932,7 @@ func (p *Provider) searchAndBindUser(conn Conn, username string, grantedScopes [
 		mappedRefreshAttributes[k] = mappedVal
 	}
 
-	// Caution: Note that any other LDAP commands after this bind will be run as this user instead of as the configured BindUsername!
-	err = bindFunc(conn, userEntry.DN)
-	if err != nil {
-		plog.DebugErr("error binding for user (if this is not the expected dn for this username, please check the user search configuration)",
-			err, "upstreamName", p.GetName(), "username", username, "dn", userEntry.DN)
-		ldapErr := &ldap.Error{}
-		if errors.As(err, &ldapErr) && ldapErr.ResultCode == ldap.LDAPResultInvalidCredentials {
-			return nil, nil
-		}
-		return nil, fmt.Errorf(`error binding for user %q using provided password against DN %q: %w`, username, userEntry.DN, err)
-	}
-
-	if len(mappedUsername) == 0 || len(mappedUID) == 0 {
-		// Couldn't find the username or couldn't bind using the password.
-		return nil, nil
-	}
-
-	response := &authenticators.Response{
+	return &authenticators.Response{
 		User: &user.DefaultInfo{
 			Name:   mappedUsername,
 			UID:    mappedUID,
@@ -634,21 +940,36 @@ func (p *Provider) searchAndBindUser(conn Conn, username string, grantedScopes [
 		},
 		DN:                     userEntry.DN,
 		ExtraRefreshAttributes: mappedRefreshAttributes,
+		AdditionalClaims:       p.additionalClaims(userEntry),
+	}, nil
+}
+
+// bindEndUser performs the end user bind on the server where the user's entry was found. When the entry was found by
+// following a referral, a new connection is made to the referred server for the bind.
+func (p *Provider) bindEndUser(ctx context.Context, conn Conn, referral *referralTarget, userDN string, bindFunc func(conn Conn, foundUserDN string) error) error {
+	if referral == nil {
+		return bindFunc(conn, userDN)
 	}
 
-	return response, nil
+	referredConn, err := p.dialReferral(ctx, referral)
+	if err != nil {
+		return err
+	}
+	defer referredConn.Close()
+
+	return bindFunc(referredConn, userDN)
 }
 
-func (p *Provider) defaultNamingContextRequest() *ldap.SearchRequest {
+func (p *Provider) rootDSERequest(attributeName string) *ldap.SearchRequest {
 	return &ldap.SearchRequest{
 		BaseDN:       "",
 		Scope:        ldap.ScopeBaseObject,
 		DerefAliases: ldap.NeverDerefAliases,
 		SizeLimit:    2,
-		TimeLimit:    90,
+		TimeLimit:    p.searchTimeLimitSeconds(),
 		TypesOnly:    false,
 		Filter:       "(objectClass=*)",
-		Attributes:   []string{"defaultNamingContext"},
+		Attributes:   []string{attributeName},
 		Controls:     nil, // don't need paging because we set the SizeLimit so small
 	}
 }
@@ -657,10 +978,10 @@ func (p *Provider) userSearchRequest(username string) *ldap.SearchRequest {
 	// See https://ldap.com/the-ldap-search-operation for general documentation of LDAP search options.
 	return &ldap.SearchRequest{
 		BaseDN:       p.c.UserSearch.Base,
-		Scope:        ldap.ScopeWholeSubtree,
-		DerefAliases: ldap.NeverDerefAliases,
+		Scope:        p.c.UserSearch.Scope.ldapScope(),
+		DerefAliases: p.c.UserSearch.DerefAliases,
 		SizeLimit:    2,
-		TimeLimit:    90,
+		TimeLimit:    p.searchTimeLimitSeconds(),
 		TypesOnly:    false,
 		Filter:       p.userSearchFilter(username),
 		Attributes:   p.userSearchRequestedAttributes(),
@@ -668,16 +989,16 @@ func (p *Provider) userSearchRequest(username string) *ldap.SearchRequest {
 	}
 }
 
-func (p *Provider) groupSearchRequest(userDN string) *ldap.SearchRequest {
+func (p *Provider) groupSearchRequest(groupSearchFilterValue string) *ldap.SearchRequest {
 	// See https://ldap.com/the-ldap-search-operation for general documentation of LDAP search options.
 	return &ldap.SearchRequest{
 		BaseDN:       p.c.GroupSearch.Base,
-		Scope:        ldap.ScopeWholeSubtree,
-		DerefAliases: ldap.NeverDerefAliases,
+		Scope:        p.c.GroupSearch.Scope.ldapScope(),
+		DerefAliases: p.c.GroupSearch.DerefAliases,
 		SizeLimit:    0, // unlimited size because we will search with paging
-		TimeLimit:    90,
+		TimeLimit:    p.searchTimeLimitSeconds(),
 		TypesOnly:    false,
-		Filter:       p.groupSearchFilter(userDN),
+		Filter:       p.groupSearchFilter(groupSearchFilterValue),
 		Attributes:   p.groupSearchRequestedAttributes(),
 		Controls:     nil, // nil because ldap.SearchWithPaging() will set the appropriate controls for us
 	}
@@ -690,14 +1011,24 @@ func (p *Provider) refreshUserSearchRequest(dn string) *ldap.SearchRequest {
 		Scope:        ldap.ScopeBaseObject,
 		DerefAliases: ldap.NeverDerefAliases,
 		SizeLimit:    2,
-		TimeLimit:    90,
+		TimeLimit:    p.searchTimeLimitSeconds(),
 		TypesOnly:    false,
 		Filter:       "(objectClass=*)", // we already have the dn, so the filter doesn't matter
-		Attributes:   p.userSearchRequestedAttributes(),
+		Attributes:   p.refreshUserSearchRequestedAttributes(),
 		Controls:     nil, // this could be used to enable paging, but we're already limiting the result max size
 	}
 }
 
+func (p *Provider) refreshUserSearchRequestedAttributes() []string {
+	attributes := p.userSearchRequestedAttributes()
+	for _, attributeName := range p.refreshAttributeAssertionAttributes() {
+		if !slices.Contains(attributes, attributeName) {
+			attributes = append(attributes, attributeName)
+		}
+	}
+	return attributes
+}
+
 func (p *Provider) userSearchRequestedAttributes() []string {
 	attributes := make([]string, 0, len(p.c.RefreshAttributeChecks)+2)
 	if p.c.UserSearch.UsernameAttribute != distinguishedNameAttributeName {
@@ -706,9 +1037,19 @@ func (p *Provider) userSearchRequestedAttributes() []string {
 	if p.c.UserSearch.UIDAttribute != distinguishedNameAttributeName {
 		attributes = append(attributes, p.c.UserSearch.UIDAttribute)
 	}
+	if p.c.GroupSearch.UserAttributeForFilter != "" &&
+		p.c.GroupSearch.UserAttributeForFilter != distinguishedNameAttributeName &&
+		!slices.Contains(attributes, p.c.GroupSearch.UserAttributeForFilter) {
+		attributes = append(attributes, p.c.GroupSearch.UserAttributeForFilter)
+	}
 	for k := range p.c.RefreshAttributeChecks {
 		attributes = append(attributes, k)
 	}
+	for _, attributeName := range p.additionalClaimAttributes() {
+		if !slices.Contains(attributes, attributeName) {
+			attributes = append(attributes, attributeName)
+		}
+	}
 	return attributes
 }
 
@@ -733,15 +1074,15 @@ func (p *Provider) userSearchFilter(username string) string {
 	return interpolateSearchFilter(p.c.UserSearch.Filter, safeUsername)
 }
 
-func (p *Provider) groupSearchFilter(userDN string) string {
-	// The DN can contain characters that are considered special characters by LDAP searches, so it should be
-	// escaped before being included in the search filter to prevent bad search syntax.
+func (p *Provider) groupSearchFilter(groupSearchFilterValue string) string {
+	// The DN or other attribute value can contain characters that are considered special characters by LDAP searches,
+	// so it should be escaped before being included in the search filter to prevent bad search syntax.
 	// E.g. for the DN `CN=My User (Admin),OU=Users,OU=my,DC=my,DC=domain` we must escape the parens.
-	safeUserDN := p.escapeForSearchFilter(userDN)
+	safeValue := p.escapeForSearchFilter(groupSearchFilterValue)
 	if len(p.c.GroupSearch.Filter) == 0 {
-		return fmt.Sprintf("(member=%s)", safeUserDN)
+		return fmt.Sprintf("(member=%s)", safeValue)
 	}
-	return interpolateSearchFilter(p.c.GroupSearch.Filter, safeUserDN)
+	return interpolateSearchFilter(p.c.GroupSearch.Filter, safeValue)
 }
 
 func interpolateSearchFilter(filterFormat, valueToInterpolateIntoFilter string) string {
@@ -1,4 +1,4 @@
-// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package upstreamldap
@@ -203,6 +203,143 @@ func TestEndUserAuthentication(t *testing.T) {
 			},
 			wantAuthResponse: expectedAuthResponse(nil),
 		},
+		{
+			name:     "when the search timeout is configured then it is used as the time limit for searches",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.SearchTimeout = 30 * time.Second
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.TimeLimit = 30
+				})).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.TimeLimit = 30
+				}), expectedGroupSearchPageSize).Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(nil),
+		},
+		{
+			name:     "when the search scopes and alias dereferencing are configured then they are used for the searches",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.UserSearch.Scope = ScopeSingleLevel
+				p.UserSearch.DerefAliases = ldap.DerefAlways
+				p.GroupSearch.Scope = ScopeBaseObject
+				p.GroupSearch.DerefAliases = ldap.DerefInSearching
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.Scope = ldap.ScopeSingleLevel
+					r.DerefAliases = ldap.DerefAlways
+				})).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.Scope = ldap.ScopeBaseObject
+					r.DerefAliases = ldap.DerefInSearching
+				}), expectedGroupSearchPageSize).Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(nil),
+		},
+		{
+			name:     "when the group search page size is configured then it is used for the paged group search",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.PageSize = 10
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), uint32(10)).Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(nil),
+		},
+		{
+			name:     "when the group search is configured to use a user attribute instead of the user DN in the filter",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.Filter = "&(objectClass=posixGroup)(memberUid={})"
+				p.GroupSearch.UserAttributeForFilter = "uid"
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.Attributes = []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute, "uid"}
+				})).Return(&ldap.SearchResult{
+					Entries: []*ldap.Entry{
+						{
+							DN: testUserSearchResultDNValue,
+							Attributes: []*ldap.EntryAttribute{
+								ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+								ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+								ldap.NewEntryAttribute("uid", []string{"some-posix-uid (with special chars)"}),
+							},
+						},
+					},
+				}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.Filter = `(&(objectClass=posixGroup)(memberUid=some-posix-uid \28with special chars\29))`
+				}), expectedGroupSearchPageSize).Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(nil),
+		},
+		{
+			name:     "when the group search is configured to use a user attribute which is already requested by the user search then it is not requested twice",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.UserAttributeForFilter = testUserSearchUsernameAttribute
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.Filter = fmt.Sprintf("(some-group-filter=%s-and-more-filter=%s)", testUserSearchResultUsernameAttributeValue, testUserSearchResultUsernameAttributeValue)
+				}), expectedGroupSearchPageSize).Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(nil),
+		},
+		{
+			name:     "when the user attribute for the group search filter is missing from the user entry",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.UserAttributeForFilter = "uid"
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.Attributes = []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute, "uid"}
+				})).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: testutil.WantExactErrorString(`found 0 values for attribute "uid" while searching for user "some-upstream-username", but expected 1 result`),
+		},
 		{
 			name:     "when the user search filter is already wrapped by parenthesis then it is not wrapped again",
 			username: testUpstreamUsername,
@@ -340,6 +477,51 @@ func TestEndUserAuthentication(t *testing.T) {
 				info.Name = testUserSearchResultDNValue
 			}),
 		},
+		{
+			name:     "when additional claims are mapped from attributes of the user entry",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.AdditionalClaimMappings = map[string]string{
+					"email":      "mail",
+					"groupNames": "memberOf",
+					"department": "department",
+					"userDN":     "dn",
+				}
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.Attributes = []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute, "department", "mail", "memberOf"}
+				})).Return(&ldap.SearchResult{
+					Entries: []*ldap.Entry{
+						{
+							DN: testUserSearchResultDNValue,
+							Attributes: []*ldap.EntryAttribute{
+								ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+								ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{testUserSearchResultUIDAttributeValue}),
+								ldap.NewEntryAttribute("mail", []string{"some-user@example.com"}),
+								ldap.NewEntryAttribute("memberOf", []string{"cn=group1,dc=example,dc=com", "cn=group2,dc=example,dc=com"}),
+							},
+						},
+					},
+				}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).
+					Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(func(r *authenticators.Response) {
+				// The department attribute is not present on the user entry, so its claim is skipped.
+				r.AdditionalClaims = map[string]interface{}{
+					"email":      "some-user@example.com",
+					"groupNames": []string{"cn=group1,dc=example,dc=com", "cn=group2,dc=example,dc=com"},
+					"userDN":     testUserSearchResultDNValue,
+				}
+			}),
+		},
 		{
 			name:     "when the UIDAttribute is dn",
 			username: testUpstreamUsername,
@@ -1354,14 +1536,16 @@ func TestUpstreamRefresh(t *testing.T) {
 	}
 
 	tests := []struct {
-		name           string
-		providerConfig *ProviderConfig
-		grantedScopes  []string
-		setupMocks     func(conn *mockldapconn.MockConn)
-		refreshUserDN  string
-		dialError      error
-		wantErr        string
-		wantGroups     []string
+		name                 string
+		providerConfig       *ProviderConfig
+		grantedScopes        []string
+		setupMocks           func(conn *mockldapconn.MockConn)
+		refreshUserDN        string
+		lastGroupRefresh     time.Time
+		dialError            error
+		wantErr              string
+		wantGroups           []string
+		wantAdditionalClaims map[string]interface{}
 	}{
 		{
 			name: "happy path without group search where searching the dn returns a single entry",
@@ -1386,6 +1570,61 @@ func TestUpstreamRefresh(t *testing.T) {
 			},
 			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2},
 		},
+		{
+			name: "happy path where additional claims are mapped from attributes of the user entry",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.AdditionalClaimMappings = map[string]string{"username": testUserSearchUsernameAttribute, "lastPasswordChange": pwdLastSetAttribute}
+			}),
+			grantedScopes: []string{},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(happyPathUserSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantAdditionalClaims: map[string]interface{}{
+				"username":           testUserSearchResultUsernameAttributeValue,
+				"lastPasswordChange": "132801740800000000",
+			},
+		},
+		{
+			name: "happy path where group search uses the configured page size",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.PageSize = 10
+			}),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(happyPathUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), uint32(10)).Return(happyPathGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2},
+		},
+		{
+			name: "happy path where group search is configured to use a user attribute instead of the user DN in the filter",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.UserAttributeForFilter = "uid"
+			}),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.Attributes = []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute, "uid", pwdLastSetAttribute}
+				})).Return(&ldap.SearchResult{
+					Entries: []*ldap.Entry{
+						{
+							DN: testUserSearchResultDNValue,
+							Attributes: append(happyPathUserSearchResult.Entries[0].Attributes,
+								ldap.NewEntryAttribute("uid", []string{"some-posix-uid"}),
+							),
+						},
+					},
+				}, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.Filter = "(some-group-filter=some-posix-uid-and-more-filter=some-posix-uid)"
+				}), expectedGroupSearchPageSize).Return(happyPathGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2},
+		},
 		{
 			name:           "happy path when the user DN has special LDAP search filter characters then they must be properly escaped in the custom group search filter",
 			providerConfig: providerConfig(nil),
@@ -1491,6 +1730,37 @@ func TestUpstreamRefresh(t *testing.T) {
 			},
 			wantGroups: nil, // do not update groups
 		},
+		{
+			name: "happy path where group search is configured but the group refresh interval has not elapsed",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.RefreshInterval = time.Hour
+			}),
+			lastGroupRefresh: time.Now().Add(-59 * time.Minute),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(happyPathUserSearchResult, nil).Times(1) // note that group search is not expected
+				conn.EXPECT().Close().Times(1)
+			},
+			wantGroups: nil, // do not update groups
+		},
+		{
+			name: "happy path where group search is configured and the group refresh interval has elapsed",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.RefreshInterval = time.Hour
+			}),
+			lastGroupRefresh: time.Now().Add(-61 * time.Minute),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(happyPathUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).Return(&ldap.SearchResult{
+					Entries:   []*ldap.Entry{},
+					Referrals: []string{},
+					Controls:  []ldap.Control{},
+				}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantGroups: []string{},
+		},
 		{
 			name:           "happy path where group search is configured but groups scope isn't included",
 			providerConfig: providerConfig(nil),
@@ -1812,12 +2082,13 @@ func TestUpstreamRefresh(t *testing.T) {
 			initialPwdLastSetEncoded := base64.RawURLEncoding.EncodeToString([]byte("132801740800000000"))
 			ldapProvider := New(*tt.providerConfig)
 			subject := "ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU"
-			groups, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+			groups, additionalClaims, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
 				Username:             testUserSearchResultUsernameAttributeValue,
 				Subject:              subject,
 				DN:                   tt.refreshUserDN,
 				AdditionalAttributes: map[string]string{pwdLastSetAttribute: initialPwdLastSetEncoded},
 				GrantedScopes:        tt.grantedScopes,
+				LastGroupRefresh:     tt.lastGroupRefresh,
 			})
 			if tt.wantErr != "" {
 				require.Error(t, err)
@@ -1827,6 +2098,7 @@ func TestUpstreamRefresh(t *testing.T) {
 			}
 			require.Equal(t, true, dialWasAttempted)
 			require.Equal(t, tt.wantGroups, groups)
+			require.Equal(t, tt.wantAdditionalClaims, additionalClaims)
 		})
 	}
 }
@@ -1969,6 +2241,15 @@ func TestGetURL(t *testing.T) {
 			Host:       "ldap.example.com",
 			UserSearch: UserSearchConfig{Base: "ou=users,dc=pinniped,dc=dev"},
 		}).GetURL().String())
+
+	require.Equal(t,
+		"ldaps://activedirectory.example.com?base=ou%3Dusers%2Cdc%3Dpinniped%2Cdc%3Ddev",
+		New(ProviderConfig{
+			Host:             "dc1.activedirectory.example.com",
+			FailoverHosts:    []string{"dc2.activedirectory.example.com"},
+			DiscoveredDomain: "activedirectory.example.com",
+			UserSearch:       UserSearchConfig{Base: "ou=users,dc=pinniped,dc=dev"},
+		}).GetURL().String())
 }
 
 // Testing of host parsing, TLS negotiation, and CA bundle, etc. for the production code's dialer.
@@ -1997,6 +2278,9 @@ func TestRealTLSDialing(t *testing.T) {
 	require.NoError(t, err)
 	testServerWithBadCertNameAddr := testutil.TLSTestServerWithCert(t, func(w http.ResponseWriter, r *http.Request) {}, cert)
 
+	clientCertPEM, clientKeyPEM, err := caForTestServerWithBadCertName.IssueClientCertPEM("test-client", nil, time.Hour)
+	require.NoError(t, err)
+
 	unusedPortGrabbingListener, err := net.Listen("tcp", "127.0.0.1:0")
 	require.NoError(t, err)
 	recentlyClaimedHostAndPort := unusedPortGrabbingListener.Addr().String()
@@ -2006,12 +2290,14 @@ func TestRealTLSDialing(t *testing.T) {
 	cancelFunc() // cancel it immediately
 
 	tests := []struct {
-		name      string
-		host      string
-		connProto LDAPConnectionProtocol
-		caBundle  []byte
-		context   context.Context
-		wantError testutil.RequireErrorStringFunc
+		name       string
+		host       string
+		connProto  LDAPConnectionProtocol
+		caBundle   []byte
+		clientCert []byte
+		clientKey  []byte
+		context    context.Context
+		wantError  testutil.RequireErrorStringFunc
 	}{
 		{
 			name:      "happy path",
@@ -2020,6 +2306,34 @@ func TestRealTLSDialing(t *testing.T) {
 			connProto: TLS,
 			context:   context.Background(),
 		},
+		{
+			name:       "happy path with a client certificate",
+			host:       testServerHostAndPort,
+			caBundle:   testServerCABundle,
+			clientCert: clientCertPEM,
+			clientKey:  clientKeyPEM,
+			connProto:  TLS,
+			context:    context.Background(),
+		},
+		{
+			name:       "invalid client certificate with TLS",
+			host:       testServerHostAndPort,
+			caBundle:   testServerCABundle,
+			clientCert: []byte("not a cert"),
+			clientKey:  clientKeyPEM,
+			connProto:  TLS,
+			context:    context.Background(),
+			wantError:  testutil.WantExactErrorString(`LDAP Result Code 200 "Network Error": could not parse client certificate: tls: failed to find any PEM data in certificate input`),
+		},
+		{
+			name:       "client certificate without a key with StartTLS",
+			host:       testServerHostAndPort,
+			caBundle:   testServerCABundle,
+			clientCert: clientCertPEM,
+			connProto:  StartTLS,
+			context:    context.Background(),
+			wantError:  testutil.WantExactErrorString(`LDAP Result Code 200 "Network Error": could not parse client certificate: tls: failed to find any PEM data in key input`),
+		},
 		{
 			name:      "server cert name does not match the address to which the client connected",
 			host:      testServerWithBadCertNameAddr,
@@ -2098,12 +2412,14 @@ func TestRealTLSDialing(t *testing.T) {
 		tt := test
 		t.Run(tt.name, func(t *testing.T) {
 			provider := New(ProviderConfig{
-				Host:               tt.host,
-				CABundle:           tt.caBundle,
-				ConnectionProtocol: tt.connProto,
-				Dialer:             nil, // this test is for the default (production) TLS dialer
+				Host:                  tt.host,
+				CABundle:              tt.caBundle,
+				ClientCertificateData: tt.clientCert,
+				ClientKeyData:         tt.clientKey,
+				ConnectionProtocol:    tt.connProto,
+				Dialer:                nil, // this test is for the default (production) TLS dialer
 			})
-			conn, err := provider.dial(tt.context)
+			conn, err := provider.dialHost(tt.context, tt.host)
 			if conn != nil {
 				defer conn.Close()
 			}
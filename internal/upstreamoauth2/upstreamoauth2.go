@@ -0,0 +1,238 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package upstreamoauth2 implements an abstraction of upstream OAuth2 provider interactions, for providers
+// which do not implement OIDC, so they do not offer OIDC discovery or ID tokens.
+package upstreamoauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/claimexpressions"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+// ProviderConfig holds the active configuration of an upstream OAuth2 provider. It implements the same interface as
+// the upstream OIDC providers, so it can be used by the same Supervisor endpoints. The userinfo endpoint response
+// takes the place of the claims of the ID token, with the "sub" claim computed by the UID expression and the "iss"
+// claim set to the userinfo URL.
+type ProviderConfig struct {
+	Name                     string
+	ResourceUID              types.UID
+	Config                   *oauth2.Config
+	Client                   *http.Client
+	UserInfoURL              *url.URL
+	AdditionalAuthcodeParams map[string]string
+
+	// UIDExpression computes the value of the "sub" claim from the userinfo endpoint response.
+	UIDExpression *claimexpressions.StringExpression
+	// ClaimExpressions compute the username and groups from the userinfo endpoint response.
+	ClaimExpressions *claimexpressions.Expressions
+}
+
+var _ provider.UpstreamOIDCIdentityProviderI = (*ProviderConfig)(nil)
+
+func (p *ProviderConfig) GetName() string {
+	return p.Name
+}
+
+func (p *ProviderConfig) GetClientID() string {
+	return p.Config.ClientID
+}
+
+func (p *ProviderConfig) GetResourceUID() types.UID {
+	return p.ResourceUID
+}
+
+func (p *ProviderConfig) GetAuthorizationURL() *url.URL {
+	result, _ := url.Parse(p.Config.Endpoint.AuthURL)
+	return result
+}
+
+// HasUserInfoURL always returns true, since the userinfo endpoint is required for OAuth2 providers.
+func (p *ProviderConfig) HasUserInfoURL() bool {
+	return true
+}
+
+func (p *ProviderConfig) GetScopes() []string {
+	return p.Config.Scopes
+}
+
+// GetUsernameClaim returns an empty string, since the username is always computed by the ClaimExpressions.
+func (p *ProviderConfig) GetUsernameClaim() string {
+	return ""
+}
+
+// GetGroupsClaim returns an empty string, since the groups are always computed by the ClaimExpressions.
+func (p *ProviderConfig) GetGroupsClaim() string {
+	return ""
+}
+
+func (p *ProviderConfig) AllowsPasswordGrant() bool {
+	return false
+}
+
+func (p *ProviderConfig) GetAdditionalAuthcodeParams() map[string]string {
+	return p.AdditionalAuthcodeParams
+}
+
+func (p *ProviderConfig) GetAdditionalClaimMappings() map[string]string {
+	return nil
+}
+
+func (p *ProviderConfig) GetClaimExpressions() *claimexpressions.Expressions {
+	return p.ClaimExpressions
+}
+
+func (p *ProviderConfig) UsesPushedAuthorizationRequests() bool {
+	return false
+}
+
+func (p *ProviderConfig) PushAuthorizationRequest(_ context.Context, _ url.Values) (string, error) {
+	return "", errors.New("pushed authorization requests are not supported for OAuth2 upstream providers")
+}
+
+func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(_ context.Context, _, _ string) (*oidctypes.Token, error) {
+	return nil, errors.New("resource owner password credentials grant is not allowed for OAuth2 upstream providers")
+}
+
+// ExchangeAuthcodeAndValidateTokens exchanges the authcode and then fetches the user's claims from the userinfo
+// endpoint. The nonce is ignored, since there is no ID token in which it could be validated.
+func (p *ProviderConfig) ExchangeAuthcodeAndValidateTokens(
+	ctx context.Context,
+	authcode string,
+	pkceCodeVerifier pkce.Code,
+	_ nonce.Nonce,
+	redirectURI string,
+) (*oidctypes.Token, error) {
+	tok, err := p.Config.Exchange(
+		coreosoidc.ClientContext(ctx, p.Client),
+		authcode,
+		pkceCodeVerifier.Verifier(),
+		oauth2.SetAuthURLParam("redirect_uri", redirectURI),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ValidateTokenAndMergeWithUserInfo(ctx, tok, "", false, true)
+}
+
+func (p *ProviderConfig) PerformRefresh(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	// Use the provided HTTP client to benefit from its CA, proxy, and other settings.
+	httpClientContext := coreosoidc.ClientContext(ctx, p.Client)
+	// Create a TokenSource without an access token, so it thinks that a refresh is immediately required.
+	// Then ask it for the tokens to cause it to perform the refresh and return the results.
+	return p.Config.TokenSource(httpClientContext, &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+// RevokeToken does nothing, since there is no standard way to discover the revocation endpoint of an OAuth2 provider.
+func (p *ProviderConfig) RevokeToken(_ context.Context, _ string, tokenType provider.RevocableTokenType) error {
+	plog.Trace("RevokeToken() was called but upstream OAuth2 provider has no revocation endpoint",
+		"providerName", p.Name,
+		"tokenType", tokenType,
+	)
+	return nil
+}
+
+// ValidateTokenAndMergeWithUserInfo fetches the user's claims from the userinfo endpoint using the access token.
+// Any ID token is ignored, since OAuth2 providers do not offer the discovery and keys needed to validate it, so the
+// userinfo endpoint is always called regardless of requireIDToken and requireUserInfo.
+func (p *ProviderConfig) ValidateTokenAndMergeWithUserInfo(ctx context.Context, tok *oauth2.Token, _ nonce.Nonce, _ bool, _ bool) (*oidctypes.Token, error) {
+	claims, err := p.fetchUserInfo(ctx, tok)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := p.UIDExpression.Eval(claims)
+	if err != nil {
+		return nil, httperr.Wrap(http.StatusUnprocessableEntity, "could not compute uid from userinfo response", err)
+	}
+	// The UID is only unique for this provider, so the userinfo URL is used as the issuer to make the downstream
+	// subject globally unique. Any "sub" or "iss" from the userinfo response cannot be trusted to do this.
+	claims[oidcapi.IDTokenClaimSubject] = uid
+	claims[oidcapi.IDTokenClaimIssuer] = p.UserInfoURL.String()
+
+	return &oidctypes.Token{
+		AccessToken: &oidctypes.AccessToken{
+			Token:  tok.AccessToken,
+			Type:   tok.TokenType,
+			Expiry: metav1.NewTime(tok.Expiry),
+		},
+		RefreshToken: &oidctypes.RefreshToken{
+			Token: tok.RefreshToken,
+		},
+		IDToken: &oidctypes.IDToken{
+			Claims: claims,
+		},
+	}, nil
+}
+
+func (p *ProviderConfig) fetchUserInfo(ctx context.Context, tok *oauth2.Token) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoURL.String(), nil)
+	if err != nil {
+		return nil, httperr.Wrap(http.StatusInternalServerError, "could not create userinfo request", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	// Always send the access token as a bearer token, even when the token endpoint returned another token type,
+	// since some providers return unusual token types such as "bearer".
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, httperr.Wrap(http.StatusInternalServerError, "could not get user info", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, httperr.Wrap(http.StatusInternalServerError, "could not read user info response", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, httperr.Newf(http.StatusInternalServerError,
+			"userinfo request failed with status %d with body: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, httperr.Wrap(http.StatusInternalServerError, "could not unmarshal user info response", err)
+	}
+	if claims == nil {
+		return nil, httperr.New(http.StatusInternalServerError, "user info response is not a JSON object")
+	}
+
+	maybeLogClaims("claims from userinfo", p.Name, claims)
+	return claims, nil
+}
+
+func maybeLogClaims(msg, name string, claims map[string]interface{}) {
+	if plog.Enabled(plog.LevelAll) { // log keys and values at all level
+		data, _ := json.Marshal(claims) // nothing we can do if it fails, but it really never should
+		plog.All(msg, "providerName", name, "claims", string(data))
+		return
+	}
+
+	if plog.Enabled(plog.LevelDebug) { // log keys at debug level
+		keys := sets.StringKeySet(claims).List() // note: this is only safe because the compiler asserts that claims is a map[string]<anything>
+		plog.Debug(msg, "providerName", name, "keys", keys)
+		return
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoauth2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"go.pinniped.dev/internal/claimexpressions"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+)
+
+func TestProviderConfig(t *testing.T) {
+	t.Run("getters", func(t *testing.T) {
+		claimExpressions, err := claimexpressions.Compile("claims.login", "", nil)
+		require.NoError(t, err)
+		p := ProviderConfig{
+			Name:        "test-name",
+			ResourceUID: "test-uid",
+			Config: &oauth2.Config{
+				ClientID: "test-client-id",
+				Endpoint: oauth2.Endpoint{AuthURL: "https://example.com/authorize"},
+				Scopes:   []string{"read:user"},
+			},
+			AdditionalAuthcodeParams: map[string]string{"prompt": "consent"},
+			ClaimExpressions:         claimExpressions,
+		}
+		require.Equal(t, "test-name", p.GetName())
+		require.Equal(t, "test-uid", string(p.GetResourceUID()))
+		require.Equal(t, "test-client-id", p.GetClientID())
+		require.Equal(t, "https://example.com/authorize", p.GetAuthorizationURL().String())
+		require.Equal(t, []string{"read:user"}, p.GetScopes())
+		require.Equal(t, map[string]string{"prompt": "consent"}, p.GetAdditionalAuthcodeParams())
+		require.Same(t, claimExpressions, p.GetClaimExpressions())
+		require.True(t, p.HasUserInfoURL())
+		require.Empty(t, p.GetUsernameClaim())
+		require.Empty(t, p.GetGroupsClaim())
+		require.Nil(t, p.GetAdditionalClaimMappings())
+		require.False(t, p.AllowsPasswordGrant())
+		require.False(t, p.UsesPushedAuthorizationRequests())
+
+		_, err = p.PasswordCredentialsGrantAndValidateTokens(context.Background(), "user", "pass")
+		require.EqualError(t, err, "resource owner password credentials grant is not allowed for OAuth2 upstream providers")
+		_, err = p.PushAuthorizationRequest(context.Background(), url.Values{})
+		require.EqualError(t, err, "pushed authorization requests are not supported for OAuth2 upstream providers")
+		require.NoError(t, p.RevokeToken(context.Background(), "some-token", "access_token"))
+	})
+
+	t.Run("ExchangeAuthcodeAndValidateTokens", func(t *testing.T) {
+		tests := []struct {
+			name             string
+			uidExpression    string
+			userInfoStatus   int
+			userInfoResponse string
+
+			wantClaims     map[string]interface{}
+			wantErr        string
+			wantHTTPStatus int
+		}{
+			{
+				name:             "exchanges the authcode and uses the userinfo response as the claims",
+				uidExpression:    `string(claims.id)`,
+				userInfoResponse: `{"id":12345,"login":"jane","sub":"ignored","iss":"ignored"}`,
+				wantClaims: map[string]interface{}{
+					"id":    float64(12345),
+					"login": "jane",
+					"sub":   "12345",
+					"iss":   "USERINFO_URL",
+				},
+			},
+			{
+				name:             "userinfo endpoint returns an error",
+				uidExpression:    `string(claims.id)`,
+				userInfoStatus:   http.StatusUnauthorized,
+				userInfoResponse: `{"message":"Bad credentials"}`,
+				wantErr:          `userinfo request failed with status 401 with body: {"message":"Bad credentials"}`,
+				wantHTTPStatus:   http.StatusInternalServerError,
+			},
+			{
+				name:             "userinfo endpoint returns invalid JSON",
+				uidExpression:    `string(claims.id)`,
+				userInfoResponse: `not json`,
+				wantErr:          "could not unmarshal user info response: invalid character 'o' in literal null (expecting 'u')",
+				wantHTTPStatus:   http.StatusInternalServerError,
+			},
+			{
+				name:             "userinfo endpoint returns null",
+				uidExpression:    `string(claims.id)`,
+				userInfoResponse: `null`,
+				wantErr:          "user info response is not a JSON object",
+				wantHTTPStatus:   http.StatusInternalServerError,
+			},
+			{
+				name:             "uid expression cannot be evaluated",
+				uidExpression:    `claims.id`,
+				userInfoResponse: `{"login":"jane"}`,
+				wantErr:          "could not compute uid from userinfo response: could not evaluate uid expression: no such key: id",
+				wantHTTPStatus:   http.StatusUnprocessableEntity,
+			},
+		}
+		for _, test := range tests {
+			tt := test
+			t.Run(tt.name, func(t *testing.T) {
+				mux := http.NewServeMux()
+				mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.NoError(t, r.ParseForm())
+					require.Equal(t, "authorization_code", r.Form.Get("grant_type"))
+					require.Equal(t, "test-authcode", r.Form.Get("code"))
+					require.Equal(t, "test-pkce", r.Form.Get("code_verifier"))
+					require.Equal(t, "https://example.com/callback", r.Form.Get("redirect_uri"))
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"test-access-token","token_type":"bearer","refresh_token":"test-refresh-token"}`))
+				})
+				mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodGet, r.Method)
+					require.Equal(t, "Bearer test-access-token", r.Header.Get("Authorization"))
+					w.Header().Set("Content-Type", "application/json")
+					if tt.userInfoStatus != 0 {
+						w.WriteHeader(tt.userInfoStatus)
+					}
+					_, _ = w.Write([]byte(tt.userInfoResponse))
+				})
+				server := httptest.NewServer(mux)
+				t.Cleanup(server.Close)
+
+				userInfoURL, err := url.Parse(server.URL + "/user")
+				require.NoError(t, err)
+				uidExpression, err := claimexpressions.CompileString("uid", tt.uidExpression)
+				require.NoError(t, err)
+				p := ProviderConfig{
+					Name: "test-name",
+					Config: &oauth2.Config{
+						ClientID:     "test-client-id",
+						ClientSecret: "test-client-secret",
+						Endpoint:     oauth2.Endpoint{TokenURL: server.URL + "/token", AuthStyle: oauth2.AuthStyleInHeader},
+					},
+					Client:        &http.Client{},
+					UserInfoURL:   userInfoURL,
+					UIDExpression: uidExpression,
+				}
+
+				tok, err := p.ExchangeAuthcodeAndValidateTokens(context.Background(), "test-authcode", pkce.Code("test-pkce"), "test-nonce", "https://example.com/callback")
+				if tt.wantErr != "" {
+					require.EqualError(t, err, tt.wantErr)
+					rec := httptest.NewRecorder()
+					err.(httperr.Responder).Respond(rec)
+					require.Equal(t, tt.wantHTTPStatus, rec.Code)
+					require.Contains(t, rec.Body.String(), http.StatusText(tt.wantHTTPStatus))
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, "test-access-token", tok.AccessToken.Token)
+				require.Equal(t, "test-refresh-token", tok.RefreshToken.Token)
+				require.Empty(t, tok.IDToken.Token)
+				if tt.wantClaims["iss"] == "USERINFO_URL" {
+					tt.wantClaims["iss"] = userInfoURL.String()
+				}
+				require.Equal(t, tt.wantClaims, tok.IDToken.Claims)
+			})
+		}
+	})
+
+	t.Run("PerformRefresh", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+			require.Equal(t, "test-refresh-token", r.Form.Get("refresh_token"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"new-access-token","token_type":"bearer"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		p := ProviderConfig{
+			Config: &oauth2.Config{
+				ClientID: "test-client-id",
+				Endpoint: oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams},
+			},
+			Client: &http.Client{},
+		}
+		tok, err := p.PerformRefresh(context.Background(), "test-refresh-token")
+		require.NoError(t, err)
+		require.Equal(t, "new-access-token", tok.AccessToken)
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// ClientAuthenticationMethod is a method of client authentication at the token endpoint, as defined by
+// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication. The methods have the same names
+// as in the OIDCIdentityProvider API.
+type ClientAuthenticationMethod string
+
+const (
+	// ClientAuthenticationMethodDefault tries client_secret_basic first, falling back to client_secret_post.
+	ClientAuthenticationMethodDefault = ClientAuthenticationMethod("")
+
+	ClientAuthenticationMethodClientSecretBasic = ClientAuthenticationMethod("client_secret_basic")
+	ClientAuthenticationMethodClientSecretPost  = ClientAuthenticationMethod("client_secret_post")
+	ClientAuthenticationMethodClientSecretJWT   = ClientAuthenticationMethod("client_secret_jwt")
+	ClientAuthenticationMethodPrivateKeyJWT     = ClientAuthenticationMethod("private_key_jwt")
+)
+
+const (
+	clientAssertionTypeParam = "client_assertion_type"
+	clientAssertionParam     = "client_assertion"
+
+	// clientAssertionTypeJWTBearer is defined by https://datatracker.ietf.org/doc/html/rfc7523#section-2.2.
+	clientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	clientAssertionLifetime = 5 * time.Minute
+)
+
+// ClientAssertionSigner signs the client assertions which are used by the client_secret_jwt and private_key_jwt
+// client authentication methods.
+type ClientAssertionSigner struct {
+	signer jose.Signer
+}
+
+// NewClientSecretJWTSigner returns a ClientAssertionSigner for the client_secret_jwt method, which signs client
+// assertions with the client secret using HS256.
+func NewClientSecretJWTSigner(clientSecret string) (*ClientAssertionSigner, error) {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(clientSecret)}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create client assertion signer: %w", err)
+	}
+	return &ClientAssertionSigner{signer: signer}, nil
+}
+
+// NewPrivateKeyJWTSigner returns a ClientAssertionSigner for the private_key_jwt method, which signs client
+// assertions with the PEM-encoded private key using RS256 for RSA keys or ES256 for ECDSA keys. When the keyID is
+// not empty, it is used as the "kid" header of the client assertions.
+func NewPrivateKeyJWTSigner(privateKeyPEM []byte, keyID string) (*ClientAssertionSigner, error) {
+	privateKey, err := keyutil.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+
+	var algorithm jose.SignatureAlgorithm
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		algorithm = jose.RS256
+	case *ecdsa.PrivateKey:
+		if k.Curve.Params().Name != "P-256" {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s (only P-256 is supported)", k.Curve.Params().Name)
+		}
+		algorithm = jose.ES256
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T (only RSA and ECDSA keys are supported)", privateKey)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: algorithm, Key: jose.JSONWebKey{Key: privateKey, KeyID: keyID}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create client assertion signer: %w", err)
+	}
+	return &ClientAssertionSigner{signer: signer}, nil
+}
+
+// assertion returns a signed client assertion as described by
+// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication.
+func (s *ClientAssertionSigner) assertion(clientID string, audience string) (string, error) {
+	now := time.Now()
+	return jwt.Signed(s.signer).Claims(jwt.Claims{
+		Issuer:   clientID,
+		Subject:  clientID,
+		Audience: jwt.Audience{audience},
+		ID:       uuid.New().String(),
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(clientAssertionLifetime)),
+	}).CompactSerialize()
+}
+
+// addClientAssertion sets the client assertion params, and removes any client secret from the params.
+func (s *ClientAssertionSigner) addClientAssertion(params url.Values, clientID string, audience string) error {
+	assertion, err := s.assertion(clientID, audience)
+	if err != nil {
+		return fmt.Errorf("could not sign client assertion: %w", err)
+	}
+	params.Del("client_secret")
+	params.Set("client_id", clientID)
+	params.Set(clientAssertionTypeParam, clientAssertionTypeJWTBearer)
+	params.Set(clientAssertionParam, assertion)
+	return nil
+}
+
+// clientAssertionRoundTripper adds a client assertion to the form-encoded body of each request, replacing any other
+// client authentication. The oauth2 library has no way to add params to the body of refresh or password grant
+// requests, so this is used for all requests to the token endpoint.
+type clientAssertionRoundTripper struct {
+	base     http.RoundTripper
+	signer   *ClientAssertionSigner
+	clientID string
+	audience string
+}
+
+func (rt *clientAssertionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse token request body: %w", err)
+	}
+	if err := rt.signer.addClientAssertion(params, rt.clientID, rt.audience); err != nil {
+		return nil, err
+	}
+	encoded := params.Encode()
+
+	// A RoundTripper must not modify the original request.
+	newReq := req.Clone(req.Context())
+	newReq.Header.Del("Authorization")
+	newReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	newReq.Body = io.NopCloser(strings.NewReader(encoded))
+	newReq.ContentLength = int64(len(encoded))
+	newReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(encoded)), nil
+	}
+	return rt.base.RoundTrip(newReq)
+}
+
+// tokenEndpointClient returns the HTTP client to use for requests to the token endpoint, which adds a client
+// assertion to each request when the provider uses the client_secret_jwt or private_key_jwt method.
+func (p *ProviderConfig) tokenEndpointClient() *http.Client {
+	if p.ClientAssertionSigner == nil {
+		return p.Client
+	}
+	client := &http.Client{}
+	if p.Client != nil {
+		*client = *p.Client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &clientAssertionRoundTripper{
+		base:     base,
+		signer:   p.ClientAssertionSigner,
+		clientID: p.Config.ClientID,
+		audience: p.Config.Endpoint.TokenURL,
+	}
+	return client
+}
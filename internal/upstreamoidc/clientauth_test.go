@@ -0,0 +1,186 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+func TestNewPrivateKeyJWTSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		privateKeyPEM []byte
+		wantAlgorithm string
+		wantErr       string
+	}{
+		{
+			name:          "RSA key",
+			privateKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+			wantAlgorithm: "RS256",
+		},
+		{
+			name:          "ECDSA P-256 key",
+			privateKeyPEM: ecPrivateKeyPEM(t, p256Key),
+			wantAlgorithm: "ES256",
+		},
+		{
+			name:          "ECDSA P-384 key",
+			privateKeyPEM: ecPrivateKeyPEM(t, p384Key),
+			wantErr:       "unsupported ECDSA curve P-384 (only P-256 is supported)",
+		},
+		{
+			name:          "not a PEM-encoded key",
+			privateKeyPEM: []byte("this is not a key"),
+			wantErr:       "could not parse private key: data does not contain a valid RSA or ECDSA private key",
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewPrivateKeyJWTSigner(tt.privateKeyPEM, "some-key-id")
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, signer)
+				return
+			}
+			require.NoError(t, err)
+
+			assertion, err := signer.assertion("some-client-id", "https://example.com/token")
+			require.NoError(t, err)
+			jws, err := jose.ParseSigned(assertion)
+			require.NoError(t, err)
+			require.Len(t, jws.Signatures, 1)
+			require.Equal(t, tt.wantAlgorithm, jws.Signatures[0].Header.Algorithm)
+			require.Equal(t, "some-key-id", jws.Signatures[0].Header.KeyID)
+		})
+	}
+}
+
+func TestClientAssertions(t *testing.T) {
+	const (
+		clientID     = "some-client-id"
+		clientSecret = "some-client-secret"
+	)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privateKeySigner, err := NewPrivateKeyJWTSigner(ecPrivateKeyPEM(t, ecKey), "")
+	require.NoError(t, err)
+	clientSecretSigner, err := NewClientSecretJWTSigner(clientSecret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		signer       *ClientAssertionSigner
+		verification interface{}
+	}{
+		{
+			name:         "private_key_jwt",
+			signer:       privateKeySigner,
+			verification: &ecKey.PublicKey,
+		},
+		{
+			name:         "client_secret_jwt",
+			signer:       clientSecretSigner,
+			verification: []byte(clientSecret),
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			var tokenURL string
+			var requestPaths []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestPaths = append(requestPaths, r.URL.Path)
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Empty(t, r.Header.Get("Authorization"))
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, clientID, r.Form.Get("client_id"))
+				require.NotContains(t, r.Form, "client_secret")
+				require.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.Form.Get("client_assertion_type"))
+
+				parsed, err := jwt.ParseSigned(r.Form.Get("client_assertion"))
+				require.NoError(t, err)
+				var claims jwt.Claims
+				require.NoError(t, parsed.Claims(tt.verification, &claims))
+				require.NoError(t, claims.Validate(jwt.Expected{
+					Issuer:   clientID,
+					Subject:  clientID,
+					Audience: jwt.Audience{tokenURL},
+					Time:     time.Now(),
+				}))
+				require.NotEmpty(t, claims.ID)
+
+				switch r.URL.Path {
+				case "/token":
+					require.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+					require.Equal(t, "some-refresh-token", r.Form.Get("refresh_token"))
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"some-access-token","token_type":"Bearer","refresh_token":"new-refresh-token"}`))
+				case "/revoke":
+					require.Equal(t, "some-token", r.Form.Get("token"))
+				default:
+					t.Fatalf("unexpected request path %q", r.URL.Path)
+				}
+			}))
+			t.Cleanup(server.Close)
+			tokenURL = server.URL + "/token"
+			revocationURL, err := url.Parse(server.URL + "/revoke")
+			require.NoError(t, err)
+
+			p := ProviderConfig{
+				Name: "test-name",
+				Config: &oauth2.Config{
+					ClientID: clientID,
+					Endpoint: oauth2.Endpoint{TokenURL: tokenURL, AuthStyle: oauth2.AuthStyleInParams},
+				},
+				Client:                     &http.Client{},
+				RevocationURL:              revocationURL,
+				ClientAuthenticationMethod: ClientAuthenticationMethod(tt.name),
+				ClientAssertionSigner:      tt.signer,
+			}
+
+			tok, err := p.PerformRefresh(context.Background(), "some-refresh-token")
+			require.NoError(t, err)
+			require.Equal(t, "some-access-token", tok.AccessToken)
+			require.Equal(t, "new-refresh-token", tok.RefreshToken)
+
+			require.NoError(t, p.RevokeToken(context.Background(), "some-token", provider.RefreshTokenType))
+
+			require.Equal(t, []string{"/token", "/revoke"}, requestPaths)
+		})
+	}
+}
+
+func ecPrivateKeyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// DefaultMicrosoftGraphURL is the base URL of the global Microsoft Graph service.
+	DefaultMicrosoftGraphURL = "https://graph.microsoft.com"
+
+	// The groups claim emitted by Azure AD, which is replaced by a groups overage claim when the user is a member of
+	// too many groups. See https://learn.microsoft.com/en-us/entra/identity-platform/id-token-claims-reference.
+	azureGroupsClaimName = "groups"
+	// The distributed claim names, which contain "groups" when there is a groups overage.
+	azureClaimNamesClaimName = "_claim_names"
+	// The groups overage claim which is used instead of _claim_names by tokens from the implicit flow.
+	azureHasGroupsClaimName = "hasgroups"
+	// The immutable object ID of the user in Azure AD.
+	azureObjectIDClaimName = "oid"
+
+	// maxMicrosoftGraphPages limits how many pages of groups are fetched for a user, to avoid looping forever on
+	// a misbehaving server. With the page size below this allows for nearly 100,000 groups.
+	maxMicrosoftGraphPages = 100
+	microsoftGraphPageSize = 999
+)
+
+// hasGroupsOverage returns true when the claims contain an Azure AD groups overage claim instead of the groups claim.
+func hasGroupsOverage(claims map[string]interface{}) bool {
+	if _, hasGroups := claims[azureGroupsClaimName]; hasGroups {
+		return false
+	}
+	if hasGroups, _ := claims[azureHasGroupsClaimName].(bool); hasGroups {
+		return true
+	}
+	claimNames, _ := claims[azureClaimNamesClaimName].(map[string]interface{})
+	_, hasGroupsClaimName := claimNames[azureGroupsClaimName]
+	return hasGroupsClaimName
+}
+
+// maybeResolveGroupsOverage replaces an Azure AD groups overage claim with a groups claim which lists the IDs of all
+// groups of which the user is a transitive member, as fetched from Microsoft Graph. It does nothing when the provider
+// is not configured to use Microsoft Graph, or when there is no groups overage claim.
+func (p *ProviderConfig) maybeResolveGroupsOverage(ctx context.Context, claims map[string]interface{}) error {
+	if p.MicrosoftGraphURL == "" || !hasGroupsOverage(claims) {
+		return nil
+	}
+
+	objectID, _ := claims[azureObjectIDClaimName].(string)
+	if objectID == "" {
+		return fmt.Errorf("groups overage claim found, but the %q claim is missing", azureObjectIDClaimName)
+	}
+
+	groups, err := p.fetchMicrosoftGraphGroups(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	claims[azureGroupsClaimName] = groups
+	plog.Debug("resolved groups overage claim using Microsoft Graph", "providerName", p.Name, "groupCount", len(groups))
+	return nil
+}
+
+// fetchMicrosoftGraphGroups returns the IDs of all groups of which the user is a transitive member.
+// See https://learn.microsoft.com/en-us/graph/api/user-list-transitivememberof.
+func (p *ProviderConfig) fetchMicrosoftGraphGroups(ctx context.Context, objectID string) ([]string, error) {
+	graphURL := strings.TrimSuffix(p.MicrosoftGraphURL, "/")
+
+	// Use the client credentials of this client to get a token for Microsoft Graph from the same token endpoint.
+	clientCredentialsConfig := &clientcredentials.Config{
+		ClientID:     p.Config.ClientID,
+		ClientSecret: p.Config.ClientSecret,
+		TokenURL:     p.Config.Endpoint.TokenURL,
+		Scopes:       []string{graphURL + "/.default"},
+		AuthStyle:    p.Config.Endpoint.AuthStyle,
+	}
+	tok, err := clientCredentialsConfig.Token(context.WithValue(ctx, oauth2.HTTPClient, p.tokenEndpointClient()))
+	if err != nil {
+		return nil, fmt.Errorf("could not get token for Microsoft Graph: %w", err)
+	}
+
+	query := url.Values{"$select": {"id"}, "$top": {strconv.Itoa(microsoftGraphPageSize)}}
+	nextURL := fmt.Sprintf("%s/v1.0/users/%s/transitiveMemberOf/microsoft.graph.group?%s",
+		graphURL, url.PathEscape(objectID), query.Encode())
+
+	groups := []string{}
+	for page := 0; nextURL != ""; page++ {
+		if page == maxMicrosoftGraphPages {
+			return nil, fmt.Errorf("too many pages of groups in Microsoft Graph response for user %q", objectID)
+		}
+		var pageGroups []string
+		pageGroups, nextURL, err = p.fetchMicrosoftGraphGroupsPage(ctx, tok, nextURL)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, pageGroups...)
+	}
+	return groups, nil
+}
+
+func (p *ProviderConfig) fetchMicrosoftGraphGroupsPage(ctx context.Context, tok *oauth2.Token, pageURL string) ([]string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	tok.SetAuthHeader(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not get groups from Microsoft Graph: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading Microsoft Graph response body on response with status code %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("request to Microsoft Graph failed with status %d with body: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsedResp struct {
+		Value []struct {
+			ID string `json:"id"`
+		} `json:"value"`
+		NextLink string `json:"@odata.nextLink"`
+	}
+	if err := json.Unmarshal(body, &parsedResp); err != nil {
+		return nil, "", fmt.Errorf("error parsing Microsoft Graph response body: %w", err)
+	}
+
+	groups := make([]string, 0, len(parsedResp.Value))
+	for _, group := range parsedResp.Value {
+		if group.ID == "" {
+			return nil, "", errors.New("response from Microsoft Graph contains a group without an id")
+		}
+		groups = append(groups, group.ID)
+	}
+
+	// The token must not be sent anywhere other than Microsoft Graph, so do not follow links to other places.
+	if parsedResp.NextLink != "" && !strings.HasPrefix(parsedResp.NextLink, strings.TrimSuffix(p.MicrosoftGraphURL, "/")+"/") {
+		return nil, "", fmt.Errorf("response from Microsoft Graph contains a next link to an unexpected URL: %q", parsedResp.NextLink)
+	}
+	return groups, parsedResp.NextLink, nil
+}
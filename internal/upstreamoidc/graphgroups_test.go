@@ -0,0 +1,190 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestMaybeResolveGroupsOverage(t *testing.T) {
+	const (
+		clientID     = "some-client-id"
+		clientSecret = "some-client-secret"
+		objectID     = "some-object-id"
+	)
+
+	overageClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub":          "some-subject",
+			"oid":          objectID,
+			"_claim_names": map[string]interface{}{"groups": "src1"},
+			"_claim_sources": map[string]interface{}{
+				"src1": map[string]interface{}{"endpoint": "https://graph.windows.net/some-tenant/users/some-object-id/getMemberObjects"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		noGraphURL        bool
+		claims            map[string]interface{}
+		tokenStatus       int
+		graphStatus       int
+		nextLinkElsewhere bool
+
+		wantClaims        map[string]interface{}
+		wantErr           string
+		wantNoServerCalls bool
+	}{
+		{
+			name:   "resolves the groups overage claim using all pages of groups",
+			claims: overageClaims(),
+			wantClaims: func() map[string]interface{} {
+				claims := overageClaims()
+				claims["groups"] = []string{"group-1", "group-2", "group-3"}
+				return claims
+			}(),
+		},
+		{
+			name:   "resolves the hasgroups overage claim from the implicit flow",
+			claims: map[string]interface{}{"sub": "some-subject", "oid": objectID, "hasgroups": true},
+			wantClaims: map[string]interface{}{
+				"sub":       "some-subject",
+				"oid":       objectID,
+				"hasgroups": true,
+				"groups":    []string{"group-1", "group-2", "group-3"},
+			},
+		},
+		{
+			name:              "does nothing when there is no groups overage claim",
+			claims:            map[string]interface{}{"sub": "some-subject", "oid": objectID, "groups": []interface{}{"a"}},
+			wantClaims:        map[string]interface{}{"sub": "some-subject", "oid": objectID, "groups": []interface{}{"a"}},
+			wantNoServerCalls: true,
+		},
+		{
+			name:              "does nothing when Microsoft Graph is not configured",
+			noGraphURL:        true,
+			claims:            overageClaims(),
+			wantClaims:        overageClaims(),
+			wantNoServerCalls: true,
+		},
+		{
+			name:              "object ID claim is missing",
+			claims:            map[string]interface{}{"sub": "some-subject", "hasgroups": true},
+			wantErr:           `groups overage claim found, but the "oid" claim is missing`,
+			wantNoServerCalls: true,
+		},
+		{
+			name:        "token endpoint returns an error",
+			claims:      overageClaims(),
+			tokenStatus: http.StatusBadRequest,
+			wantErr:     `could not get token for Microsoft Graph: oauth2: "invalid_client"`,
+		},
+		{
+			name:        "Microsoft Graph returns an error",
+			claims:      overageClaims(),
+			graphStatus: http.StatusForbidden,
+			wantErr:     `request to Microsoft Graph failed with status 403 with body: {"error":{"code":"Authorization_RequestDenied"}}`,
+		},
+		{
+			name:              "Microsoft Graph returns a next link to somewhere else",
+			claims:            overageClaims(),
+			nextLinkElsewhere: true,
+			wantErr:           `response from Microsoft Graph contains a next link to an unexpected URL: "https://example.com/page2"`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			serverCalls := 0
+			var serverURL string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+				serverCalls++
+				require.Equal(t, http.MethodPost, r.Method)
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+				require.Equal(t, serverURL+"/.default", r.Form.Get("scope"))
+				username, password, hasBasicAuth := r.BasicAuth()
+				require.True(t, hasBasicAuth)
+				require.Equal(t, clientID, username)
+				require.Equal(t, clientSecret, password)
+
+				w.Header().Set("Content-Type", "application/json")
+				if tt.tokenStatus != 0 {
+					w.WriteHeader(tt.tokenStatus)
+					_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"access_token":"some-graph-token","token_type":"Bearer","expires_in":3600}`))
+			})
+			mux.HandleFunc("/v1.0/users/"+objectID+"/transitiveMemberOf/microsoft.graph.group", func(w http.ResponseWriter, r *http.Request) {
+				serverCalls++
+				require.Equal(t, "Bearer some-graph-token", r.Header.Get("Authorization"))
+				require.Equal(t, "id", r.URL.Query().Get("$select"))
+				require.Equal(t, "999", r.URL.Query().Get("$top"))
+
+				w.Header().Set("Content-Type", "application/json")
+				if tt.graphStatus != 0 {
+					w.WriteHeader(tt.graphStatus)
+					_, _ = w.Write([]byte(`{"error":{"code":"Authorization_RequestDenied"}}`))
+					return
+				}
+				nextLink := serverURL + "/page2"
+				if tt.nextLinkElsewhere {
+					nextLink = "https://example.com/page2"
+				}
+				require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+					"value":           []map[string]string{{"id": "group-1"}, {"id": "group-2"}},
+					"@odata.nextLink": nextLink,
+				}))
+			})
+			mux.HandleFunc("/page2", func(w http.ResponseWriter, r *http.Request) {
+				serverCalls++
+				require.Equal(t, "Bearer some-graph-token", r.Header.Get("Authorization"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"value":[{"id":"group-3"}]}`))
+			})
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+			serverURL = server.URL
+
+			p := ProviderConfig{
+				Name: "test-name",
+				Config: &oauth2.Config{
+					ClientID:     clientID,
+					ClientSecret: clientSecret,
+					Endpoint:     oauth2.Endpoint{TokenURL: server.URL + "/token", AuthStyle: oauth2.AuthStyleInHeader},
+				},
+				Client:            &http.Client{},
+				MicrosoftGraphURL: server.URL,
+			}
+			if tt.noGraphURL {
+				p.MicrosoftGraphURL = ""
+			}
+
+			claims := tt.claims
+			err := p.maybeResolveGroupsOverage(context.Background(), claims)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantClaims, claims)
+			}
+
+			if tt.wantNoServerCalls {
+				require.Zero(t, serverCalls)
+			} else {
+				require.NotZero(t, serverCalls)
+			}
+		})
+	}
+}
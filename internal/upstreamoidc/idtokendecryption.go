@@ -0,0 +1,60 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// IDTokenDecrypter decrypts ID tokens which were encrypted by the upstream OIDC provider using JWE, as described by
+// https://openid.net/specs/openid-connect-core-1_0.html#Encryption. The decrypted ID tokens are nested JWTs, whose
+// signatures are then validated as usual.
+type IDTokenDecrypter struct {
+	key jose.JSONWebKey
+}
+
+// NewIDTokenDecrypter returns an IDTokenDecrypter for the given JSON Web Key, which must be an RSA or EC private key,
+// or a symmetric key.
+func NewIDTokenDecrypter(jwkJSON []byte) (*IDTokenDecrypter, error) {
+	var key jose.JSONWebKey
+	if err := key.UnmarshalJSON(jwkJSON); err != nil {
+		return nil, fmt.Errorf("could not parse JSON Web Key: %w", err)
+	}
+	if !key.Valid() {
+		return nil, errors.New("invalid JSON Web Key")
+	}
+	if key.IsPublic() {
+		return nil, errors.New("JSON Web Key must be a private key")
+	}
+	if key.Use == "sig" {
+		return nil, errors.New(`JSON Web Key must not have use "sig"`)
+	}
+	return &IDTokenDecrypter{key: key}, nil
+}
+
+// decrypt returns the nested JWT from an encrypted ID token. ID tokens which are not encrypted are returned unchanged,
+// since their signatures will be validated anyway.
+func (d *IDTokenDecrypter) decrypt(idToken string) (string, error) {
+	// A JWE in compact serialization has five parts, while a JWS has three.
+	if strings.Count(idToken, ".") != 4 {
+		return idToken, nil
+	}
+
+	encrypted, err := jose.ParseEncrypted(idToken)
+	if err != nil {
+		return "", fmt.Errorf("could not parse encrypted ID token: %w", err)
+	}
+	if kid := encrypted.Header.KeyID; kid != "" && d.key.KeyID != "" && kid != d.key.KeyID {
+		return "", fmt.Errorf("encrypted ID token has key ID %q which does not match the decryption key ID %q", kid, d.key.KeyID)
+	}
+	decrypted, err := encrypted.Decrypt(d.key)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt ID token: %w", err)
+	}
+	return string(decrypted), nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "pinniped_supervisor"
+	metricsSubsystem = "upstream_oidc"
+
+	metricsLabelIDP       = "idp"
+	metricsLabelOperation = "operation"
+)
+
+// requestRetryMetrics are the metrics for the retries of requests to upstream OIDC IDPs.
+type requestRetryMetrics struct {
+	retries   *metrics.CounterVec
+	exhausted *metrics.CounterVec
+}
+
+//nolint:gochecknoglobals // Metrics are registered once with the global registry which is served by the Supervisor.
+var (
+	retryMetrics = &requestRetryMetrics{
+		retries: metrics.NewCounterVec(&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "request_retries_total",
+			Help:           "Number of retried requests to the token and revocation endpoints of upstream OIDC IDPs, partitioned by identity provider name and operation.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{metricsLabelIDP, metricsLabelOperation}),
+		exhausted: metrics.NewCounterVec(&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "request_retries_exhausted_total",
+			Help:           "Number of requests to the token and revocation endpoints of upstream OIDC IDPs which still failed after their last allowed attempt, partitioned by identity provider name and operation.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{metricsLabelIDP, metricsLabelOperation}),
+	}
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the requests to upstream OIDC IDPs with the global registry, which is
+// served on the /metrics endpoint of the Supervisor's aggregated API server. It is safe to call more than once.
+func RegisterMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(retryMetrics.retries, retryMetrics.exhausted)
+	})
+}
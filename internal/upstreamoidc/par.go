@@ -0,0 +1,88 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func (p *ProviderConfig) UsesPushedAuthorizationRequests() bool {
+	return p.PushedAuthorizationRequestURL != nil
+}
+
+// PushAuthorizationRequest sends the authorization request params to the provider's pushed authorization request
+// endpoint, and returns the request_uri which refers to them in the authorization request.
+// See https://datatracker.ietf.org/doc/html/rfc9126#section-2 for details.
+func (p *ProviderConfig) PushAuthorizationRequest(ctx context.Context, params url.Values) (string, error) {
+	if p.PushedAuthorizationRequestURL == nil {
+		return "", fmt.Errorf("upstream provider %q does not use pushed authorization requests", p.Name)
+	}
+
+	// Copy the params, since the client authentication is added to them.
+	body := url.Values{}
+	for k, v := range params {
+		body[k] = append([]string(nil), v...)
+	}
+	clientID := p.Config.ClientID
+	useBasicAuth := false
+	switch {
+	case p.ClientAssertionSigner != nil:
+		if err := p.ClientAssertionSigner.addClientAssertion(body, clientID, p.Config.Endpoint.TokenURL); err != nil {
+			return "", err
+		}
+	case p.ClientAuthenticationMethod == ClientAuthenticationMethodClientSecretPost:
+		body.Set("client_id", clientID)
+		body.Set("client_secret", p.Config.ClientSecret)
+	default:
+		// Every provider must support basic auth for clients which have a client secret.
+		// See https://datatracker.ietf.org/doc/html/rfc6749#section-2.3.1.
+		useBasicAuth = true
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.PushedAuthorizationRequestURL.String(), strings.NewReader(body.Encode()))
+	if err != nil {
+		// This shouldn't really happen since we already know that the method and URL are legal.
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if useBasicAuth {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(p.Config.ClientSecret))
+	}
+
+	// Use the provided HTTP client to benefit from its CA, proxy, and other settings.
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response body on response with status code %d: %w", resp.StatusCode, err)
+	}
+	bodyStr := strings.TrimSpace(string(respBody)) // trimmed for error messages
+
+	// The spec says 201 Created, but be lenient and also accept 200 OK.
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server responded with status %d with body: %s", resp.StatusCode, bodyStr)
+	}
+
+	var parsedResp struct {
+		RequestURI string `json:"request_uri"`
+		ExpiresIn  int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(respBody, &parsedResp); err != nil {
+		return "", fmt.Errorf("error parsing response body %q on response with status code %d: %w", bodyStr, resp.StatusCode, err)
+	}
+	if parsedResp.RequestURI == "" {
+		return "", fmt.Errorf("server responded without a request_uri in body: %s", bodyStr)
+	}
+	return parsedResp.RequestURI, nil
+}
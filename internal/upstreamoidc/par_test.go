@@ -0,0 +1,177 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestPushAuthorizationRequest(t *testing.T) {
+	const (
+		clientID     = "some-client-id"
+		clientSecret = "some-client-secret"
+	)
+
+	clientSecretSigner, err := NewClientSecretJWTSigner(clientSecret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                 string
+		authenticationMethod ClientAuthenticationMethod
+		signer               *ClientAssertionSigner
+		noPARURL             bool
+		responseStatus       int
+		responseBody         string
+
+		wantBasicAuth     bool
+		wantClientSecret  bool
+		wantAssertion     bool
+		wantRequestURI    string
+		wantErr           string
+		wantNoServerCalls bool
+	}{
+		{
+			name:           "default client authentication uses basic auth",
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"request_uri":"urn:ietf:params:oauth:request_uri:abc","expires_in":60}`,
+			wantBasicAuth:  true,
+			wantRequestURI: "urn:ietf:params:oauth:request_uri:abc",
+		},
+		{
+			name:                 "client_secret_post sends the client secret in the params",
+			authenticationMethod: ClientAuthenticationMethodClientSecretPost,
+			responseStatus:       http.StatusCreated,
+			responseBody:         `{"request_uri":"urn:ietf:params:oauth:request_uri:abc","expires_in":60}`,
+			wantClientSecret:     true,
+			wantRequestURI:       "urn:ietf:params:oauth:request_uri:abc",
+		},
+		{
+			name:                 "client_secret_jwt sends a client assertion in the params",
+			authenticationMethod: ClientAuthenticationMethodClientSecretJWT,
+			signer:               clientSecretSigner,
+			responseStatus:       http.StatusCreated,
+			responseBody:         `{"request_uri":"urn:ietf:params:oauth:request_uri:abc","expires_in":60}`,
+			wantAssertion:        true,
+			wantRequestURI:       "urn:ietf:params:oauth:request_uri:abc",
+		},
+		{
+			name:           "server responds with 200 instead of 201",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"request_uri":"urn:ietf:params:oauth:request_uri:abc","expires_in":60}`,
+			wantBasicAuth:  true,
+			wantRequestURI: "urn:ietf:params:oauth:request_uri:abc",
+		},
+		{
+			name:           "server responds with an error status",
+			responseStatus: http.StatusBadRequest,
+			responseBody:   `{"error":"invalid_request"}`,
+			wantBasicAuth:  true,
+			wantErr:        `server responded with status 400 with body: {"error":"invalid_request"}`,
+		},
+		{
+			name:           "server responds with invalid JSON",
+			responseStatus: http.StatusCreated,
+			responseBody:   `not json`,
+			wantBasicAuth:  true,
+			wantErr:        `error parsing response body "not json" on response with status code 201: invalid character 'o' in literal null (expecting 'u')`,
+		},
+		{
+			name:           "server responds without a request_uri",
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"expires_in":60}`,
+			wantBasicAuth:  true,
+			wantErr:        `server responded without a request_uri in body: {"expires_in":60}`,
+		},
+		{
+			name:              "provider does not use pushed authorization requests",
+			noPARURL:          true,
+			wantErr:           `upstream provider "test-name" does not use pushed authorization requests`,
+			wantNoServerCalls: true,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			serverCalls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				serverCalls++
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "/par", r.URL.Path)
+				require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "some-state", r.Form.Get("state"))
+				require.Equal(t, "code", r.Form.Get("response_type"))
+
+				username, password, hasBasicAuth := r.BasicAuth()
+				require.Equal(t, tt.wantBasicAuth, hasBasicAuth)
+				if tt.wantBasicAuth {
+					require.Equal(t, clientID, username)
+					require.Equal(t, clientSecret, password)
+				}
+				if tt.wantClientSecret {
+					require.Equal(t, clientID, r.Form.Get("client_id"))
+					require.Equal(t, clientSecret, r.Form.Get("client_secret"))
+				} else {
+					require.NotContains(t, r.Form, "client_secret")
+				}
+				if tt.wantAssertion {
+					require.Equal(t, clientID, r.Form.Get("client_id"))
+					require.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.Form.Get("client_assertion_type"))
+					require.NotEmpty(t, r.Form.Get("client_assertion"))
+				} else {
+					require.NotContains(t, r.Form, "client_assertion")
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			t.Cleanup(server.Close)
+
+			var parURL *url.URL
+			if !tt.noPARURL {
+				parURL, err = url.Parse(server.URL + "/par")
+				require.NoError(t, err)
+			}
+
+			p := ProviderConfig{
+				Name: "test-name",
+				Config: &oauth2.Config{
+					ClientID:     clientID,
+					ClientSecret: clientSecret,
+					Endpoint:     oauth2.Endpoint{TokenURL: server.URL + "/token"},
+				},
+				Client:                        &http.Client{},
+				ClientAuthenticationMethod:    tt.authenticationMethod,
+				ClientAssertionSigner:         tt.signer,
+				PushedAuthorizationRequestURL: parURL,
+			}
+			require.Equal(t, !tt.noPARURL, p.UsesPushedAuthorizationRequests())
+
+			params := url.Values{"state": {"some-state"}, "response_type": {"code"}}
+			requestURI, err := p.PushAuthorizationRequest(context.Background(), params)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantRequestURI, requestURI)
+			}
+			// The params passed by the caller should not have been modified.
+			require.Equal(t, url.Values{"state": {"some-state"}, "response_type": {"code"}}, params)
+
+			if tt.wantNoServerCalls {
+				require.Zero(t, serverCalls)
+			} else {
+				require.Equal(t, 1, serverCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	retryOperationRefresh    = "refresh"
+	retryOperationRevocation = "revocation"
+)
+
+// RetryPolicy configures how the requests to the token and revocation endpoints are retried during refreshes and
+// revocations when they fail with a network error or with one of the RetryableStatusCodes.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of each request, including the first attempt.
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the first retry. Each further retry waits twice as long as the
+	// previous one, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RetryableStatusCodes are the HTTP status codes of the responses after which a request is retried.
+	RetryableStatusCodes map[int]bool
+}
+
+// retryingClient returns the given client when there is no retry policy, or otherwise a copy of it which retries
+// its failed requests according to the retry policy, recording its retries in the metrics of the given operation.
+func (p *ProviderConfig) retryingClient(client *http.Client, operation string) *http.Client {
+	if p.RetryPolicy == nil || p.RetryPolicy.MaxAttempts <= 1 {
+		return client
+	}
+	retrying := &http.Client{}
+	if client != nil {
+		*retrying = *client
+	}
+	base := retrying.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	retrying.Transport = &retryingRoundTripper{
+		base:      base,
+		policy:    p.RetryPolicy,
+		idpName:   p.Name,
+		operation: operation,
+	}
+	return retrying
+}
+
+// retryingRoundTripper is an http.RoundTripper which retries failed requests according to a RetryPolicy.
+type retryingRoundTripper struct {
+	base      http.RoundTripper
+	policy    *RetryPolicy
+	idpName   string
+	operation string
+}
+
+func (rt *retryingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The body of the request can only be sent again when it can be recreated.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return rt.base.RoundTrip(req)
+	}
+
+	backoff := rt.policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			// A RoundTripper must not modify the original request.
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := rt.base.RoundTrip(attemptReq)
+		if !rt.shouldRetry(resp, err) {
+			return resp, err
+		}
+		if attempt >= rt.policy.MaxAttempts {
+			retryMetrics.exhausted.WithLabelValues(rt.idpName, rt.operation).Inc()
+			return resp, err
+		}
+
+		plog.Debug("retrying failed request to upstream OIDC provider",
+			"providerName", rt.idpName,
+			"operation", rt.operation,
+			"attempt", attempt,
+			"backoff", backoff.String(),
+			"status", statusCodeForLog(resp),
+			"err", err,
+		)
+		if resp != nil {
+			// Drain and close the body so that the connection can be reused by the next attempt.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		retryMetrics.retries.WithLabelValues(rt.idpName, rt.operation).Inc()
+		backoff *= 2
+		if backoff > rt.policy.MaxBackoff {
+			backoff = rt.policy.MaxBackoff
+		}
+	}
+}
+
+// shouldRetry returns true when the request failed without a response, or when the response has a retryable status.
+func (rt *retryingRoundTripper) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return rt.policy.RetryableStatusCodes[resp.StatusCode]
+}
+
+func statusCodeForLog(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
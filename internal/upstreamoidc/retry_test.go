@@ -0,0 +1,194 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"k8s.io/component-base/metrics/testutil"
+
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	RegisterMetrics()
+	RegisterMetrics() // registering again is a no-op
+
+	testPolicy := &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       time.Millisecond,
+		MaxBackoff:           2 * time.Millisecond,
+		RetryableStatusCodes: map[int]bool{http.StatusBadGateway: true, http.StatusServiceUnavailable: true},
+	}
+
+	// newServer returns a server which responds with the given statuses in order, and then with 200 OK.
+	newServer := func(t *testing.T, statuses []int, okBody string) (*httptest.Server, *int32) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "test-client-id", r.Form.Get("client_id"))
+			i := int(atomic.AddInt32(&requests, 1)) - 1
+			if i < len(statuses) {
+				w.WriteHeader(statuses[i])
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(okBody))
+		}))
+		t.Cleanup(server.Close)
+		return server, &requests
+	}
+
+	counts := func(t *testing.T, idpName, operation string) (float64, float64) {
+		t.Helper()
+		retries, err := testutil.GetCounterMetricValue(retryMetrics.retries.WithLabelValues(idpName, operation))
+		require.NoError(t, err)
+		exhausted, err := testutil.GetCounterMetricValue(retryMetrics.exhausted.WithLabelValues(idpName, operation))
+		require.NoError(t, err)
+		return retries, exhausted
+	}
+
+	refreshTests := []struct {
+		name          string
+		policy        *RetryPolicy
+		statuses      []int
+		wantErr       string
+		wantRequests  int32
+		wantRetries   float64
+		wantExhausted float64
+	}{
+		{
+			name:         "succeeds after retrying retryable statuses",
+			policy:       testPolicy,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			wantRequests: 3,
+			wantRetries:  2,
+		},
+		{
+			name:          "fails after the last attempt",
+			policy:        testPolicy,
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			wantErr:       "oauth2: cannot fetch token: 503 Service Unavailable",
+			wantRequests:  3,
+			wantRetries:   2,
+			wantExhausted: 1,
+		},
+		{
+			name:         "does not retry a status which is not retryable",
+			policy:       testPolicy,
+			statuses:     []int{http.StatusBadRequest},
+			wantErr:      "oauth2: cannot fetch token: 400 Bad Request",
+			wantRequests: 1,
+		},
+		{
+			name:         "does not retry without a retry policy",
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantErr:      "oauth2: cannot fetch token: 503 Service Unavailable",
+			wantRequests: 1,
+		},
+	}
+	for _, test := range refreshTests {
+		tt := test
+		t.Run("PerformRefresh "+tt.name, func(t *testing.T) {
+			server, requests := newServer(t, tt.statuses, `{"access_token":"test-access-token","token_type":"Bearer","expires_in":3600}`)
+			idpName := "refresh-" + tt.name
+			p := ProviderConfig{
+				Name: idpName,
+				Config: &oauth2.Config{
+					ClientID:     "test-client-id",
+					ClientSecret: "test-client-secret",
+					Endpoint:     oauth2.Endpoint{TokenURL: server.URL + "/token", AuthStyle: oauth2.AuthStyleInParams},
+				},
+				Client:      server.Client(),
+				RetryPolicy: tt.policy,
+			}
+
+			tok, err := p.PerformRefresh(context.Background(), "test-refresh-token")
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "test-access-token", tok.AccessToken)
+			}
+			require.Equal(t, tt.wantRequests, atomic.LoadInt32(requests))
+
+			retries, exhausted := counts(t, idpName, retryOperationRefresh)
+			require.Equal(t, tt.wantRetries, retries)
+			require.Equal(t, tt.wantExhausted, exhausted)
+		})
+	}
+
+	t.Run("RevokeToken succeeds after retrying a retryable status", func(t *testing.T) {
+		server, requests := newServer(t, []int{http.StatusBadGateway}, "")
+		revocationURL, err := url.Parse(server.URL + "/revoke")
+		require.NoError(t, err)
+		idpName := "revoke-succeeds"
+		p := ProviderConfig{
+			Name:                       idpName,
+			Config:                     &oauth2.Config{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
+			Client:                     server.Client(),
+			RevocationURL:              revocationURL,
+			ClientAuthenticationMethod: ClientAuthenticationMethodClientSecretPost,
+			RetryPolicy:                testPolicy,
+		}
+
+		require.NoError(t, p.RevokeToken(context.Background(), "test-token", provider.RefreshTokenType))
+		require.Equal(t, int32(2), atomic.LoadInt32(requests))
+
+		retries, exhausted := counts(t, idpName, retryOperationRevocation)
+		require.Equal(t, float64(1), retries)
+		require.Equal(t, float64(0), exhausted)
+	})
+
+	t.Run("RevokeToken retries network errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		revocationURL, err := url.Parse(server.URL + "/revoke")
+		require.NoError(t, err)
+		server.Close()
+		idpName := "revoke-network-error"
+		p := ProviderConfig{
+			Name:                       idpName,
+			Config:                     &oauth2.Config{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
+			Client:                     &http.Client{},
+			RevocationURL:              revocationURL,
+			ClientAuthenticationMethod: ClientAuthenticationMethodClientSecretPost,
+			RetryPolicy:                testPolicy,
+		}
+
+		err = p.RevokeToken(context.Background(), "test-token", provider.RefreshTokenType)
+		require.ErrorAs(t, err, &provider.RetryableRevocationError{})
+
+		retries, exhausted := counts(t, idpName, retryOperationRevocation)
+		require.Equal(t, float64(2), retries)
+		require.Equal(t, float64(1), exhausted)
+	})
+
+	t.Run("stops waiting for the next attempt when the context is canceled", func(t *testing.T) {
+		server, requests := newServer(t, []int{http.StatusServiceUnavailable}, "")
+		revocationURL, err := url.Parse(server.URL + "/revoke")
+		require.NoError(t, err)
+		p := ProviderConfig{
+			Name:                       "revoke-canceled",
+			Config:                     &oauth2.Config{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
+			Client:                     server.Client(),
+			RevocationURL:              revocationURL,
+			ClientAuthenticationMethod: ClientAuthenticationMethodClientSecretPost,
+			RetryPolicy:                &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour, RetryableStatusCodes: testPolicy.RetryableStatusCodes},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err = p.RevokeToken(ctx, "test-token", provider.RefreshTokenType)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, int32(1), atomic.LoadInt32(requests))
+	})
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package upstreamoidc implements an abstraction of upstream OIDC provider interactions.
@@ -15,15 +15,19 @@ import (
 	"time"
 
 	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/claimexpressions"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/tracing"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
@@ -50,6 +54,27 @@ type ProviderConfig struct {
 		Claims(v interface{}) error
 		UserInfo(ctx context.Context, tokenSource oauth2.TokenSource) (*coreosoidc.UserInfo, error)
 	}
+
+	// ClientAuthenticationMethod is only consulted for revocation requests. Token requests use the AuthStyle of
+	// the Config's Endpoint, or the ClientAssertionSigner when it is not nil.
+	ClientAuthenticationMethod ClientAuthenticationMethod
+	ClientAssertionSigner      *ClientAssertionSigner // only used by the client_secret_jwt and private_key_jwt methods
+
+	// PushedAuthorizationRequestURL is nil when pushed authorization requests should not be used.
+	PushedAuthorizationRequestURL *url.URL
+
+	// ClaimExpressions is nil when no claim expressions are configured.
+	ClaimExpressions *claimexpressions.Expressions
+
+	// MicrosoftGraphURL is the base URL of Microsoft Graph, which is used to resolve Azure AD groups overage claims.
+	// It is empty when groups overage claims should not be resolved.
+	MicrosoftGraphURL string
+
+	// IDTokenDecrypter is nil when ID tokens are not expected to be encrypted.
+	IDTokenDecrypter *IDTokenDecrypter
+
+	// RetryPolicy is nil when failed requests during refreshes and revocations should not be retried.
+	RetryPolicy *RetryPolicy
 }
 
 var _ provider.UpstreamOIDCIdentityProviderI = (*ProviderConfig)(nil)
@@ -83,6 +108,10 @@ func (p *ProviderConfig) GetAdditionalClaimMappings() map[string]string {
 	return p.AdditionalClaimMappings
 }
 
+func (p *ProviderConfig) GetClaimExpressions() *claimexpressions.Expressions {
+	return p.ClaimExpressions
+}
+
 func (p *ProviderConfig) GetName() string {
 	return p.Name
 }
@@ -112,7 +141,10 @@ func (p *ProviderConfig) AllowsPasswordGrant() bool {
 	return p.AllowPasswordGrant
 }
 
-func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(ctx context.Context, username, password string) (*oidctypes.Token, error) {
+func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(ctx context.Context, username, password string) (_ *oidctypes.Token, err error) {
+	ctx, span := p.startSpan(ctx, "upstream password grant")
+	defer func() { tracing.End(span, err) }()
+
 	// Disallow this grant when requested.
 	if !p.AllowPasswordGrant {
 		return nil, fmt.Errorf("resource owner password credentials grant is not allowed for this upstream provider according to its configuration")
@@ -120,7 +152,7 @@ func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(ctx context.C
 
 	// Note that this implicitly uses the scopes from p.Config.Scopes.
 	tok, err := p.Config.PasswordCredentialsToken(
-		coreosoidc.ClientContext(ctx, p.Client),
+		coreosoidc.ClientContext(ctx, p.tokenEndpointClient()),
 		username,
 		password,
 	)
@@ -134,9 +166,12 @@ func (p *ProviderConfig) PasswordCredentialsGrantAndValidateTokens(ctx context.C
 	return p.ValidateTokenAndMergeWithUserInfo(ctx, tok, skipNonceValidation, true, false)
 }
 
-func (p *ProviderConfig) ExchangeAuthcodeAndValidateTokens(ctx context.Context, authcode string, pkceCodeVerifier pkce.Code, expectedIDTokenNonce nonce.Nonce, redirectURI string) (*oidctypes.Token, error) {
+func (p *ProviderConfig) ExchangeAuthcodeAndValidateTokens(ctx context.Context, authcode string, pkceCodeVerifier pkce.Code, expectedIDTokenNonce nonce.Nonce, redirectURI string) (_ *oidctypes.Token, err error) {
+	ctx, span := p.startSpan(ctx, "upstream token exchange")
+	defer func() { tracing.End(span, err) }()
+
 	tok, err := p.Config.Exchange(
-		coreosoidc.ClientContext(ctx, p.Client),
+		coreosoidc.ClientContext(ctx, p.tokenEndpointClient()),
 		authcode,
 		pkceCodeVerifier.Verifier(),
 		oauth2.SetAuthURLParam("redirect_uri", redirectURI),
@@ -148,14 +183,27 @@ func (p *ProviderConfig) ExchangeAuthcodeAndValidateTokens(ctx context.Context,
 	return p.ValidateTokenAndMergeWithUserInfo(ctx, tok, expectedIDTokenNonce, true, false)
 }
 
-func (p *ProviderConfig) PerformRefresh(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+func (p *ProviderConfig) PerformRefresh(ctx context.Context, refreshToken string) (_ *oauth2.Token, err error) {
+	ctx, span := p.startSpan(ctx, "upstream refresh")
+	defer func() { tracing.End(span, err) }()
+
 	// Use the provided HTTP client to benefit from its CA, proxy, and other settings.
-	httpClientContext := coreosoidc.ClientContext(ctx, p.Client)
+	// Retry around the client assertions, so that each attempt uses a fresh client assertion.
+	httpClientContext := coreosoidc.ClientContext(ctx, p.retryingClient(p.tokenEndpointClient(), retryOperationRefresh))
 	// Create a TokenSource without an access token, so it thinks that a refresh is immediately required.
 	// Then ask it for the tokens to cause it to perform the refresh and return the results.
 	return p.Config.TokenSource(httpClientContext, &oauth2.Token{RefreshToken: refreshToken}).Token()
 }
 
+// startSpan traces a request to the upstream provider, so that slow upstream providers can be told apart in
+// the traces of logins.
+func (p *ProviderConfig) startSpan(ctx context.Context, spanName string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, spanName,
+		attribute.String("pinniped.upstream.name", p.Name),
+		attribute.String("pinniped.upstream.type", "oidc"),
+	)
+}
+
 // RevokeToken will attempt to revoke the given token, if the provider has a revocation endpoint.
 // It may return an error wrapped by a RetryableRevocationError, which is an error indicating that it may
 // be worth trying to revoke the same token again later. Any other error returned should be assumed to
@@ -168,6 +216,15 @@ func (p *ProviderConfig) RevokeToken(ctx context.Context, token string, tokenTyp
 		)
 		return nil
 	}
+	switch {
+	case p.ClientAssertionSigner != nil, p.ClientAuthenticationMethod == ClientAuthenticationMethodClientSecretPost:
+		// Only use client auth in the request params, which is where the client assertion goes.
+		_, err := p.tryRevokeToken(ctx, token, tokenType, false)
+		return err
+	case p.ClientAuthenticationMethod == ClientAuthenticationMethodClientSecretBasic:
+		_, err := p.tryRevokeToken(ctx, token, tokenType, true)
+		return err
+	}
 	// First try using client auth in the request params.
 	tryAnotherClientAuthMethod, err := p.tryRevokeToken(ctx, token, tokenType, false)
 	if tryAnotherClientAuthMethod {
@@ -194,7 +251,7 @@ func (p *ProviderConfig) tryRevokeToken(
 	clientID := p.Config.ClientID
 	clientSecret := p.Config.ClientSecret
 	// Use the provided HTTP client to benefit from its CA, proxy, and other settings.
-	httpClient := p.Client
+	httpClient := p.retryingClient(p.Client, retryOperationRevocation)
 
 	params := url.Values{
 		"token":           []string{token},
@@ -202,7 +259,13 @@ func (p *ProviderConfig) tryRevokeToken(
 	}
 	if !useBasicAuth {
 		params["client_id"] = []string{clientID}
-		params["client_secret"] = []string{clientSecret}
+		if p.ClientAssertionSigner != nil {
+			if err := p.ClientAssertionSigner.addClientAssertion(params, clientID, p.Config.Endpoint.TokenURL); err != nil {
+				return false, err
+			}
+		} else {
+			params["client_secret"] = []string{clientSecret}
+		}
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.RevocationURL.String(), strings.NewReader(params.Encode()))
@@ -301,6 +364,10 @@ func (p *ProviderConfig) ValidateTokenAndMergeWithUserInfo(ctx context.Context,
 		}
 	}
 
+	if err := p.maybeResolveGroupsOverage(ctx, validatedClaims); err != nil {
+		return nil, httperr.Wrap(http.StatusInternalServerError, "could not resolve groups overage claim", err)
+	}
+
 	return &oidctypes.Token{
 		AccessToken: &oidctypes.AccessToken{
 			Token:  tok.AccessToken,
@@ -328,7 +395,14 @@ func (p *ProviderConfig) validateIDToken(ctx context.Context, tok *oauth2.Token,
 	if !hasIDTok {
 		return time.Time{}, "", httperr.New(http.StatusBadRequest, "received response missing ID token")
 	}
-	validated, err := p.Provider.Verifier(&coreosoidc.Config{ClientID: p.GetClientID()}).Verify(coreosoidc.ClientContext(ctx, p.Client), idTok)
+	signedIDTok := idTok
+	if p.IDTokenDecrypter != nil {
+		var err error
+		if signedIDTok, err = p.IDTokenDecrypter.decrypt(idTok); err != nil {
+			return time.Time{}, "", httperr.Wrap(http.StatusBadRequest, "received invalid ID token", err)
+		}
+	}
+	validated, err := p.Provider.Verifier(&coreosoidc.Config{ClientID: p.GetClientID()}).Verify(coreosoidc.ClientContext(ctx, p.Client), signedIDTok)
 	if err != nil {
 		return time.Time{}, "", httperr.Wrap(http.StatusBadRequest, "received invalid ID token", err)
 	}
@@ -0,0 +1,335 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package x509clienttoken creates and verifies the tokens which prove the possession of an X.509 client certificate,
+// e.g. one which is stored on a smart card. A token is a JWT which is signed by the private key of the client
+// certificate, and which includes the client certificate and any intermediate certificates in its x5c header.
+package x509clienttoken
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+const (
+	// MaxLifetime is the longest time between the iat and exp claims of a token.
+	MaxLifetime = 5 * time.Minute
+
+	// clockSkewLeeway allows for some clock skew between the client and the Concierge.
+	clockSkewLeeway = time.Minute
+)
+
+//nolint:gochecknoglobals
+var (
+	oidSubjectAltName    = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidUserPrincipalName = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+
+	supportedAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.ES256, jose.ES384, jose.ES512}
+)
+
+// New returns a token for the audience which is signed by the signer. The chain starts with the client certificate
+// whose public key belongs to the signer, followed by any intermediate certificates. The signer may be a key which
+// cannot be exported, e.g. one which is stored on a smart card.
+func New(signer crypto.Signer, chain []*x509.Certificate, audience string, now time.Time, lifetime time.Duration) (string, error) {
+	if len(chain) == 0 {
+		return "", errors.New("the certificate chain must not be empty")
+	}
+	if lifetime <= 0 || lifetime > MaxLifetime {
+		return "", fmt.Errorf("the lifetime must be between 0 and %s", MaxLifetime)
+	}
+
+	alg, err := signatureAlgorithm(signer.Public())
+	if err != nil {
+		return "", err
+	}
+
+	x5c := make([]string, 0, len(chain))
+	for _, cert := range chain {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+
+	joseSigner, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: alg, Key: &opaqueSigner{signer: signer, alg: alg}},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("x5c", x5c),
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not create signer: %w", err)
+	}
+
+	return jwt.Signed(joseSigner).Claims(jwt.Claims{
+		Audience: jwt.Audience{audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(lifetime)),
+	}).CompactSerialize()
+}
+
+// Verifier verifies tokens and extracts the identities of the users from their client certificates.
+type Verifier struct {
+	// Roots is the pool of the certificate authorities which issue the client certificates. It must not be nil,
+	// because the system roots are never trusted.
+	Roots *x509.CertPool
+
+	// Audience is the required value of the aud claim of the tokens.
+	Audience string
+
+	// AllowedOrganizationalUnits restricts which client certificates are accepted, unless it is empty.
+	AllowedOrganizationalUnits []string
+
+	// UsernameSource is the part of the client certificates which is used as the username.
+	UsernameSource auth1alpha1.X509UsernameSource
+
+	// GroupsSource is the part of the client certificates which is used as the groups.
+	GroupsSource auth1alpha1.X509GroupsSource
+}
+
+// Verify checks the signature, claims and client certificate of the token, and returns the username and groups.
+func (v *Verifier) Verify(token string, now time.Time) (string, []string, error) {
+	if v.Roots == nil {
+		return "", nil, errors.New("no certificate authority is configured")
+	}
+
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not parse token: %w", err)
+	}
+	if len(tok.Headers) != 1 {
+		return "", nil, errors.New("token must have exactly one signature")
+	}
+	if !isSupportedAlgorithm(tok.Headers[0].Algorithm) {
+		return "", nil, fmt.Errorf("unsupported signature algorithm %q", tok.Headers[0].Algorithm)
+	}
+
+	chains, err := tok.Headers[0].Certificates(x509.VerifyOptions{
+		Roots:       v.Roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("could not verify client certificate: %w", err)
+	}
+	cert := chains[0][0]
+
+	var claims jwt.Claims
+	if err := tok.Claims(cert.PublicKey, &claims); err != nil {
+		return "", nil, fmt.Errorf("could not verify token signature: %w", err)
+	}
+	if claims.IssuedAt == nil || claims.Expiry == nil {
+		return "", nil, errors.New("token must have iat and exp claims")
+	}
+	if claims.Expiry.Time().Sub(claims.IssuedAt.Time()) > MaxLifetime {
+		return "", nil, fmt.Errorf("token must not be valid for longer than %s", MaxLifetime)
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{Audience: jwt.Audience{v.Audience}, Time: now}, clockSkewLeeway); err != nil {
+		return "", nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	if !v.hasAllowedOrganizationalUnit(cert) {
+		return "", nil, errors.New("client certificate does not have an allowed organizational unit")
+	}
+
+	username, err := v.username(cert)
+	if err != nil {
+		return "", nil, err
+	}
+	return username, v.groups(cert), nil
+}
+
+func (v *Verifier) hasAllowedOrganizationalUnit(cert *x509.Certificate) bool {
+	if len(v.AllowedOrganizationalUnits) == 0 {
+		return true
+	}
+	for _, allowed := range v.AllowedOrganizationalUnits {
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if ou == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *Verifier) username(cert *x509.Certificate) (string, error) {
+	var username string
+	switch v.UsernameSource {
+	case auth1alpha1.X509UsernameSourceCommonName, "":
+		username = cert.Subject.CommonName
+	case auth1alpha1.X509UsernameSourceEmailAddress:
+		if len(cert.EmailAddresses) > 0 {
+			username = cert.EmailAddresses[0]
+		}
+	case auth1alpha1.X509UsernameSourceUserPrincipalName:
+		upns, err := userPrincipalNames(cert)
+		if err != nil {
+			return "", err
+		}
+		if len(upns) > 0 {
+			username = upns[0]
+		}
+	default:
+		return "", fmt.Errorf("unsupported username source %q", v.UsernameSource)
+	}
+	if username == "" {
+		return "", fmt.Errorf("client certificate does not have a username in its %s", usernameSourceOrDefault(v.UsernameSource))
+	}
+	return username, nil
+}
+
+func (v *Verifier) groups(cert *x509.Certificate) []string {
+	switch v.GroupsSource {
+	case auth1alpha1.X509GroupsSourceOrganizationalUnit:
+		return cert.Subject.OrganizationalUnit
+	case auth1alpha1.X509GroupsSourceNone:
+		return nil
+	default:
+		return cert.Subject.Organization
+	}
+}
+
+func usernameSourceOrDefault(source auth1alpha1.X509UsernameSource) auth1alpha1.X509UsernameSource {
+	if source == "" {
+		return auth1alpha1.X509UsernameSourceCommonName
+	}
+	return source
+}
+
+// userPrincipalNames returns the Microsoft user principal names from the otherName entries of the subject
+// alternative names, which the x509 package does not parse.
+func userPrincipalNames(cert *x509.Certificate) ([]string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+
+		var seq asn1.RawValue
+		if rest, err := asn1.Unmarshal(ext.Value, &seq); err != nil || len(rest) != 0 ||
+			seq.Class != asn1.ClassUniversal || seq.Tag != asn1.TagSequence {
+			return nil, errors.New("client certificate has invalid subject alternative names")
+		}
+
+		var upns []string
+		for rest := seq.Bytes; len(rest) > 0; {
+			var name asn1.RawValue
+			var err error
+			if rest, err = asn1.Unmarshal(rest, &name); err != nil {
+				return nil, errors.New("client certificate has invalid subject alternative names")
+			}
+			// The otherName entries have the context-specific tag 0.
+			if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+				continue
+			}
+			var otherName struct {
+				TypeID asn1.ObjectIdentifier
+				Value  asn1.RawValue
+			}
+			if _, err := asn1.UnmarshalWithParams(name.FullBytes, &otherName, "tag:0"); err != nil ||
+				otherName.Value.Class != asn1.ClassContextSpecific || otherName.Value.Tag != 0 {
+				return nil, errors.New("client certificate has an invalid otherName subject alternative name")
+			}
+			if !otherName.TypeID.Equal(oidUserPrincipalName) {
+				continue
+			}
+			// The value is explicitly tagged, so its contents are the UTF8String.
+			var upn string
+			if _, err := asn1.UnmarshalWithParams(otherName.Value.Bytes, &upn, "utf8"); err != nil {
+				return nil, errors.New("client certificate has an invalid user principal name")
+			}
+			upns = append(upns, upn)
+		}
+		return upns, nil
+	}
+	return nil, nil
+}
+
+func isSupportedAlgorithm(alg string) bool {
+	for _, supported := range supportedAlgorithms {
+		if alg == string(supported) {
+			return true
+		}
+	}
+	return false
+}
+
+func signatureAlgorithm(publicKey crypto.PublicKey) (jose.SignatureAlgorithm, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jose.RS256, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// opaqueSigner adapts a crypto.Signer, e.g. the key of a smart card, to go-jose.
+type opaqueSigner struct {
+	signer crypto.Signer
+	alg    jose.SignatureAlgorithm
+}
+
+var _ jose.OpaqueSigner = &opaqueSigner{}
+
+func (o *opaqueSigner) Public() *jose.JSONWebKey {
+	return &jose.JSONWebKey{Key: o.signer.Public(), Algorithm: string(o.alg), Use: "sig"}
+}
+
+func (o *opaqueSigner) Algs() []jose.SignatureAlgorithm {
+	return []jose.SignatureAlgorithm{o.alg}
+}
+
+func (o *opaqueSigner) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	var hash crypto.Hash
+	switch alg { //nolint:exhaustive // only the algorithms which are returned by signatureAlgorithm are used
+	case jose.RS256, jose.ES256:
+		hash = crypto.SHA256
+	case jose.ES384:
+		hash = crypto.SHA384
+	case jose.ES512:
+		hash = crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+
+	hasher := hash.New()
+	_, _ = hasher.Write(payload)
+	signature, err := o.signer.Sign(rand.Reader, hasher.Sum(nil), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := o.signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return signature, nil
+	}
+
+	// A crypto.Signer returns ASN.1 encoded ECDSA signatures, but JWS uses the concatenation of r and s.
+	var ecdsaSignature struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &ecdsaSignature); err != nil {
+		return nil, fmt.Errorf("invalid ECDSA signature: %w", err)
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	out := make([]byte, 2*size)
+	ecdsaSignature.R.FillBytes(out[:size])
+	ecdsaSignature.S.FillBytes(out[size:])
+	return out, nil
+}
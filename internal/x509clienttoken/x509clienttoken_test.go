@@ -0,0 +1,348 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package x509clienttoken
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T, now time.Time) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+func (ca *testCA) issue(t *testing.T, key crypto.Signer, template *x509.Certificate) *x509.Certificate {
+	t.Helper()
+	template.SerialNumber = big.NewInt(2)
+	if template.NotAfter.IsZero() {
+		template.NotBefore = ca.cert.NotBefore
+		template.NotAfter = ca.cert.NotAfter
+	}
+	if template.ExtKeyUsage == nil {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// upnExtension returns a subject alternative name extension with an email address and user principal names.
+func upnExtension(t *testing.T, upns ...string) pkix.Extension {
+	t.Helper()
+	names := []asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 1, Bytes: []byte("someone@example.com")}}
+	for _, upn := range upns {
+		value, err := asn1.MarshalWithParams(upn, "utf8")
+		require.NoError(t, err)
+		otherName, err := asn1.MarshalWithParams(struct {
+			TypeID asn1.ObjectIdentifier
+			Value  asn1.RawValue
+		}{
+			TypeID: oidUserPrincipalName,
+			Value:  asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value},
+		}, "tag:0")
+		require.NoError(t, err)
+		names = append(names, asn1.RawValue{FullBytes: otherName})
+	}
+	der, err := asn1.Marshal(names)
+	require.NoError(t, err)
+	return pkix.Extension{Id: oidSubjectAltName, Value: der}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2099, time.August, 8, 13, 57, 36, 0, time.UTC)
+	ca := newTestCA(t, now)
+	otherCA := newTestCA(t, now)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	subject := pkix.Name{
+		CommonName:         "some-user",
+		Organization:       []string{"org-1", "org-2"},
+		OrganizationalUnit: []string{"ou-1", "ou-2"},
+	}
+	cert := ca.issue(t, ecKey, &x509.Certificate{Subject: subject})
+
+	tests := []struct {
+		name       string
+		verifier   Verifier
+		token      func(t *testing.T) string
+		wantErr    string
+		wantUser   string
+		wantGroups []string
+	}{
+		{
+			name:     "defaults to the common name and organizations",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				tok, err := New(ecKey, []*x509.Certificate{cert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantUser:   "some-user",
+			wantGroups: []string{"org-1", "org-2"},
+		},
+		{
+			name: "RSA key with an allowed organizational unit and organizational units as groups",
+			verifier: Verifier{
+				Audience:                   "some-audience",
+				AllowedOrganizationalUnits: []string{"other-ou", "ou-2"},
+				GroupsSource:               auth1alpha1.X509GroupsSourceOrganizationalUnit,
+			},
+			token: func(t *testing.T) string {
+				rsaCert := ca.issue(t, rsaKey, &x509.Certificate{Subject: subject})
+				tok, err := New(rsaKey, []*x509.Certificate{rsaCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantUser:   "some-user",
+			wantGroups: []string{"ou-1", "ou-2"},
+		},
+		{
+			name: "email address without groups",
+			verifier: Verifier{
+				Audience:       "some-audience",
+				UsernameSource: auth1alpha1.X509UsernameSourceEmailAddress,
+				GroupsSource:   auth1alpha1.X509GroupsSourceNone,
+			},
+			token: func(t *testing.T) string {
+				emailCert := ca.issue(t, ecKey, &x509.Certificate{Subject: subject, EmailAddresses: []string{"user@example.com"}})
+				tok, err := New(ecKey, []*x509.Certificate{emailCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantUser:   "user@example.com",
+			wantGroups: nil,
+		},
+		{
+			name: "user principal name",
+			verifier: Verifier{
+				Audience:       "some-audience",
+				UsernameSource: auth1alpha1.X509UsernameSourceUserPrincipalName,
+			},
+			token: func(t *testing.T) string {
+				upnCert := ca.issue(t, ecKey, &x509.Certificate{
+					Subject:         subject,
+					ExtraExtensions: []pkix.Extension{upnExtension(t, "user@corp.example.com", "other@corp.example.com")},
+				})
+				tok, err := New(ecKey, []*x509.Certificate{upnCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantUser:   "user@corp.example.com",
+			wantGroups: []string{"org-1", "org-2"},
+		},
+		{
+			name: "missing user principal name",
+			verifier: Verifier{
+				Audience:       "some-audience",
+				UsernameSource: auth1alpha1.X509UsernameSourceUserPrincipalName,
+			},
+			token: func(t *testing.T) string {
+				upnCert := ca.issue(t, ecKey, &x509.Certificate{
+					Subject:         subject,
+					ExtraExtensions: []pkix.Extension{upnExtension(t)},
+				})
+				tok, err := New(ecKey, []*x509.Certificate{upnCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "client certificate does not have a username in its UserPrincipalName",
+		},
+		{
+			name:     "missing common name",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				noCNCert := ca.issue(t, ecKey, &x509.Certificate{Subject: pkix.Name{Organization: []string{"org-1"}}})
+				tok, err := New(ecKey, []*x509.Certificate{noCNCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "client certificate does not have a username in its CommonName",
+		},
+		{
+			name:     "organizational unit is not allowed",
+			verifier: Verifier{Audience: "some-audience", AllowedOrganizationalUnits: []string{"other-ou"}},
+			token: func(t *testing.T) string {
+				tok, err := New(ecKey, []*x509.Certificate{cert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "client certificate does not have an allowed organizational unit",
+		},
+		{
+			name:     "wrong audience",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				tok, err := New(ecKey, []*x509.Certificate{cert}, "other-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "invalid token claims: square/go-jose/jwt: validation failed, invalid audience claim (aud)",
+		},
+		{
+			name:     "expired token",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				tok, err := New(ecKey, []*x509.Certificate{cert}, "some-audience", now.Add(-10*time.Minute), time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "invalid token claims: square/go-jose/jwt: validation failed, token is expired (exp)",
+		},
+		{
+			name:     "token is valid for too long",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				return signClaims(t, ecKey, cert, jwt.Claims{
+					Audience: jwt.Audience{"some-audience"},
+					IssuedAt: jwt.NewNumericDate(now),
+					Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+				})
+			},
+			wantErr: "token must not be valid for longer than 5m0s",
+		},
+		{
+			name:     "token without iat",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				return signClaims(t, ecKey, cert, jwt.Claims{
+					Audience: jwt.Audience{"some-audience"},
+					Expiry:   jwt.NewNumericDate(now.Add(time.Minute)),
+				})
+			},
+			wantErr: "token must have iat and exp claims",
+		},
+		{
+			name:     "client certificate is issued by another CA",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				otherCert := otherCA.issue(t, ecKey, &x509.Certificate{Subject: subject})
+				tok, err := New(ecKey, []*x509.Certificate{otherCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: `could not verify client certificate: x509: certificate signed by unknown authority (possibly because of "x509: ECDSA verification failure" while trying to verify candidate authority certificate "test-ca")`,
+		},
+		{
+			name:     "client certificate is not for client authentication",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				serverCert := ca.issue(t, ecKey, &x509.Certificate{Subject: subject, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+				tok, err := New(ecKey, []*x509.Certificate{serverCert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "could not verify client certificate: x509: certificate specifies an incompatible key usage",
+		},
+		{
+			name:     "token is signed by another key",
+			verifier: Verifier{Audience: "some-audience"},
+			token: func(t *testing.T) string {
+				otherKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+				require.NoError(t, err)
+				tok, err := New(otherKey, []*x509.Certificate{cert}, "some-audience", now, time.Minute)
+				require.NoError(t, err)
+				return tok
+			},
+			wantErr: "could not verify token signature: square/go-jose: error in cryptographic primitive",
+		},
+		{
+			name:     "not a JWT",
+			verifier: Verifier{Audience: "some-audience"},
+			token:    func(t *testing.T) string { return "some-opaque-token" },
+			wantErr:  "could not parse token: square/go-jose: compact JWS format must have three parts",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := tt.verifier
+			verifier.Roots = ca.pool()
+
+			username, groups, err := verifier.Verify(tt.token(t), now)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantUser, username)
+			require.Equal(t, tt.wantGroups, groups)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	now := time.Now()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = New(key, nil, "some-audience", now, time.Minute)
+	require.EqualError(t, err, "the certificate chain must not be empty")
+
+	cert := newTestCA(t, now).cert
+	_, err = New(key, []*x509.Certificate{cert}, "some-audience", now, time.Hour)
+	require.EqualError(t, err, "the lifetime must be between 0 and 5m0s")
+}
+
+// signClaims signs arbitrary claims, which New does not allow.
+func signClaims(t *testing.T, key *ecdsa.PrivateKey, cert *x509.Certificate, claims jwt.Claims) string {
+	t.Helper()
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES384, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("x5c", []string{base64.StdEncoding.EncodeToString(cert.Raw)}),
+	)
+	require.NoError(t, err)
+	tok, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return tok
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package conciergeclient provides login helpers for the Pinniped concierge.
@@ -9,6 +9,7 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -38,6 +39,7 @@ type Client struct {
 	caBundle       string
 	endpoint       *url.URL
 	apiGroupSuffix string
+	proxy          func(*http.Request) (*url.URL, error)
 }
 
 // WithAuthenticator configures the authenticator reference (spec.authenticator) of the TokenCredentialRequests.
@@ -54,8 +56,14 @@ func WithAuthenticator(authType, authName string) Option {
 		case "jwt":
 			authenticator.APIGroup = &auth1alpha1.SchemeGroupVersion.Group
 			authenticator.Kind = "JWTAuthenticator"
+		case "x509":
+			authenticator.APIGroup = &auth1alpha1.SchemeGroupVersion.Group
+			authenticator.Kind = "X509ClientCertificateAuthenticator"
+		case "serviceaccounttoken":
+			authenticator.APIGroup = &auth1alpha1.SchemeGroupVersion.Group
+			authenticator.Kind = "ServiceAccountTokenAuthenticator"
 		default:
-			return fmt.Errorf(`invalid authenticator type: %q, supported values are "webhook" and "jwt"`, authType)
+			return fmt.Errorf(`invalid authenticator type: %q, supported values are "webhook", "jwt", "x509", and "serviceaccounttoken"`, authType)
 		}
 		c.authenticator = &authenticator
 		return nil
@@ -116,6 +124,16 @@ func WithAPIGroupSuffix(apiGroupSuffix string) Option {
 	}
 }
 
+// WithProxy configures the function which returns the proxy to use when connecting to the concierge (see
+// http.Transport.Proxy). By default, the proxy configured by the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment
+// variables is used.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) error {
+		c.proxy = proxy
+		return nil
+	}
+}
+
 // New validates the specified options and returns a newly initialized *Client.
 func New(opts ...Option) (*Client, error) {
 	c := Client{apiGroupSuffix: groupsuffix.PinnipedDefaultSuffix}
@@ -155,6 +173,9 @@ func (c *Client) clientset() (conciergeclientset.Interface, error) {
 	if err != nil {
 		return nil, err
 	}
+	if c.proxy != nil {
+		cfg.Proxy = c.proxy
+	}
 	client, err := kubeclient.New(
 		kubeclient.WithConfig(cfg),
 		kubeclient.WithMiddleware(groupsuffix.New(c.apiGroupSuffix)),
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package conciergeclient
@@ -45,7 +45,7 @@ func TestNew(t *testing.T) {
 			opts: []Option{
 				WithAuthenticator("invalid-type", "test-authenticator"),
 			},
-			wantErr: `invalid authenticator type: "invalid-type", supported values are "webhook" and "jwt"`,
+			wantErr: `invalid authenticator type: "invalid-type", supported values are "webhook", "jwt", "x509", and "serviceaccounttoken"`,
 		},
 		{
 			name: "with empty authenticator name",
@@ -196,6 +196,27 @@ func TestExchangeToken(t *testing.T) {
 		require.Nil(t, got)
 	})
 
+	t.Run("with proxy", func(t *testing.T) {
+		t.Parallel()
+		// Start a test server that returns only 500 errors, to check that the request was made using the proxy function.
+		caBundle, endpoint := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		var proxiedURLs []string
+		proxy := func(r *http.Request) (*url.URL, error) {
+			proxiedURLs = append(proxiedURLs, r.URL.String())
+			return nil, nil // connect directly to the test server
+		}
+		client, err := New(WithEndpoint(endpoint), WithCABundle(caBundle), WithAuthenticator("jwt", "test-authenticator"), WithProxy(proxy))
+		require.NoError(t, err)
+
+		got, err := client.ExchangeToken(ctx, "test-token")
+		require.Error(t, err)
+		require.Nil(t, got)
+		require.Equal(t, []string{endpoint + "/apis/login.concierge.pinniped.dev/v1alpha1/tokencredentialrequests"}, proxiedURLs)
+	})
+
 	t.Run("login failure unknown error", func(t *testing.T) {
 		t.Parallel()
 		// Start a test server that returns without any error message but also without valid credentials
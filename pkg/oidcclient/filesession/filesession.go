@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package filesession implements a simple YAML file-based login.sessionCache.
@@ -113,6 +113,25 @@ func (c *Cache) PutToken(key oidcclient.SessionCacheKey, token *oidctypes.Token)
 	})
 }
 
+// DeleteSessions deletes the sessions for which the given function returns true from the session cache.
+func (c *Cache) DeleteSessions(shouldDelete func(oidcclient.SessionCacheKey, *oidctypes.Token) bool) {
+	// If the cache file does not exist, exit immediately with no error log
+	if _, err := os.Stat(c.path); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	c.withCache(func(cache *sessionCache) {
+		remaining := make([]sessionEntry, 0, len(cache.Sessions))
+		for _, entry := range cache.Sessions {
+			tokens := entry.Tokens
+			if !shouldDelete(entry.Key, &tokens) {
+				remaining = append(remaining, entry)
+			}
+		}
+		cache.Sessions = remaining
+	})
+}
+
 // withCache is an internal helper which locks, reads the cache, processes/mutates it with the provided function, then
 // saves it back to the file.
 func (c *Cache) withCache(transact func(*sessionCache)) {
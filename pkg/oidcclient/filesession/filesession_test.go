@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package filesession
@@ -496,6 +496,70 @@ func TestPutToken(t *testing.T) {
 	}
 }
 
+func TestDeleteSessions(t *testing.T) {
+	t.Parallel()
+	now := time.Now().Round(1 * time.Second)
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+		tmp := testutil.TempDir(t) + "/sessions.yaml"
+		errors := errorCollector{t: t}
+		c := New(tmp, errors.collect())
+		c.DeleteSessions(func(oidcclient.SessionCacheKey, *oidctypes.Token) bool {
+			require.Fail(t, "should not be called")
+			return true
+		})
+		errors.require(nil)
+		require.NoFileExists(t, tmp)
+	})
+
+	t.Run("deletes the matching sessions", func(t *testing.T) {
+		t.Parallel()
+		tmp := testutil.TempDir(t) + "/sessions.yaml"
+		entry := func(issuer, refreshToken string) sessionEntry {
+			return sessionEntry{
+				Key: oidcclient.SessionCacheKey{
+					Issuer:      issuer,
+					ClientID:    "test-client-id",
+					Scopes:      []string{"email", "offline_access", "openid", "profile"},
+					RedirectURI: "http://localhost:0/callback",
+				},
+				CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+				LastUsedTimestamp: metav1.NewTime(now.Add(-1 * time.Hour)),
+				Tokens:            oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: refreshToken}},
+			}
+		}
+		validCache := emptySessionCache()
+		validCache.insert(
+			entry("test-issuer", "refresh-token-1"),
+			entry("other-issuer", "refresh-token-2"),
+			entry("test-issuer", "refresh-token-3"),
+		)
+		require.NoError(t, validCache.writeTo(tmp))
+
+		errors := errorCollector{t: t}
+		c := New(tmp, errors.collect())
+		var deleted []*oidctypes.Token
+		c.DeleteSessions(func(key oidcclient.SessionCacheKey, token *oidctypes.Token) bool {
+			if key.Issuer != "test-issuer" {
+				return false
+			}
+			deleted = append(deleted, token)
+			return true
+		})
+		errors.require(nil)
+		require.Equal(t, []*oidctypes.Token{
+			{RefreshToken: &oidctypes.RefreshToken{Token: "refresh-token-1"}},
+			{RefreshToken: &oidctypes.RefreshToken{Token: "refresh-token-3"}},
+		}, deleted)
+
+		cache, err := readSessionCache(tmp)
+		require.NoError(t, err)
+		require.Len(t, cache.Sessions, 1)
+		require.Equal(t, "other-issuer", cache.Sessions[0].Key.Issuer)
+	})
+}
+
 type errorCollector struct {
 	t   *testing.T
 	saw []error
@@ -0,0 +1,134 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package keychainsession implements a login.sessionCache which is backed by the keychain of the operating system.
+package keychainsession
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.pinniped.dev/internal/keychain"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+// keychainService is the keychain service under which the sessions are stored. Each session is stored under an
+// account which is derived from its oidcclient.SessionCacheKey.
+const keychainService = "pinniped-session"
+
+// keychainEntry is the JSON-serialized secret of a session in the keychain.
+type keychainEntry struct {
+	Key    oidcclient.SessionCacheKey `json:"key"`
+	Tokens oidctypes.Token            `json:"tokens"`
+}
+
+// Option configures a cache in New().
+type Option func(*Cache)
+
+// WithErrorReporter is an Option that specifies a callback which will be invoked for each error reported during
+// session cache operations. By default, these errors are silently ignored.
+func WithErrorReporter(reporter func(error)) Option {
+	return func(c *Cache) {
+		c.errReporter = reporter
+	}
+}
+
+// New returns a login.SessionCache implementation which stores the sessions in the specified keychain. Whenever the
+// keychain cannot be used, e.g. because it is locked or because a session is too large for it, the fallback cache is
+// used instead.
+func New(kc keychain.Keychain, fallback oidcclient.SessionCache, options ...Option) *Cache {
+	c := Cache{
+		keychain:    keychain.NewIndexed(kc),
+		fallback:    fallback,
+		errReporter: func(_ error) {},
+	}
+	for _, opt := range options {
+		opt(&c)
+	}
+	return &c
+}
+
+type Cache struct {
+	keychain    *keychain.Indexed
+	fallback    oidcclient.SessionCache
+	errReporter func(error)
+}
+
+// GetToken looks up the cached data for the given parameters. It may return nil if no valid matching session is cached.
+func (c *Cache) GetToken(key oidcclient.SessionCacheKey) *oidctypes.Token {
+	secret, err := c.keychain.Get(keychainService, account(key))
+	if err != nil {
+		if !errors.Is(err, keychain.ErrNotFound) {
+			c.errReporter(fmt.Errorf("could not read session from keychain, using fallback cache: %w", err))
+		}
+		// The session may have been stored in the fallback cache when the keychain could not be used.
+		return c.fallback.GetToken(key)
+	}
+
+	var entry keychainEntry
+	if err := json.Unmarshal([]byte(secret), &entry); err != nil {
+		c.errReporter(fmt.Errorf("could not decode session from keychain: %w", err))
+		return nil
+	}
+	return &entry.Tokens
+}
+
+// PutToken stores the provided token into the session cache under the given parameters. It does not return an error
+// but may silently fail to update the session cache.
+func (c *Cache) PutToken(key oidcclient.SessionCacheKey, token *oidctypes.Token) {
+	secret, err := json.Marshal(keychainEntry{Key: key, Tokens: *token})
+	if err != nil {
+		c.errReporter(fmt.Errorf("could not encode session: %w", err))
+		return
+	}
+	if err := c.keychain.Set(keychainService, account(key), string(secret)); err != nil {
+		c.errReporter(fmt.Errorf("could not write session to keychain, using fallback cache: %w", err))
+		c.fallback.PutToken(key, token)
+	}
+}
+
+// DeleteSessions deletes the sessions for which the given function returns true from the keychain. It does not
+// delete sessions from the fallback cache.
+func (c *Cache) DeleteSessions(shouldDelete func(oidcclient.SessionCacheKey, *oidctypes.Token) bool) {
+	accounts, err := c.keychain.Accounts(keychainService)
+	if err != nil {
+		c.errReporter(fmt.Errorf("could not list sessions in keychain: %w", err))
+		return
+	}
+
+	for _, account := range accounts {
+		secret, err := c.keychain.Get(keychainService, account)
+		switch {
+		case errors.Is(err, keychain.ErrNotFound):
+			// The session is already gone, but its account is still in the index.
+		case err != nil:
+			c.errReporter(fmt.Errorf("could not read session from keychain: %w", err))
+			continue
+		default:
+			var entry keychainEntry
+			if err := json.Unmarshal([]byte(secret), &entry); err != nil {
+				// A session which cannot be decoded is of no use, so it is deleted as well.
+				c.errReporter(fmt.Errorf("could not decode session from keychain: %w", err))
+			} else if !shouldDelete(entry.Key, &entry.Tokens) {
+				continue
+			}
+		}
+
+		if err := c.keychain.Delete(keychainService, account); err != nil && !errors.Is(err, keychain.ErrNotFound) {
+			c.errReporter(fmt.Errorf("could not delete session from keychain: %w", err))
+		}
+	}
+}
+
+// account returns the keychain account of the session, which is a hash of the session cache key.
+func account(key oidcclient.SessionCacheKey) string {
+	hash := sha256.New()
+	if err := json.NewEncoder(hash).Encode(key); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
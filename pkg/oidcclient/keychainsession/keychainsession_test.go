@@ -0,0 +1,186 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package keychainsession
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/internal/testutil/fakekeychain"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+type fallbackCache struct {
+	tokens map[string]*oidctypes.Token
+}
+
+func (f *fallbackCache) GetToken(key oidcclient.SessionCacheKey) *oidctypes.Token {
+	return f.tokens[key.Issuer]
+}
+
+func (f *fallbackCache) PutToken(key oidcclient.SessionCacheKey, token *oidctypes.Token) {
+	f.tokens[key.Issuer] = token
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Round(1 * time.Second)
+	key := oidcclient.SessionCacheKey{
+		Issuer:      "https://issuer.example.com",
+		ClientID:    "test-client-id",
+		Scopes:      []string{"email", "offline_access", "openid", "profile"},
+		RedirectURI: "http://localhost:0/callback",
+	}
+	token := &oidctypes.Token{
+		AccessToken: &oidctypes.AccessToken{
+			Token:  "test-access-token",
+			Type:   "Bearer",
+			Expiry: metav1.NewTime(now.Add(1 * time.Hour)),
+		},
+		RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"},
+		IDToken: &oidctypes.IDToken{
+			Token:  "test-id-token",
+			Expiry: metav1.NewTime(now.Add(1 * time.Hour)),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		keychainErr     error
+		fallbackToken   *oidctypes.Token
+		keychainSecret  string
+		put             bool
+		want            *oidctypes.Token
+		wantErrors      []string
+		wantKeychainLen int
+		wantInFallback  bool
+	}{
+		{
+			name: "not found",
+		},
+		{
+			name:            "stores and finds the session in the keychain",
+			put:             true,
+			want:            token,
+			wantKeychainLen: 2, // the session and the index
+		},
+		{
+			name:          "finds a session in the fallback cache",
+			fallbackToken: token,
+			want:          token,
+		},
+		{
+			name:           "invalid session in the keychain",
+			keychainSecret: "invalid",
+			wantErrors: []string{
+				"could not decode session from keychain: invalid character 'i' looking for beginning of value",
+			},
+			wantKeychainLen: 1,
+		},
+		{
+			name:        "keychain fails",
+			keychainErr: fmt.Errorf("some keychain error"),
+			put:         true,
+			want:        token,
+			wantErrors: []string{
+				"could not write session to keychain, using fallback cache: some keychain error",
+				"could not read session from keychain, using fallback cache: some keychain error",
+			},
+			wantInFallback: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kc := fakekeychain.New()
+			if tt.keychainSecret != "" {
+				require.NoError(t, kc.Set(keychainService, account(key), tt.keychainSecret))
+			}
+			kc.Err = tt.keychainErr
+
+			fallback := &fallbackCache{tokens: map[string]*oidctypes.Token{}}
+			if tt.fallbackToken != nil {
+				fallback.PutToken(key, tt.fallbackToken)
+			}
+
+			var errors []string
+			c := New(kc, fallback, WithErrorReporter(func(err error) { errors = append(errors, err.Error()) }))
+			if tt.put {
+				c.PutToken(key, token)
+			}
+			got := c.GetToken(key)
+
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantErrors, errors)
+			kc.Err = nil
+			require.Equal(t, tt.wantKeychainLen, kc.Len())
+			if tt.wantInFallback {
+				require.Equal(t, token, fallback.GetToken(key))
+			}
+		})
+	}
+}
+
+func TestDeleteSessions(t *testing.T) {
+	t.Parallel()
+
+	kc := fakekeychain.New()
+	fallback := &fallbackCache{tokens: map[string]*oidctypes.Token{}}
+	var errors []string
+	c := New(kc, fallback, WithErrorReporter(func(err error) { errors = append(errors, err.Error()) }))
+
+	key := func(issuer, clientID string) oidcclient.SessionCacheKey {
+		return oidcclient.SessionCacheKey{Issuer: issuer, ClientID: clientID, Scopes: []string{"openid"}}
+	}
+	token := func(refreshToken string) *oidctypes.Token {
+		return &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: refreshToken}}
+	}
+	c.PutToken(key("https://issuer-1.example.com", "client-1"), token("refresh-token-1"))
+	c.PutToken(key("https://issuer-1.example.com", "client-2"), token("refresh-token-2"))
+	c.PutToken(key("https://issuer-2.example.com", "client-1"), token("refresh-token-3"))
+	fallback.PutToken(key("https://issuer-1.example.com", "client-3"), token("refresh-token-4"))
+	require.Equal(t, 4, kc.Len())
+
+	// A session which cannot be decoded is deleted too.
+	invalidKey := key("https://issuer-3.example.com", "client-1")
+	c.PutToken(invalidKey, token("refresh-token-5"))
+	require.NoError(t, kc.Set(keychainService, account(invalidKey), "invalid"))
+
+	var deleted []*oidctypes.Token
+	c.DeleteSessions(func(key oidcclient.SessionCacheKey, token *oidctypes.Token) bool {
+		if key.Issuer != "https://issuer-1.example.com" {
+			return false
+		}
+		deleted = append(deleted, token)
+		return true
+	})
+	require.ElementsMatch(t, []*oidctypes.Token{token("refresh-token-1"), token("refresh-token-2")}, deleted)
+	require.Equal(t, []string{"could not decode session from keychain: invalid character 'i' looking for beginning of value"}, errors)
+
+	// Only the remaining session and the index are left in the keychain, and the fallback cache is untouched.
+	require.Equal(t, 2, kc.Len())
+	require.Equal(t, token("refresh-token-3"), c.GetToken(key("https://issuer-2.example.com", "client-1")))
+	require.Equal(t, token("refresh-token-4"), fallback.GetToken(key("https://issuer-1.example.com", "client-3")))
+
+	kc.Err = fmt.Errorf("some keychain error")
+	c.DeleteSessions(func(oidcclient.SessionCacheKey, *oidctypes.Token) bool { return true })
+	require.Equal(t, "could not list sessions in keychain: some keychain error", errors[len(errors)-1])
+}
+
+func TestAccount(t *testing.T) {
+	t.Parallel()
+
+	key := oidcclient.SessionCacheKey{Issuer: "https://issuer.example.com", ClientID: "test-client-id"}
+	require.Len(t, account(key), 64)
+	require.Equal(t, account(key), account(key))
+	require.NotEqual(t, account(key), account(oidcclient.SessionCacheKey{Issuer: "https://issuer.example.com", ClientID: "other-client-id"}))
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package oidcclient implements a CLI OIDC login flow.
@@ -16,6 +16,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"sort"
 	"strings"
 	"sync"
@@ -35,6 +36,7 @@ import (
 	"go.pinniped.dev/internal/net/phttp"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/qrcode"
 	"go.pinniped.dev/internal/upstreamoidc"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
@@ -65,8 +67,20 @@ const (
 	defaultPasswordEnvVarName = "PINNIPED_PASSWORD" //nolint:gosec // this is not a credential
 
 	httpLocationHeaderName = "Location"
+
+	// defaultDevicePollInterval is how long to wait between polls of the token endpoint during the device
+	// authorization grant when the issuer does not specify an interval, as per
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2.
+	defaultDevicePollInterval = 5 * time.Second
+
+	// devicePollSlowDownIncrement is how much to increase the polling interval when the issuer asks the client to slow
+	// down, as per https://datatracker.ietf.org/doc/html/rfc8628#section-3.5.
+	devicePollSlowDownIncrement = 5 * time.Second
 )
 
+// errStdinNotTerminal is returned when the user would need to be prompted, but stdin is not connected to a terminal.
+var errStdinNotTerminal = errors.New("stdin is not connected to a terminal")
+
 // stdin returns the file descriptor for stdin as an int.
 func stdin() int { return int(os.Stdin.Fd()) }
 
@@ -83,6 +97,16 @@ type handlerState struct {
 	upstreamIdentityProviderType string
 	cliToSendCredentials         bool
 
+	// Non-interactive sources of the username and password for CLI-based auth.
+	usernameFile       string
+	passwordFile       string
+	credentialsCommand []string
+
+	useDeviceGrant bool
+	showQRCode     bool
+
+	remoteBrowser bool
+
 	requestedAudience string
 
 	httpClient *http.Client
@@ -95,6 +119,7 @@ type handlerState struct {
 	provider     *coreosoidc.Provider
 	oauth2Config *oauth2.Config
 	useFormPost  bool
+	deviceURL    string
 	state        state.State
 	nonce        nonce.Nonce
 	pkce         pkce.Code
@@ -111,6 +136,10 @@ type handlerState struct {
 	validateIDToken func(ctx context.Context, provider *coreosoidc.Provider, audience string, token string) (*coreosoidc.IDToken, error)
 	promptForValue  func(ctx context.Context, promptLabel string) (string, error)
 	promptForSecret func(promptLabel string) (string, error)
+	readFile        func(name string) ([]byte, error)
+	runCommand      func(ctx context.Context, name string, args ...string) ([]byte, error)
+	startCommand    func(name string, args ...string) error
+	waitToPoll      func(ctx context.Context, interval time.Duration) error
 
 	callbacks chan callbackResult
 }
@@ -186,6 +215,32 @@ func WithSkipBrowserOpen() Option {
 	}
 }
 
+// WithBrowserCommand causes the login flow to open the authorize URL by starting the given command with the URL appended
+// as its last argument, instead of opening the user's default web browser. This can be used to choose a specific browser
+// or browser profile. The login flow does not wait for the command to exit.
+func WithBrowserCommand(command string, args ...string) Option {
+	return func(h *handlerState) error {
+		if command == "" {
+			return fmt.Errorf("browser command must not be empty")
+		}
+		h.openURL = func(authorizeURL string) error {
+			return h.startCommand(command, append(append([]string{}, args...), authorizeURL)...)
+		}
+		return nil
+	}
+}
+
+// WithRemoteBrowser causes the login flow to expect that the user logs in using a web browser on another machine, for
+// example when the CLI runs on a remote development VM. The authorize URL is only printed, and no localhost listener is
+// started, since the browser could not reach it. Instead, the user is always prompted to paste the authorization code,
+// or the URL of the localhost callback page which the browser failed to load. This requires stdin to be a TTY.
+func WithRemoteBrowser() Option {
+	return func(h *handlerState) error {
+		h.remoteBrowser = true
+		return nil
+	}
+}
+
 // WithSkipListen causes the login skip starting the localhost listener, forcing the manual copy/paste login flow.
 func WithSkipListen() Option {
 	return func(h *handlerState) error {
@@ -245,6 +300,33 @@ func WithCLISendingCredentials() Option {
 	}
 }
 
+// WithCLICredentialFiles causes the login flow to read the username and the password for CLI-based auth (see
+// WithCLISendingCredentials) from the given files, instead of prompting for them. Either path may be empty. A trailing
+// newline is removed from the contents of the files. The PINNIPED_USERNAME and PINNIPED_PASSWORD environment variables
+// still take precedence over the files.
+func WithCLICredentialFiles(usernameFile, passwordFile string) Option {
+	return func(h *handlerState) error {
+		h.usernameFile = usernameFile
+		h.passwordFile = passwordFile
+		return nil
+	}
+}
+
+// WithCLICredentialsCommand causes the login flow to run the given command to get the username and the password for
+// CLI-based auth (see WithCLISendingCredentials), instead of prompting for them. The command must print a JSON object
+// with "username" and "password" string fields to stdout, for example to read them from a secret store. Either field
+// may be omitted, in which case it is read from the other sources, or prompted for. The command is only run when a
+// login is needed, and it is used after the environment variables and the files (see WithCLICredentialFiles).
+func WithCLICredentialsCommand(command string, args ...string) Option {
+	return func(h *handlerState) error {
+		if command == "" {
+			return fmt.Errorf("credentials command must not be empty")
+		}
+		h.credentialsCommand = append([]string{command}, args...)
+		return nil
+	}
+}
+
 // WithUpstreamIdentityProvider causes the specified name and type to be sent as custom query parameters to the
 // issuer's authorize endpoint. This is only intended to be used when the issuer is a Pinniped Supervisor, in which
 // case it provides a mechanism to choose among several upstream identity providers.
@@ -257,6 +339,27 @@ func WithUpstreamIdentityProvider(upstreamName, upstreamType string) Option {
 	}
 }
 
+// WithDeviceAuthorizationGrant causes the login flow to use the OAuth 2.0 device authorization grant, as defined by
+// https://datatracker.ietf.org/doc/html/rfc8628, instead of the authorization code flow. The user is asked to visit a
+// verification URL in a web browser, possibly on another device, and to enter a user code there, while the CLI polls the
+// issuer's token endpoint until the login is complete. This is useful when there is no web browser on the machine which
+// runs the CLI. The issuer must advertise a device_authorization_endpoint in its OIDC discovery document.
+func WithDeviceAuthorizationGrant() Option {
+	return func(h *handlerState) error {
+		h.useDeviceGrant = true
+		return nil
+	}
+}
+
+// WithDeviceQRCode causes the login flow to also print the verification URL as a QR code when using the device
+// authorization grant, so that the user can easily open it on a phone. It has no effect on other login flows.
+func WithDeviceQRCode() Option {
+	return func(h *handlerState) error {
+		h.showQRCode = true
+		return nil
+	}
+}
+
 // nopCache is a SessionCache that doesn't actually do anything.
 type nopCache struct{}
 
@@ -291,6 +394,10 @@ func Login(issuer string, clientID string, opts ...Option) (*oidctypes.Token, er
 		},
 		promptForValue:  promptForValue,
 		promptForSecret: promptForSecret,
+		readFile:        os.ReadFile,
+		runCommand:      runCommand,
+		startCommand:    startCommand,
+		waitToPoll:      waitToPoll,
 	}
 	for _, opt := range opts {
 		if err := opt(&h); err != nil {
@@ -396,7 +503,10 @@ func (h *handlerState) baseLogin() (*oidctypes.Token, error) {
 
 	// Choose the appropriate authorization and authcode exchange strategy.
 	var authFunc = h.webBrowserBasedAuth
-	if h.cliToSendCredentials {
+	switch {
+	case h.useDeviceGrant:
+		authFunc = h.deviceBasedAuth
+	case h.cliToSendCredentials:
 		authFunc = h.cliBasedAuth
 	}
 
@@ -456,6 +566,14 @@ func (h *handlerState) cliBasedAuth(authorizeOptions *[]oauth2.AuthCodeOption) (
 	}
 	_ = authRes.Body.Close() // don't need the response body, and okay if it fails to close
 
+	// The Supervisor may limit the rate of login attempts, in which case it says how long to wait.
+	if authRes.StatusCode == http.StatusTooManyRequests {
+		if retryAfter := authRes.Header.Get("Retry-After"); retryAfter != "" {
+			return nil, fmt.Errorf("error getting authorization: too many recent login attempts, please try again in %s seconds", retryAfter)
+		}
+		return nil, errors.New("error getting authorization: too many recent login attempts, please try again later")
+	}
+
 	// A successful authorization always results in a redirect (we are flexible on the exact status code).
 	if !sawRedirect {
 		return nil, fmt.Errorf(
@@ -509,36 +627,114 @@ func (h *handlerState) cliBasedAuth(authorizeOptions *[]oauth2.AuthCodeOption) (
 	return token, nil
 }
 
-// Prompt for the user's username and password, or read them from env vars if they are available.
+// Read the user's username and password from the env vars, the files, or the credentials command if they are
+// available, or else prompt for them.
 func (h *handlerState) getUsernameAndPassword() (string, string, error) {
-	var err error
+	var fromCommand *commandCredentials // the command is run at most once, and only when it is needed
+
+	username, err := h.getCredential("username", defaultUsernameEnvVarName, h.usernameFile, &fromCommand,
+		func(c *commandCredentials) string { return c.Username },
+		func() (string, error) { return h.promptForValue(h.ctx, defaultLDAPUsernamePrompt) },
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	password, err := h.getCredential("password", defaultPasswordEnvVarName, h.passwordFile, &fromCommand,
+		func(c *commandCredentials) string { return c.Password },
+		func() (string, error) { return h.promptForSecret(defaultLDAPPasswordPrompt) },
+	)
+	if err != nil {
+		return "", "", err
+	}
 
-	username := h.getEnv(defaultUsernameEnvVarName)
-	if username == "" {
-		username, err = h.promptForValue(h.ctx, defaultLDAPUsernamePrompt)
+	return username, password, nil
+}
+
+// commandCredentials is the output of the credentials command.
+type commandCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// getCredential gets the username or the password from the first of its sources which provides it.
+func (h *handlerState) getCredential(
+	name string,
+	envVarName string,
+	file string,
+	fromCommand **commandCredentials,
+	fromCommandField func(*commandCredentials) string,
+	prompt func() (string, error),
+) (string, error) {
+	if value := h.getEnv(envVarName); value != "" {
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Read "+name+" from environment variable", "name", envVarName)
+		return value, nil
+	}
+
+	if file != "" {
+		contents, err := h.readFile(file)
 		if err != nil {
-			return "", "", fmt.Errorf("error prompting for username: %w", err)
+			return "", fmt.Errorf("could not read %s file: %w", name, err)
 		}
-	} else {
-		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Read username from environment variable", "name", defaultUsernameEnvVarName)
+		value := strings.TrimRight(string(contents), "\r\n")
+		if value == "" {
+			return "", fmt.Errorf("%s file %q is empty", name, file)
+		}
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Read "+name+" from file", "path", file)
+		return value, nil
 	}
 
-	password := h.getEnv(defaultPasswordEnvVarName)
-	if password == "" {
-		password, err = h.promptForSecret(defaultLDAPPasswordPrompt)
-		if err != nil {
-			return "", "", fmt.Errorf("error prompting for password: %w", err)
+	if len(h.credentialsCommand) > 0 {
+		if *fromCommand == nil {
+			credentials, err := h.runCredentialsCommand()
+			if err != nil {
+				return "", err
+			}
+			*fromCommand = credentials
+		}
+		if value := fromCommandField(*fromCommand); value != "" {
+			h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Read "+name+" from credentials command", "command", h.credentialsCommand[0])
+			return value, nil
 		}
-	} else {
-		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Read password from environment variable", "name", defaultPasswordEnvVarName)
 	}
 
-	return username, password, nil
+	value, err := prompt()
+	if err != nil {
+		if errors.Is(err, errStdinNotTerminal) {
+			return "", fmt.Errorf("error prompting for %s: %w (to log in non-interactively, provide the %s with the %s environment variable, a file, or a credentials command)",
+				name, err, name, envVarName)
+		}
+		return "", fmt.Errorf("error prompting for %s: %w", name, err)
+	}
+	return value, nil
+}
+
+func (h *handlerState) runCredentialsCommand() (*commandCredentials, error) {
+	h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Running credentials command", "command", h.credentialsCommand[0])
+	output, err := h.runCommand(h.ctx, h.credentialsCommand[0], h.credentialsCommand[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("could not run credentials command %q: %w", h.credentialsCommand[0], err)
+	}
+	var credentials commandCredentials
+	if err := json.Unmarshal(output, &credentials); err != nil {
+		return nil, fmt.Errorf("could not decode the output of credentials command %q: %w", h.credentialsCommand[0], err)
+	}
+	return &credentials, nil
 }
 
 // Open a web browser, or ask the user to open a web browser, to visit the authorize endpoint.
 // Create a localhost callback listener which exchanges the authcode for tokens. Return the tokens or an error.
 func (h *handlerState) webBrowserBasedAuth(authorizeOptions *[]oauth2.AuthCodeOption) (*oidctypes.Token, error) {
+	// When the browser runs on another machine, it could not reach a localhost listener, so the auth code
+	// must always be pasted manually.
+	if h.remoteBrowser {
+		if !h.isTTY(stdin()) {
+			return nil, fmt.Errorf("login failed: stdin must be a TTY to log in using a remote browser")
+		}
+		h.listen = func(string, string) (net.Listener, error) { return nil, nil }
+		h.openURL = func(_ string) error { return nil }
+	}
+
 	// Attempt to open a local TCP listener, logging but otherwise ignoring any error.
 	listener, err := h.listen("tcp", h.listenAddr)
 	if err != nil {
@@ -611,8 +807,16 @@ func (h *handlerState) promptForWebLogin(ctx context.Context, authorizeURL strin
 	}
 
 	// If the server didn't support response_mode=form_post, don't bother prompting for the manual
-	// code because the user isn't going to have any easy way to manually copy it anyway.
-	if !h.useFormPost {
+	// code because the user isn't going to have any easy way to manually copy it anyway. When using a
+	// remote browser, the user can still copy the URL of the callback page which failed to load.
+	promptLabel := "    Optionally, paste your authorization code: "
+	switch {
+	case h.remoteBrowser && h.useFormPost:
+		promptLabel = "    Paste your authorization code: "
+	case h.remoteBrowser:
+		_, _ = fmt.Fprint(out, "    After logging in, your browser will fail to load a page on 127.0.0.1.\n")
+		promptLabel = "    Paste the URL of that page: "
+	case !h.useFormPost:
 		return func() {}
 	}
 
@@ -627,7 +831,7 @@ func (h *handlerState) promptForWebLogin(ctx context.Context, authorizeURL strin
 
 			wg.Done()
 		}()
-		code, err := h.promptForValue(ctx, "    Optionally, paste your authorization code: ")
+		pasted, err := h.promptForValue(ctx, promptLabel)
 		if err != nil {
 			// Print a visual marker to show the the prompt is no longer waiting for user input, plus a trailing
 			// newline that simulates the user having pressed "enter".
@@ -637,6 +841,12 @@ func (h *handlerState) promptForWebLogin(ctx context.Context, authorizeURL strin
 			return
 		}
 
+		code, err := h.authCodeFromPastedValue(pasted)
+		if err != nil {
+			h.callbacks <- callbackResult{err: err}
+			return
+		}
+
 		// When a code is pasted, redeem it for a token and return that result on the callbacks channel.
 		token, err := h.redeemAuthCode(ctx, code)
 		h.callbacks <- callbackResult{token: token, err: err}
@@ -644,9 +854,249 @@ func (h *handlerState) promptForWebLogin(ctx context.Context, authorizeURL strin
 	return wg.Wait
 }
 
+// authCodeFromPastedValue returns the auth code which was pasted by the user. Instead of the code itself, the user
+// may paste the whole URL of the callback page, in which case its parameters are validated like in the callback handler.
+func (h *handlerState) authCodeFromPastedValue(pasted string) (string, error) {
+	callbackURL, err := url.Parse(pasted)
+	if err != nil || (callbackURL.Scheme != "http" && callbackURL.Scheme != "https") {
+		return pasted, nil
+	}
+	params := callbackURL.Query()
+
+	// Validate OAuth2 state and fail if it's incorrect (to block CSRF).
+	if err := h.state.Validate(params.Get("state")); err != nil {
+		return "", fmt.Errorf("missing or invalid state parameter in pasted URL")
+	}
+
+	// Check for error response parameters. See https://openid.net/specs/openid-connect-core-1_0.html#AuthError.
+	if errorParam := params.Get("error"); errorParam != "" {
+		if errorDescParam := params.Get("error_description"); errorDescParam != "" {
+			return "", fmt.Errorf("login failed with code %q: %s", errorParam, errorDescParam)
+		}
+		return "", fmt.Errorf("login failed with code %q", errorParam)
+	}
+
+	code := params.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("missing code parameter in pasted URL")
+	}
+	return code, nil
+}
+
+// deviceAuthorizationResponse is the response of the device authorization endpoint, as defined by
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2.
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// Request a device code and a user code from the device authorization endpoint, ask the user to visit the verification
+// URL in a web browser (possibly on another device) and to enter the user code, and poll the token endpoint until the
+// user has finished logging in. Return the tokens or an error.
+func (h *handlerState) deviceBasedAuth(_ *[]oauth2.AuthCodeOption) (*oidctypes.Token, error) {
+	if h.deviceURL == "" {
+		return nil, fmt.Errorf("issuer %q does not support the device authorization grant: its OIDC discovery does not include a device_authorization_endpoint", h.issuer)
+	}
+	if err := validateURLUsesHTTPS(h.deviceURL, "discovered device authorization URL from issuer"); err != nil {
+		return nil, err
+	}
+
+	authorization, err := h.requestDeviceAuthorization()
+	if err != nil {
+		return nil, fmt.Errorf("device authorization request failed: %w", err)
+	}
+
+	h.promptForDeviceLogin(authorization, os.Stderr)
+
+	// Stop polling when the device code expires, or when the overall login times out.
+	ctx := h.ctx
+	if authorization.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(h.ctx, time.Duration(authorization.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+
+	for {
+		if err := h.waitToPoll(ctx, interval); err != nil {
+			return nil, fmt.Errorf("timed out waiting for device authorization: %w", err)
+		}
+
+		token, errorCode, err := h.pollDeviceToken(ctx, authorization.DeviceCode)
+		if err != nil {
+			return nil, err
+		}
+		switch errorCode {
+		case "":
+			return token, nil
+		case "slow_down":
+			interval += devicePollSlowDownIncrement
+		}
+	}
+}
+
+// requestDeviceAuthorization makes the device authorization request, as defined by
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.1.
+func (h *handlerState) requestDeviceAuthorization() (*deviceAuthorizationResponse, error) {
+	reqBody := strings.NewReader(url.Values{
+		"client_id": []string{h.clientID},
+		"scope":     []string{strings.Join(h.scopes, " ")},
+	}.Encode())
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodPost, h.deviceURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request: %w", err)
+	}
+	req.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		if errorResponse := decodeOAuth2Error(resp); errorResponse != nil {
+			return nil, errorResponse
+		}
+		return nil, fmt.Errorf("unexpected HTTP response status %d", resp.StatusCode)
+	}
+
+	var authorization deviceAuthorizationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&authorization); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if authorization.DeviceCode == "" || authorization.UserCode == "" || authorization.VerificationURI == "" {
+		return nil, fmt.Errorf("response is missing device_code, user_code, or verification_uri")
+	}
+	return &authorization, nil
+}
+
+// pollDeviceToken makes one device access token request, as defined by
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.4. It returns the validated tokens when the user has finished
+// logging in, or the OAuth2 error code when the client should keep polling.
+func (h *handlerState) pollDeviceToken(ctx context.Context, deviceCode string) (*oidctypes.Token, string, error) {
+	reqBody := strings.NewReader(url.Values{
+		"client_id":   []string{h.clientID},
+		"grant_type":  []string{oidcapi.GrantTypeDeviceCode},
+		"device_code": []string{deviceCode},
+	}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.oauth2Config.Endpoint.TokenURL, reqBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not build device access token request: %w", err)
+	}
+	req.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("device access token request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		errorResponse := decodeOAuth2Error(resp)
+		if errorResponse == nil {
+			return nil, "", fmt.Errorf("device access token request failed: unexpected HTTP response status %d", resp.StatusCode)
+		}
+		switch errorResponse.Code {
+		case "authorization_pending", "slow_down":
+			return nil, errorResponse.Code, nil
+		default: // e.g. access_denied or expired_token
+			return nil, "", fmt.Errorf("device authorization failed: %w", errorResponse)
+		}
+	}
+
+	// Decode the response into an oauth2.Token, keeping the other response parameters (such as the id_token) as extras.
+	var raw map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, "", fmt.Errorf("failed to decode device access token response: %w", err)
+	}
+	tok := &oauth2.Token{}
+	tok.AccessToken, _ = raw["access_token"].(string)
+	tok.TokenType, _ = raw["token_type"].(string)
+	tok.RefreshToken, _ = raw["refresh_token"].(string)
+	if expiresIn, ok := raw["expires_in"].(float64); ok && expiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	if tok.AccessToken == "" {
+		return nil, "", fmt.Errorf("device access token response is missing the access_token")
+	}
+
+	// The CLI does not send a nonce in the device authorization grant, so there is no nonce to validate.
+	token, err := h.getProvider(h.oauth2Config, h.provider, h.httpClient).
+		ValidateTokenAndMergeWithUserInfo(ctx, tok.WithExtra(raw), "", true, false)
+	if err != nil {
+		return nil, "", fmt.Errorf("error during device access token validation: %w", err)
+	}
+	return token, "", nil
+}
+
+// oauth2Error is an OAuth2 error response, as defined by https://datatracker.ietf.org/doc/html/rfc6749#section-5.2.
+type oauth2Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauth2Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// decodeOAuth2Error returns the OAuth2 error in the response, or nil when the response does not contain one.
+func decodeOAuth2Error(resp *http.Response) *oauth2Error {
+	var errorResponse oauth2Error
+	if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil || errorResponse.Code == "" {
+		return nil
+	}
+	return &errorResponse
+}
+
+func (h *handlerState) promptForDeviceLogin(authorization *deviceAuthorizationResponse, out io.Writer) {
+	_, _ = fmt.Fprintf(out, "Log in by visiting this link:\n\n    %s\n\nand entering the code:\n\n    %s\n\n",
+		authorization.VerificationURI, authorization.UserCode)
+
+	if authorization.VerificationURIComplete == "" {
+		return
+	}
+	_, _ = fmt.Fprintf(out, "Or visit this link, which already includes the code:\n\n    %s\n\n", authorization.VerificationURIComplete)
+
+	if !h.showQRCode {
+		return
+	}
+	code, err := qrcode.Encode(authorization.VerificationURIComplete)
+	if err != nil {
+		h.logger.V(plog.KlogLevelDebug).Error(err, "could not encode QR code")
+		return
+	}
+	_, _ = fmt.Fprint(out, "Or scan this QR code:\n\n")
+	_ = code.WriteTerminal(out)
+	_, _ = fmt.Fprintln(out)
+}
+
+// waitToPoll waits for the polling interval to pass, or returns an error when the context is done first.
+func waitToPoll(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func promptForValue(ctx context.Context, promptLabel string) (string, error) {
 	if !term.IsTerminal(stdin()) {
-		return "", errors.New("stdin is not connected to a terminal")
+		return "", errStdinNotTerminal
 	}
 	_, err := fmt.Fprint(os.Stderr, promptLabel)
 	if err != nil {
@@ -678,7 +1128,7 @@ func promptForValue(ctx context.Context, promptLabel string) (string, error) {
 
 func promptForSecret(promptLabel string) (string, error) {
 	if !term.IsTerminal(stdin()) {
-		return "", errors.New("stdin is not connected to a terminal")
+		return "", errStdinNotTerminal
 	}
 	_, err := fmt.Fprint(os.Stderr, promptLabel)
 	if err != nil {
@@ -698,6 +1148,26 @@ func promptForSecret(promptLabel string) (string, error) {
 	return string(password), err
 }
 
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Let the command report its errors to the user. Its stdout is the output which we read.
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+func startCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	// Never write to stdout, which is read by kubectl.
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Do not wait for the command to exit, e.g. when it starts a new browser process, but reap it when it does.
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
 func (h *handlerState) initOIDCDiscovery() error {
 	// Make this method idempotent so it can be called in multiple cases with no extra network requests.
 	if h.provider != nil {
@@ -736,13 +1206,16 @@ func (h *handlerState) initOIDCDiscovery() error {
 	}
 
 	// Use response_mode=form_post if the provider supports it.
+	// Also remember the device authorization endpoint, if the provider has one.
 	var discoveryClaims struct {
-		ResponseModesSupported []string `json:"response_modes_supported"`
+		ResponseModesSupported      []string `json:"response_modes_supported"`
+		DeviceAuthorizationEndpoint string   `json:"device_authorization_endpoint"`
 	}
 	if err := h.provider.Claims(&discoveryClaims); err != nil {
 		return fmt.Errorf("could not decode response_modes_supported in OIDC discovery from %q: %w", h.issuer, err)
 	}
 	h.useFormPost = slices.Contains(discoveryClaims.ResponseModesSupported, "form_post")
+	h.deviceURL = discoveryClaims.DeviceAuthorizationEndpoint
 	return nil
 }
 
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclient
@@ -15,12 +15,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -283,6 +285,33 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 				response.AccessToken = testExchangedToken.IDToken.Token
 			}
 
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			if r.Form.Get("client_id") == "" {
+				http.Error(w, "expected a client_id", http.StatusBadRequest)
+				return
+			}
+
+			switch r.Form.Get("device_code") {
+			case "test-device-code":
+				response.AccessToken = testToken.AccessToken.Token
+				response.ExpiresIn = int64(time.Until(testToken.AccessToken.Expiry.Time).Seconds())
+				response.RefreshToken = testToken.RefreshToken.Token
+				response.IDToken = testToken.IDToken.Token
+			case "test-device-code-denied":
+				w.Header().Set("content-type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"access_denied","error_description":"The end-user denied the request."}`))
+				return
+			case "test-device-code-slow-down":
+				w.Header().Set("content-type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"slow_down"}`))
+				return
+			default:
+				http.Error(w, "unexpected device_code", http.StatusBadRequest)
+				return
+			}
+
 		default:
 			http.Error(w, fmt.Sprintf("invalid grant_type %q", r.Form.Get("grant_type")), http.StatusBadRequest)
 			return
@@ -304,6 +333,58 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 	formPostProviderMux.HandleFunc("/.well-known/openid-configuration", discoveryHandler(formPostSuccessServer, []string{"query", "form_post"}))
 	formPostProviderMux.HandleFunc("/token", tokenHandler)
 
+	// Start a test server that returns a discovery document with a device authorization endpoint, and answers device
+	// authorization and device access token requests.
+	deviceProviderMux := http.NewServeMux()
+	deviceSuccessServer := tlsserver.TLSTestServer(t, deviceProviderMux, nil)
+	deviceProviderMux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(&struct {
+			Issuer         string `json:"issuer"`
+			AuthURL        string `json:"authorization_endpoint"`
+			TokenURL       string `json:"token_endpoint"`
+			JWKSURL        string `json:"jwks_uri"`
+			DeviceAuthzURL string `json:"device_authorization_endpoint"`
+		}{
+			Issuer:         deviceSuccessServer.URL,
+			AuthURL:        deviceSuccessServer.URL + "/authorize",
+			TokenURL:       deviceSuccessServer.URL + "/token",
+			JWKSURL:        deviceSuccessServer.URL + "/keys",
+			DeviceAuthzURL: deviceSuccessServer.URL + "/device_authorization",
+		})
+	})
+	deviceProviderMux.HandleFunc("/device_authorization", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("scope") != "test-scope" {
+			http.Error(w, "expected scope 'test-scope'", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("content-type", "application/json")
+		deviceCode := "test-device-code"
+		switch r.Form.Get("client_id") {
+		case "test-client-id":
+		case "test-client-id-denied":
+			deviceCode = "test-device-code-denied"
+		case "test-client-id-slow-down":
+			deviceCode = "test-device-code-slow-down"
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"Client authentication failed."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"device_code":"` + deviceCode + `","user_code":"ABCD-EFGH",` +
+			`"verification_uri":"https://test-verification-uri","expires_in":300,"interval":5}`))
+	})
+	deviceProviderMux.HandleFunc("/token", tokenHandler)
+
 	defaultDiscoveryResponse := func(req *http.Request) (*http.Response, error) {
 		// Call the handler function from the test server to calculate the response.
 		handler, _ := providerMux.Handler(req)
@@ -748,6 +829,49 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			},
 			wantErr: "error handling callback: failed to prompt for manual authorization code: some prompt error",
 		},
+		{
+			name: "remote browser and non-tty stdin",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithRemoteBrowser()(h))
+					h.isTTY = func(fd int) bool {
+						assert.Equal(t, fd, syscall.Stdin)
+						return false
+					}
+					return nil
+				}
+			},
+			issuer:   successServer.URL,
+			wantLogs: []string{`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + successServer.URL + `"`},
+			wantErr:  "login failed: stdin must be a TTY to log in using a remote browser",
+		},
+		{
+			name: "remote browser and manual prompt fails",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithRemoteBrowser()(h))
+					h.isTTY = func(fd int) bool { return true }
+					h.listen = func(string, string) (net.Listener, error) {
+						require.FailNow(t, "should not start a listener when using a remote browser")
+						return nil, nil
+					}
+					h.openURL = func(string) error {
+						require.FailNow(t, "should not open a browser when using a remote browser")
+						return nil
+					}
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						assert.Equal(t, "    Paste the URL of that page: ", promptLabel)
+						return "", fmt.Errorf("some prompt error")
+					}
+					return nil
+				}
+			},
+			issuer:   successServer.URL,
+			wantLogs: []string{`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + successServer.URL + `"`},
+			wantErr:  "error handling callback: failed to prompt for manual authorization code: some prompt error",
+		},
 		{
 			name: "timeout waiting for callback",
 			opt: func(t *testing.T) Option {
@@ -1081,6 +1205,22 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
 			wantErr:  `error getting authorization: expected to be redirected, but response status was 502 Bad Gateway`,
 		},
+		{
+			name:     "ldap login when the OIDC provider authorization endpoint limits the rate of login attempts",
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					return defaultLDAPTestOpts(t, h, &http.Response{
+						StatusCode: http.StatusTooManyRequests,
+						Status:     "429 Too Many Requests",
+						Header:     http.Header{"Retry-After": []string{"30"}},
+					}, nil)
+				}
+			},
+			issuer:   successServer.URL,
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
+			wantErr:  `error getting authorization: too many recent login attempts, please try again in 30 seconds`,
+		},
 		{
 			name:     "ldap login when the OIDC provider authorization endpoint redirect has an error and error description",
 			clientID: "test-client-id",
@@ -1886,6 +2026,115 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			},
 			wantToken: &testExchangedToken,
 		},
+		{
+			name:     "device authorization grant is not supported by the issuer",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					return nil
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
+			wantErr: fmt.Sprintf("issuer %q does not support the device authorization grant: "+
+				"its OIDC discovery does not include a device_authorization_endpoint", successServer.URL),
+		},
+		{
+			name:     "device authorization request fails",
+			issuer:   deviceSuccessServer.URL,
+			clientID: "test-client-id-unauthorized",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(deviceSuccessServer))(h))
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					return nil
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceSuccessServer.URL + "\""},
+			wantErr:  "device authorization request failed: invalid_client: Client authentication failed.",
+		},
+		{
+			name:     "device authorization is denied by the user",
+			issuer:   deviceSuccessServer.URL,
+			clientID: "test-client-id-denied",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(deviceSuccessServer))(h))
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					h.waitToPoll = func(_ context.Context, _ time.Duration) error { return nil }
+					return nil
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceSuccessServer.URL + "\""},
+			wantErr:  "device authorization failed: access_denied: The end-user denied the request.",
+		},
+		{
+			name:     "device authorization slows down polling until it times out",
+			issuer:   deviceSuccessServer.URL,
+			clientID: "test-client-id-slow-down",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(deviceSuccessServer))(h))
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					var intervals []time.Duration
+					h.waitToPoll = func(_ context.Context, interval time.Duration) error {
+						intervals = append(intervals, interval)
+						if len(intervals) == 3 {
+							return context.DeadlineExceeded
+						}
+						return nil
+					}
+					t.Cleanup(func() {
+						require.Equal(t, []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second}, intervals)
+					})
+					return nil
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceSuccessServer.URL + "\""},
+			wantErr:  "timed out waiting for device authorization: context deadline exceeded",
+		},
+		{
+			name:     "device authorization grant succeeds",
+			issuer:   deviceSuccessServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(deviceSuccessServer))(h))
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					h.waitToPoll = func(_ context.Context, interval time.Duration) error {
+						require.Equal(t, 5*time.Second, interval)
+						return nil
+					}
+
+					h.getProvider = func(_ *oauth2.Config, _ *oidc.Provider, _ *http.Client) provider.UpstreamOIDCIdentityProviderI {
+						mock := mockUpstream(t)
+						mock.EXPECT().
+							ValidateTokenAndMergeWithUserInfo(gomock.Any(), HasAccessToken(testToken.AccessToken.Token), nonce.Nonce(""), true, false).
+							Return(&testToken, nil)
+						return mock
+					}
+
+					cache := &mockSessionCache{t: t, getReturnsToken: nil}
+					t.Cleanup(func() {
+						cacheKey := SessionCacheKey{
+							Issuer:      deviceSuccessServer.URL,
+							ClientID:    "test-client-id",
+							Scopes:      []string{"test-scope"},
+							RedirectURI: "http://localhost:0/callback",
+						}
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawGetKeys)
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawPutKeys)
+						require.Equal(t, []*oidctypes.Token{&testToken}, cache.sawPutTokens)
+					})
+					h.cache = cache
+					return nil
+				}
+			},
+			wantLogs:  []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceSuccessServer.URL + "\""},
+			wantToken: &testToken,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -1941,6 +2190,7 @@ func TestHandlePasteCallback(t *testing.T) {
 	tests := []struct {
 		name         string
 		opt          func(t *testing.T) Option
+		wantOutput   string
 		wantCallback *callbackResult
 	}{
 		{
@@ -2031,6 +2281,107 @@ func TestHandlePasteCallback(t *testing.T) {
 				token: &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token"}},
 			},
 		},
+		{
+			name: "remote browser with form_post mode",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					h.isTTY = func(fd int) bool { return true }
+					h.useFormPost = true
+					h.remoteBrowser = true
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						assert.Equal(t, "    Paste your authorization code: ", promptLabel)
+						return "valid", nil
+					}
+					h.oauth2Config = &oauth2.Config{RedirectURL: testRedirectURI}
+					h.getProvider = func(_ *oauth2.Config, _ *oidc.Provider, _ *http.Client) provider.UpstreamOIDCIdentityProviderI {
+						mock := mockUpstream(t)
+						mock.EXPECT().
+							ExchangeAuthcodeAndValidateTokens(gomock.Any(), "valid", pkce.Code("test-pkce"), nonce.Nonce("test-nonce"), testRedirectURI).
+							Return(&oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token"}}, nil)
+						return mock
+					}
+					return nil
+				}
+			},
+			wantCallback: &callbackResult{
+				token: &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token"}},
+			},
+		},
+		{
+			name: "remote browser without form_post mode and pasted callback URL",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					h.isTTY = func(fd int) bool { return true }
+					h.remoteBrowser = true
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						assert.Equal(t, "    Paste the URL of that page: ", promptLabel)
+						return "http://127.0.0.1:0/callback?code=valid&state=test-state", nil
+					}
+					h.oauth2Config = &oauth2.Config{RedirectURL: testRedirectURI}
+					h.getProvider = func(_ *oauth2.Config, _ *oidc.Provider, _ *http.Client) provider.UpstreamOIDCIdentityProviderI {
+						mock := mockUpstream(t)
+						mock.EXPECT().
+							ExchangeAuthcodeAndValidateTokens(gomock.Any(), "valid", pkce.Code("test-pkce"), nonce.Nonce("test-nonce"), testRedirectURI).
+							Return(&oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token"}}, nil)
+						return mock
+					}
+					return nil
+				}
+			},
+			wantOutput: "    After logging in, your browser will fail to load a page on 127.0.0.1.\n",
+			wantCallback: &callbackResult{
+				token: &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token"}},
+			},
+		},
+		{
+			name: "pasted callback URL with invalid state",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					h.isTTY = func(fd int) bool { return true }
+					h.remoteBrowser = true
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						return "http://127.0.0.1:0/callback?code=valid&state=wrong-state", nil
+					}
+					return nil
+				}
+			},
+			wantOutput: "    After logging in, your browser will fail to load a page on 127.0.0.1.\n",
+			wantCallback: &callbackResult{
+				err: fmt.Errorf("missing or invalid state parameter in pasted URL"),
+			},
+		},
+		{
+			name: "pasted callback URL with error response",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					h.isTTY = func(fd int) bool { return true }
+					h.useFormPost = true
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						return "http://127.0.0.1:0/callback?error=access_denied&error_description=some+description&state=test-state", nil
+					}
+					return nil
+				}
+			},
+			wantCallback: &callbackResult{
+				err: fmt.Errorf(`login failed with code "access_denied": some description`),
+			},
+		},
+		{
+			name: "pasted callback URL without code",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					h.isTTY = func(fd int) bool { return true }
+					h.useFormPost = true
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						return "http://127.0.0.1:0/callback?state=test-state", nil
+					}
+					return nil
+				}
+			},
+			wantCallback: &callbackResult{
+				err: fmt.Errorf("missing code parameter in pasted URL"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -2052,7 +2403,7 @@ func TestHandlePasteCallback(t *testing.T) {
 			var buf bytes.Buffer
 			h.promptForWebLogin(ctx, "https://test-authorize-url/", &buf)
 			require.Equal(t,
-				"Log in by visiting this link:\n\n    https://test-authorize-url/\n\n",
+				"Log in by visiting this link:\n\n    https://test-authorize-url/\n\n"+tt.wantOutput,
 				buf.String(),
 			)
 
@@ -2068,6 +2419,246 @@ func TestHandlePasteCallback(t *testing.T) {
 	}
 }
 
+func TestWithBrowserCommand(t *testing.T) {
+	t.Parallel()
+
+	h := &handlerState{}
+	require.EqualError(t, WithBrowserCommand("")(h), "browser command must not be empty")
+
+	args := make([]string, 1, 2)
+	args[0] = "--profile=work"
+	var started [][]string
+	h.startCommand = func(name string, args ...string) error {
+		started = append(started, append([]string{name}, args...))
+		return fmt.Errorf("some start error")
+	}
+	require.NoError(t, WithBrowserCommand("some-browser", args...)(h))
+	require.EqualError(t, h.openURL("https://test-authorize-url-1/"), "some start error")
+	require.EqualError(t, h.openURL("https://test-authorize-url-2/"), "some start error")
+	require.Equal(t, [][]string{
+		{"some-browser", "--profile=work", "https://test-authorize-url-1/"},
+		{"some-browser", "--profile=work", "https://test-authorize-url-2/"},
+	}, started)
+	require.Equal(t, []string{"--profile=work"}, args)
+}
+
+func TestPromptForDeviceLogin(t *testing.T) {
+	tests := []struct {
+		name          string
+		showQRCode    bool
+		authorization *deviceAuthorizationResponse
+		wantPrefix    string
+		wantQRCode    bool
+	}{
+		{
+			name: "without verification_uri_complete",
+			authorization: &deviceAuthorizationResponse{
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://test-verification-uri",
+			},
+			wantPrefix: "Log in by visiting this link:\n\n    https://test-verification-uri\n\n" +
+				"and entering the code:\n\n    ABCD-EFGH\n\n",
+		},
+		{
+			name:       "with verification_uri_complete but without QR code",
+			showQRCode: false,
+			authorization: &deviceAuthorizationResponse{
+				UserCode:                "ABCD-EFGH",
+				VerificationURI:         "https://test-verification-uri",
+				VerificationURIComplete: "https://test-verification-uri?user_code=ABCD-EFGH",
+			},
+			wantPrefix: "Log in by visiting this link:\n\n    https://test-verification-uri\n\n" +
+				"and entering the code:\n\n    ABCD-EFGH\n\n" +
+				"Or visit this link, which already includes the code:\n\n    https://test-verification-uri?user_code=ABCD-EFGH\n\n",
+		},
+		{
+			name:       "with QR code",
+			showQRCode: true,
+			authorization: &deviceAuthorizationResponse{
+				UserCode:                "ABCD-EFGH",
+				VerificationURI:         "https://test-verification-uri",
+				VerificationURIComplete: "https://test-verification-uri?user_code=ABCD-EFGH",
+			},
+			wantPrefix: "Log in by visiting this link:\n\n    https://test-verification-uri\n\n" +
+				"and entering the code:\n\n    ABCD-EFGH\n\n" +
+				"Or visit this link, which already includes the code:\n\n    https://test-verification-uri?user_code=ABCD-EFGH\n\n" +
+				"Or scan this QR code:\n\n",
+			wantQRCode: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &handlerState{showQRCode: tt.showQRCode}
+
+			var buf bytes.Buffer
+			h.promptForDeviceLogin(tt.authorization, &buf)
+			require.True(t, strings.HasPrefix(buf.String(), tt.wantPrefix), "unexpected output: %q", buf.String())
+			if !tt.wantQRCode {
+				require.Equal(t, tt.wantPrefix, buf.String())
+				return
+			}
+			rest := strings.TrimPrefix(buf.String(), tt.wantPrefix)
+			require.Contains(t, rest, "\x1b[97;40m")
+			require.True(t, strings.HasSuffix(rest, "\x1b[0m\n\n"))
+		})
+	}
+}
+
+func TestGetUsernameAndPassword(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		files           map[string]string
+		opts            []Option
+		commandOutput   string
+		commandErr      error
+		promptErr       error
+		wantUsername    string
+		wantPassword    string
+		wantCommandRuns int
+		wantPrompts     []string
+		wantErr         string
+	}{
+		{
+			name:         "prompts for both",
+			wantUsername: "prompted-value",
+			wantPassword: "prompted-value",
+			wantPrompts:  []string{"Username: ", "Password: "},
+		},
+		{
+			name:         "env vars",
+			env:          map[string]string{"PINNIPED_USERNAME": "env-username", "PINNIPED_PASSWORD": "env-password"},
+			opts:         []Option{WithCLICredentialFiles("/username-file", "/password-file"), WithCLICredentialsCommand("some-command")},
+			wantUsername: "env-username",
+			wantPassword: "env-password",
+		},
+		{
+			name:         "files",
+			files:        map[string]string{"/username-file": "file-username\n", "/password-file": "file-password\r\n"},
+			opts:         []Option{WithCLICredentialFiles("/username-file", "/password-file"), WithCLICredentialsCommand("some-command")},
+			wantUsername: "file-username",
+			wantPassword: "file-password",
+		},
+		{
+			name:    "missing file",
+			opts:    []Option{WithCLICredentialFiles("/username-file", "")},
+			wantErr: "could not read username file: open /username-file: file does not exist",
+		},
+		{
+			name:    "empty file",
+			files:   map[string]string{"/password-file": "\n"},
+			opts:    []Option{WithCLICredentialFiles("", "/password-file")},
+			env:     map[string]string{"PINNIPED_USERNAME": "env-username"},
+			wantErr: `password file "/password-file" is empty`,
+		},
+		{
+			name:            "command",
+			opts:            []Option{WithCLICredentialsCommand("some-command", "some-arg")},
+			commandOutput:   `{"username":"command-username","password":"command-password"}`,
+			wantUsername:    "command-username",
+			wantPassword:    "command-password",
+			wantCommandRuns: 1,
+		},
+		{
+			name:            "command which only outputs the password",
+			env:             map[string]string{"PINNIPED_USERNAME": "env-username"},
+			opts:            []Option{WithCLICredentialsCommand("some-command", "some-arg")},
+			commandOutput:   `{"password":"command-password"}`,
+			wantUsername:    "env-username",
+			wantPassword:    "command-password",
+			wantCommandRuns: 1,
+		},
+		{
+			name:            "command which only outputs the username",
+			opts:            []Option{WithCLICredentialsCommand("some-command", "some-arg")},
+			commandOutput:   `{"username":"command-username"}`,
+			wantUsername:    "command-username",
+			wantPassword:    "prompted-value",
+			wantCommandRuns: 1,
+			wantPrompts:     []string{"Password: "},
+		},
+		{
+			name:            "command fails",
+			opts:            []Option{WithCLICredentialsCommand("some-command", "some-arg")},
+			commandErr:      errors.New("exit status 1"),
+			wantCommandRuns: 1,
+			wantErr:         `could not run credentials command "some-command": exit status 1`,
+		},
+		{
+			name:            "command has invalid output",
+			opts:            []Option{WithCLICredentialsCommand("some-command", "some-arg")},
+			commandOutput:   "some-password",
+			wantCommandRuns: 1,
+			wantErr:         `could not decode the output of credentials command "some-command": invalid character 's' looking for beginning of value`,
+		},
+		{
+			name:        "stdin is not a terminal",
+			promptErr:   errStdinNotTerminal,
+			wantPrompts: []string{"Username: "},
+			wantErr: "error prompting for username: stdin is not connected to a terminal " +
+				"(to log in non-interactively, provide the username with the PINNIPED_USERNAME environment variable, a file, or a credentials command)",
+		},
+		{
+			name:        "stdin is not a terminal for the password",
+			env:         map[string]string{"PINNIPED_USERNAME": "env-username"},
+			promptErr:   errStdinNotTerminal,
+			wantPrompts: []string{"Password: "},
+			wantErr: "error prompting for password: stdin is not connected to a terminal " +
+				"(to log in non-interactively, provide the password with the PINNIPED_PASSWORD environment variable, a file, or a credentials command)",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var prompts []string
+			prompt := func(promptLabel string) (string, error) {
+				prompts = append(prompts, promptLabel)
+				return "prompted-value", tt.promptErr
+			}
+			commandRuns := 0
+			h := &handlerState{
+				ctx:    context.Background(),
+				logger: logr.Discard(),
+				getEnv: func(key string) string { return tt.env[key] },
+				readFile: func(name string) ([]byte, error) {
+					contents, ok := tt.files[name]
+					if !ok {
+						return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+					}
+					return []byte(contents), nil
+				},
+				runCommand: func(_ context.Context, name string, args ...string) ([]byte, error) {
+					commandRuns++
+					require.Equal(t, "some-command", name)
+					require.Equal(t, []string{"some-arg"}, args)
+					return []byte(tt.commandOutput), tt.commandErr
+				},
+				promptForValue:  func(_ context.Context, promptLabel string) (string, error) { return prompt(promptLabel) },
+				promptForSecret: prompt,
+			}
+			for _, opt := range tt.opts {
+				require.NoError(t, opt(h))
+			}
+
+			username, password, err := h.getUsernameAndPassword()
+			require.Equal(t, tt.wantCommandRuns, commandRuns)
+			require.Equal(t, tt.wantPrompts, prompts)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantUsername, username)
+			require.Equal(t, tt.wantPassword, password)
+		})
+	}
+}
+
 func TestHandleAuthCodeCallback(t *testing.T) {
 	const testRedirectURI = "http://127.0.0.1:12324/callback"
 
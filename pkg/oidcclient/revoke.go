@@ -0,0 +1,67 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+)
+
+// RevokeRefreshToken revokes a refresh token at the revocation endpoint of the issuer, as defined by
+// https://datatracker.ietf.org/doc/html/rfc7009. At a Pinniped Supervisor, this ends the whole session of the token.
+// The client authenticates only by sending its client ID, like the pinniped-cli client does at the token endpoint.
+func RevokeRefreshToken(ctx context.Context, httpClient *http.Client, issuer, clientID, refreshToken string) error {
+	// Validate that the issuer URL uses https, or else we cannot trust its discovery endpoint to get the other URLs.
+	if err := validateURLUsesHTTPS(issuer, "issuer"); err != nil {
+		return err
+	}
+
+	provider, err := coreosoidc.NewProvider(coreosoidc.ClientContext(ctx, httpClient), issuer)
+	if err != nil {
+		return fmt.Errorf("could not perform OIDC discovery for %q: %w", issuer, err)
+	}
+	var discoveryClaims struct {
+		RevocationEndpoint string `json:"revocation_endpoint"`
+	}
+	if err := provider.Claims(&discoveryClaims); err != nil {
+		return fmt.Errorf("could not decode revocation_endpoint in OIDC discovery from %q: %w", issuer, err)
+	}
+	if discoveryClaims.RevocationEndpoint == "" {
+		return fmt.Errorf("issuer %q does not support token revocation: its OIDC discovery does not include a revocation_endpoint", issuer)
+	}
+	if err := validateURLUsesHTTPS(discoveryClaims.RevocationEndpoint, "discovered revocation URL from issuer"); err != nil {
+		return err
+	}
+
+	params := url.Values{
+		"client_id":       []string{clientID},
+		"token":           []string{refreshToken},
+		"token_type_hint": []string{"refresh_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discoveryClaims.RevocationEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("could not build token revocation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token revocation request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Per RFC 7009, the response is a success even when the token was already invalid.
+	if resp.StatusCode != http.StatusOK {
+		if oauthErr := decodeOAuth2Error(resp); oauthErr != nil {
+			return fmt.Errorf("token revocation failed: %w", oauthErr)
+		}
+		return fmt.Errorf("token revocation failed: unexpected response status %q", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/testutil/tlsserver"
+)
+
+func TestRevokeRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	var sawRevocationParams []url.Values
+	mux := http.NewServeMux()
+	server := tlsserver.TLSTestServer(t, mux, nil)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"revocation_endpoint":    server.URL + "/revoke",
+		})
+	})
+	mux.HandleFunc("/revoke", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		sawRevocationParams = append(sawRevocationParams, r.PostForm)
+		switch r.PostForm.Get("client_id") {
+		case "test-client-id":
+			w.WriteHeader(http.StatusOK)
+		case "unauthorized-client-id":
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"unauthorized_client","error_description":"some description"}`))
+		default:
+			http.Error(w, "some error", http.StatusInternalServerError)
+		}
+	})
+
+	noRevocationMux := http.NewServeMux()
+	noRevocationServer := tlsserver.TLSTestServer(t, noRevocationMux, nil)
+	noRevocationMux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 noRevocationServer.URL,
+			"authorization_endpoint": noRevocationServer.URL + "/authorize",
+			"token_endpoint":         noRevocationServer.URL + "/token",
+		})
+	})
+
+	ctx := context.Background()
+	client := newClientForServer(server)
+
+	require.NoError(t, RevokeRefreshToken(ctx, client, server.URL, "test-client-id", "test-refresh-token"))
+	require.Equal(t, []url.Values{{
+		"client_id":       []string{"test-client-id"},
+		"token":           []string{"test-refresh-token"},
+		"token_type_hint": []string{"refresh_token"},
+	}}, sawRevocationParams)
+
+	require.EqualError(t,
+		RevokeRefreshToken(ctx, client, server.URL, "unauthorized-client-id", "test-refresh-token"),
+		"token revocation failed: unauthorized_client: some description")
+
+	require.EqualError(t,
+		RevokeRefreshToken(ctx, client, server.URL, "other-client-id", "test-refresh-token"),
+		`token revocation failed: unexpected response status "500 Internal Server Error"`)
+
+	require.EqualError(t,
+		RevokeRefreshToken(ctx, newClientForServer(noRevocationServer), noRevocationServer.URL, "test-client-id", "test-refresh-token"),
+		`issuer "`+noRevocationServer.URL+`" does not support token revocation: its OIDC discovery does not include a revocation_endpoint`)
+
+	require.EqualError(t,
+		RevokeRefreshToken(ctx, client, "http://insecure-issuer.example.com", "test-client-id", "test-refresh-token"),
+		`issuer must be an https URL, but had scheme "http" instead`)
+}
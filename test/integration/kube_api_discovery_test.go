@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -57,6 +57,8 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 	idpSupervisorGV := makeGV("idp", "supervisor")
 	configSupervisorGV := makeGV("config", "supervisor")
 	clientSecretSupervisorGV := makeGV("clientsecret", "supervisor")
+	loginCheckSupervisorGV := makeGV("logincheck", "supervisor")
+	sessionSupervisorGV := makeGV("session", "supervisor")
 
 	tests := []struct {
 		group             metav1.APIGroup
@@ -143,6 +145,68 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 				},
 			},
 		},
+		{
+			group: metav1.APIGroup{
+				Name: loginCheckSupervisorGV.Group,
+				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: loginCheckSupervisorGV.String(),
+						Version:      loginCheckSupervisorGV.Version,
+					},
+				},
+				PreferredVersion: metav1.GroupVersionForDiscovery{
+					GroupVersion: loginCheckSupervisorGV.String(),
+					Version:      loginCheckSupervisorGV.Version,
+				},
+			},
+			resourceByVersion: map[string][]metav1.APIResource{
+				loginCheckSupervisorGV.String(): {
+					{
+						Name:         "ldaplogincheckrequests",
+						SingularName: "ldaplogincheckrequest",
+						Kind:         "LDAPLoginCheckRequest",
+						Verbs:        []string{"create", "list"},
+						Namespaced:   true,
+						Categories:   []string{"pinniped"},
+					},
+				},
+			},
+		},
+		{
+			group: metav1.APIGroup{
+				Name: sessionSupervisorGV.Group,
+				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: sessionSupervisorGV.String(),
+						Version:      sessionSupervisorGV.Version,
+					},
+				},
+				PreferredVersion: metav1.GroupVersionForDiscovery{
+					GroupVersion: sessionSupervisorGV.String(),
+					Version:      sessionSupervisorGV.Version,
+				},
+			},
+			resourceByVersion: map[string][]metav1.APIResource{
+				sessionSupervisorGV.String(): {
+					{
+						Name:         "sessionlistrequests",
+						SingularName: "sessionlistrequest",
+						Kind:         "SessionListRequest",
+						Verbs:        []string{"create", "list"},
+						Namespaced:   true,
+						Categories:   []string{"pinniped"},
+					},
+					{
+						Name:         "sessionrevocationrequests",
+						SingularName: "sessionrevocationrequest",
+						Kind:         "SessionRevocationRequest",
+						Verbs:        []string{"create", "list"},
+						Namespaced:   true,
+						Categories:   []string{"pinniped"},
+					},
+				},
+			},
+		},
 		{
 			group: metav1.APIGroup{
 				Name: configSupervisorGV.Group,
@@ -248,6 +312,62 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 						Kind:       "ActiveDirectoryIdentityProvider",
 						Verbs:      []string{"get", "patch", "update"},
 					},
+					{
+						Name:         "oauth2identityproviders",
+						SingularName: "oauth2identityprovider",
+						Namespaced:   true,
+						Kind:         "OAuth2IdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "oauth2identityproviders/status",
+						Namespaced: true,
+						Kind:       "OAuth2IdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "githubidentityproviders",
+						SingularName: "githubidentityprovider",
+						Namespaced:   true,
+						Kind:         "GitHubIdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "githubidentityproviders/status",
+						Namespaced: true,
+						Kind:       "GitHubIdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "gitlabidentityproviders",
+						SingularName: "gitlabidentityprovider",
+						Namespaced:   true,
+						Kind:         "GitLabIdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "gitlabidentityproviders/status",
+						Namespaced: true,
+						Kind:       "GitLabIdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "kerberosidentityproviders",
+						SingularName: "kerberosidentityprovider",
+						Namespaced:   true,
+						Kind:         "KerberosIdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "kerberosidentityproviders/status",
+						Namespaced: true,
+						Kind:       "KerberosIdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
 				},
 			},
 		},
@@ -386,7 +506,8 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 	t.Run("every API has a status subresource", func(t *testing.T) {
 		t.Parallel()
 
-		aggregatedAPIs := sets.NewString("tokencredentialrequests", "whoamirequests", "oidcclientsecretrequests")
+		aggregatedAPIs := sets.NewString("tokencredentialrequests", "whoamirequests", "oidcclientsecretrequests", "ldaplogincheckrequests",
+			"sessionlistrequests", "sessionrevocationrequests")
 
 		var regular, status []string
 
@@ -569,6 +690,34 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
 		},
+		addSuffix("oauth2identityproviders.idp.supervisor"): {
+			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Authorization URL", Type: "string", JSONPath: ".spec.authorizationURL"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
+		},
+		addSuffix("githubidentityproviders.idp.supervisor"): {
+			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Host", Type: "string", JSONPath: ".spec.githubAPI.host"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
+		},
+		addSuffix("gitlabidentityproviders.idp.supervisor"): {
+			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Host", Type: "string", JSONPath: ".spec.host"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
+		},
+		addSuffix("kerberosidentityproviders.idp.supervisor"): {
+			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Service Principal", Type: "string", JSONPath: ".spec.servicePrincipalName"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
+		},
 		addSuffix("oidcidentityproviders.idp.supervisor"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
 				{Name: "Issuer", Type: "string", JSONPath: ".spec.issuer"},
@@ -587,7 +736,7 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 	}
 
 	actualPinnipedCRDCount := 0
-	expectedPinnipedCRDCount := 8 // the current number of CRDs that we ship as part of Pinniped
+	expectedPinnipedCRDCount := 12 // the current number of CRDs that we ship as part of Pinniped
 
 	for _, crd := range crdList.Items {
 		if !strings.Contains(crd.Spec.Group, env.APIGroupSuffix) {
@@ -1,4 +1,4 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -1785,7 +1785,7 @@ func wantGroupsInAdditionalClaimsIfGroupsExist(additionalClaims map[string]inter
 }
 
 func requireSuccessfulLDAPIdentityProviderConditions(t *testing.T, ldapIDP *idpv1alpha1.LDAPIdentityProvider, expectedLDAPConnectionValidMessage string) {
-	require.Len(t, ldapIDP.Status.Conditions, 3)
+	require.Len(t, ldapIDP.Status.Conditions, 7)
 
 	conditionsSummary := [][]string{}
 	for _, condition := range ldapIDP.Status.Conditions {
@@ -1797,6 +1797,12 @@ func requireSuccessfulLDAPIdentityProviderConditions(t *testing.T, ldapIDP *idpv
 			require.Equal(t, "loaded bind secret", condition.Message)
 		case "TLSConfigurationValid":
 			require.Equal(t, "loaded TLS configuration", condition.Message)
+		case "TimeoutsValid":
+			require.Equal(t, "loaded timeouts", condition.Message)
+		case "ProxyURLValid":
+			require.Equal(t, "no proxy URL provided", condition.Message)
+		case "SearchOptionsValid":
+			require.Equal(t, "loaded search options", condition.Message)
 		case "LDAPConnectionValid":
 			require.Equal(t, expectedLDAPConnectionValidMessage, condition.Message)
 		}
@@ -1805,12 +1811,16 @@ func requireSuccessfulLDAPIdentityProviderConditions(t *testing.T, ldapIDP *idpv
 	require.ElementsMatch(t, [][]string{
 		{"BindSecretValid", "True", "Success"},
 		{"TLSConfigurationValid", "True", "Success"},
+		{"TimeoutsValid", "True", "Success"},
+		{"ProxyURLValid", "True", "Success"},
+		{"SearchOptionsValid", "True", "Success"},
 		{"LDAPConnectionValid", "True", "Success"},
+		{"ActiveBindCredentials", "True", "UsingPrimaryBindCredentials"},
 	}, conditionsSummary)
 }
 
 func requireSuccessfulActiveDirectoryIdentityProviderConditions(t *testing.T, adIDP *idpv1alpha1.ActiveDirectoryIdentityProvider, expectedActiveDirectoryConnectionValidMessage string) {
-	require.Len(t, adIDP.Status.Conditions, 4)
+	require.Len(t, adIDP.Status.Conditions, 9)
 
 	conditionsSummary := [][]string{}
 	for _, condition := range adIDP.Status.Conditions {
@@ -1822,6 +1832,12 @@ func requireSuccessfulActiveDirectoryIdentityProviderConditions(t *testing.T, ad
 			require.Equal(t, "loaded bind secret", condition.Message)
 		case "TLSConfigurationValid":
 			require.Equal(t, "loaded TLS configuration", condition.Message)
+		case "TimeoutsValid":
+			require.Equal(t, "loaded timeouts", condition.Message)
+		case "ProxyURLValid":
+			require.Equal(t, "no proxy URL provided", condition.Message)
+		case "SearchOptionsValid":
+			require.Equal(t, "loaded search options", condition.Message)
 		case "LDAPConnectionValid":
 			require.Equal(t, expectedActiveDirectoryConnectionValidMessage, condition.Message)
 		}
@@ -1837,14 +1853,19 @@ func requireSuccessfulActiveDirectoryIdentityProviderConditions(t *testing.T, ad
 	require.ElementsMatch(t, [][]string{
 		{"BindSecretValid", "True", "Success"},
 		{"TLSConfigurationValid", "True", "Success"},
+		{"TimeoutsValid", "True", "Success"},
+		{"ProxyURLValid", "True", "Success"},
+		{"SearchOptionsValid", "True", "Success"},
 		{"LDAPConnectionValid", "True", "Success"},
+		{"ActiveBindCredentials", "True", "UsingPrimaryBindCredentials"},
 		{"SearchBaseFound", "True", expectedUserSearchReason},
+		{"DomainControllersDiscovered", "True", "UsingConfigurationFromSpec"},
 	}, conditionsSummary)
 }
 
 func requireEventuallySuccessfulLDAPIdentityProviderConditions(t *testing.T, requireEventually *require.Assertions, ldapIDP *idpv1alpha1.LDAPIdentityProvider, expectedLDAPConnectionValidMessage string) {
 	t.Helper()
-	requireEventually.Len(ldapIDP.Status.Conditions, 3)
+	requireEventually.Len(ldapIDP.Status.Conditions, 7)
 
 	conditionsSummary := [][]string{}
 	for _, condition := range ldapIDP.Status.Conditions {
@@ -1856,6 +1877,12 @@ func requireEventuallySuccessfulLDAPIdentityProviderConditions(t *testing.T, req
 			requireEventually.Equal("loaded bind secret", condition.Message)
 		case "TLSConfigurationValid":
 			requireEventually.Equal("loaded TLS configuration", condition.Message)
+		case "TimeoutsValid":
+			requireEventually.Equal("loaded timeouts", condition.Message)
+		case "ProxyURLValid":
+			requireEventually.Equal("no proxy URL provided", condition.Message)
+		case "SearchOptionsValid":
+			requireEventually.Equal("loaded search options", condition.Message)
 		case "LDAPConnectionValid":
 			requireEventually.Equal(expectedLDAPConnectionValidMessage, condition.Message)
 		}
@@ -1864,13 +1891,17 @@ func requireEventuallySuccessfulLDAPIdentityProviderConditions(t *testing.T, req
 	requireEventually.ElementsMatch([][]string{
 		{"BindSecretValid", "True", "Success"},
 		{"TLSConfigurationValid", "True", "Success"},
+		{"TimeoutsValid", "True", "Success"},
+		{"ProxyURLValid", "True", "Success"},
+		{"SearchOptionsValid", "True", "Success"},
 		{"LDAPConnectionValid", "True", "Success"},
+		{"ActiveBindCredentials", "True", "UsingPrimaryBindCredentials"},
 	}, conditionsSummary)
 }
 
 func requireEventuallySuccessfulActiveDirectoryIdentityProviderConditions(t *testing.T, requireEventually *require.Assertions, adIDP *idpv1alpha1.ActiveDirectoryIdentityProvider, expectedActiveDirectoryConnectionValidMessage string) {
 	t.Helper()
-	requireEventually.Len(adIDP.Status.Conditions, 4)
+	requireEventually.Len(adIDP.Status.Conditions, 9)
 
 	conditionsSummary := [][]string{}
 	for _, condition := range adIDP.Status.Conditions {
@@ -1882,6 +1913,12 @@ func requireEventuallySuccessfulActiveDirectoryIdentityProviderConditions(t *tes
 			requireEventually.Equal("loaded bind secret", condition.Message)
 		case "TLSConfigurationValid":
 			requireEventually.Equal("loaded TLS configuration", condition.Message)
+		case "TimeoutsValid":
+			requireEventually.Equal("loaded timeouts", condition.Message)
+		case "ProxyURLValid":
+			requireEventually.Equal("no proxy URL provided", condition.Message)
+		case "SearchOptionsValid":
+			requireEventually.Equal("loaded search options", condition.Message)
 		case "LDAPConnectionValid":
 			requireEventually.Equal(expectedActiveDirectoryConnectionValidMessage, condition.Message)
 		}
@@ -1897,8 +1934,13 @@ func requireEventuallySuccessfulActiveDirectoryIdentityProviderConditions(t *tes
 	requireEventually.ElementsMatch([][]string{
 		{"BindSecretValid", "True", "Success"},
 		{"TLSConfigurationValid", "True", "Success"},
+		{"TimeoutsValid", "True", "Success"},
+		{"ProxyURLValid", "True", "Success"},
+		{"SearchOptionsValid", "True", "Success"},
 		{"LDAPConnectionValid", "True", "Success"},
+		{"ActiveBindCredentials", "True", "UsingPrimaryBindCredentials"},
 		{"SearchBaseFound", "True", expectedUserSearchReason},
+		{"DomainControllersDiscovered", "True", "UsingConfigurationFromSpec"},
 	}, conditionsSummary)
 }
 
@@ -2119,7 +2161,7 @@ func testSupervisorLogin(
 	}
 	require.NoError(t, err)
 
-	expectedIDTokenClaims := []string{"iss", "exp", "sub", "aud", "auth_time", "iat", "jti", "nonce", "rat", "azp", "at_hash"}
+	expectedIDTokenClaims := []string{"iss", "exp", "sub", "aud", "auth_time", "iat", "jti", "nonce", "rat", "azp", "sid", "at_hash"}
 	if slices.Contains(wantDownstreamScopes, "username") {
 		// If the test wants the username scope to have been granted, then also expect the claim in the ID token.
 		expectedIDTokenClaims = append(expectedIDTokenClaims, "username")
@@ -2179,7 +2221,7 @@ func testSupervisorLogin(
 	require.NoError(t, err)
 
 	// When refreshing, do not expect a "nonce" claim.
-	expectRefreshedIDTokenClaims := []string{"iss", "exp", "sub", "aud", "auth_time", "iat", "jti", "rat", "azp", "at_hash"}
+	expectRefreshedIDTokenClaims := []string{"iss", "exp", "sub", "aud", "auth_time", "iat", "jti", "rat", "azp", "sid", "at_hash"}
 	if slices.Contains(wantDownstreamScopes, "username") {
 		// If the test wants the username scope to have been granted, then also expect the claim in the refreshed ID token.
 		expectRefreshedIDTokenClaims = append(expectRefreshedIDTokenClaims, "username")
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -549,18 +549,36 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 					Reason:  "MissingRequiredValue",
 					Message: `"authorization_code" must always be included in "allowedGrantTypes"`,
 				},
+				{
+					Type:    "AllowedRedirectURIsValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"allowedRedirectURIs" is valid`,
+				},
 				{
 					Type:    "AllowedScopesValid",
 					Status:  "False",
 					Reason:  "MissingRequiredValue",
 					Message: `"openid" must always be included in "allowedScopes"; "offline_access" must be included in "allowedScopes" when "refresh_token" is included in "allowedGrantTypes"`,
 				},
+				{
+					Type:    "ClientCredentialsValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"clientCredentials" is valid`,
+				},
 				{
 					Type:    "ClientSecretExists",
 					Status:  "False",
 					Reason:  "NoClientSecretFound",
 					Message: `no client secret found (no Secret storage found)`,
 				},
+				{
+					Type:    "TokenLifetimesValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"tokenLifetimes" is valid`,
+				},
 			},
 		},
 		{
@@ -584,18 +602,36 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 					Reason:  "Success",
 					Message: `"allowedGrantTypes" is valid`,
 				},
+				{
+					Type:    "AllowedRedirectURIsValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"allowedRedirectURIs" is valid`,
+				},
 				{
 					Type:    "AllowedScopesValid",
 					Status:  "True",
 					Reason:  "Success",
 					Message: `"allowedScopes" is valid`,
 				},
+				{
+					Type:    "ClientCredentialsValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"clientCredentials" is valid`,
+				},
 				{
 					Type:    "ClientSecretExists",
 					Status:  "False",
 					Reason:  "NoClientSecretFound",
 					Message: `no client secret found (empty list in storage)`,
 				},
+				{
+					Type:    "TokenLifetimesValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"tokenLifetimes" is valid`,
+				},
 			},
 		},
 		{
@@ -619,18 +655,36 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 					Reason:  "Success",
 					Message: `"allowedGrantTypes" is valid`,
 				},
+				{
+					Type:    "AllowedRedirectURIsValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"allowedRedirectURIs" is valid`,
+				},
 				{
 					Type:    "AllowedScopesValid",
 					Status:  "True",
 					Reason:  "Success",
 					Message: `"allowedScopes" is valid`,
 				},
+				{
+					Type:    "ClientCredentialsValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"clientCredentials" is valid`,
+				},
 				{
 					Type:    "ClientSecretExists",
 					Status:  "True",
 					Reason:  "Success",
 					Message: `1 client secret(s) found`,
 				},
+				{
+					Type:    "TokenLifetimesValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"tokenLifetimes" is valid`,
+				},
 			},
 		},
 		// Note: there are many more possible combinations of these settings, but they are covered by the controller's
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2026 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -948,6 +948,8 @@ func TestCreateOIDCClientSecretRequest_Parallel(t *testing.T) {
 
 					require.Equalf(t, clientSecretRequestResponse.Status.TotalClientSecrets, ttt.wantSecretCount,
 						"expected secret count is incorrect on iteration %d", n)
+					require.Lenf(t, clientSecretRequestResponse.Status.ClientSecrets, ttt.wantSecretCount,
+						"expected listed secrets count is incorrect on iteration %d", n)
 
 					if ttt.secretRequest.Spec.GenerateNewSecret {
 						require.Len(t, clientSecretRequestResponse.Status.GeneratedSecret, hex.EncodedLen(32),
@@ -1003,6 +1005,82 @@ func TestCreateOIDCClientSecretRequest_Parallel(t *testing.T) {
 	}
 }
 
+func TestOIDCClientSecretRequestExpiryAndRevocation_Parallel(t *testing.T) {
+	env := testlib.IntegrationEnv(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	t.Cleanup(cancel)
+
+	supervisorClient := testlib.NewSupervisorClientset(t)
+	secretRequests := supervisorClient.ClientsecretV1alpha1().OIDCClientSecretRequests(env.SupervisorNamespace)
+
+	oidcClient, err := supervisorClient.ConfigV1alpha1().OIDCClients(env.SupervisorNamespace).Create(ctx,
+		&supervisorconfigv1alpha1.OIDCClient{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "client.oauth.pinniped.dev-",
+			},
+			Spec: supervisorconfigv1alpha1.OIDCClientSpec{
+				AllowedRedirectURIs: []supervisorconfigv1alpha1.RedirectURI{"https://example.com"},
+				AllowedGrantTypes:   []supervisorconfigv1alpha1.GrantType{"authorization_code"},
+				AllowedScopes:       []supervisorconfigv1alpha1.Scope{"openid"},
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, supervisorClient.ConfigV1alpha1().
+			OIDCClients(env.SupervisorNamespace).Delete(context.Background(), oidcClient.Name, metav1.DeleteOptions{}))
+	})
+
+	newRequest := func(spec v1alpha1.OIDCClientSecretRequestSpec) *v1alpha1.OIDCClientSecretRequest {
+		return &v1alpha1.OIDCClientSecretRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: oidcClient.Name, Namespace: env.SupervisorNamespace},
+			Spec:       spec,
+		}
+	}
+
+	first, err := secretRequests.Create(ctx, newRequest(v1alpha1.OIDCClientSecretRequestSpec{
+		GenerateNewSecret: true,
+	}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Len(t, first.Status.ClientSecrets, 1)
+	require.NotNil(t, first.Status.ClientSecrets[0].CreationTimestamp)
+	require.Nil(t, first.Status.ClientSecrets[0].ExpirationTimestamp)
+
+	// Generate a second secret while keeping the first one valid for an hour.
+	second, err := secretRequests.Create(ctx, newRequest(v1alpha1.OIDCClientSecretRequestSpec{
+		GenerateNewSecret:         true,
+		OldSecretsLifetimeSeconds: 3600,
+	}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Status.TotalClientSecrets)
+	require.Len(t, second.Status.ClientSecrets, 2)
+	require.Nil(t, second.Status.ClientSecrets[0].ExpirationTimestamp)
+	require.Equal(t, first.Status.ClientSecrets[0].Hash, second.Status.ClientSecrets[1].Hash)
+	require.NotNil(t, second.Status.ClientSecrets[1].ExpirationTimestamp)
+	testutil.RequireTimeInDelta(t, second.Status.ClientSecrets[1].ExpirationTimestamp.Time, time.Now().Add(time.Hour), time.Minute)
+
+	// Both secrets are still valid.
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(second.Status.ClientSecrets[0].Hash), []byte(second.Status.GeneratedSecret)))
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(second.Status.ClientSecrets[1].Hash), []byte(first.Status.GeneratedSecret)))
+
+	// Revoke the first secret individually, before it expires.
+	revoked, err := secretRequests.Create(ctx, newRequest(v1alpha1.OIDCClientSecretRequestSpec{
+		RevokeSecretHashes: []string{first.Status.ClientSecrets[0].Hash},
+	}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, revoked.Status.TotalClientSecrets)
+	require.Equal(t, second.Status.ClientSecrets[:1], revoked.Status.ClientSecrets)
+
+	// Revoking it again fails, because it no longer exists.
+	_, err = secretRequests.Create(ctx, newRequest(v1alpha1.OIDCClientSecretRequestSpec{
+		RevokeSecretHashes: []string{first.Status.ClientSecrets[0].Hash},
+	}), metav1.CreateOptions{})
+	require.Error(t, err)
+	require.True(t, k8serrors.IsInvalid(err))
+}
+
 func retainOnlyMostRecentSecret(list []string) []string {
 	if len(list) == 0 {
 		return []string{}